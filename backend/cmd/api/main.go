@@ -1,19 +1,30 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/config"
 	"github.com/davidM20/micro-service-backend-go.git/internal/db"
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
 	"github.com/davidM20/micro-service-backend-go.git/internal/routes"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/cloudclient"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/httpcompress"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/httpmetrics"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/imagemoderation"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/scan"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/storage"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/timefmt"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	// Todas las fechas del proceso en UTC (ver docs/fechas.md)
+	timefmt.UseUTC()
+
 	// Cargar variables de entorno desde .env (opcional, pero recomendado)
 	err := godotenv.Load()
 	if err != nil {
@@ -25,46 +36,127 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	cfg.LogReport("CONFIG")
 
-	// Inicializar cliente GCS
-	if cfg.GCSBucketName != "" && cfg.GCSServiceAccountKey != "" {
+	// Inicializar el almacenamiento de archivos (STORAGE_DRIVER)
+	store, err := storage.New(storage.Options{
+		Driver:             cfg.StorageDriver,
+		GCSBucket:          cfg.GCSBucketName,
+		GCSCredentialsFile: cfg.GCSServiceAccountKey,
+		S3Endpoint:         cfg.S3Endpoint,
+		S3Region:           cfg.S3Region,
+		S3Bucket:           cfg.S3Bucket,
+		S3AccessKey:        cfg.S3AccessKeyID,
+		S3SecretKey:        cfg.S3SecretAccessKey,
+		S3PathStyle:        cfg.S3UsePathStyle,
+		S3PublicURL:        cfg.S3PublicURL,
+		LocalDir:           cfg.StorageLocalDir,
+		LocalBaseURL:       cfg.StorageLocalBaseURL,
+		LocalSigningKey:    cfg.JwtSecret,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize file storage: %v", err)
+	}
+	if cloudclient.GetBucketHandle() != nil {
+		log.Println("Google Cloud Storage client initialized successfully.")
+	} else if cfg.GCSBucketName != "" && cfg.GCSServiceAccountKey != "" {
+		// Audios, PDFs y exportaciones de chat aún usan cloudclient directamente.
 		if err := cloudclient.Open(cfg.GCSBucketName, cfg.GCSServiceAccountKey); err != nil {
 			log.Fatalf("Failed to initialize Google Cloud Storage client: %v", err)
-		} else {
-			log.Println("Google Cloud Storage client initialized successfully.")
 		}
-	} else {
+	} else if cfg.StorageDriver == storage.DriverGCS {
 		log.Println("GCS_BUCKET_NAME or GCS_SERVICE_ACCOUNT_KEY_PATH not set, GCS client not initialized.")
 	}
+	log.Printf("File storage driver: %s", cfg.StorageDriver)
+
+	// Análisis de malware de las subidas (UPLOAD_SCANNER); nil si está desactivado
+	scanner, err := scan.New(scan.Options{
+		Driver:        cfg.UploadScanner,
+		ClamAVAddress: cfg.ClamAVAddress,
+		HTTPURL:       cfg.UploadScanURL,
+		HTTPAPIKey:    cfg.UploadScanAPIKey,
+		Timeout:       cfg.UploadScanTimeout,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize upload scanner: %v", err)
+	}
+	if scanner != nil {
+		log.Printf("Upload scanner: %s", cfg.UploadScanner)
+	}
+
+	// Moderación automática de imágenes (IMAGE_MODERATION_DRIVER); nil si está desactivada
+	classifier, err := imagemoderation.New(imagemoderation.Options{
+		Driver:  cfg.ImageModerationDriver,
+		URL:     cfg.ImageModerationURL,
+		APIKey:  cfg.ImageModerationAPIKey,
+		Timeout: cfg.ImageModerationTimeout,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize image moderation: %v", err)
+	}
+	if classifier != nil {
+		log.Printf("Image moderation: %s", cfg.ImageModerationDriver)
+	}
 
 	// Conectar e inicializar la base de datos
-	dbConn, err := db.Connect(cfg.DatabaseDSN)
+	poolConfig := db.NewPoolConfig(cfg)
+	dbConn, err := db.Connect(cfg.DatabaseDSN, poolConfig)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
+	db.SetSlowQueryThreshold(cfg.DBSlowQueryThreshold)
+	// Muestreo periódico de las estadísticas del pool (DB_STATS_INTERVAL)
+	go db.NewPoolMonitor(dbConn, poolConfig).Run(context.Background())
+	// Modo solo lectura ante caídas de la BD o retraso de la réplica (docs/modo_solo_lectura.md)
+	readOnlyBreaker, err := db.NewReadOnlyBreaker(dbConn, db.NewBreakerConfig(cfg))
+	if err != nil {
+		log.Fatalf("Failed to initialize read-only mode: %v", err)
+	}
+	go readOnlyBreaker.Run(context.Background())
 	if err := db.InitializeDatabase(dbConn); err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
 	// Inicializar el paquete de consultas con la conexión a la BD
 	queries.InitDB(dbConn)
+	queries.InitCache(cfg)
 
 	// Configurar el router principal
 	mainRouter := mux.NewRouter()
 
+	// Métricas de peticiones por ruta, consultables en /admin/metrics/http
+	httpMetrics := httpmetrics.NewRegistry()
+
 	// Configurar las rutas de la API
-	routes.SetupApiRoutes(mainRouter, dbConn, cfg)
+	routes.SetupApiRoutes(mainRouter, dbConn, cfg, httpMetrics, store, scanner, classifier)
 
-	// CORS manejado por el proxy - no aplicar aquí para evitar duplicación
-	httpHandler := mainRouter
+	// CORS manejado por el proxy - no aplicar aquí para evitar duplicación.
+	// La compresión queda dentro del log para que las métricas cuenten los bytes enviados.
+	var routerHandler http.Handler = mainRouter
+	if cfg.CompressionEnabled {
+		routerHandler = httpcompress.Middleware(httpcompress.Options{
+			MinSize: cfg.CompressionMinSize,
+			Types:   httpcompress.ParseTypes(cfg.CompressionTypes),
+			Level:   cfg.CompressionLevel,
+		})(routerHandler)
+	}
+	// El log de peticiones envuelve al router para medir también las rutas inexistentes. Los
+	// pánicos se recuperan dentro para que queden en el log y en las métricas de la ruta.
+	httpHandler := middleware.RequestLogging(cfg, httpMetrics)(middleware.Recovery(routerHandler))
 
 	// Configurar servidor HTTP
 	serverAddr := cfg.ApiPort
 	log.Printf("API Server starting on port %s (CORS handled by proxy)...", serverAddr)
 
+	// Los plazos de lectura y escritura se renuevan en cada petición (middleware.RequestLimits),
+	// y las subidas y descargas grandes los amplían con API_UPLOAD_TIMEOUT.
 	srv := &http.Server{
-		Handler: httpHandler,
-		Addr:    ":" + serverAddr,
+		Handler:           httpHandler,
+		Addr:              ":" + serverAddr,
+		ReadHeaderTimeout: cfg.APIReadHeaderTimeout,
+		ReadTimeout:       cfg.APIReadTimeout,
+		WriteTimeout:      cfg.APIWriteTimeout,
+		IdleTimeout:       cfg.APIIdleTimeout,
 	}
 
 	// Iniciar servidor
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/joho/godotenv"
+)
+
+// runEncryptFields implementa el subcomando `devtools encrypt-fields`: cifra con
+// FIELD_ENCRYPTION_KEY los valores de DocId, Phone y RIF que están en texto plano o cifrados con
+// una clave anterior y calcula sus índices ciegos. Con -decrypt los vuelve a dejar en texto
+// plano. Se puede ejecutar varias veces y con los servidores en marcha.
+func runEncryptFields(args []string) {
+	fs := flag.NewFlagSet("encrypt-fields", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Calcula el informe sin modificar la base de datos")
+	decrypt := fs.Bool("decrypt", false, "Descifra las columnas y borra los índices ciegos (antes de desactivar el cifrado)")
+	fs.Parse(args)
+
+	fmt.Printf("%s%s🔐 Cifrado de columnas sensibles%s\n", Bold, Cyan, Reset)
+
+	if err := godotenv.Load(); err != nil {
+		fmt.Printf("%s[ENCRYPT]%s No se pudo cargar .env, usando variables de entorno\n", Yellow, Reset)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Printf("%s[ENCRYPT]%s Error cargando configuración: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+	cipher, err := cfg.FieldCipher()
+	if err != nil {
+		fmt.Printf("%s[ENCRYPT]%s Error en las claves de cifrado: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+	if cipher == nil {
+		fmt.Printf("%s[ENCRYPT]%s FIELD_ENCRYPTION_KEY y FIELD_ENCRYPTION_INDEX_KEY son obligatorias\n", Red, Reset)
+		os.Exit(1)
+	}
+	conn, err := db.Connect(cfg.DatabaseDSN, db.NewPoolConfig(cfg))
+	if err != nil {
+		fmt.Printf("%s[ENCRYPT]%s Error conectando a la base de datos: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+	// Crea DocIdHash y RIFHash y amplía RIF si la base de datos es anterior.
+	if err := db.InitializeDatabase(conn); err != nil {
+		fmt.Printf("%s[ENCRYPT]%s Error inicializando la base de datos: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+	queries.InitDB(conn)
+
+	fmt.Printf("%s[ENCRYPT]%s Clave actual: %s\n", Cyan, Reset, cipher.KeyID())
+	report, err := services.RewriteUserSensitiveFields(cipher, *decrypt, *dryRun)
+	if err != nil {
+		fmt.Printf("%s[ENCRYPT]%s Error reescribiendo las columnas: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s[ENCRYPT]%s Revisadas: %d\n", Green, Reset, report.Scanned)
+	fmt.Printf("%s[ENCRYPT]%s Reescritas: %d\n", Green, Reset, report.Updated)
+	columns := make([]string, 0, len(report.ByColumn))
+	for column := range report.ByColumn {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	for _, column := range columns {
+		fmt.Printf("           %-8s %d\n", column, report.ByColumn[column])
+	}
+	if report.Conflicts > 0 {
+		fmt.Printf("%s[ENCRYPT]%s Modificadas durante la migración: %d (vuelve a ejecutar el comando)\n", Yellow, Reset, report.Conflicts)
+	}
+
+	if *dryRun {
+		fmt.Printf("\n%s%s🔐 Simulación: no se modificó la base de datos%s\n", Bold, Yellow, Reset)
+	}
+}
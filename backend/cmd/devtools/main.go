@@ -3,13 +3,13 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"os/signal"
 	"strings"
-	"sync"
 	"syscall"
 	"time"
 )
@@ -35,9 +35,41 @@ type Service struct {
 	Cmd       *exec.Cmd
 	Port      string
 	BuildPath string
+
+	// Estado de ejecución usado para detener y reiniciar el servicio en modo watch.
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
+// shutdownGracePeriod es el tiempo que se espera tras SIGTERM antes de forzar la terminación.
+const shutdownGracePeriod = 10 * time.Second
+
 func main() {
+	// Subcomandos
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		runSeed(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "snapshot" {
+		runSnapshot(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "skills-map" {
+		runSkillsMap(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "encrypt-fields" {
+		runEncryptFields(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "ws-protocol" {
+		runWSProtocol(os.Args[2:])
+		return
+	}
+
+	watch := flag.Bool("watch", false, "Recompila y reinicia los servicios afectados al detectar cambios en internal/, pkg/ y cmd/")
+	flag.Parse()
+
 	fmt.Printf("%s%s🚀 Backend Microservices Development Tool%s\n", Bold, Cyan, Reset)
 	fmt.Printf("%s================================%s\n\n", Cyan, Reset)
 
@@ -74,9 +106,6 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// WaitGroup para sincronizar goroutines
-	var wg sync.WaitGroup
-
 	// Compilar servicios
 	fmt.Printf("%s%s🔨 Compilando servicios...%s\n", Bold, Purple, Reset)
 	for i := range services {
@@ -89,11 +118,7 @@ func main() {
 	// Iniciar servicios
 	fmt.Printf("%s%s🚀 Iniciando servicios...%s\n", Bold, Cyan, Reset)
 	for i := range services {
-		wg.Add(1)
-		go func(service *Service) {
-			defer wg.Done()
-			runService(ctx, service)
-		}(&services[i])
+		startService(ctx, &services[i])
 		time.Sleep(500 * time.Millisecond) // Pequeña pausa entre inicios
 	}
 
@@ -105,6 +130,17 @@ func main() {
 	}
 	fmt.Printf("\n%s%s💡 Presiona Ctrl+C para detener todos los servicios%s\n\n", Bold, White, Reset)
 
+	var watcher *serviceWatcher
+	if *watch {
+		var err error
+		watcher, err = newServiceWatcher(ctx, services)
+		if err != nil {
+			log.Fatalf("Error iniciando el modo watch: %v", err)
+		}
+		go watcher.Run()
+		fmt.Printf("%s%s👀 Modo watch activo: observando %s%s\n\n", Bold, Cyan, strings.Join(watchedRoots, ", "), Reset)
+	}
+
 	// Esperar señal de terminación
 	<-sigChan
 	fmt.Printf("\n%s%s🛑 Deteniendo servicios...%s\n", Bold, Red, Reset)
@@ -112,19 +148,37 @@ func main() {
 	// Cancelar contexto para detener todos los servicios
 	cancel()
 
+	// El watcher reasigna cancel y done al reiniciar un servicio: se espera a que termine
+	// antes de leerlos.
+	if watcher != nil {
+		watcher.Stop()
+	}
+
 	// Esperar a que todos los servicios terminen
-	wg.Wait()
+	for i := range services {
+		if services[i].done != nil {
+			<-services[i].done
+		}
+	}
 
 	fmt.Printf("%s%s✅ Todos los servicios detenidos%s\n", Bold, Green, Reset)
 }
 
 // buildService compila un servicio
 func buildService(service *Service) bool {
+	return buildServiceTo(service, binaryPath(service))
+}
+
+// binaryPath devuelve la ruta del binario compilado de un servicio.
+func binaryPath(service *Service) string {
+	return fmt.Sprintf("./bin/%s", strings.ToLower(service.Name))
+}
+
+// buildServiceTo compila un servicio en la ruta indicada.
+func buildServiceTo(service *Service, outPath string) bool {
 	fmt.Printf("%s[BUILD]%s Compilando %s...\n", Purple, Reset, service.Name)
 
-	cmd := exec.Command("go", "build", "-o",
-		fmt.Sprintf("./bin/%s", strings.ToLower(service.Name)),
-		service.BuildPath)
+	cmd := exec.Command("go", "build", "-o", outPath, service.BuildPath)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -137,12 +191,36 @@ func buildService(service *Service) bool {
 	return true
 }
 
+// startService lanza el servicio en segundo plano con un contexto propio,
+// de modo que pueda detenerse individualmente con stopService.
+func startService(parent context.Context, service *Service) {
+	ctx, cancel := context.WithCancel(parent)
+	done := make(chan struct{})
+	service.cancel = cancel
+	service.done = done
+
+	go func() {
+		defer close(done)
+		runService(ctx, service)
+	}()
+}
+
+// stopService detiene el servicio y espera a que el proceso termine.
+func stopService(service *Service) {
+	if service.cancel == nil {
+		return
+	}
+	service.cancel()
+	<-service.done
+	service.cancel = nil
+}
+
 // runService ejecuta un servicio y captura sus logs
 func runService(ctx context.Context, service *Service) {
-	binaryPath := fmt.Sprintf("./bin/%s", strings.ToLower(service.Name))
-
-	// Crear comando con contexto
-	cmd := exec.CommandContext(ctx, binaryPath)
+	// Crear comando con contexto. Al cancelar se envía SIGTERM para permitir el cierre ordenado.
+	cmd := exec.CommandContext(ctx, binaryPath(service))
+	cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+	cmd.WaitDelay = shutdownGracePeriod
 	service.Cmd = cmd
 
 	// Configurar pipes para stdout y stderr
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/fieldcrypt"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/phonetic"
+	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// seedPassword es la contraseña de todos los usuarios generados por el seed.
+const seedPassword = "Seed1234!"
+
+// seedEmailDomain identifica los usuarios creados por el seed. Los correos son deterministas,
+// de modo que ejecutar el seed dos veces con los mismos parámetros no duplica datos.
+const seedEmailDomain = "seed.local"
+
+// seedOptions define cuántos registros de cada tipo genera el subcomando seed.
+type seedOptions struct {
+	seed            int64
+	students        int
+	companies       int
+	contactsPerUser int
+	messagesPerChat int
+	events          int
+	applications    int
+}
+
+// seeder mantiene el estado de una ejecución del seed.
+type seeder struct {
+	db           *sql.DB
+	rnd          *rand.Rand
+	opts         seedOptions
+	passwordHash string
+	now          time.Time
+
+	studentIDs []int64
+	companyIDs []int64
+	eventIDs   []int64
+}
+
+var (
+	seedFirstNames = []string{"María", "José", "Luis", "Ana", "Carlos", "Gabriela", "Andrés", "Valentina", "Daniel", "Sofía", "Miguel", "Camila", "Jorge", "Isabella", "Ricardo", "Lucía", "Fernando", "Paola", "Diego", "Andrea"}
+	seedLastNames  = []string{"González", "Rodríguez", "Pérez", "Hernández", "García", "Martínez", "López", "Díaz", "Romero", "Torres", "Rojas", "Castillo", "Suárez", "Mendoza", "Ramírez", "Vargas", "Silva", "Morales", "Guerrero", "Medina"}
+	seedCompanies  = []string{"Andes Software", "Caribe Data", "Orinoco Labs", "Tepuy Tech", "Ávila Systems", "Roraima Cloud", "Delta Analytics", "Llanos Digital", "Médanos Studio", "Catatumbo AI"}
+	seedSectors    = []string{"Tecnología", "Finanzas", "Salud", "Educación", "Energía", "Comercio"}
+	seedLocations  = []string{"Caracas", "Maracaibo", "Valencia", "Barquisimeto", "Mérida", "Puerto Ordaz"}
+	seedSkills     = []string{"Go", "Python", "JavaScript", "React", "SQL", "Docker", "Kubernetes", "Java", "TypeScript", "Figma", "Excel", "Power BI"}
+	seedLevels     = []string{"Básico", "Intermedio", "Avanzado"}
+	seedLanguages  = []string{"Español", "Inglés", "Portugués", "Francés", "Italiano"}
+	seedLangLevels = []string{"A2", "B1", "B2", "C1", "Nativo"}
+	seedPositions  = []string{"Desarrollador Backend", "Desarrolladora Frontend", "Analista de Datos", "Diseñador UX", "Ingeniera DevOps", "Pasante de QA"}
+	seedMessages   = []string{"¡Hola! ¿Cómo estás?", "¿Viste la nueva oferta publicada?", "Te envío el enlace del repositorio.", "Gracias por la recomendación.", "¿Nos reunimos mañana para revisar el proyecto?", "Perfecto, quedamos así.", "Ya subí los cambios.", "¿Tienes experiencia con Docker?"}
+	seedPostTypes  = []string{"EVENTO", "NOTICIA", "ARTICULO", "ANUNCIO", "DESAFIO", "DISCUSION"}
+	seedAppStatus  = []string{"ENVIADA", "EN_REVISION", "ENTREVISTA", "PRUEBA_TECNICA", "OFERTA_REALIZADA", "APROBADA", "RECHAZADA"}
+)
+
+// runSeed implementa el subcomando `devtools seed`.
+func runSeed(args []string) {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	opts := seedOptions{}
+	fs.Int64Var(&opts.seed, "seed", 42, "Semilla del generador aleatorio (mismos valores producen los mismos datos)")
+	fs.IntVar(&opts.students, "students", 50, "Número de estudiantes/egresados")
+	fs.IntVar(&opts.companies, "companies", 10, "Número de empresas")
+	fs.IntVar(&opts.contactsPerUser, "contacts", 5, "Contactos aceptados por estudiante")
+	fs.IntVar(&opts.messagesPerChat, "messages", 10, "Mensajes por chat")
+	fs.IntVar(&opts.events, "events", 30, "Número de publicaciones comunitarias")
+	fs.IntVar(&opts.applications, "applications", 3, "Postulaciones por estudiante")
+	fs.Parse(args)
+
+	fmt.Printf("%s%s🌱 Seed de la base de datos (semilla %d)%s\n", Bold, Green, opts.seed, Reset)
+
+	if err := godotenv.Load(); err != nil {
+		fmt.Printf("%s[SEED]%s No se pudo cargar .env, usando variables de entorno\n", Yellow, Reset)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Printf("%s[SEED]%s Error cargando configuración: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+	conn, err := db.Connect(cfg.DatabaseDSN, db.NewPoolConfig(cfg))
+	if err != nil {
+		fmt.Printf("%s[SEED]%s Error conectando a la base de datos: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+	if err := db.InitializeDatabase(conn); err != nil {
+		fmt.Printf("%s[SEED]%s Error inicializando la base de datos: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(seedPassword), bcrypt.DefaultCost)
+	if err != nil {
+		fmt.Printf("%s[SEED]%s Error generando hash de contraseña: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+
+	s := &seeder{
+		db:           conn,
+		rnd:          rand.New(rand.NewSource(opts.seed)),
+		opts:         opts,
+		passwordHash: string(hash),
+		// Fecha de referencia fija para que las fechas generadas sean reproducibles.
+		now: time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC),
+	}
+
+	steps := []struct {
+		name string
+		run  func() (int, error)
+	}{
+		{"Estudiantes", s.seedStudents},
+		{"Empresas", s.seedCompanies},
+		{"Contactos y mensajes", s.seedContactsAndMessages},
+		{"Publicaciones comunitarias", s.seedEvents},
+		{"Postulaciones", s.seedApplications},
+	}
+	for i, step := range steps {
+		// Cada paso usa su propia secuencia para que los datos no dependan de lo que ya existía.
+		s.rnd = rand.New(rand.NewSource(opts.seed + int64(i)))
+		count, err := step.run()
+		if err != nil {
+			fmt.Printf("%s[SEED]%s %s: %v\n", Red, Reset, step.name, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s[SEED]%s ✅ %s: %d registros\n", Green, Reset, step.name, count)
+	}
+
+	fmt.Printf("\n%s%s🌱 Seed completado. Contraseña de todos los usuarios: %s%s\n", Bold, Green, seedPassword, Reset)
+}
+
+func (s *seeder) pick(values []string) string {
+	return values[s.rnd.Intn(len(values))]
+}
+
+// upsertUser inserta el usuario si su correo no existe y devuelve su ID.
+func (s *seeder) upsertUser(query string, email string, args ...any) (int64, error) {
+	if _, err := s.db.Exec(query, args...); err != nil {
+		return 0, fmt.Errorf("insertando usuario %s: %w", email, err)
+	}
+	var id int64
+	if err := s.db.QueryRow("SELECT Id FROM User WHERE Email = ?", email).Scan(&id); err != nil {
+		return 0, fmt.Errorf("obteniendo ID de %s: %w", email, err)
+	}
+	// Chat consigo mismo, igual que en el registro normal.
+	if _, err := s.db.Exec("INSERT IGNORE INTO Contact (User1Id, User2Id, Status, ChatId) VALUES (?, ?, 'accepted', ?)", id, id, fmt.Sprintf("seed-self-%d", id)); err != nil {
+		return 0, fmt.Errorf("creando chat propio de %s: %w", email, err)
+	}
+	return id, nil
+}
+
+func (s *seeder) seedStudents() (int, error) {
+	query := `INSERT IGNORE INTO User (
+		FirstName, LastName, UserName, Password, Email, RoleId, StatusAuthorizedId, Sex, Birthdate,
+		Summary, Github, Linkedin, dmeta_person_primary, dmeta_person_secondary
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	for i := 0; i < s.opts.students; i++ {
+		first, last := s.pick(seedFirstNames), s.pick(seedLastNames)
+		userName := fmt.Sprintf("seed_student_%d", i)
+		email := fmt.Sprintf("student%d@%s", i, seedEmailDomain)
+		role := models.RoleStudent
+		if s.rnd.Intn(3) == 0 {
+			role = models.RoleEgresado
+		}
+		pKey, sKey, _ := phonetic.GenerateKeysForPhrase(first + " " + last)
+		birthdate := s.now.AddDate(-18-s.rnd.Intn(12), -s.rnd.Intn(12), 0)
+		sex := "M"
+		if s.rnd.Intn(2) == 0 {
+			sex = "F"
+		}
+
+		id, err := s.upsertUser(query, email,
+			first, last, userName, s.passwordHash, email, role, 1, sex, birthdate,
+			fmt.Sprintf("%s interesado en %s y %s.", s.pick(seedPositions), s.pick(seedSkills), s.pick(seedSkills)),
+			"https://github.com/"+userName, "https://linkedin.com/in/"+userName, pKey, sKey,
+		)
+		if err != nil {
+			return 0, err
+		}
+		s.studentIDs = append(s.studentIDs, id)
+
+		if err := s.seedCV(id, int64(i)); err != nil {
+			return 0, err
+		}
+	}
+	return len(s.studentIDs), nil
+}
+
+// seedCV crea educación, experiencia, habilidades e idiomas para un estudiante.
+// Solo se ejecuta si el usuario aún no tiene habilidades, para no duplicar el CV.
+// Usa un generador propio por estudiante para no alterar la secuencia del paso.
+func (s *seeder) seedCV(userID int64, index int64) error {
+	rnd := rand.New(rand.NewSource(s.opts.seed*7919 + index))
+	pick := func(values []string) string { return values[rnd.Intn(len(values))] }
+
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM Skills WHERE PersonId = ?", userID).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	graduation := s.now.AddDate(-rnd.Intn(4), 0, 0)
+	if _, err := s.db.Exec(
+		"INSERT INTO Education (PersonId, Institution, Degree, GraduationDate, IsCurrentlyStudying) VALUES (?, ?, ?, ?, ?)",
+		userID, "Universidad "+pick(seedLocations), "Ingeniería "+pick([]string{"Informática", "de Sistemas", "Industrial", "Electrónica"}), graduation, rnd.Intn(2) == 0,
+	); err != nil {
+		return fmt.Errorf("insertando educación: %w", err)
+	}
+
+	start := s.now.AddDate(-1-rnd.Intn(3), 0, 0)
+	if _, err := s.db.Exec(
+		"INSERT INTO WorkExperience (PersonId, Company, Position, StartDate, Description, IsCurrentJob) VALUES (?, ?, ?, ?, ?, ?)",
+		userID, pick(seedCompanies), pick(seedPositions), start, "Experiencia generada por el seed.", true,
+	); err != nil {
+		return fmt.Errorf("insertando experiencia: %w", err)
+	}
+
+	for _, idx := range rnd.Perm(len(seedSkills))[:3] {
+		if _, err := s.db.Exec("INSERT INTO Skills (PersonId, Skill, Level) VALUES (?, ?, ?)", userID, seedSkills[idx], pick(seedLevels)); err != nil {
+			return fmt.Errorf("insertando habilidad: %w", err)
+		}
+	}
+	for _, idx := range rnd.Perm(len(seedLanguages))[:2] {
+		if _, err := s.db.Exec("INSERT INTO Languages (PersonId, Language, Level) VALUES (?, ?, ?)", userID, seedLanguages[idx], pick(seedLangLevels)); err != nil {
+			return fmt.Errorf("insertando idioma: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *seeder) seedCompanies() (int, error) {
+	query := `INSERT IGNORE INTO User (
+		CompanyName, RIF, RIFHash, Sector, FirstName, Email, Phone, Password, Location, RoleId, StatusAuthorizedId, UserName,
+		Summary, FoundationYear, EmployeeCount, dmeta_company_primary, dmeta_company_secondary
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	for i := 0; i < s.opts.companies; i++ {
+		name := seedCompanies[i%len(seedCompanies)]
+		if i >= len(seedCompanies) {
+			name = fmt.Sprintf("%s %d", name, i/len(seedCompanies)+1)
+		}
+		rif := fmt.Sprintf("J-%08d-%d", 40000000+i, i%10)
+		email := fmt.Sprintf("company%d@%s", i, seedEmailDomain)
+		pKey, sKey, _ := phonetic.GenerateKeysForPhrase(name)
+
+		id, err := s.upsertUser(query, email,
+			name, fieldcrypt.Value(rif), fieldcrypt.Index(queries.FieldRIF, rif), s.pick(seedSectors), s.pick(seedFirstNames), email,
+			fieldcrypt.Value(fmt.Sprintf("+58-412-%07d", s.rnd.Intn(10000000))),
+			s.passwordHash, s.pick(seedLocations), models.RoleBusiness, 1, rif,
+			fmt.Sprintf("%s es una empresa del sector %s.", name, s.pick(seedSectors)), 1990+s.rnd.Intn(34), 10+s.rnd.Intn(500), pKey, sKey,
+		)
+		if err != nil {
+			return 0, err
+		}
+		s.companyIDs = append(s.companyIDs, id)
+	}
+	return len(s.companyIDs), nil
+}
+
+// seedContactsAndMessages conecta cada estudiante con otros usuarios y genera un historial de chat.
+func (s *seeder) seedContactsAndMessages() (int, error) {
+	all := append(append([]int64{}, s.studentIDs...), s.companyIDs...)
+	if len(all) < 2 {
+		return 0, nil
+	}
+
+	created := 0
+	for _, userID := range s.studentIDs {
+		for c := 0; c < s.opts.contactsPerUser; c++ {
+			otherID := all[s.rnd.Intn(len(all))]
+			if otherID == userID {
+				continue
+			}
+			// Orden canónico para que el ChatId sea único por par de usuarios.
+			u1, u2 := userID, otherID
+			if u2 < u1 {
+				u1, u2 = u2, u1
+			}
+			chatID := fmt.Sprintf("seed-chat-%d-%d", u1, u2)
+			res, err := s.db.Exec("INSERT IGNORE INTO Contact (User1Id, User2Id, Status, ChatId) VALUES (?, ?, 'accepted', ?)", u1, u2, chatID)
+			if err != nil {
+				return 0, fmt.Errorf("insertando contacto: %w", err)
+			}
+			if rows, _ := res.RowsAffected(); rows == 0 {
+				continue // El contacto ya existía
+			}
+			created++
+
+			// Generador propio por chat: el historial no depende de qué contactos ya existían.
+			rnd := rand.New(rand.NewSource(s.opts.seed ^ (u1<<20 + u2)))
+			sentAt := s.now.Add(-time.Duration(rnd.Intn(30*24)) * time.Hour)
+			for m := 0; m < s.opts.messagesPerChat; m++ {
+				sender := u1
+				if rnd.Intn(2) == 0 {
+					sender = u2
+				}
+				sentAt = sentAt.Add(time.Duration(1+rnd.Intn(120)) * time.Minute)
+				if _, err := s.db.Exec(
+					"INSERT IGNORE INTO Message (Id, ChatId, SenderId, TypeMessageId, Content, SentAt, Status) VALUES (?, ?, ?, 1, ?, ?, 'read')",
+					fmt.Sprintf("%s-%d", chatID, m), chatID, sender, seedMessages[rnd.Intn(len(seedMessages))], sentAt,
+				); err != nil {
+					return 0, fmt.Errorf("insertando mensaje: %w", err)
+				}
+			}
+		}
+	}
+	return created, nil
+}
+
+// seedEvents crea publicaciones comunitarias de empresas. Se identifican por título y autor
+// para no duplicarlas en ejecuciones posteriores.
+func (s *seeder) seedEvents() (int, error) {
+	if len(s.companyIDs) == 0 {
+		return 0, nil
+	}
+
+	for i := 0; i < s.opts.events; i++ {
+		companyIdx := i % len(s.companyIDs)
+		companyID := s.companyIDs[companyIdx]
+		postType := s.pick(seedPostTypes)
+		title := fmt.Sprintf("[Seed %d] %s: %s", i, postType, s.pick(seedPositions))
+		tags, _ := json.Marshal([]string{s.pick(seedSkills), s.pick(seedSkills)})
+		eventDate := s.now.AddDate(0, 0, s.rnd.Intn(60)-15)
+		pKey, sKey, _ := phonetic.GenerateKeysForPhrase(title)
+
+		var id int64
+		err := s.db.QueryRow("SELECT Id FROM CommunityEvent WHERE Title = ? AND CreatedByUserId = ?", title, companyID).Scan(&id)
+		if err == sql.ErrNoRows {
+			res, errInsert := s.db.Exec(
+				`INSERT INTO CommunityEvent (PostType, Title, Description, EventDate, Location, Capacity, Tags,
+					OrganizerCompanyName, OrganizerUserId, CreatedByUserId, dmeta_title_primary, dmeta_title_secondary)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				postType, title, "Publicación generada por el seed para pruebas de feed y búsqueda.", eventDate,
+				s.pick(seedLocations), 20+s.rnd.Intn(200), string(tags),
+				seedCompanies[companyIdx%len(seedCompanies)], companyID, companyID, pKey, sKey,
+			)
+			if errInsert != nil {
+				return 0, fmt.Errorf("insertando publicación: %w", errInsert)
+			}
+			id, _ = res.LastInsertId()
+		} else if err != nil {
+			return 0, fmt.Errorf("buscando publicación existente: %w", err)
+		}
+		s.eventIDs = append(s.eventIDs, id)
+	}
+	return len(s.eventIDs), nil
+}
+
+func (s *seeder) seedApplications() (int, error) {
+	if len(s.eventIDs) == 0 {
+		return 0, nil
+	}
+
+	created := 0
+	for _, studentID := range s.studentIDs {
+		for a := 0; a < s.opts.applications; a++ {
+			eventID := s.eventIDs[s.rnd.Intn(len(s.eventIDs))]
+			res, err := s.db.Exec(
+				"INSERT IGNORE INTO JobApplication (CommunityEventId, ApplicantId, Status, CoverLetter) VALUES (?, ?, ?, ?)",
+				eventID, studentID, s.pick(seedAppStatus), "Postulación generada por el seed.",
+			)
+			if err != nil {
+				return 0, fmt.Errorf("insertando postulación: %w", err)
+			}
+			if rows, _ := res.RowsAffected(); rows > 0 {
+				created++
+			}
+		}
+	}
+	return created, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/joho/godotenv"
+)
+
+// runSkillsMap implementa el subcomando `devtools skills-map`: asocia las habilidades
+// existentes (Skills) con el catálogo de habilidades canónicas y elimina las repetidas de un
+// mismo usuario. Se puede ejecutar varias veces; cada ejecución solo revisa las filas aún sin
+// asociar.
+func runSkillsMap(args []string) {
+	fs := flag.NewFlagSet("skills-map", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Calcula el informe sin modificar la base de datos")
+	unmapped := fs.Int("unmapped", 20, "Número de textos sin asociar más frecuentes que se muestran al final")
+	fs.Parse(args)
+
+	fmt.Printf("%s%s🧩 Asociación de habilidades con el catálogo%s\n", Bold, Cyan, Reset)
+
+	if err := godotenv.Load(); err != nil {
+		fmt.Printf("%s[SKILLS]%s No se pudo cargar .env, usando variables de entorno\n", Yellow, Reset)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Printf("%s[SKILLS]%s Error cargando configuración: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+	conn, err := db.Connect(cfg.DatabaseDSN, db.NewPoolConfig(cfg))
+	if err != nil {
+		fmt.Printf("%s[SKILLS]%s Error conectando a la base de datos: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+	// Crea el catálogo y la columna Skills.SkillCatalogId si la base de datos es anterior.
+	if err := db.InitializeDatabase(conn); err != nil {
+		fmt.Printf("%s[SKILLS]%s Error inicializando la base de datos: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+	queries.InitDB(conn)
+
+	service := services.NewSkillService(conn)
+	report, err := service.MapExistingSkills(*dryRun)
+	if err != nil {
+		fmt.Printf("%s[SKILLS]%s Error asociando habilidades: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s[SKILLS]%s Revisadas: %d\n", Green, Reset, report.Scanned)
+	fmt.Printf("%s[SKILLS]%s Asociadas: %d\n", Green, Reset, report.Mapped)
+	kinds := make([]string, 0, len(report.ByKind))
+	for kind := range report.ByKind {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Printf("           %-8s %d\n", kind, report.ByKind[kind])
+	}
+	fmt.Printf("%s[SKILLS]%s Duplicadas eliminadas: %d\n", Green, Reset, report.Merged)
+	fmt.Printf("%s[SKILLS]%s Sin asociar: %d\n", Yellow, Reset, report.Unmapped)
+
+	if *unmapped > 0 && report.Unmapped > 0 {
+		top, err := service.GetUnmapped(*unmapped)
+		if err != nil {
+			fmt.Printf("%s[SKILLS]%s Error obteniendo las habilidades sin asociar: %v\n", Red, Reset, err)
+			os.Exit(1)
+		}
+		fmt.Printf("\n%sTextos sin asociar más usados (añádelos como habilidad o alias):%s\n", Bold, Reset)
+		for _, skill := range top {
+			fmt.Printf("  %4d  %s\n", skill.Count, skill.Skill)
+		}
+	}
+
+	if *dryRun {
+		fmt.Printf("\n%s%s🧩 Simulación: no se modificó la base de datos%s\n", Bold, Yellow, Reset)
+	}
+}
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/phonetic"
+	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// snapshotPassword es la contraseña de todos los usuarios de un snapshot: la real no sale de la
+// base de datos de origen.
+const snapshotPassword = "Snapshot1234!"
+
+// snapshotEmailDomain es el dominio de los correos de un snapshot (user<Id>@snapshot.local).
+const snapshotEmailDomain = "snapshot.local"
+
+// defaultSnapshotPath es el archivo que usan dump y restore si no se indica otro.
+const defaultSnapshotPath = "snapshots/dev.sql.gz"
+
+// snapshotBatchSize es el número de filas de cada INSERT del snapshot.
+const snapshotBatchSize = 200
+
+// snapshotSkippedTables no se copian al snapshot: sesiones, credenciales, registros y trabajos
+// pendientes que no sirven en otra base de datos o tienen datos personales. Al restaurar se
+// vacían igual que las demás.
+var snapshotSkippedTables = map[string]bool{
+	"Session":             true,
+	"PasswordReset":       true,
+	"PushDevice":          true,
+	"WebPushSubscription": true,
+	"Impersonation":       true,
+	"Online":              true,
+	"AuditLog":            true,
+	"PrivacyRequest":      true,
+	"ChatExport":          true,
+	"UploadSession":       true,
+	"UploadQuarantine":    true,
+	"Outbox":              true,
+	"DeadLetter":          true,
+	"EmailDigestItem":     true,
+	"ReportContextBundle": true,
+	"ContentFilterHit":    true,
+}
+
+// snapshotRow es una fila de la tabla que se está copiando, por nombre de columna.
+type snapshotRow map[string]interface{}
+
+// id devuelve la columna como entero, o 0 si es NULL.
+func (r snapshotRow) id(column string) int64 {
+	switch v := r[column].(type) {
+	case int64:
+		return v
+	case []byte:
+		n, _ := strconv.ParseInt(string(v), 10, 64)
+		return n
+	}
+	return 0
+}
+
+// columnRule devuelve el valor con el que se guarda una columna en el snapshot.
+type columnRule func(row snapshotRow) interface{}
+
+// nullColumn borra la columna.
+func nullColumn(snapshotRow) interface{} { return nil }
+
+// snapshotRules son los datos personales que se reemplazan al copiar cada tabla. Los nombres se
+// toman de las listas del seed a partir del Id, de modo que dos snapshots de la misma base de
+// datos coinciden.
+func snapshotRules(passwordHash string) map[string]map[string]columnRule {
+	firstName := func(r snapshotRow) interface{} {
+		return seedFirstNames[int(r.id("Id"))%len(seedFirstNames)]
+	}
+	lastName := func(r snapshotRow) interface{} {
+		return seedLastNames[int(r.id("Id")/int64(len(seedFirstNames)))%len(seedLastNames)]
+	}
+	personKeys := func(r snapshotRow) (string, string) {
+		primary, secondary, _ := phonetic.GenerateKeysForPhrase(firstName(r).(string) + " " + lastName(r).(string))
+		return primary, secondary
+	}
+	messageContent := func(r snapshotRow) interface{} {
+		if r["Content"] == nil {
+			return nil
+		}
+		return seedMessages[int(r.id("Id"))%len(seedMessages)]
+	}
+
+	return map[string]map[string]columnRule{
+		"User": {
+			"FirstName": firstName,
+			"LastName":  lastName,
+			"UserName":  func(r snapshotRow) interface{} { return fmt.Sprintf("user%d", r.id("Id")) },
+			"Email": func(r snapshotRow) interface{} {
+				return fmt.Sprintf("user%d@%s", r.id("Id"), snapshotEmailDomain)
+			},
+			"Password":               func(snapshotRow) interface{} { return passwordHash },
+			"ContactEmail":           nullColumn,
+			"Twitter":                nullColumn,
+			"Facebook":               nullColumn,
+			"Phone":                  nullColumn,
+			"DocId":                  nullColumn,
+			"DocIdHash":              nullColumn,
+			"Birthdate":              nullColumn,
+			"Picture":                nullColumn,
+			"Address":                nullColumn,
+			"Github":                 nullColumn,
+			"Linkedin":               nullColumn,
+			"RIF":                    nullColumn,
+			"RIFHash":                nullColumn,
+			"dmeta_person_primary":   func(r snapshotRow) interface{} { p, _ := personKeys(r); return p },
+			"dmeta_person_secondary": func(r snapshotRow) interface{} { _, s := personKeys(r); return s },
+		},
+		"StudentVerification": {
+			"Email": func(r snapshotRow) interface{} {
+				if r["Email"] == nil {
+					return nil
+				}
+				return fmt.Sprintf("user%d@%s", r.id("UserId"), snapshotEmailDomain)
+			},
+			"PendingEmail":        nullColumn,
+			"PendingUniversityId": nullColumn,
+			"CodeHash":            nullColumn,
+			"CodeSentAt":          nullColumn,
+			"CodeExpiresAt":       nullColumn,
+			"Attempts":            func(snapshotRow) interface{} { return int64(0) },
+		},
+		// Las conversaciones son privadas: se conserva su forma (quién, cuándo, respuestas) y
+		// se cambia el texto.
+		"Message":        {"Content": messageContent},
+		"MessageArchive": {"Content": messageContent},
+		"Report": {
+			"ContentSnapshot": func(r snapshotRow) interface{} {
+				if r["ContentSnapshot"] == nil {
+					return nil
+				}
+				return "[contenido omitido en el snapshot]"
+			},
+		},
+	}
+}
+
+// runSnapshot implementa el subcomando `devtools snapshot dump|restore`.
+func runSnapshot(args []string) {
+	if len(args) == 0 || (args[0] != "dump" && args[0] != "restore") {
+		fmt.Printf("%s[SNAPSHOT]%s Uso: devtools snapshot dump|restore [-file %s]\n", Red, Reset, defaultSnapshotPath)
+		os.Exit(2)
+	}
+	fs := flag.NewFlagSet("snapshot "+args[0], flag.ExitOnError)
+	path := fs.String("file", defaultSnapshotPath, "Archivo del snapshot (SQL comprimido con gzip)")
+	fs.Parse(args[1:])
+
+	if err := godotenv.Load(); err != nil {
+		fmt.Printf("%s[SNAPSHOT]%s No se pudo cargar .env, usando variables de entorno\n", Yellow, Reset)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Printf("%s[SNAPSHOT]%s Error cargando configuración: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+	if args[0] == "restore" && cfg.Environment == "production" {
+		fmt.Printf("%s[SNAPSHOT]%s restore reemplaza todos los datos: no se permite con ENVIRONMENT=production\n", Red, Reset)
+		os.Exit(1)
+	}
+	conn, err := db.Connect(cfg.DatabaseDSN, db.NewPoolConfig(cfg))
+	if err != nil {
+		fmt.Printf("%s[SNAPSHOT]%s Error conectando a la base de datos: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+	// El esquema lo crea la aplicación: así un snapshot antiguo se restaura sobre las columnas
+	// actuales y las que no tenía quedan con su valor por defecto.
+	if err := db.InitializeDatabase(conn); err != nil {
+		fmt.Printf("%s[SNAPSHOT]%s Error inicializando la base de datos: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+
+	start := time.Now()
+	ctx := context.Background()
+	if args[0] == "dump" {
+		err = dumpSnapshot(ctx, conn, *path)
+	} else {
+		err = restoreSnapshot(ctx, conn, *path)
+	}
+	if err != nil {
+		fmt.Printf("%s[SNAPSHOT]%s %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+	fmt.Printf("\n%s%s📦 Snapshot %s en %s (%s). Contraseña de todos los usuarios: %s%s\n",
+		Bold, Green, args[0], *path, time.Since(start).Round(time.Millisecond), snapshotPassword, Reset)
+}
+
+// snapshotColumn es una columna que se copia al snapshot.
+type snapshotColumn struct {
+	name   string
+	binary bool // se escribe en hexadecimal
+}
+
+// dumpSnapshot escribe en path un script que vacía cada tabla y vuelve a insertar sus filas,
+// con los datos personales reemplazados según snapshotRules. Una sentencia por línea.
+func dumpSnapshot(ctx context.Context, conn *sql.DB, path string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(snapshotPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("generando hash de contraseña: %w", err)
+	}
+	rules := snapshotRules(string(hash))
+
+	tables, err := snapshotTables(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	// Se escribe en un temporal para no dejar un snapshot a medias si algo falla.
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	out := bufio.NewWriter(gz)
+
+	fmt.Fprintf(out, "-- Snapshot generado con `devtools snapshot dump` el %s. Restaurar con `devtools snapshot restore`.\n",
+		time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(out, "-- Datos personales reemplazados. Contraseña de todos los usuarios: %s\n", snapshotPassword)
+	for _, table := range tables {
+		fmt.Fprintf(out, "TRUNCATE TABLE `%s`\n", table)
+		if snapshotSkippedTables[table] {
+			fmt.Printf("%s[SNAPSHOT]%s ⏭️  %s: omitida\n", Yellow, Reset, table)
+			continue
+		}
+		count, err := dumpTable(ctx, conn, out, table, rules[table])
+		if err != nil {
+			return fmt.Errorf("copiando %s: %w", table, err)
+		}
+		fmt.Printf("%s[SNAPSHOT]%s ✅ %s: %d filas\n", Green, Reset, table, count)
+	}
+
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// snapshotTables devuelve las tablas de la base de datos actual.
+func snapshotTables(ctx context.Context, conn *sql.DB) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, `
+		SELECT TABLE_NAME FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE'
+		ORDER BY TABLE_NAME`)
+	if err != nil {
+		return nil, fmt.Errorf("listando tablas: %w", err)
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// snapshotColumns devuelve las columnas de table que se pueden insertar (no las generadas).
+func snapshotColumns(ctx context.Context, conn *sql.DB, table string) ([]snapshotColumn, error) {
+	rows, err := conn.QueryContext(ctx, `
+		SELECT COLUMN_NAME, DATA_TYPE FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND EXTRA NOT LIKE '%GENERATED%'
+		ORDER BY ORDINAL_POSITION`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []snapshotColumn
+	for rows.Next() {
+		var name, dataType string
+		if err := rows.Scan(&name, &dataType); err != nil {
+			return nil, err
+		}
+		dataType = strings.ToLower(dataType)
+		columns = append(columns, snapshotColumn{
+			name:   name,
+			binary: strings.Contains(dataType, "blob") || strings.Contains(dataType, "binary"),
+		})
+	}
+	return columns, rows.Err()
+}
+
+// dumpTable escribe las filas de table en INSERTs de snapshotBatchSize filas.
+func dumpTable(ctx context.Context, conn *sql.DB, out io.Writer, table string, rules map[string]columnRule) (int, error) {
+	columns, err := snapshotColumns(ctx, conn, table)
+	if err != nil {
+		return 0, err
+	}
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = "`" + column.name + "`"
+	}
+	list := strings.Join(names, ", ")
+
+	rows, err := conn.QueryContext(ctx, "SELECT "+list+" FROM `"+table+"`")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	count := 0
+	batch := make([]string, 0, snapshotBatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			fmt.Fprintf(out, "INSERT INTO `%s` (%s) VALUES %s\n", table, list, strings.Join(batch, ", "))
+			batch = batch[:0]
+		}
+	}
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return count, err
+		}
+		row := make(snapshotRow, len(columns))
+		for i, column := range columns {
+			row[column.name] = values[i]
+		}
+		literals := make([]string, len(columns))
+		for i, column := range columns {
+			value := row[column.name]
+			if rule, ok := rules[column.name]; ok {
+				value = rule(row)
+			}
+			literals[i] = sqlLiteral(value, column.binary)
+		}
+		batch = append(batch, "("+strings.Join(literals, ", ")+")")
+		count++
+		if len(batch) == snapshotBatchSize {
+			flush()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	flush()
+	return count, nil
+}
+
+// sqlStringEscaper escapa un texto para un literal entre comillas simples de MySQL. Los saltos
+// de línea se escapan para que cada sentencia del snapshot ocupe una línea.
+var sqlStringEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`'`, `\'`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\x00", `\0`,
+	"\x1a", `\Z`,
+)
+
+// sqlLiteral convierte un valor leído de la base de datos en un literal SQL.
+func sqlLiteral(value interface{}, binary bool) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		return "'" + v.UTC().Format("2006-01-02 15:04:05.999999") + "'"
+	case []byte:
+		if binary {
+			return "X'" + hex.EncodeToString(v) + "'"
+		}
+		return "'" + sqlStringEscaper.Replace(string(v)) + "'"
+	case string:
+		return "'" + sqlStringEscaper.Replace(v) + "'"
+	default:
+		return "'" + sqlStringEscaper.Replace(fmt.Sprint(v)) + "'"
+	}
+}
+
+// restoreSnapshot ejecuta el script de path. Las claves foráneas se desactivan en la conexión
+// mientras tanto, porque las tablas se vacían y rellenan en orden alfabético.
+func restoreSnapshot(ctx context.Context, conn *sql.DB, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("%s no es un snapshot válido: %w", path, err)
+	}
+	defer gz.Close()
+
+	// Las variables de sesión solo valen en una conexión: todo el script usa la misma.
+	session, err := conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	if _, err := session.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		return err
+	}
+	defer session.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1")
+
+	in := bufio.NewReader(gz)
+	tables, rows := 0, 0
+	for {
+		line, err := in.ReadString('\n')
+		if stmt := strings.TrimSpace(line); stmt != "" && !strings.HasPrefix(stmt, "--") {
+			if _, execErr := session.ExecContext(ctx, stmt); execErr != nil {
+				return fmt.Errorf("error ejecutando %.80q: %w", stmt, execErr)
+			}
+			switch {
+			case strings.HasPrefix(stmt, "TRUNCATE"):
+				tables++
+			case strings.HasPrefix(stmt, "INSERT"):
+				rows++
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Printf("%s[SNAPSHOT]%s ✅ %d tablas restauradas (%d INSERT)\n", Green, Reset, tables, rows)
+	return nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchedRoots son los directorios observados en modo watch.
+var watchedRoots = []string{"internal", "pkg", "cmd"}
+
+// debounceDelay agrupa los eventos de guardado de un editor en un único rebuild.
+const debounceDelay = 400 * time.Millisecond
+
+// serviceWatcher observa el código fuente y reinicia solo los servicios que dependen
+// de los paquetes modificados.
+type serviceWatcher struct {
+	ctx        context.Context
+	watcher    *fsnotify.Watcher
+	services   []Service
+	modulePath string
+	// deps contiene, por servicio, el conjunto de paquetes del módulo de los que depende.
+	deps map[string]map[string]bool
+	// stopped se cierra cuando Run termina: desde entonces el watcher ya no reinicia servicios.
+	stopped chan struct{}
+}
+
+// newServiceWatcher crea el watcher y registra recursivamente los directorios observados.
+func newServiceWatcher(ctx context.Context, services []Service) (*serviceWatcher, error) {
+	modulePath, err := goListModule()
+	if err != nil {
+		return nil, err
+	}
+
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo crear el watcher: %w", err)
+	}
+
+	w := &serviceWatcher{
+		ctx:        ctx,
+		watcher:    fw,
+		services:   services,
+		modulePath: modulePath,
+		deps:       make(map[string]map[string]bool),
+		stopped:    make(chan struct{}),
+	}
+
+	for _, root := range watchedRoots {
+		if err := w.addRecursive(root); err != nil {
+			fw.Close()
+			return nil, err
+		}
+	}
+	for i := range services {
+		w.refreshDeps(&services[i])
+	}
+	return w, nil
+}
+
+// Close libera el watcher.
+func (w *serviceWatcher) Close() error {
+	return w.watcher.Close()
+}
+
+// Stop cierra el watcher y espera a que Run termine, incluido un reinicio en curso. Después
+// los campos cancel y done de los servicios ya no cambian y se pueden leer sin carreras.
+func (w *serviceWatcher) Stop() {
+	w.Close()
+	<-w.stopped
+}
+
+// Run procesa los eventos del sistema de archivos hasta que se cancele el contexto.
+func (w *serviceWatcher) Run() {
+	defer close(w.stopped)
+	pending := make(map[string]bool)
+	timer := time.NewTimer(debounceDelay)
+	timer.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					w.addRecursive(event.Name)
+					continue
+				}
+			}
+			if !strings.HasSuffix(event.Name, ".go") || event.Has(fsnotify.Chmod) {
+				continue
+			}
+			pending[event.Name] = true
+			timer.Reset(debounceDelay)
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Printf("%s[WATCH]%s Error del watcher: %v\n", Red, Reset, err)
+
+		case <-timer.C:
+			w.reload(pending)
+			pending = make(map[string]bool)
+		}
+	}
+}
+
+// reload recompila y reinicia los servicios afectados por los archivos modificados.
+func (w *serviceWatcher) reload(changed map[string]bool) {
+	changedPkgs := make(map[string]bool)
+	var files []string
+	for file := range changed {
+		files = append(files, file)
+		changedPkgs[w.importPathFor(filepath.Dir(file))] = true
+	}
+	sort.Strings(files)
+
+	for i := range w.services {
+		service := &w.services[i]
+		if !w.isAffected(service, changedPkgs) {
+			continue
+		}
+
+		printRestartBanner(service, files)
+
+		// Se compila a un binario temporal para no detener el servicio si la compilación falla.
+		next := binaryPath(service) + ".next"
+		if !buildServiceTo(service, next) {
+			fmt.Printf("%s[WATCH]%s %s sigue ejecutando la versión anterior\n", Yellow, Reset, service.Name)
+			os.Remove(next)
+			continue
+		}
+
+		stopService(service)
+		if err := os.Rename(next, binaryPath(service)); err != nil {
+			fmt.Printf("%s[WATCH]%s No se pudo reemplazar el binario de %s: %v\n", Red, Reset, service.Name, err)
+		}
+		if w.ctx.Err() != nil {
+			return
+		}
+		startService(w.ctx, service)
+		w.refreshDeps(service)
+	}
+}
+
+// isAffected indica si alguno de los paquetes modificados forma parte del grafo del servicio.
+func (w *serviceWatcher) isAffected(service *Service, changedPkgs map[string]bool) bool {
+	deps, ok := w.deps[service.Name]
+	if !ok {
+		// Sin información de dependencias, se reinicia por seguridad.
+		return true
+	}
+	for pkg := range changedPkgs {
+		if deps[pkg] {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshDeps recalcula los paquetes de los que depende un servicio (los imports pueden haber cambiado).
+func (w *serviceWatcher) refreshDeps(service *Service) {
+	out, err := exec.Command("go", "list", "-deps", "-f", "{{.ImportPath}}", "./"+service.Path).Output()
+	if err != nil {
+		fmt.Printf("%s[WATCH]%s No se pudieron calcular las dependencias de %s: %v\n", Yellow, Reset, service.Name, err)
+		delete(w.deps, service.Name)
+		return
+	}
+
+	deps := make(map[string]bool)
+	for _, pkg := range strings.Fields(string(out)) {
+		if strings.HasPrefix(pkg, w.modulePath) {
+			deps[pkg] = true
+		}
+	}
+	w.deps[service.Name] = deps
+}
+
+// importPathFor convierte un directorio relativo del módulo en su ruta de importación.
+func (w *serviceWatcher) importPathFor(dir string) string {
+	return w.modulePath + "/" + filepath.ToSlash(filepath.Clean(dir))
+}
+
+// addRecursive observa un directorio y todos sus subdirectorios.
+func (w *serviceWatcher) addRecursive(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := w.watcher.Add(path); err != nil {
+			return fmt.Errorf("no se pudo observar %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// goListModule obtiene la ruta del módulo actual.
+func goListModule() (string, error) {
+	out, err := exec.Command("go", "list", "-m").Output()
+	if err != nil {
+		return "", fmt.Errorf("no se pudo obtener la ruta del módulo: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// printRestartBanner muestra un banner coloreado con el servicio y los archivos modificados.
+func printRestartBanner(service *Service, files []string) {
+	line := strings.Repeat("━", 50)
+	fmt.Printf("\n%s%s%s%s\n", Bold, service.Color, line, Reset)
+	fmt.Printf("%s%s🔄 Reiniciando %s%s\n", Bold, service.Color, service.Name, Reset)
+	for _, file := range files {
+		fmt.Printf("%s   • %s%s\n", service.Color, file, Reset)
+	}
+	fmt.Printf("%s%s%s%s\n\n", Bold, service.Color, line, Reset)
+}
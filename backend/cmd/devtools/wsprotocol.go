@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/protocol"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/openapi"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/tsgen"
+)
+
+// runWSProtocol implementa el subcomando `devtools ws-protocol`: genera el JSON Schema del
+// protocolo WebSocket y el cliente TypeScript del frontend a partir del registro de
+// internal/websocket/protocol. Con -check no escribe nada y termina con error si algún
+// fichero no coincide con lo generado.
+func runWSProtocol(args []string) {
+	fs := flag.NewFlagSet("ws-protocol", flag.ExitOnError)
+	schemaPath := fs.String("schema", "docs/ws_protocol.schema.json", "Ruta del JSON Schema del protocolo")
+	tsPath := fs.String("ts", "../frontend/src/types/wsProtocol.gen.ts", "Ruta del cliente TypeScript")
+	check := fs.Bool("check", false, "Comprueba que los ficheros están actualizados sin escribirlos")
+	fs.Parse(args)
+
+	schema, err := wsProtocolSchema()
+	if err != nil {
+		fmt.Printf("%s[WS-PROTOCOL]%s Error generando el JSON Schema: %v\n", Red, Reset, err)
+		os.Exit(1)
+	}
+	outputs := []struct {
+		path    string
+		content []byte
+	}{
+		{*schemaPath, schema},
+		{*tsPath, []byte(wsProtocolTypeScript())},
+	}
+
+	outdated := false
+	for _, out := range outputs {
+		if *check {
+			current, err := os.ReadFile(out.path)
+			if err != nil || !bytes.Equal(current, out.content) {
+				fmt.Printf("%s[WS-PROTOCOL]%s %s está desactualizado\n", Red, Reset, out.path)
+				outdated = true
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(out.path), 0o755); err != nil {
+			fmt.Printf("%s[WS-PROTOCOL]%s Error creando el directorio de %s: %v\n", Red, Reset, out.path, err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(out.path, out.content, 0o644); err != nil {
+			fmt.Printf("%s[WS-PROTOCOL]%s Error escribiendo %s: %v\n", Red, Reset, out.path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s[WS-PROTOCOL]%s Generado %s\n", Green, Reset, out.path)
+	}
+	if outdated {
+		fmt.Printf("%s[WS-PROTOCOL]%s Ejecuta `go run ./cmd/devtools ws-protocol` y añade los cambios\n", Yellow, Reset)
+		os.Exit(1)
+	}
+	if *check {
+		fmt.Printf("%s[WS-PROTOCOL]%s Los ficheros generados están actualizados\n", Green, Reset)
+	}
+}
+
+// wsMessageSchema es la entrada de un mensaje en el JSON Schema del protocolo.
+type wsMessageSchema struct {
+	Type        string          `json:"type"`
+	Description string          `json:"description"`
+	Payload     *openapi.Schema `json:"payload,omitempty"`
+}
+
+// wsDataRequestSchema es la entrada de una acción de data_request.
+type wsDataRequestSchema struct {
+	Resource    string          `json:"resource"`
+	Action      string          `json:"action"`
+	Description string          `json:"description"`
+	Data        *openapi.Schema `json:"data,omitempty"`
+}
+
+// wsCloseCodeSchema es la entrada de un código de cierre.
+type wsCloseCodeSchema struct {
+	Code        int    `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// wsProtocolSchema genera el documento con los mensajes del protocolo. Los esquemas de los
+// payloads son JSON Schema y comparten las definiciones de "definitions".
+func wsProtocolSchema() ([]byte, error) {
+	set := openapi.NewSchemaSet("#/definitions/")
+	doc := struct {
+		Schema         string                     `json:"$schema"`
+		Title          string                     `json:"title"`
+		Description    string                     `json:"description"`
+		ClientEnvelope *openapi.Schema            `json:"clientEnvelope"`
+		ServerEnvelope *openapi.Schema            `json:"serverEnvelope"`
+		ClientMessages []wsMessageSchema          `json:"clientMessages"`
+		DataRequests   []wsDataRequestSchema      `json:"dataRequests"`
+		ServerMessages []wsMessageSchema          `json:"serverMessages"`
+		CloseReason    *openapi.Schema            `json:"closeReason"`
+		CloseCodes     []wsCloseCodeSchema        `json:"closeCodes"`
+		Definitions    map[string]*openapi.Schema `json:"definitions"`
+	}{
+		Schema:         "http://json-schema.org/draft-07/schema#",
+		Title:          "Protocolo WebSocket",
+		Description:    "Generado con `go run ./cmd/devtools ws-protocol` a partir de internal/websocket/protocol. No editar a mano.",
+		ClientEnvelope: set.Schema(types.ClientToServerMessage{}),
+		ServerEnvelope: set.Schema(types.ServerToClientMessage{}),
+		CloseReason:    set.Schema(types.CloseReason{}),
+	}
+	for _, msg := range protocol.ClientMessages {
+		doc.ClientMessages = append(doc.ClientMessages, wsMessageSchema{string(msg.Type), msg.Description, payloadSchema(set, msg.Payload)})
+	}
+	for _, dr := range protocol.DataRequests {
+		doc.DataRequests = append(doc.DataRequests, wsDataRequestSchema{dr.Resource, dr.Action, dr.Description, payloadSchema(set, dr.Data)})
+	}
+	for _, msg := range protocol.ServerMessages {
+		doc.ServerMessages = append(doc.ServerMessages, wsMessageSchema{string(msg.Type), msg.Description, payloadSchema(set, msg.Payload)})
+	}
+	for _, code := range customws.CloseCodes {
+		doc.CloseCodes = append(doc.CloseCodes, wsCloseCodeSchema{code.Code, code.Name, code.Description})
+	}
+	doc.Definitions = set.Definitions()
+
+	body, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(body, '\n'), nil
+}
+
+func payloadSchema(set *openapi.SchemaSet, newPayload func() interface{}) *openapi.Schema {
+	if newPayload == nil {
+		return nil
+	}
+	return set.Schema(newPayload())
+}
+
+// wsProtocolTypeScript genera las interfaces de los payloads, los mapas tipo de mensaje →
+// payload y un cliente con send/request/on tipados.
+func wsProtocolTypeScript() string {
+	gen := tsgen.New()
+	tsType := func(newPayload func() interface{}, input bool, fallback string) string {
+		if newPayload == nil {
+			return fallback
+		}
+		return gen.Type(newPayload(), input)
+	}
+
+	var maps strings.Builder
+	maps.WriteString("/** Payload de cada mensaje que envía el cliente. `undefined` = sin payload. */\n")
+	maps.WriteString("export interface ClientPayloads {\n")
+	for _, msg := range protocol.ClientMessages {
+		typ := tsType(msg.Payload, true, "undefined")
+		if msg.Type == types.MessageTypeDataRequest {
+			typ = "DataRequestPayload"
+		}
+		fmt.Fprintf(&maps, "  /** %s */\n  %s: %s;\n", msg.Description, strconv.Quote(string(msg.Type)), typ)
+	}
+	maps.WriteString("}\n\n")
+
+	maps.WriteString("/** Campo data de cada acción de data_request, por \"recurso/acción\". */\n")
+	maps.WriteString("export interface DataRequests {\n")
+	for _, dr := range protocol.DataRequests {
+		typ := tsType(dr.Data, true, "Record<string, unknown> | undefined")
+		fmt.Fprintf(&maps, "  /** %s */\n  %s: %s;\n", dr.Description, strconv.Quote(dr.Key()), typ)
+	}
+	maps.WriteString("}\n\n")
+
+	maps.WriteString("/** Payload de cada mensaje que envía el servidor. `undefined` = sin payload. */\n")
+	maps.WriteString("export interface ServerPayloads {\n")
+	for _, msg := range protocol.ServerMessages {
+		typ := tsType(msg.Payload, false, "undefined")
+		fmt.Fprintf(&maps, "  /** %s */\n  %s: %s;\n", msg.Description, strconv.Quote(string(msg.Type)), typ)
+	}
+	maps.WriteString("}\n\n")
+
+	maps.WriteString("/** Códigos de cierre del servidor (CloseEvent.code); el motivo se lee con parseCloseReason. */\n")
+	maps.WriteString("export const WsCloseCodes = {\n")
+	for _, code := range customws.CloseCodes {
+		fmt.Fprintf(&maps, "  /** %s */\n  %s: %d,\n", code.Description, code.Name, code.Code)
+	}
+	maps.WriteString("} as const;\n")
+
+	errorType := gen.Type(types.ErrorPayload{}, false)
+	closeReasonType := gen.Type(types.CloseReason{}, false)
+	heartbeatType := gen.Type(types.HeartbeatPayload{}, false)
+
+	var b strings.Builder
+	b.WriteString(`// Código generado por "go run ./cmd/devtools ws-protocol" (backend) a partir de
+// internal/websocket/protocol. NO EDITAR A MANO.
+
+`)
+	b.WriteString(gen.Declarations())
+	b.WriteString("\n")
+	b.WriteString(maps.String())
+	b.WriteString(strings.NewReplacer("{{ErrorPayload}}", errorType, "{{CloseReason}}", closeReasonType, "{{HeartbeatPayload}}", heartbeatType).Replace(wsClientTemplate))
+	return b.String()
+}
+
+// wsClientTemplate son los sobres de los mensajes y el cliente tipado; no dependen del
+// registro salvo por los nombres de las interfaces de error, de motivo de cierre y de heartbeat.
+const wsClientTemplate = `
+export type ClientMessageType = keyof ClientPayloads;
+export type DataRequestKey = keyof DataRequests;
+export type ServerMessageType = keyof ServerPayloads;
+
+/** Payload de data_request. */
+export interface DataRequestPayload {
+  resource: string;
+  action: string;
+  data?: unknown;
+}
+
+/** Mensaje del cliente al servidor. */
+export interface ClientMessage<T extends ClientMessageType = ClientMessageType> {
+  pid?: string;
+  type: T;
+  targetUserId?: number;
+  payload?: ClientPayloads[T];
+}
+
+/** Mensaje del servidor al cliente. */
+export interface ServerMessage<T extends ServerMessageType = ServerMessageType> {
+  pid?: string;
+  type: T;
+  fromUserId?: number;
+  topic?: string;
+  payload: ServerPayloads[T];
+  error?: {{ErrorPayload}};
+}
+
+export interface SendOptions {
+  /** PID del mensaje; por defecto se genera uno. */
+  pid?: string;
+  targetUserId?: number;
+}
+
+/** Argumentos de send/request: el payload es opcional solo si admite undefined. */
+type PayloadArgs<P> = undefined extends P
+  ? [payload?: P, options?: SendOptions]
+  : [payload: P, options?: SendOptions];
+
+type ServerHandler<T extends ServerMessageType> = (payload: ServerPayloads[T], message: ServerMessage<T>) => void;
+
+/**
+ * Decodifica el motivo JSON de un cierre del servidor. Devuelve null si el motivo no lo es
+ * (p. ej. un cierre del navegador o de un proxy). Si reconnect es true se puede reconectar
+ * con las mismas credenciales pasados retryAfter segundos.
+ */
+export function parseCloseReason(event: CloseEvent): {{CloseReason}} | null {
+  try {
+    const reason = JSON.parse(event.reason) as {{CloseReason}};
+    return typeof reason?.reason === 'string' ? reason : null;
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Estima el desfase del reloj local con el del servidor a partir de la respuesta a un
+ * heartbeat del cliente: hora del servidor ≈ Date.now() + desfase. Devuelve null si el
+ * heartbeat no responde a uno del cliente.
+ */
+export function clockOffset(heartbeat: {{HeartbeatPayload}}, receivedAt = Date.now()): number | null {
+  if (!heartbeat.clientTime || !heartbeat.receivedAt) {
+    return null;
+  }
+  return Math.round((heartbeat.receivedAt - heartbeat.clientTime + (heartbeat.serverTime - receivedAt)) / 2);
+}
+
+/**
+ * Cliente tipado sobre un WebSocket ya abierto. No gestiona la conexión ni la reconexión:
+ * al reconectar hay que crear otro cliente con el nuevo socket.
+ */
+export class WsProtocolClient {
+  private readonly socket: WebSocket;
+  private readonly handlers = new Map<string, Set<(message: ServerMessage) => void>>();
+  private seq = 0;
+
+  constructor(socket: WebSocket) {
+    this.socket = socket;
+    socket.addEventListener('message', (event: MessageEvent) => this.dispatch(event.data));
+  }
+
+  /** Envía un mensaje y devuelve su PID. */
+  send<T extends ClientMessageType>(type: T, ...args: PayloadArgs<ClientPayloads[T]>): string {
+    const [payload, options = {}] = args;
+    const pid = options.pid ?? this.nextPid();
+    const message: ClientMessage<T> = { pid, type, payload };
+    if (options.targetUserId !== undefined) {
+      message.targetUserId = options.targetUserId;
+    }
+    this.socket.send(JSON.stringify(message));
+    return pid;
+  }
+
+  /** Envía un data_request para la clave "recurso/acción" y devuelve su PID. */
+  request<K extends DataRequestKey>(key: K, ...args: PayloadArgs<DataRequests[K]>): string {
+    const [data, options] = args;
+    const [resource, action] = key.split('/');
+    return this.send('data_request', { resource, action, data }, options);
+  }
+
+  /** Confirma la recepción de un mensaje del servidor. */
+  ack(pid: string, status = 'processed'): string {
+    return this.send('client_ack', { acknowledgedPid: pid, status, error: '' });
+  }
+
+  /** Envía un heartbeat con la hora local. El servidor responde con un heartbeat con el mismo PID. */
+  heartbeat(): string {
+    return this.send('heartbeat', { clientTime: Date.now() });
+  }
+
+  /** Registra un handler para un tipo de mensaje del servidor. Devuelve la función para quitarlo. */
+  on<T extends ServerMessageType>(type: T, handler: ServerHandler<T>): () => void {
+    const wrapped = (message: ServerMessage) => handler(message.payload as ServerPayloads[T], message as ServerMessage<T>);
+    const set = this.handlers.get(type) ?? new Set<(message: ServerMessage) => void>();
+    this.handlers.set(type, set);
+    set.add(wrapped);
+    return () => {
+      set.delete(wrapped);
+    };
+  }
+
+  private dispatch(data: unknown): void {
+    if (typeof data !== 'string') {
+      return;
+    }
+    let message: ServerMessage;
+    try {
+      message = JSON.parse(data) as ServerMessage;
+    } catch {
+      return;
+    }
+    this.handlers.get(message.type)?.forEach((handler) => handler(message));
+  }
+
+  private nextPid(): string {
+    this.seq += 1;
+    return ` + "`${Date.now().toString(36)}-${this.seq}`" + `;
+  }
+}
+`
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/gorilla/websocket"
+)
+
+// outcome es el resultado de esperar la respuesta de una petición.
+type outcome int
+
+const (
+	outcomeOK      outcome = iota
+	outcomeError           // El servidor respondió con un error
+	outcomeTimeout         // Sin respuesta en -timeout
+	outcomeClosed          // La conexión se cerró
+	outcomeStopped         // La prueba terminó
+)
+
+// client es una conexión simulada. Un goroutine lee los mensajes del servidor y los deja en
+// incoming; run envía las peticiones de una en una y espera su respuesta.
+type client struct {
+	index  int
+	token  string
+	rnd    *rand.Rand
+	runner *runner
+
+	conn     *websocket.Conn
+	incoming chan serverMessage
+	closed   chan struct{} // Se cierra cuando termina la lectura
+	stop     chan struct{} // Se cierra al terminar la prueba, para que la lectura no se bloquee
+	readErr  error         // Válido tras cerrarse closed
+	chatIDs  []string
+	pidSeq   int
+}
+
+func newClient(index int, token string, r *runner) *client {
+	return &client{
+		index:    index,
+		token:    token,
+		rnd:      rand.New(rand.NewSource(r.opts.seed + int64(index))),
+		runner:   r,
+		incoming: make(chan serverMessage, 256),
+		closed:   make(chan struct{}),
+		stop:     make(chan struct{}),
+	}
+}
+
+// run conecta el cliente y ejecuta escenarios hasta que ctx termina o se cae la conexión.
+func (c *client) run(ctx context.Context) {
+	r := c.runner
+	if err := c.connect(ctx); err != nil {
+		return
+	}
+	r.active.Add(1)
+	defer r.active.Add(-1)
+	go c.readLoop()
+	defer c.close()
+
+	if usesScenario(r.mix, "chat") {
+		c.loadChats(ctx)
+	}
+
+	for {
+		// Pausa entre peticiones, ±20% para que los clientes no se sincronicen
+		pause := r.opts.interval
+		if pause > 0 {
+			pause += time.Duration((c.rnd.Float64()*0.4 - 0.2) * float64(pause))
+		}
+		if res, _ := c.await(ctx, pause, nil); res == outcomeStopped || res == outcomeClosed {
+			c.recordDrop(res)
+			return
+		}
+
+		sc := c.pick()
+		pid := c.nextPID()
+		msg, ok := sc.build(c, pid)
+		if !ok {
+			r.recorders[sc.name].skip()
+			continue
+		}
+		start := time.Now()
+		if err := c.send(msg); err != nil {
+			r.recorders[sc.name].failure("send")
+			c.recordDrop(outcomeClosed)
+			return
+		}
+		r.sent.Add(1)
+
+		res, code := c.await(ctx, r.opts.timeout, func(m serverMessage) (bool, string) {
+			if code := failure(pid, m); code != "" {
+				return true, code
+			}
+			return sc.complete(pid, m), ""
+		})
+		switch res {
+		case outcomeOK:
+			r.recorders[sc.name].success(time.Since(start))
+		case outcomeError:
+			r.recorders[sc.name].failure(code)
+		case outcomeTimeout:
+			r.recorders[sc.name].timeout()
+		case outcomeClosed:
+			r.recorders[sc.name].failure("connection_closed")
+			c.recordDrop(res)
+			return
+		case outcomeStopped:
+			return
+		}
+	}
+}
+
+// connect abre la conexión con el token del usuario.
+func (c *client) connect(ctx context.Context) error {
+	r := c.runner
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+c.token)
+	dialer := websocket.Dialer{HandshakeTimeout: r.opts.timeout}
+
+	start := time.Now()
+	conn, resp, err := dialer.DialContext(ctx, r.opts.url, header)
+	if err != nil {
+		code := "dial"
+		if resp != nil {
+			code = fmt.Sprintf("http_%d", resp.StatusCode)
+		}
+		if ctx.Err() == nil {
+			r.connects.failure(code)
+		}
+		return err
+	}
+	r.connects.success(time.Since(start))
+	c.conn = conn
+	return nil
+}
+
+// readLoop lee los mensajes del servidor hasta que la conexión se cierra.
+func (c *client) readLoop() {
+	defer close(c.closed)
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			c.readErr = err
+			return
+		}
+		c.runner.received.Add(1)
+		var msg serverMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		select {
+		case c.incoming <- msg:
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// await descarta mensajes hasta que match termina la petición o pasa timeout. Con match nil
+// solo consume los mensajes durante timeout.
+func (c *client) await(ctx context.Context, timeout time.Duration, match func(serverMessage) (bool, string)) (outcome, string) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return outcomeStopped, ""
+		case <-c.closed:
+			// Los mensajes ya leídos pueden incluir la respuesta
+			if res, code, ok := c.drain(match); ok {
+				return res, code
+			}
+			return outcomeClosed, ""
+		case <-timer.C:
+			if match == nil {
+				return outcomeOK, ""
+			}
+			return outcomeTimeout, ""
+		case msg := <-c.incoming:
+			if match == nil {
+				continue
+			}
+			if done, code := match(msg); done {
+				if code != "" {
+					return outcomeError, code
+				}
+				return outcomeOK, ""
+			}
+		}
+	}
+}
+
+// drain busca la respuesta entre los mensajes pendientes tras cerrarse la conexión.
+func (c *client) drain(match func(serverMessage) (bool, string)) (outcome, string, bool) {
+	for {
+		select {
+		case msg := <-c.incoming:
+			if match == nil {
+				continue
+			}
+			if done, code := match(msg); done {
+				if code != "" {
+					return outcomeError, code, true
+				}
+				return outcomeOK, "", true
+			}
+		default:
+			return 0, "", false
+		}
+	}
+}
+
+// loadChats obtiene los chats del usuario para el escenario chat.
+func (c *client) loadChats(ctx context.Context) {
+	pid := c.nextPID()
+	if err := c.send(dataRequest(pid, "chat", "get_list", nil)); err != nil {
+		return
+	}
+	c.await(ctx, c.runner.opts.timeout, func(m serverMessage) (bool, string) {
+		if code := failure(pid, m); code != "" {
+			return true, code
+		}
+		if m.Type != types.MessageTypeChatList {
+			return false, ""
+		}
+		var chats []struct {
+			ChatID string `json:"chatId"`
+		}
+		if json.Unmarshal(m.Payload, &chats) == nil {
+			for _, chat := range chats {
+				if chat.ChatID != "" {
+					c.chatIDs = append(c.chatIDs, chat.ChatID)
+				}
+			}
+		}
+		return true, ""
+	})
+	if len(c.chatIDs) == 0 {
+		c.runner.noChats.Add(1)
+	}
+}
+
+// pick elige un escenario según los pesos de la mezcla.
+func (c *client) pick() scenario {
+	n := c.rnd.Intn(c.runner.totalWeight)
+	for _, ws := range c.runner.mix {
+		if n < ws.weight {
+			return ws.scenario
+		}
+		n -= ws.weight
+	}
+	return c.runner.mix[len(c.runner.mix)-1].scenario
+}
+
+func (c *client) nextPID() string {
+	c.pidSeq++
+	return fmt.Sprintf("lt-%d-%d", c.index, c.pidSeq)
+}
+
+func (c *client) send(msg types.ClientToServerMessage) error {
+	c.conn.SetWriteDeadline(time.Now().Add(c.runner.opts.timeout))
+	return c.conn.WriteJSON(msg)
+}
+
+// recordDrop cuenta la conexión como caída si el servidor la cerró antes del final.
+func (c *client) recordDrop(res outcome) {
+	if res != outcomeClosed {
+		return
+	}
+	reason := "write_error"
+	select {
+	case <-c.closed:
+		reason = "read_error"
+		var closeErr *websocket.CloseError
+		if errors.As(c.readErr, &closeErr) {
+			reason = fmt.Sprintf("close_%d", closeErr.Code)
+		}
+	default:
+	}
+	c.runner.drops.failure(reason)
+}
+
+// close cierra la conexión de forma ordenada.
+func (c *client) close() {
+	close(c.stop)
+	deadline := time.Now().Add(time.Second)
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "fin de la prueba"), deadline)
+	select {
+	case <-c.closed:
+	case <-time.After(time.Second):
+	}
+	c.conn.Close()
+}
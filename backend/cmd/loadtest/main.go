@@ -0,0 +1,413 @@
+// Command loadtest abre muchas conexiones WebSocket simultáneas contra el servidor WebSocket y
+// mide la latencia y los errores de una mezcla de peticiones. Ver docs/pruebas_de_carga.md.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/auth"
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db"
+	"github.com/joho/godotenv"
+)
+
+// Colores ANSI para la salida
+const (
+	Red    = "\033[31m"
+	Green  = "\033[32m"
+	Yellow = "\033[33m"
+	Cyan   = "\033[36m"
+	Reset  = "\033[0m"
+	Bold   = "\033[1m"
+)
+
+// seedEmailDomain es el dominio de los usuarios creados por `devtools seed`, que se usan si no
+// se indica -users.
+const seedEmailDomain = "seed.local"
+
+// options son los parámetros de la prueba.
+type options struct {
+	url          string
+	connections  int
+	users        string
+	mix          string
+	duration     time.Duration
+	ramp         time.Duration
+	interval     time.Duration
+	timeout      time.Duration
+	reportEvery  time.Duration
+	seed         int64
+	jsonPath     string
+	maxErrorRate float64
+}
+
+// runner es el estado compartido por todos los clientes de una prueba.
+type runner struct {
+	opts        options
+	mix         []weightedScenario
+	totalWeight int
+	recorders   map[string]*recorder // Un recorder por escenario
+
+	connects *recorder // Latencia de conexión y conexiones fallidas
+	drops    *recorder // Conexiones cerradas por el servidor antes del final, por motivo
+	active   atomic.Int64
+	sent     atomic.Int64
+	received atomic.Int64
+	noChats  atomic.Int64 // Clientes sin chats para el escenario chat
+}
+
+// report es el resultado de la prueba.
+type report struct {
+	URL         string             `json:"url"`
+	Connections int                `json:"connections"`
+	Users       int                `json:"users"`
+	Mix         string             `json:"mix"`
+	Elapsed     float64            `json:"elapsedSeconds"`
+	Connect     summary            `json:"connect"`
+	Drops       map[string]int64   `json:"drops,omitempty"`
+	Scenarios   map[string]summary `json:"scenarios"`
+	Total       summary            `json:"total"`
+}
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s[LOADTEST]%s No se pudo cargar .env, usando variables de entorno\n", Yellow, Reset)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fatalf("Error cargando configuración: %v", err)
+	}
+
+	opts := options{}
+	flag.StringVar(&opts.url, "url", "ws://localhost:"+cfg.WsPort+"/ws", "URL del endpoint WebSocket")
+	flag.IntVar(&opts.connections, "connections", 100, "Número de conexiones simultáneas")
+	flag.StringVar(&opts.users, "users", "", "IDs de usuario para los tokens, ej. \"1-50,60\". Vacío = usuarios de devtools seed")
+	flag.StringVar(&opts.mix, "mix", "ack=5,feed=2,chat=1", "Escenarios y sus pesos (ack, chat, feed)")
+	flag.DurationVar(&opts.duration, "duration", time.Minute, "Duración de la prueba, incluida la rampa")
+	flag.DurationVar(&opts.ramp, "ramp", 10*time.Second, "Tiempo en el que se abren todas las conexiones")
+	flag.DurationVar(&opts.interval, "interval", 2*time.Second, "Pausa media entre peticiones de cada conexión")
+	flag.DurationVar(&opts.timeout, "timeout", 10*time.Second, "Tiempo máximo de espera de cada respuesta")
+	flag.DurationVar(&opts.reportEvery, "report-every", 5*time.Second, "Intervalo del progreso (0 = sin progreso)")
+	flag.Int64Var(&opts.seed, "seed", 1, "Semilla de la elección de escenarios")
+	flag.StringVar(&opts.jsonPath, "json", "", "Archivo en el que guardar además el informe en JSON")
+	flag.Float64Var(&opts.maxErrorRate, "max-error-rate", 0, "Termina con código 1 si la tasa de errores total la supera (0 = no comprobar)")
+	flag.Parse()
+
+	if opts.connections <= 0 {
+		fatalf("-connections debe ser mayor que 0")
+	}
+	mix, err := parseMix(opts.mix)
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	userIDs, err := resolveUsers(cfg, opts.users, opts.connections)
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	// Tokens firmados con JWT_SECRET: el servidor los valida como los del login
+	ttl := opts.duration + 10*time.Minute
+	tokens := make(map[int64]string, len(userIDs))
+	for _, id := range userIDs {
+		token, _, err := auth.GenerateJWT(id, 0, 0, []byte(cfg.JwtSecret), ttl)
+		if err != nil {
+			fatalf("Error generando el token de UserID %d: %v", id, err)
+		}
+		tokens[id] = token
+	}
+
+	r := &runner{
+		opts:      opts,
+		mix:       mix,
+		recorders: make(map[string]*recorder),
+		connects:  newRecorder(),
+		drops:     newRecorder(),
+	}
+	for _, ws := range mix {
+		r.totalWeight += ws.weight
+		r.recorders[ws.name] = newRecorder()
+	}
+
+	logf("%s%s🔥 Prueba de carga: %d conexiones de %d usuarios contra %s durante %s (mezcla %s)%s",
+		Bold, Cyan, opts.connections, len(userIDs), opts.url, opts.duration, opts.mix, Reset)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.duration)
+	defer cancel()
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logf("%s[LOADTEST]%s Interrumpida, generando el informe...", Yellow, Reset)
+		cancel()
+	}()
+
+	start := time.Now()
+	stopProgress := r.progress(ctx, start)
+	r.run(ctx, userIDs, tokens)
+	elapsed := time.Since(start)
+	stopProgress()
+
+	rep := r.report(elapsed, len(userIDs))
+	printReport(rep)
+	if opts.jsonPath != "" {
+		if err := writeJSONReport(opts.jsonPath, rep); err != nil {
+			fatalf("Error guardando el informe JSON: %v", err)
+		}
+		logf("%s[LOADTEST]%s Informe JSON guardado en %s", Green, Reset, opts.jsonPath)
+	}
+
+	if opts.maxErrorRate > 0 && rep.Total.ErrorRate > opts.maxErrorRate {
+		logf("%s[LOADTEST]%s Tasa de errores %.2f%% superior al máximo %.2f%%", Red, Reset, rep.Total.ErrorRate*100, opts.maxErrorRate*100)
+		os.Exit(1)
+	}
+}
+
+// run abre las conexiones repartidas en la rampa y espera a que terminen.
+func (r *runner) run(ctx context.Context, userIDs []int64, tokens map[int64]string) {
+	var step time.Duration
+	if r.opts.connections > 1 {
+		step = r.opts.ramp / time.Duration(r.opts.connections)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < r.opts.connections; i++ {
+		if i > 0 && step > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(step):
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		userID := userIDs[i%len(userIDs)]
+		c := newClient(i, tokens[userID], r)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.run(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// progress imprime el estado de la prueba cada opts.reportEvery. Devuelve la función que lo
+// detiene.
+func (r *runner) progress(ctx context.Context, start time.Time) func() {
+	if r.opts.reportEvery <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(r.opts.reportEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				connects := r.connects.summarize(0)
+				logf("%s[%s]%s activas %d/%d · fallidas %d · enviadas %d · recibidos %d",
+					Cyan, time.Since(start).Truncate(time.Second), Reset, r.active.Load(), r.opts.connections,
+					connects.Errors, r.sent.Load(), r.received.Load())
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// report resume los resultados.
+func (r *runner) report(elapsed time.Duration, users int) report {
+	rep := report{
+		URL:         r.opts.url,
+		Connections: r.opts.connections,
+		Users:       users,
+		Mix:         r.opts.mix,
+		Elapsed:     elapsed.Seconds(),
+		Connect:     r.connects.summarize(0),
+		Drops:       r.drops.summarize(0).ErrorsBy,
+		Scenarios:   make(map[string]summary, len(r.recorders)),
+	}
+
+	total := newRecorder()
+	for name, rec := range r.recorders {
+		rep.Scenarios[name] = rec.summarize(elapsed)
+		rec.mu.Lock()
+		total.latencies = append(total.latencies, rec.latencies...)
+		for code, n := range rec.errors {
+			total.errors[code] += n
+		}
+		total.timeouts += rec.timeouts
+		total.skipped += rec.skipped
+		rec.mu.Unlock()
+	}
+	rep.Total = total.summarize(elapsed)
+
+	if n := r.noChats.Load(); n > 0 {
+		logf("%s[LOADTEST]%s %d conexiones no tenían chats: sus iteraciones de chat se omitieron", Yellow, Reset, n)
+	}
+	return rep
+}
+
+// printReport imprime el informe como tabla.
+func printReport(rep report) {
+	fmt.Printf("\n%s%s📊 Resultados (%.1fs)%s\n", Bold, Cyan, rep.Elapsed, Reset)
+	fmt.Printf("Conexiones: %d correctas, %d fallidas, p50 %.1fms, p99 %.1fms\n",
+		rep.Connect.OK, rep.Connect.Errors, rep.Connect.P50Ms, rep.Connect.P99Ms)
+	if len(rep.Connect.ErrorsBy) > 0 {
+		fmt.Printf("  Fallos de conexión: %s\n", formatCounts(rep.Connect.ErrorsBy))
+	}
+	if len(rep.Drops) > 0 {
+		fmt.Printf("  %sConexiones cerradas por el servidor:%s %s\n", Yellow, Reset, formatCounts(rep.Drops))
+	}
+	fmt.Println()
+
+	names := make([]string, 0, len(rep.Scenarios))
+	for name := range rep.Scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "escenario\tpeticiones\tok\terrores\ttimeouts\t% error\tpet/s\tp50 ms\tp90 ms\tp95 ms\tp99 ms\tmax ms\t")
+	row := func(name string, s summary) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.2f\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t\n",
+			name, s.Requests, s.OK, s.Errors, s.Timeouts, s.ErrorRate*100, s.PerSecond,
+			s.P50Ms, s.P90Ms, s.P95Ms, s.P99Ms, s.MaxMs)
+	}
+	for _, name := range names {
+		row(name, rep.Scenarios[name])
+	}
+	row("total", rep.Total)
+	tw.Flush()
+
+	for _, name := range names {
+		if s := rep.Scenarios[name]; len(s.ErrorsBy) > 0 {
+			fmt.Printf("  Errores de %s: %s\n", name, formatCounts(s.ErrorsBy))
+		}
+	}
+}
+
+// writeJSONReport guarda el informe en path, para comparar pruebas entre versiones.
+func writeJSONReport(path string, rep report) error {
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// formatCounts formatea un mapa de contadores como "a=1, b=2", ordenado por clave.
+func formatCounts(counts map[string]int64) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%d", k, counts[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// resolveUsers devuelve los IDs de -users o, si está vacío, hasta max usuarios del seed.
+func resolveUsers(cfg *config.Config, spec string, max int) ([]int64, error) {
+	if spec != "" {
+		return parseUserIDs(spec)
+	}
+
+	conn, err := db.Connect(cfg.DatabaseDSN, db.NewPoolConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("error conectando a la base de datos para obtener los usuarios del seed: %w", err)
+	}
+	defer conn.Close()
+	ids, err := seedUserIDs(conn, max)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no hay usuarios @%s: ejecuta `devtools seed` o indica -users", seedEmailDomain)
+	}
+	return ids, nil
+}
+
+// seedUserIDs devuelve hasta limit IDs de usuarios creados por `devtools seed`.
+func seedUserIDs(conn *sql.DB, limit int) ([]int64, error) {
+	rows, err := conn.Query("SELECT Id FROM User WHERE Email LIKE ? ORDER BY Id LIMIT ?", "%@"+seedEmailDomain, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo los usuarios del seed: %w", err)
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error leyendo los usuarios del seed: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// parseUserIDs convierte una lista de IDs y rangos separada por comas (ej. "1-50,60").
+func parseUserIDs(spec string) ([]int64, error) {
+	var ids []int64
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(entry, "-")
+		first, err := strconv.ParseInt(strings.TrimSpace(from), 10, 64)
+		if err != nil || first <= 0 {
+			return nil, fmt.Errorf("ID de usuario %q inválido", entry)
+		}
+		last := first
+		if isRange {
+			last, err = strconv.ParseInt(strings.TrimSpace(to), 10, 64)
+			if err != nil || last < first {
+				return nil, fmt.Errorf("rango de usuarios %q inválido", entry)
+			}
+		}
+		for id := first; id <= last; id++ {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("-users no contiene ningún ID")
+	}
+	return ids, nil
+}
+
+// logf escribe el progreso y los avisos en stderr; el informe va a stdout.
+func logf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+func fatalf(format string, args ...interface{}) {
+	logf("%s[LOADTEST]%s "+format, append([]interface{}{Red, Reset}, args...)...)
+	os.Exit(1)
+}
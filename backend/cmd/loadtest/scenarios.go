@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+)
+
+// serverMessage es un mensaje del servidor con el payload sin decodificar: cada escenario
+// decodifica solo lo que necesita.
+type serverMessage struct {
+	PID     string              `json:"pid"`
+	Type    types.MessageType   `json:"type"`
+	Payload json.RawMessage     `json:"payload"`
+	Error   *types.ErrorPayload `json:"error"`
+}
+
+// scenario es un tipo de petición que el cliente repite durante la prueba.
+type scenario struct {
+	name string
+	// build construye el mensaje con el PID dado. false si el cliente no puede ejecutarlo
+	// (ej. chat sin chats), y la iteración se cuenta como omitida.
+	build func(c *client, pid string) (types.ClientToServerMessage, bool)
+	// complete decide si msg termina la petición pid: la latencia se mide hasta él.
+	complete func(pid string, msg serverMessage) bool
+}
+
+// scenarios son los escenarios disponibles, por nombre.
+var scenarios = map[string]scenario{
+	// ack: ping de data_request. Mide la ida y vuelta por el router hasta el server_ack.
+	"ack": {
+		name: "ack",
+		build: func(c *client, pid string) (types.ClientToServerMessage, bool) {
+			return dataRequest(pid, "", "ping", nil), true
+		},
+		complete: func(pid string, msg serverMessage) bool {
+			return msg.Type == types.MessageTypeServerAck && ackedPID(msg) == pid
+		},
+	},
+	// feed: primera página del feed. Termina con el data_event de la lista.
+	"feed": {
+		name: "feed",
+		build: func(c *client, pid string) (types.ClientToServerMessage, bool) {
+			return dataRequest(pid, "feed", "get_list", map[string]interface{}{"page": 1, "limit": 10}), true
+		},
+		complete: func(pid string, msg serverMessage) bool {
+			return msg.Type == types.MessageTypeDataEvent
+		},
+	},
+	// chat: mensaje de texto a uno de los chats del usuario. Termina con el
+	// message_status_update que confirma que se guardó.
+	"chat": {
+		name: "chat",
+		build: func(c *client, pid string) (types.ClientToServerMessage, bool) {
+			if len(c.chatIDs) == 0 {
+				return types.ClientToServerMessage{}, false
+			}
+			chatID := c.chatIDs[c.rnd.Intn(len(c.chatIDs))]
+			text := fmt.Sprintf("Mensaje de prueba de carga %s", pid)
+			return dataRequest(pid, "chat", "send_message", map[string]interface{}{"chatId": chatID, "text": text}), true
+		},
+		complete: func(pid string, msg serverMessage) bool {
+			if msg.Type != "message_status_update" {
+				return false
+			}
+			var payload struct {
+				OriginalPID string `json:"originalPID"`
+			}
+			return json.Unmarshal(msg.Payload, &payload) == nil && payload.OriginalPID == pid
+		},
+	},
+}
+
+// dataRequest construye un data_request.
+func dataRequest(pid, resource, action string, data map[string]interface{}) types.ClientToServerMessage {
+	payload := map[string]interface{}{"action": action}
+	if resource != "" {
+		payload["resource"] = resource
+	}
+	if data != nil {
+		payload["data"] = data
+	}
+	return types.ClientToServerMessage{PID: pid, Type: types.MessageTypeDataRequest, Payload: payload}
+}
+
+// ackedPID devuelve el PID que confirma un server_ack.
+func ackedPID(msg serverMessage) string {
+	var ack types.AckPayload
+	if json.Unmarshal(msg.Payload, &ack) != nil {
+		return ""
+	}
+	return ack.AcknowledgedPID
+}
+
+// failure devuelve el error con el que el servidor rechazó la petición pid, o "" si msg no es
+// un error de esa petición.
+func failure(pid string, msg serverMessage) string {
+	switch msg.Type {
+	case types.MessageTypeErrorNotification:
+		if msg.Error != nil && (msg.Error.OriginalPID == pid || msg.PID == pid) {
+			if msg.Error.ErrorCode != "" {
+				return msg.Error.ErrorCode
+			}
+			return strconv.Itoa(msg.Error.Code)
+		}
+	case types.MessageTypeServerAck:
+		var ack types.AckPayload
+		if json.Unmarshal(msg.Payload, &ack) == nil && ack.AcknowledgedPID == pid && ack.Status == "error" {
+			return "ack_error"
+		}
+	}
+	return ""
+}
+
+// weightedScenario es un escenario con su peso en la mezcla.
+type weightedScenario struct {
+	scenario
+	weight int
+}
+
+// parseMix convierte una lista "escenario=peso" separada por comas (ej. "ack=5,feed=2,chat=1")
+// en la mezcla de escenarios. Un escenario sin peso vale 1.
+func parseMix(spec string) ([]weightedScenario, error) {
+	var mix []weightedScenario
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, weightStr, hasWeight := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		sc, ok := scenarios[name]
+		if !ok {
+			return nil, fmt.Errorf("escenario %q desconocido (disponibles: %s)", name, strings.Join(scenarioNames(), ", "))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("escenario %q repetido", name)
+		}
+		seen[name] = true
+		weight := 1
+		if hasWeight {
+			w, err := strconv.Atoi(strings.TrimSpace(weightStr))
+			if err != nil || w < 0 {
+				return nil, fmt.Errorf("peso de %q inválido: debe ser un entero no negativo", name)
+			}
+			weight = w
+		}
+		if weight > 0 {
+			mix = append(mix, weightedScenario{scenario: sc, weight: weight})
+		}
+	}
+	if len(mix) == 0 {
+		return nil, fmt.Errorf("la mezcla %q no tiene ningún escenario con peso", spec)
+	}
+	return mix, nil
+}
+
+// scenarioNames devuelve los nombres de los escenarios, ordenados.
+func scenarioNames() []string {
+	names := make([]string, 0, len(scenarios))
+	for name := range scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// usesScenario indica si la mezcla incluye el escenario name.
+func usesScenario(mix []weightedScenario, name string) bool {
+	for _, ws := range mix {
+		if ws.name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// recorder acumula los resultados de un escenario (o de las conexiones). Lo comparten todos
+// los clientes.
+type recorder struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    map[string]int64 // Código de error -> número de peticiones
+	timeouts  int64
+	skipped   int64
+}
+
+func newRecorder() *recorder {
+	return &recorder{errors: make(map[string]int64)}
+}
+
+func (r *recorder) success(latency time.Duration) {
+	r.mu.Lock()
+	r.latencies = append(r.latencies, latency)
+	r.mu.Unlock()
+}
+
+func (r *recorder) failure(code string) {
+	r.mu.Lock()
+	r.errors[code]++
+	r.mu.Unlock()
+}
+
+func (r *recorder) timeout() {
+	r.mu.Lock()
+	r.timeouts++
+	r.mu.Unlock()
+}
+
+func (r *recorder) skip() {
+	r.mu.Lock()
+	r.skipped++
+	r.mu.Unlock()
+}
+
+// summary es el resumen de un recorder para el informe.
+type summary struct {
+	Requests  int64            `json:"requests"` // Peticiones terminadas (correctas, con error o sin respuesta)
+	OK        int64            `json:"ok"`
+	Errors    int64            `json:"errors"`
+	Timeouts  int64            `json:"timeouts"`
+	Skipped   int64            `json:"skipped,omitempty"`
+	ErrorRate float64          `json:"errorRate"` // (errores + timeouts) / peticiones
+	PerSecond float64          `json:"perSecond"` // Peticiones correctas por segundo
+	P50Ms     float64          `json:"p50Ms"`
+	P90Ms     float64          `json:"p90Ms"`
+	P95Ms     float64          `json:"p95Ms"`
+	P99Ms     float64          `json:"p99Ms"`
+	MaxMs     float64          `json:"maxMs"`
+	ErrorsBy  map[string]int64 `json:"errorsBy,omitempty"`
+}
+
+// summarize calcula el resumen. elapsed es la duración de la prueba, para el throughput.
+func (r *recorder) summarize(elapsed time.Duration) summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	latencies := append([]time.Duration(nil), r.latencies...)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	s := summary{OK: int64(len(latencies)), Timeouts: r.timeouts, Skipped: r.skipped}
+	if len(r.errors) > 0 {
+		s.ErrorsBy = make(map[string]int64, len(r.errors))
+		for code, n := range r.errors {
+			s.ErrorsBy[code] = n
+			s.Errors += n
+		}
+	}
+	s.Requests = s.OK + s.Errors + s.Timeouts
+	if s.Requests > 0 {
+		s.ErrorRate = float64(s.Errors+s.Timeouts) / float64(s.Requests)
+	}
+	if elapsed > 0 {
+		s.PerSecond = float64(s.OK) / elapsed.Seconds()
+	}
+	if len(latencies) > 0 {
+		s.P50Ms = millis(percentile(latencies, 0.50))
+		s.P90Ms = millis(percentile(latencies, 0.90))
+		s.P95Ms = millis(percentile(latencies, 0.95))
+		s.P99Ms = millis(percentile(latencies, 0.99))
+		s.MaxMs = millis(latencies[len(latencies)-1])
+	}
+	return s
+}
+
+// percentile devuelve el percentil p (0-1) de latencias ordenadas, por el método del rango
+// más cercano.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+func millis(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Millisecond)*100) / 100
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/auth"
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+)
+
+// hashReplicas es el número de nodos virtuales de cada upstream en el anillo de hash.
+// Con más réplicas el reparto es más uniforme y, al caer una instancia, sus usuarios se
+// distribuyen entre las demás en lugar de ir todos a la siguiente.
+const hashReplicas = 100
+
+// balancer elige el upstream de una petición entre los de la ruta. Devuelve nil si
+// ninguno está disponible (todos con el circuito abierto o drenándose).
+type balancer interface {
+	pick(req *http.Request, targets []*upstreamTarget) *upstreamTarget
+}
+
+// newBalancer crea el balancer de la estrategia configurada para la ruta.
+func newBalancer(strategy string, targets []*upstreamTarget, jwtSecret []byte) balancer {
+	switch strategy {
+	case config.BalanceLeastConnections:
+		return leastConnectionsBalancer{}
+	case config.BalanceHash:
+		return newHashBalancer(targets, jwtSecret)
+	default:
+		return &roundRobinBalancer{}
+	}
+}
+
+// roundRobinBalancer reparte las peticiones por turnos entre los upstreams disponibles.
+type roundRobinBalancer struct {
+	next uint64
+}
+
+func (b *roundRobinBalancer) pick(_ *http.Request, targets []*upstreamTarget) *upstreamTarget {
+	start := atomic.AddUint64(&b.next, 1) - 1
+	for i := 0; i < len(targets); i++ {
+		target := targets[(start+uint64(i))%uint64(len(targets))]
+		if target.Available() {
+			return target
+		}
+	}
+	return nil
+}
+
+// leastConnectionsBalancer elige el upstream disponible con menos peticiones o
+// conexiones WebSocket en curso.
+type leastConnectionsBalancer struct{}
+
+func (leastConnectionsBalancer) pick(_ *http.Request, targets []*upstreamTarget) *upstreamTarget {
+	var best *upstreamTarget
+	var bestActive int64
+	for _, target := range targets {
+		if !target.Available() {
+			continue
+		}
+		active := target.Active()
+		if best == nil || active < bestActive {
+			best, bestActive = target, active
+		}
+	}
+	return best
+}
+
+// hashBalancer usa hash consistente sobre la identidad del cliente (ver affinityKey).
+// Si el upstream asignado tiene el circuito abierto se usa el siguiente del anillo, y
+// el usuario vuelve a su instancia original en cuanto esta se recupera.
+type hashBalancer struct {
+	jwtSecret []byte
+	ring      []ringNode
+}
+
+type ringNode struct {
+	hash   uint32
+	target *upstreamTarget
+}
+
+func newHashBalancer(targets []*upstreamTarget, jwtSecret []byte) *hashBalancer {
+	b := &hashBalancer{jwtSecret: jwtSecret}
+	for _, target := range targets {
+		for i := 0; i < hashReplicas; i++ {
+			b.ring = append(b.ring, ringNode{
+				hash:   hashKey(fmt.Sprintf("%s#%d", target.url, i)),
+				target: target,
+			})
+		}
+	}
+	sort.Slice(b.ring, func(i, j int) bool { return b.ring[i].hash < b.ring[j].hash })
+	return b
+}
+
+func (b *hashBalancer) pick(req *http.Request, _ []*upstreamTarget) *upstreamTarget {
+	if len(b.ring) == 0 {
+		return nil
+	}
+	h := hashKey(affinityKey(req, b.jwtSecret))
+	start := sort.Search(len(b.ring), func(i int) bool { return b.ring[i].hash >= h })
+	for i := 0; i < len(b.ring); i++ {
+		node := b.ring[(start+i)%len(b.ring)]
+		if node.target.Available() {
+			return node.target
+		}
+	}
+	return nil
+}
+
+// affinityKey identifica al cliente para el hash. Con un JWT válido (header Authorization
+// o parámetro ?token, igual que el servidor WebSocket) se usa el ID de usuario, de modo
+// que la afinidad se mantiene aunque el token se renueve. Sin token se usa la IP.
+func affinityKey(req *http.Request, jwtSecret []byte) string {
+	token := ""
+	if authHeader := req.Header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		token = strings.TrimPrefix(authHeader, "Bearer ")
+	}
+	if token == "" {
+		token = req.URL.Query().Get("token")
+	}
+	if token != "" {
+		if claims, err := auth.ValidateJWT(token, jwtSecret); err == nil {
+			return "user:" + strconv.FormatInt(claims.UserID, 10)
+		}
+		return "token:" + token
+	}
+
+	if forwarded := req.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return "ip:" + strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		return "ip:" + host
+	}
+	return "ip:" + req.RemoteAddr
+}
+
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
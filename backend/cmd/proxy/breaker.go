@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// Estados del circuit breaker de un upstream.
+const (
+	circuitClosed = "closed" // upstream sano: se enruta normalmente
+	circuitOpen   = "open"   // upstream caído: se responde 503 sin contactarlo
+)
+
+const (
+	healthCheckInterval = 5 * time.Second
+	healthCheckTimeout  = 2 * time.Second
+	// failureThreshold es el número de fallos consecutivos que abren el circuito.
+	failureThreshold = 3
+	// openRetryAfter es el valor de Retry-After (segundos) devuelto con el circuito abierto.
+	openRetryAfter = "5"
+)
+
+// upstreamStatus es el estado de un upstream expuesto en /proxy/status.
+type upstreamStatus struct {
+	Name                string     `json:"name"`
+	Prefix              string     `json:"prefix"`
+	Upstream            string     `json:"upstream"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastCheck           *time.Time `json:"lastCheck,omitempty"`
+	LastError           string     `json:"lastError,omitempty"`
+	StateSince          time.Time  `json:"stateSince"`
+	ActiveConnections   int64      `json:"activeConnections"`
+	Draining            bool       `json:"draining,omitempty"` // Instancia WebSocket drenándose (ver /proxy/drain)
+}
+
+// circuitBreaker sigue la salud de un upstream combinando health checks activos
+// con los errores de conexión observados al reenviar peticiones.
+type circuitBreaker struct {
+	target    *upstreamTarget
+	healthURL string
+	client    *http.Client
+
+	mu         sync.RWMutex
+	state      string
+	failures   int
+	lastCheck  time.Time
+	lastError  string
+	stateSince time.Time
+}
+
+func newCircuitBreaker(target *upstreamTarget) *circuitBreaker {
+	return &circuitBreaker{
+		target:     target,
+		healthURL:  target.healthURL(),
+		client:     &http.Client{Timeout: healthCheckTimeout},
+		state:      circuitClosed,
+		stateSince: time.Now().UTC(),
+	}
+}
+
+// Allow indica si se puede enrutar hacia el upstream.
+func (b *circuitBreaker) Allow() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.state == circuitClosed
+}
+
+// Run ejecuta los health checks periódicos hasta que se cancele el contexto.
+func (b *circuitBreaker) Run(ctx context.Context) {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	b.check(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.check(ctx)
+		}
+	}
+}
+
+func (b *circuitBreaker) check(ctx context.Context) {
+	err := b.probe(ctx)
+
+	b.mu.Lock()
+	b.lastCheck = time.Now().UTC()
+	b.mu.Unlock()
+
+	if err != nil {
+		b.recordFailure(err)
+		return
+	}
+	b.recordSuccess()
+}
+
+func (b *circuitBreaker) probe(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.healthURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%s respondió %d", b.healthURL, resp.StatusCode)
+	}
+	return nil
+}
+
+// recordFailure suma un fallo y abre el circuito al alcanzar el umbral.
+func (b *circuitBreaker) recordFailure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.lastError = err.Error()
+	if b.state == circuitClosed && b.failures >= failureThreshold {
+		b.state = circuitOpen
+		b.stateSince = time.Now().UTC()
+		logger.Errorf("PROXY", "🔴 Circuito abierto para %s (%s): %v", b.target.route.Name, b.target.url, err)
+	}
+}
+
+// recordSuccess reinicia los fallos y cierra el circuito si estaba abierto.
+func (b *circuitBreaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.lastError = ""
+	if b.state == circuitOpen {
+		b.state = circuitClosed
+		b.stateSince = time.Now().UTC()
+		logger.Successf("PROXY", "🟢 Circuito cerrado para %s (%s): el upstream vuelve a responder", b.target.route.Name, b.target.url)
+	}
+}
+
+// Status devuelve una copia del estado actual.
+func (b *circuitBreaker) Status() upstreamStatus {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	status := upstreamStatus{
+		Name:                b.target.route.Name,
+		Prefix:              b.target.route.Prefix,
+		Upstream:            b.target.url.String(),
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+		StateSince:          b.stateSince,
+		ActiveConnections:   b.target.Active(),
+		Draining:            b.target.draining.Load(),
+	}
+	if !b.lastCheck.IsZero() {
+		lastCheck := b.lastCheck
+		status.LastCheck = &lastCheck
+	}
+	return status
+}
+
+// HealthCheck adapta el estado del circuito a una comprobación de readiness.
+func (b *circuitBreaker) HealthCheck(ctx context.Context) error {
+	if b.Allow() {
+		return nil
+	}
+	status := b.Status()
+	return fmt.Errorf("circuito abierto: %s", status.LastError)
+}
+
+// statusHandler expone el estado de todos los upstreams en /proxy/status.
+func (t *routeTable) statusHandler(w http.ResponseWriter, r *http.Request) {
+	statuses := make([]upstreamStatus, 0, len(t.routes))
+	healthy := true
+	for _, route := range t.routes {
+		for _, target := range route.targets {
+			status := target.breaker.Status()
+			if status.State != circuitClosed {
+				healthy = false
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"healthy":   healthy,
+		"timestamp": time.Now().UTC(),
+		"upstreams": statuses,
+	})
+}
+
+// startHealthChecks lanza el health check activo de cada upstream.
+func (t *routeTable) startHealthChecks(ctx context.Context) {
+	for _, route := range t.routes {
+		for _, target := range route.targets {
+			go target.breaker.Run(ctx)
+		}
+	}
+}
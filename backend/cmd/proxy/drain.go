@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// drainAdminPath es la ruta del panel admin del servidor WebSocket que drena sus conexiones.
+const drainAdminPath = "/admin/api/drain"
+
+// drainRequest es el cuerpo de POST /proxy/drain. Upstream es la URL de la instancia, como
+// aparece en /proxy/status, o su host:puerto; el resto se reenvía a su /admin/api/drain.
+type drainRequest struct {
+	Upstream          string `json:"upstream"`
+	Enabled           bool   `json:"enabled"`
+	SpreadSeconds     *int   `json:"spreadSeconds,omitempty"`
+	RetryAfterSeconds *int   `json:"retryAfterSeconds,omitempty"`
+}
+
+// drainHandler drena (enabled true) o vuelve a poner en servicio una instancia WebSocket
+// concreta para reiniciarla por turnos: el proxy deja de enviarle conexiones nuevas y la
+// instancia cierra las suyas poco a poco. Responde el estado que devuelve la instancia.
+func (t *routeTable) drainHandler(username, password string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req drainRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Cuerpo inválido", http.StatusBadRequest)
+			return
+		}
+		targets := t.webSocketTargets(strings.TrimSpace(req.Upstream))
+		if len(targets) == 0 {
+			http.Error(w, "No hay ningún upstream WebSocket "+req.Upstream, http.StatusNotFound)
+			return
+		}
+
+		upstream := req.Upstream
+		req.Upstream = ""
+		body, err := json.Marshal(req)
+		if err != nil {
+			http.Error(w, "Error interno", http.StatusInternalServerError)
+			return
+		}
+
+		// Se deja de enviarle conexiones antes de que empiece a cerrar las suyas, para que los
+		// clientes que reconectan vayan a otra instancia.
+		if req.Enabled {
+			setDraining(targets, true)
+		}
+		client := &http.Client{Timeout: maintenancePropagateTimeout}
+		state, err := targets[0].postAdmin(client, drainAdminPath, body, username, password)
+		if err != nil {
+			if req.Enabled {
+				setDraining(targets, false)
+			}
+			logger.Errorf("PROXY", "No se pudo cambiar el drenaje de %s: %v", upstream, err)
+			http.Error(w, "No se pudo cambiar el drenaje de la instancia: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+		if !req.Enabled {
+			setDraining(targets, false)
+		}
+		logger.Infof("PROXY", "Drenaje de %s: %t", upstream, req.Enabled)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(state)
+	}
+}
+
+// webSocketTargets devuelve los upstreams de las rutas WebSocket con esa URL o host:puerto.
+// Una instancia puede atender varias rutas.
+func (t *routeTable) webSocketTargets(upstream string) []*upstreamTarget {
+	var targets []*upstreamTarget
+	for _, route := range t.routes {
+		if !route.WebSocket {
+			continue
+		}
+		for _, target := range route.targets {
+			if target.url.String() == upstream || target.url.Host == upstream {
+				targets = append(targets, target)
+			}
+		}
+	}
+	return targets
+}
+
+func setDraining(targets []*upstreamTarget, draining bool) {
+	for _, target := range targets {
+		target.draining.Store(draining)
+	}
+}
@@ -2,18 +2,19 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"net"
 	"net/http"
-	"net/http/httputil"
-	"net/url"
 	"strings"
 	"time"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/health"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/httpcompress"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/maintenance"
 	"github.com/joho/godotenv"
-	"github.com/koding/websocketproxy"
 )
 
 // Wrapper para logging de respuestas
@@ -28,6 +29,12 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap expone el ResponseWriter original a http.ResponseController, que el ReverseProxy usa
+// para enviar por partes las respuestas sin Content-Length.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Implementar http.Hijacker para soporte de WebSocket
 func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	if hijacker, ok := rw.ResponseWriter.(http.Hijacker); ok {
@@ -78,34 +85,68 @@ func main() {
 		logger.Errorf("CONFIG", "Failed to load configuration: %v", err)
 		return
 	}
+	cfg.LogReport("CONFIG")
 
-	// Parsear URLs de destino
-	apiURL, err := url.Parse(fmt.Sprintf("http://localhost:%s", cfg.ApiPort))
+	// Tabla de rutas: PROXY_ROUTES_FILE o, por defecto, /api/ → API y /ws → WebSocket
+	routeConfigs, err := config.LoadProxyRoutes(cfg)
 	if err != nil {
-		logger.Errorf("CONFIG", "Failed to parse API URL: %v", err)
+		logger.Errorf("CONFIG", "Failed to load proxy routes: %v", err)
 		return
 	}
-
-	wsURL, err := url.Parse(fmt.Sprintf("ws://localhost:%s", cfg.WsPort))
+	routes, err := newRouteTable(routeConfigs, []byte(cfg.JwtSecret))
 	if err != nil {
-		logger.Errorf("CONFIG", "Failed to parse WebSocket URL: %v", err)
+		logger.Errorf("CONFIG", "Failed to build proxy routes: %v", err)
 		return
 	}
 
-	// Crear proxies inversos
-	apiProxy := httputil.NewSingleHostReverseProxy(apiURL)
-	wsProxy := websocketproxy.NewProxy(wsURL)
+	// Health checks activos de los upstreams (circuit breaker)
+	routes.startHealthChecks(context.Background())
 
-	// Modificar el director del proxy API
-	apiProxy.Director = func(req *http.Request) {
-		req.URL.Scheme = apiURL.Scheme
-		req.URL.Host = apiURL.Host
-		req.Host = apiURL.Host
-		logger.Infof("PROXY_DIRECTOR", "Authorization Header: %s", req.Header.Get("Authorization"))
+	// Probes: la readiness del proxy depende de que cada ruta tenga algún upstream disponible
+	healthChecker := health.NewChecker("proxy")
+	for _, route := range routes.routes {
+		healthChecker.Register(route.Name, route.HealthCheck)
+	}
+	http.HandleFunc("/healthz", healthChecker.LivenessHandler())
+	http.HandleFunc("/readyz", healthChecker.ReadinessHandler())
+	http.HandleFunc("/proxy/status", routes.statusHandler)
+
+	// Modo mantenimiento: 503 para las peticiones nuevas salvo las rutas exentas. Cada cambio
+	// se propaga a los servidores WebSocket, que avisan a sus clientes y cierran las conexiones.
+	maintenanceMode := maintenance.New(maintenance.Options{
+		Enabled:     cfg.MaintenanceMode,
+		Message:     cfg.MaintenanceMessage,
+		RetryAfter:  cfg.MaintenanceRetryAfter,
+		Countdown:   cfg.MaintenanceCountdown,
+		ExemptPaths: strings.Split(cfg.MaintenanceExemptPaths, ","),
+	})
+	// Un único goroutine propaga los cambios para que lleguen a los upstreams en orden.
+	maintenanceChanges := make(chan maintenance.State, 16)
+	maintenanceMode.OnChange(func(state maintenance.State) { maintenanceChanges <- state })
+	go func() {
+		for state := range maintenanceChanges {
+			routes.propagateMaintenance(state, cfg.AdminUsername, cfg.AdminPassword)
+		}
+	}()
+	http.HandleFunc("/proxy/maintenance", requireBasicAuth(cfg.AdminUsername, cfg.AdminPassword, maintenanceMode.Handler()))
+
+	// Drenaje de una instancia WebSocket concreta para reiniciarla por turnos (ver docs/drenaje_conexiones.md)
+	http.HandleFunc("/proxy/drain", requireBasicAuth(cfg.AdminUsername, cfg.AdminPassword, routes.drainHandler(cfg.AdminUsername, cfg.AdminPassword)))
+
+	// Compresión de las respuestas que los upstreams no envían ya comprimidas. Las conexiones
+	// WebSocket pasan sin comprimir.
+	compress := func(next http.HandlerFunc) http.HandlerFunc { return next }
+	if cfg.CompressionEnabled {
+		middleware := httpcompress.Middleware(httpcompress.Options{
+			MinSize: cfg.CompressionMinSize,
+			Types:   httpcompress.ParseTypes(cfg.CompressionTypes),
+			Level:   cfg.CompressionLevel,
+		})
+		compress = func(next http.HandlerFunc) http.HandlerFunc { return middleware(next).ServeHTTP }
 	}
 
 	// Definir el manejador principal del proxy con CORS
-	http.HandleFunc("/", corsMiddleware(func(w http.ResponseWriter, r *http.Request) {
+	http.HandleFunc("/", corsMiddleware(compress(func(w http.ResponseWriter, r *http.Request) {
 		startTime := time.Now()
 
 		// Wrapper para capturar el código de estado
@@ -115,29 +156,53 @@ func main() {
 			startTime:      startTime,
 		}
 
-		if strings.HasPrefix(r.URL.Path, "/api/") {
-			logger.Infof("PROXY", "→ API: %s %s", r.Method, r.URL.Path)
-			apiProxy.ServeHTTP(rw, r)
-			duration := time.Since(startTime)
-			logger.ProxyLog(r.Method, r.URL.Path, apiURL.String(), fmt.Sprintf("%d", rw.statusCode), duration)
-		} else if strings.HasPrefix(r.URL.Path, "/ws") {
-			logger.Infof("PROXY", "→ WebSocket: %s %s", r.Method, r.URL.Path)
-			wsProxy.ServeHTTP(rw, r)
-			duration := time.Since(startTime)
-			logger.ProxyLog(r.Method, r.URL.Path, wsURL.String(), "101", duration) // WebSocket upgrade
-		} else {
+		if maintenanceMode.Reject(rw, r) {
+			logger.ProxyLog(r.Method, r.URL.Path, "MAINTENANCE", "503", time.Since(startTime))
+			return
+		}
+
+		route := routes.match(r.URL.Path)
+		if route == nil {
 			http.NotFound(rw, r)
 			duration := time.Since(startTime)
 			logger.Warnf("PROXY", "Path not found: %s", r.URL.Path)
 			logger.ProxyLog(r.Method, r.URL.Path, "NOT_FOUND", "404", duration)
+			return
+		}
+
+		logger.Infof("PROXY", "→ %s: %s %s", route.Name, r.Method, r.URL.Path)
+		target := route.serve(rw, r)
+		status := fmt.Sprintf("%d", rw.statusCode)
+		if route.WebSocket && rw.statusCode == http.StatusOK {
+			status = "101" // WebSocket upgrade
+		}
+		upstream := "UNAVAILABLE"
+		if target != nil {
+			upstream = target.url.String()
 		}
-	}))
+		logger.ProxyLog(r.Method, r.URL.Path, upstream, status, time.Since(startTime))
+	})))
 
 	// Iniciar el servidor proxy
 	serverAddr := cfg.ProxyPort
 	logger.Successf("PROXY", "🚀 Reverse Proxy server starting on port %s with CORS enabled", serverAddr)
-	logger.Infof("PROXY", "📡 API proxy: http://localhost:%s/api/* → %s", serverAddr, apiURL)
-	logger.Infof("PROXY", "🔌 WebSocket proxy: http://localhost:%s/ws → %s", serverAddr, wsURL)
+	for _, route := range routes.routes {
+		icon := "📡"
+		if route.WebSocket {
+			icon = "🔌"
+		}
+		upstreams := make([]string, len(route.targets))
+		for i, target := range route.targets {
+			upstreams[i] = target.url.String()
+		}
+		if len(upstreams) == 1 {
+			logger.Infof("PROXY", "%s %s: http://localhost:%s%s* → %s", icon, route.Name, serverAddr, route.Prefix, upstreams[0])
+		} else {
+			logger.Infof("PROXY", "%s %s: http://localhost:%s%s* → %s (%s)", icon, route.Name, serverAddr, route.Prefix, strings.Join(upstreams, ", "), route.BalanceStrategy())
+		}
+	}
+	logger.Infof("PROXY", "❤️ Probes: http://localhost:%s/healthz, http://localhost:%s/readyz", serverAddr, serverAddr)
+	logger.Infof("PROXY", "🩺 Estado de upstreams: http://localhost:%s/proxy/status", serverAddr)
 
 	if err := http.ListenAndServe(":"+serverAddr, nil); err != nil {
 		logger.Errorf("PROXY", "Failed to start proxy server: %v", err)
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/maintenance"
+)
+
+// maintenanceAdminPath es la ruta del panel admin del servidor WebSocket que cambia su modo
+// mantenimiento.
+const maintenanceAdminPath = "/admin/api/maintenance"
+
+const maintenancePropagateTimeout = 5 * time.Second
+
+// requireBasicAuth protege next con las credenciales del panel de administración.
+func requireBasicAuth(username, password string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || username == "" ||
+			subtle.ConstantTimeCompare([]byte(user), []byte(username)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(pass), []byte(password)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="Proxy Admin"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// propagateMaintenance replica el estado en los upstreams WebSocket, para que avisen a sus
+// clientes y cierren las conexiones en el mismo momento que indica el proxy.
+func (t *routeTable) propagateMaintenance(state maintenance.State, username, password string) {
+	req := maintenance.SetRequest{Enabled: state.Enabled, Message: state.Message}
+	if state.Enabled {
+		countdown := int(time.Until(state.DrainAt).Round(time.Second).Seconds())
+		if countdown < 0 {
+			countdown = 0
+		}
+		req.CountdownSeconds = &countdown
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		logger.Errorf("PROXY", "Error serializando el modo mantenimiento: %v", err)
+		return
+	}
+
+	client := &http.Client{Timeout: maintenancePropagateTimeout}
+	for _, route := range t.routes {
+		if !route.WebSocket {
+			continue
+		}
+		for _, target := range route.targets {
+			if err := target.setMaintenance(client, body, username, password); err != nil {
+				logger.Errorf("PROXY", "No se pudo propagar el modo mantenimiento a %s: %v", target.url.Host, err)
+			}
+		}
+	}
+}
+
+func (t *upstreamTarget) setMaintenance(client *http.Client, body []byte, username, password string) error {
+	_, err := t.postAdmin(client, maintenanceAdminPath, body, username, password)
+	return err
+}
+
+// postAdmin envía body a la ruta path del panel admin del upstream con las credenciales del
+// panel y devuelve la respuesta. Un status distinto de 200 es un error.
+func (t *upstreamTarget) postAdmin(client *http.Client, path string, body []byte, username, password string) ([]byte, error) {
+	u := url.URL{Scheme: "http", Host: t.url.Host, Path: path}
+	if t.url.Scheme == "https" || t.url.Scheme == "wss" {
+		u.Scheme = "https"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), maintenancePropagateTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(username, password)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s respondió %d", u.String(), resp.StatusCode)
+	}
+	return respBody, nil
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/koding/websocketproxy"
+)
+
+// proxyRoute es una ruta de la tabla ya preparada para atender peticiones.
+type proxyRoute struct {
+	config.ProxyRoute
+	targets  []*upstreamTarget
+	balancer balancer
+}
+
+// upstreamTarget es una instancia concreta de la ruta, con su propio proxy y circuit breaker.
+type upstreamTarget struct {
+	route   *proxyRoute
+	url     *url.URL
+	handler http.Handler
+	breaker *circuitBreaker
+	// active cuenta las peticiones HTTP en curso y las conexiones WebSocket abiertas.
+	active int64
+	// draining marca una instancia WebSocket que se está drenando (ver drain.go): no recibe
+	// conexiones nuevas aunque su circuito esté cerrado.
+	draining atomic.Bool
+}
+
+// Active devuelve el número de peticiones o conexiones en curso hacia el upstream.
+func (t *upstreamTarget) Active() int64 {
+	return atomic.LoadInt64(&t.active)
+}
+
+// Available indica si el balancer puede elegir el upstream.
+func (t *upstreamTarget) Available() bool {
+	return t.breaker.Allow() && !t.draining.Load()
+}
+
+// routeTable resuelve cada petición a la ruta con el prefijo más largo que coincida.
+type routeTable struct {
+	routes []*proxyRoute
+}
+
+// newRouteTable construye los proxies de cada upstream de las rutas configuradas.
+// jwtSecret se usa para obtener el usuario en las rutas con balance por hash.
+func newRouteTable(routes []config.ProxyRoute, jwtSecret []byte) (*routeTable, error) {
+	table := &routeTable{}
+	for _, rc := range routes {
+		route := &proxyRoute{ProxyRoute: rc}
+		for _, rawURL := range rc.UpstreamURLs() {
+			upstream, err := url.Parse(rawURL)
+			if err != nil {
+				return nil, fmt.Errorf("invalid upstream for route %s: %w", rc.Name, err)
+			}
+
+			target := &upstreamTarget{route: route, url: upstream}
+			target.breaker = newCircuitBreaker(target)
+			if rc.WebSocket {
+				target.handler = newWebSocketProxy(target)
+			} else {
+				target.handler = newHTTPProxy(target)
+			}
+			route.targets = append(route.targets, target)
+		}
+		route.balancer = newBalancer(rc.BalanceStrategy(), route.targets, jwtSecret)
+		table.routes = append(table.routes, route)
+	}
+
+	sort.SliceStable(table.routes, func(i, j int) bool {
+		return len(table.routes[i].Prefix) > len(table.routes[j].Prefix)
+	})
+	return table, nil
+}
+
+// match devuelve la ruta que atiende el path o nil si ninguna coincide.
+func (t *routeTable) match(path string) *proxyRoute {
+	for _, route := range t.routes {
+		if strings.HasPrefix(path, route.Prefix) {
+			return route
+		}
+	}
+	return nil
+}
+
+// serve reenvía la petición al upstream elegido por el balancer, aplicando el timeout
+// de la ruta, y devuelve ese upstream. Si ninguno está disponible responde 503
+// inmediatamente sin contactar a ninguno y devuelve nil.
+func (r *proxyRoute) serve(w http.ResponseWriter, req *http.Request) *upstreamTarget {
+	target := r.balancer.pick(req, r.targets)
+	if target == nil {
+		w.Header().Set("Retry-After", openRetryAfter)
+		http.Error(w, fmt.Sprintf("Servicio %s no disponible temporalmente", r.Name), http.StatusServiceUnavailable)
+		return nil
+	}
+	if r.Timeout > 0 && !r.WebSocket {
+		ctx, cancel := context.WithTimeout(req.Context(), r.Timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	// En WebSocket el handler no retorna hasta que se cierra la conexión, así que el
+	// contador refleja las conexiones abiertas.
+	atomic.AddInt64(&target.active, 1)
+	defer atomic.AddInt64(&target.active, -1)
+	target.handler.ServeHTTP(w, req)
+	return target
+}
+
+// HealthCheck adapta la ruta a una comprobación de readiness: está lista mientras
+// alguno de sus upstreams tenga el circuito cerrado.
+func (r *proxyRoute) HealthCheck(ctx context.Context) error {
+	var lastErr error
+	for _, target := range r.targets {
+		err := target.breaker.HealthCheck(ctx)
+		if err == nil {
+			return nil
+		}
+		lastErr = fmt.Errorf("%s: %w", target.url, err)
+	}
+	return lastErr
+}
+
+// upstreamPath calcula el path que recibe el upstream, quitando el prefijo si la ruta lo indica.
+func (t *upstreamTarget) upstreamPath(path string) string {
+	if t.route.StripPrefix {
+		path = strings.TrimPrefix(path, strings.TrimSuffix(t.route.Prefix, "/"))
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+	}
+	return singleJoiningSlash(t.url.Path, path)
+}
+
+func newHTTPProxy(target *upstreamTarget) *httputil.ReverseProxy {
+	route := target.route
+	proxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = target.url.Scheme
+			req.URL.Host = target.url.Host
+			req.URL.Path = target.upstreamPath(req.URL.Path)
+			req.URL.RawPath = ""
+			req.Host = target.url.Host
+			logger.Infof("PROXY_DIRECTOR", "Authorization Header: %s", req.Header.Get("Authorization"))
+		},
+		Transport: &retryTransport{
+			next:    http.DefaultTransport,
+			retries: route.Retries,
+			backoff: route.RetryBackoff,
+		},
+	}
+	proxy.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+		if req.Context().Err() == context.Canceled {
+			// El cliente cerró la conexión: no es un fallo del upstream.
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		status := http.StatusBadGateway
+		if req.Context().Err() == context.DeadlineExceeded {
+			status = http.StatusGatewayTimeout
+		}
+		target.breaker.recordFailure(err)
+		logger.Errorf("PROXY", "Error en la ruta %s hacia %s: %v", route.Name, target.url, err)
+		w.WriteHeader(status)
+	}
+	return proxy
+}
+
+func newWebSocketProxy(target *upstreamTarget) *websocketproxy.WebsocketProxy {
+	return &websocketproxy.WebsocketProxy{
+		Backend: func(req *http.Request) *url.URL {
+			u := *target.url
+			u.Path = target.upstreamPath(req.URL.Path)
+			u.RawQuery = req.URL.RawQuery
+			return &u
+		},
+	}
+}
+
+// retryTransport reintenta las peticiones idempotentes que fallan antes de obtener respuesta
+// (p.ej. conexión rechazada durante un reinicio del upstream), con backoff exponencial.
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+	backoff time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if !isRetryable(req) {
+		return resp, err
+	}
+
+	wait := t.backoff
+	for attempt := 1; err != nil && attempt <= t.retries; attempt++ {
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+		logger.Warnf("PROXY", "Reintento %d/%d de %s %s: %v", attempt, t.retries, req.Method, req.URL.Path, err)
+		resp, err = t.next.RoundTrip(req)
+		wait *= 2
+	}
+	return resp, err
+}
+
+// isRetryable indica si la petición puede repetirse sin efectos secundarios.
+func isRetryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return req.Body == nil || req.Body == http.NoBody
+	}
+	return false
+}
+
+func singleJoiningSlash(a, b string) string {
+	aslash := strings.HasSuffix(a, "/")
+	bslash := strings.HasPrefix(b, "/")
+	switch {
+	case aslash && bslash:
+		return a + b[1:]
+	case !aslash && !bslash:
+		return a + "/" + b
+	}
+	return a + b
+}
+
+// healthURL devuelve el endpoint de salud del upstream (por HTTP también para WebSocket).
+func (t *upstreamTarget) healthURL() string {
+	u := url.URL{Scheme: "http", Host: t.url.Host, Path: t.route.HealthPath}
+	if t.url.Scheme == "https" || t.url.Scheme == "wss" {
+		u.Scheme = "https"
+	}
+	return u.String()
+}
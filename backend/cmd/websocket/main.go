@@ -7,12 +7,15 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/config"
 	"github.com/davidM20/micro-service-backend-go.git/internal/db"
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	apiservices "github.com/davidM20/micro-service-backend-go.git/internal/services"
 	internalWs "github.com/davidM20/micro-service-backend-go.git/internal/websocket"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/admin"
 	wsauth "github.com/davidM20/micro-service-backend-go.git/internal/websocket/auth"
@@ -21,11 +24,19 @@ import (
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/handoff"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/health"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/maintenance"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/scheduler"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/timefmt"
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	// Todas las fechas del proceso en UTC (ver docs/fechas.md)
+	timefmt.UseUTC()
+
 	// Cargar .env (opcional)
 	err := godotenv.Load()
 	if err != nil {
@@ -37,14 +48,23 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	cfg.LogReport("CONFIG")
 
 	// Conectar a la base de datos
-	dbConn, err := db.Connect(cfg.DatabaseDSN)
+	poolConfig := db.NewPoolConfig(cfg)
+	dbConn, err := db.Connect(cfg.DatabaseDSN, poolConfig)
 	if err != nil {
 		logger.Errorf("MAIN", "Failed to connect to database: %v", err)
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer dbConn.Close()
+	db.SetSlowQueryThreshold(cfg.DBSlowQueryThreshold)
+	// Modo solo lectura ante caídas de la BD o retraso de la réplica (docs/modo_solo_lectura.md)
+	readOnlyBreaker, err := db.NewReadOnlyBreaker(dbConn, db.NewBreakerConfig(cfg))
+	if err != nil {
+		log.Fatalf("Failed to initialize read-only mode: %v", err)
+	}
+	go readOnlyBreaker.Run(context.Background())
 
 	if err := db.InitializeDatabase(dbConn); err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
@@ -52,15 +72,18 @@ func main() {
 	log.Println("Database initialized successfully.")
 
 	// Inicializar servicios que dependen de la BD
-	services.InitializeChatService(dbConn)
-	services.InitializeNotificationService(dbConn)
+	services.InitializeChatService(dbConn, cfg.ChatSyncRetention)
+	services.InitializeNotificationService(dbConn, cfg.NotificationGroupWindow)
 	services.InitializeProfileService(dbConn)
 	queries.InitDB(dbConn)
+	queries.InitCache(cfg)
 
-	// Inicializar FeedService y FeedHandler
-	feedSvc := services.NewFeedService(dbConn) // Crear y asignar la instancia
-	handlers.InitializeFeedHandler(feedSvc)    // Pasar la instancia al inicializador del handler
-	logger.Info("MAIN", "FeedService y FeedHandler inicializados.")
+	// Servicios que usan los handlers (chat, CV, feed, notificaciones)
+	handlers.SetDependencies(handlers.NewDependencies(dbConn))
+	logger.Info("MAIN", "Dependencias de los handlers inicializadas.")
+
+	// Reseñas: se comparte el servicio de la API REST para aplicar las mismas validaciones
+	handlers.InitializeReputationHandler(apiservices.NewReputationService(dbConn))
 
 	// Configurar el paquete customws
 	wsConfig := types.DefaultConfig()
@@ -68,17 +91,36 @@ func main() {
 	wsConfig.WriteWait = 15 * time.Second
 	wsConfig.PongWait = 60 * time.Second
 	wsConfig.PingPeriod = (wsConfig.PongWait * 9) / 10
-	wsConfig.MaxMessageSize = 4096
+	wsConfig.MaxMessageSize = cfg.WsMaxMessageSize
+	wsConfig.ChunkTimeout = cfg.WsChunkTimeout
+	wsConfig.MaxPendingChunks = cfg.WsMaxPendingChunks
+	wsConfig.IdleTimeout = cfg.WsIdleTimeout
+	wsConfig.IdleWarning = cfg.WsIdleWarning
+	wsConfig.HeartbeatInterval = cfg.WsHeartbeatInterval
+	wsConfig.AuthTimeout = cfg.WsAuthTimeout
+	wsConfig.MessageSizeLimits, err = customws.ParseMessageSizeLimits(cfg.WsMessageSizeLimits)
+	if err != nil {
+		log.Fatalf("Invalid WS_MESSAGE_SIZE_LIMITS: %v", err)
+	}
 	wsConfig.SendChannelBuffer = 256
-	wsConfig.AckTimeout = 10 * time.Second
+	wsConfig.AckTimeout = cfg.WsAckTimeout
+	wsConfig.AckMaxAttempts = cfg.WsAckMaxAttempts
+	wsConfig.AckBackoff = cfg.WsAckBackoff
+	wsConfig.AckMaxBackoff = cfg.WsAckMaxBackoff
 	wsConfig.RequestTimeout = 20 * time.Second
 
+	// Una regla de permisos sin handler no restringiría nada
+	if err := internalWs.ValidateMessagePermissions(); err != nil {
+		log.Fatalf("Invalid WebSocket permissions: %v", err)
+	}
+
 	// Inicializar el autenticador para WebSocket
 	wsAuthenticator := wsauth.NewAuthenticator(dbConn, cfg)
 
 	// Configurar callbacks
 	callbacks := customws.Callbacks[wsmodels.WsUserData]{
 		AuthenticateAndGetUserData: wsAuthenticator.AuthenticateAndGetUserData,
+		AuthenticateMessage:        wsAuthenticator.AuthenticateMessage,
 		OnConnect: func(conn *customws.Connection[wsmodels.WsUserData]) error {
 			log.Printf("User connected: ID %d, Username %s", conn.ID, conn.UserData.Username)
 			// Llamar a OnConnect de callbacks.go
@@ -88,37 +130,169 @@ func main() {
 			// Llamar a OnDisconnect de callbacks.go
 			internalWs.OnDisconnect(conn, err)
 		},
-		ProcessClientMessage: internalWs.ProcessClientMessage,
-		GeneratePID: func() string { // Opcional: custom PID generation
-			// return uuid.NewString()
-			return "server-msg-" + time.Now().Format("20060102150405.000000")
-		},
+		ProcessClientMessage:  internalWs.ProcessClientMessage,
+		AuthorizeSubscription: internalWs.AuthorizeSubscription,
+		OnPanic:               internalWs.OnPanic,
+		OnDeadLetter:          internalWs.OnDeadLetter,
+		GeneratePID:           internalWs.GeneratePID,
 	}
 
 	// Crear el ConnectionManager
 	connManager := customws.NewConnectionManager(wsConfig, callbacks)
 
 	// Inicializar PresenceService después de crear el ConnectionManager
-	services.InitializePresenceService(dbConn, connManager)
+	services.InitializePresenceService(dbConn, connManager, cfg)
+
+	// Scheduler de trabajos periódicos en segundo plano
+	jobScheduler := scheduler.New()
 
-	// Inicializar sistema de administración
-	adminUser := os.Getenv("ADMIN_USERNAME")
-	adminPass := os.Getenv("ADMIN_PASSWORD")
-	if adminUser == "" {
-		adminUser = "admin" // valor por defecto
+	// Muestreo periódico de las estadísticas del pool (DB_STATS_INTERVAL), visible en el panel admin
+	poolMonitor := db.NewPoolMonitor(dbConn, poolConfig)
+	if interval := poolMonitor.Interval(); interval > 0 {
+		if err := jobScheduler.Register("db-pool-stats", "@every "+interval.String(), poolMonitor.Collect, scheduler.WithQuiet()); err != nil {
+			logger.Errorf("MAIN", "No se pudo registrar el muestreo del pool de BD: %v", err)
+		}
 	}
-	if adminPass == "" {
-		adminPass = "admin123" // valor por defecto
+
+	// Heartbeat de presencia y reaper de usuarios que quedaron online tras una caída
+	if interval := cfg.PresenceHeartbeatInterval; interval > 0 {
+		if err := jobScheduler.Register("presence-heartbeat", "@every "+interval.String(), services.PresenceHeartbeat, scheduler.WithQuiet()); err != nil {
+			logger.Errorf("MAIN", "No se pudo registrar el heartbeat de presencia: %v", err)
+		}
+		if err := jobScheduler.Register("presence-reaper", "@every "+interval.String(), services.ReapStalePresence, scheduler.WithQuiet()); err != nil {
+			logger.Errorf("MAIN", "No se pudo registrar el reaper de presencia: %v", err)
+		}
 	}
 
-	adminHandler := admin.InitializeAdmin(connManager, dbConn, adminUser, adminPass)
-	logger.Infof("MAIN", "Sistema de administración inicializado - Usuario: %s", adminUser)
+	// Comentarios nuevos de publicaciones para los suscriptores de event:{id}:comments
+	commentPublisher := services.NewCommentPublisher(connManager)
+	if err := jobScheduler.Register("comment-push", "@every 2s", commentPublisher.Publish, scheduler.WithQuiet()); err != nil {
+		logger.Errorf("MAIN", "No se pudo registrar el envío de comentarios por WebSocket: %v", err)
+	}
+
+	// Notificaciones de moderación y desconexión de cuentas suspendidas
+	moderationPublisher := services.NewModerationPublisher(connManager)
+	if err := jobScheduler.Register("moderation-push", "@every 2s", moderationPublisher.Publish, scheduler.WithQuiet()); err != nil {
+		logger.Errorf("MAIN", "No se pudo registrar el envío de notificaciones de moderación: %v", err)
+	}
+
+	// Filtro de contenido del chat: las reglas se editan desde la API REST y se recargan aquí
+	services.InitializeContentFilter(cfg)
+	if interval := cfg.ContentFilterReloadInterval; interval > 0 {
+		if err := jobScheduler.Register("content-filter-reload", "@every "+interval.String(), services.ReloadContentFilter, scheduler.WithQuiet()); err != nil {
+			logger.Errorf("MAIN", "No se pudo registrar la recarga del filtro de contenido: %v", err)
+		}
+	}
+
+	// Notificaciones push (FCM/APNs) para los usuarios sin conexión
+	services.InitializePushService(cfg)
+	pushPublisher := services.NewPushPublisher(connManager)
+	if err := jobScheduler.Register("push-notifications", "@every 2s", pushPublisher.Publish, scheduler.WithQuiet()); err != nil {
+		logger.Errorf("MAIN", "No se pudo registrar el envío de notificaciones push: %v", err)
+	}
+
+	// Resumen por correo de notificaciones y mensajes sin leer
+	if digestJob := services.NewEmailDigestJob(cfg, connManager); digestJob != nil {
+		if err := jobScheduler.Register("email-digest", "@every "+cfg.EmailDigestInterval.String(), digestJob.Run); err != nil {
+			logger.Errorf("MAIN", "No se pudo registrar el resumen por correo: %v", err)
+		}
+	}
+
+	// Resumen de lo silenciado durante el horario de No molestar de cada usuario
+	if dndJob := services.NewDndSummaryJob(cfg, connManager); dndJob != nil {
+		if err := jobScheduler.Register("dnd-summary", "@every "+cfg.DndSummaryInterval.String(), dndJob.Run, scheduler.WithQuiet()); err != nil {
+			logger.Errorf("MAIN", "No se pudo registrar el resumen de No molestar: %v", err)
+		}
+	}
+
+	// Alertas de búsquedas guardadas
+	if alertJob := services.NewSavedSearchAlertJob(cfg); alertJob != nil {
+		if err := jobScheduler.Register("saved-search-alerts", "@every "+cfg.SavedSearchAlertInterval.String(), alertJob.Run); err != nil {
+			logger.Errorf("MAIN", "No se pudo registrar las alertas de búsquedas guardadas: %v", err)
+		}
+	}
+
+	// Expiración de las notificaciones leídas antiguas, con retención por tipo
+	expiryJob, err := services.NewNotificationExpiryJob(cfg)
+	if err != nil {
+		log.Fatalf("Invalid NOTIFICATION_RETENTION_BY_TYPE: %v", err)
+	}
+	if expiryJob != nil {
+		if err := jobScheduler.Register("notification-expiry", "@every "+cfg.NotificationRetentionInterval.String(), expiryJob.Run); err != nil {
+			logger.Errorf("MAIN", "No se pudo registrar la expiración de notificaciones: %v", err)
+		}
+	}
+
+	// Outbox: mensajes en tiempo real que la API deja en la base de datos (ver docs/outbox.md)
+	if interval := cfg.OutboxPollInterval; interval > 0 {
+		outboxDispatcher := services.NewOutboxDispatcher(connManager, cfg.OutboxBatchSize)
+		if err := jobScheduler.Register("outbox-dispatch", "@every "+interval.String(), outboxDispatcher.Dispatch, scheduler.WithQuiet()); err != nil {
+			logger.Errorf("MAIN", "No se pudo registrar el envío del outbox: %v", err)
+		}
+	}
+	if cfg.OutboxRetention > 0 {
+		if err := jobScheduler.Register("outbox-cleanup", "@hourly", services.CleanupOutbox(cfg.OutboxRetention)); err != nil {
+			logger.Errorf("MAIN", "No se pudo registrar la limpieza del outbox: %v", err)
+		}
+	}
+
+	// Mensajes críticos sin entregar a la espera de que el usuario se conecte (ver docs/mensajes_criticos.md)
+	if cfg.DeadLetterRetention > 0 {
+		if err := jobScheduler.Register("dead-letter-cleanup", "@hourly", services.CleanupDeadLetters(cfg.DeadLetterRetention)); err != nil {
+			logger.Errorf("MAIN", "No se pudo registrar la limpieza de los mensajes no entregados: %v", err)
+		}
+	}
+
+	// Cambios de la lista de chats para la sincronización incremental (ver docs/sincronizacion_chats.md)
+	if cfg.ChatSyncRetention > 0 {
+		if err := jobScheduler.Register("chat-sync-cleanup", "@hourly", services.CleanupChatSync(cfg.ChatSyncRetention)); err != nil {
+			logger.Errorf("MAIN", "No se pudo registrar la limpieza de los cambios de chats: %v", err)
+		}
+	}
+
+	// Conciliación de los contadores de mensajes no leídos (ver docs/contadores_no_leidos.md)
+	if interval := cfg.ChatUnreadReconcileInterval; interval > 0 {
+		if err := jobScheduler.Register("chat-unread-reconcile", "@every "+interval.String(), services.ReconcileChatUnread); err != nil {
+			logger.Errorf("MAIN", "No se pudo registrar la conciliación de mensajes no leídos: %v", err)
+		}
+	}
+
+	// Anuncios del sistema: envío de los programados y expiración
+	announcementService := services.NewAnnouncementService(connManager)
+	if err := jobScheduler.Register("system-announcements", "@every 10s", announcementService.Run, scheduler.WithQuiet()); err != nil {
+		logger.Errorf("MAIN", "No se pudo registrar el envío de anuncios del sistema: %v", err)
+	}
+
+	// Modo mantenimiento: avisa a los conectados con una cuenta atrás, cierra las conexiones y
+	// rechaza las nuevas hasta que se desactive (ver docs/mantenimiento.md)
+	maintenanceMode := maintenance.New(maintenance.Options{
+		Enabled:     cfg.MaintenanceMode,
+		Message:     cfg.MaintenanceMessage,
+		RetryAfter:  cfg.MaintenanceRetryAfter,
+		Countdown:   cfg.MaintenanceCountdown,
+		ExemptPaths: strings.Split(cfg.MaintenanceExemptPaths, ","),
+	})
+	maintenanceMode.OnChange(services.NewMaintenanceDrainer(connManager).Handle)
+
+	// Drenaje de esta instancia para reiniciarla sin que sus clientes reconecten a la vez
+	// (ver docs/drenaje_conexiones.md)
+	instanceID := cfg.WsInstanceID
+	if instanceID == "" {
+		hostname, _ := os.Hostname()
+		instanceID = hostname + ":" + cfg.WsPort
+	}
+	drainer := services.NewConnectionDrainer(connManager, instanceID, cfg.WsDrainSpread, cfg.WsDrainRetryAfter)
+
+	adminHandler := admin.InitializeAdmin(connManager, dbConn, poolMonitor, jobScheduler, announcementService, maintenanceMode, drainer, cfg.AdminUsername, cfg.AdminPassword)
+	logger.Infof("MAIN", "Sistema de administración inicializado - Usuario: %s", cfg.AdminUsername)
+	// Las consultas medidas por el driver se muestran en el panel admin
+	db.SetQueryRecorder(admin.GetCollector())
 
 	// Configurar rutas HTTP
 	mux := http.NewServeMux()
 
 	// Ruta principal de WebSocket
-	mux.HandleFunc("/ws", connManager.ServeHTTP)
+	mux.HandleFunc("/ws", maintenanceMode.Middleware(drainer.Middleware(connManager.ServeHTTP)))
 
 	// Ruta de health check
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -127,34 +301,83 @@ func main() {
 		fmt.Fprintf(w, `{"status":"ok","timestamp":%d}`, time.Now().Unix())
 	})
 
+	// Probes de liveness y readiness (esta última verifica la conexión a la BD, reporta el modo
+	// solo lectura como "degraded" y falla mientras la instancia drena sus conexiones)
+	healthChecker := health.NewChecker("websocket")
+	healthChecker.Register("database", health.DBCheck(dbConn))
+	if readOnlyBreaker != nil {
+		healthChecker.Register("database_writes", readOnlyBreaker.Ready)
+	}
+	healthChecker.Register("draining", drainer.Ready)
+	mux.HandleFunc("/healthz", healthChecker.LivenessHandler())
+	mux.HandleFunc("/readyz", healthChecker.ReadinessHandler())
+
 	// Registrar rutas administrativas
 	adminHandler.RegisterAdminRoutes(mux)
 
 	serverAddr := cfg.WsPort
-	if serverAddr == "" {
-		serverAddr = "8082" // Puerto por defecto si no está en la configuración
-	}
 
 	srv := &http.Server{
 		Addr:         ":" + serverAddr,
-		Handler:      mux,
+		Handler:      middleware.Recovery(mux),
 		ReadTimeout:  wsConfig.WriteWait + (5 * time.Second), // Un poco más que el WriteWait de WS
 		WriteTimeout: wsConfig.WriteWait + (5 * time.Second),
 		IdleTimeout:  wsConfig.PongWait + (10 * time.Second), // Un poco más que el PongWait
 	}
 
+	jobScheduler.Start(context.Background())
+
+	// El socket se hereda del proceso anterior si este se arrancó con un reinicio sin cortes
+	// (ver docs/despliegue_sin_cortes.md)
+	listener, inherited, err := handoff.Listen(srv.Addr)
+	if err != nil {
+		log.Fatalf("Could not listen on %s: %v", serverAddr, err)
+	}
 	go func() {
-		log.Printf("WebSocket Server (using customws) starting on port %s...", serverAddr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Could not listen on %s: %v", serverAddr, err)
+		log.Printf("WebSocket Server (using customws) starting on port %s (inherited listener: %t)...", serverAddr, inherited)
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Could not serve on %s: %v", serverAddr, err)
 		}
 	}()
+	if err := handoff.Ready(); err != nil {
+		log.Printf("Could not notify the previous process: %v", err)
+	}
 
-	// Manejo de cierre ordenado
+	// Manejo de cierre ordenado. SIGUSR2 arranca un proceso nuevo sobre el mismo socket; si
+	// arranca bien, este deja de aceptar conexiones y drena las suyas.
 	stopChan := make(chan os.Signal, 1)
 	signal.Notify(stopChan, syscall.SIGINT, syscall.SIGTERM)
+	upgradeChan := make(chan os.Signal, 1)
+	handoff.Notify(upgradeChan)
 
-	<-stopChan // Esperar señal
+	handedOff := false
+waitForSignal:
+	for {
+		select {
+		case <-stopChan:
+			break waitForSignal
+		case <-upgradeChan:
+			process, err := handoff.Upgrade(listener, cfg.WsHandoffReadyTimeout)
+			if err != nil {
+				log.Printf("Zero-downtime restart aborted, still serving: %v", err)
+				continue
+			}
+			log.Printf("New process (PID %d) is serving, draining this one...", process.Pid)
+			handedOff = true
+			break waitForSignal
+		}
+	}
+
+	if handedOff {
+		// Dejar de aceptar conexiones antes de repartir el cierre de las actuales; los clientes
+		// reconectan al proceso nuevo.
+		drainCtx, cancelDrain := context.WithTimeout(context.Background(), cfg.WsHandoffDrain+5*time.Second)
+		if err := srv.Shutdown(drainCtx); err != nil {
+			log.Printf("HTTP server shutdown error: %v", err)
+		}
+		log.Printf("Drained %d connections.", connManager.Drain(drainCtx, cfg.WsHandoffDrain))
+		cancelDrain()
+	}
 
 	log.Println("Shutting down server...")
 
@@ -167,10 +390,16 @@ func main() {
 		log.Println("CustomWS ConnectionManager shutdown complete.")
 	}
 
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		log.Printf("HTTP server shutdown error: %v", err)
-	} else {
-		log.Println("HTTP server shutdown complete.")
+	if !handedOff { // Tras el traspaso el servidor HTTP ya está cerrado
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("HTTP server shutdown error: %v", err)
+		} else {
+			log.Println("HTTP server shutdown complete.")
+		}
+	}
+
+	if err := jobScheduler.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Scheduler shutdown error: %v", err)
 	}
 
 	log.Println("Server gracefully stopped.")
@@ -29,7 +29,7 @@ mkdir -p bin
 
 # Compilar herramienta de desarrollo
 echo -e "${PURPLE}🔨 Compilando herramienta de desarrollo...${NC}"
-go build -o ./bin/devtools ./cmd/devtools/main.go
+go build -o ./bin/devtools ./cmd/devtools
 
 if [ $? -eq 0 ]; then
     echo -e "${GREEN}✅ Herramienta compilada exitosamente${NC}"
@@ -37,7 +37,7 @@ if [ $? -eq 0 ]; then
     
     # Ejecutar herramienta
     echo -e "${CYAN}🚀 Iniciando servicios...${NC}"
-    ./bin/devtools
+    ./bin/devtools "$@"
 else
     echo -e "${RED}❌ Error compilando herramienta de desarrollo${NC}"
     exit 1
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"time"
@@ -17,21 +18,33 @@ const UserIDKey ContextKey = "userID"
 // ClaimsKey es la clave para almacenar el objeto de claims completo en el contexto.
 const ClaimsKey ContextKey = "claims"
 
+// ImpersonatorKey es la clave para almacenar en el contexto el ID del administrador que
+// suplanta al usuario autenticado.
+const ImpersonatorKey ContextKey = "impersonatorID"
+
 // Claims define la estructura de los claims del JWT
 type Claims struct {
 	UserID int64 `json:"userId"`
 	RoleID int64 `json:"roleId"`
+	// ImpersonatorID es el administrador que usa el token en nombre del usuario; 0 en los
+	// tokens normales. En los tokens de suplantación el jti es el ID de la Impersonation.
+	ImpersonatorID int64 `json:"impersonatorId,omitempty"`
+	// TenantID es la institución de la cuenta al iniciar sesión (ver docs/multi_institucion.md); 0
+	// si no tiene. Como RoleID, la API usa la actual de la cuenta y este valor solo si no la
+	// puede consultar.
+	TenantID int64 `json:"tid,omitempty"`
 	jwt.RegisteredClaims
 	ID string `json:"jti"` // "jti" (JWT ID) claim; ver RFC 7519, sección 4.1.7
 }
 
 // GenerateJWT genera un nuevo token JWT para un usuario.
-func GenerateJWT(userID int64, roleID int64, secretKey []byte, expirationTime time.Duration) (string, int, error) {
+func GenerateJWT(userID int64, roleID int64, tenantID int64, secretKey []byte, expirationTime time.Duration) (string, int, error) {
 	expiration := time.Now().Add(expirationTime)
 	tokenID := 1 // Usar 1 como ID de token por defecto.
 	claims := &Claims{
-		UserID: userID,
-		RoleID: roleID,
+		UserID:   userID,
+		RoleID:   roleID,
+		TenantID: tenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiration),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -51,6 +64,45 @@ func GenerateJWT(userID int64, roleID int64, secretKey []byte, expirationTime ti
 	return tokenString, tokenID, nil
 }
 
+// GenerateImpersonationJWT genera el token con el que un administrador actúa como otro
+// usuario. impersonationID identifica la suplantación y permite revocarla antes de que expire.
+func GenerateImpersonationJWT(userID, roleID, impersonatorID, impersonationID int64, secretKey []byte, expiration time.Time) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:         userID,
+		RoleID:         roleID,
+		ImpersonatorID: impersonatorID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiration),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "backend-connect",
+			Subject:   fmt.Sprintf("%d", userID),
+		},
+		// El campo ID de Claims oculta al de RegisteredClaims al serializar el claim "jti".
+		ID: strconv.FormatInt(impersonationID, 10),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(secretKey)
+	if err != nil {
+		return "", fmt.Errorf("error signing token: %w", err)
+	}
+	return tokenString, nil
+}
+
+// ImpersonationID devuelve el ID de la suplantación de un token de suplantación.
+func (c *Claims) ImpersonationID() (int64, error) {
+	return strconv.ParseInt(c.ID, 10, 64)
+}
+
+// ImpersonatorFromContext devuelve el ID del administrador que suplanta al usuario de la
+// petición, o 0 si la petición no usa un token de suplantación.
+func ImpersonatorFromContext(ctx context.Context) int64 {
+	id, _ := ctx.Value(ImpersonatorKey).(int64)
+	return id
+}
+
 // ValidateJWT valida un token JWT y devuelve los claims si es válido.
 func ValidateJWT(tokenString string, secretKey []byte) (*Claims, error) {
 	claims := &Claims{}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const passwordPolicyComponent = "PASSWORD_POLICY"
+
+// pwnedRangeURL es el endpoint de rangos de HaveIBeenPwned. Solo recibe los 5 primeros
+// caracteres del SHA-1 de la contraseña (k-anonimato).
+const pwnedRangeURL = "https://api.pwnedpasswords.com/range/"
+
+//go:embed common_passwords.txt
+var commonPasswordsList string
+
+// commonPasswords contiene la lista embebida de contraseñas prohibidas, en minúsculas.
+var commonPasswords = parseCommonPasswords(commonPasswordsList)
+
+// PasswordPolicyError agrupa todas las reglas que incumple una contraseña.
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "la contraseña no cumple la política: " + strings.Join(e.Violations, "; ")
+}
+
+// PasswordPolicy valida contraseñas nuevas antes de guardarlas.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// BreachCheck activa la consulta a HaveIBeenPwned. Si el servicio no responde la
+	// contraseña se acepta: una caída externa no debe bloquear registros.
+	BreachCheck bool
+	HTTPClient  *http.Client
+}
+
+// NewPasswordPolicy crea una política con las reglas indicadas. breachTimeout limita la
+// consulta a HaveIBeenPwned cuando breachCheck está activo.
+func NewPasswordPolicy(minLength int, requireUpper, requireLower, requireDigit, requireSymbol, breachCheck bool, breachTimeout time.Duration) *PasswordPolicy {
+	if breachTimeout <= 0 {
+		breachTimeout = 3 * time.Second
+	}
+	return &PasswordPolicy{
+		MinLength:     minLength,
+		RequireUpper:  requireUpper,
+		RequireLower:  requireLower,
+		RequireDigit:  requireDigit,
+		RequireSymbol: requireSymbol,
+		BreachCheck:   breachCheck,
+		HTTPClient:    &http.Client{Timeout: breachTimeout},
+	}
+}
+
+// Validate comprueba la contraseña contra la política. userInputs son datos del propio
+// usuario (email, nombre de usuario...) que no pueden formar parte de la contraseña.
+// Devuelve un *PasswordPolicyError con todas las reglas incumplidas, o nil.
+func (p *PasswordPolicy) Validate(ctx context.Context, password string, userInputs ...string) error {
+	var violations []string
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, fmt.Sprintf("debe tener al menos %d caracteres", p.MinLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, "debe incluir una letra mayúscula")
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, "debe incluir una letra minúscula")
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, "debe incluir un número")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, "debe incluir un símbolo")
+	}
+
+	lower := strings.ToLower(password)
+	if _, banned := commonPasswords[lower]; banned {
+		violations = append(violations, "es una contraseña demasiado común")
+	}
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if at := strings.Index(input, "@"); at > 0 {
+			input = input[:at]
+		}
+		if len(input) >= 4 && strings.Contains(lower, input) {
+			violations = append(violations, "no puede contener tu email ni tu nombre de usuario")
+			break
+		}
+	}
+
+	// La consulta externa solo merece la pena si la contraseña supera las reglas locales.
+	if len(violations) == 0 && p.BreachCheck {
+		breached, err := p.isBreached(ctx, password)
+		if err != nil {
+			logger.Warnf(passwordPolicyComponent, "No se pudo consultar HaveIBeenPwned, se omite la comprobación: %v", err)
+		} else if breached {
+			violations = append(violations, "aparece en filtraciones de datos conocidas")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// isBreached consulta el rango de HaveIBeenPwned correspondiente al prefijo del SHA-1
+// de la contraseña y busca el sufijo en la respuesta.
+func (p *PasswordPolicy) isBreached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pwnedRangeURL+prefix, nil)
+	if err != nil {
+		return false, err
+	}
+	// El padding evita que el tamaño de la respuesta revele el prefijo consultado.
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("respuesta inesperada de HaveIBeenPwned: %s", resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		candidate, count, found := strings.Cut(scanner.Text(), ":")
+		if !found || candidate != suffix {
+			continue
+		}
+		// Las entradas de padding tienen recuento 0.
+		return strings.TrimSpace(count) != "0", nil
+	}
+	return false, scanner.Err()
+}
+
+// parseCommonPasswords convierte la lista embebida en un conjunto, ignorando
+// comentarios y líneas vacías.
+func parseCommonPasswords(list string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[strings.ToLower(line)] = struct{}{}
+	}
+	return set
+}
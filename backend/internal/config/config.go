@@ -2,98 +2,599 @@ package config
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/fieldcrypt"
 	"github.com/spf13/viper" // Usaremos viper para facilitar la gestión de config
 )
 
 // Config holds the application configuration
+//
+// Los campos con la etiqueta secret se muestran ocultos en el informe de arranque (ver Report).
 type Config struct {
-	DatabaseDSN string `mapstructure:"DB_DSN"`
+	// Archivo YAML opcional con valores de configuración (ver LoadConfig)
+	ConfigFile  string `mapstructure:"CONFIG_FILE"`
+	Environment string `mapstructure:"ENVIRONMENT"` // development, staging o production
+	DatabaseDSN string `mapstructure:"DB_DSN" secret:"dsn"`
 	ApiPort     string `mapstructure:"API_PORT"`
 	WsPort      string `mapstructure:"WS_PORT"`
 	ProxyPort   string `mapstructure:"PROXY_PORT"`
-	JwtSecret   string `mapstructure:"JWT_SECRET"`
-	// TODO: Añadir configuración para Google Cloud Storage (bucket, credentials path, etc.)
+	JwtSecret   string `mapstructure:"JWT_SECRET" secret:"true"`
+	// Credenciales del panel de administración del servidor WebSocket
+	AdminUsername string `mapstructure:"ADMIN_USERNAME"`
+	AdminPassword string `mapstructure:"ADMIN_PASSWORD" secret:"true"`
+	// Duración de los tokens de suplantación que emite el soporte (POST /admin/users/{id}/impersonate)
+	ImpersonationTTL time.Duration `mapstructure:"IMPERSONATION_TTL"`
+	// Id de Role y StatusAuthorized con un significado fijo en el código. Solo hay que cambiarlos
+	// si los datos iniciales de esas tablas son distintos (ver docs/roles_y_estados.md).
+	RoleStudentID               int `mapstructure:"ROLE_STUDENT_ID"`
+	RoleAlumniID                int `mapstructure:"ROLE_ALUMNI_ID"`
+	RoleBusinessID              int `mapstructure:"ROLE_BUSINESS_ID"`
+	RoleGuestID                 int `mapstructure:"ROLE_GUEST_ID"`
+	RoleAdminID                 int `mapstructure:"ROLE_ADMIN_ID"`
+	UserStatusActiveID          int `mapstructure:"USER_STATUS_ACTIVE_ID"`
+	UserStatusCompanyPendingID  int `mapstructure:"USER_STATUS_COMPANY_PENDING_ID"`
+	UserStatusCompanyApprovedID int `mapstructure:"USER_STATUS_COMPANY_APPROVED_ID"`
+	UserStatusSuspendedID       int `mapstructure:"USER_STATUS_SUSPENDED_ID"`
+	UserStatusDeactivatedID     int `mapstructure:"USER_STATUS_DEACTIVATED_ID"`
+	// Almacenamiento de archivos: gcs, s3 o local (ver docs/almacenamiento.md)
+	StorageDriver        string `mapstructure:"STORAGE_DRIVER"`
 	GCSBucketName        string `mapstructure:"GCS_BUCKET_NAME"`
 	GCSServiceAccountKey string `mapstructure:"GCS_SERVICE_ACCOUNT_KEY_PATH"` // Ruta al archivo JSON de credenciales
-	FrontendURL          string `mapstructure:"FRONTEND_URL"`                 // URL base del frontend para redirecciones
+	S3Endpoint           string `mapstructure:"S3_ENDPOINT"`
+	S3Region             string `mapstructure:"S3_REGION"`
+	S3Bucket             string `mapstructure:"S3_BUCKET"`
+	S3AccessKeyID        string `mapstructure:"S3_ACCESS_KEY_ID" secret:"true"`
+	S3SecretAccessKey    string `mapstructure:"S3_SECRET_ACCESS_KEY" secret:"true"`
+	S3UsePathStyle       bool   `mapstructure:"S3_USE_PATH_STYLE"` // MinIO y otros servicios sin subdominio por bucket
+	S3PublicURL          string `mapstructure:"S3_PUBLIC_URL"`     // Base de las URL públicas (CDN); vacío = la del endpoint
+	StorageLocalDir      string `mapstructure:"STORAGE_LOCAL_DIR"`
+	StorageLocalBaseURL  string `mapstructure:"STORAGE_LOCAL_BASE_URL"` // Ruta en la que la API sirve los archivos locales
+	FrontendURL          string `mapstructure:"FRONTEND_URL"`           // URL base del frontend para redirecciones
+	ProxyRoutesFile      string `mapstructure:"PROXY_ROUTES_FILE"`      // YAML con la tabla de rutas del proxy (opcional)
+	// Retención de mensajes: los mensajes más antiguos que MessageRetentionDays se archivan (0 = deshabilitado)
+	MessageRetentionDays     int           `mapstructure:"MESSAGE_RETENTION_DAYS"`
+	MessageRetentionInterval time.Duration `mapstructure:"MESSAGE_RETENTION_INTERVAL"`
+	// Servidor SMTP para correos transaccionales (SMTP_HOST vacío = envío deshabilitado)
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     int    `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD" secret:"true"`
+	SMTPFrom     string `mapstructure:"SMTP_FROM"`
+	// Exportación de datos personales: directorio de los ZIP generados y tiempo que se conservan
+	DataExportDir string        `mapstructure:"DATA_EXPORT_DIR"`
+	DataExportTTL time.Duration `mapstructure:"DATA_EXPORT_TTL"`
+	// Verificación de estudiantes por correo institucional: vigencia del código, intentos
+	// fallidos permitidos por código y espera mínima entre dos códigos
+	StudentVerificationCodeTTL      time.Duration `mapstructure:"STUDENT_VERIFICATION_CODE_TTL"`
+	StudentVerificationMaxAttempts  int           `mapstructure:"STUDENT_VERIFICATION_MAX_ATTEMPTS"`
+	StudentVerificationResendPeriod time.Duration `mapstructure:"STUDENT_VERIFICATION_RESEND_PERIOD"`
+	// Multi-institución (ver docs/multi_institucion.md): con TenancyEnabled el feed, la búsqueda y los
+	// eventos se limitan a la institución de la petición, que se toma del subdominio de
+	// TenancyBaseDomain o de la cuenta
+	TenancyEnabled    bool   `mapstructure:"TENANCY_ENABLED"`
+	TenancyBaseDomain string `mapstructure:"TENANCY_BASE_DOMAIN"`
+	// Exportación de conversaciones: vida del archivo en GCS, del enlace firmado y número máximo
+	// de mensajes que se exportan durante la propia petición (los chats mayores van en segundo plano)
+	ChatExportTTL       time.Duration `mapstructure:"CHAT_EXPORT_TTL"`
+	ChatExportURLTTL    time.Duration `mapstructure:"CHAT_EXPORT_URL_TTL"`
+	ChatExportSyncLimit int           `mapstructure:"CHAT_EXPORT_SYNC_LIMIT"`
+	// Subidas reanudables de video: directorio de las partes recibidas y caducidad de una subida
+	// sin actividad
+	UploadSessionDir string        `mapstructure:"UPLOAD_SESSION_DIR"`
+	UploadSessionTTL time.Duration `mapstructure:"UPLOAD_SESSION_TTL"`
+	// Duración máxima de una nota de voz
+	VoiceNoteMaxDuration time.Duration `mapstructure:"VOICE_NOTE_MAX_DURATION"`
+	// Cuotas de almacenamiento por rol, en bytes (0 = sin límite). Los egresados e invitados usan
+	// la de estudiantes; los administradores no tienen límite
+	StorageQuotaStudent int64 `mapstructure:"STORAGE_QUOTA_STUDENT"`
+	StorageQuotaCompany int64 `mapstructure:"STORAGE_QUOTA_COMPANY"`
+	// Análisis de malware de las subidas: driver (vacío = deshabilitado, clamav o http), clamd,
+	// servicio HTTP y si se aceptan los archivos cuando el motor no responde
+	UploadScanner      string        `mapstructure:"UPLOAD_SCANNER"`
+	ClamAVAddress      string        `mapstructure:"CLAMAV_ADDRESS"`
+	UploadScanURL      string        `mapstructure:"UPLOAD_SCAN_URL"`
+	UploadScanAPIKey   string        `mapstructure:"UPLOAD_SCAN_API_KEY" secret:"true"`
+	UploadScanTimeout  time.Duration `mapstructure:"UPLOAD_SCAN_TIMEOUT"`
+	UploadScanFailOpen bool          `mapstructure:"UPLOAD_SCAN_FAIL_OPEN"`
+	// Moderación automática de imágenes: driver del clasificador NSFW (vacío = deshabilitado o
+	// http), umbrales de score para rechazar y para enviar a revisión, y si se aceptan las
+	// imágenes cuando el clasificador no responde
+	ImageModerationDriver          string        `mapstructure:"IMAGE_MODERATION_DRIVER"`
+	ImageModerationURL             string        `mapstructure:"IMAGE_MODERATION_URL"`
+	ImageModerationAPIKey          string        `mapstructure:"IMAGE_MODERATION_API_KEY" secret:"true"`
+	ImageModerationTimeout         time.Duration `mapstructure:"IMAGE_MODERATION_TIMEOUT"`
+	ImageModerationRejectThreshold float64       `mapstructure:"IMAGE_MODERATION_REJECT_THRESHOLD"`
+	ImageModerationReviewThreshold float64       `mapstructure:"IMAGE_MODERATION_REVIEW_THRESHOLD"`
+	ImageModerationFailOpen        bool          `mapstructure:"IMAGE_MODERATION_FAIL_OPEN"`
+	// Política de contraseñas para registro y restablecimiento
+	PasswordMinLength     int           `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordRequireUpper  bool          `mapstructure:"PASSWORD_REQUIRE_UPPER"`
+	PasswordRequireLower  bool          `mapstructure:"PASSWORD_REQUIRE_LOWER"`
+	PasswordRequireDigit  bool          `mapstructure:"PASSWORD_REQUIRE_DIGIT"`
+	PasswordRequireSymbol bool          `mapstructure:"PASSWORD_REQUIRE_SYMBOL"`
+	PasswordBreachCheck   bool          `mapstructure:"PASSWORD_BREACH_CHECK"` // Consulta HaveIBeenPwned (k-anonimato)
+	PasswordBreachTimeout time.Duration `mapstructure:"PASSWORD_BREACH_TIMEOUT"`
+	// Pool de conexiones a la BD
+	DBMaxOpenConns       int           `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns       int           `mapstructure:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetime    time.Duration `mapstructure:"DB_CONN_MAX_LIFETIME"`
+	DBConnMaxIdleTime    time.Duration `mapstructure:"DB_CONN_MAX_IDLE_TIME"`   // 0 = sin límite
+	DBStatsInterval      time.Duration `mapstructure:"DB_STATS_INTERVAL"`       // Muestreo de sql.DBStats (0 = deshabilitado)
+	DBWaitAlert          int64         `mapstructure:"DB_POOL_WAIT_ALERT"`      // Esperas por intervalo que disparan la alerta
+	DBSlowQueryThreshold time.Duration `mapstructure:"DB_SLOW_QUERY_THRESHOLD"` // Consultas más lentas se registran en el log (0 = no)
+	// Modo solo lectura (ver docs/modo_solo_lectura.md): se activa tras DB_READONLY_FAILURES
+	// fallos de conexión seguidos, si el servidor está en read_only o si la réplica de
+	// DB_REPLICA_DSN acumula más de DB_READONLY_MAX_LAG de retraso, y se desactiva tras
+	// DB_READONLY_RECOVERY comprobaciones correctas seguidas
+	DBReadOnlyCheckInterval time.Duration `mapstructure:"DB_READONLY_CHECK_INTERVAL"` // 0 = sin modo solo lectura
+	DBReadOnlyFailures      int           `mapstructure:"DB_READONLY_FAILURES"`
+	DBReadOnlyRecovery      int           `mapstructure:"DB_READONLY_RECOVERY"`
+	DBReadOnlyMaxLag        time.Duration `mapstructure:"DB_READONLY_MAX_LAG"` // 0 = no se mide el retraso
+	DBReplicaDSN            string        `mapstructure:"DB_REPLICA_DSN" secret:"dsn"`
+	// Retirada de versiones de la API (YYYY-MM-DD, vacío = sin fecha anunciada)
+	APIV1Sunset     string `mapstructure:"API_V1_SUNSET"`
+	APILegacySunset string `mapstructure:"API_LEGACY_SUNSET"` // Rutas /api/... sin versión
+	// Especificación OpenAPI (openapi.json y Swagger UI en /docs de cada versión) y validación
+	// de los cuerpos de las peticiones contra ella
+	APIDocsEnabled       bool `mapstructure:"API_DOCS_ENABLED"`
+	APIRequestValidation bool `mapstructure:"API_REQUEST_VALIDATION"`
+	// Endpoint /graphql de solo lectura (docs/graphql.md) y complejidad máxima de una consulta
+	GraphQLEnabled         bool `mapstructure:"GRAPHQL_ENABLED"`
+	GraphQLComplexityLimit int  `mapstructure:"GRAPHQL_COMPLEXITY_LIMIT"`
+	// Filtro de contenido del chat: idiomas de las listas (separados por comas) y servicio
+	// externo de moderación opcional (URL vacía = deshabilitado)
+	ContentFilterLanguages      string        `mapstructure:"CONTENT_FILTER_LANGUAGES"`
+	ContentFilterAPIURL         string        `mapstructure:"CONTENT_FILTER_API_URL"`
+	ContentFilterAPIKey         string        `mapstructure:"CONTENT_FILTER_API_KEY" secret:"true"`
+	ContentFilterAPITimeout     time.Duration `mapstructure:"CONTENT_FILTER_API_TIMEOUT"`
+	ContentFilterReloadInterval time.Duration `mapstructure:"CONTENT_FILTER_RELOAD_INTERVAL"` // Recarga de reglas en el servidor WS
+	// Notificaciones push: cuenta de servicio de Firebase (FCM) y clave .p8 de APNs. Un
+	// proveedor sin credenciales queda deshabilitado.
+	PushFCMCredentialsFile string        `mapstructure:"PUSH_FCM_CREDENTIALS_FILE"`
+	PushAPNsKeyFile        string        `mapstructure:"PUSH_APNS_KEY_FILE"`
+	PushAPNsKeyID          string        `mapstructure:"PUSH_APNS_KEY_ID"`
+	PushAPNsTeamID         string        `mapstructure:"PUSH_APNS_TEAM_ID"`
+	PushAPNsTopic          string        `mapstructure:"PUSH_APNS_TOPIC"` // Bundle ID de la app iOS
+	PushAPNsSandbox        bool          `mapstructure:"PUSH_APNS_SANDBOX"`
+	PushTimeout            time.Duration `mapstructure:"PUSH_TIMEOUT"`
+	// Web Push para navegadores: clave privada VAPID (base64url) y contacto del remitente.
+	PushVAPIDPrivateKey string `mapstructure:"PUSH_VAPID_PRIVATE_KEY" secret:"true"`
+	PushVAPIDSubject    string `mapstructure:"PUSH_VAPID_SUBJECT"` // mailto: o URL https://
+	// Resumen por correo de lo no leído (requiere SMTP). Intervalo 0 = deshabilitado.
+	EmailDigestInterval   time.Duration `mapstructure:"EMAIL_DIGEST_INTERVAL"`
+	EmailDigestInactivity time.Duration `mapstructure:"EMAIL_DIGEST_INACTIVITY"` // Antigüedad mínima de lo no leído
+	EmailDigestMaxItems   int           `mapstructure:"EMAIL_DIGEST_MAX_ITEMS"`  // Por tipo y por correo
+	// No molestar: cada cuánto se comprueba qué horarios terminaron para enviar el resumen de
+	// lo silenciado. 0 = no se envían resúmenes.
+	DndSummaryInterval time.Duration `mapstructure:"DND_SUMMARY_INTERVAL"`
+	// Alertas de búsquedas guardadas: cada cuánto se evalúan los elementos nuevos y cuánto se
+	// espera a que un usuario nuevo complete su perfil antes de compararlo. Intervalo 0 = deshabilitado.
+	SavedSearchAlertInterval time.Duration `mapstructure:"SAVED_SEARCH_ALERT_INTERVAL"`
+	SavedSearchPeopleDelay   time.Duration `mapstructure:"SAVED_SEARCH_PEOPLE_DELAY"`
+	// Expiración de notificaciones leídas: días que se conservan (0 = no expiran), días por
+	// tipo de notificación ("SYSTEM=30,FRIEND_REQUEST=0", 0 = ese tipo no expira) y cada cuánto
+	// se borran. Intervalo 0 = deshabilitado.
+	NotificationRetentionDays     int           `mapstructure:"NOTIFICATION_RETENTION_DAYS"`
+	NotificationRetentionByType   string        `mapstructure:"NOTIFICATION_RETENTION_BY_TYPE"`
+	NotificationRetentionInterval time.Duration `mapstructure:"NOTIFICATION_RETENTION_INTERVAL"`
+	// Agrupación de notificaciones parecidas (me gusta, comentarios, postulaciones): se suman a
+	// la notificación sin leer del mismo grupo actualizada hace menos de este tiempo. 0 = no se agrupan.
+	NotificationGroupWindow time.Duration `mapstructure:"NOTIFICATION_GROUP_WINDOW"`
+	// Presencia: cada instancia del servidor WS renueva LastSeenAt de sus usuarios conectados y
+	// pasa a offline a los que superan el TTL sin heartbeat (p. ej. tras una caída del proceso).
+	PresenceHeartbeatInterval time.Duration `mapstructure:"PRESENCE_HEARTBEAT_INTERVAL"`
+	PresenceTTL               time.Duration `mapstructure:"PRESENCE_TTL"`
+	// Tamaño de los mensajes del cliente WS: límite general (y de cada fragmento), límites
+	// propios por tipo ("tipo=bytes" separados por comas) y fragmentación de los mensajes
+	// grandes. WS_CHUNK_TIMEOUT=0 deshabilita la fragmentación.
+	WsMaxMessageSize    int64         `mapstructure:"WS_MAX_MESSAGE_SIZE"`
+	WsMessageSizeLimits string        `mapstructure:"WS_MESSAGE_SIZE_LIMITS"`
+	WsChunkTimeout      time.Duration `mapstructure:"WS_CHUNK_TIMEOUT"`
+	WsMaxPendingChunks  int           `mapstructure:"WS_MAX_PENDING_CHUNKS"` // Mensajes fragmentados incompletos por conexión
+	// Cierre de las conexiones WS sin mensajes del cliente (pestañas abandonadas), con un aviso
+	// idle_warning previo. WS_IDLE_TIMEOUT=0 lo deshabilita.
+	WsIdleTimeout time.Duration `mapstructure:"WS_IDLE_TIMEOUT"`
+	WsIdleWarning time.Duration `mapstructure:"WS_IDLE_WARNING"`
+	// Heartbeats del servidor con su hora y las estadísticas de la conexión, para que el cliente
+	// corrija el desfase de su reloj y detecte antes las conexiones medio abiertas.
+	// WS_HEARTBEAT_INTERVAL=0 los deshabilita (el servidor sigue respondiendo a los del cliente).
+	WsHeartbeatInterval time.Duration `mapstructure:"WS_HEARTBEAT_INTERVAL"`
+	// Autenticación WS: plazo para el mensaje auth de las conexiones abiertas sin token
+	// (WS_AUTH_TIMEOUT=0 lo deshabilita) y si se acepta el token en la query (?token=), que
+	// queda en los logs de proxies y servidores.
+	WsAuthTimeout    time.Duration `mapstructure:"WS_AUTH_TIMEOUT"`
+	WsAuthQueryToken bool          `mapstructure:"WS_AUTH_QUERY_TOKEN"`
+	// Mensajes críticos enviados con ack (aceptación de contactos): plazo del ack, envíos antes
+	// de darlo por no entregado, espera entre reintentos (se duplica hasta WS_ACK_MAX_BACKOFF) y
+	// cuánto se conservan los no entregados a la espera de que el usuario se conecte.
+	WsAckTimeout        time.Duration `mapstructure:"WS_ACK_TIMEOUT"`
+	WsAckMaxAttempts    int           `mapstructure:"WS_ACK_MAX_ATTEMPTS"`
+	WsAckBackoff        time.Duration `mapstructure:"WS_ACK_BACKOFF"`
+	WsAckMaxBackoff     time.Duration `mapstructure:"WS_ACK_MAX_BACKOFF"`
+	DeadLetterRetention time.Duration `mapstructure:"DEAD_LETTER_RETENTION"`
+	// Reinicio sin cortes del servidor WS (SIGUSR2): plazo para que el proceso nuevo avise de
+	// que ya atiende peticiones y tiempo en que el anterior reparte el cierre de sus conexiones.
+	WsHandoffReadyTimeout time.Duration `mapstructure:"WS_HANDOFF_READY_TIMEOUT"`
+	WsHandoffDrain        time.Duration `mapstructure:"WS_HANDOFF_DRAIN"`
+	// Drenaje de una instancia WS desde el panel o el proxy: nombre con el que se identifica
+	// (vacío = host:puerto), tiempo en que reparte el cierre de sus conexiones y Retry-After
+	// mínimo que reciben los clientes para reconectar.
+	WsInstanceID      string        `mapstructure:"WS_INSTANCE_ID"`
+	WsDrainSpread     time.Duration `mapstructure:"WS_DRAIN_SPREAD"`
+	WsDrainRetryAfter time.Duration `mapstructure:"WS_DRAIN_RETRY_AFTER"`
+	// Outbox de mensajes en tiempo real que la API deja al servidor WS: cada cuánto se lee,
+	// cuántos mensajes por lectura y cuánto se conservan. OUTBOX_POLL_INTERVAL=0 no lo lee.
+	OutboxPollInterval time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL"`
+	OutboxBatchSize    int           `mapstructure:"OUTBOX_BATCH_SIZE"`
+	OutboxRetention    time.Duration `mapstructure:"OUTBOX_RETENTION"`
+	// Sincronización incremental de la lista de chats: cuánto se conservan los cambios
+	// registrados. Un syncToken más antiguo recibe la lista completa (0 = se conservan siempre).
+	ChatSyncRetention time.Duration `mapstructure:"CHAT_SYNC_RETENTION"`
+	// Cada cuánto se recalculan los contadores de mensajes no leídos desde Message (0 = nunca).
+	ChatUnreadReconcileInterval time.Duration `mapstructure:"CHAT_UNREAD_RECONCILE_INTERVAL"`
+	// Geolocalización de las sesiones: cabeceras del CDN/proxy con país y ciudad y, como
+	// respaldo, un servicio HTTP con el marcador {ip} en la URL (vacío = no se consulta).
+	GeoIPCountryHeader string        `mapstructure:"GEOIP_COUNTRY_HEADER"`
+	GeoIPCityHeader    string        `mapstructure:"GEOIP_CITY_HEADER"`
+	GeoIPAPIURL        string        `mapstructure:"GEOIP_API_URL"`
+	GeoIPTimeout       time.Duration `mapstructure:"GEOIP_TIMEOUT"`
+	// Log de peticiones de la API. Las respuestas 5xx y las lentas se registran siempre; el
+	// resto según la tasa de muestreo. Las rutas excluidas no generan log ni métricas.
+	RequestLogEnabled       bool          `mapstructure:"REQUEST_LOG_ENABLED"`
+	RequestLogSampleRate    float64       `mapstructure:"REQUEST_LOG_SAMPLE_RATE"` // 0 a 1
+	RequestLogSlowThreshold time.Duration `mapstructure:"REQUEST_LOG_SLOW_THRESHOLD"`
+	RequestLogExclude       string        `mapstructure:"REQUEST_LOG_EXCLUDE"` // Rutas separadas por comas
+	// Límites del servidor de la API. Las subidas y descargas grandes tienen su propio límite
+	// de tamaño en la ruta y usan APIUploadTimeout en lugar de los timeouts de lectura/escritura.
+	APIReadHeaderTimeout time.Duration `mapstructure:"API_READ_HEADER_TIMEOUT"`
+	APIReadTimeout       time.Duration `mapstructure:"API_READ_TIMEOUT"`
+	APIWriteTimeout      time.Duration `mapstructure:"API_WRITE_TIMEOUT"`
+	APIIdleTimeout       time.Duration `mapstructure:"API_IDLE_TIMEOUT"`
+	APIUploadTimeout     time.Duration `mapstructure:"API_UPLOAD_TIMEOUT"`
+	APIMaxBodySize       int64         `mapstructure:"API_MAX_BODY_SIZE"` // Bytes, para las rutas sin límite propio
+	// Compresión gzip/deflate de las respuestas de la API y del proxy. Solo se comprimen los
+	// tipos de la lista que alcanzan el tamaño mínimo.
+	CompressionEnabled bool   `mapstructure:"COMPRESSION_ENABLED"`
+	CompressionMinSize int    `mapstructure:"COMPRESSION_MIN_SIZE"` // Bytes
+	CompressionTypes   string `mapstructure:"COMPRESSION_TYPES"`    // Tipos MIME separados por comas (text/* admite subtipos)
+	CompressionLevel   int    `mapstructure:"COMPRESSION_LEVEL"`    // 1 (rápido) a 9 (máxima); 0 = por defecto
+	// Caché de consultas frecuentes (información básica de usuarios, lista de chats, catálogos).
+	// Sin CACHE_REDIS_ADDR cada proceso tiene solo su caché en memoria.
+	CacheEnabled       bool          `mapstructure:"CACHE_ENABLED"`
+	CacheMaxEntries    int           `mapstructure:"CACHE_MAX_ENTRIES"`
+	CacheLocalTTL      time.Duration `mapstructure:"CACHE_LOCAL_TTL"` // Vida máxima en memoria cuando hay Redis
+	CacheRedisAddr     string        `mapstructure:"CACHE_REDIS_ADDR"`
+	CacheRedisPassword string        `mapstructure:"CACHE_REDIS_PASSWORD" secret:"true"`
+	CacheRedisDB       int           `mapstructure:"CACHE_REDIS_DB"`
+	CacheRedisTimeout  time.Duration `mapstructure:"CACHE_REDIS_TIMEOUT"`
+	CacheUserTTL       time.Duration `mapstructure:"CACHE_USER_TTL"`
+	CacheChatListTTL   time.Duration `mapstructure:"CACHE_CHAT_LIST_TTL"`
+	CacheCatalogTTL    time.Duration `mapstructure:"CACHE_CATALOG_TTL"`
+	CacheNetworkTTL    time.Duration `mapstructure:"CACHE_NETWORK_TTL"` // Contactos, sugerencias y resumen de red
+	CacheCohortTTL     time.Duration `mapstructure:"CACHE_COHORT_TTL"`  // Analíticas de cohortes de egresados
+	// Modo mantenimiento: el proxy responde 503 a las peticiones nuevas salvo las de los prefijos
+	// exentos y el servidor WebSocket avisa a los clientes durante MAINTENANCE_COUNTDOWN antes
+	// de cerrar sus conexiones. Se activa al arrancar con MAINTENANCE_MODE o desde el panel.
+	MaintenanceMode        bool          `mapstructure:"MAINTENANCE_MODE"`
+	MaintenanceMessage     string        `mapstructure:"MAINTENANCE_MESSAGE"`
+	MaintenanceRetryAfter  time.Duration `mapstructure:"MAINTENANCE_RETRY_AFTER"`
+	MaintenanceCountdown   time.Duration `mapstructure:"MAINTENANCE_COUNTDOWN"`
+	MaintenanceExemptPaths string        `mapstructure:"MAINTENANCE_EXEMPT_PATHS"` // Prefijos separados por comas
+	// Cifrado de columnas sensibles (DocId, Phone, RIF) con AES-256-GCM: claves de 32 bytes en
+	// base64. Las anteriores, separadas por comas, solo se usan para descifrar mientras se rota
+	// la clave. La de índices calcula los índices ciegos con los que se buscan DocId y RIF y no
+	// debe cambiar nunca. Sin FIELD_ENCRYPTION_KEY se guardan en texto plano (ver
+	// docs/cifrado_campos.md).
+	FieldEncryptionKey          string `mapstructure:"FIELD_ENCRYPTION_KEY" secret:"true"`
+	FieldEncryptionPreviousKeys string `mapstructure:"FIELD_ENCRYPTION_PREVIOUS_KEYS" secret:"true"`
+	FieldEncryptionIndexKey     string `mapstructure:"FIELD_ENCRYPTION_INDEX_KEY" secret:"true"`
+
+	// sources indica de dónde salió cada valor (ver Report) y warnings los avisos de la carga
+	sources  map[string]string
+	warnings []string
 }
 
 // LoadConfig loads configuration from environment variables or a config file.
+//
+// Cada valor se toma de la primera fuente que lo defina, en este orden: variables de entorno,
+// archivo .env, archivo YAML de CONFIG_FILE (claves planas, p. ej. api_port: 8080) y valores
+// por defecto. La configuración resultante se valida con Validate.
 func LoadConfig() (*Config, error) {
-	viper.SetConfigName(".env") // Nombre del archivo de configuración (sin extensión)
-	viper.SetConfigType("env")  // Tipo del archivo de configuración
-
-	// Rutas de búsqueda: directorio actual (para tests?), y directorios cmd/*
-	viper.AddConfigPath(".")       // Directorio actual (e.g. /internal/config)
-	viper.AddConfigPath("cmd/api") // Para ejecución desde la raíz del proyecto
-	viper.AddConfigPath("cmd/websocket")
-	// Buscar también relativa a donde se ejecuta el binario
-	// (Importante cuando se construye y ejecuta desde /cmd/api/ o /cmd/websocket/)
-	viper.AddConfigPath(".") // Directorio de ejecución del binario
-
 	// Añadir configuración para buscar automáticamente variables de entorno
 	viper.AutomaticEnv()
 
 	// Establecer valores por defecto (opcional, pero recomendado)
+	viper.SetDefault("CONFIG_FILE", "")
+	viper.SetDefault("ENVIRONMENT", EnvDevelopment)
+	viper.SetDefault("ADMIN_USERNAME", "admin")
+	viper.SetDefault("ADMIN_PASSWORD", defaultAdminPassword)
 	viper.SetDefault("API_PORT", "8080")
 	viper.SetDefault("WS_PORT", "8081")
 	viper.SetDefault("PROXY_PORT", "8000")
+	viper.SetDefault("DB_DSN", "") // Vacío = se construye con DB_USER, DB_PASSWORD, DB_HOST, DB_PORT y DB_NAME
 	viper.SetDefault("DB_HOST", "127.0.0.1")
 	viper.SetDefault("DB_PORT", "3306")
-	viper.SetDefault("JWT_SECRET", "un-secreto-muy-seguro-cambiar-en-produccion") // ¡CAMBIAR ESTO!
-	viper.SetDefault("FRONTEND_URL", "http://localhost:3000")                     // URL base del frontend
-
-	// Intentar leer el archivo de configuración
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			// Archivo .env no encontrado, no es un error fatal si las variables de entorno están seteadas
-			fmt.Println("Warning: .env file not found. Relying on environment variables and defaults.")
-		} else {
-			// Otro error al leer el archivo
+	viper.SetDefault("DB_MAX_OPEN_CONNS", 10)
+	viper.SetDefault("DB_MAX_IDLE_CONNS", 10)
+	viper.SetDefault("DB_CONN_MAX_LIFETIME", "3m")
+	viper.SetDefault("DB_CONN_MAX_IDLE_TIME", "0")
+	viper.SetDefault("DB_STATS_INTERVAL", "1m")
+	viper.SetDefault("DB_POOL_WAIT_ALERT", 10)
+	viper.SetDefault("DB_SLOW_QUERY_THRESHOLD", "500ms")
+	viper.SetDefault("DB_READONLY_CHECK_INTERVAL", "5s")
+	viper.SetDefault("DB_READONLY_FAILURES", 3)
+	viper.SetDefault("DB_READONLY_RECOVERY", 3)
+	viper.SetDefault("DB_READONLY_MAX_LAG", "30s")
+	viper.SetDefault("DB_REPLICA_DSN", "") // Vacío = no se mide el retraso de replicación
+	viper.SetDefault("API_V1_SUNSET", "")
+	viper.SetDefault("API_LEGACY_SUNSET", "")
+	viper.SetDefault("API_DOCS_ENABLED", true)
+	viper.SetDefault("API_REQUEST_VALIDATION", false)
+	viper.SetDefault("GRAPHQL_ENABLED", false)
+	viper.SetDefault("GRAPHQL_COMPLEXITY_LIMIT", 500)
+	viper.SetDefault("JWT_SECRET", defaultJWTSecret)          // ¡CAMBIAR ESTO! Validate lo rechaza en producción
+	viper.SetDefault("FRONTEND_URL", "http://localhost:3000") // URL base del frontend
+	viper.SetDefault("PROXY_ROUTES_FILE", "")                 // Vacío = rutas por defecto (/api/, /ws)
+	viper.SetDefault("IMPERSONATION_TTL", "15m")
+	accountIDs := models.DefaultAccountIDs()
+	viper.SetDefault("ROLE_STUDENT_ID", accountIDs.RoleStudent)
+	viper.SetDefault("ROLE_ALUMNI_ID", accountIDs.RoleAlumni)
+	viper.SetDefault("ROLE_BUSINESS_ID", accountIDs.RoleBusiness)
+	viper.SetDefault("ROLE_GUEST_ID", accountIDs.RoleGuest)
+	viper.SetDefault("ROLE_ADMIN_ID", accountIDs.RoleAdmin)
+	viper.SetDefault("USER_STATUS_ACTIVE_ID", accountIDs.StatusActive)
+	viper.SetDefault("USER_STATUS_COMPANY_PENDING_ID", accountIDs.StatusCompanyPending)
+	viper.SetDefault("USER_STATUS_COMPANY_APPROVED_ID", accountIDs.StatusCompanyApproved)
+	viper.SetDefault("USER_STATUS_SUSPENDED_ID", accountIDs.StatusSuspended)
+	viper.SetDefault("USER_STATUS_DEACTIVATED_ID", accountIDs.StatusDeactivated)
+	viper.SetDefault("MESSAGE_RETENTION_DAYS", 365)
+	viper.SetDefault("MESSAGE_RETENTION_INTERVAL", "24h")
+	viper.SetDefault("SMTP_HOST", "")
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+	viper.SetDefault("SMTP_FROM", "")
+	viper.SetDefault("DATA_EXPORT_DIR", "data_exports")
+	viper.SetDefault("DATA_EXPORT_TTL", "168h")
+	viper.SetDefault("STUDENT_VERIFICATION_CODE_TTL", "30m")
+	viper.SetDefault("STUDENT_VERIFICATION_MAX_ATTEMPTS", 5)
+	viper.SetDefault("STUDENT_VERIFICATION_RESEND_PERIOD", "1m")
+	viper.SetDefault("TENANCY_ENABLED", false)
+	viper.SetDefault("TENANCY_BASE_DOMAIN", "")
+	viper.SetDefault("CHAT_EXPORT_TTL", "24h")
+	viper.SetDefault("CHAT_EXPORT_URL_TTL", "15m")
+	viper.SetDefault("CHAT_EXPORT_SYNC_LIMIT", 1000)
+	viper.SetDefault("STORAGE_DRIVER", "gcs")
+	viper.SetDefault("GCS_BUCKET_NAME", "")
+	viper.SetDefault("GCS_SERVICE_ACCOUNT_KEY_PATH", "")
+	viper.SetDefault("S3_ENDPOINT", "")
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("S3_BUCKET", "")
+	viper.SetDefault("S3_ACCESS_KEY_ID", "")
+	viper.SetDefault("S3_SECRET_ACCESS_KEY", "")
+	viper.SetDefault("S3_USE_PATH_STYLE", false)
+	viper.SetDefault("S3_PUBLIC_URL", "")
+	viper.SetDefault("STORAGE_LOCAL_DIR", "storage")
+	viper.SetDefault("STORAGE_LOCAL_BASE_URL", "/files")
+	viper.SetDefault("UPLOAD_SESSION_DIR", "upload_sessions")
+	viper.SetDefault("UPLOAD_SESSION_TTL", "24h")
+	viper.SetDefault("VOICE_NOTE_MAX_DURATION", "5m")
+	viper.SetDefault("STORAGE_QUOTA_STUDENT", 1<<30) // 1 GiB
+	viper.SetDefault("STORAGE_QUOTA_COMPANY", 5<<30) // 5 GiB
+	viper.SetDefault("UPLOAD_SCANNER", "")
+	viper.SetDefault("CLAMAV_ADDRESS", "localhost:3310")
+	viper.SetDefault("UPLOAD_SCAN_URL", "")
+	viper.SetDefault("UPLOAD_SCAN_API_KEY", "")
+	viper.SetDefault("UPLOAD_SCAN_TIMEOUT", "2m")
+	viper.SetDefault("UPLOAD_SCAN_FAIL_OPEN", false)
+	viper.SetDefault("IMAGE_MODERATION_DRIVER", "")
+	viper.SetDefault("IMAGE_MODERATION_URL", "")
+	viper.SetDefault("IMAGE_MODERATION_API_KEY", "")
+	viper.SetDefault("IMAGE_MODERATION_TIMEOUT", "10s")
+	viper.SetDefault("IMAGE_MODERATION_REJECT_THRESHOLD", 0.9)
+	viper.SetDefault("IMAGE_MODERATION_REVIEW_THRESHOLD", 0.6)
+	viper.SetDefault("IMAGE_MODERATION_FAIL_OPEN", true)
+	viper.SetDefault("PASSWORD_MIN_LENGTH", 8)
+	viper.SetDefault("PASSWORD_REQUIRE_UPPER", true)
+	viper.SetDefault("PASSWORD_REQUIRE_LOWER", true)
+	viper.SetDefault("PASSWORD_REQUIRE_DIGIT", true)
+	viper.SetDefault("PASSWORD_REQUIRE_SYMBOL", false)
+	viper.SetDefault("PASSWORD_BREACH_CHECK", false)
+	viper.SetDefault("PASSWORD_BREACH_TIMEOUT", "3s")
+	viper.SetDefault("CONTENT_FILTER_LANGUAGES", "es,en")
+	viper.SetDefault("CONTENT_FILTER_API_URL", "")
+	viper.SetDefault("CONTENT_FILTER_API_KEY", "")
+	viper.SetDefault("CONTENT_FILTER_API_TIMEOUT", "2s")
+	viper.SetDefault("CONTENT_FILTER_RELOAD_INTERVAL", "30s")
+	viper.SetDefault("PUSH_FCM_CREDENTIALS_FILE", "")
+	viper.SetDefault("PUSH_APNS_KEY_FILE", "")
+	viper.SetDefault("PUSH_APNS_KEY_ID", "")
+	viper.SetDefault("PUSH_APNS_TEAM_ID", "")
+	viper.SetDefault("PUSH_APNS_TOPIC", "")
+	viper.SetDefault("PUSH_APNS_SANDBOX", false)
+	viper.SetDefault("PUSH_TIMEOUT", "5s")
+	viper.SetDefault("PUSH_VAPID_PRIVATE_KEY", "")
+	viper.SetDefault("PUSH_VAPID_SUBJECT", "")
+	viper.SetDefault("EMAIL_DIGEST_INTERVAL", "1h")
+	viper.SetDefault("EMAIL_DIGEST_INACTIVITY", "24h")
+	viper.SetDefault("EMAIL_DIGEST_MAX_ITEMS", 20)
+	viper.SetDefault("DND_SUMMARY_INTERVAL", "1m")
+	viper.SetDefault("SAVED_SEARCH_ALERT_INTERVAL", "5m")
+	viper.SetDefault("SAVED_SEARCH_PEOPLE_DELAY", "1h")
+	viper.SetDefault("NOTIFICATION_RETENTION_DAYS", 90)
+	viper.SetDefault("NOTIFICATION_RETENTION_BY_TYPE", "")
+	viper.SetDefault("NOTIFICATION_RETENTION_INTERVAL", "24h")
+	viper.SetDefault("NOTIFICATION_GROUP_WINDOW", "24h")
+	viper.SetDefault("PRESENCE_HEARTBEAT_INTERVAL", "30s")
+	viper.SetDefault("PRESENCE_TTL", "90s")
+	viper.SetDefault("WS_MAX_MESSAGE_SIZE", 4096)
+	viper.SetDefault("WS_MESSAGE_SIZE_LIMITS", "data_request=65536,send_chat_message=16384")
+	viper.SetDefault("WS_CHUNK_TIMEOUT", "30s")
+	viper.SetDefault("WS_MAX_PENDING_CHUNKS", 4)
+	viper.SetDefault("WS_IDLE_TIMEOUT", "30m")
+	viper.SetDefault("WS_IDLE_WARNING", "1m")
+	viper.SetDefault("WS_HEARTBEAT_INTERVAL", "30s")
+	viper.SetDefault("WS_AUTH_TIMEOUT", "10s")
+	viper.SetDefault("WS_AUTH_QUERY_TOKEN", true)
+	viper.SetDefault("WS_ACK_TIMEOUT", "10s")
+	viper.SetDefault("WS_ACK_MAX_ATTEMPTS", 3)
+	viper.SetDefault("WS_ACK_BACKOFF", "2s")
+	viper.SetDefault("WS_ACK_MAX_BACKOFF", "30s")
+	viper.SetDefault("DEAD_LETTER_RETENTION", "168h")
+	viper.SetDefault("WS_HANDOFF_READY_TIMEOUT", "30s")
+	viper.SetDefault("WS_HANDOFF_DRAIN", "30s")
+	viper.SetDefault("WS_INSTANCE_ID", "")
+	viper.SetDefault("WS_DRAIN_SPREAD", "2m")
+	viper.SetDefault("WS_DRAIN_RETRY_AFTER", "5s")
+	viper.SetDefault("OUTBOX_POLL_INTERVAL", "1s")
+	viper.SetDefault("OUTBOX_BATCH_SIZE", 500)
+	viper.SetDefault("OUTBOX_RETENTION", "24h")
+	viper.SetDefault("CHAT_SYNC_RETENTION", "720h")
+	viper.SetDefault("CHAT_UNREAD_RECONCILE_INTERVAL", "6h")
+	viper.SetDefault("GEOIP_COUNTRY_HEADER", "CF-IPCountry")
+	viper.SetDefault("GEOIP_CITY_HEADER", "")
+	viper.SetDefault("GEOIP_API_URL", "")
+	viper.SetDefault("GEOIP_TIMEOUT", "2s")
+	viper.SetDefault("REQUEST_LOG_ENABLED", true)
+	viper.SetDefault("REQUEST_LOG_SAMPLE_RATE", 1.0)
+	viper.SetDefault("REQUEST_LOG_SLOW_THRESHOLD", "1s")
+	viper.SetDefault("API_READ_HEADER_TIMEOUT", "10s")
+	viper.SetDefault("API_READ_TIMEOUT", "30s")
+	viper.SetDefault("API_WRITE_TIMEOUT", "60s")
+	viper.SetDefault("API_IDLE_TIMEOUT", "120s")
+	viper.SetDefault("API_UPLOAD_TIMEOUT", "10m")
+	viper.SetDefault("API_MAX_BODY_SIZE", 1<<20)
+	viper.SetDefault("COMPRESSION_ENABLED", true)
+	viper.SetDefault("COMPRESSION_MIN_SIZE", 1024)
+	viper.SetDefault("COMPRESSION_TYPES", "application/json,application/javascript,application/xml,image/svg+xml,text/*")
+	viper.SetDefault("COMPRESSION_LEVEL", 0)
+	viper.SetDefault("CACHE_ENABLED", true)
+	viper.SetDefault("CACHE_MAX_ENTRIES", 10000)
+	viper.SetDefault("CACHE_LOCAL_TTL", "15s")
+	viper.SetDefault("CACHE_REDIS_ADDR", "")
+	viper.SetDefault("CACHE_REDIS_PASSWORD", "")
+	viper.SetDefault("CACHE_REDIS_DB", 0)
+	viper.SetDefault("CACHE_REDIS_TIMEOUT", "500ms")
+	viper.SetDefault("CACHE_USER_TTL", "2m")
+	viper.SetDefault("CACHE_CHAT_LIST_TTL", "1m")
+	viper.SetDefault("CACHE_CATALOG_TTL", "1h")
+	viper.SetDefault("CACHE_NETWORK_TTL", "5m")
+	viper.SetDefault("CACHE_COHORT_TTL", "24h")
+	viper.SetDefault("MAINTENANCE_MODE", false)
+	viper.SetDefault("MAINTENANCE_MESSAGE", "") // Vacío = maintenance.DefaultMessage
+	viper.SetDefault("MAINTENANCE_RETRY_AFTER", "5m")
+	viper.SetDefault("MAINTENANCE_COUNTDOWN", "1m")
+	viper.SetDefault("MAINTENANCE_EXEMPT_PATHS", "/api/v1/admin/,/api/v2/admin/,/api/admin/,/admin")
+	viper.SetDefault("FIELD_ENCRYPTION_KEY", "") // Vacío = columnas sensibles en texto plano
+	viper.SetDefault("FIELD_ENCRYPTION_PREVIOUS_KEYS", "")
+	viper.SetDefault("FIELD_ENCRYPTION_INDEX_KEY", "")
+	viper.SetDefault("REQUEST_LOG_EXCLUDE", "/healthz,/readyz,/api/health,/api/v1/health,/api/v2/health")
+
+	var warnings []string
+
+	// Archivo YAML opcional (CONFIG_FILE)
+	yamlFile := viper.New()
+	if path := viper.GetString("CONFIG_FILE"); path != "" {
+		yamlFile.SetConfigFile(path)
+		if err := yamlFile.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("error reading CONFIG_FILE %s: %w", path, err)
+		}
+		if err := checkKnownKeys(yamlFile); err != nil {
+			return nil, fmt.Errorf("CONFIG_FILE %s: %w", path, err)
+		}
+	}
+
+	// Archivo .env: directorio actual o directorios cmd/* al ejecutar desde la raíz del proyecto
+	dotEnv := viper.New()
+	dotEnv.SetConfigName(".env")
+	dotEnv.SetConfigType("env")
+	dotEnv.AddConfigPath(".")
+	dotEnv.AddConfigPath("cmd/api")
+	dotEnv.AddConfigPath("cmd/websocket")
+	if err := dotEnv.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, fmt.Errorf("error reading config file: %w", err)
 		}
+		// Archivo .env no encontrado, no es un error si las variables de entorno están seteadas
+		warnings = append(warnings, ".env file not found. Relying on environment variables and defaults.")
+	}
+
+	// .env tiene prioridad sobre el YAML; las variables de entorno, sobre ambos (AutomaticEnv)
+	if err := viper.MergeConfigMap(yamlFile.AllSettings()); err != nil {
+		return nil, fmt.Errorf("error merging CONFIG_FILE: %w", err)
+	}
+	if err := viper.MergeConfigMap(dotEnv.AllSettings()); err != nil {
+		return nil, fmt.Errorf("error merging .env file: %w", err)
 	}
 
 	var cfg Config
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("unable to decode config into struct: %w", err)
 	}
+	cfg.sources = valueSources(dotEnv, yamlFile)
 
-	// Validar configuración esencial
 	if cfg.DatabaseDSN == "" {
-		// Intentar construir DSN desde variables individuales si DSN completo no está
+		// Construir el DSN desde las variables individuales si DB_DSN no está
 		dbUser := viper.GetString("DB_USER")
-		dbPassword := viper.GetString("DB_PASSWORD")
-		dbHost := viper.GetString("DB_HOST")
-		dbPort := viper.GetString("DB_PORT")
 		dbName := viper.GetString("DB_NAME")
-
 		if dbUser == "" || dbName == "" {
 			return nil, fmt.Errorf("DB_USER and DB_NAME are required if DB_DSN is not set")
 		}
-		if dbHost == "" {
-			dbHost = "127.0.0.1" // Default host if not set but others are
-		}
-		if dbPort == "" {
-			dbPort = "3306" // Default port if not set but others are
-		}
-
 		cfg.DatabaseDSN = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
-			dbUser, dbPassword, dbHost, dbPort, dbName)
+			dbUser, viper.GetString("DB_PASSWORD"), viper.GetString("DB_HOST"), viper.GetString("DB_PORT"), dbName)
+		cfg.sources["DB_DSN"] = sourceDerived
+	}
 
-	} else {
-		// Si se proporciona DB_DSN, usarlo directamente
-		fmt.Println("Using provided DB_DSN")
+	if (cfg.StorageDriver == "gcs" || cfg.StorageDriver == "") && cfg.GCSBucketName == "" {
+		warnings = append(warnings, "GCS_BUCKET_NAME is not set. File uploads will fail (set STORAGE_DRIVER=local for development).")
 	}
+	if cfg.FieldEncryptionKey == "" && cfg.Environment == EnvProduction {
+		warnings = append(warnings, "FIELD_ENCRYPTION_KEY is not set. DocId, Phone and RIF are stored in plaintext.")
+	}
+	cfg.warnings = warnings
 
-	if cfg.JwtSecret == "" {
-		return nil, fmt.Errorf("JWT_SECRET is required")
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	models.SetAccountIDs(cfg.AccountIDs())
+	fieldCipher, err := cfg.FieldCipher()
+	if err != nil {
+		return nil, err
 	}
+	fieldcrypt.SetDefault(fieldCipher)
+	return &cfg, nil
+}
 
-	if cfg.GCSBucketName == "" {
-		fmt.Println("Warning: GCS_BUCKET_NAME is not set. File uploads will fail if GCS is intended.")
+// FieldCipher devuelve el cifrado de columnas sensibles configurado, o nil si
+// FIELD_ENCRYPTION_KEY está vacío.
+func (c *Config) FieldCipher() (*fieldcrypt.Cipher, error) {
+	if c.FieldEncryptionKey == "" {
+		return nil, nil
+	}
+	var previous []string
+	for _, key := range strings.Split(c.FieldEncryptionPreviousKeys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			previous = append(previous, key)
+		}
 	}
+	return fieldcrypt.New(c.FieldEncryptionKey, previous, c.FieldEncryptionIndexKey)
+}
 
-	return &cfg, nil
+// AccountIDs devuelve los Id de roles y estados configurados.
+func (c *Config) AccountIDs() models.AccountIDs {
+	return models.AccountIDs{
+		RoleStudent:           c.RoleStudentID,
+		RoleAlumni:            c.RoleAlumniID,
+		RoleBusiness:          c.RoleBusinessID,
+		RoleGuest:             c.RoleGuestID,
+		RoleAdmin:             c.RoleAdminID,
+		StatusActive:          c.UserStatusActiveID,
+		StatusCompanyPending:  c.UserStatusCompanyPendingID,
+		StatusCompanyApproved: c.UserStatusCompanyApprovedID,
+		StatusSuspended:       c.UserStatusSuspendedID,
+		StatusDeactivated:     c.UserStatusDeactivatedID,
+	}
 }
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// ProxyRoute define cómo el proxy reenvía las peticiones cuyo path empieza por Prefix.
+type ProxyRoute struct {
+	Name     string `mapstructure:"name"`
+	Prefix   string `mapstructure:"prefix"`
+	Upstream string `mapstructure:"upstream"` // p.ej. http://localhost:8081 o ws://localhost:8082
+	// Upstreams define varias instancias del mismo servicio (alternativa a Upstream).
+	Upstreams []string `mapstructure:"upstreams"`
+	// Balance es la estrategia de reparto entre Upstreams: round_robin, least_connections o hash.
+	// Vacío = hash en rutas WebSocket (afinidad por usuario) y round_robin en HTTP.
+	Balance string `mapstructure:"balance"`
+	// WebSocket indica que la ruta se atiende con el proxy de WebSocket en lugar del HTTP.
+	WebSocket bool `mapstructure:"websocket"`
+	// StripPrefix elimina Prefix del path antes de reenviar la petición.
+	StripPrefix bool `mapstructure:"strip_prefix"`
+	// Timeout es el tiempo máximo de la petición al upstream (0 = sin límite). No aplica a WebSocket.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Retries es el número de reintentos ante errores de conexión (solo métodos idempotentes).
+	Retries int `mapstructure:"retries"`
+	// RetryBackoff es la espera inicial entre reintentos; se duplica en cada intento.
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+	// HealthPath es el endpoint del upstream que usa el health check activo del proxy.
+	HealthPath string `mapstructure:"health_path"`
+}
+
+// Estrategias de reparto entre varios upstreams de una ruta.
+const (
+	BalanceRoundRobin       = "round_robin"
+	BalanceLeastConnections = "least_connections"
+	// BalanceHash reparte por hash consistente del usuario autenticado, de modo que un
+	// usuario que reconecta vuelve a la misma instancia.
+	BalanceHash = "hash"
+)
+
+// UpstreamURLs devuelve las instancias de la ruta, ya sea de Upstreams o de Upstream.
+func (r ProxyRoute) UpstreamURLs() []string {
+	if len(r.Upstreams) > 0 {
+		return r.Upstreams
+	}
+	return []string{r.Upstream}
+}
+
+// BalanceStrategy devuelve la estrategia de reparto, aplicando el valor por defecto.
+func (r ProxyRoute) BalanceStrategy() string {
+	if r.Balance != "" {
+		return r.Balance
+	}
+	if r.WebSocket {
+		return BalanceHash
+	}
+	return BalanceRoundRobin
+}
+
+// defaultHealthPath es el endpoint de liveness que exponen todos los servicios.
+const defaultHealthPath = "/healthz"
+
+// proxyRoutesFile es la estructura del archivo YAML de rutas del proxy.
+type proxyRoutesFile struct {
+	Routes []ProxyRoute `mapstructure:"routes"`
+}
+
+// DefaultProxyRoutes reproduce el enrutamiento histórico: /api/ → API y /ws → WebSocket.
+func DefaultProxyRoutes(cfg *Config) []ProxyRoute {
+	return []ProxyRoute{
+		{
+			Name:         "api",
+			Prefix:       "/api/",
+			Upstream:     fmt.Sprintf("http://localhost:%s", cfg.ApiPort),
+			Retries:      2,
+			RetryBackoff: 200 * time.Millisecond,
+			HealthPath:   defaultHealthPath,
+		},
+		{
+			Name:       "websocket",
+			Prefix:     "/ws",
+			Upstream:   fmt.Sprintf("ws://localhost:%s", cfg.WsPort),
+			WebSocket:  true,
+			HealthPath: defaultHealthPath,
+		},
+	}
+}
+
+// LoadProxyRoutes carga la tabla de rutas desde PROXY_ROUTES_FILE o, si no está
+// configurado, devuelve las rutas por defecto.
+func LoadProxyRoutes(cfg *Config) ([]ProxyRoute, error) {
+	if cfg.ProxyRoutesFile == "" {
+		return DefaultProxyRoutes(cfg), nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(cfg.ProxyRoutesFile)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("error reading proxy routes file %s: %w", cfg.ProxyRoutesFile, err)
+	}
+
+	var file proxyRoutesFile
+	if err := v.Unmarshal(&file); err != nil {
+		return nil, fmt.Errorf("unable to decode proxy routes: %w", err)
+	}
+	if len(file.Routes) == 0 {
+		return nil, fmt.Errorf("proxy routes file %s does not define any route", cfg.ProxyRoutesFile)
+	}
+
+	for i := range file.Routes {
+		if err := validateProxyRoute(&file.Routes[i]); err != nil {
+			return nil, err
+		}
+	}
+	return file.Routes, nil
+}
+
+// validateProxyRoute comprueba los campos obligatorios y completa los opcionales.
+func validateProxyRoute(route *ProxyRoute) error {
+	if !strings.HasPrefix(route.Prefix, "/") {
+		return fmt.Errorf("proxy route %q: prefix must start with '/'", route.Name)
+	}
+	if route.Name == "" {
+		route.Name = route.Prefix
+	}
+	if route.HealthPath == "" {
+		route.HealthPath = defaultHealthPath
+	}
+
+	if route.Upstream != "" && len(route.Upstreams) > 0 {
+		return fmt.Errorf("proxy route %q: use either upstream or upstreams, not both", route.Name)
+	}
+	for _, upstream := range route.UpstreamURLs() {
+		if err := validateUpstream(route, upstream); err != nil {
+			return err
+		}
+	}
+
+	switch route.Balance {
+	case "", BalanceRoundRobin, BalanceLeastConnections, BalanceHash:
+	default:
+		return fmt.Errorf("proxy route %q: unsupported balance %q (use %s, %s or %s)",
+			route.Name, route.Balance, BalanceRoundRobin, BalanceLeastConnections, BalanceHash)
+	}
+
+	if route.Retries < 0 || route.Timeout < 0 || route.RetryBackoff < 0 {
+		return fmt.Errorf("proxy route %q: timeout, retries and retry_backoff must not be negative", route.Name)
+	}
+	return nil
+}
+
+// validateUpstream comprueba que el esquema del upstream corresponda al tipo de ruta.
+func validateUpstream(route *ProxyRoute, upstream string) error {
+	u, err := url.Parse(upstream)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("proxy route %q: invalid upstream %q", route.Name, upstream)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if route.WebSocket {
+			return fmt.Errorf("proxy route %q: websocket routes require a ws:// or wss:// upstream", route.Name)
+		}
+	case "ws", "wss":
+		if !route.WebSocket {
+			return fmt.Errorf("proxy route %q: ws:// upstreams require websocket: true", route.Name)
+		}
+	default:
+		return fmt.Errorf("proxy route %q: unsupported upstream scheme %q", route.Name, u.Scheme)
+	}
+	return nil
+}
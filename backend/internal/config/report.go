@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/go-sql-driver/mysql"
+	"github.com/spf13/viper"
+)
+
+// Orígenes de los valores en el informe de arranque.
+const (
+	sourceEnv     = "entorno"
+	sourceDotEnv  = ".env"
+	sourceDefault = "defecto"
+	sourceDerived = "derivado" // DB_DSN construido con DB_USER, DB_HOST...
+)
+
+const redacted = "****"
+
+// Setting es un valor de la configuración efectiva, con los secretos ocultos.
+type Setting struct {
+	Key    string
+	Value  string
+	Source string // entorno, .env, ruta del CONFIG_FILE, defecto o derivado
+}
+
+// Report devuelve la configuración efectiva en el orden de Config. Los campos con la etiqueta
+// secret solo indican si tienen valor; del DSN se oculta la contraseña.
+func (c *Config) Report() []Setting {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	settings := make([]Setting, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+		value := formatValue(v.Field(i).Interface())
+		switch field.Tag.Get("secret") {
+		case "true":
+			if value != `""` {
+				value = redacted
+			}
+		case "dsn":
+			value = fmt.Sprintf("%q", redactDSN(v.Field(i).String()))
+		}
+		source := c.sources[key]
+		if source == "" {
+			source = sourceDefault
+		}
+		settings = append(settings, Setting{Key: key, Value: value, Source: source})
+	}
+	return settings
+}
+
+// LogReport registra la configuración efectiva y los avisos de la carga al arrancar.
+func (c *Config) LogReport(component string) {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, s := range c.Report() {
+		fmt.Fprintf(w, "  %s\t%s\t(%s)\n", s.Key, s.Value, s.Source)
+	}
+	w.Flush()
+	logger.Infof(component, "Configuración efectiva (ENVIRONMENT=%s):\n%s", c.Environment, strings.TrimRight(b.String(), "\n"))
+	for _, warning := range c.warnings {
+		logger.Warn(component, warning)
+	}
+}
+
+// valueSources indica de dónde sale cada clave de la configuración.
+func valueSources(dotEnv, yamlFile *viper.Viper) map[string]string {
+	sources := make(map[string]string)
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+		switch {
+		case envSet(key):
+			sources[key] = sourceEnv
+		case dotEnv.IsSet(key):
+			sources[key] = sourceDotEnv
+		case yamlFile.IsSet(key):
+			sources[key] = yamlFile.ConfigFileUsed()
+		default:
+			sources[key] = sourceDefault
+		}
+	}
+	return sources
+}
+
+// envSet replica AutomaticEnv: una variable vacía no cuenta.
+func envSet(key string) bool {
+	value, ok := os.LookupEnv(key)
+	return ok && value != ""
+}
+
+// redactDSN oculta la contraseña del DSN. Si no se puede interpretar, lo oculta entero.
+func redactDSN(dsn string) string {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return redacted
+	}
+	if cfg.Passwd != "" {
+		cfg.Passwd = redacted
+	}
+	return cfg.FormatDSN()
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case time.Duration:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Entornos de ejecución (ENVIRONMENT).
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+const (
+	// Valores por defecto pensados solo para desarrollo: Validate los rechaza en producción.
+	defaultJWTSecret     = "un-secreto-muy-seguro-cambiar-en-produccion"
+	defaultAdminPassword = "admin123"
+	// minProductionSecretLength es la longitud mínima de JWT_SECRET en producción.
+	minProductionSecretLength = 32
+)
+
+// Validate revisa la configuración y devuelve todos los problemas encontrados a la vez, para no
+// tener que arrancar una vez por cada valor erróneo.
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	switch c.Environment {
+	case EnvDevelopment, EnvStaging, EnvProduction:
+	default:
+		add("ENVIRONMENT debe ser %s, %s o %s (es %q)", EnvDevelopment, EnvStaging, EnvProduction, c.Environment)
+	}
+
+	for _, port := range []struct{ key, value string }{
+		{"API_PORT", c.ApiPort},
+		{"WS_PORT", c.WsPort},
+		{"PROXY_PORT", c.ProxyPort},
+	} {
+		if n, err := strconv.Atoi(port.value); err != nil || n < 1 || n > 65535 {
+			add("%s debe ser un puerto entre 1 y 65535 (es %q)", port.key, port.value)
+		}
+	}
+
+	// Obligatorios
+	if c.JwtSecret == "" {
+		add("JWT_SECRET es obligatorio")
+	}
+	if c.AdminUsername == "" || c.AdminPassword == "" {
+		add("ADMIN_USERNAME y ADMIN_PASSWORD son obligatorios")
+	}
+	if c.Environment == EnvProduction {
+		if c.JwtSecret == defaultJWTSecret || len(c.JwtSecret) < minProductionSecretLength {
+			add("JWT_SECRET debe cambiarse y tener al menos %d caracteres en producción", minProductionSecretLength)
+		}
+		if c.AdminPassword == defaultAdminPassword {
+			add("ADMIN_PASSWORD no puede ser la contraseña por defecto en producción")
+		}
+	}
+	if c.SMTPHost != "" && c.SMTPFrom == "" {
+		add("SMTP_FROM es obligatorio si SMTP_HOST está configurado")
+	}
+
+	// Ninguna duración ni cantidad admite valores negativos
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		key := v.Type().Field(i).Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+		switch value := v.Field(i).Interface().(type) {
+		case time.Duration:
+			if value < 0 {
+				add("%s no puede ser negativo (es %s)", key, value)
+			}
+		case int:
+			if value < 0 {
+				add("%s no puede ser negativo (es %d)", key, value)
+			}
+		case int64:
+			if value < 0 {
+				add("%s no puede ser negativo (es %d)", key, value)
+			}
+		}
+	}
+
+	// Rangos y relaciones entre valores
+	if c.DBMaxOpenConns < 1 {
+		add("DB_MAX_OPEN_CONNS debe ser al menos 1 (es %d)", c.DBMaxOpenConns)
+	}
+	if c.DBReadOnlyCheckInterval > 0 && (c.DBReadOnlyFailures < 1 || c.DBReadOnlyRecovery < 1) {
+		add("DB_READONLY_FAILURES y DB_READONLY_RECOVERY deben ser al menos 1 (son %d y %d)", c.DBReadOnlyFailures, c.DBReadOnlyRecovery)
+	}
+	if c.RequestLogSampleRate < 0 || c.RequestLogSampleRate > 1 {
+		add("REQUEST_LOG_SAMPLE_RATE debe estar entre 0 y 1 (es %g)", c.RequestLogSampleRate)
+	}
+	if c.CompressionLevel > 9 {
+		add("COMPRESSION_LEVEL debe estar entre 0 y 9 (es %d)", c.CompressionLevel)
+	}
+	for _, threshold := range []struct {
+		key   string
+		value float64
+	}{
+		{"IMAGE_MODERATION_REJECT_THRESHOLD", c.ImageModerationRejectThreshold},
+		{"IMAGE_MODERATION_REVIEW_THRESHOLD", c.ImageModerationReviewThreshold},
+	} {
+		if threshold.value < 0 || threshold.value > 1 {
+			add("%s debe estar entre 0 y 1 (es %g)", threshold.key, threshold.value)
+		}
+	}
+	if c.ImageModerationReviewThreshold > c.ImageModerationRejectThreshold {
+		add("IMAGE_MODERATION_REVIEW_THRESHOLD no puede ser mayor que IMAGE_MODERATION_REJECT_THRESHOLD")
+	}
+	if c.PresenceHeartbeatInterval > 0 && c.PresenceTTL > 0 && c.PresenceTTL <= c.PresenceHeartbeatInterval {
+		add("PRESENCE_TTL (%s) debe ser mayor que PRESENCE_HEARTBEAT_INTERVAL (%s)", c.PresenceTTL, c.PresenceHeartbeatInterval)
+	}
+	if c.GraphQLEnabled && c.GraphQLComplexityLimit < 1 {
+		add("GRAPHQL_COMPLEXITY_LIMIT debe ser al menos 1 con GRAPHQL_ENABLED (es %d)", c.GraphQLComplexityLimit)
+	}
+	if c.OutboxPollInterval > 0 && c.OutboxBatchSize < 1 {
+		add("OUTBOX_BATCH_SIZE debe ser al menos 1 (es %d)", c.OutboxBatchSize)
+	}
+	// Los Id de roles y estados con significado fijo: positivos y, cuando el código los
+	// distingue, distintos entre sí
+	roles := []struct {
+		key   string
+		value int
+	}{
+		{"ROLE_STUDENT_ID", c.RoleStudentID},
+		{"ROLE_ALUMNI_ID", c.RoleAlumniID},
+		{"ROLE_BUSINESS_ID", c.RoleBusinessID},
+		{"ROLE_GUEST_ID", c.RoleGuestID},
+		{"ROLE_ADMIN_ID", c.RoleAdminID},
+	}
+	statuses := []struct {
+		key   string
+		value int
+	}{
+		{"USER_STATUS_ACTIVE_ID", c.UserStatusActiveID},
+		{"USER_STATUS_COMPANY_APPROVED_ID", c.UserStatusCompanyApprovedID},
+		{"USER_STATUS_SUSPENDED_ID", c.UserStatusSuspendedID},
+		{"USER_STATUS_DEACTIVATED_ID", c.UserStatusDeactivatedID},
+	}
+	for _, group := range [][]struct {
+		key   string
+		value int
+	}{roles, statuses} {
+		seen := make(map[int]string, len(group))
+		for _, id := range group {
+			if id.value < 1 {
+				add("%s debe ser al menos 1 (es %d)", id.key, id.value)
+				continue
+			}
+			if other, ok := seen[id.value]; ok {
+				add("%s y %s no pueden tener el mismo Id (%d)", other, id.key, id.value)
+			}
+			seen[id.value] = id.key
+		}
+	}
+	if c.UserStatusCompanyPendingID < 1 {
+		add("USER_STATUS_COMPANY_PENDING_ID debe ser al menos 1 (es %d)", c.UserStatusCompanyPendingID)
+	} else if c.UserStatusCompanyPendingID == c.UserStatusCompanyApprovedID {
+		add("USER_STATUS_COMPANY_PENDING_ID y USER_STATUS_COMPANY_APPROVED_ID no pueden tener el mismo Id (%d)", c.UserStatusCompanyPendingID)
+	}
+
+	if c.FieldEncryptionKey == "" {
+		if c.FieldEncryptionPreviousKeys != "" {
+			add("FIELD_ENCRYPTION_PREVIOUS_KEYS requiere FIELD_ENCRYPTION_KEY")
+		}
+	} else if c.FieldEncryptionIndexKey == "" {
+		add("FIELD_ENCRYPTION_INDEX_KEY es obligatorio si FIELD_ENCRYPTION_KEY está configurado")
+	} else if _, err := c.FieldCipher(); err != nil {
+		add("FIELD_ENCRYPTION_*: %v", err)
+	}
+
+	for _, sunset := range []struct{ key, value string }{
+		{"API_V1_SUNSET", c.APIV1Sunset},
+		{"API_LEGACY_SUNSET", c.APILegacySunset},
+	} {
+		if sunset.value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", sunset.value); err != nil {
+			add("%s debe ser una fecha YYYY-MM-DD (es %q)", sunset.key, sunset.value)
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration:\n  - " + strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// dsnParts son las claves con las que se construye DB_DSN si no está definido.
+var dsnParts = []string{"DB_USER", "DB_PASSWORD", "DB_HOST", "DB_PORT", "DB_NAME"}
+
+// checkKnownKeys rechaza las claves del archivo YAML que no corresponden a ningún valor de
+// Config, para que una errata no se ignore en silencio.
+func checkKnownKeys(file *viper.Viper) error {
+	known := make(map[string]bool)
+	for _, key := range dsnParts {
+		known[strings.ToLower(key)] = true
+	}
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if key := t.Field(i).Tag.Get("mapstructure"); key != "" {
+			known[strings.ToLower(key)] = true
+		}
+	}
+
+	var unknown []string
+	for _, key := range file.AllKeys() {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown keys: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/models" // Ajusta la ruta si es necesario
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/taxonomy"
 	"github.com/go-sql-driver/mysql"
 )
 
@@ -18,15 +19,38 @@ var (
 )
 
 // Connect initializes the database connection.
-// It expects the DSN (Data Source Name) for the MySQL database.
-func Connect(dsn string) (*sql.DB, error) {
+// It expects the DSN (Data Source Name) for the MySQL database and the pool settings.
+// The connection is opened once and shared through GetDB.
+func Connect(dsn string, pool PoolConfig) (*sql.DB, error) {
 	var err error
+	once.Do(func() {
+		db, err = Open(dsn, pool)
+		if err != nil {
+			once = sync.Once{} // Reset once so connection can be retried
+			return
+		}
+		logger.Success("DB", "Database connection successful!")
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	if db == nil { // Check if connection failed inside once.Do
+		return nil, fmt.Errorf("database connection failed and was reset")
+	}
+	return db, nil
+}
 
+// Open opens a new connection pool for dsn, creating the database if it doesn't exist.
+// Unlike Connect it doesn't register the pool for GetDB, so it can be called several times
+// (e.g. the integration test harness opens one per test database).
+func Open(dsn string, pool PoolConfig) (*sql.DB, error) {
 	// Parse the DSN using the MySQL driver's parser
 	cfg, err := mysql.ParseDSN(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse DSN: %w", err)
 	}
+	useUTC(cfg)
 
 	dbName := cfg.DBName
 	// Temporarily remove the database name to connect to the server
@@ -46,34 +70,42 @@ func Connect(dsn string) (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to create database '%s': %w", dbName, err)
 	}
 
-	// Now, connect to the specific database
-	once.Do(func() {
-		db, err = sql.Open("mysql", dsn)
-		if err != nil {
-			return // err will be handled outside once.Do
-		}
-
-		db.SetConnMaxLifetime(time.Minute * 3)
-		db.SetMaxOpenConns(10)
-		db.SetMaxIdleConns(10)
-
-		err = db.Ping()
-		if err != nil {
-			db.Close()         // Close the connection if ping fails
-			db = nil           // Reset db to nil so subsequent calls can retry
-			once = sync.Once{} // Reset once so connection can be retried
-			return
-		}
-		logger.Success("DB", "Database connection successful!")
-	})
-
+	// Now, connect to the specific database. The connector is wrapped so every
+	// query is measured (see instrument.go).
+	cfg.DBName = dbName
+	connector, err := mysql.NewConnector(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
-	if db == nil { // Check if connection failed inside once.Do
-		return nil, fmt.Errorf("database connection failed and was reset")
+	conn := sql.OpenDB(instrumentedConnector{Connector: connector})
+	pool.apply(conn)
+
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
-	return db, nil
+	return conn, nil
+}
+
+// useUTC makes every connection work in UTC, whatever the DSN says: DATETIME/TIMESTAMP
+// columns are scanned into time.Time (parseTime) as UTC values, and the session time zone is
+// UTC so NOW() and CURRENT_TIMESTAMP match the times written from Go. A time_zone set
+// explicitly in the DSN is kept.
+func useUTC(cfg *mysql.Config) {
+	cfg.ParseTime = true
+	cfg.Loc = time.UTC
+	if _, ok := cfg.Params["time_zone"]; !ok {
+		if cfg.Params == nil {
+			cfg.Params = make(map[string]string)
+		}
+		cfg.Params["time_zone"] = "'+00:00'"
+	}
+}
+
+// SetDB replaces the pool returned by GetDB. The integration test harness uses it to point
+// the handlers that call GetDB at the test database.
+func SetDB(conn *sql.DB) {
+	db = conn
 }
 
 // GetDB returns the existing database connection pool.
@@ -102,6 +134,14 @@ func InitializeDatabase(conn *sql.DB) error {
 		return fmt.Errorf("failed to create tables: %w", err)
 	}
 
+	if err := addMissingColumns(tx); err != nil {
+		return fmt.Errorf("failed to add missing columns: %w", err)
+	}
+
+	if err := backfillTables(tx); err != nil {
+		return fmt.Errorf("failed to backfill tables: %w", err)
+	}
+
 	if err := insertDefaultData(tx); err != nil {
 		return fmt.Errorf("failed to insert default data: %w", err)
 	}
@@ -157,7 +197,9 @@ func createTables(tx *sql.Tx) error {
     CREATE TABLE IF NOT EXISTS University (
         Id BIGINT AUTO_INCREMENT PRIMARY KEY,
         Name VARCHAR(255) UNIQUE,
-        Campus VARCHAR(255)
+        Campus VARCHAR(255),
+        EmailDomains VARCHAR(1000) NOT NULL DEFAULT '', -- Dominios de correo institucional separados por comas
+        Slug VARCHAR(63) NULL UNIQUE -- Subdominio de la institución (ver docs/multi_institucion.md)
     );
 
     CREATE TABLE IF NOT EXISTS Degree (
@@ -208,7 +250,8 @@ Twitter VARCHAR(255),
 Facebook VARCHAR(255),
         Phone VARCHAR(255),
         Sex VARCHAR(255),
-        DocId VARCHAR(255) UNIQUE,
+        DocId VARCHAR(255) UNIQUE, -- Cifrado con FIELD_ENCRYPTION_KEY (ver docs/cifrado_campos.md)
+        DocIdHash CHAR(64) NULL UNIQUE, -- Índice ciego de DocId para buscarlo cifrado
         NationalityId INT,
         Birthdate DATE,
         Picture VARCHAR(255),
@@ -220,7 +263,8 @@ Summary TEXT,
         Address VARCHAR(255),
         Github VARCHAR(255),
         Linkedin VARCHAR(255),
-RIF VARCHAR(20) UNIQUE,
+RIF VARCHAR(255) UNIQUE, -- Cifrado como DocId y Phone
+RIFHash CHAR(64) NULL UNIQUE, -- Índice ciego de RIF
 Sector VARCHAR(100),
 CompanyName VARCHAR(255),
 Location VARCHAR(255),
@@ -232,17 +276,22 @@ dmeta_company_primary VARCHAR(24) NOT NULL DEFAULT '',
 dmeta_company_secondary VARCHAR(24) NOT NULL DEFAULT '',
 CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+TenantId BIGINT NULL, -- Institución de la cuenta. NULL = sin institución (empresas, administradores globales)
+        INDEX idx_user_tenant (TenantId),
         FOREIGN KEY (NationalityId) REFERENCES Nationality(Id),
         FOREIGN KEY (DegreeId) REFERENCES Degree(Id),
         FOREIGN KEY (UniversityId) REFERENCES University(Id),
         FOREIGN KEY (RoleId) REFERENCES Role(Id),
-        FOREIGN KEY (StatusAuthorizedId) REFERENCES StatusAuthorized(Id)
+        FOREIGN KEY (StatusAuthorizedId) REFERENCES StatusAuthorized(Id),
+        FOREIGN KEY (TenantId) REFERENCES University(Id) ON DELETE SET NULL
     );
 
     CREATE TABLE IF NOT EXISTS Online (
         UserOnlineId BIGINT PRIMARY KEY,
 CreateAt DATE,
         Status TINYINT(1),
+        LastSeenAt DATETIME, -- Último heartbeat. Con Status = 1 caducado, el reaper lo pasa a offline
+        INDEX idx_online_status_seen (Status, LastSeenAt),
 FOREIGN KEY (UserOnlineId) REFERENCES User(Id)
 );
 
@@ -281,7 +330,8 @@ FOREIGN KEY (AdminOfGroup) REFERENCES User(Id)
     HLSManifestBaseURL VARCHAR(255),
     HLSManifest1080p VARCHAR(255),
     HLSManifest720p VARCHAR(255),
-    HLSManifest480p VARCHAR(255)
+    HLSManifest480p VARCHAR(255),
+    RefCount INT NOT NULL DEFAULT 0 -- Mensajes (también archivados) que adjuntan el archivo
     );
 
     CREATE TABLE IF NOT EXISTS Session (
@@ -291,6 +341,14 @@ Tk VARCHAR(255),
         Ip VARCHAR(255),
         RoleId INT,
         TokenId INT,
+        UserAgent VARCHAR(512),
+        DeviceType VARCHAR(20),
+        Browser VARCHAR(50),
+        OS VARCHAR(50),
+        Country CHAR(2),
+        City VARCHAR(100),
+        CreatedAt DATETIME DEFAULT CURRENT_TIMESTAMP,
+        INDEX idx_session_user_created (UserId, CreatedAt),
 FOREIGN KEY (UserId) REFERENCES User(Id),
 FOREIGN KEY (RoleId) REFERENCES Role(Id)
 );
@@ -325,11 +383,21 @@ Mejoras sobre la versión original:
     -- Para mensajes que son una respuesta a otro.
     ReplyToMessageId VARCHAR(255),
 
+    -- Para mensajes reenviados: mensaje y autor originales. Sin clave foránea para que la
+    -- marca se conserve aunque el original se archive o se borre.
+    ForwardedFromMessageId VARCHAR(255),
+    ForwardedFromSenderId BIGINT,
+
     SentAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     EditedAt DATETIME, -- Se actualiza si el mensaje es editado.
 
     Status ENUM('sending', 'sent', 'delivered', 'read', 'failed') NOT NULL DEFAULT 'sending',
 
+    -- Id que el cliente genera para el mensaje. Si reintenta el envío tras perder la
+    -- confirmación, el índice único impide guardarlo dos veces (ver guardado_mensajes_chat.md).
+    ClientMessageId VARCHAR(64) NULL,
+    UNIQUE KEY uq_message_client (SenderId, ClientMessageId),
+
     FOREIGN KEY (SenderId) REFERENCES User(Id),
     FOREIGN KEY (TypeMessageId) REFERENCES TypeMessage(Id),
     FOREIGN KEY (MediaId) REFERENCES Multimedia(Id),
@@ -399,6 +467,26 @@ FOREIGN KEY (PersonId) REFERENCES User(Id)
 FOREIGN KEY (PersonId) REFERENCES User(Id)
     );
 
+    -- Habilidades canónicas. Skills.SkillCatalogId apunta aquí cuando la normalización reconoce
+    -- el texto escrito por el usuario.
+    CREATE TABLE IF NOT EXISTS SkillCatalog (
+        Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+        Name VARCHAR(100) NOT NULL,
+        NormalizedName VARCHAR(100) NOT NULL,
+        Category VARCHAR(100) NOT NULL DEFAULT '',
+        CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        UNIQUE KEY uq_skill_catalog_name (NormalizedName)
+    );
+
+    -- Otras formas de escribir una habilidad canónica ("JS" para JavaScript). Alias está
+    -- normalizado (minúsculas, sin acentos, espacios ni puntuación) igual que NormalizedName.
+    CREATE TABLE IF NOT EXISTS SkillAlias (
+        Alias VARCHAR(100) PRIMARY KEY,
+        SkillId BIGINT NOT NULL,
+        INDEX idx_skill_alias_skill (SkillId),
+        FOREIGN KEY (SkillId) REFERENCES SkillCatalog(Id) ON DELETE CASCADE
+    );
+
     CREATE TABLE IF NOT EXISTS Skills (
         Id BIGINT AUTO_INCREMENT PRIMARY KEY,
         PersonId BIGINT,
@@ -406,7 +494,10 @@ FOREIGN KEY (PersonId) REFERENCES User(Id)
         Level VARCHAR(255),
 dmeta_primary VARCHAR(12) NOT NULL DEFAULT '',
 dmeta_secondary VARCHAR(12) NOT NULL DEFAULT '',
-FOREIGN KEY (PersonId) REFERENCES User(Id)
+SkillCatalogId BIGINT NULL,
+INDEX idx_skills_catalog (SkillCatalogId),
+FOREIGN KEY (PersonId) REFERENCES User(Id),
+CONSTRAINT fk_skills_catalog FOREIGN KEY (SkillCatalogId) REFERENCES SkillCatalog(Id) ON DELETE SET NULL
     );
 
 
@@ -456,10 +547,16 @@ dmeta_title_primary VARCHAR(24) NOT NULL DEFAULT '',
 dmeta_title_secondary VARCHAR(24) NOT NULL DEFAULT '',
 CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+-- Agrupación de notificaciones parecidas (GroupId es el grupo de chat): clave del grupo,
+-- número de eventos agrupados y últimos usuarios que lo originaron.
+GroupKey VARCHAR(191) NULL,
+GroupCount INT NOT NULL DEFAULT 1,
+GroupActors JSON NULL,
 FOREIGN KEY (UserId) REFERENCES User(Id),
 FOREIGN KEY (OtherUserId) REFERENCES User(Id),
 FOREIGN KEY (ProyectId) REFERENCES Project(Id),
-FOREIGN KEY (GroupId) REFERENCES GroupsUsers(Id)
+FOREIGN KEY (GroupId) REFERENCES GroupsUsers(Id),
+INDEX idx_event_group_key (UserId, GroupKey, IsRead)
 );
 
 
@@ -510,9 +607,12 @@ CREATE TABLE IF NOT EXISTS CommunityEvent (
     dmeta_title_secondary VARCHAR(24) NOT NULL DEFAULT '',
     CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    TenantId BIGINT NULL, -- Institución del autor al publicar. NULL = visible en todas
 
+    INDEX idx_community_event_tenant (TenantId),
     FOREIGN KEY (OrganizerUserId) REFERENCES User(Id) ON DELETE SET NULL,
-    FOREIGN KEY (CreatedByUserId) REFERENCES User(Id) ON DELETE CASCADE
+    FOREIGN KEY (CreatedByUserId) REFERENCES User(Id) ON DELETE CASCADE,
+    FOREIGN KEY (TenantId) REFERENCES University(Id) ON DELETE SET NULL
 );
 
 
@@ -627,6 +727,619 @@ CREATE TABLE IF NOT EXISTS JobApplication (
     -- Restricción para asegurar que un usuario no pueda postularse dos veces a la misma oferta.
     UNIQUE KEY uq_event_applicant (CommunityEventId, ApplicantId)
     );
+
+-- Requisitos de una oferta para el motor de recomendaciones. Skills, Degrees y Languages son
+-- arrays JSON: [{"name","skillCatalogId","required"}], ["carrera", ...] y [{"language","level"}].
+CREATE TABLE IF NOT EXISTS JobPostingRequirements (
+    CommunityEventId BIGINT PRIMARY KEY,
+    Skills JSON NOT NULL,
+    Degrees JSON NOT NULL,
+    Languages JSON NOT NULL,
+    MinExperienceYears INT NOT NULL DEFAULT 0,
+    UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (CommunityEventId) REFERENCES CommunityEvent(Id) ON DELETE CASCADE
+);
+
+-- Búsquedas guardadas con alertas. LastSeenItemId es el mayor ID de CommunityEvent (JOBS) o de
+-- User (PEOPLE) ya evaluado por el job de alertas.
+CREATE TABLE IF NOT EXISTS SavedSearch (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    UserId BIGINT NOT NULL,
+    Name VARCHAR(100) NOT NULL,
+    SearchType ENUM('JOBS', 'PEOPLE') NOT NULL,
+    Query VARCHAR(255) NOT NULL DEFAULT '',
+    Filters JSON NOT NULL,
+    AlertsEnabled BOOLEAN NOT NULL DEFAULT TRUE,
+    LastSeenItemId BIGINT NOT NULL DEFAULT 0,
+    LastAlertAt DATETIME NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE,
+    INDEX idx_saved_search_user (UserId),
+    INDEX idx_saved_search_alerts (SearchType, AlertsEnabled, LastSeenItemId)
+);
+
+-- Bloqueos entre usuarios. Mientras exista la fila, ninguno de los dos puede enviar al otro
+-- solicitudes de contacto ni mensajes, y sus publicaciones y perfiles no se muestran entre sí.
+CREATE TABLE IF NOT EXISTS UserBlock (
+    BlockerId BIGINT NOT NULL,
+    BlockedId BIGINT NOT NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (BlockerId, BlockedId),
+    INDEX idx_user_block_blocked (BlockedId),
+    FOREIGN KEY (BlockerId) REFERENCES User(Id) ON DELETE CASCADE,
+    FOREIGN KEY (BlockedId) REFERENCES User(Id) ON DELETE CASCADE
+);
+
+
+-- Copia de los mensajes archivados por la política de retención. Sin claves foráneas:
+-- los mensajes deben sobrevivir aunque se eliminen sus chats, medios o mensajes respondidos.
+CREATE TABLE IF NOT EXISTS MessageArchive (
+    Id VARCHAR(255) PRIMARY KEY,
+    ChatId VARCHAR(255),
+    ChatIdGroup VARCHAR(255),
+    SenderId BIGINT NOT NULL,
+    TypeMessageId BIGINT NOT NULL,
+    Content TEXT,
+    MediaId VARCHAR(255),
+    ReplyToMessageId VARCHAR(255),
+    ForwardedFromMessageId VARCHAR(255),
+    ForwardedFromSenderId BIGINT,
+    SentAt DATETIME NOT NULL,
+    EditedAt DATETIME,
+    Status VARCHAR(20) NOT NULL,
+    ArchivedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    RunId BIGINT NOT NULL,
+    INDEX idx_message_archive_chat (ChatId, SentAt),
+    INDEX idx_message_archive_group (ChatIdGroup, SentAt)
+);
+
+
+-- Chats (ChatId de Contact o de GroupsUsers) excluidos del archivado de mensajes.
+CREATE TABLE IF NOT EXISTS ChatRetentionOptOut (
+    ChatId VARCHAR(255) PRIMARY KEY,
+    OptedOutBy BIGINT NOT NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (OptedOutBy) REFERENCES User(Id)
+);
+
+
+CREATE TABLE IF NOT EXISTS MessageRetentionRun (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    TriggerType ENUM('scheduled', 'manual') NOT NULL,
+    TriggeredBy BIGINT,
+    Status ENUM('running', 'succeeded', 'failed') NOT NULL DEFAULT 'running',
+    Cutoff DATETIME NOT NULL,
+    Archived BIGINT NOT NULL DEFAULT 0,
+    StartedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FinishedAt DATETIME,
+    ErrorMessage TEXT,
+    INDEX idx_retention_run_status (Status, StartedAt)
+);
+
+-- Registro de auditoría de acciones sensibles. Sin claves foráneas: debe sobrevivir
+-- al borrado de los usuarios implicados.
+CREATE TABLE IF NOT EXISTS AuditLog (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    ActorId BIGINT,
+    ActorName VARCHAR(255),
+    Action VARCHAR(64) NOT NULL,
+    TargetType VARCHAR(32),
+    TargetId VARCHAR(64),
+    IPAddress VARCHAR(45),
+    Metadata JSON,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_audit_created (CreatedAt),
+    INDEX idx_audit_actor (ActorId, CreatedAt),
+    INDEX idx_audit_action (Action, CreatedAt),
+    INDEX idx_audit_target (TargetType, TargetId)
+);
+
+-- Solicitudes de exportación de datos personales y de borrado de cuenta. Sin claves
+-- foráneas: el registro debe sobrevivir al borrado del usuario.
+CREATE TABLE IF NOT EXISTS PrivacyRequest (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    UserId BIGINT NOT NULL,
+    RequestType ENUM('export', 'deletion') NOT NULL,
+    DeletionMode ENUM('anonymize', 'cascade'),
+    Status ENUM('pending', 'processing', 'completed', 'failed') NOT NULL DEFAULT 'pending',
+    Email VARCHAR(255), -- Destinatario del aviso; se borra al completar un borrado de cuenta
+    FilePath VARCHAR(512),
+    ExpiresAt DATETIME,
+    ErrorMessage TEXT,
+    RequestedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CompletedAt DATETIME,
+    INDEX idx_privacy_request_user (UserId, RequestType, Status),
+    INDEX idx_privacy_request_status (Status)
+);
+
+-- Exportaciones de una conversación pedidas por uno de sus participantes. El archivo se sube
+-- a GCS (ObjectPath) y se descarga con un enlace firmado hasta ExpiresAt.
+CREATE TABLE IF NOT EXISTS ChatExport (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    UserId BIGINT NOT NULL,
+    ChatId VARCHAR(255),
+    ChatIdGroup VARCHAR(255),
+    Format ENUM('json', 'txt') NOT NULL,
+    Status ENUM('pending', 'processing', 'completed', 'failed') NOT NULL DEFAULT 'pending',
+    ObjectPath VARCHAR(512),
+    MessageCount INT NOT NULL DEFAULT 0,
+    ExpiresAt DATETIME,
+    ErrorMessage TEXT,
+    RequestedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CompletedAt DATETIME,
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE,
+    INDEX idx_chat_export_user (UserId, Status),
+    INDEX idx_chat_export_status (Status),
+    INDEX idx_chat_export_expires (ExpiresAt)
+);
+
+-- Subidas reanudables de video. El cliente envía el archivo por partes; los bytes recibidos
+-- (ReceivedBytes) se acumulan en un archivo temporal de la instancia hasta completar TotalSize
+-- y al finalizar se procesa como una subida normal (ContentId del video resultante).
+CREATE TABLE IF NOT EXISTS UploadSession (
+    Id VARCHAR(36) PRIMARY KEY,
+    UserId BIGINT NOT NULL,
+    FileName VARCHAR(255) NOT NULL,
+    TotalSize BIGINT NOT NULL,
+    ReceivedBytes BIGINT NOT NULL DEFAULT 0,
+    Status ENUM('uploading', 'processing', 'completed', 'cancelled', 'expired') NOT NULL DEFAULT 'uploading',
+    ContentId VARCHAR(255),
+    ErrorMessage TEXT,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    ExpiresAt DATETIME NOT NULL,
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE,
+    INDEX idx_upload_session_user (UserId, Status),
+    INDEX idx_upload_session_expires (Status, ExpiresAt)
+);
+
+-- Espacio ocupado por los archivos subidos por cada usuario. QuotaBytes es la cuota fijada por
+-- un administrador (NULL = la del rol, 0 = sin límite).
+CREATE TABLE IF NOT EXISTS UserStorage (
+    UserId BIGINT PRIMARY KEY,
+    UsedBytes BIGINT NOT NULL DEFAULT 0,
+    QuotaBytes BIGINT,
+    UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE
+);
+
+-- Archivos subidos que el análisis de malware marcó como infectados. El registro de Multimedia
+-- queda con ProcessingStatus = 'quarantined' y el archivo como objeto privado.
+CREATE TABLE IF NOT EXISTS UploadQuarantine (
+    MultimediaId VARCHAR(255) PRIMARY KEY,
+    OriginalName VARCHAR(255) NOT NULL,
+    Signature VARCHAR(255) NOT NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (MultimediaId) REFERENCES Multimedia(Id) ON DELETE CASCADE,
+    INDEX idx_upload_quarantine_created (CreatedAt)
+);
+
+-- Imágenes subidas que el clasificador automático (IMAGE_MODERATION_*) marcó como dudosas.
+-- Siguen publicadas hasta que un moderador las aprueba o las elimina. ContentId agrupa la
+-- imagen original y sus variantes en Multimedia.
+CREATE TABLE IF NOT EXISTS ImageReview (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    UserId BIGINT NOT NULL,
+    ContentId VARCHAR(255) NOT NULL,
+    FileName VARCHAR(255) NOT NULL,
+    Score DECIMAL(5, 4) NOT NULL,
+    Labels JSON,
+    Status ENUM('PENDIENTE', 'APROBADA', 'ELIMINADA') NOT NULL DEFAULT 'PENDIENTE',
+    ResolutionNote TEXT,
+    ReviewedBy BIGINT,
+    ReviewedAt DATETIME,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE,
+    FOREIGN KEY (ReviewedBy) REFERENCES User(Id) ON DELETE SET NULL,
+    UNIQUE KEY uq_image_review_content (ContentId),
+    INDEX idx_image_review_queue (Status, CreatedAt)
+);
+
+-- Visitas a perfiles de estudiantes y egresados. Si el visitante oculta sus visitas
+-- (UserPrivacySettings.ShareProfileViews = FALSE) se marca IsAnonymous y su identidad no
+-- se muestra; ViewerId se conserva solo para no contar varias veces la misma visita.
+CREATE TABLE IF NOT EXISTS ProfileView (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    ProfileUserId BIGINT NOT NULL,
+    ViewerId BIGINT NOT NULL,
+    ViewerRoleId INT NOT NULL,
+    IsAnonymous BOOLEAN NOT NULL DEFAULT FALSE,
+    ViewedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (ProfileUserId) REFERENCES User(Id) ON DELETE CASCADE,
+    FOREIGN KEY (ViewerId) REFERENCES User(Id) ON DELETE CASCADE,
+    INDEX idx_profile_view_profile (ProfileUserId, ViewedAt),
+    INDEX idx_profile_view_viewer (ViewerId, ProfileUserId, ViewedAt)
+);
+
+-- Preferencias de privacidad. Sin fila se aplican los valores por defecto.
+CREATE TABLE IF NOT EXISTS UserPrivacySettings (
+    UserId BIGINT PRIMARY KEY,
+    ShareProfileViews BOOLEAN NOT NULL DEFAULT TRUE, -- FALSE = sus visitas a otros perfiles son anónimas
+    TrackProfileViews BOOLEAN NOT NULL DEFAULT TRUE, -- FALSE = no se registran las visitas a su perfil
+    LastSeenVisibility VARCHAR(10) NOT NULL DEFAULT 'everyone', -- Quién ve su última conexión: everyone, contacts o nobody
+    UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE
+);
+
+-- Comentarios de las publicaciones de la comunidad. Las respuestas tienen un solo nivel:
+-- ParentCommentId apunta siempre a un comentario raíz. Un comentario con respuestas se
+-- borra de forma lógica (DeletedAt) para no romper el hilo.
+CREATE TABLE IF NOT EXISTS Comment (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    CommunityEventId BIGINT NOT NULL,
+    AuthorId BIGINT NOT NULL,
+    ParentCommentId BIGINT,
+    Content TEXT NOT NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    EditedAt DATETIME,
+    DeletedAt DATETIME,
+    FOREIGN KEY (CommunityEventId) REFERENCES CommunityEvent(Id) ON DELETE CASCADE,
+    FOREIGN KEY (AuthorId) REFERENCES User(Id) ON DELETE CASCADE,
+    FOREIGN KEY (ParentCommentId) REFERENCES Comment(Id) ON DELETE CASCADE,
+    INDEX idx_comment_event (CommunityEventId, ParentCommentId, Id),
+    INDEX idx_comment_parent (ParentCommentId, Id),
+    INDEX idx_comment_author (AuthorId)
+);
+
+-- "Me gusta" y guardados de las publicaciones de la comunidad (incluidas las ofertas de empleo).
+CREATE TABLE IF NOT EXISTS CommunityEventLike (
+    UserId BIGINT NOT NULL,
+    CommunityEventId BIGINT NOT NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (UserId, CommunityEventId),
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE,
+    FOREIGN KEY (CommunityEventId) REFERENCES CommunityEvent(Id) ON DELETE CASCADE,
+    INDEX idx_event_like_event (CommunityEventId, CreatedAt)
+);
+
+CREATE TABLE IF NOT EXISTS CommunityEventBookmark (
+    UserId BIGINT NOT NULL,
+    CommunityEventId BIGINT NOT NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (UserId, CommunityEventId),
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE,
+    FOREIGN KEY (CommunityEventId) REFERENCES CommunityEvent(Id) ON DELETE CASCADE,
+    INDEX idx_event_bookmark_user (UserId, CreatedAt)
+);
+
+-- Entregas de los desafíos (CommunityEvent con PostType = 'DESAFIO'). Una por usuario y
+-- desafío: puede reemplazarse mientras el desafío esté ABIERTO y no se haya revisado. Al
+-- cerrar el desafío las entregas APROBADA reciben PointsRP (ver ReputationReview).
+CREATE TABLE IF NOT EXISTS ChallengeSubmission (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    CommunityEventId BIGINT NOT NULL,
+    UserId BIGINT NOT NULL,
+    RepositoryUrl VARCHAR(512),
+    FileContentId VARCHAR(255), -- Multimedia.ContentId del archivo subido por el usuario
+    Description TEXT,
+    Status ENUM('ENVIADA', 'EN_REVISION', 'APROBADA', 'RECHAZADA') NOT NULL DEFAULT 'ENVIADA',
+    Score TINYINT UNSIGNED, -- 0 a 100
+    Feedback TEXT,
+    ReviewedBy BIGINT,
+    ReviewedAt DATETIME,
+    PointsRP INT,
+    SubmittedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (CommunityEventId) REFERENCES CommunityEvent(Id) ON DELETE CASCADE,
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE,
+    FOREIGN KEY (ReviewedBy) REFERENCES User(Id) ON DELETE SET NULL,
+    UNIQUE KEY uq_challenge_submission (CommunityEventId, UserId),
+    INDEX idx_challenge_submission_status (CommunityEventId, Status, SubmittedAt),
+    INDEX idx_challenge_submission_user (UserId)
+);
+
+-- Reportes de contenido enviados por los usuarios (cola de moderación). TargetId es el ID del
+-- mensaje, publicación, comentario o usuario reportado y TargetUserId su autor.
+-- ContentSnapshot guarda el contenido tal como estaba al reportarlo, para revisarlo aunque se
+-- edite o elimine. Los reportes pendientes de un mismo contenido se resuelven juntos.
+CREATE TABLE IF NOT EXISTS Report (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    ReporterId BIGINT NOT NULL,
+    TargetType ENUM('MESSAGE', 'POST', 'COMMENT', 'PROFILE') NOT NULL,
+    TargetId VARCHAR(255) NOT NULL,
+    TargetUserId BIGINT,
+    Reason ENUM('SPAM', 'ACOSO', 'CONTENIDO_INAPROPIADO', 'FRAUDE', 'SUPLANTACION', 'OTRO') NOT NULL,
+    Details TEXT,
+    ContentSnapshot TEXT,
+    Status ENUM('PENDIENTE', 'RESUELTO', 'DESCARTADO') NOT NULL DEFAULT 'PENDIENTE',
+    ContentAction ENUM('NINGUNA', 'OCULTAR', 'ELIMINAR'),
+    AuthorAction ENUM('NINGUNA', 'ADVERTIR', 'SUSPENDER'),
+    ResolutionNote TEXT,
+    ResolvedBy BIGINT,
+    ResolvedAt DATETIME,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (ReporterId) REFERENCES User(Id) ON DELETE CASCADE,
+    FOREIGN KEY (TargetUserId) REFERENCES User(Id) ON DELETE SET NULL,
+    FOREIGN KEY (ResolvedBy) REFERENCES User(Id) ON DELETE SET NULL,
+    UNIQUE KEY uq_report_per_reporter (ReporterId, TargetType, TargetId),
+    INDEX idx_report_queue (Status, CreatedAt),
+    INDEX idx_report_target (TargetType, TargetId, Status)
+);
+
+-- Contenido ocultado por moderación. No se borra: el feed, los comentarios y el historial de
+-- chat lo omiten o muestran sin contenido mientras tenga fila aquí.
+CREATE TABLE IF NOT EXISTS HiddenContent (
+    TargetType ENUM('MESSAGE', 'POST', 'COMMENT') NOT NULL,
+    TargetId VARCHAR(255) NOT NULL,
+    ReportId BIGINT,
+    HiddenBy BIGINT,
+    HiddenAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (TargetType, TargetId),
+    FOREIGN KEY (ReportId) REFERENCES Report(Id) ON DELETE SET NULL,
+    FOREIGN KEY (HiddenBy) REFERENCES User(Id) ON DELETE SET NULL
+);
+
+-- Contexto de un mensaje reportado: los mensajes anteriores y posteriores del mismo chat y sus
+-- participantes, copiados en JSON al capturarlo para que el moderador los vea aunque después se
+-- editen o eliminen. No se modifica; solo se borra junto con su reporte.
+CREATE TABLE IF NOT EXISTS ReportContextBundle (
+    ReportId BIGINT PRIMARY KEY,
+    MessageId VARCHAR(255) NOT NULL,
+    ChatId VARCHAR(255),
+    ChatIdGroup VARCHAR(255),
+    MessagesBefore INT NOT NULL,
+    MessagesAfter INT NOT NULL,
+    Participants JSON NOT NULL,
+    Messages JSON NOT NULL,
+    CapturedBy BIGINT, -- NULL si se capturó automáticamente al crear el reporte
+    CapturedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (ReportId) REFERENCES Report(Id) ON DELETE CASCADE,
+    FOREIGN KEY (CapturedBy) REFERENCES User(Id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS ContentFilterRule (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    Language VARCHAR(8) NOT NULL DEFAULT '*', -- Código ISO 639-1 (es, en...) o '*' para todos
+    MatchType ENUM('PALABRA', 'FRASE', 'REGEX') NOT NULL DEFAULT 'PALABRA',
+    Pattern VARCHAR(255) NOT NULL,
+    Category ENUM('PROFANIDAD', 'SPAM', 'ACOSO', 'OTRO') NOT NULL DEFAULT 'PROFANIDAD',
+    Severity ENUM('BAJA', 'MEDIA', 'ALTA') NOT NULL DEFAULT 'MEDIA',
+    IsActive BOOLEAN NOT NULL DEFAULT TRUE,
+    CreatedBy BIGINT,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_filter_rule (Language, MatchType, Pattern),
+    FOREIGN KEY (CreatedBy) REFERENCES User(Id) ON DELETE SET NULL
+);
+
+-- Fila única (Id = 1) con la política del filtro. Sin fila se usan los valores por defecto.
+CREATE TABLE IF NOT EXISTS ContentFilterPolicy (
+    Id TINYINT PRIMARY KEY,
+    Enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    LowAction ENUM('MARCAR', 'BORRADO_SILENCIOSO', 'BLOQUEAR') NOT NULL DEFAULT 'MARCAR',
+    MediumAction ENUM('MARCAR', 'BORRADO_SILENCIOSO', 'BLOQUEAR') NOT NULL DEFAULT 'BORRADO_SILENCIOSO',
+    HighAction ENUM('MARCAR', 'BORRADO_SILENCIOSO', 'BLOQUEAR') NOT NULL DEFAULT 'BLOQUEAR',
+    MaxLinks INT NOT NULL DEFAULT 3,
+    MaxRepeatedChars INT NOT NULL DEFAULT 10,
+    MaxUppercaseRatio DECIMAL(3,2) NOT NULL DEFAULT 0.80,
+    DuplicateThreshold INT NOT NULL DEFAULT 3,
+    DuplicateWindowSeconds INT NOT NULL DEFAULT 60,
+    SpamSeverity ENUM('BAJA', 'MEDIA', 'ALTA') NOT NULL DEFAULT 'BAJA',
+    UpdatedBy BIGINT,
+    UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (UpdatedBy) REFERENCES User(Id) ON DELETE SET NULL
+);
+
+-- Mensajes marcados, ocultados al resto o bloqueados por el filtro. MessageId es NULL en los
+-- bloqueados, que no se guardan en Message.
+CREATE TABLE IF NOT EXISTS ContentFilterHit (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    MessageId VARCHAR(255),
+    SenderId BIGINT NOT NULL,
+    ChatId VARCHAR(255),
+    ChatIdGroup VARCHAR(255),
+    Content TEXT,
+    Action ENUM('MARCAR', 'BORRADO_SILENCIOSO', 'BLOQUEAR') NOT NULL,
+    Severity ENUM('BAJA', 'MEDIA', 'ALTA') NOT NULL,
+    Hits JSON NOT NULL, -- Coincidencias: origen, regla, detalle y severidad
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_filter_hit_message (MessageId, Action),
+    INDEX idx_filter_hit_created (CreatedAt),
+    FOREIGN KEY (SenderId) REFERENCES User(Id) ON DELETE CASCADE
+);
+
+-- Dispositivos registrados para notificaciones push. Un token pertenece a un solo usuario:
+-- si se registra con otra cuenta en el mismo dispositivo pasa a la nueva.
+CREATE TABLE IF NOT EXISTS PushDevice (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    UserId BIGINT NOT NULL,
+    Provider ENUM('FCM', 'APNS') NOT NULL,
+    Token VARCHAR(255) NOT NULL,
+    DeviceName VARCHAR(100),
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP, -- Último registro del token
+    UNIQUE KEY uq_push_device_token (Token),
+    INDEX idx_push_device_user (UserId),
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE
+);
+
+-- Preferencias de notificaciones push y del resumen por correo. Sin fila se aplican los
+-- valores por defecto.
+CREATE TABLE IF NOT EXISTS NotificationPreferences (
+    UserId BIGINT PRIMARY KEY,
+    PushEnabled BOOLEAN NOT NULL DEFAULT TRUE,
+    ChatMessages BOOLEAN NOT NULL DEFAULT TRUE,
+    ChatPreviews BOOLEAN NOT NULL DEFAULT TRUE, -- FALSE = el push no incluye el texto del mensaje
+    ContactRequests BOOLEAN NOT NULL DEFAULT TRUE,
+    Community BOOLEAN NOT NULL DEFAULT TRUE, -- Comentarios, me gusta y reseñas
+    Challenges BOOLEAN NOT NULL DEFAULT TRUE,
+    JobApplications BOOLEAN NOT NULL DEFAULT TRUE,
+    SavedSearches BOOLEAN NOT NULL DEFAULT TRUE, -- Alertas de búsquedas guardadas
+    EmailDigest BOOLEAN NOT NULL DEFAULT TRUE, -- Resumen por correo de lo pendiente de leer
+    -- No molestar: horario en la hora local de DndTimezone (puede cruzar la medianoche)
+    DndEnabled BOOLEAN NOT NULL DEFAULT FALSE,
+    DndStart TIME NOT NULL DEFAULT '22:00:00',
+    DndEnd TIME NOT NULL DEFAULT '07:00:00',
+    DndTimezone VARCHAR(64) NOT NULL DEFAULT 'UTC', -- Zona horaria IANA
+    DndSuppressedAt DATETIME NULL, -- Primer aviso silenciado pendiente de resumir
+    UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE
+);
+
+-- Suscripciones Web Push (navegadores). El endpoint lo asigna el servicio de push del
+-- navegador y puede superar los 255 caracteres, por eso la unicidad se aplica sobre su hash.
+CREATE TABLE IF NOT EXISTS WebPushSubscription (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    UserId BIGINT NOT NULL,
+    Endpoint TEXT NOT NULL,
+    EndpointHash CHAR(64) NOT NULL, -- SHA-256 hexadecimal del endpoint
+    P256dh VARCHAR(128) NOT NULL,
+    Auth VARCHAR(64) NOT NULL,
+    UserAgent VARCHAR(255),
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_web_push_endpoint (EndpointHash),
+    INDEX idx_web_push_user (UserId),
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE
+);
+
+-- Elementos ya incluidos en un resumen por correo (notificaciones de Event y mensajes de
+-- chat). Evita que un mismo elemento se resuma dos veces.
+CREATE TABLE IF NOT EXISTS EmailDigestItem (
+    UserId BIGINT NOT NULL,
+    ItemType ENUM('EVENT', 'MESSAGE') NOT NULL,
+    ItemId VARCHAR(255) NOT NULL, -- Event.Id o Message.Id
+    DigestedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (UserId, ItemType, ItemId),
+    INDEX idx_email_digest_user_date (UserId, DigestedAt),
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE
+);
+
+-- Cuentas desactivadas por un administrador (User.StatusAuthorizedId = 4). PreviousStatusId es
+-- el estado que se restaura al reactivar la cuenta.
+CREATE TABLE IF NOT EXISTS UserDeactivation (
+    UserId BIGINT PRIMARY KEY,
+    PreviousStatusId INT NOT NULL,
+    DeactivatedBy BIGINT,
+    Reason VARCHAR(500),
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE,
+    FOREIGN KEY (DeactivatedBy) REFERENCES User(Id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS Impersonation (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    AdminId BIGINT,
+    UserId BIGINT NOT NULL,
+    Reason VARCHAR(500) NOT NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ExpiresAt DATETIME NOT NULL,
+    EndedAt DATETIME NULL,
+    FOREIGN KEY (AdminId) REFERENCES User(Id) ON DELETE SET NULL,
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE,
+    INDEX idx_impersonation_user (UserId, CreatedAt)
+);
+
+-- Anuncios del sistema enviados desde el panel de administración. TargetRoleId NULL es para
+-- todos los usuarios. Al enviarse se guarda un Event SYSTEM_ANNOUNCEMENT para cada usuario sin
+-- conexión, que se eliminan cuando el anuncio expira.
+CREATE TABLE IF NOT EXISTS SystemAnnouncement (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    Title VARCHAR(255) NOT NULL,
+    Message TEXT NOT NULL,
+    Level ENUM('INFO', 'WARNING', 'CRITICAL') NOT NULL DEFAULT 'INFO',
+    TargetRoleId INT NULL,
+    ScheduledAt DATETIME NOT NULL,
+    ExpiresAt DATETIME NULL,
+    Status ENUM('SCHEDULED', 'SENT', 'CANCELLED', 'EXPIRED') NOT NULL DEFAULT 'SCHEDULED',
+    CreatedBy VARCHAR(100) NOT NULL,
+    SentAt DATETIME NULL,
+    DeliveredNow INT NOT NULL DEFAULT 0,
+    StoredCount INT NOT NULL DEFAULT 0,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (TargetRoleId) REFERENCES Role(Id),
+    INDEX idx_announcement_status (Status, ScheduledAt)
+);
+
+-- Outbox de mensajes en tiempo real. La API escribe aquí, en la misma transacción que el cambio,
+-- lo que el servidor WebSocket debe enviar a los usuarios conectados. EventId apunta a la
+-- notificación que se envía como new_notification y, si es NULL, Topic es el tipo del mensaje
+-- WebSocket y Payload su contenido. DeliveredAt se anota cuando algún servidor lo entrega.
+CREATE TABLE IF NOT EXISTS Outbox (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    Topic VARCHAR(64) NOT NULL,
+    UserId BIGINT NOT NULL,
+    EventId BIGINT NULL,
+    Payload JSON NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    DeliveredAt DATETIME NULL,
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE,
+    FOREIGN KEY (EventId) REFERENCES Event(Id) ON DELETE CASCADE,
+    INDEX idx_outbox_created (CreatedAt)
+);
+
+-- Verificación de la condición de estudiante o egresado con el correo institucional. Email,
+-- UniversityId y VerifiedAt son la verificación confirmada (la insignia del perfil); Pending*
+-- y Code* son el código enviado a un correo sin confirmar. Un correo verificado no puede
+-- verificar otra cuenta.
+CREATE TABLE IF NOT EXISTS StudentVerification (
+    UserId BIGINT PRIMARY KEY,
+    Email VARCHAR(255) NULL,
+    UniversityId BIGINT NULL,
+    VerifiedAt DATETIME NULL,
+    PendingEmail VARCHAR(255) NULL,
+    PendingUniversityId BIGINT NULL,
+    CodeHash CHAR(64) NULL, -- SHA-256 hexadecimal del código enviado
+    CodeSentAt DATETIME NULL,
+    CodeExpiresAt DATETIME NULL,
+    Attempts INT NOT NULL DEFAULT 0, -- Intentos fallidos con el código actual
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_student_verification_email (Email),
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE,
+    FOREIGN KEY (UniversityId) REFERENCES University(Id),
+    FOREIGN KEY (PendingUniversityId) REFERENCES University(Id) ON DELETE SET NULL
+);
+
+-- Cambios de la lista de chats de cada usuario para la sincronización incremental (chat/get_list
+-- con syncToken, ver docs/sincronizacion_chats.md). Los mensajes nuevos se ven en la fecha del
+-- último mensaje; aquí se anotan las lecturas, los bloqueos, los contactos aceptados y los
+-- mensajes borrados. Removed marca un chat que salió de la lista (su contacto se eliminó).
+-- OtherUserId no tiene clave foránea para que la marca sobreviva al borrado del otro usuario.
+-- Las filas más antiguas que CHAT_SYNC_RETENTION se borran.
+CREATE TABLE IF NOT EXISTS ChatSyncChange (
+    UserId BIGINT NOT NULL,
+    OtherUserId BIGINT NOT NULL,
+    ChatId VARCHAR(255) NOT NULL,
+    Removed BOOLEAN NOT NULL DEFAULT FALSE,
+    ChangedAt DATETIME(3) NOT NULL,
+    PRIMARY KEY (UserId, OtherUserId),
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE,
+    INDEX idx_chat_sync_user_changed (UserId, ChangedAt),
+    INDEX idx_chat_sync_changed (ChangedAt)
+);
+
+-- Mensajes sin leer de cada usuario en cada chat privado (ver docs/contadores_no_leidos.md). Se
+-- incrementa al enviar un mensaje y se descuenta al leerlo, en la misma transacción que el
+-- cambio del mensaje; la tarea chat-unread-reconcile lo recalcula desde Message.
+CREATE TABLE IF NOT EXISTS ChatUnread (
+    UserId BIGINT NOT NULL,
+    ChatId VARCHAR(255) NOT NULL,
+    Unread INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (UserId, ChatId),
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE,
+    INDEX idx_chat_unread_chat (ChatId)
+);
+
+-- Mensajes críticos del servidor WebSocket que el usuario no confirmó con client_ack tras
+-- todos los reintentos, o que se le enviaron sin conexión (ver docs/mensajes_criticos.md). Se
+-- envían de nuevo, con el mismo PID, cuando se conecta y se borran al recibir el ack.
+CREATE TABLE IF NOT EXISTS DeadLetter (
+    Id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    UserId BIGINT NOT NULL,
+    Pid VARCHAR(100) NOT NULL,
+    Type VARCHAR(64) NOT NULL,
+    FromUserId BIGINT NULL,
+    Payload JSON NULL,
+    Attempts INT NOT NULL DEFAULT 0,
+    LastError VARCHAR(255) NULL,
+    CreatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UpdatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_dead_letter_pid (UserId, Pid),
+    FOREIGN KEY (UserId) REFERENCES User(Id) ON DELETE CASCADE,
+    INDEX idx_dead_letter_created (CreatedAt)
+);
 	`
 
 	// Dividir el esquema en sentencias individuales
@@ -649,21 +1362,163 @@ CREATE TABLE IF NOT EXISTS JobApplication (
 	return nil
 }
 
+// columnMigrations son las columnas añadidas a tablas que ya existían. CREATE TABLE IF NOT
+// EXISTS no modifica una tabla creada con una versión anterior, así que addMissingColumns las
+// añade si faltan. Cada una se documenta también en la sección de migraciones de schema.sql.
+var columnMigrations = []struct {
+	table, column, alter string
+}{
+	{"Skills", "SkillCatalogId", `ALTER TABLE Skills
+		ADD COLUMN SkillCatalogId BIGINT NULL AFTER dmeta_secondary,
+		ADD INDEX idx_skills_catalog (SkillCatalogId),
+		ADD CONSTRAINT fk_skills_catalog FOREIGN KEY (SkillCatalogId) REFERENCES SkillCatalog(Id) ON DELETE SET NULL`},
+	{"NotificationPreferences", "SavedSearches", `ALTER TABLE NotificationPreferences
+		ADD COLUMN SavedSearches BOOLEAN NOT NULL DEFAULT TRUE AFTER JobApplications`},
+	{"Message", "ForwardedFromMessageId", `ALTER TABLE Message
+		ADD COLUMN ForwardedFromMessageId VARCHAR(255) NULL AFTER ReplyToMessageId,
+		ADD COLUMN ForwardedFromSenderId BIGINT NULL AFTER ForwardedFromMessageId`},
+	{"MessageArchive", "ForwardedFromMessageId", `ALTER TABLE MessageArchive
+		ADD COLUMN ForwardedFromMessageId VARCHAR(255) NULL AFTER ReplyToMessageId,
+		ADD COLUMN ForwardedFromSenderId BIGINT NULL AFTER ForwardedFromMessageId`},
+	{"Multimedia", "RefCount", `ALTER TABLE Multimedia
+		ADD COLUMN RefCount INT NOT NULL DEFAULT 0 AFTER HLSManifest480p`},
+	{"Event", "GroupKey", `ALTER TABLE Event
+		ADD COLUMN GroupKey VARCHAR(191) NULL AFTER UpdatedAt,
+		ADD COLUMN GroupCount INT NOT NULL DEFAULT 1 AFTER GroupKey,
+		ADD COLUMN GroupActors JSON NULL AFTER GroupCount,
+		ADD INDEX idx_event_group_key (UserId, GroupKey, IsRead)`},
+	{"NotificationPreferences", "DndEnabled", `ALTER TABLE NotificationPreferences
+		ADD COLUMN DndEnabled BOOLEAN NOT NULL DEFAULT FALSE AFTER EmailDigest,
+		ADD COLUMN DndStart TIME NOT NULL DEFAULT '22:00:00' AFTER DndEnabled,
+		ADD COLUMN DndEnd TIME NOT NULL DEFAULT '07:00:00' AFTER DndStart,
+		ADD COLUMN DndTimezone VARCHAR(64) NOT NULL DEFAULT 'UTC' AFTER DndEnd,
+		ADD COLUMN DndSuppressedAt DATETIME NULL AFTER DndTimezone`},
+	{"University", "EmailDomains", `ALTER TABLE University
+		ADD COLUMN EmailDomains VARCHAR(1000) NOT NULL DEFAULT '' AFTER Campus`},
+	{"UserPrivacySettings", "LastSeenVisibility", `ALTER TABLE UserPrivacySettings
+		ADD COLUMN LastSeenVisibility VARCHAR(10) NOT NULL DEFAULT 'everyone' AFTER TrackProfileViews`},
+	{"University", "Slug", `ALTER TABLE University
+		ADD COLUMN Slug VARCHAR(63) NULL UNIQUE AFTER EmailDomains`},
+	{"User", "TenantId", `ALTER TABLE User
+		ADD COLUMN TenantId BIGINT NULL AFTER UpdatedAt,
+		ADD INDEX idx_user_tenant (TenantId),
+		ADD CONSTRAINT fk_user_tenant FOREIGN KEY (TenantId) REFERENCES University(Id) ON DELETE SET NULL`},
+	{"CommunityEvent", "TenantId", `ALTER TABLE CommunityEvent
+		ADD COLUMN TenantId BIGINT NULL AFTER UpdatedAt,
+		ADD INDEX idx_community_event_tenant (TenantId),
+		ADD CONSTRAINT fk_community_event_tenant FOREIGN KEY (TenantId) REFERENCES University(Id) ON DELETE SET NULL`},
+	{"Message", "ClientMessageId", `ALTER TABLE Message
+		ADD COLUMN ClientMessageId VARCHAR(64) NULL AFTER Status,
+		ADD UNIQUE KEY uq_message_client (SenderId, ClientMessageId)`},
+	// Cifrado de columnas: índices ciegos de DocId y RIF y RIF con espacio para el valor cifrado.
+	// Los datos existentes se cifran con devtools encrypt-fields.
+	{"User", "DocIdHash", `ALTER TABLE User
+		MODIFY COLUMN RIF VARCHAR(255) NULL,
+		ADD COLUMN DocIdHash CHAR(64) NULL UNIQUE AFTER DocId,
+		ADD COLUMN RIFHash CHAR(64) NULL UNIQUE AFTER RIF`},
+}
+
+// columnBackfills calcula el valor inicial de una columna de columnMigrations a partir de los
+// datos existentes. Solo se ejecuta cuando la columna se acaba de añadir.
+var columnBackfills = map[string]string{
+	"Multimedia.RefCount": `UPDATE Multimedia mm SET RefCount =
+		(SELECT COUNT(*) FROM Message WHERE MediaId = mm.Id) +
+		(SELECT COUNT(*) FROM MessageArchive WHERE MediaId = mm.Id)`,
+	// Los estudiantes y egresados verificados pertenecen a la universidad de su verificación.
+	"User.TenantId": `UPDATE User u
+		JOIN StudentVerification sv ON sv.UserId = u.Id AND sv.VerifiedAt IS NOT NULL
+		SET u.TenantId = sv.UniversityId`,
+	// Las publicaciones existentes quedan en la institución de su autor.
+	"CommunityEvent.TenantId": `UPDATE CommunityEvent ce
+		JOIN User u ON u.Id = ce.CreatedByUserId
+		SET ce.TenantId = u.TenantId`,
+}
+
+// tableBackfills calculan el contenido de las tablas derivadas de otras. Solo se ejecutan con la
+// tabla vacía: al crearla en una base de datos con datos.
+var tableBackfills = []struct {
+	table, query string
+}{
+	// Mismo cálculo que la conciliación de queries.ReconcileChatUnread, para todos los chats.
+	{"ChatUnread", `INSERT INTO ChatUnread (UserId, ChatId, Unread)
+		SELECT p.UserId, p.ChatId, COUNT(m.Id)
+		FROM (
+			SELECT c.User1Id AS UserId, c.User2Id AS OtherId, c.ChatId FROM Contact c WHERE c.ChatId IS NOT NULL
+			UNION ALL
+			SELECT c.User2Id, c.User1Id, c.ChatId FROM Contact c WHERE c.ChatId IS NOT NULL
+		) p
+		LEFT JOIN Message m ON m.ChatId = p.ChatId AND m.SenderId = p.OtherId AND m.Status <> 'read'
+			AND NOT EXISTS (SELECT 1 FROM ContentFilterHit f WHERE f.MessageId = m.Id AND f.Action = 'BORRADO_SILENCIOSO')
+		GROUP BY p.UserId, p.ChatId`},
+}
+
+// backfillTables ejecuta los tableBackfills de las tablas vacías.
+func backfillTables(tx *sql.Tx) error {
+	for _, b := range tableBackfills {
+		empty, err := tableIsEmpty(tx, b.table)
+		if err != nil {
+			return err
+		}
+		if !empty {
+			continue
+		}
+		if _, err := tx.Exec(b.query); err != nil {
+			return fmt.Errorf("failed to backfill table %s: %w", b.table, err)
+		}
+	}
+	return nil
+}
+
+// addMissingColumns ejecuta las migraciones de columnMigrations cuya columna no existe.
+func addMissingColumns(tx *sql.Tx) error {
+	for _, m := range columnMigrations {
+		var exists bool
+		err := tx.QueryRow(`
+			SELECT EXISTS(SELECT 1 FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?)`, m.table, m.column).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check column %s.%s: %w", m.table, m.column, err)
+		}
+		if exists {
+			continue
+		}
+		if _, err := tx.Exec(m.alter); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", m.table, m.column, err)
+		}
+		if backfill, ok := columnBackfills[m.table+"."+m.column]; ok {
+			if _, err := tx.Exec(backfill); err != nil {
+				return fmt.Errorf("failed to backfill column %s.%s: %w", m.table, m.column, err)
+			}
+		}
+		logger.Infof("DB", "Columna %s.%s añadida", m.table, m.column)
+	}
+	return nil
+}
+
 // insertDefaultData populates tables with initial values, ignoring duplicates.
 func insertDefaultData(tx *sql.Tx) error {
 	logger.Info("DB", "Inserting default data...")
 
-	// Insert Nationalities
-	stmtNat, err := tx.Prepare("INSERT IGNORE INTO Nationality (CountryName, IsoCode, DocIdFormat) VALUES (?, ?, ?)")
+	// Los catálogos de nacionalidades, universidades y carreras se gestionan desde el panel de
+	// administración: los valores por defecto solo se cargan en una base de datos vacía para no
+	// recrear los que un administrador haya editado o borrado.
+	seedNationalities, err := tableIsEmpty(tx, "Nationality")
 	if err != nil {
-		return fmt.Errorf("failed to prepare Nationality statement: %w", err)
+		return err
 	}
-	defer stmtNat.Close()
-	for _, nat := range models.GetDefaultNationalities() {
-		_, err := stmtNat.Exec(nat.CountryName, nat.IsoCode, nat.DocIdFormat)
-		if err != nil {
-			logger.Warnf("DB", "Failed to insert nationality %s: %v", nat.CountryName, err)
-			// Continue trying to insert others
+	seedUniversities, err := tableIsEmpty(tx, "University")
+	if err != nil {
+		return err
+	}
+	seedSkills, err := tableIsEmpty(tx, "SkillCatalog")
+	if err != nil {
+		return err
+	}
+
+	// Insert Nationalities
+	if seedNationalities {
+		if err := insertDefaultNationalities(tx); err != nil {
+			return err
 		}
 	}
 
@@ -706,7 +1561,87 @@ func insertDefaultData(tx *sql.Tx) error {
 		}
 	}
 
-	// Insert Universities
+	// Insert Universities and Degrees
+	if seedUniversities {
+		if err := insertDefaultUniversities(tx); err != nil {
+			return err
+		}
+	}
+
+	// Insert SkillCatalog and SkillAlias
+	if seedSkills {
+		if err := insertDefaultSkillCatalog(tx); err != nil {
+			return err
+		}
+	}
+
+	// Insert TypeMessages
+	stmtMsgType, err := tx.Prepare("INSERT IGNORE INTO TypeMessage (Id, Name, Description) VALUES (?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare TypeMessage statement: %w", err)
+	}
+	defer stmtMsgType.Close()
+	for _, msgType := range models.GetDefaultTypeMessages() {
+		_, err := stmtMsgType.Exec(msgType.Id, msgType.Name, msgType.Description)
+		if err != nil {
+			logger.Warnf("DB", "Failed to insert message type %s: %v", msgType.Name, err)
+		}
+	}
+
+	logger.Success("DB", "Finished inserting default data.")
+	return nil
+}
+
+// tableIsEmpty indica si la tabla no tiene filas.
+func tableIsEmpty(tx *sql.Tx, table string) (bool, error) {
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM " + table + ")").Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check %s table: %w", table, err)
+	}
+	return !exists, nil
+}
+
+// insertDefaultNationalities carga las nacionalidades por defecto.
+func insertDefaultNationalities(tx *sql.Tx) error {
+	stmtNat, err := tx.Prepare("INSERT IGNORE INTO Nationality (CountryName, IsoCode, DocIdFormat) VALUES (?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare Nationality statement: %w", err)
+	}
+	defer stmtNat.Close()
+	for _, nat := range models.GetDefaultNationalities() {
+		_, err := stmtNat.Exec(nat.CountryName, nat.IsoCode, nat.DocIdFormat)
+		if err != nil {
+			logger.Warnf("DB", "Failed to insert nationality %s: %v", nat.CountryName, err)
+			// Continue trying to insert others
+		}
+	}
+	return nil
+}
+
+// insertDefaultSkillCatalog carga las habilidades canónicas por defecto y sus alias.
+func insertDefaultSkillCatalog(tx *sql.Tx) error {
+	for _, skill := range models.GetDefaultSkillCatalog() {
+		res, err := tx.Exec("INSERT IGNORE INTO SkillCatalog (Name, NormalizedName, Category) VALUES (?, ?, ?)",
+			skill.Name, taxonomy.Normalize(skill.Name), skill.Category)
+		if err != nil {
+			return fmt.Errorf("failed to insert skill %s: %w", skill.Name, err)
+		}
+		id, err := res.LastInsertId()
+		if err != nil || id == 0 {
+			logger.Warnf("DB", "Skill %s was not inserted: %v", skill.Name, err)
+			continue
+		}
+		for _, alias := range skill.Aliases {
+			if _, err := tx.Exec("INSERT IGNORE INTO SkillAlias (Alias, SkillId) VALUES (?, ?)", taxonomy.Normalize(alias), id); err != nil {
+				return fmt.Errorf("failed to insert alias %s: %w", alias, err)
+			}
+		}
+	}
+	return nil
+}
+
+// insertDefaultUniversities carga las universidades por defecto y sus carreras.
+func insertDefaultUniversities(tx *sql.Tx) error {
 	stmtUni, err := tx.Prepare("INSERT IGNORE INTO University (Name, Campus) VALUES (?, ?)")
 	if err != nil {
 		return fmt.Errorf("failed to prepare University statement: %w", err)
@@ -766,21 +1701,6 @@ func insertDefaultData(tx *sql.Tx) error {
 			logger.Warnf("DB", "Failed to insert degree %s: %v", deg.DegreeName, err)
 		}
 	}
-
-	// Insert TypeMessages
-	stmtMsgType, err := tx.Prepare("INSERT IGNORE INTO TypeMessage (Id, Name, Description) VALUES (?, ?, ?)")
-	if err != nil {
-		return fmt.Errorf("failed to prepare TypeMessage statement: %w", err)
-	}
-	defer stmtMsgType.Close()
-	for _, msgType := range models.GetDefaultTypeMessages() {
-		_, err := stmtMsgType.Exec(msgType.Id, msgType.Name, msgType.Description)
-		if err != nil {
-			logger.Warnf("DB", "Failed to insert message type %s: %v", msgType.Name, err)
-		}
-	}
-
-	logger.Success("DB", "Finished inserting default data.")
 	return nil
 }
 
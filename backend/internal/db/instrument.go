@@ -0,0 +1,284 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+/*
+INSTRUMENTACIÓN DE CONSULTAS
+
+Connect abre el pool con un conector que envuelve al driver de MySQL y mide cada
+Exec/Query (directas o mediante sentencias preparadas, dentro o fuera de transacciones).
+Cada medición se etiqueta con la función que lanzó la consulta (p.ej.
+"queries.GetUserBySessionToken"), se entrega al QueryRecorder registrado y, si supera
+el umbral de consulta lenta, se registra en el log.
+
+El mismo conector aplica el modo solo lectura (readonly.go): con el breaker abierto los
+Exec se rechazan con ErrReadOnly, y los fallos de conexión se le notifican.
+
+En las Query se mide hasta obtener el primer resultado; el tiempo de recorrer las
+filas corre por cuenta del llamador.
+*/
+
+// QueryRecorder recibe la duración de cada consulta ejecutada contra la BD.
+type QueryRecorder interface {
+	RecordQuery(caller string, duration time.Duration, slow bool)
+}
+
+var (
+	queryRecorder      atomic.Value // queryRecorderHolder
+	slowQueryThreshold atomic.Int64 // nanosegundos; 0 = sin log de consultas lentas
+)
+
+type queryRecorderHolder struct{ recorder QueryRecorder }
+
+// SetQueryRecorder registra el destino de las métricas de consultas (p.ej. el
+// MetricsCollector del panel admin). nil deja de registrarlas.
+func SetQueryRecorder(recorder QueryRecorder) {
+	queryRecorder.Store(queryRecorderHolder{recorder: recorder})
+}
+
+// SetSlowQueryThreshold fija la duración a partir de la cual una consulta se registra
+// en el log como lenta (0 = deshabilitado).
+func SetSlowQueryThreshold(threshold time.Duration) {
+	slowQueryThreshold.Store(int64(threshold))
+}
+
+// observeQuery registra una consulta ya ejecutada.
+func observeQuery(query string, start time.Time, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		// El driver delega en otra ruta (p.ej. prepare + exec), que se mide por separado.
+		return
+	}
+	duration := time.Since(start)
+	threshold := time.Duration(slowQueryThreshold.Load())
+	slow := threshold > 0 && duration >= threshold
+
+	holder, _ := queryRecorder.Load().(queryRecorderHolder)
+	if holder.recorder == nil && !slow {
+		return
+	}
+
+	caller := queryCaller()
+	if holder.recorder != nil {
+		holder.recorder.RecordQuery(caller, duration, slow)
+	}
+	if slow {
+		logger.Warnf("DB", "🐢 Consulta lenta (%s) en %s: %s", duration.Round(time.Millisecond), caller, compactQuery(query))
+	}
+}
+
+// dbPackagePrefix es el prefijo de las funciones de este paquete en los stack frames.
+var dbPackagePrefix = reflect.TypeOf(instrumentedConn{}).PkgPath() + "."
+
+// funcLiteralSuffix elimina el sufijo de las closures (".func1", ".func2.1"...).
+var funcLiteralSuffix = regexp.MustCompile(`(\.func\d+)+(\.\d+)*$`)
+
+// queryCaller devuelve la función que lanzó la consulta, saltando database/sql, este
+// paquete y los decoradores MeasureQuery*. Se devuelve como "paquete.Función".
+func queryCaller() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		name := frame.Function
+		switch {
+		case name == "",
+			strings.HasPrefix(name, "database/sql."),
+			strings.HasPrefix(name, "runtime."),
+			strings.HasPrefix(name, dbPackagePrefix),
+			strings.Contains(name, ".MeasureQuery"):
+		default:
+			if slash := strings.LastIndex(name, "/"); slash >= 0 {
+				name = name[slash+1:]
+			}
+			return funcLiteralSuffix.ReplaceAllString(name, "")
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// compactQuery colapsa los espacios de la consulta y la recorta para el log.
+func compactQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > 300 {
+		query = query[:300] + "…"
+	}
+	return query
+}
+
+// instrumentedConnector envuelve el conector del driver para medir las consultas.
+type instrumentedConnector struct {
+	driver.Connector
+}
+
+func (c instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		activeBreaker.Load().observe(err)
+		return nil, err
+	}
+	return &instrumentedConn{Conn: conn}, nil
+}
+
+// instrumentedConn reenvía al driver todas las interfaces opcionales que usa
+// database/sql, midiendo las que ejecutan consultas.
+type instrumentedConn struct {
+	driver.Conn
+}
+
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	breaker := activeBreaker.Load()
+	if breaker.ReadOnly() {
+		return nil, ErrReadOnly
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	observeQuery(query, start, err)
+	breaker.observe(err)
+	return result, err
+}
+
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	observeQuery(query, start, err)
+	activeBreaker.Load().observe(err)
+	return rows, err
+}
+
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedStmt{Stmt: stmt, query: query}, nil
+}
+
+func (c *instrumentedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() //nolint:staticcheck // fallback para drivers sin BeginTx
+}
+
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *instrumentedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// instrumentedStmt mide la ejecución de las sentencias preparadas.
+type instrumentedStmt struct {
+	driver.Stmt
+	query string
+}
+
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	breaker := activeBreaker.Load()
+	if breaker.ReadOnly() {
+		return nil, ErrReadOnly
+	}
+	start := time.Now()
+	var result driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			result, err = s.Stmt.Exec(values) //nolint:staticcheck // fallback para drivers sin ExecContext
+		}
+	}
+	observeQuery(s.query, start, err)
+	breaker.observe(err)
+	return result, err
+}
+
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			rows, err = s.Stmt.Query(values) //nolint:staticcheck // fallback para drivers sin QueryContext
+		}
+	}
+	observeQuery(s.query, start, err)
+	activeBreaker.Load().observe(err)
+	return rows, err
+}
+
+func (s *instrumentedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func namedValuesToValues(named []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(named))
+	for i, nv := range named {
+		if nv.Name != "" {
+			return nil, errors.New("db: el driver no admite parámetros con nombre")
+		}
+		values[i] = nv.Value
+	}
+	return values, nil
+}
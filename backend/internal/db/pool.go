@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// PoolConfig agrupa el dimensionamiento del pool de conexiones y su monitorización.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// StatsInterval es cada cuánto se muestrea sql.DBStats (0 = sin muestreo periódico).
+	StatsInterval time.Duration
+	// WaitAlert es el número de esperas por conexión en un intervalo a partir del cual
+	// el pool se considera saturado.
+	WaitAlert int64
+}
+
+// NewPoolConfig toma la configuración del pool de las variables DB_*.
+func NewPoolConfig(cfg *config.Config) PoolConfig {
+	return PoolConfig{
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+		ConnMaxIdleTime: cfg.DBConnMaxIdleTime,
+		StatsInterval:   cfg.DBStatsInterval,
+		WaitAlert:       cfg.DBWaitAlert,
+	}
+}
+
+func (p PoolConfig) apply(conn *sql.DB) {
+	conn.SetMaxOpenConns(p.MaxOpenConns)
+	conn.SetMaxIdleConns(p.MaxIdleConns)
+	conn.SetConnMaxLifetime(p.ConnMaxLifetime)
+	conn.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+	logger.Infof("DB", "Pool de conexiones: maxOpen=%d maxIdle=%d maxLifetime=%s maxIdleTime=%s",
+		p.MaxOpenConns, p.MaxIdleConns, p.ConnMaxLifetime, p.ConnMaxIdleTime)
+}
+
+// PoolSnapshot es una muestra de sql.DBStats con las esperas del último intervalo.
+type PoolSnapshot struct {
+	MaxOpenConnections int   `json:"maxOpenConnections"`
+	OpenConnections    int   `json:"openConnections"`
+	InUse              int   `json:"inUse"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"waitCount"`
+	WaitDurationMs     int64 `json:"waitDurationMs"`
+	MaxIdleClosed      int64 `json:"maxIdleClosed"`
+	MaxIdleTimeClosed  int64 `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed  int64 `json:"maxLifetimeClosed"`
+	// WaitsLastInterval es el incremento de WaitCount desde la muestra anterior.
+	WaitsLastInterval int64     `json:"waitsLastInterval"`
+	WaitAlert         int64     `json:"waitAlertThreshold"`
+	Alert             bool      `json:"alert"`
+	SampledAt         time.Time `json:"sampledAt"`
+}
+
+// PoolMonitor muestrea periódicamente las estadísticas del pool, las registra en el log
+// y marca una alerta cuando las esperas por conexión crecen por encima del umbral.
+type PoolMonitor struct {
+	conn     *sql.DB
+	interval time.Duration
+	alertAt  int64
+
+	mu   sync.RWMutex
+	last PoolSnapshot
+}
+
+// NewPoolMonitor crea el monitor del pool y toma una primera muestra.
+func NewPoolMonitor(conn *sql.DB, pool PoolConfig) *PoolMonitor {
+	m := &PoolMonitor{conn: conn, interval: pool.StatsInterval, alertAt: pool.WaitAlert}
+	m.sample()
+	return m
+}
+
+// Interval devuelve el intervalo de muestreo configurado (0 = deshabilitado).
+func (m *PoolMonitor) Interval() time.Duration {
+	return m.interval
+}
+
+// Collect toma una muestra y la registra en el log. Tiene la firma de scheduler.Job.
+func (m *PoolMonitor) Collect(ctx context.Context) error {
+	s := m.sample()
+	if s.Alert {
+		logger.Warnf("DB", "⚠️ Pool de BD saturado: %d esperas en el último intervalo (en uso %d/%d, idle %d, espera acumulada %dms)",
+			s.WaitsLastInterval, s.InUse, s.MaxOpenConnections, s.Idle, s.WaitDurationMs)
+		return nil
+	}
+	logger.Infof("DB", "Pool de BD: abiertas %d (en uso %d, idle %d) de %d, esperas %d (+%d)",
+		s.OpenConnections, s.InUse, s.Idle, s.MaxOpenConnections, s.WaitCount, s.WaitsLastInterval)
+	return nil
+}
+
+// Run ejecuta Collect en cada intervalo hasta que se cancele el contexto.
+// No hace nada si el muestreo está deshabilitado.
+func (m *PoolMonitor) Run(ctx context.Context) {
+	if m.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Collect(ctx)
+		}
+	}
+}
+
+// Snapshot devuelve la última muestra junto con el estado actual del pool. Las esperas
+// y la alerta corresponden al último intervalo completo.
+func (m *PoolMonitor) Snapshot() PoolSnapshot {
+	m.mu.RLock()
+	s := m.last
+	m.mu.RUnlock()
+
+	stats := m.conn.Stats()
+	s.OpenConnections = stats.OpenConnections
+	s.InUse = stats.InUse
+	s.Idle = stats.Idle
+	s.WaitCount = stats.WaitCount
+	s.WaitDurationMs = stats.WaitDuration.Milliseconds()
+	return s
+}
+
+func (m *PoolMonitor) sample() PoolSnapshot {
+	stats := m.conn.Stats()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	waits := int64(0)
+	if !m.last.SampledAt.IsZero() {
+		waits = stats.WaitCount - m.last.WaitCount
+	}
+	m.last = PoolSnapshot{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		WaitsLastInterval:  waits,
+		WaitAlert:          m.alertAt,
+		Alert:              m.alertAt > 0 && waits >= m.alertAt,
+		SampledAt:          time.Now().UTC(),
+	}
+	return m.last
+}
@@ -0,0 +1,52 @@
+package queries
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// Condiciones SQL sobre los roles y estados de cuenta de internal/models. Los Id se escriben
+// en la consulta en lugar de pasarse como argumentos porque muchas de estas condiciones forman
+// parte de fragmentos reutilizables (CTE, subconsultas) que no reciben argumentos propios; son
+// enteros de la configuración, así que no hay riesgo de inyección. Se construyen en cada
+// llamada y no al iniciar el paquete: los Id pueden cambiar al cargar la configuración
+// (models.SetAccountIDs).
+
+// roleIn devuelve la condición "column IN (...)" con los roles dados.
+func roleIn(column string, roles ...models.UserRole) string {
+	ids := make([]string, len(roles))
+	for i, role := range roles {
+		ids[i] = strconv.Itoa(int(role))
+	}
+	return column + " IN (" + strings.Join(ids, ", ") + ")"
+}
+
+// studentRoleIn es la condición de "column es el rol de un estudiante o egresado".
+func studentRoleIn(column string) string {
+	return roleIn(column, models.RoleStudent, models.RoleEgresado)
+}
+
+// memberRoleIn es la condición de "column es el rol de un estudiante, egresado o empresa", los
+// usuarios que aparecen en el feed, la red y las búsquedas.
+func memberRoleIn(column string) string {
+	return roleIn(column, models.RoleStudent, models.RoleEgresado, models.RoleBusiness)
+}
+
+// businessRoleIs es la condición de "column es el rol de empresa".
+func businessRoleIs(column string) string {
+	return column + " = " + strconv.Itoa(int(models.RoleBusiness))
+}
+
+// activeStatusIs es la condición de "column es el estado de cuenta activa".
+func activeStatusIs(column string) string {
+	return column + " = " + strconv.Itoa(int(models.UserStatusActive))
+}
+
+// disabledStatusNotIn es la condición de "column no es un estado de cuenta suspendida ni
+// desactivada" (ver models.IsAccountDisabled).
+func disabledStatusNotIn(column string) string {
+	return column + " NOT IN (" + strconv.Itoa(int(models.UserStatusSuspended)) + ", " +
+		strconv.Itoa(int(models.UserStatusDeactivated)) + ")"
+}
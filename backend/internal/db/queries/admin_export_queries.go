@@ -0,0 +1,316 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/fieldcrypt"
+)
+
+// exportKind es el tipo con el que se lee una columna exportada.
+type exportKind int
+
+const (
+	exportText exportKind = iota
+	exportInt
+	exportFloat
+	exportTime
+	exportEncrypted // Texto cifrado con pkg/fieldcrypt: se descifra al leerlo
+)
+
+// exportColumn es una columna exportable: su clave pública, la cabecera del archivo y la
+// expresión SQL que la produce.
+type exportColumn struct {
+	key    string
+	header string
+	expr   string
+	kind   exportKind
+}
+
+// exportDataset es un conjunto de datos exportable desde el panel de administración.
+type exportDataset struct {
+	columns []exportColumn
+	from    string // FROM y JOIN de la consulta
+	orderBy string
+	filters []string // Parámetros de query que admite (se documentan en /admin/exports)
+	// conditions devuelve las condiciones WHERE del filtro y sus argumentos.
+	conditions func(models.AdminExportFilter) ([]string, []interface{})
+}
+
+// dateRangeConditions añade las condiciones de From (incluido) y To (excluido) sobre column.
+func dateRangeConditions(column string, filter models.AdminExportFilter, conditions []string, args []interface{}) ([]string, []interface{}) {
+	if filter.From != nil {
+		conditions = append(conditions, column+" >= ?")
+		args = append(args, filter.From.UTC())
+	}
+	if filter.To != nil {
+		conditions = append(conditions, column+" < ?")
+		args = append(args, filter.To.UTC())
+	}
+	return conditions, args
+}
+
+// adminExportDatasets son los conjuntos exportables, por nombre.
+var adminExportDatasets = map[string]exportDataset{
+	models.AdminExportUsers: {
+		columns: []exportColumn{
+			{"id", "ID", "u.Id", exportInt},
+			{"firstName", "Nombre", "u.FirstName", exportText},
+			{"lastName", "Apellido", "u.LastName", exportText},
+			{"userName", "Usuario", "u.UserName", exportText},
+			{"email", "Email", "u.Email", exportText},
+			{"phone", "Teléfono", "u.Phone", exportEncrypted},
+			{"companyName", "Empresa", "u.CompanyName", exportText},
+			{"roleId", "ID de rol", "u.RoleId", exportInt},
+			{"role", "Rol", "r.Name", exportText},
+			{"statusId", "ID de estado", "u.StatusAuthorizedId", exportInt},
+			{"status", "Estado", "s.Name", exportText},
+			{"createdAt", "Fecha de alta", "u.CreatedAt", exportTime},
+			{"updatedAt", "Última modificación", "u.UpdatedAt", exportTime},
+		},
+		from: `User u
+			LEFT JOIN Role r ON u.RoleId = r.Id
+			LEFT JOIN StatusAuthorized s ON u.StatusAuthorizedId = s.Id`,
+		orderBy: "u.Id",
+		filters: []string{"q", "roleId", "statusId", "from", "to"},
+		conditions: func(filter models.AdminExportFilter) ([]string, []interface{}) {
+			conditions, args := adminUserSearchConditions(filter.Users)
+			return dateRangeConditions("u.CreatedAt", filter, conditions, args)
+		},
+	},
+	models.AdminExportJobApplications: {
+		columns: []exportColumn{
+			{"id", "ID", "ja.Id", exportInt},
+			{"eventId", "ID de publicación", "ce.Id", exportInt},
+			{"eventTitle", "Publicación", "ce.Title", exportText},
+			{"postType", "Tipo de publicación", "ce.PostType", exportText},
+			{"company", "Empresa", "COALESCE(NULLIF(ce.OrganizerCompanyName, ''), cu.CompanyName)", exportText},
+			{"applicantId", "ID de postulante", "a.Id", exportInt},
+			{"applicantName", "Postulante", "CONCAT_WS(' ', a.FirstName, a.LastName)", exportText},
+			{"applicantEmail", "Email del postulante", "a.Email", exportText},
+			{"status", "Estado", "ja.Status", exportText},
+			{"appliedAt", "Fecha de postulación", "ja.AppliedAt", exportTime},
+			{"updatedAt", "Último cambio de estado", "ja.UpdatedAt", exportTime},
+		},
+		from: `JobApplication ja
+			JOIN CommunityEvent ce ON ce.Id = ja.CommunityEventId
+			JOIN User a ON a.Id = ja.ApplicantId
+			LEFT JOIN User cu ON cu.Id = ce.CreatedByUserId`,
+		orderBy: "ja.Id",
+		filters: []string{"eventId", "status", "postType", "from", "to"},
+		conditions: func(filter models.AdminExportFilter) ([]string, []interface{}) {
+			var conditions []string
+			var args []interface{}
+			if filter.EventId != 0 {
+				conditions = append(conditions, "ja.CommunityEventId = ?")
+				args = append(args, filter.EventId)
+			}
+			if filter.Status != "" {
+				conditions = append(conditions, "ja.Status = ?")
+				args = append(args, filter.Status)
+			}
+			if filter.PostType != "" {
+				conditions = append(conditions, "ce.PostType = ?")
+				args = append(args, filter.PostType)
+			}
+			return dateRangeConditions("ja.AppliedAt", filter, conditions, args)
+		},
+	},
+	// La asistencia a los eventos son las postulaciones a publicaciones de tipo EVENTO: una
+	// fila por evento con sus inscripciones por estado.
+	models.AdminExportEventAttendance: {
+		columns: []exportColumn{
+			{"eventId", "ID de evento", "ce.Id", exportInt},
+			{"title", "Evento", "ce.Title", exportText},
+			{"eventDate", "Fecha del evento", "ce.EventDate", exportTime},
+			{"location", "Lugar", "ce.Location", exportText},
+			{"organizer", "Organizador", "COALESCE(NULLIF(ce.OrganizerCompanyName, ''), o.CompanyName, CONCAT_WS(' ', o.FirstName, o.LastName))", exportText},
+			{"capacity", "Aforo", "ce.Capacity", exportInt},
+			{"registered", "Inscritos", "COALESCE(ja.Registered, 0)", exportInt},
+			{"confirmed", "Confirmados", "COALESCE(ja.Confirmed, 0)", exportInt},
+			{"rejected", "Rechazados", "COALESCE(ja.Rejected, 0)", exportInt},
+			{"withdrawn", "Retirados", "COALESCE(ja.Withdrawn, 0)", exportInt},
+		},
+		from: `CommunityEvent ce
+			LEFT JOIN User o ON o.Id = ce.CreatedByUserId
+			LEFT JOIN (
+				SELECT CommunityEventId,
+					SUM(Status NOT IN ('RECHAZADA', 'RETIRADA')) AS Registered,
+					SUM(Status = 'APROBADA') AS Confirmed,
+					SUM(Status = 'RECHAZADA') AS Rejected,
+					SUM(Status = 'RETIRADA') AS Withdrawn
+				FROM JobApplication
+				GROUP BY CommunityEventId
+			) ja ON ja.CommunityEventId = ce.Id`,
+		orderBy: "ce.EventDate, ce.Id",
+		filters: []string{"eventId", "from", "to"},
+		conditions: func(filter models.AdminExportFilter) ([]string, []interface{}) {
+			conditions := []string{"ce.PostType = 'EVENTO'"}
+			var args []interface{}
+			if filter.EventId != 0 {
+				conditions = append(conditions, "ce.Id = ?")
+				args = append(args, filter.EventId)
+			}
+			return dateRangeConditions("ce.EventDate", filter, conditions, args)
+		},
+	},
+	models.AdminExportReviews: {
+		columns: []exportColumn{
+			{"id", "ID", "rr.Id", exportInt},
+			{"reviewerId", "ID de autor", "rr.ReviewerId", exportInt},
+			{"reviewerName", "Autor", "COALESCE(NULLIF(rv.CompanyName, ''), CONCAT_WS(' ', rv.FirstName, rv.LastName))", exportText},
+			{"revieweeId", "ID de reseñado", "rr.RevieweeId", exportInt},
+			{"revieweeName", "Reseñado", "COALESCE(NULLIF(re.CompanyName, ''), CONCAT_WS(' ', re.FirstName, re.LastName))", exportText},
+			{"eventId", "ID de publicación", "rr.CommunityEventId", exportInt},
+			{"eventTitle", "Publicación", "ce.Title", exportText},
+			{"interactionType", "Tipo de interacción", "rr.InteractionType", exportText},
+			{"pointsRP", "Puntos RP", "rr.PointsRP", exportInt},
+			{"rating", "Calificación", "rr.Rating", exportFloat},
+			{"comment", "Comentario", "rr.Comment", exportText},
+			{"createdAt", "Fecha", "rr.CreatedAt", exportTime},
+		},
+		from: `ReputationReview rr
+			JOIN User rv ON rv.Id = rr.ReviewerId
+			JOIN User re ON re.Id = rr.RevieweeId
+			LEFT JOIN CommunityEvent ce ON ce.Id = rr.CommunityEventId`,
+		orderBy: "rr.Id",
+		filters: []string{"eventId", "reviewerId", "revieweeId", "interactionType", "from", "to"},
+		conditions: func(filter models.AdminExportFilter) ([]string, []interface{}) {
+			var conditions []string
+			var args []interface{}
+			if filter.EventId != 0 {
+				conditions = append(conditions, "rr.CommunityEventId = ?")
+				args = append(args, filter.EventId)
+			}
+			if filter.ReviewerId != 0 {
+				conditions = append(conditions, "rr.ReviewerId = ?")
+				args = append(args, filter.ReviewerId)
+			}
+			if filter.RevieweeId != 0 {
+				conditions = append(conditions, "rr.RevieweeId = ?")
+				args = append(args, filter.RevieweeId)
+			}
+			if filter.InteractionType != "" {
+				conditions = append(conditions, "rr.InteractionType = ?")
+				args = append(args, filter.InteractionType)
+			}
+			return dateRangeConditions("rr.CreatedAt", filter, conditions, args)
+		},
+	},
+}
+
+// AdminExportDatasets devuelve los conjuntos exportables con sus columnas, ordenados por nombre.
+func AdminExportDatasets() []models.AdminExportDataset {
+	datasets := make([]models.AdminExportDataset, 0, len(adminExportDatasets))
+	for name := range adminExportDatasets {
+		dataset, _ := AdminExportDataset(name)
+		datasets = append(datasets, dataset)
+	}
+	sort.Slice(datasets, func(i, j int) bool { return datasets[i].Name < datasets[j].Name })
+	return datasets
+}
+
+// AdminExportDataset devuelve la descripción del conjunto exportable name.
+func AdminExportDataset(name string) (models.AdminExportDataset, bool) {
+	def, ok := adminExportDatasets[name]
+	if !ok {
+		return models.AdminExportDataset{}, false
+	}
+	dataset := models.AdminExportDataset{Name: name, Filters: def.filters}
+	for _, column := range def.columns {
+		dataset.Columns = append(dataset.Columns, models.AdminExportColumn{Key: column.key, Header: column.header})
+	}
+	return dataset, true
+}
+
+// StreamAdminExport recorre las filas del conjunto dataset que cumplen el filtro y llama a fn
+// con los valores de las columnas pedidas (por clave y en ese orden), sin cargar el resultado
+// en memoria. La consulta se cancela con ctx; si fn falla, el recorrido se detiene con su error.
+func StreamAdminExport(ctx context.Context, dataset string, columnKeys []string, filter models.AdminExportFilter, fn func(values []any) error) error {
+	def, ok := adminExportDatasets[dataset]
+	if !ok {
+		return fmt.Errorf("conjunto de exportación desconocido: %q", dataset)
+	}
+	columns := make([]exportColumn, 0, len(columnKeys))
+	exprs := make([]string, 0, len(columnKeys))
+	for _, key := range columnKeys {
+		found := false
+		for _, column := range def.columns {
+			if column.key == key {
+				columns = append(columns, column)
+				exprs = append(exprs, column.expr)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("columna de exportación desconocida en %s: %q", dataset, key)
+		}
+	}
+
+	conditions, args := def.conditions(filter)
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+	rows, err := DB.QueryContext(ctx, "SELECT "+strings.Join(exprs, ", ")+" FROM "+def.from+where+" ORDER BY "+def.orderBy, args...)
+	if err != nil {
+		return fmt.Errorf("error al consultar la exportación %s: %w", dataset, err)
+	}
+	defer rows.Close()
+
+	targets := make([]any, len(columns))
+	for i, column := range columns {
+		switch column.kind {
+		case exportInt:
+			targets[i] = new(sql.NullInt64)
+		case exportFloat:
+			targets[i] = new(sql.NullFloat64)
+		case exportTime:
+			targets[i] = new(sql.NullTime)
+		case exportEncrypted:
+			targets[i] = new(fieldcrypt.NullString)
+		default:
+			targets[i] = new(sql.NullString)
+		}
+	}
+	values := make([]any, len(columns))
+	for rows.Next() {
+		if err := rows.Scan(targets...); err != nil {
+			return fmt.Errorf("error al leer la exportación %s: %w", dataset, err)
+		}
+		for i, target := range targets {
+			values[i] = nil
+			switch t := target.(type) {
+			case *sql.NullInt64:
+				if t.Valid {
+					values[i] = t.Int64
+				}
+			case *sql.NullFloat64:
+				if t.Valid {
+					values[i] = t.Float64
+				}
+			case *sql.NullTime:
+				if t.Valid {
+					values[i] = t.Time
+				}
+			case *sql.NullString:
+				if t.Valid {
+					values[i] = t.String
+				}
+			case *fieldcrypt.NullString:
+				if t.Valid {
+					values[i] = t.String
+				}
+			}
+		}
+		if err := fn(values); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
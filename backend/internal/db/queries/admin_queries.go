@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/fieldcrypt"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 )
 
@@ -167,10 +168,17 @@ func GetUsersPaginated(page, pageSize int) ([]models.UserDTO, error) {
 	}
 	defer rows.Close()
 
+	return scanUserDTOs(rows)
+}
+
+// scanUserDTOs lee las filas de las consultas de usuarios del panel de administración, que
+// seleccionan las columnas de GetUsersPaginated.
+func scanUserDTOs(rows *sql.Rows) ([]models.UserDTO, error) {
 	var users []models.UserDTO
 	for rows.Next() {
 		var user models.UserDTO
-		var firstName, lastName, phone, picture, roleName, statusName sql.NullString
+		var firstName, lastName, picture, roleName, statusName sql.NullString
+		var phone fieldcrypt.NullString
 
 		if err := rows.Scan(
 			&user.Id, &firstName, &lastName, &user.UserName, &user.Email, &phone,
@@ -192,7 +200,7 @@ func GetUsersPaginated(page, pageSize int) ([]models.UserDTO, error) {
 		users = append(users, user)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		logger.Errorf(adminQueriesLogComponent, "Error after iterating user rows: %v", err)
 		return nil, fmt.Errorf("error after iterating user rows: %w", err)
 	}
@@ -203,9 +211,8 @@ func GetUsersPaginated(page, pageSize int) ([]models.UserDTO, error) {
 // CountUnapprovedCompanies cuenta el número total de empresas pendientes de aprobación.
 func CountUnapprovedCompanies() (int, error) {
 	var count int
-	// StatusAuthorizedId = 1 significa 'No Autorizado' o 'Pendiente'
-	query := "SELECT COUNT(*) FROM User WHERE RoleId = ? AND StatusAuthorizedId = 1"
-	err := DB.QueryRow(query, models.RoleBusiness).Scan(&count)
+	query := "SELECT COUNT(*) FROM User WHERE RoleId = ? AND StatusAuthorizedId = ?"
+	err := DB.QueryRow(query, models.RoleBusiness, models.UserStatusCompanyPending).Scan(&count)
 	if err != nil {
 		logger.Errorf(adminQueriesLogComponent, "Error counting unapproved companies: %v", err)
 		return 0, fmt.Errorf("error counting unapproved companies: %w", err)
@@ -221,11 +228,11 @@ func GetUnapprovedCompaniesPaginated(page, pageSize int) ([]models.CompanyApprov
 			u.Id, u.CompanyName, u.RIF, u.Email, u.FirstName, u.Phone, s.Name as StatusName, u.CreatedAt
 		FROM User u
 		LEFT JOIN StatusAuthorized s ON u.StatusAuthorizedId = s.Id
-		WHERE u.RoleId = ? AND u.StatusAuthorizedId = 1
+		WHERE u.RoleId = ? AND u.StatusAuthorizedId = ?
 		ORDER BY u.CreatedAt ASC
 		LIMIT ? OFFSET ?
 	`
-	rows, err := DB.Query(query, models.RoleBusiness, pageSize, offset)
+	rows, err := DB.Query(query, models.RoleBusiness, models.UserStatusCompanyPending, pageSize, offset)
 	if err != nil {
 		logger.Errorf(adminQueriesLogComponent, "Error querying unapproved companies: %v", err)
 		return nil, fmt.Errorf("error querying unapproved companies: %w", err)
@@ -236,7 +243,8 @@ func GetUnapprovedCompaniesPaginated(page, pageSize int) ([]models.CompanyApprov
 	for rows.Next() {
 		var company models.CompanyApprovalDTO
 		// Usamos sql.NullString para campos que podrían ser NULL en la BD aunque el DTO los espere como string
-		var companyName, rif, contactName, phone, statusName sql.NullString
+		var companyName, contactName, statusName sql.NullString
+		var rif, phone fieldcrypt.NullString
 
 		if err := rows.Scan(
 			&company.Id, &companyName, &rif, &company.Email, &contactName, &phone, &statusName, &company.CreatedAt,
@@ -262,13 +270,11 @@ func GetUnapprovedCompaniesPaginated(page, pageSize int) ([]models.CompanyApprov
 	return companies, nil
 }
 
-// ApproveCompanyStatus cambia el estado de una empresa a 'Aprobado'.
-// El ID de estado para 'Aprobado' es 2.
+// ApproveCompanyStatus cambia el estado de una empresa a 'Aprobado' (UserStatusCompanyApproved).
 func ApproveCompanyStatus(companyID int) error {
-	// StatusAuthorizedId = 2 para 'Aprobado'
-	query := "UPDATE User SET StatusAuthorizedId = 2 WHERE Id = ? AND RoleId = ?"
+	query := "UPDATE User SET StatusAuthorizedId = ? WHERE Id = ? AND RoleId = ?"
 
-	result, err := DB.Exec(query, companyID, models.RoleBusiness)
+	result, err := DB.Exec(query, models.UserStatusCompanyApproved, companyID, models.RoleBusiness)
 	if err != nil {
 		logger.Errorf(adminQueriesLogComponent, "Error updating company status for ID %d: %v", companyID, err)
 		return fmt.Errorf("error updating company status: %w", err)
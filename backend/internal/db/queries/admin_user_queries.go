@@ -0,0 +1,217 @@
+package queries
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// ErrUserDeactivated y ErrUserNotDeactivated indican que la cuenta ya estaba (o no estaba)
+// desactivada al desactivarla o reactivarla.
+var (
+	ErrUserDeactivated    = errors.New("la cuenta ya está desactivada")
+	ErrUserNotDeactivated = errors.New("la cuenta no está desactivada")
+)
+
+// SearchUsers busca usuarios para el panel de administración y devuelve la página pedida y
+// el total de resultados.
+func SearchUsers(filter models.AdminUserSearch, page, pageSize int) ([]models.UserDTO, int, error) {
+	conditions, args := adminUserSearchConditions(filter)
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM User u `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error al contar los usuarios: %w", err)
+	}
+	if total == 0 {
+		return []models.UserDTO{}, 0, nil
+	}
+
+	rows, err := DB.Query(`
+		SELECT
+			u.Id, u.FirstName, u.LastName, u.UserName, u.Email, u.Phone,
+			u.Picture, u.RoleId, r.Name as RoleName, u.StatusAuthorizedId, s.Name as StatusName,
+			u.CreatedAt, u.UpdatedAt
+		FROM User u
+		LEFT JOIN Role r ON u.RoleId = r.Id
+		LEFT JOIN StatusAuthorized s ON u.StatusAuthorizedId = s.Id
+		`+where+`
+		ORDER BY u.Id ASC
+		LIMIT ? OFFSET ?`, append(args, pageSize, (page-1)*pageSize)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al buscar usuarios: %w", err)
+	}
+	defer rows.Close()
+
+	users, err := scanUserDTOs(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// adminUserSearchConditions devuelve las condiciones SQL (sobre el alias u de User) y los
+// argumentos de los filtros de la búsqueda de usuarios del panel.
+func adminUserSearchConditions(filter models.AdminUserSearch) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if filter.Query != "" {
+		like := "%" + escapeLike(filter.Query) + "%"
+		conditions = append(conditions, `(u.FirstName LIKE ? OR u.LastName LIKE ? OR u.UserName LIKE ? OR u.Email LIKE ? OR u.CompanyName LIKE ?)`)
+		args = append(args, like, like, like, like, like)
+	}
+	if filter.RoleId != 0 {
+		conditions = append(conditions, "u.RoleId = ?")
+		args = append(args, filter.RoleId)
+	}
+	if filter.StatusId != 0 {
+		conditions = append(conditions, "u.StatusAuthorizedId = ?")
+		args = append(args, filter.StatusId)
+	}
+	if filter.TenantId != 0 {
+		conditions = append(conditions, "u.TenantId = ?")
+		args = append(args, filter.TenantId)
+	}
+	return conditions, args
+}
+
+// escapeLike escapa los comodines de LIKE para que el texto se busque literalmente.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// GetAccountState devuelve el estado, el rol y la institución actuales del usuario. Si no
+// existe devuelve sql.ErrNoRows.
+func GetAccountState(userID int64) (models.AccountState, error) {
+	var state models.AccountState
+	var status, role, tenant sql.NullInt64
+	err := DB.QueryRow(`SELECT StatusAuthorizedId, RoleId, TenantId FROM User WHERE Id = ?`, userID).Scan(&status, &role, &tenant)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return state, err
+		}
+		return state, fmt.Errorf("error al obtener el estado de la cuenta %d: %w", userID, err)
+	}
+	state.StatusAuthorizedId, state.RoleId, state.TenantId = int(status.Int64), int(role.Int64), tenant.Int64
+	return state, nil
+}
+
+// UpdateUserRole cambia el rol del usuario. Si no existe devuelve sql.ErrNoRows.
+func UpdateUserRole(userID int64, roleID int) error {
+	result, err := DB.Exec(`UPDATE User SET RoleId = ? WHERE Id = ?`, roleID, userID)
+	if err != nil {
+		return fmt.Errorf("error al cambiar el rol del usuario %d: %w", userID, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		// RowsAffected es 0 también si el rol no cambia; se distingue comprobando si existe.
+		if _, err := GetAccountState(userID); err != nil {
+			return err
+		}
+	}
+	InvalidateUserCache(userID)
+	return nil
+}
+
+// UpdateUserStatus cambia el StatusAuthorized del usuario. Si no existe devuelve
+// sql.ErrNoRows.
+func UpdateUserStatus(userID int64, statusID int) error {
+	result, err := DB.Exec(`UPDATE User SET StatusAuthorizedId = ? WHERE Id = ?`, statusID, userID)
+	if err != nil {
+		return fmt.Errorf("error al cambiar el estado del usuario %d: %w", userID, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		if _, err := GetAccountState(userID); err != nil {
+			return err
+		}
+	}
+	InvalidateUserCache(userID)
+	return nil
+}
+
+// DeactivateUser desactiva la cuenta, guarda su estado anterior en UserDeactivation y cierra
+// sus sesiones. Devuelve sql.ErrNoRows si el usuario no existe y ErrUserDeactivated si ya
+// estaba desactivada.
+func DeactivateUser(userID, adminID int64, reason string) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status sql.NullInt64
+	if err := tx.QueryRow(`SELECT StatusAuthorizedId FROM User WHERE Id = ? FOR UPDATE`, userID).Scan(&status); err != nil {
+		return err
+	}
+	if int(status.Int64) == int(models.UserStatusDeactivated) {
+		return ErrUserDeactivated
+	}
+	previous := int(status.Int64)
+	if !status.Valid {
+		previous = 1
+	}
+
+	// Puede quedar una fila de una desactivación anterior si la cuenta se suspendió y
+	// reactivó por moderación mientras estaba desactivada; se reemplaza.
+	if _, err := tx.Exec(`
+		INSERT INTO UserDeactivation (UserId, PreviousStatusId, DeactivatedBy, Reason)
+		VALUES (?, ?, ?, NULLIF(?, ''))
+		ON DUPLICATE KEY UPDATE PreviousStatusId = VALUES(PreviousStatusId), DeactivatedBy = VALUES(DeactivatedBy),
+			Reason = VALUES(Reason), CreatedAt = CURRENT_TIMESTAMP`,
+		userID, previous, adminID, reason); err != nil {
+		return fmt.Errorf("error al registrar la desactivación del usuario %d: %w", userID, err)
+	}
+	if _, err := tx.Exec(`UPDATE User SET StatusAuthorizedId = ? WHERE Id = ?`, models.UserStatusDeactivated, userID); err != nil {
+		return fmt.Errorf("error al desactivar al usuario %d: %w", userID, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM Session WHERE UserId = ?`, userID); err != nil {
+		return fmt.Errorf("error al cerrar las sesiones del usuario %d: %w", userID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	InvalidateUserCache(userID)
+	return nil
+}
+
+// ReactivateUser reactiva una cuenta desactivada restaurando el estado que tenía y devuelve
+// ese estado. Devuelve sql.ErrNoRows si el usuario no existe y ErrUserNotDeactivated si no
+// estaba desactivada.
+func ReactivateUser(userID int64) (int, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var status sql.NullInt64
+	if err := tx.QueryRow(`SELECT StatusAuthorizedId FROM User WHERE Id = ? FOR UPDATE`, userID).Scan(&status); err != nil {
+		return 0, err
+	}
+	if int(status.Int64) != int(models.UserStatusDeactivated) {
+		return 0, ErrUserNotDeactivated
+	}
+
+	// Sin fila (cuenta cerrada antes de existir UserDeactivation) se restaura como activa.
+	previous := 1
+	err = tx.QueryRow(`SELECT PreviousStatusId FROM UserDeactivation WHERE UserId = ?`, userID).Scan(&previous)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("error al obtener la desactivación del usuario %d: %w", userID, err)
+	}
+	if _, err := tx.Exec(`UPDATE User SET StatusAuthorizedId = ? WHERE Id = ?`, previous, userID); err != nil {
+		return 0, fmt.Errorf("error al reactivar al usuario %d: %w", userID, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM UserDeactivation WHERE UserId = ?`, userID); err != nil {
+		return 0, fmt.Errorf("error al borrar la desactivación del usuario %d: %w", userID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	InvalidateUserCache(userID)
+	return previous, nil
+}
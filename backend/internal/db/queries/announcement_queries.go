@@ -0,0 +1,228 @@
+package queries
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// ErrAnnouncementNotScheduled indica que el anuncio ya se envió, se canceló o expiró.
+var ErrAnnouncementNotScheduled = errors.New("el anuncio ya no está programado")
+
+const announcementColumns = `Id, Title, Message, Level, TargetRoleId, ScheduledAt, ExpiresAt, Status,
+	CreatedBy, SentAt, DeliveredNow, StoredCount, CreatedAt`
+
+// CreateAnnouncement guarda un anuncio programado y asigna su ID.
+func CreateAnnouncement(a *models.SystemAnnouncement) error {
+	result, err := DB.Exec(`
+		INSERT INTO SystemAnnouncement (Title, Message, Level, TargetRoleId, ScheduledAt, ExpiresAt, Status, CreatedBy)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.Title, a.Message, a.Level, a.TargetRoleId, a.ScheduledAt, a.ExpiresAt, models.AnnouncementStatusScheduled, a.CreatedBy)
+	if err != nil {
+		return fmt.Errorf("error al guardar el anuncio: %w", err)
+	}
+	a.Id, err = result.LastInsertId()
+	a.Status = models.AnnouncementStatusScheduled
+	return err
+}
+
+// GetAnnouncement devuelve el anuncio. Si no existe devuelve sql.ErrNoRows.
+func GetAnnouncement(id int64) (models.SystemAnnouncement, error) {
+	a, err := scanAnnouncement(DB.QueryRow(`SELECT `+announcementColumns+` FROM SystemAnnouncement WHERE Id = ?`, id))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return a, fmt.Errorf("error al obtener el anuncio %d: %w", id, err)
+	}
+	return a, err
+}
+
+// ListAnnouncements devuelve los últimos anuncios, del más reciente al más antiguo.
+func ListAnnouncements(limit int) ([]models.SystemAnnouncement, error) {
+	return queryAnnouncements(`SELECT `+announcementColumns+` FROM SystemAnnouncement ORDER BY Id DESC LIMIT ?`, limit)
+}
+
+// GetDueAnnouncements devuelve los anuncios programados cuya hora de envío ya llegó.
+func GetDueAnnouncements(now time.Time) ([]models.SystemAnnouncement, error) {
+	return queryAnnouncements(`
+		SELECT `+announcementColumns+` FROM SystemAnnouncement
+		WHERE Status = ? AND ScheduledAt <= ?
+		ORDER BY ScheduledAt, Id`, models.AnnouncementStatusScheduled, now)
+}
+
+// ClaimAnnouncement marca el anuncio como enviado si seguía programado. Devuelve false si otra
+// ejecución ya lo tomó o se canceló entretanto.
+func ClaimAnnouncement(id int64, sentAt time.Time) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE SystemAnnouncement SET Status = ?, SentAt = ?
+		WHERE Id = ? AND Status = ?`,
+		models.AnnouncementStatusSent, sentAt, id, models.AnnouncementStatusScheduled)
+	if err != nil {
+		return false, fmt.Errorf("error al marcar el anuncio %d como enviado: %w", id, err)
+	}
+	rows, err := result.RowsAffected()
+	return rows == 1, err
+}
+
+// StoreAnnouncementEvents guarda la notificación del anuncio para cada usuario activo de su
+// segmento salvo los de deliveredUserIDs, que ya lo recibieron en tiempo real, y anota ambos
+// totales en el anuncio. Devuelve el número de notificaciones guardadas.
+func StoreAnnouncementEvents(a models.SystemAnnouncement, metadata []byte, deliveredUserIDs []int64) (int64, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO Event (EventType, EventTitle, Description, UserId, Metadata)
+		SELECT ?, ?, ?, u.Id, ?
+		FROM User u
+		WHERE (u.StatusAuthorizedId IS NULL OR u.StatusAuthorizedId NOT IN (?, ?))`
+	args := []interface{}{models.EventTypeSystemAnnouncement, a.Title, a.Message, metadata,
+		models.UserStatusSuspended, models.UserStatusDeactivated}
+	if a.TargetRoleId != nil {
+		query += " AND u.RoleId = ?"
+		args = append(args, *a.TargetRoleId)
+	}
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("error al guardar las notificaciones del anuncio %d: %w", a.Id, err)
+	}
+	stored, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	// Los conectados ya recibieron el anuncio: se quitan sus notificaciones por lotes, porque
+	// pueden ser demasiados para una sola cláusula NOT IN.
+	err = forEachIDChunk(uniqueIDs(deliveredUserIDs), func(chunk []int64, chunkArgs []interface{}) error {
+		result, err := tx.Exec(`
+			DELETE FROM Event
+			WHERE EventType = ? AND JSON_EXTRACT(Metadata, '$.announcementId') = ? AND UserId IN (`+placeholders(len(chunk))+`)`,
+			append([]interface{}{models.EventTypeSystemAnnouncement, a.Id}, chunkArgs...)...)
+		if err != nil {
+			return err
+		}
+		removed, err := result.RowsAffected()
+		stored -= removed
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error al descartar las notificaciones entregadas del anuncio %d: %w", a.Id, err)
+	}
+
+	if _, err := tx.Exec(`UPDATE SystemAnnouncement SET DeliveredNow = ?, StoredCount = ? WHERE Id = ?`,
+		len(deliveredUserIDs), stored, a.Id); err != nil {
+		return 0, fmt.Errorf("error al actualizar los totales del anuncio %d: %w", a.Id, err)
+	}
+	return stored, tx.Commit()
+}
+
+// CancelAnnouncement cancela un anuncio programado. Devuelve sql.ErrNoRows si no existe y
+// ErrAnnouncementNotScheduled si ya se envió, se canceló o expiró.
+func CancelAnnouncement(id int64) error {
+	result, err := DB.Exec(`UPDATE SystemAnnouncement SET Status = ? WHERE Id = ? AND Status = ?`,
+		models.AnnouncementStatusCancelled, id, models.AnnouncementStatusScheduled)
+	if err != nil {
+		return fmt.Errorf("error al cancelar el anuncio %d: %w", id, err)
+	}
+	if rows, err := result.RowsAffected(); err != nil || rows == 1 {
+		return err
+	}
+	if _, err := GetAnnouncement(id); err != nil {
+		return err
+	}
+	return ErrAnnouncementNotScheduled
+}
+
+// ExpireAnnouncements marca como expirados los anuncios programados o enviados cuyo ExpiresAt
+// ya pasó y elimina sus notificaciones. Devuelve los IDs expirados.
+func ExpireAnnouncements(now time.Time) ([]int64, error) {
+	rows, err := DB.Query(`
+		SELECT Id FROM SystemAnnouncement
+		WHERE Status IN (?, ?) AND ExpiresAt IS NOT NULL AND ExpiresAt <= ?`,
+		models.AnnouncementStatusScheduled, models.AnnouncementStatusSent, now)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar los anuncios expirados: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		if err := expireAnnouncement(id); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+func expireAnnouncement(id int64) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		DELETE FROM Event WHERE EventType = ? AND JSON_EXTRACT(Metadata, '$.announcementId') = ?`,
+		models.EventTypeSystemAnnouncement, id); err != nil {
+		return fmt.Errorf("error al eliminar las notificaciones del anuncio %d: %w", id, err)
+	}
+	if _, err := tx.Exec(`UPDATE SystemAnnouncement SET Status = ? WHERE Id = ?`, models.AnnouncementStatusExpired, id); err != nil {
+		return fmt.Errorf("error al marcar el anuncio %d como expirado: %w", id, err)
+	}
+	return tx.Commit()
+}
+
+func queryAnnouncements(query string, args ...interface{}) ([]models.SystemAnnouncement, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar los anuncios: %w", err)
+	}
+	defer rows.Close()
+
+	announcements := []models.SystemAnnouncement{}
+	for rows.Next() {
+		a, err := scanAnnouncement(rows)
+		if err != nil {
+			return nil, err
+		}
+		announcements = append(announcements, a)
+	}
+	return announcements, rows.Err()
+}
+
+func scanAnnouncement(row rowScanner) (models.SystemAnnouncement, error) {
+	var a models.SystemAnnouncement
+	var targetRoleID sql.NullInt64
+	var expiresAt, sentAt sql.NullTime
+	err := row.Scan(&a.Id, &a.Title, &a.Message, &a.Level, &targetRoleID, &a.ScheduledAt, &expiresAt, &a.Status,
+		&a.CreatedBy, &sentAt, &a.DeliveredNow, &a.StoredCount, &a.CreatedAt)
+	if err != nil {
+		return a, err
+	}
+	if targetRoleID.Valid {
+		roleID := int(targetRoleID.Int64)
+		a.TargetRoleId = &roleID
+	}
+	if expiresAt.Valid {
+		a.ExpiresAt = &expiresAt.Time
+	}
+	if sentAt.Valid {
+		a.SentAt = &sentAt.Time
+	}
+	return a, nil
+}
@@ -0,0 +1,109 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// InsertAuditLog registra una entrada en el registro de auditoría.
+func InsertAuditLog(entry models.AuditLog) error {
+	var metadata interface{}
+	if len(entry.Metadata) > 0 {
+		metadata = string(entry.Metadata)
+	}
+	_, err := DB.Exec(`
+		INSERT INTO AuditLog (ActorId, ActorName, Action, TargetType, TargetId, IPAddress, Metadata)
+		VALUES (?, NULLIF(?, ''), ?, NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), ?)`,
+		entry.ActorId, entry.ActorName, entry.Action, entry.TargetType, entry.TargetId, entry.IPAddress, metadata,
+	)
+	if err != nil {
+		return fmt.Errorf("error registrando auditoría %s: %w", entry.Action, err)
+	}
+	return nil
+}
+
+// auditLogWhere construye la cláusula WHERE de los filtros del registro de auditoría.
+func auditLogWhere(filter models.AuditLogFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.ActorId != nil {
+		conditions = append(conditions, "ActorId = ?")
+		args = append(args, *filter.ActorId)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "Action = ?")
+		args = append(args, filter.Action)
+	}
+	if filter.TargetType != "" {
+		conditions = append(conditions, "TargetType = ?")
+		args = append(args, filter.TargetType)
+	}
+	if filter.TargetId != "" {
+		conditions = append(conditions, "TargetId = ?")
+		args = append(args, filter.TargetId)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "CreatedAt >= ?")
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "CreatedAt < ?")
+		args = append(args, *filter.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// CountAuditLogs cuenta las entradas de auditoría que cumplen los filtros.
+func CountAuditLogs(filter models.AuditLogFilter) (int, error) {
+	where, args := auditLogWhere(filter)
+	var total int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM AuditLog"+where, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("error contando entradas de auditoría: %w", err)
+	}
+	return total, nil
+}
+
+// GetAuditLogsPaginated devuelve una página de entradas de auditoría, de la más reciente a la más antigua.
+func GetAuditLogsPaginated(filter models.AuditLogFilter, page, pageSize int) ([]models.AuditLog, error) {
+	where, args := auditLogWhere(filter)
+	args = append(args, pageSize, (page-1)*pageSize)
+
+	rows, err := DB.Query(`
+		SELECT Id, ActorId, ActorName, Action, TargetType, TargetId, IPAddress, Metadata, CreatedAt
+		FROM AuditLog`+where+`
+		ORDER BY CreatedAt DESC, Id DESC
+		LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo entradas de auditoría: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []models.AuditLog{}
+	for rows.Next() {
+		var entry models.AuditLog
+		var actorID sql.NullInt64
+		var actorName, targetType, targetID, ip sql.NullString
+		var metadata []byte
+		if err := rows.Scan(&entry.Id, &actorID, &actorName, &entry.Action, &targetType, &targetID, &ip, &metadata, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error escaneando entrada de auditoría: %w", err)
+		}
+		if actorID.Valid {
+			entry.ActorId = &actorID.Int64
+		}
+		entry.ActorName = actorName.String
+		entry.TargetType = targetType.String
+		entry.TargetId = targetID.String
+		entry.IPAddress = ip.String
+		entry.Metadata = metadata
+		logs = append(logs, entry)
+	}
+	return logs, rows.Err()
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/fieldcrypt"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 )
 
@@ -80,11 +81,12 @@ func CheckUserExists(db *sql.DB, email, username string) (bool, error) {
 // CheckCompanyExists verifica si ya existe una empresa con el mismo email o RIF
 func CheckCompanyExists(email, rif string) (bool, error) {
 	var exists bool
-	query := "SELECT EXISTS(SELECT 1 FROM User WHERE Email = ? OR RIF = ?)"
+	match, args := EncryptedMatch("RIF", rif)
+	query := "SELECT EXISTS(SELECT 1 FROM User WHERE Email = ? OR " + match + ")"
 
 	result, err := MeasureQueryWithResult(func() (interface{}, error) {
 		var e bool
-		err := DB.QueryRow(query, email, rif).Scan(&e)
+		err := DB.QueryRow(query, append([]interface{}{email}, args...)...).Scan(&e)
 		return e, err
 	})
 
@@ -136,8 +138,8 @@ func RegisterNewUser(db *sql.DB, user models.RegistrationStep1, hashedPassword s
 // RegisterNewCompany registra una nueva empresa en el sistema
 func RegisterNewCompany(db *sql.DB, req models.CompanyRegistrationRequest, hashedPassword string, roleId, statusId int) (int64, error) {
 	query := `
-        INSERT INTO User (CompanyName, RIF, Sector, FirstName, Email, Phone, Password, Location, RoleId, StatusAuthorizedId, UserName)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+        INSERT INTO User (CompanyName, RIF, RIFHash, Sector, FirstName, Email, Phone, Password, Location, RoleId, StatusAuthorizedId, UserName)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
     `
 
 	result, err := MeasureQueryWithResult(func() (interface{}, error) {
@@ -145,11 +147,12 @@ func RegisterNewCompany(db *sql.DB, req models.CompanyRegistrationRequest, hashe
 		return db.Exec(
 			query,
 			req.CompanyName,
-			req.RIF,
+			fieldcrypt.Value(req.RIF),
+			fieldcrypt.Index(FieldRIF, req.RIF),
 			req.Sector,
 			req.ContactName, // Mapped to FirstName
 			req.Email,
-			req.Phone,
+			fieldcrypt.Value(req.Phone),
 			hashedPassword,
 			req.Location,
 			roleId,
@@ -175,16 +178,17 @@ func RegisterNewCompany(db *sql.DB, req models.CompanyRegistrationRequest, hashe
 
 // CheckDocIdExists verifica si ya existe un usuario con el mismo documento de identidad
 func CheckDocIdExists(db *sql.DB, docId string, userId int64) (bool, error) {
-	query := "SELECT EXISTS(SELECT 1 FROM User WHERE DocId = ? AND Id != ?)"
+	match, args := EncryptedMatch("DocId", docId)
+	query := "SELECT EXISTS(SELECT 1 FROM User WHERE " + match + " AND Id != ?)"
 
 	result, err := MeasureQueryWithResult(func() (interface{}, error) {
 		var exists bool
-		err := db.QueryRow(query, docId, userId).Scan(&exists)
+		err := db.QueryRow(query, append(args, userId)...).Scan(&exists)
 		return exists, err
 	})
 
 	if err != nil {
-		logger.Errorf("AUTH_QUERIES", "Error checking DocId existence for %s: %v", docId, err)
+		logger.Errorf("AUTH_QUERIES", "Error checking DocId existence for UserID %d: %v", userId, err)
 		return false, err
 	}
 
@@ -193,10 +197,10 @@ func CheckDocIdExists(db *sql.DB, docId string, userId int64) (bool, error) {
 
 // UpdateUserStep2 actualiza la información del paso 2 del registro
 func UpdateUserStep2(db *sql.DB, userId int64, docId string, nationalityId int) error {
-	query := "UPDATE User SET DocId = ?, NationalityId = ? WHERE Id = ?"
+	query := "UPDATE User SET DocId = ?, DocIdHash = ?, NationalityId = ? WHERE Id = ?"
 
 	err := MeasureQuery(func() error {
-		_, err := db.Exec(query, docId, nationalityId, userId)
+		_, err := db.Exec(query, fieldcrypt.Value(docId), fieldcrypt.Index(FieldDocId, docId), nationalityId, userId)
 		return err
 	})
 
@@ -222,6 +226,7 @@ func UpdateUserStep3(db *sql.DB, userId int64, sex string, birthdate time.Time,
 		return err
 	}
 
+	InvalidateUserCache(userId)
 	return nil
 }
 
@@ -239,6 +244,7 @@ func UpdateUserPicture(userID int64, pictureFileName string) error {
 		return err
 	}
 
+	InvalidateUserCache(userID)
 	return nil
 }
 
@@ -250,7 +256,7 @@ func GetUserByEmail(db *sql.DB, email string) (models.User, string, error) {
         SELECT
             Id, FirstName, LastName, UserName, Password, Email, Phone, Sex, DocId,
             NationalityId, Birthdate, Picture, DegreeId, UniversityId,
-            RoleId, StatusAuthorizedId, Summary, Address, Github, Linkedin
+            RoleId, StatusAuthorizedId, Summary, Address, Github, Linkedin, TenantId
         FROM User WHERE Email = ?
     `
 
@@ -261,7 +267,7 @@ func GetUserByEmail(db *sql.DB, email string) (models.User, string, error) {
 			&u.Id, &u.FirstName, &u.LastName, &u.UserName, &pwd, &u.Email,
 			&u.Phone, &u.Sex, &u.DocId, &u.NationalityId, &u.Birthdate,
 			&u.Picture, &u.DegreeId, &u.UniversityId, &u.RoleId,
-			&u.StatusAuthorizedId, &u.Summary, &u.Address, &u.Github, &u.Linkedin,
+			&u.StatusAuthorizedId, &u.Summary, &u.Address, &u.Github, &u.Linkedin, &u.TenantId,
 		)
 		return struct {
 			User     models.User
@@ -292,14 +298,14 @@ func GetUserByID(db *sql.DB, userID int64) (models.User, error) {
         SELECT
             Id, FirstName, LastName, UserName, Email, Phone, Sex, DocId,
             NationalityId, Birthdate, Picture, DegreeId, UniversityId,
-            RoleId, StatusAuthorizedId, Summary, Address, Github, Linkedin
+            RoleId, StatusAuthorizedId, Summary, Address, Github, Linkedin, TenantId
         FROM User WHERE Id = ?
     `
 	err := db.QueryRow(query, userID).Scan(
 		&user.Id, &user.FirstName, &user.LastName, &user.UserName, &user.Email,
 		&user.Phone, &user.Sex, &user.DocId, &user.NationalityId, &user.Birthdate,
 		&user.Picture, &user.DegreeId, &user.UniversityId, &user.RoleId,
-		&user.StatusAuthorizedId, &user.Summary, &user.Address, &user.Github, &user.Linkedin,
+		&user.StatusAuthorizedId, &user.Summary, &user.Address, &user.Github, &user.Linkedin, &user.TenantId,
 	)
 	if err != nil {
 		if err != sql.ErrNoRows {
@@ -310,15 +316,21 @@ func GetUserByID(db *sql.DB, userID int64) (models.User, error) {
 	return user, nil
 }
 
-// RegisterUserSession registra una nueva sesión para el usuario
-func RegisterUserSession(db *sql.DB, userId int64, token, ip string, roleId int, tokenId int) error {
-	logger.Infof("AUTH_QUERIES", "Registering user session for UserID %d with token %s, IP %s, RoleId %d, TokenId %d", userId, token, ip, roleId, tokenId)
+// RegisterUserSession registra una nueva sesión para el usuario junto con el dispositivo y la
+// ubicación desde los que inició sesión.
+func RegisterUserSession(db *sql.DB, userId int64, token, ip string, roleId int, tokenId int, device models.SessionDevice) error {
+	logger.Infof("AUTH_QUERIES", "Registering user session for UserID %d, IP %s, RoleId %d, TokenId %d, device %s/%s/%s, country %q",
+		userId, ip, roleId, tokenId, device.DeviceType, device.Browser, device.OS, device.Country)
 
 	query := `
-		INSERT INTO Session (UserId, Tk, Ip, RoleId, TokenId)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO Session (UserId, Tk, Ip, RoleId, TokenId, UserAgent, DeviceType, Browser, OS, Country, City)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err := db.Exec(query, userId, token, ip, roleId, tokenId) // Usar el tokenId proporcionado
+	// Los datos desconocidos se guardan como NULL
+	nullable := func(s string) sql.NullString { return sql.NullString{String: s, Valid: s != ""} }
+	_, err := db.Exec(query, userId, token, ip, roleId, tokenId,
+		nullable(device.UserAgent), nullable(device.DeviceType), nullable(device.Browser),
+		nullable(device.OS), nullable(device.Country), nullable(device.City))
 	if err != nil {
 		logger.Errorf("AUTH_QUERIES", "Failed inserting session for UserID %d: %v", userId, err)
 		return err
@@ -326,6 +338,55 @@ func RegisterUserSession(db *sql.DB, userId int64, token, ip string, roleId int,
 	return nil
 }
 
+// GetKnownSessionDevices devuelve los dispositivos (huella tipo|navegador|SO) y los países de
+// las sesiones anteriores del usuario. Las sesiones creadas antes de registrar el dispositivo
+// no cuentan, así que ambos mapas pueden estar vacíos aunque el usuario ya haya iniciado sesión.
+func GetKnownSessionDevices(db *sql.DB, userId int64) (devices map[string]bool, countries map[string]bool, err error) {
+	query := `
+		SELECT DISTINCT DeviceType, COALESCE(Browser, ''), COALESCE(OS, ''), COALESCE(Country, '')
+		FROM Session
+		WHERE UserId = ? AND DeviceType IS NOT NULL
+	`
+	rows, err := db.Query(query, userId)
+	if err != nil {
+		logger.Errorf("AUTH_QUERIES", "Error getting known session devices for UserID %d: %v", userId, err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	devices = make(map[string]bool)
+	countries = make(map[string]bool)
+	for rows.Next() {
+		var deviceType, browser, os, country string
+		if err := rows.Scan(&deviceType, &browser, &os, &country); err != nil {
+			return nil, nil, err
+		}
+		devices[deviceType+"|"+browser+"|"+os] = true
+		if country != "" {
+			countries[country] = true
+		}
+	}
+	return devices, countries, rows.Err()
+}
+
+// GetSessionDevice devuelve la IP y el dispositivo con los que se creó la sesión del token.
+// Devuelve sql.ErrNoRows si la sesión no existe.
+func GetSessionDevice(db *sql.DB, userId int64, token string) (string, models.SessionDevice, error) {
+	query := `
+		SELECT COALESCE(Ip, ''), COALESCE(UserAgent, ''), COALESCE(DeviceType, ''), COALESCE(Browser, ''),
+		       COALESCE(OS, ''), COALESCE(Country, ''), COALESCE(City, '')
+		FROM Session
+		WHERE UserId = ? AND Tk = ?
+		ORDER BY Id DESC
+		LIMIT 1
+	`
+	var ip string
+	var device models.SessionDevice
+	err := db.QueryRow(query, userId, token).Scan(&ip, &device.UserAgent, &device.DeviceType,
+		&device.Browser, &device.OS, &device.Country, &device.City)
+	return ip, device, err
+}
+
 // IsSessionValid verifica si un token de sesión para un usuario específico es válido.
 // Devuelve true si la sesión existe en la base de datos, de lo contrario false.
 func IsSessionValid(db *sql.DB, userId int64, token string) (bool, error) {
@@ -0,0 +1,35 @@
+package queries
+
+// batchQueryChunkSize limita el número de IDs por cláusula IN en las consultas por lotes,
+// para no superar el límite de marcadores de MySQL ni generar sentencias enormes.
+const batchQueryChunkSize = 500
+
+// uniqueIDs devuelve los IDs sin repetir, conservando el orden de la primera aparición.
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]struct{}, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	return unique
+}
+
+// forEachIDChunk llama a fn con grupos de hasta batchQueryChunkSize IDs y sus argumentos ya
+// preparados para una cláusula IN (placeholders(len(chunk))). Se detiene en el primer error.
+func forEachIDChunk(ids []int64, fn func(chunk []int64, args []interface{}) error) error {
+	for start := 0; start < len(ids); start += batchQueryChunkSize {
+		chunk := ids[start:min(start+batchQueryChunkSize, len(ids))]
+		args := make([]interface{}, len(chunk))
+		for i, id := range chunk {
+			args[i] = id
+		}
+		if err := fn(chunk, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package queries
+
+import (
+	"fmt"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// NotBlockedCondition devuelve una condición SQL que se cumple si no hay un bloqueo, en ningún
+// sentido, entre el usuario de la columna userColumn y el usuario que consulta. Recibe el ID
+// del usuario que consulta dos veces. userColumn debe ser un nombre de columna fijo, nunca un
+// valor del cliente.
+func NotBlockedCondition(userColumn string) string {
+	return `NOT EXISTS (SELECT 1 FROM UserBlock ub WHERE (ub.BlockerId = ? AND ub.BlockedId = ` + userColumn +
+		`) OR (ub.BlockerId = ` + userColumn + ` AND ub.BlockedId = ?))`
+}
+
+// BlockUser registra que blockerID bloquea a blockedID y cancela las solicitudes de contacto
+// pendientes entre ambos, para que tras desbloquear se pueda enviar una nueva. Los contactos
+// aceptados y sus chats se conservan: quedan congelados mientras dure el bloqueo. Devuelve
+// false si el bloqueo ya existía.
+func BlockUser(blockerID, blockedID int64) (bool, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("error al iniciar la transacción de bloqueo: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT IGNORE INTO UserBlock (BlockerId, BlockedId) VALUES (?, ?)`, blockerID, blockedID)
+	if err != nil {
+		return false, fmt.Errorf("error al bloquear al usuario %d: %w", blockedID, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error al bloquear al usuario %d: %w", blockedID, err)
+	}
+	_, err = tx.Exec(`
+		DELETE FROM Contact
+		WHERE ((User1Id = ? AND User2Id = ?) OR (User1Id = ? AND User2Id = ?)) AND Status = 'pending'`,
+		blockerID, blockedID, blockedID, blockerID)
+	if err != nil {
+		return false, fmt.Errorf("error al cancelar las solicitudes pendientes con el usuario %d: %w", blockedID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("error al confirmar el bloqueo: %w", err)
+	}
+
+	InvalidateChatListCache(blockerID, blockedID)
+	InvalidateNetworkCache(blockerID, blockedID)
+	if affected > 0 {
+		touchChatSyncBetween(blockerID, blockedID)
+	}
+	return affected > 0, nil
+}
+
+// UnblockUser elimina el bloqueo de blockerID a blockedID. Devuelve false si no existía.
+func UnblockUser(blockerID, blockedID int64) (bool, error) {
+	result, err := DB.Exec(`DELETE FROM UserBlock WHERE BlockerId = ? AND BlockedId = ?`, blockerID, blockedID)
+	if err != nil {
+		return false, fmt.Errorf("error al desbloquear al usuario %d: %w", blockedID, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error al desbloquear al usuario %d: %w", blockedID, err)
+	}
+	if affected > 0 {
+		InvalidateChatListCache(blockerID, blockedID)
+		InvalidateNetworkCache(blockerID, blockedID)
+		touchChatSyncBetween(blockerID, blockedID)
+	}
+	return affected > 0, nil
+}
+
+// ListBlockedUsers devuelve una página de los usuarios bloqueados por userID, del bloqueo más
+// reciente al más antiguo, y el total.
+func ListBlockedUsers(userID int64, limit, offset int) ([]models.BlockedUser, int, error) {
+	var total int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM UserBlock WHERE BlockerId = ?`, userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error al contar los bloqueos del usuario %d: %w", userID, err)
+	}
+
+	rows, err := DB.Query(`
+		SELECT BlockedId, CreatedAt FROM UserBlock
+		WHERE BlockerId = ?
+		ORDER BY CreatedAt DESC, BlockedId DESC
+		LIMIT ? OFFSET ?`, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al consultar los bloqueos del usuario %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	blocks := []models.BlockedUser{}
+	for rows.Next() {
+		var block models.BlockedUser
+		if err := rows.Scan(&block.User.ID, &block.BlockedAt); err != nil {
+			return nil, 0, fmt.Errorf("error al leer los bloqueos: %w", err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, total, rows.Err()
+}
+
+// IsBlockedEither indica si alguno de los dos usuarios bloqueó al otro.
+func IsBlockedEither(userID, otherUserID int64) (bool, error) {
+	var blocked bool
+	err := DB.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM UserBlock
+			WHERE (BlockerId = ? AND BlockedId = ?) OR (BlockerId = ? AND BlockedId = ?)
+		)`, userID, otherUserID, otherUserID, userID).Scan(&blocked)
+	if err != nil {
+		return false, fmt.Errorf("error al comprobar el bloqueo entre %d y %d: %w", userID, otherUserID, err)
+	}
+	return blocked, nil
+}
+
+// GetBlockRelations devuelve los usuarios con los que userID tiene un bloqueo en cualquier
+// sentido (los que bloqueó y los que lo bloquearon).
+func GetBlockRelations(userID int64) (map[int64]bool, error) {
+	rows, err := DB.Query(`
+		SELECT BlockedId FROM UserBlock WHERE BlockerId = ?
+		UNION
+		SELECT BlockerId FROM UserBlock WHERE BlockedId = ?`, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar los bloqueos del usuario %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error al leer los bloqueos del usuario %d: %w", userID, err)
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
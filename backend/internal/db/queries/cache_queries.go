@@ -0,0 +1,137 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/cache"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// Grupos de claves de la caché de consultas. Cada grupo aparece por separado en las métricas.
+const (
+	cacheGroupUserBase = "user_base"
+	cacheGroupChatList = "chat_list"
+	cacheGroupCatalog  = "catalog"
+	cacheGroupNetwork  = "network"
+	cacheGroupCohort   = "cohort"
+)
+
+// queryCache es la caché de las consultas más frecuentes. Es nil (sin caché) hasta InitCache.
+var queryCache *cache.Cache
+
+var cacheTTL struct {
+	user     time.Duration
+	chatList time.Duration
+	catalog  time.Duration
+	network  time.Duration
+	cohort   time.Duration
+}
+
+// InitCache activa la caché de consultas según la configuración. Se llama una vez al arrancar
+// cada servidor, después de InitDB.
+func InitCache(cfg *config.Config) {
+	if !cfg.CacheEnabled {
+		logger.Info("CACHE", "Caché de consultas desactivada")
+		return
+	}
+	queryCache = cache.New(cache.Options{
+		MaxEntries:    cfg.CacheMaxEntries,
+		LocalTTL:      cfg.CacheLocalTTL,
+		RedisAddr:     cfg.CacheRedisAddr,
+		RedisPassword: cfg.CacheRedisPassword,
+		RedisDB:       cfg.CacheRedisDB,
+		RedisTimeout:  cfg.CacheRedisTimeout,
+		// Con la BD en modo solo lectura se sirven los valores caducados si la consulta falla
+		ServeStale: db.ReadOnly,
+	})
+	cacheTTL.user = cfg.CacheUserTTL
+	cacheTTL.chatList = cfg.CacheChatListTTL
+	cacheTTL.catalog = cfg.CacheCatalogTTL
+	cacheTTL.network = cfg.CacheNetworkTTL
+	cacheTTL.cohort = cfg.CacheCohortTTL
+
+	tier := "solo memoria"
+	if cfg.CacheRedisAddr != "" {
+		tier = "memoria + Redis " + cfg.CacheRedisAddr
+	}
+	logger.Infof("CACHE", "Caché de consultas activada (%s, %d entradas)", tier, cfg.CacheMaxEntries)
+}
+
+// CacheStats devuelve las métricas de aciertos y fallos de la caché de consultas.
+func CacheStats() cache.Stats {
+	return queryCache.Stats()
+}
+
+func userBaseCacheKey(userID int64) string {
+	return cacheGroupUserBase + ":" + strconv.FormatInt(userID, 10)
+}
+
+func chatListCacheKey(userID int64) string {
+	return cacheGroupChatList + ":" + strconv.FormatInt(userID, 10)
+}
+
+func catalogCacheKey(name string, args ...any) string {
+	key := cacheGroupCatalog + ":" + name
+	for _, arg := range args {
+		key += ":" + fmt.Sprint(arg)
+	}
+	return key
+}
+
+// InvalidateUserCache descarta los datos cacheados de un usuario tras modificar su nombre,
+// foto, rol o nombre de empresa. Como esos datos aparecen en las listas de chats de sus
+// contactos, también se descartan todas las listas de chats.
+func InvalidateUserCache(userID int64) {
+	queryCache.Delete(userBaseCacheKey(userID))
+	queryCache.DeletePrefix(cacheGroupChatList + ":")
+}
+
+// InvalidateChatListCache descarta la lista de chats de los usuarios indicados, tras un cambio
+// en sus mensajes o contactos.
+func InvalidateChatListCache(userIDs ...int64) {
+	keys := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		keys = append(keys, chatListCacheKey(userID))
+	}
+	queryCache.Delete(keys...)
+}
+
+// InvalidateNetworkCache descarta los contactos, las sugerencias y el resumen de red de los
+// usuarios indicados, tras un cambio en sus contactos. Los datos de segundo grado de sus
+// contactos se actualizan al caducar (CACHE_NETWORK_TTL).
+func InvalidateNetworkCache(userIDs ...int64) {
+	keys := make([]string, 0, 3*len(userIDs))
+	for _, userID := range userIDs {
+		keys = append(keys, networkCacheKey("contacts", userID), networkCacheKey("suggestions", userID), networkCacheKey("summary", userID))
+	}
+	queryCache.Delete(keys...)
+}
+
+// InvalidateAllChatListsCache descarta todas las listas de chats, para los cambios masivos
+// (retención, moderación, borrado de cuentas) en los que no se sabe a quién afectan.
+func InvalidateAllChatListsCache() {
+	queryCache.DeletePrefix(cacheGroupChatList + ":")
+}
+
+// InvalidateChatCache descarta la lista de chats de los dos participantes de un chat, tras un
+// mensaje nuevo o un cambio de estado. Los chats de grupo no están en la lista de chats.
+func InvalidateChatCache(chatID string) {
+	if queryCache == nil || chatID == "" {
+		return
+	}
+	var user1, user2 int64
+	err := DB.QueryRow("SELECT User1Id, User2Id FROM Contact WHERE ChatId = ? LIMIT 1", chatID).Scan(&user1, &user2)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		logger.Warnf("CACHE", "No se pudieron obtener los participantes del chat %s: %v", chatID, err)
+		InvalidateAllChatListsCache()
+	default:
+		InvalidateChatListCache(user1, user2)
+	}
+}
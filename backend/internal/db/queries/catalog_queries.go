@@ -0,0 +1,304 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/cache"
+)
+
+// Los catálogos (nacionalidades, universidades y carreras) cambian muy poco: se cachean con
+// CACHE_CATALOG_TTL y las escrituras del panel de administración descartan toda la caché de
+// catálogos con InvalidateCatalogCache.
+
+// GetNationalities devuelve todas las nacionalidades ordenadas por nombre.
+func GetNationalities() ([]models.Nationality, error) {
+	return cache.Load(queryCache, catalogCacheKey("nationalities"), cacheTTL.catalog, func() ([]models.Nationality, error) {
+		rows, err := DB.Query("SELECT Id, CountryName, IsoCode, DocIdFormat FROM Nationality ORDER BY CountryName")
+		if err != nil {
+			return nil, fmt.Errorf("error consultando nacionalidades: %w", err)
+		}
+		defer rows.Close()
+
+		nationalities := []models.Nationality{}
+		for rows.Next() {
+			var nat models.Nationality
+			var isoCode, docIdFormat sql.NullString
+			if err := rows.Scan(&nat.Id, &nat.CountryName, &isoCode, &docIdFormat); err != nil {
+				return nil, fmt.Errorf("error escaneando nacionalidad: %w", err)
+			}
+			nat.IsoCode, nat.DocIdFormat = isoCode.String, docIdFormat.String
+			nationalities = append(nationalities, nat)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterando nacionalidades: %w", err)
+		}
+		return nationalities, nil
+	})
+}
+
+// GetUniversities devuelve todas las universidades ordenadas por nombre.
+func GetUniversities() ([]models.University, error) {
+	return cache.Load(queryCache, catalogCacheKey("universities"), cacheTTL.catalog, func() ([]models.University, error) {
+		rows, err := DB.Query("SELECT Id, Name, Campus, EmailDomains, COALESCE(Slug, '') FROM University ORDER BY Name")
+		if err != nil {
+			return nil, fmt.Errorf("error consultando universidades: %w", err)
+		}
+		defer rows.Close()
+
+		universities := []models.University{}
+		for rows.Next() {
+			var uni models.University
+			var domains string
+			if err := rows.Scan(&uni.Id, &uni.Name, &uni.Campus, &domains, &uni.Slug); err != nil {
+				return nil, fmt.Errorf("error escaneando universidad: %w", err)
+			}
+			uni.EmailDomains = splitEmailDomains(domains)
+			universities = append(universities, uni)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterando universidades: %w", err)
+		}
+		return universities, nil
+	})
+}
+
+// GetDegreesByUniversity devuelve las carreras de una universidad ordenadas por nombre.
+func GetDegreesByUniversity(universityID int64) ([]models.Degree, error) {
+	return cache.Load(queryCache, catalogCacheKey("degrees", universityID), cacheTTL.catalog, func() ([]models.Degree, error) {
+		rows, err := DB.Query("SELECT Id, DegreeName, Descriptions, Code FROM Degree WHERE UniversityId = ? ORDER BY DegreeName", universityID)
+		if err != nil {
+			return nil, fmt.Errorf("error consultando carreras de la universidad %d: %w", universityID, err)
+		}
+		defer rows.Close()
+
+		degrees := []models.Degree{}
+		for rows.Next() {
+			var deg models.Degree
+			if err := rows.Scan(&deg.Id, &deg.DegreeName, &deg.Descriptions, &deg.Code); err != nil {
+				return nil, fmt.Errorf("error escaneando carrera: %w", err)
+			}
+			deg.UniversityId = universityID
+			degrees = append(degrees, deg)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterando carreras: %w", err)
+		}
+		return degrees, nil
+	})
+}
+
+// GetAllDegrees devuelve las carreras de todas las universidades ordenadas por universidad y
+// nombre.
+func GetAllDegrees() ([]models.Degree, error) {
+	return cache.Load(queryCache, catalogCacheKey("degrees"), cacheTTL.catalog, func() ([]models.Degree, error) {
+		rows, err := DB.Query("SELECT Id, DegreeName, Descriptions, Code, UniversityId FROM Degree ORDER BY UniversityId, DegreeName")
+		if err != nil {
+			return nil, fmt.Errorf("error consultando carreras: %w", err)
+		}
+		defer rows.Close()
+
+		degrees := []models.Degree{}
+		for rows.Next() {
+			var deg models.Degree
+			var descriptions, code sql.NullString
+			var universityID sql.NullInt64
+			if err := rows.Scan(&deg.Id, &deg.DegreeName, &descriptions, &code, &universityID); err != nil {
+				return nil, fmt.Errorf("error escaneando carrera: %w", err)
+			}
+			deg.Descriptions, deg.Code, deg.UniversityId = descriptions.String, code.String, universityID.Int64
+			degrees = append(degrees, deg)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterando carreras: %w", err)
+		}
+		return degrees, nil
+	})
+}
+
+// InvalidateCatalogCache descarta todos los catálogos cacheados tras una escritura.
+func InvalidateCatalogCache() {
+	queryCache.DeletePrefix(cacheGroupCatalog + ":")
+}
+
+// catalogRowExists indica si existe la fila id de la tabla de catálogo. table es siempre una
+// constante del paquete, nunca un valor del cliente.
+func catalogRowExists(table string, id int64) (bool, error) {
+	var exists bool
+	if err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM "+table+" WHERE Id = ?)", id).Scan(&exists); err != nil {
+		return false, fmt.Errorf("error al comprobar %s %d: %w", table, id, err)
+	}
+	return exists, nil
+}
+
+// catalogWrite ejecuta una escritura sobre una fila del catálogo, devuelve sql.ErrNoRows si
+// la fila no existe y descarta la caché de catálogos.
+func catalogWrite(table string, id int64, query string, args ...interface{}) error {
+	result, err := DB.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		// RowsAffected es 0 también si una actualización no cambia nada.
+		exists, err := catalogRowExists(table, id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return sql.ErrNoRows
+		}
+	}
+	InvalidateCatalogCache()
+	return nil
+}
+
+// NationalityIsoCodeTaken indica si otra nacionalidad distinta de excludeID usa el código ISO.
+func NationalityIsoCodeTaken(isoCode string, excludeID int64) (bool, error) {
+	var taken bool
+	err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM Nationality WHERE IsoCode = ? AND Id <> ?)", isoCode, excludeID).Scan(&taken)
+	if err != nil {
+		return false, fmt.Errorf("error al comprobar el código ISO %s: %w", isoCode, err)
+	}
+	return taken, nil
+}
+
+// InsertNationality crea una nacionalidad y devuelve su ID.
+func InsertNationality(nat models.Nationality) (int64, error) {
+	result, err := DB.Exec("INSERT INTO Nationality (CountryName, IsoCode, DocIdFormat) VALUES (?, ?, ?)",
+		nat.CountryName, nat.IsoCode, nat.DocIdFormat)
+	if err != nil {
+		return 0, err
+	}
+	InvalidateCatalogCache()
+	return result.LastInsertId()
+}
+
+// UpdateNationality reemplaza los datos de la nacionalidad. Si no existe devuelve
+// sql.ErrNoRows.
+func UpdateNationality(nat models.Nationality) error {
+	return catalogWrite("Nationality", int64(nat.Id),
+		"UPDATE Nationality SET CountryName = ?, IsoCode = ?, DocIdFormat = ? WHERE Id = ?",
+		nat.CountryName, nat.IsoCode, nat.DocIdFormat, nat.Id)
+}
+
+// DeleteNationality elimina la nacionalidad. Si no existe devuelve sql.ErrNoRows; si algún
+// usuario la tiene asignada la base de datos rechaza el borrado.
+func DeleteNationality(id int64) error {
+	return catalogWrite("Nationality", id, "DELETE FROM Nationality WHERE Id = ?", id)
+}
+
+// UniversityExists indica si existe la universidad.
+func UniversityExists(id int64) (bool, error) {
+	return catalogRowExists("University", id)
+}
+
+// InsertUniversity crea una universidad y devuelve su ID.
+func InsertUniversity(uni models.University) (int64, error) {
+	result, err := DB.Exec("INSERT INTO University (Name, Campus, EmailDomains, Slug) VALUES (?, ?, ?, ?)",
+		uni.Name, uni.Campus, strings.Join(uni.EmailDomains, ","), universitySlug(uni.Slug))
+	if err != nil {
+		return 0, err
+	}
+	InvalidateCatalogCache()
+	return result.LastInsertId()
+}
+
+// UpdateUniversity reemplaza los datos de la universidad. Si no existe devuelve sql.ErrNoRows.
+func UpdateUniversity(uni models.University) error {
+	return catalogWrite("University", uni.Id,
+		"UPDATE University SET Name = ?, Campus = ?, EmailDomains = ?, Slug = ? WHERE Id = ?",
+		uni.Name, uni.Campus, strings.Join(uni.EmailDomains, ","), universitySlug(uni.Slug), uni.Id)
+}
+
+// universitySlug guarda el subdominio vacío como NULL: la columna es única y varias
+// universidades pueden no tenerlo.
+func universitySlug(slug string) sql.NullString {
+	return sql.NullString{String: slug, Valid: slug != ""}
+}
+
+// FindUniversityBySlug devuelve la universidad del subdominio. ok es false si ninguna lo usa.
+func FindUniversityBySlug(slug string) (uni models.University, ok bool, err error) {
+	universities, err := GetUniversities()
+	if err != nil {
+		return uni, false, err
+	}
+	for _, u := range universities {
+		if u.Slug != "" && u.Slug == slug {
+			return u, true, nil
+		}
+	}
+	return uni, false, nil
+}
+
+// FindUniversityByEmailDomain devuelve la universidad a la que pertenece el dominio de correo,
+// según los dominios configurados en el catálogo. ok es false si no pertenece a ninguna.
+func FindUniversityByEmailDomain(domain string) (uni models.University, ok bool, err error) {
+	universities, err := GetUniversities()
+	if err != nil {
+		return uni, false, err
+	}
+	for _, u := range universities {
+		if u.MatchesEmailDomain(domain) {
+			return u, true, nil
+		}
+	}
+	return uni, false, nil
+}
+
+// splitEmailDomains convierte la columna EmailDomains (dominios separados por comas) en lista.
+func splitEmailDomains(value string) []string {
+	domains := []string{}
+	for _, d := range strings.Split(value, ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return domains
+}
+
+// DeleteUniversity elimina la universidad. Si no existe devuelve sql.ErrNoRows; si tiene
+// carreras o usuarios la base de datos rechaza el borrado.
+func DeleteUniversity(id int64) error {
+	return catalogWrite("University", id, "DELETE FROM University WHERE Id = ?", id)
+}
+
+// DegreeTaken indica si la universidad ya tiene otra carrera distinta de excludeID con ese
+// nombre o código.
+func DegreeTaken(universityID int64, name, code string, excludeID int64) (bool, error) {
+	var taken bool
+	err := DB.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM Degree
+			WHERE UniversityId = ? AND Id <> ? AND (DegreeName = ? OR (? <> '' AND Code = ?))
+		)`, universityID, excludeID, name, code, code).Scan(&taken)
+	if err != nil {
+		return false, fmt.Errorf("error al comprobar la carrera %s: %w", name, err)
+	}
+	return taken, nil
+}
+
+// InsertDegree crea una carrera y devuelve su ID.
+func InsertDegree(deg models.Degree) (int64, error) {
+	result, err := DB.Exec("INSERT INTO Degree (DegreeName, Descriptions, Code, UniversityId) VALUES (?, ?, ?, ?)",
+		deg.DegreeName, deg.Descriptions, deg.Code, deg.UniversityId)
+	if err != nil {
+		return 0, err
+	}
+	InvalidateCatalogCache()
+	return result.LastInsertId()
+}
+
+// UpdateDegree reemplaza los datos de la carrera. Si no existe devuelve sql.ErrNoRows.
+func UpdateDegree(deg models.Degree) error {
+	return catalogWrite("Degree", deg.Id,
+		"UPDATE Degree SET DegreeName = ?, Descriptions = ?, Code = ?, UniversityId = ? WHERE Id = ?",
+		deg.DegreeName, deg.Descriptions, deg.Code, deg.UniversityId, deg.Id)
+}
+
+// DeleteDegree elimina la carrera. Si no existe devuelve sql.ErrNoRows; si algún usuario la
+// tiene asignada la base de datos rechaza el borrado.
+func DeleteDegree(id int64) error {
+	return catalogWrite("Degree", id, "DELETE FROM Degree WHERE Id = ?", id)
+}
@@ -0,0 +1,246 @@
+package queries
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// ErrChallengeStatusChanged indica que el estado del desafío cambió mientras se procesaba
+// la transición (otra petición lo modificó antes).
+var ErrChallengeStatusChanged = errors.New("el estado del desafío cambió durante la operación")
+
+// submissionColumns son las columnas que lee scanSubmission.
+const submissionColumns = `
+	s.Id, s.CommunityEventId, s.UserId, COALESCE(s.RepositoryUrl, ''), COALESCE(s.FileContentId, ''),
+	COALESCE((SELECT m.FileName FROM Multimedia m WHERE m.ContentId = s.FileContentId AND m.UserId = s.UserId LIMIT 1), ''),
+	COALESCE(s.Description, ''), s.Status, s.Score, COALESCE(s.Feedback, ''), s.ReviewedAt, s.PointsRP,
+	s.SubmittedAt, s.UpdatedAt`
+
+func scanSubmission(row rowScanner, extra ...any) (models.ChallengeSubmission, error) {
+	var sub models.ChallengeSubmission
+	var score, pointsRP sql.NullInt64
+	var reviewedAt sql.NullTime
+	dest := []any{
+		&sub.Id, &sub.CommunityEventId, &sub.UserId, &sub.RepositoryUrl, &sub.FileContentId, &sub.FileName,
+		&sub.Description, &sub.Status, &score, &sub.Feedback, &reviewedAt, &pointsRP,
+		&sub.SubmittedAt, &sub.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return sub, err
+	}
+	if score.Valid {
+		value := int(score.Int64)
+		sub.Score = &value
+	}
+	if pointsRP.Valid {
+		value := int(pointsRP.Int64)
+		sub.PointsRP = &value
+	}
+	if reviewedAt.Valid {
+		sub.ReviewedAt = &reviewedAt.Time
+	}
+	return sub, nil
+}
+
+// GetChallengeInfo devuelve el tipo, dueño, estado y fechas de una publicación. Si no
+// existe devuelve sql.ErrNoRows.
+func GetChallengeInfo(eventID int64) (*models.ChallengeInfo, error) {
+	var info models.ChallengeInfo
+	var startDate, endDate sql.NullTime
+	err := DB.QueryRow(`
+		SELECT Id, Title, PostType, CreatedByUserId, ChallengeStatus, ChallengeStartDate, ChallengeEndDate
+		FROM CommunityEvent WHERE Id = ?`, eventID).
+		Scan(&info.Id, &info.Title, &info.PostType, &info.OwnerId, &info.Status, &startDate, &endDate)
+	if err != nil {
+		return nil, err
+	}
+	if startDate.Valid {
+		info.StartDate = &startDate.Time
+	}
+	if endDate.Valid {
+		info.EndDate = &endDate.Time
+	}
+	return &info, nil
+}
+
+// UserOwnsContent indica si el archivo (Multimedia.ContentId) fue subido por el usuario.
+func UserOwnsContent(userID int64, contentID string) (bool, error) {
+	var exists bool
+	err := DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM Multimedia WHERE ContentId = ? AND UserId = ?)`, contentID, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error al verificar el archivo %s del usuario %d: %w", contentID, userID, err)
+	}
+	return exists, nil
+}
+
+// GetUserSubmission devuelve la entrega del usuario al desafío. Si no existe devuelve
+// sql.ErrNoRows.
+func GetUserSubmission(eventID, userID int64) (*models.ChallengeSubmission, error) {
+	row := DB.QueryRow(`SELECT `+submissionColumns+` FROM ChallengeSubmission s WHERE s.CommunityEventId = ? AND s.UserId = ?`, eventID, userID)
+	sub, err := scanSubmission(row)
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// GetSubmissionByID devuelve una entrega. Si no existe devuelve sql.ErrNoRows.
+func GetSubmissionByID(submissionID int64) (*models.ChallengeSubmission, error) {
+	row := DB.QueryRow(`SELECT `+submissionColumns+` FROM ChallengeSubmission s WHERE s.Id = ?`, submissionID)
+	sub, err := scanSubmission(row)
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// SaveSubmission crea la entrega del usuario o reemplaza la que ya tenía. Devuelve true si
+// se creó.
+func SaveSubmission(eventID, userID int64, req models.SubmitChallengeRequest) (bool, error) {
+	result, err := DB.Exec(`
+		INSERT INTO ChallengeSubmission (CommunityEventId, UserId, RepositoryUrl, FileContentId, Description)
+		VALUES (?, ?, NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''))
+		ON DUPLICATE KEY UPDATE
+			RepositoryUrl = VALUES(RepositoryUrl), FileContentId = VALUES(FileContentId),
+			Description = VALUES(Description), SubmittedAt = CURRENT_TIMESTAMP`,
+		eventID, userID, req.RepositoryUrl, req.FileContentId, req.Description)
+	if err != nil {
+		return false, fmt.Errorf("error al guardar la entrega de %d al desafío %d: %w", userID, eventID, err)
+	}
+	// MySQL devuelve 1 fila afectada al insertar y 2 al actualizar.
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// ListSubmissions devuelve una página de las entregas del desafío, de la más antigua a la
+// más reciente, con los datos del participante y el total. status vacío no filtra.
+func ListSubmissions(eventID int64, status string, limit, offset int) ([]models.ChallengeSubmission, int, error) {
+	var total int
+	if err := DB.QueryRow(`
+		SELECT COUNT(*) FROM ChallengeSubmission WHERE CommunityEventId = ? AND (? = '' OR Status = ?)`,
+		eventID, status, status).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error al contar las entregas del desafío %d: %w", eventID, err)
+	}
+	if total == 0 {
+		return []models.ChallengeSubmission{}, 0, nil
+	}
+
+	rows, err := DB.Query(`
+		SELECT `+submissionColumns+`,
+		       COALESCE(u.FirstName, ''), COALESCE(u.LastName, ''), COALESCE(u.UserName, ''), COALESCE(u.Picture, '')
+		FROM ChallengeSubmission s
+		JOIN User u ON u.Id = s.UserId
+		WHERE s.CommunityEventId = ? AND (? = '' OR s.Status = ?)
+		ORDER BY s.SubmittedAt, s.Id
+		LIMIT ? OFFSET ?`, eventID, status, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al obtener las entregas del desafío %d: %w", eventID, err)
+	}
+	defer rows.Close()
+
+	submissions := []models.ChallengeSubmission{}
+	for rows.Next() {
+		var participant models.SubmissionParticipant
+		sub, err := scanSubmission(rows, &participant.FirstName, &participant.LastName, &participant.UserName, &participant.Picture)
+		if err != nil {
+			return nil, 0, err
+		}
+		sub.Participant = &participant
+		submissions = append(submissions, sub)
+	}
+	return submissions, total, rows.Err()
+}
+
+// GetAllSubmissions devuelve todas las entregas del desafío, sin paginar. Se usa al cerrar
+// o cancelar el desafío.
+func GetAllSubmissions(eventID int64) ([]models.ChallengeSubmission, error) {
+	rows, err := DB.Query(`SELECT `+submissionColumns+` FROM ChallengeSubmission s WHERE s.CommunityEventId = ? ORDER BY s.Id`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las entregas del desafío %d: %w", eventID, err)
+	}
+	defer rows.Close()
+
+	var submissions []models.ChallengeSubmission
+	for rows.Next() {
+		sub, err := scanSubmission(rows)
+		if err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, sub)
+	}
+	return submissions, rows.Err()
+}
+
+// ReviewSubmission guarda la revisión de una entrega.
+func ReviewSubmission(submissionID, reviewerID int64, status string, score *int, feedback string) error {
+	_, err := DB.Exec(`
+		UPDATE ChallengeSubmission
+		SET Status = ?, Score = ?, Feedback = NULLIF(?, ''), ReviewedBy = ?, ReviewedAt = NOW()
+		WHERE Id = ?`, status, score, feedback, reviewerID, submissionID)
+	if err != nil {
+		return fmt.Errorf("error al revisar la entrega %d: %w", submissionID, err)
+	}
+	return nil
+}
+
+// UpdateChallengeStatus cambia el estado del desafío si sigue siendo from. Devuelve
+// ErrChallengeStatusChanged si otra petición lo cambió antes.
+func UpdateChallengeStatus(eventID int64, from, to string) error {
+	result, err := DB.Exec(`UPDATE CommunityEvent SET ChallengeStatus = ? WHERE Id = ? AND ChallengeStatus = ?`, to, eventID, from)
+	if err != nil {
+		return fmt.Errorf("error al cambiar el estado del desafío %d: %w", eventID, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrChallengeStatusChanged
+	}
+	return nil
+}
+
+// ChallengeAward son los RP que recibe el autor de una entrega aprobada al cerrar el desafío.
+type ChallengeAward struct {
+	SubmissionId int64
+	UserId       int64
+	PointsRP     int
+	Rating       float64
+	Comment      string
+}
+
+// CloseChallenge pasa el desafío de from a CERRADO y, en la misma transacción, registra los
+// RP de cada entrega aprobada como una ReputationReview de tipo DESAFIO_COMPLETADO emitida
+// por el dueño del desafío. Si ya existía una reseña del dueño al participante para este
+// desafío no se duplica.
+func CloseChallenge(eventID, ownerID int64, from string, awards []ChallengeAward) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE CommunityEvent SET ChallengeStatus = ? WHERE Id = ? AND ChallengeStatus = ?`,
+		models.ChallengeStatusClosed, eventID, from)
+	if err != nil {
+		return fmt.Errorf("error al cerrar el desafío %d: %w", eventID, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrChallengeStatusChanged
+	}
+
+	for _, award := range awards {
+		if _, err := tx.Exec(`
+			INSERT IGNORE INTO ReputationReview (ReviewerId, RevieweeId, CommunityEventId, PointsRP, Rating, Comment, InteractionType)
+			VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), 'DESAFIO_COMPLETADO')`,
+			ownerID, award.UserId, eventID, award.PointsRP, award.Rating, award.Comment); err != nil {
+			return fmt.Errorf("error al otorgar los RP de la entrega %d: %w", award.SubmissionId, err)
+		}
+		if _, err := tx.Exec(`UPDATE ChallengeSubmission SET PointsRP = ? WHERE Id = ?`, award.PointsRP, award.SubmissionId); err != nil {
+			return fmt.Errorf("error al guardar los RP de la entrega %d: %w", award.SubmissionId, err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,249 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+const chatExportColumns = `Id, UserId, ChatId, ChatIdGroup, Format, Status, ObjectPath, MessageCount, ExpiresAt, ErrorMessage, RequestedAt, CompletedAt`
+
+func scanChatExport(row rowScanner) (*models.ChatExport, error) {
+	var export models.ChatExport
+	var chatID, chatIDGroup, objectPath, errMsg sql.NullString
+	var expiresAt, completedAt sql.NullTime
+	if err := row.Scan(&export.Id, &export.UserId, &chatID, &chatIDGroup, &export.Format, &export.Status, &objectPath,
+		&export.MessageCount, &expiresAt, &errMsg, &export.RequestedAt, &completedAt); err != nil {
+		return nil, err
+	}
+	export.ChatId, export.IsGroup = chatID.String, !chatID.Valid
+	if export.IsGroup {
+		export.ChatId = chatIDGroup.String
+	}
+	export.ObjectPath = objectPath.String
+	export.Error = errMsg.String
+	if expiresAt.Valid {
+		export.ExpiresAt = &expiresAt.Time
+	}
+	if completedAt.Valid {
+		export.CompletedAt = &completedAt.Time
+	}
+	return &export, nil
+}
+
+// chatColumn devuelve la columna de Message (y MessageArchive) que identifica el chat.
+func chatColumn(isGroup bool) string {
+	if isGroup {
+		return "ChatIdGroup"
+	}
+	return "ChatId"
+}
+
+// GetUserChatKind comprueba que userID participa en el chat chatID e indica si es un chat de
+// grupo. Devuelve sql.ErrNoRows si el chat no existe o el usuario no participa en él.
+func GetUserChatKind(userID int64, chatID string) (isGroup bool, err error) {
+	err = DB.QueryRow(`
+		SELECT FALSE FROM Contact WHERE ChatId = ? AND (User1Id = ? OR User2Id = ?)
+		UNION ALL
+		SELECT TRUE FROM GroupsUsers g
+		JOIN GroupMembers gm ON gm.GroupId = g.Id
+		WHERE g.ChatId = ? AND gm.UserId = ?
+		LIMIT 1`, chatID, userID, userID, chatID, userID).Scan(&isGroup)
+	if err != nil && err != sql.ErrNoRows {
+		return false, fmt.Errorf("error comprobando el acceso del usuario %d al chat %s: %w", userID, chatID, err)
+	}
+	return isGroup, err
+}
+
+// CountChatMessages cuenta los mensajes de un chat, incluidos los archivados.
+func CountChatMessages(chatID string, isGroup bool) (int, error) {
+	column := chatColumn(isGroup)
+	var total int
+	err := DB.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM Message WHERE `+column+` = ?) +
+		       (SELECT COUNT(*) FROM MessageArchive WHERE `+column+` = ?)`, chatID, chatID).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("error contando los mensajes del chat %s: %w", chatID, err)
+	}
+	return total, nil
+}
+
+// ForEachChatExportMessage recorre en orden cronológico los mensajes de un chat, incluidos los
+// archivados, y llama a fn con cada uno. Los mensajes ocultos por moderación llegan sin
+// contenido ni adjunto. Si fn devuelve un error el recorrido se detiene con ese error.
+func ForEachChatExportMessage(chatID string, isGroup bool, fn func(models.ChatExportMessage) error) error {
+	column := chatColumn(isGroup)
+	rows, err := DB.Query(`
+		SELECT m.Id, m.SenderId, TRIM(CONCAT_WS(' ', u.FirstName, u.LastName)), COALESCE(u.UserName, ''),
+		       m.TypeMessageId, COALESCE(m.Content, ''), COALESCE(m.MediaId, ''), COALESCE(m.ReplyToMessageId, ''),
+		       m.SentAt, m.EditedAt,
+		       EXISTS (SELECT 1 FROM HiddenContent h WHERE h.TargetType = 'MESSAGE' AND h.TargetId = m.Id)
+		FROM (
+			SELECT Id, SenderId, TypeMessageId, Content, MediaId, ReplyToMessageId, SentAt, EditedAt
+			FROM Message WHERE `+column+` = ?
+			UNION ALL
+			SELECT Id, SenderId, TypeMessageId, Content, MediaId, ReplyToMessageId, SentAt, EditedAt
+			FROM MessageArchive WHERE `+column+` = ?
+		) m
+		LEFT JOIN User u ON u.Id = m.SenderId
+		ORDER BY m.SentAt, m.Id`, chatID, chatID)
+	if err != nil {
+		return fmt.Errorf("error consultando los mensajes del chat %s: %w", chatID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var message models.ChatExportMessage
+		var fullName sql.NullString
+		var userName string
+		var editedAt sql.NullTime
+		if err := rows.Scan(&message.Id, &message.SenderId, &fullName, &userName, &message.TypeMessageId, &message.Content,
+			&message.MediaId, &message.ReplyToMessageId, &message.SentAt, &editedAt, &message.IsHidden); err != nil {
+			return fmt.Errorf("error leyendo los mensajes del chat %s: %w", chatID, err)
+		}
+		message.SenderName = fullName.String
+		if message.SenderName == "" {
+			message.SenderName = userName
+		}
+		if editedAt.Valid {
+			message.EditedAt = &editedAt.Time
+		}
+		if message.IsHidden {
+			message.Content, message.MediaId = "", ""
+		}
+		if err := fn(message); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// CreateChatExport registra una exportación pendiente si el usuario no tiene otra en curso del
+// mismo chat. Devuelve el ID y false si ya había una activa.
+func CreateChatExport(userID int64, chatID string, isGroup bool, format string, messageCount int) (int64, bool, error) {
+	column := chatColumn(isGroup)
+	result, err := DB.Exec(`
+		INSERT INTO ChatExport (UserId, `+column+`, Format, MessageCount)
+		SELECT ?, ?, ?, ? FROM DUAL
+		WHERE NOT EXISTS (
+			SELECT 1 FROM ChatExport
+			WHERE UserId = ? AND `+column+` = ? AND Status IN ('pending', 'processing')
+		)`,
+		userID, chatID, format, messageCount, userID, chatID,
+	)
+	if err != nil {
+		return 0, false, fmt.Errorf("error registrando la exportación del chat %s: %w", chatID, err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return 0, false, nil
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, false, fmt.Errorf("error obteniendo ID de la exportación del chat %s: %w", chatID, err)
+	}
+	return id, true, nil
+}
+
+// GetChatExport obtiene una exportación por ID. Devuelve sql.ErrNoRows si no existe.
+func GetChatExport(id int64) (*models.ChatExport, error) {
+	export, err := scanChatExport(DB.QueryRow(`SELECT `+chatExportColumns+` FROM ChatExport WHERE Id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error obteniendo la exportación de chat %d: %w", id, err)
+	}
+	return export, nil
+}
+
+// GetChatExportsByUser lista las exportaciones de un usuario, de la más reciente a la más antigua.
+func GetChatExportsByUser(userID int64, limit, offset int) ([]models.ChatExport, int, error) {
+	var total int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM ChatExport WHERE UserId = ?`, userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error contando las exportaciones de chat del usuario %d: %w", userID, err)
+	}
+	exports, err := queryChatExports(`
+		SELECT `+chatExportColumns+` FROM ChatExport WHERE UserId = ?
+		ORDER BY Id DESC LIMIT ? OFFSET ?`, userID, limit, offset)
+	return exports, total, err
+}
+
+// GetUnfinishedChatExports lista las exportaciones pendientes o interrumpidas (p.ej. por un reinicio).
+func GetUnfinishedChatExports() ([]models.ChatExport, error) {
+	return queryChatExports(`SELECT ` + chatExportColumns + ` FROM ChatExport WHERE Status IN ('pending', 'processing') ORDER BY Id`)
+}
+
+// GetExpiredChatExports lista las exportaciones cuyo archivo ya caducó y sigue en GCS.
+func GetExpiredChatExports(now time.Time) ([]models.ChatExport, error) {
+	return queryChatExports(`
+		SELECT `+chatExportColumns+` FROM ChatExport
+		WHERE ObjectPath IS NOT NULL AND ExpiresAt < ?`, now)
+}
+
+// GetStoredChatExportsByUser lista las exportaciones de un usuario cuyo archivo sigue en GCS.
+func GetStoredChatExportsByUser(userID int64) ([]models.ChatExport, error) {
+	return queryChatExports(`SELECT `+chatExportColumns+` FROM ChatExport WHERE UserId = ? AND ObjectPath IS NOT NULL`, userID)
+}
+
+func queryChatExports(query string, args ...any) ([]models.ChatExport, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo exportaciones de chat: %w", err)
+	}
+	defer rows.Close()
+
+	exports := []models.ChatExport{}
+	for rows.Next() {
+		export, err := scanChatExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error escaneando exportación de chat: %w", err)
+		}
+		exports = append(exports, *export)
+	}
+	return exports, rows.Err()
+}
+
+// ClaimChatExport pasa una exportación a 'processing' si está pendiente o abandonada (ver
+// stalePrivacyRequestAfter). Devuelve false si otra instancia ya la está procesando o ya terminó.
+func ClaimChatExport(id int64) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE ChatExport SET Status = 'processing'
+		WHERE Id = ? AND (Status = 'pending' OR (Status = 'processing' AND RequestedAt < ?))`,
+		id, time.Now().Add(-stalePrivacyRequestAfter))
+	if err != nil {
+		return false, fmt.Errorf("error reclamando la exportación de chat %d: %w", id, err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// CompleteChatExport marca una exportación como completada con la ruta del archivo, el número
+// de mensajes exportados y la caducidad del archivo.
+func CompleteChatExport(id int64, objectPath string, messageCount int, expiresAt time.Time) error {
+	_, err := DB.Exec(`
+		UPDATE ChatExport SET Status = 'completed', ObjectPath = ?, MessageCount = ?, ExpiresAt = ?,
+		       CompletedAt = NOW(), ErrorMessage = NULL
+		WHERE Id = ?`, objectPath, messageCount, expiresAt, id)
+	if err != nil {
+		return fmt.Errorf("error completando la exportación de chat %d: %w", id, err)
+	}
+	return nil
+}
+
+// FailChatExport marca una exportación como fallida.
+func FailChatExport(id int64, errMsg string) error {
+	if _, err := DB.Exec(`UPDATE ChatExport SET Status = 'failed', ErrorMessage = ?, CompletedAt = NOW() WHERE Id = ?`, errMsg, id); err != nil {
+		return fmt.Errorf("error marcando como fallida la exportación de chat %d: %w", id, err)
+	}
+	return nil
+}
+
+// ClearChatExportObject olvida la ruta de un archivo de exportación ya eliminado de GCS.
+func ClearChatExportObject(id int64) error {
+	if _, err := DB.Exec(`UPDATE ChatExport SET ObjectPath = NULL WHERE Id = ?`, id); err != nil {
+		return fmt.Errorf("error limpiando el archivo de la exportación de chat %d: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package queries
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// Registro de cambios de la lista de chats para la sincronización incremental (ver
+// docs/sincronizacion_chats.md). Un mensaje nuevo ya se ve en la fecha del último mensaje; aquí
+// se anotan los demás cambios de un chat (lecturas, bloqueos, contacto aceptado, mensajes
+// borrados) y los chats que desaparecen de la lista.
+
+// chatSyncUpsert anota un cambio en los chats de la lista de cada participante. %s es la
+// condición sobre el contacto c, que se repite en las dos mitades de la unión.
+const chatSyncUpsert = `
+	INSERT INTO ChatSyncChange (UserId, OtherUserId, ChatId, Removed, ChangedAt)
+	SELECT * FROM (
+		SELECT c.User1Id, c.User2Id, c.ChatId, FALSE, ? FROM Contact c
+		WHERE c.Status = 'accepted' AND c.ChatId IS NOT NULL AND (%[1]s)
+		UNION ALL
+		SELECT c.User2Id, c.User1Id, c.ChatId, FALSE, ? FROM Contact c
+		WHERE c.Status = 'accepted' AND c.ChatId IS NOT NULL AND (%[1]s)
+	) AS changes
+	ON DUPLICATE KEY UPDATE ChatId = VALUES(ChatId), Removed = FALSE, ChangedAt = VALUES(ChangedAt)`
+
+// touchChatSync anota un cambio en los chats de los contactos aceptados que cumplen condition
+// (sobre el alias c de Contact), para los dos participantes.
+func touchChatSync(ex Execer, condition string, args ...interface{}) error {
+	now := time.Now()
+	allArgs := append(append([]interface{}{now}, args...), now)
+	allArgs = append(allArgs, args...)
+	if _, err := ex.Exec(fmt.Sprintf(chatSyncUpsert, condition), allArgs...); err != nil {
+		return fmt.Errorf("error registrando el cambio de chat para la sincronización: %w", err)
+	}
+	return nil
+}
+
+// TouchChatSync anota un cambio en el chat privado chatID (por ejemplo, mensajes marcados como
+// leídos). Los chats de grupo no están en la lista de chats y se ignoran.
+func TouchChatSync(chatID string) error {
+	if chatID == "" {
+		return nil
+	}
+	return touchChatSync(DB, "c.ChatId = ?", chatID)
+}
+
+// touchChatSyncBetween anota un cambio en el chat entre dos usuarios. Como la invalidación de
+// la caché, no hace fallar la operación que lo origina: solo registra el error.
+func touchChatSyncBetween(user1ID, user2ID int64) {
+	err := touchChatSync(DB, "(c.User1Id = ? AND c.User2Id = ?) OR (c.User1Id = ? AND c.User2Id = ?)",
+		user1ID, user2ID, user2ID, user1ID)
+	if err != nil {
+		logger.Warnf("QUERY", "No se pudo registrar el cambio del chat entre %d y %d: %v", user1ID, user2ID, err)
+	}
+}
+
+// recordChatTombstones marca como eliminados los chats de userID en la lista de sus contactos,
+// antes de borrar sus contactos.
+func recordChatTombstones(ex Execer, userID int64) error {
+	_, err := ex.Exec(`
+		INSERT INTO ChatSyncChange (UserId, OtherUserId, ChatId, Removed, ChangedAt)
+		SELECT CASE WHEN c.User1Id = ? THEN c.User2Id ELSE c.User1Id END, ?, c.ChatId, TRUE, ?
+		FROM Contact c
+		WHERE (c.User1Id = ? OR c.User2Id = ?) AND c.Status = 'accepted' AND c.ChatId IS NOT NULL
+		ON DUPLICATE KEY UPDATE ChatId = VALUES(ChatId), Removed = TRUE, ChangedAt = VALUES(ChangedAt)`,
+		userID, userID, time.Now(), userID, userID)
+	if err != nil {
+		return fmt.Errorf("error registrando los chats eliminados del usuario %d: %w", userID, err)
+	}
+	return nil
+}
+
+// GetChatSyncChanges devuelve los cambios de la lista de chats de userID anotados después de
+// since.
+func GetChatSyncChanges(userID int64, since time.Time) ([]models.ChatSyncChange, error) {
+	rows, err := DB.Query(`
+		SELECT OtherUserId, ChatId, Removed, ChangedAt
+		FROM ChatSyncChange
+		WHERE UserId = ? AND ChangedAt > ?`, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo los cambios de chats del usuario %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	var changes []models.ChatSyncChange
+	for rows.Next() {
+		var change models.ChatSyncChange
+		if err := rows.Scan(&change.OtherUserID, &change.ChatID, &change.Removed, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("error escaneando un cambio de chat: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// DeleteOldChatSyncChanges borra los cambios anotados hace más de retention.
+func DeleteOldChatSyncChanges(retention time.Duration) (int64, error) {
+	result, err := DB.Exec(`DELETE FROM ChatSyncChange WHERE ChangedAt < ?`, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("error al limpiar los cambios de chats: %w", err)
+	}
+	return result.RowsAffected()
+}
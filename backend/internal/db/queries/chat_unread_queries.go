@@ -0,0 +1,146 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Contadores de mensajes no leídos por usuario y chat privado (tabla ChatUnread, ver
+// docs/contadores_no_leidos.md). Se mantienen al enviar y al leer mensajes, en la misma
+// transacción que el cambio del mensaje; la conciliación los recalcula desde Message.
+
+// chatUnreadRecount recalcula los contadores de los dos participantes de los chats de los
+// contactos que cumplen la condición %[1]s (sobre el alias c de Contact). Cuenta lo mismo que
+// contaba la lista de chats: los mensajes del otro participante sin leer y no ocultados por el
+// filtro de contenido.
+const chatUnreadRecount = `
+	INSERT INTO ChatUnread (UserId, ChatId, Unread)
+	SELECT p.UserId, p.ChatId, COUNT(m.Id)
+	FROM (
+		SELECT c.User1Id AS UserId, c.User2Id AS OtherId, c.ChatId FROM Contact c
+		WHERE c.ChatId IS NOT NULL AND (%[1]s)
+		UNION ALL
+		SELECT c.User2Id, c.User1Id, c.ChatId FROM Contact c
+		WHERE c.ChatId IS NOT NULL AND (%[1]s)
+	) p
+	LEFT JOIN Message m ON m.ChatId = p.ChatId AND m.SenderId = p.OtherId AND m.Status <> 'read'
+		AND NOT EXISTS (` + shadowDeletedMessage + `)
+	GROUP BY p.UserId, p.ChatId
+	ON DUPLICATE KEY UPDATE Unread = VALUES(Unread)`
+
+// IncrementChatUnread suma un mensaje no leído al contador de userID en el chat privado chatID.
+func IncrementChatUnread(ex Execer, userID int64, chatID string) error {
+	_, err := ex.Exec(`
+		INSERT INTO ChatUnread (UserId, ChatId, Unread) VALUES (?, ?, 1)
+		ON DUPLICATE KEY UPDATE Unread = Unread + 1`, userID, chatID)
+	if err != nil {
+		return fmt.Errorf("error incrementando los no leídos del chat %s para el usuario %d: %w", chatID, userID, err)
+	}
+	return nil
+}
+
+// DecrementChatUnread resta del contador del destinatario el mensaje messageID de senderID,
+// recién marcado como leído. Los mensajes ocultados por el filtro de contenido no se contaron.
+func DecrementChatUnread(ex Execer, messageID, chatID string, senderID int64) error {
+	_, err := ex.Exec(`
+		UPDATE ChatUnread SET Unread = Unread - 1
+		WHERE ChatId = ? AND UserId <> ? AND Unread > 0
+		  AND NOT EXISTS (SELECT 1 FROM ContentFilterHit f WHERE f.MessageId = ? AND f.Action = 'BORRADO_SILENCIOSO')`,
+		chatID, senderID, messageID)
+	if err != nil {
+		return fmt.Errorf("error descontando el mensaje leído %s del chat %s: %w", messageID, chatID, err)
+	}
+	return nil
+}
+
+// recountChatUnread recalcula los contadores de los chats de los contactos que cumplen
+// condition. Devuelve las filas afectadas: 0 si ningún contador cambió.
+func recountChatUnread(ex Execer, condition string, args ...interface{}) (int64, error) {
+	allArgs := append(append([]interface{}{}, args...), args...)
+	result, err := ex.Exec(fmt.Sprintf(chatUnreadRecount, condition), allArgs...)
+	if err != nil {
+		return 0, fmt.Errorf("error recalculando los mensajes no leídos: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// recountChatUnreadByChatIDs recalcula los contadores de los chats privados indicados, tras
+// borrar mensajes que podían estar sin leer.
+func recountChatUnreadByChatIDs(ex Execer, chatIDs []string) error {
+	if len(chatIDs) == 0 {
+		return nil
+	}
+	args := make([]interface{}, len(chatIDs))
+	for i, chatID := range chatIDs {
+		args[i] = chatID
+	}
+	_, err := recountChatUnread(ex, "c.ChatId IN ("+placeholders(len(args))+")", args...)
+	return err
+}
+
+// ReconcileChatUnread recalcula los contadores de hasta limit contactos con ContactId mayor
+// que afterContactID. Devuelve el último ContactId procesado (0 si no quedan) y las filas
+// afectadas: 0 si todos los contadores estaban bien.
+func ReconcileChatUnread(afterContactID int64, limit int) (int64, int64, error) {
+	rows, err := DB.Query(`
+		SELECT ContactId FROM Contact
+		WHERE ContactId > ? AND ChatId IS NOT NULL
+		ORDER BY ContactId LIMIT ?`, afterContactID, limit)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error obteniendo los contactos a conciliar: %w", err)
+	}
+	var ids []string
+	var lastID int64
+	for rows.Next() {
+		if err := rows.Scan(&lastID); err != nil {
+			rows.Close()
+			return 0, 0, fmt.Errorf("error escaneando un contacto a conciliar: %w", err)
+		}
+		ids = append(ids, strconv.FormatInt(lastID, 10))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+	if len(ids) == 0 {
+		return 0, 0, nil
+	}
+
+	// Los Id son enteros leídos de la base de datos: se escriben en la condición, que se repite
+	// en las dos mitades de la consulta.
+	changed, err := recountChatUnread(DB, "c.ContactId IN ("+strings.Join(ids, ", ")+")")
+	if err != nil {
+		return 0, 0, err
+	}
+	return lastID, changed, nil
+}
+
+// GetUnreadMessagesTotal devuelve el total de mensajes de chats privados sin leer de userID.
+func GetUnreadMessagesTotal(userID int64) (int, error) {
+	var total int
+	err := DB.QueryRow(`SELECT COALESCE(SUM(Unread), 0) FROM ChatUnread WHERE UserId = ?`, userID).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo los mensajes no leídos del usuario %d: %w", userID, err)
+	}
+	return total, nil
+}
+
+// messageChatIDs devuelve los chats privados de los mensajes indicados.
+func messageChatIDs(tx *sql.Tx, messageIDs []interface{}) ([]string, error) {
+	rows, err := tx.Query(`SELECT DISTINCT ChatId FROM Message WHERE ChatId IS NOT NULL AND Id IN (`+placeholders(len(messageIDs))+`)`, messageIDs...)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo los chats de los mensajes: %w", err)
+	}
+	defer rows.Close()
+	var chatIDs []string
+	for rows.Next() {
+		var chatID string
+		if err := rows.Scan(&chatID); err != nil {
+			return nil, fmt.Errorf("error escaneando el chat de un mensaje: %w", err)
+		}
+		chatIDs = append(chatIDs, chatID)
+	}
+	return chatIDs, rows.Err()
+}
@@ -0,0 +1,150 @@
+package queries
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/cache"
+)
+
+// cohortMembersCTE es la población de las analíticas de cohortes: una fila por estudio
+// terminado o en curso con fecha de graduación de cada estudiante o egresado activo. Los %s
+// son la condición de rol, la de estado y las condiciones adicionales del filtro.
+const cohortMembersCTE = `
+	members AS (
+		SELECT e.PersonId, TRIM(e.Institution) AS University, TRIM(e.Degree) AS Degree,
+			YEAR(e.GraduationDate) AS GraduationYear, COALESCE(e.IsCurrentlyStudying, FALSE) AS Studying,
+			EXISTS (SELECT 1 FROM WorkExperience w WHERE w.PersonId = e.PersonId AND w.IsCurrentJob) AS Employed
+		FROM Education e
+		JOIN User u ON u.Id = e.PersonId
+		WHERE %s AND %s
+			AND e.GraduationDate IS NOT NULL AND TRIM(e.Institution) <> '' AND TRIM(e.Degree) <> ''%s
+	)`
+
+// cohortAnalyticsCacheKey identifica un filtro y un tamaño de ranking en la caché.
+func cohortAnalyticsCacheKey(filter models.CohortAnalyticsFilter, topN int) string {
+	return fmt.Sprintf("%s:%s:%s:%d:%d:%d", cacheGroupCohort,
+		strings.ToLower(filter.University), strings.ToLower(filter.Degree), filter.FromYear, filter.ToYear, topN)
+}
+
+// cohortFilterConditions devuelve las condiciones SQL y los argumentos del filtro.
+func cohortFilterConditions(filter models.CohortAnalyticsFilter) (string, []interface{}) {
+	var sb strings.Builder
+	var args []interface{}
+	if filter.University != "" {
+		sb.WriteString(" AND LOWER(TRIM(e.Institution)) = LOWER(?)")
+		args = append(args, filter.University)
+	}
+	if filter.Degree != "" {
+		sb.WriteString(" AND LOWER(TRIM(e.Degree)) = LOWER(?)")
+		args = append(args, filter.Degree)
+	}
+	if filter.FromYear > 0 {
+		sb.WriteString(" AND YEAR(e.GraduationDate) >= ?")
+		args = append(args, filter.FromYear)
+	}
+	if filter.ToYear > 0 {
+		sb.WriteString(" AND YEAR(e.GraduationDate) <= ?")
+		args = append(args, filter.ToYear)
+	}
+	return sb.String(), args
+}
+
+// GetCohortAnalytics calcula las estadísticas de las cohortes que cumplen el filtro y las
+// topN empresas y habilidades más frecuentes entre sus miembros. Devuelve todas las cohortes,
+// sin omitir las pequeñas. El resultado se cachea (CACHE_COHORT_TTL).
+func GetCohortAnalytics(filter models.CohortAnalyticsFilter, topN int) (*models.CohortAnalytics, error) {
+	return cache.Load(queryCache, cohortAnalyticsCacheKey(filter, topN), cacheTTL.cohort, func() (*models.CohortAnalytics, error) {
+		return loadCohortAnalytics(filter, topN)
+	})
+}
+
+func loadCohortAnalytics(filter models.CohortAnalyticsFilter, topN int) (*models.CohortAnalytics, error) {
+	conditions, args := cohortFilterConditions(filter)
+	with := "WITH" + fmt.Sprintf(cohortMembersCTE, studentRoleIn("u.RoleId"), disabledStatusNotIn("u.StatusAuthorizedId"), conditions)
+	result := &models.CohortAnalytics{
+		Filter:       filter,
+		Cohorts:      []models.CohortStats{},
+		TopEmployers: []models.EmployerCount{},
+		TopSkills:    []models.SkillCount{},
+		GeneratedAt:  time.Now().UTC(),
+	}
+
+	rows, err := DB.Query(with+`
+		SELECT MIN(University), MIN(Degree), GraduationYear, COUNT(DISTINCT PersonId),
+			COUNT(DISTINCT CASE WHEN Studying THEN PersonId END),
+			COUNT(DISTINCT CASE WHEN Employed THEN PersonId END)
+		FROM members
+		GROUP BY LOWER(University), LOWER(Degree), GraduationYear
+		ORDER BY MIN(University), MIN(Degree), GraduationYear DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al calcular las cohortes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c models.CohortStats
+		if err := rows.Scan(&c.University, &c.Degree, &c.GraduationYear, &c.Members, &c.Studying, &c.Employed); err != nil {
+			return nil, fmt.Errorf("error al leer las cohortes: %w", err)
+		}
+		result.Cohorts = append(result.Cohorts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = DB.QueryRow(with+`
+		SELECT COUNT(DISTINCT PersonId), COUNT(DISTINCT CASE WHEN Employed THEN PersonId END)
+		FROM members`, args...).Scan(&result.Totals.Members, &result.Totals.Employed)
+	if err != nil {
+		return nil, fmt.Errorf("error al calcular los totales de las cohortes: %w", err)
+	}
+
+	employerRows, err := DB.Query(with+`
+		SELECT MIN(TRIM(w.Company)) AS Company, COUNT(DISTINCT w.PersonId) AS Employees
+		FROM WorkExperience w
+		WHERE w.IsCurrentJob AND TRIM(w.Company) <> '' AND w.PersonId IN (SELECT PersonId FROM members)
+		GROUP BY LOWER(TRIM(w.Company))
+		ORDER BY Employees DESC, Company
+		LIMIT ?`, append(args, topN)...)
+	if err != nil {
+		return nil, fmt.Errorf("error al calcular las empresas de las cohortes: %w", err)
+	}
+	defer employerRows.Close()
+	for employerRows.Next() {
+		var e models.EmployerCount
+		if err := employerRows.Scan(&e.Company, &e.Employees); err != nil {
+			return nil, fmt.Errorf("error al leer las empresas de las cohortes: %w", err)
+		}
+		result.TopEmployers = append(result.TopEmployers, e)
+	}
+	if err := employerRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Las habilidades enlazadas al catálogo se agrupan por su nombre canónico.
+	skillRows, err := DB.Query(with+`
+		SELECT MIN(COALESCE(sc.Name, TRIM(s.Skill))) AS Skill, COUNT(DISTINCT s.PersonId) AS Members
+		FROM Skills s
+		LEFT JOIN SkillCatalog sc ON sc.Id = s.SkillCatalogId
+		WHERE COALESCE(sc.Name, TRIM(s.Skill)) <> '' AND s.PersonId IN (SELECT PersonId FROM members)
+		GROUP BY LOWER(COALESCE(sc.Name, TRIM(s.Skill)))
+		ORDER BY Members DESC, Skill
+		LIMIT ?`, append(args, topN)...)
+	if err != nil {
+		return nil, fmt.Errorf("error al calcular las habilidades de las cohortes: %w", err)
+	}
+	defer skillRows.Close()
+	for skillRows.Next() {
+		var s models.SkillCount
+		if err := skillRows.Scan(&s.Skill, &s.Applicants); err != nil {
+			return nil, fmt.Errorf("error al leer las habilidades de las cohortes: %w", err)
+		}
+		result.TopSkills = append(result.TopSkills, s)
+	}
+	if err := skillRows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,302 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// commentColumns son las columnas que lee scanComment, con el autor del comentario y si
+// moderación lo ocultó.
+const commentColumns = `
+	c.Id, c.CommunityEventId, c.ParentCommentId, c.Content, c.CreatedAt, c.EditedAt, c.DeletedAt,
+	u.Id, u.RoleId, COALESCE(u.FirstName, ''), COALESCE(u.LastName, ''), COALESCE(u.CompanyName, ''), COALESCE(u.Picture, ''),
+	EXISTS (SELECT 1 FROM HiddenContent h WHERE h.TargetType = 'COMMENT' AND h.TargetId = CAST(c.Id AS CHAR))`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanComment(row rowScanner, extra ...any) (models.Comment, error) {
+	var comment models.Comment
+	var parentID sql.NullInt64
+	var editedAt, deletedAt sql.NullTime
+	dest := []any{
+		&comment.Id, &comment.CommunityEventId, &parentID, &comment.Content, &comment.CreatedAt, &editedAt, &deletedAt,
+		&comment.Author.Id, &comment.Author.RoleId, &comment.Author.FirstName, &comment.Author.LastName,
+		&comment.Author.CompanyName, &comment.Author.Picture, &comment.IsHidden,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return comment, err
+	}
+	if parentID.Valid {
+		comment.ParentId = &parentID.Int64
+	}
+	if editedAt.Valid {
+		comment.EditedAt = &editedAt.Time
+	}
+	if deletedAt.Valid {
+		// El contenido y el autor de un comentario eliminado no se muestran.
+		comment.IsDeleted = true
+		comment.Content = ""
+		comment.Author = models.CommentAuthor{}
+	} else if comment.IsHidden {
+		// El contenido oculto por moderación no se muestra; el autor se conserva para que
+		// pueda eliminarlo.
+		comment.Content = ""
+	}
+	return comment, nil
+}
+
+// GetCommunityEventOwner devuelve el usuario que creó la publicación y su título. Si no
+// existe o moderación la ocultó devuelve sql.ErrNoRows.
+func GetCommunityEventOwner(eventID int64) (ownerID int64, title string, err error) {
+	err = DB.QueryRow(`
+		SELECT ce.CreatedByUserId, ce.Title FROM CommunityEvent ce
+		WHERE ce.Id = ?
+		  AND NOT EXISTS (SELECT 1 FROM HiddenContent h WHERE h.TargetType = 'POST' AND h.TargetId = CAST(ce.Id AS CHAR))`,
+		eventID).Scan(&ownerID, &title)
+	return ownerID, title, err
+}
+
+// InsertComment guarda un comentario y devuelve su ID.
+func InsertComment(eventID, authorID int64, parentID *int64, content string) (int64, error) {
+	result, err := DB.Exec(`INSERT INTO Comment (CommunityEventId, AuthorId, ParentCommentId, Content) VALUES (?, ?, ?, ?)`,
+		eventID, authorID, parentID, content)
+	if err != nil {
+		return 0, fmt.Errorf("error al guardar el comentario en el evento %d: %w", eventID, err)
+	}
+	return result.LastInsertId()
+}
+
+// GetCommentByID devuelve un comentario con su autor. Si no existe devuelve sql.ErrNoRows.
+func GetCommentByID(commentID int64) (*models.Comment, error) {
+	row := DB.QueryRow(`SELECT `+commentColumns+` FROM Comment c JOIN User u ON u.Id = c.AuthorId WHERE c.Id = ?`, commentID)
+	comment, err := scanComment(row)
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// GetCommentAuthorID devuelve el autor de un comentario y si está eliminado. Si no existe
+// devuelve sql.ErrNoRows.
+func GetCommentAuthorID(commentID int64) (authorID int64, deleted bool, err error) {
+	err = DB.QueryRow(`SELECT AuthorId, DeletedAt IS NOT NULL FROM Comment WHERE Id = ?`, commentID).Scan(&authorID, &deleted)
+	return authorID, deleted, err
+}
+
+// UpdateCommentContent cambia el texto de un comentario no eliminado y marca EditedAt.
+func UpdateCommentContent(commentID int64, content string) error {
+	_, err := DB.Exec(`UPDATE Comment SET Content = ?, EditedAt = NOW() WHERE Id = ? AND DeletedAt IS NULL`, content, commentID)
+	if err != nil {
+		return fmt.Errorf("error al editar el comentario %d: %w", commentID, err)
+	}
+	return nil
+}
+
+// DeleteComment elimina un comentario. Si es un comentario raíz con respuestas se borra
+// de forma lógica para conservar el hilo; en otro caso se borra la fila. Un comentario
+// raíz ya eliminado de forma lógica se borra del todo cuando pierde su última respuesta.
+func DeleteComment(commentID int64) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteComment(tx, commentID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// deleteComment aplica DeleteComment dentro de la transacción tx.
+func deleteComment(tx *sql.Tx, commentID int64) error {
+	var parentID sql.NullInt64
+	var replies int
+	err := tx.QueryRow(`
+		SELECT c.ParentCommentId, (SELECT COUNT(*) FROM Comment r WHERE r.ParentCommentId = c.Id)
+		FROM Comment c WHERE c.Id = ? FOR UPDATE`, commentID).Scan(&parentID, &replies)
+	if err != nil {
+		return err
+	}
+
+	if replies > 0 {
+		_, err = tx.Exec(`UPDATE Comment SET Content = '', DeletedAt = NOW() WHERE Id = ?`, commentID)
+	} else {
+		_, err = tx.Exec(`DELETE FROM Comment WHERE Id = ?`, commentID)
+	}
+	if err != nil {
+		return fmt.Errorf("error al eliminar el comentario %d: %w", commentID, err)
+	}
+
+	if parentID.Valid {
+		_, err = tx.Exec(`
+			DELETE FROM Comment
+			WHERE Id = ? AND DeletedAt IS NOT NULL
+			  AND NOT EXISTS (SELECT 1 FROM (SELECT Id FROM Comment WHERE ParentCommentId = ?) r)`,
+			parentID.Int64, parentID.Int64)
+		if err != nil {
+			return fmt.Errorf("error al limpiar el comentario %d: %w", parentID.Int64, err)
+		}
+	}
+	return nil
+}
+
+// GetRootComments devuelve una página de comentarios raíz de la publicación, del más
+// reciente al más antiguo, con su número de respuestas y el total de comentarios raíz.
+func GetRootComments(eventID int64, limit, offset int) ([]models.Comment, int, error) {
+	var total int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM Comment WHERE CommunityEventId = ? AND ParentCommentId IS NULL`, eventID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error al contar los comentarios del evento %d: %w", eventID, err)
+	}
+	if total == 0 {
+		return []models.Comment{}, 0, nil
+	}
+
+	rows, err := DB.Query(`
+		SELECT `+commentColumns+`,
+		       (SELECT COUNT(*) FROM Comment r WHERE r.ParentCommentId = c.Id)
+		FROM Comment c
+		JOIN User u ON u.Id = c.AuthorId
+		WHERE c.CommunityEventId = ? AND c.ParentCommentId IS NULL
+		ORDER BY c.Id DESC
+		LIMIT ? OFFSET ?`, eventID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al obtener los comentarios del evento %d: %w", eventID, err)
+	}
+	defer rows.Close()
+
+	comments := []models.Comment{}
+	for rows.Next() {
+		var replyCount int
+		comment, err := scanComment(rows, &replyCount)
+		if err != nil {
+			return nil, 0, err
+		}
+		comment.ReplyCount = replyCount
+		comments = append(comments, comment)
+	}
+	return comments, total, rows.Err()
+}
+
+// GetReplyPreviews devuelve, para cada comentario raíz, sus primeras perRoot respuestas
+// en orden cronológico.
+func GetReplyPreviews(rootIDs []int64, perRoot int) (map[int64][]models.Comment, error) {
+	previews := make(map[int64][]models.Comment, len(rootIDs))
+	if len(rootIDs) == 0 || perRoot <= 0 {
+		return previews, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(rootIDs)), ",")
+	args := make([]any, 0, len(rootIDs)+1)
+	for _, id := range rootIDs {
+		args = append(args, id)
+	}
+	args = append(args, perRoot)
+
+	rows, err := DB.Query(`
+		SELECT `+commentColumns+`
+		FROM (
+			SELECT c.*, ROW_NUMBER() OVER (PARTITION BY c.ParentCommentId ORDER BY c.Id) AS rn
+			FROM Comment c
+			WHERE c.ParentCommentId IN (`+placeholders+`)
+		) c
+		JOIN User u ON u.Id = c.AuthorId
+		WHERE c.rn <= ?
+		ORDER BY c.ParentCommentId, c.Id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las respuestas de los comentarios: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		reply, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		previews[*reply.ParentId] = append(previews[*reply.ParentId], reply)
+	}
+	return previews, rows.Err()
+}
+
+// GetCommentReplies devuelve una página de respuestas de un comentario raíz en orden
+// cronológico y el total de respuestas.
+func GetCommentReplies(rootID int64, limit, offset int) ([]models.Comment, int, error) {
+	var total int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM Comment WHERE ParentCommentId = ?`, rootID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error al contar las respuestas del comentario %d: %w", rootID, err)
+	}
+	if total == 0 {
+		return []models.Comment{}, 0, nil
+	}
+
+	rows, err := DB.Query(`
+		SELECT `+commentColumns+`
+		FROM Comment c
+		JOIN User u ON u.Id = c.AuthorId
+		WHERE c.ParentCommentId = ?
+		ORDER BY c.Id
+		LIMIT ? OFFSET ?`, rootID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al obtener las respuestas del comentario %d: %w", rootID, err)
+	}
+	defer rows.Close()
+
+	replies := []models.Comment{}
+	for rows.Next() {
+		reply, err := scanComment(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		replies = append(replies, reply)
+	}
+	return replies, total, rows.Err()
+}
+
+// GetMaxCommentID devuelve el mayor ID de la tabla Comment (0 si está vacía).
+func GetMaxCommentID() (int64, error) {
+	var maxID sql.NullInt64
+	if err := DB.QueryRow(`SELECT MAX(Id) FROM Comment`).Scan(&maxID); err != nil {
+		return 0, err
+	}
+	return maxID.Int64, nil
+}
+
+// GetNewCommentsForEvents devuelve los comentarios con ID en (afterID, upToID] de las
+// publicaciones indicadas, en orden de creación. Lo usa el servicio WebSocket para
+// publicar los comentarios nuevos a los suscriptores.
+func GetNewCommentsForEvents(eventIDs []int64, afterID, upToID int64) ([]models.Comment, error) {
+	if len(eventIDs) == 0 {
+		return nil, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(eventIDs)), ",")
+	args := []any{afterID, upToID}
+	for _, id := range eventIDs {
+		args = append(args, id)
+	}
+
+	rows, err := DB.Query(`
+		SELECT `+commentColumns+`
+		FROM Comment c
+		JOIN User u ON u.Id = c.AuthorId
+		WHERE c.Id > ? AND c.Id <= ? AND c.DeletedAt IS NULL AND c.CommunityEventId IN (`+placeholders+`)
+		ORDER BY c.Id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los comentarios nuevos: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
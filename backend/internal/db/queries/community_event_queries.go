@@ -7,9 +7,10 @@ import (
 	"math"
 	"time"
 
-	"github.com/davidM20/micro-service-backend-go.git/internal/db"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/timefmt"
 )
 
 /*
@@ -65,9 +66,9 @@ func InsertCommunityEvent(db *sql.DB, eventData models.CommunityEventCreateReque
         INSERT INTO CommunityEvent (
             Title, Description, EventDate, Location, Capacity, Price, Tags, 
             OrganizerCompanyName, OrganizerUserId, ImageUrl, 
-            CreatedByUserId, CreatedAt, UpdatedAt
+            CreatedByUserId, CreatedAt, UpdatedAt, TenantId
         )
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, (SELECT TenantId FROM User WHERE Id = ?))
     `
 	now := time.Now()
 
@@ -113,8 +114,9 @@ func InsertCommunityEvent(db *sql.DB, eventData models.CommunityEventCreateReque
 			organizerUserID,
 			imageURL,
 			createdByUserID,
-			now, // CreatedAt
-			now, // UpdatedAt
+			now,             // CreatedAt
+			now,             // UpdatedAt
+			createdByUserID, // TenantId: la institución del autor
 		)
 	})
 
@@ -219,9 +221,9 @@ func CreateCommunityEvent(db *sql.DB, req models.CommunityEventCreateRequest, cr
             LinkPreviewDescription, LinkPreviewImage, EventDate, Location, Capacity, Price, 
             ChallengeStartDate, ChallengeEndDate, ChallengeDifficulty, ChallengePrize,
             Tags, OrganizerCompanyName, OrganizerUserId, OrganizerLogoUrl, CreatedByUserId, 
-            dmeta_title_primary, dmeta_title_secondary, CreatedAt, UpdatedAt
+            dmeta_title_primary, dmeta_title_secondary, CreatedAt, UpdatedAt, TenantId
         )
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, (SELECT TenantId FROM User WHERE Id = ?))
     `
 	now := time.Now()
 
@@ -242,7 +244,7 @@ func CreateCommunityEvent(db *sql.DB, req models.CommunityEventCreateRequest, cr
 
 	var eventDate sql.NullTime
 	if req.EventDate != nil {
-		t, err := time.Parse("2006-01-02 15:04:05", *req.EventDate)
+		t, err := timefmt.Parse(*req.EventDate)
 		if err != nil {
 			logger.Warnf("COMMUNITY_EVENT_QUERIES", "Fecha de evento inválida: %v. Se guardará como NULL.", err)
 		} else {
@@ -253,7 +255,7 @@ func CreateCommunityEvent(db *sql.DB, req models.CommunityEventCreateRequest, cr
 
 	var challengeStartDate sql.NullTime
 	if req.ChallengeStartDate != nil {
-		t, err := time.Parse("2006-01-02 15:04:05", *req.ChallengeStartDate)
+		t, err := timefmt.Parse(*req.ChallengeStartDate)
 		if err != nil {
 			logger.Warnf("COMMUNITY_EVENT_QUERIES", "Fecha de inicio de desafío inválida: %v. Se guardará como NULL.", err)
 		} else {
@@ -264,7 +266,7 @@ func CreateCommunityEvent(db *sql.DB, req models.CommunityEventCreateRequest, cr
 
 	var challengeEndDate sql.NullTime
 	if req.ChallengeEndDate != nil {
-		t, err := time.Parse("2006-01-02 15:04:05", *req.ChallengeEndDate)
+		t, err := timefmt.Parse(*req.ChallengeEndDate)
 		if err != nil {
 			logger.Warnf("COMMUNITY_EVENT_QUERIES", "Fecha de fin de desafío inválida: %v. Se guardará como NULL.", err)
 		} else {
@@ -312,6 +314,7 @@ func CreateCommunityEvent(db *sql.DB, req models.CommunityEventCreateRequest, cr
 		sKey,
 		now,
 		now,
+		createdByUserID, // TenantId: la institución del autor
 	)
 
 	if err != nil {
@@ -410,16 +413,7 @@ func GetMyCommunityEvents(db *sql.DB, userID int64, page, pageSize int) (*models
 	}
 
 	offset := (page - 1) * pageSize
-	query := `
-        SELECT 
-            ce.Id, ce.PostType, ce.Title, ce.Description, ce.ImageUrl, ce.ContentUrl, ce.LinkPreviewTitle, 
-            ce.LinkPreviewDescription, ce.LinkPreviewImage, ce.EventDate, ce.Location, ce.Capacity, ce.Price,
-            ce.ChallengeStartDate, ce.ChallengeEndDate, ce.ChallengeDifficulty, ce.ChallengePrize, ce.ChallengeStatus,
-            ce.Tags, ce.OrganizerCompanyName, ce.OrganizerUserId, ce.OrganizerLogoUrl,
-            ce.CreatedByUserId, ce.CreatedAt, ce.UpdatedAt,
-            -- Subconsulta para verificar si existen postulaciones para este evento
-            EXISTS(SELECT 1 FROM JobApplication ja WHERE ja.CommunityEventId = ce.Id) AS HasApplicants
-        FROM CommunityEvent ce
+	query := myCommunityEventsSelect + `
         WHERE ce.CreatedByUserId = ?
         ORDER BY ce.EventDate DESC
         LIMIT ? OFFSET ?`
@@ -431,6 +425,63 @@ func GetMyCommunityEvents(db *sql.DB, userID int64, page, pageSize int) (*models
 	}
 	defer rows.Close()
 
+	events, err := scanMyCommunityEvents(rows, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(totalEvents) / float64(pageSize)))
+	return &models.PaginatedCommunityEvents{
+		Data: events,
+		Pagination: models.PaginationDetails{
+			TotalItems:  totalEvents,
+			TotalPages:  totalPages,
+			CurrentPage: page,
+			PageSize:    pageSize,
+		},
+	}, nil
+}
+
+// GetMyCommunityEventsPage obtiene una página por cursor de los eventos creados por el
+// usuario, ordenados por CreatedAt DESC, Id DESC. Devuelve hasta page.FetchLimit() eventos.
+// No cuenta el total, a diferencia de GetMyCommunityEvents.
+func GetMyCommunityEventsPage(db *sql.DB, userID int64, page pagination.Params) ([]models.CommunityEvent, error) {
+	query := myCommunityEventsSelect + " WHERE ce.CreatedByUserId = ?"
+	args := []interface{}{userID}
+	if cond, condArgs := page.Where("ce.CreatedAt", "ce.Id"); cond != "" {
+		query += " AND " + cond
+		args = append(args, condArgs...)
+	}
+	query += " ORDER BY ce.CreatedAt DESC, ce.Id DESC LIMIT ?"
+	args = append(args, page.FetchLimit())
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		logger.Errorf("COMMUNITY_EVENT_QUERIES", "Error al obtener la página de eventos para el usuario %d: %v", userID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMyCommunityEvents(rows, userID)
+}
+
+// myCommunityEventsSelect son las columnas de los listados de eventos propios, incluido si el
+// evento tiene postulaciones.
+const myCommunityEventsSelect = `
+        SELECT 
+            ce.Id, ce.PostType, ce.Title, ce.Description, ce.ImageUrl, ce.ContentUrl, ce.LinkPreviewTitle, 
+            ce.LinkPreviewDescription, ce.LinkPreviewImage, ce.EventDate, ce.Location, ce.Capacity, ce.Price,
+            ce.ChallengeStartDate, ce.ChallengeEndDate, ce.ChallengeDifficulty, ce.ChallengePrize, ce.ChallengeStatus,
+            ce.Tags, ce.OrganizerCompanyName, ce.OrganizerUserId, ce.OrganizerLogoUrl,
+            ce.CreatedByUserId, ce.CreatedAt, ce.UpdatedAt,
+            -- Subconsulta para verificar si existen postulaciones para este evento
+            EXISTS(SELECT 1 FROM JobApplication ja WHERE ja.CommunityEventId = ce.Id) AS HasApplicants
+        FROM CommunityEvent ce
+`
+
+// scanMyCommunityEvents lee las filas de myCommunityEventsSelect. Las filas que no se pueden
+// leer se omiten.
+func scanMyCommunityEvents(rows *sql.Rows, userID int64) ([]models.CommunityEvent, error) {
 	var events []models.CommunityEvent
 	for rows.Next() {
 		var event models.CommunityEvent
@@ -476,26 +527,18 @@ func GetMyCommunityEvents(db *sql.DB, userID int64, page, pageSize int) (*models
 
 		events = append(events, event)
 	}
-
-	totalPages := int(math.Ceil(float64(totalEvents) / float64(pageSize)))
-	return &models.PaginatedCommunityEvents{
-		Data: events,
-		Pagination: models.PaginationDetails{
-			TotalItems:  totalEvents,
-			TotalPages:  totalPages,
-			CurrentPage: page,
-			PageSize:    pageSize,
-		},
-	}, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
 }
 
 // GetEventCreatorID obtiene el ID del usuario que creó un evento específico.
 func GetEventCreatorID(eventID int64) (int64, error) {
-	db := db.GetDB()
 	var creatorID int64
 
 	query := "SELECT CreatedByUserId FROM CommunityEvent WHERE Id = ?"
-	err := db.QueryRow(query, eventID).Scan(&creatorID)
+	err := DB.QueryRow(query, eventID).Scan(&creatorID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, fmt.Errorf("evento con ID %d no encontrado", eventID)
@@ -0,0 +1,24 @@
+package queries_test
+
+import (
+	"testing"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/testharness"
+)
+
+func TestGetEventCreatorID(t *testing.T) {
+	h := testharness.Shared(t)
+	h.LoadFixtures(t, "usuarios", "eventos")
+
+	creatorID, err := queries.GetEventCreatorID(testharness.FixtureEventID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creatorID != testharness.FixtureCompanyID {
+		t.Fatalf("creador %d, se esperaba %d", creatorID, testharness.FixtureCompanyID)
+	}
+	if _, err := queries.GetEventCreatorID(9999); err == nil {
+		t.Error("GetEventCreatorID de un evento inexistente no devolvió error")
+	}
+}
@@ -0,0 +1,45 @@
+package queries
+
+// Consultas agregadas del dashboard de empresa. Todas filtran por las publicaciones creadas
+// por la empresa (CommunityEvent.CreatedByUserId) y por la fecha de postulación.
+const (
+	// CompanyApplicationsPerPostingByDay cuenta las postulaciones por publicación y día.
+	CompanyApplicationsPerPostingByDay = `
+		SELECT ce.Id, ce.Title, ce.PostType, DATE(ja.AppliedAt) AS Day, COUNT(*) AS Total
+		FROM JobApplication ja
+		JOIN CommunityEvent ce ON ce.Id = ja.CommunityEventId
+		WHERE ce.CreatedByUserId = ? AND ja.AppliedAt >= ?
+		GROUP BY ce.Id, ce.Title, ce.PostType, Day
+		ORDER BY ce.Id, Day
+	`
+
+	// CompanyApplicationStatusCounts cuenta las postulaciones por estado actual.
+	CompanyApplicationStatusCounts = `
+		SELECT ja.Status, COUNT(*) AS Total
+		FROM JobApplication ja
+		JOIN CommunityEvent ce ON ce.Id = ja.CommunityEventId
+		WHERE ce.CreatedByUserId = ? AND ja.AppliedAt >= ?
+		GROUP BY ja.Status
+	`
+
+	// CompanyAverageTimeToHire calcula el tiempo medio (en segundos) entre la postulación y
+	// la aprobación. UpdatedAt es la fecha del último cambio de estado.
+	CompanyAverageTimeToHire = `
+		SELECT AVG(TIMESTAMPDIFF(SECOND, ja.AppliedAt, ja.UpdatedAt))
+		FROM JobApplication ja
+		JOIN CommunityEvent ce ON ce.Id = ja.CommunityEventId
+		WHERE ce.CreatedByUserId = ? AND ja.AppliedAt >= ? AND ja.Status = 'APROBADA'
+	`
+
+	// CompanyTopApplicantSkills devuelve las habilidades más declaradas por los postulantes.
+	CompanyTopApplicantSkills = `
+		SELECT MIN(TRIM(s.Skill)) AS Skill, COUNT(DISTINCT ja.ApplicantId) AS Applicants
+		FROM JobApplication ja
+		JOIN CommunityEvent ce ON ce.Id = ja.CommunityEventId
+		JOIN Skills s ON s.PersonId = ja.ApplicantId
+		WHERE ce.CreatedByUserId = ? AND ja.AppliedAt >= ? AND TRIM(s.Skill) <> ''
+		GROUP BY LOWER(TRIM(s.Skill))
+		ORDER BY Applicants DESC, Skill
+		LIMIT ?
+	`
+)
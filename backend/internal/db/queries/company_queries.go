@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/fieldcrypt"
 )
 
 // GetCompanyProfile recupera la información de perfil de una empresa por su ID.
@@ -14,13 +15,14 @@ func GetCompanyProfile(userID int64) (*models.CompanyProfile, error) {
             Id, CompanyName, Email, ContactEmail, RIF, Sector, Location, Address,
             FoundationYear, EmployeeCount, Summary, Phone, Github, Linkedin, Twitter, Facebook,
             Picture, RoleId, StatusAuthorizedId, CreatedAt, UpdatedAt
-        FROM User WHERE Id = ? AND RoleId = 3
+        FROM User WHERE Id = ? AND RoleId = ?
     `
 	var profile models.CompanyProfile
-	var contactEmail, rif, sector, location, address, summary, phone, github, linkedin, twitter, facebook, picture sql.NullString
+	var contactEmail, sector, location, address, summary, github, linkedin, twitter, facebook, picture sql.NullString
+	var rif, phone fieldcrypt.NullString
 	var foundationYear, employeeCount sql.NullInt32
 
-	err := DB.QueryRow(query, userID).Scan(
+	err := DB.QueryRow(query, userID, models.RoleBusiness).Scan(
 		&profile.Id, &profile.CompanyName, &profile.Email, &contactEmail, &rif, &sector, &location, &address,
 		&foundationYear, &employeeCount, &summary, &phone, &github, &linkedin, &twitter, &facebook,
 		&picture, &profile.RoleId, &profile.StatusAuthorizedId, &profile.CreatedAt, &profile.UpdatedAt,
@@ -134,8 +136,9 @@ func GetUserRoleByID(userID int64) (int, error) {
 // GetUserIDByRIF recupera el ID de un usuario empresa por su RIF.
 func GetUserIDByRIF(rif string) (int64, error) {
 	var userID int64
-	query := "SELECT Id FROM User WHERE RIF = ? AND RoleId = 3"
-	err := DB.QueryRow(query, rif).Scan(&userID)
+	match, args := EncryptedMatch("RIF", rif)
+	query := "SELECT Id FROM User WHERE " + match + " AND RoleId = ?"
+	err := DB.QueryRow(query, append(args, models.RoleBusiness)...).Scan(&userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, fmt.Errorf("empresa con RIF %s no encontrada", rif)
@@ -149,8 +152,8 @@ func GetUserIDByRIF(rif string) (int64, error) {
 // Dado que el nombre no es único, devuelve el primero que encuentra.
 func GetUserIDByCompanyName(companyName string) (int64, error) {
 	var userID int64
-	query := "SELECT Id FROM User WHERE CompanyName = ? AND RoleId = 3 LIMIT 1"
-	err := DB.QueryRow(query, companyName).Scan(&userID)
+	query := "SELECT Id FROM User WHERE CompanyName = ? AND RoleId = ? LIMIT 1"
+	err := DB.QueryRow(query, companyName, models.RoleBusiness).Scan(&userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, fmt.Errorf("empresa con nombre '%s' no encontrada", companyName)
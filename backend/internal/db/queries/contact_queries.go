@@ -80,6 +80,20 @@ func CheckContactExists(user1ID, user2ID int64) (bool, error) {
 	return exists, nil
 }
 
+// AreAcceptedContacts indica si dos usuarios tienen un contacto aceptado (en cualquier dirección).
+func AreAcceptedContacts(userID, otherUserID int64) (bool, error) {
+	var exists bool
+	err := DB.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM Contact
+			WHERE ((User1Id = ? AND User2Id = ?) OR (User1Id = ? AND User2Id = ?)) AND Status = 'accepted'
+		)`, userID, otherUserID, otherUserID, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error verificando el contacto entre %d y %d: %w", userID, otherUserID, err)
+	}
+	return exists, nil
+}
+
 func CreateContact(user1ID, user2ID int64, chatID string, status string) error {
 	query := "INSERT INTO Contact (User1Id, User2Id, Status, ChatId) VALUES (?, ?, ?, ?)"
 	_, err := DB.Exec(query, user1ID, user2ID, status, chatID)
@@ -87,6 +101,9 @@ func CreateContact(user1ID, user2ID int64, chatID string, status string) error {
 		logger.Errorf("QUERY", "Error al crear contacto entre %d y %d: %v", user1ID, user2ID, err)
 		return fmt.Errorf("no se pudo crear el contacto: %w", err)
 	}
+	InvalidateChatListCache(user1ID, user2ID)
+	InvalidateNetworkCache(user1ID, user2ID)
+	touchChatSyncBetween(user1ID, user2ID)
 	logger.Successf("QUERY", "Contacto creado exitosamente entre %d y %d con estado '%s'", user1ID, user2ID, status)
 	return nil
 }
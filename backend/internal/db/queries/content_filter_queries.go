@@ -0,0 +1,313 @@
+package queries
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/contentfilter"
+)
+
+const contentFilterRuleColumns = `Id, Language, MatchType, Pattern, Category, Severity, IsActive, CreatedBy, CreatedAt, UpdatedAt`
+
+func scanContentFilterRule(row rowScanner) (models.ContentFilterRule, error) {
+	var rule models.ContentFilterRule
+	var createdBy sql.NullInt64
+	err := row.Scan(&rule.Id, &rule.Language, &rule.MatchType, &rule.Pattern, &rule.Category, &rule.Severity,
+		&rule.IsActive, &createdBy, &rule.CreatedAt, &rule.UpdatedAt)
+	if createdBy.Valid {
+		rule.CreatedBy = &createdBy.Int64
+	}
+	return rule, err
+}
+
+// ListContentFilterRules devuelve una página de reglas ordenadas por idioma y patrón.
+// language y matchType vacíos no filtran.
+func ListContentFilterRules(language, matchType string, limit, offset int) ([]models.ContentFilterRule, int, error) {
+	var conditions []string
+	var args []interface{}
+	if language != "" {
+		conditions = append(conditions, "Language = ?")
+		args = append(args, language)
+	}
+	if matchType != "" {
+		conditions = append(conditions, "MatchType = ?")
+		args = append(args, matchType)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM ContentFilterRule`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error al contar las reglas del filtro: %w", err)
+	}
+
+	rows, err := DB.Query(`SELECT `+contentFilterRuleColumns+` FROM ContentFilterRule`+where+
+		` ORDER BY Language, MatchType, Pattern LIMIT ? OFFSET ?`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al obtener las reglas del filtro: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []models.ContentFilterRule{}
+	for rows.Next() {
+		rule, err := scanContentFilterRule(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error al leer una regla del filtro: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, total, rows.Err()
+}
+
+// GetContentFilterRule devuelve una regla. Si no existe devuelve sql.ErrNoRows.
+func GetContentFilterRule(ruleID int64) (*models.ContentFilterRule, error) {
+	rule, err := scanContentFilterRule(DB.QueryRow(`SELECT `+contentFilterRuleColumns+` FROM ContentFilterRule WHERE Id = ?`, ruleID))
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// InsertContentFilterRule guarda una regla y devuelve su ID.
+func InsertContentFilterRule(rule models.ContentFilterRule, createdBy int64) (int64, error) {
+	result, err := DB.Exec(`
+		INSERT INTO ContentFilterRule (Language, MatchType, Pattern, Category, Severity, IsActive, CreatedBy)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		rule.Language, rule.MatchType, rule.Pattern, rule.Category, rule.Severity, rule.IsActive, createdBy)
+	if err != nil {
+		return 0, fmt.Errorf("error al guardar la regla del filtro: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// UpdateContentFilterRule reemplaza los campos editables de una regla.
+func UpdateContentFilterRule(rule models.ContentFilterRule) error {
+	_, err := DB.Exec(`
+		UPDATE ContentFilterRule
+		SET Language = ?, MatchType = ?, Pattern = ?, Category = ?, Severity = ?, IsActive = ?
+		WHERE Id = ?`,
+		rule.Language, rule.MatchType, rule.Pattern, rule.Category, rule.Severity, rule.IsActive, rule.Id)
+	if err != nil {
+		return fmt.Errorf("error al modificar la regla %d del filtro: %w", rule.Id, err)
+	}
+	return nil
+}
+
+// DeleteContentFilterRule elimina una regla. Si no existe devuelve sql.ErrNoRows.
+func DeleteContentFilterRule(ruleID int64) error {
+	result, err := DB.Exec(`DELETE FROM ContentFilterRule WHERE Id = ?`, ruleID)
+	if err != nil {
+		return fmt.Errorf("error al eliminar la regla %d del filtro: %w", ruleID, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ImportContentFilterWords guarda una regla PALABRA por cada palabra, en una transacción.
+// Las que ya existen para el idioma se omiten. Devuelve cuántas se crearon.
+func ImportContentFilterWords(req models.ContentFilterImportRequest, createdBy int64) (int, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error iniciando la importación de palabras: %w", err)
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya fue confirmada
+
+	stmt, err := tx.Prepare(`
+		INSERT IGNORE INTO ContentFilterRule (Language, MatchType, Pattern, Category, Severity, CreatedBy)
+		VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, fmt.Errorf("error preparando la importación de palabras: %w", err)
+	}
+	defer stmt.Close()
+
+	imported := 0
+	for _, word := range req.Words {
+		result, err := stmt.Exec(req.Language, contentfilter.MatchWord, word, req.Category, req.Severity, createdBy)
+		if err != nil {
+			return 0, fmt.Errorf("error importando la palabra %q: %w", word, err)
+		}
+		if rows, err := result.RowsAffected(); err == nil {
+			imported += int(rows)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error confirmando la importación de palabras: %w", err)
+	}
+	return imported, nil
+}
+
+// GetContentFilterPolicy devuelve la política del filtro, o la política por defecto si no
+// se ha guardado ninguna.
+func GetContentFilterPolicy() (models.ContentFilterPolicy, error) {
+	var policy models.ContentFilterPolicy
+	var updatedBy sql.NullInt64
+	var updatedAt time.Time
+	err := DB.QueryRow(`
+		SELECT Enabled, LowAction, MediumAction, HighAction, MaxLinks, MaxRepeatedChars, MaxUppercaseRatio,
+		       DuplicateThreshold, DuplicateWindowSeconds, SpamSeverity, UpdatedBy, UpdatedAt
+		FROM ContentFilterPolicy WHERE Id = 1`).
+		Scan(&policy.Enabled, &policy.LowAction, &policy.MediumAction, &policy.HighAction, &policy.MaxLinks,
+			&policy.MaxRepeatedChars, &policy.MaxUppercaseRatio, &policy.DuplicateThreshold,
+			&policy.DuplicateWindowSeconds, &policy.SpamSeverity, &updatedBy, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.DefaultContentFilterPolicy(), nil
+	}
+	if err != nil {
+		return policy, fmt.Errorf("error al obtener la política del filtro: %w", err)
+	}
+	if updatedBy.Valid {
+		policy.UpdatedBy = &updatedBy.Int64
+	}
+	policy.UpdatedAt = &updatedAt
+	return policy, nil
+}
+
+// SaveContentFilterPolicy guarda la política del filtro.
+func SaveContentFilterPolicy(policy models.ContentFilterPolicy, adminID int64) error {
+	_, err := DB.Exec(`
+		INSERT INTO ContentFilterPolicy (Id, Enabled, LowAction, MediumAction, HighAction, MaxLinks, MaxRepeatedChars,
+		                                 MaxUppercaseRatio, DuplicateThreshold, DuplicateWindowSeconds, SpamSeverity, UpdatedBy)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			Enabled = VALUES(Enabled), LowAction = VALUES(LowAction), MediumAction = VALUES(MediumAction),
+			HighAction = VALUES(HighAction), MaxLinks = VALUES(MaxLinks), MaxRepeatedChars = VALUES(MaxRepeatedChars),
+			MaxUppercaseRatio = VALUES(MaxUppercaseRatio), DuplicateThreshold = VALUES(DuplicateThreshold),
+			DuplicateWindowSeconds = VALUES(DuplicateWindowSeconds), SpamSeverity = VALUES(SpamSeverity),
+			UpdatedBy = VALUES(UpdatedBy)`,
+		policy.Enabled, policy.LowAction, policy.MediumAction, policy.HighAction, policy.MaxLinks, policy.MaxRepeatedChars,
+		policy.MaxUppercaseRatio, policy.DuplicateThreshold, policy.DuplicateWindowSeconds, policy.SpamSeverity, adminID)
+	if err != nil {
+		return fmt.Errorf("error al guardar la política del filtro: %w", err)
+	}
+	return nil
+}
+
+// FilterPolicy convierte la política guardada en la del paquete contentfilter.
+func FilterPolicy(policy models.ContentFilterPolicy) contentfilter.Policy {
+	return contentfilter.Policy{
+		Enabled: policy.Enabled,
+		Actions: map[string]string{
+			contentfilter.SeverityLow:    policy.LowAction,
+			contentfilter.SeverityMedium: policy.MediumAction,
+			contentfilter.SeverityHigh:   policy.HighAction,
+		},
+		MaxLinks:           policy.MaxLinks,
+		MaxRepeatedChars:   policy.MaxRepeatedChars,
+		MaxUppercaseRatio:  policy.MaxUppercaseRatio,
+		DuplicateThreshold: policy.DuplicateThreshold,
+		DuplicateWindow:    time.Duration(policy.DuplicateWindowSeconds) * time.Second,
+		SpamSeverity:       policy.SpamSeverity,
+	}
+}
+
+// GetActiveContentFilterRules devuelve las reglas activas del filtro de todos los idiomas.
+func GetActiveContentFilterRules() ([]contentfilter.Rule, error) {
+	rows, err := DB.Query(`SELECT Id, Language, MatchType, Pattern, Severity FROM ContentFilterRule WHERE IsActive = TRUE`)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las reglas activas del filtro: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []contentfilter.Rule
+	for rows.Next() {
+		var rule contentfilter.Rule
+		if err := rows.Scan(&rule.Id, &rule.Language, &rule.MatchType, &rule.Pattern, &rule.Severity); err != nil {
+			return nil, fmt.Errorf("error al leer una regla del filtro: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// LoadContentFilter carga en f las reglas activas y la política guardadas. Las reglas
+// inválidas se omiten y se informan en el error, pero el resto queda cargado.
+func LoadContentFilter(f *contentfilter.Filter) error {
+	policy, err := GetContentFilterPolicy()
+	if err != nil {
+		return err
+	}
+	rules, err := GetActiveContentFilterRules()
+	if err != nil {
+		return err
+	}
+	return f.Load(rules, FilterPolicy(policy))
+}
+
+// InsertContentFilterHit registra un mensaje marcado, ocultado o bloqueado por el filtro.
+func InsertContentFilterHit(hit models.ContentFilterHit) error {
+	_, err := DB.Exec(`
+		INSERT INTO ContentFilterHit (MessageId, SenderId, ChatId, ChatIdGroup, Content, Action, Severity, Hits)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		hit.MessageId, hit.SenderId, hit.ChatId, hit.ChatIdGroup, hit.Content, hit.Action, hit.Severity, []byte(hit.Hits))
+	if err != nil {
+		return fmt.Errorf("error al registrar la coincidencia del filtro del usuario %d: %w", hit.SenderId, err)
+	}
+	return nil
+}
+
+// ListContentFilterHits devuelve una página del registro del filtro, de la más reciente a la
+// más antigua. action vacía y senderID 0 no filtran.
+func ListContentFilterHits(action string, senderID int64, limit, offset int) ([]models.ContentFilterHit, int, error) {
+	var conditions []string
+	var args []interface{}
+	if action != "" {
+		conditions = append(conditions, "h.Action = ?")
+		args = append(args, action)
+	}
+	if senderID > 0 {
+		conditions = append(conditions, "h.SenderId = ?")
+		args = append(args, senderID)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM ContentFilterHit h`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error al contar el registro del filtro: %w", err)
+	}
+
+	rows, err := DB.Query(`
+		SELECT h.Id, h.MessageId, h.SenderId, `+fmt.Sprintf(reportDisplayName, "u")+`, h.ChatId, h.ChatIdGroup,
+		       COALESCE(h.Content, ''), h.Action, h.Severity, h.Hits, h.CreatedAt
+		FROM ContentFilterHit h
+		JOIN User u ON u.Id = h.SenderId`+where+`
+		ORDER BY h.Id DESC LIMIT ? OFFSET ?`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al obtener el registro del filtro: %w", err)
+	}
+	defer rows.Close()
+
+	hits := []models.ContentFilterHit{}
+	for rows.Next() {
+		var hit models.ContentFilterHit
+		var messageID, chatID, chatIDGroup sql.NullString
+		var matches []byte
+		if err := rows.Scan(&hit.Id, &messageID, &hit.SenderId, &hit.SenderName, &chatID, &chatIDGroup,
+			&hit.Content, &hit.Action, &hit.Severity, &matches, &hit.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("error al leer el registro del filtro: %w", err)
+		}
+		if messageID.Valid {
+			hit.MessageId = &messageID.String
+		}
+		if chatID.Valid {
+			hit.ChatId = &chatID.String
+		}
+		if chatIDGroup.Valid {
+			hit.ChatIdGroup = &chatIDGroup.String
+		}
+		hit.Hits = matches
+		hits = append(hits, hit)
+	}
+	return hits, total, rows.Err()
+}
@@ -2,7 +2,9 @@ package queries
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
@@ -58,15 +60,34 @@ NORMAS Y DIRECTRICES PARA ESTE ARCHIVO:
 
 // SetSkill agrega o actualiza una habilidad en el CV del usuario
 func SetSkill(db *sql.DB, skill *models.Skills) error {
+	// Si el usuario ya tiene la misma habilidad canónica ("JS" y "JavaScript") se actualiza esa
+	// fila en lugar de crear otra.
+	if skill.SkillCatalogId != nil {
+		var existingID int64
+		err := db.QueryRow(`SELECT Id FROM Skills WHERE PersonId = ? AND SkillCatalogId = ? ORDER BY Id LIMIT 1`,
+			skill.PersonId, *skill.SkillCatalogId).Scan(&existingID)
+		switch {
+		case err == nil:
+			if _, err := db.Exec(`UPDATE Skills SET Skill = ?, Level = ? WHERE Id = ?`, skill.Skill, skill.Level, existingID); err != nil {
+				return fmt.Errorf("error al establecer habilidad: %w", err)
+			}
+			skill.Id = existingID
+			return nil
+		case !errors.Is(err, sql.ErrNoRows):
+			return fmt.Errorf("error al buscar la habilidad: %w", err)
+		}
+	}
+
 	query := `
-		INSERT INTO Skills (PersonId, Skill, Level)
-		VALUES (?, ?, ?)
+		INSERT INTO Skills (PersonId, Skill, Level, SkillCatalogId)
+		VALUES (?, ?, ?, ?)
 		ON DUPLICATE KEY UPDATE
 		Skill = VALUES(Skill),
-		Level = VALUES(Level)
+		Level = VALUES(Level),
+		SkillCatalogId = VALUES(SkillCatalogId)
 	`
 
-	_, err := db.Exec(query, skill.PersonId, skill.Skill, skill.Level)
+	_, err := db.Exec(query, skill.PersonId, skill.Skill, skill.Level, skill.SkillCatalogId)
 	if err != nil {
 		return fmt.Errorf("error al establecer habilidad: %w", err)
 	}
@@ -422,3 +443,73 @@ func GetCV(db *sql.DB, personId int64) (*wsmodels.CurriculumVitae, error) {
 
 	return cv, nil
 }
+
+// ImportCVSections inserta en una única transacción los items de CV importados para una persona.
+// Las fechas deben venir en formato YYYY-MM-DD; el llamador es responsable de validarlas y de
+// descartar duplicados antes de invocar esta función. Si alguna inserción falla no se guarda nada.
+func ImportCVSections(personID int64, items models.CVImportSections) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción de importación de CV: %w", err)
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya fue confirmada
+
+	for _, e := range items.Education {
+		if _, err := tx.Exec(
+			`INSERT INTO Education (PersonId, Institution, Degree, Campus, GraduationDate, IsCurrentlyStudying) VALUES (?, ?, ?, ?, ?, ?)`,
+			personID, e.Institution, e.Degree, importNullString(e.Campus), importNullDate(e.GraduationDate), e.IsCurrentlyStudying,
+		); err != nil {
+			return fmt.Errorf("error importando educación '%s': %w", e.Institution, err)
+		}
+	}
+
+	for _, w := range items.WorkExperience {
+		if _, err := tx.Exec(
+			`INSERT INTO WorkExperience (PersonId, Company, Position, StartDate, EndDate, Description, IsCurrentJob) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			personID, w.Company, w.Position, importNullDate(w.StartDate), importNullDate(w.EndDate), importNullString(w.Description), w.IsCurrentJob,
+		); err != nil {
+			return fmt.Errorf("error importando experiencia laboral '%s': %w", w.Company, err)
+		}
+	}
+
+	for _, s := range items.Skills {
+		if _, err := tx.Exec(`INSERT INTO Skills (PersonId, Skill, Level, SkillCatalogId) VALUES (?, ?, ?, ?)`, personID, s.Skill, s.Level, s.SkillCatalogId); err != nil {
+			return fmt.Errorf("error importando habilidad '%s': %w", s.Skill, err)
+		}
+	}
+
+	for _, l := range items.Languages {
+		if _, err := tx.Exec(`INSERT INTO Languages (PersonId, Language, Level) VALUES (?, ?, ?)`, personID, l.Language, l.Level); err != nil {
+			return fmt.Errorf("error importando idioma '%s': %w", l.Language, err)
+		}
+	}
+
+	for _, p := range items.Projects {
+		if _, err := tx.Exec(
+			`INSERT INTO Project (PersonID, Title, Role, Description, Company, ProjectStatus, StartDate, ExpectedEndDate, IsOngoing) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			personID, p.Title, importNullString(p.Role), importNullString(p.Description), importNullString(p.Company),
+			importNullString(p.Status), importNullDate(p.StartDate), importNullDate(p.EndDate), p.IsOngoing,
+		); err != nil {
+			return fmt.Errorf("error importando proyecto '%s': %w", p.Title, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando la importación de CV: %w", err)
+	}
+	return nil
+}
+
+// importNullString convierte una cadena vacía en NULL.
+func importNullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// importNullDate convierte una fecha YYYY-MM-DD en sql.NullTime; vacía o inválida se guarda como NULL.
+func importNullDate(s string) sql.NullTime {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t, Valid: true}
+}
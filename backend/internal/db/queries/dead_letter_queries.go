@@ -0,0 +1,80 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// maxDeadLetterErrorLength es el tamaño de la columna DeadLetter.LastError.
+const maxDeadLetterErrorLength = 255
+
+// SaveDeadLetter guarda un mensaje no entregado. Si ya estaba guardado (un reenvío al
+// conectarse que tampoco se confirmó) suma los intentos y actualiza el último error.
+func SaveDeadLetter(letter models.DeadLetter) error {
+	var payload interface{}
+	if len(letter.Payload) > 0 {
+		payload = []byte(letter.Payload)
+	}
+	lastError := sql.NullString{String: letter.LastError, Valid: letter.LastError != ""}
+	if len(lastError.String) > maxDeadLetterErrorLength {
+		lastError.String = lastError.String[:maxDeadLetterErrorLength]
+	}
+	_, err := DB.Exec(`
+		INSERT INTO DeadLetter (UserId, Pid, Type, FromUserId, Payload, Attempts, LastError)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE Attempts = Attempts + VALUES(Attempts), LastError = VALUES(LastError)`,
+		letter.UserId, letter.Pid, letter.Type, sql.NullInt64{Int64: letter.FromUserId, Valid: letter.FromUserId != 0}, payload, letter.Attempts, lastError)
+	if err != nil {
+		return fmt.Errorf("error al guardar el mensaje %s no entregado al usuario %d: %w", letter.Pid, letter.UserId, err)
+	}
+	return nil
+}
+
+// GetDeadLetters devuelve hasta limit mensajes no entregados del usuario, del más antiguo al
+// más reciente.
+func GetDeadLetters(userID int64, limit int) ([]models.DeadLetter, error) {
+	rows, err := DB.Query(`
+		SELECT Id, UserId, Pid, Type, FromUserId, Payload, Attempts, LastError, CreatedAt
+		FROM DeadLetter WHERE UserId = ? ORDER BY Id LIMIT ?`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer los mensajes no entregados del usuario %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	var letters []models.DeadLetter
+	for rows.Next() {
+		var letter models.DeadLetter
+		var payload []byte
+		var fromUserID sql.NullInt64
+		var lastError sql.NullString
+		if err := rows.Scan(&letter.Id, &letter.UserId, &letter.Pid, &letter.Type, &fromUserID, &payload, &letter.Attempts, &lastError, &letter.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error al leer un mensaje no entregado: %w", err)
+		}
+		letter.FromUserId = fromUserID.Int64
+		letter.Payload = payload
+		letter.LastError = lastError.String
+		letters = append(letters, letter)
+	}
+	return letters, rows.Err()
+}
+
+// DeleteDeadLetter borra un mensaje no entregado una vez confirmado.
+func DeleteDeadLetter(id int64) error {
+	if _, err := DB.Exec(`DELETE FROM DeadLetter WHERE Id = ?`, id); err != nil {
+		return fmt.Errorf("error al borrar el mensaje no entregado %d: %w", id, err)
+	}
+	return nil
+}
+
+// DeleteOldDeadLetters borra los mensajes no entregados con más de retention. Devuelve
+// cuántos borró.
+func DeleteOldDeadLetters(retention time.Duration) (int64, error) {
+	result, err := DB.Exec(`DELETE FROM DeadLetter WHERE CreatedAt < NOW() - INTERVAL ? SECOND`, int64(retention.Seconds()))
+	if err != nil {
+		return 0, fmt.Errorf("error al limpiar los mensajes no entregados: %w", err)
+	}
+	return result.RowsAffected()
+}
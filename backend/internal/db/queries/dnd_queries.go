@@ -0,0 +1,85 @@
+package queries
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// MarkDndSuppressed registra que se silenció un aviso al usuario por su horario de No
+// molestar. Solo guarda el primero: la fecha marca desde cuándo hay que resumir. No cambia
+// UpdatedAt, que es la fecha de la última modificación de las preferencias.
+func MarkDndSuppressed(userID int64, at time.Time) error {
+	_, err := DB.Exec(`
+		UPDATE NotificationPreferences
+		SET DndSuppressedAt = COALESCE(DndSuppressedAt, ?), UpdatedAt = UpdatedAt
+		WHERE UserId = ?`, at.UTC().Truncate(time.Second), userID)
+	if err != nil {
+		return fmt.Errorf("error al registrar el aviso silenciado del usuario %d: %w", userID, err)
+	}
+	return nil
+}
+
+// DndPendingSummary es un usuario con avisos silenciados pendientes de resumir.
+type DndPendingSummary struct {
+	UserId       int64
+	SuppressedAt time.Time
+}
+
+// GetDndPendingSummaries devuelve los usuarios con avisos silenciados por No molestar que
+// aún no recibieron el resumen.
+func GetDndPendingSummaries(limit int) ([]DndPendingSummary, error) {
+	rows, err := DB.Query(`
+		SELECT UserId, DndSuppressedAt FROM NotificationPreferences
+		WHERE DndSuppressedAt IS NOT NULL
+		ORDER BY DndSuppressedAt
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los resúmenes de No molestar pendientes: %w", err)
+	}
+	defer rows.Close()
+
+	var pending []DndPendingSummary
+	for rows.Next() {
+		var p DndPendingSummary
+		if err := rows.Scan(&p.UserId, &p.SuppressedAt); err != nil {
+			return nil, err
+		}
+		pending = append(pending, p)
+	}
+	return pending, rows.Err()
+}
+
+// ClaimDndSummary marca como enviado el resumen de los avisos silenciados desde
+// suppressedAt. Devuelve false si otra instancia ya lo reclamó.
+func ClaimDndSummary(userID int64, suppressedAt time.Time) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE NotificationPreferences
+		SET DndSuppressedAt = NULL, UpdatedAt = UpdatedAt
+		WHERE UserId = ? AND DndSuppressedAt = ?`, userID, suppressedAt)
+	if err != nil {
+		return false, fmt.Errorf("error al reclamar el resumen de No molestar del usuario %d: %w", userID, err)
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// GetDndSummary cuenta las notificaciones y los mensajes de chats privados que el usuario
+// recibió desde since y sigue sin leer.
+func GetDndSummary(userID int64, since time.Time) (models.DndSummary, error) {
+	summary := models.DndSummary{Since: since, Until: time.Now().UTC()}
+	err := DB.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM Event e WHERE e.UserId = ? AND e.IsRead = FALSE AND e.CreateAt >= ?),
+			(SELECT COUNT(*) FROM Message m
+				JOIN Contact c ON c.ChatId = m.ChatId
+				WHERE (c.User1Id = ? OR c.User2Id = ?) AND m.SenderId <> ?
+					AND m.Status <> 'read' AND m.SentAt >= ?
+					AND NOT EXISTS (`+shadowDeletedMessage+`))`,
+		userID, since, userID, userID, userID, since).Scan(&summary.Notifications, &summary.Messages)
+	if err != nil {
+		return summary, fmt.Errorf("error al obtener el resumen de No molestar del usuario %d: %w", userID, err)
+	}
+	return summary, nil
+}
@@ -0,0 +1,180 @@
+package queries
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// Condiciones de los elementos pendientes de resumir. Usan el alias u para el destinatario y
+// reciben como parámetro la fecha hasta la que un elemento sin leer entra en el resumen.
+const (
+	digestPendingEvents = `
+		SELECT 1 FROM Event e
+		WHERE e.UserId = u.Id AND e.IsRead = FALSE AND e.CreateAt <= ?
+			AND NOT EXISTS (SELECT 1 FROM EmailDigestItem d
+				WHERE d.UserId = u.Id AND d.ItemType = 'EVENT' AND d.ItemId = CAST(e.Id AS CHAR))`
+	digestPendingMessages = `
+		SELECT 1 FROM Message m
+		JOIN Contact c ON c.ChatId = m.ChatId
+		WHERE (c.User1Id = u.Id OR c.User2Id = u.Id) AND m.SenderId <> u.Id
+			AND m.Status <> 'read' AND m.SentAt <= ?
+			AND NOT EXISTS (` + shadowDeletedMessage + `)
+			AND NOT EXISTS (SELECT 1 FROM EmailDigestItem d
+				WHERE d.UserId = u.Id AND d.ItemType = 'MESSAGE' AND d.ItemId = m.Id)`
+)
+
+// GetEmailDigestRecipients devuelve los usuarios que deben recibir un resumen: tienen
+// notificaciones o mensajes sin leer desde antes de unreadBefore que aún no se resumieron,
+// no están conectados, no desactivaron el resumen y no recibieron otro desde unreadBefore.
+// Se excluyen las cuentas suspendidas, las desactivadas y las eliminadas.
+func GetEmailDigestRecipients(unreadBefore time.Time, limit int) ([]int64, error) {
+	rows, err := DB.Query(`
+		SELECT u.Id
+		FROM User u
+		LEFT JOIN NotificationPreferences np ON np.UserId = u.Id
+		LEFT JOIN Online o ON o.UserOnlineId = u.Id
+		WHERE COALESCE(np.EmailDigest, TRUE)
+			AND COALESCE(o.Status, 0) = 0
+			AND COALESCE(u.StatusAuthorizedId, 0) NOT IN (?, ?)
+			AND u.Email NOT LIKE '%@deleted.invalid'
+			AND NOT EXISTS (SELECT 1 FROM EmailDigestItem d WHERE d.UserId = u.Id AND d.DigestedAt > ?)
+			AND (EXISTS (`+digestPendingEvents+`) OR EXISTS (`+digestPendingMessages+`))
+		ORDER BY u.Id
+		LIMIT ?`,
+		models.UserStatusSuspended, models.UserStatusDeactivated, unreadBefore, unreadBefore, unreadBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los destinatarios del resumen por correo: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+// GetDigestEvents devuelve las notificaciones sin leer del usuario creadas hasta
+// unreadBefore que aún no se resumieron, de la más reciente a la más antigua.
+func GetDigestEvents(userID int64, unreadBefore time.Time, limit int) ([]models.DigestEvent, error) {
+	rows, err := DB.Query(`
+		SELECT e.Id, e.EventType, e.EventTitle, COALESCE(e.Description, ''), e.CreateAt
+		FROM Event e
+		WHERE e.UserId = ? AND e.IsRead = FALSE AND e.CreateAt <= ?
+			AND NOT EXISTS (SELECT 1 FROM EmailDigestItem d
+				WHERE d.UserId = e.UserId AND d.ItemType = 'EVENT' AND d.ItemId = CAST(e.Id AS CHAR))
+		ORDER BY e.CreateAt DESC, e.Id DESC
+		LIMIT ?`, userID, unreadBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las notificaciones a resumir del usuario %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	var events []models.DigestEvent
+	for rows.Next() {
+		var event models.DigestEvent
+		if err := rows.Scan(&event.Id, &event.EventType, &event.Title, &event.Description, &event.CreateAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// GetDigestMessages devuelve los mensajes de chats privados que el usuario no ha leído,
+// enviados hasta unreadBefore y que aún no se resumieron, del más reciente al más antiguo.
+func GetDigestMessages(userID int64, unreadBefore time.Time, limit int) ([]models.DigestMessage, error) {
+	rows, err := DB.Query(`
+		SELECT m.Id, m.ChatId, m.SenderId, `+fmt.Sprintf(reportDisplayName, "s")+`,
+			COALESCE(m.Content, ''), m.MediaId IS NOT NULL, m.SentAt
+		FROM Message m
+		JOIN Contact c ON c.ChatId = m.ChatId
+		JOIN User s ON s.Id = m.SenderId
+		WHERE (c.User1Id = ? OR c.User2Id = ?) AND m.SenderId <> ?
+			AND m.Status <> 'read' AND m.SentAt <= ?
+			AND NOT EXISTS (`+shadowDeletedMessage+`)
+			AND NOT EXISTS (SELECT 1 FROM EmailDigestItem d
+				WHERE d.UserId = ? AND d.ItemType = 'MESSAGE' AND d.ItemId = m.Id)
+		ORDER BY m.SentAt DESC, m.Id DESC
+		LIMIT ?`, userID, userID, userID, unreadBefore, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los mensajes a resumir del usuario %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	var messages []models.DigestMessage
+	for rows.Next() {
+		var message models.DigestMessage
+		if err := rows.Scan(&message.Id, &message.ChatId, &message.SenderId, &message.SenderName,
+			&message.Content, &message.HasMedia, &message.SentAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+// ClaimDigestItems registra los elementos como resumidos antes de enviar el correo. Devuelve
+// false si otra instancia ya había reclamado alguno, en cuyo caso no se registra nada y no se
+// debe enviar.
+func ClaimDigestItems(userID int64, items []models.DigestItemKey) (bool, error) {
+	if len(items) == 0 {
+		return false, nil
+	}
+	args := make([]interface{}, 0, len(items)*3)
+	for _, item := range items {
+		args = append(args, userID, item.Type, item.Id)
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("error iniciando transacción del resumen del usuario %d: %w", userID, err)
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya fue confirmada
+
+	result, err := tx.Exec(`INSERT IGNORE INTO EmailDigestItem (UserId, ItemType, ItemId) VALUES (?, ?, ?)`+
+		strings.Repeat(", (?, ?, ?)", len(items)-1), args...)
+	if err != nil {
+		return false, fmt.Errorf("error al registrar el resumen del usuario %d: %w", userID, err)
+	}
+	if inserted, err := result.RowsAffected(); err != nil || inserted < int64(len(items)) {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("error confirmando el resumen del usuario %d: %w", userID, err)
+	}
+	return true, nil
+}
+
+// ReleaseDigestItems deshace ClaimDigestItems cuando el correo no se pudo enviar, para que
+// los elementos entren en el siguiente resumen.
+func ReleaseDigestItems(userID int64, items []models.DigestItemKey) error {
+	if len(items) == 0 {
+		return nil
+	}
+	conditions := make([]string, 0, len(items))
+	args := []interface{}{userID}
+	for _, item := range items {
+		conditions = append(conditions, "(ItemType = ? AND ItemId = ?)")
+		args = append(args, item.Type, item.Id)
+	}
+	if _, err := DB.Exec(`DELETE FROM EmailDigestItem WHERE UserId = ? AND (`+strings.Join(conditions, " OR ")+`)`, args...); err != nil {
+		return fmt.Errorf("error al liberar el resumen del usuario %d: %w", userID, err)
+	}
+	return nil
+}
+
+// GetDigestRecipient devuelve el correo y el nombre para mostrar del destinatario de un
+// resumen.
+func GetDigestRecipient(userID int64) (email, name string, err error) {
+	err = DB.QueryRow(`SELECT u.Email, `+fmt.Sprintf(reportDisplayName, "u")+` FROM User u WHERE u.Id = ?`, userID).
+		Scan(&email, &name)
+	return email, name, err
+}
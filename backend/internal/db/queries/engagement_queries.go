@@ -0,0 +1,99 @@
+package queries
+
+import (
+	"fmt"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// SetPostLike marca o desmarca el "me gusta" del usuario en la publicación. Devuelve true
+// si el estado cambió (marcar dos veces no es un error, pero no cuenta como cambio).
+func SetPostLike(userID, eventID int64, liked bool) (bool, error) {
+	return setPostFlag("CommunityEventLike", userID, eventID, liked)
+}
+
+// SetPostBookmark guarda o quita la publicación de los guardados del usuario. Devuelve
+// true si el estado cambió.
+func SetPostBookmark(userID, eventID int64, saved bool) (bool, error) {
+	return setPostFlag("CommunityEventBookmark", userID, eventID, saved)
+}
+
+// setPostFlag inserta o borra la fila (UserId, CommunityEventId) de table, que es siempre
+// una de las dos tablas fijas de arriba.
+func setPostFlag(table string, userID, eventID int64, set bool) (bool, error) {
+	query := `INSERT IGNORE INTO ` + table + ` (UserId, CommunityEventId) VALUES (?, ?)`
+	if !set {
+		query = `DELETE FROM ` + table + ` WHERE UserId = ? AND CommunityEventId = ?`
+	}
+	result, err := DB.Exec(query, userID, eventID)
+	if err != nil {
+		return false, fmt.Errorf("error al actualizar %s del usuario %d en la publicación %d: %w", table, userID, eventID, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// GetPostEngagement devuelve los contadores de la publicación y si el usuario le dio
+// "me gusta" o la guardó.
+func GetPostEngagement(userID, eventID int64) (models.PostEngagement, error) {
+	engagement := models.PostEngagement{CommunityEventId: eventID}
+	err := DB.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM CommunityEventLike WHERE CommunityEventId = ?),
+			(SELECT COUNT(*) FROM Comment WHERE CommunityEventId = ? AND DeletedAt IS NULL),
+			EXISTS (SELECT 1 FROM CommunityEventLike WHERE CommunityEventId = ? AND UserId = ?),
+			EXISTS (SELECT 1 FROM CommunityEventBookmark WHERE CommunityEventId = ? AND UserId = ?)`,
+		eventID, eventID, eventID, userID, eventID, userID).
+		Scan(&engagement.LikeCount, &engagement.CommentCount, &engagement.LikedByMe, &engagement.BookmarkedByMe)
+	if err != nil {
+		return engagement, fmt.Errorf("error al obtener los contadores de la publicación %d: %w", eventID, err)
+	}
+	return engagement, nil
+}
+
+// GetSavedItems devuelve una página de las publicaciones guardadas por el usuario, de la
+// guardada más recientemente a la más antigua, y el total de guardadas.
+func GetSavedItems(userID int64, limit, offset int) ([]models.SavedItem, int, error) {
+	var total int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM CommunityEventBookmark WHERE UserId = ?`, userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error al contar los guardados del usuario %d: %w", userID, err)
+	}
+	if total == 0 {
+		return []models.SavedItem{}, 0, nil
+	}
+
+	rows, err := DB.Query(`
+		SELECT ce.Id, ce.PostType, ce.Title, COALESCE(ce.Description, ''), COALESCE(ce.ImageUrl, ''),
+		       ce.CreatedByUserId,
+		       COALESCE(NULLIF(u.CompanyName, ''), NULLIF(TRIM(CONCAT_WS(' ', u.FirstName, u.LastName)), ''), ce.OrganizerCompanyName, ''),
+		       COALESCE(u.Picture, ce.OrganizerLogoUrl, ''),
+		       (SELECT COUNT(*) FROM CommunityEventLike l WHERE l.CommunityEventId = ce.Id),
+		       (SELECT COUNT(*) FROM Comment c WHERE c.CommunityEventId = ce.Id AND c.DeletedAt IS NULL),
+		       EXISTS (SELECT 1 FROM CommunityEventLike l WHERE l.CommunityEventId = ce.Id AND l.UserId = b.UserId),
+		       ce.CreatedAt, b.CreatedAt
+		FROM CommunityEventBookmark b
+		JOIN CommunityEvent ce ON ce.Id = b.CommunityEventId
+		LEFT JOIN User u ON u.Id = ce.CreatedByUserId
+		WHERE b.UserId = ?
+		ORDER BY b.CreatedAt DESC, ce.Id DESC
+		LIMIT ? OFFSET ?`, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al obtener los guardados del usuario %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	items := []models.SavedItem{}
+	for rows.Next() {
+		var item models.SavedItem
+		if err := rows.Scan(&item.CommunityEventId, &item.PostType, &item.Title, &item.Description, &item.ImageUrl,
+			&item.AuthorId, &item.AuthorName, &item.AuthorPicture, &item.LikeCount, &item.CommentCount, &item.LikedByMe,
+			&item.CreatedAt, &item.SavedAt); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+	return items, total, rows.Err()
+}
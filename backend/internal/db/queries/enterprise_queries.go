@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/fieldcrypt"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 )
 
@@ -74,9 +75,10 @@ func CheckEmailExists(db *sql.DB, email string) (bool, error) {
 // CheckRIFExists verifica si ya existe una empresa con el RIF proporcionado
 func CheckRIFExists(db *sql.DB, rif string) (bool, error) {
 	var exists bool
-	query := "SELECT EXISTS(SELECT 1 FROM User WHERE RIF = ?)"
+	match, args := EncryptedMatch("RIF", rif)
+	query := "SELECT EXISTS(SELECT 1 FROM User WHERE " + match + ")"
 
-	err := db.QueryRow(query, rif).Scan(&exists)
+	err := db.QueryRow(query, args...).Scan(&exists)
 	if err != nil {
 		logger.Errorf("ENTERPRISE_QUERY", "Error checking RIF existence: %v", err)
 		return false, fmt.Errorf("error verificando RIF: %w", err)
@@ -87,25 +89,26 @@ func CheckRIFExists(db *sql.DB, rif string) (bool, error) {
 
 // RegisterEnterprise registra una nueva empresa en la tabla User
 func RegisterEnterprise(db *sql.DB, enterprise *models.EnterpriseRegistration) (int64, error) {
-	// Constantes para roles y estados
-	const enterpriseRoleId = 9 // Rol para empresas
-	const defaultStatusId = 1  // Estado por defecto
+	// La empresa queda pendiente hasta que un administrador la apruebe
+	enterpriseRoleId := models.RoleBusiness
+	defaultStatusId := models.UserStatusCompanyPending
 
 	query := `
 		INSERT INTO User (
-			CompanyName, RIF, Sector, FirstName, Email, Phone, Password,
+			CompanyName, RIF, RIFHash, Sector, FirstName, Email, Phone, Password,
 			Location, RoleId, StatusAuthorizedId
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := db.Exec(
 		query,
 		enterprise.CompanyName,
-		enterprise.RIF,
+		fieldcrypt.Value(enterprise.RIF),
+		fieldcrypt.Index(FieldRIF, enterprise.RIF),
 		enterprise.Sector,
 		enterprise.FirstName,
 		enterprise.Email,
-		enterprise.Phone,
+		fieldcrypt.Value(enterprise.Phone),
 		enterprise.Password, // Debería recibir el hash, no la contraseña en texto plano
 		enterprise.Location,
 		enterpriseRoleId,
@@ -158,7 +161,7 @@ func UpdateEnterpriseProfile(db *sql.DB, userID int64, data *models.EnterprisePr
 		addField("Facebook", *data.Facebook)
 	}
 	if data.Phone != nil {
-		addField("Phone", *data.Phone)
+		addField("Phone", fieldcrypt.Value(*data.Phone))
 	}
 	if data.Picture != nil {
 		addField("Picture", *data.Picture)
@@ -207,6 +210,7 @@ func UpdateEnterpriseProfile(db *sql.DB, userID int64, data *models.EnterprisePr
 		return fmt.Errorf("error executing update for user %d: %w", userID, err)
 	}
 
+	InvalidateUserCache(userID)
 	return nil
 }
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
 )
 
 /*
@@ -75,30 +76,93 @@ NORMAS Y DIRECTRICES PARA ESTE ARCHIVO:
  * - Adaptar los datos al formato wsmodels.FeedItem.
  */
 
-func GetUnifiedFeed(db *sql.DB, userID int64, limit int, offset int) ([]wsmodels.FeedItem, int, error) {
+func GetUnifiedFeed(db *sql.DB, userID, tenantID int64, limit int, offset int) ([]wsmodels.FeedItem, int, error) {
 	// Primero, obtenemos el recuento total para la paginación, incluyendo todos los tipos de items.
 	countQuery := `
     SELECT COUNT(*) FROM (
         (
             SELECT ce.Id FROM CommunityEvent ce
+            WHERE NOT EXISTS (SELECT 1 FROM HiddenContent h WHERE h.TargetType = 'POST' AND h.TargetId = CAST(ce.Id AS CHAR))
+                AND ` + NotBlockedCondition("ce.CreatedByUserId") + `
+                AND ` + TenantCondition("ce.TenantId", tenantID) + `
         )
         UNION ALL
         (
             SELECT u.Id FROM User u
-            WHERE u.StatusAuthorizedId = 1 AND u.RoleId IN (?, ?, ?) -- 1:estudiante, 2:egresado, 3:empresa
+            WHERE ` + activeStatusIs("u.StatusAuthorizedId") + ` AND ` + memberRoleIn("u.RoleId") + `
+                AND ` + NotBlockedCondition("u.Id") + `
+                AND ` + TenantCondition("u.TenantId", tenantID) + `
         )
     ) as feed_items;
     `
 	var totalItems int
-	// Los argumentos aquí (1, 2, 3) corresponden a los RoleId para estudiantes, egresados y empresas.
-	err := db.QueryRow(countQuery, 1, 2, 3).Scan(&totalItems)
+	err := db.QueryRow(countQuery, userID, userID, userID, userID).Scan(&totalItems)
 	if err != nil {
 		logger.Errorf("GetUnifiedFeed", "Error al contar los items del feed: %v", err)
 		return nil, 0, err
 	}
 
 	// Consulta principal para obtener los datos de la página actual.
-	query := `
+	query := unifiedFeedSources(tenantID) + `
+    -- Final Ordering and Pagination, applied to the whole UNION result.
+    ORDER BY relevance_score DESC, created_at DESC, item_id DESC
+    LIMIT ? OFFSET ?;
+    `
+
+	logger.Debugf("GetUnifiedFeed", "Ejecutando consulta unificada de feed para UserID %d con Limit: %d, Offset: %d", userID, limit, offset)
+
+	// Ejecuta la consulta.
+	args := append(unifiedFeedArgs(userID), limit, offset)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		logger.Errorf("GetUnifiedFeed", "Error al ejecutar la consulta de feed unificado para UserID %d: %v", userID, err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	feedItems, err := scanFeedItems(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	logger.Successf("GetUnifiedFeed", "Procesados %d items del feed unificado para el usuario %d", len(feedItems), userID)
+	return feedItems, totalItems, nil
+}
+
+// GetUnifiedFeedPage obtiene una página del feed por cursor. A diferencia de GetUnifiedFeed,
+// ordena por fecha (created_at DESC, item_type DESC, item_id DESC) y no por relevance_score:
+// la puntuación depende de NOW() y de los items que el usuario ya vio, así que cambia entre
+// una página y la siguiente y un cursor sobre ella repetiría o saltaría items. item_type
+// desempata entre eventos y perfiles con el mismo Id. Devuelve hasta page.FetchLimit() items.
+func GetUnifiedFeedPage(db *sql.DB, userID, tenantID int64, page pagination.Params) ([]wsmodels.FeedItem, error) {
+	query := "SELECT * FROM (" + unifiedFeedSources(tenantID) + ") AS feed"
+	args := unifiedFeedArgs(userID)
+	if page.After != nil {
+		cond, condArgs := pagination.Before(
+			[]string{"created_at", "item_type", "item_id"},
+			[]interface{}{page.After.CreatedAt, page.After.Kind, page.After.ID},
+		)
+		query += " WHERE " + cond
+		args = append(args, condArgs...)
+	}
+	query += " ORDER BY created_at DESC, item_type DESC, item_id DESC LIMIT ?"
+	args = append(args, page.FetchLimit())
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		logger.Errorf("GetUnifiedFeedPage", "Error al ejecutar la consulta de feed para UserID %d: %v", userID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanFeedItems(rows)
+}
+
+// unifiedFeedSources es la unión de las fuentes del feed (eventos de la comunidad y perfiles),
+// con las mismas columnas en ambas, limitada a la institución tenantID y a las filas sin
+// institución (0 no limita). Sus argumentos los da unifiedFeedArgs.
+func unifiedFeedSources(tenantID int64) string {
+	return `
     (
         -- Source 1: Community Events (Events, Challenges, Articles, etc.)
         SELECT
@@ -119,24 +183,33 @@ func GetUnifiedFeed(db *sql.DB, userID int64, limit int, offset int) ([]wsmodels
             NULL as user_sector,
             NULL as user_username,
 			NULL as has_contact,
+            -- Contadores de interacción y estado para el usuario que pide el feed
+            (SELECT COUNT(*) FROM CommunityEventLike l WHERE l.CommunityEventId = ce.Id) AS like_count,
+            (SELECT COUNT(*) FROM Comment c WHERE c.CommunityEventId = ce.Id AND c.DeletedAt IS NULL) AS comment_count,
+            EXISTS (SELECT 1 FROM CommunityEventLike l WHERE l.CommunityEventId = ce.Id AND l.UserId = ?) AS liked_by_me,
+            EXISTS (SELECT 1 FROM CommunityEventBookmark b WHERE b.CommunityEventId = ce.Id AND b.UserId = ?) AS bookmarked_by_me,
             -- Scoring: Prioritize newer content. Penalize heavily if already viewed.
             (DATEDIFF(NOW(), ce.CreatedAt) * -0.6) + (IF(vi.UserId IS NULL, 0, -100)) AS relevance_score
         FROM
             CommunityEvent ce
         LEFT JOIN User u ON ce.CreatedByUserId = u.Id
         LEFT JOIN FeedItemView vi ON vi.UserId = ? AND vi.ItemType = 'COMMUNITY_EVENT' AND vi.ItemId = ce.Id
+        -- Las publicaciones ocultas por moderación y las de usuarios bloqueados no aparecen en el feed
+        WHERE NOT EXISTS (SELECT 1 FROM HiddenContent h WHERE h.TargetType = 'POST' AND h.TargetId = CAST(ce.Id AS CHAR))
+            AND ` + NotBlockedCondition("ce.CreatedByUserId") + `
+            AND ` + TenantCondition("ce.TenantId", tenantID) + `
     )
     UNION ALL
     (
         -- Source 2: Users (Students, Graduates, and Companies)
         SELECT
             CASE
-                WHEN u.RoleId IN (1, 2) THEN 'student'
-                WHEN u.RoleId = 3 THEN 'company'
+                WHEN ` + studentRoleIn("u.RoleId") + ` THEN 'student'
+                WHEN ` + businessRoleIs("u.RoleId") + ` THEN 'company'
             END AS item_type,
             u.Id AS item_id,
             CASE
-                WHEN u.RoleId = 3 THEN u.CompanyName
+                WHEN ` + businessRoleIs("u.RoleId") + ` THEN u.CompanyName
                 ELSE CONCAT(u.FirstName, ' ', u.LastName)
             END AS title,
             u.Summary AS description,
@@ -155,40 +228,43 @@ func GetUnifiedFeed(db *sql.DB, userID int64, limit int, offset int) ([]wsmodels
                 WHERE ((c.User1Id = ? AND c.User2Id = u.Id) OR (c.User1Id = u.Id AND c.User2Id = ?))
                 AND c.Status = 'accepted'
             ) as has_contact,
+            NULL AS like_count,
+            NULL AS comment_count,
+            NULL AS liked_by_me,
+            NULL AS bookmarked_by_me,
             -- Scoring: Similar to events, but with slightly less weight on recency.
             (DATEDIFF(NOW(), u.CreatedAt) * -0.5) + (IF(vi.UserId IS NULL, 0, -100)) AS relevance_score
         FROM
             User u
         LEFT JOIN FeedItemView vi ON vi.UserId = ? AND vi.ItemType = 'USER' AND vi.ItemId = u.Id
-        WHERE u.StatusAuthorizedId = 1 AND u.RoleId IN (?, ?, ?) -- 1, 2, 3
+        WHERE ` + activeStatusIs("u.StatusAuthorizedId") + ` AND ` + memberRoleIn("u.RoleId") + `
+            AND ` + NotBlockedCondition("u.Id") + `
+            AND ` + TenantCondition("u.TenantId", tenantID) + `
     )
-    -- Final Ordering and Pagination, applied to the whole UNION result.
-    ORDER BY relevance_score DESC, created_at DESC, item_id DESC
-    LIMIT ? OFFSET ?;
-    `
-
-	logger.Debugf("GetUnifiedFeed", "Ejecutando consulta unificada de feed para UserID %d con Limit: %d, Offset: %d", userID, limit, offset)
+`
+}
 
-	// Ejecuta la consulta.
-	rows, err := db.Query(query, userID, userID, userID, userID, 1, 2, 3, limit, offset)
-	if err != nil {
-		logger.Errorf("GetUnifiedFeed", "Error al ejecutar la consulta de feed unificado para UserID %d: %v", userID, err)
-		return nil, 0, err
-	}
-	defer rows.Close()
+// unifiedFeedArgs devuelve los argumentos de unifiedFeedSources para el usuario que pide el feed.
+func unifiedFeedArgs(userID int64) []interface{} {
+	return []interface{}{userID, userID, userID, userID, userID, userID, userID, userID, userID, userID}
+}
 
+// scanFeedItems convierte las filas de unifiedFeedSources en items del feed. Las filas que no se
+// pueden leer o de tipo desconocido se omiten.
+func scanFeedItems(rows *sql.Rows) ([]wsmodels.FeedItem, error) {
 	var feedItems []wsmodels.FeedItem
 	for rows.Next() {
 		var itemType, title, description, imageUrl, subType, userFirstName, userLastName, companyName, userAvatar, userSector, userUsername sql.NullString
 		var itemID, userID sql.NullInt64
 		var createdAt sql.NullTime
 		var relevanceScore sql.NullFloat64
-		var hasContact sql.NullBool
+		var hasContact, likedByMe, bookmarkedByMe sql.NullBool
+		var likeCount, commentCount sql.NullInt64
 
 		if err := rows.Scan(
 			&itemType, &itemID, &title, &description, &imageUrl, &createdAt, &subType,
 			&userID, &userFirstName, &userLastName, &companyName, &userAvatar, &userSector, &userUsername,
-			&hasContact, &relevanceScore,
+			&hasContact, &likeCount, &commentCount, &likedByMe, &bookmarkedByMe, &relevanceScore,
 		); err != nil {
 			logger.Errorf("GetUnifiedFeed", "Error al escanear fila de feed unificado: %v", err)
 			continue
@@ -205,16 +281,20 @@ func GetUnifiedFeed(db *sql.DB, userID int64, limit int, offset int) ([]wsmodels
 				uid = userID.Int64
 			}
 			data = wsmodels.EventFeedData{
-				Title:       title.String,
-				Company:     companyName.String,
-				CompanyLogo: userAvatar.String,
-				Date:        formatEventDate(createdAt),
-				Location:    companyName.String, // Asumiendo que el evento ocurre en la ubicación de la empresa
-				Image:       imageUrl.String,
-				Description: description.String,
-				PostType:    subType.String,
-				EventID:     itemID.Int64,
-				UserID:      uid,
+				Title:          title.String,
+				Company:        companyName.String,
+				CompanyLogo:    userAvatar.String,
+				Date:           formatEventDate(createdAt),
+				Location:       companyName.String, // Asumiendo que el evento ocurre en la ubicación de la empresa
+				Image:          imageUrl.String,
+				Description:    description.String,
+				PostType:       subType.String,
+				EventID:        itemID.Int64,
+				UserID:         uid,
+				LikeCount:      likeCount.Int64,
+				CommentCount:   commentCount.Int64,
+				LikedByMe:      likedByMe.Bool,
+				BookmarkedByMe: bookmarkedByMe.Bool,
 			}
 		case "student":
 			idStr = "user-" + strconv.FormatInt(itemID.Int64, 10)
@@ -255,13 +335,11 @@ func GetUnifiedFeed(db *sql.DB, userID int64, limit int, offset int) ([]wsmodels
 		feedItems = append(feedItems, feedItem)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		logger.Errorf("GetUnifiedFeed", "Error durante el recorrido de las filas del feed: %v", err)
-		return nil, 0, err
+		return nil, err
 	}
-
-	logger.Successf("GetUnifiedFeed", "Procesados %d items del feed unificado para el usuario %d", len(feedItems), userID)
-	return feedItems, totalItems, nil
+	return feedItems, nil
 }
 
 func formatEventDate(t sql.NullTime) string {
@@ -0,0 +1,95 @@
+package queries
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/fieldcrypt"
+)
+
+// Columnas de User cifradas con pkg/fieldcrypt (ver docs/cifrado_campos.md): se escriben con
+// fieldcrypt.Value y se leen con fieldcrypt.NullString. DocId y RIF se buscan por igualdad, así
+// que tienen además un índice ciego en DocIdHash y RIFHash que se escribe con fieldcrypt.Index.
+const (
+	FieldDocId = "DocId"
+	FieldRIF   = "RIF"
+	FieldPhone = "Phone"
+)
+
+// EncryptedMatch devuelve la condición SQL que compara la columna cifrada column (p. ej. "RIF" o
+// "u.RIF") con value, y sus argumentos. Compara el índice ciego o, en las filas que aún están en
+// texto plano (sin índice), el valor.
+func EncryptedMatch(column, value string) (string, []interface{}) {
+	field := column[strings.LastIndex(column, ".")+1:]
+	condition := fmt.Sprintf("(%[1]sHash = ? OR (%[1]sHash IS NULL AND %[1]s = ?))", column)
+	return condition, []interface{}{fieldcrypt.Index(field, value), value}
+}
+
+// decryptField descifra value si está cifrado. Lo usan las consultas genéricas, que leen las
+// columnas sin saber cuáles están cifradas.
+func decryptField(value string) (string, error) {
+	var field fieldcrypt.NullString
+	if err := field.Scan(value); err != nil {
+		return "", err
+	}
+	return field.String, nil
+}
+
+// GetUserSensitiveFields devuelve hasta limit filas de User con Id mayor que afterID, con las
+// columnas cifradas tal como están guardadas.
+func GetUserSensitiveFields(afterID int64, limit int) ([]models.UserSensitiveFields, error) {
+	rows, err := DB.Query(`
+		SELECT Id, DocId, DocIdHash, Phone, RIF, RIFHash
+		FROM User
+		WHERE Id > ?
+		ORDER BY Id
+		LIMIT ?`, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando las columnas cifradas: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.UserSensitiveFields
+	for rows.Next() {
+		var u models.UserSensitiveFields
+		if err := rows.Scan(&u.Id, &u.DocId, &u.DocIdHash, &u.Phone, &u.RIF, &u.RIFHash); err != nil {
+			return nil, fmt.Errorf("error escaneando las columnas cifradas: %w", err)
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// UpdateUserSensitiveFields guarda las columnas cifradas de cada fila de users en una sola
+// transacción, sin cambiar UpdatedAt. Una fila solo se actualiza si DocId, Phone y RIF siguen
+// como en la fila de previous con el mismo índice; si el usuario los cambió mientras tanto se
+// omite. Devuelve cuántas filas se omitieron.
+func UpdateUserSensitiveFields(users, previous []models.UserSensitiveFields) (int, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		UPDATE User SET DocId = ?, DocIdHash = ?, Phone = ?, RIF = ?, RIFHash = ?, UpdatedAt = UpdatedAt
+		WHERE Id = ? AND DocId <=> ? AND Phone <=> ? AND RIF <=> ?`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	skipped := 0
+	for i, u := range users {
+		old := previous[i]
+		result, err := stmt.Exec(u.DocId, u.DocIdHash, u.Phone, u.RIF, u.RIFHash, u.Id, old.DocId, old.Phone, old.RIF)
+		if err != nil {
+			return 0, fmt.Errorf("error al reescribir las columnas cifradas del usuario %d: %w", u.Id, err)
+		}
+		if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+			skipped++
+		}
+	}
+	return skipped, tx.Commit()
+}
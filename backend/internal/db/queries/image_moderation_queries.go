@@ -0,0 +1,185 @@
+package queries
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// ErrImageReviewClosed indica que la imagen ya fue aprobada o eliminada.
+var ErrImageReviewClosed = errors.New("la imagen ya fue revisada")
+
+var imageReviewSelect = `
+	SELECT ir.Id, ir.UserId, ` + fmt.Sprintf(reportDisplayName, "u") + `, ir.ContentId, ir.FileName, ir.Score,
+	       ir.Labels, ir.Status, COALESCE(ir.ResolutionNote, ''), ir.ReviewedBy, ir.ReviewedAt, ir.CreatedAt
+	FROM ImageReview ir
+	JOIN User u ON u.Id = ir.UserId`
+
+func scanImageReview(row rowScanner) (models.ImageReview, error) {
+	var review models.ImageReview
+	var labels []byte
+	var reviewedBy sql.NullInt64
+	var reviewedAt sql.NullTime
+	err := row.Scan(&review.Id, &review.UserId, &review.UserName, &review.ContentId, &review.FileName, &review.Score,
+		&labels, &review.Status, &review.ResolutionNote, &reviewedBy, &reviewedAt, &review.CreatedAt)
+	if err != nil {
+		return review, err
+	}
+	review.Labels = []string{}
+	if len(labels) > 0 {
+		if err := json.Unmarshal(labels, &review.Labels); err != nil {
+			return review, fmt.Errorf("error al leer las etiquetas de la revisión %d: %w", review.Id, err)
+		}
+	}
+	if reviewedBy.Valid {
+		review.ReviewedBy = &reviewedBy.Int64
+	}
+	if reviewedAt.Valid {
+		review.ReviewedAt = &reviewedAt.Time
+	}
+	return review, nil
+}
+
+// InsertImageReview añade una imagen a la cola de revisión. Si ya estaba no hace nada.
+func InsertImageReview(userID int64, contentID, fileName string, score float64, labels []string) error {
+	if labels == nil {
+		labels = []string{}
+	}
+	labelsJSON, err := json.Marshal(labels)
+	if err != nil {
+		return err
+	}
+	_, err = DB.Exec(`
+		INSERT IGNORE INTO ImageReview (UserId, ContentId, FileName, Score, Labels)
+		VALUES (?, ?, ?, ?, ?)`, userID, contentID, fileName, score, labelsJSON)
+	if err != nil {
+		return fmt.Errorf("error al añadir la imagen %s a la cola de revisión: %w", contentID, err)
+	}
+	return nil
+}
+
+// GetImageReview devuelve una imagen de la cola de revisión. Si no existe devuelve
+// sql.ErrNoRows.
+func GetImageReview(id int64) (*models.ImageReview, error) {
+	review, err := scanImageReview(DB.QueryRow(imageReviewSelect+` WHERE ir.Id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// ListImageReviews devuelve una página de la cola de revisión, de las imágenes más antiguas a
+// las más recientes, y el total. status vacío no filtra.
+func ListImageReviews(status string, limit, offset int) ([]models.ImageReview, int, error) {
+	const filter = ` WHERE (? = '' OR ir.Status = ?)`
+
+	var total int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM ImageReview ir`+filter, status, status).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error al contar las imágenes en revisión: %w", err)
+	}
+	if total == 0 {
+		return []models.ImageReview{}, 0, nil
+	}
+
+	rows, err := DB.Query(imageReviewSelect+filter+` ORDER BY ir.CreatedAt, ir.Id LIMIT ? OFFSET ?`, status, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al obtener las imágenes en revisión: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := []models.ImageReview{}
+	for rows.Next() {
+		review, err := scanImageReview(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, total, rows.Err()
+}
+
+// ResolveImageReview guarda la decisión del moderador en una transacción. Al eliminar, marca la
+// imagen y sus variantes como removed y la quita de la foto de perfil y de las publicaciones
+// que la usan; devuelve los archivos a borrar del almacenamiento. Si la imagen ya fue revisada
+// devuelve ErrImageReviewClosed.
+func ResolveImageReview(id, adminID int64, status, note string) ([]string, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var userID int64
+	var contentID, current string
+	err = tx.QueryRow(`SELECT UserId, ContentId, Status FROM ImageReview WHERE Id = ? FOR UPDATE`, id).
+		Scan(&userID, &contentID, &current)
+	if err != nil {
+		return nil, err
+	}
+	if current != models.ImageReviewPending {
+		return nil, ErrImageReviewClosed
+	}
+
+	var fileNames []string
+	if status == models.ImageReviewRemoved {
+		rows, err := tx.Query(`SELECT FileName FROM Multimedia WHERE ContentId = ? AND FileName IS NOT NULL`, contentID)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var fileName string
+			if err := rows.Scan(&fileName); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			fileNames = append(fileNames, fileName)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		// Las filas de Multimedia se conservan porque los mensajes que adjuntan la imagen las
+		// referencian.
+		if _, err := tx.Exec(`UPDATE Multimedia SET ProcessingStatus = 'removed' WHERE ContentId = ?`, contentID); err != nil {
+			return nil, fmt.Errorf("error al marcar como eliminada la imagen %s: %w", contentID, err)
+		}
+		if len(fileNames) > 0 {
+			placeholders := strings.TrimSuffix(strings.Repeat("?,", len(fileNames)), ",")
+			args := make([]any, 0, len(fileNames)+1)
+			args = append(args, userID)
+			for _, fileName := range fileNames {
+				args = append(args, fileName)
+			}
+			if _, err := tx.Exec(`UPDATE User SET Picture = NULL WHERE Id = ? AND Picture IN (`+placeholders+`)`, args...); err != nil {
+				return nil, fmt.Errorf("error al quitar la foto de perfil del usuario %d: %w", userID, err)
+			}
+			// ImageUrl guarda la URL completa o solo el nombre del archivo
+			for _, fileName := range fileNames {
+				if _, err := tx.Exec(`
+					UPDATE CommunityEvent SET ImageUrl = NULL
+					WHERE CreatedByUserId = ? AND (ImageUrl = ? OR ImageUrl LIKE CONCAT('%/', ?))`,
+					userID, fileName, fileName); err != nil {
+					return nil, fmt.Errorf("error al quitar la imagen %s de las publicaciones: %w", fileName, err)
+				}
+			}
+		}
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE ImageReview SET Status = ?, ResolutionNote = NULLIF(?, ''), ReviewedBy = ?, ReviewedAt = NOW()
+		WHERE Id = ?`, status, note, adminID, id); err != nil {
+		return nil, fmt.Errorf("error al cerrar la revisión de la imagen %d: %w", id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	if status == models.ImageReviewRemoved {
+		InvalidateUserCache(userID)
+	}
+	return fileNames, nil
+}
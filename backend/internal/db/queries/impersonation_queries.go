@@ -0,0 +1,83 @@
+package queries
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// ErrImpersonationEnded indica que la suplantación ya terminó o expiró.
+var ErrImpersonationEnded = errors.New("la suplantación ya terminó")
+
+// CreateImpersonation registra una suplantación y devuelve su ID.
+func CreateImpersonation(adminID, userID int64, reason string, expiresAt time.Time) (int64, error) {
+	result, err := DB.Exec(`
+		INSERT INTO Impersonation (AdminId, UserId, Reason, ExpiresAt)
+		VALUES (?, ?, ?, ?)`,
+		adminID, userID, reason, expiresAt)
+	if err != nil {
+		return 0, fmt.Errorf("error al registrar la suplantación del usuario %d: %w", userID, err)
+	}
+	return result.LastInsertId()
+}
+
+// IsImpersonationActive indica si la suplantación existe para ese administrador y usuario y
+// no ha terminado ni expirado.
+func IsImpersonationActive(impersonationID, adminID, userID int64) (bool, error) {
+	var active bool
+	err := DB.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM Impersonation
+			WHERE Id = ? AND AdminId = ? AND UserId = ? AND EndedAt IS NULL AND ExpiresAt > ?
+		)`, impersonationID, adminID, userID, time.Now()).Scan(&active)
+	if err != nil {
+		return false, fmt.Errorf("error al comprobar la suplantación %d: %w", impersonationID, err)
+	}
+	return active, nil
+}
+
+// GetImpersonation devuelve la suplantación. Si no existe devuelve sql.ErrNoRows.
+func GetImpersonation(impersonationID int64) (models.Impersonation, error) {
+	var imp models.Impersonation
+	var adminID sql.NullInt64
+	var endedAt sql.NullTime
+	err := DB.QueryRow(`
+		SELECT Id, AdminId, UserId, Reason, CreatedAt, ExpiresAt, EndedAt
+		FROM Impersonation WHERE Id = ?`, impersonationID).
+		Scan(&imp.Id, &adminID, &imp.UserId, &imp.Reason, &imp.CreatedAt, &imp.ExpiresAt, &endedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return imp, err
+		}
+		return imp, fmt.Errorf("error al obtener la suplantación %d: %w", impersonationID, err)
+	}
+	imp.AdminId = adminID.Int64
+	if endedAt.Valid {
+		imp.EndedAt = &endedAt.Time
+	}
+	return imp, nil
+}
+
+// EndImpersonation termina una suplantación antes de que expire y la devuelve. Devuelve
+// sql.ErrNoRows si no existe y ErrImpersonationEnded si ya había terminado o expirado.
+func EndImpersonation(impersonationID int64) (models.Impersonation, error) {
+	now := time.Now()
+	result, err := DB.Exec(`
+		UPDATE Impersonation SET EndedAt = ?
+		WHERE Id = ? AND EndedAt IS NULL AND ExpiresAt > ?`,
+		now, impersonationID, now)
+	if err != nil {
+		return models.Impersonation{}, fmt.Errorf("error al terminar la suplantación %d: %w", impersonationID, err)
+	}
+	imp, err := GetImpersonation(impersonationID)
+	if err != nil {
+		return imp, err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return imp, ErrImpersonationEnded
+	}
+	return imp, nil
+}
@@ -9,7 +9,16 @@ const (
 
 	// ListApplicantsByEvent recupera la lista de postulantes para una oferta específica,
 	// ordenados por su calificación y reputación.
-	ListApplicantsByEvent = `
+	ListApplicantsByEvent = ListApplicantsByEventBase + `
+		ORDER BY
+			AverageRating DESC,
+			ReputationScore DESC;
+	`
+
+	// ListApplicantsByEventBase selecciona los postulantes de una oferta (argumento: el Id del
+	// evento) sin orden. La paginación por cursor le añade la condición keyset sobre
+	// (ja.AppliedAt, ja.ApplicantId), el ORDER BY y el LIMIT.
+	ListApplicantsByEventBase = `
 		WITH UserRatings AS (
 			SELECT
 				RevieweeId,
@@ -50,9 +59,6 @@ const (
 			UserReputationScore urs ON ja.ApplicantId = urs.RevieweeId
 		WHERE
 			ja.CommunityEventId = ?
-		ORDER BY
-			AverageRating DESC,
-			ReputationScore DESC;
 	`
 
 	// UpdateJobApplicationStatus actualiza el estado de una postulación específica.
@@ -0,0 +1,333 @@
+package queries
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// GetJobRequirements devuelve los requisitos de una oferta. Si la publicación no tiene
+// requisitos devuelve sql.ErrNoRows.
+func GetJobRequirements(eventID int64) (*models.JobRequirements, error) {
+	req := models.JobRequirements{CommunityEventId: eventID}
+	var skills, degrees, languages []byte
+	err := DB.QueryRow(`
+		SELECT Skills, Degrees, Languages, MinExperienceYears, UpdatedAt
+		FROM JobPostingRequirements WHERE CommunityEventId = ?`, eventID).
+		Scan(&skills, &degrees, &languages, &req.MinExperienceYears, &req.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeJobRequirements(&req, skills, degrees, languages); err != nil {
+		return nil, fmt.Errorf("error al leer los requisitos de la oferta %d: %w", eventID, err)
+	}
+	return &req, nil
+}
+
+// decodeJobRequirements lee las columnas JSON de JobPostingRequirements.
+func decodeJobRequirements(req *models.JobRequirements, skills, degrees, languages []byte) error {
+	req.Skills = []models.JobSkillRequirement{}
+	req.Degrees = []string{}
+	req.Languages = []models.JobLanguageRequirement{}
+	if err := json.Unmarshal(skills, &req.Skills); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(degrees, &req.Degrees); err != nil {
+		return err
+	}
+	return json.Unmarshal(languages, &req.Languages)
+}
+
+// SaveJobRequirements crea o reemplaza los requisitos de una oferta.
+func SaveJobRequirements(req models.JobRequirements) error {
+	skills, err := json.Marshal(req.Skills)
+	if err != nil {
+		return fmt.Errorf("error al serializar las habilidades: %w", err)
+	}
+	degrees, err := json.Marshal(req.Degrees)
+	if err != nil {
+		return fmt.Errorf("error al serializar las carreras: %w", err)
+	}
+	languages, err := json.Marshal(req.Languages)
+	if err != nil {
+		return fmt.Errorf("error al serializar los idiomas: %w", err)
+	}
+
+	_, err = DB.Exec(`
+		INSERT INTO JobPostingRequirements (CommunityEventId, Skills, Degrees, Languages, MinExperienceYears)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			Skills = VALUES(Skills), Degrees = VALUES(Degrees), Languages = VALUES(Languages),
+			MinExperienceYears = VALUES(MinExperienceYears)`,
+		req.CommunityEventId, skills, degrees, languages, req.MinExperienceYears)
+	if err != nil {
+		return fmt.Errorf("error al guardar los requisitos de la oferta %d: %w", req.CommunityEventId, err)
+	}
+	return nil
+}
+
+// DeleteJobRequirements elimina los requisitos de una oferta; la publicación deja de
+// recomendarse.
+func DeleteJobRequirements(eventID int64) error {
+	if _, err := DB.Exec(`DELETE FROM JobPostingRequirements WHERE CommunityEventId = ?`, eventID); err != nil {
+		return fmt.Errorf("error al eliminar los requisitos de la oferta %d: %w", eventID, err)
+	}
+	return nil
+}
+
+// GetCandidatePool devuelve hasta limit estudiantes y egresados activos que tienen alguna de
+// las habilidades (por ID del catálogo o por texto, sin distinguir mayúsculas) o estudian
+// alguna de las carreras indicadas. Sin habilidades ni carreras devuelve los candidatos con más
+// reputación. En ambos casos se ordenan por reputación y se excluye a excludeUserID.
+func GetCandidatePool(skillIDs []int64, skillNames, degrees []string, excludeUserID int64, limit int) ([]int64, error) {
+	var conditions []string
+	var args []interface{}
+	if len(skillIDs) > 0 {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM Skills s WHERE s.PersonId = u.Id AND s.SkillCatalogId IN (`+placeholders(len(skillIDs))+`))`)
+		for _, id := range skillIDs {
+			args = append(args, id)
+		}
+	}
+	if len(skillNames) > 0 {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM Skills s WHERE s.PersonId = u.Id AND LOWER(s.Skill) IN (`+placeholders(len(skillNames))+`))`)
+		for _, name := range skillNames {
+			args = append(args, strings.ToLower(name))
+		}
+	}
+	if len(degrees) > 0 {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM Education e WHERE e.PersonId = u.Id AND LOWER(e.Degree) IN (`+placeholders(len(degrees))+`))`)
+		for _, degree := range degrees {
+			args = append(args, strings.ToLower(degree))
+		}
+	}
+
+	query := `
+		SELECT u.Id
+		FROM User u
+		LEFT JOIN (
+			SELECT RevieweeId, SUM(PointsRP) AS Total FROM ReputationReview GROUP BY RevieweeId
+		) rep ON rep.RevieweeId = u.Id
+		WHERE ` + studentRoleIn("u.RoleId") + ` AND ` + activeStatusIs("u.StatusAuthorizedId") + ` AND u.Id <> ?`
+	args = append([]interface{}{excludeUserID}, args...)
+	if len(conditions) > 0 {
+		query += ` AND (` + strings.Join(conditions, ` OR `) + `)`
+	}
+	query += ` ORDER BY COALESCE(rep.Total, 0) DESC, u.Id ASC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar los candidatos: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error al leer los candidatos: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetCandidateProfiles carga el perfil que se compara con los requisitos de una oferta
+// (habilidades, carreras, idiomas, años de experiencia y RP) de varios usuarios. Los años de
+// experiencia suman la duración de cada experiencia laboral; las que siguen en curso cuentan
+// hasta hoy. ReputationPercentile no se rellena.
+func GetCandidateProfiles(userIDs []int64) (map[int64]*models.CandidateProfile, error) {
+	profiles := make(map[int64]*models.CandidateProfile, len(userIDs))
+	err := forEachIDChunk(uniqueIDs(userIDs), func(chunk []int64, args []interface{}) error {
+		in := placeholders(len(chunk))
+
+		rows, err := DB.Query(`
+			SELECT u.Id, COALESCE(u.FirstName, ''), COALESCE(u.LastName, ''), COALESCE(u.Picture, ''), u.RoleId,
+				`+VerifiedStudentCondition("u.Id")+`,
+				COALESCE((SELECT SUM(PointsRP) FROM ReputationReview WHERE RevieweeId = u.Id), 0),
+				COALESCE((
+					SELECT SUM(TIMESTAMPDIFF(MONTH, w.StartDate, CASE WHEN w.IsCurrentJob OR w.EndDate IS NULL THEN CURDATE() ELSE w.EndDate END))
+					FROM WorkExperience w WHERE w.PersonId = u.Id AND w.StartDate IS NOT NULL
+				), 0)
+			FROM User u WHERE u.Id IN (`+in+`)`, args...)
+		if err != nil {
+			return fmt.Errorf("error al consultar los perfiles de %d candidatos: %w", len(chunk), err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			profile := &models.CandidateProfile{Skills: []models.Skills{}, Degrees: []string{}, Languages: []models.Languages{}}
+			var months int64
+			if err := rows.Scan(&profile.UserId, &profile.FirstName, &profile.LastName, &profile.Picture, &profile.RoleId, &profile.VerifiedStudent, &profile.ReputationRP, &months); err != nil {
+				return fmt.Errorf("error al leer el perfil del candidato: %w", err)
+			}
+			profile.ExperienceYears = float64(max(months, 0)) / 12
+			profiles[profile.UserId] = profile
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		skillRows, err := DB.Query(`SELECT PersonId, Skill, COALESCE(Level, ''), SkillCatalogId FROM Skills WHERE PersonId IN (`+in+`)`, args...)
+		if err != nil {
+			return fmt.Errorf("error al consultar las habilidades de los candidatos: %w", err)
+		}
+		defer skillRows.Close()
+		for skillRows.Next() {
+			var skill models.Skills
+			var catalogID sql.NullInt64
+			if err := skillRows.Scan(&skill.PersonId, &skill.Skill, &skill.Level, &catalogID); err != nil {
+				return fmt.Errorf("error al leer las habilidades de los candidatos: %w", err)
+			}
+			if catalogID.Valid {
+				skill.SkillCatalogId = &catalogID.Int64
+			}
+			if profile, ok := profiles[skill.PersonId]; ok {
+				profile.Skills = append(profile.Skills, skill)
+			}
+		}
+		if err := skillRows.Err(); err != nil {
+			return err
+		}
+
+		degreeRows, err := DB.Query(`SELECT PersonId, Degree FROM Education WHERE PersonId IN (`+in+`) AND Degree IS NOT NULL AND Degree <> ''`, args...)
+		if err != nil {
+			return fmt.Errorf("error al consultar las carreras de los candidatos: %w", err)
+		}
+		defer degreeRows.Close()
+		for degreeRows.Next() {
+			var personID int64
+			var degree string
+			if err := degreeRows.Scan(&personID, &degree); err != nil {
+				return fmt.Errorf("error al leer las carreras de los candidatos: %w", err)
+			}
+			if profile, ok := profiles[personID]; ok {
+				profile.Degrees = append(profile.Degrees, degree)
+			}
+		}
+		if err := degreeRows.Err(); err != nil {
+			return err
+		}
+
+		languageRows, err := DB.Query(`SELECT PersonId, Language, COALESCE(Level, '') FROM Languages WHERE PersonId IN (`+in+`)`, args...)
+		if err != nil {
+			return fmt.Errorf("error al consultar los idiomas de los candidatos: %w", err)
+		}
+		defer languageRows.Close()
+		for languageRows.Next() {
+			var language models.Languages
+			if err := languageRows.Scan(&language.PersonId, &language.Language, &language.Level); err != nil {
+				return fmt.Errorf("error al leer los idiomas de los candidatos: %w", err)
+			}
+			if profile, ok := profiles[language.PersonId]; ok {
+				profile.Languages = append(profile.Languages, language)
+			}
+		}
+		return languageRows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// GetStudentReputationTotals devuelve, en orden ascendente, el total de RP de cada estudiante
+// o egresado con reseñas. Sirve para calcular percentiles sin una consulta por candidato.
+func GetStudentReputationTotals() ([]int, error) {
+	rows, err := DB.Query(`
+		SELECT SUM(rr.PointsRP) AS Total
+		FROM ReputationReview rr
+		JOIN User u ON rr.RevieweeId = u.Id
+		WHERE ` + leaderboardRoleFilters[LeaderboardStudents]("u.RoleId") + `
+		GROUP BY rr.RevieweeId
+		ORDER BY Total ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar los totales de reputación: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []int{}
+	for rows.Next() {
+		var total int
+		if err := rows.Scan(&total); err != nil {
+			return nil, fmt.Errorf("error al leer los totales de reputación: %w", err)
+		}
+		totals = append(totals, total)
+	}
+	return totals, rows.Err()
+}
+
+// GetApplicantIDs devuelve los usuarios que se postularon a una oferta.
+func GetApplicantIDs(eventID int64) (map[int64]bool, error) {
+	return scanIDSet(`SELECT ApplicantId FROM JobApplication WHERE CommunityEventId = ?`, eventID)
+}
+
+// GetAppliedEventIDs devuelve las ofertas a las que se postuló un usuario.
+func GetAppliedEventIDs(userID int64) (map[int64]bool, error) {
+	return scanIDSet(`SELECT CommunityEventId FROM JobApplication WHERE ApplicantId = ?`, userID)
+}
+
+// scanIDSet ejecuta una consulta que devuelve una columna de IDs y los devuelve como conjunto.
+func scanIDSet(query string, args ...interface{}) (map[int64]bool, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar las postulaciones: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error al leer las postulaciones: %w", err)
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// JobPostingWithRequirements es una oferta con sus requisitos, candidata a recomendarse.
+type JobPostingWithRequirements struct {
+	Posting      models.JobRecommendation
+	Requirements models.JobRequirements
+}
+
+// ListJobPostingsWithRequirements devuelve las limit ofertas más recientes que tienen
+// requisitos, excluyendo las creadas por excludeUserID. Los desafíos cerrados o cancelados no
+// se incluyen.
+func ListJobPostingsWithRequirements(excludeUserID int64, limit int) ([]JobPostingWithRequirements, error) {
+	rows, err := DB.Query(`
+		SELECT ce.Id, ce.Title, ce.PostType, ce.CreatedByUserId,
+			COALESCE(NULLIF(u.CompanyName, ''), ce.OrganizerCompanyName, ''), ce.CreatedAt,
+			r.Skills, r.Degrees, r.Languages, r.MinExperienceYears, r.UpdatedAt
+		FROM JobPostingRequirements r
+		JOIN CommunityEvent ce ON ce.Id = r.CommunityEventId
+		LEFT JOIN User u ON u.Id = ce.CreatedByUserId
+		WHERE ce.CreatedByUserId <> ?
+		  AND NOT (ce.PostType = 'DESAFIO' AND ce.ChallengeStatus IN ('CERRADO', 'CANCELADO'))
+		ORDER BY ce.CreatedAt DESC
+		LIMIT ?`, excludeUserID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar las ofertas con requisitos: %w", err)
+	}
+	defer rows.Close()
+
+	postings := []JobPostingWithRequirements{}
+	for rows.Next() {
+		var p JobPostingWithRequirements
+		var skills, degrees, languages []byte
+		if err := rows.Scan(&p.Posting.CommunityEventId, &p.Posting.Title, &p.Posting.PostType, &p.Posting.CompanyId,
+			&p.Posting.CompanyName, &p.Posting.CreatedAt,
+			&skills, &degrees, &languages, &p.Requirements.MinExperienceYears, &p.Requirements.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error al leer las ofertas con requisitos: %w", err)
+		}
+		p.Requirements.CommunityEventId = p.Posting.CommunityEventId
+		if err := decodeJobRequirements(&p.Requirements, skills, degrees, languages); err != nil {
+			return nil, fmt.Errorf("error al leer los requisitos de la oferta %d: %w", p.Posting.CommunityEventId, err)
+		}
+		postings = append(postings, p)
+	}
+	return postings, rows.Err()
+}
@@ -0,0 +1,53 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// GetForwardSource devuelve el mensaje messageID si userID participa en su chat privado o de
+// grupo. Devuelve sql.ErrNoRows si el mensaje no existe o el usuario no tiene acceso a él.
+func GetForwardSource(userID int64, messageID string) (*models.ForwardSource, error) {
+	var source models.ForwardSource
+	var content, fileName, forwardedFrom sql.NullString
+	var forwardedSender sql.NullInt64
+	err := DB.QueryRow(`
+		SELECT m.Id, m.SenderId, m.Content, mm.FileName, m.ForwardedFromMessageId, m.ForwardedFromSenderId,
+		       EXISTS (SELECT 1 FROM HiddenContent h WHERE h.TargetType = 'MESSAGE' AND h.TargetId = m.Id)
+		FROM Message m
+		LEFT JOIN Multimedia mm ON mm.Id = m.MediaId
+		WHERE m.Id = ? AND (
+			EXISTS (SELECT 1 FROM Contact c WHERE c.ChatId = m.ChatId AND (c.User1Id = ? OR c.User2Id = ?))
+			OR EXISTS (
+				SELECT 1 FROM GroupsUsers g JOIN GroupMembers gm ON gm.GroupId = g.Id
+				WHERE g.ChatId = m.ChatIdGroup AND gm.UserId = ?)
+		)`, messageID, userID, userID, userID).
+		Scan(&source.Id, &source.SenderId, &content, &fileName, &forwardedFrom, &forwardedSender, &source.IsHidden)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error obteniendo el mensaje %s a reenviar: %w", messageID, err)
+	}
+	source.Content = content.String
+	source.MediaFileName = fileName.String
+	source.ForwardedFromMessageId = forwardedFrom.String
+	source.ForwardedFromSenderId = forwardedSender.Int64
+	return &source, nil
+}
+
+// GetAcceptedContactChatID devuelve el ChatId del contacto aceptado entre userID y otherID.
+// Devuelve sql.ErrNoRows si no son contactos.
+func GetAcceptedContactChatID(userID, otherID int64) (string, error) {
+	var chatID string
+	err := DB.QueryRow(`
+		SELECT ChatId FROM Contact
+		WHERE ((User1Id = ? AND User2Id = ?) OR (User1Id = ? AND User2Id = ?)) AND Status = 'accepted'
+		LIMIT 1`, userID, otherID, otherID, userID).Scan(&chatID)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("error obteniendo el chat entre %d y %d: %w", userID, otherID, err)
+	}
+	return chatID, err
+}
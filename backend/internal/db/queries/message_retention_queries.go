@@ -0,0 +1,255 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// staleRetentionRunAfter es el tiempo tras el cual una ejecución 'running' se considera
+// abandonada (p.ej. el proceso murió) y deja de bloquear nuevas ejecuciones.
+const staleRetentionRunAfter = 6 * time.Hour
+
+// StartMessageRetentionRun registra una nueva ejecución de retención si no hay otra en curso.
+// Devuelve el ID de la ejecución y false si ya había una ejecución activa.
+func StartMessageRetentionRun(trigger string, triggeredBy *int64, cutoff time.Time) (int64, bool, error) {
+	result, err := DB.Exec(`
+		INSERT INTO MessageRetentionRun (TriggerType, TriggeredBy, Status, Cutoff)
+		SELECT ?, ?, 'running', ? FROM DUAL
+		WHERE NOT EXISTS (
+			SELECT 1 FROM MessageRetentionRun WHERE Status = 'running' AND StartedAt > ?
+		)`,
+		trigger, triggeredBy, cutoff, time.Now().Add(-staleRetentionRunAfter),
+	)
+	if err != nil {
+		return 0, false, fmt.Errorf("error registrando ejecución de retención: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return 0, false, nil
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, false, fmt.Errorf("error obteniendo ID de la ejecución de retención: %w", err)
+	}
+	return id, true, nil
+}
+
+// FinishMessageRetentionRun cierra una ejecución con su estado final y el total archivado.
+func FinishMessageRetentionRun(runID int64, status string, archived int64, errMsg string) error {
+	_, err := DB.Exec(
+		`UPDATE MessageRetentionRun SET Status = ?, Archived = ?, FinishedAt = NOW(), ErrorMessage = NULLIF(?, '') WHERE Id = ?`,
+		status, archived, errMsg, runID,
+	)
+	if err != nil {
+		return fmt.Errorf("error finalizando ejecución de retención %d: %w", runID, err)
+	}
+	return nil
+}
+
+// UpdateMessageRetentionRunProgress actualiza el total archivado de una ejecución en curso.
+func UpdateMessageRetentionRunProgress(runID int64, archived int64) error {
+	if _, err := DB.Exec(`UPDATE MessageRetentionRun SET Archived = ? WHERE Id = ?`, archived, runID); err != nil {
+		return fmt.Errorf("error actualizando progreso de la ejecución de retención %d: %w", runID, err)
+	}
+	return nil
+}
+
+// GetMessageRetentionRuns devuelve las ejecuciones más recientes, de la más nueva a la más antigua.
+func GetMessageRetentionRuns(limit int) ([]models.MessageRetentionRun, error) {
+	rows, err := DB.Query(`
+		SELECT Id, TriggerType, TriggeredBy, Status, Cutoff, Archived, StartedAt, FinishedAt, ErrorMessage
+		FROM MessageRetentionRun
+		ORDER BY Id DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo ejecuciones de retención: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.MessageRetentionRun{}
+	for rows.Next() {
+		var run models.MessageRetentionRun
+		var triggeredBy sql.NullInt64
+		var finishedAt sql.NullTime
+		var errMsg sql.NullString
+		if err := rows.Scan(&run.Id, &run.Trigger, &triggeredBy, &run.Status, &run.Cutoff, &run.Archived, &run.StartedAt, &finishedAt, &errMsg); err != nil {
+			return nil, fmt.Errorf("error escaneando ejecución de retención: %w", err)
+		}
+		if triggeredBy.Valid {
+			run.TriggeredBy = &triggeredBy.Int64
+		}
+		if finishedAt.Valid {
+			run.FinishedAt = &finishedAt.Time
+		}
+		run.Error = errMsg.String
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// ArchiveMessagesBatch mueve a MessageArchive hasta limit mensajes enviados antes de cutoff
+// (excluyendo los chats con opt-out) y los elimina de Message en una única transacción.
+// Devuelve cuántos mensajes se archivaron; 0 indica que no queda nada archivable.
+//
+// Los mensajes que todavía son respondidos por mensajes fuera del lote se omiten, ya que la
+// clave foránea ReplyToMessageId impediría borrarlos; se archivarán junto con sus respuestas.
+func ArchiveMessagesBatch(runID int64, cutoff time.Time, limit int) (int64, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error iniciando transacción de archivado: %w", err)
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya fue confirmada
+
+	rows, err := tx.Query(`
+		SELECT m.Id FROM Message m
+		WHERE m.SentAt < ?
+		  AND COALESCE(m.ChatId, m.ChatIdGroup) NOT IN (SELECT ChatId FROM ChatRetentionOptOut)
+		ORDER BY m.SentAt
+		LIMIT ?
+		FOR UPDATE`, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("error seleccionando mensajes a archivar: %w", err)
+	}
+	var ids []interface{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error escaneando mensaje a archivar: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// Excluir los mensajes respondidos por mensajes que no forman parte del lote.
+	in := placeholders(len(ids))
+	args := append(append([]interface{}{}, ids...), ids...)
+	refRows, err := tx.Query(
+		`SELECT DISTINCT ReplyToMessageId FROM Message WHERE ReplyToMessageId IN (`+in+`) AND Id NOT IN (`+in+`)`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("error comprobando respuestas a mensajes archivables: %w", err)
+	}
+	referenced := make(map[string]bool)
+	for refRows.Next() {
+		var id string
+		if err := refRows.Scan(&id); err != nil {
+			refRows.Close()
+			return 0, fmt.Errorf("error escaneando mensaje respondido: %w", err)
+		}
+		referenced[id] = true
+	}
+	refRows.Close()
+
+	batch := ids[:0]
+	for _, id := range ids {
+		if !referenced[id.(string)] {
+			batch = append(batch, id)
+		}
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	in = placeholders(len(batch))
+
+	if _, err := tx.Exec(`
+		INSERT INTO MessageArchive (Id, ChatId, ChatIdGroup, SenderId, TypeMessageId, Content, MediaId, ReplyToMessageId,
+			ForwardedFromMessageId, ForwardedFromSenderId, SentAt, EditedAt, Status, RunId)
+		SELECT Id, ChatId, ChatIdGroup, SenderId, TypeMessageId, Content, MediaId, ReplyToMessageId,
+			ForwardedFromMessageId, ForwardedFromSenderId, SentAt, EditedAt, Status, ?
+		FROM Message WHERE Id IN (`+in+`)`, append([]interface{}{runID}, batch...)...); err != nil {
+		return 0, fmt.Errorf("error copiando mensajes al archivo: %w", err)
+	}
+
+	// El último mensaje y los no leídos de los chats del lote pueden cambiar
+	if err := touchChatSync(tx, "c.ChatId IN (SELECT ChatId FROM Message WHERE Id IN ("+in+"))", batch...); err != nil {
+		return 0, err
+	}
+	chatIDs, err := messageChatIDs(tx, batch)
+	if err != nil {
+		return 0, err
+	}
+
+	// Las respuestas dentro del lote se desvinculan antes de borrar (la copia archivada conserva la referencia).
+	if _, err := tx.Exec(`UPDATE Message SET ReplyToMessageId = NULL WHERE ReplyToMessageId IS NOT NULL AND Id IN (`+in+`)`, batch...); err != nil {
+		return 0, fmt.Errorf("error desvinculando respuestas del lote archivado: %w", err)
+	}
+
+	result, err := tx.Exec(`DELETE FROM Message WHERE Id IN (`+in+`)`, batch...)
+	if err != nil {
+		return 0, fmt.Errorf("error eliminando mensajes archivados: %w", err)
+	}
+	deleted, _ := result.RowsAffected()
+	if err := recountChatUnreadByChatIDs(tx, chatIDs); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error confirmando transacción de archivado: %w", err)
+	}
+	// El último mensaje de los chats afectados puede haber cambiado
+	if deleted > 0 {
+		InvalidateAllChatListsCache()
+	}
+	return deleted, nil
+}
+
+// ChatExists indica si el ChatId corresponde a un chat privado (Contact) o de grupo (GroupsUsers).
+func ChatExists(chatID string) (bool, error) {
+	var exists bool
+	err := DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM Contact WHERE ChatId = ?)
+		    OR EXISTS(SELECT 1 FROM GroupsUsers WHERE ChatId = ?)`, chatID, chatID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error comprobando existencia del chat %s: %w", chatID, err)
+	}
+	return exists, nil
+}
+
+// SetChatRetentionOptOut excluye un chat del archivado de mensajes (idempotente).
+func SetChatRetentionOptOut(chatID string, adminID int64) error {
+	if _, err := DB.Exec(`INSERT IGNORE INTO ChatRetentionOptOut (ChatId, OptedOutBy) VALUES (?, ?)`, chatID, adminID); err != nil {
+		return fmt.Errorf("error registrando opt-out de retención para el chat %s: %w", chatID, err)
+	}
+	return nil
+}
+
+// DeleteChatRetentionOptOut vuelve a incluir un chat en el archivado de mensajes.
+// Devuelve false si el chat no tenía opt-out.
+func DeleteChatRetentionOptOut(chatID string) (bool, error) {
+	result, err := DB.Exec(`DELETE FROM ChatRetentionOptOut WHERE ChatId = ?`, chatID)
+	if err != nil {
+		return false, fmt.Errorf("error eliminando opt-out de retención para el chat %s: %w", chatID, err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// GetChatRetentionOptOuts lista los chats excluidos del archivado.
+func GetChatRetentionOptOuts() ([]models.ChatRetentionOptOut, error) {
+	rows, err := DB.Query(`SELECT ChatId, OptedOutBy, CreatedAt FROM ChatRetentionOptOut ORDER BY CreatedAt DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo opt-outs de retención: %w", err)
+	}
+	defer rows.Close()
+
+	optOuts := []models.ChatRetentionOptOut{}
+	for rows.Next() {
+		var o models.ChatRetentionOptOut
+		if err := rows.Scan(&o.ChatId, &o.OptedOutBy, &o.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error escaneando opt-out de retención: %w", err)
+		}
+		optOuts = append(optOuts, o)
+	}
+	return optOuts, rows.Err()
+}
+
+// placeholders devuelve "?, ?, ..." con n marcadores para cláusulas IN.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
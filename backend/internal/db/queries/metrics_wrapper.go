@@ -2,47 +2,23 @@ package queries
 
 import (
 	"database/sql"
-	"time"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 )
 
-// MetricsRecorder define la interfaz para registrar métricas
-type MetricsRecorder interface {
-	RecordDatabaseQuery(duration time.Duration)
-}
-
-var metricsRecorder MetricsRecorder
-
-// SetMetricsRecorder establece el recorder de métricas
-func SetMetricsRecorder(recorder MetricsRecorder) {
-	metricsRecorder = recorder
-}
+// La duración de cada consulta la mide el driver instrumentado (ver db.SetQueryRecorder),
+// que la etiqueta con la función que la lanzó. MeasureQuery y MeasureQueryWithResult se
+// conservan para el código que ya los usa: no vuelven a registrar la consulta, y las
+// consultas hechas dentro de ellos se atribuyen a la función que los llama.
 
-// MeasureQuery es un decorador que mide el tiempo de ejecución de una consulta
+// MeasureQuery es un decorador que ejecuta una consulta
 func MeasureQuery(queryFunc func() error) error {
-	start := time.Now()
-	err := queryFunc()
-	duration := time.Since(start)
-
-	if metricsRecorder != nil {
-		metricsRecorder.RecordDatabaseQuery(duration)
-	}
-
-	return err
+	return queryFunc()
 }
 
 // MeasureQueryWithResult es un decorador para consultas que retornan un valor
 func MeasureQueryWithResult[T any](queryFunc func() (T, error)) (T, error) {
-	start := time.Now()
-	result, err := queryFunc()
-	duration := time.Since(start)
-
-	if metricsRecorder != nil {
-		metricsRecorder.RecordDatabaseQuery(duration)
-	}
-
-	return result, err
+	return queryFunc()
 }
 
 // Ejemplos de uso en funciones existentes:
@@ -0,0 +1,378 @@
+package queries
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// ErrReportClosed indica que el reporte ya no está pendiente.
+var ErrReportClosed = errors.New("el reporte ya fue resuelto o descartado")
+
+// reportColumns son las columnas que lee scanReport. reportDisplayName calcula el nombre a
+// mostrar de un usuario (razón social para empresas) a partir del alias de la tabla User.
+const (
+	reportColumns = `
+	r.Id, r.ReporterId, r.TargetType, r.TargetId, r.TargetUserId, r.Reason, COALESCE(r.Details, ''),
+	COALESCE(r.ContentSnapshot, ''), r.Status, COALESCE(r.ContentAction, ''), COALESCE(r.AuthorAction, ''),
+	COALESCE(r.ResolutionNote, ''), r.ResolvedAt, r.CreatedAt`
+	reportDisplayName = `COALESCE(NULLIF(%[1]s.CompanyName, ''), TRIM(CONCAT(COALESCE(%[1]s.FirstName, ''), ' ', COALESCE(%[1]s.LastName, ''))), '')`
+)
+
+func scanReport(row rowScanner, extra ...any) (models.Report, error) {
+	var report models.Report
+	var targetUserID sql.NullInt64
+	var resolvedAt sql.NullTime
+	dest := []any{
+		&report.Id, &report.ReporterId, &report.TargetType, &report.TargetId, &targetUserID, &report.Reason,
+		&report.Details, &report.ContentSnapshot, &report.Status, &report.ContentAction, &report.AuthorAction,
+		&report.ResolutionNote, &resolvedAt, &report.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return report, err
+	}
+	if targetUserID.Valid {
+		report.TargetUserId = &targetUserID.Int64
+	}
+	if resolvedAt.Valid {
+		report.ResolvedAt = &resolvedAt.Time
+	}
+	return report, nil
+}
+
+// GetReportedContent devuelve el autor y una copia del contenido a reportar. Un mensaje solo
+// lo puede reportar un participante de su chat. Si el contenido no existe o el usuario no
+// tiene acceso devuelve sql.ErrNoRows.
+func GetReportedContent(targetType, targetID string, reporterID int64) (*models.ReportedContent, error) {
+	var content models.ReportedContent
+	var err error
+	switch targetType {
+	case models.ReportTargetMessage:
+		err = DB.QueryRow(`
+			SELECT m.SenderId, COALESCE(m.Content, '')
+			FROM Message m
+			WHERE m.Id = ? AND (
+				EXISTS (SELECT 1 FROM Contact c WHERE c.ChatId = m.ChatId AND (c.User1Id = ? OR c.User2Id = ?))
+				OR EXISTS (
+					SELECT 1 FROM GroupMembers gm JOIN GroupsUsers g ON g.Id = gm.GroupId
+					WHERE g.ChatId = m.ChatIdGroup AND gm.UserId = ?))`,
+			targetID, reporterID, reporterID, reporterID).Scan(&content.AuthorId, &content.Snapshot)
+	case models.ReportTargetPost:
+		err = DB.QueryRow(`
+			SELECT CreatedByUserId, CONCAT_WS('\n\n', Title, Description, ContentUrl)
+			FROM CommunityEvent WHERE Id = ?`, targetID).Scan(&content.AuthorId, &content.Snapshot)
+	case models.ReportTargetComment:
+		err = DB.QueryRow(`SELECT AuthorId, Content FROM Comment WHERE Id = ? AND DeletedAt IS NULL`, targetID).
+			Scan(&content.AuthorId, &content.Snapshot)
+	case models.ReportTargetProfile:
+		err = DB.QueryRow(`
+			SELECT Id, CONCAT_WS('\n', `+fmt.Sprintf(reportDisplayName, "User")+`, UserName, Picture, Summary)
+			FROM User WHERE Id = ?`, targetID).Scan(&content.AuthorId, &content.Snapshot)
+	default:
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &content, nil
+}
+
+// InsertReport guarda un reporte pendiente y devuelve su ID. Si el usuario ya reportó el
+// mismo contenido MySQL devuelve un error de clave duplicada (1062).
+func InsertReport(reporterID int64, req models.CreateReportRequest, content models.ReportedContent) (int64, error) {
+	result, err := DB.Exec(`
+		INSERT INTO Report (ReporterId, TargetType, TargetId, TargetUserId, Reason, Details, ContentSnapshot)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), ?)`,
+		reporterID, req.TargetType, req.TargetId, content.AuthorId, req.Reason, req.Details, content.Snapshot)
+	if err != nil {
+		return 0, fmt.Errorf("error al guardar el reporte de %d sobre %s %s: %w", reporterID, req.TargetType, req.TargetId, err)
+	}
+	return result.LastInsertId()
+}
+
+// reportQueueSelect lee un reporte con los nombres del denunciante y del autor del contenido
+// y el número de reportes pendientes del mismo contenido.
+var reportQueueSelect = `
+	SELECT ` + reportColumns + `, ` + fmt.Sprintf(reportDisplayName, "rep") + `, ` + fmt.Sprintf(reportDisplayName, "tu") + `,
+	       (SELECT COUNT(*) FROM Report p WHERE p.TargetType = r.TargetType AND p.TargetId = r.TargetId AND p.Status = 'PENDIENTE')
+	FROM Report r
+	JOIN User rep ON rep.Id = r.ReporterId
+	LEFT JOIN User tu ON tu.Id = r.TargetUserId`
+
+func scanQueueReport(row rowScanner) (models.Report, error) {
+	var reporterName, targetUserName string
+	var pending int
+	report, err := scanReport(row, &reporterName, &targetUserName, &pending)
+	if err != nil {
+		return report, err
+	}
+	report.ReporterName, report.TargetUserName, report.PendingReports = reporterName, targetUserName, pending
+	return report, nil
+}
+
+// GetReportByID devuelve un reporte de la cola de moderación. Si no existe devuelve
+// sql.ErrNoRows.
+func GetReportByID(reportID int64) (*models.Report, error) {
+	report, err := scanQueueReport(DB.QueryRow(reportQueueSelect+` WHERE r.Id = ?`, reportID))
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ListReports devuelve una página de la cola de moderación, de los reportes más antiguos a
+// los más recientes, y el total. status y targetType vacíos no filtran.
+func ListReports(status, targetType string, limit, offset int) ([]models.Report, int, error) {
+	const filter = ` WHERE (? = '' OR r.Status = ?) AND (? = '' OR r.TargetType = ?)`
+	args := []any{status, status, targetType, targetType}
+
+	var total int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM Report r`+filter, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error al contar los reportes: %w", err)
+	}
+	if total == 0 {
+		return []models.Report{}, 0, nil
+	}
+
+	rows, err := DB.Query(reportQueueSelect+filter+` ORDER BY r.CreatedAt, r.Id LIMIT ? OFFSET ?`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al obtener los reportes: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []models.Report{}
+	for rows.Next() {
+		report, err := scanQueueReport(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, total, rows.Err()
+}
+
+// ListReportsByReporter devuelve una página de los reportes enviados por el usuario, del más
+// reciente al más antiguo, y el total. No incluye la copia del contenido ni el autor.
+func ListReportsByReporter(reporterID int64, limit, offset int) ([]models.Report, int, error) {
+	var total int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM Report WHERE ReporterId = ?`, reporterID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error al contar los reportes del usuario %d: %w", reporterID, err)
+	}
+	if total == 0 {
+		return []models.Report{}, 0, nil
+	}
+
+	rows, err := DB.Query(`SELECT `+reportColumns+` FROM Report r WHERE r.ReporterId = ? ORDER BY r.Id DESC LIMIT ? OFFSET ?`,
+		reporterID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al obtener los reportes del usuario %d: %w", reporterID, err)
+	}
+	defer rows.Close()
+
+	reports := []models.Report{}
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		report.TargetUserId, report.ContentSnapshot = nil, ""
+		reports = append(reports, report)
+	}
+	return reports, total, rows.Err()
+}
+
+// ModerationDecision es la resolución de un administrador sobre un reporte.
+type ModerationDecision struct {
+	ReportId      int64
+	AdminId       int64
+	Status        string // RESUELTO o DESCARTADO
+	ContentAction string
+	AuthorAction  string
+	Note          string
+}
+
+// CloseReports aplica la decisión en una transacción: oculta o elimina el contenido,
+// suspende al autor (cerrando sus sesiones) y cierra el reporte junto con los demás
+// reportes pendientes del mismo contenido. Devuelve los denunciantes de los reportes
+// cerrados. Si el reporte ya no está pendiente devuelve ErrReportClosed.
+func CloseReports(decision ModerationDecision) ([]int64, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var targetType, targetID, status string
+	var targetUserID sql.NullInt64
+	err = tx.QueryRow(`SELECT TargetType, TargetId, TargetUserId, Status FROM Report WHERE Id = ? FOR UPDATE`, decision.ReportId).
+		Scan(&targetType, &targetID, &targetUserID, &status)
+	if err != nil {
+		return nil, err
+	}
+	if status != models.ReportStatusPending {
+		return nil, ErrReportClosed
+	}
+
+	switch decision.ContentAction {
+	case models.ContentActionHide:
+		if _, err := tx.Exec(`INSERT IGNORE INTO HiddenContent (TargetType, TargetId, ReportId, HiddenBy) VALUES (?, ?, ?, ?)`,
+			targetType, targetID, decision.ReportId, decision.AdminId); err != nil {
+			return nil, fmt.Errorf("error al ocultar %s %s: %w", targetType, targetID, err)
+		}
+	case models.ContentActionDelete:
+		if err := deleteReportedContent(tx, targetType, targetID); err != nil {
+			return nil, err
+		}
+	}
+
+	if decision.AuthorAction == models.AuthorActionSuspend && targetUserID.Valid {
+		if _, err := tx.Exec(`UPDATE User SET StatusAuthorizedId = ? WHERE Id = ?`, models.UserStatusSuspended, targetUserID.Int64); err != nil {
+			return nil, fmt.Errorf("error al suspender al usuario %d: %w", targetUserID.Int64, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM Session WHERE UserId = ?`, targetUserID.Int64); err != nil {
+			return nil, fmt.Errorf("error al cerrar las sesiones del usuario %d: %w", targetUserID.Int64, err)
+		}
+	}
+
+	rows, err := tx.Query(`
+		SELECT ReporterId FROM Report
+		WHERE TargetType = ? AND TargetId = ? AND Status = 'PENDIENTE' FOR UPDATE`, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	var reporterIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		reporterIDs = append(reporterIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE Report
+		SET Status = ?, ContentAction = NULLIF(?, ''), AuthorAction = NULLIF(?, ''), ResolutionNote = NULLIF(?, ''),
+		    ResolvedBy = ?, ResolvedAt = NOW()
+		WHERE TargetType = ? AND TargetId = ? AND Status = 'PENDIENTE'`,
+		decision.Status, decision.ContentAction, decision.AuthorAction, decision.Note, decision.AdminId,
+		targetType, targetID); err != nil {
+		return nil, fmt.Errorf("error al cerrar los reportes de %s %s: %w", targetType, targetID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	// Un mensaje eliminado puede ser el último de su chat
+	if decision.ContentAction == models.ContentActionDelete && targetType == models.ReportTargetMessage {
+		InvalidateAllChatListsCache()
+	}
+	return reporterIDs, nil
+}
+
+// deleteReportedContent elimina el contenido reportado. Si ya no existe no hace nada.
+func deleteReportedContent(tx *sql.Tx, targetType, targetID string) error {
+	var err error
+	switch targetType {
+	case models.ReportTargetMessage:
+		// Puede ser el último mensaje de su chat o uno sin leer
+		if err = touchChatSync(tx, "c.ChatId = (SELECT ChatId FROM Message WHERE Id = ?)", targetID); err != nil {
+			return err
+		}
+		var chatIDs []string
+		if chatIDs, err = messageChatIDs(tx, []interface{}{targetID}); err != nil {
+			return err
+		}
+		if _, err = tx.Exec(`UPDATE Message SET ReplyToMessageId = NULL WHERE ReplyToMessageId = ?`, targetID); err == nil {
+			if err = releaseMessageMedia(tx, targetID); err == nil {
+				if _, err = tx.Exec(`DELETE FROM Message WHERE Id = ?`, targetID); err == nil {
+					err = recountChatUnreadByChatIDs(tx, chatIDs)
+				}
+			}
+		}
+	case models.ReportTargetPost:
+		_, err = tx.Exec(`DELETE FROM CommunityEvent WHERE Id = ?`, targetID)
+	case models.ReportTargetComment:
+		commentID, _ := strconv.ParseInt(targetID, 10, 64)
+		err = deleteComment(tx, commentID)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil
+		}
+	default:
+		return fmt.Errorf("no se puede eliminar un contenido de tipo %s", targetType)
+	}
+	if err != nil {
+		return fmt.Errorf("error al eliminar %s %s: %w", targetType, targetID, err)
+	}
+	_, err = tx.Exec(`DELETE FROM HiddenContent WHERE TargetType = ? AND TargetId = ?`, targetType, targetID)
+	return err
+}
+
+// UnhideContent vuelve a mostrar un contenido ocultado. Si no estaba oculto devuelve
+// sql.ErrNoRows.
+func UnhideContent(targetType, targetID string) error {
+	result, err := DB.Exec(`DELETE FROM HiddenContent WHERE TargetType = ? AND TargetId = ?`, targetType, targetID)
+	if err != nil {
+		return fmt.Errorf("error al mostrar %s %s: %w", targetType, targetID, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ReinstateUser reactiva una cuenta suspendida. Si el usuario no existe o no estaba
+// suspendido devuelve sql.ErrNoRows.
+func ReinstateUser(userID int64) error {
+	result, err := DB.Exec(`UPDATE User SET StatusAuthorizedId = ? WHERE Id = ? AND StatusAuthorizedId = ?`, models.UserStatusActive, userID, models.UserStatusSuspended)
+	if err != nil {
+		return fmt.Errorf("error al reactivar al usuario %d: %w", userID, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetModerationEvents devuelve las notificaciones de moderación (reporte resuelto,
+// advertencia, suspensión e imagen eliminada) y de gestión de cuentas (desactivación y cambio de rol) con ID
+// entre afterID (excluido) y upToID (incluido).
+func GetModerationEvents(afterID, upToID int64) ([]models.Event, error) {
+	rows, err := DB.Query(`
+		SELECT Id, EventType, EventTitle, Description, UserId, OtherUserId, CreateAt, IsRead, Metadata
+		FROM Event
+		WHERE Id > ? AND Id <= ? AND EventType IN (?, ?, ?, ?, ?, ?)
+		ORDER BY Id`, afterID, upToID,
+		models.EventTypeReportResolved, models.EventTypeModerationWarning, models.EventTypeModerationSuspended,
+		models.EventTypeImageRemoved, models.EventTypeAccountDeactivated, models.EventTypeRoleChanged)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las notificaciones de moderación: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.Event
+	for rows.Next() {
+		var event models.Event
+		var metadata []byte
+		if err := rows.Scan(&event.Id, &event.EventType, &event.EventTitle, &event.Description, &event.UserId,
+			&event.OtherUserId, &event.CreateAt, &event.IsRead, &metadata); err != nil {
+			return nil, err
+		}
+		event.Metadata = metadata
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// GetMaxEventID devuelve el mayor ID de la tabla Event (0 si está vacía).
+func GetMaxEventID() (int64, error) {
+	var maxID int64
+	err := DB.QueryRow(`SELECT COALESCE(MAX(Id), 0) FROM Event`).Scan(&maxID)
+	return maxID, err
+}
@@ -219,7 +219,7 @@ func GetMultimedia(ctx context.Context, db *sql.DB, id, filename string) (*model
         SELECT
             Id, Type, Ratio, UserId, FileName, CreateAt, ContentId, ChatId, Size,
             ProcessingStatus, Duration, HLSManifestBaseURL, HLSManifest1080p,
-            HLSManifest720p, HLSManifest480p
+            HLSManifest720p, HLSManifest480p, RefCount
         FROM Multimedia
         WHERE Id = ? OR FileName = ?
     `
@@ -230,7 +230,7 @@ func GetMultimedia(ctx context.Context, db *sql.DB, id, filename string) (*model
 	err := row.Scan(
 		&m.Id, &m.Type, &m.Ratio, &m.UserId, &m.FileName, &m.CreateAt, &m.ContentId, &m.ChatId, &m.Size,
 		&m.ProcessingStatus, &m.Duration, &m.HLSManifestBaseURL, &m.HLSManifest1080p,
-		&m.HLSManifest720p, &m.HLSManifest480p,
+		&m.HLSManifest720p, &m.HLSManifest480p, &m.RefCount,
 	)
 
 	if err != nil {
@@ -242,3 +242,62 @@ func GetMultimedia(ctx context.Context, db *sql.DB, id, filename string) (*model
 
 	return &m, nil
 }
+
+// releaseMessageMedia descuenta de Multimedia.RefCount la referencia del mensaje messageID a
+// su adjunto, si lo tiene. Se llama antes de borrar el mensaje.
+func releaseMessageMedia(tx *sql.Tx, messageID string) error {
+	_, err := tx.Exec(`
+		UPDATE Multimedia mm JOIN Message m ON m.MediaId = mm.Id
+		SET mm.RefCount = GREATEST(mm.RefCount - 1, 0)
+		WHERE m.Id = ?`, messageID)
+	if err != nil {
+		return fmt.Errorf("error liberando el adjunto del mensaje %s: %w", messageID, err)
+	}
+	return nil
+}
+
+// recountMediaReferences recalcula Multimedia.RefCount de los archivos indicados a partir de
+// los mensajes (también archivados) que los adjuntan. Se usa tras borrados masivos de mensajes.
+func recountMediaReferences(tx *sql.Tx, mediaIDs []string) error {
+	for _, id := range mediaIDs {
+		_, err := tx.Exec(`
+			UPDATE Multimedia SET RefCount =
+				(SELECT COUNT(*) FROM Message WHERE MediaId = ?) +
+				(SELECT COUNT(*) FROM MessageArchive WHERE MediaId = ?)
+			WHERE Id = ?`, id, id, id)
+		if err != nil {
+			return fmt.Errorf("error recalculando las referencias del multimedia %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// GetAccessibleMultimedia devuelve el archivo fileName si userID lo subió o participa en un chat
+// (privado o de grupo) con algún mensaje visible, también archivado, que lo adjunta. Devuelve
+// sql.ErrNoRows si el archivo no existe o el usuario no tiene acceso.
+func GetAccessibleMultimedia(userID int64, fileName string) (*models.Multimedia, error) {
+	var m models.Multimedia
+	err := DB.QueryRow(`
+		SELECT mm.Id, mm.Type, mm.UserId, mm.FileName, mm.CreateAt, mm.Size, mm.Duration
+		FROM Multimedia mm
+		WHERE mm.FileName = ? AND (mm.UserId = ? OR EXISTS (
+			SELECT 1 FROM (
+				SELECT Id, ChatId, ChatIdGroup FROM Message WHERE MediaId = mm.Id
+				UNION ALL
+				SELECT Id, ChatId, ChatIdGroup FROM MessageArchive WHERE MediaId = mm.Id
+			) m
+			WHERE NOT EXISTS (SELECT 1 FROM HiddenContent h WHERE h.TargetType = 'MESSAGE' AND h.TargetId = m.Id)
+			  AND (EXISTS (SELECT 1 FROM Contact c WHERE c.ChatId = m.ChatId AND (c.User1Id = ? OR c.User2Id = ?))
+			       OR EXISTS (
+			           SELECT 1 FROM GroupsUsers g JOIN GroupMembers gm ON gm.GroupId = g.Id
+			           WHERE g.ChatId = m.ChatIdGroup AND gm.UserId = ?))
+		))`, fileName, userID, userID, userID, userID).
+		Scan(&m.Id, &m.Type, &m.UserId, &m.FileName, &m.CreateAt, &m.Size, &m.Duration)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error obteniendo el multimedia %s para el usuario %d: %w", fileName, userID, err)
+	}
+	return &m, nil
+}
@@ -0,0 +1,191 @@
+package queries
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/cache"
+)
+
+// networkFirstDegreeCTE son los contactos aceptados y activos del usuario, sin el contacto
+// consigo mismo del chat personal. Recibe el ID del usuario cuatro veces. Cada rama usa los
+// índices (User1Id, Status) y (User2Id, Status) de Contact.
+func networkFirstDegreeCTE() string {
+	return `
+	first AS (
+		SELECT c.User2Id AS Id FROM Contact c
+		JOIN User u ON u.Id = c.User2Id AND ` + activeStatusIs("u.StatusAuthorizedId") + `
+		WHERE c.User1Id = ? AND c.Status = 'accepted' AND c.User2Id <> ?
+		UNION
+		SELECT c.User1Id FROM Contact c
+		JOIN User u ON u.Id = c.User1Id AND ` + activeStatusIs("u.StatusAuthorizedId") + `
+		WHERE c.User2Id = ? AND c.Status = 'accepted' AND c.User1Id <> ?
+	)`
+}
+
+// networkSecondDegreeCTE añade a networkFirstDegreeCTE los contactos de sus contactos (Id) y
+// el contacto en común a través del cual se llega (Via), uniendo Contact consigo misma.
+func networkSecondDegreeCTE() string {
+	return networkFirstDegreeCTE() + `,
+	second AS (
+		SELECT c.User2Id AS Id, f.Id AS Via FROM first f
+		JOIN Contact c ON c.User1Id = f.Id AND c.Status = 'accepted'
+		UNION ALL
+		SELECT c.User1Id, f.Id FROM first f
+		JOIN Contact c ON c.User2Id = f.Id AND c.Status = 'accepted'
+	)`
+}
+
+// networkSecondDegreeFilter descarta del segundo grado al propio usuario, a quienes ya tienen
+// cualquier relación con él (contacto, solicitud pendiente o rechazada) y a las cuentas
+// inactivas o que no son de estudiantes, egresados ni empresas, así como a los usuarios con los
+// que hay un bloqueo. Recibe el ID del usuario cinco veces.
+func networkSecondDegreeFilter() string {
+	return `
+	JOIN User u ON u.Id = s.Id AND ` + activeStatusIs("u.StatusAuthorizedId") + ` AND ` + memberRoleIn("u.RoleId") + `
+	WHERE s.Id <> ?
+		AND NOT EXISTS (SELECT 1 FROM Contact x WHERE x.User1Id = ? AND x.User2Id = s.Id)
+		AND NOT EXISTS (SELECT 1 FROM Contact x WHERE x.User1Id = s.Id AND x.User2Id = ?)
+		AND ` + NotBlockedCondition("s.Id")
+}
+
+func networkCacheKey(kind string, userID int64) string {
+	return cacheGroupNetwork + ":" + kind + ":" + strconv.FormatInt(userID, 10)
+}
+
+// GetNetworkContactIDs devuelve los IDs de los contactos aceptados y activos del usuario,
+// ordenados. El resultado se cachea (CACHE_NETWORK_TTL).
+func GetNetworkContactIDs(userID int64) ([]int64, error) {
+	key := networkCacheKey("contacts", userID)
+	if ids, ok := cache.GetJSON[[]int64](queryCache, key); ok {
+		return ids, nil
+	}
+
+	rows, err := DB.Query(`WITH`+networkFirstDegreeCTE()+` SELECT Id FROM first ORDER BY Id`,
+		userID, userID, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar los contactos del usuario %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error al leer los contactos del usuario %d: %w", userID, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	cache.SetJSON(queryCache, key, ids, cacheTTL.network)
+	return ids, nil
+}
+
+// GetContactSuggestionCandidates devuelve hasta limit contactos de los contactos del usuario
+// con quienes no tiene relación, ordenados por número de contactos en común, con hasta tres de
+// esos contactos. El resultado se cachea (CACHE_NETWORK_TTL).
+func GetContactSuggestionCandidates(userID int64, limit int) ([]models.NetworkSuggestionCandidate, error) {
+	key := networkCacheKey("suggestions", userID)
+	if candidates, ok := cache.GetJSON[[]models.NetworkSuggestionCandidate](queryCache, key); ok {
+		return candidates, nil
+	}
+
+	rows, err := DB.Query(`WITH`+networkSecondDegreeCTE()+`
+		SELECT s.Id, COUNT(DISTINCT s.Via) AS Mutual,
+			SUBSTRING_INDEX(GROUP_CONCAT(DISTINCT s.Via ORDER BY s.Via), ',', 3) AS MutualIds
+		FROM second s`+networkSecondDegreeFilter()+`
+		GROUP BY s.Id
+		ORDER BY Mutual DESC, s.Id ASC
+		LIMIT ?`,
+		userID, userID, userID, userID, userID, userID, userID, userID, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error al calcular las sugerencias de contacto del usuario %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	candidates := []models.NetworkSuggestionCandidate{}
+	for rows.Next() {
+		var candidate models.NetworkSuggestionCandidate
+		var mutualIDs string
+		if err := rows.Scan(&candidate.UserId, &candidate.MutualCount, &mutualIDs); err != nil {
+			return nil, fmt.Errorf("error al leer las sugerencias de contacto: %w", err)
+		}
+		candidate.MutualIds = []int64{}
+		for _, raw := range strings.Split(mutualIDs, ",") {
+			if id, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				candidate.MutualIds = append(candidate.MutualIds, id)
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	cache.SetJSON(queryCache, key, candidates, cacheTTL.network)
+	return candidates, nil
+}
+
+// GetNetworkSummary cuenta los contactos del usuario por rol, sus solicitudes pendientes y su
+// segundo grado. El resultado se cachea (CACHE_NETWORK_TTL).
+func GetNetworkSummary(userID int64) (*models.NetworkSummary, error) {
+	key := networkCacheKey("summary", userID)
+	if summary, ok := cache.GetJSON[*models.NetworkSummary](queryCache, key); ok && summary != nil {
+		return summary, nil
+	}
+
+	summary := &models.NetworkSummary{
+		ContactsByRole: map[string]int{"student": 0, "graduate": 0, "company": 0},
+		ComputedAt:     time.Now().UTC(),
+	}
+
+	rows, err := DB.Query(`WITH`+networkFirstDegreeCTE()+`
+		SELECT u.RoleId, COUNT(*) FROM first f JOIN User u ON u.Id = f.Id GROUP BY u.RoleId`,
+		userID, userID, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error al contar los contactos del usuario %d: %w", userID, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var roleID, count int
+		if err := rows.Scan(&roleID, &count); err != nil {
+			return nil, fmt.Errorf("error al leer los contactos por rol: %w", err)
+		}
+		summary.TotalContacts += count
+		switch models.UserRole(roleID) {
+		case models.RoleStudent:
+			summary.ContactsByRole["student"] += count
+		case models.RoleEgresado:
+			summary.ContactsByRole["graduate"] += count
+		case models.RoleBusiness:
+			summary.ContactsByRole["company"] += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// En Contact, User1Id es quien envió la solicitud.
+	err = DB.QueryRow(`
+		SELECT COALESCE(SUM(User2Id = ?), 0), COALESCE(SUM(User1Id = ?), 0)
+		FROM Contact
+		WHERE (User1Id = ? OR User2Id = ?) AND Status = 'pending' AND User1Id <> User2Id`,
+		userID, userID, userID, userID).Scan(&summary.PendingReceived, &summary.PendingSent)
+	if err != nil {
+		return nil, fmt.Errorf("error al contar las solicitudes pendientes del usuario %d: %w", userID, err)
+	}
+
+	err = DB.QueryRow(`WITH`+networkSecondDegreeCTE()+`
+		SELECT COUNT(DISTINCT s.Id) FROM second s`+networkSecondDegreeFilter(),
+		userID, userID, userID, userID, userID, userID, userID, userID, userID).Scan(&summary.SecondDegree)
+	if err != nil {
+		return nil, fmt.Errorf("error al contar el segundo grado del usuario %d: %w", userID, err)
+	}
+
+	cache.SetJSON(queryCache, key, summary, cacheTTL.network)
+	return summary, nil
+}
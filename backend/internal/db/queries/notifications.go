@@ -1,7 +1,9 @@
 package queries
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -62,3 +64,226 @@ func CreateNotification(notification models.Event) (int64, error) {
 	logger.Successf("QUERY", "Notificación creada con éxito con ID %d para el usuario %d", id, notification.UserId)
 	return id, nil
 }
+
+// SetNotificationsRead marca como leídas (read=true) o no leídas las notificaciones ids del
+// usuario. Los ids de otros usuarios se ignoran. Devuelve cuántas cambiaron de estado.
+func SetNotificationsRead(userID int64, ids []int64, read bool) (int64, error) {
+	var changed int64
+	err := forEachIDChunk(uniqueIDs(ids), func(chunk []int64, chunkArgs []interface{}) error {
+		result, err := DB.Exec(`
+			UPDATE Event SET IsRead = ?
+			WHERE UserId = ? AND IsRead <> ? AND Id IN (`+placeholders(len(chunk))+`)`,
+			append([]interface{}{read, userID, read}, chunkArgs...)...)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		changed += affected
+		return err
+	})
+	if err != nil {
+		return changed, fmt.Errorf("error actualizando el estado de lectura de las notificaciones del usuario %d: %w", userID, err)
+	}
+	return changed, nil
+}
+
+// DeleteNotifications borra las notificaciones ids del usuario y sus filas derivadas en
+// Notification. Los ids de otros usuarios se ignoran. Devuelve cuántas se borraron.
+func DeleteNotifications(userID int64, ids []int64) (int64, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error iniciando transacción de borrado de notificaciones: %w", err)
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya fue confirmada
+
+	var deleted int64
+	err = forEachIDChunk(uniqueIDs(ids), func(chunk []int64, chunkArgs []interface{}) error {
+		args := append([]interface{}{userID}, chunkArgs...)
+		in := placeholders(len(chunk))
+		if _, err := tx.Exec(`
+			DELETE n FROM Notification n JOIN Event e ON n.EventId = e.Id
+			WHERE e.UserId = ? AND e.Id IN (`+in+`)`, args...); err != nil {
+			return err
+		}
+		result, err := tx.Exec(`DELETE FROM Event WHERE UserId = ? AND Id IN (`+in+`)`, args...)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		deleted += affected
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error borrando las notificaciones del usuario %d: %w", userID, err)
+	}
+	return deleted, tx.Commit()
+}
+
+// DeleteExpiredReadNotificationsBatch borra hasta limit notificaciones leídas creadas antes de
+// cutoff. Con eventType borra solo las de ese tipo; vacío, las de cualquier tipo salvo
+// excludeTypes (los que tienen su propia retención). Nunca borra las que esperan una acción
+// del usuario (ActionRequired sin ActionTakenAt). Devuelve cuántas borró: 0 indica que no
+// quedan.
+func DeleteExpiredReadNotificationsBatch(eventType string, excludeTypes []string, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		SELECT Id FROM Event
+		WHERE IsRead = TRUE AND CreateAt < ?
+		  AND NOT (ActionRequired = TRUE AND ActionTakenAt IS NULL)`
+	args := []interface{}{cutoff}
+	if eventType != "" {
+		query += " AND EventType = ?"
+		args = append(args, eventType)
+	} else if len(excludeTypes) > 0 {
+		query += " AND EventType NOT IN (" + placeholders(len(excludeTypes)) + ")"
+		for _, t := range excludeTypes {
+			args = append(args, t)
+		}
+	}
+	query += " ORDER BY CreateAt LIMIT ?"
+	args = append(args, limit)
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error iniciando transacción de expiración de notificaciones: %w", err)
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya fue confirmada
+
+	rows, err := tx.Query(query+" FOR UPDATE", args...)
+	if err != nil {
+		return 0, fmt.Errorf("error seleccionando notificaciones expiradas: %w", err)
+	}
+	var ids []interface{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error escaneando notificación expirada: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error leyendo notificaciones expiradas: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	in := placeholders(len(ids))
+	if _, err := tx.Exec(`DELETE FROM Notification WHERE EventId IN (`+in+`)`, ids...); err != nil {
+		return 0, fmt.Errorf("error borrando filas derivadas de notificaciones expiradas: %w", err)
+	}
+	result, err := tx.Exec(`DELETE FROM Event WHERE Id IN (`+in+`)`, ids...)
+	if err != nil {
+		return 0, fmt.Errorf("error borrando notificaciones expiradas: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return deleted, tx.Commit()
+}
+
+// CreateGroupedEventWithOutbox guarda la notificación agrupándola con la del mismo
+// event.GroupKey que el usuario tenga sin leer y actualizada hace menos de window: en lugar
+// de crear otra, suma el evento al grupo, añade event.OtherUserId a sus últimos usuarios y la
+// vuelve a poner como la más reciente. render calcula el título y la descripción a partir
+// del grupo resultante ("A Ana y 4 personas más..."). La notificación, nueva o agrupada, se
+// deja en el outbox para el servidor WebSocket. Sin GroupKey o con window 0 no agrupa.
+func CreateGroupedEventWithOutbox(event *models.Event, window time.Duration, render func(group models.EventGroup) (title, description string)) error {
+	var actor []int64
+	if event.OtherUserId.Valid {
+		actor = []int64{event.OtherUserId.Int64}
+	}
+	if !event.GroupKey.Valid || window <= 0 {
+		event.GroupCount = 1
+		event.GroupActors = actor
+		event.EventTitle, event.Description = render(models.EventGroup{Count: 1, ActorIds: actor})
+		return CreateEventWithOutbox(event)
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción de notificación agrupada: %w", err)
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya fue confirmada
+
+	var groupID int64
+	var groupCount int
+	var groupActors []byte
+	err = tx.QueryRow(`
+		SELECT Id, GroupCount, GroupActors FROM Event
+		WHERE UserId = ? AND GroupKey = ? AND IsRead = FALSE AND CreateAt >= ?
+		ORDER BY Id DESC
+		LIMIT 1
+		FOR UPDATE`, event.UserId, event.GroupKey.String, time.Now().UTC().Add(-window)).Scan(&groupID, &groupCount, &groupActors)
+	if errors.Is(err, sql.ErrNoRows) {
+		event.GroupCount = 1
+		event.GroupActors = actor
+		event.EventTitle, event.Description = render(models.EventGroup{Count: 1, ActorIds: actor})
+		if err := InsertEventWithOutbox(tx, event); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+	if err != nil {
+		return fmt.Errorf("error buscando la notificación agrupada %s del usuario %d: %w", event.GroupKey.String, event.UserId, err)
+	}
+
+	previous, err := unmarshalGroupActors(groupActors)
+	if err != nil {
+		return fmt.Errorf("notificación %d: %w", groupID, err)
+	}
+	group := models.EventGroup{Count: groupCount + 1, ActorIds: models.MergeEventGroupActors(actor, previous)}
+	event.Id = groupID
+	event.GroupCount = group.Count
+	event.GroupActors = group.ActorIds
+	event.CreateAt = time.Now().UTC()
+	event.EventTitle, event.Description = render(group)
+
+	encodedActors, err := marshalGroupActors(group.ActorIds)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE Event
+		SET EventTitle = ?, Description = ?, OtherUserId = ?, Metadata = ?, CreateAt = ?,
+		    GroupCount = ?, GroupActors = ?
+		WHERE Id = ?`,
+		event.EventTitle, event.Description, event.OtherUserId, event.Metadata, event.CreateAt,
+		group.Count, encodedActors, groupID); err != nil {
+		return fmt.Errorf("error actualizando la notificación agrupada %d: %w", groupID, err)
+	}
+	if err := EnqueueOutbox(tx, models.OutboxMessage{
+		Topic:   models.OutboxTopicNotification,
+		UserId:  event.UserId,
+		EventId: sql.NullInt64{Int64: groupID, Valid: true},
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// marshalGroupActors codifica la columna GroupActors; sin usuarios devuelve NULL.
+func marshalGroupActors(actors []int64) (interface{}, error) {
+	if len(actors) == 0 {
+		return nil, nil
+	}
+	raw, err := json.Marshal(actors)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar los usuarios del grupo: %w", err)
+	}
+	return raw, nil
+}
+
+// unmarshalGroupActors decodifica la columna GroupActors (NULL = sin usuarios).
+func unmarshalGroupActors(raw []byte) ([]int64, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var actors []int64
+	if err := json.Unmarshal(raw, &actors); err != nil {
+		return nil, fmt.Errorf("GroupActors inválido: %w", err)
+	}
+	return actors, nil
+}
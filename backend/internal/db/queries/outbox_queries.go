@@ -0,0 +1,157 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// Execer es lo que comparten *sql.DB y *sql.Tx. Permite escribir en el outbox con la misma
+// transacción que el cambio que origina el mensaje.
+type Execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// EnqueueOutbox guarda un mensaje en el outbox para que el servidor WebSocket lo envíe al
+// usuario si está conectado. Con una transacción, el mensaje solo existe si el cambio se confirma.
+func EnqueueOutbox(ex Execer, msg models.OutboxMessage) error {
+	var payload interface{}
+	if len(msg.Payload) > 0 {
+		payload = []byte(msg.Payload)
+	}
+	if _, err := ex.Exec(`INSERT INTO Outbox (Topic, UserId, EventId, Payload) VALUES (?, ?, ?, ?)`,
+		msg.Topic, msg.UserId, msg.EventId, payload); err != nil {
+		return fmt.Errorf("error al guardar el mensaje %s del outbox para el usuario %d: %w", msg.Topic, msg.UserId, err)
+	}
+	return nil
+}
+
+// InsertEventWithOutbox guarda la notificación y su mensaje en el outbox con ex, normalmente
+// la transacción del cambio que la origina. Actualiza el ID de la notificación.
+func InsertEventWithOutbox(ex Execer, event *models.Event) error {
+	if err := insertEvent(ex, event); err != nil {
+		return err
+	}
+	return EnqueueOutbox(ex, models.OutboxMessage{
+		Topic:   models.OutboxTopicNotification,
+		UserId:  event.UserId,
+		EventId: sql.NullInt64{Int64: event.Id, Valid: true},
+	})
+}
+
+// CreateEventWithOutbox es CreateEvent más el mensaje del outbox, en una transacción: para las
+// notificaciones que se crean fuera del servidor WebSocket y deben llegar en tiempo real.
+func CreateEventWithOutbox(event *models.Event) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := InsertEventWithOutbox(tx, event); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetOutboxMessages devuelve hasta limit mensajes con ID mayor que afterID, en orden, con la
+// notificación de los que la tienen.
+func GetOutboxMessages(afterID int64, limit int) ([]models.OutboxMessage, error) {
+	rows, err := DB.Query(`
+		SELECT o.Id, o.Topic, o.UserId, o.EventId, o.Payload, o.CreatedAt, o.DeliveredAt,
+			e.Id, e.EventType, e.EventTitle, e.Description, e.OtherUserId, e.CreateAt, e.IsRead, e.Metadata,
+			e.GroupKey, e.GroupCount, e.GroupActors
+		FROM Outbox o
+		LEFT JOIN Event e ON e.Id = o.EventId
+		WHERE o.Id > ?
+		ORDER BY o.Id
+		LIMIT ?`, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el outbox desde el ID %d: %w", afterID, err)
+	}
+	defer rows.Close()
+
+	var messages []models.OutboxMessage
+	for rows.Next() {
+		var msg models.OutboxMessage
+		var payload, metadata, groupActors []byte
+		var eventID sql.NullInt64
+		var eventType, eventTitle, description sql.NullString
+		var otherUserID sql.NullInt64
+		var createAt sql.NullTime
+		var isRead sql.NullBool
+		var groupKey sql.NullString
+		var groupCount sql.NullInt64
+		if err := rows.Scan(&msg.Id, &msg.Topic, &msg.UserId, &msg.EventId, &payload, &msg.CreatedAt, &msg.DeliveredAt,
+			&eventID, &eventType, &eventTitle, &description, &otherUserID, &createAt, &isRead, &metadata,
+			&groupKey, &groupCount, &groupActors); err != nil {
+			return nil, err
+		}
+		msg.Payload = payload
+		if eventID.Valid {
+			msg.Event = &models.Event{
+				Id:          eventID.Int64,
+				EventType:   eventType.String,
+				EventTitle:  eventTitle.String,
+				Description: description.String,
+				UserId:      msg.UserId,
+				OtherUserId: otherUserID,
+				CreateAt:    createAt.Time,
+				IsRead:      isRead.Bool,
+				Metadata:    metadata,
+				GroupKey:    groupKey,
+				GroupCount:  int(groupCount.Int64),
+			}
+			actors, err := unmarshalGroupActors(groupActors)
+			if err != nil {
+				return nil, fmt.Errorf("notificación %d: %w", eventID.Int64, err)
+			}
+			msg.Event.GroupActors = actors
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// GetOutboxStart devuelve desde dónde empieza a leer un servidor que arranca: startID queda
+// justo antes del mensaje sin entregar más antiguo de los creados en el último replayWindow (o
+// es el último ID si no hay ninguno), y lastID es el último ID del outbox en ese momento.
+func GetOutboxStart(replayWindow time.Duration) (startID, lastID int64, err error) {
+	err = DB.QueryRow(`
+		SELECT
+			COALESCE((SELECT MIN(Id) - 1 FROM Outbox
+				WHERE DeliveredAt IS NULL AND CreatedAt >= NOW() - INTERVAL ? SECOND), MAX(Id), 0),
+			COALESCE(MAX(Id), 0)
+		FROM Outbox`, int64(replayWindow.Seconds())).Scan(&startID, &lastID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error al obtener el punto de partida del outbox: %w", err)
+	}
+	return startID, lastID, nil
+}
+
+// MarkOutboxDelivered anota la entrega de los mensajes. Si otro servidor ya los entregó se
+// conserva la primera fecha.
+func MarkOutboxDelivered(ids []int64) error {
+	err := forEachIDChunk(uniqueIDs(ids), func(chunk []int64, args []interface{}) error {
+		_, err := DB.Exec(`
+			UPDATE Outbox SET DeliveredAt = NOW()
+			WHERE DeliveredAt IS NULL AND Id IN (`+placeholders(len(chunk))+`)`, args...)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error al marcar como entregados los mensajes del outbox: %w", err)
+	}
+	return nil
+}
+
+// DeleteOldOutbox borra los mensajes con más de retention, entregados o no. Las fechas del
+// outbox las pone la base de datos, así que se comparan con su reloj. Devuelve cuántos borró.
+func DeleteOldOutbox(retention time.Duration) (int64, error) {
+	result, err := DB.Exec(`DELETE FROM Outbox WHERE CreatedAt < NOW() - INTERVAL ? SECOND`, int64(retention.Seconds()))
+	if err != nil {
+		return 0, fmt.Errorf("error al limpiar el outbox: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,153 @@
+package queries
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// presenceTouchBatchSize limita el número de usuarios por sentencia del heartbeat.
+const presenceTouchBatchSize = 500
+
+// TouchOnlineUsers registra el heartbeat de los usuarios conectados a esta instancia:
+// actualiza LastSeenAt y los vuelve a marcar online por si el reaper los hubiera pasado a
+// offline durante una caída de la BD.
+func TouchOnlineUsers(userIDs []int64, now time.Time) error {
+	for start := 0; start < len(userIDs); start += presenceTouchBatchSize {
+		end := min(start+presenceTouchBatchSize, len(userIDs))
+		batch := userIDs[start:end]
+
+		args := make([]interface{}, 0, len(batch)*3)
+		for _, userID := range batch {
+			args = append(args, userID, now, now)
+		}
+		_, err := DB.Exec(`INSERT INTO Online (UserOnlineId, CreateAt, Status, LastSeenAt) VALUES (?, ?, 1, ?)`+
+			strings.Repeat(", (?, ?, 1, ?)", len(batch)-1)+`
+			ON DUPLICATE KEY UPDATE Status = 1, LastSeenAt = VALUES(LastSeenAt)`, args...)
+		if err != nil {
+			return fmt.Errorf("error actualizando el heartbeat de %d usuarios: %w", len(batch), err)
+		}
+	}
+	return nil
+}
+
+// MarkStaleUsersOffline pasa a offline a los usuarios marcados online cuyo último heartbeat
+// es anterior a staleBefore (o que nunca lo registraron) y devuelve sus IDs. LastSeenAt se
+// conserva como última vez visto.
+func MarkStaleUsersOffline(staleBefore time.Time) ([]int64, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción del reaper de presencia: %w", err)
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya fue confirmada
+
+	rows, err := tx.Query(`
+		SELECT UserOnlineId FROM Online
+		WHERE Status = 1 AND (LastSeenAt IS NULL OR LastSeenAt < ?)
+		FOR UPDATE`, staleBefore)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando presencias caducadas: %w", err)
+	}
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, len(userIDs))
+	for i, userID := range userIDs {
+		args[i] = userID
+	}
+	if _, err := tx.Exec(`UPDATE Online SET Status = 0 WHERE UserOnlineId IN (?`+
+		strings.Repeat(", ?", len(userIDs)-1)+`)`, args...); err != nil {
+		return nil, fmt.Errorf("error marcando offline %d presencias caducadas: %w", len(userIDs), err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error confirmando el reaper de presencia: %w", err)
+	}
+	return userIDs, nil
+}
+
+// GetOnlineStatusByIDs obtiene el estado online de varios usuarios desde la tabla Online en
+// lugar de llamar a GetUserOnlineStatus por cada uno. Todos los IDs aparecen en el mapa; los
+// que no tienen registro se consideran offline.
+func GetOnlineStatusByIDs(userIDs []int64) (map[int64]bool, error) {
+	ids := uniqueIDs(userIDs)
+	statuses := make(map[int64]bool, len(ids))
+	for _, userID := range ids {
+		statuses[userID] = false
+	}
+
+	err := forEachIDChunk(ids, func(chunk []int64, args []interface{}) error {
+		rows, err := DB.Query(`SELECT UserOnlineId FROM Online WHERE Status = 1 AND UserOnlineId IN (`+placeholders(len(chunk))+`)`, args...)
+		if err != nil {
+			return fmt.Errorf("error obteniendo estado online de %d usuarios: %w", len(chunk), err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var userID int64
+			if err := rows.Scan(&userID); err != nil {
+				return fmt.Errorf("error escaneando estado online: %w", err)
+			}
+			statuses[userID] = true
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// LastSeenRecord es la última conexión registrada de un usuario y quién puede verla.
+type LastSeenRecord struct {
+	LastSeenAt time.Time
+	Visibility models.LastSeenVisibility
+}
+
+// GetLastSeenByIDs devuelve la última conexión (Online.LastSeenAt: desconexión o último
+// heartbeat) de varios usuarios junto con su preferencia de visibilidad. Los usuarios que
+// nunca se conectaron no aparecen en el mapa.
+func GetLastSeenByIDs(userIDs []int64) (map[int64]LastSeenRecord, error) {
+	records := make(map[int64]LastSeenRecord)
+	err := forEachIDChunk(uniqueIDs(userIDs), func(chunk []int64, args []interface{}) error {
+		rows, err := DB.Query(`
+			SELECT o.UserOnlineId, o.LastSeenAt, COALESCE(p.LastSeenVisibility, ?)
+			FROM Online o
+			LEFT JOIN UserPrivacySettings p ON p.UserId = o.UserOnlineId
+			WHERE o.LastSeenAt IS NOT NULL AND o.UserOnlineId IN (`+placeholders(len(chunk))+`)`,
+			append([]interface{}{models.DefaultPrivacySettings().LastSeenVisibility}, args...)...)
+		if err != nil {
+			return fmt.Errorf("error obteniendo la última conexión de %d usuarios: %w", len(chunk), err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var userID int64
+			var record LastSeenRecord
+			if err := rows.Scan(&userID, &record.LastSeenAt, &record.Visibility); err != nil {
+				return fmt.Errorf("error escaneando la última conexión: %w", err)
+			}
+			records[userID] = record
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
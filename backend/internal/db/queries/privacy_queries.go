@@ -0,0 +1,507 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+const privacyRequestColumns = `Id, UserId, RequestType, DeletionMode, Status, Email, FilePath, ExpiresAt, ErrorMessage, RequestedAt, CompletedAt`
+
+func scanPrivacyRequest(scanner interface{ Scan(...interface{}) error }) (*models.PrivacyRequest, error) {
+	var req models.PrivacyRequest
+	var mode, email, filePath, errMsg sql.NullString
+	var expiresAt, completedAt sql.NullTime
+	if err := scanner.Scan(&req.Id, &req.UserId, &req.Type, &mode, &req.Status, &email, &filePath, &expiresAt, &errMsg, &req.RequestedAt, &completedAt); err != nil {
+		return nil, err
+	}
+	req.DeletionMode = mode.String
+	req.Email = email.String
+	req.FilePath = filePath.String
+	req.Error = errMsg.String
+	if expiresAt.Valid {
+		req.ExpiresAt = &expiresAt.Time
+	}
+	if completedAt.Valid {
+		req.CompletedAt = &completedAt.Time
+	}
+	return &req, nil
+}
+
+// CreatePrivacyRequest registra una solicitud pendiente si el usuario no tiene otra del mismo
+// tipo en curso. Devuelve el ID y false si ya había una activa.
+func CreatePrivacyRequest(userID int64, requestType, deletionMode, email string) (int64, bool, error) {
+	result, err := DB.Exec(`
+		INSERT INTO PrivacyRequest (UserId, RequestType, DeletionMode, Email)
+		SELECT ?, ?, NULLIF(?, ''), ? FROM DUAL
+		WHERE NOT EXISTS (
+			SELECT 1 FROM PrivacyRequest
+			WHERE UserId = ? AND RequestType = ? AND Status IN ('pending', 'processing')
+		)`,
+		userID, requestType, deletionMode, email, userID, requestType,
+	)
+	if err != nil {
+		return 0, false, fmt.Errorf("error registrando solicitud de privacidad: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return 0, false, nil
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, false, fmt.Errorf("error obteniendo ID de la solicitud de privacidad: %w", err)
+	}
+	return id, true, nil
+}
+
+// GetPrivacyRequest obtiene una solicitud por ID. Devuelve sql.ErrNoRows si no existe.
+func GetPrivacyRequest(id int64) (*models.PrivacyRequest, error) {
+	req, err := scanPrivacyRequest(DB.QueryRow(`SELECT `+privacyRequestColumns+` FROM PrivacyRequest WHERE Id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error obteniendo solicitud de privacidad %d: %w", id, err)
+	}
+	return req, nil
+}
+
+// GetPrivacyRequestsByUser lista las solicitudes de un usuario, de la más reciente a la más antigua.
+func GetPrivacyRequestsByUser(userID int64) ([]models.PrivacyRequest, error) {
+	return queryPrivacyRequests(`SELECT `+privacyRequestColumns+` FROM PrivacyRequest WHERE UserId = ? ORDER BY Id DESC`, userID)
+}
+
+// GetUnfinishedPrivacyRequests lista las solicitudes pendientes o interrumpidas (p.ej. por un reinicio).
+func GetUnfinishedPrivacyRequests() ([]models.PrivacyRequest, error) {
+	return queryPrivacyRequests(`SELECT ` + privacyRequestColumns + ` FROM PrivacyRequest WHERE Status IN ('pending', 'processing') ORDER BY Id`)
+}
+
+// GetExpiredDataExports lista las exportaciones completadas cuyo ZIP ya caducó.
+func GetExpiredDataExports(now time.Time) ([]models.PrivacyRequest, error) {
+	return queryPrivacyRequests(`
+		SELECT `+privacyRequestColumns+` FROM PrivacyRequest
+		WHERE RequestType = 'export' AND FilePath IS NOT NULL AND ExpiresAt < ?`, now)
+}
+
+func queryPrivacyRequests(query string, args ...interface{}) ([]models.PrivacyRequest, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo solicitudes de privacidad: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []models.PrivacyRequest{}
+	for rows.Next() {
+		req, err := scanPrivacyRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error escaneando solicitud de privacidad: %w", err)
+		}
+		requests = append(requests, *req)
+	}
+	return requests, rows.Err()
+}
+
+// stalePrivacyRequestAfter es el tiempo tras el cual una solicitud 'processing' se considera
+// abandonada (p.ej. la instancia que la procesaba se reinició) y puede reclamarse de nuevo.
+const stalePrivacyRequestAfter = time.Hour
+
+// ClaimPrivacyRequest pasa una solicitud a 'processing' si está pendiente o abandonada.
+// Devuelve false si otra instancia ya la está procesando o ya terminó.
+func ClaimPrivacyRequest(id int64) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE PrivacyRequest SET Status = 'processing'
+		WHERE Id = ? AND (Status = 'pending' OR (Status = 'processing' AND RequestedAt < ?))`,
+		id, time.Now().Add(-stalePrivacyRequestAfter))
+	if err != nil {
+		return false, fmt.Errorf("error reclamando solicitud de privacidad %d: %w", id, err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// CompleteDataExport marca una exportación como completada con la ruta del ZIP y su caducidad.
+func CompleteDataExport(id int64, filePath string, expiresAt time.Time) error {
+	_, err := DB.Exec(`
+		UPDATE PrivacyRequest SET Status = 'completed', FilePath = ?, ExpiresAt = ?, CompletedAt = NOW(), ErrorMessage = NULL
+		WHERE Id = ?`, filePath, expiresAt, id)
+	if err != nil {
+		return fmt.Errorf("error completando exportación %d: %w", id, err)
+	}
+	return nil
+}
+
+// CompleteAccountDeletion marca un borrado como completado y olvida el email de contacto.
+func CompleteAccountDeletion(id int64) error {
+	_, err := DB.Exec(`
+		UPDATE PrivacyRequest SET Status = 'completed', Email = NULL, CompletedAt = NOW(), ErrorMessage = NULL
+		WHERE Id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("error completando borrado de cuenta %d: %w", id, err)
+	}
+	return nil
+}
+
+// FailPrivacyRequest marca una solicitud como fallida.
+func FailPrivacyRequest(id int64, errMsg string) error {
+	if _, err := DB.Exec(`UPDATE PrivacyRequest SET Status = 'failed', ErrorMessage = ?, CompletedAt = NOW() WHERE Id = ?`, errMsg, id); err != nil {
+		return fmt.Errorf("error marcando como fallida la solicitud de privacidad %d: %w", id, err)
+	}
+	return nil
+}
+
+// ClearDataExportFile olvida la ruta de un ZIP de exportación ya eliminado.
+func ClearDataExportFile(id int64) error {
+	if _, err := DB.Exec(`UPDATE PrivacyRequest SET FilePath = NULL WHERE Id = ?`, id); err != nil {
+		return fmt.Errorf("error limpiando el archivo de la exportación %d: %w", id, err)
+	}
+	return nil
+}
+
+// GetUserEmailAndPasswordHash devuelve el email y el hash de la contraseña de un usuario.
+func GetUserEmailAndPasswordHash(userID int64) (string, string, error) {
+	var email string
+	var hash sql.NullString
+	if err := DB.QueryRow(`SELECT Email, Password FROM User WHERE Id = ?`, userID).Scan(&email, &hash); err != nil {
+		return "", "", err
+	}
+	return email, hash.String, nil
+}
+
+// userDataExportSections define los archivos JSON de la exportación y la consulta de cada uno.
+// Todas las consultas reciben el ID del usuario en cada marcador.
+var userDataExportSections = []struct {
+	file  string
+	query string
+}{
+	{"profile.json", `
+		SELECT Id, FirstName, LastName, UserName, Email, ContactEmail, Twitter, Facebook, Phone, Sex, DocId,
+		       NationalityId, Birthdate, Picture, RoleId, StatusAuthorizedId, Summary, Address, Github, Linkedin,
+		       RIF, Sector, CompanyName, Location, FoundationYear, EmployeeCount, CreatedAt, UpdatedAt
+		FROM User WHERE Id = ?`},
+	{"education.json", `SELECT Id, Institution, Degree, Campus, GraduationDate, CountryId, IsCurrentlyStudying FROM Education WHERE PersonId = ?`},
+	{"work_experience.json", `SELECT Id, Company, Position, StartDate, EndDate, Description, CountryId, IsCurrentJob FROM WorkExperience WHERE PersonId = ?`},
+	{"certifications.json", `SELECT Id, Certification, Institution, DateObtained FROM Certifications WHERE PersonId = ?`},
+	{"skills.json", `SELECT Id, Skill, Level FROM Skills WHERE PersonId = ?`},
+	{"languages.json", `SELECT Id, Language, Level FROM Languages WHERE PersonId = ?`},
+	{"projects.json", `
+		SELECT Id, Title, Role, Description, Company, Document, ProjectStatus, StartDate, ExpectedEndDate, IsOngoing
+		FROM Project WHERE PersonID = ?`},
+	{"contacts.json", `
+		SELECT ContactId, IF(User1Id = ?, User2Id, User1Id) AS OtherUserId, Status, ChatId
+		FROM Contact WHERE User1Id = ? OR User2Id = ?`},
+	{"messages.json", `
+		SELECT Id, ChatId, ChatIdGroup, TypeMessageId, Content, MediaId, ReplyToMessageId, SentAt, EditedAt, Status
+		FROM Message WHERE SenderId = ? ORDER BY SentAt`},
+	{"archived_messages.json", `
+		SELECT Id, ChatId, ChatIdGroup, TypeMessageId, Content, MediaId, ReplyToMessageId, SentAt, EditedAt, Status, ArchivedAt
+		FROM MessageArchive WHERE SenderId = ? ORDER BY SentAt`},
+	{"media.json", `SELECT Id, Type, FileName, ContentId, ChatId, Size, Duration, CreateAt FROM Multimedia WHERE UserId = ?`},
+	{"privacy_settings.json", `SELECT ShareProfileViews, TrackProfileViews, LastSeenVisibility, UpdatedAt FROM UserPrivacySettings WHERE UserId = ?`},
+	{"notification_preferences.json", `
+		SELECT PushEnabled, ChatMessages, ChatPreviews, ContactRequests, Community, Challenges, JobApplications, SavedSearches, EmailDigest,
+			DndEnabled, DndStart, DndEnd, DndTimezone, UpdatedAt
+		FROM NotificationPreferences WHERE UserId = ?`},
+	{"student_verification.json", `SELECT Email, UniversityId, VerifiedAt, PendingEmail, CreatedAt FROM StudentVerification WHERE UserId = ?`},
+	{"push_devices.json", `SELECT Id, Provider, DeviceName, CreatedAt, UpdatedAt FROM PushDevice WHERE UserId = ?`},
+	{"web_push_subscriptions.json", `SELECT Id, UserAgent, CreatedAt, UpdatedAt FROM WebPushSubscription WHERE UserId = ?`},
+	// Las visitas anónimas a su perfil no revelan al visitante.
+	{"profile_views_received.json", `
+		SELECT IF(IsAnonymous, NULL, ViewerId) AS ViewerId, ViewerRoleId, ViewedAt
+		FROM ProfileView WHERE ProfileUserId = ? ORDER BY ViewedAt`},
+	{"profile_views_made.json", `SELECT ProfileUserId, IsAnonymous, ViewedAt FROM ProfileView WHERE ViewerId = ? ORDER BY ViewedAt`},
+	{"comments.json", `
+		SELECT Id, CommunityEventId, ParentCommentId, Content, CreatedAt, EditedAt
+		FROM Comment WHERE AuthorId = ? AND DeletedAt IS NULL ORDER BY CreatedAt`},
+	{"likes.json", `SELECT CommunityEventId, CreatedAt FROM CommunityEventLike WHERE UserId = ? ORDER BY CreatedAt`},
+	{"saved_items.json", `SELECT CommunityEventId, CreatedAt FROM CommunityEventBookmark WHERE UserId = ? ORDER BY CreatedAt`},
+	{"saved_searches.json", `
+		SELECT Id, Name, SearchType, Query, Filters, AlertsEnabled, LastAlertAt, CreatedAt, UpdatedAt
+		FROM SavedSearch WHERE UserId = ? ORDER BY Id`},
+	// Solo los bloqueos que hizo el usuario; los que recibió no se revelan.
+	{"user_blocks.json", `SELECT BlockedId, CreatedAt FROM UserBlock WHERE BlockerId = ? ORDER BY CreatedAt`},
+	{"notifications.json", `
+		SELECT Id, EventType, EventTitle, Description, OtherUserId, IsRead, Status, Metadata, CreateAt
+		FROM Event WHERE UserId = ? ORDER BY CreateAt`},
+	{"community_events.json", `
+		SELECT Id, PostType, Title, Description, EventDate, Location, Capacity, Price, Tags, CreatedAt, UpdatedAt
+		FROM CommunityEvent WHERE CreatedByUserId = ?`},
+	{"job_applications.json", `SELECT Id, CommunityEventId, Status, AppliedAt, UpdatedAt, CoverLetter FROM JobApplication WHERE ApplicantId = ?`},
+	{"challenge_submissions.json", `
+		SELECT Id, CommunityEventId, RepositoryUrl, FileContentId, Description, Status, Score, Feedback,
+		       ReviewedAt, PointsRP, SubmittedAt, UpdatedAt
+		FROM ChallengeSubmission WHERE UserId = ? ORDER BY SubmittedAt`},
+	// Solo los reportes enviados; los recibidos y la copia del contenido son de moderación.
+	{"reports.json", `
+		SELECT Id, TargetType, TargetId, Reason, Details, Status, ResolvedAt, CreatedAt
+		FROM Report WHERE ReporterId = ? ORDER BY CreatedAt`},
+	{"reviews_given.json", `SELECT * FROM ReputationReview WHERE ReviewerId = ?`},
+	{"reviews_received.json", `SELECT * FROM ReputationReview WHERE RevieweeId = ?`},
+	{"sessions.json", `SELECT Id, Ip, RoleId, UserAgent, DeviceType, Browser, OS, Country, City, CreatedAt FROM Session WHERE UserId = ?`},
+	{"audit_log.json", `
+		SELECT Id, Action, TargetType, TargetId, IPAddress, Metadata, CreatedAt
+		FROM AuditLog WHERE ActorId = ? OR (TargetType = 'user' AND TargetId = CAST(? AS CHAR)) ORDER BY CreatedAt`},
+}
+
+// GetUserDataExport recopila todos los datos personales de un usuario, agrupados por
+// nombre de archivo de la exportación.
+func GetUserDataExport(userID int64) (map[string][]map[string]interface{}, error) {
+	export := make(map[string][]map[string]interface{}, len(userDataExportSections))
+	for _, section := range userDataExportSections {
+		args := make([]interface{}, countPlaceholders(section.query))
+		for i := range args {
+			args[i] = userID
+		}
+		rows, err := queryRowsAsMaps(section.query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("error exportando %s: %w", section.file, err)
+		}
+		export[section.file] = rows
+	}
+	return export, nil
+}
+
+func countPlaceholders(query string) int {
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+		}
+	}
+	return n
+}
+
+// queryRowsAsMaps ejecuta una consulta y devuelve cada fila como un mapa columna → valor.
+func queryRowsAsMaps(query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []map[string]interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				// Las columnas cifradas (DocId, Phone, RIF) se exportan descifradas
+				value, err := decryptField(string(b))
+				if err != nil {
+					return nil, fmt.Errorf("columna %s: %w", column, err)
+				}
+				row[column] = value
+			} else {
+				row[column] = values[i]
+			}
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// AnonymizeUser elimina los datos personales de un usuario conservando sus mensajes y
+// publicaciones, que pasan a mostrarse como de un usuario eliminado. La cuenta queda
+// desactivada, con lo que AuthMiddleware rechaza los tokens ya emitidos, y el evento
+// ACCOUNT_DEACTIVATED hace que el servidor WebSocket cierre sus conexiones.
+func AnonymizeUser(userID int64) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción de anonimización: %w", err)
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya fue confirmada
+
+	if err := deletePersonalData(tx, userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE User SET
+			FirstName = 'Usuario', LastName = 'eliminado',
+			UserName = CONCAT('deleted-', Id), Password = '', StatusAuthorizedId = ?,
+			Email = CONCAT('deleted-', Id, '@deleted.invalid'),
+			ContactEmail = NULL, Twitter = NULL, Facebook = NULL, Phone = NULL, Sex = NULL, DocId = NULL, DocIdHash = NULL,
+			NationalityId = NULL, Birthdate = NULL, Picture = NULL, Summary = NULL, Address = NULL,
+			Github = NULL, Linkedin = NULL, RIF = NULL, RIFHash = NULL, Sector = NULL, CompanyName = NULL, Location = NULL,
+			FoundationYear = NULL, EmployeeCount = NULL,
+			dmeta_person_primary = '', dmeta_person_secondary = '',
+			dmeta_company_primary = '', dmeta_company_secondary = ''
+		WHERE Id = ?`, models.UserStatusDeactivated, userID); err != nil {
+		return fmt.Errorf("error anonimizando el usuario %d: %w", userID, err)
+	}
+
+	// deletePersonalData ya borró sus notificaciones; esta es la que desconecta al usuario.
+	if err := insertEvent(tx, &models.Event{
+		EventType:   models.EventTypeAccountDeactivated,
+		EventTitle:  "Tu cuenta fue eliminada",
+		Description: "Se completó la eliminación de tu cuenta y de tus datos personales.",
+		UserId:      userID,
+	}); err != nil {
+		return fmt.Errorf("error registrando la desactivación del usuario %d: %w", userID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando anonimización del usuario %d: %w", userID, err)
+	}
+	InvalidateUserCache(userID)
+	return nil
+}
+
+// DeleteUserCascade elimina la cuenta de un usuario junto con sus mensajes, contactos,
+// datos del CV, notificaciones y publicaciones.
+func DeleteUserCascade(userID int64) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción de borrado: %w", err)
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya fue confirmada
+
+	if err := deletePersonalData(tx, userID); err != nil {
+		return err
+	}
+
+	// Adjuntos de los mensajes que se van a borrar: al final se recalcula su RefCount, porque
+	// pueden seguir referenciados por reenvíos en otros chats.
+	mediaIDs, err := cascadeMessageMediaIDs(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Sus chats desaparecen de la lista de sus contactos
+	if err := recordChatTombstones(tx, userID); err != nil {
+		return err
+	}
+
+	statements := []struct {
+		desc  string
+		query string
+	}{
+		// Mensajes: los del usuario y todos los de sus chats privados, que desaparecen con el contacto.
+		{"desvincular respuestas a mensajes eliminados", `
+			UPDATE Message m
+			JOIN Message target ON m.ReplyToMessageId = target.Id
+			LEFT JOIN Contact c ON target.ChatId = c.ChatId
+			SET m.ReplyToMessageId = NULL
+			WHERE target.SenderId = ? OR c.User1Id = ? OR c.User2Id = ?`},
+		{"eliminar mensajes de chats privados", `
+			DELETE m FROM Message m JOIN Contact c ON m.ChatId = c.ChatId
+			WHERE c.User1Id = ? OR c.User2Id = ?`},
+		{"eliminar mensajes en grupos", `DELETE FROM Message WHERE SenderId = ?`},
+		{"eliminar mensajes archivados", `DELETE FROM MessageArchive WHERE SenderId = ?`},
+		{"eliminar opt-outs de retención de sus chats", `
+			DELETE o FROM ChatRetentionOptOut o JOIN Contact c ON o.ChatId = c.ChatId
+			WHERE c.User1Id = ? OR c.User2Id = ?`},
+		{"eliminar opt-outs de retención registrados", `DELETE FROM ChatRetentionOptOut WHERE OptedOutBy = ?`},
+		{"eliminar contadores de no leídos de sus chats", `
+			DELETE u FROM ChatUnread u JOIN Contact c ON u.ChatId = c.ChatId
+			WHERE c.User1Id = ? OR c.User2Id = ?`},
+		{"eliminar contactos", `DELETE FROM Contact WHERE User1Id = ? OR User2Id = ?`},
+		{"eliminar membresías de grupos", `DELETE FROM GroupMembers WHERE UserId = ?`},
+		{"desvincular grupos administrados", `UPDATE GroupsUsers SET AdminOfGroup = NULL WHERE AdminOfGroup = ?`},
+		{"eliminar multimedia no referenciada", `
+			DELETE FROM Multimedia WHERE UserId = ?
+			AND Id NOT IN (SELECT MediaId FROM (SELECT MediaId FROM Message WHERE MediaId IS NOT NULL) referenced)`},
+		// CommunityEvent, Comment (con las respuestas a sus comentarios), ReputationReview,
+		// JobApplication, ChallengeSubmission, los reportes enviados y el registro del filtro
+		// de contenido se eliminan en cascada.
+		{"eliminar usuario", `DELETE FROM User WHERE Id = ?`},
+	}
+	for _, stmt := range statements {
+		args := make([]interface{}, countPlaceholders(stmt.query))
+		for i := range args {
+			args[i] = userID
+		}
+		if _, err := tx.Exec(stmt.query, args...); err != nil {
+			return fmt.Errorf("error al %s del usuario %d: %w", stmt.desc, userID, err)
+		}
+	}
+	if err := recountMediaReferences(tx, mediaIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando borrado del usuario %d: %w", userID, err)
+	}
+	InvalidateUserCache(userID)
+	return nil
+}
+
+// cascadeMessageMediaIDs devuelve los adjuntos de los mensajes que elimina DeleteUserCascade.
+func cascadeMessageMediaIDs(tx *sql.Tx, userID int64) ([]string, error) {
+	rows, err := tx.Query(`
+		SELECT m.MediaId FROM Message m LEFT JOIN Contact c ON m.ChatId = c.ChatId
+		WHERE m.MediaId IS NOT NULL AND (m.SenderId = ? OR c.User1Id = ? OR c.User2Id = ?)
+		UNION
+		SELECT MediaId FROM MessageArchive WHERE MediaId IS NOT NULL AND SenderId = ?`, userID, userID, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo los adjuntos de los mensajes del usuario %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error leyendo los adjuntos de los mensajes del usuario %d: %w", userID, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// deletePersonalData elimina los datos comunes a ambos modos de borrado: CV, sesiones,
+// notificaciones, presencia y códigos de recuperación.
+func deletePersonalData(tx *sql.Tx, userID int64) error {
+	statements := []struct {
+		desc  string
+		query string
+	}{
+		{"eliminar notificaciones derivadas", `DELETE n FROM Notification n JOIN Event e ON n.EventId = e.Id WHERE e.UserId = ?`},
+		{"eliminar notificaciones", `DELETE FROM Event WHERE UserId = ?`},
+		{"desvincular notificaciones de terceros", `UPDATE Event SET OtherUserId = NULL WHERE OtherUserId = ?`},
+		{"desvincular proyectos de notificaciones", `
+			UPDATE Event SET ProyectId = NULL
+			WHERE ProyectId IN (SELECT Id FROM (SELECT Id FROM Project WHERE PersonID = ?) p)`},
+		{"eliminar educación", `DELETE FROM Education WHERE PersonId = ?`},
+		{"eliminar experiencia laboral", `DELETE FROM WorkExperience WHERE PersonId = ?`},
+		{"eliminar certificaciones", `DELETE FROM Certifications WHERE PersonId = ?`},
+		{"eliminar habilidades", `DELETE FROM Skills WHERE PersonId = ?`},
+		{"eliminar idiomas", `DELETE FROM Languages WHERE PersonId = ?`},
+		{"eliminar proyectos", `DELETE FROM Project WHERE PersonID = ?`},
+		{"eliminar vistas del feed", `DELETE FROM FeedItemView WHERE UserId = ?`},
+		{"eliminar visitas a su perfil", `DELETE FROM ProfileView WHERE ProfileUserId = ?`},
+		{"eliminar visitas a otros perfiles", `DELETE FROM ProfileView WHERE ViewerId = ?`},
+		{"eliminar me gusta", `DELETE FROM CommunityEventLike WHERE UserId = ?`},
+		{"eliminar publicaciones guardadas", `DELETE FROM CommunityEventBookmark WHERE UserId = ?`},
+		{"eliminar configuración de privacidad", `DELETE FROM UserPrivacySettings WHERE UserId = ?`},
+		{"eliminar dispositivos push", `DELETE FROM PushDevice WHERE UserId = ?`},
+		{"eliminar suscripciones web push", `DELETE FROM WebPushSubscription WHERE UserId = ?`},
+		{"eliminar preferencias de notificaciones", `DELETE FROM NotificationPreferences WHERE UserId = ?`},
+		{"eliminar registro de resúmenes por correo", `DELETE FROM EmailDigestItem WHERE UserId = ?`},
+		{"eliminar verificación de estudiante", `DELETE FROM StudentVerification WHERE UserId = ?`},
+		{"eliminar sesiones", `DELETE FROM Session WHERE UserId = ?`},
+		{"eliminar presencia", `DELETE FROM Online WHERE UserOnlineId = ?`},
+		{"eliminar códigos de recuperación", `DELETE FROM PasswordReset WHERE UserID = ?`},
+		// Los mensajes bloqueados por el filtro solo se guardan en su registro. El resto del
+		// registro sigue a los mensajes: se conserva al anonimizar y se borra en cascada.
+		{"eliminar mensajes bloqueados por el filtro", `DELETE FROM ContentFilterHit WHERE SenderId = ? AND MessageId IS NULL`},
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt.query, userID); err != nil {
+			return fmt.Errorf("error al %s del usuario %d: %w", stmt.desc, userID, err)
+		}
+	}
+	return nil
+}
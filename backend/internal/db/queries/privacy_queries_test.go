@@ -0,0 +1,72 @@
+package queries_test
+
+import (
+	"testing"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/testharness"
+)
+
+// anonymizedUser resume lo que queda de una cuenta tras AnonymizeUser.
+type anonymizedUser struct {
+	FirstName          string `json:"firstName"`
+	LastName           string `json:"lastName"`
+	UserName           string `json:"userName"`
+	Email              string `json:"email"`
+	Deactivated        bool   `json:"deactivated"`
+	Sessions           int    `json:"sessions"`
+	DeactivationEvents int    `json:"deactivationEvents"`
+	MessagesKept       int    `json:"messagesKept"`
+}
+
+func TestAnonymizeUser(t *testing.T) {
+	h := testharness.Shared(t)
+	h.LoadFixtures(t, "usuarios", "contactos")
+	if _, err := h.DB.Exec(`INSERT INTO Session (UserId, Tk, RoleId) VALUES (?, 'token-fixture', 1)`, testharness.FixtureStudentID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := queries.AnonymizeUser(testharness.FixtureStudentID); err != nil {
+		t.Fatalf("AnonymizeUser: %v", err)
+	}
+
+	var got anonymizedUser
+	var status int
+	if err := h.DB.QueryRow(`SELECT FirstName, LastName, UserName, Email, StatusAuthorizedId FROM User WHERE Id = ?`,
+		testharness.FixtureStudentID).Scan(&got.FirstName, &got.LastName, &got.UserName, &got.Email, &status); err != nil {
+		t.Fatal(err)
+	}
+	got.Deactivated = status == int(models.UserStatusDeactivated)
+	counts := []struct {
+		dest  *int
+		query string
+		args  []interface{}
+	}{
+		{&got.Sessions, `SELECT COUNT(*) FROM Session WHERE UserId = ?`, []interface{}{testharness.FixtureStudentID}},
+		{&got.DeactivationEvents, `SELECT COUNT(*) FROM Event WHERE UserId = ? AND EventType = ?`, []interface{}{testharness.FixtureStudentID, models.EventTypeAccountDeactivated}},
+		{&got.MessagesKept, `SELECT COUNT(*) FROM Message WHERE SenderId = ?`, []interface{}{testharness.FixtureStudentID}},
+	}
+	for _, c := range counts {
+		if err := h.DB.QueryRow(c.query, c.args...).Scan(c.dest); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testharness.Golden(t, "anonymize_user", got)
+
+	// La cuenta queda desactivada para AuthMiddleware y el resto de usuarios no cambia.
+	state, err := queries.GetAccountState(testharness.FixtureStudentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if models.UserStatus(state.StatusAuthorizedId) != models.UserStatusDeactivated {
+		t.Errorf("estado %d tras anonimizar, se esperaba %d", state.StatusAuthorizedId, models.UserStatusDeactivated)
+	}
+	var otherName string
+	if err := h.DB.QueryRow(`SELECT FirstName FROM User WHERE Id = ?`, testharness.FixtureGraduateID).Scan(&otherName); err != nil {
+		t.Fatal(err)
+	}
+	if otherName != "Luis" {
+		t.Errorf("el egresado pasó a llamarse %q", otherName)
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/cache"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/fieldcrypt"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 )
 
@@ -36,7 +38,7 @@ func UpdateUserProfile(personID int64, payload models.UpdateProfilePayload) erro
 	}
 	if payload.Phone != nil {
 		setClauses = append(setClauses, fmt.Sprintf("Phone = $%d", argID))
-		args = append(args, *payload.Phone)
+		args = append(args, fieldcrypt.Value(*payload.Phone))
 		argID++
 	}
 	if payload.Sex != nil {
@@ -110,7 +112,10 @@ func UpdateUserProfile(personID int64, payload models.UpdateProfilePayload) erro
 	}
 	if payload.DocId != nil {
 		setClauses = append(setClauses, fmt.Sprintf("DocId = $%d", argID))
-		args = append(args, *payload.DocId)
+		args = append(args, fieldcrypt.Value(*payload.DocId))
+		argID++
+		setClauses = append(setClauses, fmt.Sprintf("DocIdHash = $%d", argID))
+		args = append(args, fieldcrypt.Index(FieldDocId, *payload.DocId))
 		argID++
 	}
 	if payload.DegreeId != nil {
@@ -173,11 +178,12 @@ func GetUserProfile(userID int64) (*models.UserProfile, error) {
 	result, err := MeasureQueryWithResult(func() (interface{}, error) {
 		var profile models.UserProfile
 		var (
-			contactEmail, twitter, facebook, phone, sex, docId, summary, address, github, linkedin, rif, sector, companyName, location sql.NullString
-			nationalityId                                                                                                              sql.NullInt32
-			birthdate                                                                                                                  sql.NullTime
-			degreeId, universityId                                                                                                     sql.NullInt64
-			foundationYear, employeeCount                                                                                              sql.NullInt32
+			contactEmail, twitter, facebook, sex, summary, address, github, linkedin, sector, companyName, location sql.NullString
+			phone, docId, rif                                                                                       fieldcrypt.NullString
+			nationalityId                                                                                           sql.NullInt32
+			birthdate                                                                                               sql.NullTime
+			degreeId, universityId                                                                                  sql.NullInt64
+			foundationYear, employeeCount                                                                           sql.NullInt32
 		)
 
 		err := DB.QueryRow(query, userID).Scan(
@@ -236,7 +242,7 @@ func GetUserProfile(userID int64) (*models.UserProfile, error) {
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("usuario con ID %d no encontrado", userID)
+			return nil, fmt.Errorf("usuario con ID %d no encontrado: %w", userID, err)
 		}
 		return nil, fmt.Errorf("error al obtener el perfil de usuario: %w", err)
 	}
@@ -252,7 +258,8 @@ func GetUserProfileByID(db *sql.DB, userID int64) (*models.SearchResultProfile,
             (SELECT SUM(rr.PointsRP) FROM ReputationReview rr WHERE rr.RevieweeId = u.Id) AS TotalReputation,
             (SELECT AVG(rr.Rating) FROM ReputationReview rr WHERE rr.RevieweeId = u.Id) AS AverageRating,
             (SELECT e.Degree FROM Education e WHERE e.PersonId = u.Id ORDER BY e.GraduationDate DESC LIMIT 1) AS Career,
-            (SELECT SUM(DATEDIFF(IF(we.IsCurrentJob, CURDATE(), we.EndDate), we.StartDate)) / 365.25 FROM WorkExperience we WHERE we.PersonId = u.Id) AS YearsOfExperience
+            (SELECT SUM(DATEDIFF(IF(we.IsCurrentJob, CURDATE(), we.EndDate), we.StartDate)) / 365.25 FROM WorkExperience we WHERE we.PersonId = u.Id) AS YearsOfExperience,
+            ` + VerifiedStudentCondition("u.Id") + ` AS VerifiedStudent
         FROM User u
         WHERE u.Id = ?
     `
@@ -264,7 +271,7 @@ func GetUserProfileByID(db *sql.DB, userID int64) (*models.SearchResultProfile,
 
 	err := db.QueryRow(query, userID).Scan(
 		&profile.ID, &firstName, &lastName, &picture, &summary, &location, &profile.RoleId, &companyName,
-		&totalReputation, &averageRating, &career, &yearsOfExperience,
+		&totalReputation, &averageRating, &career, &yearsOfExperience, &profile.VerifiedStudent,
 	)
 
 	if err != nil {
@@ -309,12 +316,12 @@ func GetUserProfileByID(db *sql.DB, userID int64) (*models.SearchResultProfile,
 	}
 
 	// Traducir RoleId a un string legible
-	switch profile.RoleId {
-	case 1:
+	switch models.UserRole(profile.RoleId) {
+	case models.RoleStudent:
 		profile.Role = "student"
-	case 2:
+	case models.RoleEgresado:
 		profile.Role = "graduate"
-	case 3:
+	case models.RoleBusiness:
 		profile.Role = "company"
 	default:
 		profile.Role = "unknown"
@@ -472,6 +479,32 @@ func GetSkillsForUser(userID int64) ([]models.Skills, error) {
 	return result.([]models.Skills), nil
 }
 
+// GetSkillsByUserIDs recupera las habilidades de varios usuarios, en lugar de llamar a
+// GetSkillsForUser por cada uno. Los usuarios sin habilidades no aparecen en el mapa.
+func GetSkillsByUserIDs(userIDs []int64) (map[int64][]models.Skills, error) {
+	skills := make(map[int64][]models.Skills)
+	err := forEachIDChunk(uniqueIDs(userIDs), func(chunk []int64, args []interface{}) error {
+		rows, err := DB.Query(`SELECT Id, PersonId, Skill, Level FROM Skills WHERE PersonId IN (`+placeholders(len(chunk))+`) ORDER BY Id`, args...)
+		if err != nil {
+			return fmt.Errorf("error al obtener las habilidades de %d usuarios: %w", len(chunk), err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var skill models.Skills
+			if err := rows.Scan(&skill.Id, &skill.PersonId, &skill.Skill, &skill.Level); err != nil {
+				return fmt.Errorf("error al leer una habilidad: %w", err)
+			}
+			skills[skill.PersonId] = append(skills[skill.PersonId], skill)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return skills, nil
+}
+
 // GetLanguagesForUser recupera los idiomas de un usuario.
 func GetLanguagesForUser(userID int64) ([]models.Languages, error) {
 	query := "SELECT Id, PersonId, Language, Level FROM Languages WHERE PersonId = ?"
@@ -540,8 +573,15 @@ func GetProjectsForUser(userID int64) ([]wsmodels.ProjectItem, error) {
 	return result.([]wsmodels.ProjectItem), nil
 }
 
-// GetDegreeByID recupera los detalles de un título universitario por su ID.
+// GetDegreeByID recupera los detalles de un título universitario por su ID. Se cachea como
+// el resto de catálogos.
 func GetDegreeByID(degreeID int64) (*models.Degree, error) {
+	return cache.Load(queryCache, catalogCacheKey("degree", degreeID), cacheTTL.catalog, func() (*models.Degree, error) {
+		return getDegreeByID(degreeID)
+	})
+}
+
+func getDegreeByID(degreeID int64) (*models.Degree, error) {
 	query := "SELECT Id, DegreeName, Descriptions, Code, UniversityId FROM Degree WHERE Id = ?"
 
 	result, err := MeasureQueryWithResult(func() (interface{}, error) {
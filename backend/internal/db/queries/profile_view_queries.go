@@ -0,0 +1,104 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// GetPrivacySettings devuelve las preferencias de privacidad del usuario, o las de por
+// defecto si nunca las ha cambiado.
+func GetPrivacySettings(userID int64) (models.PrivacySettings, error) {
+	settings := models.DefaultPrivacySettings()
+	err := DB.QueryRow(`SELECT ShareProfileViews, TrackProfileViews, LastSeenVisibility FROM UserPrivacySettings WHERE UserId = ?`, userID).
+		Scan(&settings.ShareProfileViews, &settings.TrackProfileViews, &settings.LastSeenVisibility)
+	if err == sql.ErrNoRows {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, fmt.Errorf("error al obtener la configuración de privacidad del usuario %d: %w", userID, err)
+	}
+	return settings, nil
+}
+
+// SavePrivacySettings guarda las preferencias de privacidad del usuario.
+func SavePrivacySettings(userID int64, settings models.PrivacySettings) error {
+	_, err := DB.Exec(`
+		INSERT INTO UserPrivacySettings (UserId, ShareProfileViews, TrackProfileViews, LastSeenVisibility) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE ShareProfileViews = VALUES(ShareProfileViews), TrackProfileViews = VALUES(TrackProfileViews),
+			LastSeenVisibility = VALUES(LastSeenVisibility)`,
+		userID, settings.ShareProfileViews, settings.TrackProfileViews, settings.LastSeenVisibility)
+	if err != nil {
+		return fmt.Errorf("error al guardar la configuración de privacidad del usuario %d: %w", userID, err)
+	}
+	return nil
+}
+
+// InsertProfileView registra una visita a un perfil salvo que el mismo visitante ya lo
+// haya visitado desde since. Devuelve true si se registró.
+func InsertProfileView(profileUserID, viewerID int64, viewerRoleID int, anonymous bool, since time.Time) (bool, error) {
+	result, err := DB.Exec(`
+		INSERT INTO ProfileView (ProfileUserId, ViewerId, ViewerRoleId, IsAnonymous)
+		SELECT ?, ?, ?, ? FROM DUAL
+		WHERE NOT EXISTS (
+			SELECT 1 FROM ProfileView WHERE ViewerId = ? AND ProfileUserId = ? AND ViewedAt >= ?
+		)`,
+		profileUserID, viewerID, viewerRoleID, anonymous, viewerID, profileUserID, since)
+	if err != nil {
+		return false, fmt.Errorf("error al registrar la visita de %d al perfil %d: %w", viewerID, profileUserID, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// Consultas de las estadísticas de estudiante. Todas reciben el ID del usuario y el
+// inicio del periodo.
+const (
+	// StudentProfileViewsByDay cuenta las visitas al perfil por día, con el desglose de
+	// visitas de empresas y anónimas. Recibe antes el RoleId de las empresas.
+	StudentProfileViewsByDay = `
+		SELECT DATE(ViewedAt) AS Day, COUNT(*), SUM(ViewerRoleId = ?), SUM(IsAnonymous)
+		FROM ProfileView
+		WHERE ProfileUserId = ? AND ViewedAt >= ?
+		GROUP BY Day
+		ORDER BY Day
+	`
+
+	// StudentRecentProfileViewers devuelve los últimos visitantes que comparten sus visitas,
+	// una fila por visitante.
+	StudentRecentProfileViewers = `
+		SELECT u.Id, u.RoleId, COALESCE(u.CompanyName, ''), COALESCE(u.FirstName, ''), COALESCE(u.LastName, ''),
+		       COALESCE(u.Picture, ''), v.LastViewedAt
+		FROM (
+			SELECT ViewerId, MAX(ViewedAt) AS LastViewedAt
+			FROM ProfileView
+			WHERE ProfileUserId = ? AND ViewedAt >= ? AND IsAnonymous = FALSE
+			GROUP BY ViewerId
+		) v
+		JOIN User u ON u.Id = v.ViewerId
+		ORDER BY v.LastViewedAt DESC
+		LIMIT ?
+	`
+
+	// StudentApplicationStatusCounts cuenta las postulaciones del usuario por estado actual.
+	StudentApplicationStatusCounts = `
+		SELECT Status, COUNT(*)
+		FROM JobApplication
+		WHERE ApplicantId = ? AND AppliedAt >= ?
+		GROUP BY Status
+	`
+
+	// StudentFeedImpressionsByDay cuenta las veces que el perfil se mostró en el feed por día.
+	StudentFeedImpressionsByDay = `
+		SELECT DATE(ViewedAt) AS Day, COUNT(*)
+		FROM FeedItemView
+		WHERE ItemType = 'USER' AND ItemId = ? AND ViewedAt >= ?
+		GROUP BY Day
+		ORDER BY Day
+	`
+)
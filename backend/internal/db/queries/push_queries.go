@@ -0,0 +1,256 @@
+package queries
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// UpsertPushDevice registra el token de un dispositivo para el usuario. Si el token ya estaba
+// registrado (por el mismo u otro usuario) se reasigna y se actualiza. Devuelve el ID.
+func UpsertPushDevice(userID int64, req models.RegisterPushDeviceRequest) (int64, error) {
+	var deviceName sql.NullString
+	if req.DeviceName != "" {
+		deviceName = sql.NullString{String: req.DeviceName, Valid: true}
+	}
+	// LAST_INSERT_ID(Id) hace que LastInsertId devuelva el ID de la fila existente.
+	result, err := DB.Exec(`
+		INSERT INTO PushDevice (UserId, Provider, Token, DeviceName) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE Id = LAST_INSERT_ID(Id), UserId = VALUES(UserId), Provider = VALUES(Provider),
+			DeviceName = VALUES(DeviceName), UpdatedAt = CURRENT_TIMESTAMP`,
+		userID, req.Provider, req.Token, deviceName)
+	if err != nil {
+		return 0, fmt.Errorf("error al registrar el dispositivo del usuario %d: %w", userID, err)
+	}
+	return result.LastInsertId()
+}
+
+// GetPushDevices devuelve los dispositivos registrados por el usuario, del más reciente al
+// más antiguo.
+func GetPushDevices(userID int64) ([]models.PushDevice, error) {
+	rows, err := DB.Query(`
+		SELECT Id, UserId, Provider, Token, COALESCE(DeviceName, ''), CreatedAt, UpdatedAt
+		FROM PushDevice WHERE UserId = ? ORDER BY UpdatedAt DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los dispositivos del usuario %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	devices := []models.PushDevice{}
+	for rows.Next() {
+		var device models.PushDevice
+		if err := rows.Scan(&device.Id, &device.UserId, &device.Provider, &device.Token, &device.DeviceName,
+			&device.CreatedAt, &device.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error al leer un dispositivo del usuario %d: %w", userID, err)
+		}
+		devices = append(devices, device)
+	}
+	return devices, rows.Err()
+}
+
+// GetPushDevice devuelve un dispositivo del usuario. Si no existe devuelve sql.ErrNoRows.
+func GetPushDevice(userID, deviceID int64) (*models.PushDevice, error) {
+	var device models.PushDevice
+	err := DB.QueryRow(`
+		SELECT Id, UserId, Provider, Token, COALESCE(DeviceName, ''), CreatedAt, UpdatedAt
+		FROM PushDevice WHERE Id = ? AND UserId = ?`, deviceID, userID).
+		Scan(&device.Id, &device.UserId, &device.Provider, &device.Token, &device.DeviceName,
+			&device.CreatedAt, &device.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// DeletePushDevice elimina un dispositivo del usuario. Si no existe devuelve sql.ErrNoRows.
+func DeletePushDevice(userID, deviceID int64) error {
+	result, err := DB.Exec(`DELETE FROM PushDevice WHERE Id = ? AND UserId = ?`, deviceID, userID)
+	if err != nil {
+		return fmt.Errorf("error al eliminar el dispositivo %d del usuario %d: %w", deviceID, userID, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeletePushDeviceByToken da de baja un token que el proveedor rechazó.
+func DeletePushDeviceByToken(token string) error {
+	if _, err := DB.Exec(`DELETE FROM PushDevice WHERE Token = ?`, token); err != nil {
+		return fmt.Errorf("error al dar de baja un token de push: %w", err)
+	}
+	return nil
+}
+
+// webPushEndpointHash es la clave única de una suscripción Web Push (columna EndpointHash).
+func webPushEndpointHash(endpoint string) string {
+	sum := sha256.Sum256([]byte(endpoint))
+	return hex.EncodeToString(sum[:])
+}
+
+// UpsertWebPushSubscription registra la suscripción Web Push de un navegador. Si el endpoint
+// ya estaba registrado se reasigna al usuario y se actualizan sus claves. Devuelve el ID.
+func UpsertWebPushSubscription(userID int64, subscription models.WebPushSubscription) (int64, error) {
+	var userAgent sql.NullString
+	if subscription.UserAgent != "" {
+		userAgent = sql.NullString{String: subscription.UserAgent, Valid: true}
+	}
+	result, err := DB.Exec(`
+		INSERT INTO WebPushSubscription (UserId, Endpoint, EndpointHash, P256dh, Auth, UserAgent) VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE Id = LAST_INSERT_ID(Id), UserId = VALUES(UserId), P256dh = VALUES(P256dh),
+			Auth = VALUES(Auth), UserAgent = VALUES(UserAgent), UpdatedAt = CURRENT_TIMESTAMP`,
+		userID, subscription.Endpoint, webPushEndpointHash(subscription.Endpoint), subscription.P256dh, subscription.Auth, userAgent)
+	if err != nil {
+		return 0, fmt.Errorf("error al registrar la suscripción web push del usuario %d: %w", userID, err)
+	}
+	return result.LastInsertId()
+}
+
+// GetWebPushSubscriptions devuelve las suscripciones Web Push del usuario, de la más reciente
+// a la más antigua.
+func GetWebPushSubscriptions(userID int64) ([]models.WebPushSubscription, error) {
+	rows, err := DB.Query(`
+		SELECT Id, UserId, Endpoint, P256dh, Auth, COALESCE(UserAgent, ''), CreatedAt, UpdatedAt
+		FROM WebPushSubscription WHERE UserId = ? ORDER BY UpdatedAt DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las suscripciones web push del usuario %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	subscriptions := []models.WebPushSubscription{}
+	for rows.Next() {
+		var subscription models.WebPushSubscription
+		if err := rows.Scan(&subscription.Id, &subscription.UserId, &subscription.Endpoint, &subscription.P256dh,
+			&subscription.Auth, &subscription.UserAgent, &subscription.CreatedAt, &subscription.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error al leer una suscripción web push del usuario %d: %w", userID, err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, rows.Err()
+}
+
+// GetWebPushSubscription devuelve una suscripción del usuario. Si no existe devuelve
+// sql.ErrNoRows.
+func GetWebPushSubscription(userID, subscriptionID int64) (*models.WebPushSubscription, error) {
+	var subscription models.WebPushSubscription
+	err := DB.QueryRow(`
+		SELECT Id, UserId, Endpoint, P256dh, Auth, COALESCE(UserAgent, ''), CreatedAt, UpdatedAt
+		FROM WebPushSubscription WHERE Id = ? AND UserId = ?`, subscriptionID, userID).
+		Scan(&subscription.Id, &subscription.UserId, &subscription.Endpoint, &subscription.P256dh,
+			&subscription.Auth, &subscription.UserAgent, &subscription.CreatedAt, &subscription.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// DeleteWebPushSubscription elimina una suscripción del usuario. Si no existe devuelve
+// sql.ErrNoRows.
+func DeleteWebPushSubscription(userID, subscriptionID int64) error {
+	result, err := DB.Exec(`DELETE FROM WebPushSubscription WHERE Id = ? AND UserId = ?`, subscriptionID, userID)
+	if err != nil {
+		return fmt.Errorf("error al eliminar la suscripción web push %d del usuario %d: %w", subscriptionID, userID, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteWebPushSubscriptionByEndpoint da de baja una suscripción que el servicio de push
+// del navegador rechazó.
+func DeleteWebPushSubscriptionByEndpoint(endpoint string) error {
+	if _, err := DB.Exec(`DELETE FROM WebPushSubscription WHERE EndpointHash = ?`, webPushEndpointHash(endpoint)); err != nil {
+		return fmt.Errorf("error al dar de baja una suscripción web push: %w", err)
+	}
+	return nil
+}
+
+// GetNotificationPreferences devuelve las preferencias de notificaciones del usuario, o las
+// de por defecto si nunca las ha cambiado.
+func GetNotificationPreferences(userID int64) (models.NotificationPreferences, error) {
+	prefs := models.DefaultNotificationPreferences()
+	var updatedAt time.Time
+	err := DB.QueryRow(`
+		SELECT PushEnabled, ChatMessages, ChatPreviews, ContactRequests, Community, Challenges, JobApplications, SavedSearches, EmailDigest,
+			DndEnabled, TIME_FORMAT(DndStart, '%H:%i'), TIME_FORMAT(DndEnd, '%H:%i'), DndTimezone, UpdatedAt
+		FROM NotificationPreferences WHERE UserId = ?`, userID).
+		Scan(&prefs.PushEnabled, &prefs.ChatMessages, &prefs.ChatPreviews, &prefs.ContactRequests, &prefs.Community,
+			&prefs.Challenges, &prefs.JobApplications, &prefs.SavedSearches, &prefs.EmailDigest,
+			&prefs.DndEnabled, &prefs.DndStart, &prefs.DndEnd, &prefs.DndTimezone, &updatedAt)
+	if err == sql.ErrNoRows {
+		return prefs, nil
+	}
+	if err != nil {
+		return prefs, fmt.Errorf("error al obtener las preferencias de notificaciones del usuario %d: %w", userID, err)
+	}
+	prefs.UpdatedAt = &updatedAt
+	return prefs, nil
+}
+
+// SaveNotificationPreferences guarda las preferencias de notificaciones del usuario.
+func SaveNotificationPreferences(userID int64, prefs models.NotificationPreferences) error {
+	_, err := DB.Exec(`
+		INSERT INTO NotificationPreferences (UserId, PushEnabled, ChatMessages, ChatPreviews, ContactRequests, Community, Challenges, JobApplications, SavedSearches, EmailDigest,
+			DndEnabled, DndStart, DndEnd, DndTimezone)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE PushEnabled = VALUES(PushEnabled), ChatMessages = VALUES(ChatMessages),
+			ChatPreviews = VALUES(ChatPreviews), ContactRequests = VALUES(ContactRequests), Community = VALUES(Community),
+			Challenges = VALUES(Challenges), JobApplications = VALUES(JobApplications), SavedSearches = VALUES(SavedSearches),
+			EmailDigest = VALUES(EmailDigest), DndEnabled = VALUES(DndEnabled), DndStart = VALUES(DndStart),
+			DndEnd = VALUES(DndEnd), DndTimezone = VALUES(DndTimezone)`,
+		userID, prefs.PushEnabled, prefs.ChatMessages, prefs.ChatPreviews, prefs.ContactRequests, prefs.Community,
+		prefs.Challenges, prefs.JobApplications, prefs.SavedSearches, prefs.EmailDigest,
+		prefs.DndEnabled, prefs.DndStart, prefs.DndEnd, prefs.DndTimezone)
+	if err != nil {
+		return fmt.Errorf("error al guardar las preferencias de notificaciones del usuario %d: %w", userID, err)
+	}
+	return nil
+}
+
+// GetUserDisplayName devuelve el nombre con el que se muestra un usuario: el nombre de la
+// empresa o el nombre y apellido.
+func GetUserDisplayName(userID int64) (string, error) {
+	var name string
+	err := DB.QueryRow(`SELECT `+fmt.Sprintf(reportDisplayName, "u")+` FROM User u WHERE u.Id = ?`, userID).Scan(&name)
+	return name, err
+}
+
+// GetEventsByType devuelve las notificaciones con ID en (afterID, upToID] de los tipos
+// indicados, en orden de creación.
+func GetEventsByType(afterID, upToID int64, eventTypes []string) ([]models.Event, error) {
+	if len(eventTypes) == 0 {
+		return nil, nil
+	}
+	args := []interface{}{afterID, upToID}
+	for _, eventType := range eventTypes {
+		args = append(args, eventType)
+	}
+	rows, err := DB.Query(`
+		SELECT Id, EventType, EventTitle, Description, UserId, OtherUserId, CreateAt, IsRead, Metadata
+		FROM Event
+		WHERE Id > ? AND Id <= ? AND EventType IN (?`+strings.Repeat(", ?", len(eventTypes)-1)+`)
+		ORDER BY Id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las notificaciones entre %d y %d: %w", afterID, upToID, err)
+	}
+	defer rows.Close()
+
+	var events []models.Event
+	for rows.Next() {
+		var event models.Event
+		var metadata []byte
+		if err := rows.Scan(&event.Id, &event.EventType, &event.EventTitle, &event.Description, &event.UserId,
+			&event.OtherUserId, &event.CreateAt, &event.IsRead, &metadata); err != nil {
+			return nil, err
+		}
+		event.Metadata = metadata
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
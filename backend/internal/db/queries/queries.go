@@ -56,7 +56,9 @@ import (
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/cache"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
 	"github.com/google/uuid"
 )
 
@@ -103,9 +105,9 @@ func GetUserBySessionToken(token string) (*models.User, error) {
 			NationalityId, Birthdate, Picture, DegreeId, UniversityId,
 			RoleId, StatusAuthorizedId, Summary, Address, Github, Linkedin
 		FROM User 
-		WHERE Id = ? AND StatusAuthorizedId = 1
+		WHERE Id = ? AND StatusAuthorizedId = ?
 		LIMIT 1
-	`, userId).Scan(
+	`, userId, models.UserStatusActive).Scan(
 		&user.Id, &user.FirstName, &user.LastName, &user.UserName, &user.Email, &user.Phone, &user.Sex, &user.DocId,
 		&user.NationalityId, &user.Birthdate, &user.Picture, &user.DegreeId, &user.UniversityId,
 		&user.RoleId, &user.StatusAuthorizedId, &user.Summary, &user.Address, &user.Github, &user.Linkedin,
@@ -181,6 +183,7 @@ func CreateMessage(msg *models.Message) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("error insertando mensaje: %w", err)
 	}
+	InvalidateChatCache(msg.ChatId)
 
 	return msg.Id, nil
 }
@@ -264,8 +267,15 @@ func GetAcceptedContacts(userID int64) ([]models.Contact, error) {
 	return contacts, nil
 }
 
-// GetUserBaseInfo recupera información básica del usuario.
+// GetUserBaseInfo recupera información básica del usuario. El resultado se cachea
+// (CACHE_USER_TTL) y se invalida con InvalidateUserCache.
 func GetUserBaseInfo(userID int64) (*models.UserBaseInfo, error) {
+	return cache.Load(queryCache, userBaseCacheKey(userID), cacheTTL.user, func() (*models.UserBaseInfo, error) {
+		return getUserBaseInfo(userID)
+	})
+}
+
+func getUserBaseInfo(userID int64) (*models.UserBaseInfo, error) {
 	user := &models.UserBaseInfo{}
 	query := `SELECT Id, FirstName, LastName, UserName, Picture, RoleId FROM User WHERE Id = ?`
 
@@ -294,6 +304,48 @@ func GetUserBaseInfo(userID int64) (*models.UserBaseInfo, error) {
 	return user, nil
 }
 
+// GetUserBaseInfoByIDs recupera la información básica de varios usuarios, en lugar de llamar
+// a GetUserBaseInfo por cada uno. Los que están en la caché no se consultan; el resto se lee
+// con cláusulas IN de hasta batchQueryChunkSize IDs y se guarda en la caché. Los IDs que no
+// existen no aparecen en el mapa devuelto.
+func GetUserBaseInfoByIDs(userIDs []int64) (map[int64]*models.UserBaseInfo, error) {
+	users := make(map[int64]*models.UserBaseInfo, len(userIDs))
+	var missing []int64
+	for _, userID := range uniqueIDs(userIDs) {
+		if user, ok := cache.GetJSON[*models.UserBaseInfo](queryCache, userBaseCacheKey(userID)); ok && user != nil {
+			users[userID] = user
+			continue
+		}
+		missing = append(missing, userID)
+	}
+
+	err := forEachIDChunk(missing, func(chunk []int64, args []interface{}) error {
+		rows, err := DB.Query(`SELECT Id, FirstName, LastName, UserName, Picture, RoleId FROM User WHERE Id IN (`+placeholders(len(chunk))+`)`, args...)
+		if err != nil {
+			return fmt.Errorf("error consultando información base de %d usuarios: %w", len(chunk), err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			user := &models.UserBaseInfo{}
+			var firstName, lastName, picture sql.NullString
+			if err := rows.Scan(&user.ID, &firstName, &lastName, &user.UserName, &picture, &user.RoleId); err != nil {
+				return fmt.Errorf("error escaneando información base de usuario: %w", err)
+			}
+			user.FirstName = firstName.String
+			user.LastName = lastName.String
+			user.Picture = picture.String
+			users[user.ID] = user
+			cache.SetJSON(queryCache, userBaseCacheKey(user.ID), user, cacheTTL.user)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 // GetLastMessageBetweenUsers recupera el último mensaje entre dos usuarios.
 func GetLastMessageBetweenUsers(userID1 int64, userID2 int64) (*models.Message, error) {
 	// Primero obtener el ChatId
@@ -375,18 +427,18 @@ func SetUserOnlineStatus(userID int64, isOnline bool) error {
 		// Inserta o actualiza el registro del usuario en la tabla "Online".
 		// Se utiliza ON DUPLICATE KEY UPDATE para la operación de "upsert" en MySQL.
 		// El estado se establece en 1 (online) y CreateAt se actualiza.
-		queryMysql := `INSERT INTO Online (UserOnlineId, CreateAt, Status)
-		               VALUES (?, ?, 1)
-		               ON DUPLICATE KEY UPDATE CreateAt = VALUES(CreateAt), Status = 1`
+		queryMysql := `INSERT INTO Online (UserOnlineId, CreateAt, Status, LastSeenAt)
+		               VALUES (?, ?, 1, ?)
+		               ON DUPLICATE KEY UPDATE CreateAt = VALUES(CreateAt), Status = 1, LastSeenAt = VALUES(LastSeenAt)`
 		// Nota: VALUES(ColumnName) en la cláusula UPDATE se refiere al valor que se habría insertado.
-		_, err := DB.Exec(queryMysql, userID, now)
+		_, err := DB.Exec(queryMysql, userID, now, now)
 		if err != nil {
 			return fmt.Errorf("error estableciendo estado online para userID %d: %w", userID, err)
 		}
 	} else {
 		// Marcar como offline (Status = 0) y actualizar CreateAt (interpretado como LastSeenAt).
-		queryMysql := `UPDATE Online SET Status = 0, CreateAt = ? WHERE UserOnlineId = ?`
-		res, err := DB.Exec(queryMysql, now, userID)
+		queryMysql := `UPDATE Online SET Status = 0, CreateAt = ?, LastSeenAt = ? WHERE UserOnlineId = ?`
+		res, err := DB.Exec(queryMysql, now, now, userID)
 		if err != nil {
 			return fmt.Errorf("error estableciendo estado offline para userID %d: %w", userID, err)
 		}
@@ -437,17 +489,30 @@ func GetUserContactIDs(userID int64) ([]int64, error) {
 // CreateEvent guarda un nuevo evento/notificación en la base de datos.
 // Actualiza el ID del evento pasado por referencia.
 func CreateEvent(event *models.Event) error {
+	return insertEvent(DB, event)
+}
+
+func insertEvent(ex Execer, event *models.Event) error {
 	if event.CreateAt.IsZero() {
 		event.CreateAt = time.Now().UTC()
 	}
 
+	if event.GroupCount < 1 {
+		event.GroupCount = 1
+	}
+	groupActors, err := marshalGroupActors(event.GroupActors)
+	if err != nil {
+		return err
+	}
+
 	query := `INSERT INTO Event (
 		EventType, EventTitle, Description, UserId, OtherUserId, 
 		ProyectId, CreateAt, IsRead, GroupId, Status, 
-		ActionRequired, ActionTakenAt, Metadata
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		ActionRequired, ActionTakenAt, Metadata,
+		GroupKey, GroupCount, GroupActors
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	result, err := DB.Exec(query,
+	result, err := ex.Exec(query,
 		event.EventType,
 		event.EventTitle,
 		event.Description,
@@ -461,6 +526,9 @@ func CreateEvent(event *models.Event) error {
 		event.ActionRequired,
 		event.ActionTakenAt,
 		event.Metadata,
+		event.GroupKey,
+		event.GroupCount,
+		groupActors,
 	)
 	if err != nil {
 		return fmt.Errorf("error insertando evento: %w", err)
@@ -527,7 +595,6 @@ func GetNotificationsForUser(userID int64, onlyUnread bool, limit int, offset in
 	var notifications []wsmodels.NotificationInfo
 	for rows.Next() {
 		var notification wsmodels.NotificationInfo
-		var rawCreateAt []byte // Para escanear el timestamp directamente
 
 		// Variables para los campos del perfil que pueden ser NULL
 		var profileID sql.NullInt64
@@ -544,7 +611,7 @@ func GetNotificationsForUser(userID int64, onlyUnread bool, limit int, offset in
 		// Escanear los campos disponibles. notification.Type, notification.Title, notification.IsRead
 		// quedarán con sus zero values (string vacío, false).
 		err := rows.Scan(
-			&notification.ID, &notification.Message, &rawCreateAt, // EventType, EventTitle, IsRead omitidos
+			&notification.ID, &notification.Message, &notification.Timestamp, // EventType, EventTitle, IsRead omitidos
 			&otherUserID, &projectID,
 			&profileID, &profileFirstName, &profileLastName, &profileUserName, &profilePicture, &profileEmail,
 		)
@@ -556,15 +623,6 @@ func GetNotificationsForUser(userID int64, onlyUnread bool, limit int, offset in
 		// notification.Title = "" (valor por defecto)
 		// notification.IsRead = false (valor por defecto)
 
-		parsedTime, parseErr := time.Parse("2006-01-02 15:04:05", string(rawCreateAt))
-		if parseErr != nil {
-			parsedTime, parseErr = time.Parse(time.RFC3339, string(rawCreateAt))
-			if parseErr != nil {
-				return nil, fmt.Errorf("error parseando CreateAt de notificación (%s): %w", string(rawCreateAt), parseErr)
-			}
-		}
-		notification.Timestamp = parsedTime
-
 		payloadMap := make(map[string]interface{})
 		if otherUserID.Valid {
 			payloadMap["otherUserId"] = otherUserID.Int64
@@ -638,11 +696,9 @@ func MarkAllNotificationsAsRead(userID int64) (int64, error) {
 
 // --- Perfil de Usuario ---
 
-// GetUserFullProfileData recupera los datos principales del perfil de un usuario desde la tabla User.
-func GetUserFullProfileData(userID int64) (*models.User, error) {
-	user := &models.User{}
-	// Unir con tablas relacionadas para obtener nombres en lugar de solo IDs
-	query := `SELECT
+// userFullProfileQuery selecciona los datos principales del perfil, uniendo con las tablas
+// relacionadas para obtener nombres en lugar de solo IDs. Se completa con la condición sobre u.Id.
+const userFullProfileQuery = `SELECT
 	            u.Id, u.FirstName, u.LastName, u.UserName, u.Email, u.Phone, u.Sex, u.DocId,
 	            u.NationalityId, n.CountryName AS NationalityName, u.Birthdate, u.Picture,
 	            u.DegreeId, d.DegreeName AS DegreeName, u.UniversityId, un.Name AS UniversityName,
@@ -652,15 +708,23 @@ func GetUserFullProfileData(userID int64) (*models.User, error) {
 	        LEFT JOIN Degree d ON u.DegreeId = d.Id
 	        LEFT JOIN University un ON u.UniversityId = un.Id
 	        LEFT JOIN Role r ON u.RoleId = r.Id
-	        WHERE u.Id = ?`
+	        WHERE `
 
-	err := DB.QueryRow(query, userID).Scan(
+// scanUserFullProfile lee una fila de userFullProfileQuery.
+func scanUserFullProfile(row interface{ Scan(...interface{}) error }) (*models.User, error) {
+	user := &models.User{}
+	err := row.Scan(
 		&user.Id, &user.FirstName, &user.LastName, &user.UserName, &user.Email, &user.Phone, &user.Sex, &user.DocId,
 		&user.NationalityId, &user.NationalityName, &user.Birthdate, &user.Picture,
 		&user.DegreeId, &user.DegreeName, &user.UniversityId, &user.UniversityName,
 		&user.RoleId, &user.RoleName, &user.StatusAuthorizedId, &user.Summary, &user.Address, &user.Github, &user.Linkedin,
 	)
+	return user, err
+}
 
+// GetUserFullProfileData recupera los datos principales del perfil de un usuario desde la tabla User.
+func GetUserFullProfileData(userID int64) (*models.User, error) {
+	user, err := scanUserFullProfile(DB.QueryRow(userFullProfileQuery+"u.Id = ?", userID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("usuario con ID %d no encontrado para perfil completo", userID)
@@ -670,6 +734,33 @@ func GetUserFullProfileData(userID int64) (*models.User, error) {
 	return user, nil
 }
 
+// GetUserFullProfileDataByIDs recupera los datos principales del perfil de varios usuarios,
+// en lugar de llamar a GetUserFullProfileData por cada uno. Los IDs que no existen no aparecen
+// en el mapa devuelto.
+func GetUserFullProfileDataByIDs(userIDs []int64) (map[int64]*models.User, error) {
+	users := make(map[int64]*models.User, len(userIDs))
+	err := forEachIDChunk(uniqueIDs(userIDs), func(chunk []int64, args []interface{}) error {
+		rows, err := DB.Query(userFullProfileQuery+"u.Id IN ("+placeholders(len(chunk))+")", args...)
+		if err != nil {
+			return fmt.Errorf("error consultando datos completos de perfil de %d usuarios: %w", len(chunk), err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			user, err := scanUserFullProfile(rows)
+			if err != nil {
+				return fmt.Errorf("error escaneando datos completos de perfil: %w", err)
+			}
+			users[user.Id] = user
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 // GetEducationItemsForUser recupera los items de educación para un usuario.
 func GetEducationItemsForUser(personID int64) ([]models.Education, error) {
 	query := `SELECT e.Id, e.PersonId, e.Institution, e.Degree, e.Campus, e.GraduationDate, e.CountryId, n.CountryName AS CountryName, e.IsCurrentlyStudying
@@ -743,7 +834,7 @@ func GetCertificationItemsForUser(personID int64) ([]models.Certifications, erro
 
 // GetSkillItemsForUser recupera las habilidades para un usuario.
 func GetSkillItemsForUser(personID int64) ([]models.Skills, error) {
-	query := `SELECT Id, PersonId, Skill, Level FROM Skills WHERE PersonId = ? ORDER BY Skill ASC, Id DESC`
+	query := `SELECT Id, PersonId, Skill, Level, SkillCatalogId FROM Skills WHERE PersonId = ? ORDER BY Skill ASC, Id DESC`
 	rows, err := DB.Query(query, personID)
 	if err != nil {
 		return nil, fmt.Errorf("error consultando skills para PersonID %d: %w", personID, err)
@@ -753,7 +844,7 @@ func GetSkillItemsForUser(personID int64) ([]models.Skills, error) {
 	var items []models.Skills
 	for rows.Next() {
 		var item models.Skills
-		if err := rows.Scan(&item.Id, &item.PersonId, &item.Skill, &item.Level); err != nil {
+		if err := rows.Scan(&item.Id, &item.PersonId, &item.Skill, &item.Level, &item.SkillCatalogId); err != nil {
 			return nil, fmt.Errorf("error escaneando skill: %w", err)
 		}
 		items = append(items, item)
@@ -1026,7 +1117,8 @@ func UpdateEventStatus(eventId int64, status string, metadata interface{}) error
 func GetEventsByUserID(userID int64, onlyUnread bool, limit int, offset int) ([]models.Event, error) {
 	var args []interface{}
 	query := `
-		SELECT Id, EventType, EventTitle, Description, UserId, OtherUserId, ProyectId, CreateAt, IsRead, GroupId, Status, ActionRequired, ActionTakenAt, Metadata
+		SELECT Id, EventType, EventTitle, Description, UserId, OtherUserId, ProyectId, CreateAt, IsRead, GroupId, Status, ActionRequired, ActionTakenAt, Metadata,
+		       GroupKey, GroupCount, GroupActors
 		FROM Event
 		WHERE UserId = ?`
 	args = append(args, userID)
@@ -1053,10 +1145,55 @@ func GetEventsByUserID(userID int64, onlyUnread bool, limit int, offset int) ([]
 	}
 	defer rows.Close()
 
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetEventsByUserID: %w", err)
+	}
+	return events, nil
+}
+
+// GetEventsPageByUserID recupera una página de eventos del usuario por cursor, ordenados por
+// CreateAt DESC, Id DESC. Devuelve hasta page.FetchLimit() filas; la de más indica que hay
+// otra página (ver pagination.NewPage).
+func GetEventsPageByUserID(userID int64, onlyUnread bool, page pagination.Params) ([]models.Event, error) {
+	query := `
+		SELECT Id, EventType, EventTitle, Description, UserId, OtherUserId, ProyectId, CreateAt, IsRead, GroupId, Status, ActionRequired, ActionTakenAt, Metadata,
+		       GroupKey, GroupCount, GroupActors
+		FROM Event
+		WHERE UserId = ?`
+	args := []interface{}{userID}
+
+	if onlyUnread {
+		query += " AND IsRead = false"
+	}
+	if cond, condArgs := page.Where("CreateAt", "Id"); cond != "" {
+		query += " AND " + cond
+		args = append(args, condArgs...)
+	}
+	query += " ORDER BY CreateAt DESC, Id DESC LIMIT ?"
+	args = append(args, page.FetchLimit())
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("GetEventsPageByUserID: error en db.Query: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetEventsPageByUserID: %w", err)
+	}
+	return events, nil
+}
+
+// scanEvents lee las filas de una consulta sobre Event con las columnas de GetEventsByUserID,
+// incluidas las de agrupación.
+func scanEvents(rows *sql.Rows) ([]models.Event, error) {
 	var events []models.Event
 	for rows.Next() {
 		var event models.Event
 		var metadataScanValue []byte // Usar []byte para escanear Metadata
+		var groupActors []byte
 
 		err := rows.Scan(
 			&event.Id,
@@ -1073,27 +1210,30 @@ func GetEventsByUserID(userID int64, onlyUnread bool, limit int, offset int) ([]
 			&event.ActionRequired,
 			&event.ActionTakenAt,
 			&metadataScanValue, // Escanear en el []byte
+			&event.GroupKey,
+			&event.GroupCount,
+			&groupActors,
 		)
 		if err != nil {
-			// Loguear el error y continuar podría ser una opción si una fila corrupta no debe detener todo
-			return nil, fmt.Errorf("GetEventsByUserID: error en rows.Scan: %w", err)
+			return nil, fmt.Errorf("error en rows.Scan: %w", err)
+		}
+		if event.GroupActors, err = unmarshalGroupActors(groupActors); err != nil {
+			return nil, fmt.Errorf("evento %d: %w", event.Id, err)
 		}
 
 		if metadataScanValue != nil {
 			event.Metadata = json.RawMessage(metadataScanValue)
 		} else {
 			// Si es NULL en la BD, event.Metadata será nil, lo cual es correcto para json.RawMessage
-			// o puedes asignarle un JSON vacío si prefieres: event.Metadata = json.RawMessage("null") o json.RawMessage("{}")
 			event.Metadata = nil
 		}
 
 		events = append(events, event)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("GetEventsByUserID: error en rows.Err: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error en rows.Err: %w", err)
 	}
-
 	return events, nil
 }
 
@@ -1123,6 +1263,9 @@ func UpdateContactStatus(userID, otherUserID int64, status string, _ string) err
 		return fmt.Errorf("no se encontró una solicitud de contacto pendiente para actualizar")
 	}
 
+	InvalidateChatListCache(userID, otherUserID)
+	InvalidateNetworkCache(userID, otherUserID)
+	touchChatSyncBetween(userID, otherUserID)
 	return nil
 }
 
@@ -1134,6 +1277,8 @@ func UpdateContactChatId(user1ID, user2ID int64, chatID string) error {
 		logger.Errorf("QUERY", "Error al actualizar ChatId para los usuarios %d y %d: %v", user1ID, user2ID, err)
 		return fmt.Errorf("no se pudo actualizar el chatId: %w", err)
 	}
+	InvalidateChatListCache(user1ID, user2ID)
+	touchChatSyncBetween(user1ID, user2ID)
 	logger.Successf("QUERY", "ChatId actualizado correctamente para los usuarios %d y %d", user1ID, user2ID)
 	return nil
 }
@@ -1181,8 +1326,21 @@ func GetNotificationById(notificationId string) (*models.Notification, error) {
 	return &notification, nil
 }
 
+// shadowDeletedMessage es la subconsulta que indica si el filtro de contenido ocultó el
+// mensaje m al resto de participantes (solo lo ve su remitente).
+const shadowDeletedMessage = `SELECT 1 FROM ContentFilterHit f WHERE f.MessageId = m.Id AND f.Action = 'BORRADO_SILENCIOSO'`
+
 // GetChatList recupera la lista de información de chat para un usuario con una única consulta optimizada.
+// Los mensajes no leídos salen de los contadores de ChatUnread.
+// El resultado se cachea (CACHE_CHAT_LIST_TTL); los mensajes y cambios de contactos lo invalidan
+// con InvalidateChatCache e InvalidateChatListCache.
 func GetChatList(userID int64) ([]models.ChatInfoQueryResult, error) {
+	return cache.Load(queryCache, chatListCacheKey(userID), cacheTTL.chatList, func() ([]models.ChatInfoQueryResult, error) {
+		return getChatList(userID)
+	})
+}
+
+func getChatList(userID int64) ([]models.ChatInfoQueryResult, error) {
 	query := `
 WITH LastMessages AS (
     SELECT
@@ -1193,29 +1351,21 @@ WITH LastMessages AS (
         m.Id,
         ROW_NUMBER() OVER(PARTITION BY m.ChatId ORDER BY m.SentAt DESC, m.Id DESC) as rn
     FROM Message m
-),
-UnreadCounts AS (
-    SELECT
-        m.ChatId,
-        m.SenderId,
-        COUNT(*) as unread
-    FROM Message m
-    WHERE m.Status != 'read'
-    GROUP BY m.ChatId, m.SenderId
+    WHERE m.SenderId = ? OR NOT EXISTS (` + shadowDeletedMessage + `)
 )
 SELECT
     c.ChatId,
     CASE WHEN c.User1Id = ? THEN c.User2Id ELSE c.User1Id END AS OtherUserID,
     u.RoleId AS OtherUserRoleID,
     u.UserName,
-    CASE WHEN u.RoleId = 3 THEN u.CompanyName ELSE u.FirstName END AS OtherFirstName,
-    CASE WHEN u.RoleId = 3 THEN '' ELSE u.LastName END AS OtherLastName,
+    CASE WHEN u.RoleId = ? THEN u.CompanyName ELSE u.FirstName END AS OtherFirstName,
+    CASE WHEN u.RoleId = ? THEN '' ELSE u.LastName END AS OtherLastName,
     u.CompanyName AS OtherCompanyName,
     u.Picture,
     lm.Content AS LastMessage,
     lm.SentAt AS LastMessageTs,
     lm.SenderId AS LastMessageFromUserId,
-    COALESCE(uc.unread, 0) as UnreadCount
+    COALESCE(cu.Unread, 0) as UnreadCount
 FROM
     Contact c
 JOIN
@@ -1223,14 +1373,14 @@ JOIN
 LEFT JOIN
     LastMessages lm ON lm.ChatId = c.ChatId AND lm.rn = 1
 LEFT JOIN
-    UnreadCounts uc ON uc.ChatId = c.ChatId AND uc.SenderId = u.Id
+    ChatUnread cu ON cu.UserId = ? AND cu.ChatId = c.ChatId
 WHERE
     (c.User1Id = ? OR c.User2Id = ?) AND c.Status = 'accepted'
 ORDER BY
     lm.SentAt DESC
 `
 
-	rows, err := DB.Query(query, userID, userID, userID, userID)
+	rows, err := DB.Query(query, userID, userID, models.RoleBusiness, models.RoleBusiness, userID, userID, userID, userID)
 	if err != nil {
 		return nil, fmt.Errorf("error querying chat list for userID %d: %w", userID, err)
 	}
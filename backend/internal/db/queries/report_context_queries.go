@@ -0,0 +1,178 @@
+package queries
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// reportContextMessageColumns son las columnas que lee scanReportContextMessage. El alias de la
+// tabla Message es m.
+const reportContextMessageColumns = `
+	m.Id, m.SenderId, m.TypeMessageId, COALESCE(m.Content, ''), COALESCE(m.MediaId, ''),
+	COALESCE(m.ReplyToMessageId, ''), m.SentAt, m.EditedAt,
+	EXISTS (SELECT 1 FROM HiddenContent h WHERE h.TargetType = 'MESSAGE' AND h.TargetId = m.Id)`
+
+func scanReportContextMessage(row rowScanner, extra ...any) (models.ReportContextMessage, error) {
+	var message models.ReportContextMessage
+	var editedAt sql.NullTime
+	dest := []any{
+		&message.Id, &message.SenderId, &message.TypeMessageId, &message.Content, &message.MediaId,
+		&message.ReplyToMessageId, &message.SentAt, &editedAt, &message.IsHidden,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return message, err
+	}
+	if editedAt.Valid {
+		message.EditedAt = &editedAt.Time
+	}
+	return message, nil
+}
+
+// GetMessageContext devuelve, en orden cronológico, hasta before mensajes anteriores y after
+// posteriores al mensaje messageID dentro de su chat privado o de grupo, incluido el propio
+// mensaje marcado con IsReported. Si el mensaje no existe devuelve sql.ErrNoRows.
+func GetMessageContext(messageID string, before, after int) (chatID, chatIDGroup string, messages []models.ReportContextMessage, err error) {
+	var chat, group sql.NullString
+	target, err := scanReportContextMessage(DB.QueryRow(`
+		SELECT `+reportContextMessageColumns+`, m.ChatId, m.ChatIdGroup FROM Message m WHERE m.Id = ?`, messageID), &chat, &group)
+	if err != nil {
+		return "", "", nil, err
+	}
+	target.IsReported = true
+
+	// El chat del mensaje decide la columna; los dos índices (ChatId, SentAt) y
+	// (ChatIdGroup, SentAt) cubren las búsquedas. Los empates de SentAt se ordenan por Id.
+	column, chatValue := "m.ChatId", chat.String
+	if !chat.Valid {
+		column, chatValue = "m.ChatIdGroup", group.String
+	}
+
+	previous, err := queryReportContextMessages(`
+		SELECT `+reportContextMessageColumns+` FROM Message m
+		WHERE `+column+` = ? AND (m.SentAt < ? OR (m.SentAt = ? AND m.Id < ?))
+		ORDER BY m.SentAt DESC, m.Id DESC
+		LIMIT ?`, chatValue, target.SentAt, target.SentAt, target.Id, before)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("error al leer los mensajes anteriores a %s: %w", messageID, err)
+	}
+	next, err := queryReportContextMessages(`
+		SELECT `+reportContextMessageColumns+` FROM Message m
+		WHERE `+column+` = ? AND (m.SentAt > ? OR (m.SentAt = ? AND m.Id > ?))
+		ORDER BY m.SentAt ASC, m.Id ASC
+		LIMIT ?`, chatValue, target.SentAt, target.SentAt, target.Id, after)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("error al leer los mensajes posteriores a %s: %w", messageID, err)
+	}
+
+	messages = make([]models.ReportContextMessage, 0, len(previous)+1+len(next))
+	for i := len(previous) - 1; i >= 0; i-- {
+		messages = append(messages, previous[i])
+	}
+	messages = append(messages, target)
+	messages = append(messages, next...)
+	return chat.String, group.String, messages, nil
+}
+
+func queryReportContextMessages(query string, args ...any) ([]models.ReportContextMessage, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []models.ReportContextMessage{}
+	for rows.Next() {
+		message, err := scanReportContextMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+// GetChatMemberIDs devuelve los participantes actuales de un chat privado (chatID) o de grupo
+// (chatIDGroup).
+func GetChatMemberIDs(chatID, chatIDGroup string) ([]int64, error) {
+	var rows *sql.Rows
+	var err error
+	if chatID != "" {
+		rows, err = DB.Query(`
+			SELECT User1Id FROM Contact WHERE ChatId = ?
+			UNION
+			SELECT User2Id FROM Contact WHERE ChatId = ?`, chatID, chatID)
+	} else {
+		rows, err = DB.Query(`
+			SELECT DISTINCT gm.UserId FROM GroupMembers gm
+			JOIN GroupsUsers g ON g.Id = gm.GroupId
+			WHERE g.ChatId = ?`, chatIDGroup)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar los participantes del chat: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error al leer los participantes del chat: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// InsertReportContextBundle guarda el contexto de un reporte. Si ya existe MySQL devuelve un
+// error de clave duplicada (1062): el contexto no se reemplaza.
+func InsertReportContextBundle(bundle models.ReportContextBundle) error {
+	participants, err := json.Marshal(bundle.Participants)
+	if err != nil {
+		return fmt.Errorf("error al serializar los participantes del reporte %d: %w", bundle.ReportId, err)
+	}
+	messages, err := json.Marshal(bundle.Messages)
+	if err != nil {
+		return fmt.Errorf("error al serializar los mensajes del reporte %d: %w", bundle.ReportId, err)
+	}
+	_, err = DB.Exec(`
+		INSERT INTO ReportContextBundle
+			(ReportId, MessageId, ChatId, ChatIdGroup, MessagesBefore, MessagesAfter, Participants, Messages, CapturedBy)
+		VALUES (?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?, ?, ?)`,
+		bundle.ReportId, bundle.MessageId, bundle.ChatId, bundle.ChatIdGroup, bundle.MessagesBefore, bundle.MessagesAfter,
+		participants, messages, bundle.CapturedBy)
+	if err != nil {
+		return fmt.Errorf("error al guardar el contexto del reporte %d: %w", bundle.ReportId, err)
+	}
+	return nil
+}
+
+// GetReportContextBundle devuelve el contexto capturado de un reporte. Si no existe devuelve
+// sql.ErrNoRows.
+func GetReportContextBundle(reportID int64) (*models.ReportContextBundle, error) {
+	var bundle models.ReportContextBundle
+	var chatID, chatIDGroup sql.NullString
+	var participants, messages []byte
+	var capturedBy sql.NullInt64
+	err := DB.QueryRow(`
+		SELECT ReportId, MessageId, ChatId, ChatIdGroup, MessagesBefore, MessagesAfter, Participants, Messages, CapturedBy, CapturedAt
+		FROM ReportContextBundle WHERE ReportId = ?`, reportID).
+		Scan(&bundle.ReportId, &bundle.MessageId, &chatID, &chatIDGroup, &bundle.MessagesBefore, &bundle.MessagesAfter,
+			&participants, &messages, &capturedBy, &bundle.CapturedAt)
+	if err != nil {
+		return nil, err
+	}
+	bundle.ChatId, bundle.ChatIdGroup = chatID.String, chatIDGroup.String
+	if capturedBy.Valid {
+		bundle.CapturedBy = &capturedBy.Int64
+	}
+	if err := json.Unmarshal(participants, &bundle.Participants); err != nil {
+		return nil, fmt.Errorf("error al leer los participantes del reporte %d: %w", reportID, err)
+	}
+	if err := json.Unmarshal(messages, &bundle.Messages); err != nil {
+		return nil, fmt.Errorf("error al leer los mensajes del reporte %d: %w", reportID, err)
+	}
+	return &bundle, nil
+}
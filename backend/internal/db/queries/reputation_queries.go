@@ -1,6 +1,7 @@
 package queries
 
 import (
+	"database/sql"
 	"fmt"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
@@ -31,7 +32,7 @@ func GetReputationStatsByUserID(userID int64) (*models.ReputationStats, error) {
 }
 
 // GetReputationReviewsByUserID recupera una lista de reseñas detalladas para un usuario.
-// Solo incluye reseñas hechas por empresas (RoleId = 3).
+// Solo incluye reseñas hechas por empresas.
 func GetReputationReviewsByUserID(userID int64) ([]models.ReputationReviewInfo, error) {
 	query := `
         SELECT
@@ -42,11 +43,11 @@ func GetReputationReviewsByUserID(userID int64) ([]models.ReputationReviewInfo,
             reviewer.Picture
         FROM ReputationReview rr
         JOIN User reviewer ON rr.ReviewerId = reviewer.Id
-        WHERE rr.RevieweeId = ? AND reviewer.RoleId = 3
+        WHERE rr.RevieweeId = ? AND reviewer.RoleId = ?
         ORDER BY rr.CreatedAt DESC
     `
 
-	rows, err := DB.Query(query, userID)
+	rows, err := DB.Query(query, userID, models.RoleBusiness)
 	if err != nil {
 		return nil, fmt.Errorf("error al consultar reseñas para el usuario %d: %w", userID, err)
 	}
@@ -75,7 +76,7 @@ func GetReputationReviewsByUserID(userID int64) ([]models.ReputationReviewInfo,
 }
 
 // GetReputationReviewsForCompanyByUserID recupera una lista de reseñas detalladas para una empresa.
-// Solo incluye reseñas hechas por estudiantes o egresados (RoleId = 1 o 2).
+// Solo incluye reseñas hechas por estudiantes o egresados.
 func GetReputationReviewsForCompanyByUserID(userID int64) ([]models.CompanyReputationReviewInfo, error) {
 	query := `
         SELECT
@@ -117,3 +118,229 @@ func GetReputationReviewsForCompanyByUserID(userID int64) ([]models.CompanyReput
 
 	return reviews, nil
 }
+
+// Grupos válidos para el ranking de reputación.
+const (
+	LeaderboardStudents  = "students"
+	LeaderboardCompanies = "companies"
+)
+
+// leaderboardRoleFilters mapea cada grupo del ranking a la función que construye su filtro de
+// roles. Los fragmentos nunca provienen de la entrada del usuario.
+var leaderboardRoleFilters = map[string]func(column string) string{
+	LeaderboardStudents:  studentRoleIn,
+	LeaderboardCompanies: businessRoleIs,
+}
+
+// IsValidLeaderboardGroup indica si el grupo solicitado existe.
+func IsValidLeaderboardGroup(group string) bool {
+	_, ok := leaderboardRoleFilters[group]
+	return ok
+}
+
+// LeaderboardGroupForRole devuelve el grupo del ranking al que pertenece un rol.
+func LeaderboardGroupForRole(roleID int) string {
+	if roleID == int(models.RoleBusiness) {
+		return LeaderboardCompanies
+	}
+	return LeaderboardStudents
+}
+
+// GetReputationSummaryByUserID recupera el total de RP, el número de reseñas y la
+// calificación promedio de un usuario.
+func GetReputationSummaryByUserID(userID int64) (*models.ReputationSummary, error) {
+	query := `
+        SELECT
+            COUNT(*),
+            COALESCE(SUM(PointsRP), 0),
+            COALESCE(AVG(Rating), 0)
+        FROM ReputationReview
+        WHERE RevieweeId = ?
+    `
+	summary := models.ReputationSummary{UserID: userID}
+	err := DB.QueryRow(query, userID).Scan(
+		&summary.ReviewCount,
+		&summary.TotalPointsRP,
+		&summary.AverageRating,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el resumen de reputación del usuario %d: %w", userID, err)
+	}
+	return &summary, nil
+}
+
+// GetAverageRatingByInteractionType recupera la calificación promedio de un usuario
+// agrupada por tipo de interacción.
+func GetAverageRatingByInteractionType(userID int64) ([]models.InteractionRating, error) {
+	query := `
+        SELECT
+            COALESCE(InteractionType, 'SIN_TIPO'),
+            COALESCE(AVG(Rating), 0),
+            COUNT(*)
+        FROM ReputationReview
+        WHERE RevieweeId = ?
+        GROUP BY InteractionType
+        ORDER BY COUNT(*) DESC
+    `
+	rows, err := DB.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar calificaciones por tipo de interacción del usuario %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	ratings := []models.InteractionRating{}
+	for rows.Next() {
+		var rating models.InteractionRating
+		if err := rows.Scan(&rating.InteractionType, &rating.AverageRating, &rating.ReviewCount); err != nil {
+			return nil, fmt.Errorf("error al escanear calificación por tipo de interacción: %w", err)
+		}
+		ratings = append(ratings, rating)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar calificaciones por tipo de interacción: %w", err)
+	}
+	return ratings, nil
+}
+
+// GetReputationPercentile calcula el percentil (0-100) de un total de RP dentro de un grupo
+// del ranking, es decir, el porcentaje de usuarios reseñados del grupo con menos RP.
+func GetReputationPercentile(group string, totalPointsRP int) (float64, error) {
+	filter, ok := leaderboardRoleFilters[group]
+	if !ok {
+		return 0, fmt.Errorf("grupo de ranking no válido: %s", group)
+	}
+
+	query := `
+        WITH Totals AS (
+            SELECT rr.RevieweeId, SUM(rr.PointsRP) AS Total
+            FROM ReputationReview rr
+            JOIN User u ON rr.RevieweeId = u.Id
+            WHERE ` + filter("u.RoleId") + `
+            GROUP BY rr.RevieweeId
+        )
+        SELECT COUNT(*), COALESCE(SUM(Total < ?), 0) FROM Totals
+    `
+	var total, below int
+	if err := DB.QueryRow(query, totalPointsRP).Scan(&total, &below); err != nil {
+		return 0, fmt.Errorf("error al calcular el percentil de reputación: %w", err)
+	}
+	if total == 0 {
+		return 0, nil
+	}
+	return float64(below) * 100 / float64(total), nil
+}
+
+// GetReputationLeaderboard recupera las primeras `limit` posiciones del ranking de un grupo,
+// ordenadas por RP total y calificación promedio.
+func GetReputationLeaderboard(group string, limit int) ([]models.LeaderboardEntry, error) {
+	filter, ok := leaderboardRoleFilters[group]
+	if !ok {
+		return nil, fmt.Errorf("grupo de ranking no válido: %s", group)
+	}
+
+	query := `
+        SELECT
+            u.Id,
+            CASE
+                WHEN ` + businessRoleIs("u.RoleId") + ` THEN COALESCE(u.CompanyName, u.UserName, '')
+                ELSE TRIM(CONCAT(COALESCE(u.FirstName, ''), ' ', COALESCE(u.LastName, '')))
+            END AS DisplayName,
+            COALESCE(u.Picture, ''),
+            u.RoleId,
+            SUM(rr.PointsRP) AS TotalPointsRP,
+            COALESCE(AVG(rr.Rating), 0) AS AverageRating,
+            COUNT(*) AS ReviewCount
+        FROM ReputationReview rr
+        JOIN User u ON rr.RevieweeId = u.Id
+        WHERE ` + filter("u.RoleId") + `
+        GROUP BY u.Id, u.RoleId, u.CompanyName, u.UserName, u.FirstName, u.LastName, u.Picture
+        ORDER BY TotalPointsRP DESC, AverageRating DESC, u.Id ASC
+        LIMIT ?
+    `
+	rows, err := DB.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar el ranking de reputación (%s): %w", group, err)
+	}
+	defer rows.Close()
+
+	entries := []models.LeaderboardEntry{}
+	for rows.Next() {
+		var entry models.LeaderboardEntry
+		if err := rows.Scan(
+			&entry.UserID,
+			&entry.DisplayName,
+			&entry.Picture,
+			&entry.RoleID,
+			&entry.TotalPointsRP,
+			&entry.AverageRating,
+			&entry.ReviewCount,
+		); err != nil {
+			return nil, fmt.Errorf("error al escanear posición del ranking: %w", err)
+		}
+		entry.Rank = len(entries) + 1
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar el ranking de reputación: %w", err)
+	}
+	return entries, nil
+}
+
+// HasEventInteraction verifica que el revisor y el revisado hayan interactuado a través del
+// evento comunitario indicado: uno de los dos debe ser el creador u organizador del evento y el
+// otro debe tener una postulación que haya avanzado en el proceso (entrevista, prueba técnica,
+// oferta o aprobada). En un desafío también cuenta una entrega APROBADA del otro usuario.
+func HasEventInteraction(communityEventID, reviewerID, revieweeID int64) (bool, error) {
+	query := `
+        SELECT EXISTS(
+            SELECT 1
+            FROM CommunityEvent ce
+            JOIN JobApplication ja ON ja.CommunityEventId = ce.Id
+            WHERE ce.Id = ?
+              AND ja.Status IN ('ENTREVISTA', 'PRUEBA_TECNICA', 'OFERTA_REALIZADA', 'APROBADA')
+              AND (
+                    ((ce.CreatedByUserId = ? OR ce.OrganizerUserId = ?) AND ja.ApplicantId = ?)
+                 OR ((ce.CreatedByUserId = ? OR ce.OrganizerUserId = ?) AND ja.ApplicantId = ?)
+              )
+        ) OR EXISTS(
+            SELECT 1
+            FROM CommunityEvent ce
+            JOIN ChallengeSubmission cs ON cs.CommunityEventId = ce.Id
+            WHERE ce.Id = ?
+              AND cs.Status = 'APROBADA'
+              AND (
+                    (ce.CreatedByUserId = ? AND cs.UserId = ?)
+                 OR (ce.CreatedByUserId = ? AND cs.UserId = ?)
+              )
+        )
+    `
+	var exists bool
+	err := DB.QueryRow(query,
+		communityEventID,
+		reviewerID, reviewerID, revieweeID,
+		revieweeID, revieweeID, reviewerID,
+		communityEventID,
+		reviewerID, revieweeID,
+		revieweeID, reviewerID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error al verificar la interacción entre %d y %d en el evento %d: %w", reviewerID, revieweeID, communityEventID, err)
+	}
+	return exists, nil
+}
+
+// CreateReputationReview inserta una nueva reseña. InteractionType se guarda como NULL si viene vacío.
+// Devuelve el ID de la reseña creada.
+func CreateReputationReview(reviewerID int64, req models.CreateReviewRequest, pointsRP int) (int64, error) {
+	query := `
+        INSERT INTO ReputationReview (ReviewerId, RevieweeId, CommunityEventId, PointsRP, Rating, Comment, InteractionType)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
+    `
+	interactionType := sql.NullString{String: req.InteractionType, Valid: req.InteractionType != ""}
+
+	result, err := DB.Exec(query, reviewerID, req.RevieweeID, req.CommunityEventId, pointsRP, req.Rating, req.Comment, interactionType)
+	if err != nil {
+		return 0, fmt.Errorf("error al insertar la reseña: %w", err)
+	}
+	return result.LastInsertId()
+}
@@ -0,0 +1,277 @@
+package queries
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+const savedSearchColumns = `Id, UserId, Name, SearchType, Query, Filters, AlertsEnabled, LastSeenItemId, LastAlertAt, CreatedAt, UpdatedAt`
+
+// scanSavedSearch lee una fila con las columnas de savedSearchColumns.
+func scanSavedSearch(scanner interface{ Scan(...interface{}) error }) (models.SavedSearch, error) {
+	var search models.SavedSearch
+	var filters []byte
+	var lastAlertAt sql.NullTime
+	err := scanner.Scan(&search.Id, &search.UserId, &search.Name, &search.SearchType, &search.Query, &filters,
+		&search.AlertsEnabled, &search.LastSeenItemId, &lastAlertAt, &search.CreatedAt, &search.UpdatedAt)
+	if err != nil {
+		return search, err
+	}
+	if lastAlertAt.Valid {
+		search.LastAlertAt = &lastAlertAt.Time
+	}
+	if err := json.Unmarshal(filters, &search.Filters); err != nil {
+		return search, fmt.Errorf("error al leer los filtros de la búsqueda %d: %w", search.Id, err)
+	}
+	return search, nil
+}
+
+// ListSavedSearches devuelve las búsquedas guardadas del usuario, de la más reciente a la más
+// antigua.
+func ListSavedSearches(userID int64) ([]models.SavedSearch, error) {
+	rows, err := DB.Query(`SELECT `+savedSearchColumns+` FROM SavedSearch WHERE UserId = ? ORDER BY Id DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar las búsquedas guardadas del usuario %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	searches := []models.SavedSearch{}
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al leer las búsquedas guardadas: %w", err)
+		}
+		searches = append(searches, search)
+	}
+	return searches, rows.Err()
+}
+
+// GetSavedSearch devuelve una búsqueda guardada del usuario. Si no existe o es de otro usuario
+// devuelve sql.ErrNoRows.
+func GetSavedSearch(id, userID int64) (*models.SavedSearch, error) {
+	search, err := scanSavedSearch(DB.QueryRow(`SELECT `+savedSearchColumns+` FROM SavedSearch WHERE Id = ? AND UserId = ?`, id, userID))
+	if err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+// CountSavedSearches devuelve cuántas búsquedas tiene guardadas el usuario.
+func CountSavedSearches(userID int64) (int, error) {
+	var count int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM SavedSearch WHERE UserId = ?`, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error al contar las búsquedas guardadas del usuario %d: %w", userID, err)
+	}
+	return count, nil
+}
+
+// InsertSavedSearch crea una búsqueda guardada y devuelve su ID.
+func InsertSavedSearch(search models.SavedSearch) (int64, error) {
+	filters, err := json.Marshal(search.Filters)
+	if err != nil {
+		return 0, fmt.Errorf("error al serializar los filtros: %w", err)
+	}
+	result, err := DB.Exec(`
+		INSERT INTO SavedSearch (UserId, Name, SearchType, Query, Filters, AlertsEnabled, LastSeenItemId)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		search.UserId, search.Name, search.SearchType, search.Query, filters, search.AlertsEnabled, search.LastSeenItemId)
+	if err != nil {
+		return 0, fmt.Errorf("error al guardar la búsqueda del usuario %d: %w", search.UserId, err)
+	}
+	return result.LastInsertId()
+}
+
+// UpdateSavedSearch reemplaza los criterios de una búsqueda guardada del usuario. La marca de
+// elementos evaluados solo avanza: nunca se vuelve a avisar de un elemento ya evaluado. Si la
+// búsqueda no existe devuelve sql.ErrNoRows.
+func UpdateSavedSearch(search models.SavedSearch) error {
+	filters, err := json.Marshal(search.Filters)
+	if err != nil {
+		return fmt.Errorf("error al serializar los filtros: %w", err)
+	}
+	result, err := DB.Exec(`
+		UPDATE SavedSearch
+		SET Name = ?, SearchType = ?, Query = ?, Filters = ?, AlertsEnabled = ?,
+			LastSeenItemId = GREATEST(LastSeenItemId, ?), UpdatedAt = NOW()
+		WHERE Id = ? AND UserId = ?`,
+		search.Name, search.SearchType, search.Query, filters, search.AlertsEnabled, search.LastSeenItemId,
+		search.Id, search.UserId)
+	if err != nil {
+		return fmt.Errorf("error al actualizar la búsqueda %d: %w", search.Id, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteSavedSearch elimina una búsqueda guardada del usuario. Devuelve false si no existía.
+func DeleteSavedSearch(id, userID int64) (bool, error) {
+	result, err := DB.Exec(`DELETE FROM SavedSearch WHERE Id = ? AND UserId = ?`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("error al eliminar la búsqueda %d: %w", id, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error al eliminar la búsqueda %d: %w", id, err)
+	}
+	return affected > 0, nil
+}
+
+// GetSavedSearchMaxItemID devuelve el mayor ID de los elementos que puede evaluar una búsqueda
+// del tipo indicado: publicaciones (JOBS) o usuarios registrados hasta createdBefore (PEOPLE).
+func GetSavedSearchMaxItemID(searchType string, createdBefore time.Time) (int64, error) {
+	var maxID int64
+	var err error
+	if searchType == models.SavedSearchTypePeople {
+		err = DB.QueryRow(`SELECT COALESCE(MAX(Id), 0) FROM User WHERE CreatedAt <= ?`, createdBefore).Scan(&maxID)
+	} else {
+		err = DB.QueryRow(`SELECT COALESCE(MAX(Id), 0) FROM CommunityEvent`).Scan(&maxID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error al consultar el último elemento de tipo %s: %w", searchType, err)
+	}
+	return maxID, nil
+}
+
+// GetSavedSearchesToEvaluate devuelve hasta limit búsquedas del tipo indicado con alertas
+// activadas, de usuarios activos, que tienen elementos sin evaluar hasta maxItemID. Se recorren
+// por ID a partir de afterID.
+func GetSavedSearchesToEvaluate(searchType string, maxItemID, afterID int64, limit int) ([]models.SavedSearch, error) {
+	rows, err := DB.Query(`
+		SELECT s.Id, s.UserId, s.Name, s.SearchType, s.Query, s.Filters, s.AlertsEnabled, s.LastSeenItemId,
+			s.LastAlertAt, s.CreatedAt, s.UpdatedAt
+		FROM SavedSearch s
+		JOIN User u ON u.Id = s.UserId AND u.StatusAuthorizedId = ?
+		WHERE s.SearchType = ? AND s.AlertsEnabled = TRUE AND s.LastSeenItemId < ? AND s.Id > ?
+		ORDER BY s.Id ASC
+		LIMIT ?`, models.UserStatusActive, searchType, maxItemID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar las búsquedas a evaluar: %w", err)
+	}
+	defer rows.Close()
+
+	searches := []models.SavedSearch{}
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al leer las búsquedas a evaluar: %w", err)
+		}
+		searches = append(searches, search)
+	}
+	return searches, rows.Err()
+}
+
+// FindSavedSearchMatches devuelve, en orden de ID, hasta limit elementos posteriores a la marca
+// de la búsqueda y no mayores que maxItemID que cumplen sus criterios. Cada palabra de la
+// consulta y cada filtro deben cumplirse; se excluyen las publicaciones y el perfil del propio
+// usuario y los de usuarios con los que tiene un bloqueo.
+func FindSavedSearchMatches(search models.SavedSearch, maxItemID int64, limit int) ([]int64, error) {
+	var query string
+	var conditions []string
+	args := []interface{}{search.LastSeenItemId, maxItemID, search.UserId}
+	filters := search.Filters
+
+	if search.SearchType == models.SavedSearchTypePeople {
+		query = `SELECT u.Id FROM User u WHERE u.Id > ? AND u.Id <= ? AND u.Id <> ? AND ` + activeStatusIs("u.StatusAuthorizedId")
+		conditions = append(conditions, NotBlockedCondition("u.Id"))
+		args = append(args, search.UserId, search.UserId)
+		if filters.RoleId > 0 {
+			conditions = append(conditions, `u.RoleId = ?`)
+			args = append(args, filters.RoleId)
+		} else {
+			conditions = append(conditions, memberRoleIn("u.RoleId"))
+		}
+		for _, word := range strings.Fields(search.Query) {
+			conditions = append(conditions, `(u.FirstName LIKE ? OR u.LastName LIKE ? OR u.UserName LIKE ? OR u.CompanyName LIKE ? OR u.Summary LIKE ?)`)
+			pattern := "%" + escapeLike(word) + "%"
+			args = append(args, pattern, pattern, pattern, pattern, pattern)
+		}
+		if filters.Degree != "" {
+			conditions = append(conditions, `EXISTS (SELECT 1 FROM Education e WHERE e.PersonId = u.Id AND e.Degree LIKE ?)`)
+			args = append(args, "%"+escapeLike(filters.Degree)+"%")
+		}
+		if filters.University != "" {
+			conditions = append(conditions, `EXISTS (SELECT 1 FROM Education e WHERE e.PersonId = u.Id AND e.Institution LIKE ?)`)
+			args = append(args, "%"+escapeLike(filters.University)+"%")
+		}
+		if filters.Location != "" {
+			conditions = append(conditions, `(u.Location LIKE ? OR u.Address LIKE ?)`)
+			pattern := "%" + escapeLike(filters.Location) + "%"
+			args = append(args, pattern, pattern)
+		}
+		for _, skill := range filters.Skills {
+			conditions = append(conditions, `EXISTS (SELECT 1 FROM Skills s WHERE s.PersonId = u.Id AND LOWER(s.Skill) = ?)`)
+			args = append(args, strings.ToLower(skill))
+		}
+		query += ` AND ` + strings.Join(conditions, ` AND `) + ` ORDER BY u.Id ASC LIMIT ?`
+	} else {
+		query = `SELECT ce.Id FROM CommunityEvent ce WHERE ce.Id > ? AND ce.Id <= ? AND ce.CreatedByUserId <> ?`
+		conditions = append(conditions, NotBlockedCondition("ce.CreatedByUserId"))
+		args = append(args, search.UserId, search.UserId)
+		if filters.PostType != "" {
+			conditions = append(conditions, `ce.PostType = ?`)
+			args = append(args, filters.PostType)
+		}
+		for _, word := range strings.Fields(search.Query) {
+			conditions = append(conditions, `(ce.Title LIKE ? OR ce.Description LIKE ?)`)
+			pattern := "%" + escapeLike(word) + "%"
+			args = append(args, pattern, pattern)
+		}
+		if filters.Location != "" {
+			conditions = append(conditions, `ce.Location LIKE ?`)
+			args = append(args, "%"+escapeLike(filters.Location)+"%")
+		}
+		// Una habilidad coincide con las etiquetas de la publicación o con los requisitos de la
+		// oferta.
+		for _, skill := range filters.Skills {
+			conditions = append(conditions, `(JSON_SEARCH(LOWER(ce.Tags), 'one', ?) IS NOT NULL OR EXISTS (
+				SELECT 1 FROM JobPostingRequirements r
+				WHERE r.CommunityEventId = ce.Id AND JSON_SEARCH(LOWER(r.Skills), 'one', ?, NULL, '$[*].name') IS NOT NULL))`)
+			args = append(args, strings.ToLower(skill), strings.ToLower(skill))
+		}
+		query += ` AND ` + strings.Join(conditions, ` AND `) + ` ORDER BY ce.Id ASC LIMIT ?`
+	}
+	args = append(args, limit)
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar coincidencias de la búsqueda %d: %w", search.Id, err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error al leer las coincidencias de la búsqueda %d: %w", search.Id, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// AdvanceSavedSearch mueve la marca de la búsqueda de fromItemID a toItemID y, si hubo
+// coincidencias, registra la fecha de la alerta. Devuelve false si la marca ya no era
+// fromItemID (otra instancia evaluó la búsqueda o el usuario la editó): en ese caso no se debe
+// avisar. UpdatedAt no cambia: refleja la última edición del usuario.
+func AdvanceSavedSearch(id, fromItemID, toItemID int64, alerted bool) (bool, error) {
+	query := `UPDATE SavedSearch SET LastSeenItemId = ?, UpdatedAt = UpdatedAt WHERE Id = ? AND LastSeenItemId = ?`
+	if alerted {
+		query = `UPDATE SavedSearch SET LastSeenItemId = ?, LastAlertAt = NOW(), UpdatedAt = UpdatedAt WHERE Id = ? AND LastSeenItemId = ?`
+	}
+	result, err := DB.Exec(query, toItemID, id, fromItemID)
+	if err != nil {
+		return false, fmt.Errorf("error al actualizar la marca de la búsqueda %d: %w", id, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error al actualizar la marca de la búsqueda %d: %w", id, err)
+	}
+	return affected > 0, nil
+}
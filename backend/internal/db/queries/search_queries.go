@@ -59,6 +59,7 @@ NORMAS Y DIRECTRICES PARA ESTE ARCHIVO:
 //
 // Parámetros:
 //   - currentUserID: ID del usuario actual.
+//   - tenantID: Institución de la conexión; con 0 no se limita (ver TenantCondition).
 //   - searchTerm: Término de búsqueda.
 //   - limit: Número máximo de resultados a devolver.
 //   - offset: Número de resultados a omitir.
@@ -66,7 +67,7 @@ NORMAS Y DIRECTRICES PARA ESTE ARCHIVO:
 // Retorna:
 //   - Una lista de usuarios (`[]models.User`) que coinciden con el término de búsqueda.
 //   - Un error si la consulta falla.
-func SearchAll(currentUserID int64, searchTerm string, limit, offset int) ([]models.User, error) {
+func SearchAll(currentUserID, tenantID int64, searchTerm string, limit, offset int) ([]models.User, error) {
 	query := `
 	SELECT
 		u.Id,
@@ -96,15 +97,17 @@ func SearchAll(currentUserID int64, searchTerm string, limit, offset int) ([]mod
 	LEFT JOIN Contact c ON ((c.User1Id = ? AND c.User2Id = u.Id) OR (c.User1Id = u.Id AND c.User2Id = ?)) AND c.Status = 'accepted'
 	WHERE
 		u.Id != ? AND
+		` + NotBlockedCondition("u.Id") + ` AND
+		` + TenantCondition("u.TenantId", tenantID) + ` AND
 		(
-			(u.RoleId IN (1, 2) AND (
+			(` + studentRoleIn("u.RoleId") + ` AND (
 				u.UserName LIKE ? OR
 				u.FirstName LIKE ? OR
 				u.LastName LIKE ? OR
 				e.Institution LIKE ? OR
 				e.Degree LIKE ?
 			)) OR
-			(u.RoleId = 3 AND (
+			(` + businessRoleIs("u.RoleId") + ` AND (
 				u.CompanyName LIKE ? OR
 				u.Sector LIKE ?
 			))
@@ -113,7 +116,7 @@ func SearchAll(currentUserID int64, searchTerm string, limit, offset int) ([]mod
 `
 
 	likeTerm := "%" + searchTerm + "%"
-	rows, err := DB.Query(query, currentUserID, currentUserID, currentUserID, likeTerm, likeTerm, likeTerm, likeTerm, likeTerm, likeTerm, likeTerm, limit, offset)
+	rows, err := DB.Query(query, currentUserID, currentUserID, currentUserID, currentUserID, currentUserID, likeTerm, likeTerm, likeTerm, likeTerm, likeTerm, likeTerm, likeTerm, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("error al ejecutar la consulta de búsqueda 'all': %w", err)
 	}
@@ -0,0 +1,246 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/cache"
+)
+
+// El catálogo de habilidades se cachea con el resto de catálogos (CACHE_CATALOG_TTL). El
+// número de usos de cada habilidad solo ordena las sugerencias, así que puede ir retrasado
+// hasta que la caché vence.
+
+// GetSkillCatalog devuelve todas las habilidades canónicas con sus alias y el número de
+// habilidades de usuarios asociadas, ordenadas por nombre.
+func GetSkillCatalog() ([]models.SkillCatalogItem, error) {
+	return cache.Load(queryCache, catalogCacheKey("skills"), cacheTTL.catalog, func() ([]models.SkillCatalogItem, error) {
+		rows, err := DB.Query(`
+			SELECT c.Id, c.Name, c.Category, COUNT(s.Id)
+			FROM SkillCatalog c
+			LEFT JOIN Skills s ON s.SkillCatalogId = c.Id
+			GROUP BY c.Id, c.Name, c.Category
+			ORDER BY c.Name`)
+		if err != nil {
+			return nil, fmt.Errorf("error consultando el catálogo de habilidades: %w", err)
+		}
+		defer rows.Close()
+
+		items := []models.SkillCatalogItem{}
+		positions := make(map[int64]int)
+		for rows.Next() {
+			item := models.SkillCatalogItem{Aliases: []string{}}
+			if err := rows.Scan(&item.Id, &item.Name, &item.Category, &item.UsageCount); err != nil {
+				return nil, fmt.Errorf("error escaneando habilidad del catálogo: %w", err)
+			}
+			positions[item.Id] = len(items)
+			items = append(items, item)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterando el catálogo de habilidades: %w", err)
+		}
+
+		aliasRows, err := DB.Query("SELECT SkillId, Alias FROM SkillAlias ORDER BY Alias")
+		if err != nil {
+			return nil, fmt.Errorf("error consultando alias de habilidades: %w", err)
+		}
+		defer aliasRows.Close()
+		for aliasRows.Next() {
+			var skillID int64
+			var alias string
+			if err := aliasRows.Scan(&skillID, &alias); err != nil {
+				return nil, fmt.Errorf("error escaneando alias de habilidad: %w", err)
+			}
+			if pos, ok := positions[skillID]; ok {
+				items[pos].Aliases = append(items[pos].Aliases, alias)
+			}
+		}
+		return items, aliasRows.Err()
+	})
+}
+
+// SkillCatalogNameTaken indica si otra habilidad distinta de excludeID usa el nombre o alias
+// normalizado key.
+func SkillCatalogNameTaken(key string, excludeID int64) (bool, error) {
+	var taken bool
+	err := DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM SkillCatalog WHERE NormalizedName = ? AND Id <> ?)
+			OR EXISTS(SELECT 1 FROM SkillAlias WHERE Alias = ? AND SkillId <> ?)`,
+		key, excludeID, key, excludeID).Scan(&taken)
+	if err != nil {
+		return false, fmt.Errorf("error al comprobar la habilidad %s: %w", key, err)
+	}
+	return taken, nil
+}
+
+// InsertSkillCatalogItem crea una habilidad canónica con sus alias (ya normalizados) y
+// devuelve su ID.
+func InsertSkillCatalogItem(item models.SkillCatalogItem, normalizedName string) (int64, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("INSERT INTO SkillCatalog (Name, NormalizedName, Category) VALUES (?, ?, ?)",
+		item.Name, normalizedName, item.Category)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := insertSkillAliases(tx, id, item.Aliases); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	InvalidateCatalogCache()
+	return id, nil
+}
+
+// UpdateSkillCatalogItem reemplaza el nombre, la categoría y los alias de la habilidad y
+// renombra las habilidades de usuarios asociadas. Si no existe devuelve sql.ErrNoRows.
+func UpdateSkillCatalogItem(item models.SkillCatalogItem, normalizedName string) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM SkillCatalog WHERE Id = ?)", item.Id).Scan(&exists); err != nil {
+		return fmt.Errorf("error al comprobar la habilidad %d: %w", item.Id, err)
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.Exec("UPDATE SkillCatalog SET Name = ?, NormalizedName = ?, Category = ? WHERE Id = ?",
+		item.Name, normalizedName, item.Category, item.Id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM SkillAlias WHERE SkillId = ?", item.Id); err != nil {
+		return fmt.Errorf("error al borrar los alias de la habilidad %d: %w", item.Id, err)
+	}
+	if err := insertSkillAliases(tx, item.Id, item.Aliases); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE Skills SET Skill = ? WHERE SkillCatalogId = ?", item.Name, item.Id); err != nil {
+		return fmt.Errorf("error al renombrar las habilidades de la habilidad %d: %w", item.Id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	InvalidateCatalogCache()
+	return nil
+}
+
+func insertSkillAliases(tx *sql.Tx, skillID int64, aliases []string) error {
+	for _, alias := range aliases {
+		if _, err := tx.Exec("INSERT INTO SkillAlias (Alias, SkillId) VALUES (?, ?)", alias, skillID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteSkillCatalogItem elimina la habilidad y sus alias. Las habilidades de usuarios
+// asociadas conservan su texto y quedan sin SkillCatalogId. Si no existe devuelve
+// sql.ErrNoRows.
+func DeleteSkillCatalogItem(id int64) error {
+	return catalogWrite("SkillCatalog", id, "DELETE FROM SkillCatalog WHERE Id = ?", id)
+}
+
+// GetUnmappedSkills devuelve los textos de habilidad sin habilidad canónica, ordenados por el
+// número de usuarios que los usan.
+func GetUnmappedSkills(limit int) ([]models.UnmappedSkill, error) {
+	rows, err := DB.Query(`
+		SELECT Skill, COUNT(DISTINCT PersonId) AS Users
+		FROM Skills
+		WHERE SkillCatalogId IS NULL AND Skill IS NOT NULL AND Skill <> ''
+		GROUP BY Skill
+		ORDER BY Users DESC, Skill
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando habilidades sin asociar: %w", err)
+	}
+	defer rows.Close()
+
+	skills := []models.UnmappedSkill{}
+	for rows.Next() {
+		var skill models.UnmappedSkill
+		if err := rows.Scan(&skill.Skill, &skill.Count); err != nil {
+			return nil, fmt.Errorf("error escaneando habilidad sin asociar: %w", err)
+		}
+		skills = append(skills, skill)
+	}
+	return skills, rows.Err()
+}
+
+// GetUnmappedSkillRows devuelve hasta limit habilidades de usuarios sin SkillCatalogId con
+// Id mayor que afterID, en orden de Id. Se usa para recorrer la tabla por lotes.
+func GetUnmappedSkillRows(afterID int64, limit int) ([]models.Skills, error) {
+	rows, err := DB.Query(`
+		SELECT Id, PersonId, Skill, Level
+		FROM Skills
+		WHERE Id > ? AND SkillCatalogId IS NULL
+		ORDER BY Id
+		LIMIT ?`, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando habilidades sin asociar: %w", err)
+	}
+	defer rows.Close()
+
+	var skills []models.Skills
+	for rows.Next() {
+		var skill models.Skills
+		var personID sql.NullInt64
+		var name, level sql.NullString
+		if err := rows.Scan(&skill.Id, &personID, &name, &level); err != nil {
+			return nil, fmt.Errorf("error escaneando habilidad: %w", err)
+		}
+		skill.PersonId, skill.Skill, skill.Level = personID.Int64, name.String, level.String
+		skills = append(skills, skill)
+	}
+	return skills, rows.Err()
+}
+
+// AssignSkillCatalog asocia cada habilidad de skills con su SkillCatalogId y le pone el nombre
+// canónico (Skill), en una sola transacción.
+func AssignSkillCatalog(skills []models.Skills) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare("UPDATE Skills SET SkillCatalogId = ?, Skill = ? WHERE Id = ?")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, skill := range skills {
+		if _, err := stmt.Exec(skill.SkillCatalogId, skill.Skill, skill.Id); err != nil {
+			return fmt.Errorf("error al asociar la habilidad %d: %w", skill.Id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// MergeDuplicateSkills elimina las habilidades repetidas de un mismo usuario (misma habilidad
+// canónica), conservando la más antigua, y devuelve cuántas se eliminaron.
+func MergeDuplicateSkills() (int64, error) {
+	result, err := DB.Exec(`
+		DELETE dup FROM Skills dup
+		JOIN Skills kept ON kept.PersonId = dup.PersonId
+			AND kept.SkillCatalogId = dup.SkillCatalogId
+			AND kept.Id < dup.Id`)
+	if err != nil {
+		return 0, fmt.Errorf("error al eliminar habilidades duplicadas: %w", err)
+	}
+	InvalidateCatalogCache()
+	return result.RowsAffected()
+}
@@ -0,0 +1,65 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetUserStorage devuelve los bytes ocupados por el usuario y la cuota fijada por un
+// administrador (no válida si se aplica la del rol). Sin fila el uso es 0.
+func GetUserStorage(userID int64) (int64, sql.NullInt64, error) {
+	var used int64
+	var quota sql.NullInt64
+	err := DB.QueryRow(`SELECT UsedBytes, QuotaBytes FROM UserStorage WHERE UserId = ?`, userID).Scan(&used, &quota)
+	if err == sql.ErrNoRows {
+		return 0, quota, nil
+	}
+	if err != nil {
+		return 0, quota, fmt.Errorf("error obteniendo el almacenamiento del usuario %d: %w", userID, err)
+	}
+	return used, quota, nil
+}
+
+// ReserveUserStorage suma size bytes al uso del usuario si no supera la cuota (la fijada por
+// un administrador o, si no hay, roleQuota; 0 = sin límite). Devuelve false si la supera. La
+// comprobación y la suma son una sola sentencia, así que dos subidas simultáneas no pueden
+// pasarse de la cuota.
+func ReserveUserStorage(userID, size, roleQuota int64) (bool, error) {
+	if _, err := DB.Exec(`INSERT IGNORE INTO UserStorage (UserId) VALUES (?)`, userID); err != nil {
+		return false, fmt.Errorf("error registrando el almacenamiento del usuario %d: %w", userID, err)
+	}
+	result, err := DB.Exec(`
+		UPDATE UserStorage SET UsedBytes = UsedBytes + ?
+		WHERE UserId = ? AND (COALESCE(QuotaBytes, ?) = 0 OR UsedBytes + ? <= COALESCE(QuotaBytes, ?))`,
+		size, userID, roleQuota, size, roleQuota)
+	if err != nil {
+		return false, fmt.Errorf("error reservando %d bytes para el usuario %d: %w", size, userID, err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// AdjustUserStorage suma delta bytes (negativo para liberar) al uso del usuario, sin
+// comprobar la cuota. El uso nunca baja de 0.
+func AdjustUserStorage(userID, delta int64) error {
+	_, err := DB.Exec(`
+		INSERT INTO UserStorage (UserId, UsedBytes) VALUES (?, GREATEST(?, 0))
+		ON DUPLICATE KEY UPDATE UsedBytes = GREATEST(UsedBytes + ?, 0)`,
+		userID, delta, delta)
+	if err != nil {
+		return fmt.Errorf("error ajustando el almacenamiento del usuario %d en %d bytes: %w", userID, delta, err)
+	}
+	return nil
+}
+
+// SetUserStorageQuota fija la cuota del usuario. quota nil vuelve a la del rol.
+func SetUserStorageQuota(userID int64, quota *int64) error {
+	_, err := DB.Exec(`
+		INSERT INTO UserStorage (UserId, QuotaBytes) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE QuotaBytes = VALUES(QuotaBytes)`,
+		userID, quota)
+	if err != nil {
+		return fmt.Errorf("error fijando la cuota de almacenamiento del usuario %d: %w", userID, err)
+	}
+	return nil
+}
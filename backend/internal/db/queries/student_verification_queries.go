@@ -0,0 +1,169 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+// verifiedStudentCondition es la condición SQL de "el usuario %s tiene la insignia de
+// estudiante o egresado verificado". El primer %s es la columna con el Id del usuario y el
+// segundo, la condición de rol (studentRoleIn).
+const verifiedStudentCondition = `EXISTS (
+	SELECT 1 FROM StudentVerification sv JOIN User svu ON svu.Id = sv.UserId
+	WHERE sv.UserId = %s AND sv.VerifiedAt IS NOT NULL AND %s)`
+
+// VerifiedStudentCondition devuelve la condición SQL que filtra los usuarios verificados;
+// userColumn es la columna con el Id del usuario (p. ej. "u.Id").
+func VerifiedStudentCondition(userColumn string) string {
+	return fmt.Sprintf(verifiedStudentCondition, userColumn, studentRoleIn("svu.RoleId"))
+}
+
+// StudentVerificationRecord es la fila de StudentVerification de un usuario.
+type StudentVerificationRecord struct {
+	UserId              int64
+	Email               sql.NullString
+	UniversityId        sql.NullInt64
+	UniversityName      sql.NullString
+	VerifiedAt          sql.NullTime
+	PendingEmail        sql.NullString
+	PendingUniversityId sql.NullInt64
+	PendingUniversity   sql.NullString
+	CodeHash            sql.NullString
+	CodeSentAt          sql.NullTime
+	CodeExpiresAt       sql.NullTime
+	Attempts            int
+}
+
+// GetStudentVerification devuelve la verificación de estudiante del usuario, o nil si nunca
+// la solicitó.
+func GetStudentVerification(userID int64) (*StudentVerificationRecord, error) {
+	r := StudentVerificationRecord{UserId: userID}
+	err := DB.QueryRow(`
+		SELECT sv.Email, sv.UniversityId, u.Name, sv.VerifiedAt,
+			sv.PendingEmail, sv.PendingUniversityId, pu.Name,
+			sv.CodeHash, sv.CodeSentAt, sv.CodeExpiresAt, sv.Attempts
+		FROM StudentVerification sv
+		LEFT JOIN University u ON u.Id = sv.UniversityId
+		LEFT JOIN University pu ON pu.Id = sv.PendingUniversityId
+		WHERE sv.UserId = ?`, userID).Scan(
+		&r.Email, &r.UniversityId, &r.UniversityName, &r.VerifiedAt,
+		&r.PendingEmail, &r.PendingUniversityId, &r.PendingUniversity,
+		&r.CodeHash, &r.CodeSentAt, &r.CodeExpiresAt, &r.Attempts)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la verificación de estudiante del usuario %d: %w", userID, err)
+	}
+	return &r, nil
+}
+
+// StudentEmailVerifiedByOther indica si otro usuario ya verificó el correo.
+func StudentEmailVerifiedByOther(email string, userID int64) (bool, error) {
+	var taken bool
+	err := DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM StudentVerification WHERE Email = ? AND UserId <> ?)`,
+		email, userID).Scan(&taken)
+	if err != nil {
+		return false, fmt.Errorf("error al comprobar el correo institucional: %w", err)
+	}
+	return taken, nil
+}
+
+// SaveStudentVerificationCode guarda el código enviado a email y reinicia los intentos. Una
+// verificación confirmada anterior se conserva hasta que se confirme el código nuevo.
+func SaveStudentVerificationCode(userID int64, email string, universityID int64, codeHash string, sentAt, expiresAt time.Time) error {
+	_, err := DB.Exec(`
+		INSERT INTO StudentVerification
+			(UserId, PendingEmail, PendingUniversityId, CodeHash, CodeSentAt, CodeExpiresAt, Attempts)
+		VALUES (?, ?, ?, ?, ?, ?, 0)
+		ON DUPLICATE KEY UPDATE
+			PendingEmail = VALUES(PendingEmail), PendingUniversityId = VALUES(PendingUniversityId),
+			CodeHash = VALUES(CodeHash), CodeSentAt = VALUES(CodeSentAt),
+			CodeExpiresAt = VALUES(CodeExpiresAt), Attempts = 0`,
+		userID, email, universityID, codeHash, sentAt.UTC(), expiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("error al guardar el código de verificación del usuario %d: %w", userID, err)
+	}
+	return nil
+}
+
+// AddStudentVerificationAttempt suma un intento fallido al código pendiente del usuario.
+func AddStudentVerificationAttempt(userID int64) error {
+	_, err := DB.Exec(`UPDATE StudentVerification SET Attempts = Attempts + 1 WHERE UserId = ?`, userID)
+	if err != nil {
+		return fmt.Errorf("error al registrar el intento de verificación del usuario %d: %w", userID, err)
+	}
+	return nil
+}
+
+// ConfirmStudentVerification convierte el código pendiente del usuario en su verificación
+// confirmada. Si otro usuario ya verificó el mismo correo, falla con la clave duplicada
+// (1062) del índice único de Email.
+func ConfirmStudentVerification(userID int64, verifiedAt time.Time) error {
+	result, err := DB.Exec(`
+		UPDATE StudentVerification SET
+			Email = PendingEmail, UniversityId = PendingUniversityId, VerifiedAt = ?,
+			PendingEmail = NULL, PendingUniversityId = NULL,
+			CodeHash = NULL, CodeSentAt = NULL, CodeExpiresAt = NULL, Attempts = 0
+		WHERE UserId = ? AND PendingEmail IS NOT NULL AND PendingUniversityId IS NOT NULL`,
+		verifiedAt.UTC(), userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetStudentBadges devuelve la insignia de estudiante o egresado verificado de los usuarios
+// que la tienen. Solo los estudiantes y egresados la conservan: si el rol cambia, deja de
+// mostrarse.
+func GetStudentBadges(userIDs []int64) (map[int64]models.StudentBadge, error) {
+	badges := make(map[int64]models.StudentBadge)
+	err := forEachIDChunk(uniqueIDs(userIDs), func(chunk []int64, args []interface{}) error {
+		rows, err := DB.Query(`
+			SELECT sv.UserId, u.RoleId, sv.UniversityId, un.Name, sv.VerifiedAt
+			FROM StudentVerification sv
+			JOIN User u ON u.Id = sv.UserId
+			JOIN University un ON un.Id = sv.UniversityId
+			WHERE sv.UserId IN (`+placeholders(len(chunk))+`) AND sv.VerifiedAt IS NOT NULL AND `+studentRoleIn("u.RoleId"), args...)
+		if err != nil {
+			return fmt.Errorf("error al obtener las insignias de estudiante: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var userID int64
+			var roleID int
+			var badge models.StudentBadge
+			if err := rows.Scan(&userID, &roleID, &badge.UniversityId, &badge.UniversityName, &badge.VerifiedAt); err != nil {
+				return err
+			}
+			badge.Kind = models.StudentBadgeKind(roleID)
+			badges[userID] = badge
+		}
+		return rows.Err()
+	})
+	return badges, err
+}
+
+// GetStudentBadge devuelve la insignia del usuario, o nil si no la tiene.
+func GetStudentBadge(userID int64) (*models.StudentBadge, error) {
+	badges, err := GetStudentBadges([]int64{userID})
+	if err != nil {
+		return nil, err
+	}
+	if badge, ok := badges[userID]; ok {
+		return &badge, nil
+	}
+	return nil, nil
+}
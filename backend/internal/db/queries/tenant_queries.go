@@ -0,0 +1,52 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+)
+
+// TenantCondition es la condición "la fila de column es de la institución tenantID o no es de
+// ninguna" (ver docs/multi_institucion.md). Las filas sin institución (empresas, administradores,
+// publicaciones anteriores) se comparten entre todas. Con tenantID 0, una petición sin
+// institución o con el soporte desactivado, no filtra nada.
+//
+// Como las condiciones de account_sql.go, el Id se escribe en la consulta porque se usa en
+// fragmentos que no reciben argumentos propios (unifiedFeedSources); es un entero resuelto por
+// el servidor, no un texto del cliente.
+func TenantCondition(column string, tenantID int64) string {
+	if tenantID == 0 {
+		return "TRUE"
+	}
+	id := strconv.FormatInt(tenantID, 10)
+	return "(" + column + " = " + id + " OR " + column + " IS NULL)"
+}
+
+// SetUserTenant asigna la institución del usuario; tenantID 0 la quita. Si no existe devuelve
+// sql.ErrNoRows.
+func SetUserTenant(userID, tenantID int64) error {
+	tenant := sql.NullInt64{Int64: tenantID, Valid: tenantID != 0}
+	result, err := DB.Exec(`UPDATE User SET TenantId = ? WHERE Id = ?`, tenant, userID)
+	if err != nil {
+		return fmt.Errorf("error al cambiar la institución del usuario %d: %w", userID, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		// RowsAffected es 0 también si la institución no cambia; se distingue comprobando si existe.
+		if _, err := GetAccountState(userID); err != nil {
+			return err
+		}
+	}
+	InvalidateUserCache(userID)
+	return nil
+}
+
+// AssignTenantIfUnset asigna la institución al usuario si aún no tiene una. Se usa al
+// verificar a un estudiante con el correo de su universidad; una institución asignada por un
+// administrador no se cambia.
+func AssignTenantIfUnset(userID, tenantID int64) error {
+	if _, err := DB.Exec(`UPDATE User SET TenantId = ? WHERE Id = ? AND TenantId IS NULL`, tenantID, userID); err != nil {
+		return fmt.Errorf("error al asignar la institución %d al usuario %d: %w", tenantID, userID, err)
+	}
+	InvalidateUserCache(userID)
+	return nil
+}
@@ -0,0 +1,114 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+const quarantinedFileColumns = `q.MultimediaId, m.UserId, m.Type, q.OriginalName, m.FileName, COALESCE(m.Size, 0), q.Signature, q.CreatedAt`
+
+const quarantinedFileFrom = `FROM UploadQuarantine q JOIN Multimedia m ON m.Id = q.MultimediaId`
+
+func scanQuarantinedFile(row rowScanner) (*models.QuarantinedFile, error) {
+	var file models.QuarantinedFile
+	if err := row.Scan(&file.MultimediaId, &file.UserId, &file.Type, &file.OriginalName, &file.ObjectPath,
+		&file.Size, &file.Signature, &file.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// InsertQuarantinedFile registra un archivo en cuarentena: su fila de Multimedia (que debe
+// traer ProcessingStatus quarantined) y el motivo.
+func InsertQuarantinedFile(media *models.Multimedia, originalName, signature string) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error iniciando la transacción de cuarentena: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO Multimedia (Id, Type, UserId, FileName, CreateAt, ContentId, Size, ProcessingStatus)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		media.Id, media.Type, media.UserId, media.FileName, media.CreateAt, media.ContentId, media.Size, media.ProcessingStatus); err != nil {
+		return fmt.Errorf("error registrando el archivo en cuarentena del usuario %d: %w", media.UserId, err)
+	}
+	if _, err := tx.Exec(`INSERT INTO UploadQuarantine (MultimediaId, OriginalName, Signature) VALUES (?, ?, ?)`,
+		media.Id, originalName, signature); err != nil {
+		return fmt.Errorf("error registrando el motivo de la cuarentena de %s: %w", media.Id, err)
+	}
+	return tx.Commit()
+}
+
+// ListQuarantinedFiles devuelve una página de los archivos en cuarentena, los más recientes
+// primero, y el total.
+func ListQuarantinedFiles(page, pageSize int) ([]models.QuarantinedFile, int, error) {
+	var total int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM UploadQuarantine`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error contando los archivos en cuarentena: %w", err)
+	}
+
+	rows, err := DB.Query(`SELECT `+quarantinedFileColumns+` `+quarantinedFileFrom+`
+		ORDER BY q.CreatedAt DESC LIMIT ? OFFSET ?`, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listando los archivos en cuarentena: %w", err)
+	}
+	defer rows.Close()
+
+	files := []models.QuarantinedFile{}
+	for rows.Next() {
+		file, err := scanQuarantinedFile(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error leyendo un archivo en cuarentena: %w", err)
+		}
+		files = append(files, *file)
+	}
+	return files, total, rows.Err()
+}
+
+// GetQuarantinedFile obtiene un archivo en cuarentena por el ID de Multimedia. Devuelve
+// sql.ErrNoRows si no existe.
+func GetQuarantinedFile(multimediaID string) (*models.QuarantinedFile, error) {
+	file, err := scanQuarantinedFile(DB.QueryRow(`SELECT `+quarantinedFileColumns+` `+quarantinedFileFrom+`
+		WHERE q.MultimediaId = ?`, multimediaID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error obteniendo el archivo en cuarentena %s: %w", multimediaID, err)
+	}
+	return file, nil
+}
+
+// DeleteQuarantinedFile borra el registro de un archivo en cuarentena (UploadQuarantine se
+// borra en cascada).
+func DeleteQuarantinedFile(multimediaID string) error {
+	if _, err := DB.Exec(`DELETE FROM Multimedia WHERE Id = ? AND ProcessingStatus = 'quarantined'`, multimediaID); err != nil {
+		return fmt.Errorf("error borrando el archivo en cuarentena %s: %w", multimediaID, err)
+	}
+	return nil
+}
+
+// GetActiveAdminIDs devuelve los administradores con la cuenta activa.
+func GetActiveAdminIDs() ([]int64, error) {
+	rows, err := DB.Query(`SELECT Id, COALESCE(StatusAuthorizedId, 0) FROM User WHERE RoleId = ?`, models.RoleAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo los administradores: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var status int
+		if err := rows.Scan(&id, &status); err != nil {
+			return nil, fmt.Errorf("error leyendo un administrador: %w", err)
+		}
+		if !models.IsAccountDisabled(status) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,163 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+)
+
+const uploadSessionColumns = `Id, UserId, FileName, TotalSize, ReceivedBytes, Status, ContentId, ErrorMessage, CreatedAt, UpdatedAt, ExpiresAt`
+
+func scanUploadSession(row rowScanner) (*models.UploadSession, error) {
+	var session models.UploadSession
+	var contentID, errMsg sql.NullString
+	if err := row.Scan(&session.Id, &session.UserId, &session.FileName, &session.Size, &session.Offset, &session.Status,
+		&contentID, &errMsg, &session.CreatedAt, &session.UpdatedAt, &session.ExpiresAt); err != nil {
+		return nil, err
+	}
+	session.ContentId = contentID.String
+	session.Error = errMsg.String
+	return &session, nil
+}
+
+// CreateUploadSession registra una subida reanudable si el usuario tiene menos de maxActive
+// subidas en curso. Devuelve false si alcanzó el límite.
+func CreateUploadSession(id string, userID int64, fileName string, size int64, expiresAt time.Time, maxActive int) (bool, error) {
+	result, err := DB.Exec(`
+		INSERT INTO UploadSession (Id, UserId, FileName, TotalSize, ExpiresAt)
+		SELECT ?, ?, ?, ?, ? FROM DUAL
+		WHERE (SELECT COUNT(*) FROM UploadSession
+		       WHERE UserId = ? AND Status IN ('uploading', 'processing')) < ?`,
+		id, userID, fileName, size, expiresAt, userID, maxActive)
+	if err != nil {
+		return false, fmt.Errorf("error registrando la subida del usuario %d: %w", userID, err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// GetUploadSession obtiene una subida por ID. Devuelve sql.ErrNoRows si no existe.
+func GetUploadSession(id string) (*models.UploadSession, error) {
+	session, err := scanUploadSession(DB.QueryRow(`SELECT `+uploadSessionColumns+` FROM UploadSession WHERE Id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error obteniendo la subida %s: %w", id, err)
+	}
+	return session, nil
+}
+
+// AdvanceUploadSession registra los bytes recibidos de una subida y renueva su caducidad. Solo
+// avanza si nadie la movió desde fromOffset; devuelve false en caso contrario.
+func AdvanceUploadSession(id string, fromOffset, toOffset int64, expiresAt time.Time) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE UploadSession SET ReceivedBytes = ?, ExpiresAt = ?
+		WHERE Id = ? AND Status = 'uploading' AND ReceivedBytes = ?`,
+		toOffset, expiresAt, id, fromOffset)
+	if err != nil {
+		return false, fmt.Errorf("error actualizando el progreso de la subida %s: %w", id, err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// ClaimUploadSession pasa a 'processing' una subida completa del usuario. Devuelve false si no
+// está en curso, no es suya o faltan bytes.
+func ClaimUploadSession(id string, userID int64) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE UploadSession SET Status = 'processing', ErrorMessage = NULL
+		WHERE Id = ? AND UserId = ? AND Status = 'uploading' AND ReceivedBytes = TotalSize`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("error reclamando la subida %s: %w", id, err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// CompleteUploadSession marca una subida como completada con el video resultante.
+func CompleteUploadSession(id, contentID string) error {
+	if _, err := DB.Exec(`UPDATE UploadSession SET Status = 'completed', ContentId = ? WHERE Id = ?`, contentID, id); err != nil {
+		return fmt.Errorf("error completando la subida %s: %w", id, err)
+	}
+	return nil
+}
+
+// ReleaseUploadSession devuelve a 'uploading' una subida cuyo procesamiento falló, con el
+// motivo, para que el cliente pueda reintentar la finalización sin volver a enviar el archivo.
+func ReleaseUploadSession(id, errMsg string) error {
+	if _, err := DB.Exec(`UPDATE UploadSession SET Status = 'uploading', ErrorMessage = ? WHERE Id = ? AND Status = 'processing'`, errMsg, id); err != nil {
+		return fmt.Errorf("error liberando la subida %s: %w", id, err)
+	}
+	return nil
+}
+
+// CloseUploadSession pasa una subida en curso al estado final status ('cancelled' o
+// 'expired'). Devuelve false si ya no estaba en curso.
+func CloseUploadSession(id, status string) (bool, error) {
+	result, err := DB.Exec(`UPDATE UploadSession SET Status = ? WHERE Id = ? AND Status = 'uploading'`, status, id)
+	if err != nil {
+		return false, fmt.Errorf("error cerrando la subida %s: %w", id, err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// GetExpiredUploadSessionIDs lista las subidas en curso caducadas.
+func GetExpiredUploadSessionIDs(now time.Time) ([]string, error) {
+	return queryUploadSessionIDs(`SELECT Id FROM UploadSession WHERE Status = 'uploading' AND ExpiresAt < ?`, now)
+}
+
+// ReleaseStaleUploadSessions devuelve a 'uploading' las subidas que llevan en 'processing'
+// desde antes de staleBefore, p.ej. por un reinicio durante el procesamiento.
+func ReleaseStaleUploadSessions(staleBefore time.Time) error {
+	_, err := DB.Exec(`
+		UPDATE UploadSession SET Status = 'uploading', ErrorMessage = 'procesamiento interrumpido'
+		WHERE Status = 'processing' AND UpdatedAt < ?`, staleBefore)
+	if err != nil {
+		return fmt.Errorf("error liberando subidas interrumpidas: %w", err)
+	}
+	return nil
+}
+
+// GetOpenUploadSessionIDs devuelve cuáles de ids siguen en curso (en 'uploading' o
+// 'processing'). Se usa para detectar archivos temporales huérfanos.
+func GetOpenUploadSessionIDs(ids []string) (map[string]bool, error) {
+	open := map[string]bool{}
+	if len(ids) == 0 {
+		return open, nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	found, err := queryUploadSessionIDs(`SELECT Id FROM UploadSession
+		WHERE Status IN ('uploading', 'processing') AND Id IN (`+placeholders(len(ids))+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range found {
+		open[id] = true
+	}
+	return open, nil
+}
+
+func queryUploadSessionIDs(query string, args ...any) ([]string, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo subidas: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error escaneando subida: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
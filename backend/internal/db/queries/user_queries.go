@@ -8,16 +8,16 @@ import (
 	"time"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/fieldcrypt"
 )
 
 // GetCompanyNameByID recupera el nombre de la empresa de un usuario por su ID.
 // Devuelve el nombre de la empresa o un error si no se encuentra o el usuario no es una empresa.
 func GetCompanyNameByID(userID int64) (string, error) {
 	var companyName sql.NullString
-	// Asumimos que el rol de empresa es 3.
-	query := "SELECT CompanyName FROM User WHERE Id = ? AND RoleId = 3"
+	query := "SELECT CompanyName FROM User WHERE Id = ? AND RoleId = ?"
 
-	err := DB.QueryRow(query, userID).Scan(&companyName)
+	err := DB.QueryRow(query, userID, models.RoleBusiness).Scan(&companyName)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", fmt.Errorf("no se encontró una empresa con ID %d o el usuario no es una empresa", userID)
@@ -109,6 +109,13 @@ func BuildUpdateUserQuery(userID int64, payload models.UpdateProfilePayload) (st
 					return "", nil, fmt.Errorf("invalid date format for Birthdate: %w", err)
 				}
 				args = append(args, t)
+			case "Phone":
+				args = append(args, fieldcrypt.Value(field.Elem().Interface().(string)))
+			case "DocId":
+				docId := field.Elem().Interface().(string)
+				args = append(args, fieldcrypt.Value(docId))
+				updates = append(updates, "DocIdHash = ?")
+				args = append(args, fieldcrypt.Index(FieldDocId, docId))
 			default:
 				args = append(args, field.Elem().Interface())
 			}
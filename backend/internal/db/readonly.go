@@ -0,0 +1,391 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/health"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/go-sql-driver/mysql"
+)
+
+/*
+MODO SOLO LECTURA
+
+ReadOnlyBreaker es un circuit breaker sobre las escrituras en la BD. Se abre (modo solo
+lectura) cuando:
+
+  - las consultas o las comprobaciones fallan por no poder conectar FailureThreshold veces
+    seguidas;
+  - el servidor rechaza una escritura por estar en read_only (p. ej. durante una conmutación);
+  - la réplica de ReplicaDSN acumula más de MaxReplicationLag de retraso.
+
+Con el breaker abierto, el conector instrumentado (instrument.go) rechaza los Exec con
+ErrReadOnly sin enviarlos al servidor; las lecturas siguen su curso y la caché de consultas
+sirve los valores caducados si la BD no responde. Cada CheckInterval una comprobación hace de
+estado semiabierto: tras RecoveryThreshold comprobaciones correctas seguidas el breaker se
+cierra solo. Ver docs/modo_solo_lectura.md.
+*/
+
+// ErrReadOnly es el error de las escrituras rechazadas con el modo solo lectura activo.
+var ErrReadOnly = apperrors.New(apperrors.ReadOnly, "La plataforma está en modo solo lectura por un problema con la base de datos. Vuelve a intentarlo en unos minutos.")
+
+// Motivos del modo solo lectura.
+const (
+	ReadOnlyUnreachable    = "db_unreachable"  // No se puede conectar con la BD
+	ReadOnlyServer         = "db_read_only"    // El servidor tiene read_only activo
+	ReadOnlyReplicationLag = "replication_lag" // La réplica va retrasada o está detenida
+)
+
+// BreakerConfig es la configuración del modo solo lectura.
+type BreakerConfig struct {
+	// CheckInterval es cada cuánto se comprueba la BD (0 = sin modo solo lectura).
+	CheckInterval     time.Duration
+	FailureThreshold  int           // Fallos de conexión seguidos que activan el modo
+	RecoveryThreshold int           // Comprobaciones correctas seguidas que lo desactivan
+	MaxReplicationLag time.Duration // 0 = no se mide el retraso
+	ReplicaDSN        string        // Réplica cuyo retraso se mide; vacío = ninguna
+}
+
+// NewBreakerConfig toma la configuración del modo solo lectura de las variables DB_READONLY_*.
+func NewBreakerConfig(cfg *config.Config) BreakerConfig {
+	return BreakerConfig{
+		CheckInterval:     cfg.DBReadOnlyCheckInterval,
+		FailureThreshold:  cfg.DBReadOnlyFailures,
+		RecoveryThreshold: cfg.DBReadOnlyRecovery,
+		MaxReplicationLag: cfg.DBReadOnlyMaxLag,
+		ReplicaDSN:        cfg.DBReplicaDSN,
+	}
+}
+
+// ReadOnlyState es el estado del modo solo lectura que se muestra en /readyz y en el panel admin.
+type ReadOnlyState struct {
+	Enabled  bool      `json:"enabled"`  // El breaker está configurado
+	ReadOnly bool      `json:"readOnly"` // Las escrituras se rechazan
+	Reason   string    `json:"reason,omitempty"`
+	Since    time.Time `json:"since,omitempty"` // Momento en que se activó
+	// LastError es el último fallo observado, aunque no haya bastado para activar el modo.
+	LastError string `json:"lastError,omitempty"`
+	// ReplicationLagSeconds es el último retraso medido; nil si no se mide o no se conoce.
+	ReplicationLagSeconds *int64    `json:"replicationLagSeconds,omitempty"`
+	Failures              int       `json:"consecutiveFailures"`
+	Recoveries            int       `json:"consecutiveRecoveries"` // Comprobaciones correctas con el modo activo
+	Trips                 int64     `json:"trips"`                 // Veces que se activó desde el arranque
+	RetryAfterSeconds     int       `json:"retryAfterSeconds,omitempty"`
+	CheckedAt             time.Time `json:"checkedAt,omitempty"`
+}
+
+// ReadOnlyBreaker decide cuándo la BD pasa a modo solo lectura y cuándo se recupera.
+type ReadOnlyBreaker struct {
+	conn    *sql.DB
+	replica *sql.DB
+	cfg     BreakerConfig
+
+	readOnly atomic.Bool // Copia de state.ReadOnly para el camino de cada consulta
+	failures atomic.Int32
+
+	mu    sync.RWMutex
+	state ReadOnlyState
+}
+
+// activeBreaker es el breaker que consulta el conector instrumentado (nil = ninguno).
+var activeBreaker atomic.Pointer[ReadOnlyBreaker]
+
+// NewReadOnlyBreaker crea el breaker de conn y lo registra en el conector instrumentado.
+// Devuelve nil si cfg.CheckInterval es 0; los métodos de un breaker nil son válidos.
+func NewReadOnlyBreaker(conn *sql.DB, cfg BreakerConfig) (*ReadOnlyBreaker, error) {
+	if cfg.CheckInterval <= 0 {
+		logger.Info("DB", "Modo solo lectura desactivado (DB_READONLY_CHECK_INTERVAL=0)")
+		return nil, nil
+	}
+	b := &ReadOnlyBreaker{conn: conn, cfg: cfg}
+	b.state = ReadOnlyState{Enabled: true, RetryAfterSeconds: b.retryAfterSeconds()}
+	if cfg.ReplicaDSN != "" && cfg.MaxReplicationLag > 0 {
+		replica, err := sql.Open("mysql", cfg.ReplicaDSN)
+		if err != nil {
+			return nil, fmt.Errorf("DB_REPLICA_DSN inválido: %w", err)
+		}
+		replica.SetMaxOpenConns(1)
+		b.replica = replica
+	}
+	activeBreaker.Store(b)
+	logger.Infof("DB", "Modo solo lectura: comprobación cada %s, %d fallos para activarlo, %d comprobaciones para salir, retraso máximo %s",
+		cfg.CheckInterval, cfg.FailureThreshold, cfg.RecoveryThreshold, cfg.MaxReplicationLag)
+	return b, nil
+}
+
+// ReadOnly indica si las escrituras se están rechazando en este proceso.
+func ReadOnly() bool {
+	return activeBreaker.Load().ReadOnly()
+}
+
+// CurrentReadOnlyState devuelve el estado del breaker registrado en este proceso.
+func CurrentReadOnlyState() ReadOnlyState {
+	return activeBreaker.Load().State()
+}
+
+// ReadOnlyCheck es la comprobación de readiness del breaker registrado en este proceso (ver
+// ReadOnlyBreaker.Ready).
+func ReadOnlyCheck(ctx context.Context) error {
+	return activeBreaker.Load().Ready(ctx)
+}
+
+// ReadOnly indica si el breaker está abierto.
+func (b *ReadOnlyBreaker) ReadOnly() bool {
+	return b != nil && b.readOnly.Load()
+}
+
+// State devuelve el estado actual.
+func (b *ReadOnlyBreaker) State() ReadOnlyState {
+	if b == nil {
+		return ReadOnlyState{}
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	state := b.state
+	state.Failures = int(b.failures.Load())
+	return state
+}
+
+// Ready es la comprobación de readiness del modo solo lectura: con el modo activo devuelve un
+// error health.Degraded con el motivo, de modo que /readyz responde "degraded" sin sacar el
+// servicio del balanceador (las lecturas siguen funcionando).
+func (b *ReadOnlyBreaker) Ready(ctx context.Context) error {
+	state := b.State()
+	if !state.ReadOnly {
+		return nil
+	}
+	return health.Degraded(fmt.Errorf("modo solo lectura (%s) desde %s", state.Reason, state.Since.Format(time.RFC3339)))
+}
+
+// retryAfterSeconds es el tiempo mínimo hasta la recuperación que se anuncia en Retry-After.
+func (b *ReadOnlyBreaker) retryAfterSeconds() int {
+	seconds := int((b.cfg.CheckInterval * time.Duration(b.cfg.RecoveryThreshold)).Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// Run ejecuta Check en cada intervalo hasta que se cancele el contexto.
+func (b *ReadOnlyBreaker) Run(ctx context.Context) {
+	if b == nil {
+		return
+	}
+	ticker := time.NewTicker(b.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if b.replica != nil {
+				b.replica.Close()
+			}
+			return
+		case <-ticker.C:
+			b.Check(ctx)
+		}
+	}
+}
+
+// Check comprueba la BD (conexión, read_only y retraso de la réplica) y abre o cierra el
+// breaker según el resultado.
+func (b *ReadOnlyBreaker) Check(ctx context.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, b.cfg.CheckInterval)
+	defer cancel()
+
+	reason, lag, err := b.probe(checkCtx)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state.CheckedAt = time.Now().UTC()
+	b.state.ReplicationLagSeconds = lag
+	if err != nil {
+		b.state.LastError = err.Error()
+	}
+
+	switch {
+	case reason == ReadOnlyUnreachable:
+		if b.failures.Add(1) >= int32(b.cfg.FailureThreshold) {
+			b.trip(reason, err)
+		}
+		b.state.Recoveries = 0
+	case reason != "":
+		b.trip(reason, err)
+		b.state.Recoveries = 0
+	default:
+		b.failures.Store(0)
+		if b.state.ReadOnly {
+			b.state.Recoveries++
+			if b.state.Recoveries >= b.cfg.RecoveryThreshold {
+				b.recover()
+			}
+		}
+	}
+}
+
+// probe devuelve el motivo para estar en solo lectura ("" si la BD admite escrituras), el
+// retraso de la réplica si se mide y el error observado.
+func (b *ReadOnlyBreaker) probe(ctx context.Context) (string, *int64, error) {
+	if err := b.conn.PingContext(ctx); err != nil {
+		return ReadOnlyUnreachable, nil, err
+	}
+	var serverReadOnly bool
+	if err := b.conn.QueryRowContext(ctx, `SELECT @@global.read_only`).Scan(&serverReadOnly); err != nil {
+		if isConnectionError(err) {
+			return ReadOnlyUnreachable, nil, err
+		}
+		return "", nil, err
+	}
+	if serverReadOnly {
+		return ReadOnlyServer, nil, errors.New("el servidor de base de datos tiene read_only activo")
+	}
+
+	if b.replica == nil {
+		return "", nil, nil
+	}
+	lag, err := replicationLag(ctx, b.replica)
+	if err != nil {
+		// Sin réplica accesible no se sabe el retraso: el primario sigue admitiendo escrituras.
+		logger.Warnf("DB", "No se pudo medir el retraso de la réplica: %v", err)
+		return "", nil, err
+	}
+	if lag == nil {
+		return ReadOnlyReplicationLag, nil, errors.New("la replicación está detenida")
+	}
+	if time.Duration(*lag)*time.Second > b.cfg.MaxReplicationLag {
+		return ReadOnlyReplicationLag, lag, fmt.Errorf("la réplica va %ds retrasada (máximo %s)", *lag, b.cfg.MaxReplicationLag)
+	}
+	return "", lag, nil
+}
+
+// replicationLag lee Seconds_Behind_Source de la réplica (Seconds_Behind_Master antes de
+// MySQL 8.0.22). Devuelve nil si la replicación está detenida.
+func replicationLag(ctx context.Context, replica *sql.DB) (*int64, error) {
+	rows, err := replica.QueryContext(ctx, `SHOW REPLICA STATUS`)
+	if err != nil {
+		if rows, err = replica.QueryContext(ctx, `SHOW SLAVE STATUS`); err != nil {
+			return nil, err
+		}
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("el servidor de DB_REPLICA_DSN no es una réplica")
+	}
+	values := make([]sql.NullInt64, len(columns))
+	dest := make([]interface{}, len(columns))
+	lagColumn := -1
+	for i, column := range columns {
+		if column == "Seconds_Behind_Source" || column == "Seconds_Behind_Master" {
+			lagColumn = i
+			dest[i] = &values[i]
+		} else {
+			dest[i] = new(sql.RawBytes)
+		}
+	}
+	if lagColumn < 0 {
+		return nil, errors.New("SHOW REPLICA STATUS no devolvió el retraso")
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if !values[lagColumn].Valid {
+		return nil, nil
+	}
+	return &values[lagColumn].Int64, nil
+}
+
+// trip activa el modo solo lectura. Debe llamarse con mu tomado.
+func (b *ReadOnlyBreaker) trip(reason string, err error) {
+	if b.state.ReadOnly {
+		b.state.Reason = reason
+		return
+	}
+	b.state.ReadOnly = true
+	b.state.Reason = reason
+	b.state.Since = time.Now().UTC()
+	b.state.Trips++
+	b.readOnly.Store(true)
+	logger.Errorf("DB", "🔒 Modo solo lectura activado (%s): %v", reason, err)
+}
+
+// recover desactiva el modo solo lectura. Debe llamarse con mu tomado.
+func (b *ReadOnlyBreaker) recover() {
+	logger.Successf("DB", "🔓 Modo solo lectura desactivado tras %s (%s)", time.Since(b.state.Since).Round(time.Second), b.state.Reason)
+	b.state.ReadOnly = false
+	b.state.Reason = ""
+	b.state.Since = time.Time{}
+	b.state.Recoveries = 0
+	b.readOnly.Store(false)
+}
+
+// observe recibe el resultado de cada consulta del conector instrumentado: los fallos de
+// conexión cuentan para activar el modo y las escrituras rechazadas por read_only lo activan.
+func (b *ReadOnlyBreaker) observe(err error) {
+	if b == nil {
+		return
+	}
+	switch {
+	case err == nil:
+		if !b.readOnly.Load() && b.failures.Load() > 0 {
+			b.failures.Store(0)
+		}
+	case isServerReadOnlyError(err):
+		b.mu.Lock()
+		b.state.LastError = err.Error()
+		b.trip(ReadOnlyServer, err)
+		b.mu.Unlock()
+	case isConnectionError(err):
+		failures := b.failures.Add(1)
+		b.mu.Lock()
+		b.state.LastError = err.Error()
+		if failures >= int32(b.cfg.FailureThreshold) {
+			b.trip(ReadOnlyUnreachable, err)
+		}
+		b.mu.Unlock()
+	}
+}
+
+// isConnectionError indica si err se debe a que no se puede hablar con el servidor, a
+// diferencia de los errores de la propia consulta.
+func isConnectionError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// isServerReadOnlyError indica si el servidor rechazó la sentencia por estar en read_only
+// (1290 ER_OPTION_PREVENTS_STATEMENT, que también se usa para otras opciones, 1792
+// ER_CANT_EXECUTE_IN_READ_ONLY_TRANSACTION y 1836 ER_READ_ONLY_MODE).
+func isServerReadOnlyError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	switch mysqlErr.Number {
+	case 1290:
+		return strings.Contains(mysqlErr.Message, "read-only") || strings.Contains(mysqlErr.Message, "read_only")
+	case 1792, 1836:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Pesos de los campos caros en el cálculo de complejidad. Los que no aparecen cuentan 1.
+const (
+	// profileComplexity: el perfil se carga por lotes, pero cada sección del currículum que se
+	// pida es una consulta por usuario.
+	profileComplexity = 10
+	// chatsEstimate es el número de chats que se supone al calcular la complejidad de chats, que
+	// no está paginado.
+	chatsEstimate = 50
+)
+
+// NewHandler crea el handler de /graphql. Debe ir detrás de middleware.AuthMiddleware: los
+// resolvers leen el usuario autenticado del contexto. Acepta GET y POST; en modo solo lectura
+// de la BD solo GET (middleware.ReadOnly rechaza los POST), aunque el esquema no tiene
+// mutaciones.
+func NewHandler(cfg *config.Config) http.Handler {
+	schemaConfig := Config{Resolvers: &Resolver{}}
+	schemaConfig.Complexity.User.Profile = func(childComplexity int) int {
+		return childComplexity + profileComplexity
+	}
+	schemaConfig.Complexity.Query.Feed = func(childComplexity int, first *int, after *string) int {
+		limit := pagination.DefaultLimit
+		if first != nil && *first > 0 {
+			limit = min(*first, pagination.MaxLimit)
+		}
+		return limit * childComplexity
+	}
+	schemaConfig.Complexity.Query.Chats = func(childComplexity int) int {
+		return chatsEstimate * childComplexity
+	}
+
+	srv := handler.New(NewExecutableSchema(schemaConfig))
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.FixedComplexityLimit(cfg.GraphQLComplexityLimit))
+	if cfg.Environment != config.EnvProduction {
+		srv.Use(extension.Introspection{})
+	}
+	srv.SetErrorPresenter(presentError)
+	return WithLoaders(srv)
+}
+
+// presentError devuelve tal cual los errores de la consulta (sintaxis, validación,
+// complejidad) y de los argumentos. Los de los resolvers pueden incluir detalles de la base
+// de datos: se registran y el cliente recibe un mensaje genérico.
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	presented := graphql.DefaultErrorPresenter(ctx, err)
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) && gqlErr.Err == nil {
+		return presented
+	}
+	if errors.Is(err, pagination.ErrInvalidCursor) || errors.Is(err, ErrInvalidID) {
+		return presented
+	}
+	logger.Errorf("GRAPHQL", "Error resolviendo %s: %v", presented.Path, err)
+	presented.Message = "Error interno del servidor"
+	return presented
+}
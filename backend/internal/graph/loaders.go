@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// loaderWait es cuánto espera un dataloader a que lleguen más claves antes de lanzar la
+// consulta. gqlgen resuelve en paralelo los elementos de una lista, así que en ese tiempo
+// se juntan, p. ej., todos los autores de una página del feed.
+const loaderWait = 2 * time.Millisecond
+
+// Loaders agrupa por petición las cargas por ID, para que una lista de N elementos haga una
+// consulta por campo y no N (el problema N+1). Cada petición tiene los suyos: la caché de un
+// dataloader no se comparte entre usuarios.
+type Loaders struct {
+	users    *dataloader.Loader[int64, *models.UserBaseInfo]
+	online   *dataloader.Loader[int64, bool]
+	skills   *dataloader.Loader[int64, []models.Skills]
+	profiles *dataloader.Loader[int64, *models.User]
+}
+
+type loadersKey struct{}
+
+// NewLoaders crea los dataloaders de una petición sobre las consultas por lotes de
+// internal/db/queries.
+func NewLoaders() *Loaders {
+	return &Loaders{
+		users:    newLoader(queries.GetUserBaseInfoByIDs),
+		online:   newLoader(queries.GetOnlineStatusByIDs),
+		skills:   newLoader(queries.GetSkillsByUserIDs),
+		profiles: newLoader(queries.GetUserFullProfileDataByIDs),
+	}
+}
+
+// newLoader adapta una consulta por lotes que devuelve un mapa por ID. Los IDs que faltan en
+// el mapa se resuelven con el valor cero (nil en los punteros): el usuario no existe o no
+// tiene datos.
+func newLoader[V any](fetch func([]int64) (map[int64]V, error)) *dataloader.Loader[int64, V] {
+	batch := func(_ context.Context, ids []int64) []*dataloader.Result[V] {
+		results := make([]*dataloader.Result[V], len(ids))
+		found, err := fetch(ids)
+		for i, id := range ids {
+			if err != nil {
+				results[i] = &dataloader.Result[V]{Error: err}
+				continue
+			}
+			results[i] = &dataloader.Result[V]{Data: found[id]}
+		}
+		return results
+	}
+	return dataloader.NewBatchedLoader(batch, dataloader.WithWait[int64, V](loaderWait))
+}
+
+// WithLoaders es el middleware que crea los dataloaders de cada petición y la carga única de
+// los bloqueos del usuario autenticado.
+func WithLoaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), loadersKey{}, NewLoaders())
+		ctx = context.WithValue(ctx, blocksKey{}, &blockRelations{})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// loadersFor devuelve los dataloaders de la petición.
+func loadersFor(ctx context.Context) (*Loaders, error) {
+	l, ok := ctx.Value(loadersKey{}).(*Loaders)
+	if !ok {
+		return nil, fmt.Errorf("graphql: la petición no tiene dataloaders")
+	}
+	return l, nil
+}
+
+// loadUser carga la información básica de un usuario. Devuelve nil si no existe.
+func loadUser(ctx context.Context, id int64) (*User, error) {
+	l, err := loadersFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	base, err := l.users.Load(ctx, id)()
+	if err != nil || base == nil {
+		return nil, err
+	}
+	return &User{UserBaseInfo: *base}, nil
+}
@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+)
+
+// User es un usuario del esquema. Los campos básicos vienen de la carga por lotes de
+// queries.GetUserBaseInfoByIDs; el estado de conexión, las habilidades y el perfil tienen su
+// propio resolver.
+type User struct {
+	models.UserBaseInfo
+	// hideStatus oculta el estado de conexión: hay un bloqueo entre el usuario y quien consulta.
+	hideStatus bool
+}
+
+// CompleteProfile es el perfil completo de un usuario. Las secciones del currículum se
+// resuelven aparte, solo si la consulta las pide.
+type CompleteProfile struct {
+	UserID         int64
+	Summary        string
+	Address        string
+	Github         string
+	Linkedin       string
+	DegreeName     string
+	UniversityName string
+	// Email y Phone solo se rellenan en el perfil propio.
+	Email *string
+	Phone *string
+}
+
+// FeedPage es una página del feed por cursor (pagination.Page).
+type FeedPage struct {
+	Items      []wsmodels.FeedItem
+	NextCursor *string
+	HasMore    bool
+}
+
+// ErrInvalidID indica un argumento de tipo Int64ID que no es un número entero.
+var ErrInvalidID = errors.New("ID inválido")
+
+// MarshalInt64ID serializa los IDs numéricos como texto, como pide la especificación para el
+// tipo ID.
+func MarshalInt64ID(id int64) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		io.WriteString(w, strconv.Quote(strconv.FormatInt(id, 10)))
+	})
+}
+
+// UnmarshalInt64ID acepta el ID como texto o como número.
+func UnmarshalInt64ID(v interface{}) (int64, error) {
+	switch v := v.(type) {
+	case string:
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidID, v)
+		}
+		return id, nil
+	default:
+		id, err := graphql.UnmarshalInt64(v)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInvalidID, v)
+		}
+		return id, nil
+	}
+}
@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+)
+
+// Resolver es la raíz de los resolvers del esquema. No tiene estado: las consultas usan
+// queries.DB y lo propio de cada petición (usuario, dataloaders) va en el contexto.
+type Resolver struct{}
+
+// errNotAuthenticated no debería ocurrir: la ruta /graphql pasa por AuthMiddleware.
+var errNotAuthenticated = errors.New("graphql: petición sin usuario autenticado")
+
+// viewerID devuelve el usuario autenticado de la petición.
+func viewerID(ctx context.Context) (int64, error) {
+	userID, ok := ctx.Value(middleware.UserIDContextKey).(int64)
+	if !ok || userID == 0 {
+		return 0, errNotAuthenticated
+	}
+	return userID, nil
+}
+
+// blockRelations carga una sola vez por petición los usuarios con los que el usuario
+// autenticado tiene un bloqueo en cualquier sentido.
+type blockRelations struct {
+	once    sync.Once
+	blocked map[int64]bool
+	err     error
+}
+
+type blocksKey struct{}
+
+// viewerBlocks devuelve los bloqueos del usuario autenticado (queries.GetBlockRelations).
+func viewerBlocks(ctx context.Context) (map[int64]bool, error) {
+	viewer, err := viewerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	relations, ok := ctx.Value(blocksKey{}).(*blockRelations)
+	if !ok {
+		return queries.GetBlockRelations(viewer)
+	}
+	relations.once.Do(func() {
+		relations.blocked, relations.err = queries.GetBlockRelations(viewer)
+	})
+	return relations.blocked, relations.err
+}
+
+// nonNil evita devolver null en las listas no nulas del esquema cuando la consulta no
+// encuentra filas.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// optional devuelve nil para la cadena vacía, para los campos que pueden ser null.
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,278 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.70
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/services"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
+)
+
+// OtherUser is the resolver for the otherUser field.
+func (r *chatResolver) OtherUser(ctx context.Context, obj *models.ChatInfoQueryResult) (*User, error) {
+	user, err := loadUser(ctx, obj.OtherUserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("graphql: el usuario %d del chat %s no existe", obj.OtherUserID, obj.ChatID)
+	}
+	blocked, err := viewerBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user.hideStatus = blocked[obj.OtherUserID]
+	return user, nil
+}
+
+// LastMessage is the resolver for the lastMessage field.
+func (r *chatResolver) LastMessage(ctx context.Context, obj *models.ChatInfoQueryResult) (*string, error) {
+	if !obj.LastMessage.Valid {
+		return nil, nil
+	}
+	return &obj.LastMessage.String, nil
+}
+
+// LastMessageAt is the resolver for the lastMessageAt field.
+func (r *chatResolver) LastMessageAt(ctx context.Context, obj *models.ChatInfoQueryResult) (*string, error) {
+	if !obj.LastMessage.Valid || !obj.LastMessageTs.Valid {
+		return nil, nil
+	}
+	return optional(obj.LastMessageTs.Time.UTC().Format(time.RFC3339)), nil
+}
+
+// LastMessageFromUserID is the resolver for the lastMessageFromUserId field.
+func (r *chatResolver) LastMessageFromUserID(ctx context.Context, obj *models.ChatInfoQueryResult) (*int64, error) {
+	if !obj.LastMessage.Valid || !obj.LastMessageFromUserId.Valid {
+		return nil, nil
+	}
+	return &obj.LastMessageFromUserId.Int64, nil
+}
+
+// IsBlocked is the resolver for the isBlocked field.
+func (r *chatResolver) IsBlocked(ctx context.Context, obj *models.ChatInfoQueryResult) (bool, error) {
+	blocked, err := viewerBlocks(ctx)
+	if err != nil {
+		return false, err
+	}
+	return blocked[obj.OtherUserID], nil
+}
+
+// Education is the resolver for the education field.
+func (r *completeProfileResolver) Education(ctx context.Context, obj *CompleteProfile) ([]wsmodels.EducationItem, error) {
+	items, err := queries.GetEducationForUser(obj.UserID)
+	return nonNil(items), err
+}
+
+// Experience is the resolver for the experience field.
+func (r *completeProfileResolver) Experience(ctx context.Context, obj *CompleteProfile) ([]wsmodels.WorkExperienceItem, error) {
+	items, err := queries.GetWorkExperienceForUser(obj.UserID)
+	return nonNil(items), err
+}
+
+// Certifications is the resolver for the certifications field.
+func (r *completeProfileResolver) Certifications(ctx context.Context, obj *CompleteProfile) ([]wsmodels.CertificationItem, error) {
+	items, err := queries.GetCertificationsForUser(obj.UserID)
+	return nonNil(items), err
+}
+
+// Projects is the resolver for the projects field.
+func (r *completeProfileResolver) Projects(ctx context.Context, obj *CompleteProfile) ([]wsmodels.ProjectItem, error) {
+	items, err := queries.GetProjectsForUser(obj.UserID)
+	return nonNil(items), err
+}
+
+// Languages is the resolver for the languages field.
+func (r *completeProfileResolver) Languages(ctx context.Context, obj *CompleteProfile) ([]models.Languages, error) {
+	items, err := queries.GetLanguagesForUser(obj.UserID)
+	return nonNil(items), err
+}
+
+// Title is the resolver for the title field.
+func (r *feedItemResolver) Title(ctx context.Context, obj *wsmodels.FeedItem) (*string, error) {
+	if data, ok := obj.Data.(wsmodels.EventFeedData); ok {
+		return &data.Title, nil
+	}
+	return nil, nil
+}
+
+// Description is the resolver for the description field.
+func (r *feedItemResolver) Description(ctx context.Context, obj *wsmodels.FeedItem) (string, error) {
+	switch data := obj.Data.(type) {
+	case wsmodels.StudentFeedData:
+		return data.Description, nil
+	case wsmodels.CompanyFeedData:
+		return data.Description, nil
+	case wsmodels.EventFeedData:
+		return data.Description, nil
+	}
+	return "", nil
+}
+
+// Author is the resolver for the author field.
+func (r *feedItemResolver) Author(ctx context.Context, obj *wsmodels.FeedItem) (*User, error) {
+	var authorID int64
+	switch data := obj.Data.(type) {
+	case wsmodels.StudentFeedData:
+		authorID = data.UserID
+	case wsmodels.CompanyFeedData:
+		authorID = data.UserID
+	case wsmodels.EventFeedData:
+		authorID = data.UserID
+	}
+	if authorID == 0 {
+		return nil, nil
+	}
+	return loadUser(ctx, authorID)
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*User, error) {
+	viewer, err := viewerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := loadUser(ctx, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("graphql: el usuario autenticado %d no existe", viewer)
+	}
+	return user, nil
+}
+
+// User is the resolver for the user field.
+func (r *queryResolver) User(ctx context.Context, id int64) (*User, error) {
+	blocked, err := viewerBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if blocked[id] {
+		return nil, nil
+	}
+	return loadUser(ctx, id)
+}
+
+// Feed is the resolver for the feed field.
+func (r *queryResolver) Feed(ctx context.Context, first *int, after *string) (*FeedPage, error) {
+	viewer, err := viewerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var cursor string
+	limit := 0
+	if after != nil {
+		cursor = *after
+	}
+	if first != nil {
+		limit = *first
+	}
+	page, err := pagination.New(cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := services.NewFeedService(queries.DB).GetFeedPage(viewer, middleware.TenantID(ctx), page)
+	if err != nil {
+		return nil, err
+	}
+	return &FeedPage{
+		Items:      result.Items,
+		NextCursor: optional(result.NextCursor),
+		HasMore:    result.HasMore,
+	}, nil
+}
+
+// Chats is the resolver for the chats field.
+func (r *queryResolver) Chats(ctx context.Context) ([]models.ChatInfoQueryResult, error) {
+	viewer, err := viewerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chats, err := queries.GetChatList(viewer)
+	return nonNil(chats), err
+}
+
+// IsOnline is the resolver for the isOnline field.
+func (r *userResolver) IsOnline(ctx context.Context, obj *User) (bool, error) {
+	if obj.hideStatus {
+		return false, nil
+	}
+	l, err := loadersFor(ctx)
+	if err != nil {
+		return false, err
+	}
+	return l.online.Load(ctx, obj.ID)()
+}
+
+// Skills is the resolver for the skills field.
+func (r *userResolver) Skills(ctx context.Context, obj *User) ([]models.Skills, error) {
+	l, err := loadersFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	skills, err := l.skills.Load(ctx, obj.ID)()
+	return nonNil(skills), err
+}
+
+// Profile is the resolver for the profile field.
+func (r *userResolver) Profile(ctx context.Context, obj *User) (*CompleteProfile, error) {
+	l, err := loadersFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := l.profiles.Load(ctx, obj.ID)()
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("graphql: no se encontró el perfil del usuario %d", obj.ID)
+	}
+
+	profile := &CompleteProfile{
+		UserID:         user.Id,
+		Summary:        user.Summary.String,
+		Address:        user.Address.String,
+		Github:         user.Github.String,
+		Linkedin:       user.Linkedin.String,
+		DegreeName:     user.DegreeName.String,
+		UniversityName: user.UniversityName.String,
+	}
+	// El correo y el teléfono solo se muestran a su dueño.
+	if viewer, _ := viewerID(ctx); viewer == user.Id {
+		profile.Email = optional(user.Email)
+		profile.Phone = optional(user.Phone.String)
+	}
+	return profile, nil
+}
+
+// Chat returns ChatResolver implementation.
+func (r *Resolver) Chat() ChatResolver { return &chatResolver{r} }
+
+// CompleteProfile returns CompleteProfileResolver implementation.
+func (r *Resolver) CompleteProfile() CompleteProfileResolver { return &completeProfileResolver{r} }
+
+// FeedItem returns FeedItemResolver implementation.
+func (r *Resolver) FeedItem() FeedItemResolver { return &feedItemResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type chatResolver struct{ *Resolver }
+type completeProfileResolver struct{ *Resolver }
+type feedItemResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/tabular"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/timefmt"
+	"github.com/gorilla/mux"
+)
+
+const adminExportHandlerComponent = "ADMIN_EXPORT_HANDLER"
+
+// AdminExportHandler expone la exportación en CSV y XLSX de los conjuntos de datos del panel.
+type AdminExportHandler struct {
+	service services.IAdminExportService
+}
+
+// NewAdminExportHandler crea una nueva instancia de AdminExportHandler.
+func NewAdminExportHandler(service services.IAdminExportService) *AdminExportHandler {
+	return &AdminExportHandler{service: service}
+}
+
+// ListDatasets devuelve los conjuntos exportables con sus columnas y filtros.
+func (h *AdminExportHandler) ListDatasets(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.service.Datasets())
+}
+
+// Export descarga un conjunto de datos. Parámetros de query: format (csv o xlsx), columns
+// (claves separadas por comas) y los filtros del conjunto: q, roleId, statusId, eventId,
+// status, postType, reviewerId, revieweeId, interactionType, from y to (RFC3339).
+func (h *AdminExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := models.AdminExportRequest{
+		Dataset: mux.Vars(r)["dataset"],
+		Format:  strings.ToLower(q.Get("format")),
+		Filter: models.AdminExportFilter{
+			Users:           models.AdminUserSearch{Query: q.Get("q")},
+			Status:          q.Get("status"),
+			PostType:        q.Get("postType"),
+			InteractionType: q.Get("interactionType"),
+		},
+	}
+	if raw := q.Get("columns"); raw != "" {
+		for _, key := range strings.Split(raw, ",") {
+			req.Columns = append(req.Columns, strings.TrimSpace(key))
+		}
+	}
+	for param, target := range map[string]*int{"roleId": &req.Filter.Users.RoleId, "statusId": &req.Filter.Users.StatusId} {
+		raw := q.Get(param)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value <= 0 {
+			apperrors.Write(w, apperrors.InvalidParam, param+" inválido")
+			return
+		}
+		*target = value
+	}
+	for param, target := range map[string]*int64{"eventId": &req.Filter.EventId, "reviewerId": &req.Filter.ReviewerId, "revieweeId": &req.Filter.RevieweeId} {
+		raw := q.Get(param)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || value <= 0 {
+			apperrors.Write(w, apperrors.InvalidParam, param+" inválido")
+			return
+		}
+		*target = value
+	}
+	for param, dst := range map[string]**time.Time{"from": &req.Filter.From, "to": &req.Filter.To} {
+		value := q.Get(param)
+		if value == "" {
+			continue
+		}
+		t, err := timefmt.Parse(value)
+		if err != nil {
+			apperrors.Write(w, apperrors.InvalidParam, param+" debe tener formato RFC3339")
+			return
+		}
+		*dst = &t
+	}
+
+	req, err := h.service.Prepare(req)
+	if err != nil {
+		apperrors.WriteError(w, err, "Error al preparar la exportación")
+		return
+	}
+
+	fileName := fmt.Sprintf("%s-%s.%s", req.Dataset, time.Now().UTC().Format("20060102-150405"), req.Format)
+	w.Header().Set("Content-Type", tabular.ContentType(req.Format))
+	w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+
+	rows, err := h.service.Export(r.Context(), req, w)
+	if err != nil {
+		// La respuesta ya empezó: se corta la conexión para que el cliente no tome el archivo
+		// incompleto por bueno.
+		if !errors.Is(err, r.Context().Err()) {
+			logger.Errorf(adminExportHandlerComponent, "Error al exportar %s tras %d filas: %v", req.Dataset, rows, err)
+		}
+		panic(http.ErrAbortHandler)
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionDatasetExported,
+		TargetType: models.AuditTargetDataset,
+		TargetId:   req.Dataset,
+	}, map[string]interface{}{"format": req.Format, "columns": req.Columns, "filter": req.Filter, "rows": rows})
+}
@@ -9,7 +9,10 @@ import (
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/config"
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 	"github.com/gorilla/mux"
 )
@@ -54,7 +57,7 @@ func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 	totalUsers, err := queries.CountTotalUsers()
 	if err != nil {
 		logger.Errorf("ADMIN_HANDLER", "Failed to count users: %v", err)
-		http.Error(w, "Error al obtener la lista de usuarios", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error al obtener la lista de usuarios")
 		return
 	}
 
@@ -77,7 +80,7 @@ func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 	users, err := queries.GetUsersPaginated(page, pageSize)
 	if err != nil {
 		logger.Errorf("ADMIN_HANDLER", "Failed to get paginated users: %v", err)
-		http.Error(w, "Error al obtener la lista de usuarios", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error al obtener la lista de usuarios")
 		return
 	}
 
@@ -113,7 +116,7 @@ func (h *AdminHandler) ListUnapprovedCompanies(w http.ResponseWriter, r *http.Re
 	totalCompanies, err := queries.CountUnapprovedCompanies()
 	if err != nil {
 		logger.Errorf("ADMIN_HANDLER", "Failed to count unapproved companies: %v", err)
-		http.Error(w, "Error al obtener la lista de empresas", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error al obtener la lista de empresas")
 		return
 	}
 
@@ -134,7 +137,7 @@ func (h *AdminHandler) ListUnapprovedCompanies(w http.ResponseWriter, r *http.Re
 	companies, err := queries.GetUnapprovedCompaniesPaginated(page, pageSize)
 	if err != nil {
 		logger.Errorf("ADMIN_HANDLER", "Failed to get unapproved companies: %v", err)
-		http.Error(w, "Error al obtener la lista de empresas", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error al obtener la lista de empresas")
 		return
 	}
 
@@ -157,13 +160,13 @@ func (h *AdminHandler) ApproveCompany(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr, ok := vars["id"]
 	if !ok {
-		http.Error(w, "ID de la empresa no proporcionado", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.MissingFields, "ID de la empresa no proporcionado")
 		return
 	}
 
 	companyID, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "ID de la empresa inválido", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.InvalidParam, "ID de la empresa inválido")
 		return
 	}
 
@@ -172,16 +175,27 @@ func (h *AdminHandler) ApproveCompany(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		if err == sql.ErrNoRows {
 			// Si no se afectaron filas, significa que no se encontró la empresa.
-			http.Error(w, "Empresa no encontrada o no es una cuenta de empresa pendiente", http.StatusNotFound)
+			apperrors.Write(w, apperrors.NotFound, "Empresa no encontrada o no es una cuenta de empresa pendiente")
 		} else {
 			// Otros errores de la base de datos.
 			logger.Errorf("ADMIN_HANDLER", "Failed to approve company with ID %d: %v", companyID, err)
-			http.Error(w, "Error al aprobar la empresa", http.StatusInternalServerError)
+			apperrors.Write(w, apperrors.Internal, "Error al aprobar la empresa")
 		}
 		return
 	}
 
-	// 3. Responder con éxito.
+	// 3. Registrar la aprobación en la auditoría.
+	entry := models.AuditLog{
+		Action:     models.AuditActionCompanyApproved,
+		TargetType: models.AuditTargetUser,
+		TargetId:   idStr,
+	}
+	if adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64); ok {
+		entry.ActorId = &adminID
+	}
+	services.RecordAudit(r, entry, nil)
+
+	// 4. Responder con éxito.
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Empresa aprobada exitosamente"})
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const adminUserHandlerComponent = "ADMIN_USER_HANDLER"
+
+// AdminUserHandler maneja la gestión de usuarios del panel de administración: búsqueda,
+// cambio de rol y estado, restablecimiento de contraseña y desactivación de cuentas.
+type AdminUserHandler struct {
+	db      *sql.DB
+	service services.IAdminUserService
+}
+
+// NewAdminUserHandler crea una nueva instancia de AdminUserHandler.
+func NewAdminUserHandler(db *sql.DB, service services.IAdminUserService) *AdminUserHandler {
+	return &AdminUserHandler{db: db, service: service}
+}
+
+// adminAndTarget lee el ID del administrador autenticado y el del usuario de la ruta. Si
+// falta alguno escribe el error y devuelve ok = false.
+func adminAndTarget(w http.ResponseWriter, r *http.Request) (adminID, userID int64, ok bool) {
+	adminID, ok = r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return 0, 0, false
+	}
+	userID, ok = pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de usuario inválido")
+		return 0, 0, false
+	}
+	return adminID, userID, true
+}
+
+// SearchUsers busca usuarios. Parámetros de query: q (nombre, usuario, email o empresa),
+// roleId, statusId, page y pageSize. Un administrador de una institución solo ve a los
+// usuarios de la suya.
+func (h *AdminUserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	filter := models.AdminUserSearch{Query: r.URL.Query().Get("q"), TenantId: middleware.AdminTenantID(r.Context())}
+	for param, target := range map[string]*int{"roleId": &filter.RoleId, "statusId": &filter.StatusId} {
+		raw := r.URL.Query().Get(param)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value <= 0 {
+			apperrors.Write(w, apperrors.InvalidParam, param+" inválido")
+			return
+		}
+		*target = value
+	}
+
+	page, pageSize := pageParams(r)
+	response, err := h.service.SearchUsers(filter, page, pageSize)
+	if err != nil {
+		logger.Errorf(adminUserHandlerComponent, "Error al buscar usuarios: %v", err)
+		apperrors.WriteError(w, err, "Error al buscar usuarios")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ChangeRole cambia el rol del usuario. Cuerpo: {"roleId": 2}.
+func (h *AdminUserHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	adminID, userID, ok := adminAndTarget(w, r)
+	if !ok {
+		return
+	}
+	var req models.UpdateUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	previous, err := h.service.ChangeRole(adminID, userID, req.RoleId)
+	if err != nil {
+		logger.Warnf(adminUserHandlerComponent, "No se pudo cambiar el rol del usuario %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al cambiar el rol")
+		return
+	}
+	middleware.ForgetAccountState(userID)
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionRoleChanged,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(userID, 10),
+		ActorId:    &adminID,
+	}, map[string]interface{}{"previousRoleId": previous, "roleId": req.RoleId})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"userId":         userID,
+		"roleId":         req.RoleId,
+		"previousRoleId": previous,
+	})
+}
+
+// ChangeStatus cambia el StatusAuthorized del usuario. Cuerpo: {"statusAuthorizedId": 1}.
+func (h *AdminUserHandler) ChangeStatus(w http.ResponseWriter, r *http.Request) {
+	adminID, userID, ok := adminAndTarget(w, r)
+	if !ok {
+		return
+	}
+	var req models.UpdateUserStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	previous, err := h.service.ChangeStatus(adminID, userID, req.StatusAuthorizedId)
+	if err != nil {
+		logger.Warnf(adminUserHandlerComponent, "No se pudo cambiar el estado del usuario %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al cambiar el estado")
+		return
+	}
+	middleware.ForgetAccountState(userID)
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionUserStatusChanged,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(userID, 10),
+		ActorId:    &adminID,
+	}, map[string]interface{}{"previousStatusId": previous, "statusId": req.StatusAuthorizedId})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"userId":             userID,
+		"statusAuthorizedId": req.StatusAuthorizedId,
+		"previousStatusId":   previous,
+	})
+}
+
+// ChangeTenant cambia la institución del usuario. Cuerpo: {"tenantId": 3}; 0 la quita.
+func (h *AdminUserHandler) ChangeTenant(w http.ResponseWriter, r *http.Request) {
+	adminID, userID, ok := adminAndTarget(w, r)
+	if !ok {
+		return
+	}
+	var req models.UpdateUserTenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	previous, err := h.service.ChangeTenant(adminID, middleware.AdminTenantID(r.Context()), userID, req.TenantId)
+	if err != nil {
+		logger.Warnf(adminUserHandlerComponent, "No se pudo cambiar la institución del usuario %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al cambiar la institución")
+		return
+	}
+	middleware.ForgetAccountState(userID)
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionUserTenantChanged,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(userID, 10),
+		ActorId:    &adminID,
+	}, map[string]interface{}{"previousTenantId": previous, "tenantId": req.TenantId})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"userId":           userID,
+		"tenantId":         req.TenantId,
+		"previousTenantId": previous,
+	})
+}
+
+// SendPasswordReset envía al usuario un código de restablecimiento de contraseña, igual que
+// si lo hubiera pedido él desde la pantalla de inicio de sesión.
+func (h *AdminUserHandler) SendPasswordReset(w http.ResponseWriter, r *http.Request) {
+	adminID, userID, ok := adminAndTarget(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		apperrors.WriteError(w, err, "Error al obtener el usuario")
+		return
+	}
+
+	resetCode, err := generateResetToken()
+	if err != nil {
+		logger.Errorf(adminUserHandlerComponent, "Error generating reset code: %v", err)
+		apperrors.Write(w, apperrors.Internal, "Error processing request")
+		return
+	}
+	if err := saveResetCode(h.db, user.Id, resetCode, time.Now().Add(1*time.Hour)); err != nil {
+		logger.Errorf(adminUserHandlerComponent, "Error saving reset code: %v", err)
+		apperrors.Write(w, apperrors.Internal, "Error processing request")
+		return
+	}
+	if err := sendPasswordResetEmail(resetCode, user.Email); err != nil {
+		logger.Errorf(adminUserHandlerComponent, "Error sending email: %v", err)
+		apperrors.Write(w, apperrors.Internal, "Error sending email")
+		return
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionPasswordResetSent,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(userID, 10),
+		ActorId:    &adminID,
+	}, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Código de restablecimiento enviado al correo del usuario"})
+}
+
+// Deactivate desactiva la cuenta del usuario y cierra sus sesiones. Cuerpo opcional:
+// {"reason": "..."}; el motivo se muestra al usuario en la notificación.
+func (h *AdminUserHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
+	adminID, userID, ok := adminAndTarget(w, r)
+	if !ok {
+		return
+	}
+	var req models.DeactivateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	if err := h.service.Deactivate(adminID, userID, req.Reason); err != nil {
+		logger.Warnf(adminUserHandlerComponent, "No se pudo desactivar la cuenta %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al desactivar la cuenta")
+		return
+	}
+	middleware.ForgetAccountState(userID)
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionUserDeactivated,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(userID, 10),
+		ActorId:    &adminID,
+	}, map[string]interface{}{"reason": req.Reason})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Reactivate reactiva una cuenta desactivada con el estado que tenía antes.
+func (h *AdminUserHandler) Reactivate(w http.ResponseWriter, r *http.Request) {
+	adminID, userID, ok := adminAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.Reactivate(userID)
+	if err != nil {
+		logger.Warnf(adminUserHandlerComponent, "No se pudo reactivar la cuenta %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al reactivar la cuenta")
+		return
+	}
+	middleware.ForgetAccountState(userID)
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionUserReactivated,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(userID, 10),
+		ActorId:    &adminID,
+	}, map[string]interface{}{"statusId": status})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"userId":             userID,
+		"statusAuthorizedId": status,
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
 	"github.com/davidM20/micro-service-backend-go.git/internal/services"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/storage"
 	"github.com/gorilla/mux"
 )
 
@@ -29,11 +30,12 @@ import (
 type AudioHandler struct {
 	audioService *services.AudioUploadService
 	cfg          *config.Config // Añadido para JWT y GCS config
+	store        storage.Storage
 }
 
 // NewAudioHandler crea una nueva instancia de AudioHandler.
-func NewAudioHandler(audioService *services.AudioUploadService, cfg *config.Config) *AudioHandler {
-	return &AudioHandler{audioService: audioService, cfg: cfg}
+func NewAudioHandler(audioService *services.AudioUploadService, cfg *config.Config, store storage.Storage) *AudioHandler {
+	return &AudioHandler{audioService: audioService, cfg: cfg, store: store}
 }
 
 // UploadAudio es el método que maneja la petición POST para subir un archivo de audio.
@@ -72,6 +74,9 @@ func (h *AudioHandler) UploadAudio(w http.ResponseWriter, r *http.Request) {
 	uploadDetails, err := h.audioService.ProcessAndUploadAudio(r.Context(), userID, file, handler)
 	if err != nil {
 		logger.Errorf("UploadAudio.ServiceCall", "Error procesando el audio para el usuario %d: %v", userID, err)
+		if writeUploadCheckError(w, err) {
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError) // O BadRequest dependiendo del error
 		json.NewEncoder(w).Encode(map[string]string{"error": "Error al procesar el archivo de audio: " + err.Error()})
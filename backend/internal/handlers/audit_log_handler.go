@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/timefmt"
+)
+
+const auditLogHandlerComponent = "AUDIT_LOG_HANDLER"
+
+// maxAuditLogPageSize limita el tamaño de página del registro de auditoría.
+const maxAuditLogPageSize = 100
+
+// AuditLogHandler expone al administrador el registro de auditoría.
+type AuditLogHandler struct {
+	service services.IAuditService
+}
+
+// NewAuditLogHandler crea una nueva instancia de AuditLogHandler.
+func NewAuditLogHandler(service services.IAuditService) *AuditLogHandler {
+	return &AuditLogHandler{service: service}
+}
+
+// ListAuditLogs devuelve el registro de auditoría paginado.
+// Query params opcionales: page, pageSize, actorId, action, targetType, targetId,
+// from y to (RFC3339).
+func (h *AuditLogHandler) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	page, err := strconv.Atoi(q.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(q.Get("pageSize"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > maxAuditLogPageSize {
+		pageSize = maxAuditLogPageSize
+	}
+
+	filter := models.AuditLogFilter{
+		Action:     q.Get("action"),
+		TargetType: q.Get("targetType"),
+		TargetId:   q.Get("targetId"),
+	}
+	if actor := q.Get("actorId"); actor != "" {
+		actorID, err := strconv.ParseInt(actor, 10, 64)
+		if err != nil {
+			apperrors.Write(w, apperrors.InvalidParam, "actorId inválido")
+			return
+		}
+		filter.ActorId = &actorID
+	}
+	for param, dst := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := q.Get(param)
+		if value == "" {
+			continue
+		}
+		t, err := timefmt.Parse(value)
+		if err != nil {
+			apperrors.Write(w, apperrors.InvalidParam, param+" debe tener formato RFC3339")
+			return
+		}
+		*dst = &t
+	}
+
+	response, err := h.service.ListLogs(filter, page, pageSize)
+	if err != nil {
+		logger.Errorf(auditLogHandlerComponent, "Error obteniendo el registro de auditoría: %v", err)
+		apperrors.Write(w, apperrors.Internal, "Error al obtener el registro de auditoría")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
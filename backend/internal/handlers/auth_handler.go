@@ -14,8 +14,11 @@ import (
 	"github.com/davidM20/micro-service-backend-go.git/internal/auth"   // Para JWT y hash de contraseña
 	"github.com/davidM20/micro-service-backend-go.git/internal/config" // Importar config
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/phonetic"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/timefmt"
 
 	// Importa otros paquetes necesarios (ej. para validación, logging)
 
@@ -28,37 +31,67 @@ import (
 
 // AuthHandler maneja las peticiones relacionadas con autenticación y registro
 type AuthHandler struct {
-	DB  *sql.DB
-	Cfg *config.Config // Añadir configuración
+	DB             *sql.DB
+	Cfg            *config.Config // Añadir configuración
+	PasswordPolicy *auth.PasswordPolicy
+	LoginSecurity  services.ILoginSecurityService
 }
 
 // NewAuthHandler crea una nueva instancia de AuthHandler
 func NewAuthHandler(db *sql.DB, cfg *config.Config) *AuthHandler { // Añadir cfg como parámetro
-	return &AuthHandler{DB: db, Cfg: cfg} // Almacenar cfg
+	policy := auth.NewPasswordPolicy(
+		cfg.PasswordMinLength,
+		cfg.PasswordRequireUpper,
+		cfg.PasswordRequireLower,
+		cfg.PasswordRequireDigit,
+		cfg.PasswordRequireSymbol,
+		cfg.PasswordBreachCheck,
+		cfg.PasswordBreachTimeout,
+	)
+	return &AuthHandler{
+		DB:             db,
+		Cfg:            cfg, // Almacenar cfg
+		PasswordPolicy: policy,
+		LoginSecurity:  services.NewLoginSecurityService(db, cfg),
+	}
+}
+
+// validatePassword aplica la política de contraseñas y responde 400 si no la cumple.
+// Devuelve false cuando ya se escribió la respuesta.
+func (h *AuthHandler) validatePassword(w http.ResponseWriter, r *http.Request, password string, userInputs ...string) bool {
+	if err := h.PasswordPolicy.Validate(r.Context(), password, userInputs...); err != nil {
+		apperrors.Write(w, apperrors.WeakPassword, err.Error())
+		return false
+	}
+	return true
 }
 
 // Register maneja el primer paso del registro de usuario una vez que se ha registrado los pasos siguientes ocurren al hacer login
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req models.RegistrationStep1
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.InvalidBody, "Invalid request body")
 		return
 	}
 
 	// TODO: Validar los datos de entrada (longitud, formato email, etc.)
 	if req.Email == "" || req.Password == "" || req.FirstName == "" || req.LastName == "" || req.UserName == "" {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.MissingFields, "Missing required fields")
+		return
+	}
+
+	if !h.validatePassword(w, r, req.Password, req.Email, req.UserName) {
 		return
 	}
 
 	// Verificar si el email o username ya existen usando la consulta centralizada
 	exists, err := queries.CheckUserExists(h.DB, req.Email, req.UserName)
 	if err != nil {
-		http.Error(w, "Database error", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Database error")
 		return
 	}
 	if exists {
-		http.Error(w, "Email or Username already exists", http.StatusConflict)
+		apperrors.Write(w, apperrors.AccountExists, "Email or Username already exists")
 		return
 	}
 
@@ -66,7 +99,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		logger.Errorf("REGISTER", "Error hashing password for %s: %v", req.Email, err)
-		http.Error(w, "Error processing registration", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error processing registration")
 		return
 	}
 
@@ -80,15 +113,13 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		sKey = ""
 	}
 
-	// Insertar usuario inicial usando la consulta centralizada
-	// Asignar rol por defecto (ej. invitado o pendiente) y estado pendiente
-	// TODO: Definir IDs para rol 'pendiente' y estado 'pendiente verificación'
-	defaultRoleId := 1   // Asumiendo 1 = /estudiante
-	defaultStatusId := 1 // Asumiendo 1 como verificado, 2 como pendiente de verificación
+	// Insertar usuario inicial usando la consulta centralizada, como estudiante activo
+	defaultRoleId := int(models.RoleStudent)
+	defaultStatusId := int(models.UserStatusActive)
 
 	userID, err := queries.RegisterNewUser(h.DB, req, string(hashedPassword), defaultRoleId, defaultStatusId, pKey, sKey)
 	if err != nil {
-		http.Error(w, "Failed to register user", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Failed to register user")
 		return
 	}
 
@@ -140,37 +171,37 @@ func (h *AuthHandler) RegisterStep2(w http.ResponseWriter, r *http.Request) {
 	// Obtener el ID del usuario del contexto (establecido por el middleware de autenticación)
 	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
 	if !ok {
-		http.Error(w, "Usuario no autenticado", http.StatusUnauthorized)
+		apperrors.Write(w, apperrors.Unauthenticated, "Usuario no autenticado")
 		return
 	}
 
 	var req models.RegistrationStep2
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.InvalidBody, "Invalid request body")
 		return
 	}
 
 	// TODO: Validar DocId con el formato de NationalityId si es posible
 	if req.DocId == "" || req.NationalityId == 0 {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.MissingFields, "Missing required fields")
 		return
 	}
 
 	// Verificar si el DocId ya existe usando la consulta centralizada
 	exists, err := queries.CheckDocIdExists(h.DB, req.DocId, userID)
 	if err != nil {
-		http.Error(w, "Database error", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Database error")
 		return
 	}
 	if exists {
-		http.Error(w, "Document ID already registered", http.StatusConflict)
+		apperrors.Write(w, apperrors.DocumentIdTaken, "Document ID already registered")
 		return
 	}
 
 	// Actualizar usuario usando la consulta centralizada
 	err = queries.UpdateUserStep2(h.DB, userID, req.DocId, req.NationalityId)
 	if err != nil {
-		http.Error(w, "Failed to update registration", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Failed to update registration")
 		return
 	}
 
@@ -184,30 +215,29 @@ func (h *AuthHandler) RegisterStep3(w http.ResponseWriter, r *http.Request) {
 	// Obtener el ID del usuario del contexto (establecido por el middleware de autenticación)
 	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
 	if !ok {
-		http.Error(w, "Usuario no autenticado", http.StatusUnauthorized)
+		apperrors.Write(w, apperrors.Unauthenticated, "Usuario no autenticado")
 		return
 	}
 
 	var req models.RegistrationStep3
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.InvalidBody, "Invalid request body")
 		return
 	}
 
 	// TODO: Validar Sex y Birthdate
 	if req.Sex == "" || req.Birthdate.IsZero() {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.MissingFields, "Missing required fields")
 		return
 	}
 
 	// Actualizar usuario y marcar como activo/verificado usando la consulta centralizada
-	// TODO: Decidir el RoleId final (ej. estudiante-pregrado si aplica) y StatusAuthorizedId final (ej. Active)
-	finalRoleId := 1   // Asumiendo 1 = estudiante-pregrado por defecto tras registro
-	finalStatusId := 1 // Asumiendo 1 = Active
+	finalRoleId := int(models.RoleStudent)
+	finalStatusId := int(models.UserStatusActive)
 
 	err := queries.UpdateUserStep3(h.DB, userID, req.Sex, req.Birthdate, finalRoleId, finalStatusId)
 	if err != nil {
-		http.Error(w, "Failed to complete registration", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Failed to complete registration")
 		return
 	}
 
@@ -220,24 +250,28 @@ func (h *AuthHandler) RegisterStep3(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) RegisterCompany(w http.ResponseWriter, r *http.Request) {
 	var req models.CompanyRegistrationRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.InvalidBody, "Invalid request body")
 		return
 	}
 
 	// Validar los datos de entrada
 	if req.Email == "" || req.Password == "" || req.CompanyName == "" || req.RIF == "" {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.MissingFields, "Missing required fields")
+		return
+	}
+
+	if !h.validatePassword(w, r, req.Password, req.Email, req.CompanyName) {
 		return
 	}
 
 	// Verificar si el email o RIF ya existen
 	exists, err := queries.CheckCompanyExists(req.Email, req.RIF)
 	if err != nil {
-		http.Error(w, "Database error", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Database error")
 		return
 	}
 	if exists {
-		http.Error(w, "Email or RIF already exists", http.StatusConflict)
+		apperrors.Write(w, apperrors.AccountExists, "Email or RIF already exists")
 		return
 	}
 
@@ -245,17 +279,17 @@ func (h *AuthHandler) RegisterCompany(w http.ResponseWriter, r *http.Request) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		logger.Errorf("REGISTER_COMPANY", "Error hashing password for %s: %v", req.Email, err)
-		http.Error(w, "Error processing registration", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error processing registration")
 		return
 	}
 
-	// Rol para empresa es 3, estado pendiente de verificación es 2
-	companyRoleId := 3
-	defaultStatusId := 1 // 2 = Activo/Verificado, 1 = Pendiente de Verificación
+	// La empresa queda pendiente hasta que un administrador la apruebe
+	companyRoleId := int(models.RoleBusiness)
+	defaultStatusId := int(models.UserStatusCompanyPending)
 
 	userID, err := queries.RegisterNewCompany(h.DB, req, string(hashedPassword), companyRoleId, defaultStatusId)
 	if err != nil {
-		http.Error(w, "Failed to register company", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Failed to register company")
 		return
 	}
 
@@ -298,29 +332,30 @@ func (h *AuthHandler) RegisterCompany(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req models.LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.InvalidBody, "Invalid request body")
 		return
 	}
 
 	// TODO: Usar username O email para login?
 	// Por ahora, la consulta SQL solo busca por Email.
 	if req.Email == "" || req.Password == "" { // Ajustar validación si se permite username
-		http.Error(w, "Email and password are required", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.MissingFields, "Email and password are required")
 		return
 	}
 
 	// Obtener datos del usuario usando la consulta centralizada
 	user, hashedPassword, err := queries.GetUserByEmail(h.DB, req.Email)
 	if err == sql.ErrNoRows {
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		services.RecordAudit(r, models.AuditLog{ActorName: req.Email, Action: models.AuditActionLoginFailed}, map[string]interface{}{"reason": "unknown_email"})
+		apperrors.Write(w, apperrors.InvalidCredentials, "Invalid credentials")
 		return
 	}
 	if err != nil {
-		http.Error(w, "Login failed due to server error", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Login failed due to server error")
 		return
 	}
 
-	// if user.StatusAuthorizedId != 1 { // Asumiendo 1 = Active
+	// if user.StatusAuthorizedId != int(models.UserStatusActive) {
 	// 	logger.Warnf("LOGIN", "Login attempt for inactive account: UserID %d, StatusID %d", user.Id, user.StatusAuthorizedId)
 	// 	http.Error(w, "Account is not active", http.StatusForbidden)
 	// 	return
@@ -328,34 +363,71 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	// Compara la contraseña ingresada con la contraseña hasheada almacenada
 	if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(req.Password)); err != nil {
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		services.RecordAudit(r, models.AuditLog{
+			ActorName:  req.Email,
+			Action:     models.AuditActionLoginFailed,
+			TargetType: models.AuditTargetUser,
+			TargetId:   strconv.FormatInt(user.Id, 10),
+		}, map[string]interface{}{"reason": "invalid_password"})
+		apperrors.Write(w, apperrors.InvalidCredentials, "Invalid credentials")
+		return
+	}
+
+	// Las cuentas suspendidas por moderación o desactivadas por un administrador no pueden
+	// iniciar sesión. Se comprueba después de la contraseña para no revelar el estado de la
+	// cuenta a quien no la conoce.
+	if user.StatusAuthorizedId == int(models.UserStatusSuspended) {
+		services.RecordAudit(r, models.AuditLog{
+			ActorName:  req.Email,
+			Action:     models.AuditActionLoginFailed,
+			TargetType: models.AuditTargetUser,
+			TargetId:   strconv.FormatInt(user.Id, 10),
+		}, map[string]interface{}{"reason": "account_suspended"})
+		apperrors.Write(w, apperrors.AccountSuspended, "Account suspended")
+		return
+	}
+	if user.StatusAuthorizedId == int(models.UserStatusDeactivated) {
+		services.RecordAudit(r, models.AuditLog{
+			ActorName:  req.Email,
+			Action:     models.AuditActionLoginFailed,
+			TargetType: models.AuditTargetUser,
+			TargetId:   strconv.FormatInt(user.Id, 10),
+		}, map[string]interface{}{"reason": "account_deactivated"})
+		apperrors.Write(w, apperrors.AccountDeactivated, "Account deactivated")
 		return
 	}
 
 	// Generar el token JWT
 	expirationTime := time.Hour * 24 * 360 // Token válido por 24 horas
-	tokenString, tokenID, err := auth.GenerateJWT(user.Id, int64(user.RoleId), []byte(h.Cfg.JwtSecret), expirationTime)
+	tokenString, tokenID, err := auth.GenerateJWT(user.Id, int64(user.RoleId), user.TenantId.Int64, []byte(h.Cfg.JwtSecret), expirationTime)
 	if err != nil {
 		logger.Errorf("LOGIN", "Error generating JWT for user %s: %v", req.Email, err)
-		http.Error(w, "Error generating session token", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error generating session token")
 		return
 	}
 
-	// Insertar el token en la tabla Session usando la consulta centralizada
-	// clientIP := getClientIP(r)
-
-	clientIP := "127.0.0.1"
-	err = queries.RegisterUserSession(h.DB, user.Id, tokenString, clientIP, user.RoleId, tokenID)
+	// Insertar el token en la tabla Session con el dispositivo y la ubicación del inicio de
+	// sesión. Los dispositivos o países nuevos generan un aviso de seguridad.
+	clientIP, device, err := h.LoginSecurity.RegisterSession(r, user, tokenString, tokenID)
 	if err != nil {
 		logger.Errorf("LOGIN", "Error creating session for user %s: %v", req.Email, err)
-		http.Error(w, "Error creating session", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error creating session")
 		return
 	}
 
 	// Si el usuario es administrador, enviar notificación de seguridad en una goroutine
+	loginAction := models.AuditActionLogin
 	if user.RoleId == int(models.RoleAdmin) {
+		loginAction = models.AuditActionAdminLogin
 		go h.handleAdminLoginNotification(user, clientIP)
 	}
+	services.RecordAudit(r, models.AuditLog{
+		ActorId:    &user.Id,
+		ActorName:  user.Email,
+		Action:     loginAction,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(user.Id, 10),
+	}, map[string]interface{}{"roleId": user.RoleId, "deviceType": device.DeviceType, "country": device.Country})
 
 	// Preparar la respuesta
 	resp := models.LoginResponse{
@@ -387,7 +459,7 @@ func (h *AuthHandler) handleAdminLoginNotification(user models.User, ipAddress s
 	notif := models.Event{
 		EventType:      "ADMIN_LOGIN",
 		EventTitle:     "Alerta de Seguridad: Inicio de Sesión de Administrador",
-		Description:    fmt.Sprintf("Se ha iniciado sesión en una cuenta de administrador desde la IP: %s a las %s.", ipAddress, time.Now().Format("2006-01-02 15:04:05")),
+		Description:    fmt.Sprintf("Se ha iniciado sesión en una cuenta de administrador desde la IP: %s a las %s.", ipAddress, timefmt.Display(time.Now()).Format("2006-01-02 15:04:05")),
 		UserId:         user.Id,
 		ActionRequired: true,
 		Metadata:       j,
@@ -421,12 +493,12 @@ func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Reques
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.InvalidBody, "Invalid request body")
 		return
 	}
 
 	if req.Email == "" {
-		http.Error(w, "Email is required", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.MissingFields, "Email is required")
 		return
 	}
 
@@ -442,7 +514,7 @@ func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Reques
 
 	if err != nil {
 		logger.Errorf("RESET_PASSWORD", "Error checking email existence: %v", err)
-		http.Error(w, "Error processing request", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error processing request")
 		return
 	}
 
@@ -450,7 +522,7 @@ func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Reques
 	resetCode, err := generateResetToken()
 	if err != nil {
 		logger.Errorf("RESET_PASSWORD", "Error generating reset code: %v", err)
-		http.Error(w, "Error processing request", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error processing request")
 		return
 	}
 
@@ -459,7 +531,7 @@ func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Reques
 	err = saveResetCode(h.DB, user.Id, resetCode, expiration)
 	if err != nil {
 		logger.Errorf("RESET_PASSWORD", "Error saving reset code: %v", err)
-		http.Error(w, "Error processing request", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error processing request")
 		return
 	}
 
@@ -467,10 +539,17 @@ func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Reques
 	err = sendPasswordResetEmail(resetCode, req.Email)
 	if err != nil {
 		logger.Errorf("RESET_PASSWORD", "Error sending email: %v", err)
-		http.Error(w, "Error sending email", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error sending email")
 		return
 	}
 
+	services.RecordAudit(r, models.AuditLog{
+		ActorName:  req.Email,
+		Action:     models.AuditActionPasswordResetRequested,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(user.Id, 10),
+	}, nil)
+
 	logger.Successf("RESET_PASSWORD", "Password reset code sent to user %s (ID: %d)", req.Email, user.Id)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{
@@ -483,7 +562,7 @@ func (h *AuthHandler) VerifyPasswordReset(w http.ResponseWriter, r *http.Request
 	// Obtener el código de la URL
 	code := r.URL.Query().Get("code")
 	if code == "" {
-		http.Error(w, "Code is required", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.MissingFields, "Code is required")
 		return
 	}
 
@@ -491,12 +570,12 @@ func (h *AuthHandler) VerifyPasswordReset(w http.ResponseWriter, r *http.Request
 	_, valid, err := verifyResetCode(h.DB, code)
 	if err != nil {
 		logger.Errorf("RESET_PASSWORD", "Error verifying code: %v", err)
-		http.Error(w, "Error verifying code", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error verifying code")
 		return
 	}
 
 	if !valid {
-		http.Error(w, "Invalid or expired code", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.InvalidResetCode, "Invalid or expired code")
 		return
 	}
 
@@ -516,12 +595,12 @@ func (h *AuthHandler) CompletePasswordReset(w http.ResponseWriter, r *http.Reque
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.InvalidBody, "Invalid request body")
 		return
 	}
 
 	if req.Code == "" || req.NewPassword == "" {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.MissingFields, "Missing required fields")
 		return
 	}
 
@@ -529,18 +608,17 @@ func (h *AuthHandler) CompletePasswordReset(w http.ResponseWriter, r *http.Reque
 	userID, valid, err := verifyResetCode(h.DB, req.Code)
 	if err != nil {
 		logger.Errorf("RESET_PASSWORD", "Error verifying code: %v", err)
-		http.Error(w, "Error verifying code", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error verifying code")
 		return
 	}
 
 	if !valid {
-		http.Error(w, "Invalid or expired code", http.StatusBadRequest)
+		apperrors.Write(w, apperrors.InvalidResetCode, "Invalid or expired code")
 		return
 	}
 
-	// Validar que la nueva contraseña cumpla con requisitos mínimos
-	if len(req.NewPassword) < 8 {
-		http.Error(w, "Password must be at least 8 characters long", http.StatusBadRequest)
+	// Validar que la nueva contraseña cumpla la política
+	if !h.validatePassword(w, r, req.NewPassword) {
 		return
 	}
 
@@ -548,7 +626,7 @@ func (h *AuthHandler) CompletePasswordReset(w http.ResponseWriter, r *http.Reque
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
 		logger.Errorf("RESET_PASSWORD", "Error hashing password: %v", err)
-		http.Error(w, "Error processing request", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error processing request")
 		return
 	}
 
@@ -556,7 +634,7 @@ func (h *AuthHandler) CompletePasswordReset(w http.ResponseWriter, r *http.Reque
 	err = updateUserPassword(h.DB, userID, string(hashedPassword))
 	if err != nil {
 		logger.Errorf("RESET_PASSWORD", "Error updating password: %v", err)
-		http.Error(w, "Error updating password", http.StatusInternalServerError)
+		apperrors.Write(w, apperrors.Internal, "Error updating password")
 		return
 	}
 
@@ -567,6 +645,13 @@ func (h *AuthHandler) CompletePasswordReset(w http.ResponseWriter, r *http.Reque
 		// No devolvemos error al cliente porque la contraseña ya se cambió
 	}
 
+	services.RecordAudit(r, models.AuditLog{
+		ActorId:    &userID,
+		Action:     models.AuditActionPasswordResetCompleted,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(userID, 10),
+	}, nil)
+
 	logger.Successf("RESET_PASSWORD", "Password reset completed for user ID %d", userID)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Contraseña actualizada con éxito"})
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const blockHandlerComponent = "BLOCK_HANDLER"
+
+// BlockHandler maneja los bloqueos del usuario autenticado.
+type BlockHandler struct {
+	service services.IBlockService
+}
+
+// NewBlockHandler crea una nueva instancia de BlockHandler.
+func NewBlockHandler(service services.IBlockService) *BlockHandler {
+	return &BlockHandler{service: service}
+}
+
+// List devuelve los usuarios bloqueados. Parámetros de query: page y pageSize.
+func (h *BlockHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	page, pageSize := pageParams(r)
+	blocks, err := h.service.List(userID, page, pageSize)
+	if err != nil {
+		logger.Errorf(blockHandlerComponent, "Error al listar los bloqueos de %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al obtener los usuarios bloqueados")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(blocks)
+}
+
+// Block bloquea al usuario de la ruta.
+func (h *BlockHandler) Block(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	blockedID, ok := pathID(r, "userID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de usuario inválido")
+		return
+	}
+
+	if err := h.service.Block(userID, blockedID); err != nil {
+		logger.Warnf(blockHandlerComponent, "No se pudo bloquear a %d por %d: %v", blockedID, userID, err)
+		apperrors.WriteError(w, err, "Error al bloquear al usuario")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Unblock elimina el bloqueo al usuario de la ruta.
+func (h *BlockHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	blockedID, ok := pathID(r, "userID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de usuario inválido")
+		return
+	}
+
+	if err := h.service.Unblock(userID, blockedID); err != nil {
+		logger.Warnf(blockHandlerComponent, "No se pudo desbloquear a %d por %d: %v", blockedID, userID, err)
+		apperrors.WriteError(w, err, "Error al desbloquear al usuario")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
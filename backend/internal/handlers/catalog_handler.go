@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const catalogHandlerComponent = "CATALOG_HANDLER"
+
+// Nombres de los catálogos en el registro de auditoría (TargetId = "<catálogo>:<id>").
+const (
+	catalogNationality = "nationality"
+	catalogUniversity  = "university"
+	catalogDegree      = "degree"
+)
+
+// CatalogHandler expone los catálogos de nacionalidades, universidades y carreras: la
+// lectura pública para los selectores y el CRUD del panel de administración.
+type CatalogHandler struct {
+	service services.ICatalogService
+}
+
+// NewCatalogHandler crea una nueva instancia de CatalogHandler.
+func NewCatalogHandler(service services.ICatalogService) *CatalogHandler {
+	return &CatalogHandler{service: service}
+}
+
+// GetCatalogs devuelve los tres catálogos en una sola respuesta. La ruta usa el middleware
+// ETag, así que el cliente puede revalidar con If-None-Match.
+func (h *CatalogHandler) GetCatalogs(w http.ResponseWriter, r *http.Request) {
+	catalogs, err := h.service.GetCatalogs()
+	if err != nil {
+		logger.Errorf(catalogHandlerComponent, "Error al obtener los catálogos: %v", err)
+		apperrors.WriteError(w, err, "Error al obtener los catálogos")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(catalogs)
+}
+
+// CreateNationality crea una nacionalidad. Cuerpo: {"country_name", "iso_code", "doc_id_format"}.
+func (h *CatalogHandler) CreateNationality(w http.ResponseWriter, r *http.Request) {
+	createCatalogItem(w, r, catalogNationality, h.service.CreateNationality,
+		func(nat models.Nationality) int64 { return int64(nat.Id) })
+}
+
+// UpdateNationality reemplaza los datos de una nacionalidad.
+func (h *CatalogHandler) UpdateNationality(w http.ResponseWriter, r *http.Request) {
+	updateCatalogItem(w, r, catalogNationality, h.service.UpdateNationality)
+}
+
+// DeleteNationality elimina una nacionalidad que ningún usuario tenga asignada.
+func (h *CatalogHandler) DeleteNationality(w http.ResponseWriter, r *http.Request) {
+	deleteCatalogItem(w, r, catalogNationality, h.service.DeleteNationality)
+}
+
+// CreateUniversity crea una universidad. Cuerpo: {"name", "campus", "email_domains"}.
+func (h *CatalogHandler) CreateUniversity(w http.ResponseWriter, r *http.Request) {
+	createCatalogItem(w, r, catalogUniversity, h.service.CreateUniversity,
+		func(uni models.University) int64 { return uni.Id })
+}
+
+// UpdateUniversity reemplaza los datos de una universidad.
+func (h *CatalogHandler) UpdateUniversity(w http.ResponseWriter, r *http.Request) {
+	updateCatalogItem(w, r, catalogUniversity, h.service.UpdateUniversity)
+}
+
+// DeleteUniversity elimina una universidad sin carreras ni usuarios.
+func (h *CatalogHandler) DeleteUniversity(w http.ResponseWriter, r *http.Request) {
+	deleteCatalogItem(w, r, catalogUniversity, h.service.DeleteUniversity)
+}
+
+// CreateDegree crea una carrera. Cuerpo: {"degree_name", "descriptions", "code", "university_id"}.
+func (h *CatalogHandler) CreateDegree(w http.ResponseWriter, r *http.Request) {
+	createCatalogItem(w, r, catalogDegree, h.service.CreateDegree,
+		func(deg models.Degree) int64 { return deg.Id })
+}
+
+// UpdateDegree reemplaza los datos de una carrera.
+func (h *CatalogHandler) UpdateDegree(w http.ResponseWriter, r *http.Request) {
+	updateCatalogItem(w, r, catalogDegree, h.service.UpdateDegree)
+}
+
+// DeleteDegree elimina una carrera que ningún usuario tenga asignada.
+func (h *CatalogHandler) DeleteDegree(w http.ResponseWriter, r *http.Request) {
+	deleteCatalogItem(w, r, catalogDegree, h.service.DeleteDegree)
+}
+
+// createCatalogItem decodifica el elemento, lo crea, registra la auditoría y responde 201 con
+// el elemento creado.
+func createCatalogItem[T any](w http.ResponseWriter, r *http.Request, catalog string, create func(T) (T, error), idOf func(T) int64) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	var item T
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	created, err := create(item)
+	if err != nil {
+		logger.Warnf(catalogHandlerComponent, "No se pudo crear el elemento del catálogo %s: %v", catalog, err)
+		apperrors.WriteError(w, err, "Error al crear el elemento del catálogo")
+		return
+	}
+
+	auditCatalog(r, models.AuditActionCatalogItemCreated, catalog, idOf(created), adminID, created)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// updateCatalogItem decodifica el elemento, reemplaza el de la ruta y registra la auditoría.
+func updateCatalogItem[T any](w http.ResponseWriter, r *http.Request, catalog string, update func(int64, T) (T, error)) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	id, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID inválido")
+		return
+	}
+	var item T
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	updated, err := update(id, item)
+	if err != nil {
+		logger.Warnf(catalogHandlerComponent, "No se pudo actualizar el elemento %d del catálogo %s: %v", id, catalog, err)
+		apperrors.WriteError(w, err, "Error al actualizar el elemento del catálogo")
+		return
+	}
+
+	auditCatalog(r, models.AuditActionCatalogItemUpdated, catalog, id, adminID, updated)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// deleteCatalogItem elimina el elemento de la ruta y registra la auditoría.
+func deleteCatalogItem(w http.ResponseWriter, r *http.Request, catalog string, del func(int64) error) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	id, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID inválido")
+		return
+	}
+
+	if err := del(id); err != nil {
+		logger.Warnf(catalogHandlerComponent, "No se pudo eliminar el elemento %d del catálogo %s: %v", id, catalog, err)
+		apperrors.WriteError(w, err, "Error al eliminar el elemento del catálogo")
+		return
+	}
+
+	auditCatalog(r, models.AuditActionCatalogItemDeleted, catalog, id, adminID, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// auditCatalog registra una escritura del catálogo con el elemento resultante como metadatos.
+func auditCatalog(r *http.Request, action, catalog string, id, adminID int64, item interface{}) {
+	metadata := map[string]interface{}{"catalog": catalog}
+	if item != nil {
+		metadata["item"] = item
+	}
+	services.RecordAudit(r, models.AuditLog{
+		Action:     action,
+		TargetType: models.AuditTargetCatalog,
+		TargetId:   fmt.Sprintf("%s:%d", catalog, id),
+		ActorId:    &adminID,
+	}, metadata)
+}
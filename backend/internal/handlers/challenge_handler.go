@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const challengeHandlerComponent = "CHALLENGE_HANDLER"
+
+// ChallengeHandler maneja las entregas y revisiones de los desafíos de la comunidad.
+type ChallengeHandler struct {
+	service services.IChallengeService
+}
+
+// NewChallengeHandler crea una nueva instancia de ChallengeHandler.
+func NewChallengeHandler(service services.IChallengeService) *ChallengeHandler {
+	return &ChallengeHandler{service: service}
+}
+
+// Submit crea o reemplaza la entrega del usuario autenticado al desafío.
+func (h *ChallengeHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	roleID, _ := r.Context().Value(middleware.RoleIDContextKey).(int64)
+	eventID, ok := pathID(r, "eventID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de desafío inválido")
+		return
+	}
+
+	var req models.SubmitChallengeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	submission, err := h.service.Submit(eventID, userID, models.UserRole(roleID), req)
+	if err != nil {
+		logger.Warnf(challengeHandlerComponent, "No se pudo guardar la entrega de %d al desafío %d: %v", userID, eventID, err)
+		apperrors.WriteError(w, err, "Error al enviar la entrega")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(submission)
+}
+
+// GetMySubmission devuelve la entrega del usuario autenticado al desafío.
+func (h *ChallengeHandler) GetMySubmission(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	eventID, ok := pathID(r, "eventID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de desafío inválido")
+		return
+	}
+
+	submission, err := h.service.GetMySubmission(eventID, userID)
+	if err != nil {
+		apperrors.WriteError(w, err, "Error al obtener la entrega")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(submission)
+}
+
+// ListSubmissions devuelve las entregas del desafío a su creador, de la más antigua a la más
+// reciente. Parámetros de query: status, page y pageSize.
+func (h *ChallengeHandler) ListSubmissions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	roleID, _ := r.Context().Value(middleware.RoleIDContextKey).(int64)
+	eventID, ok := pathID(r, "eventID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de desafío inválido")
+		return
+	}
+
+	page, pageSize := pageParams(r)
+	submissions, err := h.service.ListSubmissions(eventID, userID, models.UserRole(roleID), r.URL.Query().Get("status"), page, pageSize)
+	if err != nil {
+		logger.Warnf(challengeHandlerComponent, "No se pudieron obtener las entregas del desafío %d para %d: %v", eventID, userID, err)
+		apperrors.WriteError(w, err, "Error al obtener las entregas")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(submissions)
+}
+
+// ReviewSubmission guarda la revisión (estado, puntuación y feedback) de una entrega.
+func (h *ChallengeHandler) ReviewSubmission(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	eventID, ok := pathID(r, "eventID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de desafío inválido")
+		return
+	}
+	submissionID, ok := pathID(r, "submissionID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de entrega inválido")
+		return
+	}
+
+	var req models.ReviewSubmissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	submission, err := h.service.ReviewSubmission(eventID, submissionID, userID, req)
+	if err != nil {
+		logger.Warnf(challengeHandlerComponent, "No se pudo revisar la entrega %d por %d: %v", submissionID, userID, err)
+		apperrors.WriteError(w, err, "Error al revisar la entrega")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(submission)
+}
+
+// UpdateChallengeStatus cambia el estado del desafío (EN_EVALUACION, CERRADO o CANCELADO).
+// Al cerrarlo se otorgan los RP de las entregas aprobadas.
+func (h *ChallengeHandler) UpdateChallengeStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	roleID, _ := r.Context().Value(middleware.RoleIDContextKey).(int64)
+	eventID, ok := pathID(r, "eventID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de desafío inválido")
+		return
+	}
+
+	var req models.UpdateChallengeStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	if err := h.service.UpdateChallengeStatus(eventID, userID, models.UserRole(roleID), req); err != nil {
+		logger.Warnf(challengeHandlerComponent, "No se pudo cambiar el estado del desafío %d a %s: %v", eventID, req.Status, err)
+		apperrors.WriteError(w, err, "Error al cambiar el estado del desafío")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
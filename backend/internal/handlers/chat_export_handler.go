@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const chatExportHandlerComponent = "CHAT_EXPORT_HANDLER"
+
+// ChatExportHandler expone la exportación de conversaciones del usuario autenticado.
+type ChatExportHandler struct {
+	service services.IChatExportService
+}
+
+// NewChatExportHandler crea una nueva instancia de ChatExportHandler.
+func NewChatExportHandler(service services.IChatExportService) *ChatExportHandler {
+	return &ChatExportHandler{service: service}
+}
+
+// Create exporta una conversación. Responde 201 con el enlace de descarga si la exportación
+// se generó durante la petición y 202 si se genera en segundo plano.
+func (h *ChatExportHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	var body models.CreateChatExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido")
+		return
+	}
+
+	export, err := h.service.Create(userID, body)
+	if err != nil {
+		logger.Warnf(chatExportHandlerComponent, "No se pudo exportar el chat %s del usuario %d: %v", body.ChatId, userID, err)
+		apperrors.WriteError(w, err, "Error al exportar la conversación")
+		return
+	}
+
+	status := http.StatusAccepted
+	if export.Status == models.ChatExportCompleted {
+		status = http.StatusCreated
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(export)
+}
+
+// List devuelve las exportaciones del usuario. Parámetros de query: page y pageSize.
+func (h *ChatExportHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	page, pageSize := pageParams(r)
+	exports, err := h.service.List(userID, page, pageSize)
+	if err != nil {
+		logger.Errorf(chatExportHandlerComponent, "Error al listar las exportaciones de chat de %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al obtener las exportaciones")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(exports)
+}
+
+// Get devuelve el estado de una exportación y, si está lista, un enlace de descarga nuevo.
+func (h *ChatExportHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	exportID, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de exportación inválido")
+		return
+	}
+
+	export, err := h.service.Get(userID, exportID)
+	if err != nil {
+		logger.Warnf(chatExportHandlerComponent, "No se pudo obtener la exportación %d del usuario %d: %v", exportID, userID, err)
+		apperrors.WriteError(w, err, "Error al obtener la exportación")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(export)
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const cohortAnalyticsHandlerComponent = "COHORT_ANALYTICS_HANDLER"
+
+// CohortAnalyticsHandler expone las analíticas de cohortes de egresados a los administradores.
+type CohortAnalyticsHandler struct {
+	service services.ICohortAnalyticsService
+}
+
+// NewCohortAnalyticsHandler crea una nueva instancia de CohortAnalyticsHandler.
+func NewCohortAnalyticsHandler(service services.ICohortAnalyticsService) *CohortAnalyticsHandler {
+	return &CohortAnalyticsHandler{service: service}
+}
+
+// GetCohorts devuelve las estadísticas por universidad, carrera y año de graduación.
+// Parámetros de query opcionales: university, degree, fromYear y toYear.
+func (h *CohortAnalyticsHandler) GetCohorts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := models.CohortAnalyticsFilter{
+		University: q.Get("university"),
+		Degree:     q.Get("degree"),
+	}
+	for name, target := range map[string]*int{"fromYear": &filter.FromYear, "toYear": &filter.ToYear} {
+		raw := q.Get(name)
+		if raw == "" {
+			continue
+		}
+		year, err := strconv.Atoi(raw)
+		if err != nil {
+			apperrors.Write(w, apperrors.InvalidParam, "El parámetro '"+name+"' debe ser un año")
+			return
+		}
+		*target = year
+	}
+
+	analytics, err := h.service.GetCohortAnalytics(filter)
+	if err != nil {
+		if !errors.Is(err, services.ErrInvalidCohortFilter) {
+			logger.Errorf(cohortAnalyticsHandlerComponent, "Error al obtener las analíticas de cohortes: %v", err)
+		}
+		apperrors.WriteError(w, err, "Error al obtener las analíticas de cohortes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analytics)
+}
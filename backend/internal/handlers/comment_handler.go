@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+const commentHandlerComponent = "COMMENT_HANDLER"
+
+// CommentHandler maneja los comentarios y respuestas de las publicaciones de la comunidad.
+type CommentHandler struct {
+	service services.ICommentService
+}
+
+// NewCommentHandler crea una nueva instancia de CommentHandler.
+func NewCommentHandler(service services.ICommentService) *CommentHandler {
+	return &CommentHandler{service: service}
+}
+
+// pageParams lee page y pageSize de la query (por defecto 1 y 20, máximo 100).
+func pageParams(r *http.Request) (page, pageSize int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err = strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if err != nil || pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+// pathID lee un ID numérico de la ruta.
+func pathID(r *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)[name], 10, 64)
+	return id, err == nil && id > 0
+}
+
+// ListComments devuelve los comentarios raíz de una publicación, del más reciente al más
+// antiguo, cada uno con sus primeras respuestas. Parámetros de query: page y pageSize.
+func (h *CommentHandler) ListComments(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := pathID(r, "eventID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de publicación inválido")
+		return
+	}
+
+	page, pageSize := pageParams(r)
+	comments, err := h.service.ListComments(eventID, page, pageSize)
+	if err != nil {
+		logger.Warnf(commentHandlerComponent, "No se pudieron obtener los comentarios de la publicación %d: %v", eventID, err)
+		apperrors.WriteError(w, err, "Error al obtener los comentarios")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comments)
+}
+
+// ListReplies devuelve las respuestas de un comentario en orden cronológico.
+// Parámetros de query: page y pageSize.
+func (h *CommentHandler) ListReplies(w http.ResponseWriter, r *http.Request) {
+	commentID, ok := pathID(r, "commentID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de comentario inválido")
+		return
+	}
+
+	page, pageSize := pageParams(r)
+	replies, err := h.service.ListReplies(commentID, page, pageSize)
+	if err != nil {
+		logger.Warnf(commentHandlerComponent, "No se pudieron obtener las respuestas del comentario %d: %v", commentID, err)
+		apperrors.WriteError(w, err, "Error al obtener las respuestas")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(replies)
+}
+
+// CreateComment publica un comentario en una publicación. Con parentId en el cuerpo el
+// comentario es una respuesta.
+func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	eventID, ok := pathID(r, "eventID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de publicación inválido")
+		return
+	}
+
+	var req models.CreateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	comment, err := h.service.CreateComment(eventID, userID, req)
+	if err != nil {
+		logger.Warnf(commentHandlerComponent, "No se pudo crear el comentario de %d en la publicación %d: %v", userID, eventID, err)
+		apperrors.WriteError(w, err, "Error al publicar el comentario")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+// UpdateComment edita el texto de un comentario propio.
+func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	commentID, ok := pathID(r, "commentID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de comentario inválido")
+		return
+	}
+
+	var req models.UpdateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	comment, err := h.service.UpdateComment(commentID, userID, req)
+	if err != nil {
+		logger.Warnf(commentHandlerComponent, "No se pudo editar el comentario %d por %d: %v", commentID, userID, err)
+		apperrors.WriteError(w, err, "Error al editar el comentario")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comment)
+}
+
+// DeleteComment elimina un comentario. Pueden hacerlo su autor, el dueño de la publicación
+// y los administradores. Un comentario con respuestas se conserva como "eliminado".
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	roleID, _ := r.Context().Value(middleware.RoleIDContextKey).(int64)
+	commentID, ok := pathID(r, "commentID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de comentario inválido")
+		return
+	}
+
+	if err := h.service.DeleteComment(commentID, userID, models.UserRole(roleID)); err != nil {
+		logger.Warnf(commentHandlerComponent, "No se pudo eliminar el comentario %d por %d: %v", commentID, userID, err)
+		apperrors.WriteError(w, err, "Error al eliminar el comentario")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
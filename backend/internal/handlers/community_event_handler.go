@@ -11,6 +11,7 @@ import (
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/internal/services"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
 )
 
 // CommunityEventHandler maneja las peticiones HTTP relacionadas con eventos comunitarios.
@@ -139,13 +140,33 @@ func (h *CommunityEventHandler) GetMyCommunityEvents(w http.ResponseWriter, r *h
 		return
 	}
 
-	// Verificar que el rol es 3 (Empresa) o 1 (Estudiante)
-	if roleID != 3 && roleID != 1 && roleID != 2 {
+	// Verificar que el rol es de empresa, estudiante o egresado
+	if role := models.UserRole(roleID); role != models.RoleBusiness && role != models.RoleStudent && role != models.RoleEgresado {
 		logger.Warnf("COMMUNITY_EVENT_HANDLER", "GetMyCommunityEvents: Usuario %d con rol %d intentó acceder a un recurso restringido", userID, roleID)
 		http.Error(w, "Acceso denegado. Este recurso es solo para empresas o estudiantes.", http.StatusForbidden)
 		return
 	}
 
+	// Con ?cursor= (vacío para la primera página) se pagina por cursor y se responde con
+	// {items, nextCursor, hasMore}. page y pageSize quedan para los clientes anteriores.
+	params, paged, err := pagination.FromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if paged {
+		eventsPage, err := h.Service.GetMyCommunityEventsPage(userID, params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(eventsPage); err != nil {
+			logger.Errorf("COMMUNITY_EVENT_HANDLER", "GetMyCommunityEvents: Error codificando la respuesta JSON: %v", err)
+		}
+		return
+	}
+
 	// Parsear parámetros de paginación de la query string
 	page, err := strconv.Atoi(r.URL.Query().Get("page"))
 	if err != nil || page < 1 {
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const companyDashboardHandlerComponent = "COMPANY_DASHBOARD_HANDLER"
+
+// defaultDashboardDays es el periodo del dashboard si no se indica ?days.
+const defaultDashboardDays = 30
+
+// CompanyDashboardHandler maneja las solicitudes del dashboard de contratación de empresas.
+type CompanyDashboardHandler struct {
+	service services.ICompanyDashboardService
+}
+
+// NewCompanyDashboardHandler crea una nueva instancia de CompanyDashboardHandler.
+func NewCompanyDashboardHandler(service services.ICompanyDashboardService) *CompanyDashboardHandler {
+	return &CompanyDashboardHandler{service: service}
+}
+
+// GetMyDashboard devuelve las métricas de contratación de la empresa autenticada:
+// postulaciones por publicación y día, embudo de etapas, tiempo medio de contratación y
+// habilidades más frecuentes entre los postulantes. Parámetro de query: days (1-365, por defecto 30).
+func (h *CompanyDashboardHandler) GetMyDashboard(w http.ResponseWriter, r *http.Request) {
+	companyID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	if roleID, _ := r.Context().Value(middleware.RoleIDContextKey).(int64); models.UserRole(roleID) != models.RoleBusiness {
+		apperrors.Write(w, apperrors.Forbidden, "Solo las empresas pueden consultar el dashboard de contratación")
+		return
+	}
+
+	days := defaultDashboardDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			apperrors.Write(w, apperrors.InvalidParam, "El parámetro 'days' debe ser un número entero")
+			return
+		}
+		days = parsed
+	}
+
+	dashboard, err := h.service.GetDashboard(companyID, days)
+	if err != nil {
+		if !errors.Is(err, services.ErrInvalidDashboardRange) {
+			logger.Errorf(companyDashboardHandlerComponent, "Error al obtener el dashboard de la empresa %d: %v", companyID, err)
+		}
+		apperrors.WriteError(w, err, "Error al obtener el dashboard de contratación")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dashboard)
+}
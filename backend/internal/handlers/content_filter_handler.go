@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const contentFilterHandlerComponent = "CONTENT_FILTER_HANDLER"
+
+// ContentFilterHandler maneja la administración del filtro de contenido del chat.
+type ContentFilterHandler struct {
+	service services.IContentFilterService
+}
+
+// NewContentFilterHandler crea una nueva instancia de ContentFilterHandler.
+func NewContentFilterHandler(service services.IContentFilterService) *ContentFilterHandler {
+	return &ContentFilterHandler{service: service}
+}
+
+// ListRules devuelve las reglas del filtro. Parámetros de query: language, matchType, page
+// y pageSize.
+func (h *ContentFilterHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	page, pageSize := pageParams(r)
+	query := r.URL.Query()
+	rules, err := h.service.ListRules(query.Get("language"), query.Get("matchType"), page, pageSize)
+	if err != nil {
+		logger.Errorf(contentFilterHandlerComponent, "No se pudieron obtener las reglas del filtro: %v", err)
+		apperrors.WriteError(w, err, "Error al obtener las reglas del filtro")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// CreateRule añade una regla al filtro.
+func (h *ContentFilterHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	var req models.ContentFilterRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	rule, err := h.service.CreateRule(adminID, req)
+	if err != nil {
+		logger.Warnf(contentFilterHandlerComponent, "No se pudo crear la regla del filtro: %v", err)
+		apperrors.WriteError(w, err, "Error al crear la regla del filtro")
+		return
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionFilterRuleCreated,
+		TargetType: models.AuditTargetFilterRule,
+		TargetId:   strconv.FormatInt(rule.Id, 10),
+		ActorId:    &adminID,
+	}, map[string]interface{}{
+		"language":  rule.Language,
+		"matchType": rule.MatchType,
+		"severity":  rule.Severity,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+// UpdateRule reemplaza una regla del filtro.
+func (h *ContentFilterHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	ruleID, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de regla inválido")
+		return
+	}
+
+	var req models.ContentFilterRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	rule, err := h.service.UpdateRule(ruleID, req)
+	if err != nil {
+		logger.Warnf(contentFilterHandlerComponent, "No se pudo modificar la regla %d del filtro: %v", ruleID, err)
+		apperrors.WriteError(w, err, "Error al modificar la regla del filtro")
+		return
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionFilterRuleUpdated,
+		TargetType: models.AuditTargetFilterRule,
+		TargetId:   strconv.FormatInt(ruleID, 10),
+		ActorId:    &adminID,
+	}, map[string]interface{}{
+		"severity": rule.Severity,
+		"isActive": rule.IsActive,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// DeleteRule elimina una regla del filtro.
+func (h *ContentFilterHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	ruleID, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de regla inválido")
+		return
+	}
+
+	if err := h.service.DeleteRule(ruleID); err != nil {
+		logger.Warnf(contentFilterHandlerComponent, "No se pudo eliminar la regla %d del filtro: %v", ruleID, err)
+		apperrors.WriteError(w, err, "Error al eliminar la regla del filtro")
+		return
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionFilterRuleDeleted,
+		TargetType: models.AuditTargetFilterRule,
+		TargetId:   strconv.FormatInt(ruleID, 10),
+		ActorId:    &adminID,
+	}, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImportWords importa una lista de palabras de un idioma como reglas PALABRA.
+func (h *ContentFilterHandler) ImportWords(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	var req models.ContentFilterImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	result, err := h.service.ImportWords(adminID, req)
+	if err != nil {
+		logger.Warnf(contentFilterHandlerComponent, "No se pudo importar la lista de palabras: %v", err)
+		apperrors.WriteError(w, err, "Error al importar la lista de palabras")
+		return
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionFilterRulesImported,
+		TargetType: models.AuditTargetFilterRule,
+		ActorId:    &adminID,
+	}, map[string]interface{}{
+		"language": req.Language,
+		"imported": result.Imported,
+		"skipped":  result.Skipped,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetPolicy devuelve la política vigente del filtro.
+func (h *ContentFilterHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.service.GetPolicy()
+	if err != nil {
+		logger.Errorf(contentFilterHandlerComponent, "No se pudo obtener la política del filtro: %v", err)
+		apperrors.WriteError(w, err, "Error al obtener la política del filtro")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// UpdatePolicy reemplaza la política del filtro.
+func (h *ContentFilterHandler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	var req models.ContentFilterPolicy
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	policy, err := h.service.UpdatePolicy(adminID, req)
+	if err != nil {
+		logger.Warnf(contentFilterHandlerComponent, "No se pudo actualizar la política del filtro: %v", err)
+		apperrors.WriteError(w, err, "Error al actualizar la política del filtro")
+		return
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionFilterPolicyUpdated,
+		TargetType: models.AuditTargetFilterPolicy,
+		ActorId:    &adminID,
+	}, map[string]interface{}{
+		"enabled":      policy.Enabled,
+		"lowAction":    policy.LowAction,
+		"mediumAction": policy.MediumAction,
+		"highAction":   policy.HighAction,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// ListHits devuelve el registro de mensajes marcados, ocultados o bloqueados por el filtro.
+// Parámetros de query: action, senderId, page y pageSize.
+func (h *ContentFilterHandler) ListHits(w http.ResponseWriter, r *http.Request) {
+	page, pageSize := pageParams(r)
+	var senderID int64
+	if raw := r.URL.Query().Get("senderId"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			apperrors.Write(w, apperrors.InvalidParam, "senderId inválido")
+			return
+		}
+		senderID = id
+	}
+
+	hits, err := h.service.ListHits(r.URL.Query().Get("action"), senderID, page, pageSize)
+	if err != nil {
+		logger.Warnf(contentFilterHandlerComponent, "No se pudo obtener el registro del filtro: %v", err)
+		apperrors.WriteError(w, err, "Error al obtener el registro del filtro")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hits)
+}
+
+// Test evalúa un texto con las reglas guardadas y devuelve la acción que se aplicaría.
+func (h *ContentFilterHandler) Test(w http.ResponseWriter, r *http.Request) {
+	var req models.ContentFilterTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	verdict, err := h.service.Test(r.Context(), req.Text)
+	if err != nil {
+		logger.Warnf(contentFilterHandlerComponent, "No se pudo probar el filtro: %v", err)
+		apperrors.WriteError(w, err, "Error al probar el filtro")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(verdict)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const cvExportHandlerComponent = "CV_EXPORT_HANDLER"
+
+// CVExportHandler maneja la descarga del CV del usuario en PDF o JSON.
+type CVExportHandler struct {
+	service services.ICVExportService
+}
+
+// NewCVExportHandler crea una nueva instancia de CVExportHandler.
+func NewCVExportHandler(service services.ICVExportService) *CVExportHandler {
+	return &CVExportHandler{service: service}
+}
+
+// ExportMyCV genera el CV del usuario autenticado.
+// Query param "format": "pdf" (por defecto) o "json".
+func (h *CVExportHandler) ExportMyCV(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		http.Error(w, "No se pudo obtener el ID del usuario desde el token", http.StatusUnauthorized)
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = "pdf"
+	}
+	if format != "pdf" && format != "json" {
+		http.Error(w, "Formato no soportado. Use 'pdf' o 'json'", http.StatusBadRequest)
+		return
+	}
+
+	cv, err := h.service.BuildCVExport(userID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		logger.Errorf(cvExportHandlerComponent, "Error armando el CV del usuario %d: %v", userID, err)
+		http.Error(w, "Error al generar el CV", http.StatusInternalServerError)
+		return
+	}
+
+	fileName := "cv"
+	if cv.Profile.UserName != "" {
+		fileName = "cv-" + cv.Profile.UserName
+	}
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName+".json"))
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(cv)
+		return
+	}
+
+	pdf, err := h.service.RenderCVPDF(cv)
+	if err != nil {
+		logger.Errorf(cvExportHandlerComponent, "Error renderizando el PDF del CV del usuario %d: %v", userID, err)
+		http.Error(w, "Error al generar el CV", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Successf(cvExportHandlerComponent, "CV exportado en PDF para el usuario %d (%d bytes)", userID, len(pdf))
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName+".pdf"))
+	w.WriteHeader(http.StatusOK)
+	w.Write(pdf)
+}
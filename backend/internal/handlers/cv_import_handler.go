@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const cvImportHandlerComponent = "CV_IMPORT_HANDLER"
+
+// maxCVImportSize limita el tamaño del documento aceptado por la importación.
+const maxCVImportSize = 1 << 20 // 1 MB
+
+// CVImportHandler maneja la importación masiva de items de CV.
+type CVImportHandler struct {
+	service services.ICVImportService
+}
+
+// NewCVImportHandler crea una nueva instancia de CVImportHandler.
+func NewCVImportHandler(service services.ICVImportService) *CVImportHandler {
+	return &CVImportHandler{service: service}
+}
+
+// ImportMyCV importa items al CV del usuario autenticado.
+// Query params: "format" ("json" por defecto o "linkedin") y "dryRun" ("true" para solo previsualizar).
+func (h *CVImportHandler) ImportMyCV(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		http.Error(w, "No se pudo obtener el ID del usuario desde el token", http.StatusUnauthorized)
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = models.CVImportFormatJSON
+	}
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCVImportSize))
+	if err != nil {
+		http.Error(w, "El documento excede el tamaño máximo permitido o no se pudo leer", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	result, err := h.service.ImportCV(userID, format, payload, dryRun)
+	if err != nil {
+		if errors.Is(err, services.ErrUnsupportedCVFormat) || errors.Is(err, services.ErrInvalidCVPayload) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logger.Errorf(cvImportHandlerComponent, "Error importando el CV del usuario %d: %v", userID, err)
+		http.Error(w, "Error al importar el CV", http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const engagementHandlerComponent = "ENGAGEMENT_HANDLER"
+
+// EngagementHandler maneja los "me gusta" y guardados de las publicaciones de la comunidad.
+type EngagementHandler struct {
+	service services.IEngagementService
+}
+
+// NewEngagementHandler crea una nueva instancia de EngagementHandler.
+func NewEngagementHandler(service services.IEngagementService) *EngagementHandler {
+	return &EngagementHandler{service: service}
+}
+
+// Like marca la publicación con "me gusta" (PUT) o lo quita (DELETE). Ambas operaciones son
+// idempotentes y responden con los contadores actualizados.
+func (h *EngagementHandler) Like(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	roleID, _ := r.Context().Value(middleware.RoleIDContextKey).(int64)
+	eventID, ok := pathID(r, "eventID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de publicación inválido")
+		return
+	}
+
+	engagement, err := h.service.SetLike(userID, models.UserRole(roleID), eventID, r.Method != http.MethodDelete)
+	if err != nil {
+		logger.Warnf(engagementHandlerComponent, "No se pudo actualizar el me gusta de %d en la publicación %d: %v", userID, eventID, err)
+		apperrors.WriteError(w, err, "Error al actualizar el me gusta")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(engagement)
+}
+
+// Bookmark guarda la publicación (PUT) o la quita de los guardados (DELETE). Ambas
+// operaciones son idempotentes y responden con los contadores actualizados.
+func (h *EngagementHandler) Bookmark(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	eventID, ok := pathID(r, "eventID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de publicación inválido")
+		return
+	}
+
+	engagement, err := h.service.SetBookmark(userID, eventID, r.Method != http.MethodDelete)
+	if err != nil {
+		logger.Warnf(engagementHandlerComponent, "No se pudo actualizar el guardado de %d en la publicación %d: %v", userID, eventID, err)
+		apperrors.WriteError(w, err, "Error al actualizar el guardado")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(engagement)
+}
+
+// ListMySavedItems devuelve las publicaciones guardadas por el usuario autenticado, de la
+// guardada más recientemente a la más antigua. Parámetros de query: page y pageSize.
+func (h *EngagementHandler) ListMySavedItems(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	page, pageSize := pageParams(r)
+	items, err := h.service.ListSavedItems(userID, page, pageSize)
+	if err != nil {
+		logger.Errorf(engagementHandlerComponent, "Error al obtener los guardados del usuario %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al obtener las publicaciones guardadas")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
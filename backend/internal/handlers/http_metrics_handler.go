@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/httpmetrics"
+)
+
+// HTTPMetricsHandler expone al administrador las métricas de peticiones de la API que acumula
+// el middleware de log (número de peticiones, estados, bytes e histograma de latencias por
+// ruta). Las métricas son de esta instancia y se pierden al reiniciarla.
+type HTTPMetricsHandler struct {
+	metrics *httpmetrics.Registry
+}
+
+// NewHTTPMetricsHandler crea una nueva instancia de HTTPMetricsHandler.
+func NewHTTPMetricsHandler(metrics *httpmetrics.Registry) *HTTPMetricsHandler {
+	return &HTTPMetricsHandler{metrics: metrics}
+}
+
+// GetMetrics devuelve las métricas por método y ruta, de la más usada a la menos usada.
+func (h *HTTPMetricsHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, h.metrics.Snapshot())
+}
+
+// GetCacheMetrics devuelve los aciertos, fallos e invalidaciones de la caché de consultas de
+// esta instancia, por grupo de claves.
+func (h *HTTPMetricsHandler) GetCacheMetrics(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, queries.CacheStats())
+}
+
+// ResetMetrics descarta las métricas acumuladas, por ejemplo antes de una prueba de carga.
+func (h *HTTPMetricsHandler) ResetMetrics(w http.ResponseWriter, r *http.Request) {
+	h.metrics.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -3,8 +3,6 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -15,6 +13,7 @@ import (
 	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
 	"github.com/davidM20/micro-service-backend-go.git/internal/services"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/storage"
 	"github.com/gorilla/mux"
 )
 
@@ -29,13 +28,14 @@ import (
 // ImageHandler maneja las solicitudes de subida y visualización de imágenes.
 type ImageHandler struct {
 	imageService *services.ImageUploadService
-	cfg          *config.Config // Añadido para acceder a la configuración (ej. JWT secret, GCS bucket)
+	cfg          *config.Config // Añadido para acceder a la configuración (ej. JWT secret)
 	db           *sql.DB
+	store        storage.Storage
 }
 
 // NewImageHandler crea una nueva instancia de ImageHandler.
-func NewImageHandler(imageService *services.ImageUploadService, cfg *config.Config, db *sql.DB) *ImageHandler {
-	return &ImageHandler{imageService: imageService, cfg: cfg, db: db}
+func NewImageHandler(imageService *services.ImageUploadService, cfg *config.Config, db *sql.DB, store storage.Storage) *ImageHandler {
+	return &ImageHandler{imageService: imageService, cfg: cfg, db: db, store: store}
 }
 
 // UploadImage es el método que maneja la petición POST para subir una imagen.
@@ -74,6 +74,9 @@ func (h *ImageHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
 	uploadDetails, err := h.imageService.ProcessAndUploadImage(r.Context(), userID, file, handler)
 	if err != nil {
 		logger.Errorf("UploadImage.ServiceCall", "Error procesando la imagen para el usuario %d: %v", userID, err)
+		if writeUploadCheckError(w, err) {
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		// Determinar el código de estado basado en el tipo de error podría ser más granular aquí
 		w.WriteHeader(http.StatusInternalServerError) // Podría ser BadRequest dependiendo del error del servicio
@@ -116,6 +119,9 @@ func (h *ImageHandler) UpdateProfilePicture(w http.ResponseWriter, r *http.Reque
 	uploadDetails, err := h.imageService.ProcessAndUploadImage(r.Context(), userID, file, handler)
 	if err != nil {
 		logger.Errorf("UpdateProfilePicture.ServiceCallUpload", "Error procesando la imagen para el usuario %d: %v", userID, err)
+		if writeUploadCheckError(w, err) {
+			return
+		}
 		http.Error(w, `{"error": "Error al procesar la imagen: `+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -193,54 +199,8 @@ func (h *ImageHandler) ViewUserProfilePicture(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// 4. Servir la imagen
-	if h.cfg.GCSBucketName == "" {
-		logger.Error("ViewUserProfilePicture.Config", "El nombre del bucket GCS no está configurado.")
-		http.Error(w, `{"error": "Error de configuración del servidor."}`, http.StatusInternalServerError)
-		return
-	}
-
-	gcsURL := fmt.Sprintf("https://storage.googleapis.com/%s/%s", h.cfg.GCSBucketName, filename)
-
-	client := &http.Client{}
-	req, err := http.NewRequestWithContext(r.Context(), "GET", gcsURL, nil)
-	if err != nil {
-		logger.Errorf("ViewUserProfilePicture.GCSRequestError", "Error creando request para GCS %s: %v", gcsURL, err)
-		http.Error(w, `{"error": "Error al solicitar la imagen."}`, http.StatusInternalServerError)
-		return
-	}
-
-	gcsResponse, err := client.Do(req)
-	if err != nil {
-		logger.Errorf("ViewUserProfilePicture.GCSDownloadError", "Error descargando imagen de GCS %s: %v", gcsURL, err)
-		http.Error(w, `{"error": "No se pudo obtener la imagen del almacenamiento."}`, http.StatusBadGateway)
-		return
-	}
-	defer gcsResponse.Body.Close()
-
-	if gcsResponse.StatusCode != http.StatusOK {
-		logger.Warnf("ViewUserProfilePicture.GCSStatusError", "GCS devolvió estado no OK (%d) para %s", gcsResponse.StatusCode, gcsURL)
-		if gcsResponse.StatusCode == http.StatusNotFound {
-			http.Error(w, `{"error": "Imagen no encontrada en el almacenamiento."}`, http.StatusNotFound)
-		} else {
-			http.Error(w, `{"error": "Error al obtener la imagen del almacenamiento."}`, http.StatusBadGateway)
-		}
-		return
-	}
-
-	contentType := gcsResponse.Header.Get("Content-Type")
-	if contentType == "" {
-		contentType = "application/octet-stream"
-	}
-	w.Header().Set("Content-Type", contentType)
-	if gcsResponse.ContentLength > 0 {
-		w.Header().Set("Content-Length", fmt.Sprintf("%d", gcsResponse.ContentLength))
-	}
-
-	_, err = io.Copy(w, gcsResponse.Body)
-	if err != nil {
-		logger.Errorf("ViewUserProfilePicture.ResponseWriteError", "Error escribiendo imagen al cliente: %v", err)
-	}
+	// 4. Servir la imagen desde el almacenamiento (con soporte de Range)
+	serveObjectRange(w, r, h.store, filename, "")
 }
 
 // ViewImage maneja la solicitud GET para ver una imagen, autenticando con token en query param.
@@ -277,84 +237,8 @@ func (h *ImageHandler) ViewImage(w http.ResponseWriter, r *http.Request) {
 	// Log opcional del usuario autenticado
 	logger.Infof("ViewImage.Auth", "Acceso autorizado para UserID: %s a imagen: %s", claims.Subject, filename)
 
-	// Construir la URL de GCS
-	if h.cfg.GCSBucketName == "" {
-		logger.Error("ViewImage.Config", "El nombre del bucket GCS no está configurado en el servidor.")
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Error de configuración del servidor."})
-		return
-	}
-
-	gcsURL := fmt.Sprintf("https://storage.googleapis.com/%s/%s", h.cfg.GCSBucketName, filename)
-
-	// Descargar la imagen desde GCS
-	client := &http.Client{}
-	req, err := http.NewRequestWithContext(r.Context(), "GET", gcsURL, nil)
-	if err != nil {
-		logger.Errorf("ViewImage.GCSRequestError", "Error creando request para GCS %s: %v", gcsURL, err)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Error al solicitar la imagen."})
-		return
-	}
-
-	gcsResponse, err := client.Do(req)
-	if err != nil {
-		logger.Errorf("ViewImage.GCSDownloadError", "Error descargando imagen de GCS %s: %v", gcsURL, err)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadGateway) // 502 si GCS falla
-		json.NewEncoder(w).Encode(map[string]string{"error": "No se pudo obtener la imagen del almacenamiento."})
-		return
-	}
-	defer gcsResponse.Body.Close()
-
-	if gcsResponse.StatusCode != http.StatusOK {
-		logger.Warnf("ViewImage.GCSStatusError", "GCS devolvió estado no OK (%d) para %s", gcsResponse.StatusCode, gcsURL)
-		// Devolver un error genérico o mapear el código de estado de GCS si es necesario
-		w.Header().Set("Content-Type", "application/json")
-		if gcsResponse.StatusCode == http.StatusNotFound {
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Imagen no encontrada."})
-		} else {
-			w.WriteHeader(http.StatusBadGateway)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Error al obtener la imagen del almacenamiento."})
-		}
-		return
-	}
-
-	// Obtener Content-Type y Content-Length de la respuesta de GCS
-	contentType := gcsResponse.Header.Get("Content-Type")
-	if contentType == "" {
-		logger.Warnf("ViewImage.GCSContentTypeMissing", "GCS no devolvió Content-Type para %s. Intentando deducir.", gcsURL)
-		// Intento básico de deducir por extensión, aunque es menos fiable
-		if strings.HasSuffix(strings.ToLower(filename), ".webp") {
-			contentType = "image/webp"
-		} else if strings.HasSuffix(strings.ToLower(filename), ".jpeg") || strings.HasSuffix(strings.ToLower(filename), ".jpg") {
-			contentType = "image/jpeg"
-		} else if strings.HasSuffix(strings.ToLower(filename), ".png") {
-			contentType = "image/png"
-		} else {
-			contentType = "application/octet-stream" // Genérico si no se puede deducir
-		}
-	}
-
-	// Configurar headers de la respuesta al cliente
-	w.Header().Set("Content-Type", contentType)
-	if gcsResponse.ContentLength > 0 {
-		w.Header().Set("Content-Length", fmt.Sprintf("%d", gcsResponse.ContentLength))
-	}
-
-	// Escribir los bytes de la imagen en la respuesta
-	// http.ServeContent podría ser una opción más robusta aquí si tuviéramos un io.ReadSeeker y un modtime.
-	// Por ahora, copiamos directamente el stream.
-	_, err = io.Copy(w, gcsResponse.Body)
-	if err != nil {
-		logger.Errorf("ViewImage.ResponseWriteError", "Error escribiendo imagen al cliente: %v", err)
-		// Es posible que los headers ya se hayan enviado, por lo que es difícil enviar un error JSON aquí.
-		// El cliente podría recibir una respuesta truncada.
-		return
-	}
+	// Servir la imagen desde el almacenamiento (con soporte de Range)
+	serveObjectRange(w, r, h.store, filename, "")
 }
 
 // GetMultimediaInfo maneja la solicitud GET para obtener los metadatos de un archivo multimedia
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const impersonationHandlerComponent = "IMPERSONATION_HANDLER"
+
+// ImpersonationHandler permite al soporte obtener un token de corta duración para actuar como
+// un usuario y terminarlo antes de que expire.
+type ImpersonationHandler struct {
+	service services.IImpersonationService
+}
+
+// NewImpersonationHandler crea una nueva instancia de ImpersonationHandler.
+func NewImpersonationHandler(service services.IImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{service: service}
+}
+
+// Start inicia la suplantación del usuario de la ruta. Cuerpo: {"reason": "..."}; el motivo
+// es obligatorio y queda en el registro de auditoría.
+func (h *ImpersonationHandler) Start(w http.ResponseWriter, r *http.Request) {
+	adminID, userID, ok := adminAndTarget(w, r)
+	if !ok {
+		return
+	}
+	var req models.StartImpersonationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	token, err := h.service.Start(adminID, userID, req.Reason)
+	if err != nil {
+		logger.Warnf(impersonationHandlerComponent, "No se pudo suplantar al usuario %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al iniciar la suplantación")
+		return
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionImpersonationStarted,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(userID, 10),
+		ActorId:    &adminID,
+	}, map[string]interface{}{
+		"impersonationId": token.ImpersonationId,
+		"reason":          req.Reason,
+		"expiresAt":       token.ExpiresAt.Format(time.RFC3339),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(token)
+}
+
+// End termina la suplantación de la ruta antes de que expire.
+func (h *ImpersonationHandler) End(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	impersonationID, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de suplantación inválido")
+		return
+	}
+
+	imp, err := h.service.End(impersonationID)
+	if err != nil {
+		logger.Warnf(impersonationHandlerComponent, "No se pudo terminar la suplantación %d: %v", impersonationID, err)
+		apperrors.WriteError(w, err, "Error al terminar la suplantación")
+		return
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionImpersonationEnded,
+		TargetType: models.AuditTargetImpersonation,
+		TargetId:   strconv.FormatInt(impersonationID, 10),
+		ActorId:    &adminID,
+	}, map[string]interface{}{"userId": imp.UserId, "impersonatorId": imp.AdminId})
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -7,12 +7,15 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
 	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/internal/services"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
 	"github.com/go-sql-driver/mysql"
 	"github.com/gorilla/mux"
 )
@@ -23,13 +26,16 @@ const jobApplicationHandlerComponent = "JOB_APPLICATION_HANDLER"
 type JobApplicationHandler struct {
 	service services.IJobApplication
 	DB      *sql.DB
+	// groupWindow es NOTIFICATION_GROUP_WINDOW: plazo para agrupar los avisos de postulaciones.
+	groupWindow time.Duration
 }
 
 // NewJobApplicationHandler crea una nueva instancia de JobApplicationHandler.
-func NewJobApplicationHandler(service services.IJobApplication, db *sql.DB) *JobApplicationHandler {
+func NewJobApplicationHandler(service services.IJobApplication, db *sql.DB, cfg *config.Config) *JobApplicationHandler {
 	return &JobApplicationHandler{
-		service: service,
-		DB:      db,
+		service:     service,
+		DB:          db,
+		groupWindow: cfg.NotificationGroupWindow,
 	}
 }
 
@@ -111,15 +117,15 @@ func (h *JobApplicationHandler) createApplicationNotification(eventID, applicant
 		applicantName = "un postulante"
 	}
 
-	// 3. Crear el objeto de notificación/evento
+	// 3. Crear el objeto de notificación/evento. Las postulaciones a la misma oferta se
+	// agrupan en una sola notificación ("3 nuevos postulantes para ...").
 	companyUserID := event.CreatedByUserId
 	notification := models.Event{
 		EventType:      "NEW_JOB_APPLICATION",
-		EventTitle:     fmt.Sprintf("Nuevo postulante para '%s'", event.Title),
-		Description:    fmt.Sprintf("%s se ha postulado a tu oferta.", applicantName),
 		UserId:         companyUserID,                                  // Notificación PARA la empresa
 		OtherUserId:    sql.NullInt64{Int64: applicantID, Valid: true}, // Notificación SOBRE el postulante
 		ActionRequired: true,                                           // La empresa debe revisar la postulación
+		GroupKey:       sql.NullString{String: models.EventGroupKey("NEW_JOB_APPLICATION", eventID), Valid: true},
 	}
 
 	// Adjuntar metadata útil como el ID del evento
@@ -132,7 +138,14 @@ func (h *JobApplicationHandler) createApplicationNotification(eventID, applicant
 	}
 
 	// 4. Guardar la notificación en la base de datos
-	if err := queries.CreateEvent(&notification); err != nil {
+	render := func(group models.EventGroup) (string, string) {
+		if group.Count > 1 {
+			return fmt.Sprintf("%d nuevos postulantes para '%s'", group.Count, event.Title),
+				fmt.Sprintf("%s y otros %d se han postulado a tu oferta.", applicantName, group.Count-1)
+		}
+		return fmt.Sprintf("Nuevo postulante para '%s'", event.Title), fmt.Sprintf("%s se ha postulado a tu oferta.", applicantName)
+	}
+	if err := queries.CreateGroupedEventWithOutbox(&notification, h.groupWindow, render); err != nil {
 		logger.Errorf(jobApplicationHandlerComponent, "No se pudo crear la notificación para la empresa %d sobre el evento %d: %v", companyUserID, eventID, err)
 	}
 
@@ -151,6 +164,25 @@ func (h *JobApplicationHandler) ListApplicants(w http.ResponseWriter, r *http.Re
 	// TODO: Añadir validación para asegurar que quien consulta es el creador de la oferta o un admin.
 	// Por ahora, cualquier usuario autenticado puede ver los postulantes.
 
+	// Con ?cursor= (vacío para la primera página) se pagina por cursor y se responde con
+	// {items, nextCursor, hasMore}. Sin cursor se devuelve la lista completa, como antes.
+	params, paged, err := pagination.FromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if paged {
+		page, err := h.service.ListApplicantsPage(eventID, params)
+		if err != nil {
+			logger.Errorf(jobApplicationHandlerComponent, "Error en el servicio al listar aplicantes: %v", err)
+			http.Error(w, "Error al obtener la lista de postulantes", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+		return
+	}
+
 	applicants, err := h.service.ListApplicants(eventID)
 	if err != nil {
 		logger.Errorf(jobApplicationHandlerComponent, "Error en el servicio al listar aplicantes: %v", err)
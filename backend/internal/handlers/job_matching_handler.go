@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const jobMatchingHandlerComponent = "JOB_MATCHING_HANDLER"
+
+// JobMatchingHandler maneja los requisitos de las ofertas y las recomendaciones de candidatos
+// y ofertas.
+type JobMatchingHandler struct {
+	service services.IJobMatchingService
+}
+
+// NewJobMatchingHandler crea una nueva instancia de JobMatchingHandler.
+func NewJobMatchingHandler(service services.IJobMatchingService) *JobMatchingHandler {
+	return &JobMatchingHandler{service: service}
+}
+
+// GetRequirements devuelve los requisitos de una oferta.
+func (h *JobMatchingHandler) GetRequirements(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := pathID(r, "eventID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de publicación inválido")
+		return
+	}
+
+	requirements, err := h.service.GetRequirements(eventID)
+	if err != nil {
+		if !errors.Is(err, services.ErrJobRequirementsNotFound) {
+			logger.Errorf(jobMatchingHandlerComponent, "Error al obtener los requisitos de la oferta %d: %v", eventID, err)
+		}
+		apperrors.WriteError(w, err, "Error al obtener los requisitos")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requirements)
+}
+
+// SetRequirements reemplaza los requisitos de una oferta del usuario autenticado.
+func (h *JobMatchingHandler) SetRequirements(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	eventID, ok := pathID(r, "eventID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de publicación inválido")
+		return
+	}
+
+	var req models.SetJobRequirementsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	requirements, err := h.service.SetRequirements(eventID, userID, req)
+	if err != nil {
+		logger.Warnf(jobMatchingHandlerComponent, "No se pudieron guardar los requisitos de la oferta %d por %d: %v", eventID, userID, err)
+		apperrors.WriteError(w, err, "Error al guardar los requisitos")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requirements)
+}
+
+// DeleteRequirements elimina los requisitos de una oferta del usuario autenticado.
+func (h *JobMatchingHandler) DeleteRequirements(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	eventID, ok := pathID(r, "eventID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de publicación inválido")
+		return
+	}
+
+	if err := h.service.DeleteRequirements(eventID, userID); err != nil {
+		logger.Warnf(jobMatchingHandlerComponent, "No se pudieron eliminar los requisitos de la oferta %d por %d: %v", eventID, userID, err)
+		apperrors.WriteError(w, err, "Error al eliminar los requisitos")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RecommendCandidates devuelve los candidatos recomendados para una oferta, con el desglose de
+// su puntuación. Parámetros de query: page, pageSize y verifiedOnly (solo estudiantes y
+// egresados verificados).
+func (h *JobMatchingHandler) RecommendCandidates(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	roleID, _ := r.Context().Value(middleware.RoleIDContextKey).(int64)
+	eventID, ok := pathID(r, "eventID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de publicación inválido")
+		return
+	}
+
+	page, pageSize := pageParams(r)
+	verifiedOnly, _ := strconv.ParseBool(r.URL.Query().Get("verifiedOnly"))
+	candidates, err := h.service.RecommendCandidates(eventID, userID, models.UserRole(roleID), verifiedOnly, page, pageSize)
+	if err != nil {
+		logger.Warnf(jobMatchingHandlerComponent, "No se pudieron recomendar candidatos para la oferta %d a %d: %v", eventID, userID, err)
+		apperrors.WriteError(w, err, "Error al obtener los candidatos recomendados")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(candidates)
+}
+
+// RecommendJobs devuelve las ofertas recomendadas para el estudiante autenticado, con el
+// desglose de su puntuación. Parámetros de query: page y pageSize.
+func (h *JobMatchingHandler) RecommendJobs(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	roleID, _ := r.Context().Value(middleware.RoleIDContextKey).(int64)
+
+	page, pageSize := pageParams(r)
+	jobs, err := h.service.RecommendJobs(userID, models.UserRole(roleID), page, pageSize)
+	if err != nil {
+		logger.Warnf(jobMatchingHandlerComponent, "No se pudieron recomendar ofertas a %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al obtener las ofertas recomendadas")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jobs)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+const messageRetentionHandlerComponent = "MESSAGE_RETENTION_HANDLER"
+
+// MessageRetentionHandler expone al administrador el control de la retención de mensajes.
+type MessageRetentionHandler struct {
+	service services.IMessageRetentionService
+}
+
+// NewMessageRetentionHandler crea una nueva instancia de MessageRetentionHandler.
+func NewMessageRetentionHandler(service services.IMessageRetentionService) *MessageRetentionHandler {
+	return &MessageRetentionHandler{service: service}
+}
+
+// GetStatus devuelve la configuración de retención y la última ejecución.
+func (h *MessageRetentionHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.service.GetStatus()
+	if err != nil {
+		logger.Errorf(messageRetentionHandlerComponent, "Error obteniendo estado de retención: %v", err)
+		apperrors.Write(w, apperrors.Internal, "Error al obtener el estado de la retención")
+		return
+	}
+	writeRetentionJSON(w, http.StatusOK, status)
+}
+
+// ListRuns devuelve las ejecuciones recientes de la retención.
+func (h *MessageRetentionHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	runs, err := h.service.ListRuns()
+	if err != nil {
+		logger.Errorf(messageRetentionHandlerComponent, "Error listando ejecuciones de retención: %v", err)
+		apperrors.Write(w, apperrors.Internal, "Error al obtener las ejecuciones de retención")
+		return
+	}
+	writeRetentionJSON(w, http.StatusOK, runs)
+}
+
+// TriggerRun lanza una ejecución manual. Responde 202 con el registro de la ejecución.
+func (h *MessageRetentionHandler) TriggerRun(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	run, err := h.service.TriggerRun(adminID)
+	if err != nil {
+		if !errors.Is(err, services.ErrRetentionRunActive) && !errors.Is(err, services.ErrRetentionDisabled) {
+			logger.Errorf(messageRetentionHandlerComponent, "Error iniciando retención manual: %v", err)
+		}
+		apperrors.WriteError(w, err, "Error al iniciar la retención")
+		return
+	}
+	writeRetentionJSON(w, http.StatusAccepted, run)
+}
+
+// ListOptOuts lista los chats excluidos del archivado.
+func (h *MessageRetentionHandler) ListOptOuts(w http.ResponseWriter, r *http.Request) {
+	optOuts, err := h.service.ListChatOptOuts()
+	if err != nil {
+		logger.Errorf(messageRetentionHandlerComponent, "Error listando opt-outs de retención: %v", err)
+		apperrors.Write(w, apperrors.Internal, "Error al obtener los chats excluidos")
+		return
+	}
+	writeRetentionJSON(w, http.StatusOK, optOuts)
+}
+
+// SetOptOut excluye el chat {chatId} del archivado.
+func (h *MessageRetentionHandler) SetOptOut(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	chatID := mux.Vars(r)["chatId"]
+
+	if err := h.service.SetChatOptOut(chatID, adminID); err != nil {
+		if !errors.Is(err, services.ErrChatNotFound) {
+			logger.Errorf(messageRetentionHandlerComponent, "Error excluyendo el chat %s de la retención: %v", chatID, err)
+		}
+		apperrors.WriteError(w, err, "Error al excluir el chat de la retención")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveOptOut vuelve a incluir el chat {chatId} en el archivado.
+func (h *MessageRetentionHandler) RemoveOptOut(w http.ResponseWriter, r *http.Request) {
+	chatID := mux.Vars(r)["chatId"]
+
+	if err := h.service.RemoveChatOptOut(chatID); err != nil {
+		if !errors.Is(err, services.ErrRetentionOptOutMiss) {
+			logger.Errorf(messageRetentionHandlerComponent, "Error eliminando opt-out del chat %s: %v", chatID, err)
+		}
+		apperrors.WriteError(w, err, "Error al incluir el chat en la retención")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeRetentionJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
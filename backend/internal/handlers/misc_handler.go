@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 	"github.com/gorilla/mux"
@@ -23,32 +24,12 @@ func NewMiscHandler(db *sql.DB) *MiscHandler {
 
 // GetNationalities devuelve la lista de nacionalidades
 func (h *MiscHandler) GetNationalities(w http.ResponseWriter, r *http.Request) {
-	nationalities := models.GetDefaultNationalities() // Obtener desde los datos por defecto
-	// Opcionalmente, podrías leerlos de la tabla Nationality si prefieres gestionarlos en BD
-	/*
-	   rows, err := h.DB.Query("SELECT Id, CountryName, IsoCode, DocIdFormat FROM Nationality ORDER BY CountryName")
-	   if err != nil {
-	       logger.Errorf("MISC", "Error querying nationalities: %v", err)
-	       http.Error(w, "Failed to retrieve data", http.StatusInternalServerError)
-	       return
-	   }
-	   defer rows.Close()
-
-	   nationalities := []models.Nationality{}
-	   for rows.Next() {
-	       var nat models.Nationality
-	       if err := rows.Scan(&nat.Id, &nat.CountryName, &nat.IsoCode, &nat.DocIdFormat); err != nil {
-	           logger.Errorf("MISC", "Error scanning nationality row: %v", err)
-	           continue // O manejar el error de otra forma
-	       }
-	       nationalities = append(nationalities, nat)
-	   }
-	   if err = rows.Err(); err != nil {
-	        logger.Errorf("MISC", "Error iterating nationality rows: %v", err)
-	        http.Error(w, "Failed to retrieve data", http.StatusInternalServerError)
-	        return
-	   }
-	*/
+	nationalities, err := queries.GetNationalities()
+	if err != nil {
+		logger.Errorf("MISC", "Error querying nationalities: %v", err)
+		http.Error(w, "Failed to retrieve data", http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -57,29 +38,12 @@ func (h *MiscHandler) GetNationalities(w http.ResponseWriter, r *http.Request) {
 
 // GetUniversities devuelve la lista de universidades
 func (h *MiscHandler) GetUniversities(w http.ResponseWriter, r *http.Request) {
-	// Leer desde la base de datos
-	rows, err := h.DB.Query("SELECT Id, Name, Campus FROM University ORDER BY Name")
+	universities, err := queries.GetUniversities()
 	if err != nil {
 		logger.Errorf("MISC", "Error querying universities: %v", err)
 		http.Error(w, "Failed to retrieve data", http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
-
-	universities := []models.University{}
-	for rows.Next() {
-		var uni models.University
-		if err := rows.Scan(&uni.Id, &uni.Name, &uni.Campus); err != nil {
-			logger.Errorf("MISC", "Error scanning university row: %v", err)
-			continue
-		}
-		universities = append(universities, uni)
-	}
-	if err = rows.Err(); err != nil {
-		logger.Errorf("MISC", "Error iterating university rows: %v", err)
-		http.Error(w, "Failed to retrieve data", http.StatusInternalServerError)
-		return
-	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -96,30 +60,12 @@ func (h *MiscHandler) GetDegreesByUniversity(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	rows, err := h.DB.Query("SELECT Id, DegreeName, Descriptions, Code FROM Degree WHERE UniversityId = ? ORDER BY DegreeName", universityID)
+	degrees, err := queries.GetDegreesByUniversity(universityID)
 	if err != nil {
 		logger.Errorf("MISC", "Error querying degrees for university %d: %v", universityID, err)
 		http.Error(w, "Failed to retrieve data", http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
-
-	degrees := []models.Degree{}
-	for rows.Next() {
-		var deg models.Degree
-		// Omitimos UniversityId al escanear ya que lo tenemos del path
-		if err := rows.Scan(&deg.Id, &deg.DegreeName, &deg.Descriptions, &deg.Code); err != nil {
-			logger.Errorf("MISC", "Error scanning degree row: %v", err)
-			continue
-		}
-		deg.UniversityId = universityID // Asignar el ID conocido
-		degrees = append(degrees, deg)
-	}
-	if err = rows.Err(); err != nil {
-		logger.Errorf("MISC", "Error iterating degree rows: %v", err)
-		http.Error(w, "Failed to retrieve data", http.StatusInternalServerError)
-		return
-	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+const moderationHandlerComponent = "MODERATION_HANDLER"
+
+// ModerationHandler maneja los reportes de contenido y la cola de moderación.
+type ModerationHandler struct {
+	service services.IModerationService
+}
+
+// NewModerationHandler crea una nueva instancia de ModerationHandler.
+func NewModerationHandler(service services.IModerationService) *ModerationHandler {
+	return &ModerationHandler{service: service}
+}
+
+// CreateReport reporta un mensaje, publicación, comentario o perfil.
+func (h *ModerationHandler) CreateReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	var req models.CreateReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	report, err := h.service.CreateReport(userID, req)
+	if err != nil {
+		logger.Warnf(moderationHandlerComponent, "No se pudo crear el reporte de %d sobre %s %s: %v", userID, req.TargetType, req.TargetId, err)
+		apperrors.WriteError(w, err, "Error al crear el reporte")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(report)
+}
+
+// ListMyReports devuelve los reportes enviados por el usuario autenticado y su estado.
+// Parámetros de query: page y pageSize.
+func (h *ModerationHandler) ListMyReports(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	page, pageSize := pageParams(r)
+	reports, err := h.service.ListMyReports(userID, page, pageSize)
+	if err != nil {
+		logger.Warnf(moderationHandlerComponent, "No se pudieron obtener los reportes de %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al obtener los reportes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+// ListReports devuelve la cola de moderación, de los reportes más antiguos a los más
+// recientes. Parámetros de query: status, targetType, page y pageSize.
+func (h *ModerationHandler) ListReports(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, pageSize := pageParams(r)
+	reports, err := h.service.ListReports(strings.ToUpper(query.Get("status")), strings.ToUpper(query.Get("targetType")), page, pageSize)
+	if err != nil {
+		logger.Warnf(moderationHandlerComponent, "No se pudo obtener la cola de moderación: %v", err)
+		apperrors.WriteError(w, err, "Error al obtener los reportes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+// GetReport devuelve un reporte con la copia del contenido reportado.
+func (h *ModerationHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	reportID, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de reporte inválido")
+		return
+	}
+
+	report, err := h.service.GetReport(reportID)
+	if err != nil {
+		apperrors.WriteError(w, err, "Error al obtener el reporte")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// ResolveReport oculta o elimina el contenido reportado y advierte o suspende a su autor.
+func (h *ModerationHandler) ResolveReport(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	reportID, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de reporte inválido")
+		return
+	}
+
+	var req models.ResolveReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+	req.ContentAction = strings.ToUpper(strings.TrimSpace(req.ContentAction))
+	req.AuthorAction = strings.ToUpper(strings.TrimSpace(req.AuthorAction))
+
+	report, err := h.service.ResolveReport(reportID, adminID, req)
+	if err != nil {
+		logger.Warnf(moderationHandlerComponent, "No se pudo resolver el reporte %d: %v", reportID, err)
+		apperrors.WriteError(w, err, "Error al resolver el reporte")
+		return
+	}
+	if report.AuthorAction == models.AuthorActionSuspend && report.TargetUserId != nil {
+		middleware.ForgetAccountState(*report.TargetUserId)
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionReportResolved,
+		TargetType: models.AuditTargetReport,
+		TargetId:   strconv.FormatInt(reportID, 10),
+		ActorId:    &adminID,
+	}, map[string]interface{}{
+		"targetType":    report.TargetType,
+		"targetId":      report.TargetId,
+		"contentAction": report.ContentAction,
+		"authorAction":  report.AuthorAction,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// DismissReport descarta el reporte sin tomar acciones sobre el contenido.
+func (h *ModerationHandler) DismissReport(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	reportID, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de reporte inválido")
+		return
+	}
+
+	// El cuerpo es opcional: solo contiene la nota.
+	var req models.DismissReportRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+			return
+		}
+	}
+
+	report, err := h.service.DismissReport(reportID, adminID, req)
+	if err != nil {
+		logger.Warnf(moderationHandlerComponent, "No se pudo descartar el reporte %d: %v", reportID, err)
+		apperrors.WriteError(w, err, "Error al descartar el reporte")
+		return
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionReportDismissed,
+		TargetType: models.AuditTargetReport,
+		TargetId:   strconv.FormatInt(reportID, 10),
+		ActorId:    &adminID,
+	}, map[string]interface{}{
+		"targetType": report.TargetType,
+		"targetId":   report.TargetId,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetReportContext devuelve el contexto capturado de un reporte de mensaje.
+func (h *ModerationHandler) GetReportContext(w http.ResponseWriter, r *http.Request) {
+	reportID, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de reporte inválido")
+		return
+	}
+
+	bundle, err := h.service.GetReportContext(reportID)
+	if err != nil {
+		apperrors.WriteError(w, err, "Error al obtener el contexto del reporte")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bundle)
+}
+
+// CaptureReportContext captura el contexto de un reporte de mensaje que no lo tiene.
+func (h *ModerationHandler) CaptureReportContext(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	reportID, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de reporte inválido")
+		return
+	}
+
+	// El cuerpo es opcional: solo contiene la ventana de mensajes.
+	var req models.CaptureReportContextRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+			return
+		}
+	}
+
+	bundle, err := h.service.CaptureReportContext(reportID, adminID, req)
+	if err != nil {
+		logger.Warnf(moderationHandlerComponent, "No se pudo capturar el contexto del reporte %d: %v", reportID, err)
+		apperrors.WriteError(w, err, "Error al capturar el contexto del reporte")
+		return
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionReportContextCaptured,
+		TargetType: models.AuditTargetReport,
+		TargetId:   strconv.FormatInt(reportID, 10),
+		ActorId:    &adminID,
+	}, map[string]interface{}{
+		"messageId": bundle.MessageId,
+		"messages":  len(bundle.Messages),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(bundle)
+}
+
+// UnhideContent vuelve a mostrar un contenido ocultado por moderación.
+func (h *ModerationHandler) UnhideContent(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	vars := mux.Vars(r)
+	targetType, targetID := strings.ToUpper(vars["targetType"]), vars["targetId"]
+
+	if err := h.service.UnhideContent(targetType, targetID); err != nil {
+		logger.Warnf(moderationHandlerComponent, "No se pudo mostrar de nuevo %s %s: %v", targetType, targetID, err)
+		apperrors.WriteError(w, err, "Error al mostrar el contenido")
+		return
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionContentUnhidden,
+		TargetType: models.AuditTargetContent,
+		TargetId:   targetType + ":" + targetID,
+		ActorId:    &adminID,
+	}, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReinstateUser reactiva una cuenta suspendida por moderación.
+func (h *ModerationHandler) ReinstateUser(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	userID, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de usuario inválido")
+		return
+	}
+
+	if err := h.service.ReinstateUser(userID); err != nil {
+		logger.Warnf(moderationHandlerComponent, "No se pudo reactivar la cuenta %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al reactivar la cuenta")
+		return
+	}
+	middleware.ForgetAccountState(userID)
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionUserReinstated,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(userID, 10),
+		ActorId:    &adminID,
+	}, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListImageReviews devuelve la cola de revisión de imágenes, de las más antiguas a las más
+// recientes. Parámetros de query: status, page y pageSize.
+func (h *ModerationHandler) ListImageReviews(w http.ResponseWriter, r *http.Request) {
+	page, pageSize := pageParams(r)
+	reviews, err := h.service.ListImageReviews(strings.ToUpper(r.URL.Query().Get("status")), page, pageSize)
+	if err != nil {
+		logger.Warnf(moderationHandlerComponent, "No se pudo obtener la cola de revisión de imágenes: %v", err)
+		apperrors.WriteError(w, err, "Error al obtener las imágenes en revisión")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reviews)
+}
+
+// ResolveImageReview aprueba o elimina una imagen de la cola de revisión.
+func (h *ModerationHandler) ResolveImageReview(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	reviewID, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de revisión inválido")
+		return
+	}
+
+	var req models.ResolveImageReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+	req.Action = strings.ToUpper(strings.TrimSpace(req.Action))
+
+	review, err := h.service.ResolveImageReview(reviewID, adminID, req)
+	if err != nil {
+		logger.Warnf(moderationHandlerComponent, "No se pudo resolver la revisión de imagen %d: %v", reviewID, err)
+		apperrors.WriteError(w, err, "Error al resolver la revisión de la imagen")
+		return
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionImageReviewed,
+		TargetType: models.AuditTargetImageReview,
+		TargetId:   strconv.FormatInt(reviewID, 10),
+		ActorId:    &adminID,
+	}, map[string]interface{}{
+		"userId":    review.UserId,
+		"contentId": review.ContentId,
+		"status":    review.Status,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
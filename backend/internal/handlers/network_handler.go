@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const networkHandlerComponent = "NETWORK_HANDLER"
+
+// NetworkHandler maneja los contactos en común, las sugerencias de contacto y el resumen de
+// la red del usuario autenticado.
+type NetworkHandler struct {
+	service services.INetworkService
+}
+
+// NewNetworkHandler crea una nueva instancia de NetworkHandler.
+func NewNetworkHandler(service services.INetworkService) *NetworkHandler {
+	return &NetworkHandler{service: service}
+}
+
+// GetMutualContacts devuelve los contactos en común con otro usuario. Parámetros de query:
+// page y pageSize.
+func (h *NetworkHandler) GetMutualContacts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	otherUserID, ok := pathID(r, "userID")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de usuario inválido")
+		return
+	}
+
+	page, pageSize := pageParams(r)
+	mutual, err := h.service.GetMutualContacts(userID, otherUserID, page, pageSize)
+	if err != nil {
+		logger.Warnf(networkHandlerComponent, "No se pudieron obtener los contactos en común de %d y %d: %v", userID, otherUserID, err)
+		apperrors.WriteError(w, err, "Error al obtener los contactos en común")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mutual)
+}
+
+// GetSuggestions devuelve las personas que el usuario quizá conozca. Parámetros de query: page
+// y pageSize.
+func (h *NetworkHandler) GetSuggestions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	page, pageSize := pageParams(r)
+	suggestions, err := h.service.GetSuggestions(userID, page, pageSize)
+	if err != nil {
+		logger.Errorf(networkHandlerComponent, "Error al obtener las sugerencias de contacto de %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al obtener las sugerencias de contacto")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestions)
+}
+
+// GetSummary devuelve el resumen de la red de contactos del usuario.
+func (h *NetworkHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	summary, err := h.service.GetSummary(userID)
+	if err != nil {
+		logger.Errorf(networkHandlerComponent, "Error al obtener el resumen de red de %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al obtener el resumen de la red")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
@@ -2,11 +2,15 @@ package handlers
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 	"github.com/gorilla/mux"
 )
@@ -62,3 +66,55 @@ func (h *NotificationHandler) MarkAsRead(w http.ResponseWriter, r *http.Request)
 	// para una operación de actualización exitosa que no necesita devolver datos.
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// MarkManyAsRead marca como leídas las notificaciones del cuerpo.
+func (h *NotificationHandler) MarkManyAsRead(w http.ResponseWriter, r *http.Request) {
+	h.bulk(w, r, func(userID int64, ids []int64) (int64, error) {
+		return h.Service.SetRead(userID, ids, true)
+	})
+}
+
+// MarkManyAsUnread marca como no leídas las notificaciones del cuerpo.
+func (h *NotificationHandler) MarkManyAsUnread(w http.ResponseWriter, r *http.Request) {
+	h.bulk(w, r, func(userID int64, ids []int64) (int64, error) {
+		return h.Service.SetRead(userID, ids, false)
+	})
+}
+
+// DeleteMany borra las notificaciones del cuerpo.
+func (h *NotificationHandler) DeleteMany(w http.ResponseWriter, r *http.Request) {
+	h.bulk(w, r, h.Service.Delete)
+}
+
+// bulk lee y valida el cuerpo de una operación masiva, la aplica con op y responde cuántas
+// notificaciones cambiaron.
+func (h *NotificationHandler) bulk(w http.ResponseWriter, r *http.Request, op func(userID int64, ids []int64) (int64, error)) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	var req models.NotificationIDsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+	if len(req.Ids) == 0 {
+		apperrors.Write(w, apperrors.MissingFields, "ids es requerido")
+		return
+	}
+	if len(req.Ids) > models.MaxNotificationBulkIDs {
+		apperrors.Write(w, apperrors.InvalidFields, fmt.Sprintf("ids debe tener como máximo %d elementos", models.MaxNotificationBulkIDs))
+		return
+	}
+
+	affected, err := op(userID, req.Ids)
+	if err != nil {
+		apperrors.WriteError(w, err, "Error al actualizar las notificaciones")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.NotificationBulkResponse{Affected: affected})
+}
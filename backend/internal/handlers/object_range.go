@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/storage"
+)
+
+const objectRangeComponent = "OBJECT_RANGE"
+
+// serveObjectRange sirve un objeto del almacenamiento respetando la cabecera Range, para que los
+// reproductores puedan saltar a cualquier punto y los clientes reanudar descargas. Solo se
+// atiende un rango por petición; si se piden varios se devuelve el objeto completo, como
+// permite RFC 9110. Las peticiones HEAD reciben solo las cabeceras.
+func serveObjectRange(w http.ResponseWriter, r *http.Request, store storage.Storage, objectPath, contentType string) {
+	info, err := store.Stat(r.Context(), objectPath)
+	if errors.Is(err, storage.ErrNotExist) {
+		apperrors.Write(w, apperrors.MediaNotFound, "Archivo no encontrado")
+		return
+	}
+	if err != nil {
+		logger.Errorf(objectRangeComponent, "Error consultando %s: %v", objectPath, err)
+		apperrors.Write(w, apperrors.MediaUnavailable, "No se pudo obtener el archivo del almacenamiento")
+		return
+	}
+	if contentType == "" {
+		contentType = info.ContentType
+	}
+
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Content-Type", contentType)
+	if !info.Updated.IsZero() {
+		w.Header().Set("Last-Modified", info.Updated.UTC().Format(http.TimeFormat))
+	}
+	if info.ETag != "" {
+		w.Header().Set("ETag", quoteETag(info.ETag))
+	}
+
+	offset, length, status := int64(0), info.Size, http.StatusOK
+	if header := r.Header.Get("Range"); header != "" && ifRangeMatches(r, info) {
+		start, end, ok := parseByteRange(header, info.Size)
+		switch {
+		case !ok:
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+			apperrors.Write(w, apperrors.MediaRangeInvalid, "Rango no satisfacible")
+			return
+		case start >= 0:
+			offset, length, status = start, end-start+1, http.StatusPartialContent
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, info.Size))
+		}
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+	if length == 0 {
+		w.WriteHeader(status)
+		return
+	}
+
+	rc, err := store.OpenRange(r.Context(), objectPath, offset, length)
+	if err != nil {
+		logger.Errorf(objectRangeComponent, "Error abriendo %s (%d+%d): %v", objectPath, offset, length, err)
+		w.Header().Del("Content-Range")
+		w.Header().Del("Content-Length")
+		apperrors.Write(w, apperrors.MediaUnavailable, "No se pudo obtener el archivo del almacenamiento")
+		return
+	}
+	defer rc.Close()
+
+	w.WriteHeader(status)
+	if _, err := io.Copy(w, rc); err != nil {
+		// El cliente suele cortar la conexión al saltar a otro punto; no es un error del servidor.
+		logger.Warnf(objectRangeComponent, "Transferencia de %s interrumpida: %v", objectPath, err)
+	}
+}
+
+// ifRangeMatches evalúa If-Range: si el objeto cambió desde el ETag o la fecha indicados se
+// ignora el rango y se envía completo, para que una descarga reanudada no mezcle versiones.
+func ifRangeMatches(r *http.Request, info *storage.ObjectInfo) bool {
+	value := r.Header.Get("If-Range")
+	if value == "" {
+		return true
+	}
+	if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "W/") {
+		// If-Range exige comparación fuerte: un ETag débil nunca coincide.
+		return info.ETag != "" && value == quoteETag(info.ETag)
+	}
+	since, err := http.ParseTime(value)
+	if err != nil {
+		return false
+	}
+	return !info.Updated.Truncate(time.Second).After(since)
+}
+
+// quoteETag devuelve el ETag del almacenamiento entre comillas, como exige la cabecera HTTP.
+func quoteETag(etag string) string {
+	if strings.HasPrefix(etag, `"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// parseByteRange interpreta una cabecera Range de un solo rango ("bytes=a-b", "bytes=a-" o
+// "bytes=-n") sobre un objeto de size bytes. Devuelve start -1 con ok true si la cabecera
+// debe ignorarse (otra unidad o varios rangos) y ok false si el rango no es satisfacible.
+func parseByteRange(header string, size int64) (start, end int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return -1, -1, true
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	if first == "" { // sufijo: los últimos n bytes
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 || size == 0 {
+			return 0, 0, false
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false
+	}
+	end = size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	return start, end, true
+}
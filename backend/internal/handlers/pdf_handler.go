@@ -72,6 +72,9 @@ func (h *PDFHandler) UploadPDF(w http.ResponseWriter, r *http.Request) {
 	uploadDetails, err := h.pdfService.ProcessAndUploadPDF(r.Context(), userID, file, handler)
 	if err != nil {
 		logger.Errorf("UploadPDF.ServiceCall", "Error procesando el PDF para el usuario %d: %v", userID, err)
+		if writeUploadCheckError(w, err) {
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		// Determinar el código de estado basado en el tipo de error podría ser más granular
 		// Por ejemplo, si es un error de validación del servicio (tipo/tamaño), podría ser BadRequest.
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+const privacyHandlerComponent = "PRIVACY_HANDLER"
+
+// PrivacyHandler expone la exportación de datos personales, el borrado de la propia cuenta y
+// las preferencias de privacidad.
+type PrivacyHandler struct {
+	service services.IPrivacyService
+}
+
+// NewPrivacyHandler crea una nueva instancia de PrivacyHandler.
+func NewPrivacyHandler(service services.IPrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{service: service}
+}
+
+// RequestDataExport programa la exportación de los datos del usuario. Responde 202.
+func (h *PrivacyHandler) RequestDataExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	req, err := h.service.RequestDataExport(userID)
+	if err != nil {
+		h.writeServiceError(w, err, "Error al solicitar la exportación de datos")
+		return
+	}
+	writePrivacyJSON(w, http.StatusAccepted, req)
+}
+
+// RequestAccountDeletion programa el borrado de la cuenta tras confirmar la contraseña. Responde 202.
+func (h *PrivacyHandler) RequestAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	var body models.AccountDeletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Password == "" {
+		apperrors.Write(w, apperrors.MissingFields, "Se requiere la contraseña para confirmar el borrado")
+		return
+	}
+
+	req, err := h.service.RequestAccountDeletion(userID, body.Password, body.Mode)
+	if err != nil {
+		h.writeServiceError(w, err, "Error al solicitar el borrado de la cuenta")
+		return
+	}
+	writePrivacyJSON(w, http.StatusAccepted, req)
+}
+
+// ListRequests lista las solicitudes de exportación y borrado del usuario.
+func (h *PrivacyHandler) ListRequests(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	requests, err := h.service.ListRequests(userID)
+	if err != nil {
+		h.writeServiceError(w, err, "Error al obtener las solicitudes")
+		return
+	}
+	writePrivacyJSON(w, http.StatusOK, requests)
+}
+
+// DownloadDataExport descarga el ZIP de la exportación {requestID}.
+func (h *PrivacyHandler) DownloadDataExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	requestID, err := strconv.ParseInt(mux.Vars(r)["requestID"], 10, 64)
+	if err != nil {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de solicitud inválido")
+		return
+	}
+
+	file, err := h.service.OpenDataExport(userID, requestID)
+	if err != nil {
+		h.writeServiceError(w, err, "Error al descargar la exportación")
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		logger.Errorf(privacyHandlerComponent, "Error leyendo la exportación %d: %v", requestID, err)
+		apperrors.Write(w, apperrors.Internal, "Error al descargar la exportación")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="datos-personales-%d.zip"`, requestID))
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeContent(w, r, "", info.ModTime(), file)
+}
+
+// GetSettings devuelve las preferencias de privacidad del usuario.
+func (h *PrivacyHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	settings, err := h.service.GetSettings(userID)
+	if err != nil {
+		h.writeServiceError(w, err, "Error al obtener la configuración de privacidad")
+		return
+	}
+	writePrivacyJSON(w, http.StatusOK, settings)
+}
+
+// UpdateSettings modifica las preferencias de privacidad del usuario. Los campos omitidos
+// en el cuerpo conservan su valor.
+func (h *PrivacyHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	var body models.UpdatePrivacySettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(userID, body)
+	if err != nil {
+		h.writeServiceError(w, err, "Error al guardar la configuración de privacidad")
+		return
+	}
+	writePrivacyJSON(w, http.StatusOK, settings)
+}
+
+// writeServiceError responde con el error de dominio del servicio o, si es un error
+// interno, lo registra y responde con fallback.
+func (h *PrivacyHandler) writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	appErr := apperrors.From(err, fallback)
+	if appErr.Code == apperrors.Internal {
+		logger.Errorf(privacyHandlerComponent, "%s: %v", fallback, err)
+	}
+	apperrors.Write(w, appErr.Code, appErr.Message)
+}
+
+func writePrivacyJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const pushHandlerComponent = "PUSH_HANDLER"
+
+// PushHandler maneja los dispositivos y las preferencias de notificaciones push.
+type PushHandler struct {
+	service services.IPushDeviceService
+}
+
+// NewPushHandler crea una nueva instancia de PushHandler.
+func NewPushHandler(service services.IPushDeviceService) *PushHandler {
+	return &PushHandler{service: service}
+}
+
+// ListDevices devuelve los dispositivos registrados por el usuario autenticado.
+func (h *PushHandler) ListDevices(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	devices, err := h.service.ListDevices(userID)
+	if err != nil {
+		logger.Errorf(pushHandlerComponent, "No se pudieron obtener los dispositivos de %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al obtener los dispositivos")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(devices)
+}
+
+// RegisterDevice registra el token push de un dispositivo del usuario autenticado.
+func (h *PushHandler) RegisterDevice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	var req models.RegisterPushDeviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	device, err := h.service.RegisterDevice(userID, req)
+	if err != nil {
+		logger.Warnf(pushHandlerComponent, "No se pudo registrar el dispositivo de %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al registrar el dispositivo")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(device)
+}
+
+// DeleteDevice da de baja un dispositivo del usuario autenticado.
+func (h *PushHandler) DeleteDevice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	deviceID, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de dispositivo inválido")
+		return
+	}
+
+	if err := h.service.DeleteDevice(userID, deviceID); err != nil {
+		logger.Warnf(pushHandlerComponent, "No se pudo eliminar el dispositivo %d de %d: %v", deviceID, userID, err)
+		apperrors.WriteError(w, err, "Error al eliminar el dispositivo")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPreferences devuelve las preferencias de notificaciones del usuario autenticado.
+func (h *PushHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	prefs, err := h.service.GetPreferences(userID)
+	if err != nil {
+		logger.Errorf(pushHandlerComponent, "No se pudieron obtener las preferencias de %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al obtener las preferencias de notificaciones")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
+
+// UpdatePreferences modifica las preferencias de notificaciones del usuario autenticado. Los
+// campos omitidos en el cuerpo conservan su valor.
+func (h *PushHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	var req models.UpdateNotificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	prefs, err := h.service.UpdatePreferences(userID, req)
+	if err != nil {
+		logger.Errorf(pushHandlerComponent, "No se pudieron guardar las preferencias de %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al guardar las preferencias de notificaciones")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
+
+// GetWebPushConfig devuelve si Web Push está disponible y la clave pública VAPID con la que el
+// navegador debe suscribirse.
+func (h *PushHandler) GetWebPushConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.service.GetWebPushConfig())
+}
+
+// ListWebPushSubscriptions devuelve las suscripciones Web Push del usuario autenticado.
+func (h *PushHandler) ListWebPushSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	subscriptions, err := h.service.ListWebPushSubscriptions(userID)
+	if err != nil {
+		logger.Errorf(pushHandlerComponent, "No se pudieron obtener las suscripciones web push de %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al obtener las suscripciones web push")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subscriptions)
+}
+
+// RegisterWebPushSubscription registra la suscripción Web Push del navegador del usuario
+// autenticado.
+func (h *PushHandler) RegisterWebPushSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	var req models.RegisterWebPushSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	subscription, err := h.service.RegisterWebPushSubscription(userID, req, r.UserAgent())
+	if err != nil {
+		logger.Warnf(pushHandlerComponent, "No se pudo registrar la suscripción web push de %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al registrar la suscripción web push")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(subscription)
+}
+
+// DeleteWebPushSubscription da de baja una suscripción Web Push del usuario autenticado.
+func (h *PushHandler) DeleteWebPushSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	subscriptionID, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de suscripción inválido")
+		return
+	}
+
+	if err := h.service.DeleteWebPushSubscription(userID, subscriptionID); err != nil {
+		logger.Warnf(pushHandlerComponent, "No se pudo eliminar la suscripción web push %d de %d: %v", subscriptionID, userID, err)
+		apperrors.WriteError(w, err, "Error al eliminar la suscripción web push")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const savedSearchHandlerComponent = "SAVED_SEARCH_HANDLER"
+
+// SavedSearchHandler maneja las búsquedas guardadas del usuario autenticado.
+type SavedSearchHandler struct {
+	service services.ISavedSearchService
+}
+
+// NewSavedSearchHandler crea una nueva instancia de SavedSearchHandler.
+func NewSavedSearchHandler(service services.ISavedSearchService) *SavedSearchHandler {
+	return &SavedSearchHandler{service: service}
+}
+
+// List devuelve las búsquedas guardadas del usuario.
+func (h *SavedSearchHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	searches, err := h.service.List(userID)
+	if err != nil {
+		logger.Errorf(savedSearchHandlerComponent, "Error al listar las búsquedas guardadas de %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al obtener las búsquedas guardadas")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(searches)
+}
+
+// Create guarda una búsqueda nueva.
+func (h *SavedSearchHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	var req models.SavedSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	search, err := h.service.Create(userID, req)
+	if err != nil {
+		logger.Warnf(savedSearchHandlerComponent, "No se pudo guardar la búsqueda de %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al guardar la búsqueda")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(search)
+}
+
+// Update reemplaza los criterios de una búsqueda guardada.
+func (h *SavedSearchHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	id, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de búsqueda inválido")
+		return
+	}
+
+	var req models.SavedSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	search, err := h.service.Update(id, userID, req)
+	if err != nil {
+		logger.Warnf(savedSearchHandlerComponent, "No se pudo actualizar la búsqueda %d de %d: %v", id, userID, err)
+		apperrors.WriteError(w, err, "Error al actualizar la búsqueda")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(search)
+}
+
+// Delete elimina una búsqueda guardada.
+func (h *SavedSearchHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	id, ok := pathID(r, "id")
+	if !ok {
+		apperrors.Write(w, apperrors.InvalidParam, "ID de búsqueda inválido")
+		return
+	}
+
+	if err := h.service.Delete(id, userID); err != nil {
+		logger.Warnf(savedSearchHandlerComponent, "No se pudo eliminar la búsqueda %d de %d: %v", id, userID, err)
+		apperrors.WriteError(w, err, "Error al eliminar la búsqueda")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/internal/services"
 )
@@ -43,6 +44,11 @@ func (h *SearchHandler) SearchTalent(w http.ResponseWriter, r *http.Request) {
 		Location:   queryValues.Get("location"),
 		Page:       page,
 		Limit:      limit,
+		TenantID:   middleware.TenantID(r.Context()),
+	}
+	// La ruta es protegida: se excluyen los usuarios con los que hay un bloqueo.
+	if userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64); ok {
+		params.ViewerID = userID
 	}
 
 	if years, err := strconv.Atoi(queryValues.Get("years_of_experience_min")); err == nil {
@@ -60,6 +66,9 @@ func (h *SearchHandler) SearchTalent(w http.ResponseWriter, r *http.Request) {
 	if isWorking, err := strconv.ParseBool(queryValues.Get("is_currently_working")); err == nil {
 		params.IsCurrentlyWorking = &isWorking
 	}
+	if verifiedOnly, err := strconv.ParseBool(queryValues.Get("verified_only")); err == nil {
+		params.VerifiedOnly = verifiedOnly
+	}
 	if skills := queryValues.Get("skills"); skills != "" {
 		params.Skills = strings.Split(skills, ",")
 	}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const skillHandlerComponent = "SKILL_HANDLER"
+
+// catalogSkill es el nombre del catálogo de habilidades en el registro de auditoría.
+const catalogSkill = "skill"
+
+// Límites de los listados del catálogo de habilidades.
+const (
+	skillSuggestDefaultLimit  = 10
+	skillSuggestMaxLimit      = 25
+	skillUnmappedDefaultLimit = 50
+	skillUnmappedMaxLimit     = 500
+)
+
+// SkillHandler expone el catálogo de habilidades canónicas: la lectura y el autocompletado
+// públicos y, en el panel de administración, su gestión y la migración de las habilidades
+// existentes.
+type SkillHandler struct {
+	service services.ISkillService
+}
+
+// NewSkillHandler crea una nueva instancia de SkillHandler.
+func NewSkillHandler(service services.ISkillService) *SkillHandler {
+	return &SkillHandler{service: service}
+}
+
+// limitParam lee el parámetro de query limit; si falta o no es válido usa def y nunca supera
+// max.
+func limitParam(r *http.Request, def, max int) int {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		return def
+	}
+	if limit > max {
+		return max
+	}
+	return limit
+}
+
+// GetSkills devuelve el catálogo completo de habilidades con sus alias.
+func (h *SkillHandler) GetSkills(w http.ResponseWriter, r *http.Request) {
+	skills, err := h.service.GetCatalog()
+	if err != nil {
+		logger.Errorf(skillHandlerComponent, "Error al obtener el catálogo de habilidades: %v", err)
+		apperrors.WriteError(w, err, "Error al obtener el catálogo de habilidades")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(skills)
+}
+
+// SuggestSkills devuelve habilidades para autocompletar. Parámetros de query: q (texto
+// escrito hasta el momento; vacío devuelve las más usadas) y limit (por defecto 10, máximo 25).
+func (h *SkillHandler) SuggestSkills(w http.ResponseWriter, r *http.Request) {
+	limit := limitParam(r, skillSuggestDefaultLimit, skillSuggestMaxLimit)
+	suggestions, err := h.service.Suggest(r.URL.Query().Get("q"), limit)
+	if err != nil {
+		logger.Errorf(skillHandlerComponent, "Error al sugerir habilidades: %v", err)
+		apperrors.WriteError(w, err, "Error al sugerir habilidades")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestions)
+}
+
+// CreateSkill crea una habilidad canónica. Cuerpo: {"name", "category", "aliases": [...]}.
+func (h *SkillHandler) CreateSkill(w http.ResponseWriter, r *http.Request) {
+	createCatalogItem(w, r, catalogSkill, h.service.CreateSkill,
+		func(item models.SkillCatalogItem) int64 { return item.Id })
+}
+
+// UpdateSkill reemplaza el nombre, la categoría y los alias de una habilidad canónica.
+func (h *SkillHandler) UpdateSkill(w http.ResponseWriter, r *http.Request) {
+	updateCatalogItem(w, r, catalogSkill, h.service.UpdateSkill)
+}
+
+// DeleteSkill elimina una habilidad canónica; las habilidades de usuarios asociadas quedan
+// como texto libre.
+func (h *SkillHandler) DeleteSkill(w http.ResponseWriter, r *http.Request) {
+	deleteCatalogItem(w, r, catalogSkill, h.service.DeleteSkill)
+}
+
+// GetUnmappedSkills devuelve los textos de habilidad que el catálogo no reconoce, de más a
+// menos usados. Parámetro de query: limit (por defecto 50, máximo 500).
+func (h *SkillHandler) GetUnmappedSkills(w http.ResponseWriter, r *http.Request) {
+	skills, err := h.service.GetUnmapped(limitParam(r, skillUnmappedDefaultLimit, skillUnmappedMaxLimit))
+	if err != nil {
+		logger.Errorf(skillHandlerComponent, "Error al obtener las habilidades sin asociar: %v", err)
+		apperrors.WriteError(w, err, "Error al obtener las habilidades sin asociar")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(skills)
+}
+
+// RemapSkills asocia con el catálogo las habilidades de usuarios que aún no lo están, por
+// ejemplo después de añadir habilidades o alias. Con ?dryRun=true solo devuelve el informe.
+func (h *SkillHandler) RemapSkills(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+
+	report, err := h.service.MapExistingSkills(dryRun)
+	if err != nil {
+		logger.Errorf(skillHandlerComponent, "Error al asociar las habilidades existentes: %v", err)
+		apperrors.WriteError(w, err, "Error al asociar las habilidades existentes")
+		return
+	}
+
+	if !dryRun {
+		services.RecordAudit(r, models.AuditLog{
+			Action:     models.AuditActionSkillsRemapped,
+			TargetType: models.AuditTargetCatalog,
+			TargetId:   catalogSkill,
+			ActorId:    &adminID,
+		}, map[string]interface{}{"report": report})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
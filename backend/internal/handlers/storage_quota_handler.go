@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const storageQuotaHandlerComponent = "STORAGE_QUOTA_HANDLER"
+
+// StorageQuotaHandler expone el uso de almacenamiento del usuario y permite a los
+// administradores consultar y cambiar la cuota de cualquier usuario.
+type StorageQuotaHandler struct {
+	service services.IStorageQuotaService
+}
+
+// NewStorageQuotaHandler crea una nueva instancia de StorageQuotaHandler.
+func NewStorageQuotaHandler(service services.IStorageQuotaService) *StorageQuotaHandler {
+	return &StorageQuotaHandler{service: service}
+}
+
+func (h *StorageQuotaHandler) writeUsage(w http.ResponseWriter, userID int64) {
+	usage, err := h.service.Usage(userID)
+	if err != nil {
+		logger.Errorf(storageQuotaHandlerComponent, "Error obteniendo el almacenamiento del usuario %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al obtener el uso de almacenamiento")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(usage)
+}
+
+// GetUsage devuelve el espacio usado por el usuario autenticado y su cuota.
+func (h *StorageQuotaHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	h.writeUsage(w, userID)
+}
+
+// GetUserUsage devuelve el espacio usado por un usuario y su cuota (administración).
+func (h *StorageQuotaHandler) GetUserUsage(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := adminAndTarget(w, r)
+	if !ok {
+		return
+	}
+	h.writeUsage(w, userID)
+}
+
+// SetUserQuota fija la cuota de un usuario. Cuerpo: {"quotaBytes": 2147483648}; 0 quita el
+// límite y null vuelve a la cuota de su rol.
+func (h *StorageQuotaHandler) SetUserQuota(w http.ResponseWriter, r *http.Request) {
+	adminID, userID, ok := adminAndTarget(w, r)
+	if !ok {
+		return
+	}
+	var req models.UpdateStorageQuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido o malformado.")
+		return
+	}
+
+	usage, err := h.service.SetQuota(userID, req.QuotaBytes)
+	if err != nil {
+		logger.Warnf(storageQuotaHandlerComponent, "No se pudo cambiar la cuota del usuario %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al cambiar la cuota de almacenamiento")
+		return
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionStorageQuotaChanged,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(userID, 10),
+		ActorId:    &adminID,
+	}, map[string]interface{}{"quotaBytes": req.QuotaBytes})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(usage)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const studentAnalyticsHandlerComponent = "STUDENT_ANALYTICS_HANDLER"
+
+// StudentAnalyticsHandler maneja las solicitudes de estadísticas de estudiantes y egresados.
+type StudentAnalyticsHandler struct {
+	service services.IStudentAnalyticsService
+}
+
+// NewStudentAnalyticsHandler crea una nueva instancia de StudentAnalyticsHandler.
+func NewStudentAnalyticsHandler(service services.IStudentAnalyticsService) *StudentAnalyticsHandler {
+	return &StudentAnalyticsHandler{service: service}
+}
+
+// GetMyAnalytics devuelve las estadísticas del usuario autenticado: visitas a su perfil,
+// desglose de sus postulaciones por estado e impresiones en el feed.
+// Parámetro de query: days (1-365, por defecto 30).
+func (h *StudentAnalyticsHandler) GetMyAnalytics(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	roleID, _ := r.Context().Value(middleware.RoleIDContextKey).(int64)
+	if role := models.UserRole(roleID); role != models.RoleStudent && role != models.RoleEgresado {
+		apperrors.Write(w, apperrors.Forbidden, "Las estadísticas de perfil solo están disponibles para estudiantes y egresados")
+		return
+	}
+
+	days := defaultDashboardDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			apperrors.Write(w, apperrors.InvalidParam, "El parámetro 'days' debe ser un número entero")
+			return
+		}
+		days = parsed
+	}
+
+	analytics, err := h.service.GetAnalytics(userID, days)
+	if err != nil {
+		if !errors.Is(err, services.ErrInvalidAnalyticsRange) {
+			logger.Errorf(studentAnalyticsHandlerComponent, "Error al obtener las estadísticas del usuario %d: %v", userID, err)
+		}
+		apperrors.WriteError(w, err, "Error al obtener las estadísticas")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analytics)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const studentVerificationHandlerComponent = "STUDENT_VERIFICATION_HANDLER"
+
+// StudentVerificationHandler expone la verificación de estudiantes y egresados con el correo
+// institucional.
+type StudentVerificationHandler struct {
+	service services.IStudentVerificationService
+}
+
+// NewStudentVerificationHandler crea una nueva instancia de StudentVerificationHandler.
+func NewStudentVerificationHandler(service services.IStudentVerificationService) *StudentVerificationHandler {
+	return &StudentVerificationHandler{service: service}
+}
+
+// GetStatus devuelve el estado de la verificación del usuario.
+func (h *StudentVerificationHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	roleID, _ := r.Context().Value(middleware.RoleIDContextKey).(int64)
+
+	status, err := h.service.GetStatus(userID, models.UserRole(roleID))
+	if err != nil {
+		h.writeServiceError(w, err, "Error al obtener la verificación de estudiante")
+		return
+	}
+	writePrivacyJSON(w, http.StatusOK, status)
+}
+
+// RequestCode envía un código de verificación al correo institucional. Cuerpo: {"email"}.
+// Responde 202.
+func (h *StudentVerificationHandler) RequestCode(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	roleID, _ := r.Context().Value(middleware.RoleIDContextKey).(int64)
+
+	var body models.StudentVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Email == "" {
+		apperrors.Write(w, apperrors.MissingFields, "Se requiere el correo institucional")
+		return
+	}
+
+	status, err := h.service.RequestCode(userID, models.UserRole(roleID), body.Email)
+	if err != nil {
+		h.writeServiceError(w, err, "Error al enviar el código de verificación")
+		return
+	}
+	writePrivacyJSON(w, http.StatusAccepted, status)
+}
+
+// Confirm confirma el correo institucional con el código recibido. Cuerpo: {"code"}.
+func (h *StudentVerificationHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	roleID, _ := r.Context().Value(middleware.RoleIDContextKey).(int64)
+
+	var body models.StudentVerificationConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Code == "" {
+		apperrors.Write(w, apperrors.MissingFields, "Se requiere el código de verificación")
+		return
+	}
+
+	status, err := h.service.Confirm(userID, models.UserRole(roleID), body.Code)
+	if err != nil {
+		h.writeServiceError(w, err, "Error al confirmar la verificación de estudiante")
+		return
+	}
+	// La verificación puede asignar la institución de la cuenta
+	middleware.ForgetAccountState(userID)
+	writePrivacyJSON(w, http.StatusOK, status)
+}
+
+// writeServiceError responde con el error de dominio del servicio o, si es un error
+// interno, lo registra y responde con fallback.
+func (h *StudentVerificationHandler) writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	appErr := apperrors.From(err, fallback)
+	if appErr.Code == apperrors.Internal {
+		logger.Errorf(studentVerificationHandlerComponent, "%s: %v", fallback, err)
+	}
+	apperrors.Write(w, appErr.Code, appErr.Message)
+}
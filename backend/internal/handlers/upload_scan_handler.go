@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+const uploadScanHandlerComponent = "UPLOAD_SCAN_HANDLER"
+
+// UploadScanHandler permite a los administradores revisar y borrar los archivos en cuarentena.
+type UploadScanHandler struct {
+	service services.IUploadScanService
+}
+
+// NewUploadScanHandler crea una nueva instancia de UploadScanHandler.
+func NewUploadScanHandler(service services.IUploadScanService) *UploadScanHandler {
+	return &UploadScanHandler{service: service}
+}
+
+// uploadCheckErrors son los errores del análisis de malware y de la moderación automática de
+// imágenes.
+var uploadCheckErrors = []error{
+	services.ErrUploadQuarantined,
+	services.ErrUploadScanUnavailable,
+	services.ErrImageRejected,
+	services.ErrImageModerationDown,
+}
+
+// writeUploadCheckError responde con el error del análisis de malware o de la moderación
+// automática si err lo es. Lo usan los handlers de subida que todavía responden sus propios
+// errores en vez de apperrors.
+func writeUploadCheckError(w http.ResponseWriter, err error) bool {
+	for _, target := range uploadCheckErrors {
+		if errors.Is(err, target) {
+			apperrors.WriteError(w, err, "Error al revisar el archivo")
+			return true
+		}
+	}
+	return false
+}
+
+// ListQuarantined devuelve los archivos en cuarentena, del más reciente al más antiguo.
+// Parámetros de query: page y pageSize.
+func (h *UploadScanHandler) ListQuarantined(w http.ResponseWriter, r *http.Request) {
+	page, pageSize := pageParams(r)
+	response, err := h.service.ListQuarantined(page, pageSize)
+	if err != nil {
+		logger.Errorf(uploadScanHandlerComponent, "Error obteniendo los archivos en cuarentena: %v", err)
+		apperrors.WriteError(w, err, "Error al obtener los archivos en cuarentena")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteQuarantined borra definitivamente un archivo en cuarentena.
+func (h *UploadScanHandler) DeleteQuarantined(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	multimediaID := mux.Vars(r)["id"]
+
+	if err := h.service.DeleteQuarantined(r.Context(), multimediaID); err != nil {
+		logger.Warnf(uploadScanHandlerComponent, "No se pudo borrar el archivo en cuarentena %s: %v", multimediaID, err)
+		apperrors.WriteError(w, err, "Error al borrar el archivo en cuarentena")
+		return
+	}
+
+	services.RecordAudit(r, models.AuditLog{
+		Action:     models.AuditActionQuarantineDeleted,
+		TargetType: models.AuditTargetContent,
+		TargetId:   "MULTIMEDIA:" + multimediaID,
+		ActorId:    &adminID,
+	}, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+const uploadSessionHandlerComponent = "UPLOAD_SESSION_HANDLER"
+
+// Cabeceras del protocolo de subida reanudable (inspiradas en tus).
+const (
+	uploadOffsetHeader = "Upload-Offset"
+	uploadLengthHeader = "Upload-Length"
+)
+
+// UploadSessionHandler expone las subidas reanudables de video: se crea la subida, se envían
+// partes con PATCH indicando su offset y se completa cuando están todas. Ver
+// docs/subidas_reanudables.md.
+type UploadSessionHandler struct {
+	service services.IUploadSessionService
+}
+
+// NewUploadSessionHandler crea una nueva instancia de UploadSessionHandler.
+func NewUploadSessionHandler(service services.IUploadSessionService) *UploadSessionHandler {
+	return &UploadSessionHandler{service: service}
+}
+
+// Create registra una subida. Cuerpo: fileName y size en bytes. Responde 201 con la subida y
+// su URL en la cabecera Location.
+func (h *UploadSessionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	var body models.CreateUploadSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.Write(w, apperrors.InvalidBody, "Cuerpo de la solicitud inválido")
+		return
+	}
+
+	session, err := h.service.Create(userID, body)
+	if err != nil {
+		logger.Warnf(uploadSessionHandlerComponent, "No se pudo crear la subida del usuario %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al crear la subida")
+		return
+	}
+
+	writeUploadHeaders(w, session)
+	w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+session.Id)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(session)
+}
+
+// Status devuelve el estado de una subida. Con HEAD solo envía las cabeceras Upload-Offset y
+// Upload-Length, que es lo que necesita el cliente para reanudar.
+func (h *UploadSessionHandler) Status(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	session, err := h.service.Get(userID, mux.Vars(r)["id"])
+	if err != nil {
+		apperrors.WriteError(w, err, "Error al obtener la subida")
+		return
+	}
+
+	writeUploadHeaders(w, session)
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
+// Upload recibe una parte de la subida. La cabecera Upload-Offset debe coincidir con los
+// bytes ya recibidos; el cuerpo son los bytes en bruto. Responde 204 con el nuevo
+// Upload-Offset, o 409 con el offset esperado si no coincide.
+func (h *UploadSessionHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get(uploadOffsetHeader), 10, 64)
+	if err != nil || offset < 0 {
+		apperrors.Write(w, apperrors.UploadSessionInvalid, "Se requiere la cabecera Upload-Offset")
+		return
+	}
+	id := mux.Vars(r)["id"]
+
+	newOffset, err := h.service.WriteChunk(userID, id, offset, r.Body)
+	w.Header().Set("Cache-Control", "no-store")
+	if err != nil {
+		if errors.Is(err, services.ErrUploadOffsetMismatch) || newOffset > offset {
+			// El cliente reanuda desde el offset indicado.
+			w.Header().Set(uploadOffsetHeader, strconv.FormatInt(newOffset, 10))
+		}
+		logger.Warnf(uploadSessionHandlerComponent, "Parte rechazada de la subida %s del usuario %d: %v", id, userID, err)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apperrors.Write(w, apperrors.UploadSessionInvalid, "La parte excede el tamaño máximo (chunkSize)")
+			return
+		}
+		apperrors.WriteError(w, err, "Error al recibir la parte")
+		return
+	}
+
+	w.Header().Set(uploadOffsetHeader, strconv.FormatInt(newOffset, 10))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Complete procesa el archivo de una subida con todos sus bytes como POST /videos/upload y
+// responde 202 con los mismos detalles. Si falla, la subida sigue disponible para reintentar.
+func (h *UploadSessionHandler) Complete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	id := mux.Vars(r)["id"]
+
+	details, err := h.service.Complete(userID, id)
+	if err != nil {
+		logger.Errorf(uploadSessionHandlerComponent, "No se pudo completar la subida %s del usuario %d: %v", id, userID, err)
+		apperrors.WriteError(w, err, "Error al procesar el archivo de video")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(details)
+}
+
+// Cancel cancela una subida en curso y borra los bytes recibidos.
+func (h *UploadSessionHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	if err := h.service.Cancel(userID, mux.Vars(r)["id"]); err != nil {
+		apperrors.WriteError(w, err, "Error al cancelar la subida")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeUploadHeaders añade las cabeceras con el progreso de la subida.
+func writeUploadHeaders(w http.ResponseWriter, session *models.UploadSession) {
+	w.Header().Set(uploadOffsetHeader, strconv.FormatInt(session.Offset, 10))
+	w.Header().Set(uploadLengthHeader, strconv.FormatInt(session.Size, 10))
+	w.Header().Set("Cache-Control", "no-store")
+}
@@ -122,6 +122,8 @@ func (h *UserHandler) UpdateMyProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	queries.InvalidateUserCache(userID)
+
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		logger.Errorf("USER", "Error getting rows affected for UserID %d: %v", userID, err)
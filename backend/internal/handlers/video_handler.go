@@ -8,8 +8,8 @@
  * Este archivo maneja todas las solicitudes HTTP relacionadas con videos, incluyendo
  * la subida de nuevos videos y el servicio de manifiestos y segmentos HLS para streaming.
  * Interactúa con VideoUploadService para la lógica de negocio (subida, transcodificación),
- * con el paquete de queries para el acceso a la base de datos, y con cloudclient para
- * la interacción con Google Cloud Storage (GCS).
+ * con el paquete de queries para el acceso a la base de datos, y con storage.Storage para
+ * leer los archivos (GCS, S3 o disco local según STORAGE_DRIVER).
  *
  * =====================================================================================
  * GUÍA DE MANTENIMIENTO Y EXTENSIÓN
@@ -18,7 +18,7 @@
  * ESTRUCTURA Y DEPENDENCIAS:
  * --------------------------
  * - VideoHandler: Contiene referencias a VideoUploadService, *sql.DB (para queries directas
- *   como GetMultimediaByContentID) y *config.Config (para JWT secret, etc.).
+ *   como GetMultimediaByContentID), *config.Config (para JWT secret, etc.) y el almacenamiento.
  * - NewVideoHandler: Constructor que inicializa el handler con sus dependencias.
  *
  * FLUJO DE SECUENCIA DE FUNCIONES PÚBLICAS:
@@ -27,7 +27,7 @@
  *    a. Extrae `userID` del contexto JWT (ruta protegida).
  *    b. Parsea el formulario `multipart/form-data` para obtener el archivo "video".
  *    c. Valida el archivo (tamaño máximo) y lo pasa a `h.videoService.ProcessAndUploadVideo`.
- *       - El servicio se encarga de: subir el original a GCS, guardar el registro
+ *       - El servicio se encarga de: subir el original al almacenamiento, guardar el registro
  *         inicial en `Multimedia` con estado "uploaded", y disparar la
  *         transcodificación asíncrona (actualmente simulada).
  *    d. Responde con `202 Accepted` y los detalles de la subida inicial (ContentID, URL original).
@@ -49,27 +49,29 @@
  *    a. Extrae `contentID`, `quality` (ej. "1080p"), y `fileName` (ej. "playlist.m3u8" o "segment001.ts")
  *       de la ruta URL.
  *    b. Extrae y valida el token JWT del query parameter "token".
- *    c. Construye la ruta completa al objeto en GCS (ej. "videos/{contentID}/{quality}/{fileName}").
- *    d. Llama a `cloudclient.DownloadFile` para descargar el archivo de GCS.
- *       - Se asume que `DownloadFile` devuelve `([]byte, error)`.
+ *    c. Construye la ruta completa al objeto (ej. "videos/{contentID}/{quality}/{fileName}").
+ *    d. Llama a `h.store.Get` para descargar el archivo del almacenamiento.
  *    e. Determina el `Content-Type` apropiado (`application/vnd.apple.mpegurl` para .m3u8,
  *       `video/MP2T` para .ts).
  *    f. Establece los headers (`Content-Type`, `Content-Length`, `Access-Control-Allow-Origin`).
  *    g. Escribe los bytes del archivo en `http.ResponseWriter` (usando `bytes.NewReader`).
  *
+ * 4. DownloadOriginalVideo (GET|HEAD /api/v1/videos/{contentID}/original?token=<jwt>):
+ *    a. Valida el token JWT del query parameter "token" y busca el video por `contentID`.
+ *    b. Sirve el archivo original (`Multimedia.FileName`) con `serveObjectRange`, que atiende
+ *       `Range`/`If-Range` leyendo del almacenamiento solo los bytes pedidos (206 Partial Content).
+ *       Permite reanudar descargas y saltar dentro de un MP4 sin esperar a la transcodificación.
+ *    c. Con `?download=1` añade `Content-Disposition: attachment`.
+ *
  * REGLAS Y CONSIDERACIONES PARA FUTUROS CAMBIOS:
  * ---------------------------------------------
  * 1.  AUTENTICACIÓN: Las rutas de streaming usan un token JWT en el query param ("token").
  *     Esto es diferente de las rutas de API protegidas estándar que usan el header `Authorization`.
  *     Mantener esta consistencia o documentar cualquier cambio.
  *
- * 2.  EXTRACCIÓN DE PARÁMETROS URL: La extracción actual de `contentID`, `quality`, `fileName`
- *     de `r.URL.Path` mediante `strings.Split` es funcional pero podría ser más robusta
- *     si se usaran las capacidades de extracción de parámetros de `gorilla/mux` de forma más directa
- *     (requeriría que las rutas se definan con `mux.Vars(r)` en mente, lo cual se hace
- *     automáticamente por `gorilla/mux` si las rutas tienen placeholders como `{contentID}`).
- *     Actualmente, las rutas en `api_routes.go` están definidas con esos placeholders,
- *     así que idealmente se usaría `mux.Vars(r)["paramName"]`.
+ * 2.  EXTRACCIÓN DE PARÁMETROS URL: `contentID`, `quality` y `fileName` se leen con `mux.Vars(r)`
+ *     a partir de los placeholders definidos en `api_routes.go`. No parsear `r.URL.Path`: las
+ *     mismas rutas se montan bajo varios prefijos de versión (/api/v1, /api/v2, /api).
  *
  * 3.  DEPENDENCIA DE VideoUploadService: Este handler delega la lógica compleja de subida y
  *     el inicio de la transcodificación al `VideoUploadService`. No se debe duplicar esa lógica aquí.
@@ -77,11 +79,10 @@
  * 4.  ACCESO A BD: Para obtener el estado y los paths HLS, se usa `queries.GetMultimediaByContentID`.
  *     Asegurar que los modelos y queries estén sincronizados con la estructura de la tabla `Multimedia`.
  *
- * 5.  INTERACCIÓN CON GCS (cloudclient): El streaming actúa como un proxy, descargando archivos
- *     de GCS y sirviéndolos. La implementación actual asume que `cloudclient.DownloadFile`
- *     devuelve `([]byte, error)`. Si esta firma cambia (ej. a `io.ReadCloser`), se deberá ajustar
- *     `StreamVideoVariant` (manejo de `defer Close()`, `io.Copy` directo, y obtención de
- *     `Content-Length` que sería más compleja).
+ * 5.  INTERACCIÓN CON EL ALMACENAMIENTO (pkg/storage): El streaming actúa como un proxy,
+ *     descargando archivos y sirviéndolos. `StreamVideoVariant` lee el archivo completo con
+ *     `Get` (los segmentos HLS son pequeños); para archivos grandes usar `serveObjectRange`, que
+ *     lee por rangos con `OpenRange`. Un objeto inexistente se detecta con `storage.ErrNotExist`.
  *
  * 6.  TRANSCODIFICACIÓN ASÍNCRONA: El streaming solo funciona para videos cuyo `ProcessingStatus`
  *     es "completed". El handler `StreamVideoMasterPlaylist` verifica esto. Considerar si se
@@ -116,9 +117,10 @@ import (
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
 	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
 	"github.com/davidM20/micro-service-backend-go.git/internal/services"
-	"github.com/davidM20/micro-service-backend-go.git/pkg/cloudclient"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
-	gcsErrors "google.golang.org/api/googleapi"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/storage"
+	"github.com/gorilla/mux"
 )
 
 /*
@@ -132,11 +134,12 @@ type VideoHandler struct {
 	videoService *services.VideoUploadService
 	db           *sql.DB
 	cfg          *config.Config
+	store        storage.Storage
 }
 
 // NewVideoHandler crea una nueva instancia de VideoHandler.
-func NewVideoHandler(videoService *services.VideoUploadService, db *sql.DB, cfg *config.Config) *VideoHandler {
-	return &VideoHandler{videoService: videoService, db: db, cfg: cfg}
+func NewVideoHandler(videoService *services.VideoUploadService, db *sql.DB, cfg *config.Config, store storage.Storage) *VideoHandler {
+	return &VideoHandler{videoService: videoService, db: db, cfg: cfg, store: store}
 }
 
 // UploadVideo es el método que maneja la petición POST para subir un archivo de video.
@@ -176,6 +179,9 @@ func (h *VideoHandler) UploadVideo(w http.ResponseWriter, r *http.Request) {
 	uploadDetails, err := h.videoService.ProcessAndUploadVideo(r.Context(), userID, file, handler)
 	if err != nil {
 		logger.Errorf("UploadVideo.ServiceCall", "Error procesando el video para el usuario %d: %v", userID, err)
+		if writeUploadCheckError(w, err) {
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		// El código de estado podría depender del tipo de error (ej. BadRequest por tipo no soportado)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -191,13 +197,7 @@ func (h *VideoHandler) UploadVideo(w http.ResponseWriter, r *http.Request) {
 // StreamVideoMasterPlaylist sirve el manifiesto HLS maestro para un video.
 // La ruta esperada es /api/v1/videos/stream/{contentID}/master.m3u8?token=<jwt>
 func (h *VideoHandler) StreamVideoMasterPlaylist(w http.ResponseWriter, r *http.Request) {
-	// Extracción simple de contentID del path:
-	// /api/v1/videos/stream/{contentID}/master.m3u8
-	pathSegments := strings.Split(r.URL.Path, "/")
-	var contentID string
-	if len(pathSegments) >= 6 && pathSegments[1] == "api" && pathSegments[2] == "v1" && pathSegments[3] == "videos" && pathSegments[4] == "stream" && pathSegments[6] == "master.m3u8" {
-		contentID = pathSegments[5]
-	}
+	contentID := mux.Vars(r)["contentID"]
 
 	if contentID == "" {
 		logger.Warnf("StreamVideoMasterPlaylist.ExtractParam", "No se pudo extraer contentID del path: %s", r.URL.Path)
@@ -327,16 +327,10 @@ func extractRelativePathFromGCS(fullGCSPath, baseGCSPath string) string {
 // StreamVideoVariant sirve un manifiesto de calidad HLS o un segmento de video.
 // La ruta esperada es /api/v1/videos/stream/{contentID}/{quality}/{fileName}?token=<jwt>
 func (h *VideoHandler) StreamVideoVariant(w http.ResponseWriter, r *http.Request) {
-	// Path: /api/v1/videos/stream/{contentID}/{quality}/{fileName}
-	pathSegments := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/videos/stream/"), "/")
-	if len(pathSegments) < 3 { // Esperamos contentID, quality, fileName (fileName puede tener '/')
-		logger.Warnf("StreamVideoVariant.ExtractParam", "Path inválido, no se pudieron extraer parámetros: %s. Segments: %v", r.URL.Path, pathSegments)
-		http.Error(w, "Ruta inválida para variante de video.", http.StatusBadRequest)
-		return
-	}
-	contentID := pathSegments[0]
-	quality := pathSegments[1]                      // "1080p", "720p", "480p"
-	fileName := strings.Join(pathSegments[2:], "/") // Reconstruye el resto del path como fileName
+	vars := mux.Vars(r)
+	contentID := vars["contentID"]
+	quality := vars["quality"]   // "1080p", "720p", "480p"
+	fileName := vars["fileName"] // Puede contener '/'
 
 	if contentID == "" || quality == "" || fileName == "" {
 		logger.Warnf("StreamVideoVariant.ExtractParam", "Parámetros de path incompletos: contentID='%s', quality='%s', fileName='%s'", contentID, quality, fileName)
@@ -361,22 +355,15 @@ func (h *VideoHandler) StreamVideoVariant(w http.ResponseWriter, r *http.Request
 	gcsObjectPath := fmt.Sprintf("videos/%s/%s/%s", contentID, quality, fileName)
 	logger.Infof("StreamVideoVariant", "Solicitud para servir variante: GCS Path %s", gcsObjectPath)
 
-	fileBytes, err := cloudclient.DownloadFile(r.Context(), gcsObjectPath) // Asumiendo que DownloadFile devuelve []byte, error
+	fileBytes, err := h.store.Get(r.Context(), gcsObjectPath)
 	if err != nil {
-		// Intentar detectar error de "objeto no encontrado" de GCS
-		var gae *gcsErrors.Error
-		if errors.As(err, &gae) && gae.Code == http.StatusNotFound {
-			logger.Warnf("StreamVideoVariant.GCS", "Archivo no encontrado en GCS: %s. API Error: %v", gcsObjectPath, gae)
-			http.NotFound(w, r)
-			return
-		} else if strings.Contains(strings.ToLower(err.Error()), "object") && strings.Contains(strings.ToLower(err.Error()), "not found") {
-			// Fallback para mensajes de error genéricos de "no encontrado"
-			logger.Warnf("StreamVideoVariant.GCS", "Archivo no encontrado en GCS (string match): %s. Error: %v", gcsObjectPath, err)
+		if errors.Is(err, storage.ErrNotExist) {
+			logger.Warnf("StreamVideoVariant.Storage", "Archivo no encontrado en el almacenamiento: %s", gcsObjectPath)
 			http.NotFound(w, r)
 			return
 		}
 
-		logger.Errorf("StreamVideoVariant.GCS", "Error descargando archivo %s de GCS: %v", gcsObjectPath, err)
+		logger.Errorf("StreamVideoVariant.Storage", "Error descargando archivo %s del almacenamiento: %v", gcsObjectPath, err)
 		http.Error(w, "Error interno al obtener el archivo de video.", http.StatusInternalServerError)
 		return
 	}
@@ -398,3 +385,36 @@ func (h *VideoHandler) StreamVideoVariant(w http.ResponseWriter, r *http.Request
 	}
 	logger.Infof("StreamVideoVariant", "Archivo %s servido (Content-Type: %s, Size: %d)", gcsObjectPath, contentType, len(fileBytes))
 }
+
+// DownloadOriginalVideo sirve el archivo original de un video con soporte de Range, para
+// reanudar descargas y reproducir el MP4 directamente. No depende de la transcodificación.
+// La ruta esperada es /api/v1/videos/{contentID}/original?token=<jwt>[&download=1]; va detrás
+// de AuthMiddleware, que acepta el token en la query y rechaza las cuentas suspendidas o
+// desactivadas y las suplantaciones terminadas.
+func (h *VideoHandler) DownloadOriginalVideo(w http.ResponseWriter, r *http.Request) {
+	contentID := mux.Vars(r)["contentID"]
+
+	multimedia, err := queries.GetMultimediaByContentID(h.db, contentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			apperrors.Write(w, apperrors.MediaNotFound, "Video no encontrado")
+			return
+		}
+		logger.Errorf("DownloadOriginalVideo.DB", "Error obteniendo video de DB para contentID %s: %v", contentID, err)
+		apperrors.Write(w, apperrors.Internal, "Error al obtener la información del video")
+		return
+	}
+	// Un archivo en cuarentena solo se descarga desde el panel de administración.
+	if multimedia.ProcessingStatus.String == services.ProcessingStatusQuarantined {
+		logger.Warnf("DownloadOriginalVideo", "Descarga rechazada del video en cuarentena %s", contentID)
+		apperrors.Write(w, apperrors.MediaNotFound, "Video no encontrado")
+		return
+	}
+
+	if r.URL.Query().Get("download") == "1" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", multimedia.FileName))
+	}
+	w.Header().Set("Access-Control-Allow-Origin", "*") // CORS, como el resto del streaming
+	w.Header().Set("Access-Control-Expose-Headers", "Content-Range, Accept-Ranges, Content-Length, ETag")
+	serveObjectRange(w, r, h.store, multimedia.FileName, "")
+}
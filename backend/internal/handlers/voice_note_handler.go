@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+const voiceNoteHandlerComponent = "VOICE_NOTE_HANDLER"
+
+// UploadVoiceNote recibe una nota de voz (campo "audio" del form-data, WebM o M4A) y responde
+// 201 con su fileName, duración en segundos y la URL de reproducción. El fileName es el
+// mediaId que se envía en chat/send_message.
+func (h *AudioHandler) UploadVoiceNote(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+
+	file, _, err := r.FormFile("audio")
+	if err != nil {
+		apperrors.Write(w, apperrors.MissingFields, "Se requiere el archivo 'audio'")
+		return
+	}
+	defer file.Close()
+
+	details, err := h.audioService.ProcessAndUploadVoiceNote(r.Context(), userID, file)
+	if err != nil {
+		logger.Warnf(voiceNoteHandlerComponent, "No se pudo subir la nota de voz del usuario %d: %v", userID, err)
+		apperrors.WriteError(w, err, "Error al procesar la nota de voz")
+		return
+	}
+	// La URL de reproducción usa la misma versión de la API que la subida.
+	details.URL = strings.TrimSuffix(r.URL.Path, "/upload") + "/" + details.FileName
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(details)
+}
+
+// StreamVoiceNote reproduce una nota de voz con soporte de Range para que el cliente pueda
+// saltar a cualquier punto. Acepta el token en la query (?token=) para usarse como src de un
+// elemento <audio>. Solo la pueden reproducir quien la subió y los participantes del chat
+// donde se envió.
+func (h *AudioHandler) StreamVoiceNote(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDContextKey).(int64)
+	if !ok {
+		apperrors.Write(w, apperrors.Unauthenticated, "No se pudo obtener el ID del usuario desde el token")
+		return
+	}
+	fileName := mux.Vars(r)["filename"]
+
+	media, err := h.audioService.GetVoiceNote(userID, fileName)
+	if err != nil {
+		logger.Warnf(voiceNoteHandlerComponent, "Usuario %d sin acceso a la nota de voz %s: %v", userID, fileName, err)
+		apperrors.WriteError(w, err, "Error al obtener la nota de voz")
+		return
+	}
+
+	// Las notas de voz no cambian: el cliente puede cachearlas, pero solo en privado.
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	serveObjectRange(w, r, h.store, services.VoiceNoteObjectPath(media.FileName), "")
+}
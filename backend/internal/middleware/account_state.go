@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// accountStateCacheTTL es cuánto tiempo se recuerdan el estado y el rol de una cuenta. Evita
+// consultar la base de datos en cada petición; una suspensión, desactivación o cambio de rol
+// hecho desde otra instancia tarda como mucho este tiempo en aplicarse a los tokens ya
+// emitidos.
+const accountStateCacheTTL = time.Minute
+
+type accountStateEntry struct {
+	state     models.AccountState
+	found     bool
+	expiresAt time.Time
+}
+
+var (
+	accountStateMu    sync.Mutex
+	accountStateCache = make(map[int64]accountStateEntry)
+)
+
+// accountState devuelve el estado, el rol y la institución actuales de la cuenta. ok es false si no se pudo
+// consultar o el usuario no existe; en ese caso se usan los datos del token: la suspensión y
+// la desactivación ya se aplican al iniciar sesión.
+func accountState(userID int64) (models.AccountState, bool) {
+	if queries.DB == nil {
+		return models.AccountState{}, false
+	}
+
+	now := time.Now()
+	accountStateMu.Lock()
+	entry, ok := accountStateCache[userID]
+	accountStateMu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.state, entry.found
+	}
+
+	state, err := queries.GetAccountState(userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Errorf("AUTH", "AuthMiddleware: %v", err)
+		return models.AccountState{}, false
+	}
+
+	accountStateMu.Lock()
+	defer accountStateMu.Unlock()
+	// Se descartan las entradas vencidas para que el mapa no crezca sin límite.
+	if len(accountStateCache) > 10000 {
+		for id, e := range accountStateCache {
+			if now.After(e.expiresAt) {
+				delete(accountStateCache, id)
+			}
+		}
+	}
+	accountStateCache[userID] = accountStateEntry{state: state, found: err == nil, expiresAt: now.Add(accountStateCacheTTL)}
+	return state, err == nil
+}
+
+// ForgetAccountState descarta el estado, el rol y la institución recordados del usuario. Se
+// llama al suspender, desactivar, reactivar o cambiar el rol o la institución de una cuenta
+// para que el cambio se aplique de inmediato en esta instancia.
+func ForgetAccountState(userID int64) {
+	accountStateMu.Lock()
+	delete(accountStateCache, userID)
+	accountStateMu.Unlock()
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 )
 
@@ -18,21 +19,21 @@ func AdminMiddleware(db *sql.DB) func(http.Handler) http.Handler {
 			userID, ok := r.Context().Value(UserIDContextKey).(int64)
 			if !ok {
 				logger.Warn("ADMIN_AUTH", "AdminMiddleware: UserID no encontrado en el contexto")
-				http.Error(w, "Acceso no autorizado", http.StatusForbidden)
+				apperrors.Write(w, apperrors.Forbidden, "Acceso no autorizado")
 				return
 			}
 
 			roleID, ok := r.Context().Value(RoleIDContextKey).(int64)
 			if !ok {
 				logger.Warn("ADMIN_AUTH", "AdminMiddleware: RoleID no encontrado en el contexto")
-				http.Error(w, "Acceso no autorizado", http.StatusForbidden)
+				apperrors.Write(w, apperrors.Forbidden, "Acceso no autorizado")
 				return
 			}
 
 			// 2. Verificar el rol de administrador
 			if roleID != int64(models.RoleAdmin) {
 				logger.Warnf("ADMIN_AUTH", "Intento de acceso de no-administrador (UserID: %d, RoleID: %d)", userID, roleID)
-				http.Error(w, "Acceso prohibido: se requiere rol de administrador", http.StatusForbidden)
+				apperrors.Write(w, apperrors.AdminRequired, "Acceso prohibido: se requiere rol de administrador")
 				return
 			}
 
@@ -41,12 +42,12 @@ func AdminMiddleware(db *sql.DB) func(http.Handler) http.Handler {
 			valid, err := queries.IsSessionValid(db, userID, token)
 			if err != nil {
 				// El error ya fue logueado dentro de IsSessionValid
-				http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+				apperrors.Write(w, apperrors.Internal, "Error interno del servidor")
 				return
 			}
 			if !valid {
 				logger.Warnf("ADMIN_AUTH", "Intento de acceso con token inválido o sesión cerrada (UserID: %d)", userID)
-				http.Error(w, "Sesión inválida o expirada", http.StatusUnauthorized)
+				apperrors.Write(w, apperrors.SessionExpired, "Sesión inválida o expirada")
 				return
 			}
 
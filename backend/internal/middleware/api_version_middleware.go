@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIVersionContextKey guarda en el contexto la versión de la API por la que entró la petición.
+const APIVersionContextKey contextKey = "apiVersion"
+
+// Versiones de la API REST
+const (
+	APIVersion1 = "v1"
+	APIVersion2 = "v2"
+)
+
+// Deprecation describe un prefijo de rutas obsoleto y su sustituto.
+type Deprecation struct {
+	// Prefix es el prefijo obsoleto (ej. "/api") y SuccessorPrefix el que lo reemplaza
+	// (ej. "/api/v1"); se usan para construir el header Link de cada petición.
+	Prefix          string
+	SuccessorPrefix string
+	// Sunset es la fecha a partir de la cual el prefijo dejará de responder (cero = sin fecha).
+	Sunset time.Time
+}
+
+// APIVersionMiddleware marca las peticiones con su versión de la API (header API-Version y
+// contexto) y, si el prefijo está obsoleto, añade los headers Deprecation, Sunset y Link
+// (RFC 8594) para que los clientes migren a la ruta equivalente de la versión sucesora.
+func APIVersionMiddleware(version string, deprecation *Deprecation) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("API-Version", version)
+			if deprecation != nil {
+				w.Header().Set("Deprecation", "true")
+				if !deprecation.Sunset.IsZero() {
+					w.Header().Set("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+				}
+				if deprecation.SuccessorPrefix != "" {
+					successor := deprecation.SuccessorPrefix + strings.TrimPrefix(r.URL.Path, deprecation.Prefix)
+					w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), APIVersionContextKey, version)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// APIVersion devuelve la versión de la API de la petición (v1 si no pasó por el middleware).
+// Permite que un mismo handler sirva varias versiones adaptando la forma de la petición o
+// de la respuesta.
+func APIVersion(r *http.Request) string {
+	if version, ok := r.Context().Value(APIVersionContextKey).(string); ok {
+		return version
+	}
+	return APIVersion1
+}
@@ -3,9 +3,14 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/auth"
 	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 )
 
@@ -17,8 +22,13 @@ const (
 	RoleIDContextKey contextKey = "roleID"
 )
 
+// ImpersonatedByHeader se añade a las respuestas de las peticiones hechas con un token de
+// suplantación, con el ID del administrador, para que el cliente muestre el aviso.
+const ImpersonatedByHeader = "X-Impersonated-By"
+
 // AuthMiddleware valida el token JWT de las peticiones entrantes
 func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
+	tenants := services.NewTenantResolver(cfg)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			var token string
@@ -40,7 +50,7 @@ func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 			// Si no se encuentra el token en ningún lugar
 			if token == "" {
 				logger.Warn("AUTH", "AuthMiddleware: No token found in Authorization header or query parameter")
-				http.Error(w, "Missing token", http.StatusUnauthorized)
+				apperrors.Write(w, apperrors.MissingToken, "Missing token")
 				return
 			}
 
@@ -48,18 +58,75 @@ func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 			claims, err := auth.ValidateJWT(token, []byte(cfg.JwtSecret))
 			if err != nil {
 				logger.Warnf("AUTH", "AuthMiddleware: Invalid token: %v", err)
-				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				apperrors.Write(w, apperrors.InvalidToken, "Invalid token")
+				return
+			}
+
+			// Un token de suplantación solo vale mientras la suplantación registrada siga
+			// activa: terminarla desde el panel lo revoca en todas las instancias.
+			if claims.ImpersonatorID != 0 && !impersonationActive(claims) {
+				logger.Warnf("AUTH", "AuthMiddleware: Impersonation token %s for user %d is no longer active", claims.ID, claims.UserID)
+				apperrors.Write(w, apperrors.SessionExpired, "Impersonation ended")
+				return
+			}
+
+			// Las cuentas suspendidas o desactivadas no pueden usar la API aunque su token siga
+			// vigente, y un cambio de rol o de institución se aplica sin esperar a un token nuevo.
+			roleID, accountTenant := int64(claims.RoleID), claims.TenantID
+			if state, ok := accountState(claims.UserID); ok {
+				switch models.UserStatus(state.StatusAuthorizedId) {
+				case models.UserStatusSuspended:
+					logger.Warnf("AUTH", "AuthMiddleware: User %d is suspended", claims.UserID)
+					apperrors.Write(w, apperrors.AccountSuspended, "Account suspended")
+					return
+				case models.UserStatusDeactivated:
+					logger.Warnf("AUTH", "AuthMiddleware: User %d is deactivated", claims.UserID)
+					apperrors.Write(w, apperrors.AccountDeactivated, "Account deactivated")
+					return
+				}
+				roleID, accountTenant = int64(state.RoleId), state.TenantId
+			}
+
+			// Institución de la petición (docs/multi_institucion.md): la de la cuenta o, si no tiene,
+			// la del subdominio.
+			tenantID, err := requestTenant(tenants, r, accountTenant)
+			if err != nil {
+				logger.Warnf("AUTH", "AuthMiddleware: User %d of tenant %d on another tenant's subdomain", claims.UserID, accountTenant)
+				apperrors.WriteError(w, err, "Tenant mismatch")
 				return
 			}
 
+			setRequestLogUser(r, claims.UserID, claims.ImpersonatorID)
+
 			// Agregar información del usuario al contexto usando claves tipadas
 			ctx := context.WithValue(r.Context(), UserIDContextKey, claims.UserID)
-			ctx = context.WithValue(ctx, RoleIDContextKey, int64(claims.RoleID))
+			ctx = context.WithValue(ctx, RoleIDContextKey, roleID)
+			if tenants.Enabled() {
+				ctx = context.WithValue(ctx, TenantIDContextKey, tenantID)
+			}
+			if claims.ImpersonatorID != 0 {
+				ctx = context.WithValue(ctx, auth.ImpersonatorKey, claims.ImpersonatorID)
+				w.Header().Set(ImpersonatedByHeader, strconv.FormatInt(claims.ImpersonatorID, 10))
+			}
 
-			logger.Infof("AUTH", "AuthMiddleware: User %d authenticated with Role %d", claims.UserID, claims.RoleID)
+			logger.Infof("AUTH", "AuthMiddleware: User %d authenticated with Role %d", claims.UserID, roleID)
 
 			// Continuar con el siguiente handler
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// impersonationActive indica si la suplantación del token sigue activa. No se guarda en caché
+// para que terminarla tenga efecto en la siguiente petición.
+func impersonationActive(claims *auth.Claims) bool {
+	impersonationID, err := claims.ImpersonationID()
+	if err != nil || queries.DB == nil {
+		return false
+	}
+	active, err := queries.IsImpersonationActive(impersonationID, claims.ImpersonatorID, claims.UserID)
+	if err != nil {
+		logger.Errorf("AUTH", "AuthMiddleware: %v", err)
+	}
+	return active
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/etag"
+)
+
+// Valores de Cache-Control para las rutas con ETag.
+const (
+	// CacheControlPrivate es para respuestas propias del usuario (su perfil): el cliente
+	// guarda la respuesta pero la revalida en cada uso.
+	CacheControlPrivate = "private, no-cache"
+	// CacheControlCatalog es para catálogos que casi no cambian (nacionalidades, carreras...).
+	CacheControlCatalog = "public, max-age=300"
+)
+
+// ETag añade a una ruta GET un ETag calculado con el hash de la respuesta y atiende
+// If-None-Match: si el cliente ya tiene esa versión responde 304 sin cuerpo. La consulta a
+// la base de datos se hace igual, pero se ahorra enviar la respuesta, que es lo que cuesta en
+// los clientes móviles. cacheControl se envía tanto en la respuesta completa como en el 304.
+func ETag(cacheControl string) func(http.HandlerFunc) http.Handler {
+	return func(next http.HandlerFunc) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buffer := &etagResponseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(buffer, r)
+
+			header := w.Header()
+			if buffer.status != http.StatusOK {
+				w.WriteHeader(buffer.status)
+				w.Write(buffer.body.Bytes())
+				return
+			}
+			tag := header.Get("ETag")
+			if tag == "" {
+				tag = etag.FromBytes(buffer.body.Bytes())
+				header.Set("ETag", tag)
+			}
+			if cacheControl != "" {
+				header.Set("Cache-Control", cacheControl)
+			}
+			if etag.Matches(r.Header.Get("If-None-Match"), tag) {
+				header.Del("Content-Type")
+				header.Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write(buffer.body.Bytes())
+		})
+	}
+}
+
+// etagResponseWriter retiene la respuesta del handler para calcular su ETag antes de enviarla.
+type etagResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *etagResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+}
+
+func (w *etagResponseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	return w.body.Write(p)
+}
+
+// Unwrap expone el ResponseWriter original a http.ResponseController.
+func (w *etagResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
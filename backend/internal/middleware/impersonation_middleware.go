@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/auth"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// NoImpersonation rechaza con 403 (USR_008) las peticiones hechas con un token de
+// suplantación. Protege las rutas de la cuenta que el soporte no necesita para reproducir un
+// problema y que sacarían datos personales o tomarían decisiones por el usuario: exportación
+// de datos, de chats y del CV, importación del CV, borrado de la cuenta, privacidad,
+// dispositivos push y verificación de estudiante. Debe usarse DESPUÉS de AuthMiddleware.
+func NoImpersonation(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if impersonatorID := auth.ImpersonatorFromContext(r.Context()); impersonatorID != 0 {
+			userID, _ := r.Context().Value(UserIDContextKey).(int64)
+			logger.Warnf("AUTH", "Suplantación rechazada en %s %s (UserID: %d, administrador: %d)", r.Method, r.URL.Path, userID, impersonatorID)
+			apperrors.Write(w, apperrors.ImpersonationNotAllowed, "Esta operación no está permitida durante una suplantación")
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+)
+
+// ReadOnly rechaza con 503 (GEN_011, con Retry-After) las peticiones que modifican datos
+// mientras la BD está en modo solo lectura (ver docs/modo_solo_lectura.md). GET, HEAD y
+// OPTIONS se atienden con normalidad. Sin este middleware las escrituras fallarían igual en
+// el conector de la BD, pero a mitad del handler y con el error que cada uno decida.
+func ReadOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !db.ReadOnly() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(db.CurrentReadOnlyState().RetryAfterSeconds))
+		apperrors.Write(w, db.ErrReadOnly.Code, db.ErrReadOnly.Message)
+	})
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const recoveryComponent = "RECOVERY"
+
+// Recovery convierte un pánico en un handler en una respuesta 500 (GEN_005), con la traza en
+// el log. Va dentro de RequestLogging para que la petición quede registrada como error y se
+// cuente en las métricas de pánicos de su ruta.
+//
+// http.ErrAbortHandler se relanza: es la forma de abortar una respuesta a propósito.
+func Recovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &panicRecorder{ResponseWriter: w}
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(rec)
+			}
+
+			logger.Errorf(recoveryComponent, "Pánico recuperado en %s %s: %v\n%s", r.Method, r.URL.Path, rec, debug.Stack())
+			if entry, ok := r.Context().Value(requestLogContextKey).(*requestLogEntry); ok {
+				entry.panicked = true
+			}
+			// Si la respuesta ya empezó no se puede cambiar el estado: el cliente la recibe
+			// incompleta.
+			if !recorder.wroteHeader {
+				apperrors.Write(w, apperrors.Internal, "Error interno del servidor")
+			}
+		}()
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+// panicRecorder recuerda si el handler ya empezó a escribir la respuesta.
+type panicRecorder struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (p *panicRecorder) WriteHeader(status int) {
+	p.wroteHeader = true
+	p.ResponseWriter.WriteHeader(status)
+}
+
+func (p *panicRecorder) Write(b []byte) (int, error) {
+	p.wroteHeader = true
+	return p.ResponseWriter.Write(b)
+}
+
+// Flush permite el envío progresivo a través del recorder.
+func (p *panicRecorder) Flush() {
+	if flusher, ok := p.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack permite actualizar la conexión a WebSocket a través del recorder (gorilla/websocket
+// no usa http.ResponseController).
+func (p *panicRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := p.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("el ResponseWriter no implementa http.Hijacker")
+	}
+	p.wroteHeader = true
+	return hijacker.Hijack()
+}
+
+// Unwrap expone el ResponseWriter original a http.ResponseController.
+func (p *panicRecorder) Unwrap() http.ResponseWriter {
+	return p.ResponseWriter
+}
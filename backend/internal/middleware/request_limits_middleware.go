@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// requestLimitsContextKey guarda el requestLimits de la petición para que LargeTransfer pueda
+// ampliarlo en las rutas de subida y descarga de archivos.
+const requestLimitsContextKey contextKey = "requestLimits"
+
+// requestLimits son los límites de la petición y el resultado de leer su cuerpo.
+type requestLimits struct {
+	maxBody         int64
+	transferTimeout time.Duration // Plazo de las rutas con LargeTransfer
+	read            int64
+	failure         apperrors.Code // BodyTooLarge o Timeout si la lectura del cuerpo falló por un límite
+}
+
+// RequestLimits limita el cuerpo de todas las peticiones a API_MAX_BODY_SIZE y fija los
+// plazos de lectura y escritura de la conexión (API_READ_TIMEOUT y API_WRITE_TIMEOUT). Se
+// registra con Use en el router principal; las rutas que necesitan más (subidas y descargas
+// de archivos) lo amplían con LargeTransfer.
+//
+// Si la lectura del cuerpo falla por superar el tamaño o el plazo y el handler responde con
+// un error, la respuesta se sustituye por 413 (GEN_007) o 408 (GEN_008). Así los handlers no
+// tienen que distinguir estos casos de un cuerpo mal formado.
+func RequestLimits(cfg *config.Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limits := &requestLimits{maxBody: cfg.APIMaxBodySize, transferTimeout: cfg.APIUploadTimeout}
+			setConnectionDeadlines(w, cfg.APIReadTimeout, cfg.APIWriteTimeout)
+
+			writer := &limitsResponseWriter{ResponseWriter: w, limits: limits}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = &limitedBody{ReadCloser: r.Body, limits: limits, contentLength: r.ContentLength}
+			}
+			next.ServeHTTP(writer, r.WithContext(context.WithValue(r.Context(), requestLimitsContextKey, limits)))
+		})
+	}
+}
+
+// LargeTransfer amplía los límites de una ruta de subida o descarga de archivos: el cuerpo
+// puede llegar a maxBody bytes (0 = el máximo general) y los plazos de lectura y escritura
+// pasan a API_UPLOAD_TIMEOUT.
+func LargeTransfer(maxBody int64) func(http.HandlerFunc) http.Handler {
+	return func(next http.HandlerFunc) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limits, ok := r.Context().Value(requestLimitsContextKey).(*requestLimits); ok {
+				if maxBody > 0 {
+					limits.maxBody = maxBody
+				}
+				setConnectionDeadlines(w, limits.transferTimeout, limits.transferTimeout)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setConnectionDeadlines fija los plazos de la conexión desde ahora. Un plazo 0 no se toca.
+func setConnectionDeadlines(w http.ResponseWriter, read, write time.Duration) {
+	rc := http.NewResponseController(w)
+	now := time.Now()
+	if read > 0 {
+		if err := rc.SetReadDeadline(now.Add(read)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			logger.Warnf("LIMITS", "No se pudo fijar el plazo de lectura: %v", err)
+		}
+	}
+	if write > 0 {
+		if err := rc.SetWriteDeadline(now.Add(write)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			logger.Warnf("LIMITS", "No se pudo fijar el plazo de escritura: %v", err)
+		}
+	}
+}
+
+// limitedBody cuenta los bytes leídos y falla al superar el máximo. El máximo se consulta en
+// cada lectura porque LargeTransfer puede ampliarlo después de envolver el cuerpo.
+type limitedBody struct {
+	io.ReadCloser
+	limits        *requestLimits
+	contentLength int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	limits := b.limits
+	if limits.failure != "" {
+		return 0, b.failureError()
+	}
+	if limits.maxBody > 0 {
+		// Content-Length permite rechazar el cuerpo sin leerlo
+		if b.contentLength > limits.maxBody {
+			limits.failure = apperrors.BodyTooLarge
+			return 0, b.failureError()
+		}
+		if remaining := limits.maxBody - limits.read + 1; int64(len(p)) > remaining {
+			p = p[:remaining]
+		}
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	limits.read += int64(n)
+	if limits.maxBody > 0 && limits.read > limits.maxBody {
+		limits.failure = apperrors.BodyTooLarge
+		return n - int(limits.read-limits.maxBody), b.failureError()
+	}
+	if err != nil && isTimeoutError(err) {
+		limits.failure = apperrors.Timeout
+	}
+	return n, err
+}
+
+func (b *limitedBody) failureError() error {
+	if b.limits.failure == apperrors.Timeout {
+		return os.ErrDeadlineExceeded
+	}
+	return &http.MaxBytesError{Limit: b.limits.maxBody}
+}
+
+func isTimeoutError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, os.ErrDeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// limitsResponseWriter sustituye la respuesta de error del handler por 413 o 408 cuando la
+// lectura del cuerpo falló por un límite.
+type limitsResponseWriter struct {
+	http.ResponseWriter
+	limits      *requestLimits
+	wroteHeader bool
+	replaced    bool
+}
+
+func (w *limitsResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if failure := w.limits.failure; failure != "" && status >= http.StatusBadRequest {
+		w.replaced = true
+		// El resto del cuerpo no se lee: la conexión no se puede reutilizar
+		w.Header().Set("Connection", "close")
+		message := "La petición no se terminó de enviar a tiempo"
+		if failure == apperrors.BodyTooLarge {
+			message = fmt.Sprintf("El cuerpo de la petición supera el máximo de %d bytes", w.limits.maxBody)
+		}
+		apperrors.Write(w.ResponseWriter, failure, message)
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *limitsResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.replaced {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush permite el envío progresivo (streaming de video) a través del writer.
+func (w *limitsResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap expone el ResponseWriter original a http.ResponseController.
+func (w *limitsResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/httpmetrics"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+const requestLogComponent = "HTTP"
+
+// unmatchedRoute agrupa en las métricas las peticiones que no coinciden con ninguna ruta, para
+// no crear una serie por cada URL desconocida.
+const unmatchedRoute = "(sin ruta)"
+
+// requestLogContextKey guarda el requestLogEntry de la petición. Los middlewares internos lo
+// completan (ruta y usuario) porque el middleware de log envuelve al router y no ve el
+// contexto que ellos crean.
+const requestLogContextKey contextKey = "requestLog"
+
+type requestLogEntry struct {
+	route          string
+	userID         int64
+	impersonatorID int64 // Administrador que suplanta al usuario (0 = ninguno)
+	panicked       bool  // El handler entró en pánico (ver Recovery)
+}
+
+// RequestLogging registra cada petición de la API (método, plantilla de ruta, estado, bytes,
+// latencia y usuario) en el log y en metrics. Debe envolver al router principal, que a su vez
+// usa RequestRoute para informar la plantilla de ruta.
+//
+// Las respuestas 5xx y las que superan REQUEST_LOG_SLOW_THRESHOLD se registran siempre; el
+// resto con probabilidad REQUEST_LOG_SAMPLE_RATE, salvo las hechas con un token de
+// suplantación, que también se registran siempre. Las rutas de REQUEST_LOG_EXCLUDE (p. ej.
+// las sondas de salud) no generan log ni métricas.
+func RequestLogging(cfg *config.Config, metrics *httpmetrics.Registry) func(http.Handler) http.Handler {
+	exact, prefixes := parseRequestLogExclude(cfg.RequestLogExclude)
+	sampleRate := cfg.RequestLogSampleRate
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isExcludedPath(r.URL.Path, exact, prefixes) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			entry := &requestLogEntry{}
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), requestLogContextKey, entry)))
+			latency := time.Since(start)
+
+			route := entry.route
+			if route == "" {
+				route = unmatchedRoute
+			}
+			if metrics != nil {
+				metrics.Observe(r.Method, route, recorder.status, recorder.bytes, latency)
+				if entry.panicked {
+					metrics.ObservePanic(r.Method, route)
+				}
+			}
+			if !cfg.RequestLogEnabled {
+				return
+			}
+
+			slow := cfg.RequestLogSlowThreshold > 0 && latency >= cfg.RequestLogSlowThreshold
+			if recorder.status < 500 && !slow && !entry.panicked && entry.impersonatorID == 0 && (sampleRate <= 0 || (sampleRate < 1 && rand.Float64() >= sampleRate)) {
+				return
+			}
+			message := formatRequestLog(r, route, entry, recorder, latency, slow)
+			switch {
+			case recorder.status >= 500 || entry.panicked:
+				logger.Error(requestLogComponent, message)
+			case slow || recorder.status >= 400:
+				logger.Warn(requestLogComponent, message)
+			default:
+				logger.Info(requestLogComponent, message)
+			}
+		})
+	}
+}
+
+// RequestRoute informa al middleware de log de la plantilla de la ruta que atendió la
+// petición. Se registra con Use en el router principal, así que solo se ejecuta cuando la
+// petición coincide con una ruta.
+func RequestRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if entry, ok := r.Context().Value(requestLogContextKey).(*requestLogEntry); ok {
+			if route := mux.CurrentRoute(r); route != nil {
+				if template, err := route.GetPathTemplate(); err == nil {
+					entry.route = template
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setRequestLogUser informa al middleware de log del usuario autenticado y, si la petición
+// usa un token de suplantación, del administrador que lo suplanta.
+func setRequestLogUser(r *http.Request, userID, impersonatorID int64) {
+	if entry, ok := r.Context().Value(requestLogContextKey).(*requestLogEntry); ok {
+		entry.userID = userID
+		entry.impersonatorID = impersonatorID
+	}
+}
+
+// formatRequestLog arma la línea del log en formato clave=valor.
+func formatRequestLog(r *http.Request, route string, entry *requestLogEntry, recorder *statusRecorder, latency time.Duration, slow bool) string {
+	var b strings.Builder
+	b.WriteString("method=" + r.Method)
+	b.WriteString(" route=" + route)
+	b.WriteString(" path=" + r.URL.Path)
+	b.WriteString(" status=" + strconv.Itoa(recorder.status))
+	b.WriteString(" bytes=" + strconv.FormatInt(recorder.bytes, 10))
+	b.WriteString(" latency_ms=" + strconv.FormatInt(latency.Milliseconds(), 10))
+	if entry.userID != 0 {
+		b.WriteString(" user_id=" + strconv.FormatInt(entry.userID, 10))
+	}
+	if entry.impersonatorID != 0 {
+		b.WriteString(" impersonator_id=" + strconv.FormatInt(entry.impersonatorID, 10))
+	}
+	if slow {
+		b.WriteString(" slow=true")
+	}
+	if entry.panicked {
+		b.WriteString(" panic=true")
+	}
+	return b.String()
+}
+
+// parseRequestLogExclude separa la lista en rutas exactas y prefijos (terminados en *).
+func parseRequestLogExclude(value string) (map[string]bool, []string) {
+	exact := make(map[string]bool)
+	var prefixes []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		switch {
+		case item == "":
+		case strings.HasSuffix(item, "*"):
+			prefixes = append(prefixes, strings.TrimSuffix(item, "*"))
+		default:
+			exact[item] = true
+		}
+	}
+	return exact, prefixes
+}
+
+func isExcludedPath(path string, exact map[string]bool, prefixes []string) bool {
+	if exact[path] {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// statusRecorder captura el código de estado y los bytes escritos en la respuesta.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status = status
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(p []byte) (int, error) {
+	s.wroteHeader = true
+	n, err := s.ResponseWriter.Write(p)
+	s.bytes += int64(n)
+	return n, err
+}
+
+// Flush permite el envío progresivo (streaming de video) a través del recorder.
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap expone el ResponseWriter original a http.ResponseController.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/validation"
+)
+
+// ValidateBody valida el cuerpo JSON de la petición contra el tipo que devuelve newBody (un
+// puntero a struct con etiquetas `validate`) antes de llamar al handler. Si no es JSON válido
+// responde GEN_001; si algún campo tiene un tipo incorrecto o incumple sus reglas, GEN_010
+// con el detalle de cada campo. El handler recibe el cuerpo intacto.
+//
+// Un cuerpo vacío se valida como un objeto vacío, de modo que solo falla si hay campos
+// obligatorios.
+func ValidateBody(newBody func() interface{}) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				// RequestLimits sustituye la respuesta por 413 o 408 si el cuerpo excedió el límite
+				apperrors.Write(w, apperrors.InvalidBody, "No se pudo leer el cuerpo de la petición")
+				return
+			}
+			r.Body.Close()
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			var payload json.RawMessage
+			if len(bytes.TrimSpace(body)) > 0 {
+				payload = body
+			}
+			if err := validation.Decode(payload, newBody()); err != nil {
+				var fieldErrs validation.Errors
+				if errors.As(err, &fieldErrs) {
+					apperrors.WriteFields(w, apperrors.InvalidFields, "El cuerpo de la petición no es válido", fieldErrs)
+					return
+				}
+				apperrors.Write(w, apperrors.InvalidBody, "El cuerpo de la petición no es JSON válido")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
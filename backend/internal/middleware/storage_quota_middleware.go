@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// StorageQuotaReserver reserva y devuelve espacio de la cuota de almacenamiento de un usuario.
+// Lo implementa services.IStorageQuotaService.
+type StorageQuotaReserver interface {
+	Reserve(userID, size int64) error
+	Charge(userID, size int64)
+	Release(userID, size int64)
+}
+
+// StorageQuota descuenta de la cuota de almacenamiento del usuario el cuerpo de una subida de
+// archivos. El Content-Length se reserva antes de llamar al handler (si no cabe se responde
+// 413, QUOTA_001, sin leer el cuerpo) y se devuelve si el handler no responde 2xx. Sin
+// Content-Length solo se comprueba que la cuota no esté agotada y se cargan los bytes leídos.
+// Debe usarse DESPUÉS de AuthMiddleware.
+func StorageQuota(quota StorageQuotaReserver) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := r.Context().Value(UserIDContextKey).(int64)
+			if !ok {
+				next(w, r)
+				return
+			}
+
+			reserved := max(r.ContentLength, 0)
+			if err := quota.Reserve(userID, reserved); err != nil {
+				var appErr *apperrors.Error
+				if !errors.As(err, &appErr) {
+					logger.Errorf("STORAGE_QUOTA", "Error reservando %d bytes para el usuario %d: %v", reserved, userID, err)
+				}
+				apperrors.WriteError(w, err, "Error al comprobar la cuota de almacenamiento")
+				return
+			}
+
+			body := &countingBody{ReadCloser: r.Body}
+			r.Body = body
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			succeeded := false
+			defer func() {
+				switch {
+				case !succeeded:
+					quota.Release(userID, reserved)
+				case r.ContentLength < 0:
+					quota.Charge(userID, body.read)
+				}
+			}()
+
+			next(recorder, r)
+			succeeded = recorder.status >= 200 && recorder.status < 300
+		}
+	}
+}
+
+// countingBody cuenta los bytes leídos del cuerpo de la petición.
+type countingBody struct {
+	io.ReadCloser
+	read int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	return n, err
+}
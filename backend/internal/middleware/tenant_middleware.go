@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+// Claves de contexto de la institución (ver docs/multi_institucion.md).
+const (
+	// TenantIDContextKey es la institución de la petición: la del subdominio y, tras
+	// AuthMiddleware, la de la cuenta si tiene una.
+	TenantIDContextKey contextKey = "tenantID"
+	// AdminTenantIDContextKey es la institución del administrador en las rutas /admin; 0 para
+	// los administradores globales.
+	AdminTenantIDContextKey contextKey = "adminTenantID"
+)
+
+// TenantID devuelve la institución de la petición, o 0 si no tiene: entonces el feed, la
+// búsqueda y los eventos no se filtran.
+func TenantID(ctx context.Context) int64 {
+	tenantID, _ := ctx.Value(TenantIDContextKey).(int64)
+	return tenantID
+}
+
+// AdminTenantID devuelve la institución del administrador de la petición, o 0 si es global.
+func AdminTenantID(ctx context.Context) int64 {
+	tenantID, _ := ctx.Value(AdminTenantIDContextKey).(int64)
+	return tenantID
+}
+
+// Tenant guarda en el contexto la institución del subdominio de la petición. Se aplica a
+// todas las rutas, también a las públicas; AuthMiddleware la sustituye después por la de la
+// cuenta.
+func Tenant(tenants *services.TenantResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !tenants.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := context.WithValue(r.Context(), TenantIDContextKey, tenants.FromRequest(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requestTenant resuelve la institución de una petición autenticada a partir de la de la
+// cuenta. La del subdominio se toma del contexto si Tenant ya la resolvió.
+func requestTenant(tenants *services.TenantResolver, r *http.Request, accountTenant int64) (int64, error) {
+	hostTenant, ok := r.Context().Value(TenantIDContextKey).(int64)
+	if !ok {
+		hostTenant = tenants.FromRequest(r)
+	}
+	return tenants.Resolve(accountTenant, hostTenant)
+}
+
+// tenantAdminRoutes son las rutas de /admin que puede usar un administrador de una
+// institución: la gestión de usuarios y las suplantaciones. El resto (métricas, catálogos,
+// retención, moderación de contenido...) afecta a toda la plataforma.
+var tenantAdminRoutes = []string{"/admin/users/", "/admin/impersonations/"}
+
+// TenantAdminScope limita a los administradores con institución a la gestión de los usuarios
+// de su institución: las demás rutas de /admin responden TEN_002 y las de un usuario de otra
+// institución, 404 como si no existiera. Los administradores sin institución no tienen límite.
+// Debe usarse DESPUÉS de AdminMiddleware.
+func TenantAdminScope(tenants *services.TenantResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !tenants.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			adminID, _ := r.Context().Value(UserIDContextKey).(int64)
+			state, ok := accountState(adminID)
+			if !ok {
+				// Sin la institución del administrador no se sabe si es global.
+				apperrors.Write(w, apperrors.Internal, "Error interno del servidor")
+				return
+			}
+			if state.TenantId == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			template := ""
+			if route := mux.CurrentRoute(r); route != nil {
+				template, _ = route.GetPathTemplate()
+			}
+			allowed := false
+			for _, prefix := range tenantAdminRoutes {
+				if strings.Contains(template, prefix) {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				logger.Warnf("ADMIN_AUTH", "Administrador %d de la institución %d sin acceso a %s", adminID, state.TenantId, template)
+				apperrors.Write(w, apperrors.TenantAdminRequired, "La operación requiere un administrador global")
+				return
+			}
+
+			if strings.Contains(template, "/admin/users/{id") {
+				if userID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64); err == nil {
+					if target, found := accountState(userID); found && target.TenantId != state.TenantId {
+						logger.Warnf("ADMIN_AUTH", "Administrador %d de la institución %d sin acceso al usuario %d", adminID, state.TenantId, userID)
+						apperrors.Write(w, apperrors.NotFound, "usuario no encontrado")
+						return
+					}
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), AdminTenantIDContextKey, state.TenantId)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
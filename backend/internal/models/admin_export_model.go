@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Conjuntos de datos exportables desde el panel de administración (GET /admin/exports/{dataset}).
+const (
+	AdminExportUsers           = "users"
+	AdminExportJobApplications = "job-applications"
+	AdminExportEventAttendance = "event-attendance"
+	AdminExportReviews         = "reputation-reviews"
+)
+
+// AdminExportFilter son los filtros de una exportación. Cada conjunto de datos usa solo los
+// suyos, con el mismo significado que en su listado; los campos vacíos no filtran.
+type AdminExportFilter struct {
+	Users           AdminUserSearch `json:"users"`                     // users: q, roleId y statusId
+	EventId         int64           `json:"eventId,omitempty"`         // job-applications, event-attendance y reputation-reviews
+	Status          string          `json:"status,omitempty"`          // job-applications: estado de la postulación
+	PostType        string          `json:"postType,omitempty"`        // job-applications: tipo de publicación
+	ReviewerId      int64           `json:"reviewerId,omitempty"`      // reputation-reviews
+	RevieweeId      int64           `json:"revieweeId,omitempty"`      // reputation-reviews
+	InteractionType string          `json:"interactionType,omitempty"` // reputation-reviews
+	// From y To acotan la fecha principal del conjunto: alta del usuario, fecha de postulación,
+	// fecha del evento o fecha de la reseña. To es exclusivo.
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+}
+
+// AdminExportRequest es una exportación pedida por un administrador.
+type AdminExportRequest struct {
+	Dataset string
+	Format  string   // csv o xlsx
+	Columns []string // Claves de columna en el orden deseado; vacío = todas
+	Filter  AdminExportFilter
+}
+
+// AdminExportDataset describe un conjunto de datos exportable y sus columnas.
+type AdminExportDataset struct {
+	Name    string              `json:"name"`
+	Columns []AdminExportColumn `json:"columns"`
+	Filters []string            `json:"filters"` // Parámetros de query que admite
+}
+
+// AdminExportColumn es una columna exportable.
+type AdminExportColumn struct {
+	Key    string `json:"key"`
+	Header string `json:"header"`
+}
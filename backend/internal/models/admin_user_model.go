@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Tipos de notificación (Event.EventType) de la gestión de usuarios. El servicio WebSocket
+// cierra las conexiones del usuario al recibirlas: una cuenta desactivada no puede
+// reconectarse y un cambio de rol se aplica al reconectar.
+const (
+	EventTypeAccountDeactivated = "ACCOUNT_DEACTIVATED"
+	EventTypeRoleChanged        = "ROLE_CHANGED"
+)
+
+// AdminUserSearch son los filtros de la búsqueda de usuarios del panel de administración.
+// Query busca en nombre, apellido, usuario, email y nombre de empresa; los campos vacíos no
+// filtran. TenantId limita la búsqueda a los usuarios de una institución: la del
+// administrador, si no es global.
+type AdminUserSearch struct {
+	Query    string
+	RoleId   int
+	StatusId int
+	TenantId int64
+}
+
+// UpdateUserRoleRequest es el cuerpo de PATCH /admin/users/{id}/role.
+type UpdateUserRoleRequest struct {
+	RoleId int `json:"roleId"`
+}
+
+// UpdateUserStatusRequest es el cuerpo de PATCH /admin/users/{id}/status.
+type UpdateUserStatusRequest struct {
+	StatusAuthorizedId int `json:"statusAuthorizedId"`
+}
+
+// UpdateUserTenantRequest es el cuerpo de PATCH /admin/users/{id}/tenant. TenantId es el Id
+// de la universidad; 0 quita la institución.
+type UpdateUserTenantRequest struct {
+	TenantId int64 `json:"tenantId"`
+}
+
+// DeactivateUserRequest es el cuerpo opcional de PATCH /admin/users/{id}/deactivate.
+type DeactivateUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+// UserDeactivation es la desactivación vigente de una cuenta.
+type UserDeactivation struct {
+	UserId           int64     `json:"userId"`
+	PreviousStatusId int       `json:"previousStatusId"` // Estado que se restaura al reactivar
+	DeactivatedBy    *int64    `json:"deactivatedBy,omitempty"`
+	Reason           string    `json:"reason,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+// AccountState es el estado, el rol y la institución actuales de una cuenta, que el
+// middleware de autenticación usa en lugar de los del token. TenantId es 0 si la cuenta no
+// tiene institución.
+type AccountState struct {
+	StatusAuthorizedId int
+	RoleId             int
+	TenantId           int64
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// EventTypeSystemAnnouncement es la notificación de un anuncio del sistema. Se guarda para los
+// usuarios que no lo recibieron en tiempo real y se elimina cuando el anuncio expira.
+const EventTypeSystemAnnouncement = "SYSTEM_ANNOUNCEMENT"
+
+// Estados de un anuncio del sistema.
+const (
+	AnnouncementStatusScheduled = "SCHEDULED" // Pendiente de enviar en ScheduledAt
+	AnnouncementStatusSent      = "SENT"      // Enviado a los conectados y guardado para el resto
+	AnnouncementStatusCancelled = "CANCELLED" // Cancelado antes de enviarse
+	AnnouncementStatusExpired   = "EXPIRED"   // Pasó ExpiresAt; sus notificaciones ya no se muestran
+)
+
+// Niveles de un anuncio del sistema, para que el cliente elija cómo mostrarlo.
+const (
+	AnnouncementLevelInfo     = "INFO"
+	AnnouncementLevelWarning  = "WARNING"
+	AnnouncementLevelCritical = "CRITICAL"
+)
+
+// SystemAnnouncement es un anuncio que un administrador envía a todos los usuarios o a los
+// de un rol.
+type SystemAnnouncement struct {
+	Id           int64      `json:"id"`
+	Title        string     `json:"title"`
+	Message      string     `json:"message"`
+	Level        string     `json:"level"`
+	TargetRoleId *int       `json:"targetRoleId,omitempty"` // nil: todos los usuarios
+	ScheduledAt  time.Time  `json:"scheduledAt"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	Status       string     `json:"status"`
+	CreatedBy    string     `json:"createdBy"`
+	SentAt       *time.Time `json:"sentAt,omitempty"`
+	DeliveredNow int        `json:"deliveredNow"` // Usuarios conectados que lo recibieron al enviarse
+	StoredCount  int        `json:"storedCount"`  // Notificaciones guardadas para los usuarios sin conexión
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// CreateAnnouncementRequest es el cuerpo de POST /admin/api/announcements. Sin ScheduledAt
+// el anuncio se envía en el momento.
+type CreateAnnouncementRequest struct {
+	Title        string     `json:"title"`
+	Message      string     `json:"message"`
+	Level        string     `json:"level"`
+	TargetRoleId *int       `json:"targetRoleId"`
+	ScheduledAt  *time.Time `json:"scheduledAt"`
+	ExpiresAt    *time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Acciones sensibles registradas en el AuditLog.
+const (
+	AuditActionLogin                  = "auth.login"
+	AuditActionLoginFailed            = "auth.login_failed"
+	AuditActionAdminLogin             = "auth.admin_login"
+	AuditActionPasswordResetRequested = "auth.password_reset_requested"
+	AuditActionPasswordResetCompleted = "auth.password_reset_completed"
+	AuditActionRoleChanged            = "user.role_changed"
+	AuditActionProfileDeleted         = "user.profile_deleted"
+	AuditActionDataExported           = "user.data_exported"
+	AuditActionChatExported           = "user.chat_exported"
+	AuditActionStudentVerified        = "user.student_verified"
+	AuditActionCompanyApproved        = "admin.company_approved"
+	AuditActionForceDisconnect        = "admin.force_disconnect"
+	AuditActionReportResolved         = "admin.report_resolved"
+	AuditActionReportDismissed        = "admin.report_dismissed"
+	AuditActionImageReviewed          = "admin.image_reviewed"
+	AuditActionReportContextCaptured  = "admin.report_context_captured"
+	AuditActionContentUnhidden        = "admin.content_unhidden"
+	AuditActionUserReinstated         = "admin.user_reinstated"
+	AuditActionUserStatusChanged      = "admin.user_status_changed"
+	AuditActionUserDeactivated        = "admin.user_deactivated"
+	AuditActionUserReactivated        = "admin.user_reactivated"
+	AuditActionUserTenantChanged      = "admin.user_tenant_changed"
+	AuditActionPasswordResetSent      = "admin.password_reset_sent"
+	AuditActionImpersonationStarted   = "admin.impersonation_started"
+	AuditActionImpersonationEnded     = "admin.impersonation_ended"
+	AuditActionCatalogItemCreated     = "admin.catalog_item_created"
+	AuditActionCatalogItemUpdated     = "admin.catalog_item_updated"
+	AuditActionCatalogItemDeleted     = "admin.catalog_item_deleted"
+	AuditActionSkillsRemapped         = "admin.skills_remapped"
+	AuditActionFilterRuleCreated      = "admin.filter_rule_created"
+	AuditActionFilterRuleUpdated      = "admin.filter_rule_updated"
+	AuditActionFilterRuleDeleted      = "admin.filter_rule_deleted"
+	AuditActionFilterRulesImported    = "admin.filter_rules_imported"
+	AuditActionFilterPolicyUpdated    = "admin.filter_policy_updated"
+	AuditActionStorageQuotaChanged    = "admin.storage_quota_changed"
+	AuditActionQuarantineDeleted      = "admin.quarantine_deleted"
+	AuditActionAnnouncementCreated    = "admin.announcement_created"
+	AuditActionAnnouncementCancelled  = "admin.announcement_cancelled"
+	AuditActionMaintenanceChanged     = "admin.maintenance_changed"
+	AuditActionDrainChanged           = "admin.drain_changed"
+	AuditActionDatasetExported        = "admin.dataset_exported"
+)
+
+// Tipos de objetivo de una entrada de auditoría.
+const (
+	AuditTargetUser          = "user"
+	AuditTargetReport        = "report"
+	AuditTargetImageReview   = "image_review"
+	AuditTargetContent       = "content"
+	AuditTargetFilterRule    = "content_filter_rule"
+	AuditTargetFilterPolicy  = "content_filter_policy"
+	AuditTargetImpersonation = "impersonation"
+	AuditTargetCatalog       = "catalog"
+	AuditTargetChat          = "chat"
+	AuditTargetAnnouncement  = "announcement"
+	AuditTargetService       = "service"
+	AuditTargetDataset       = "dataset"
+)
+
+// AuditLog es una entrada del registro de auditoría de acciones sensibles.
+type AuditLog struct {
+	Id         int64           `json:"id"`
+	ActorId    *int64          `json:"actorId,omitempty"`   // Usuario que realizó la acción (nil si es anónima)
+	ActorName  string          `json:"actorName,omitempty"` // Email o usuario del panel cuando no hay ActorId
+	Action     string          `json:"action"`
+	TargetType string          `json:"targetType,omitempty"`
+	TargetId   string          `json:"targetId,omitempty"`
+	IPAddress  string          `json:"ipAddress,omitempty"`
+	Metadata   json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+// AuditLogFilter son los filtros opcionales de la consulta del registro de auditoría.
+type AuditLogFilter struct {
+	ActorId    *int64
+	Action     string
+	TargetType string
+	TargetId   string
+	From       *time.Time
+	To         *time.Time
+}
+
+// PaginatedAuditLogResponse es la respuesta paginada del registro de auditoría.
+type PaginatedAuditLogResponse struct {
+	CurrentPage  int        `json:"currentPage"`
+	PageSize     int        `json:"pageSize"`
+	TotalPages   int        `json:"totalPages"`
+	TotalRecords int        `json:"totalRecords"`
+	Logs         []AuditLog `json:"logs"`
+}
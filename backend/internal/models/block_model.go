@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// BlockedUser es un usuario bloqueado por el usuario autenticado.
+type BlockedUser struct {
+	User      UserBaseInfo `json:"user"`
+	BlockedAt time.Time    `json:"blockedAt"`
+}
+
+// BlockedUsersResponse es la lista paginada de usuarios bloqueados.
+type BlockedUsersResponse struct {
+	Data       []BlockedUser     `json:"data"`
+	Pagination PaginationDetails `json:"pagination"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// Catalogs agrupa los catálogos con los que los clientes llenan los selectores del registro y
+// del perfil.
+type Catalogs struct {
+	Nationalities []Nationality `json:"nationalities"`
+	Universities  []University  `json:"universities"`
+	Degrees       []Degree      `json:"degrees"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Estados de un desafío (ENUM CommunityEvent.ChallengeStatus).
+const (
+	ChallengeStatusOpen       = "ABIERTO"
+	ChallengeStatusEvaluating = "EN_EVALUACION"
+	ChallengeStatusClosed     = "CERRADO"
+	ChallengeStatusCancelled  = "CANCELADO"
+)
+
+// Estados de una entrega (ENUM ChallengeSubmission.Status).
+const (
+	SubmissionStatusSubmitted = "ENVIADA"
+	SubmissionStatusReviewing = "EN_REVISION"
+	SubmissionStatusApproved  = "APROBADA"
+	SubmissionStatusRejected  = "RECHAZADA"
+)
+
+// ChallengeSubmission es la entrega de un usuario a un desafío.
+type ChallengeSubmission struct {
+	Id               int64      `json:"id"`
+	CommunityEventId int64      `json:"communityEventId"`
+	UserId           int64      `json:"userId"`
+	RepositoryUrl    string     `json:"repositoryUrl,omitempty"`
+	FileContentId    string     `json:"fileContentId,omitempty"`
+	FileName         string     `json:"fileName,omitempty"` // Nombre del archivo en el almacenamiento, para descargarlo
+	Description      string     `json:"description,omitempty"`
+	Status           string     `json:"status"`
+	Score            *int       `json:"score,omitempty"`
+	Feedback         string     `json:"feedback,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewedAt,omitempty"`
+	PointsRP         *int       `json:"pointsRP,omitempty"` // RP otorgados al cerrar el desafío
+	SubmittedAt      time.Time  `json:"submittedAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+
+	// Datos del participante, solo en el listado para la empresa.
+	Participant *SubmissionParticipant `json:"participant,omitempty"`
+}
+
+// SubmissionParticipant son los datos públicos del autor de una entrega.
+type SubmissionParticipant struct {
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	UserName  string `json:"userName,omitempty"`
+	Picture   string `json:"picture,omitempty"`
+}
+
+// SubmitChallengeRequest es el cuerpo de POST /community-events/{eventID}/submissions. Se
+// requiere repositoryUrl, fileContentId (de un archivo subido con /pdfs/upload o
+// /media/upload) o ambos.
+type SubmitChallengeRequest struct {
+	RepositoryUrl string `json:"repositoryUrl"`
+	FileContentId string `json:"fileContentId"`
+	Description   string `json:"description"`
+}
+
+// ReviewSubmissionRequest es el cuerpo con el que la empresa revisa una entrega. Score (0 a
+// 100) es obligatorio para aprobarla.
+type ReviewSubmissionRequest struct {
+	Status   string `json:"status"`
+	Score    *int   `json:"score"`
+	Feedback string `json:"feedback"`
+}
+
+// UpdateChallengeStatusRequest es el cuerpo de PATCH /community-events/{eventID}/challenge-status.
+type UpdateChallengeStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// ChallengeInfo son los datos de un desafío que determinan si admite entregas y revisiones.
+type ChallengeInfo struct {
+	Id        int64
+	Title     string
+	PostType  string
+	OwnerId   int64
+	Status    string
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// PaginatedChallengeSubmissions es la respuesta paginada de las entregas de un desafío.
+type PaginatedChallengeSubmissions struct {
+	Data       []ChallengeSubmission `json:"data"`
+	Pagination PaginationDetails     `json:"pagination"`
+}
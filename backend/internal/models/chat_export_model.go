@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Formatos de exportación de una conversación.
+const (
+	ChatExportFormatJSON = "json"
+	ChatExportFormatText = "txt"
+)
+
+// Estados de una exportación de conversación. Son los mismos que los de PrivacyRequest.
+const (
+	ChatExportPending    = "pending"
+	ChatExportProcessing = "processing"
+	ChatExportCompleted  = "completed"
+	ChatExportFailed     = "failed"
+)
+
+// ChatExport es la exportación del historial de un chat privado o de grupo pedida por uno de
+// sus participantes.
+type ChatExport struct {
+	Id           int64      `json:"id"`
+	UserId       int64      `json:"userId"`
+	ChatId       string     `json:"chatId"` // Chat privado o de grupo, según lo pidió el usuario
+	IsGroup      bool       `json:"isGroup"`
+	Format       string     `json:"format"`
+	Status       string     `json:"status"`
+	ObjectPath   string     `json:"-"`
+	MessageCount int        `json:"messageCount"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"` // Caducidad del archivo en GCS
+	DownloadURL  string     `json:"downloadUrl,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	RequestedAt  time.Time  `json:"requestedAt"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty"`
+}
+
+// ChatExportsResponse es la lista paginada de exportaciones del usuario.
+type ChatExportsResponse struct {
+	Data       []ChatExport      `json:"data"`
+	Pagination PaginationDetails `json:"pagination"`
+}
+
+// CreateChatExportRequest es el cuerpo de POST /users/me/chat-exports.
+type CreateChatExportRequest struct {
+	ChatId string `json:"chatId"`
+	Format string `json:"format"` // json (por defecto) o txt
+}
+
+// ChatExportMessage es un mensaje dentro del archivo exportado. Los mensajes ocultos por
+// moderación se incluyen sin contenido.
+type ChatExportMessage struct {
+	Id               string     `json:"id"`
+	SenderId         int64      `json:"senderId"`
+	SenderName       string     `json:"senderName"`
+	TypeMessageId    int64      `json:"typeMessageId"`
+	Content          string     `json:"content,omitempty"`
+	MediaId          string     `json:"mediaId,omitempty"`
+	ReplyToMessageId string     `json:"replyToMessageId,omitempty"`
+	SentAt           time.Time  `json:"sentAt"`
+	EditedAt         *time.Time `json:"editedAt,omitempty"`
+	IsHidden         bool       `json:"isHidden,omitempty"`
+}
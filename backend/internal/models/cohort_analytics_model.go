@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// CohortAnalyticsFilter restringe la población de las analíticas de cohortes. Los textos se
+// comparan sin distinguir mayúsculas ni espacios a los lados; los campos vacíos o a cero no
+// filtran.
+type CohortAnalyticsFilter struct {
+	University string `json:"university,omitempty"`
+	Degree     string `json:"degree,omitempty"`
+	FromYear   int    `json:"fromYear,omitempty"`
+	ToYear     int    `json:"toYear,omitempty"`
+}
+
+// CohortAnalytics son las estadísticas agregadas de los egresados por universidad, carrera y
+// año de graduación, para los paneles de las universidades.
+type CohortAnalytics struct {
+	Filter  CohortAnalyticsFilter `json:"filter"`
+	Cohorts []CohortStats         `json:"cohorts"`
+	Totals  CohortTotals          `json:"totals"`
+	// SuppressedCohorts es el número de cohortes omitidas por tener menos miembros que
+	// MinCohortSize; sus miembros sí cuentan en Totals.
+	SuppressedCohorts int             `json:"suppressedCohorts"`
+	MinCohortSize     int             `json:"minCohortSize"`
+	TopEmployers      []EmployerCount `json:"topEmployers"`
+	TopSkills         []SkillCount    `json:"topSkills"` // Applicants es el número de miembros con la habilidad
+	GeneratedAt       time.Time       `json:"generatedAt"`
+}
+
+// CohortStats son las estadísticas de una cohorte (universidad, carrera y año de graduación).
+type CohortStats struct {
+	University     string  `json:"university"`
+	Degree         string  `json:"degree"`
+	GraduationYear int     `json:"graduationYear"`
+	Members        int64   `json:"members"`
+	Studying       int64   `json:"studying"`       // Miembros que siguen estudiando en la institución
+	Employed       int64   `json:"employed"`       // Miembros con un trabajo actual (WorkExperience.IsCurrentJob)
+	EmploymentRate float64 `json:"employmentRate"` // Fracción (0-1) de miembros empleados
+}
+
+// CohortTotals resume toda la población filtrada. Un usuario en varias cohortes cuenta una vez.
+type CohortTotals struct {
+	Members        int64   `json:"members"`
+	Employed       int64   `json:"employed"`
+	EmploymentRate float64 `json:"employmentRate"`
+}
+
+// EmployerCount es una empresa y el número de miembros que trabajan actualmente en ella.
+type EmployerCount struct {
+	Company   string `json:"company"`
+	Employees int64  `json:"employees"`
+}
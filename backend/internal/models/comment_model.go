@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Comment es un comentario de una publicación de la comunidad. Las respuestas tienen un
+// solo nivel de anidación: ParentId siempre apunta a un comentario raíz.
+type Comment struct {
+	Id               int64         `json:"id"`
+	CommunityEventId int64         `json:"communityEventId"`
+	ParentId         *int64        `json:"parentId,omitempty"`
+	Author           CommentAuthor `json:"author"`
+	// Content queda vacío si el comentario fue eliminado pero se conserva por sus respuestas.
+	Content   string     `json:"content"`
+	CreatedAt time.Time  `json:"createdAt"`
+	EditedAt  *time.Time `json:"editedAt,omitempty"`
+	IsDeleted bool       `json:"isDeleted"`
+	// IsHidden indica que moderación ocultó el comentario; Content queda vacío.
+	IsHidden bool `json:"isHidden,omitempty"`
+	// ReplyCount y Replies solo se rellenan en los comentarios raíz. Replies contiene las
+	// primeras respuestas; el resto se pide a /comments/{commentID}/replies.
+	ReplyCount int       `json:"replyCount"`
+	Replies    []Comment `json:"replies,omitempty"`
+}
+
+// CommentAuthor son los datos públicos del autor de un comentario.
+type CommentAuthor struct {
+	Id          int64  `json:"id"`
+	RoleId      int    `json:"roleId"`
+	FirstName   string `json:"firstName,omitempty"`
+	LastName    string `json:"lastName,omitempty"`
+	CompanyName string `json:"companyName,omitempty"`
+	Picture     string `json:"picture,omitempty"`
+}
+
+// CreateCommentRequest es el cuerpo de POST /community-events/{eventID}/comments. Si se
+// indica ParentId el comentario es una respuesta.
+type CreateCommentRequest struct {
+	Content  string `json:"content"`
+	ParentId *int64 `json:"parentId,omitempty"`
+}
+
+// UpdateCommentRequest es el cuerpo de PUT /comments/{commentID}.
+type UpdateCommentRequest struct {
+	Content string `json:"content"`
+}
+
+// PaginatedComments es la respuesta paginada de comentarios o respuestas.
+type PaginatedComments struct {
+	Data       []Comment         `json:"data"`
+	Pagination PaginationDetails `json:"pagination"`
+}
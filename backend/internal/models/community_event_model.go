@@ -51,7 +51,7 @@ type CommunityEventCreateRequest struct {
 	LinkPreviewTitle       *string  `json:"link_preview_title,omitempty"`
 	LinkPreviewDescription *string  `json:"link_preview_description,omitempty"`
 	LinkPreviewImage       *string  `json:"link_preview_image,omitempty"`
-	EventDate              *string  `json:"event_date,omitempty"` // Formato "YYYY-MM-DD HH:MM:SS"
+	EventDate              *string  `json:"event_date,omitempty"` // RFC 3339; se acepta "YYYY-MM-DD HH:MM:SS" (ver docs/fechas.md)
 	Location               *string  `json:"location,omitempty"`
 	Capacity               *int32   `json:"capacity,omitempty"`
 	Price                  *float64 `json:"price,omitempty"`
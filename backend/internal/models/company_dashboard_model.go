@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// CompanyDashboard agrupa las métricas de contratación de una empresa en un periodo.
+type CompanyDashboard struct {
+	CompanyId   int64                 `json:"companyId"`
+	Days        int                   `json:"days"`
+	From        time.Time             `json:"from"`
+	Postings    []PostingApplications `json:"postings"`
+	Funnel      []FunnelStage         `json:"funnel"`
+	Rejected    int64                 `json:"rejected"`
+	Withdrawn   int64                 `json:"withdrawn"`
+	Hired       int64                 `json:"hired"`
+	TimeToHire  *float64              `json:"averageTimeToHireDays"` // nil si no hubo contrataciones
+	TopSkills   []SkillCount          `json:"topApplicantSkills"`
+	GeneratedAt time.Time             `json:"generatedAt"`
+}
+
+// PostingApplications son las postulaciones recibidas por una publicación, por día.
+type PostingApplications struct {
+	EventId  int64        `json:"eventId"`
+	Title    string       `json:"title"`
+	PostType string       `json:"postType"`
+	Total    int64        `json:"total"`
+	Series   []DailyCount `json:"series"`
+}
+
+// DailyCount es un conteo para un día (YYYY-MM-DD).
+type DailyCount struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+// FunnelStage es una etapa del proceso de selección con las postulaciones que la alcanzaron.
+type FunnelStage struct {
+	Stage string `json:"stage"`
+	Count int64  `json:"count"`
+	// ConversionFromPrevious es la fracción (0-1) de la etapa anterior que llegó a esta.
+	ConversionFromPrevious float64 `json:"conversionFromPrevious"`
+	// ConversionFromStart es la fracción (0-1) del total de postulaciones que llegó a esta.
+	ConversionFromStart float64 `json:"conversionFromStart"`
+}
+
+// SkillCount es una habilidad y el número de postulantes que la declaran.
+type SkillCount struct {
+	Skill      string `json:"skill"`
+	Applicants int64  `json:"applicants"`
+}
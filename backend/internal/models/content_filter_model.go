@@ -0,0 +1,120 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Categorías de una regla del filtro de contenido (ENUM ContentFilterRule.Category).
+const (
+	FilterCategoryProfanity  = "PROFANIDAD"
+	FilterCategorySpam       = "SPAM"
+	FilterCategoryHarassment = "ACOSO"
+	FilterCategoryOther      = "OTRO"
+)
+
+// ContentFilterRule es una regla de la lista de palabras del filtro de contenido del chat.
+type ContentFilterRule struct {
+	Id        int64     `json:"id"`
+	Language  string    `json:"language"`
+	MatchType string    `json:"matchType"`
+	Pattern   string    `json:"pattern"`
+	Category  string    `json:"category"`
+	Severity  string    `json:"severity"`
+	IsActive  bool      `json:"isActive"`
+	CreatedBy *int64    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ContentFilterRuleRequest es el cuerpo para crear o modificar una regla. Los campos vacíos
+// toman su valor por defecto: idioma "*", tipo PALABRA, categoría PROFANIDAD y severidad
+// MEDIA. IsActive nil equivale a true.
+type ContentFilterRuleRequest struct {
+	Language  string `json:"language"`
+	MatchType string `json:"matchType"`
+	Pattern   string `json:"pattern"`
+	Category  string `json:"category"`
+	Severity  string `json:"severity"`
+	IsActive  *bool  `json:"isActive"`
+}
+
+// ContentFilterImportRequest es el cuerpo para importar una lista de palabras de un idioma.
+type ContentFilterImportRequest struct {
+	Language string   `json:"language"`
+	Category string   `json:"category"`
+	Severity string   `json:"severity"`
+	Words    []string `json:"words"`
+}
+
+// ContentFilterImportResult indica cuántas palabras se importaron y cuántas ya existían.
+type ContentFilterImportResult struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
+
+// ContentFilterPolicy es la configuración ajustable del filtro: la acción de cada severidad
+// y los umbrales de las heurísticas de spam. Un umbral en cero desactiva su heurística.
+type ContentFilterPolicy struct {
+	Enabled                bool       `json:"enabled"`
+	LowAction              string     `json:"lowAction"`
+	MediumAction           string     `json:"mediumAction"`
+	HighAction             string     `json:"highAction"`
+	MaxLinks               int        `json:"maxLinks"`
+	MaxRepeatedChars       int        `json:"maxRepeatedChars"`
+	MaxUppercaseRatio      float64    `json:"maxUppercaseRatio"`
+	DuplicateThreshold     int        `json:"duplicateThreshold"`
+	DuplicateWindowSeconds int        `json:"duplicateWindowSeconds"`
+	SpamSeverity           string     `json:"spamSeverity"`
+	UpdatedBy              *int64     `json:"updatedBy,omitempty"`
+	UpdatedAt              *time.Time `json:"updatedAt,omitempty"`
+}
+
+// DefaultContentFilterPolicy devuelve la política que se aplica mientras no se haya
+// guardado ninguna (los DEFAULT de la tabla ContentFilterPolicy).
+func DefaultContentFilterPolicy() ContentFilterPolicy {
+	return ContentFilterPolicy{
+		Enabled:                true,
+		LowAction:              "MARCAR",
+		MediumAction:           "BORRADO_SILENCIOSO",
+		HighAction:             "BLOQUEAR",
+		MaxLinks:               3,
+		MaxRepeatedChars:       10,
+		MaxUppercaseRatio:      0.8,
+		DuplicateThreshold:     3,
+		DuplicateWindowSeconds: 60,
+		SpamSeverity:           "BAJA",
+	}
+}
+
+// ContentFilterHit es un mensaje marcado, ocultado al resto o bloqueado por el filtro.
+type ContentFilterHit struct {
+	Id          int64           `json:"id"`
+	MessageId   *string         `json:"messageId,omitempty"` // Nulo en los mensajes bloqueados
+	SenderId    int64           `json:"senderId"`
+	SenderName  string          `json:"senderName,omitempty"`
+	ChatId      *string         `json:"chatId,omitempty"`
+	ChatIdGroup *string         `json:"chatIdGroup,omitempty"`
+	Content     string          `json:"content"`
+	Action      string          `json:"action"`
+	Severity    string          `json:"severity"`
+	Hits        json.RawMessage `json:"hits"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+// ContentFilterTestRequest es el cuerpo para probar el filtro con un texto.
+type ContentFilterTestRequest struct {
+	Text string `json:"text"`
+}
+
+// PaginatedContentFilterRules es la respuesta paginada de las reglas del filtro.
+type PaginatedContentFilterRules struct {
+	Data       []ContentFilterRule `json:"data"`
+	Pagination PaginationDetails   `json:"pagination"`
+}
+
+// PaginatedContentFilterHits es la respuesta paginada del registro del filtro.
+type PaginatedContentFilterHits struct {
+	Data       []ContentFilterHit `json:"data"`
+	Pagination PaginationDetails  `json:"pagination"`
+}
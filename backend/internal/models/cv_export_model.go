@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+// CVExportVersion identifica el formato del documento de exportación del CV.
+// Debe incrementarse si se cambia la estructura de CVExport de forma incompatible.
+const CVExportVersion = "1"
+
+// CVExport es la representación portable del CompleteProfile de un usuario.
+// A diferencia de los modelos de BD, no usa tipos sql.Null* para que el JSON
+// resultante sea legible por otras herramientas.
+type CVExport struct {
+	Version        string                   `json:"version"`
+	GeneratedAt    time.Time                `json:"generatedAt"`
+	Profile        CVExportProfile          `json:"profile"`
+	Education      []CVExportEducation      `json:"education"`
+	WorkExperience []CVExportWorkExperience `json:"workExperience"`
+	Certifications []CVExportCertification  `json:"certifications"`
+	Skills         []CVExportSkill          `json:"skills"`
+	Languages      []CVExportLanguage       `json:"languages"`
+	Projects       []CVExportProject        `json:"projects"`
+}
+
+// CVExportProfile contiene los datos personales incluidos en el CV.
+type CVExportProfile struct {
+	FirstName    string `json:"firstName,omitempty"`
+	LastName     string `json:"lastName,omitempty"`
+	UserName     string `json:"userName,omitempty"`
+	Email        string `json:"email,omitempty"`
+	ContactEmail string `json:"contactEmail,omitempty"`
+	Phone        string `json:"phone,omitempty"`
+	Address      string `json:"address,omitempty"`
+	Summary      string `json:"summary,omitempty"`
+	Github       string `json:"github,omitempty"`
+	Linkedin     string `json:"linkedin,omitempty"`
+	Degree       string `json:"degree,omitempty"`
+}
+
+// CVExportEducation es un item de educación del CV. Las fechas usan el formato YYYY-MM-DD.
+type CVExportEducation struct {
+	Institution         string `json:"institution"`
+	Degree              string `json:"degree"`
+	Campus              string `json:"campus,omitempty"`
+	Country             string `json:"country,omitempty"`
+	GraduationDate      string `json:"graduationDate,omitempty"`
+	IsCurrentlyStudying bool   `json:"isCurrentlyStudying"`
+}
+
+// CVExportWorkExperience es un item de experiencia laboral del CV.
+type CVExportWorkExperience struct {
+	Company      string `json:"company"`
+	Position     string `json:"position"`
+	Country      string `json:"country,omitempty"`
+	StartDate    string `json:"startDate,omitempty"`
+	EndDate      string `json:"endDate,omitempty"`
+	IsCurrentJob bool   `json:"isCurrentJob"`
+	Description  string `json:"description,omitempty"`
+}
+
+// CVExportCertification es una certificación del CV.
+type CVExportCertification struct {
+	Certification string `json:"certification"`
+	Institution   string `json:"institution"`
+	DateObtained  string `json:"dateObtained,omitempty"`
+}
+
+// CVExportSkill es una habilidad del CV.
+type CVExportSkill struct {
+	Skill string `json:"skill"`
+	Level string `json:"level,omitempty"`
+	// SkillCatalogId lo asigna la normalización al importar; no forma parte del formato.
+	SkillCatalogId *int64 `json:"-"`
+}
+
+// CVExportLanguage es un idioma del CV.
+type CVExportLanguage struct {
+	Language string `json:"language"`
+	Level    string `json:"level,omitempty"`
+}
+
+// CVExportProject es un proyecto del CV.
+type CVExportProject struct {
+	Title       string `json:"title"`
+	Role        string `json:"role,omitempty"`
+	Company     string `json:"company,omitempty"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status,omitempty"`
+	StartDate   string `json:"startDate,omitempty"`
+	EndDate     string `json:"endDate,omitempty"`
+	IsOngoing   bool   `json:"isOngoing"`
+}
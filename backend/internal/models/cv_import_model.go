@@ -0,0 +1,76 @@
+package models
+
+// Formatos aceptados por la importación de CV.
+const (
+	CVImportFormatJSON     = "json"     // Documento CVExport generado por /users/me/cv/export
+	CVImportFormatLinkedIn = "linkedin" // Archivos CSV de la exportación de datos de LinkedIn convertidos a JSON
+)
+
+// CVImportSections agrupa los items del CV que la importación puede crear.
+// Reutiliza los tipos de exportación para que un CV exportado pueda reimportarse tal cual.
+type CVImportSections struct {
+	Education      []CVExportEducation      `json:"education"`
+	WorkExperience []CVExportWorkExperience `json:"workExperience"`
+	Skills         []CVExportSkill          `json:"skills"`
+	Languages      []CVExportLanguage       `json:"languages"`
+	Projects       []CVExportProject        `json:"projects"`
+}
+
+// CVImportResult describe el resultado de una importación.
+// En modo dry-run, Created contiene lo que se crearía sin haber escrito nada en la BD.
+type CVImportResult struct {
+	DryRun  bool             `json:"dryRun"`
+	Created CVImportSections `json:"created"`
+	// Skipped cuenta los items descartados por estar ya presentes en el CV o repetidos en el payload.
+	Skipped int `json:"skipped"`
+}
+
+// LinkedInExport representa los archivos de la exportación de datos de LinkedIn
+// (Positions.csv, Education.csv, Skills.csv, Languages.csv, Projects.csv) como JSON.
+// Las claves respetan los encabezados de columna originales de LinkedIn.
+type LinkedInExport struct {
+	Positions []LinkedInPosition  `json:"Positions"`
+	Education []LinkedInEducation `json:"Education"`
+	Skills    []LinkedInSkill     `json:"Skills"`
+	Languages []LinkedInLanguage  `json:"Languages"`
+	Projects  []LinkedInProject   `json:"Projects"`
+}
+
+// LinkedInPosition es una fila de Positions.csv. Las fechas usan el formato "Jan 2020".
+type LinkedInPosition struct {
+	CompanyName string `json:"Company Name"`
+	Title       string `json:"Title"`
+	Description string `json:"Description"`
+	Location    string `json:"Location"`
+	StartedOn   string `json:"Started On"`
+	FinishedOn  string `json:"Finished On"`
+}
+
+// LinkedInEducation es una fila de Education.csv.
+type LinkedInEducation struct {
+	SchoolName string `json:"School Name"`
+	StartDate  string `json:"Start Date"`
+	EndDate    string `json:"End Date"`
+	DegreeName string `json:"Degree Name"`
+	Notes      string `json:"Notes"`
+}
+
+// LinkedInSkill es una fila de Skills.csv.
+type LinkedInSkill struct {
+	Name string `json:"Name"`
+}
+
+// LinkedInLanguage es una fila de Languages.csv.
+type LinkedInLanguage struct {
+	Name        string `json:"Name"`
+	Proficiency string `json:"Proficiency"`
+}
+
+// LinkedInProject es una fila de Projects.csv.
+type LinkedInProject struct {
+	Title       string `json:"Title"`
+	Description string `json:"Description"`
+	URL         string `json:"Url"`
+	StartedOn   string `json:"Started On"`
+	FinishedOn  string `json:"Finished On"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetter es un mensaje crítico del servidor WebSocket que el usuario no confirmó. Se le
+// vuelve a enviar con el mismo PID cuando se conecta (ver docs/mensajes_criticos.md).
+type DeadLetter struct {
+	Id         int64           `json:"id"`
+	UserId     int64           `json:"userId"`
+	Pid        string          `json:"pid"`
+	Type       string          `json:"type"`
+	FromUserId int64           `json:"fromUserId,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Attempts   int             `json:"attempts"`
+	LastError  string          `json:"lastError,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
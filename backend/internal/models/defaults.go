@@ -188,5 +188,48 @@ func GetDefaultTypeMessages() []TypeMessage {
 			Name:        "Gif",
 			Description: "Gif",
 		},
+		{
+			Id:          TypeMessageVoiceNote,
+			Name:        "VoiceNote",
+			Description: "Nota de voz",
+		},
+	}
+}
+
+// GetDefaultSkillCatalog returns the predefined canonical skills and their aliases. Aliases
+// only need the spellings that normalization does not already unify: case, accents, spaces
+// and punctuation are ignored ("Node.js" = "nodejs" = "node js").
+func GetDefaultSkillCatalog() []SkillCatalogItem {
+	return []SkillCatalogItem{
+		{Name: "JavaScript", Category: "Lenguajes", Aliases: []string{"JS", "ECMAScript", "ES6"}},
+		{Name: "TypeScript", Category: "Lenguajes", Aliases: []string{"TS"}},
+		{Name: "Python", Category: "Lenguajes", Aliases: []string{"Py", "Python3"}},
+		{Name: "Go", Category: "Lenguajes", Aliases: []string{"Golang"}},
+		{Name: "Java", Category: "Lenguajes"},
+		{Name: "C", Category: "Lenguajes"},
+		{Name: "C++", Category: "Lenguajes", Aliases: []string{"CPP"}},
+		{Name: "C#", Category: "Lenguajes", Aliases: []string{"CSharp", "C Sharp"}},
+		{Name: "PHP", Category: "Lenguajes"},
+		{Name: "Kotlin", Category: "Lenguajes"},
+		{Name: "Swift", Category: "Lenguajes"},
+		{Name: "SQL", Category: "Bases de datos"},
+		{Name: "MySQL", Category: "Bases de datos"},
+		{Name: "PostgreSQL", Category: "Bases de datos", Aliases: []string{"Postgres", "PSQL"}},
+		{Name: "MongoDB", Category: "Bases de datos", Aliases: []string{"Mongo"}},
+		{Name: "HTML", Category: "Web", Aliases: []string{"HTML5"}},
+		{Name: "CSS", Category: "Web", Aliases: []string{"CSS3"}},
+		{Name: "React", Category: "Web", Aliases: []string{"ReactJS"}},
+		{Name: "Angular", Category: "Web", Aliases: []string{"AngularJS"}},
+		{Name: "Vue.js", Category: "Web", Aliases: []string{"Vue"}},
+		{Name: "Node.js", Category: "Web", Aliases: []string{"Node"}},
+		{Name: "Docker", Category: "Infraestructura"},
+		{Name: "Kubernetes", Category: "Infraestructura", Aliases: []string{"K8s"}},
+		{Name: "AWS", Category: "Infraestructura", Aliases: []string{"Amazon Web Services"}},
+		{Name: "Linux", Category: "Infraestructura"},
+		{Name: "Git", Category: "Herramientas", Aliases: []string{"GitHub", "GitLab"}},
+		{Name: "Figma", Category: "Diseño"},
+		{Name: "Excel", Category: "Ofimática", Aliases: []string{"Microsoft Excel", "MS Excel"}},
+		{Name: "Power BI", Category: "Datos", Aliases: []string{"Microsoft Power BI"}},
+		{Name: "Machine Learning", Category: "Datos", Aliases: []string{"ML", "Aprendizaje automático"}},
 	}
 }
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Tipos de elemento de un resumen por correo (ENUM EmailDigestItem.ItemType).
+const (
+	DigestItemEvent   = "EVENT"
+	DigestItemMessage = "MESSAGE"
+)
+
+// DigestItemKey identifica un elemento ya incluido en un resumen.
+type DigestItemKey struct {
+	Type string
+	Id   string
+}
+
+// DigestEvent es una notificación no leída incluida en el resumen.
+type DigestEvent struct {
+	Id          int64
+	EventType   string
+	Title       string
+	Description string
+	CreateAt    time.Time
+}
+
+// DigestMessage es un mensaje de chat privado no leído incluido en el resumen.
+type DigestMessage struct {
+	Id         string
+	ChatId     string
+	SenderId   int64
+	SenderName string
+	Content    string
+	HasMedia   bool
+	SentAt     time.Time
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// PostEngagement es el estado de "me gusta" y guardado de una publicación para el usuario
+// autenticado. Es la respuesta de los endpoints /community-events/{eventID}/like y /bookmark.
+type PostEngagement struct {
+	CommunityEventId int64 `json:"communityEventId"`
+	LikeCount        int64 `json:"likeCount"`
+	CommentCount     int64 `json:"commentCount"`
+	LikedByMe        bool  `json:"likedByMe"`
+	BookmarkedByMe   bool  `json:"bookmarkedByMe"`
+}
+
+// SavedItem es una publicación guardada por el usuario.
+type SavedItem struct {
+	CommunityEventId int64     `json:"communityEventId"`
+	PostType         string    `json:"postType"`
+	Title            string    `json:"title"`
+	Description      string    `json:"description,omitempty"`
+	ImageUrl         string    `json:"imageUrl,omitempty"`
+	AuthorId         int64     `json:"authorId"`
+	AuthorName       string    `json:"authorName"`
+	AuthorPicture    string    `json:"authorPicture,omitempty"`
+	LikeCount        int64     `json:"likeCount"`
+	CommentCount     int64     `json:"commentCount"`
+	LikedByMe        bool      `json:"likedByMe"`
+	CreatedAt        time.Time `json:"createdAt"`
+	SavedAt          time.Time `json:"savedAt"`
+}
+
+// PaginatedSavedItems es la respuesta paginada de GET /users/me/saved-items.
+type PaginatedSavedItems struct {
+	Data       []SavedItem       `json:"data"`
+	Pagination PaginationDetails `json:"pagination"`
+}
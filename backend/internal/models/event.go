@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql"
 	"encoding/json"
+	"strconv"
 	"time"
 )
 
@@ -22,6 +23,45 @@ type Event struct {
 	ActionRequired bool            `json:"actionRequired"`
 	ActionTakenAt  sql.NullTime    `json:"actionTakenAt"`
 	Metadata       json.RawMessage `json:"metadata"`
+	// Agrupación (ver CreateGroupedEventWithOutbox): clave del grupo, número de eventos
+	// agrupados y últimos usuarios que lo originaron, el más reciente primero.
+	GroupKey    sql.NullString `json:"groupKey"`
+	GroupCount  int            `json:"groupCount"`
+	GroupActors []int64        `json:"groupActors,omitempty"`
+}
+
+// MaxEventGroupActors es el número de usuarios que se guardan en una notificación agrupada
+// para mostrar su vista previa ("Ana, Luis y 18 personas más").
+const MaxEventGroupActors = 3
+
+// EventGroup es el estado de una notificación agrupada después de añadirle un evento.
+type EventGroup struct {
+	Count    int     // Eventos agrupados, incluido el nuevo
+	ActorIds []int64 // Últimos usuarios, el más reciente primero (hasta MaxEventGroupActors)
+}
+
+// EventGroupKey construye la clave de agrupación de las notificaciones de tipo eventType
+// sobre el mismo objeto (una publicación, un comentario...).
+func EventGroupKey(eventType string, subjectID int64) string {
+	return eventType + ":" + strconv.FormatInt(subjectID, 10)
+}
+
+// MergeEventGroupActors antepone los usuarios de newer a los de older sin repetir ninguno y
+// se queda con los MaxEventGroupActors primeros.
+func MergeEventGroupActors(newer, older []int64) []int64 {
+	merged := make([]int64, 0, MaxEventGroupActors)
+	seen := make(map[int64]bool, len(newer)+len(older))
+	for _, id := range append(append([]int64{}, newer...), older...) {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		merged = append(merged, id)
+		if len(merged) == MaxEventGroupActors {
+			break
+		}
+	}
+	return merged
 }
 
 // EventType constants
@@ -30,6 +70,10 @@ const (
 	EventTypeSystem          = "SYSTEM"
 	EventTypeEvent           = "EVENT"
 	EventTypeRequestResponse = "REQUEST_RESPONSE"
+	// Avisos de seguridad: inicio de sesión desde un dispositivo o un país que el usuario no
+	// había usado antes.
+	EventTypeSecurityNewDevice  = "SECURITY_NEW_DEVICE"
+	EventTypeSecurityNewCountry = "SECURITY_NEW_COUNTRY"
 )
 
 // EventStatus constants
@@ -56,6 +100,19 @@ type EventMetadata struct {
 	// Quién recibe la reseña.
 	RevieweeId int64 `json:"revieweeId,omitempty"`
 
+	// Comentario que originó la notificación (comentarios y respuestas en publicaciones).
+	CommentId int64 `json:"commentId,omitempty"`
+	// Total de "me gusta" de la publicación en la notificación agrupada POST_LIKED.
+	LikeCount int64 `json:"likeCount,omitempty"`
+	// Entrega de desafío que originó la notificación y RP otorgados al cerrarlo.
+	SubmissionId int64 `json:"submissionId,omitempty"`
+	PointsRP     int   `json:"pointsRP,omitempty"`
+	// Reporte de moderación que originó la notificación.
+	ReportId int64 `json:"reportId,omitempty"`
+	// Anuncio del sistema (SYSTEM_ANNOUNCEMENT) y su nivel.
+	AnnouncementId    int64  `json:"announcementId,omitempty"`
+	AnnouncementLevel string `json:"announcementLevel,omitempty"`
+
 	// Para eventos del sistema
 	SystemEventType string `json:"systemEventType,omitempty"`
 	AdditionalData  any    `json:"additionalData,omitempty"`
@@ -0,0 +1,23 @@
+package models
+
+import "database/sql"
+
+// UserSensitiveFields son las columnas cifradas de una fila de User tal como están guardadas
+// (cifradas o aún en texto plano), con los índices ciegos de DocId y RIF.
+type UserSensitiveFields struct {
+	Id        int64
+	DocId     sql.NullString
+	DocIdHash sql.NullString
+	Phone     sql.NullString
+	RIF       sql.NullString
+	RIFHash   sql.NullString
+}
+
+// FieldEncryptionReport resume una ejecución de la migración que cifra (o descifra) las
+// columnas sensibles de User.
+type FieldEncryptionReport struct {
+	Scanned   int            `json:"scanned"`   // Filas de User revisadas
+	Updated   int            `json:"updated"`   // Filas reescritas
+	Conflicts int            `json:"conflicts"` // Filas modificadas durante la migración; se omiten hasta la siguiente ejecución
+	ByColumn  map[string]int `json:"by_column"` // Filas reescritas por columna
+}
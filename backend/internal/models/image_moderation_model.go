@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Estados de una imagen en la cola de revisión (ENUM ImageReview.Status).
+const (
+	ImageReviewPending  = "PENDIENTE"
+	ImageReviewApproved = "APROBADA"
+	ImageReviewRemoved  = "ELIMINADA"
+)
+
+// EventTypeImageRemoved avisa al autor de que un moderador eliminó una de sus imágenes.
+const EventTypeImageRemoved = "IMAGE_REMOVED"
+
+// ImageReview es una imagen que el clasificador automático marcó como dudosa y espera la
+// decisión de un moderador.
+type ImageReview struct {
+	Id             int64      `json:"id"`
+	UserId         int64      `json:"userId"`
+	UserName       string     `json:"userName,omitempty"`
+	ContentId      string     `json:"contentId"`
+	FileName       string     `json:"fileName"` // Imagen original; se ve en /images/view/{fileName}
+	Score          float64    `json:"score"`
+	Labels         []string   `json:"labels"`
+	Status         string     `json:"status"`
+	ResolutionNote string     `json:"resolutionNote,omitempty"`
+	ReviewedBy     *int64     `json:"reviewedBy,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// ResolveImageReviewRequest es el cuerpo con el que un moderador decide sobre una imagen:
+// Action APROBADA la deja publicada y ELIMINADA la borra.
+type ResolveImageReviewRequest struct {
+	Action string `json:"action"`
+	Note   string `json:"note"`
+}
+
+// PaginatedImageReviews es la respuesta paginada de la cola de revisión de imágenes.
+type PaginatedImageReviews struct {
+	Data       []ImageReview     `json:"data"`
+	Pagination PaginationDetails `json:"pagination"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Impersonation es una suplantación: un administrador de soporte actúa como UserId con un
+// token de corta duración para reproducir un problema que reportó el usuario.
+type Impersonation struct {
+	Id        int64      `json:"id"`
+	AdminId   int64      `json:"adminId"`
+	UserId    int64      `json:"userId"`
+	Reason    string     `json:"reason"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+}
+
+// StartImpersonationRequest es el cuerpo de POST /admin/users/{id}/impersonate.
+type StartImpersonationRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ImpersonationToken es la respuesta al iniciar una suplantación. El token se usa como
+// cualquier otro en la API (Authorization: Bearer) y en el WebSocket (?token=).
+type ImpersonationToken struct {
+	ImpersonationId int64     `json:"impersonationId"`
+	UserId          int64     `json:"userId"`
+	Token           string    `json:"token"`
+	ExpiresAt       time.Time `json:"expiresAt"`
+}
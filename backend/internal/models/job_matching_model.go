@@ -0,0 +1,121 @@
+package models
+
+import "time"
+
+// Componentes de la puntuación de afinidad entre un candidato y una oferta.
+const (
+	MatchComponentSkills     = "skills"
+	MatchComponentDegree     = "degree"
+	MatchComponentLanguages  = "languages"
+	MatchComponentExperience = "experience"
+	MatchComponentReputation = "reputation"
+)
+
+// JobRequirements son los requisitos de una oferta (una publicación de CommunityEvent) con los
+// que se calcula la afinidad de los candidatos. Una publicación con requisitos es una oferta.
+type JobRequirements struct {
+	CommunityEventId   int64                    `json:"communityEventId"`
+	Skills             []JobSkillRequirement    `json:"skills"`
+	Degrees            []string                 `json:"degrees"`
+	Languages          []JobLanguageRequirement `json:"languages"`
+	MinExperienceYears int                      `json:"minExperienceYears"`
+	UpdatedAt          time.Time                `json:"updatedAt"`
+}
+
+// SetJobRequirementsRequest es el cuerpo de PUT /community-events/{eventID}/requirements.
+// Reemplaza todos los requisitos de la oferta.
+type SetJobRequirementsRequest struct {
+	Skills             []JobSkillRequirement    `json:"skills"`
+	Degrees            []string                 `json:"degrees"`
+	Languages          []JobLanguageRequirement `json:"languages"`
+	MinExperienceYears int                      `json:"minExperienceYears"`
+}
+
+// JobSkillRequirement es una habilidad pedida por la oferta. Al guardarla se normaliza con el
+// catálogo de habilidades: Name pasa a ser el nombre canónico y SkillCatalogId su ID.
+type JobSkillRequirement struct {
+	Name           string `json:"name"`
+	SkillCatalogId *int64 `json:"skillCatalogId,omitempty"`
+	Required       bool   `json:"required"` // Las obligatorias pesan el doble que las deseables
+}
+
+// JobLanguageRequirement es un idioma pedido por la oferta. Level es opcional.
+type JobLanguageRequirement struct {
+	Language string `json:"language"`
+	Level    string `json:"level,omitempty"`
+}
+
+// MatchComponent explica una parte de la puntuación: Score (0-1) es lo que cumple el
+// candidato, Weight el peso del componente y Points su aportación a la puntuación total.
+type MatchComponent struct {
+	Component string   `json:"component"`
+	Weight    float64  `json:"weight"`
+	Score     float64  `json:"score"`
+	Points    float64  `json:"points"`
+	Detail    string   `json:"detail"`
+	Matched   []string `json:"matched,omitempty"`
+	Missing   []string `json:"missing,omitempty"`
+}
+
+// MatchResult es la puntuación (0-100) de un candidato para una oferta con su desglose.
+type MatchResult struct {
+	Score float64 `json:"score"`
+	// MissingRequired indica que al candidato le falta alguna habilidad obligatoria.
+	MissingRequired bool             `json:"missingRequired"`
+	Components      []MatchComponent `json:"components"`
+}
+
+// CandidateProfile son los datos del perfil de un estudiante o egresado que se comparan con
+// los requisitos de una oferta.
+type CandidateProfile struct {
+	UserId          int64
+	FirstName       string
+	LastName        string
+	Picture         string
+	RoleId          int
+	VerifiedStudent bool // Insignia de estudiante o egresado verificado
+	Skills          []Skills
+	Degrees         []string
+	Languages       []Languages
+	ExperienceYears float64
+	ReputationRP    int
+	// ReputationPercentile es el percentil (0-100) del candidato en el ranking de estudiantes.
+	ReputationPercentile float64
+}
+
+// CandidateRecommendation es un candidato recomendado para una oferta.
+type CandidateRecommendation struct {
+	UserId     int64  `json:"userId"`
+	FirstName  string `json:"firstName"`
+	LastName   string `json:"lastName"`
+	Picture    string `json:"picture,omitempty"`
+	RoleId     int    `json:"roleId"`
+	HasApplied bool   `json:"hasApplied"`
+	// VerifiedStudent indica la insignia de estudiante o egresado verificado. No suma puntos.
+	VerifiedStudent bool `json:"verifiedStudent"`
+	MatchResult
+}
+
+// JobRecommendation es una oferta recomendada para un estudiante.
+type JobRecommendation struct {
+	CommunityEventId int64     `json:"communityEventId"`
+	Title            string    `json:"title"`
+	PostType         string    `json:"postType"`
+	CompanyId        int64     `json:"companyId"`
+	CompanyName      string    `json:"companyName,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+	HasApplied       bool      `json:"hasApplied"`
+	MatchResult
+}
+
+// PaginatedCandidateRecommendations es la respuesta paginada de candidatos recomendados.
+type PaginatedCandidateRecommendations struct {
+	Data       []CandidateRecommendation `json:"data"`
+	Pagination PaginationDetails         `json:"pagination"`
+}
+
+// PaginatedJobRecommendations es la respuesta paginada de ofertas recomendadas.
+type PaginatedJobRecommendations struct {
+	Data       []JobRecommendation `json:"data"`
+	Pagination PaginationDetails   `json:"pagination"`
+}
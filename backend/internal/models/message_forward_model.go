@@ -0,0 +1,30 @@
+package models
+
+// ForwardSource es el mensaje original de un reenvío, con la marca de reenvío que heredará la
+// copia.
+type ForwardSource struct {
+	Id                     string
+	SenderId               int64
+	Content                string
+	MediaFileName          string // Multimedia.FileName del adjunto, vacío si no tiene
+	ForwardedFromMessageId string // Vacío si el original no es a su vez un reenvío
+	ForwardedFromSenderId  int64
+	IsHidden               bool
+}
+
+// OriginMessageId devuelve el mensaje del que procede el contenido: si el mensaje ya era un
+// reenvío se conserva el original y no el intermedio.
+func (s ForwardSource) OriginMessageId() string {
+	if s.ForwardedFromMessageId != "" {
+		return s.ForwardedFromMessageId
+	}
+	return s.Id
+}
+
+// OriginSenderId devuelve el autor del mensaje original (ver OriginMessageId).
+func (s ForwardSource) OriginSenderId() int64 {
+	if s.ForwardedFromMessageId != "" {
+		return s.ForwardedFromSenderId
+	}
+	return s.SenderId
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Origen de una ejecución de retención de mensajes.
+const (
+	RetentionTriggerScheduled = "scheduled"
+	RetentionTriggerManual    = "manual"
+)
+
+// Estados de una ejecución de retención.
+const (
+	RetentionRunRunning   = "running"
+	RetentionRunSucceeded = "succeeded"
+	RetentionRunFailed    = "failed"
+)
+
+// MessageRetentionRun es una ejecución del archivado de mensajes antiguos.
+type MessageRetentionRun struct {
+	Id          int64      `json:"id"`
+	Trigger     string     `json:"trigger"`
+	TriggeredBy *int64     `json:"triggeredBy,omitempty"` // Admin que lanzó la ejecución manual
+	Status      string     `json:"status"`
+	Cutoff      time.Time  `json:"cutoff"` // Se archivan los mensajes enviados antes de esta fecha
+	Archived    int64      `json:"archived"`
+	StartedAt   time.Time  `json:"startedAt"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// ChatRetentionOptOut marca un chat (privado o de grupo) excluido del archivado.
+type ChatRetentionOptOut struct {
+	ChatId     string    `json:"chatId"`
+	OptedOutBy int64     `json:"optedOutBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// MessageRetentionStatus resume la configuración y la última ejecución para el panel de administración.
+type MessageRetentionStatus struct {
+	Enabled       bool                 `json:"enabled"`
+	RetentionDays int                  `json:"retentionDays"`
+	Interval      string               `json:"interval"`
+	LastRun       *MessageRetentionRun `json:"lastRun,omitempty"`
+}
@@ -3,7 +3,10 @@ package models
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/fieldcrypt"
 )
 
 // REGLAS A SEGUIR
@@ -64,6 +67,23 @@ type University struct {
 	Id     int64  `json:"id" db:"Id"`
 	Name   string `json:"name" db:"Name"`
 	Campus string `json:"campus" db:"Campus"`
+	// EmailDomains son los dominios del correo institucional, para verificar estudiantes.
+	EmailDomains []string `json:"email_domains" db:"EmailDomains"`
+	// Slug es el subdominio de la institución (ucv.example.com), vacío si no tiene; ver
+	// docs/multi_institucion.md.
+	Slug string `json:"slug" db:"Slug"`
+}
+
+// MatchesEmailDomain indica si domain es uno de los dominios de correo de la universidad o un
+// subdominio suyo: alumnos.ucv.ve coincide con ucv.ve.
+func (u University) MatchesEmailDomain(domain string) bool {
+	domain = strings.ToLower(domain)
+	for _, d := range u.EmailDomains {
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
 }
 
 // Degree defines the structure for the Degree table.
@@ -83,36 +103,38 @@ type Role struct {
 
 // User defines the structure for the User table, handling potential NULL values.
 type User struct {
-	Id                 int64          `json:"id" db:"Id"`
-	FirstName          sql.NullString `json:"first_name" db:"FirstName"` // Handle NULL
-	LastName           sql.NullString `json:"last_name" db:"LastName"`   // Handle NULL
-	UserName           string         `json:"user_name" db:"UserName"`
-	Password           string         `json:"-" db:"Password"` // Exclude password from JSON responses
-	Email              string         `json:"email" db:"Email"`
-	Phone              sql.NullString `json:"phone" db:"Phone"`                  // Handle NULL
-	Sex                sql.NullString `json:"sex" db:"Sex"`                      // Handle NULL (Asumiendo que puede ser NULL)
-	DocId              sql.NullString `json:"doc_id" db:"DocId"`                 // Handle NULL
-	NationalityId      sql.NullInt32  `json:"nationality_id" db:"NationalityId"` // Handle NULL (int es int32)
-	NationalityName    sql.NullString `json:"nationality_name,omitempty" db:"NationalityName"`
-	Birthdate          sql.NullTime   `json:"birthdate" db:"Birthdate"` // Handle NULL
-	Picture            sql.NullString `json:"picture" db:"Picture"`     // Handle NULL
-	DegreeId           sql.NullInt64  `json:"degree_id" db:"DegreeId"`  // Handle NULL
-	DegreeName         sql.NullString `json:"degree_name,omitempty" db:"DegreeName"`
-	UniversityId       sql.NullInt64  `json:"university_id" db:"UniversityId"` // Handle NULL
-	UniversityName     sql.NullString `json:"university_name,omitempty" db:"UniversityName"`
-	RoleId             int            `json:"role_id" db:"RoleId"` // Asumiendo no NULL
-	RoleName           sql.NullString `json:"role_name,omitempty" db:"RoleName"`
-	StatusAuthorizedId int            `json:"status_authorized_id" db:"StatusAuthorizedId"` // Asumiendo no NULL
-	Summary            sql.NullString `json:"summary" db:"Summary"`                         // Handle NULL
-	Address            sql.NullString `json:"address" db:"Address"`                         // Handle NULL
-	Github             sql.NullString `json:"github" db:"Github"`                           // Handle NULL
-	Linkedin           sql.NullString `json:"linkedin" db:"Linkedin"`                       // Handle NULL
-	CompanyName        sql.NullString `json:"company_name,omitempty" db:"CompanyName"`
-	Sector             sql.NullString `json:"sector,omitempty" db:"Sector"`
-	Location           sql.NullString `json:"location,omitempty" db:"Location"`
-	ChatId             sql.NullString `json:"chat_id,omitempty" db:"ChatId"`
-	CreatedAt          time.Time      `json:"created_at" db:"CreatedAt"`
-	UpdatedAt          time.Time      `json:"updated_at" db:"UpdatedAt"`
+	Id                 int64                 `json:"id" db:"Id"`
+	FirstName          sql.NullString        `json:"first_name" db:"FirstName"` // Handle NULL
+	LastName           sql.NullString        `json:"last_name" db:"LastName"`   // Handle NULL
+	UserName           string                `json:"user_name" db:"UserName"`
+	Password           string                `json:"-" db:"Password"` // Exclude password from JSON responses
+	Email              string                `json:"email" db:"Email"`
+	Phone              fieldcrypt.NullString `json:"phone" db:"Phone"`                  // Cifrado en la BD (ver docs/cifrado_campos.md)
+	Sex                sql.NullString        `json:"sex" db:"Sex"`                      // Handle NULL (Asumiendo que puede ser NULL)
+	DocId              fieldcrypt.NullString `json:"doc_id" db:"DocId"`                 // Cifrado en la BD
+	NationalityId      sql.NullInt32         `json:"nationality_id" db:"NationalityId"` // Handle NULL (int es int32)
+	NationalityName    sql.NullString        `json:"nationality_name,omitempty" db:"NationalityName"`
+	Birthdate          sql.NullTime          `json:"birthdate" db:"Birthdate"` // Handle NULL
+	Picture            sql.NullString        `json:"picture" db:"Picture"`     // Handle NULL
+	DegreeId           sql.NullInt64         `json:"degree_id" db:"DegreeId"`  // Handle NULL
+	DegreeName         sql.NullString        `json:"degree_name,omitempty" db:"DegreeName"`
+	UniversityId       sql.NullInt64         `json:"university_id" db:"UniversityId"` // Handle NULL
+	UniversityName     sql.NullString        `json:"university_name,omitempty" db:"UniversityName"`
+	RoleId             int                   `json:"role_id" db:"RoleId"` // Asumiendo no NULL
+	RoleName           sql.NullString        `json:"role_name,omitempty" db:"RoleName"`
+	StatusAuthorizedId int                   `json:"status_authorized_id" db:"StatusAuthorizedId"` // Asumiendo no NULL
+	Summary            sql.NullString        `json:"summary" db:"Summary"`                         // Handle NULL
+	Address            sql.NullString        `json:"address" db:"Address"`                         // Handle NULL
+	Github             sql.NullString        `json:"github" db:"Github"`                           // Handle NULL
+	Linkedin           sql.NullString        `json:"linkedin" db:"Linkedin"`                       // Handle NULL
+	CompanyName        sql.NullString        `json:"company_name,omitempty" db:"CompanyName"`
+	Sector             sql.NullString        `json:"sector,omitempty" db:"Sector"`
+	Location           sql.NullString        `json:"location,omitempty" db:"Location"`
+	ChatId             sql.NullString        `json:"chat_id,omitempty" db:"ChatId"`
+	CreatedAt          time.Time             `json:"created_at" db:"CreatedAt"`
+	UpdatedAt          time.Time             `json:"updated_at" db:"UpdatedAt"`
+	// TenantId es la institución de la cuenta (ver docs/multi_institucion.md); NULL si no tiene.
+	TenantId sql.NullInt64 `json:"tenant_id" db:"TenantId"`
 }
 
 // Online defines the structure for the Online table.
@@ -163,6 +185,15 @@ type ChatInfoQueryResult struct {
 	UnreadCount           int
 }
 
+// ChatSyncChange es un cambio anotado en un chat de la lista de un usuario (tabla ChatSyncChange).
+// Removed indica que el chat salió de la lista.
+type ChatSyncChange struct {
+	OtherUserID int64
+	ChatID      string
+	Removed     bool
+	ChangedAt   time.Time
+}
+
 // Session defines the structure for the Session table.
 type Session struct {
 	Id      int64  `json:"id" db:"Id"`
@@ -171,6 +202,19 @@ type Session struct {
 	Ip      string `json:"ip" db:"Ip"`
 	RoleId  int    `json:"role_id" db:"RoleId"`
 	TokenId int    `json:"token_id" db:"TokenId"` // Refers to Token.Id
+	SessionDevice
+	CreatedAt time.Time `json:"created_at" db:"CreatedAt"`
+}
+
+// SessionDevice son los datos del dispositivo y la ubicación con los que se inició una
+// sesión. Los campos vacíos son desconocidos.
+type SessionDevice struct {
+	UserAgent  string `json:"user_agent" db:"UserAgent"`
+	DeviceType string `json:"device_type" db:"DeviceType"` // desktop, mobile, tablet, bot, unknown
+	Browser    string `json:"browser" db:"Browser"`
+	OS         string `json:"os" db:"OS"`
+	Country    string `json:"country" db:"Country"` // ISO 3166-1 alfa-2
+	City       string `json:"city" db:"City"`
 }
 
 // Message defines the structure for the Message table.
@@ -235,6 +279,9 @@ type Skills struct {
 	PersonId int64  `json:"PersonId" db:"PersonId"`
 	Skill    string `json:"Skill" db:"Skill"`
 	Level    string `json:"Level" db:"Level"` // e.g., Basic, Intermediate, Advanced
+	// SkillCatalogId es la habilidad canónica asociada por la normalización; nil si el texto
+	// no corresponde a ninguna entrada del catálogo.
+	SkillCatalogId *int64 `json:"SkillCatalogId,omitempty" db:"SkillCatalogId"`
 }
 
 // Languages defines the structure for the Languages table.
@@ -282,12 +329,12 @@ type Enterprise struct {
 
 // RegistrationStep1 defines the data for the first step of user registration.
 type RegistrationStep1 struct {
-	FirstName string `json:"firstName"`
-	LastName  string `json:"lastName"`
-	UserName  string `json:"userName"`
-	Email     string `json:"email"`
+	FirstName string `json:"firstName" validate:"required"`
+	LastName  string `json:"lastName" validate:"required"`
+	UserName  string `json:"userName" validate:"required"`
+	Email     string `json:"email" validate:"required"`
 	Phone     string `json:"phone"`
-	Password  string `json:"password"`
+	Password  string `json:"password" validate:"required"`
 }
 
 // RegistrationStep2 defines the structure for the second step of user registration.
@@ -304,20 +351,20 @@ type RegistrationStep3 struct {
 
 // CompanyRegistrationRequest defines the data for company registration.
 type CompanyRegistrationRequest struct {
-	CompanyName string `json:"companyName"`
-	RIF         string `json:"rif"`
+	CompanyName string `json:"companyName" validate:"required"`
+	RIF         string `json:"rif" validate:"required"`
 	Sector      string `json:"sector"`
 	ContactName string `json:"contactName"`
-	Email       string `json:"email"`
+	Email       string `json:"email" validate:"required"`
 	Phone       string `json:"phone"`
-	Password    string `json:"password"`
+	Password    string `json:"password" validate:"required"`
 	Location    string `json:"location"`
 }
 
 // LoginRequest defines the structure for login requests.
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 // LoginResponse defines the structure for login responses.
@@ -584,8 +631,8 @@ type PaginatedUserResponse struct {
 // CompanyApprovalDTO define la estructura de datos para una empresa en la lista de aprobación.
 type CompanyApprovalDTO struct {
 	Id          int64  `json:"id"`
-	CompanyName string `json:"companyName"`
-	RIF         string `json:"rif"`
+	CompanyName string `json:"companyName" validate:"required"`
+	RIF         string `json:"rif" validate:"required"`
 	Email       string `json:"email"`
 	ContactName string `json:"contactName"` // Corresponde a FirstName en la tabla User
 	Phone       string `json:"phone"`
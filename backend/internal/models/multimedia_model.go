@@ -14,6 +14,13 @@ import (
  * que almacena información sobre los archivos subidos (imágenes, videos, etc.).
  */
 
+// MultimediaTypeVoiceNote es el Type de las notas de voz. Se guardan como objetos privados en
+// GCS y solo se reproducen a través de la API.
+const MultimediaTypeVoiceNote = "voice_note"
+
+// TypeMessageVoiceNote es el TypeMessage de los mensajes que adjuntan una nota de voz.
+const TypeMessageVoiceNote int64 = 10
+
 // Multimedia representa la estructura de la tabla Multimedia en la base de datos.
 type Multimedia struct {
 	Id        string        `json:"id" db_field:"Id" sql_type:"VARCHAR(255)"`
@@ -33,5 +40,6 @@ type Multimedia struct {
 	HLSManifest1080p   sql.NullString  `json:"hls_manifest_1080p,omitempty" db_field:"HLSManifest1080p" sql_type:"VARCHAR(255)"`      // Path relativo al BaseURL para 1080p (ej. 1080p/playlist.m3u8)
 	HLSManifest720p    sql.NullString  `json:"hls_manifest_720p,omitempty" db_field:"HLSManifest720p" sql_type:"VARCHAR(255)"`        // Path relativo para 720p
 	HLSManifest480p    sql.NullString  `json:"hls_manifest_480p,omitempty" db_field:"HLSManifest480p" sql_type:"VARCHAR(255)"`        // Path relativo para 480p
+	RefCount           int             `json:"ref_count" db_field:"RefCount" sql_type:"INT"`                                          // Mensajes (también archivados y reenvíos) que lo adjuntan
 	// Podríamos añadir más campos para DASH si fuera necesario
 }
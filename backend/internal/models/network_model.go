@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// MutualContactsResponse es la lista paginada de contactos en común con otro usuario.
+type MutualContactsResponse struct {
+	UserId     int64             `json:"userId"`
+	Data       []UserBaseInfo    `json:"data"`
+	Pagination PaginationDetails `json:"pagination"`
+}
+
+// ContactSuggestion es una persona que el usuario quizá conozca: un contacto de sus contactos
+// con quien todavía no tiene relación.
+type ContactSuggestion struct {
+	User           UserBaseInfo   `json:"user"`
+	MutualCount    int            `json:"mutualCount"`
+	MutualContacts []UserBaseInfo `json:"mutualContacts"` // Hasta 3, para mostrar "X y 2 más"
+}
+
+// ContactSuggestionsResponse es la lista paginada de sugerencias de contacto.
+type ContactSuggestionsResponse struct {
+	Data       []ContactSuggestion `json:"data"`
+	Pagination PaginationDetails   `json:"pagination"`
+}
+
+// NetworkSummary resume la red de contactos del usuario.
+type NetworkSummary struct {
+	TotalContacts   int            `json:"totalContacts"`
+	ContactsByRole  map[string]int `json:"contactsByRole"` // student, graduate, company
+	PendingReceived int            `json:"pendingReceived"`
+	PendingSent     int            `json:"pendingSent"`
+	SecondDegree    int            `json:"secondDegree"` // Contactos de sus contactos con quienes no tiene relación
+	ComputedAt      time.Time      `json:"computedAt"`
+}
+
+// NetworkSuggestionCandidate es una sugerencia tal como la calcula la base de datos, antes de
+// cargar la información de los usuarios.
+type NetworkSuggestionCandidate struct {
+	UserId      int64   `json:"userId"`
+	MutualCount int     `json:"mutualCount"`
+	MutualIds   []int64 `json:"mutualIds"`
+}
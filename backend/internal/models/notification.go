@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
 
 // Notification representa una notificación en el sistema
 type Notification struct {
@@ -17,3 +20,43 @@ type Notification struct {
 	Status         string     `json:"status"`
 	ActionTakenAt  *time.Time `json:"actionTakenAt,omitempty"`
 }
+
+// MaxNotificationBulkIDs es el número máximo de notificaciones por operación masiva.
+const MaxNotificationBulkIDs = 100
+
+// NotificationIDsRequest es el cuerpo de POST /notifications/read, /notifications/unread y
+// /notifications/delete.
+type NotificationIDsRequest struct {
+	Ids []int64 `json:"ids" validate:"required,min=1,max=100"`
+}
+
+// NotificationBulkResponse es la respuesta de las operaciones masivas sobre notificaciones.
+// Affected no cuenta las notificaciones de otros usuarios ni las que ya estaban en ese estado.
+type NotificationBulkResponse struct {
+	Affected int64 `json:"affected"`
+}
+
+// NotificationSync es el payload de read_sync con el que se avisa a los demás dispositivos
+// del usuario de un cambio masivo en sus notificaciones. Read indica el nuevo estado de
+// lectura; Deleted, que se borraron.
+type NotificationSync struct {
+	Resource        string   `json:"resource"` // Siempre "notification"
+	NotificationIds []string `json:"notificationIds"`
+	Read            *bool    `json:"read,omitempty"`
+	Deleted         bool     `json:"deleted,omitempty"`
+}
+
+// NewNotificationSync crea el aviso de un cambio de estado de lectura (deleted=false) o de un
+// borrado de las notificaciones ids.
+func NewNotificationSync(ids []int64, read, deleted bool) NotificationSync {
+	sync := NotificationSync{Resource: "notification", NotificationIds: make([]string, len(ids))}
+	for i, id := range ids {
+		sync.NotificationIds[i] = strconv.FormatInt(id, 10)
+	}
+	if deleted {
+		sync.Deleted = true
+	} else {
+		sync.Read = &read
+	}
+	return sync
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// OutboxTopicNotification es el topic de las notificaciones (tabla Event) que el servidor
+// WebSocket envía como new_notification.
+const OutboxTopicNotification = "notification"
+
+// OutboxMessage es un mensaje pendiente de enviar en tiempo real a un usuario. Con EventId el
+// servidor WebSocket envía la notificación; sin él, Topic es el tipo del mensaje WebSocket y
+// Payload su contenido.
+type OutboxMessage struct {
+	Id          int64           `json:"id"`
+	Topic       string          `json:"topic"`
+	UserId      int64           `json:"userId"`
+	EventId     sql.NullInt64   `json:"eventId"`
+	Payload     json.RawMessage `json:"payload,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	DeliveredAt sql.NullTime    `json:"deliveredAt"`
+
+	// Event es la notificación de EventId, cargada al leer el outbox. nil si ya no existe.
+	Event *Event `json:"-"`
+}
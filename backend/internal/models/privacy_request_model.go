@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Tipos de solicitud de privacidad.
+const (
+	PrivacyRequestExport   = "export"
+	PrivacyRequestDeletion = "deletion"
+)
+
+// Modos de borrado de una cuenta.
+const (
+	// DeletionModeAnonymize conserva los mensajes y publicaciones del usuario pero elimina
+	// sus datos personales y anonimiza la cuenta.
+	DeletionModeAnonymize = "anonymize"
+	// DeletionModeCascade elimina la cuenta y todo el contenido asociado.
+	DeletionModeCascade = "cascade"
+)
+
+// Estados de una solicitud de privacidad.
+const (
+	PrivacyRequestPending    = "pending"
+	PrivacyRequestProcessing = "processing"
+	PrivacyRequestCompleted  = "completed"
+	PrivacyRequestFailed     = "failed"
+)
+
+// PrivacyRequest es una solicitud de exportación de datos personales o de borrado de cuenta.
+type PrivacyRequest struct {
+	Id           int64      `json:"id"`
+	UserId       int64      `json:"userId"`
+	Type         string     `json:"type"`
+	DeletionMode string     `json:"deletionMode,omitempty"`
+	Status       string     `json:"status"`
+	Email        string     `json:"-"`
+	FilePath     string     `json:"-"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"` // Caducidad del ZIP de exportación
+	Error        string     `json:"error,omitempty"`
+	RequestedAt  time.Time  `json:"requestedAt"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty"`
+}
+
+// AccountDeletionRequest es el cuerpo de la solicitud de borrado de cuenta.
+type AccountDeletionRequest struct {
+	Password string `json:"password"`
+	Mode     string `json:"mode"` // anonymize (por defecto) o cascade
+}
@@ -0,0 +1,205 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Categorías de notificación push. Cada una se activa o desactiva en NotificationPreferences,
+// salvo PushCategoryAccount, que se envía siempre que el push esté habilitado.
+const (
+	PushCategoryChat            = "chat"
+	PushCategoryContactRequests = "contact_requests"
+	PushCategoryCommunity       = "community"
+	PushCategoryChallenges      = "challenges"
+	PushCategoryJobApplications = "job_applications"
+	PushCategorySavedSearches   = "saved_searches" // Alertas de búsquedas guardadas
+	PushCategoryAccount         = "account"        // Moderación y avisos de la cuenta
+)
+
+// PushDevice es un dispositivo registrado para recibir notificaciones push.
+type PushDevice struct {
+	Id         int64     `json:"id"`
+	UserId     int64     `json:"-"`
+	Provider   string    `json:"provider"`
+	Token      string    `json:"-"`
+	DeviceName string    `json:"deviceName,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// RegisterPushDeviceRequest es el cuerpo para registrar un dispositivo. El cliente debe
+// registrar el token cada vez que arranca o el proveedor lo rota.
+type RegisterPushDeviceRequest struct {
+	Provider   string `json:"provider"` // FCM o APNS
+	Token      string `json:"token"`
+	DeviceName string `json:"deviceName"`
+}
+
+// NotificationPreferences son las preferencias de notificaciones push y del resumen por
+// correo de un usuario.
+type NotificationPreferences struct {
+	PushEnabled     bool `json:"pushEnabled"`
+	ChatMessages    bool `json:"chatMessages"`
+	ChatPreviews    bool `json:"chatPreviews"` // Incluir el texto del mensaje en el push
+	ContactRequests bool `json:"contactRequests"`
+	Community       bool `json:"community"`
+	Challenges      bool `json:"challenges"`
+	JobApplications bool `json:"jobApplications"`
+	SavedSearches   bool `json:"savedSearches"`
+	EmailDigest     bool `json:"emailDigest"` // Resumen por correo de lo no leído
+	// No molestar: entre DndStart y DndEnd (HH:MM en DndTimezone) no se envían push ni avisos
+	// en tiempo real; al terminar se envía un resumen.
+	DndEnabled  bool       `json:"dndEnabled"`
+	DndStart    string     `json:"dndStart"`
+	DndEnd      string     `json:"dndEnd"`
+	DndTimezone string     `json:"dndTimezone"` // Zona horaria IANA, p. ej. America/Caracas
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+}
+
+// UpdateNotificationPreferencesRequest es el cuerpo de PUT /users/me/notification-preferences.
+// Los campos omitidos conservan su valor.
+type UpdateNotificationPreferencesRequest struct {
+	PushEnabled     *bool   `json:"pushEnabled"`
+	ChatMessages    *bool   `json:"chatMessages"`
+	ChatPreviews    *bool   `json:"chatPreviews"`
+	ContactRequests *bool   `json:"contactRequests"`
+	Community       *bool   `json:"community"`
+	Challenges      *bool   `json:"challenges"`
+	JobApplications *bool   `json:"jobApplications"`
+	SavedSearches   *bool   `json:"savedSearches"`
+	EmailDigest     *bool   `json:"emailDigest"`
+	DndEnabled      *bool   `json:"dndEnabled"`
+	DndStart        *string `json:"dndStart"`
+	DndEnd          *string `json:"dndEnd"`
+	DndTimezone     *string `json:"dndTimezone"`
+}
+
+// DefaultNotificationPreferences devuelve las preferencias de un usuario que no las ha
+// modificado (los DEFAULT de la tabla NotificationPreferences).
+func DefaultNotificationPreferences() NotificationPreferences {
+	return NotificationPreferences{
+		PushEnabled:     true,
+		ChatMessages:    true,
+		ChatPreviews:    true,
+		ContactRequests: true,
+		Community:       true,
+		Challenges:      true,
+		JobApplications: true,
+		SavedSearches:   true,
+		EmailDigest:     true,
+		DndStart:        "22:00",
+		DndEnd:          "07:00",
+		DndTimezone:     "UTC",
+	}
+}
+
+// Allows indica si el usuario quiere recibir notificaciones push de la categoría.
+func (p NotificationPreferences) Allows(category string) bool {
+	if !p.PushEnabled {
+		return false
+	}
+	switch category {
+	case PushCategoryChat:
+		return p.ChatMessages
+	case PushCategoryContactRequests:
+		return p.ContactRequests
+	case PushCategoryCommunity:
+		return p.Community
+	case PushCategoryChallenges:
+		return p.Challenges
+	case PushCategoryJobApplications:
+		return p.JobApplications
+	case PushCategorySavedSearches:
+		return p.SavedSearches
+	case PushCategoryAccount:
+		return true
+	}
+	return false
+}
+
+// dndClockLayout es el formato de DndStart y DndEnd.
+const dndClockLayout = "15:04"
+
+// ValidateDnd comprueba el horario de No molestar: horas HH:MM distintas y una zona horaria
+// IANA conocida.
+func (p NotificationPreferences) ValidateDnd() error {
+	start, err := time.Parse(dndClockLayout, p.DndStart)
+	if err != nil {
+		return errors.New("dndStart debe tener el formato HH:MM")
+	}
+	end, err := time.Parse(dndClockLayout, p.DndEnd)
+	if err != nil {
+		return errors.New("dndEnd debe tener el formato HH:MM")
+	}
+	if start.Equal(end) {
+		return errors.New("dndStart y dndEnd no pueden ser iguales")
+	}
+	if _, err := time.LoadLocation(p.DndTimezone); err != nil {
+		return errors.New("dndTimezone no es una zona horaria conocida")
+	}
+	return nil
+}
+
+// InDoNotDisturb indica si now cae dentro del horario de No molestar, en la hora local del
+// usuario. El horario puede cruzar la medianoche (22:00–07:00). Un horario inválido no se
+// aplica.
+func (p NotificationPreferences) InDoNotDisturb(now time.Time) bool {
+	if !p.DndEnabled {
+		return false
+	}
+	loc, err := time.LoadLocation(p.DndTimezone)
+	if err != nil {
+		return false
+	}
+	start, errStart := time.Parse(dndClockLayout, p.DndStart)
+	end, errEnd := time.Parse(dndClockLayout, p.DndEnd)
+	if errStart != nil || errEnd != nil {
+		return false
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from < to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// DndSummary es el resumen de lo recibido durante el horario de No molestar.
+type DndSummary struct {
+	Since         time.Time `json:"since"` // Primera notificación o mensaje silenciado
+	Until         time.Time `json:"until"`
+	Notifications int       `json:"notifications"` // Notificaciones sin leer recibidas
+	Messages      int       `json:"messages"`      // Mensajes de chats privados sin leer recibidos
+}
+
+// WebPushSubscription es una suscripción Web Push de un navegador del usuario.
+type WebPushSubscription struct {
+	Id        int64     `json:"id"`
+	UserId    int64     `json:"-"`
+	Endpoint  string    `json:"-"`
+	P256dh    string    `json:"-"`
+	Auth      string    `json:"-"`
+	UserAgent string    `json:"userAgent,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// RegisterWebPushSubscriptionRequest es el cuerpo para registrar una suscripción: el JSON que
+// devuelve PushSubscription.toJSON() en el navegador.
+type RegisterWebPushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// WebPushConfigResponse es la respuesta de GET /users/me/web-push/config.
+type WebPushConfigResponse struct {
+	Enabled   bool   `json:"enabled"`
+	PublicKey string `json:"publicKey,omitempty"` // applicationServerKey para PushManager.subscribe()
+}
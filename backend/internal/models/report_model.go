@@ -0,0 +1,144 @@
+package models
+
+import "time"
+
+// Tipos de contenido que se pueden reportar (ENUM Report.TargetType).
+const (
+	ReportTargetMessage = "MESSAGE"
+	ReportTargetPost    = "POST"
+	ReportTargetComment = "COMMENT"
+	ReportTargetProfile = "PROFILE"
+)
+
+// Motivos de un reporte (ENUM Report.Reason).
+const (
+	ReportReasonSpam          = "SPAM"
+	ReportReasonHarassment    = "ACOSO"
+	ReportReasonInappropriate = "CONTENIDO_INAPROPIADO"
+	ReportReasonFraud         = "FRAUDE"
+	ReportReasonImpersonation = "SUPLANTACION"
+	ReportReasonOther         = "OTRO"
+)
+
+// Estados de un reporte (ENUM Report.Status).
+const (
+	ReportStatusPending   = "PENDIENTE"
+	ReportStatusResolved  = "RESUELTO"
+	ReportStatusDismissed = "DESCARTADO"
+)
+
+// Acciones de moderación sobre el contenido (Report.ContentAction) y su autor
+// (Report.AuthorAction).
+const (
+	ContentActionNone   = "NINGUNA"
+	ContentActionHide   = "OCULTAR"
+	ContentActionDelete = "ELIMINAR"
+
+	AuthorActionNone    = "NINGUNA"
+	AuthorActionWarn    = "ADVERTIR"
+	AuthorActionSuspend = "SUSPENDER"
+)
+
+// Tipos de notificación (Event.EventType) de moderación. El servicio WebSocket los envía en
+// tiempo real a los usuarios conectados.
+const (
+	EventTypeReportResolved      = "REPORT_RESOLVED"
+	EventTypeModerationWarning   = "MODERATION_WARNING"
+	EventTypeModerationSuspended = "MODERATION_SUSPENDED"
+)
+
+// Report es un reporte de contenido en la cola de moderación.
+type Report struct {
+	Id              int64      `json:"id"`
+	ReporterId      int64      `json:"reporterId"`
+	TargetType      string     `json:"targetType"`
+	TargetId        string     `json:"targetId"`
+	TargetUserId    *int64     `json:"targetUserId,omitempty"`
+	Reason          string     `json:"reason"`
+	Details         string     `json:"details,omitempty"`
+	ContentSnapshot string     `json:"contentSnapshot,omitempty"`
+	Status          string     `json:"status"`
+	ContentAction   string     `json:"contentAction,omitempty"`
+	AuthorAction    string     `json:"authorAction,omitempty"`
+	ResolutionNote  string     `json:"resolutionNote,omitempty"`
+	ResolvedAt      *time.Time `json:"resolvedAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+
+	// Solo en la cola de moderación.
+	ReporterName   string `json:"reporterName,omitempty"`
+	TargetUserName string `json:"targetUserName,omitempty"`
+	PendingReports int    `json:"pendingReports,omitempty"` // Reportes pendientes del mismo contenido
+}
+
+// CreateReportRequest es el cuerpo de POST /reports. TargetId es el ID del mensaje,
+// publicación, comentario o usuario reportado.
+type CreateReportRequest struct {
+	TargetType string `json:"targetType"`
+	TargetId   string `json:"targetId"`
+	Reason     string `json:"reason"`
+	Details    string `json:"details"`
+}
+
+// ResolveReportRequest es el cuerpo con el que un administrador resuelve un reporte.
+// ContentAction y AuthorAction vacíos equivalen a NINGUNA.
+type ResolveReportRequest struct {
+	ContentAction string `json:"contentAction"`
+	AuthorAction  string `json:"authorAction"`
+	Note          string `json:"note"`
+}
+
+// DismissReportRequest es el cuerpo con el que un administrador descarta un reporte.
+type DismissReportRequest struct {
+	Note string `json:"note"`
+}
+
+// ReportedContent es el contenido reportado tal como se encontró al crear el reporte.
+type ReportedContent struct {
+	AuthorId int64
+	Snapshot string
+}
+
+// PaginatedReports es la respuesta paginada de la cola de moderación y de los reportes
+// propios.
+type PaginatedReports struct {
+	Data       []Report          `json:"data"`
+	Pagination PaginationDetails `json:"pagination"`
+}
+
+// ReportContextBundle es la copia del contexto de un mensaje reportado: los mensajes que lo
+// rodean en su chat y los participantes, tal como estaban al capturarlo. No cambia aunque los
+// mensajes se editen o eliminen después.
+type ReportContextBundle struct {
+	ReportId       int64                  `json:"reportId"`
+	MessageId      string                 `json:"messageId"`
+	ChatId         string                 `json:"chatId,omitempty"`
+	ChatIdGroup    string                 `json:"chatIdGroup,omitempty"`
+	MessagesBefore int                    `json:"messagesBefore"` // Ventana solicitada; puede haber menos mensajes
+	MessagesAfter  int                    `json:"messagesAfter"`
+	Participants   []UserBaseInfo         `json:"participants"`
+	Messages       []ReportContextMessage `json:"messages"`             // En orden cronológico, con el reportado
+	CapturedBy     *int64                 `json:"capturedBy,omitempty"` // Vacío si se capturó al crear el reporte
+	CapturedAt     time.Time              `json:"capturedAt"`
+}
+
+// ReportContextMessage es un mensaje dentro del contexto de un reporte. El contenido se copia
+// aunque el mensaje estuviera oculto por moderación.
+type ReportContextMessage struct {
+	Id               string     `json:"id"`
+	SenderId         int64      `json:"senderId"`
+	TypeMessageId    int64      `json:"typeMessageId"`
+	Content          string     `json:"content,omitempty"`
+	MediaId          string     `json:"mediaId,omitempty"`
+	ReplyToMessageId string     `json:"replyToMessageId,omitempty"`
+	SentAt           time.Time  `json:"sentAt"`
+	EditedAt         *time.Time `json:"editedAt,omitempty"`
+	IsHidden         bool       `json:"isHidden,omitempty"`
+	IsReported       bool       `json:"isReported,omitempty"` // El mensaje del reporte
+}
+
+// CaptureReportContextRequest es el cuerpo con el que un administrador captura el contexto de
+// un reporte. Los valores vacíos usan la ventana por defecto.
+type CaptureReportContextRequest struct {
+	Before int `json:"before"`
+	After  int `json:"after"`
+}
@@ -1,21 +1,23 @@
 package models
 
+import "time"
+
 // CreateReviewRequest define la estructura para la solicitud de creación de una reseña.
 // Contiene todos los datos necesarios para registrar una calificación en el sistema.
 type CreateReviewRequest struct {
 	// ID del usuario que está siendo calificado.
-	RevieweeID int64 `json:"revieweeId"`
+	RevieweeID int64 `json:"revieweeId" validate:"required,min=1"`
 
 	// ID del evento comunitario (oferta, desafío, etc.) que origina esta reseña.
 	// Es obligatorio para vincular la reseña a un contexto específico.
-	CommunityEventId int64 `json:"communityEventId"`
+	CommunityEventId int64 `json:"communityEventId" validate:"required,min=1"`
 
 	// Calificación en formato de estrellas, de 0 a 5.
 	// Se permite un decimal para calificaciones como 4.5.
-	Rating float64 `json:"rating"`
+	Rating float64 `json:"rating" validate:"min=0,max=5"`
 
 	// Comentario o feedback cualitativo sobre la interacción.
-	Comment string `json:"comment"`
+	Comment string `json:"comment" validate:"max=1000"`
 
 	// Tipo de interacción que originó la reseña (ej. "ENTREVISTA").
 	// Debe coincidir con los valores del ENUM en la base de datos.
@@ -25,3 +27,43 @@ type CreateReviewRequest struct {
 	// Corresponde a la condición de "3 estrellas extra".
 	ApplyBonus bool `json:"applyBonus"`
 }
+
+// InteractionRating agrupa la calificación promedio de un usuario para un tipo de interacción.
+type InteractionRating struct {
+	InteractionType string  `json:"interactionType"`
+	AverageRating   float64 `json:"averageRating"`
+	ReviewCount     int     `json:"reviewCount"`
+}
+
+// ReputationSummary contiene la reputación agregada de un usuario.
+type ReputationSummary struct {
+	UserID         int64               `json:"userId"`
+	TotalPointsRP  int                 `json:"totalPointsRp"`
+	ReviewCount    int                 `json:"reviewCount"`
+	AverageRating  float64             `json:"averageRating"`
+	PercentileRank float64             `json:"percentileRank"` // Porcentaje (0-100) de usuarios del mismo grupo con menos RP.
+	ByInteraction  []InteractionRating `json:"byInteraction"`
+}
+
+// LeaderboardEntry representa una posición dentro del ranking de reputación.
+type LeaderboardEntry struct {
+	Rank          int     `json:"rank"`
+	UserID        int64   `json:"userId"`
+	DisplayName   string  `json:"displayName"`
+	Picture       string  `json:"picture,omitempty"`
+	RoleID        int     `json:"roleId"`
+	TotalPointsRP int     `json:"totalPointsRp"`
+	AverageRating float64 `json:"averageRating"`
+	ReviewCount   int     `json:"reviewCount"`
+}
+
+// PaginatedLeaderboardResponse es la respuesta paginada del ranking de reputación.
+type PaginatedLeaderboardResponse struct {
+	Type         string             `json:"type"` // "students" o "companies"
+	CurrentPage  int                `json:"currentPage"`
+	PageSize     int                `json:"pageSize"`
+	TotalPages   int                `json:"totalPages"`
+	TotalRecords int                `json:"totalRecords"`
+	RefreshedAt  time.Time          `json:"refreshedAt"`
+	Entries      []LeaderboardEntry `json:"entries"`
+}
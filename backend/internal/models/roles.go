@@ -3,10 +3,84 @@ package models
 // UserRole represents the role of a user in the system.
 type UserRole int
 
-const (
+// Roles de usuario (User.RoleId). Son variables y no constantes porque los Id dependen de los
+// datos iniciales de la tabla Role: SetAccountIDs los sustituye al cargar la configuración
+// (ROLE_*_ID). Los valores por defecto son los del despliegue original.
+var (
 	RoleStudent  UserRole = 1
 	RoleEgresado UserRole = 2
 	RoleBusiness UserRole = 3
 	RoleGuest    UserRole = 4
 	RoleAdmin    UserRole = 8
 )
+
+// UserStatus es el estado de una cuenta (User.StatusAuthorizedId).
+type UserStatus int
+
+// Estados de cuenta. Como los roles, se pueden sustituir con la configuración
+// (USER_STATUS_*_ID).
+var (
+	// UserStatusActive es el estado de las cuentas de estudiantes y egresados al registrarse.
+	UserStatusActive UserStatus = 1
+	// UserStatusCompanyPending es el estado de las empresas registradas que un administrador
+	// aún no ha aprobado. Por defecto coincide con UserStatusActive.
+	UserStatusCompanyPending UserStatus = 1
+	// UserStatusCompanyApproved es el estado de las empresas aprobadas desde el panel.
+	UserStatusCompanyApproved UserStatus = 2
+	// UserStatusSuspended ("Suspended") es el estado de las cuentas suspendidas por
+	// moderación: no pueden iniciar sesión ni usar la API o el WebSocket.
+	UserStatusSuspended UserStatus = 3
+	// UserStatusDeactivated ("Closed") es el estado de las cuentas desactivadas por un
+	// administrador: como las suspendidas, no pueden iniciar sesión ni usar la API o el
+	// WebSocket. El estado anterior se guarda en UserDeactivation para restaurarlo al reactivar.
+	UserStatusDeactivated UserStatus = 4
+)
+
+// AccountIDs son los Id de Role y StatusAuthorized que el código usa con un significado fijo.
+type AccountIDs struct {
+	RoleStudent  int
+	RoleAlumni   int
+	RoleBusiness int
+	RoleGuest    int
+	RoleAdmin    int
+
+	StatusActive          int
+	StatusCompanyPending  int
+	StatusCompanyApproved int
+	StatusSuspended       int
+	StatusDeactivated     int
+}
+
+// DefaultAccountIDs devuelve los Id del despliegue original.
+func DefaultAccountIDs() AccountIDs {
+	return AccountIDs{
+		RoleStudent: 1, RoleAlumni: 2, RoleBusiness: 3, RoleGuest: 4, RoleAdmin: 8,
+		StatusActive: 1, StatusCompanyPending: 1, StatusCompanyApproved: 2, StatusSuspended: 3, StatusDeactivated: 4,
+	}
+}
+
+// SetAccountIDs sustituye los Id de los roles y estados. Se llama una vez al cargar la
+// configuración, antes de atender peticiones.
+func SetAccountIDs(ids AccountIDs) {
+	RoleStudent = UserRole(ids.RoleStudent)
+	RoleEgresado = UserRole(ids.RoleAlumni)
+	RoleBusiness = UserRole(ids.RoleBusiness)
+	RoleGuest = UserRole(ids.RoleGuest)
+	RoleAdmin = UserRole(ids.RoleAdmin)
+
+	UserStatusActive = UserStatus(ids.StatusActive)
+	UserStatusCompanyPending = UserStatus(ids.StatusCompanyPending)
+	UserStatusCompanyApproved = UserStatus(ids.StatusCompanyApproved)
+	UserStatusSuspended = UserStatus(ids.StatusSuspended)
+	UserStatusDeactivated = UserStatus(ids.StatusDeactivated)
+}
+
+// IsStudentRole indica si el rol es de estudiante o egresado.
+func IsStudentRole(roleID int) bool {
+	return roleID == int(RoleStudent) || roleID == int(RoleEgresado)
+}
+
+// IsAccountDisabled indica si el estado impide usar la cuenta (suspendida o desactivada).
+func IsAccountDisabled(statusID int) bool {
+	return statusID == int(UserStatusSuspended) || statusID == int(UserStatusDeactivated)
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Tipos de búsqueda guardada.
+const (
+	SavedSearchTypeJobs   = "JOBS"   // Publicaciones de la comunidad (ofertas, desafíos, eventos...)
+	SavedSearchTypePeople = "PEOPLE" // Estudiantes, egresados y empresas
+)
+
+// EventTypeSavedSearchMatch es la notificación de elementos nuevos que coinciden con una
+// búsqueda guardada.
+const EventTypeSavedSearchMatch = "SAVED_SEARCH_MATCH"
+
+// SavedSearchFilters son los filtros de una búsqueda guardada. Los de publicaciones
+// (postType) y los de personas (roleId, degree, university) solo se aplican a su tipo.
+type SavedSearchFilters struct {
+	PostType   string   `json:"postType,omitempty"`   // JOBS: EVENTO, NOTICIA, DESAFIO...
+	RoleId     int      `json:"roleId,omitempty"`     // PEOPLE: 1 estudiante, 2 egresado, 3 empresa
+	Degree     string   `json:"degree,omitempty"`     // PEOPLE: carrera de su formación
+	University string   `json:"university,omitempty"` // PEOPLE: institución de su formación
+	Location   string   `json:"location,omitempty"`
+	Skills     []string `json:"skills,omitempty"` // Todas deben estar presentes
+}
+
+// SavedSearch es una búsqueda guardada por el usuario. LastSeenItemId es el mayor ID de
+// publicación o usuario ya evaluado: las alertas solo consideran los posteriores.
+type SavedSearch struct {
+	Id             int64              `json:"id"`
+	UserId         int64              `json:"userId"`
+	Name           string             `json:"name"`
+	SearchType     string             `json:"searchType"`
+	Query          string             `json:"query"`
+	Filters        SavedSearchFilters `json:"filters"`
+	AlertsEnabled  bool               `json:"alertsEnabled"`
+	LastSeenItemId int64              `json:"-"`
+	LastAlertAt    *time.Time         `json:"lastAlertAt,omitempty"`
+	CreatedAt      time.Time          `json:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt"`
+}
+
+// SavedSearchRequest es el cuerpo para crear o reemplazar una búsqueda guardada. Si se omite
+// alertsEnabled, las alertas quedan activadas.
+type SavedSearchRequest struct {
+	Name          string             `json:"name"`
+	SearchType    string             `json:"searchType"`
+	Query         string             `json:"query"`
+	Filters       SavedSearchFilters `json:"filters"`
+	AlertsEnabled *bool              `json:"alertsEnabled"`
+}
@@ -15,8 +15,11 @@ type UniversalSearchParams struct {
 	Languages            []string
 	YearsOfExperienceMin int
 	YearsOfExperienceMax int
+	VerifiedOnly         bool // Solo estudiantes y egresados verificados con el correo institucional
 	Page                 int
 	Limit                int
+	ViewerID             int64 // Usuario que busca; se excluyen los usuarios con los que hay un bloqueo
+	TenantID             int64 // Institución de quien busca; 0 no limita (ver docs/multi_institucion.md)
 }
 
 // SearchResultProfile representa un perfil de usuario simplificado para los resultados de búsqueda.
@@ -34,6 +37,7 @@ type SearchResultProfile struct {
 	AverageRating     *float64 `json:"average_rating"`
 	RoleId            int64    `json:"role_id"`
 	Role              string   `json:"role"`
+	VerifiedStudent   bool     `json:"verified_student"` // Insignia de estudiante o egresado verificado
 }
 
 // UniversalSearchResponse es la estructura de respuesta que combina usuarios y eventos.
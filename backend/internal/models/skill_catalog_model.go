@@ -0,0 +1,37 @@
+package models
+
+// SkillCatalogItem es una habilidad canónica del catálogo. Las habilidades de los usuarios
+// (Skills) que la normalización asocia con ella guardan su Id en SkillCatalogId y su nombre en
+// Skill, de modo que "JS" y "javascript" se muestran y cuentan como "JavaScript".
+type SkillCatalogItem struct {
+	Id         int64    `json:"id" db:"Id"`
+	Name       string   `json:"name" db:"Name"`
+	Category   string   `json:"category" db:"Category"`
+	Aliases    []string `json:"aliases"`
+	UsageCount int      `json:"usage_count"` // Habilidades de usuarios asociadas; solo lectura
+}
+
+// SkillMatch es el resultado de normalizar una habilidad escrita por un usuario. Kind indica
+// cómo se encontró: exact, alias, phonetic o fuzzy.
+type SkillMatch struct {
+	SkillCatalogId int64  `json:"skill_catalog_id"`
+	Name           string `json:"name"`
+	Kind           string `json:"kind"`
+}
+
+// UnmappedSkill es un texto de habilidad que ninguna entrada del catálogo reconoce, con el
+// número de usuarios que lo usan. Sirve para decidir qué habilidades o alias añadir.
+type UnmappedSkill struct {
+	Skill string `json:"skill"`
+	Count int    `json:"count"`
+}
+
+// SkillMappingReport resume una ejecución de la migración que asocia las habilidades
+// existentes con el catálogo.
+type SkillMappingReport struct {
+	Scanned  int            `json:"scanned"`  // Filas sin SkillCatalogId revisadas
+	Mapped   int            `json:"mapped"`   // Filas asociadas a una habilidad canónica
+	Merged   int            `json:"merged"`   // Filas duplicadas eliminadas (mismo usuario y habilidad)
+	Unmapped int            `json:"unmapped"` // Filas que siguen sin asociar
+	ByKind   map[string]int `json:"by_kind"`  // Filas asociadas por tipo de coincidencia
+}
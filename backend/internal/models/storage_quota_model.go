@@ -0,0 +1,16 @@
+package models
+
+// StorageUsage es el espacio que ocupan los archivos subidos por un usuario y su cuota.
+type StorageUsage struct {
+	UserId         int64  `json:"userId"`
+	UsedBytes      int64  `json:"usedBytes"`
+	QuotaBytes     int64  `json:"quotaBytes"`     // 0 = sin límite
+	RemainingBytes *int64 `json:"remainingBytes"` // nil si no hay límite
+	Override       bool   `json:"override"`       // La cuota la fijó un administrador
+}
+
+// UpdateStorageQuotaRequest es el cuerpo de PUT /admin/users/{id}/storage-quota. quotaBytes
+// null vuelve a la cuota del rol; 0 quita el límite.
+type UpdateStorageQuotaRequest struct {
+	QuotaBytes *int64 `json:"quotaBytes"`
+}
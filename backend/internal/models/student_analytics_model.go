@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// PrivacySettings son las preferencias de privacidad de un usuario.
+type PrivacySettings struct {
+	// ShareProfileViews indica si los perfiles que visita pueden ver su identidad.
+	ShareProfileViews bool `json:"shareProfileViews"`
+	// TrackProfileViews indica si se registran las visitas a su propio perfil.
+	TrackProfileViews bool `json:"trackProfileViews"`
+	// LastSeenVisibility indica quién puede ver su última conexión.
+	LastSeenVisibility LastSeenVisibility `json:"lastSeenVisibility"`
+}
+
+// DefaultPrivacySettings son las preferencias de un usuario que no ha cambiado ninguna.
+func DefaultPrivacySettings() PrivacySettings {
+	return PrivacySettings{ShareProfileViews: true, TrackProfileViews: true, LastSeenVisibility: LastSeenEveryone}
+}
+
+// LastSeenVisibility es quién puede ver la última conexión ("última vez visto") de un usuario.
+type LastSeenVisibility string
+
+// Valores de LastSeenVisibility.
+const (
+	LastSeenEveryone LastSeenVisibility = "everyone" // Cualquier usuario que vea su perfil
+	LastSeenContacts LastSeenVisibility = "contacts" // Solo sus contactos aceptados
+	LastSeenNobody   LastSeenVisibility = "nobody"   // Nadie
+)
+
+// IsValid indica si v es uno de los valores admitidos.
+func (v LastSeenVisibility) IsValid() bool {
+	return v == LastSeenEveryone || v == LastSeenContacts || v == LastSeenNobody
+}
+
+// VisibleTo indica si la última conexión es visible para otro usuario; isContact indica si
+// ese usuario es un contacto aceptado.
+func (v LastSeenVisibility) VisibleTo(isContact bool) bool {
+	return v == LastSeenEveryone || (v == LastSeenContacts && isContact)
+}
+
+// UpdatePrivacySettingsRequest es el cuerpo de PUT /users/me/privacy-settings. Los campos
+// omitidos conservan su valor.
+type UpdatePrivacySettingsRequest struct {
+	ShareProfileViews  *bool               `json:"shareProfileViews"`
+	TrackProfileViews  *bool               `json:"trackProfileViews"`
+	LastSeenVisibility *LastSeenVisibility `json:"lastSeenVisibility"`
+}
+
+// StudentAnalytics agrupa las estadísticas de un estudiante o egresado en un periodo.
+type StudentAnalytics struct {
+	UserId          int64               `json:"userId"`
+	Days            int                 `json:"days"`
+	From            time.Time           `json:"from"`
+	ProfileViews    ProfileViewStats    `json:"profileViews"`
+	Applications    ApplicationStats    `json:"applications"`
+	FeedImpressions FeedImpressionStats `json:"feedImpressions"`
+	GeneratedAt     time.Time           `json:"generatedAt"`
+}
+
+// ProfileViewStats resume las visitas recibidas por el perfil.
+type ProfileViewStats struct {
+	Total         int64           `json:"total"`
+	FromCompanies int64           `json:"fromCompanies"`
+	Anonymous     int64           `json:"anonymous"`
+	Series        []DailyCount    `json:"series"`
+	RecentViewers []ProfileViewer `json:"recentViewers"` // Solo visitantes que comparten sus visitas
+}
+
+// ProfileViewer es un visitante identificado del perfil.
+type ProfileViewer struct {
+	UserId   int64     `json:"userId"`
+	RoleId   int       `json:"roleId"`
+	Name     string    `json:"name"` // Nombre de la empresa o nombre y apellido
+	Picture  string    `json:"picture,omitempty"`
+	ViewedAt time.Time `json:"viewedAt"`
+}
+
+// ApplicationStats desglosa por estado actual las postulaciones enviadas en el periodo.
+type ApplicationStats struct {
+	Total    int64            `json:"total"`
+	ByStatus map[string]int64 `json:"byStatus"`
+}
+
+// FeedImpressionStats cuenta las veces que el perfil apareció en el feed de otros usuarios.
+type FeedImpressionStats struct {
+	Total  int64        `json:"total"`
+	Series []DailyCount `json:"series"`
+}
+
+// ProfileViewedEvent es el payload que recibe por WebSocket un estudiante cuando una
+// empresa visita su perfil. Si la empresa oculta sus visitas solo se envía Anonymous.
+type ProfileViewedEvent struct {
+	Anonymous   bool      `json:"anonymous"`
+	CompanyId   int64     `json:"companyId,omitempty"`
+	CompanyName string    `json:"companyName,omitempty"`
+	ViewedAt    time.Time `json:"viewedAt"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Estados de la verificación de estudiante.
+const (
+	StudentVerificationNone     = "none"     // Nunca se solicitó
+	StudentVerificationPending  = "pending"  // Código enviado, sin confirmar
+	StudentVerificationVerified = "verified" // Correo institucional confirmado
+)
+
+// Tipos de insignia según el rol del usuario verificado.
+const (
+	StudentBadgeStudent = "student"
+	StudentBadgeAlumni  = "alumni"
+)
+
+// StudentVerificationRequest es el cuerpo de POST /users/me/student-verification.
+type StudentVerificationRequest struct {
+	Email string `json:"email" validate:"required,max=255"`
+}
+
+// StudentVerificationConfirmRequest es el cuerpo de POST /users/me/student-verification/confirm.
+type StudentVerificationConfirmRequest struct {
+	Code string `json:"code" validate:"required,max=16"`
+}
+
+// StudentVerification es el estado de la verificación de estudiante del usuario. Con un
+// código pendiente, Email y la universidad son los del código y Badge conserva la verificación
+// anterior, si la hay.
+type StudentVerification struct {
+	Status         string        `json:"status"` // none, pending o verified
+	Email          string        `json:"email,omitempty"`
+	UniversityId   int64         `json:"universityId,omitempty"`
+	UniversityName string        `json:"universityName,omitempty"`
+	CodeExpiresAt  *time.Time    `json:"codeExpiresAt,omitempty"`
+	Badge          *StudentBadge `json:"badge,omitempty"`
+}
+
+// StudentBadge es la insignia de estudiante o egresado verificado que muestra el perfil.
+type StudentBadge struct {
+	Kind           string    `json:"kind"` // student o alumni, según el rol actual del usuario
+	UniversityId   int64     `json:"universityId"`
+	UniversityName string    `json:"universityName"`
+	VerifiedAt     time.Time `json:"verifiedAt"`
+}
+
+// StudentBadgeKind devuelve el tipo de insignia para el rol, o "" si el rol no la admite.
+func StudentBadgeKind(roleID int) string {
+	switch UserRole(roleID) {
+	case RoleStudent:
+		return StudentBadgeStudent
+	case RoleEgresado:
+		return StudentBadgeAlumni
+	}
+	return ""
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// EventTypeUploadQuarantined notifica al autor y a los administradores que un archivo subido
+// se puso en cuarentena.
+const EventTypeUploadQuarantined = "UPLOAD_QUARANTINED"
+
+// QuarantinedFile es un archivo subido que el análisis de malware marcó como infectado. Se
+// registra en Multimedia con ProcessingStatus quarantined y se guarda como objeto privado en
+// ObjectPath, sin que el autor reciba su nombre.
+type QuarantinedFile struct {
+	MultimediaId string    `json:"multimediaId"`
+	UserId       int64     `json:"userId"`
+	Type         string    `json:"type"`
+	OriginalName string    `json:"originalName"`
+	ObjectPath   string    `json:"objectPath"`
+	Size         int64     `json:"size"`
+	Signature    string    `json:"signature"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// PaginatedQuarantineResponse es la respuesta paginada de los archivos en cuarentena.
+type PaginatedQuarantineResponse struct {
+	CurrentPage  int               `json:"currentPage"`
+	PageSize     int               `json:"pageSize"`
+	TotalPages   int               `json:"totalPages"`
+	TotalRecords int               `json:"totalRecords"`
+	Files        []QuarantinedFile `json:"files"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Estados de una subida reanudable.
+const (
+	UploadSessionUploading  = "uploading"
+	UploadSessionProcessing = "processing" // Se está procesando el archivo completo
+	UploadSessionCompleted  = "completed"
+	UploadSessionCancelled  = "cancelled"
+	UploadSessionExpired    = "expired"
+)
+
+// UploadSession es una subida de video por partes. Offset es el número de bytes recibidos: el
+// cliente envía la siguiente parte a partir de ahí.
+type UploadSession struct {
+	Id        string    `json:"id"`
+	UserId    int64     `json:"userId"`
+	FileName  string    `json:"fileName"`
+	Size      int64     `json:"size"`
+	Offset    int64     `json:"offset"`
+	Status    string    `json:"status"`
+	ContentId string    `json:"contentId,omitempty"` // Video resultante, al completarse
+	Error     string    `json:"error,omitempty"`     // Último error al procesar el archivo
+	ChunkSize int64     `json:"chunkSize"`           // Tamaño máximo de cada parte
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CreateUploadSessionRequest es el cuerpo de POST /videos/uploads.
+type CreateUploadSessionRequest struct {
+	FileName string `json:"fileName"`
+	Size     int64  `json:"size"`
+}
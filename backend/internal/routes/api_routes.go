@@ -11,7 +11,8 @@ package routes
  * define todas las rutas de la API REST del microservicio. La estructura sigue un
  * enfoque modular y agrupado por dominios para facilitar el mantenimiento.
  *
- * 1. Función principal `SetupApiRoutes` que orquesta la configuración de todos los grupos de rutas.
+ * 1. Función principal `SetupApiRoutes` que monta cada versión de la API (ver versioning.go) y
+ *    `setupVersionedRoutes`, que orquesta la configuración de todos los grupos de rutas.
  * 2. Grupos de rutas claramente definidos:
  *    - Rutas públicas (`setupPublicRoutes`)
  *    - Rutas de streaming (`setupStreamingRoutes`)
@@ -42,12 +43,21 @@ package routes
 
 import (
 	"database/sql"
+	"encoding/json"
 	"net/http"
-
-	"github.com/davidM20/micro-service-backend-go.git/internal/config"     // Importar config
-	"github.com/davidM20/micro-service-backend-go.git/internal/handlers"   // Crearemos este paquete
-	"github.com/davidM20/micro-service-backend-go.git/internal/middleware" // Importar middleware
-	"github.com/davidM20/micro-service-backend-go.git/internal/services"   // Necesario para inicializar ImageUploadService
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"      // Importar config
+	database "github.com/davidM20/micro-service-backend-go.git/internal/db" // db es el nombre del pool en este archivo
+	"github.com/davidM20/micro-service-backend-go.git/internal/graph"       // Endpoint GraphQL
+	"github.com/davidM20/micro-service-backend-go.git/internal/handlers"    // Crearemos este paquete
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"  // Importar middleware
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"    // Necesario para inicializar ImageUploadService
+	"github.com/davidM20/micro-service-backend-go.git/pkg/health"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/httpmetrics"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/imagemoderation"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/scan"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/storage"
 	"github.com/gorilla/mux"
 )
 
@@ -57,20 +67,65 @@ const (
 	APIPrefix = "/api/v1"
 )
 
+// Tamaño máximo del cuerpo de las rutas de subida. Incluye un margen sobre el máximo del
+// archivo para la codificación multipart. El resto de rutas usan API_MAX_BODY_SIZE.
+const (
+	maxImageUploadSize  = 20 << 20
+	maxAudioUploadSize  = 25 << 20
+	maxPDFUploadSize    = services.MaxPDFSize + 1<<20
+	maxVideoUploadSize  = services.MaxVideoSize + 10<<20
+	maxUploadChunkSize  = services.MaxUploadChunkSize
+	maxFilterImportSize = 5 << 20
+)
+
 // SetupApiRoutes configura todas las rutas para el microservicio de API REST
 // siguiendo un enfoque modular inspirado en frameworks como Gin.
-func SetupApiRoutes(r *mux.Router, db *sql.DB, cfg *config.Config) {
+// metrics recibe las métricas por ruta del log de peticiones (middleware.RequestLogging).
+// scanner es el análisis de malware de las subidas y classifier el clasificador NSFW de las
+// imágenes; cada uno es nil si no está configurado.
+func SetupApiRoutes(r *mux.Router, db *sql.DB, cfg *config.Config, metrics *httpmetrics.Registry, store storage.Storage, scanner scan.Scanner, classifier imagemoderation.Classifier) {
 	// Crear instancias de los handlers
-	handlers := initializeHandlers(db, cfg)
+	handlers := initializeHandlers(db, cfg, metrics, store, scanner, classifier)
+
+	// Informar la plantilla de ruta al log de peticiones y aplicar los límites de tamaño y
+	// tiempo de las peticiones (las subidas los amplían con middleware.LargeTransfer)
+	r.Use(middleware.RequestRoute)
+	r.Use(middleware.RequestLimits(cfg))
+	// Con la BD en modo solo lectura las escrituras se rechazan antes de llegar al handler
+	r.Use(middleware.ReadOnly)
+	// Institución del subdominio de la petición, con TENANCY_ENABLED (docs/multi_institucion.md)
+	r.Use(middleware.Tenant(services.NewTenantResolver(cfg)))
+
+	// Probes de liveness/readiness en la raíz, fuera del prefijo versionado
+	setupProbeRoutes(r, db, cfg, store, scanner, classifier)
+
+	// Con el almacenamiento local la propia API sirve los archivos (públicos y enlaces firmados)
+	if local, ok := store.(*storage.Local); ok {
+		prefix := strings.TrimSuffix(cfg.StorageLocalBaseURL, "/")
+		r.PathPrefix(prefix+"/").Handler(middleware.LargeTransfer(0)(http.StripPrefix(prefix, local.Handler()).ServeHTTP)).
+			Methods(http.MethodGet, http.MethodHead)
+	}
 
-	// Crear subrouter para la API con prefijo /api/v1
-	api := r.PathPrefix(APIPrefix).Subrouter()
+	// Montar las mismas rutas bajo cada versión (/api/v2, /api/v1 y /api obsoleto), con su
+	// especificación OpenAPI (ver openapi.go)
+	for _, version := range apiVersions(cfg) {
+		api := mountAPIVersion(r, version)
+		if cfg.APIDocsEnabled {
+			setupOpenAPIRoutes(api, handlers, db, cfg, version)
+		}
+		setupVersionedRoutes(api, handlers, db, cfg)
+		if cfg.APIRequestValidation {
+			applyRequestValidation(api, version)
+		}
+	}
+}
 
-	// Configurar grupos de rutas
-	setupPublicRoutes(api, handlers)
-	setupStreamingRoutes(api, handlers)
-	setupProtectedRoutes(api, handlers, cfg)
-	setupAdminRoutes(api, handlers.adminHandler, db, cfg)
+// setupVersionedRoutes configura los grupos de rutas de una versión de la API.
+func setupVersionedRoutes(api *mux.Router, h serviceHandlers, db *sql.DB, cfg *config.Config) {
+	setupPublicRoutes(api, h)
+	setupStreamingRoutes(api, h)
+	setupProtectedRoutes(api, h, cfg)
+	setupAdminRoutes(api, h, db, cfg)
 }
 
 // Estructura para agrupar todos los handlers y facilitar su paso a las funciones
@@ -91,18 +146,63 @@ type serviceHandlers struct {
 	notificationHandler   *handlers.NotificationHandler
 	jobApplicationHandler *handlers.JobApplicationHandler
 	reputationHandler     *handlers.ReputationHandler
+	cvExportHandler       *handlers.CVExportHandler
+	cvImportHandler       *handlers.CVImportHandler
+	retentionHandler      *handlers.MessageRetentionHandler
+	auditLogHandler       *handlers.AuditLogHandler
+	privacyHandler        *handlers.PrivacyHandler
+	companyDashHandler    *handlers.CompanyDashboardHandler
+	studentAnalytics      *handlers.StudentAnalyticsHandler
+	commentHandler        *handlers.CommentHandler
+	engagementHandler     *handlers.EngagementHandler
+	challengeHandler      *handlers.ChallengeHandler
+	moderationHandler     *handlers.ModerationHandler
+	adminUserHandler      *handlers.AdminUserHandler
+	impersonationHandler  *handlers.ImpersonationHandler
+	catalogHandler        *handlers.CatalogHandler
+	skillHandler          *handlers.SkillHandler
+	jobMatchingHandler    *handlers.JobMatchingHandler
+	savedSearchHandler    *handlers.SavedSearchHandler
+	networkHandler        *handlers.NetworkHandler
+	blockHandler          *handlers.BlockHandler
+	chatExportHandler     *handlers.ChatExportHandler
+	contentFilterHandler  *handlers.ContentFilterHandler
+	pushHandler           *handlers.PushHandler
+	httpMetricsHandler    *handlers.HTTPMetricsHandler
+	uploadSessionHandler  *handlers.UploadSessionHandler
+	storageQuotaHandler   *handlers.StorageQuotaHandler
+	storageQuotaService   services.IStorageQuotaService
+	uploadScanHandler     *handlers.UploadScanHandler
+	studentVerification   *handlers.StudentVerificationHandler
+	cohortAnalytics       *handlers.CohortAnalyticsHandler
+	adminExport           *handlers.AdminExportHandler
 }
 
 // initializeHandlers crea e inicializa todas las instancias de handlers necesarias
-func initializeHandlers(db *sql.DB, cfg *config.Config) serviceHandlers {
+func initializeHandlers(db *sql.DB, cfg *config.Config, metrics *httpmetrics.Registry, store storage.Storage, scanner scan.Scanner, classifier imagemoderation.Classifier) serviceHandlers {
 	// Inicializar servicios primero si los handlers dependen de ellos
-	imageUploadService := services.NewImageUploadService(db, cfg)
-	audioUploadService := services.NewAudioUploadService(db, cfg)
-	pdfUploadService := services.NewPDFUploadService(db, cfg)
-	videoUploadService := services.NewVideoUploadService(db, cfg)
+	uploadScanService := services.NewUploadScanService(cfg, scanner, store)
+	imageModerationService := services.NewImageModerationService(cfg, classifier)
+	imageUploadService := services.NewImageUploadService(db, cfg, store, uploadScanService, imageModerationService)
+	audioUploadService := services.NewAudioUploadService(db, cfg, store, uploadScanService)
+	pdfUploadService := services.NewPDFUploadService(db, cfg, uploadScanService)
+	videoUploadService := services.NewVideoUploadService(db, cfg, store, uploadScanService)
 	searchService := services.NewSearchService(db)
 	jobApplicationService := services.NewJobApplicationService(db)
 	reputationService := services.NewReputationService(db)
+	cvExportService := services.NewCVExportService(db)
+	cvImportService := services.NewCVImportService(db)
+	retentionService := services.NewMessageRetentionService(db, cfg)
+	auditService := services.NewAuditService()
+	privacyService := services.NewPrivacyService(db, cfg, middleware.ForgetAccountState)
+	companyDashboardService := services.NewCompanyDashboardService(db)
+	studentAnalyticsService := services.NewStudentAnalyticsService(db)
+	commentService := services.NewCommentService(db, cfg)
+	engagementService := services.NewEngagementService(db, cfg)
+	challengeService := services.NewChallengeService(db)
+	moderationService := services.NewModerationService(db, store)
+	skillService := services.NewSkillService(db)
+	storageQuotaService := services.NewStorageQuotaService(cfg)
 
 	return serviceHandlers{
 		authHandler:           handlers.NewAuthHandler(db, cfg),
@@ -112,15 +212,45 @@ func initializeHandlers(db *sql.DB, cfg *config.Config) serviceHandlers {
 		mediaHandler:          handlers.NewMediaHandler(db, cfg),
 		categoryHandler:       handlers.NewCategoryHandler(),
 		communityEventHandler: handlers.NewCommunityEventHandler(db, cfg),
-		imageHandler:          handlers.NewImageHandler(imageUploadService, cfg, db),
-		audioHandler:          handlers.NewAudioHandler(audioUploadService, cfg),
+		imageHandler:          handlers.NewImageHandler(imageUploadService, cfg, db, store),
+		audioHandler:          handlers.NewAudioHandler(audioUploadService, cfg, store),
 		pdfHandler:            handlers.NewPDFHandler(pdfUploadService, cfg),
-		videoHandler:          handlers.NewVideoHandler(videoUploadService, db, cfg),
+		videoHandler:          handlers.NewVideoHandler(videoUploadService, db, cfg, store),
 		searchHandler:         handlers.NewSearchHandler(searchService),
 		adminHandler:          handlers.NewAdminHandler(db, cfg),
 		notificationHandler:   handlers.NewNotificationHandler(db),
-		jobApplicationHandler: handlers.NewJobApplicationHandler(jobApplicationService, db),
+		jobApplicationHandler: handlers.NewJobApplicationHandler(jobApplicationService, db, cfg),
 		reputationHandler:     handlers.NewReputationHandler(reputationService),
+		cvExportHandler:       handlers.NewCVExportHandler(cvExportService),
+		cvImportHandler:       handlers.NewCVImportHandler(cvImportService),
+		retentionHandler:      handlers.NewMessageRetentionHandler(retentionService),
+		auditLogHandler:       handlers.NewAuditLogHandler(auditService),
+		privacyHandler:        handlers.NewPrivacyHandler(privacyService),
+		companyDashHandler:    handlers.NewCompanyDashboardHandler(companyDashboardService),
+		studentAnalytics:      handlers.NewStudentAnalyticsHandler(studentAnalyticsService),
+		commentHandler:        handlers.NewCommentHandler(commentService),
+		engagementHandler:     handlers.NewEngagementHandler(engagementService),
+		challengeHandler:      handlers.NewChallengeHandler(challengeService),
+		moderationHandler:     handlers.NewModerationHandler(moderationService),
+		adminUserHandler:      handlers.NewAdminUserHandler(db, services.NewAdminUserService(db)),
+		impersonationHandler:  handlers.NewImpersonationHandler(services.NewImpersonationService(db, cfg)),
+		catalogHandler:        handlers.NewCatalogHandler(services.NewCatalogService(db)),
+		skillHandler:          handlers.NewSkillHandler(skillService),
+		jobMatchingHandler:    handlers.NewJobMatchingHandler(services.NewJobMatchingService(db, skillService)),
+		savedSearchHandler:    handlers.NewSavedSearchHandler(services.NewSavedSearchService(db, skillService)),
+		networkHandler:        handlers.NewNetworkHandler(services.NewNetworkService(db)),
+		blockHandler:          handlers.NewBlockHandler(services.NewBlockService(db)),
+		chatExportHandler:     handlers.NewChatExportHandler(services.NewChatExportService(db, cfg)),
+		contentFilterHandler:  handlers.NewContentFilterHandler(services.NewContentFilterService(db, cfg)),
+		pushHandler:           handlers.NewPushHandler(services.NewPushDeviceService(db, cfg)),
+		httpMetricsHandler:    handlers.NewHTTPMetricsHandler(metrics),
+		uploadSessionHandler:  handlers.NewUploadSessionHandler(services.NewUploadSessionService(cfg, videoUploadService, storageQuotaService)),
+		storageQuotaHandler:   handlers.NewStorageQuotaHandler(storageQuotaService),
+		storageQuotaService:   storageQuotaService,
+		studentVerification:   handlers.NewStudentVerificationHandler(services.NewStudentVerificationService(db, cfg)),
+		uploadScanHandler:     handlers.NewUploadScanHandler(uploadScanService),
+		cohortAnalytics:       handlers.NewCohortAnalyticsHandler(services.NewCohortAnalyticsService()),
+		adminExport:           handlers.NewAdminExportHandler(services.NewAdminExportService()),
 	}
 }
 
@@ -137,12 +267,41 @@ func setupPublicRoutes(api *mux.Router, h serviceHandlers) {
 	setupPublicAuthRoutes(api, h.authHandler)
 	setupPublicEnterpriseRoutes(api, h.enterpriseHandler)
 	setupPublicCategoryRoutes(api, h.categoryHandler)
-	setupPublicMiscRoutes(api, h.miscHandler)
+	setupPublicMiscRoutes(api, h.miscHandler, h.catalogHandler, h.skillHandler)
+}
+
+// setupProbeRoutes configura /healthz (liveness) y /readyz (readiness con comprobación de BD, del
+// modo solo lectura y del almacenamiento de archivos, el escáner de malware y el clasificador
+// de imágenes, si están configurados)
+func setupProbeRoutes(router *mux.Router, db *sql.DB, cfg *config.Config, store storage.Storage, scanner scan.Scanner, classifier imagemoderation.Classifier) {
+	checker := health.NewChecker("api")
+	checker.Register("database", health.DBCheck(db))
+	if database.CurrentReadOnlyState().Enabled {
+		checker.Register("database_writes", database.ReadOnlyCheck)
+	}
+	if store != storage.Unavailable() {
+		checker.Register("storage", store.Ping)
+	}
+	if scanner != nil {
+		checker.Register("scanner", scanner.Ping)
+	}
+	if classifier != nil {
+		checker.Register("image_moderation", classifier.Ping)
+	}
+
+	router.HandleFunc("/healthz", checker.LivenessHandler()).Methods(http.MethodGet)
+	router.HandleFunc("/readyz", checker.ReadinessHandler()).Methods(http.MethodGet)
 }
 
-// setupHealthRoutes configura las rutas de verificación de estado del sistema
+// setupHealthRoutes configura las rutas de verificación de estado del sistema.
+// Desde v2 la respuesta es JSON; v1 mantiene el texto plano.
 func setupHealthRoutes(router *mux.Router) {
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		if version := middleware.APIVersion(r); version != middleware.APIVersion1 {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]string{"status": "ok", "version": version})
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("API is healthy"))
 	}).Methods(http.MethodGet)
@@ -175,19 +334,25 @@ func setupPublicEnterpriseRoutes(router *mux.Router, enterpriseHandler *handlers
 
 // setupPublicCategoryRoutes configura las rutas públicas para categorías
 func setupPublicCategoryRoutes(router *mux.Router, categoryHandler *handlers.CategoryHandler) {
-	router.HandleFunc("/categories", categoryHandler.ListCategories).Methods(http.MethodGet)
+	router.Handle("/categories", middleware.ETag(middleware.CacheControlCatalog)(categoryHandler.ListCategories)).Methods(http.MethodGet)
 }
 
 // setupPublicMiscRoutes configura las rutas públicas para datos misceláneos
-func setupPublicMiscRoutes(router *mux.Router, miscHandler *handlers.MiscHandler) {
-	router.HandleFunc("/nationalities", miscHandler.GetNationalities).Methods(http.MethodGet)
-	router.HandleFunc("/universities", miscHandler.GetUniversities).Methods(http.MethodGet)
-	router.HandleFunc("/degrees/{universityID:[0-9]+}", miscHandler.GetDegreesByUniversity).Methods(http.MethodGet)
+func setupPublicMiscRoutes(router *mux.Router, miscHandler *handlers.MiscHandler, catalogHandler *handlers.CatalogHandler, skillHandler *handlers.SkillHandler) {
+	// Catálogos con ETag: los clientes revalidan con If-None-Match y reciben 304 si no cambiaron
+	catalog := middleware.ETag(middleware.CacheControlCatalog)
+	router.Handle("/nationalities", catalog(miscHandler.GetNationalities)).Methods(http.MethodGet)
+	router.Handle("/universities", catalog(miscHandler.GetUniversities)).Methods(http.MethodGet)
+	router.Handle("/degrees/{universityID:[0-9]+}", catalog(miscHandler.GetDegreesByUniversity)).Methods(http.MethodGet)
+	// Los tres catálogos juntos, para llenar los selectores con una sola petición
+	router.Handle("/catalogs", catalog(catalogHandler.GetCatalogs)).Methods(http.MethodGet)
+	// Habilidades canónicas y autocompletado (?q=)
+	router.Handle("/skills", catalog(skillHandler.GetSkills)).Methods(http.MethodGet)
+	router.Handle("/skills/suggest", catalog(skillHandler.SuggestSkills)).Methods(http.MethodGet)
 
 	// TODO: Evaluar si estas rutas deberían requerir autenticación
 	// Rutas comentadas pendientes de implementación:
 	// - GET /languages - Falta implementar el handler
-	// - GET /skills - Handler existe pero causa error
 }
 
 // ---------------------------------------------------------------------------------
@@ -217,7 +382,7 @@ func setupStreamingRoutes(api *mux.Router, h serviceHandlers) {
 	videoRouter := api.PathPrefix("/videos/stream").Subrouter()
 	{
 		videoRouter.HandleFunc("/{contentID}/master.m3u8", h.videoHandler.StreamVideoMasterPlaylist).Methods(http.MethodGet)
-		videoRouter.HandleFunc("/{contentID}/{quality}/{fileName:.+}", h.videoHandler.StreamVideoVariant).Methods(http.MethodGet)
+		videoRouter.Handle("/{contentID}/{quality}/{fileName:.+}", middleware.LargeTransfer(0)(h.videoHandler.StreamVideoVariant)).Methods(http.MethodGet)
 	}
 
 	// Ruta para ver foto de perfil de usuario
@@ -234,15 +399,28 @@ func setupProtectedRoutes(api *mux.Router, h serviceHandlers, cfg *config.Config
 
 	// Agrupar por dominio para mayor claridad
 	setupAuthProtectedRoutes(protected, h.authHandler)
-	setupUserProtectedRoutes(protected, h.userHandler, h.imageHandler)
-	setupEnterpriseProtectedRoutes(protected, h.enterpriseHandler)
+	setupUserProtectedRoutes(protected, h)
+	setupEnterpriseProtectedRoutes(protected, h)
 	setupCategoryProtectedRoutes(protected, h.categoryHandler)
 	setupMediaProtectedRoutes(protected, h)
 	setupCommunityEventsProtectedRoutes(protected, h.communityEventHandler)
 	setupJobApplicationProtectedRoutes(protected, h.jobApplicationHandler)
+	setupJobMatchingProtectedRoutes(protected, h.jobMatchingHandler)
+	setupCommentProtectedRoutes(protected, h.commentHandler)
+	setupEngagementProtectedRoutes(protected, h.engagementHandler)
+	setupChallengeProtectedRoutes(protected, h.challengeHandler)
+	setupModerationProtectedRoutes(protected, h.moderationHandler)
 	setupReputationProtectedRoutes(protected, h.reputationHandler)
 	setupNotificationProtectedRoutes(protected, h.notificationHandler)
 	setupSearchProtectedRoutes(protected, h.searchHandler)
+	if cfg.GraphQLEnabled {
+		setupGraphQLProtectedRoutes(protected, cfg)
+	}
+}
+
+// setupGraphQLProtectedRoutes configura el endpoint GraphQL de solo lectura (docs/graphql.md)
+func setupGraphQLProtectedRoutes(router *mux.Router, cfg *config.Config) {
+	router.Handle("/graphql", graph.NewHandler(cfg)).Methods(http.MethodGet, http.MethodPost)
 }
 
 // setupAuthProtectedRoutes configura las rutas protegidas de registro (pasos 2 y 3)
@@ -255,21 +433,75 @@ func setupAuthProtectedRoutes(router *mux.Router, authHandler *handlers.AuthHand
 }
 
 // setupUserProtectedRoutes configura las rutas protegidas del perfil de usuario
-func setupUserProtectedRoutes(router *mux.Router, userHandler *handlers.UserHandler, imageHandler *handlers.ImageHandler) {
+func setupUserProtectedRoutes(router *mux.Router, h serviceHandlers) {
 	userRouter := router.PathPrefix("/users").Subrouter()
 	{
 		meRouter := userRouter.PathPrefix("/me").Subrouter()
-		meRouter.HandleFunc("", userHandler.GetMyProfile).Methods(http.MethodGet)
-		meRouter.HandleFunc("", userHandler.UpdateMyProfile).Methods(http.MethodPut)
-		meRouter.HandleFunc("/picture", imageHandler.UpdateProfilePicture).Methods(http.MethodPost)
+		meRouter.Handle("", middleware.ETag(middleware.CacheControlPrivate)(h.userHandler.GetMyProfile)).Methods(http.MethodGet)
+		meRouter.HandleFunc("", h.userHandler.UpdateMyProfile).Methods(http.MethodPut)
+		meRouter.Handle("/picture", middleware.LargeTransfer(maxImageUploadSize)(middleware.StorageQuota(h.storageQuotaService)(h.imageHandler.UpdateProfilePicture))).Methods(http.MethodPost)
+		meRouter.HandleFunc("/storage", h.storageQuotaHandler.GetUsage).Methods(http.MethodGet)
+		meRouter.HandleFunc("/analytics", h.studentAnalytics.GetMyAnalytics).Methods(http.MethodGet)
+		meRouter.HandleFunc("/recommended-jobs", h.jobMatchingHandler.RecommendJobs).Methods(http.MethodGet)
+		meRouter.HandleFunc("/saved-items", h.engagementHandler.ListMySavedItems).Methods(http.MethodGet)
+		meRouter.HandleFunc("/network/suggestions", h.networkHandler.GetSuggestions).Methods(http.MethodGet)
+		meRouter.HandleFunc("/network/summary", h.networkHandler.GetSummary).Methods(http.MethodGet)
+		meRouter.HandleFunc("/saved-searches", h.savedSearchHandler.List).Methods(http.MethodGet)
+		meRouter.HandleFunc("/saved-searches", h.savedSearchHandler.Create).Methods(http.MethodPost)
+		meRouter.HandleFunc("/saved-searches/{id:[0-9]+}", h.savedSearchHandler.Update).Methods(http.MethodPut)
+		meRouter.HandleFunc("/saved-searches/{id:[0-9]+}", h.savedSearchHandler.Delete).Methods(http.MethodDelete)
+		meRouter.HandleFunc("/blocks", h.blockHandler.List).Methods(http.MethodGet)
+		meRouter.HandleFunc("/blocks/{userID:[0-9]+}", h.blockHandler.Block).Methods(http.MethodPut)
+		meRouter.HandleFunc("/blocks/{userID:[0-9]+}", h.blockHandler.Unblock).Methods(http.MethodDelete)
+		meRouter.HandleFunc("/reports", h.moderationHandler.ListMyReports).Methods(http.MethodGet)
+
+		// Las rutas de la cuenta que exportan datos personales, la borran o registran
+		// dispositivos no se pueden usar con un token de suplantación (soporte).
+		noImpersonation := middleware.NoImpersonation
+
+		// CV: exportarlo saca datos personales e importarlo lo reemplaza
+		meRouter.HandleFunc("/cv/export", noImpersonation(h.cvExportHandler.ExportMyCV)).Methods(http.MethodGet)
+		meRouter.HandleFunc("/cv/import", noImpersonation(h.cvImportHandler.ImportMyCV)).Methods(http.MethodPost)
+
+		// Notificaciones push: dispositivos registrados y preferencias
+		meRouter.HandleFunc("/devices", noImpersonation(h.pushHandler.ListDevices)).Methods(http.MethodGet)
+		meRouter.HandleFunc("/devices", noImpersonation(h.pushHandler.RegisterDevice)).Methods(http.MethodPost)
+		meRouter.HandleFunc("/devices/{id:[0-9]+}", noImpersonation(h.pushHandler.DeleteDevice)).Methods(http.MethodDelete)
+		meRouter.HandleFunc("/notification-preferences", h.pushHandler.GetPreferences).Methods(http.MethodGet)
+		meRouter.HandleFunc("/notification-preferences", h.pushHandler.UpdatePreferences).Methods(http.MethodPut)
+		meRouter.HandleFunc("/web-push/config", h.pushHandler.GetWebPushConfig).Methods(http.MethodGet)
+		meRouter.HandleFunc("/web-push/subscriptions", noImpersonation(h.pushHandler.ListWebPushSubscriptions)).Methods(http.MethodGet)
+		meRouter.HandleFunc("/web-push/subscriptions", noImpersonation(h.pushHandler.RegisterWebPushSubscription)).Methods(http.MethodPost)
+		meRouter.HandleFunc("/web-push/subscriptions/{id:[0-9]+}", noImpersonation(h.pushHandler.DeleteWebPushSubscription)).Methods(http.MethodDelete)
+
+		// Privacidad: exportación de datos personales y borrado de la cuenta
+		meRouter.HandleFunc("/privacy-requests", noImpersonation(h.privacyHandler.ListRequests)).Methods(http.MethodGet)
+		meRouter.HandleFunc("/data-export", noImpersonation(h.privacyHandler.RequestDataExport)).Methods(http.MethodPost)
+		meRouter.Handle("/data-export/{requestID:[0-9]+}/download", middleware.LargeTransfer(0)(noImpersonation(h.privacyHandler.DownloadDataExport))).Methods(http.MethodGet)
+		meRouter.HandleFunc("/deletion", noImpersonation(h.privacyHandler.RequestAccountDeletion)).Methods(http.MethodPost)
+		meRouter.HandleFunc("/privacy-settings", noImpersonation(h.privacyHandler.GetSettings)).Methods(http.MethodGet)
+		meRouter.HandleFunc("/privacy-settings", noImpersonation(h.privacyHandler.UpdateSettings)).Methods(http.MethodPut)
+
+		// Verificación de estudiante con el correo institucional
+		meRouter.HandleFunc("/student-verification", noImpersonation(h.studentVerification.GetStatus)).Methods(http.MethodGet)
+		meRouter.HandleFunc("/student-verification", noImpersonation(h.studentVerification.RequestCode)).Methods(http.MethodPost)
+		meRouter.HandleFunc("/student-verification/confirm", noImpersonation(h.studentVerification.Confirm)).Methods(http.MethodPost)
+
+		// Exportación de conversaciones
+		meRouter.HandleFunc("/chat-exports", noImpersonation(h.chatExportHandler.List)).Methods(http.MethodGet)
+		meRouter.HandleFunc("/chat-exports", noImpersonation(h.chatExportHandler.Create)).Methods(http.MethodPost)
+		meRouter.HandleFunc("/chat-exports/{id:[0-9]+}", noImpersonation(h.chatExportHandler.Get)).Methods(http.MethodGet)
+
+		userRouter.HandleFunc("/{userID:[0-9]+}/mutual-contacts", h.networkHandler.GetMutualContacts).Methods(http.MethodGet)
 	}
 }
 
 // setupEnterpriseProtectedRoutes configura las rutas protegidas para empresas
-func setupEnterpriseProtectedRoutes(router *mux.Router, enterpriseHandler *handlers.EnterpriseHandler) {
+func setupEnterpriseProtectedRoutes(router *mux.Router, h serviceHandlers) {
 	enterpriseRouter := router.PathPrefix("/enterprises").Subrouter()
 	{
-		enterpriseRouter.HandleFunc("/me", enterpriseHandler.UpdateEnterpriseProfile).Methods(http.MethodPut)
+		enterpriseRouter.HandleFunc("/me", h.enterpriseHandler.UpdateEnterpriseProfile).Methods(http.MethodPut)
+		enterpriseRouter.HandleFunc("/me/dashboard", h.companyDashHandler.GetMyDashboard).Methods(http.MethodGet)
 	}
 }
 
@@ -280,11 +512,25 @@ func setupCategoryProtectedRoutes(router *mux.Router, categoryHandler *handlers.
 
 // setupMediaProtectedRoutes configura las rutas protegidas para subida de multimedia
 func setupMediaProtectedRoutes(router *mux.Router, h serviceHandlers) {
-	router.HandleFunc("/media/upload", h.mediaHandler.UploadMedia).Methods(http.MethodPost)
-	router.HandleFunc("/images/upload", h.imageHandler.UploadImage).Methods(http.MethodPost)
-	router.HandleFunc("/audios/upload", h.audioHandler.UploadAudio).Methods(http.MethodPost)
-	router.HandleFunc("/pdfs/upload", h.pdfHandler.UploadPDF).Methods(http.MethodPost)
-	router.HandleFunc("/videos/upload", h.videoHandler.UploadVideo).Methods(http.MethodPost)
+	// Las subidas se descuentan de la cuota de almacenamiento del usuario
+	quota := middleware.StorageQuota(h.storageQuotaService)
+	router.Handle("/media/upload", middleware.LargeTransfer(maxImageUploadSize)(quota(h.mediaHandler.UploadMedia))).Methods(http.MethodPost)
+	router.Handle("/images/upload", middleware.LargeTransfer(maxImageUploadSize)(quota(h.imageHandler.UploadImage))).Methods(http.MethodPost)
+	router.Handle("/audios/upload", middleware.LargeTransfer(maxAudioUploadSize)(quota(h.audioHandler.UploadAudio))).Methods(http.MethodPost)
+	// Notas de voz: subida y reproducción con Range (acepta ?token= para usarse en <audio>)
+	router.Handle("/voice-notes/upload", middleware.LargeTransfer(maxAudioUploadSize)(quota(h.audioHandler.UploadVoiceNote))).Methods(http.MethodPost)
+	router.Handle("/voice-notes/{filename}", middleware.LargeTransfer(0)(h.audioHandler.StreamVoiceNote)).Methods(http.MethodGet, http.MethodHead)
+	router.Handle("/pdfs/upload", middleware.LargeTransfer(maxPDFUploadSize)(quota(h.pdfHandler.UploadPDF))).Methods(http.MethodPost)
+	router.Handle("/videos/upload", middleware.LargeTransfer(maxVideoUploadSize)(quota(h.videoHandler.UploadVideo))).Methods(http.MethodPost)
+	// Las subidas reanudables descuentan de la cuota el tamaño declarado al crearse
+	// Subidas reanudables de video: se crean, se envían por partes y se completan
+	router.HandleFunc("/videos/uploads", h.uploadSessionHandler.Create).Methods(http.MethodPost)
+	router.HandleFunc("/videos/uploads/{id}", h.uploadSessionHandler.Status).Methods(http.MethodGet, http.MethodHead)
+	router.Handle("/videos/uploads/{id}", middleware.LargeTransfer(maxUploadChunkSize)(h.uploadSessionHandler.Upload)).Methods(http.MethodPatch)
+	router.HandleFunc("/videos/uploads/{id}", h.uploadSessionHandler.Cancel).Methods(http.MethodDelete)
+	router.Handle("/videos/uploads/{id}/complete", middleware.LargeTransfer(0)(h.uploadSessionHandler.Complete)).Methods(http.MethodPost)
+	// Descarga del video original con soporte de Range (reanudación y seek en MP4; acepta ?token=)
+	router.Handle("/videos/{contentID}/original", middleware.LargeTransfer(0)(h.videoHandler.DownloadOriginalVideo)).Methods(http.MethodGet, http.MethodHead)
 }
 
 // setupCommunityEventsProtectedRoutes configura las rutas protegidas para eventos comunitarios
@@ -307,6 +553,59 @@ func setupJobApplicationProtectedRoutes(router *mux.Router, jobApplicationHandle
 	}
 }
 
+// setupJobMatchingProtectedRoutes configura las rutas de requisitos de ofertas y candidatos recomendados
+func setupJobMatchingProtectedRoutes(router *mux.Router, jobMatchingHandler *handlers.JobMatchingHandler) {
+	matchingRouter := router.PathPrefix("/community-events/{eventID:[0-9]+}").Subrouter()
+	{
+		matchingRouter.HandleFunc("/requirements", jobMatchingHandler.GetRequirements).Methods(http.MethodGet)
+		matchingRouter.HandleFunc("/requirements", jobMatchingHandler.SetRequirements).Methods(http.MethodPut)
+		matchingRouter.HandleFunc("/requirements", jobMatchingHandler.DeleteRequirements).Methods(http.MethodDelete)
+		matchingRouter.HandleFunc("/recommended-candidates", jobMatchingHandler.RecommendCandidates).Methods(http.MethodGet)
+	}
+}
+
+// setupCommentProtectedRoutes configura las rutas protegidas para comentarios de publicaciones
+func setupCommentProtectedRoutes(router *mux.Router, commentHandler *handlers.CommentHandler) {
+	eventCommentsRouter := router.PathPrefix("/community-events/{eventID:[0-9]+}/comments").Subrouter()
+	{
+		eventCommentsRouter.HandleFunc("", commentHandler.ListComments).Methods(http.MethodGet)
+		eventCommentsRouter.HandleFunc("", commentHandler.CreateComment).Methods(http.MethodPost)
+	}
+
+	commentsRouter := router.PathPrefix("/comments/{commentID:[0-9]+}").Subrouter()
+	{
+		commentsRouter.HandleFunc("", commentHandler.UpdateComment).Methods(http.MethodPut)
+		commentsRouter.HandleFunc("", commentHandler.DeleteComment).Methods(http.MethodDelete)
+		commentsRouter.HandleFunc("/replies", commentHandler.ListReplies).Methods(http.MethodGet)
+	}
+}
+
+// setupEngagementProtectedRoutes configura las rutas protegidas de "me gusta" y guardados
+func setupEngagementProtectedRoutes(router *mux.Router, engagementHandler *handlers.EngagementHandler) {
+	postRouter := router.PathPrefix("/community-events/{eventID:[0-9]+}").Subrouter()
+	{
+		postRouter.HandleFunc("/like", engagementHandler.Like).Methods(http.MethodPut, http.MethodDelete)
+		postRouter.HandleFunc("/bookmark", engagementHandler.Bookmark).Methods(http.MethodPut, http.MethodDelete)
+	}
+}
+
+// setupChallengeProtectedRoutes configura las rutas protegidas de entregas y revisión de desafíos
+func setupChallengeProtectedRoutes(router *mux.Router, challengeHandler *handlers.ChallengeHandler) {
+	challengeRouter := router.PathPrefix("/community-events/{eventID:[0-9]+}").Subrouter()
+	{
+		challengeRouter.HandleFunc("/submissions", challengeHandler.Submit).Methods(http.MethodPost)
+		challengeRouter.HandleFunc("/submissions", challengeHandler.ListSubmissions).Methods(http.MethodGet)
+		challengeRouter.HandleFunc("/submissions/me", challengeHandler.GetMySubmission).Methods(http.MethodGet)
+		challengeRouter.HandleFunc("/submissions/{submissionID:[0-9]+}/review", challengeHandler.ReviewSubmission).Methods(http.MethodPatch)
+		challengeRouter.HandleFunc("/challenge-status", challengeHandler.UpdateChallengeStatus).Methods(http.MethodPatch)
+	}
+}
+
+// setupModerationProtectedRoutes configura la ruta con la que los usuarios reportan contenido
+func setupModerationProtectedRoutes(router *mux.Router, moderationHandler *handlers.ModerationHandler) {
+	router.HandleFunc("/reports", moderationHandler.CreateReport).Methods(http.MethodPost)
+}
+
 // setupReputationProtectedRoutes configura las rutas protegidas para reseñas y reputación
 func setupReputationProtectedRoutes(router *mux.Router, reputationHandler *handlers.ReputationHandler) {
 	reviewsRouter := router.PathPrefix("/reviews").Subrouter()
@@ -314,6 +613,12 @@ func setupReputationProtectedRoutes(router *mux.Router, reputationHandler *handl
 		reviewsRouter.HandleFunc("", reputationHandler.CreateReview).Methods(http.MethodPost)
 		reviewsRouter.HandleFunc("/student", reputationHandler.CreateReviewByStudent).Methods(http.MethodPost)
 	}
+
+	reputationRouter := router.PathPrefix("/reputation").Subrouter()
+	{
+		reputationRouter.HandleFunc("/leaderboard", reputationHandler.GetLeaderboard).Methods(http.MethodGet)
+		reputationRouter.HandleFunc("/users/{userID:[0-9]+}", reputationHandler.GetUserReputation).Methods(http.MethodGet)
+	}
 }
 
 // setupNotificationProtectedRoutes configura las rutas protegidas para notificaciones
@@ -321,6 +626,9 @@ func setupNotificationProtectedRoutes(router *mux.Router, notificationHandler *h
 	notificationRouter := router.PathPrefix("/notifications").Subrouter()
 	{
 		notificationRouter.HandleFunc("/{notificationID:[0-9]+}/read", notificationHandler.MarkAsRead).Methods(http.MethodPut)
+		notificationRouter.HandleFunc("/read", notificationHandler.MarkManyAsRead).Methods(http.MethodPost)
+		notificationRouter.HandleFunc("/unread", notificationHandler.MarkManyAsUnread).Methods(http.MethodPost)
+		notificationRouter.HandleFunc("/delete", notificationHandler.DeleteMany).Methods(http.MethodPost)
 	}
 }
 
@@ -338,19 +646,106 @@ func setupSearchProtectedRoutes(router *mux.Router, searchHandler *handlers.Sear
 
 // setupAdminRoutes configura las rutas que requieren privilegios de administrador.
 // Aplica tanto el middleware de autenticación como el de verificación de rol de administrador.
-func setupAdminRoutes(router *mux.Router, adminHandler *handlers.AdminHandler, db *sql.DB, cfg *config.Config) {
+func setupAdminRoutes(router *mux.Router, h serviceHandlers, db *sql.DB, cfg *config.Config) {
+	adminHandler := h.adminHandler
+
 	adminRouter := router.PathPrefix("/admin").Subrouter()
 
-	// Cadena de middlewares: primero autenticación, luego validación de rol y sesión de admin.
+	// Cadena de middlewares: primero autenticación, luego validación de rol y sesión de admin
+	// y, por último, el límite de los administradores de una institución.
 	adminRouter.Use(middleware.AuthMiddleware(cfg))
 	adminRouter.Use(middleware.AdminMiddleware(db))
+	adminRouter.Use(middleware.TenantAdminScope(services.NewTenantResolver(cfg)))
 
 	adminRouter.HandleFunc("/dashboard", adminHandler.GetDashboard).Methods(http.MethodGet)
 	adminRouter.HandleFunc("/users", adminHandler.ListUsers).Methods(http.MethodGet)
 	adminRouter.HandleFunc("/companies/unapproved", adminHandler.ListUnapprovedCompanies).Methods(http.MethodGet)
 	adminRouter.HandleFunc("/companies/{id:[0-9]+}/approve", adminHandler.ApproveCompany).Methods(http.MethodPatch)
+	adminRouter.HandleFunc("/audit-logs", h.auditLogHandler.ListAuditLogs).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/metrics/http", h.httpMetricsHandler.GetMetrics).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/metrics/http", h.httpMetricsHandler.ResetMetrics).Methods(http.MethodDelete)
+	adminRouter.HandleFunc("/metrics/cache", h.httpMetricsHandler.GetCacheMetrics).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/analytics/cohorts", h.cohortAnalytics.GetCohorts).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/exports", h.adminExport.ListDatasets).Methods(http.MethodGet)
+	adminRouter.Handle("/exports/{dataset}", middleware.LargeTransfer(0)(h.adminExport.Export)).Methods(http.MethodGet)
+
+	// Retención de mensajes: estado, ejecuciones y chats excluidos
+	retentionRouter := adminRouter.PathPrefix("/retention").Subrouter()
+	{
+		retentionRouter.HandleFunc("", h.retentionHandler.GetStatus).Methods(http.MethodGet)
+		retentionRouter.HandleFunc("/runs", h.retentionHandler.ListRuns).Methods(http.MethodGet)
+		retentionRouter.HandleFunc("/runs", h.retentionHandler.TriggerRun).Methods(http.MethodPost)
+		retentionRouter.HandleFunc("/opt-outs", h.retentionHandler.ListOptOuts).Methods(http.MethodGet)
+		retentionRouter.HandleFunc("/opt-outs/{chatId}", h.retentionHandler.SetOptOut).Methods(http.MethodPut)
+		retentionRouter.HandleFunc("/opt-outs/{chatId}", h.retentionHandler.RemoveOptOut).Methods(http.MethodDelete)
+	}
+
+	// Moderación: cola de reportes, contenido oculto y cuentas suspendidas
+	reportsRouter := adminRouter.PathPrefix("/reports").Subrouter()
+	{
+		reportsRouter.HandleFunc("", h.moderationHandler.ListReports).Methods(http.MethodGet)
+		reportsRouter.HandleFunc("/{id:[0-9]+}", h.moderationHandler.GetReport).Methods(http.MethodGet)
+		reportsRouter.HandleFunc("/{id:[0-9]+}/resolve", h.moderationHandler.ResolveReport).Methods(http.MethodPost)
+		reportsRouter.HandleFunc("/{id:[0-9]+}/dismiss", h.moderationHandler.DismissReport).Methods(http.MethodPost)
+		reportsRouter.HandleFunc("/{id:[0-9]+}/context", h.moderationHandler.GetReportContext).Methods(http.MethodGet)
+		reportsRouter.HandleFunc("/{id:[0-9]+}/context", h.moderationHandler.CaptureReportContext).Methods(http.MethodPost)
+	}
+	// Imágenes que la moderación automática envió a revisión
+	adminRouter.HandleFunc("/image-reviews", h.moderationHandler.ListImageReviews).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/image-reviews/{id:[0-9]+}/resolve", h.moderationHandler.ResolveImageReview).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/hidden-content/{targetType}/{targetId}", h.moderationHandler.UnhideContent).Methods(http.MethodDelete)
+	adminRouter.HandleFunc("/users/{id:[0-9]+}/reinstate", h.moderationHandler.ReinstateUser).Methods(http.MethodPatch)
+
+	// Gestión de usuarios: búsqueda, rol, estado, contraseña y desactivación
+	adminRouter.HandleFunc("/users/search", h.adminUserHandler.SearchUsers).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/users/{id:[0-9]+}/role", h.adminUserHandler.ChangeRole).Methods(http.MethodPatch)
+	adminRouter.HandleFunc("/users/{id:[0-9]+}/status", h.adminUserHandler.ChangeStatus).Methods(http.MethodPatch)
+	adminRouter.HandleFunc("/users/{id:[0-9]+}/password-reset", h.adminUserHandler.SendPasswordReset).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/users/{id:[0-9]+}/deactivate", h.adminUserHandler.Deactivate).Methods(http.MethodPatch)
+	adminRouter.HandleFunc("/users/{id:[0-9]+}/reactivate", h.adminUserHandler.Reactivate).Methods(http.MethodPatch)
+	adminRouter.HandleFunc("/users/{id:[0-9]+}/tenant", h.adminUserHandler.ChangeTenant).Methods(http.MethodPatch)
+	adminRouter.HandleFunc("/users/{id:[0-9]+}/impersonate", h.impersonationHandler.Start).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/impersonations/{id:[0-9]+}", h.impersonationHandler.End).Methods(http.MethodDelete)
+	adminRouter.HandleFunc("/users/{id:[0-9]+}/storage", h.storageQuotaHandler.GetUserUsage).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/users/{id:[0-9]+}/storage-quota", h.storageQuotaHandler.SetUserQuota).Methods(http.MethodPut)
+
+	// Archivos subidos que el análisis de malware puso en cuarentena
+	adminRouter.HandleFunc("/quarantine", h.uploadScanHandler.ListQuarantined).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/quarantine/{id}", h.uploadScanHandler.DeleteQuarantined).Methods(http.MethodDelete)
+
+	// Filtro de contenido del chat: reglas, política, registro y prueba en seco
+	filterRouter := adminRouter.PathPrefix("/content-filter").Subrouter()
+	{
+		filterRouter.HandleFunc("/rules", h.contentFilterHandler.ListRules).Methods(http.MethodGet)
+		filterRouter.HandleFunc("/rules", h.contentFilterHandler.CreateRule).Methods(http.MethodPost)
+		filterRouter.Handle("/rules/import", middleware.LargeTransfer(maxFilterImportSize)(h.contentFilterHandler.ImportWords)).Methods(http.MethodPost)
+		filterRouter.HandleFunc("/rules/{id:[0-9]+}", h.contentFilterHandler.UpdateRule).Methods(http.MethodPut)
+		filterRouter.HandleFunc("/rules/{id:[0-9]+}", h.contentFilterHandler.DeleteRule).Methods(http.MethodDelete)
+		filterRouter.HandleFunc("/policy", h.contentFilterHandler.GetPolicy).Methods(http.MethodGet)
+		filterRouter.HandleFunc("/policy", h.contentFilterHandler.UpdatePolicy).Methods(http.MethodPut)
+		filterRouter.HandleFunc("/hits", h.contentFilterHandler.ListHits).Methods(http.MethodGet)
+		filterRouter.HandleFunc("/test", h.contentFilterHandler.Test).Methods(http.MethodPost)
+	}
+
+	// Catálogos: nacionalidades, universidades, carreras y habilidades
+	catalogRouter := adminRouter.PathPrefix("/catalogs").Subrouter()
+	{
+		catalogRouter.HandleFunc("/nationalities", h.catalogHandler.CreateNationality).Methods(http.MethodPost)
+		catalogRouter.HandleFunc("/nationalities/{id:[0-9]+}", h.catalogHandler.UpdateNationality).Methods(http.MethodPut)
+		catalogRouter.HandleFunc("/nationalities/{id:[0-9]+}", h.catalogHandler.DeleteNationality).Methods(http.MethodDelete)
+		catalogRouter.HandleFunc("/universities", h.catalogHandler.CreateUniversity).Methods(http.MethodPost)
+		catalogRouter.HandleFunc("/universities/{id:[0-9]+}", h.catalogHandler.UpdateUniversity).Methods(http.MethodPut)
+		catalogRouter.HandleFunc("/universities/{id:[0-9]+}", h.catalogHandler.DeleteUniversity).Methods(http.MethodDelete)
+		catalogRouter.HandleFunc("/degrees", h.catalogHandler.CreateDegree).Methods(http.MethodPost)
+		catalogRouter.HandleFunc("/degrees/{id:[0-9]+}", h.catalogHandler.UpdateDegree).Methods(http.MethodPut)
+		catalogRouter.HandleFunc("/degrees/{id:[0-9]+}", h.catalogHandler.DeleteDegree).Methods(http.MethodDelete)
+		catalogRouter.HandleFunc("/skills", h.skillHandler.CreateSkill).Methods(http.MethodPost)
+		catalogRouter.HandleFunc("/skills/{id:[0-9]+}", h.skillHandler.UpdateSkill).Methods(http.MethodPut)
+		catalogRouter.HandleFunc("/skills/{id:[0-9]+}", h.skillHandler.DeleteSkill).Methods(http.MethodDelete)
+		catalogRouter.HandleFunc("/skills/unmapped", h.skillHandler.GetUnmappedSkills).Methods(http.MethodGet)
+		catalogRouter.HandleFunc("/skills/remap", h.skillHandler.RemapSkills).Methods(http.MethodPost)
+	}
 
 	// TODO: Implementar los siguientes handlers y rutas
-	// adminRouter.HandleFunc("/users/{id}", adminHandler.ManageUser).Methods(http.MethodPut, http.MethodDelete)
 	// adminRouter.HandleFunc("/categories", adminHandler.ManageCategories).Methods(http.MethodPost, http.MethodPut)
 }
@@ -0,0 +1,201 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/openapi"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
+	"github.com/gorilla/mux"
+)
+
+/*
+ * ESPECIFICACIÓN OPENAPI
+ * ----------------------
+ * La especificación se genera de las propias rutas: cada grupo (`setupPublicRoutes`,
+ * `setupProtectedRoutes`...) se monta en un router aparte solo para recorrerlo, así que una
+ * ruta nueva aparece en la documentación sin hacer nada más. `apiOperations` añade el resumen
+ * y el tipo del cuerpo JSON de las operaciones que lo tienen; con API_REQUEST_VALIDATION ese
+ * mismo tipo valida el cuerpo de las peticiones (`middleware.ValidateBody`).
+ *
+ * Para documentar el cuerpo de una ruta nueva, añade una entrada con la plantilla de la ruta
+ * sin el prefijo de versión, tal como está en `api_routes.go`.
+ */
+
+// Esquemas de seguridad de la especificación
+const (
+	securityBearer     = "bearerAuth"
+	securityQueryToken = "queryToken"
+)
+
+// apiOperation documenta una operación: su resumen y los tipos de su cuerpo y su respuesta.
+type apiOperation struct {
+	summary  string
+	request  func() interface{} // Puntero al tipo del cuerpo; nil = sin cuerpo JSON
+	response interface{}
+}
+
+// apiOperations son las operaciones con cuerpo JSON o respuesta documentada, por
+// "MÉTODO plantilla".
+var apiOperations = map[string]apiOperation{
+	// Autenticación y registro
+	"POST /register":         {summary: "Registro de estudiante (paso 1)", request: func() interface{} { return &models.RegistrationStep1{} }},
+	"POST /register/company": {summary: "Registro de empresa", request: func() interface{} { return &models.CompanyRegistrationRequest{} }},
+	"POST /register/step2":   {summary: "Registro de estudiante (paso 2)", request: func() interface{} { return &models.RegistrationStep2{} }},
+	"POST /register/step3":   {summary: "Registro de estudiante (paso 3)", request: func() interface{} { return &models.RegistrationStep3{} }},
+	"POST /login":            {summary: "Inicio de sesión", request: func() interface{} { return &models.LoginRequest{} }, response: models.LoginResponse{}},
+	"POST /enterprises":      {summary: "Registro de empresa (formulario completo)", request: func() interface{} { return &models.EnterpriseRegistration{} }},
+
+	// Perfil y preferencias del usuario
+	"PUT /users/me":                               {summary: "Actualizar el perfil propio", request: func() interface{} { return &models.UpdateProfilePayload{} }},
+	"POST /users/me/devices":                      {summary: "Registrar un dispositivo para notificaciones push", request: func() interface{} { return &models.RegisterPushDeviceRequest{} }},
+	"PUT /users/me/notification-preferences":      {summary: "Actualizar las preferencias de notificación", request: func() interface{} { return &models.UpdateNotificationPreferencesRequest{} }},
+	"POST /users/me/web-push/subscriptions":       {summary: "Registrar una suscripción Web Push", request: func() interface{} { return &models.RegisterWebPushSubscriptionRequest{} }},
+	"POST /users/me/deletion":                     {summary: "Solicitar el borrado de la cuenta", request: func() interface{} { return &models.AccountDeletionRequest{} }},
+	"PUT /users/me/privacy-settings":              {summary: "Actualizar la privacidad de las analíticas", request: func() interface{} { return &models.UpdatePrivacySettingsRequest{} }},
+	"POST /users/me/student-verification":         {summary: "Enviar un código al correo institucional", request: func() interface{} { return &models.StudentVerificationRequest{} }, response: models.StudentVerification{}},
+	"POST /users/me/student-verification/confirm": {summary: "Confirmar el correo institucional", request: func() interface{} { return &models.StudentVerificationConfirmRequest{} }, response: models.StudentVerification{}},
+	"GET /users/me/student-verification":          {summary: "Estado de la verificación de estudiante", response: models.StudentVerification{}},
+	"POST /users/me/chat-exports":                 {summary: "Exportar una conversación", request: func() interface{} { return &models.CreateChatExportRequest{} }},
+	"POST /users/me/saved-searches":               {summary: "Guardar una búsqueda", request: func() interface{} { return &models.SavedSearchRequest{} }},
+	"PUT /users/me/saved-searches/{id:[0-9]+}":    {summary: "Modificar una búsqueda guardada", request: func() interface{} { return &models.SavedSearchRequest{} }},
+	"POST /notifications/read":                    {summary: "Marcar notificaciones como leídas", request: func() interface{} { return &models.NotificationIDsRequest{} }, response: models.NotificationBulkResponse{}},
+	"POST /notifications/unread":                  {summary: "Marcar notificaciones como no leídas", request: func() interface{} { return &models.NotificationIDsRequest{} }, response: models.NotificationBulkResponse{}},
+	"POST /notifications/delete":                  {summary: "Borrar notificaciones", request: func() interface{} { return &models.NotificationIDsRequest{} }, response: models.NotificationBulkResponse{}},
+	"POST /videos/uploads":                        {summary: "Iniciar una subida de video reanudable", request: func() interface{} { return &models.CreateUploadSessionRequest{} }},
+
+	// Publicaciones: postulaciones, requisitos, comentarios y desafíos
+	"POST /community-events":                                                            {summary: "Crear una publicación", request: func() interface{} { return &models.CommunityEventCreateRequest{} }},
+	"POST /community-events/{eventID:[0-9]+}/apply":                                     {summary: "Postularse a una oferta", request: func() interface{} { return &models.JobApplicationCreateRequest{} }},
+	"GET /community-events/my-events":                                                   {summary: "Publicaciones propias; con ?cursor= responde una página por cursor", response: pagination.Page[models.CommunityEvent]{}},
+	"GET /community-events/{eventID:[0-9]+}/applicants":                                 {summary: "Postulantes de una oferta; con ?cursor= responde una página por cursor", response: pagination.Page[models.ApplicantInfo]{}},
+	"PATCH /community-events/{eventID:[0-9]+}/applicants/{applicantID:[0-9]+}/status":   {summary: "Cambiar el estado de una postulación", request: func() interface{} { return &models.UpdateApplicationStatusRequest{} }},
+	"PUT /community-events/{eventID:[0-9]+}/requirements":                               {summary: "Fijar los requisitos de una oferta", request: func() interface{} { return &models.SetJobRequirementsRequest{} }},
+	"POST /community-events/{eventID:[0-9]+}/comments":                                  {summary: "Comentar una publicación", request: func() interface{} { return &models.CreateCommentRequest{} }},
+	"PUT /comments/{commentID:[0-9]+}":                                                  {summary: "Editar un comentario", request: func() interface{} { return &models.UpdateCommentRequest{} }},
+	"POST /community-events/{eventID:[0-9]+}/submissions":                               {summary: "Entregar un desafío", request: func() interface{} { return &models.SubmitChallengeRequest{} }},
+	"PATCH /community-events/{eventID:[0-9]+}/submissions/{submissionID:[0-9]+}/review": {summary: "Revisar una entrega", request: func() interface{} { return &models.ReviewSubmissionRequest{} }},
+	"PATCH /community-events/{eventID:[0-9]+}/challenge-status":                         {summary: "Cambiar el estado de un desafío", request: func() interface{} { return &models.UpdateChallengeStatusRequest{} }},
+
+	// Moderación y reputación
+	"POST /reports":         {summary: "Reportar contenido", request: func() interface{} { return &models.CreateReportRequest{} }},
+	"POST /reviews":         {summary: "Calificar a un estudiante", request: func() interface{} { return &models.CreateReviewRequest{} }},
+	"POST /reviews/student": {summary: "Calificar a una empresa", request: func() interface{} { return &models.CreateReviewRequest{} }},
+
+	// Administración
+	"POST /admin/reports/{id:[0-9]+}/resolve":       {summary: "Resolver un reporte", request: func() interface{} { return &models.ResolveReportRequest{} }},
+	"POST /admin/reports/{id:[0-9]+}/dismiss":       {summary: "Descartar un reporte", request: func() interface{} { return &models.DismissReportRequest{} }},
+	"POST /admin/reports/{id:[0-9]+}/context":       {summary: "Capturar el contexto de un reporte", request: func() interface{} { return &models.CaptureReportContextRequest{} }},
+	"POST /admin/image-reviews/{id:[0-9]+}/resolve": {summary: "Resolver la revisión de una imagen", request: func() interface{} { return &models.ResolveImageReviewRequest{} }},
+	"PATCH /admin/users/{id:[0-9]+}/role":           {summary: "Cambiar el rol de un usuario", request: func() interface{} { return &models.UpdateUserRoleRequest{} }},
+	"PATCH /admin/users/{id:[0-9]+}/status":         {summary: "Cambiar el estado de un usuario", request: func() interface{} { return &models.UpdateUserStatusRequest{} }},
+	"PATCH /admin/users/{id:[0-9]+}/deactivate":     {summary: "Desactivar una cuenta", request: func() interface{} { return &models.DeactivateUserRequest{} }},
+	"PATCH /admin/users/{id:[0-9]+}/tenant":         {summary: "Cambiar la institución de un usuario", request: func() interface{} { return &models.UpdateUserTenantRequest{} }},
+	"POST /admin/users/{id:[0-9]+}/impersonate":     {summary: "Suplantar a un usuario", request: func() interface{} { return &models.StartImpersonationRequest{} }},
+	"PUT /admin/users/{id:[0-9]+}/storage-quota":    {summary: "Fijar la cuota de almacenamiento de un usuario", request: func() interface{} { return &models.UpdateStorageQuotaRequest{} }},
+	"POST /admin/content-filter/rules":              {summary: "Crear una regla del filtro de contenido", request: func() interface{} { return &models.ContentFilterRuleRequest{} }},
+	"PUT /admin/content-filter/rules/{id:[0-9]+}":   {summary: "Modificar una regla del filtro de contenido", request: func() interface{} { return &models.ContentFilterRuleRequest{} }},
+	"PUT /admin/content-filter/policy":              {summary: "Actualizar la política del filtro de contenido", request: func() interface{} { return &models.ContentFilterPolicy{} }},
+	"POST /admin/content-filter/test":               {summary: "Probar el filtro de contenido con un texto", request: func() interface{} { return &models.ContentFilterTestRequest{} }},
+	"GET /admin/analytics/cohorts":                  {summary: "Analíticas de cohortes de egresados", response: models.CohortAnalytics{}},
+	"GET /admin/exports":                            {summary: "Conjuntos de datos exportables", response: []models.AdminExportDataset{}},
+	"GET /admin/exports/{dataset}":                  {summary: "Exportar un conjunto de datos en CSV o XLSX"},
+}
+
+// setupOpenAPIRoutes publica la especificación de la versión en /openapi.json y Swagger UI
+// en /docs.
+func setupOpenAPIRoutes(api *mux.Router, h serviceHandlers, db *sql.DB, cfg *config.Config, v apiVersion) {
+	doc := buildOpenAPIDocument(h, db, cfg, v)
+	api.HandleFunc("/openapi.json", openapi.Handler(doc)).Methods(http.MethodGet)
+	api.HandleFunc("/docs", openapi.UIHandler(doc.Info.Title, v.prefix+"/openapi.json")).Methods(http.MethodGet)
+}
+
+// buildOpenAPIDocument genera la especificación de una versión. Cada grupo de rutas se monta
+// en un router propio para saber qué autenticación exige.
+func buildOpenAPIDocument(h serviceHandlers, db *sql.DB, cfg *config.Config, v apiVersion) *openapi.Document {
+	groups := []struct {
+		security []string
+		setup    func(r *mux.Router)
+	}{
+		{nil, func(r *mux.Router) { setupPublicRoutes(r, h) }},
+		{[]string{securityQueryToken}, func(r *mux.Router) { setupStreamingRoutes(r, h) }},
+		{[]string{securityBearer, securityQueryToken}, func(r *mux.Router) { setupProtectedRoutes(r, h, cfg) }},
+		{[]string{securityBearer, securityQueryToken}, func(r *mux.Router) { setupAdminRoutes(r, h, db, cfg) }},
+	}
+
+	var routes []openapi.Route
+	for _, group := range groups {
+		router := mux.NewRouter()
+		group.setup(router)
+		router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			template, err := route.GetPathTemplate()
+			if err != nil {
+				return nil
+			}
+			methods, err := route.GetMethods()
+			if err != nil {
+				return nil // Prefijo de un subrouter, no una ruta
+			}
+			for _, method := range methods {
+				op := apiOperations[method+" "+template]
+				r := openapi.Route{
+					Method:   method,
+					Path:     template,
+					Summary:  op.summary,
+					Security: group.security,
+					Response: op.response,
+				}
+				if op.request != nil {
+					r.Request = op.request()
+				}
+				routes = append(routes, r)
+			}
+			return nil
+		})
+	}
+
+	return openapi.Build(openapi.Spec{
+		Info: openapi.Info{
+			Title:       "API REST " + v.version,
+			Description: "Especificación generada de las rutas del servicio. Los errores siguen el catálogo de docs/codigos_de_error.md.",
+			Version:     v.version,
+		},
+		Servers: []openapi.Server{{URL: v.prefix}},
+		SecuritySchemes: map[string]*openapi.SecurityScheme{
+			securityBearer:     {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			securityQueryToken: {Type: "apiKey", In: "query", Name: "token", Description: "El mismo JWT en el parámetro token (enlaces de imágenes, audio y video)"},
+		},
+		Error: apperrors.Response{},
+	}, routes)
+}
+
+// applyRequestValidation añade la validación del cuerpo a las rutas de la versión que tienen
+// tipo de cuerpo en apiOperations. La validación envuelve directamente al handler, así que se
+// ejecuta después de la autenticación.
+func applyRequestValidation(api *mux.Router, v apiVersion) {
+	validated := 0
+	api.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil || len(methods) != 1 || route.GetHandler() == nil {
+			return nil
+		}
+		op, ok := apiOperations[methods[0]+" "+strings.TrimPrefix(template, v.prefix)]
+		if !ok || op.request == nil {
+			return nil
+		}
+		route.Handler(middleware.ValidateBody(op.request)(route.GetHandler()))
+		validated++
+		return nil
+	})
+	logger.Infof("ROUTES", "Validación de cuerpos activa en %d rutas de %s", validated, v.prefix)
+}
@@ -0,0 +1,81 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/middleware"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+/*
+ * VERSIONADO DE LA API
+ * --------------------
+ * Cada versión se monta como un subrouter con su propio prefijo y comparte los mismos
+ * handlers (`setupVersionedRoutes`). Un handler que deba responder distinto según la
+ * versión consulta `middleware.APIVersion(r)`; no se duplican rutas ni handlers.
+ *
+ * - /api/v2: versión actual.
+ * - /api/v1: estable. Con API_V1_SUNSET se anuncia su retirada (Deprecation + Sunset).
+ * - /api:    rutas sin versión, obsoletas. Responden como v1 e indican en el header
+ *            Link la ruta equivalente en /api/v1. API_LEGACY_SUNSET fija su retirada.
+ *
+ * El orden importa: gorilla/mux prueba los prefijos en orden de registro, así que el
+ * prefijo sin versión debe montarse el último.
+ */
+
+// Prefijos de cada versión de la API
+const (
+	APIPrefixV2     = "/api/v2"
+	APILegacyPrefix = "/api"
+)
+
+// apiVersion es un prefijo montado de la API.
+type apiVersion struct {
+	version     string
+	prefix      string
+	deprecation *middleware.Deprecation
+}
+
+// apiVersions devuelve las versiones a montar, en orden de registro.
+func apiVersions(cfg *config.Config) []apiVersion {
+	var v1Deprecation *middleware.Deprecation
+	if sunset := parseSunset("API_V1_SUNSET", cfg.APIV1Sunset); !sunset.IsZero() {
+		v1Deprecation = &middleware.Deprecation{Prefix: APIPrefix, SuccessorPrefix: APIPrefixV2, Sunset: sunset}
+	}
+
+	return []apiVersion{
+		{version: middleware.APIVersion2, prefix: APIPrefixV2},
+		{version: middleware.APIVersion1, prefix: APIPrefix, deprecation: v1Deprecation},
+		{
+			version: middleware.APIVersion1,
+			prefix:  APILegacyPrefix,
+			deprecation: &middleware.Deprecation{
+				Prefix:          APILegacyPrefix,
+				SuccessorPrefix: APIPrefix,
+				Sunset:          parseSunset("API_LEGACY_SUNSET", cfg.APILegacySunset),
+			},
+		},
+	}
+}
+
+// mountAPIVersion crea el subrouter de una versión con su middleware de versionado.
+func mountAPIVersion(r *mux.Router, v apiVersion) *mux.Router {
+	api := r.PathPrefix(v.prefix).Subrouter()
+	api.Use(middleware.APIVersionMiddleware(v.version, v.deprecation))
+	return api
+}
+
+// parseSunset interpreta una fecha de retirada YYYY-MM-DD. Vacía o inválida = sin fecha.
+func parseSunset(key, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	sunset, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		logger.Warnf("ROUTES", "%s=%q no es una fecha válida (YYYY-MM-DD); se ignora", key, value)
+		return time.Time{}
+	}
+	return sunset
+}
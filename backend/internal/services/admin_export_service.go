@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/tabular"
+)
+
+// Errores de validación de las exportaciones del panel.
+var (
+	ErrExportDatasetNotFound = apperrors.New(apperrors.NotFound, "el conjunto de datos no existe")
+	ErrExportFormat          = apperrors.New(apperrors.InvalidParam, "el parámetro 'format' debe ser csv o xlsx")
+	ErrExportColumns         = apperrors.New(apperrors.InvalidParam, "'columns' contiene columnas desconocidas o repetidas")
+	ErrExportStatus          = apperrors.New(apperrors.InvalidParam, "el parámetro 'status' no es un estado de postulación válido")
+	ErrExportPostType        = apperrors.New(apperrors.InvalidParam, "el parámetro 'postType' no es un tipo de publicación válido")
+	ErrExportInteractionType = apperrors.New(apperrors.InvalidParam, "el parámetro 'interactionType' no es un tipo de interacción válido")
+	ErrExportDateRange       = apperrors.New(apperrors.InvalidParam, "'from' debe ser anterior a 'to'")
+)
+
+// IAdminExportService define la exportación de conjuntos de datos del panel de administración.
+type IAdminExportService interface {
+	Datasets() []models.AdminExportDataset
+	Prepare(req models.AdminExportRequest) (models.AdminExportRequest, error)
+	Export(ctx context.Context, req models.AdminExportRequest, w io.Writer) (int64, error)
+}
+
+// AdminExportService exporta en CSV o XLSX los conjuntos de datos del panel (usuarios,
+// postulaciones, asistencia a eventos y reseñas). Las filas se escriben a medida que se leen
+// de la base de datos, sin cargarlas en memoria.
+type AdminExportService struct{}
+
+// NewAdminExportService crea una nueva instancia de AdminExportService.
+func NewAdminExportService() IAdminExportService {
+	return &AdminExportService{}
+}
+
+// Datasets devuelve los conjuntos exportables con sus columnas y filtros.
+func (s *AdminExportService) Datasets() []models.AdminExportDataset {
+	return queries.AdminExportDatasets()
+}
+
+// Prepare valida la exportación y la devuelve normalizada: formato csv por defecto y, sin
+// columnas, todas las del conjunto.
+func (s *AdminExportService) Prepare(req models.AdminExportRequest) (models.AdminExportRequest, error) {
+	dataset, ok := queries.AdminExportDataset(req.Dataset)
+	if !ok {
+		return req, ErrExportDatasetNotFound
+	}
+	if req.Format == "" {
+		req.Format = tabular.FormatCSV
+	}
+	if !tabular.IsValidFormat(req.Format) {
+		return req, ErrExportFormat
+	}
+
+	if len(req.Columns) == 0 {
+		for _, column := range dataset.Columns {
+			req.Columns = append(req.Columns, column.Key)
+		}
+	} else {
+		known := make(map[string]bool, len(dataset.Columns))
+		for _, column := range dataset.Columns {
+			known[column.Key] = true
+		}
+		seen := make(map[string]bool, len(req.Columns))
+		for _, key := range req.Columns {
+			if !known[key] || seen[key] {
+				return req, ErrExportColumns
+			}
+			seen[key] = true
+		}
+	}
+
+	filter := req.Filter
+	if _, ok := validStatuses[filter.Status]; filter.Status != "" && !ok {
+		return req, ErrExportStatus
+	}
+	if filter.PostType != "" && !savedSearchPostTypes[filter.PostType] {
+		return req, ErrExportPostType
+	}
+	if _, ok := validInteractionTypes[filter.InteractionType]; filter.InteractionType != "" && !ok {
+		return req, ErrExportInteractionType
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return req, ErrExportDateRange
+	}
+	return req, nil
+}
+
+// Export escribe en w la exportación ya preparada con Prepare: una fila de cabecera y una por
+// registro. Devuelve el número de registros escritos. Si falla a mitad, w queda incompleto.
+func (s *AdminExportService) Export(ctx context.Context, req models.AdminExportRequest, w io.Writer) (int64, error) {
+	dataset, ok := queries.AdminExportDataset(req.Dataset)
+	if !ok {
+		return 0, ErrExportDatasetNotFound
+	}
+	headers := make(map[string]string, len(dataset.Columns))
+	for _, column := range dataset.Columns {
+		headers[column.Key] = column.Header
+	}
+
+	out, err := tabular.New(req.Format, w)
+	if err != nil {
+		return 0, err
+	}
+	header := make([]any, len(req.Columns))
+	for i, key := range req.Columns {
+		header[i] = headers[key]
+	}
+	if err := out.WriteRow(header); err != nil {
+		return 0, err
+	}
+
+	var rows int64
+	err = queries.StreamAdminExport(ctx, req.Dataset, req.Columns, req.Filter, func(values []any) error {
+		rows++
+		return out.WriteRow(values)
+	})
+	if err != nil {
+		return rows, fmt.Errorf("error al exportar %s: %w", req.Dataset, err)
+	}
+	return rows, out.Close()
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const adminUserServiceComponent = "ADMIN_USER_SERVICE"
+
+// deactivationReasonMaxLength es la longitud máxima del motivo de una desactivación, en
+// caracteres.
+const deactivationReasonMaxLength = 500
+
+// Errores de negocio de la gestión de usuarios.
+var (
+	ErrAdminUserNotFound       = apperrors.New(apperrors.NotFound, "usuario no encontrado")
+	ErrAdminUserRoleInvalid    = apperrors.New(apperrors.UserRoleInvalid, "el rol no existe")
+	ErrAdminUserStatusInvalid  = apperrors.New(apperrors.UserStatusInvalid, "el estado no existe")
+	ErrAdminUserStatusReserved = apperrors.New(apperrors.UserStatusInvalid, "usa la moderación para suspender y /deactivate para desactivar la cuenta")
+	ErrAdminUserStatusLocked   = apperrors.New(apperrors.UserStatusInvalid, "la cuenta está suspendida o desactivada; reactívala antes de cambiar su estado")
+	ErrAdminUserSelf           = apperrors.New(apperrors.UserSelfManagement, "no puedes cambiar tu propio rol o estado")
+	ErrAdminUserReasonTooLong  = apperrors.New(apperrors.InvalidParam, fmt.Sprintf("el motivo no puede superar los %d caracteres", deactivationReasonMaxLength))
+	ErrAdminUserDeactivated    = apperrors.New(apperrors.UserAlreadyDeactivated, "la cuenta ya está desactivada")
+	ErrAdminUserNotDeactivated = apperrors.New(apperrors.UserNotDeactivated, "la cuenta no está desactivada")
+	ErrAdminUserTenantInvalid  = apperrors.New(apperrors.TenantInvalid, "tenantId no corresponde a ninguna universidad")
+	ErrAdminUserTenantScope    = apperrors.New(apperrors.TenantAdminRequired, "solo un administrador global puede cambiar la institución de un usuario")
+)
+
+// IAdminUserService define la interfaz del servicio de gestión de usuarios.
+type IAdminUserService interface {
+	SearchUsers(filter models.AdminUserSearch, page, pageSize int) (*models.PaginatedUserResponse, error)
+	GetUser(userID int64) (models.User, error)
+	ChangeRole(adminID, userID int64, roleID int) (int, error)
+	ChangeStatus(adminID, userID int64, statusID int) (int, error)
+	ChangeTenant(adminID, adminTenantID, userID, tenantID int64) (int64, error)
+	Deactivate(adminID, userID int64, reason string) error
+	Reactivate(userID int64) (int, error)
+}
+
+// AdminUserService permite a los administradores buscar usuarios, cambiar su rol o estado y
+// desactivar o reactivar sus cuentas. Los cambios se aplican de inmediato: la API consulta el
+// estado y el rol actuales en cada petición y el servicio WebSocket cierra las conexiones del
+// usuario al recibir la notificación del cambio.
+type AdminUserService struct {
+	db *sql.DB
+}
+
+// NewAdminUserService crea una nueva instancia de AdminUserService.
+func NewAdminUserService(db *sql.DB) IAdminUserService {
+	return &AdminUserService{db: db}
+}
+
+// SearchUsers devuelve una página de usuarios que cumplen los filtros.
+func (s *AdminUserService) SearchUsers(filter models.AdminUserSearch, page, pageSize int) (*models.PaginatedUserResponse, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	users, total, err := queries.SearchUsers(filter, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PaginatedUserResponse{
+		CurrentPage:  page,
+		PageSize:     pageSize,
+		TotalPages:   int(math.Ceil(float64(total) / float64(pageSize))),
+		TotalRecords: total,
+		Users:        users,
+	}, nil
+}
+
+// GetUser devuelve el usuario, sin filtrar por estado.
+func (s *AdminUserService) GetUser(userID int64) (models.User, error) {
+	user, err := queries.GetUserByID(s.db, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user, ErrAdminUserNotFound
+	}
+	return user, err
+}
+
+// ChangeRole cambia el rol del usuario y devuelve el anterior. El usuario recibe una
+// notificación y sus conexiones WebSocket se cierran para que reconecten con el rol nuevo.
+func (s *AdminUserService) ChangeRole(adminID, userID int64, roleID int) (int, error) {
+	if adminID == userID {
+		return 0, ErrAdminUserSelf
+	}
+	roleName, ok := defaultRoleName(roleID)
+	if !ok {
+		return 0, ErrAdminUserRoleInvalid
+	}
+	state, err := s.accountState(userID)
+	if err != nil {
+		return 0, err
+	}
+	if state.RoleId == roleID {
+		return roleID, nil
+	}
+	if err := queries.UpdateUserRole(userID, roleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrAdminUserNotFound
+		}
+		return 0, err
+	}
+
+	s.notify(userID, models.EventTypeRoleChanged, "Tu rol cambió",
+		fmt.Sprintf("Un administrador cambió el rol de tu cuenta a %s.", roleName))
+	logger.Infof(adminUserServiceComponent, "Rol del usuario %d cambiado de %d a %d por %d", userID, state.RoleId, roleID, adminID)
+	return state.RoleId, nil
+}
+
+// ChangeStatus cambia el StatusAuthorized del usuario y devuelve el anterior. La suspensión y
+// la desactivación tienen sus propias operaciones, porque cierran las sesiones y se
+// revierten de forma distinta, así que no se pueden asignar ni abandonar desde aquí.
+func (s *AdminUserService) ChangeStatus(adminID, userID int64, statusID int) (int, error) {
+	if adminID == userID {
+		return 0, ErrAdminUserSelf
+	}
+	if !isDefaultStatus(statusID) {
+		return 0, ErrAdminUserStatusInvalid
+	}
+	if models.IsAccountDisabled(statusID) {
+		return 0, ErrAdminUserStatusReserved
+	}
+	state, err := s.accountState(userID)
+	if err != nil {
+		return 0, err
+	}
+	if models.IsAccountDisabled(state.StatusAuthorizedId) {
+		return 0, ErrAdminUserStatusLocked
+	}
+	if err := queries.UpdateUserStatus(userID, statusID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrAdminUserNotFound
+		}
+		return 0, err
+	}
+	logger.Infof(adminUserServiceComponent, "Estado del usuario %d cambiado de %d a %d por %d", userID, state.StatusAuthorizedId, statusID, adminID)
+	return state.StatusAuthorizedId, nil
+}
+
+// ChangeTenant cambia la institución del usuario (0 la quita) y devuelve la anterior (ver
+// docs/multi_institucion.md). Solo pueden hacerlo los administradores globales: uno de una
+// institución, adminTenantID distinto de 0, podría sacar usuarios de su alcance o meterlos
+// en él.
+func (s *AdminUserService) ChangeTenant(adminID, adminTenantID, userID, tenantID int64) (int64, error) {
+	if adminTenantID != 0 {
+		return 0, ErrAdminUserTenantScope
+	}
+	if adminID == userID {
+		return 0, ErrAdminUserSelf
+	}
+	if tenantID < 0 {
+		return 0, ErrAdminUserTenantInvalid
+	}
+	if tenantID != 0 {
+		exists, err := queries.UniversityExists(tenantID)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			return 0, ErrAdminUserTenantInvalid
+		}
+	}
+	state, err := s.accountState(userID)
+	if err != nil {
+		return 0, err
+	}
+	if state.TenantId == tenantID {
+		return tenantID, nil
+	}
+	if err := queries.SetUserTenant(userID, tenantID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrAdminUserNotFound
+		}
+		return 0, err
+	}
+	logger.Infof(adminUserServiceComponent, "Institución del usuario %d cambiada de %d a %d por %d", userID, state.TenantId, tenantID, adminID)
+	return state.TenantId, nil
+}
+
+// Deactivate desactiva la cuenta y cierra sus sesiones. El usuario recibe una notificación
+// antes de que el servicio WebSocket cierre sus conexiones.
+func (s *AdminUserService) Deactivate(adminID, userID int64, reason string) error {
+	if adminID == userID {
+		return ErrAdminUserSelf
+	}
+	reason = strings.TrimSpace(reason)
+	if utf8.RuneCountInString(reason) > deactivationReasonMaxLength {
+		return ErrAdminUserReasonTooLong
+	}
+	if err := queries.DeactivateUser(userID, adminID, reason); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrAdminUserNotFound
+		case errors.Is(err, queries.ErrUserDeactivated):
+			return ErrAdminUserDeactivated
+		}
+		return err
+	}
+
+	description := "Un administrador desactivó tu cuenta."
+	if reason != "" {
+		description = reason
+	}
+	s.notify(userID, models.EventTypeAccountDeactivated, "Tu cuenta fue desactivada", description)
+	logger.Infof(adminUserServiceComponent, "Cuenta %d desactivada por %d", userID, adminID)
+	return nil
+}
+
+// Reactivate reactiva una cuenta desactivada y devuelve el estado restaurado.
+func (s *AdminUserService) Reactivate(userID int64) (int, error) {
+	status, err := queries.ReactivateUser(userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrAdminUserNotFound
+		case errors.Is(err, queries.ErrUserNotDeactivated):
+			return 0, ErrAdminUserNotDeactivated
+		}
+		return 0, err
+	}
+	logger.Infof(adminUserServiceComponent, "Cuenta %d reactivada con estado %d", userID, status)
+	return status, nil
+}
+
+func (s *AdminUserService) accountState(userID int64) (models.AccountState, error) {
+	state, err := queries.GetAccountState(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return state, ErrAdminUserNotFound
+	}
+	return state, err
+}
+
+// notify crea una notificación de gestión de la cuenta para userID. Un fallo no revierte el
+// cambio.
+func (s *AdminUserService) notify(userID int64, eventType, title, description string) {
+	notification := models.Event{
+		EventType:   eventType,
+		EventTitle:  title,
+		Description: description,
+		UserId:      userID,
+	}
+	if err := queries.CreateEvent(&notification); err != nil {
+		logger.Errorf(adminUserServiceComponent, "No se pudo crear la notificación %s para el usuario %d: %v", eventType, userID, err)
+	}
+}
+
+// defaultRoleName devuelve el nombre del rol si existe en el catálogo.
+func defaultRoleName(roleID int) (string, bool) {
+	for _, role := range models.GetDefaultRoles() {
+		if role.Id == roleID {
+			return role.Name, true
+		}
+	}
+	return "", false
+}
+
+// isDefaultStatus indica si el StatusAuthorized existe en el catálogo.
+func isDefaultStatus(statusID int) bool {
+	for _, status := range models.GetDefaultStatusAuthorized() {
+		if status.Id == statusID {
+			return true
+		}
+	}
+	return false
+}
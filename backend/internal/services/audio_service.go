@@ -14,6 +14,7 @@ import (
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/cloudclient"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/storage"
 	"github.com/google/uuid"
 	"github.com/h2non/filetype"
 	"github.com/h2non/filetype/types"
@@ -21,22 +22,24 @@ import (
 
 // AudioUploadService encapsula la lógica para subir y procesar archivos de audio.
 type AudioUploadService struct {
-	db  *sql.DB
-	cfg *config.Config
+	db      *sql.DB
+	cfg     *config.Config
+	store   storage.Storage // Notas de voz; los audios normales aún se suben con cloudclient
+	scanner IUploadScanService
 }
 
 // NewAudioUploadService crea una nueva instancia de AudioUploadService.
-func NewAudioUploadService(db *sql.DB, cfg *config.Config) *AudioUploadService {
-	return &AudioUploadService{db: db, cfg: cfg}
+func NewAudioUploadService(db *sql.DB, cfg *config.Config, store storage.Storage, scanner IUploadScanService) *AudioUploadService {
+	return &AudioUploadService{db: db, cfg: cfg, store: store, scanner: scanner}
 }
 
 // UploadAudioDetails contiene la información del audio subido para la respuesta.
 type UploadAudioDetails struct {
-	ID        string `json:"id"`        // ID del contenido (ContentID)
-	FileName  string `json:"fileName"`  // Nombre del archivo en GCS (ej: uuid.mp3)
-	Extension string `json:"extension"` // ej. "mp3", "wav"
-	URL       string `json:"url"`       // URL GCS del archivo
-	// Podríamos añadir Duration float32 `json:"duration,omitempty"` si se implementa extracción de duración
+	ID        string  `json:"id"`                 // ID del contenido (ContentID)
+	FileName  string  `json:"fileName"`           // Nombre del archivo en GCS (ej: uuid.mp3)
+	Extension string  `json:"extension"`          // ej. "mp3", "wav"
+	URL       string  `json:"url"`                // URL GCS del archivo
+	Duration  float64 `json:"duration,omitempty"` // Segundos; solo en notas de voz
 }
 
 // ProcessAndUploadAudio procesa un archivo de audio subido y lo guarda.
@@ -73,6 +76,10 @@ func (s *AudioUploadService) ProcessAndUploadAudio(ctx context.Context, userID i
 		return nil, fmt.Errorf("el tipo de archivo de audio no está permitido: %s", kind.MIME.Value)
 	}
 
+	if err := s.scanner.Check(ctx, userID, "audio", fileHeader.Filename, fileBytes); err != nil {
+		return nil, err
+	}
+
 	contentID := uuid.New().String()
 	baseFileName := uuid.New().String()
 	fileExtension := strings.ToLower(kind.Extension) // Usar la extensión detectada
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/auth"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const auditServiceComponent = "AUDIT_SERVICE"
+
+// IAuditService define la consulta del registro de auditoría.
+type IAuditService interface {
+	ListLogs(filter models.AuditLogFilter, page, pageSize int) (*models.PaginatedAuditLogResponse, error)
+}
+
+// AuditService consulta el registro de auditoría de acciones sensibles.
+type AuditService struct{}
+
+// NewAuditService crea una nueva instancia de AuditService.
+func NewAuditService() IAuditService {
+	return &AuditService{}
+}
+
+// ListLogs devuelve una página del registro de auditoría con los filtros indicados.
+func (s *AuditService) ListLogs(filter models.AuditLogFilter, page, pageSize int) (*models.PaginatedAuditLogResponse, error) {
+	total, err := queries.CountAuditLogs(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.PaginatedAuditLogResponse{
+		CurrentPage:  page,
+		PageSize:     pageSize,
+		TotalPages:   int(math.Ceil(float64(total) / float64(pageSize))),
+		TotalRecords: total,
+		Logs:         []models.AuditLog{},
+	}
+	if total == 0 {
+		return response, nil
+	}
+
+	response.Logs, err = queries.GetAuditLogsPaginated(filter, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// RecordAudit registra una acción sensible en el AuditLog. Si r no es nil y la entrada no
+// trae IP, se toma la del cliente. Un fallo al registrar solo se anota en el log: nunca
+// debe interrumpir la operación auditada.
+func RecordAudit(r *http.Request, entry models.AuditLog, metadata map[string]interface{}) {
+	if entry.IPAddress == "" && r != nil {
+		entry.IPAddress = ClientIP(r)
+	}
+	// Las acciones hechas con un token de suplantación se atribuyen también al administrador.
+	if r != nil {
+		if impersonatorID := auth.ImpersonatorFromContext(r.Context()); impersonatorID != 0 {
+			if metadata == nil {
+				metadata = make(map[string]interface{})
+			}
+			metadata["impersonatorId"] = impersonatorID
+		}
+	}
+	if len(metadata) > 0 {
+		raw, err := json.Marshal(metadata)
+		if err != nil {
+			logger.Warnf(auditServiceComponent, "No se pudo serializar la metadata de %s: %v", entry.Action, err)
+		} else {
+			entry.Metadata = raw
+		}
+	}
+	if err := queries.InsertAuditLog(entry); err != nil {
+		logger.Errorf(auditServiceComponent, "%v", err)
+	}
+}
+
+// ClientIP obtiene la IP del cliente teniendo en cuenta las cabeceras del proxy.
+func ClientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		return realIP
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
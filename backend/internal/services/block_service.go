@@ -0,0 +1,98 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const blockServiceComponent = "BLOCK_SERVICE"
+
+// Errores de negocio de los bloqueos.
+var (
+	ErrBlockSelf         = apperrors.New(apperrors.BlockInvalid, "no puedes bloquearte a ti mismo")
+	ErrBlockUserNotFound = apperrors.New(apperrors.NotFound, "usuario no encontrado")
+)
+
+// IBlockService define la interfaz del servicio de bloqueos.
+type IBlockService interface {
+	Block(userID, blockedID int64) error
+	Unblock(userID, blockedID int64) error
+	List(userID int64, page, pageSize int) (*models.BlockedUsersResponse, error)
+}
+
+// BlockService gestiona los bloqueos entre usuarios. El bloqueo es unilateral al crearlo, pero
+// sus efectos son simétricos: ninguno de los dos puede enviar solicitudes ni mensajes al otro
+// y cada uno deja de ver el contenido del otro en el feed y en las búsquedas.
+type BlockService struct {
+	db *sql.DB
+}
+
+// NewBlockService crea una nueva instancia de BlockService.
+func NewBlockService(db *sql.DB) IBlockService {
+	return &BlockService{db: db}
+}
+
+// Block bloquea a blockedID. Bloquear a un usuario ya bloqueado no es un error.
+func (s *BlockService) Block(userID, blockedID int64) error {
+	if userID == blockedID {
+		return ErrBlockSelf
+	}
+	if _, err := queries.GetAccountState(blockedID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrBlockUserNotFound
+		}
+		return err
+	}
+
+	created, err := queries.BlockUser(userID, blockedID)
+	if err != nil {
+		return err
+	}
+	if created {
+		logger.Infof(blockServiceComponent, "El usuario %d bloqueó al usuario %d", userID, blockedID)
+	}
+	return nil
+}
+
+// Unblock elimina el bloqueo a blockedID. Desbloquear a un usuario no bloqueado no es un error.
+func (s *BlockService) Unblock(userID, blockedID int64) error {
+	removed, err := queries.UnblockUser(userID, blockedID)
+	if err != nil {
+		return err
+	}
+	if removed {
+		logger.Infof(blockServiceComponent, "El usuario %d desbloqueó al usuario %d", userID, blockedID)
+	}
+	return nil
+}
+
+// List devuelve los usuarios bloqueados por userID.
+func (s *BlockService) List(userID int64, page, pageSize int) (*models.BlockedUsersResponse, error) {
+	blocks, total, err := queries.ListBlockedUsers(userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(blocks))
+	for i, block := range blocks {
+		ids[i] = block.User.ID
+	}
+	users, err := queries.GetUserBaseInfoByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range blocks {
+		if user, ok := users[blocks[i].User.ID]; ok {
+			blocks[i].User = *user
+		}
+	}
+	return &models.BlockedUsersResponse{
+		Data:       blocks,
+		Pagination: paginationDetails(total, page, pageSize),
+	}, nil
+}
@@ -0,0 +1,346 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/go-sql-driver/mysql"
+)
+
+const catalogServiceComponent = "CATALOG_SERVICE"
+
+// catalogFieldMaxLength es la longitud máxima de los campos de texto de los catálogos, en
+// caracteres (las columnas son VARCHAR(255)).
+const catalogFieldMaxLength = 255
+
+const (
+	// universityEmailDomainsMax es el máximo de dominios de correo por universidad.
+	universityEmailDomainsMax = 20
+	// universityEmailDomainsMaxLength es la longitud de la lista separada por comas que cabe en
+	// la columna EmailDomains (VARCHAR(1000)).
+	universityEmailDomainsMaxLength = 1000
+)
+
+// emailDomainPattern valida un dominio de correo en minúsculas, p. ej. ucv.ve.
+var emailDomainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+// slugPattern valida el subdominio de una universidad, p. ej. ucv.
+var slugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// isoCodePattern valida los códigos ISO 3166-1 alfa-2 de las nacionalidades.
+var isoCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// Errores de negocio de los catálogos.
+var (
+	ErrCatalogNotFound          = apperrors.New(apperrors.CatalogNotFound, "el elemento del catálogo no existe")
+	ErrCatalogInUse             = apperrors.New(apperrors.CatalogInUse, "el elemento está asignado a usuarios o tiene carreras")
+	ErrNationalityDuplicate     = apperrors.New(apperrors.CatalogDuplicate, "ya existe una nacionalidad con ese nombre o código ISO")
+	ErrUniversityDuplicate      = apperrors.New(apperrors.CatalogDuplicate, "ya existe una universidad con ese nombre")
+	ErrDegreeDuplicate          = apperrors.New(apperrors.CatalogDuplicate, "la universidad ya tiene una carrera con ese nombre o código")
+	ErrNationalityIsoCode       = apperrors.New(apperrors.CatalogInvalid, "iso_code debe ser un código ISO 3166-1 alfa-2 (ej. VE)")
+	ErrNationalityDocIdFormat   = apperrors.New(apperrors.CatalogInvalid, "doc_id_format no es una expresión regular válida")
+	ErrDegreeUniversityNotFound = apperrors.New(apperrors.CatalogInvalid, "university_id no corresponde a ninguna universidad")
+	ErrUniversityEmailDomains   = apperrors.New(apperrors.CatalogInvalid, fmt.Sprintf("email_domains admite hasta %d dominios válidos (ej. ucv.ve)", universityEmailDomainsMax))
+	ErrUniversitySlug           = apperrors.New(apperrors.CatalogInvalid, "slug debe ser un subdominio válido: minúsculas, números y guiones (ej. ucv)")
+)
+
+// ICatalogService define la interfaz del servicio de catálogos.
+type ICatalogService interface {
+	GetCatalogs() (*models.Catalogs, error)
+	CreateNationality(nat models.Nationality) (models.Nationality, error)
+	UpdateNationality(id int64, nat models.Nationality) (models.Nationality, error)
+	DeleteNationality(id int64) error
+	CreateUniversity(uni models.University) (models.University, error)
+	UpdateUniversity(id int64, uni models.University) (models.University, error)
+	DeleteUniversity(id int64) error
+	CreateDegree(deg models.Degree) (models.Degree, error)
+	UpdateDegree(id int64, deg models.Degree) (models.Degree, error)
+	DeleteDegree(id int64) error
+}
+
+// CatalogService gestiona los catálogos de nacionalidades, universidades y carreras. Cada
+// escritura descarta la caché de catálogos, así que las lecturas públicas (y su ETag) cambian
+// de inmediato.
+type CatalogService struct {
+	db *sql.DB
+}
+
+// NewCatalogService crea una nueva instancia de CatalogService.
+func NewCatalogService(db *sql.DB) ICatalogService {
+	return &CatalogService{db: db}
+}
+
+// GetCatalogs devuelve los tres catálogos.
+func (s *CatalogService) GetCatalogs() (*models.Catalogs, error) {
+	nationalities, err := queries.GetNationalities()
+	if err != nil {
+		return nil, err
+	}
+	universities, err := queries.GetUniversities()
+	if err != nil {
+		return nil, err
+	}
+	degrees, err := queries.GetAllDegrees()
+	if err != nil {
+		return nil, err
+	}
+	return &models.Catalogs{Nationalities: nationalities, Universities: universities, Degrees: degrees}, nil
+}
+
+// CreateNationality crea una nacionalidad.
+func (s *CatalogService) CreateNationality(nat models.Nationality) (models.Nationality, error) {
+	nat, err := s.validateNationality(0, nat)
+	if err != nil {
+		return nat, err
+	}
+	id, err := queries.InsertNationality(nat)
+	if err != nil {
+		return nat, catalogWriteError(err, ErrNationalityDuplicate)
+	}
+	nat.Id = int(id)
+	logger.Infof(catalogServiceComponent, "Nacionalidad %d creada: %s (%s)", nat.Id, nat.CountryName, nat.IsoCode)
+	return nat, nil
+}
+
+// UpdateNationality reemplaza los datos de la nacionalidad.
+func (s *CatalogService) UpdateNationality(id int64, nat models.Nationality) (models.Nationality, error) {
+	nat, err := s.validateNationality(id, nat)
+	if err != nil {
+		return nat, err
+	}
+	nat.Id = int(id)
+	if err := queries.UpdateNationality(nat); err != nil {
+		return nat, catalogWriteError(err, ErrNationalityDuplicate)
+	}
+	return nat, nil
+}
+
+// DeleteNationality elimina una nacionalidad que ningún usuario tenga asignada.
+func (s *CatalogService) DeleteNationality(id int64) error {
+	return catalogWriteError(queries.DeleteNationality(id), nil)
+}
+
+// CreateUniversity crea una universidad.
+func (s *CatalogService) CreateUniversity(uni models.University) (models.University, error) {
+	uni, err := validateUniversity(0, uni)
+	if err != nil {
+		return uni, err
+	}
+	id, err := queries.InsertUniversity(uni)
+	if err != nil {
+		return uni, catalogWriteError(err, ErrUniversityDuplicate)
+	}
+	uni.Id = id
+	logger.Infof(catalogServiceComponent, "Universidad %d creada: %s", uni.Id, uni.Name)
+	return uni, nil
+}
+
+// UpdateUniversity reemplaza los datos de la universidad.
+func (s *CatalogService) UpdateUniversity(id int64, uni models.University) (models.University, error) {
+	uni, err := validateUniversity(id, uni)
+	if err != nil {
+		return uni, err
+	}
+	uni.Id = id
+	if err := queries.UpdateUniversity(uni); err != nil {
+		return uni, catalogWriteError(err, ErrUniversityDuplicate)
+	}
+	return uni, nil
+}
+
+// DeleteUniversity elimina una universidad sin carreras ni usuarios.
+func (s *CatalogService) DeleteUniversity(id int64) error {
+	return catalogWriteError(queries.DeleteUniversity(id), nil)
+}
+
+// CreateDegree crea una carrera.
+func (s *CatalogService) CreateDegree(deg models.Degree) (models.Degree, error) {
+	deg, err := s.validateDegree(0, deg)
+	if err != nil {
+		return deg, err
+	}
+	id, err := queries.InsertDegree(deg)
+	if err != nil {
+		return deg, catalogWriteError(err, ErrDegreeDuplicate)
+	}
+	deg.Id = id
+	logger.Infof(catalogServiceComponent, "Carrera %d creada en la universidad %d: %s", deg.Id, deg.UniversityId, deg.DegreeName)
+	return deg, nil
+}
+
+// UpdateDegree reemplaza los datos de la carrera.
+func (s *CatalogService) UpdateDegree(id int64, deg models.Degree) (models.Degree, error) {
+	deg, err := s.validateDegree(id, deg)
+	if err != nil {
+		return deg, err
+	}
+	deg.Id = id
+	if err := queries.UpdateDegree(deg); err != nil {
+		return deg, catalogWriteError(err, ErrDegreeDuplicate)
+	}
+	return deg, nil
+}
+
+// DeleteDegree elimina una carrera que ningún usuario tenga asignada.
+func (s *CatalogService) DeleteDegree(id int64) error {
+	return catalogWriteError(queries.DeleteDegree(id), nil)
+}
+
+// validateNationality normaliza la nacionalidad y comprueba que el código ISO no lo use otra
+// distinta de id. El nombre repetido lo rechaza el índice único de la tabla.
+func (s *CatalogService) validateNationality(id int64, nat models.Nationality) (models.Nationality, error) {
+	nat.CountryName = strings.TrimSpace(nat.CountryName)
+	nat.IsoCode = strings.ToUpper(strings.TrimSpace(nat.IsoCode))
+	nat.DocIdFormat = strings.TrimSpace(nat.DocIdFormat)
+	if err := catalogText("country_name", nat.CountryName, true); err != nil {
+		return nat, err
+	}
+	if !isoCodePattern.MatchString(nat.IsoCode) {
+		return nat, ErrNationalityIsoCode
+	}
+	if err := catalogText("doc_id_format", nat.DocIdFormat, false); err != nil {
+		return nat, err
+	}
+	if nat.DocIdFormat != "" {
+		if _, err := regexp.Compile(nat.DocIdFormat); err != nil {
+			return nat, ErrNationalityDocIdFormat
+		}
+	}
+
+	taken, err := queries.NationalityIsoCodeTaken(nat.IsoCode, id)
+	if err != nil {
+		return nat, err
+	}
+	if taken {
+		return nat, ErrNationalityDuplicate
+	}
+	return nat, nil
+}
+
+// validateUniversity normaliza la universidad y comprueba que ninguna otra distinta de id use
+// sus dominios de correo ni su subdominio. El nombre repetido lo rechaza el índice único de la
+// tabla.
+func validateUniversity(id int64, uni models.University) (models.University, error) {
+	uni.Name = strings.TrimSpace(uni.Name)
+	uni.Campus = strings.TrimSpace(uni.Campus)
+	if err := catalogText("name", uni.Name, true); err != nil {
+		return uni, err
+	}
+	if err := catalogText("campus", uni.Campus, false); err != nil {
+		return uni, err
+	}
+
+	domains := []string{}
+	seen := make(map[string]bool)
+	for _, d := range uni.EmailDomains {
+		d = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), "@")
+		if !emailDomainPattern.MatchString(d) {
+			return uni, ErrUniversityEmailDomains
+		}
+		if !seen[d] {
+			seen[d] = true
+			domains = append(domains, d)
+		}
+	}
+	if len(domains) > universityEmailDomainsMax || len(strings.Join(domains, ",")) > universityEmailDomainsMaxLength {
+		return uni, ErrUniversityEmailDomains
+	}
+	uni.EmailDomains = domains
+
+	uni.Slug = strings.ToLower(strings.TrimSpace(uni.Slug))
+	if uni.Slug != "" && !slugPattern.MatchString(uni.Slug) {
+		return uni, ErrUniversitySlug
+	}
+
+	universities, err := queries.GetUniversities()
+	if err != nil {
+		return uni, err
+	}
+	for _, other := range universities {
+		if other.Id == id {
+			continue
+		}
+		if uni.Slug != "" && other.Slug == uni.Slug {
+			return uni, apperrors.New(apperrors.CatalogDuplicate, fmt.Sprintf("la universidad %s ya usa el subdominio %s", other.Name, uni.Slug))
+		}
+		for _, d := range other.EmailDomains {
+			if seen[d] {
+				return uni, apperrors.New(apperrors.CatalogDuplicate, fmt.Sprintf("la universidad %s ya usa el dominio de correo %s", other.Name, d))
+			}
+		}
+	}
+	return uni, nil
+}
+
+// validateDegree normaliza la carrera y comprueba que la universidad exista y no tenga otra
+// carrera distinta de id con el mismo nombre o código.
+func (s *CatalogService) validateDegree(id int64, deg models.Degree) (models.Degree, error) {
+	deg.DegreeName = strings.TrimSpace(deg.DegreeName)
+	deg.Descriptions = strings.TrimSpace(deg.Descriptions)
+	deg.Code = strings.ToUpper(strings.TrimSpace(deg.Code))
+	if err := catalogText("degree_name", deg.DegreeName, true); err != nil {
+		return deg, err
+	}
+	if err := catalogText("descriptions", deg.Descriptions, false); err != nil {
+		return deg, err
+	}
+	if err := catalogText("code", deg.Code, false); err != nil {
+		return deg, err
+	}
+
+	exists, err := queries.UniversityExists(deg.UniversityId)
+	if err != nil {
+		return deg, err
+	}
+	if !exists {
+		return deg, ErrDegreeUniversityNotFound
+	}
+	taken, err := queries.DegreeTaken(deg.UniversityId, deg.DegreeName, deg.Code, id)
+	if err != nil {
+		return deg, err
+	}
+	if taken {
+		return deg, ErrDegreeDuplicate
+	}
+	return deg, nil
+}
+
+// catalogText valida la longitud de un campo de texto del catálogo.
+func catalogText(field, value string, required bool) error {
+	if required && value == "" {
+		return apperrors.New(apperrors.CatalogInvalid, field+" es obligatorio")
+	}
+	if utf8.RuneCountInString(value) > catalogFieldMaxLength {
+		return apperrors.New(apperrors.CatalogInvalid, fmt.Sprintf("%s no puede superar los %d caracteres", field, catalogFieldMaxLength))
+	}
+	return nil
+}
+
+// catalogWriteError traduce los errores de escritura de los catálogos: fila inexistente,
+// clave duplicada (1062) y fila referenciada por otra tabla (1451).
+func catalogWriteError(err error, duplicate error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCatalogNotFound
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch {
+		case mysqlErr.Number == 1062 && duplicate != nil:
+			return duplicate
+		case mysqlErr.Number == 1451:
+			return ErrCatalogInUse
+		}
+	}
+	return err
+}
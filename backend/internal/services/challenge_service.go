@@ -0,0 +1,413 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const challengeServiceComponent = "CHALLENGE_SERVICE"
+
+const (
+	// challengeTextMaxLength es la longitud máxima de la descripción de una entrega y del
+	// feedback de una revisión, en caracteres.
+	challengeTextMaxLength = 2000
+	// challengeMaxScore es la puntuación máxima de una revisión.
+	challengeMaxScore = 100
+)
+
+// Tipos de notificación (Event.EventType) generados por los desafíos.
+const (
+	EventTypeChallengeSubmission = "CHALLENGE_SUBMISSION"
+	EventTypeChallengeReviewed   = "CHALLENGE_REVIEWED"
+	EventTypeChallengeCompleted  = "CHALLENGE_COMPLETED"
+	EventTypeChallengeClosed     = "CHALLENGE_CLOSED"
+	EventTypeChallengeCancelled  = "CHALLENGE_CANCELLED"
+)
+
+// challengeTransitions son los cambios de estado permitidos de un desafío. CERRADO y
+// CANCELADO son finales.
+var challengeTransitions = map[string][]string{
+	models.ChallengeStatusOpen:       {models.ChallengeStatusEvaluating, models.ChallengeStatusClosed, models.ChallengeStatusCancelled},
+	models.ChallengeStatusEvaluating: {models.ChallengeStatusClosed, models.ChallengeStatusCancelled},
+}
+
+// Errores de negocio del servicio de desafíos.
+var (
+	ErrNotAChallenge            = apperrors.New(apperrors.ChallengeInvalid, "la publicación no es un desafío")
+	ErrSubmissionEmpty          = apperrors.New(apperrors.ChallengeInvalid, "la entrega necesita un repositorio o un archivo")
+	ErrSubmissionInvalidURL     = apperrors.New(apperrors.ChallengeInvalid, "la URL del repositorio debe ser http o https")
+	ErrSubmissionFileNotFound   = apperrors.New(apperrors.ChallengeInvalid, "el archivo indicado no existe o no es tuyo")
+	ErrSubmissionTextTooLong    = apperrors.New(apperrors.ChallengeInvalid, fmt.Sprintf("la descripción y el feedback no pueden superar los %d caracteres", challengeTextMaxLength))
+	ErrSubmissionInvalidStatus  = apperrors.New(apperrors.ChallengeInvalid, "estado de revisión no válido (EN_REVISION, APROBADA o RECHAZADA)")
+	ErrSubmissionInvalidScore   = apperrors.New(apperrors.ChallengeInvalid, fmt.Sprintf("la puntuación debe estar entre 0 y %d y es obligatoria para aprobar", challengeMaxScore))
+	ErrChallengeNotOpen         = apperrors.New(apperrors.ChallengeClosed, "el desafío no está recibiendo entregas")
+	ErrChallengeNotReviewable   = apperrors.New(apperrors.ChallengeClosed, "el desafío ya no admite revisiones")
+	ErrSubmissionNotFound       = apperrors.New(apperrors.SubmissionNotFound, "entrega no encontrada")
+	ErrChallengeTransition      = apperrors.New(apperrors.ChallengeInvalidTransition, "transición de estado del desafío no permitida")
+	ErrSubmissionLocked         = apperrors.New(apperrors.SubmissionLocked, "la entrega ya está en revisión y no se puede reemplazar")
+	ErrChallengeSubmitForbidden = apperrors.New(apperrors.Forbidden, "solo estudiantes y egresados pueden participar, y no en sus propios desafíos")
+	ErrChallengeOwnerOnly       = apperrors.New(apperrors.Forbidden, "solo el creador del desafío puede gestionar sus entregas")
+)
+
+// IChallengeService define la interfaz del servicio de entregas de desafíos.
+type IChallengeService interface {
+	Submit(eventID, userID int64, roleID models.UserRole, req models.SubmitChallengeRequest) (*models.ChallengeSubmission, error)
+	GetMySubmission(eventID, userID int64) (*models.ChallengeSubmission, error)
+	ListSubmissions(eventID, userID int64, roleID models.UserRole, status string, page, pageSize int) (*models.PaginatedChallengeSubmissions, error)
+	ReviewSubmission(eventID, submissionID, reviewerID int64, req models.ReviewSubmissionRequest) (*models.ChallengeSubmission, error)
+	UpdateChallengeStatus(eventID, userID int64, roleID models.UserRole, req models.UpdateChallengeStatusRequest) error
+}
+
+// ChallengeService gestiona las entregas a las publicaciones de tipo DESAFIO: los
+// participantes envían un repositorio o archivo, la empresa las revisa y al cerrar el
+// desafío las entregas aprobadas suman Puntos de Reputación a su autor.
+type ChallengeService struct {
+	db *sql.DB
+}
+
+// NewChallengeService crea una nueva instancia de ChallengeService.
+func NewChallengeService(db *sql.DB) IChallengeService {
+	return &ChallengeService{db: db}
+}
+
+// getChallenge obtiene la publicación y comprueba que sea un desafío.
+func getChallenge(eventID int64) (*models.ChallengeInfo, error) {
+	info, err := queries.GetChallengeInfo(eventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPostNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la publicación %d: %w", eventID, err)
+	}
+	if info.PostType != "DESAFIO" {
+		return nil, ErrNotAChallenge
+	}
+	return info, nil
+}
+
+// acceptsSubmissions indica si el desafío está abierto y dentro de sus fechas.
+func acceptsSubmissions(info *models.ChallengeInfo, now time.Time) bool {
+	if info.Status != models.ChallengeStatusOpen {
+		return false
+	}
+	if info.StartDate != nil && now.Before(*info.StartDate) {
+		return false
+	}
+	if info.EndDate != nil && now.After(*info.EndDate) {
+		return false
+	}
+	return true
+}
+
+// validateSubmission normaliza la entrega y comprueba que tenga un repositorio válido o un
+// archivo subido por el propio usuario.
+func validateSubmission(userID int64, req models.SubmitChallengeRequest) (models.SubmitChallengeRequest, error) {
+	req.RepositoryUrl = strings.TrimSpace(req.RepositoryUrl)
+	req.FileContentId = strings.TrimSpace(req.FileContentId)
+	req.Description = strings.TrimSpace(req.Description)
+
+	if req.RepositoryUrl == "" && req.FileContentId == "" {
+		return req, ErrSubmissionEmpty
+	}
+	if utf8.RuneCountInString(req.Description) > challengeTextMaxLength {
+		return req, ErrSubmissionTextTooLong
+	}
+	if req.RepositoryUrl != "" {
+		parsed, err := url.Parse(req.RepositoryUrl)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return req, ErrSubmissionInvalidURL
+		}
+	}
+	if req.FileContentId != "" {
+		owns, err := queries.UserOwnsContent(userID, req.FileContentId)
+		if err != nil {
+			return req, err
+		}
+		if !owns {
+			return req, ErrSubmissionFileNotFound
+		}
+	}
+	return req, nil
+}
+
+// Submit crea o reemplaza la entrega del usuario. Solo se admite mientras el desafío está
+// ABIERTO y dentro de sus fechas, y una entrega solo se puede reemplazar mientras la
+// empresa no haya empezado a revisarla. La primera entrega notifica al creador del desafío.
+func (s *ChallengeService) Submit(eventID, userID int64, roleID models.UserRole, req models.SubmitChallengeRequest) (*models.ChallengeSubmission, error) {
+	if roleID != models.RoleStudent && roleID != models.RoleEgresado {
+		return nil, ErrChallengeSubmitForbidden
+	}
+	info, err := getChallenge(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if info.OwnerId == userID {
+		return nil, ErrChallengeSubmitForbidden
+	}
+	if !acceptsSubmissions(info, time.Now()) {
+		return nil, ErrChallengeNotOpen
+	}
+
+	req, err = validateSubmission(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := queries.GetUserSubmission(eventID, userID)
+	switch {
+	case err == nil:
+		if existing.Status != models.SubmissionStatusSubmitted {
+			return nil, ErrSubmissionLocked
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("error al obtener la entrega de %d al desafío %d: %w", userID, eventID, err)
+	}
+
+	created, err := queries.SaveSubmission(eventID, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	submission, err := queries.GetUserSubmission(eventID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la entrega recién guardada: %w", err)
+	}
+
+	if created {
+		s.notify(info.OwnerId, userID, EventTypeChallengeSubmission,
+			fmt.Sprintf("Nueva entrega en \"%s\"", info.Title),
+			fmt.Sprintf("%s envió una solución a tu desafío.", userDisplayName(userID, roleID)),
+			models.EventMetadata{CommunityEventId: eventID, SubmissionId: submission.Id})
+	}
+	logger.Infof(challengeServiceComponent, "Entrega %d de %d guardada en el desafío %d", submission.Id, userID, eventID)
+	return submission, nil
+}
+
+// GetMySubmission devuelve la entrega del usuario al desafío.
+func (s *ChallengeService) GetMySubmission(eventID, userID int64) (*models.ChallengeSubmission, error) {
+	if _, err := getChallenge(eventID); err != nil {
+		return nil, err
+	}
+	submission, err := queries.GetUserSubmission(eventID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSubmissionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la entrega de %d al desafío %d: %w", userID, eventID, err)
+	}
+	return submission, nil
+}
+
+// ListSubmissions devuelve una página de las entregas del desafío para su creador (o un
+// administrador). status filtra por estado de la entrega; vacío las devuelve todas.
+func (s *ChallengeService) ListSubmissions(eventID, userID int64, roleID models.UserRole, status string, page, pageSize int) (*models.PaginatedChallengeSubmissions, error) {
+	info, err := getChallenge(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if info.OwnerId != userID && roleID != models.RoleAdmin {
+		return nil, ErrChallengeOwnerOnly
+	}
+	switch status {
+	case "", models.SubmissionStatusSubmitted, models.SubmissionStatusReviewing, models.SubmissionStatusApproved, models.SubmissionStatusRejected:
+	default:
+		return nil, apperrors.New(apperrors.InvalidParam, "estado de entrega no válido")
+	}
+
+	submissions, total, err := queries.ListSubmissions(eventID, status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := 0
+	if total > 0 {
+		totalPages = (total + pageSize - 1) / pageSize
+	}
+	return &models.PaginatedChallengeSubmissions{
+		Data: submissions,
+		Pagination: models.PaginationDetails{
+			TotalItems:  total,
+			TotalPages:  totalPages,
+			CurrentPage: page,
+			PageSize:    pageSize,
+		},
+	}, nil
+}
+
+// ReviewSubmission registra la revisión del creador del desafío sobre una entrega y avisa a
+// su autor. Se puede revisar mientras el desafío esté ABIERTO o EN_EVALUACION, y cambiar la
+// revisión hasta que se cierre.
+func (s *ChallengeService) ReviewSubmission(eventID, submissionID, reviewerID int64, req models.ReviewSubmissionRequest) (*models.ChallengeSubmission, error) {
+	info, err := getChallenge(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if info.OwnerId != reviewerID {
+		return nil, ErrChallengeOwnerOnly
+	}
+	if info.Status != models.ChallengeStatusOpen && info.Status != models.ChallengeStatusEvaluating {
+		return nil, ErrChallengeNotReviewable
+	}
+
+	switch req.Status {
+	case models.SubmissionStatusReviewing, models.SubmissionStatusApproved, models.SubmissionStatusRejected:
+	default:
+		return nil, ErrSubmissionInvalidStatus
+	}
+	if req.Score != nil && (*req.Score < 0 || *req.Score > challengeMaxScore) {
+		return nil, ErrSubmissionInvalidScore
+	}
+	if req.Status == models.SubmissionStatusApproved && req.Score == nil {
+		return nil, ErrSubmissionInvalidScore
+	}
+	req.Feedback = strings.TrimSpace(req.Feedback)
+	if utf8.RuneCountInString(req.Feedback) > challengeTextMaxLength {
+		return nil, ErrSubmissionTextTooLong
+	}
+
+	submission, err := queries.GetSubmissionByID(submissionID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && submission.CommunityEventId != eventID) {
+		return nil, ErrSubmissionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la entrega %d: %w", submissionID, err)
+	}
+
+	if err := queries.ReviewSubmission(submissionID, reviewerID, req.Status, req.Score, req.Feedback); err != nil {
+		return nil, err
+	}
+	updated, err := queries.GetSubmissionByID(submissionID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la entrega %d revisada: %w", submissionID, err)
+	}
+
+	if req.Status != models.SubmissionStatusReviewing {
+		title := fmt.Sprintf("Tu entrega en \"%s\" fue aprobada", info.Title)
+		if req.Status == models.SubmissionStatusRejected {
+			title = fmt.Sprintf("Tu entrega en \"%s\" fue rechazada", info.Title)
+		}
+		description := "Revisa el feedback de la empresa."
+		if req.Feedback != "" {
+			description = previewText(req.Feedback, 100)
+		}
+		s.notify(submission.UserId, reviewerID, EventTypeChallengeReviewed, title, description,
+			models.EventMetadata{CommunityEventId: eventID, SubmissionId: submissionID})
+	}
+	return updated, nil
+}
+
+// UpdateChallengeStatus cambia el estado del desafío. Al cerrarlo, cada entrega aprobada
+// suma a su autor los RP de su puntuación (convertida a estrellas, 0-100 -> 0-5) como una
+// reseña DESAFIO_COMPLETADO del creador. Al cerrarlo o cancelarlo se avisa a todos los
+// participantes.
+func (s *ChallengeService) UpdateChallengeStatus(eventID, userID int64, roleID models.UserRole, req models.UpdateChallengeStatusRequest) error {
+	info, err := getChallenge(eventID)
+	if err != nil {
+		return err
+	}
+	if info.OwnerId != userID && roleID != models.RoleAdmin {
+		return ErrChallengeOwnerOnly
+	}
+
+	allowed := false
+	for _, next := range challengeTransitions[info.Status] {
+		allowed = allowed || next == req.Status
+	}
+	if !allowed {
+		return ErrChallengeTransition
+	}
+
+	submissions, err := queries.GetAllSubmissions(eventID)
+	if err != nil {
+		return err
+	}
+
+	if req.Status == models.ChallengeStatusClosed {
+		awards := make(map[int64]int)
+		var toAward []queries.ChallengeAward
+		for _, sub := range submissions {
+			if sub.Status != models.SubmissionStatusApproved || sub.Score == nil {
+				continue
+			}
+			rating := math.Round(float64(*sub.Score)/challengeMaxScore*5*10) / 10
+			award := queries.ChallengeAward{
+				SubmissionId: sub.Id,
+				UserId:       sub.UserId,
+				PointsRP:     starsToRP(rating),
+				Rating:       rating,
+				Comment:      sub.Feedback,
+			}
+			toAward = append(toAward, award)
+			awards[sub.Id] = award.PointsRP
+		}
+		if err := queries.CloseChallenge(eventID, info.OwnerId, info.Status, toAward); err != nil {
+			if errors.Is(err, queries.ErrChallengeStatusChanged) {
+				return ErrChallengeTransition
+			}
+			return err
+		}
+
+		for _, sub := range submissions {
+			metadata := models.EventMetadata{CommunityEventId: eventID, SubmissionId: sub.Id}
+			if points, ok := awards[sub.Id]; ok {
+				metadata.PointsRP = points
+				s.notify(sub.UserId, info.OwnerId, EventTypeChallengeCompleted,
+					fmt.Sprintf("Completaste el desafío \"%s\"", info.Title),
+					fmt.Sprintf("Tu entrega fue aprobada y ganaste %d RP.", points), metadata)
+				continue
+			}
+			s.notify(sub.UserId, info.OwnerId, EventTypeChallengeClosed,
+				fmt.Sprintf("El desafío \"%s\" terminó", info.Title),
+				"El desafío se cerró. Consulta el resultado de tu entrega.", metadata)
+		}
+		logger.Infof(challengeServiceComponent, "Desafío %d cerrado con %d entregas aprobadas", eventID, len(toAward))
+		return nil
+	}
+
+	if err := queries.UpdateChallengeStatus(eventID, info.Status, req.Status); err != nil {
+		if errors.Is(err, queries.ErrChallengeStatusChanged) {
+			return ErrChallengeTransition
+		}
+		return err
+	}
+	if req.Status == models.ChallengeStatusCancelled {
+		for _, sub := range submissions {
+			s.notify(sub.UserId, info.OwnerId, EventTypeChallengeCancelled,
+				fmt.Sprintf("El desafío \"%s\" fue cancelado", info.Title),
+				"El creador canceló el desafío. Tu entrega no será evaluada.",
+				models.EventMetadata{CommunityEventId: eventID, SubmissionId: sub.Id})
+		}
+	}
+	logger.Infof(challengeServiceComponent, "Desafío %d pasó de %s a %s", eventID, info.Status, req.Status)
+	return nil
+}
+
+// notify crea una notificación para userID. Un fallo al notificar no invalida la operación.
+func (s *ChallengeService) notify(userID, otherUserID int64, eventType, title, description string, metadata models.EventMetadata) {
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		logger.Errorf(challengeServiceComponent, "Error al serializar los metadatos de la notificación: %v", err)
+	}
+	notification := models.Event{
+		EventType:   eventType,
+		EventTitle:  title,
+		Description: description,
+		UserId:      userID,
+		OtherUserId: sql.NullInt64{Int64: otherUserID, Valid: true},
+		Metadata:    metadataJSON,
+	}
+	if err := queries.CreateEventWithOutbox(&notification); err != nil {
+		logger.Errorf(challengeServiceComponent, "No se pudo crear la notificación %s para el usuario %d: %v", eventType, userID, err)
+	}
+}
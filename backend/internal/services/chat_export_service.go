@@ -0,0 +1,385 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/cloudclient"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const chatExportServiceComponent = "CHAT_EXPORT_SERVICE"
+
+const (
+	// chatExportMaintenanceInterval es la frecuencia con la que se borran los archivos caducados
+	// y se retoman las exportaciones interrumpidas.
+	chatExportMaintenanceInterval = time.Hour
+	// chatExportUploadTimeout limita la generación y subida de un archivo a GCS.
+	chatExportUploadTimeout = 30 * time.Minute
+	// chatExportObjectPrefix agrupa los archivos en el bucket; permite añadir una regla de
+	// ciclo de vida como respaldo del borrado periódico.
+	chatExportObjectPrefix = "chat-exports/"
+)
+
+var (
+	ErrChatExportInvalidChat   = apperrors.New(apperrors.ChatExportInvalid, "se requiere el chatId de la conversación")
+	ErrChatExportInvalidFormat = apperrors.New(apperrors.ChatExportInvalid, "formato inválido: use 'json' o 'txt'")
+	ErrChatExportChatNotFound  = apperrors.New(apperrors.ChatNotFound, "chat no encontrado")
+	ErrChatExportActive        = apperrors.New(apperrors.ChatExportActive, "ya tienes una exportación en curso de este chat")
+	ErrChatExportNotFound      = apperrors.New(apperrors.ChatExportNotFound, "exportación no encontrada")
+	ErrChatExportUnavailable   = apperrors.New(apperrors.ChatExportUnavailable, "la exportación de conversaciones no está disponible")
+)
+
+// IChatExportService define la exportación del historial de una conversación.
+type IChatExportService interface {
+	Create(userID int64, req models.CreateChatExportRequest) (*models.ChatExport, error)
+	List(userID int64, page, pageSize int) (*models.ChatExportsResponse, error)
+	Get(userID, exportID int64) (*models.ChatExport, error)
+}
+
+// ChatExportService genera el historial de un chat en JSON o texto plano, lo sube a GCS sin
+// acceso público y lo entrega con enlaces firmados de corta duración. Los chats pequeños se
+// exportan durante la propia petición y los grandes en segundo plano.
+type ChatExportService struct {
+	db        *sql.DB
+	exportTTL time.Duration
+	urlTTL    time.Duration
+	syncLimit int
+}
+
+// NewChatExportService crea el servicio y lanza el mantenimiento periódico de las exportaciones.
+func NewChatExportService(db *sql.DB, cfg *config.Config) IChatExportService {
+	s := &ChatExportService{
+		db:        db,
+		exportTTL: cfg.ChatExportTTL,
+		urlTTL:    cfg.ChatExportURLTTL,
+		syncLimit: cfg.ChatExportSyncLimit,
+	}
+	go s.maintenanceLoop()
+	return s
+}
+
+// storageReady indica si el cliente de GCS está inicializado.
+func (s *ChatExportService) storageReady() bool {
+	return cloudclient.GetBucketHandle() != nil
+}
+
+// maintenanceLoop retoma exportaciones interrumpidas y borra los archivos caducados.
+func (s *ChatExportService) maintenanceLoop() {
+	s.maintain()
+
+	ticker := time.NewTicker(chatExportMaintenanceInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.maintain()
+	}
+}
+
+func (s *ChatExportService) maintain() {
+	if !s.storageReady() {
+		return
+	}
+
+	unfinished, err := queries.GetUnfinishedChatExports()
+	if err != nil {
+		logger.Errorf(chatExportServiceComponent, "No se pudieron obtener las exportaciones pendientes: %v", err)
+	}
+	for i := range unfinished {
+		s.process(&unfinished[i])
+	}
+
+	expired, err := queries.GetExpiredChatExports(time.Now())
+	if err != nil {
+		logger.Errorf(chatExportServiceComponent, "No se pudieron obtener las exportaciones caducadas: %v", err)
+		return
+	}
+	for i := range expired {
+		removeChatExportObject(&expired[i])
+	}
+}
+
+// removeChatExportObject borra de GCS el archivo de una exportación y olvida su ruta.
+func removeChatExportObject(export *models.ChatExport) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := cloudclient.DeleteFile(ctx, export.ObjectPath); err != nil {
+		logger.Warnf(chatExportServiceComponent, "No se pudo eliminar la exportación de chat %d: %v", export.Id, err)
+		return
+	}
+	if err := queries.ClearChatExportObject(export.Id); err != nil {
+		logger.Errorf(chatExportServiceComponent, "%v", err)
+	}
+}
+
+// Create registra la exportación de un chat del usuario. Si el chat no supera syncLimit
+// mensajes la exportación se devuelve completada y con el enlace de descarga; si no, se
+// devuelve pendiente y se genera en segundo plano.
+func (s *ChatExportService) Create(userID int64, req models.CreateChatExportRequest) (*models.ChatExport, error) {
+	req.ChatId = strings.TrimSpace(req.ChatId)
+	if req.ChatId == "" {
+		return nil, ErrChatExportInvalidChat
+	}
+	if req.Format == "" {
+		req.Format = models.ChatExportFormatJSON
+	}
+	if req.Format != models.ChatExportFormatJSON && req.Format != models.ChatExportFormatText {
+		return nil, ErrChatExportInvalidFormat
+	}
+	if !s.storageReady() {
+		return nil, ErrChatExportUnavailable
+	}
+
+	isGroup, err := queries.GetUserChatKind(userID, req.ChatId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrChatExportChatNotFound
+		}
+		return nil, err
+	}
+	count, err := queries.CountChatMessages(req.ChatId, isGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	id, created, err := queries.CreateChatExport(userID, req.ChatId, isGroup, req.Format, count)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrChatExportActive
+	}
+	export, err := queries.GetChatExport(id)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof(chatExportServiceComponent, "Exportación %d del chat %s (%d mensajes) registrada para el usuario %d", id, req.ChatId, count, userID)
+
+	if count > s.syncLimit {
+		go s.process(export)
+		return export, nil
+	}
+	if err := s.process(export); err != nil {
+		return nil, err
+	}
+	return s.Get(userID, id)
+}
+
+// List devuelve las exportaciones del usuario, sin enlaces de descarga.
+func (s *ChatExportService) List(userID int64, page, pageSize int) (*models.ChatExportsResponse, error) {
+	exports, total, err := queries.GetChatExportsByUser(userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ChatExportsResponse{
+		Data:       exports,
+		Pagination: paginationDetails(total, page, pageSize),
+	}, nil
+}
+
+// Get devuelve una exportación del usuario. Si está completada y el archivo no ha caducado
+// incluye un enlace de descarga firmado nuevo, válido durante urlTTL.
+func (s *ChatExportService) Get(userID, exportID int64) (*models.ChatExport, error) {
+	export, err := queries.GetChatExport(exportID)
+	if err == sql.ErrNoRows || (err == nil && export.UserId != userID) {
+		return nil, ErrChatExportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if export.Status != models.ChatExportCompleted || export.ObjectPath == "" ||
+		(export.ExpiresAt != nil && time.Now().After(*export.ExpiresAt)) {
+		return export, nil
+	}
+	if !s.storageReady() {
+		return nil, ErrChatExportUnavailable
+	}
+
+	// El enlace no debe sobrevivir al archivo.
+	ttl := s.urlTTL
+	if export.ExpiresAt != nil {
+		ttl = min(ttl, time.Until(*export.ExpiresAt))
+	}
+	downloadName := fmt.Sprintf("conversacion-%d.%s", export.Id, export.Format)
+	export.DownloadURL, err = cloudclient.SignedURL(export.ObjectPath, ttl, downloadName)
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// process reclama la exportación y la genera; otra instancia puede haberla reclamado antes.
+func (s *ChatExportService) process(export *models.ChatExport) error {
+	claimed, err := queries.ClaimChatExport(export.Id)
+	if err != nil {
+		logger.Errorf(chatExportServiceComponent, "%v", err)
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	if err := s.generate(export); err != nil {
+		logger.Errorf(chatExportServiceComponent, "Exportación %d del chat %s falló: %v", export.Id, export.ChatId, err)
+		if ferr := queries.FailChatExport(export.Id, err.Error()); ferr != nil {
+			logger.Errorf(chatExportServiceComponent, "%v", ferr)
+		}
+		return err
+	}
+	return nil
+}
+
+// generate escribe el archivo directamente sobre la subida a GCS, sin cargar el historial
+// completo en memoria.
+func (s *ChatExportService) generate(export *models.ChatExport) error {
+	participants, err := s.participants(export)
+	if err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	objectPath := fmt.Sprintf("%suser-%d/%d-%s.%s", chatExportObjectPrefix, export.UserId, export.Id, hex.EncodeToString(suffix), export.Format)
+	contentType := "application/json"
+	if export.Format == models.ChatExportFormatText {
+		contentType = "text/plain; charset=utf-8"
+	}
+
+	pr, pw := io.Pipe()
+	written := make(chan int, 1)
+	go func() {
+		count, err := writeChatExport(pw, export, participants)
+		written <- count
+		pw.CloseWithError(err)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), chatExportUploadTimeout)
+	defer cancel()
+	if err := cloudclient.UploadPrivate(ctx, pr, objectPath, contentType); err != nil {
+		pr.CloseWithError(err)
+		return err
+	}
+	count := <-written
+
+	expiresAt := time.Now().Add(s.exportTTL)
+	if err := queries.CompleteChatExport(export.Id, objectPath, count, expiresAt); err != nil {
+		cloudclient.DeleteFile(context.Background(), objectPath)
+		return err
+	}
+	logger.Successf(chatExportServiceComponent, "Exportación %d del chat %s generada: %d mensajes", export.Id, export.ChatId, count)
+
+	RecordAudit(nil, models.AuditLog{
+		ActorId:    &export.UserId,
+		Action:     models.AuditActionChatExported,
+		TargetType: models.AuditTargetChat,
+		TargetId:   export.ChatId,
+	}, map[string]interface{}{"exportId": export.Id, "format": export.Format, "messages": count})
+	return nil
+}
+
+// participants devuelve la información básica de los participantes actuales del chat.
+func (s *ChatExportService) participants(export *models.ChatExport) ([]models.UserBaseInfo, error) {
+	chatID, chatIDGroup := export.ChatId, ""
+	if export.IsGroup {
+		chatID, chatIDGroup = "", export.ChatId
+	}
+	ids, err := queries.GetChatMemberIDs(chatID, chatIDGroup)
+	if err != nil {
+		return nil, err
+	}
+	return baseInfoList(ids)
+}
+
+// writeChatExport escribe la exportación en el formato pedido y devuelve el número de
+// mensajes escritos.
+func writeChatExport(w io.Writer, export *models.ChatExport, participants []models.UserBaseInfo) (int, error) {
+	bw := bufio.NewWriter(w)
+	exportedAt := time.Now().UTC()
+	count := 0
+
+	var err error
+	if export.Format == models.ChatExportFormatText {
+		names := make([]string, len(participants))
+		for i, p := range participants {
+			names[i] = chatExportDisplayName(p)
+		}
+		fmt.Fprintf(bw, "Conversación %s\nExportada el %s UTC\nParticipantes: %s\n\n",
+			export.ChatId, exportedAt.Format("02/01/2006 15:04"), strings.Join(names, ", "))
+		err = queries.ForEachChatExportMessage(export.ChatId, export.IsGroup, func(m models.ChatExportMessage) error {
+			count++
+			_, err := bw.WriteString(chatExportTextLine(m))
+			return err
+		})
+	} else {
+		header, herr := json.Marshal(map[string]interface{}{
+			"chatId":       export.ChatId,
+			"isGroup":      export.IsGroup,
+			"exportedBy":   export.UserId,
+			"exportedAt":   exportedAt,
+			"participants": participants,
+		})
+		if herr != nil {
+			return 0, herr
+		}
+		fmt.Fprintf(bw, `{"chat":%s,"messages":[`, header)
+		err = queries.ForEachChatExportMessage(export.ChatId, export.IsGroup, func(m models.ChatExportMessage) error {
+			line, err := json.Marshal(m)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				bw.WriteByte(',')
+			}
+			count++
+			_, err = bw.Write(append([]byte{'\n'}, line...))
+			return err
+		})
+		if err == nil {
+			_, err = bw.WriteString("\n]}\n")
+		}
+	}
+	if err != nil {
+		return count, err
+	}
+	return count, bw.Flush()
+}
+
+// chatExportTextLine da formato de texto plano a un mensaje.
+func chatExportTextLine(m models.ChatExportMessage) string {
+	var text string
+	switch {
+	case m.IsHidden:
+		text = "[mensaje oculto por moderación]"
+	case m.Content != "" && m.MediaId != "":
+		text = fmt.Sprintf("%s [adjunto %s]", m.Content, m.MediaId)
+	case m.MediaId != "":
+		text = fmt.Sprintf("[adjunto %s]", m.MediaId)
+	default:
+		text = m.Content
+	}
+	if m.EditedAt != nil && !m.IsHidden {
+		text += " (editado)"
+	}
+	return fmt.Sprintf("[%s] %s: %s\n", m.SentAt.UTC().Format("2006-01-02 15:04:05"), m.SenderName, text)
+}
+
+func chatExportDisplayName(user models.UserBaseInfo) string {
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	return user.UserName
+}
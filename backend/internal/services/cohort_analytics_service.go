@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+)
+
+const (
+	// cohortAnalyticsMinSize es el número mínimo de miembros para mostrar una cohorte o los
+	// rankings de la población, para que las cifras no identifiquen a nadie.
+	cohortAnalyticsMinSize = 5
+	// cohortAnalyticsTopN es el número de empresas y habilidades devueltas.
+	cohortAnalyticsTopN = 10
+	// cohortAnalyticsMinYear es el primer año de graduación que se puede consultar.
+	cohortAnalyticsMinYear = 1950
+)
+
+// ErrInvalidCohortFilter se devuelve si el filtro de años está fuera de rango o invertido.
+var ErrInvalidCohortFilter = apperrors.New(apperrors.InvalidParam, fmt.Sprintf("'fromYear' y 'toYear' deben estar entre %d y el año próximo, con fromYear <= toYear", cohortAnalyticsMinYear))
+
+// ICohortAnalyticsService define las analíticas de cohortes de egresados.
+type ICohortAnalyticsService interface {
+	GetCohortAnalytics(filter models.CohortAnalyticsFilter) (*models.CohortAnalytics, error)
+}
+
+// CohortAnalyticsService calcula las estadísticas por universidad, carrera y año de graduación a
+// partir de los estudios (Education) y trabajos (WorkExperience) declarados por los usuarios.
+// Las consultas se cachean un día (CACHE_COHORT_TTL).
+type CohortAnalyticsService struct{}
+
+// NewCohortAnalyticsService crea una nueva instancia de CohortAnalyticsService.
+func NewCohortAnalyticsService() ICohortAnalyticsService {
+	return &CohortAnalyticsService{}
+}
+
+// GetCohortAnalytics devuelve las estadísticas de las cohortes que cumplen el filtro. Las
+// cohortes con menos de cohortAnalyticsMinSize miembros se omiten y, si toda la población es
+// menor, tampoco se devuelven las empresas ni las habilidades.
+func (s *CohortAnalyticsService) GetCohortAnalytics(filter models.CohortAnalyticsFilter) (*models.CohortAnalytics, error) {
+	filter.University = strings.TrimSpace(filter.University)
+	filter.Degree = strings.TrimSpace(filter.Degree)
+	maxYear := time.Now().Year() + 1
+	for _, year := range []int{filter.FromYear, filter.ToYear} {
+		if year != 0 && (year < cohortAnalyticsMinYear || year > maxYear) {
+			return nil, ErrInvalidCohortFilter
+		}
+	}
+	if filter.FromYear != 0 && filter.ToYear != 0 && filter.FromYear > filter.ToYear {
+		return nil, ErrInvalidCohortFilter
+	}
+
+	cached, err := queries.GetCohortAnalytics(filter, cohortAnalyticsTopN)
+	if err != nil {
+		return nil, err
+	}
+
+	// La caché puede compartirse entre peticiones: se trabaja sobre una copia.
+	result := *cached
+	result.MinCohortSize = cohortAnalyticsMinSize
+	result.Cohorts = make([]models.CohortStats, 0, len(cached.Cohorts))
+	for _, cohort := range cached.Cohorts {
+		if cohort.Members < cohortAnalyticsMinSize {
+			result.SuppressedCohorts++
+			continue
+		}
+		cohort.EmploymentRate = conversionRate(cohort.Employed, cohort.Members)
+		result.Cohorts = append(result.Cohorts, cohort)
+	}
+	if result.Totals.Members < cohortAnalyticsMinSize {
+		result.Totals = models.CohortTotals{}
+		result.TopEmployers = []models.EmployerCount{}
+		result.TopSkills = []models.SkillCount{}
+	}
+	result.Totals.EmploymentRate = conversionRate(result.Totals.Employed, result.Totals.Members)
+	return &result, nil
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const commentServiceComponent = "COMMENT_SERVICE"
+
+const (
+	// commentMaxLength es la longitud máxima de un comentario, en caracteres.
+	commentMaxLength = 2000
+	// commentReplyPreview es el número de respuestas que acompañan a cada comentario raíz
+	// en el listado de una publicación.
+	commentReplyPreview = 3
+)
+
+// Tipos de notificación (Event.EventType) generados por los comentarios.
+const (
+	EventTypeCommentCreated = "COMMENT_CREATED"
+	EventTypeCommentReply   = "COMMENT_REPLY"
+)
+
+// Errores de negocio del servicio de comentarios.
+var (
+	ErrEmptyComment         = apperrors.New(apperrors.CommentInvalid, "el comentario no puede estar vacío")
+	ErrCommentTooLong       = apperrors.New(apperrors.CommentInvalid, fmt.Sprintf("el comentario no puede superar los %d caracteres", commentMaxLength))
+	ErrCommentNotFound      = apperrors.New(apperrors.CommentNotFound, "comentario no encontrado")
+	ErrPostNotFound         = apperrors.New(apperrors.NotFound, "publicación no encontrada")
+	ErrCommentWrongPost     = apperrors.New(apperrors.CommentInvalid, "el comentario al que respondes pertenece a otra publicación")
+	ErrCommentEditForbidden = apperrors.New(apperrors.Forbidden, "solo el autor puede editar el comentario")
+	ErrCommentDelForbidden  = apperrors.New(apperrors.Forbidden, "solo el autor, el dueño de la publicación o un administrador pueden eliminar el comentario")
+)
+
+// ICommentService define la interfaz del servicio de comentarios de publicaciones.
+type ICommentService interface {
+	CreateComment(eventID, authorID int64, req models.CreateCommentRequest) (*models.Comment, error)
+	ListComments(eventID int64, page, pageSize int) (*models.PaginatedComments, error)
+	ListReplies(commentID int64, page, pageSize int) (*models.PaginatedComments, error)
+	UpdateComment(commentID, userID int64, req models.UpdateCommentRequest) (*models.Comment, error)
+	DeleteComment(commentID, userID int64, roleID models.UserRole) error
+}
+
+// CommentService gestiona los comentarios y respuestas de las publicaciones de la comunidad.
+// Los comentarios nuevos llegan en tiempo real a los suscriptores del tópico
+// "event:{id}:comments" a través del servicio WebSocket.
+type CommentService struct {
+	db *sql.DB
+	// groupWindow es NOTIFICATION_GROUP_WINDOW: plazo para agrupar los avisos de comentarios.
+	groupWindow time.Duration
+}
+
+// NewCommentService crea una nueva instancia de CommentService.
+func NewCommentService(db *sql.DB, cfg *config.Config) ICommentService {
+	return &CommentService{db: db, groupWindow: cfg.NotificationGroupWindow}
+}
+
+// validateCommentContent normaliza el contenido y comprueba su longitud.
+func validateCommentContent(content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", ErrEmptyComment
+	}
+	if utf8.RuneCountInString(content) > commentMaxLength {
+		return "", ErrCommentTooLong
+	}
+	return content, nil
+}
+
+// CreateComment publica un comentario en la publicación. Una respuesta a otra respuesta se
+// cuelga del comentario raíz para mantener un solo nivel de anidación. Notifica al dueño
+// de la publicación y, si es una respuesta, al autor del comentario respondido.
+func (s *CommentService) CreateComment(eventID, authorID int64, req models.CreateCommentRequest) (*models.Comment, error) {
+	content, err := validateCommentContent(req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerID, postTitle, err := queries.GetCommunityEventOwner(eventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPostNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la publicación %d: %w", eventID, err)
+	}
+
+	var parentID *int64
+	var repliedTo *models.Comment
+	if req.ParentId != nil {
+		repliedTo, err = queries.GetCommentByID(*req.ParentId)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && repliedTo.IsDeleted) {
+			return nil, ErrCommentNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error al obtener el comentario %d: %w", *req.ParentId, err)
+		}
+		if repliedTo.CommunityEventId != eventID {
+			return nil, ErrCommentWrongPost
+		}
+		rootID := repliedTo.Id
+		if repliedTo.ParentId != nil {
+			rootID = *repliedTo.ParentId
+		}
+		parentID = &rootID
+	}
+
+	commentID, err := queries.InsertComment(eventID, authorID, parentID, content)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := queries.GetCommentByID(commentID)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el comentario %d recién creado: %w", commentID, err)
+	}
+
+	s.notifyComment(comment, ownerID, postTitle, repliedTo)
+	return comment, nil
+}
+
+// notifyComment crea las notificaciones de un comentario nuevo. Nadie recibe avisos de sus
+// propios comentarios ni dos avisos por el mismo comentario. Los comentarios de la misma
+// publicación, o las respuestas al mismo comentario, se agrupan en una sola notificación.
+// Un fallo al notificar no invalida el comentario ya guardado.
+func (s *CommentService) notifyComment(comment *models.Comment, ownerID int64, postTitle string, repliedTo *models.Comment) {
+	authorName := commentAuthorName(comment.Author)
+	metadataJSON, err := json.Marshal(models.EventMetadata{
+		CommunityEventId: comment.CommunityEventId,
+		CommentId:        comment.Id,
+	})
+	if err != nil {
+		logger.Errorf(commentServiceComponent, "Error al serializar los metadatos de la notificación: %v", err)
+	}
+
+	description := previewText(comment.Content, 140)
+	// notify agrupa por subjectID. title recibe el nombre del autor, o "Ana y N personas más"
+	// con many a true si el grupo ya tiene más de un comentario.
+	notify := func(userID int64, eventType string, subjectID int64, title func(actors string, many bool) string) {
+		notification := models.Event{
+			EventType:   eventType,
+			UserId:      userID,
+			OtherUserId: sql.NullInt64{Int64: comment.Author.Id, Valid: true},
+			Metadata:    metadataJSON,
+			GroupKey:    sql.NullString{String: models.EventGroupKey(eventType, subjectID), Valid: true},
+		}
+		err := queries.CreateGroupedEventWithOutbox(&notification, s.groupWindow, func(group models.EventGroup) (string, string) {
+			return title(groupedActorsText(authorName, group.Count), group.Count > 1), description
+		})
+		if err != nil {
+			logger.Errorf(commentServiceComponent, "No se pudo notificar el comentario %d al usuario %d: %v", comment.Id, userID, err)
+		}
+	}
+
+	notified := map[int64]bool{comment.Author.Id: true}
+	if repliedTo != nil && !notified[repliedTo.Author.Id] {
+		notified[repliedTo.Author.Id] = true
+		notify(repliedTo.Author.Id, EventTypeCommentReply, repliedTo.Id, func(actors string, many bool) string {
+			if many {
+				return actors + " respondieron a tu comentario"
+			}
+			return actors + " respondió a tu comentario"
+		})
+	}
+	if !notified[ownerID] {
+		notify(ownerID, EventTypeCommentCreated, comment.CommunityEventId, func(actors string, many bool) string {
+			if many {
+				return fmt.Sprintf("%s comentaron en \"%s\"", actors, postTitle)
+			}
+			return fmt.Sprintf("%s comentó en \"%s\"", actors, postTitle)
+		})
+	}
+}
+
+// commentAuthorName devuelve el nombre a mostrar del autor de un comentario.
+func commentAuthorName(author models.CommentAuthor) string {
+	if author.CompanyName != "" {
+		return author.CompanyName
+	}
+	if name := strings.TrimSpace(author.FirstName + " " + author.LastName); name != "" {
+		return name
+	}
+	return "Un usuario"
+}
+
+// previewText recorta text a max caracteres para usarlo en una notificación.
+func previewText(text string, max int) string {
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	return string([]rune(text)[:max]) + "…"
+}
+
+// ListComments devuelve una página de comentarios raíz de la publicación, del más reciente
+// al más antiguo, con sus primeras respuestas.
+func (s *CommentService) ListComments(eventID int64, page, pageSize int) (*models.PaginatedComments, error) {
+	if _, _, err := queries.GetCommunityEventOwner(eventID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
+		return nil, fmt.Errorf("error al obtener la publicación %d: %w", eventID, err)
+	}
+
+	comments, total, err := queries.GetRootComments(eventID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	rootIDs := make([]int64, 0, len(comments))
+	for _, comment := range comments {
+		if comment.ReplyCount > 0 {
+			rootIDs = append(rootIDs, comment.Id)
+		}
+	}
+	previews, err := queries.GetReplyPreviews(rootIDs, commentReplyPreview)
+	if err != nil {
+		return nil, err
+	}
+	for i := range comments {
+		comments[i].Replies = previews[comments[i].Id]
+	}
+
+	return paginatedComments(comments, total, page, pageSize), nil
+}
+
+// ListReplies devuelve una página de respuestas de un comentario raíz en orden cronológico.
+func (s *CommentService) ListReplies(commentID int64, page, pageSize int) (*models.PaginatedComments, error) {
+	comment, err := queries.GetCommentByID(commentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el comentario %d: %w", commentID, err)
+	}
+	if comment.ParentId != nil {
+		// Las respuestas no tienen respuestas propias.
+		return paginatedComments([]models.Comment{}, 0, page, pageSize), nil
+	}
+
+	replies, total, err := queries.GetCommentReplies(commentID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return paginatedComments(replies, total, page, pageSize), nil
+}
+
+func paginatedComments(comments []models.Comment, total, page, pageSize int) *models.PaginatedComments {
+	totalPages := 0
+	if total > 0 {
+		totalPages = (total + pageSize - 1) / pageSize
+	}
+	return &models.PaginatedComments{
+		Data: comments,
+		Pagination: models.PaginationDetails{
+			TotalItems:  total,
+			TotalPages:  totalPages,
+			CurrentPage: page,
+			PageSize:    pageSize,
+		},
+	}
+}
+
+// UpdateComment cambia el texto de un comentario. Solo puede hacerlo su autor.
+func (s *CommentService) UpdateComment(commentID, userID int64, req models.UpdateCommentRequest) (*models.Comment, error) {
+	content, err := validateCommentContent(req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	authorID, deleted, err := queries.GetCommentAuthorID(commentID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && deleted) {
+		return nil, ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el comentario %d: %w", commentID, err)
+	}
+	if authorID != userID {
+		return nil, ErrCommentEditForbidden
+	}
+
+	if err := queries.UpdateCommentContent(commentID, content); err != nil {
+		return nil, err
+	}
+	return queries.GetCommentByID(commentID)
+}
+
+// DeleteComment elimina un comentario. Pueden hacerlo su autor, el dueño de la publicación
+// y los administradores.
+func (s *CommentService) DeleteComment(commentID, userID int64, roleID models.UserRole) error {
+	comment, err := queries.GetCommentByID(commentID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && comment.IsDeleted) {
+		return ErrCommentNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error al obtener el comentario %d: %w", commentID, err)
+	}
+
+	if comment.Author.Id != userID && roleID != models.RoleAdmin {
+		ownerID, _, err := queries.GetCommunityEventOwner(comment.CommunityEventId)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCommentDelForbidden
+		}
+		if err != nil {
+			return fmt.Errorf("error al obtener la publicación %d: %w", comment.CommunityEventId, err)
+		}
+		if ownerID != userID {
+			return ErrCommentDelForbidden
+		}
+	}
+
+	if err := queries.DeleteComment(commentID); err != nil {
+		return err
+	}
+	logger.Infof(commentServiceComponent, "Comentario %d eliminado por el usuario %d", commentID, userID)
+	return nil
+}
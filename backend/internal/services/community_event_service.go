@@ -3,10 +3,12 @@ package services
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/phonetic"
 )
 
@@ -49,3 +51,15 @@ func (s *CommunityEventService) GetMyCommunityEvents(userID int64, page, pageSiz
 	// Usamos la función de queries paginada
 	return queries.GetMyCommunityEvents(s.db, userID, page, pageSize)
 }
+
+// GetMyCommunityEventsPage recupera una página por cursor de los eventos del usuario, del más
+// reciente al más antiguo.
+func (s *CommunityEventService) GetMyCommunityEventsPage(userID int64, page pagination.Params) (pagination.Page[models.CommunityEvent], error) {
+	events, err := queries.GetMyCommunityEventsPage(s.db, userID, page)
+	if err != nil {
+		return pagination.Page[models.CommunityEvent]{}, err
+	}
+	return pagination.NewPage(events, page.Limit, func(e models.CommunityEvent) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: e.CreatedAt, ID: strconv.FormatInt(e.Id, 10)}
+	}), nil
+}
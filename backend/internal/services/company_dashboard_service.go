@@ -0,0 +1,236 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const companyDashboardServiceComponent = "COMPANY_DASHBOARD_SERVICE"
+
+const (
+	// companyDashboardCacheTTL es el tiempo que se reutiliza un dashboard ya calculado.
+	companyDashboardCacheTTL = 5 * time.Minute
+	// companyDashboardMaxDays es el periodo máximo que se puede consultar.
+	companyDashboardMaxDays = 365
+	// companyDashboardTopSkills es el número de habilidades devueltas.
+	companyDashboardTopSkills = 10
+)
+
+// ErrInvalidDashboardRange se devuelve si el periodo solicitado está fuera de rango.
+var ErrInvalidDashboardRange = apperrors.New(apperrors.InvalidParam, fmt.Sprintf("el parámetro 'days' debe estar entre 1 y %d", companyDashboardMaxDays))
+
+// hiringFunnelStages es el orden de las etapas del proceso de selección (ENUM JobApplication.Status).
+// RECHAZADA y RETIRADA no forman parte del embudo.
+var hiringFunnelStages = []string{"ENVIADA", "EN_REVISION", "ENTREVISTA", "PRUEBA_TECNICA", "OFERTA_REALIZADA", "APROBADA"}
+
+// ICompanyDashboardService define la interfaz del dashboard de contratación de empresas.
+type ICompanyDashboardService interface {
+	GetDashboard(companyID int64, days int) (*models.CompanyDashboard, error)
+}
+
+type companyDashboardCacheEntry struct {
+	dashboard *models.CompanyDashboard
+	expiresAt time.Time
+}
+
+// CompanyDashboardService calcula las métricas agregadas de contratación de una empresa.
+// Los resultados se guardan en memoria durante companyDashboardCacheTTL.
+type CompanyDashboardService struct {
+	db *sql.DB
+
+	cacheMu sync.Mutex
+	cache   map[string]companyDashboardCacheEntry
+}
+
+// NewCompanyDashboardService crea una nueva instancia de CompanyDashboardService.
+func NewCompanyDashboardService(db *sql.DB) ICompanyDashboardService {
+	return &CompanyDashboardService{
+		db:    db,
+		cache: make(map[string]companyDashboardCacheEntry),
+	}
+}
+
+// GetDashboard devuelve las métricas de los últimos days días para la empresa.
+func (s *CompanyDashboardService) GetDashboard(companyID int64, days int) (*models.CompanyDashboard, error) {
+	if days < 1 || days > companyDashboardMaxDays {
+		return nil, ErrInvalidDashboardRange
+	}
+
+	key := fmt.Sprintf("%d:%d", companyID, days)
+	now := time.Now()
+	s.cacheMu.Lock()
+	if entry, ok := s.cache[key]; ok && now.Before(entry.expiresAt) {
+		s.cacheMu.Unlock()
+		return entry.dashboard, nil
+	}
+	s.cacheMu.Unlock()
+
+	dashboard, err := s.buildDashboard(companyID, days, now)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cacheMu.Lock()
+	// Limpiar las entradas vencidas para que la caché no crezca sin límite
+	for k, entry := range s.cache {
+		if now.After(entry.expiresAt) {
+			delete(s.cache, k)
+		}
+	}
+	s.cache[key] = companyDashboardCacheEntry{dashboard: dashboard, expiresAt: now.Add(companyDashboardCacheTTL)}
+	s.cacheMu.Unlock()
+
+	return dashboard, nil
+}
+
+func (s *CompanyDashboardService) buildDashboard(companyID int64, days int, now time.Time) (*models.CompanyDashboard, error) {
+	from := now.AddDate(0, 0, -days).Truncate(24 * time.Hour)
+	dashboard := &models.CompanyDashboard{
+		CompanyId:   companyID,
+		Days:        days,
+		From:        from,
+		GeneratedAt: now.UTC(),
+	}
+
+	var err error
+	if dashboard.Postings, err = s.applicationsPerPosting(companyID, from); err != nil {
+		return nil, err
+	}
+	if err = s.fillFunnel(dashboard, companyID, from); err != nil {
+		return nil, err
+	}
+
+	var avgSeconds sql.NullFloat64
+	if err = s.db.QueryRow(queries.CompanyAverageTimeToHire, companyID, from).Scan(&avgSeconds); err != nil {
+		logger.Errorf(companyDashboardServiceComponent, "Error al calcular el tiempo de contratación de la empresa %d: %v", companyID, err)
+		return nil, fmt.Errorf("error al calcular el tiempo de contratación: %w", err)
+	}
+	if avgSeconds.Valid {
+		timeToHire := avgSeconds.Float64 / (24 * 60 * 60)
+		dashboard.TimeToHire = &timeToHire
+	}
+
+	if dashboard.TopSkills, err = s.topApplicantSkills(companyID, from); err != nil {
+		return nil, err
+	}
+	return dashboard, nil
+}
+
+// applicationsPerPosting agrupa por publicación la serie diaria de postulaciones.
+func (s *CompanyDashboardService) applicationsPerPosting(companyID int64, from time.Time) ([]models.PostingApplications, error) {
+	rows, err := s.db.Query(queries.CompanyApplicationsPerPostingByDay, companyID, from)
+	if err != nil {
+		logger.Errorf(companyDashboardServiceComponent, "Error al consultar las postulaciones por publicación de la empresa %d: %v", companyID, err)
+		return nil, fmt.Errorf("error al consultar las postulaciones: %w", err)
+	}
+	defer rows.Close()
+
+	postings := []models.PostingApplications{}
+	for rows.Next() {
+		var (
+			eventID  int64
+			title    string
+			postType string
+			day      time.Time
+			count    int64
+		)
+		if err := rows.Scan(&eventID, &title, &postType, &day, &count); err != nil {
+			return nil, fmt.Errorf("error al leer las postulaciones: %w", err)
+		}
+		// Las filas vienen ordenadas por publicación
+		if n := len(postings); n == 0 || postings[n-1].EventId != eventID {
+			postings = append(postings, models.PostingApplications{EventId: eventID, Title: title, PostType: postType, Series: []models.DailyCount{}})
+		}
+		posting := &postings[len(postings)-1]
+		posting.Total += count
+		posting.Series = append(posting.Series, models.DailyCount{Date: day.Format(time.DateOnly), Count: count})
+	}
+	return postings, rows.Err()
+}
+
+// fillFunnel calcula el embudo a partir del estado actual de cada postulación: una
+// postulación en ENTREVISTA ha pasado también por ENVIADA y EN_REVISION. Las rechazadas y
+// retiradas solo cuentan en la primera etapa, porque no se guarda en qué etapa salieron.
+func (s *CompanyDashboardService) fillFunnel(dashboard *models.CompanyDashboard, companyID int64, from time.Time) error {
+	rows, err := s.db.Query(queries.CompanyApplicationStatusCounts, companyID, from)
+	if err != nil {
+		logger.Errorf(companyDashboardServiceComponent, "Error al consultar los estados de postulación de la empresa %d: %v", companyID, err)
+		return fmt.Errorf("error al consultar los estados de postulación: %w", err)
+	}
+	defer rows.Close()
+
+	byStatus := make(map[string]int64)
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return fmt.Errorf("error al leer los estados de postulación: %w", err)
+		}
+		byStatus[status] = count
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error al leer los estados de postulación: %w", err)
+	}
+
+	dashboard.Rejected = byStatus["RECHAZADA"]
+	dashboard.Withdrawn = byStatus["RETIRADA"]
+	dashboard.Hired = byStatus["APROBADA"]
+
+	reached := make([]int64, len(hiringFunnelStages))
+	for i := len(hiringFunnelStages) - 1; i >= 0; i-- {
+		reached[i] = byStatus[hiringFunnelStages[i]]
+		if i < len(hiringFunnelStages)-1 {
+			reached[i] += reached[i+1]
+		}
+	}
+	reached[0] += dashboard.Rejected + dashboard.Withdrawn
+
+	dashboard.Funnel = make([]models.FunnelStage, len(hiringFunnelStages))
+	for i, stage := range hiringFunnelStages {
+		dashboard.Funnel[i] = models.FunnelStage{
+			Stage:               stage,
+			Count:               reached[i],
+			ConversionFromStart: conversionRate(reached[i], reached[0]),
+		}
+		if i == 0 {
+			dashboard.Funnel[i].ConversionFromPrevious = conversionRate(reached[0], reached[0])
+		} else {
+			dashboard.Funnel[i].ConversionFromPrevious = conversionRate(reached[i], reached[i-1])
+		}
+	}
+	return nil
+}
+
+func (s *CompanyDashboardService) topApplicantSkills(companyID int64, from time.Time) ([]models.SkillCount, error) {
+	rows, err := s.db.Query(queries.CompanyTopApplicantSkills, companyID, from, companyDashboardTopSkills)
+	if err != nil {
+		logger.Errorf(companyDashboardServiceComponent, "Error al consultar las habilidades de los postulantes de la empresa %d: %v", companyID, err)
+		return nil, fmt.Errorf("error al consultar las habilidades: %w", err)
+	}
+	defer rows.Close()
+
+	skills := []models.SkillCount{}
+	for rows.Next() {
+		var skill models.SkillCount
+		if err := rows.Scan(&skill.Skill, &skill.Applicants); err != nil {
+			return nil, fmt.Errorf("error al leer las habilidades: %w", err)
+		}
+		skills = append(skills, skill)
+	}
+	return skills, rows.Err()
+}
+
+// conversionRate devuelve part/total, o 0 si total es 0.
+func conversionRate(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
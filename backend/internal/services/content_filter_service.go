@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/contentfilter"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/go-sql-driver/mysql"
+)
+
+const contentFilterServiceComponent = "CONTENT_FILTER_SERVICE"
+
+// contentFilterImportMax es el máximo de palabras por importación.
+const contentFilterImportMax = 5000
+
+// Errores de negocio del servicio del filtro de contenido.
+var (
+	ErrFilterRuleNotFound  = apperrors.New(apperrors.FilterRuleNotFound, "regla del filtro no encontrada")
+	ErrFilterRuleDuplicate = apperrors.New(apperrors.FilterRuleDuplicate, "ya existe una regla con ese patrón para el idioma")
+	ErrFilterImportEmpty   = apperrors.New(apperrors.FilterRuleInvalid, "la lista de palabras está vacía o supera el máximo permitido")
+)
+
+// filterCategories son las categorías válidas de una regla.
+var filterCategories = map[string]bool{
+	models.FilterCategoryProfanity:  true,
+	models.FilterCategorySpam:       true,
+	models.FilterCategoryHarassment: true,
+	models.FilterCategoryOther:      true,
+}
+
+// IContentFilterService define la interfaz del servicio de administración del filtro.
+type IContentFilterService interface {
+	ListRules(language, matchType string, page, pageSize int) (*models.PaginatedContentFilterRules, error)
+	CreateRule(adminID int64, req models.ContentFilterRuleRequest) (*models.ContentFilterRule, error)
+	UpdateRule(ruleID int64, req models.ContentFilterRuleRequest) (*models.ContentFilterRule, error)
+	DeleteRule(ruleID int64) error
+	ImportWords(adminID int64, req models.ContentFilterImportRequest) (*models.ContentFilterImportResult, error)
+	GetPolicy() (*models.ContentFilterPolicy, error)
+	UpdatePolicy(adminID int64, policy models.ContentFilterPolicy) (*models.ContentFilterPolicy, error)
+	ListHits(action string, senderID int64, page, pageSize int) (*models.PaginatedContentFilterHits, error)
+	Test(ctx context.Context, text string) (*contentfilter.Verdict, error)
+}
+
+// ContentFilterService administra las reglas y la política del filtro de contenido del
+// chat. El filtro se aplica en el servidor WebSocket, que recarga los cambios cada
+// CONTENT_FILTER_RELOAD_INTERVAL.
+type ContentFilterService struct {
+	db        *sql.DB
+	languages []string
+	checker   contentfilter.Checker
+}
+
+// NewContentFilterService crea una nueva instancia de ContentFilterService.
+func NewContentFilterService(db *sql.DB, cfg *config.Config) IContentFilterService {
+	service := &ContentFilterService{db: db, languages: contentfilter.ParseLanguages(cfg.ContentFilterLanguages)}
+	if cfg.ContentFilterAPIURL != "" {
+		service.checker = contentfilter.NewHTTPChecker(cfg.ContentFilterAPIURL, cfg.ContentFilterAPIKey, cfg.ContentFilterAPITimeout)
+	}
+	return service
+}
+
+// ruleInvalid envuelve un error de validación del paquete contentfilter.
+func ruleInvalid(err error) error {
+	return apperrors.New(apperrors.FilterRuleInvalid, err.Error())
+}
+
+// normalizeRule aplica los valores por defecto de la petición y valida la regla resultante.
+func normalizeRule(req models.ContentFilterRuleRequest) (models.ContentFilterRule, error) {
+	rule := models.ContentFilterRule{
+		Language:  strings.ToLower(strings.TrimSpace(req.Language)),
+		MatchType: strings.ToUpper(strings.TrimSpace(req.MatchType)),
+		Pattern:   strings.TrimSpace(req.Pattern),
+		Category:  strings.ToUpper(strings.TrimSpace(req.Category)),
+		Severity:  strings.ToUpper(strings.TrimSpace(req.Severity)),
+		IsActive:  req.IsActive == nil || *req.IsActive,
+	}
+	if rule.Language == "" {
+		rule.Language = contentfilter.AllLanguages
+	}
+	if rule.MatchType == "" {
+		rule.MatchType = contentfilter.MatchWord
+	}
+	if rule.Category == "" {
+		rule.Category = models.FilterCategoryProfanity
+	}
+	if rule.Severity == "" {
+		rule.Severity = contentfilter.SeverityMedium
+	}
+
+	if len(rule.Language) > 8 {
+		return rule, apperrors.New(apperrors.FilterRuleInvalid, "código de idioma no válido")
+	}
+	if len(rule.Pattern) > 255 {
+		return rule, apperrors.New(apperrors.FilterRuleInvalid, "el patrón no puede superar los 255 caracteres")
+	}
+	if !filterCategories[rule.Category] {
+		return rule, apperrors.New(apperrors.FilterRuleInvalid, "categoría no válida (PROFANIDAD, SPAM, ACOSO u OTRO)")
+	}
+	err := contentfilter.ValidateRule(contentfilter.Rule{
+		Language:  rule.Language,
+		MatchType: rule.MatchType,
+		Pattern:   rule.Pattern,
+		Severity:  rule.Severity,
+	})
+	if err != nil {
+		return rule, ruleInvalid(err)
+	}
+	return rule, nil
+}
+
+// ListRules devuelve una página de reglas. language y matchType vacíos no filtran.
+func (s *ContentFilterService) ListRules(language, matchType string, page, pageSize int) (*models.PaginatedContentFilterRules, error) {
+	rules, total, err := queries.ListContentFilterRules(strings.ToLower(language), strings.ToUpper(matchType), pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PaginatedContentFilterRules{
+		Data:       rules,
+		Pagination: filterPagination(total, page, pageSize),
+	}, nil
+}
+
+// CreateRule añade una regla al filtro.
+func (s *ContentFilterService) CreateRule(adminID int64, req models.ContentFilterRuleRequest) (*models.ContentFilterRule, error) {
+	rule, err := normalizeRule(req)
+	if err != nil {
+		return nil, err
+	}
+	ruleID, err := queries.InsertContentFilterRule(rule, adminID)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return nil, ErrFilterRuleDuplicate
+		}
+		return nil, err
+	}
+	logger.Infof(contentFilterServiceComponent, "Regla %d del filtro creada por %d: %s %s (%s)", ruleID, adminID, rule.MatchType, rule.Language, rule.Severity)
+	return queries.GetContentFilterRule(ruleID)
+}
+
+// UpdateRule reemplaza una regla con los valores de la petición.
+func (s *ContentFilterService) UpdateRule(ruleID int64, req models.ContentFilterRuleRequest) (*models.ContentFilterRule, error) {
+	if _, err := queries.GetContentFilterRule(ruleID); errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFilterRuleNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	rule, err := normalizeRule(req)
+	if err != nil {
+		return nil, err
+	}
+	rule.Id = ruleID
+	if err := queries.UpdateContentFilterRule(rule); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return nil, ErrFilterRuleDuplicate
+		}
+		return nil, err
+	}
+	return queries.GetContentFilterRule(ruleID)
+}
+
+// DeleteRule elimina una regla.
+func (s *ContentFilterService) DeleteRule(ruleID int64) error {
+	err := queries.DeleteContentFilterRule(ruleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrFilterRuleNotFound
+	}
+	return err
+}
+
+// ImportWords crea una regla PALABRA por cada palabra de la lista. Las palabras vacías o
+// repetidas en la lista se descartan y las que ya existen se cuentan como omitidas.
+func (s *ContentFilterService) ImportWords(adminID int64, req models.ContentFilterImportRequest) (*models.ContentFilterImportResult, error) {
+	seen := make(map[string]bool, len(req.Words))
+	var words []string
+	for _, word := range req.Words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" && !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 || len(words) > contentFilterImportMax {
+		return nil, ErrFilterImportEmpty
+	}
+
+	// Se valida la primera palabra con los valores comunes de la petición y después cada
+	// palabra por separado.
+	base, err := normalizeRule(models.ContentFilterRuleRequest{
+		Language: req.Language, MatchType: contentfilter.MatchWord, Pattern: words[0],
+		Category: req.Category, Severity: req.Severity,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, word := range words {
+		err := contentfilter.ValidateRule(contentfilter.Rule{MatchType: contentfilter.MatchWord, Pattern: word, Severity: base.Severity})
+		if err != nil || len(word) > 255 {
+			return nil, apperrors.New(apperrors.FilterRuleInvalid, "palabra no válida: "+word)
+		}
+	}
+
+	imported, err := queries.ImportContentFilterWords(models.ContentFilterImportRequest{
+		Language: base.Language, Category: base.Category, Severity: base.Severity, Words: words,
+	}, adminID)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof(contentFilterServiceComponent, "%d palabras importadas al filtro (%s) por %d", imported, base.Language, adminID)
+	return &models.ContentFilterImportResult{Imported: imported, Skipped: len(words) - imported}, nil
+}
+
+// GetPolicy devuelve la política vigente del filtro.
+func (s *ContentFilterService) GetPolicy() (*models.ContentFilterPolicy, error) {
+	policy, err := queries.GetContentFilterPolicy()
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// UpdatePolicy valida y guarda la política del filtro.
+func (s *ContentFilterService) UpdatePolicy(adminID int64, policy models.ContentFilterPolicy) (*models.ContentFilterPolicy, error) {
+	policy.LowAction = strings.ToUpper(strings.TrimSpace(policy.LowAction))
+	policy.MediumAction = strings.ToUpper(strings.TrimSpace(policy.MediumAction))
+	policy.HighAction = strings.ToUpper(strings.TrimSpace(policy.HighAction))
+	policy.SpamSeverity = strings.ToUpper(strings.TrimSpace(policy.SpamSeverity))
+	if err := contentfilter.ValidatePolicy(queries.FilterPolicy(policy)); err != nil {
+		return nil, apperrors.New(apperrors.FilterPolicyInvalid, err.Error())
+	}
+
+	if err := queries.SaveContentFilterPolicy(policy, adminID); err != nil {
+		return nil, err
+	}
+	logger.Infof(contentFilterServiceComponent, "Política del filtro actualizada por %d", adminID)
+	return s.GetPolicy()
+}
+
+// ListHits devuelve una página del registro del filtro. action vacía y senderID 0 no filtran.
+func (s *ContentFilterService) ListHits(action string, senderID int64, page, pageSize int) (*models.PaginatedContentFilterHits, error) {
+	action = strings.ToUpper(action)
+	switch action {
+	case "", contentfilter.ActionFlag, contentfilter.ActionShadowDelete, contentfilter.ActionBlock:
+	default:
+		return nil, apperrors.New(apperrors.InvalidParam, "acción no válida (MARCAR, BORRADO_SILENCIOSO o BLOQUEAR)")
+	}
+
+	hits, total, err := queries.ListContentFilterHits(action, senderID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PaginatedContentFilterHits{
+		Data:       hits,
+		Pagination: filterPagination(total, page, pageSize),
+	}, nil
+}
+
+// Test evalúa un texto con las reglas y la política guardadas, sin registrarlo. Sirve para
+// comprobar una regla antes de que la recargue el servidor WebSocket.
+func (s *ContentFilterService) Test(ctx context.Context, text string) (*contentfilter.Verdict, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, apperrors.New(apperrors.MissingFields, "el texto es obligatorio")
+	}
+	policy, err := queries.GetContentFilterPolicy()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := queries.GetActiveContentFilterRules()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := contentfilter.New(s.languages, s.checker)
+	if err := filter.Load(rules, queries.FilterPolicy(policy)); err != nil {
+		// Las reglas inválidas se omiten; el resto queda cargado y se puede probar.
+		logger.Warnf(contentFilterServiceComponent, "Reglas del filtro omitidas en la prueba: %v", err)
+	}
+	verdict := filter.Test(ctx, text)
+	return &verdict, nil
+}
+
+func filterPagination(total, page, pageSize int) models.PaginationDetails {
+	totalPages := 0
+	if total > 0 {
+		totalPages = (total + pageSize - 1) / pageSize
+	}
+	return models.PaginationDetails{
+		TotalItems:  total,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+		PageSize:    pageSize,
+	}
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pdfgen"
+)
+
+const cvExportServiceComponent = "CV_EXPORT_SERVICE"
+
+// cvDateLayout es el formato de fecha usado en la exportación del CV.
+const cvDateLayout = "2006-01-02"
+
+// cvPDFTemplate es la plantilla del CV en el marcado de pkg/pdfgen.
+// Los campos de texto libre pasan por "para" para que no se interpreten como encabezados.
+const cvPDFTemplate = `# {{para (fullName .Profile)}}
+{{- with .Profile}}
+{{if .Degree}}{{para .Degree}}
+{{end}}{{if .ContactEmail}}{{para .ContactEmail}}{{else if .Email}}{{para .Email}}{{end}}{{if .Phone}} | {{para .Phone}}{{end}}
+{{if .Address}}{{para .Address}}
+{{end}}{{if .Linkedin}}LinkedIn: {{para .Linkedin}}
+{{end}}{{if .Github}}GitHub: {{para .Github}}
+{{end}}{{if .Summary}}
+## Resumen
+{{para .Summary}}
+{{end}}{{end}}
+{{- if .WorkExperience}}
+## Experiencia laboral
+{{range .WorkExperience}}### {{para .Position}} - {{para .Company}}
+{{period .StartDate .EndDate .IsCurrentJob}}{{if .Country}} | {{para .Country}}{{end}}
+{{if .Description}}{{para .Description}}
+{{end}}
+{{end}}{{end}}
+{{- if .Education}}
+## Educación
+{{range .Education}}### {{para .Degree}} - {{para .Institution}}
+{{if .IsCurrentlyStudying}}En curso{{else if .GraduationDate}}Graduación: {{.GraduationDate}}{{end}}{{if .Campus}} | {{para .Campus}}{{end}}{{if .Country}} | {{para .Country}}{{end}}
+
+{{end}}{{end}}
+{{- if .Projects}}
+## Proyectos
+{{range .Projects}}### {{para .Title}}{{if .Role}} ({{para .Role}}){{end}}
+{{period .StartDate .EndDate .IsOngoing}}{{if .Company}} | {{para .Company}}{{end}}{{if .Status}} | {{para .Status}}{{end}}
+{{if .Description}}{{para .Description}}
+{{end}}
+{{end}}{{end}}
+{{- if .Certifications}}
+## Certificaciones
+{{range .Certifications}}{{para .Certification}} - {{para .Institution}}{{if .DateObtained}} ({{.DateObtained}}){{end}}
+{{end}}{{end}}
+{{- if .Skills}}
+## Habilidades
+{{range .Skills}}{{para .Skill}}{{if .Level}}: {{para .Level}}{{end}}
+{{end}}{{end}}
+{{- if .Languages}}
+## Idiomas
+{{range .Languages}}{{para .Language}}{{if .Level}}: {{para .Level}}{{end}}
+{{end}}{{end}}`
+
+var cvTemplate = template.Must(template.New("cv").Funcs(template.FuncMap{
+	"para":     cvParagraph,
+	"fullName": cvFullName,
+	"period":   cvPeriod,
+}).Parse(cvPDFTemplate))
+
+// ICVExportService define la exportación del CompleteProfile de un usuario.
+type ICVExportService interface {
+	BuildCVExport(userID int64) (*models.CVExport, error)
+	RenderCVPDF(cv *models.CVExport) ([]byte, error)
+}
+
+// CVExportService reúne el perfil completo de un usuario y lo renderiza como JSON o PDF.
+type CVExportService struct {
+	db *sql.DB
+}
+
+// NewCVExportService crea una nueva instancia de CVExportService.
+func NewCVExportService(db *sql.DB) ICVExportService {
+	return &CVExportService{db: db}
+}
+
+// BuildCVExport arma el documento de exportación a partir de las tablas del CV.
+func (s *CVExportService) BuildCVExport(userID int64) (*models.CVExport, error) {
+	profile, err := queries.GetUserProfile(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("error obteniendo el perfil del usuario %d: %w", userID, err)
+	}
+
+	complete := models.CompleteProfile{User: *profile}
+	if complete.Education, err = queries.GetEducationItemsForUser(userID); err != nil {
+		return nil, err
+	}
+	if complete.WorkExperience, err = queries.GetWorkExperienceItemsForUser(userID); err != nil {
+		return nil, err
+	}
+	if complete.Certifications, err = queries.GetCertificationItemsForUser(userID); err != nil {
+		return nil, err
+	}
+	if complete.Skills, err = queries.GetSkillItemsForUser(userID); err != nil {
+		return nil, err
+	}
+	if complete.Languages, err = queries.GetLanguageItemsForUser(userID); err != nil {
+		return nil, err
+	}
+	if complete.Projects, err = queries.GetProjectItemsForUser(userID); err != nil {
+		return nil, err
+	}
+	if profile.DegreeId != nil {
+		degree, errDegree := queries.GetDegreeByID(*profile.DegreeId)
+		if errDegree != nil {
+			// La carrera es un dato accesorio; no debe impedir la exportación.
+			logger.Warnf(cvExportServiceComponent, "No se pudo obtener la carrera %d del usuario %d: %v", *profile.DegreeId, userID, errDegree)
+		} else {
+			complete.Degree = degree
+		}
+	}
+
+	return toCVExport(complete), nil
+}
+
+// RenderCVPDF aplica la plantilla del CV y genera el PDF.
+func (s *CVExportService) RenderCVPDF(cv *models.CVExport) ([]byte, error) {
+	var markup bytes.Buffer
+	if err := cvTemplate.Execute(&markup, cv); err != nil {
+		return nil, fmt.Errorf("error aplicando la plantilla del CV: %w", err)
+	}
+	return pdfgen.RenderMarkup(markup.String())
+}
+
+// toCVExport convierte el CompleteProfile (modelos de BD) al formato portable de exportación.
+func toCVExport(p models.CompleteProfile) *models.CVExport {
+	cv := &models.CVExport{
+		Version:     models.CVExportVersion,
+		GeneratedAt: time.Now().UTC(),
+		Profile: models.CVExportProfile{
+			FirstName:    p.User.FirstName,
+			LastName:     p.User.LastName,
+			UserName:     p.User.UserName,
+			Email:        p.User.Email,
+			ContactEmail: p.User.ContactEmail,
+			Phone:        p.User.Phone,
+			Address:      p.User.Address,
+			Summary:      p.User.Summary,
+			Github:       p.User.Github,
+			Linkedin:     p.User.Linkedin,
+		},
+		Education:      make([]models.CVExportEducation, 0, len(p.Education)),
+		WorkExperience: make([]models.CVExportWorkExperience, 0, len(p.WorkExperience)),
+		Certifications: make([]models.CVExportCertification, 0, len(p.Certifications)),
+		Skills:         make([]models.CVExportSkill, 0, len(p.Skills)),
+		Languages:      make([]models.CVExportLanguage, 0, len(p.Languages)),
+		Projects:       make([]models.CVExportProject, 0, len(p.Projects)),
+	}
+	if p.Degree != nil {
+		cv.Profile.Degree = p.Degree.DegreeName
+	}
+
+	for _, e := range p.Education {
+		cv.Education = append(cv.Education, models.CVExportEducation{
+			Institution:         e.Institution,
+			Degree:              e.Degree,
+			Campus:              e.Campus.String,
+			Country:             e.CountryName.String,
+			GraduationDate:      formatCVDate(e.GraduationDate),
+			IsCurrentlyStudying: e.IsCurrentlyStudying.Bool,
+		})
+	}
+	for _, w := range p.WorkExperience {
+		cv.WorkExperience = append(cv.WorkExperience, models.CVExportWorkExperience{
+			Company:      w.Company,
+			Position:     w.Position,
+			Country:      w.CountryName.String,
+			StartDate:    formatCVDate(w.StartDate),
+			EndDate:      formatCVDate(w.EndDate),
+			IsCurrentJob: w.IsCurrentJob.Bool,
+			Description:  w.Description.String,
+		})
+	}
+	for _, c := range p.Certifications {
+		cv.Certifications = append(cv.Certifications, models.CVExportCertification{
+			Certification: c.Certification,
+			Institution:   c.Institution,
+			DateObtained:  formatCVDate(c.DateObtained),
+		})
+	}
+	for _, sk := range p.Skills {
+		cv.Skills = append(cv.Skills, models.CVExportSkill{Skill: sk.Skill, Level: sk.Level})
+	}
+	for _, l := range p.Languages {
+		cv.Languages = append(cv.Languages, models.CVExportLanguage{Language: l.Language, Level: l.Level})
+	}
+	for _, pr := range p.Projects {
+		cv.Projects = append(cv.Projects, models.CVExportProject{
+			Title:       pr.Title,
+			Role:        pr.Role.String,
+			Company:     pr.Company.String,
+			Description: pr.Description.String,
+			Status:      pr.ProjectStatus.String,
+			StartDate:   formatCVDate(pr.StartDate),
+			EndDate:     formatCVDate(pr.ExpectedEndDate),
+			IsOngoing:   pr.IsOngoing.Bool,
+		})
+	}
+	return cv
+}
+
+func formatCVDate(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.Format(cvDateLayout)
+}
+
+// cvParagraph evita que el texto libre del usuario se interprete como marcado de encabezado.
+func cvParagraph(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			lines[i] = " " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func cvFullName(p models.CVExportProfile) string {
+	name := strings.TrimSpace(p.FirstName + " " + p.LastName)
+	if name == "" {
+		return p.UserName
+	}
+	return name
+}
+
+func cvPeriod(start, end string, current bool) string {
+	switch {
+	case start == "" && end == "":
+		if current {
+			return "Actualidad"
+		}
+		return ""
+	case current:
+		return start + " - Actualidad"
+	case end == "":
+		return start
+	default:
+		return start + " - " + end
+	}
+}
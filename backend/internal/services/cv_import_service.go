@@ -0,0 +1,322 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const cvImportServiceComponent = "CV_IMPORT_SERVICE"
+
+var (
+	ErrUnsupportedCVFormat = errors.New("formato de importación no soportado, use 'json' o 'linkedin'")
+	ErrInvalidCVPayload    = errors.New("el documento de CV es inválido")
+)
+
+// linkedInDateLayouts son los formatos de fecha que aparecen en la exportación de LinkedIn.
+var linkedInDateLayouts = []string{"Jan 2006", "January 2006", "2006-01-02", "2006"}
+
+// ICVImportService define la importación masiva de items de CV.
+type ICVImportService interface {
+	ImportCV(userID int64, format string, payload []byte, dryRun bool) (*models.CVImportResult, error)
+}
+
+// CVImportService importa Education, WorkExperience, Skills, Languages y Projects
+// desde un CV exportado o desde la exportación de datos de LinkedIn.
+type CVImportService struct {
+	db *sql.DB
+}
+
+// NewCVImportService crea una nueva instancia de CVImportService.
+func NewCVImportService(db *sql.DB) ICVImportService {
+	return &CVImportService{db: db}
+}
+
+// ImportCV interpreta el payload según el formato, descarta los items que ya existen en el CV
+// del usuario y crea el resto en una única transacción. Con dryRun no se escribe nada.
+func (s *CVImportService) ImportCV(userID int64, format string, payload []byte, dryRun bool) (*models.CVImportResult, error) {
+	var sections models.CVImportSections
+	switch format {
+	case models.CVImportFormatJSON:
+		var cv models.CVExport
+		if err := json.Unmarshal(payload, &cv); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCVPayload, err)
+		}
+		sections = models.CVImportSections{
+			Education:      cv.Education,
+			WorkExperience: cv.WorkExperience,
+			Skills:         cv.Skills,
+			Languages:      cv.Languages,
+			Projects:       cv.Projects,
+		}
+	case models.CVImportFormatLinkedIn:
+		var export models.LinkedInExport
+		if err := json.Unmarshal(payload, &export); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCVPayload, err)
+		}
+		sections = fromLinkedInExport(export)
+	default:
+		return nil, ErrUnsupportedCVFormat
+	}
+
+	if err := validateCVImport(sections); err != nil {
+		return nil, err
+	}
+
+	// Las habilidades se normalizan antes de descartar duplicados, para que "JS" no se importe
+	// si el CV ya tiene "JavaScript".
+	skills := NewSkillService(s.db)
+	for i := range sections.Skills {
+		sections.Skills[i].Skill, sections.Skills[i].SkillCatalogId = skills.CanonicalizeSkill(sections.Skills[i].Skill)
+	}
+
+	result, err := s.dedupeAgainstExisting(userID, sections)
+	if err != nil {
+		return nil, err
+	}
+	result.DryRun = dryRun
+	if dryRun {
+		return result, nil
+	}
+
+	if err := queries.ImportCVSections(userID, result.Created); err != nil {
+		logger.Errorf(cvImportServiceComponent, "Error importando CV para el usuario %d: %v", userID, err)
+		return nil, err
+	}
+	logger.Successf(cvImportServiceComponent, "CV importado para el usuario %d (%s): %d omitidos", userID, format, result.Skipped)
+	return result, nil
+}
+
+// dedupeAgainstExisting elimina los items que ya están en el CV del usuario o repetidos en el payload.
+func (s *CVImportService) dedupeAgainstExisting(userID int64, in models.CVImportSections) (*models.CVImportResult, error) {
+	result := &models.CVImportResult{Created: models.CVImportSections{
+		Education:      []models.CVExportEducation{},
+		WorkExperience: []models.CVExportWorkExperience{},
+		Skills:         []models.CVExportSkill{},
+		Languages:      []models.CVExportLanguage{},
+		Projects:       []models.CVExportProject{},
+	}}
+
+	existingEducation, err := queries.GetEducationItemsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, e := range existingEducation {
+		seen[cvKey(e.Institution, e.Degree)] = true
+	}
+	for _, e := range in.Education {
+		if key := cvKey(e.Institution, e.Degree); !seen[key] {
+			seen[key] = true
+			result.Created.Education = append(result.Created.Education, e)
+		} else {
+			result.Skipped++
+		}
+	}
+
+	existingWork, err := queries.GetWorkExperienceItemsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	seen = make(map[string]bool)
+	for _, w := range existingWork {
+		seen[cvKey(w.Company, w.Position, formatCVDate(w.StartDate))] = true
+	}
+	for _, w := range in.WorkExperience {
+		if key := cvKey(w.Company, w.Position, w.StartDate); !seen[key] {
+			seen[key] = true
+			result.Created.WorkExperience = append(result.Created.WorkExperience, w)
+		} else {
+			result.Skipped++
+		}
+	}
+
+	existingSkills, err := queries.GetSkillItemsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	seen = make(map[string]bool)
+	for _, sk := range existingSkills {
+		seen[cvKey(sk.Skill)] = true
+	}
+	for _, sk := range in.Skills {
+		if key := cvKey(sk.Skill); !seen[key] {
+			seen[key] = true
+			result.Created.Skills = append(result.Created.Skills, sk)
+		} else {
+			result.Skipped++
+		}
+	}
+
+	existingLanguages, err := queries.GetLanguageItemsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	seen = make(map[string]bool)
+	for _, l := range existingLanguages {
+		seen[cvKey(l.Language)] = true
+	}
+	for _, l := range in.Languages {
+		if key := cvKey(l.Language); !seen[key] {
+			seen[key] = true
+			result.Created.Languages = append(result.Created.Languages, l)
+		} else {
+			result.Skipped++
+		}
+	}
+
+	existingProjects, err := queries.GetProjectItemsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	seen = make(map[string]bool)
+	for _, p := range existingProjects {
+		seen[cvKey(p.Title)] = true
+	}
+	for _, p := range in.Projects {
+		if key := cvKey(p.Title); !seen[key] {
+			seen[key] = true
+			result.Created.Projects = append(result.Created.Projects, p)
+		} else {
+			result.Skipped++
+		}
+	}
+
+	return result, nil
+}
+
+// validateCVImport comprueba los campos obligatorios y el formato de las fechas.
+func validateCVImport(in models.CVImportSections) error {
+	for i, e := range in.Education {
+		if strings.TrimSpace(e.Institution) == "" {
+			return fmt.Errorf("%w: education[%d] requiere 'institution'", ErrInvalidCVPayload, i)
+		}
+		if err := validateCVDates(fmt.Sprintf("education[%d]", i), e.GraduationDate); err != nil {
+			return err
+		}
+	}
+	for i, w := range in.WorkExperience {
+		if strings.TrimSpace(w.Company) == "" || strings.TrimSpace(w.Position) == "" {
+			return fmt.Errorf("%w: workExperience[%d] requiere 'company' y 'position'", ErrInvalidCVPayload, i)
+		}
+		if err := validateCVDates(fmt.Sprintf("workExperience[%d]", i), w.StartDate, w.EndDate); err != nil {
+			return err
+		}
+	}
+	for i, sk := range in.Skills {
+		if strings.TrimSpace(sk.Skill) == "" {
+			return fmt.Errorf("%w: skills[%d] requiere 'skill'", ErrInvalidCVPayload, i)
+		}
+	}
+	for i, l := range in.Languages {
+		if strings.TrimSpace(l.Language) == "" {
+			return fmt.Errorf("%w: languages[%d] requiere 'language'", ErrInvalidCVPayload, i)
+		}
+	}
+	for i, p := range in.Projects {
+		if strings.TrimSpace(p.Title) == "" {
+			return fmt.Errorf("%w: projects[%d] requiere 'title'", ErrInvalidCVPayload, i)
+		}
+		if err := validateCVDates(fmt.Sprintf("projects[%d]", i), p.StartDate, p.EndDate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateCVDates(field string, dates ...string) error {
+	for _, d := range dates {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse(cvDateLayout, d); err != nil {
+			return fmt.Errorf("%w: %s tiene una fecha inválida '%s' (formato esperado YYYY-MM-DD)", ErrInvalidCVPayload, field, d)
+		}
+	}
+	return nil
+}
+
+// fromLinkedInExport convierte las filas de la exportación de LinkedIn al formato interno.
+func fromLinkedInExport(export models.LinkedInExport) models.CVImportSections {
+	var out models.CVImportSections
+	now := time.Now()
+
+	for _, p := range export.Positions {
+		out.WorkExperience = append(out.WorkExperience, models.CVExportWorkExperience{
+			Company:      strings.TrimSpace(p.CompanyName),
+			Position:     strings.TrimSpace(p.Title),
+			StartDate:    parseLinkedInDate(p.StartedOn),
+			EndDate:      parseLinkedInDate(p.FinishedOn),
+			IsCurrentJob: strings.TrimSpace(p.FinishedOn) == "",
+			Description:  strings.TrimSpace(p.Description),
+		})
+	}
+
+	for _, e := range export.Education {
+		graduation := parseLinkedInDate(e.EndDate)
+		studying := false
+		if t, err := time.Parse(cvDateLayout, graduation); err == nil {
+			studying = t.After(now)
+		}
+		out.Education = append(out.Education, models.CVExportEducation{
+			Institution:         strings.TrimSpace(e.SchoolName),
+			Degree:              strings.TrimSpace(e.DegreeName),
+			GraduationDate:      graduation,
+			IsCurrentlyStudying: studying,
+		})
+	}
+
+	for _, sk := range export.Skills {
+		out.Skills = append(out.Skills, models.CVExportSkill{Skill: strings.TrimSpace(sk.Name)})
+	}
+
+	for _, l := range export.Languages {
+		out.Languages = append(out.Languages, models.CVExportLanguage{
+			Language: strings.TrimSpace(l.Name),
+			Level:    strings.TrimSpace(l.Proficiency),
+		})
+	}
+
+	for _, p := range export.Projects {
+		description := strings.TrimSpace(p.Description)
+		if p.URL != "" {
+			description = strings.TrimSpace(description + "\n" + p.URL)
+		}
+		out.Projects = append(out.Projects, models.CVExportProject{
+			Title:       strings.TrimSpace(p.Title),
+			Description: description,
+			StartDate:   parseLinkedInDate(p.StartedOn),
+			EndDate:     parseLinkedInDate(p.FinishedOn),
+			IsOngoing:   strings.TrimSpace(p.StartedOn) != "" && strings.TrimSpace(p.FinishedOn) == "",
+		})
+	}
+
+	return out
+}
+
+// parseLinkedInDate convierte fechas como "Jan 2020" o "2020" a YYYY-MM-DD.
+// Devuelve una cadena vacía si la fecha no se reconoce.
+func parseLinkedInDate(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range linkedInDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(cvDateLayout)
+		}
+	}
+	return ""
+}
+
+// cvKey normaliza los campos que identifican un item del CV para detectar duplicados.
+func cvKey(parts ...string) string {
+	for i, p := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(p))
+	}
+	return strings.Join(parts, "|")
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const engagementServiceComponent = "ENGAGEMENT_SERVICE"
+
+// EventTypePostLiked es la notificación que recibe el autor de una publicación por los
+// "me gusta". Se agrupa por publicación (ver queries.CreateGroupedEventWithOutbox).
+const EventTypePostLiked = "POST_LIKED"
+
+// IEngagementService define la interfaz de "me gusta" y guardados de publicaciones.
+type IEngagementService interface {
+	SetLike(userID int64, roleID models.UserRole, eventID int64, liked bool) (*models.PostEngagement, error)
+	SetBookmark(userID, eventID int64, saved bool) (*models.PostEngagement, error)
+	ListSavedItems(userID int64, page, pageSize int) (*models.PaginatedSavedItems, error)
+}
+
+// EngagementService gestiona los "me gusta" y guardados de las publicaciones de la
+// comunidad, incluidas las ofertas de empleo.
+type EngagementService struct {
+	db *sql.DB
+	// groupWindow es NOTIFICATION_GROUP_WINDOW: plazo para agrupar los avisos de "me gusta".
+	groupWindow time.Duration
+}
+
+// NewEngagementService crea una nueva instancia de EngagementService.
+func NewEngagementService(db *sql.DB, cfg *config.Config) IEngagementService {
+	return &EngagementService{db: db, groupWindow: cfg.NotificationGroupWindow}
+}
+
+// SetLike marca o desmarca el "me gusta" del usuario y devuelve el estado resultante. Un
+// "me gusta" nuevo notifica al autor de la publicación (salvo que sea el propio autor).
+func (s *EngagementService) SetLike(userID int64, roleID models.UserRole, eventID int64, liked bool) (*models.PostEngagement, error) {
+	ownerID, postTitle, err := queries.GetCommunityEventOwner(eventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPostNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la publicación %d: %w", eventID, err)
+	}
+
+	changed, err := queries.SetPostLike(userID, eventID, liked)
+	if err != nil {
+		return nil, err
+	}
+	engagement, err := queries.GetPostEngagement(userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if changed && liked && ownerID != userID {
+		s.notifyLike(userID, roleID, ownerID, eventID, postTitle, engagement.LikeCount)
+	}
+	return &engagement, nil
+}
+
+// notifyLike avisa al autor de la publicación. Los "me gusta" de la misma publicación se
+// agrupan en una notificación con el último usuario y el total de los agrupados ("A Ana y 4
+// personas más les gustó..."). Un fallo al notificar no invalida el "me gusta".
+func (s *EngagementService) notifyLike(likerID int64, likerRole models.UserRole, ownerID, eventID int64, postTitle string, likeCount int64) {
+	likerName := userDisplayName(likerID, likerRole)
+	description := fmt.Sprintf("Tu publicación tiene %d me gusta.", likeCount)
+	if likeCount == 1 {
+		description = "Tu publicación tiene su primer me gusta."
+	}
+
+	metadataJSON, err := json.Marshal(models.EventMetadata{CommunityEventId: eventID, LikeCount: likeCount})
+	if err != nil {
+		logger.Errorf(engagementServiceComponent, "Error al serializar los metadatos de la notificación: %v", err)
+	}
+
+	notification := models.Event{
+		EventType:   EventTypePostLiked,
+		UserId:      ownerID,
+		OtherUserId: sql.NullInt64{Int64: likerID, Valid: true},
+		Metadata:    metadataJSON,
+		GroupKey:    sql.NullString{String: models.EventGroupKey(EventTypePostLiked, eventID), Valid: true},
+	}
+	err = queries.CreateGroupedEventWithOutbox(&notification, s.groupWindow, func(group models.EventGroup) (string, string) {
+		if group.Count > 1 {
+			return fmt.Sprintf("A %s les gustó \"%s\"", groupedActorsText(likerName, group.Count), postTitle), description
+		}
+		return fmt.Sprintf("A %s le gustó \"%s\"", likerName, postTitle), description
+	})
+	if err != nil {
+		logger.Errorf(engagementServiceComponent, "No se pudo notificar el me gusta de %d en la publicación %d: %v", likerID, eventID, err)
+	}
+}
+
+// userDisplayName devuelve el nombre a mostrar de un usuario en una notificación.
+func userDisplayName(userID int64, roleID models.UserRole) string {
+	if roleID == models.RoleBusiness {
+		if name, err := queries.GetCompanyNameByID(userID); err == nil {
+			return name
+		}
+		return "Una empresa"
+	}
+	firstName, lastName, err := queries.GetUserNameByID(userID)
+	if name := strings.TrimSpace(firstName + " " + lastName); err == nil && name != "" {
+		return name
+	}
+	return "Un usuario"
+}
+
+// SetBookmark guarda o quita la publicación de los guardados del usuario y devuelve el
+// estado resultante.
+func (s *EngagementService) SetBookmark(userID, eventID int64, saved bool) (*models.PostEngagement, error) {
+	if _, _, err := queries.GetCommunityEventOwner(eventID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
+		return nil, fmt.Errorf("error al obtener la publicación %d: %w", eventID, err)
+	}
+
+	if _, err := queries.SetPostBookmark(userID, eventID, saved); err != nil {
+		return nil, err
+	}
+	engagement, err := queries.GetPostEngagement(userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return &engagement, nil
+}
+
+// ListSavedItems devuelve una página de las publicaciones guardadas por el usuario.
+func (s *EngagementService) ListSavedItems(userID int64, page, pageSize int) (*models.PaginatedSavedItems, error) {
+	items, total, err := queries.GetSavedItems(userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := 0
+	if total > 0 {
+		totalPages = (total + pageSize - 1) / pageSize
+	}
+	return &models.PaginatedSavedItems{
+		Data: items,
+		Pagination: models.PaginationDetails{
+			TotalItems:  total,
+			TotalPages:  totalPages,
+			CurrentPage: page,
+			PageSize:    pageSize,
+		},
+	}, nil
+}
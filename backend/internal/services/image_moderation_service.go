@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/imagemoderation"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const imageModerationServiceComponent = "IMAGE_MODERATION_SERVICE"
+
+var (
+	ErrImageRejected       = apperrors.New(apperrors.ImageRejected, "la imagen infringe las normas de la comunidad")
+	ErrImageModerationDown = apperrors.New(apperrors.ImageModerationDown, "no se pudo revisar la imagen; inténtalo más tarde")
+)
+
+// ImageVerdict es el resultado de la moderación automática de una imagen aceptada. Review
+// indica que debe pasar por la cola de revisión una vez guardada.
+type ImageVerdict struct {
+	Review bool
+	Score  float64
+	Labels []string
+}
+
+// IImageModerationService define la moderación automática de las imágenes subidas.
+type IImageModerationService interface {
+	Check(ctx context.Context, userID int64, image []byte, contentType string) (*ImageVerdict, error)
+	QueueReview(userID int64, contentID, fileName string, verdict *ImageVerdict)
+}
+
+// ImageModerationService clasifica las imágenes subidas (fotos de perfil, imágenes de
+// publicaciones) con un modelo NSFW antes de guardarlas. Con un score igual o superior al
+// umbral de rechazo la subida se rechaza; entre el umbral de revisión y el de rechazo la imagen
+// se publica y entra en la cola de revisión de moderación (ModerationService).
+//
+// Sin clasificador configurado (IMAGE_MODERATION_DRIVER vacío) todas las imágenes se aceptan.
+type ImageModerationService struct {
+	classifier      imagemoderation.Classifier
+	rejectThreshold float64
+	reviewThreshold float64
+	failOpen        bool
+}
+
+// NewImageModerationService crea una nueva instancia de ImageModerationService. classifier
+// puede ser nil.
+func NewImageModerationService(cfg *config.Config, classifier imagemoderation.Classifier) IImageModerationService {
+	return &ImageModerationService{
+		classifier:      classifier,
+		rejectThreshold: cfg.ImageModerationRejectThreshold,
+		reviewThreshold: cfg.ImageModerationReviewThreshold,
+		failOpen:        cfg.ImageModerationFailOpen,
+	}
+}
+
+// Check clasifica la imagen. Devuelve ErrImageRejected si no está permitida y
+// ErrImageModerationDown si no se pudo clasificar (salvo con IMAGE_MODERATION_FAIL_OPEN, que la
+// acepta sin revisión).
+func (s *ImageModerationService) Check(ctx context.Context, userID int64, image []byte, contentType string) (*ImageVerdict, error) {
+	if s.classifier == nil {
+		return &ImageVerdict{}, nil
+	}
+	result, err := s.classifier.Classify(ctx, image, contentType)
+	if err != nil {
+		if s.failOpen {
+			logger.Warnf(imageModerationServiceComponent, "Imagen del usuario %d aceptada sin clasificar: %v", userID, err)
+			return &ImageVerdict{}, nil
+		}
+		logger.Errorf(imageModerationServiceComponent, "No se pudo clasificar la imagen del usuario %d: %v", userID, err)
+		return nil, ErrImageModerationDown
+	}
+
+	if result.Score >= s.rejectThreshold {
+		logger.Warnf(imageModerationServiceComponent, "Imagen del usuario %d rechazada (score %.3f, %s)",
+			userID, result.Score, strings.Join(result.Labels, ", "))
+		return nil, ErrImageRejected
+	}
+	return &ImageVerdict{
+		Review: result.Score >= s.reviewThreshold,
+		Score:  result.Score,
+		Labels: result.Labels,
+	}, nil
+}
+
+// QueueReview añade la imagen ya guardada a la cola de revisión si el veredicto lo pide. Un
+// fallo solo se registra: la imagen ya está publicada.
+func (s *ImageModerationService) QueueReview(userID int64, contentID, fileName string, verdict *ImageVerdict) {
+	if verdict == nil || !verdict.Review {
+		return
+	}
+	if err := queries.InsertImageReview(userID, contentID, fileName, verdict.Score, verdict.Labels); err != nil {
+		logger.Errorf(imageModerationServiceComponent, "%v", err)
+		return
+	}
+	logger.Infof(imageModerationServiceComponent, "Imagen %s del usuario %d enviada a revisión (score %.3f)", contentID, userID, verdict.Score)
+}
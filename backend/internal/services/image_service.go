@@ -17,8 +17,8 @@ import (
 	"github.com/davidM20/micro-service-backend-go.git/internal/config"
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
-	"github.com/davidM20/micro-service-backend-go.git/pkg/cloudclient"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/storage"
 	"github.com/google/uuid"
 	"github.com/h2non/filetype"
 	"github.com/h2non/filetype/types"
@@ -35,15 +35,16 @@ const (
 
 // ImageUploadService encapsula la lógica para subir y procesar imágenes.
 type ImageUploadService struct {
-	db  *sql.DB
-	cfg *config.Config
-	// No necesitamos el bucketHandle aquí si cloudclient usa variables globales
-	// o si cloudclient.UploadFile es una función estática/paquete.
+	db         *sql.DB
+	cfg        *config.Config
+	store      storage.Storage
+	scanner    IUploadScanService
+	moderation IImageModerationService
 }
 
 // NewImageUploadService crea una nueva instancia de ImageUploadService.
-func NewImageUploadService(db *sql.DB, cfg *config.Config) *ImageUploadService {
-	return &ImageUploadService{db: db, cfg: cfg}
+func NewImageUploadService(db *sql.DB, cfg *config.Config, store storage.Storage, scanner IUploadScanService, moderation IImageModerationService) *ImageUploadService {
+	return &ImageUploadService{db: db, cfg: cfg, store: store, scanner: scanner, moderation: moderation}
 }
 
 // InMemoryMultipartFile es un adaptador para io.Reader a multipart.File para el cloudclient.
@@ -101,6 +102,14 @@ func (s *ImageUploadService) ProcessAndUploadImage(ctx context.Context, userID i
 		return nil, fmt.Errorf("el archivo no es una imagen soportada: %s", kind.MIME.Value)
 	}
 
+	if err := s.scanner.Check(ctx, userID, "image", fileHeader.Filename, fileBytes); err != nil {
+		return nil, err
+	}
+	verdict, err := s.moderation.Check(ctx, userID, fileBytes, kind.MIME.Value)
+	if err != nil {
+		return nil, err
+	}
+
 	img, _, err := image.Decode(bytes.NewReader(fileBytes))
 	if err != nil {
 		logger.Errorf("ProcessAndUploadImage", "Error decodificando la imagen original (tipo: %s): %v", kind.MIME.Value, err)
@@ -123,10 +132,9 @@ func (s *ImageUploadService) ProcessAndUploadImage(ctx context.Context, userID i
 		return nil, fmt.Errorf("error convirtiendo original a WebP: %w", err)
 	}
 	originalFileName := baseFileName + "." + outputFormat
-	// Usar el constructor para InMemoryMultipartFile
-	err = cloudclient.UploadFile(ctx, NewInMemoryMultipartFile(originalWebPBytes, originalFileName), originalFileName, "image/webp")
+	err = s.store.Put(ctx, originalFileName, bytes.NewReader(originalWebPBytes), "image/webp", true)
 	if err != nil {
-		return nil, fmt.Errorf("error subiendo original WebP a GCS: %w", err)
+		return nil, fmt.Errorf("error subiendo original WebP al almacenamiento: %w", err)
 	}
 	originalGCSUrl = originalFileName
 	_, err = queries.InsertMultimedia(s.db, &models.Multimedia{
@@ -141,6 +149,7 @@ func (s *ImageUploadService) ProcessAndUploadImage(ctx context.Context, userID i
 	if err != nil {
 		return nil, fmt.Errorf("error guardando registro original en BD: %w", err)
 	}
+	s.moderation.QueueReview(userID, contentID, originalFileName, verdict)
 
 	// Procesar y subir variante de baja resolución
 	lowResImg := s.resizeImage(img, lowResWidth)
@@ -149,9 +158,9 @@ func (s *ImageUploadService) ProcessAndUploadImage(ctx context.Context, userID i
 		logger.Warnf("ProcessAndUploadImage", "Error convirtiendo low-res a WebP: %v", err)
 	} else {
 		lowResFileName := "low-" + originalFileName
-		err = cloudclient.UploadFile(ctx, NewInMemoryMultipartFile(lowResWebPBytes, lowResFileName), lowResFileName, "image/webp")
+		err = s.store.Put(ctx, lowResFileName, bytes.NewReader(lowResWebPBytes), "image/webp", true)
 		if err != nil {
-			logger.Warnf("ProcessAndUploadImage", "Error subiendo low-res WebP a GCS: %v", err)
+			logger.Warnf("ProcessAndUploadImage", "Error subiendo low-res WebP al almacenamiento: %v", err)
 		} else {
 			_, errDb := queries.InsertMultimedia(s.db, &models.Multimedia{
 				Id:        uuid.New().String(),
@@ -175,9 +184,9 @@ func (s *ImageUploadService) ProcessAndUploadImage(ctx context.Context, userID i
 		logger.Warnf("ProcessAndUploadImage", "Error convirtiendo medium-res a WebP: %v", err)
 	} else {
 		mediumResFileName := "medium-" + originalFileName
-		err = cloudclient.UploadFile(ctx, NewInMemoryMultipartFile(mediumResWebPBytes, mediumResFileName), mediumResFileName, "image/webp")
+		err = s.store.Put(ctx, mediumResFileName, bytes.NewReader(mediumResWebPBytes), "image/webp", true)
 		if err != nil {
-			logger.Warnf("ProcessAndUploadImage", "Error subiendo medium-res WebP a GCS: %v", err)
+			logger.Warnf("ProcessAndUploadImage", "Error subiendo medium-res WebP al almacenamiento: %v", err)
 		} else {
 			_, errDb := queries.InsertMultimedia(s.db, &models.Multimedia{
 				Id:        uuid.New().String(),
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/auth"
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const impersonationServiceComponent = "IMPERSONATION_SERVICE"
+
+const (
+	// impersonationReasonMinLength obliga a explicar la suplantación, normalmente con el
+	// número de ticket del reporte del usuario.
+	impersonationReasonMinLength = 10
+	impersonationReasonMaxLength = 500
+	// impersonationMaxTTL limita IMPERSONATION_TTL: los tokens de suplantación deben ser de
+	// corta duración.
+	impersonationMaxTTL     = time.Hour
+	impersonationDefaultTTL = 15 * time.Minute
+)
+
+// Errores de negocio de la suplantación.
+var (
+	ErrImpersonationSelf        = apperrors.New(apperrors.UserSelfManagement, "no puedes suplantarte a ti mismo")
+	ErrImpersonationAdmin       = apperrors.New(apperrors.ImpersonationForbidden, "no se puede suplantar a un administrador")
+	ErrImpersonationDisabled    = apperrors.New(apperrors.ImpersonationForbidden, "no se puede suplantar una cuenta suspendida o desactivada")
+	ErrImpersonationReason      = apperrors.New(apperrors.InvalidParam, fmt.Sprintf("el motivo debe tener entre %d y %d caracteres", impersonationReasonMinLength, impersonationReasonMaxLength))
+	ErrImpersonationNotFound    = apperrors.New(apperrors.NotFound, "suplantación no encontrada")
+	ErrImpersonationAlreadyDone = apperrors.New(apperrors.ImpersonationEnded, "la suplantación ya terminó o expiró")
+)
+
+// IImpersonationService define la interfaz del servicio de suplantación.
+type IImpersonationService interface {
+	Start(adminID, userID int64, reason string) (*models.ImpersonationToken, error)
+	End(impersonationID int64) (models.Impersonation, error)
+}
+
+// ImpersonationService emite los tokens con los que el soporte actúa como un usuario para
+// reproducir los problemas que reporta. El token es un JWT normal del usuario con el
+// administrador en el claim impersonatorId; cada petición comprueba que la suplantación
+// registrada siga activa, así que terminarla revoca el token de inmediato.
+type ImpersonationService struct {
+	db  *sql.DB
+	cfg *config.Config
+}
+
+// NewImpersonationService crea una nueva instancia de ImpersonationService.
+func NewImpersonationService(db *sql.DB, cfg *config.Config) IImpersonationService {
+	return &ImpersonationService{db: db, cfg: cfg}
+}
+
+// Start registra la suplantación de userID por adminID y devuelve su token.
+func (s *ImpersonationService) Start(adminID, userID int64, reason string) (*models.ImpersonationToken, error) {
+	if adminID == userID {
+		return nil, ErrImpersonationSelf
+	}
+	reason = strings.TrimSpace(reason)
+	if n := utf8.RuneCountInString(reason); n < impersonationReasonMinLength || n > impersonationReasonMaxLength {
+		return nil, ErrImpersonationReason
+	}
+
+	state, err := queries.GetAccountState(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAdminUserNotFound
+		}
+		return nil, err
+	}
+	// Un token de administrador daría acceso al panel en nombre de otra persona.
+	if models.UserRole(state.RoleId) == models.RoleAdmin {
+		return nil, ErrImpersonationAdmin
+	}
+	if models.IsAccountDisabled(state.StatusAuthorizedId) {
+		return nil, ErrImpersonationDisabled
+	}
+
+	ttl := s.cfg.ImpersonationTTL
+	switch {
+	case ttl <= 0:
+		ttl = impersonationDefaultTTL
+	case ttl > impersonationMaxTTL:
+		ttl = impersonationMaxTTL
+	}
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+
+	impersonationID, err := queries.CreateImpersonation(adminID, userID, reason, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	token, err := auth.GenerateImpersonationJWT(userID, int64(state.RoleId), adminID, impersonationID, []byte(s.cfg.JwtSecret), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof(impersonationServiceComponent, "Suplantación %d: el administrador %d actúa como el usuario %d hasta %s",
+		impersonationID, adminID, userID, expiresAt.Format(time.RFC3339))
+	return &models.ImpersonationToken{
+		ImpersonationId: impersonationID,
+		UserId:          userID,
+		Token:           token,
+		ExpiresAt:       expiresAt,
+	}, nil
+}
+
+// End termina la suplantación antes de que expire. La API deja de aceptar su token en la
+// siguiente petición y el servicio WebSocket cierra sus conexiones en menos de un minuto.
+func (s *ImpersonationService) End(impersonationID int64) (models.Impersonation, error) {
+	imp, err := queries.EndImpersonation(impersonationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return imp, ErrImpersonationNotFound
+		case errors.Is(err, queries.ErrImpersonationEnded):
+			return imp, ErrImpersonationAlreadyDone
+		}
+		return imp, err
+	}
+	logger.Infof(impersonationServiceComponent, "Suplantación %d del usuario %d terminada", impersonationID, imp.UserId)
+	return imp, nil
+}
@@ -4,10 +4,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
 )
 
 const jobApplicationServiceComponent = "JOB_APPLICATION_SERVICE"
@@ -16,6 +18,7 @@ const jobApplicationServiceComponent = "JOB_APPLICATION_SERVICE"
 type IJobApplication interface {
 	ApplyToJob(eventID, applicantID int64, request models.JobApplicationCreateRequest) error
 	ListApplicants(eventID int64) ([]models.ApplicantInfo, error)
+	ListApplicantsPage(eventID int64, page pagination.Params) (pagination.Page[models.ApplicantInfo], error)
 	UpdateApplicationStatus(eventID, applicantID int64, newStatus string) error
 }
 
@@ -61,6 +64,40 @@ func (s *JobApplicationService) ListApplicants(eventID int64) ([]models.Applican
 	}
 	defer rows.Close()
 
+	return scanApplicants(rows)
+}
+
+// ListApplicantsPage devuelve una página de postulantes por cursor, de la postulación más
+// reciente a la más antigua (AppliedAt DESC, ApplicantId DESC). El orden por reputación de
+// ListApplicants no sirve para un cursor porque cambia con cada reseña nueva.
+func (s *JobApplicationService) ListApplicantsPage(eventID int64, page pagination.Params) (pagination.Page[models.ApplicantInfo], error) {
+	query := queries.ListApplicantsByEventBase
+	args := []interface{}{eventID}
+	if cond, condArgs := page.Where("ja.AppliedAt", "ja.ApplicantId"); cond != "" {
+		query += " AND " + cond
+		args = append(args, condArgs...)
+	}
+	query += " ORDER BY ja.AppliedAt DESC, ja.ApplicantId DESC LIMIT ?"
+	args = append(args, page.FetchLimit())
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		logger.Errorf(jobApplicationServiceComponent, "Error al listar postulantes para el evento %d: %v", eventID, err)
+		return pagination.Page[models.ApplicantInfo]{}, fmt.Errorf("error al consultar la base de datos: %w", err)
+	}
+	defer rows.Close()
+
+	applicants, err := scanApplicants(rows)
+	if err != nil {
+		return pagination.Page[models.ApplicantInfo]{}, err
+	}
+	return pagination.NewPage(applicants, page.Limit, func(a models.ApplicantInfo) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: a.AppliedAt, ID: strconv.FormatInt(a.ApplicantID, 10)}
+	}), nil
+}
+
+// scanApplicants lee las filas de ListApplicantsByEventBase.
+func scanApplicants(rows *sql.Rows) ([]models.ApplicantInfo, error) {
 	var applicants []models.ApplicantInfo
 	for rows.Next() {
 		var nullableApp struct {
@@ -100,7 +137,7 @@ func (s *JobApplicationService) ListApplicants(eventID int64) ([]models.Applican
 		applicants = append(applicants, app)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		logger.Errorf(jobApplicationServiceComponent, "Error durante la iteración de los postulantes: %v", err)
 		return nil, fmt.Errorf("error al leer los resultados: %w", err)
 	}
@@ -0,0 +1,520 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/taxonomy"
+)
+
+const jobMatchingServiceComponent = "JOB_MATCHING_SERVICE"
+
+const (
+	// matchingCandidatePoolSize es el número máximo de candidatos que se puntúan por oferta.
+	// Se preseleccionan en SQL los que tienen alguna habilidad o carrera pedida.
+	matchingCandidatePoolSize = 500
+	// matchingJobPoolSize es el número máximo de ofertas recientes que se puntúan por estudiante.
+	matchingJobPoolSize = 500
+	// Límites de los requisitos de una oferta.
+	matchingMaxSkills        = 30
+	matchingMaxDegrees       = 10
+	matchingMaxLanguages     = 10
+	matchingMaxExperience    = 50
+	matchingNameMaxLength    = 100
+	matchingRequiredSkillMul = 2.0 // Las habilidades obligatorias pesan el doble
+	// matchingLowerLevelScore es la puntuación de un idioma que el candidato habla por debajo del
+	// nivel pedido.
+	matchingLowerLevelScore = 0.5
+)
+
+// matchWeights es el peso de cada componente en la puntuación total (suman 100). Los
+// componentes sin requisitos en la oferta no cuentan y su peso se reparte entre los demás.
+var matchWeights = []struct {
+	component string
+	weight    float64
+}{
+	{models.MatchComponentSkills, 40},
+	{models.MatchComponentDegree, 20},
+	{models.MatchComponentLanguages, 15},
+	{models.MatchComponentExperience, 15},
+	{models.MatchComponentReputation, 10},
+}
+
+// languageLevels ordena los niveles de idioma (MCER y sus equivalentes habituales) para
+// comparar el del candidato con el pedido. Las claves están normalizadas con taxonomy.Normalize.
+var languageLevels = map[string]int{
+	"a1": 1, "basico": 2, "a2": 2, "b1": 3, "intermedio": 3, "b2": 4,
+	"c1": 5, "avanzado": 5, "c2": 6, "nativo": 7, "native": 7, "lenguamaterna": 7,
+}
+
+// Errores de negocio del motor de recomendaciones.
+var (
+	ErrJobRequirementsNotFound = apperrors.New(apperrors.JobRequirementsNotFound, "la publicación no tiene requisitos")
+	ErrJobRequirementsOwner    = apperrors.New(apperrors.Forbidden, "solo el creador de la publicación puede gestionar sus requisitos y ver sus candidatos")
+	ErrJobRecommendationsRole  = apperrors.New(apperrors.Forbidden, "las ofertas recomendadas solo están disponibles para estudiantes y egresados")
+	ErrJobRequirementsTooMany  = apperrors.New(apperrors.JobRequirementsInvalid, fmt.Sprintf("como máximo %d habilidades, %d carreras y %d idiomas", matchingMaxSkills, matchingMaxDegrees, matchingMaxLanguages))
+	ErrJobRequirementsEmpty    = apperrors.New(apperrors.JobRequirementsInvalid, "los nombres de habilidades, carreras e idiomas no pueden estar vacíos ni superar los 100 caracteres")
+	ErrJobRequirementsYears    = apperrors.New(apperrors.JobRequirementsInvalid, fmt.Sprintf("los años de experiencia deben estar entre 0 y %d", matchingMaxExperience))
+)
+
+// IJobMatchingService define la interfaz del motor de recomendaciones.
+type IJobMatchingService interface {
+	GetRequirements(eventID int64) (*models.JobRequirements, error)
+	SetRequirements(eventID, userID int64, req models.SetJobRequirementsRequest) (*models.JobRequirements, error)
+	DeleteRequirements(eventID, userID int64) error
+	RecommendCandidates(eventID, userID int64, roleID models.UserRole, verifiedOnly bool, page, pageSize int) (*models.PaginatedCandidateRecommendations, error)
+	RecommendJobs(userID int64, roleID models.UserRole, page, pageSize int) (*models.PaginatedJobRecommendations, error)
+}
+
+// JobMatchingService puntúa la afinidad entre estudiantes y ofertas. Una oferta es una
+// publicación de CommunityEvent con requisitos (habilidades, carreras, idiomas y años de
+// experiencia); la puntuación (0-100) se desglosa por componente para explicar el resultado.
+type JobMatchingService struct {
+	db     *sql.DB
+	skills ISkillService
+}
+
+// NewJobMatchingService crea una nueva instancia de JobMatchingService.
+func NewJobMatchingService(db *sql.DB, skills ISkillService) IJobMatchingService {
+	return &JobMatchingService{db: db, skills: skills}
+}
+
+// GetRequirements devuelve los requisitos de una oferta.
+func (s *JobMatchingService) GetRequirements(eventID int64) (*models.JobRequirements, error) {
+	req, err := queries.GetJobRequirements(eventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrJobRequirementsNotFound
+	}
+	return req, err
+}
+
+// SetRequirements valida y guarda los requisitos de una publicación del usuario. Las
+// habilidades se normalizan con el catálogo y las repetidas se fusionan (obligatoria si alguna
+// lo es); carreras e idiomas repetidos se descartan.
+func (s *JobMatchingService) SetRequirements(eventID, userID int64, req models.SetJobRequirementsRequest) (*models.JobRequirements, error) {
+	if err := s.checkOwner(eventID, userID); err != nil {
+		return nil, err
+	}
+	if len(req.Skills) > matchingMaxSkills || len(req.Degrees) > matchingMaxDegrees || len(req.Languages) > matchingMaxLanguages {
+		return nil, ErrJobRequirementsTooMany
+	}
+	if req.MinExperienceYears < 0 || req.MinExperienceYears > matchingMaxExperience {
+		return nil, ErrJobRequirementsYears
+	}
+
+	requirements := models.JobRequirements{
+		CommunityEventId:   eventID,
+		Skills:             []models.JobSkillRequirement{},
+		Degrees:            []string{},
+		Languages:          []models.JobLanguageRequirement{},
+		MinExperienceYears: req.MinExperienceYears,
+	}
+	skillIndexByKey := make(map[string]int)
+	for _, skill := range req.Skills {
+		name := strings.TrimSpace(skill.Name)
+		if !validRequirementName(name) {
+			return nil, ErrJobRequirementsEmpty
+		}
+		name, catalogID := s.skills.CanonicalizeSkill(name)
+		key := taxonomy.Normalize(name)
+		if i, ok := skillIndexByKey[key]; ok {
+			requirements.Skills[i].Required = requirements.Skills[i].Required || skill.Required
+			continue
+		}
+		skillIndexByKey[key] = len(requirements.Skills)
+		requirements.Skills = append(requirements.Skills, models.JobSkillRequirement{Name: name, SkillCatalogId: catalogID, Required: skill.Required})
+	}
+	seenDegrees := make(map[string]bool)
+	for _, degree := range req.Degrees {
+		degree = strings.TrimSpace(degree)
+		if !validRequirementName(degree) {
+			return nil, ErrJobRequirementsEmpty
+		}
+		if key := taxonomy.Normalize(degree); !seenDegrees[key] {
+			seenDegrees[key] = true
+			requirements.Degrees = append(requirements.Degrees, degree)
+		}
+	}
+	seenLanguages := make(map[string]bool)
+	for _, language := range req.Languages {
+		language.Language = strings.TrimSpace(language.Language)
+		language.Level = strings.TrimSpace(language.Level)
+		if !validRequirementName(language.Language) || utf8.RuneCountInString(language.Level) > matchingNameMaxLength {
+			return nil, ErrJobRequirementsEmpty
+		}
+		if key := taxonomy.Normalize(language.Language); !seenLanguages[key] {
+			seenLanguages[key] = true
+			requirements.Languages = append(requirements.Languages, language)
+		}
+	}
+
+	if err := queries.SaveJobRequirements(requirements); err != nil {
+		logger.Errorf(jobMatchingServiceComponent, "Error al guardar los requisitos de la oferta %d: %v", eventID, err)
+		return nil, err
+	}
+	return s.GetRequirements(eventID)
+}
+
+// DeleteRequirements elimina los requisitos de una publicación del usuario.
+func (s *JobMatchingService) DeleteRequirements(eventID, userID int64) error {
+	if err := s.checkOwner(eventID, userID); err != nil {
+		return err
+	}
+	return queries.DeleteJobRequirements(eventID)
+}
+
+// RecommendCandidates devuelve, de mayor a menor afinidad, los estudiantes y egresados
+// recomendados para una oferta. Solo el creador de la publicación o un administrador pueden
+// consultarlos. Con verifiedOnly solo se devuelven los verificados con el correo institucional.
+func (s *JobMatchingService) RecommendCandidates(eventID, userID int64, roleID models.UserRole, verifiedOnly bool, page, pageSize int) (*models.PaginatedCandidateRecommendations, error) {
+	info, err := queries.GetChallengeInfo(eventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPostNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la publicación %d: %w", eventID, err)
+	}
+	if info.OwnerId != userID && roleID != models.RoleAdmin {
+		return nil, ErrJobRequirementsOwner
+	}
+	requirements, err := s.GetRequirements(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	var skillIDs []int64
+	var skillNames []string
+	for _, skill := range requirements.Skills {
+		if skill.SkillCatalogId != nil {
+			skillIDs = append(skillIDs, *skill.SkillCatalogId)
+		} else {
+			skillNames = append(skillNames, skill.Name)
+		}
+	}
+	pool, err := queries.GetCandidatePool(skillIDs, skillNames, requirements.Degrees, info.OwnerId, matchingCandidatePoolSize)
+	if err != nil {
+		logger.Errorf(jobMatchingServiceComponent, "Error al preseleccionar candidatos para la oferta %d: %v", eventID, err)
+		return nil, err
+	}
+	profiles, err := queries.GetCandidateProfiles(pool)
+	if err != nil {
+		logger.Errorf(jobMatchingServiceComponent, "Error al cargar los perfiles de los candidatos de la oferta %d: %v", eventID, err)
+		return nil, err
+	}
+	totals, err := queries.GetStudentReputationTotals()
+	if err != nil {
+		return nil, err
+	}
+	applicants, err := queries.GetApplicantIDs(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]models.CandidateRecommendation, 0, len(profiles))
+	for _, id := range pool {
+		profile, ok := profiles[id]
+		if !ok || (verifiedOnly && !profile.VerifiedStudent) {
+			continue
+		}
+		profile.ReputationPercentile = reputationPercentile(totals, profile.ReputationRP)
+		candidates = append(candidates, models.CandidateRecommendation{
+			UserId:          profile.UserId,
+			FirstName:       profile.FirstName,
+			LastName:        profile.LastName,
+			Picture:         profile.Picture,
+			RoleId:          profile.RoleId,
+			HasApplied:      applicants[profile.UserId],
+			VerifiedStudent: profile.VerifiedStudent,
+			MatchResult:     ScoreMatch(requirements, profile),
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	start, end := pageBounds(len(candidates), page, pageSize)
+	return &models.PaginatedCandidateRecommendations{
+		Data:       candidates[start:end],
+		Pagination: paginationDetails(len(candidates), page, pageSize),
+	}, nil
+}
+
+// RecommendJobs devuelve, de mayor a menor afinidad, las ofertas recomendadas para un
+// estudiante o egresado. Las ofertas sin ningún punto de afinidad no se incluyen.
+func (s *JobMatchingService) RecommendJobs(userID int64, roleID models.UserRole, page, pageSize int) (*models.PaginatedJobRecommendations, error) {
+	if roleID != models.RoleStudent && roleID != models.RoleEgresado {
+		return nil, ErrJobRecommendationsRole
+	}
+	profiles, err := queries.GetCandidateProfiles([]int64{userID})
+	if err != nil {
+		logger.Errorf(jobMatchingServiceComponent, "Error al cargar el perfil del usuario %d: %v", userID, err)
+		return nil, err
+	}
+	profile, ok := profiles[userID]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	totals, err := queries.GetStudentReputationTotals()
+	if err != nil {
+		return nil, err
+	}
+	profile.ReputationPercentile = reputationPercentile(totals, profile.ReputationRP)
+
+	postings, err := queries.ListJobPostingsWithRequirements(userID, matchingJobPoolSize)
+	if err != nil {
+		logger.Errorf(jobMatchingServiceComponent, "Error al consultar las ofertas para el usuario %d: %v", userID, err)
+		return nil, err
+	}
+	applied, err := queries.GetAppliedEventIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]models.JobRecommendation, 0, len(postings))
+	for i := range postings {
+		result := ScoreMatch(&postings[i].Requirements, profile)
+		if result.Score <= 0 {
+			continue
+		}
+		job := postings[i].Posting
+		job.HasApplied = applied[job.CommunityEventId]
+		job.MatchResult = result
+		jobs = append(jobs, job)
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].Score > jobs[j].Score
+	})
+
+	start, end := pageBounds(len(jobs), page, pageSize)
+	return &models.PaginatedJobRecommendations{
+		Data:       jobs[start:end],
+		Pagination: paginationDetails(len(jobs), page, pageSize),
+	}, nil
+}
+
+// checkOwner comprueba que la publicación existe y la creó el usuario.
+func (s *JobMatchingService) checkOwner(eventID, userID int64) error {
+	info, err := queries.GetChallengeInfo(eventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPostNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error al obtener la publicación %d: %w", eventID, err)
+	}
+	if info.OwnerId != userID {
+		return ErrJobRequirementsOwner
+	}
+	return nil
+}
+
+// ScoreMatch calcula la afinidad (0-100) de un candidato con los requisitos de una oferta. Cada
+// componente aporta Score (0-1) por su peso; los componentes sin requisitos no cuentan y el
+// total se reescala sobre los pesos restantes. La reputación cuenta siempre.
+func ScoreMatch(req *models.JobRequirements, profile *models.CandidateProfile) models.MatchResult {
+	result := models.MatchResult{Components: []models.MatchComponent{}}
+	var components []models.MatchComponent
+	for _, w := range matchWeights {
+		var component models.MatchComponent
+		var applies bool
+		switch w.component {
+		case models.MatchComponentSkills:
+			component, applies = scoreSkills(req.Skills, profile.Skills)
+			result.MissingRequired = hasMissingRequired(req.Skills, component.Missing)
+		case models.MatchComponentDegree:
+			component, applies = scoreDegree(req.Degrees, profile.Degrees)
+		case models.MatchComponentLanguages:
+			component, applies = scoreLanguages(req.Languages, profile.Languages)
+		case models.MatchComponentExperience:
+			component, applies = scoreExperience(req.MinExperienceYears, profile.ExperienceYears)
+		case models.MatchComponentReputation:
+			component = models.MatchComponent{
+				Score:  profile.ReputationPercentile / 100,
+				Detail: fmt.Sprintf("%d RP, percentil %.0f entre estudiantes", profile.ReputationRP, profile.ReputationPercentile),
+			}
+			applies = true
+		}
+		if !applies {
+			continue
+		}
+		component.Component = w.component
+		component.Weight = w.weight
+		components = append(components, component)
+	}
+
+	var totalWeight float64
+	for _, c := range components {
+		totalWeight += c.Weight
+	}
+	for _, c := range components {
+		c.Weight = roundScore(c.Weight * 100 / totalWeight)
+		c.Score = roundScore(c.Score)
+		c.Points = roundScore(c.Score * c.Weight)
+		result.Score += c.Points
+		result.Components = append(result.Components, c)
+	}
+	result.Score = roundScore(math.Min(result.Score, 100))
+	return result
+}
+
+// scoreSkills puntúa las habilidades: la suma de los pesos de las pedidas que tiene el
+// candidato entre la suma de todos los pesos. Se comparan por ID del catálogo o por nombre
+// normalizado.
+func scoreSkills(required []models.JobSkillRequirement, skills []models.Skills) (models.MatchComponent, bool) {
+	if len(required) == 0 {
+		return models.MatchComponent{}, false
+	}
+	catalogIDs := make(map[int64]bool)
+	names := make(map[string]bool)
+	for _, skill := range skills {
+		if skill.SkillCatalogId != nil {
+			catalogIDs[*skill.SkillCatalogId] = true
+		}
+		names[taxonomy.Normalize(skill.Skill)] = true
+	}
+
+	component := models.MatchComponent{}
+	var got, total float64
+	for _, req := range required {
+		weight := 1.0
+		if req.Required {
+			weight = matchingRequiredSkillMul
+		}
+		total += weight
+		if (req.SkillCatalogId != nil && catalogIDs[*req.SkillCatalogId]) || names[taxonomy.Normalize(req.Name)] {
+			got += weight
+			component.Matched = append(component.Matched, req.Name)
+		} else {
+			component.Missing = append(component.Missing, req.Name)
+		}
+	}
+	component.Score = got / total
+	component.Detail = fmt.Sprintf("%d de %d habilidades", len(component.Matched), len(required))
+	return component, true
+}
+
+// hasMissingRequired indica si alguna habilidad obligatoria está entre las que faltan.
+func hasMissingRequired(required []models.JobSkillRequirement, missing []string) bool {
+	missingSet := make(map[string]bool, len(missing))
+	for _, name := range missing {
+		missingSet[name] = true
+	}
+	for _, req := range required {
+		if req.Required && missingSet[req.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// scoreDegree puntúa 1 si alguna carrera del candidato coincide con una de las pedidas. Una
+// carrera coincide si, normalizadas, una contiene a la otra ("Ingeniería Informática" y
+// "Informática").
+func scoreDegree(required, degrees []string) (models.MatchComponent, bool) {
+	if len(required) == 0 {
+		return models.MatchComponent{}, false
+	}
+	component := models.MatchComponent{Detail: "Ninguna carrera coincide"}
+	for _, req := range required {
+		reqKey := taxonomy.Normalize(req)
+		for _, degree := range degrees {
+			key := taxonomy.Normalize(degree)
+			if key != "" && reqKey != "" && (strings.Contains(key, reqKey) || strings.Contains(reqKey, key)) {
+				component.Score = 1
+				component.Matched = []string{degree}
+				component.Detail = fmt.Sprintf("Estudia %s", degree)
+				return component, true
+			}
+		}
+	}
+	component.Missing = required
+	return component, true
+}
+
+// scoreLanguages puntúa la proporción de idiomas pedidos que habla el candidato. Un idioma
+// con un nivel inferior al pedido cuenta matchingLowerLevelScore; si alguno de los niveles no
+// se reconoce cuenta entero.
+func scoreLanguages(required []models.JobLanguageRequirement, languages []models.Languages) (models.MatchComponent, bool) {
+	if len(required) == 0 {
+		return models.MatchComponent{}, false
+	}
+	levels := make(map[string]string, len(languages))
+	for _, language := range languages {
+		levels[taxonomy.Normalize(language.Language)] = language.Level
+	}
+
+	component := models.MatchComponent{}
+	var got float64
+	for _, req := range required {
+		level, ok := levels[taxonomy.Normalize(req.Language)]
+		if !ok {
+			component.Missing = append(component.Missing, req.Language)
+			continue
+		}
+		component.Matched = append(component.Matched, req.Language)
+		want, wantOK := languageLevels[taxonomy.Normalize(req.Level)]
+		have, haveOK := languageLevels[taxonomy.Normalize(level)]
+		if wantOK && haveOK && have < want {
+			got += matchingLowerLevelScore
+		} else {
+			got++
+		}
+	}
+	component.Score = got / float64(len(required))
+	component.Detail = fmt.Sprintf("%d de %d idiomas", len(component.Matched), len(required))
+	return component, true
+}
+
+// scoreExperience puntúa los años de experiencia del candidato respecto al mínimo pedido.
+func scoreExperience(minYears int, years float64) (models.MatchComponent, bool) {
+	if minYears <= 0 {
+		return models.MatchComponent{}, false
+	}
+	return models.MatchComponent{
+		Score:  math.Min(years/float64(minYears), 1),
+		Detail: fmt.Sprintf("%.1f de %d años de experiencia", years, minYears),
+	}, true
+}
+
+// reputationPercentile devuelve el porcentaje de totales (ordenados ascendentemente) menores
+// que points, igual que queries.GetReputationPercentile.
+func reputationPercentile(totals []int, points int) float64 {
+	if len(totals) == 0 {
+		return 0
+	}
+	below := sort.SearchInts(totals, points)
+	return float64(below) * 100 / float64(len(totals))
+}
+
+// validRequirementName indica si un nombre de habilidad, carrera o idioma es aceptable.
+func validRequirementName(name string) bool {
+	return name != "" && utf8.RuneCountInString(name) <= matchingNameMaxLength
+}
+
+// roundScore redondea a dos decimales.
+func roundScore(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// pageBounds devuelve los índices [start, end) de la página dentro de total elementos.
+func pageBounds(total, page, pageSize int) (int, int) {
+	start := min((page-1)*pageSize, total)
+	return start, min(start+pageSize, total)
+}
+
+// paginationDetails calcula la paginación de total elementos.
+func paginationDetails(total, page, pageSize int) models.PaginationDetails {
+	totalPages := 0
+	if total > 0 {
+		totalPages = (total + pageSize - 1) / pageSize
+	}
+	return models.PaginationDetails{TotalItems: total, TotalPages: totalPages, CurrentPage: page, PageSize: pageSize}
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/geoip"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/timefmt"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/useragent"
+)
+
+const loginSecurityServiceComponent = "LOGIN_SECURITY_SERVICE"
+
+// ILoginSecurityService define la interfaz del servicio que registra las sesiones con los
+// datos del dispositivo y avisa de los inicios de sesión inusuales.
+type ILoginSecurityService interface {
+	RegisterSession(r *http.Request, user models.User, token string, tokenID int) (string, models.SessionDevice, error)
+}
+
+// LoginSecurityService guarda en la sesión el User-Agent, el tipo de dispositivo y la
+// ubicación aproximada del inicio de sesión, y crea un Event de seguridad cuando el usuario
+// entra desde un dispositivo o un país que no había usado antes.
+type LoginSecurityService struct {
+	db       *sql.DB
+	resolver *geoip.Resolver
+}
+
+// NewLoginSecurityService crea una nueva instancia de LoginSecurityService.
+func NewLoginSecurityService(db *sql.DB, cfg *config.Config) ILoginSecurityService {
+	return &LoginSecurityService{
+		db:       db,
+		resolver: geoip.NewResolver(cfg.GeoIPCountryHeader, cfg.GeoIPCityHeader, cfg.GeoIPAPIURL, cfg.GeoIPTimeout),
+	}
+}
+
+// RegisterSession crea la sesión del token y devuelve la IP y el dispositivo detectados. Los
+// avisos de seguridad se crean después de registrar la sesión; si fallan solo se registra el
+// error y el inicio de sesión continúa.
+func (s *LoginSecurityService) RegisterSession(r *http.Request, user models.User, token string, tokenID int) (string, models.SessionDevice, error) {
+	ip := ClientIP(r)
+	userAgent := r.UserAgent()
+	if utf8.RuneCountInString(userAgent) > 512 {
+		userAgent = string([]rune(userAgent)[:512])
+	}
+	info := useragent.Parse(userAgent)
+	location := s.resolver.Resolve(r.Context(), r, ip)
+	device := models.SessionDevice{
+		UserAgent:  userAgent,
+		DeviceType: info.DeviceType,
+		Browser:    info.Browser,
+		OS:         info.OS,
+		Country:    location.Country,
+		City:       location.City,
+	}
+
+	// Se consultan antes de insertar para no comparar la sesión consigo misma
+	knownDevices, knownCountries, err := queries.GetKnownSessionDevices(s.db, user.Id)
+	if err != nil {
+		logger.Warnf(loginSecurityServiceComponent, "No se pudo comprobar el historial de sesiones de UserID %d: %v", user.Id, err)
+	}
+
+	if err := queries.RegisterUserSession(s.db, user.Id, token, ip, user.RoleId, tokenID, device); err != nil {
+		return ip, device, err
+	}
+
+	// Sin historial (primer inicio de sesión o sesiones anteriores a este registro) no hay con
+	// qué comparar y no se avisa.
+	if len(knownDevices) > 0 && !knownDevices[info.Fingerprint()] {
+		s.notify(user.Id, models.EventTypeSecurityNewDevice, "Inicio de sesión desde un dispositivo nuevo",
+			fmt.Sprintf("Se ha iniciado sesión en tu cuenta desde %s", info.String()), ip, device)
+	}
+	if device.Country != "" && len(knownCountries) > 0 && !knownCountries[device.Country] {
+		s.notify(user.Id, models.EventTypeSecurityNewCountry, "Inicio de sesión desde un país nuevo",
+			fmt.Sprintf("Se ha iniciado sesión en tu cuenta desde %s", describeLocation(device)), ip, device)
+	}
+	return ip, device, nil
+}
+
+// notify crea el Event de seguridad. La descripción termina con la IP y la hora para que el
+// usuario pueda reconocer el acceso.
+func (s *LoginSecurityService) notify(userID int64, eventType, title, description, ip string, device models.SessionDevice) {
+	metadata, err := json.Marshal(map[string]interface{}{
+		"alertSecurity": true,
+		"ipAddress":     ip,
+		"deviceType":    device.DeviceType,
+		"browser":       device.Browser,
+		"os":            device.OS,
+		"country":       device.Country,
+		"city":          device.City,
+	})
+	if err != nil {
+		logger.Errorf(loginSecurityServiceComponent, "Error serializando metadatos del aviso de seguridad: %v", err)
+	}
+	event := models.Event{
+		EventType:  eventType,
+		EventTitle: title,
+		Description: fmt.Sprintf("%s (IP %s) el %s. Si no fuiste tú, cambia tu contraseña.",
+			description, ip, timefmt.Display(time.Now()).Format("2006-01-02 15:04:05")),
+		UserId:   userID,
+		Metadata: metadata,
+	}
+	if _, err := queries.CreateNotification(event); err != nil {
+		logger.Errorf(loginSecurityServiceComponent, "Error creando aviso %s para UserID %d: %v", eventType, userID, err)
+		return
+	}
+	logger.Warnf(loginSecurityServiceComponent, "Aviso %s para UserID %d (IP %s, %s/%s/%s, país %q)",
+		eventType, userID, ip, device.DeviceType, device.Browser, device.OS, device.Country)
+}
+
+// describeLocation devuelve "Ciudad, PA" o solo el código de país.
+func describeLocation(device models.SessionDevice) string {
+	if device.City != "" {
+		return device.City + ", " + device.Country
+	}
+	return device.Country
+}
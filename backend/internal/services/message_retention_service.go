@@ -0,0 +1,209 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const messageRetentionServiceComponent = "MESSAGE_RETENTION_SERVICE"
+
+const (
+	// retentionBatchSize es el número de mensajes movidos por transacción.
+	retentionBatchSize = 500
+	// retentionBatchPause evita acaparar la BD entre lotes consecutivos.
+	retentionBatchPause = 200 * time.Millisecond
+	// retentionRunsListLimit es el número máximo de ejecuciones devueltas al panel.
+	retentionRunsListLimit = 50
+)
+
+var (
+	ErrRetentionDisabled   = apperrors.New(apperrors.RetentionDisabled, "la retención de mensajes está deshabilitada (MESSAGE_RETENTION_DAYS=0)")
+	ErrRetentionRunActive  = apperrors.New(apperrors.RetentionRunActive, "ya hay una ejecución de retención en curso")
+	ErrChatNotFound        = apperrors.New(apperrors.ChatNotFound, "chat no encontrado")
+	ErrRetentionOptOutMiss = apperrors.New(apperrors.RetentionOptOutMissing, "el chat no tiene opt-out de retención")
+)
+
+// IMessageRetentionService define el archivado de mensajes antiguos y su administración.
+type IMessageRetentionService interface {
+	TriggerRun(adminID int64) (*models.MessageRetentionRun, error)
+	ListRuns() ([]models.MessageRetentionRun, error)
+	GetStatus() (*models.MessageRetentionStatus, error)
+	SetChatOptOut(chatID string, adminID int64) error
+	RemoveChatOptOut(chatID string) error
+	ListChatOptOuts() ([]models.ChatRetentionOptOut, error)
+}
+
+// MessageRetentionService mueve a MessageArchive los mensajes más antiguos que el periodo
+// de retención configurado y los elimina de la tabla Message.
+type MessageRetentionService struct {
+	db            *sql.DB
+	retentionDays int
+	interval      time.Duration
+}
+
+// NewMessageRetentionService crea el servicio y, si la retención está habilitada,
+// lanza la ejecución periódica cada MESSAGE_RETENTION_INTERVAL.
+func NewMessageRetentionService(db *sql.DB, cfg *config.Config) IMessageRetentionService {
+	s := &MessageRetentionService{
+		db:            db,
+		retentionDays: cfg.MessageRetentionDays,
+		interval:      cfg.MessageRetentionInterval,
+	}
+	if s.enabled() && s.interval > 0 {
+		go s.scheduleLoop()
+	}
+	return s
+}
+
+func (s *MessageRetentionService) enabled() bool {
+	return s.retentionDays > 0
+}
+
+func (s *MessageRetentionService) cutoff() time.Time {
+	return time.Now().AddDate(0, 0, -s.retentionDays)
+}
+
+// scheduleLoop lanza una ejecución programada en cada intervalo.
+func (s *MessageRetentionService) scheduleLoop() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		run, err := s.startRun(models.RetentionTriggerScheduled, nil)
+		if err != nil {
+			if !errors.Is(err, ErrRetentionRunActive) {
+				logger.Errorf(messageRetentionServiceComponent, "No se pudo iniciar la retención programada: %v", err)
+			}
+			continue
+		}
+		s.archive(run)
+	}
+}
+
+// TriggerRun inicia una ejecución manual en segundo plano y devuelve su registro.
+func (s *MessageRetentionService) TriggerRun(adminID int64) (*models.MessageRetentionRun, error) {
+	if !s.enabled() {
+		return nil, ErrRetentionDisabled
+	}
+	run, err := s.startRun(models.RetentionTriggerManual, &adminID)
+	if err != nil {
+		return nil, err
+	}
+	go s.archive(run)
+	return run, nil
+}
+
+// startRun registra la ejecución en la BD; falla con ErrRetentionRunActive si hay otra en curso
+// (en esta u otra instancia de la API).
+func (s *MessageRetentionService) startRun(trigger string, triggeredBy *int64) (*models.MessageRetentionRun, error) {
+	cutoff := s.cutoff()
+	id, started, err := queries.StartMessageRetentionRun(trigger, triggeredBy, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, ErrRetentionRunActive
+	}
+	return &models.MessageRetentionRun{
+		Id:          id,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		Status:      models.RetentionRunRunning,
+		Cutoff:      cutoff,
+		StartedAt:   time.Now(),
+	}, nil
+}
+
+// archive procesa lotes hasta que no queden mensajes archivables y cierra la ejecución.
+func (s *MessageRetentionService) archive(run *models.MessageRetentionRun) {
+	logger.Infof(messageRetentionServiceComponent, "Retención #%d (%s): archivando mensajes anteriores a %s", run.Id, run.Trigger, run.Cutoff.Format(time.RFC3339))
+
+	var total int64
+	for {
+		archived, err := queries.ArchiveMessagesBatch(run.Id, run.Cutoff, retentionBatchSize)
+		if err != nil {
+			logger.Errorf(messageRetentionServiceComponent, "Retención #%d falló tras archivar %d mensajes: %v", run.Id, total, err)
+			if ferr := queries.FinishMessageRetentionRun(run.Id, models.RetentionRunFailed, total, err.Error()); ferr != nil {
+				logger.Errorf(messageRetentionServiceComponent, "%v", ferr)
+			}
+			return
+		}
+		total += archived
+		if archived == 0 {
+			break
+		}
+		if err := queries.UpdateMessageRetentionRunProgress(run.Id, total); err != nil {
+			logger.Warnf(messageRetentionServiceComponent, "%v", err)
+		}
+		time.Sleep(retentionBatchPause)
+	}
+
+	if err := queries.FinishMessageRetentionRun(run.Id, models.RetentionRunSucceeded, total, ""); err != nil {
+		logger.Errorf(messageRetentionServiceComponent, "%v", err)
+		return
+	}
+	logger.Successf(messageRetentionServiceComponent, "Retención #%d completada: %d mensajes archivados", run.Id, total)
+}
+
+// ListRuns devuelve las ejecuciones más recientes.
+func (s *MessageRetentionService) ListRuns() ([]models.MessageRetentionRun, error) {
+	return queries.GetMessageRetentionRuns(retentionRunsListLimit)
+}
+
+// GetStatus devuelve la configuración vigente y la última ejecución.
+func (s *MessageRetentionService) GetStatus() (*models.MessageRetentionStatus, error) {
+	status := &models.MessageRetentionStatus{
+		Enabled:       s.enabled(),
+		RetentionDays: s.retentionDays,
+		Interval:      s.interval.String(),
+	}
+	runs, err := queries.GetMessageRetentionRuns(1)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) > 0 {
+		status.LastRun = &runs[0]
+	}
+	return status, nil
+}
+
+// SetChatOptOut excluye un chat existente del archivado.
+func (s *MessageRetentionService) SetChatOptOut(chatID string, adminID int64) error {
+	exists, err := queries.ChatExists(chatID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrChatNotFound, chatID)
+	}
+	if err := queries.SetChatRetentionOptOut(chatID, adminID); err != nil {
+		return err
+	}
+	logger.Infof(messageRetentionServiceComponent, "Chat %s excluido de la retención por el admin %d", chatID, adminID)
+	return nil
+}
+
+// RemoveChatOptOut vuelve a incluir un chat en el archivado.
+func (s *MessageRetentionService) RemoveChatOptOut(chatID string) error {
+	removed, err := queries.DeleteChatRetentionOptOut(chatID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrRetentionOptOutMiss
+	}
+	return nil
+}
+
+// ListChatOptOuts lista los chats excluidos del archivado.
+func (s *MessageRetentionService) ListChatOptOuts() ([]models.ChatRetentionOptOut, error) {
+	return queries.GetChatRetentionOptOuts()
+}
@@ -0,0 +1,580 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/storage"
+	"github.com/go-sql-driver/mysql"
+)
+
+const moderationServiceComponent = "MODERATION_SERVICE"
+
+// reportTextMaxLength es la longitud máxima de los detalles de un reporte y de la nota de
+// resolución, en caracteres.
+const reportTextMaxLength = 1000
+
+// Ventana de mensajes del contexto de un reporte de mensaje: la que se captura al crear el
+// reporte y el máximo que puede pedir un moderador a cada lado.
+const (
+	reportContextDefaultMessages = 10
+	reportContextMaxMessages     = 50
+)
+
+// Errores de negocio del servicio de moderación.
+var (
+	ErrReportInvalidTarget  = apperrors.New(apperrors.ReportInvalid, "tipo de contenido no válido (MESSAGE, POST, COMMENT o PROFILE)")
+	ErrReportInvalidReason  = apperrors.New(apperrors.ReportInvalid, "motivo de reporte no válido")
+	ErrReportTargetNotFound = apperrors.New(apperrors.ReportInvalid, "el contenido reportado no existe o no tienes acceso a él")
+	ErrReportOwnContent     = apperrors.New(apperrors.ReportInvalid, "no puedes reportar tu propio contenido")
+	ErrReportTextTooLong    = apperrors.New(apperrors.ReportInvalid, fmt.Sprintf("los detalles y la nota no pueden superar los %d caracteres", reportTextMaxLength))
+	ErrReportNotFound       = apperrors.New(apperrors.ReportNotFound, "reporte no encontrado")
+	ErrReportDuplicate      = apperrors.New(apperrors.ReportDuplicate, "ya reportaste este contenido")
+	ErrReportClosed         = apperrors.New(apperrors.ReportAlreadyClosed, "el reporte ya fue resuelto o descartado")
+	ErrModerationAction     = apperrors.New(apperrors.ModerationActionInvalid, "acción de moderación no válida")
+	ErrModerationNoAction   = apperrors.New(apperrors.ModerationActionInvalid, "indica al menos una acción o descarta el reporte")
+	ErrModerationProfile    = apperrors.New(apperrors.ModerationActionInvalid, "un perfil no se puede ocultar ni eliminar; advierte o suspende al usuario")
+	ErrModerationNoAuthor   = apperrors.New(apperrors.ModerationActionInvalid, "el autor del contenido ya no existe")
+	ErrModerationAdmin      = apperrors.New(apperrors.ModerationActionInvalid, "no se puede suspender a un administrador")
+	ErrHiddenContentMissing = apperrors.New(apperrors.NotFound, "el contenido no está oculto")
+	ErrUserNotSuspended     = apperrors.New(apperrors.NotFound, "el usuario no existe o no está suspendido")
+	ErrReportContextInvalid = apperrors.New(apperrors.ReportContextInvalid, "el reporte no es de un mensaje o el mensaje ya no existe")
+	ErrReportContextWindow  = apperrors.New(apperrors.InvalidParam, fmt.Sprintf("before y after deben estar entre 0 y %d", reportContextMaxMessages))
+	ErrReportContextExists  = apperrors.New(apperrors.ReportContextExists, "el contexto de este reporte ya fue capturado")
+	ErrReportContextMissing = apperrors.New(apperrors.NotFound, "el reporte no tiene contexto capturado")
+	ErrImageReviewNotFound  = apperrors.New(apperrors.NotFound, "imagen en revisión no encontrada")
+	ErrImageReviewClosed    = apperrors.New(apperrors.ReportAlreadyClosed, "la imagen ya fue revisada")
+	ErrImageReviewAction    = apperrors.New(apperrors.ModerationActionInvalid, "acción no válida (APROBADA o ELIMINADA)")
+)
+
+// reportReasons son los motivos válidos de un reporte.
+var reportReasons = map[string]bool{
+	models.ReportReasonSpam:          true,
+	models.ReportReasonHarassment:    true,
+	models.ReportReasonInappropriate: true,
+	models.ReportReasonFraud:         true,
+	models.ReportReasonImpersonation: true,
+	models.ReportReasonOther:         true,
+}
+
+// reportTargetNames son los nombres con los que se describe el contenido en las
+// notificaciones.
+var reportTargetNames = map[string]string{
+	models.ReportTargetMessage: "el mensaje",
+	models.ReportTargetPost:    "la publicación",
+	models.ReportTargetComment: "el comentario",
+	models.ReportTargetProfile: "el perfil",
+}
+
+// IModerationService define la interfaz del servicio de moderación.
+type IModerationService interface {
+	CreateReport(reporterID int64, req models.CreateReportRequest) (*models.Report, error)
+	ListMyReports(userID int64, page, pageSize int) (*models.PaginatedReports, error)
+	ListReports(status, targetType string, page, pageSize int) (*models.PaginatedReports, error)
+	GetReport(reportID int64) (*models.Report, error)
+	ResolveReport(reportID, adminID int64, req models.ResolveReportRequest) (*models.Report, error)
+	DismissReport(reportID, adminID int64, req models.DismissReportRequest) (*models.Report, error)
+	UnhideContent(targetType, targetID string) error
+	ReinstateUser(userID int64) error
+	CaptureReportContext(reportID, adminID int64, req models.CaptureReportContextRequest) (*models.ReportContextBundle, error)
+	GetReportContext(reportID int64) (*models.ReportContextBundle, error)
+	ListImageReviews(status string, page, pageSize int) (*models.PaginatedImageReviews, error)
+	ResolveImageReview(reviewID, adminID int64, req models.ResolveImageReviewRequest) (*models.ImageReview, error)
+}
+
+// ModerationService gestiona la cola de moderación: los usuarios reportan mensajes,
+// publicaciones, comentarios y perfiles, y los administradores ocultan o eliminan el
+// contenido y advierten o suspenden a su autor. También revisa las imágenes que la moderación
+// automática (ImageModerationService) marcó como dudosas. Las notificaciones de moderación
+// llegan en tiempo real a través del servicio WebSocket.
+type ModerationService struct {
+	db    *sql.DB
+	store storage.Storage
+}
+
+// NewModerationService crea una nueva instancia de ModerationService.
+func NewModerationService(db *sql.DB, store storage.Storage) IModerationService {
+	return &ModerationService{db: db, store: store}
+}
+
+// validateReportTarget comprueba el tipo de contenido y que el ID tenga el formato esperado:
+// los mensajes usan UUID y el resto IDs numéricos.
+func validateReportTarget(targetType, targetID string) error {
+	if _, ok := reportTargetNames[targetType]; !ok {
+		return ErrReportInvalidTarget
+	}
+	if targetID == "" {
+		return ErrReportTargetNotFound
+	}
+	if targetType != models.ReportTargetMessage {
+		if id, err := strconv.ParseInt(targetID, 10, 64); err != nil || id <= 0 {
+			return ErrReportTargetNotFound
+		}
+	}
+	return nil
+}
+
+// CreateReport registra un reporte con una copia del contenido tal como está ahora. Un
+// usuario solo puede reportar una vez el mismo contenido.
+func (s *ModerationService) CreateReport(reporterID int64, req models.CreateReportRequest) (*models.Report, error) {
+	req.TargetType = strings.ToUpper(strings.TrimSpace(req.TargetType))
+	req.TargetId = strings.TrimSpace(req.TargetId)
+	req.Reason = strings.ToUpper(strings.TrimSpace(req.Reason))
+	req.Details = strings.TrimSpace(req.Details)
+
+	if err := validateReportTarget(req.TargetType, req.TargetId); err != nil {
+		return nil, err
+	}
+	if !reportReasons[req.Reason] {
+		return nil, ErrReportInvalidReason
+	}
+	if utf8.RuneCountInString(req.Details) > reportTextMaxLength {
+		return nil, ErrReportTextTooLong
+	}
+
+	content, err := queries.GetReportedContent(req.TargetType, req.TargetId, reporterID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReportTargetNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el contenido reportado: %w", err)
+	}
+	if content.AuthorId == reporterID {
+		return nil, ErrReportOwnContent
+	}
+
+	reportID, err := queries.InsertReport(reporterID, req, *content)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return nil, ErrReportDuplicate
+		}
+		return nil, err
+	}
+	logger.Infof(moderationServiceComponent, "Reporte %d de %d sobre %s %s (%s)", reportID, reporterID, req.TargetType, req.TargetId, req.Reason)
+
+	// El contexto de un mensaje se captura ya, antes de que la conversación cambie. Si falla,
+	// el reporte sigue siendo válido y un moderador puede capturarlo después.
+	if req.TargetType == models.ReportTargetMessage {
+		if _, err := s.captureContext(reportID, req.TargetId, nil, reportContextDefaultMessages, reportContextDefaultMessages); err != nil {
+			logger.Warnf(moderationServiceComponent, "No se pudo capturar el contexto del reporte %d: %v", reportID, err)
+		}
+	}
+
+	report, err := queries.GetReportByID(reportID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el reporte recién creado: %w", err)
+	}
+	return reporterView(report), nil
+}
+
+// reporterView quita del reporte los datos que solo ven los moderadores.
+func reporterView(report *models.Report) *models.Report {
+	report.TargetUserId, report.ContentSnapshot = nil, ""
+	report.ReporterName, report.TargetUserName, report.PendingReports = "", "", 0
+	return report
+}
+
+// ListMyReports devuelve una página de los reportes enviados por el usuario y su estado.
+func (s *ModerationService) ListMyReports(userID int64, page, pageSize int) (*models.PaginatedReports, error) {
+	reports, total, err := queries.ListReportsByReporter(userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return paginatedReports(reports, total, page, pageSize), nil
+}
+
+// ListReports devuelve una página de la cola de moderación. status y targetType filtran
+// por estado y tipo de contenido; vacíos no filtran.
+func (s *ModerationService) ListReports(status, targetType string, page, pageSize int) (*models.PaginatedReports, error) {
+	switch status {
+	case "", models.ReportStatusPending, models.ReportStatusResolved, models.ReportStatusDismissed:
+	default:
+		return nil, apperrors.New(apperrors.InvalidParam, "estado de reporte no válido")
+	}
+	if _, ok := reportTargetNames[targetType]; targetType != "" && !ok {
+		return nil, ErrReportInvalidTarget
+	}
+
+	reports, total, err := queries.ListReports(status, targetType, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return paginatedReports(reports, total, page, pageSize), nil
+}
+
+func paginatedReports(reports []models.Report, total, page, pageSize int) *models.PaginatedReports {
+	totalPages := 0
+	if total > 0 {
+		totalPages = (total + pageSize - 1) / pageSize
+	}
+	return &models.PaginatedReports{
+		Data: reports,
+		Pagination: models.PaginationDetails{
+			TotalItems:  total,
+			TotalPages:  totalPages,
+			CurrentPage: page,
+			PageSize:    pageSize,
+		},
+	}
+}
+
+// GetReport devuelve un reporte de la cola de moderación.
+func (s *ModerationService) GetReport(reportID int64) (*models.Report, error) {
+	report, err := queries.GetReportByID(reportID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el reporte %d: %w", reportID, err)
+	}
+	return report, nil
+}
+
+// ResolveReport aplica las acciones del administrador sobre el contenido y su autor y
+// cierra el reporte junto con los demás reportes pendientes del mismo contenido. Avisa a
+// los denunciantes y, si se le advierte o suspende, al autor.
+func (s *ModerationService) ResolveReport(reportID, adminID int64, req models.ResolveReportRequest) (*models.Report, error) {
+	if req.ContentAction == "" {
+		req.ContentAction = models.ContentActionNone
+	}
+	if req.AuthorAction == "" {
+		req.AuthorAction = models.AuthorActionNone
+	}
+	req.Note = strings.TrimSpace(req.Note)
+
+	switch req.ContentAction {
+	case models.ContentActionNone, models.ContentActionHide, models.ContentActionDelete:
+	default:
+		return nil, ErrModerationAction
+	}
+	switch req.AuthorAction {
+	case models.AuthorActionNone, models.AuthorActionWarn, models.AuthorActionSuspend:
+	default:
+		return nil, ErrModerationAction
+	}
+	if req.ContentAction == models.ContentActionNone && req.AuthorAction == models.AuthorActionNone {
+		return nil, ErrModerationNoAction
+	}
+	if utf8.RuneCountInString(req.Note) > reportTextMaxLength {
+		return nil, ErrReportTextTooLong
+	}
+
+	report, err := s.GetReport(reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != models.ReportStatusPending {
+		return nil, ErrReportClosed
+	}
+	if report.TargetType == models.ReportTargetProfile && req.ContentAction != models.ContentActionNone {
+		return nil, ErrModerationProfile
+	}
+	if req.AuthorAction != models.AuthorActionNone {
+		if report.TargetUserId == nil {
+			return nil, ErrModerationNoAuthor
+		}
+		if req.AuthorAction == models.AuthorActionSuspend {
+			author, err := queries.GetUserByID(s.db, *report.TargetUserId)
+			if err != nil {
+				return nil, fmt.Errorf("error al obtener al autor %d: %w", *report.TargetUserId, err)
+			}
+			if models.UserRole(author.RoleId) == models.RoleAdmin {
+				return nil, ErrModerationAdmin
+			}
+		}
+	}
+
+	return s.closeReport(report, queries.ModerationDecision{
+		ReportId:      reportID,
+		AdminId:       adminID,
+		Status:        models.ReportStatusResolved,
+		ContentAction: req.ContentAction,
+		AuthorAction:  req.AuthorAction,
+		Note:          req.Note,
+	})
+}
+
+// DismissReport descarta el reporte y los demás reportes pendientes del mismo contenido
+// sin tomar acciones, y avisa a los denunciantes.
+func (s *ModerationService) DismissReport(reportID, adminID int64, req models.DismissReportRequest) (*models.Report, error) {
+	req.Note = strings.TrimSpace(req.Note)
+	if utf8.RuneCountInString(req.Note) > reportTextMaxLength {
+		return nil, ErrReportTextTooLong
+	}
+	report, err := s.GetReport(reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != models.ReportStatusPending {
+		return nil, ErrReportClosed
+	}
+
+	return s.closeReport(report, queries.ModerationDecision{
+		ReportId: reportID,
+		AdminId:  adminID,
+		Status:   models.ReportStatusDismissed,
+		Note:     req.Note,
+	})
+}
+
+// closeReport guarda la decisión y envía las notificaciones. Un fallo al notificar no
+// invalida la decisión.
+func (s *ModerationService) closeReport(report *models.Report, decision queries.ModerationDecision) (*models.Report, error) {
+	reporterIDs, err := queries.CloseReports(decision)
+	if errors.Is(err, queries.ErrReportClosed) {
+		return nil, ErrReportClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	targetName := reportTargetNames[report.TargetType]
+	metadata := models.EventMetadata{ReportId: report.Id}
+	title := fmt.Sprintf("Revisamos %s que reportaste", targetName)
+	description := "Tomamos medidas sobre el contenido. Gracias por ayudarnos a cuidar la comunidad."
+	if decision.Status == models.ReportStatusDismissed {
+		description = "No encontramos una infracción de las normas de la comunidad."
+	}
+	for _, reporterID := range reporterIDs {
+		s.notify(reporterID, models.EventTypeReportResolved, title, description, metadata)
+	}
+
+	if report.TargetUserId != nil {
+		authorID := *report.TargetUserId
+		reason := "Tu contenido infringe las normas de la comunidad."
+		if decision.Note != "" {
+			reason = decision.Note
+		}
+		switch decision.AuthorAction {
+		case models.AuthorActionWarn:
+			s.notify(authorID, models.EventTypeModerationWarning, "Recibiste una advertencia de moderación", reason, metadata)
+		case models.AuthorActionSuspend:
+			s.notify(authorID, models.EventTypeModerationSuspended, "Tu cuenta fue suspendida", reason, metadata)
+		}
+	}
+
+	logger.Infof(moderationServiceComponent, "Reporte %d %s por %d (contenido: %s, autor: %s, %d denunciantes)",
+		report.Id, decision.Status, decision.AdminId, decision.ContentAction, decision.AuthorAction, len(reporterIDs))
+	return s.GetReport(report.Id)
+}
+
+// notify crea una notificación de moderación para userID.
+func (s *ModerationService) notify(userID int64, eventType, title, description string, metadata models.EventMetadata) {
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		logger.Errorf(moderationServiceComponent, "Error al serializar los metadatos de la notificación: %v", err)
+	}
+	notification := models.Event{
+		EventType:   eventType,
+		EventTitle:  title,
+		Description: description,
+		UserId:      userID,
+		Metadata:    metadataJSON,
+	}
+	if err := queries.CreateEvent(&notification); err != nil {
+		logger.Errorf(moderationServiceComponent, "No se pudo crear la notificación %s para el usuario %d: %v", eventType, userID, err)
+	}
+}
+
+// UnhideContent vuelve a mostrar un contenido ocultado por moderación.
+func (s *ModerationService) UnhideContent(targetType, targetID string) error {
+	if err := validateReportTarget(targetType, targetID); err != nil {
+		return err
+	}
+	if targetType == models.ReportTargetProfile {
+		return ErrModerationProfile
+	}
+	if err := queries.UnhideContent(targetType, targetID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrHiddenContentMissing
+		}
+		return err
+	}
+	return nil
+}
+
+// ReinstateUser reactiva una cuenta suspendida por moderación.
+func (s *ModerationService) ReinstateUser(userID int64) error {
+	if err := queries.ReinstateUser(userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotSuspended
+		}
+		return err
+	}
+	logger.Infof(moderationServiceComponent, "Cuenta %d reactivada", userID)
+	return nil
+}
+
+// CaptureReportContext captura el contexto de un reporte de mensaje que no lo tiene, por
+// ejemplo porque falló la captura automática. before y after en 0 usan la ventana por defecto.
+// El contexto ya capturado no se reemplaza.
+func (s *ModerationService) CaptureReportContext(reportID, adminID int64, req models.CaptureReportContextRequest) (*models.ReportContextBundle, error) {
+	if req.Before < 0 || req.Before > reportContextMaxMessages || req.After < 0 || req.After > reportContextMaxMessages {
+		return nil, ErrReportContextWindow
+	}
+	if req.Before == 0 {
+		req.Before = reportContextDefaultMessages
+	}
+	if req.After == 0 {
+		req.After = reportContextDefaultMessages
+	}
+
+	report, err := s.GetReport(reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.TargetType != models.ReportTargetMessage {
+		return nil, ErrReportContextInvalid
+	}
+	return s.captureContext(reportID, report.TargetId, &adminID, req.Before, req.After)
+}
+
+// captureContext copia los mensajes que rodean a messageID y los participantes de su chat y
+// los guarda como contexto del reporte. capturedBy es nil en la captura automática.
+func (s *ModerationService) captureContext(reportID int64, messageID string, capturedBy *int64, before, after int) (*models.ReportContextBundle, error) {
+	chatID, chatIDGroup, messages, err := queries.GetMessageContext(messageID, before, after)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReportContextInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el contexto del mensaje %s: %w", messageID, err)
+	}
+
+	// Participantes: los miembros actuales del chat y cualquier autor de la ventana que ya no
+	// lo sea (por ejemplo, quien salió del grupo).
+	memberIDs, err := queries.GetChatMemberIDs(chatID, chatIDGroup)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int64]bool, len(memberIDs))
+	for _, id := range memberIDs {
+		seen[id] = true
+	}
+	for _, message := range messages {
+		if !seen[message.SenderId] {
+			seen[message.SenderId] = true
+			memberIDs = append(memberIDs, message.SenderId)
+		}
+	}
+	participants, err := baseInfoList(memberIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := models.ReportContextBundle{
+		ReportId:       reportID,
+		MessageId:      messageID,
+		ChatId:         chatID,
+		ChatIdGroup:    chatIDGroup,
+		MessagesBefore: before,
+		MessagesAfter:  after,
+		Participants:   participants,
+		Messages:       messages,
+		CapturedBy:     capturedBy,
+	}
+	if err := queries.InsertReportContextBundle(bundle); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return nil, ErrReportContextExists
+		}
+		return nil, err
+	}
+	logger.Infof(moderationServiceComponent, "Contexto del reporte %d capturado: %d mensajes, %d participantes", reportID, len(messages), len(participants))
+	return s.GetReportContext(reportID)
+}
+
+// GetReportContext devuelve el contexto capturado de un reporte.
+func (s *ModerationService) GetReportContext(reportID int64) (*models.ReportContextBundle, error) {
+	bundle, err := queries.GetReportContextBundle(reportID)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := s.GetReport(reportID); err != nil {
+			return nil, err
+		}
+		return nil, ErrReportContextMissing
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el contexto del reporte %d: %w", reportID, err)
+	}
+	return bundle, nil
+}
+
+// ListImageReviews devuelve una página de la cola de revisión de imágenes. status vacío no
+// filtra.
+func (s *ModerationService) ListImageReviews(status string, page, pageSize int) (*models.PaginatedImageReviews, error) {
+	switch status {
+	case "", models.ImageReviewPending, models.ImageReviewApproved, models.ImageReviewRemoved:
+	default:
+		return nil, apperrors.New(apperrors.InvalidParam, "estado de revisión no válido")
+	}
+
+	reviews, total, err := queries.ListImageReviews(status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	totalPages := 0
+	if total > 0 {
+		totalPages = (total + pageSize - 1) / pageSize
+	}
+	return &models.PaginatedImageReviews{
+		Data: reviews,
+		Pagination: models.PaginationDetails{
+			TotalItems:  total,
+			TotalPages:  totalPages,
+			CurrentPage: page,
+			PageSize:    pageSize,
+		},
+	}, nil
+}
+
+// ResolveImageReview aprueba o elimina una imagen de la cola de revisión. Al eliminarla se
+// borran del almacenamiento la imagen y sus variantes y se avisa al autor.
+func (s *ModerationService) ResolveImageReview(reviewID, adminID int64, req models.ResolveImageReviewRequest) (*models.ImageReview, error) {
+	req.Note = strings.TrimSpace(req.Note)
+	if req.Action != models.ImageReviewApproved && req.Action != models.ImageReviewRemoved {
+		return nil, ErrImageReviewAction
+	}
+	if utf8.RuneCountInString(req.Note) > reportTextMaxLength {
+		return nil, ErrReportTextTooLong
+	}
+
+	fileNames, err := queries.ResolveImageReview(reviewID, adminID, req.Action, req.Note)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrImageReviewNotFound
+	}
+	if errors.Is(err, queries.ErrImageReviewClosed) {
+		return nil, ErrImageReviewClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, fileName := range fileNames {
+		if err := s.store.Delete(context.Background(), fileName); err != nil {
+			logger.Warnf(moderationServiceComponent, "No se pudo borrar la imagen eliminada %s: %v", fileName, err)
+		}
+	}
+
+	review, err := queries.GetImageReview(reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la revisión de la imagen %d: %w", reviewID, err)
+	}
+	if req.Action == models.ImageReviewRemoved {
+		reason := "Una de tus imágenes infringe las normas de la comunidad y fue eliminada."
+		if req.Note != "" {
+			reason = req.Note
+		}
+		s.notify(review.UserId, models.EventTypeImageRemoved, "Eliminamos una de tus imágenes", reason, models.EventMetadata{})
+	}
+	logger.Infof(moderationServiceComponent, "Imagen %s del usuario %d %s por %d", review.ContentId, review.UserId, req.Action, adminID)
+	return review, nil
+}
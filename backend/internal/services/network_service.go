@@ -0,0 +1,156 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+)
+
+// networkSuggestionPoolSize es el máximo de sugerencias que se calculan (y cachean) por
+// usuario; las páginas se sirven de esa lista.
+const networkSuggestionPoolSize = 100
+
+// Errores de negocio de la red de contactos.
+var (
+	ErrNetworkUserNotFound = apperrors.New(apperrors.NotFound, "usuario no encontrado")
+	ErrNetworkSelf         = apperrors.New(apperrors.InvalidParam, "no se pueden consultar los contactos en común con uno mismo")
+)
+
+// INetworkService define la interfaz del servicio de red de contactos.
+type INetworkService interface {
+	GetMutualContacts(userID, otherUserID int64, page, pageSize int) (*models.MutualContactsResponse, error)
+	GetSuggestions(userID int64, page, pageSize int) (*models.ContactSuggestionsResponse, error)
+	GetSummary(userID int64) (*models.NetworkSummary, error)
+}
+
+// NetworkService calcula los contactos en común, las sugerencias de contacto ("personas que
+// quizá conozcas") y el resumen de la red. Los cálculos se cachean por usuario porque recorren
+// la tabla Contact, que crece mucho más rápido que el número de usuarios.
+type NetworkService struct {
+	db *sql.DB
+}
+
+// NewNetworkService crea una nueva instancia de NetworkService.
+func NewNetworkService(db *sql.DB) INetworkService {
+	return &NetworkService{db: db}
+}
+
+// GetMutualContacts devuelve los contactos que userID y otherUserID tienen en común.
+func (s *NetworkService) GetMutualContacts(userID, otherUserID int64, page, pageSize int) (*models.MutualContactsResponse, error) {
+	if userID == otherUserID {
+		return nil, ErrNetworkSelf
+	}
+	if _, err := queries.GetAccountState(otherUserID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNetworkUserNotFound
+		}
+		return nil, err
+	}
+	// Con un bloqueo de por medio, el otro usuario no es visible.
+	blocked, err := queries.IsBlockedEither(userID, otherUserID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrNetworkUserNotFound
+	}
+
+	mine, err := queries.GetNetworkContactIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := queries.GetNetworkContactIDs(otherUserID)
+	if err != nil {
+		return nil, err
+	}
+	// Ambas listas están ordenadas: la intersección es un recorrido en paralelo.
+	mutual := []int64{}
+	for i, j := 0, 0; i < len(mine) && j < len(theirs); {
+		switch {
+		case mine[i] == theirs[j]:
+			mutual = append(mutual, mine[i])
+			i++
+			j++
+		case mine[i] < theirs[j]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	start, end := pageBounds(len(mutual), page, pageSize)
+	users, err := baseInfoList(mutual[start:end])
+	if err != nil {
+		return nil, err
+	}
+	return &models.MutualContactsResponse{
+		UserId:     otherUserID,
+		Data:       users,
+		Pagination: paginationDetails(len(mutual), page, pageSize),
+	}, nil
+}
+
+// GetSuggestions devuelve los contactos de los contactos del usuario con quienes todavía no
+// tiene relación, empezando por los que tienen más contactos en común.
+func (s *NetworkService) GetSuggestions(userID int64, page, pageSize int) (*models.ContactSuggestionsResponse, error) {
+	candidates, err := queries.GetContactSuggestionCandidates(userID, networkSuggestionPoolSize)
+	if err != nil {
+		return nil, err
+	}
+	total := len(candidates)
+	start, end := pageBounds(total, page, pageSize)
+	candidates = candidates[start:end]
+
+	ids := make([]int64, 0, len(candidates)*4)
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.UserId)
+		ids = append(ids, candidate.MutualIds...)
+	}
+	users, err := queries.GetUserBaseInfoByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]models.ContactSuggestion, 0, len(candidates))
+	for _, candidate := range candidates {
+		user, ok := users[candidate.UserId]
+		if !ok {
+			continue
+		}
+		suggestion := models.ContactSuggestion{User: *user, MutualCount: candidate.MutualCount, MutualContacts: []models.UserBaseInfo{}}
+		for _, id := range candidate.MutualIds {
+			if mutual, ok := users[id]; ok {
+				suggestion.MutualContacts = append(suggestion.MutualContacts, *mutual)
+			}
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return &models.ContactSuggestionsResponse{
+		Data:       suggestions,
+		Pagination: paginationDetails(total, page, pageSize),
+	}, nil
+}
+
+// GetSummary devuelve el resumen de la red del usuario.
+func (s *NetworkService) GetSummary(userID int64) (*models.NetworkSummary, error) {
+	return queries.GetNetworkSummary(userID)
+}
+
+// baseInfoList devuelve la información básica de los usuarios en el orden de ids, omitiendo
+// los que ya no existen.
+func baseInfoList(ids []int64) ([]models.UserBaseInfo, error) {
+	users, err := queries.GetUserBaseInfoByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]models.UserBaseInfo, 0, len(ids))
+	for _, id := range ids {
+		if user, ok := users[id]; ok {
+			list = append(list, *user)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,16 @@
+package services
+
+import "fmt"
+
+// groupedActorsText nombra a quienes originaron una notificación agrupada a partir del más
+// reciente: "Ana", "Ana y 1 persona más" o "Ana y 4 personas más".
+func groupedActorsText(latestName string, count int) string {
+	switch others := count - 1; {
+	case others == 1:
+		return latestName + " y 1 persona más"
+	case others > 1:
+		return fmt.Sprintf("%s y %d personas más", latestName, others)
+	default:
+		return latestName
+	}
+}
@@ -2,8 +2,12 @@ package services
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 )
 
@@ -50,3 +54,44 @@ func (s *NotificationService) MarkAsRead(notificationID int64, userID int64) err
 	logger.Successf("NOTIFICATION_SERVICE", "Notification %d successfully marked as read for user %d", notificationID, userID)
 	return nil
 }
+
+// SetRead marca como leídas (read=true) o no leídas las notificaciones ids del usuario y
+// avisa a sus dispositivos conectados por el outbox. Los ids de otros usuarios se ignoran.
+// Devuelve cuántas cambiaron de estado.
+func (s *NotificationService) SetRead(userID int64, ids []int64, read bool) (int64, error) {
+	affected, err := queries.SetNotificationsRead(userID, ids, read)
+	if err != nil {
+		logger.Errorf("NOTIFICATION_SERVICE", "%v", err)
+		return 0, err
+	}
+	if affected > 0 {
+		s.syncDevices(userID, models.NewNotificationSync(ids, read, false))
+	}
+	return affected, nil
+}
+
+// Delete borra las notificaciones ids del usuario y avisa a sus dispositivos conectados por el
+// outbox. Los ids de otros usuarios se ignoran. Devuelve cuántas se borraron.
+func (s *NotificationService) Delete(userID int64, ids []int64) (int64, error) {
+	deleted, err := queries.DeleteNotifications(userID, ids)
+	if err != nil {
+		logger.Errorf("NOTIFICATION_SERVICE", "%v", err)
+		return 0, err
+	}
+	if deleted > 0 {
+		s.syncDevices(userID, models.NewNotificationSync(ids, false, true))
+	}
+	return deleted, nil
+}
+
+// syncDevices deja en el outbox el read_sync para los dispositivos conectados del usuario. Si
+// falla solo se registra: el cambio ya está guardado y los clientes lo verán al recargar.
+func (s *NotificationService) syncDevices(userID int64, sync models.NotificationSync) {
+	payload, err := json.Marshal(sync)
+	if err == nil {
+		err = queries.EnqueueOutbox(s.DB, models.OutboxMessage{Topic: string(types.MessageTypeReadSync), UserId: userID, Payload: payload})
+	}
+	if err != nil {
+		logger.Warnf("NOTIFICATION_SERVICE", "No se pudo avisar a los dispositivos del usuario %d: %v", userID, err)
+	}
+}
@@ -21,13 +21,14 @@ import (
 
 // PDFUploadService encapsula la lógica para subir y procesar archivos PDF.
 type PDFUploadService struct {
-	db  *sql.DB
-	cfg *config.Config
+	db      *sql.DB
+	cfg     *config.Config
+	scanner IUploadScanService
 }
 
 // NewPDFUploadService crea una nueva instancia de PDFUploadService.
-func NewPDFUploadService(db *sql.DB, cfg *config.Config) *PDFUploadService {
-	return &PDFUploadService{db: db, cfg: cfg}
+func NewPDFUploadService(db *sql.DB, cfg *config.Config, scanner IUploadScanService) *PDFUploadService {
+	return &PDFUploadService{db: db, cfg: cfg, scanner: scanner}
 }
 
 // UploadPDFDetails contiene la información del PDF subido para la respuesta.
@@ -68,9 +69,10 @@ func (s *PDFUploadService) ProcessAndUploadPDF(ctx context.Context, userID int64
 		return nil, fmt.Errorf("el archivo no es un PDF válido. Tipo detectado: %s", kind.MIME.Value)
 	}
 
-	// Aquí se podrían añadir validaciones más profundas del contenido del PDF si se dispone
-	// de librerías especializadas para análisis de PDF (ej. buscar scripts maliciosos,
-	// verificar estructura, etc.). Por ahora, nos centramos en tipo y tamaño.
+	// El contenido malicioso (scripts, exploits conocidos) lo detecta el análisis de malware.
+	if err := s.scanner.Check(ctx, userID, "pdf", fileHeader.Filename, fileBytes); err != nil {
+		return nil, err
+	}
 	logger.Infof("ProcessAndUploadPDF", "Archivo validado como PDF. MIME: %s, Extension: %s", kind.MIME.Value, kind.Extension)
 
 	contentID := uuid.New().String()
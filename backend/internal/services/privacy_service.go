@@ -0,0 +1,403 @@
+package services
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/mailer"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const privacyServiceComponent = "PRIVACY_SERVICE"
+
+// privacyMaintenanceInterval es la frecuencia con la que se borran los ZIP caducados y se
+// retoman las solicitudes interrumpidas.
+const privacyMaintenanceInterval = time.Hour
+
+var (
+	ErrPrivacyRequestActive   = apperrors.New(apperrors.PrivacyRequestActive, "ya tienes una solicitud de este tipo en curso")
+	ErrPrivacyRequestNotFound = apperrors.New(apperrors.PrivacyRequestNotFound, "solicitud no encontrada")
+	ErrInvalidPassword        = apperrors.New(apperrors.PrivacyInvalidPassword, "la contraseña no es correcta")
+	ErrInvalidDeletionMode    = apperrors.New(apperrors.PrivacyInvalidMode, "modo de borrado inválido: use 'anonymize' o 'cascade'")
+	ErrDataExportNotReady     = apperrors.New(apperrors.DataExportNotReady, "la exportación todavía no está lista")
+	ErrDataExportExpired      = apperrors.New(apperrors.DataExportExpired, "la exportación ha caducado; solicita una nueva")
+	ErrLastSeenVisibility     = apperrors.New(apperrors.InvalidBody, "lastSeenVisibility debe ser 'everyone', 'contacts' o 'nobody'")
+)
+
+// IPrivacyService define la exportación de datos personales, el borrado de cuentas y las
+// preferencias de privacidad.
+type IPrivacyService interface {
+	RequestDataExport(userID int64) (*models.PrivacyRequest, error)
+	RequestAccountDeletion(userID int64, password, mode string) (*models.PrivacyRequest, error)
+	ListRequests(userID int64) ([]models.PrivacyRequest, error)
+	OpenDataExport(userID, requestID int64) (*os.File, error)
+	GetSettings(userID int64) (models.PrivacySettings, error)
+	UpdateSettings(userID int64, req models.UpdatePrivacySettingsRequest) (models.PrivacySettings, error)
+}
+
+// PrivacyService procesa en segundo plano las solicitudes de exportación y borrado y
+// avisa al usuario por correo cuando terminan.
+type PrivacyService struct {
+	db        *sql.DB
+	mailer    *mailer.Mailer
+	exportDir string
+	exportTTL time.Duration
+	// forgetAccountState descarta el estado de la cuenta que recuerda AuthMiddleware
+	// (middleware.ForgetAccountState), para que los tokens del usuario borrado dejen de
+	// valer de inmediato en esta instancia.
+	forgetAccountState func(userID int64)
+}
+
+// NewPrivacyService crea el servicio, retoma las solicitudes que quedaron sin terminar y
+// lanza el mantenimiento periódico de las exportaciones. forgetAccountState se llama tras
+// borrar una cuenta.
+func NewPrivacyService(db *sql.DB, cfg *config.Config, forgetAccountState func(userID int64)) IPrivacyService {
+	s := &PrivacyService{
+		db:                 db,
+		mailer:             mailer.New(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom),
+		exportDir:          cfg.DataExportDir,
+		exportTTL:          cfg.DataExportTTL,
+		forgetAccountState: forgetAccountState,
+	}
+	if !s.mailer.Enabled() {
+		logger.Warn(privacyServiceComponent, "SMTP no configurado: no se enviarán avisos de exportación ni de borrado de cuenta")
+	}
+	go s.maintenanceLoop()
+	return s
+}
+
+// maintenanceLoop retoma solicitudes interrumpidas y borra las exportaciones caducadas.
+func (s *PrivacyService) maintenanceLoop() {
+	s.resumeUnfinished()
+	s.removeExpiredExports()
+
+	ticker := time.NewTicker(privacyMaintenanceInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.resumeUnfinished()
+		s.removeExpiredExports()
+	}
+}
+
+func (s *PrivacyService) resumeUnfinished() {
+	requests, err := queries.GetUnfinishedPrivacyRequests()
+	if err != nil {
+		logger.Errorf(privacyServiceComponent, "No se pudieron obtener las solicitudes pendientes: %v", err)
+		return
+	}
+	for i := range requests {
+		s.process(&requests[i])
+	}
+}
+
+func (s *PrivacyService) removeExpiredExports() {
+	expired, err := queries.GetExpiredDataExports(time.Now())
+	if err != nil {
+		logger.Errorf(privacyServiceComponent, "No se pudieron obtener las exportaciones caducadas: %v", err)
+		return
+	}
+	for _, req := range expired {
+		if err := os.Remove(req.FilePath); err != nil && !os.IsNotExist(err) {
+			logger.Warnf(privacyServiceComponent, "No se pudo eliminar la exportación %d (%s): %v", req.Id, req.FilePath, err)
+			continue
+		}
+		if err := queries.ClearDataExportFile(req.Id); err != nil {
+			logger.Errorf(privacyServiceComponent, "%v", err)
+		}
+	}
+}
+
+// RequestDataExport registra una exportación y la genera en segundo plano.
+func (s *PrivacyService) RequestDataExport(userID int64) (*models.PrivacyRequest, error) {
+	email, _, err := queries.GetUserEmailAndPasswordHash(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo el usuario %d: %w", userID, err)
+	}
+	return s.createRequest(userID, models.PrivacyRequestExport, "", email)
+}
+
+// RequestAccountDeletion verifica la contraseña y programa el borrado de la cuenta.
+func (s *PrivacyService) RequestAccountDeletion(userID int64, password, mode string) (*models.PrivacyRequest, error) {
+	if mode == "" {
+		mode = models.DeletionModeAnonymize
+	}
+	if mode != models.DeletionModeAnonymize && mode != models.DeletionModeCascade {
+		return nil, ErrInvalidDeletionMode
+	}
+
+	email, hash, err := queries.GetUserEmailAndPasswordHash(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo el usuario %d: %w", userID, err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return nil, ErrInvalidPassword
+	}
+	return s.createRequest(userID, models.PrivacyRequestDeletion, mode, email)
+}
+
+func (s *PrivacyService) createRequest(userID int64, requestType, mode, email string) (*models.PrivacyRequest, error) {
+	id, created, err := queries.CreatePrivacyRequest(userID, requestType, mode, email)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrPrivacyRequestActive
+	}
+	req, err := queries.GetPrivacyRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof(privacyServiceComponent, "Solicitud %d (%s) registrada para el usuario %d", id, requestType, userID)
+
+	go s.process(req)
+	return req, nil
+}
+
+// ListRequests devuelve las solicitudes del usuario.
+func (s *PrivacyService) ListRequests(userID int64) ([]models.PrivacyRequest, error) {
+	return queries.GetPrivacyRequestsByUser(userID)
+}
+
+// GetSettings devuelve las preferencias de privacidad del usuario.
+func (s *PrivacyService) GetSettings(userID int64) (models.PrivacySettings, error) {
+	return queries.GetPrivacySettings(userID)
+}
+
+// UpdateSettings aplica los campos indicados sobre las preferencias actuales del usuario.
+// Los cambios no afectan a las visitas ya registradas; la visibilidad de la última conexión se
+// aplica desde la siguiente consulta o desconexión.
+func (s *PrivacyService) UpdateSettings(userID int64, req models.UpdatePrivacySettingsRequest) (models.PrivacySettings, error) {
+	if req.LastSeenVisibility != nil && !req.LastSeenVisibility.IsValid() {
+		return models.PrivacySettings{}, ErrLastSeenVisibility
+	}
+	settings, err := queries.GetPrivacySettings(userID)
+	if err != nil {
+		return settings, err
+	}
+	if req.ShareProfileViews != nil {
+		settings.ShareProfileViews = *req.ShareProfileViews
+	}
+	if req.TrackProfileViews != nil {
+		settings.TrackProfileViews = *req.TrackProfileViews
+	}
+	if req.LastSeenVisibility != nil {
+		settings.LastSeenVisibility = *req.LastSeenVisibility
+	}
+	if err := queries.SavePrivacySettings(userID, settings); err != nil {
+		return settings, err
+	}
+	logger.Infof(privacyServiceComponent, "Usuario %d actualizó su configuración de privacidad: %+v", userID, settings)
+	return settings, nil
+}
+
+// OpenDataExport abre el ZIP de una exportación completada del usuario.
+func (s *PrivacyService) OpenDataExport(userID, requestID int64) (*os.File, error) {
+	req, err := queries.GetPrivacyRequest(requestID)
+	if err == sql.ErrNoRows || (err == nil && (req.UserId != userID || req.Type != models.PrivacyRequestExport)) {
+		return nil, ErrPrivacyRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != models.PrivacyRequestCompleted {
+		return nil, ErrDataExportNotReady
+	}
+	if req.FilePath == "" || (req.ExpiresAt != nil && time.Now().After(*req.ExpiresAt)) {
+		return nil, ErrDataExportExpired
+	}
+
+	file, err := os.Open(req.FilePath)
+	if os.IsNotExist(err) {
+		return nil, ErrDataExportExpired
+	}
+	return file, err
+}
+
+// process reclama la solicitud y la ejecuta; otra instancia puede haberla reclamado antes.
+func (s *PrivacyService) process(req *models.PrivacyRequest) {
+	claimed, err := queries.ClaimPrivacyRequest(req.Id)
+	if err != nil {
+		logger.Errorf(privacyServiceComponent, "%v", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	switch req.Type {
+	case models.PrivacyRequestExport:
+		err = s.processExport(req)
+	case models.PrivacyRequestDeletion:
+		err = s.processDeletion(req)
+	default:
+		err = fmt.Errorf("tipo de solicitud desconocido: %s", req.Type)
+	}
+
+	if err != nil {
+		logger.Errorf(privacyServiceComponent, "Solicitud %d (%s) del usuario %d falló: %v", req.Id, req.Type, req.UserId, err)
+		if ferr := queries.FailPrivacyRequest(req.Id, err.Error()); ferr != nil {
+			logger.Errorf(privacyServiceComponent, "%v", ferr)
+		}
+	}
+}
+
+func (s *PrivacyService) processExport(req *models.PrivacyRequest) error {
+	data, err := queries.GetUserDataExport(req.UserId)
+	if err != nil {
+		return err
+	}
+
+	path, err := s.writeExportZip(req, data)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(s.exportTTL)
+	if err := queries.CompleteDataExport(req.Id, path, expiresAt); err != nil {
+		os.Remove(path)
+		return err
+	}
+	logger.Successf(privacyServiceComponent, "Exportación %d del usuario %d generada en %s", req.Id, req.UserId, path)
+
+	RecordAudit(nil, models.AuditLog{
+		ActorId:    &req.UserId,
+		Action:     models.AuditActionDataExported,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(req.UserId, 10),
+	}, map[string]interface{}{"requestId": req.Id})
+
+	s.notify(req.Email, "Tu exportación de datos está lista - Alumni USM", fmt.Sprintf(
+		`<p>La copia de tus datos personales está lista.</p>
+		<p>Puedes descargarla desde la sección de privacidad de tu cuenta hasta el %s.</p>`,
+		expiresAt.Format("02/01/2006 15:04")))
+	return nil
+}
+
+// writeExportZip escribe un JSON por sección en un ZIP con nombre no adivinable.
+func (s *PrivacyService) writeExportZip(req *models.PrivacyRequest, data map[string][]map[string]interface{}) (string, error) {
+	if err := os.MkdirAll(s.exportDir, 0o700); err != nil {
+		return "", fmt.Errorf("error creando el directorio de exportaciones: %w", err)
+	}
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	path := filepath.Join(s.exportDir, fmt.Sprintf("user-%d-export-%d-%s.zip", req.UserId, req.Id, hex.EncodeToString(suffix)))
+	tmpPath := path + ".tmp"
+
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("error creando el ZIP de exportación: %w", err)
+	}
+	zw := zip.NewWriter(file)
+
+	files := map[string]interface{}{
+		"export_info.json": map[string]interface{}{
+			"userId":      req.UserId,
+			"requestId":   req.Id,
+			"generatedAt": time.Now().UTC(),
+		},
+	}
+	for name, rows := range data {
+		files[name] = rows
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err == nil {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(content)
+		}
+		if err != nil {
+			zw.Close()
+			file.Close()
+			os.Remove(tmpPath)
+			return "", fmt.Errorf("error escribiendo %s en la exportación: %w", name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("error cerrando el ZIP de exportación: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("error cerrando el ZIP de exportación: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("error moviendo el ZIP de exportación: %w", err)
+	}
+	return path, nil
+}
+
+func (s *PrivacyService) processDeletion(req *models.PrivacyRequest) error {
+	// Las exportaciones de chats se borran antes: con el borrado en cascada se perdería la
+	// ruta de sus archivos en GCS.
+	if exports, err := queries.GetStoredChatExportsByUser(req.UserId); err == nil {
+		for i := range exports {
+			removeChatExportObject(&exports[i])
+		}
+	}
+
+	var err error
+	if req.DeletionMode == models.DeletionModeCascade {
+		err = queries.DeleteUserCascade(req.UserId)
+	} else {
+		err = queries.AnonymizeUser(req.UserId)
+	}
+	if err != nil {
+		return err
+	}
+	s.forgetAccountState(req.UserId)
+
+	// Las exportaciones previas también contienen datos personales.
+	if requests, err := queries.GetPrivacyRequestsByUser(req.UserId); err == nil {
+		for _, other := range requests {
+			if other.Type == models.PrivacyRequestExport && other.FilePath != "" {
+				os.Remove(other.FilePath)
+				queries.ClearDataExportFile(other.Id)
+			}
+		}
+	}
+
+	RecordAudit(nil, models.AuditLog{
+		ActorId:    &req.UserId,
+		Action:     models.AuditActionProfileDeleted,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(req.UserId, 10),
+	}, map[string]interface{}{"requestId": req.Id, "mode": req.DeletionMode})
+
+	s.notify(req.Email, "Tu cuenta ha sido eliminada - Alumni USM",
+		`<p>Hemos completado la eliminación de tu cuenta y de tus datos personales.</p>
+		<p>Si no solicitaste esta acción, contacta con el equipo de soporte.</p>`)
+
+	if err := queries.CompleteAccountDeletion(req.Id); err != nil {
+		return err
+	}
+	logger.Successf(privacyServiceComponent, "Cuenta del usuario %d eliminada (modo %s)", req.UserId, req.DeletionMode)
+	return nil
+}
+
+// notify envía el aviso por correo; un fallo no invalida la solicitud ya procesada.
+func (s *PrivacyService) notify(email, subject, body string) {
+	if email == "" || !s.mailer.Enabled() {
+		return
+	}
+	if err := s.mailer.Send(email, subject, body); err != nil {
+		logger.Warnf(privacyServiceComponent, "%v", err)
+	}
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/push"
+)
+
+const pushDeviceServiceComponent = "PUSH_DEVICE_SERVICE"
+
+// Errores de negocio del servicio de dispositivos push.
+var (
+	ErrPushProviderInvalid = apperrors.New(apperrors.PushDeviceInvalid, "proveedor no válido (FCM o APNS)")
+	ErrPushTokenInvalid    = apperrors.New(apperrors.PushDeviceInvalid, "token de dispositivo vacío o demasiado largo")
+	ErrPushDeviceNotFound  = apperrors.New(apperrors.PushDeviceNotFound, "dispositivo no encontrado")
+
+	ErrWebPushDisabled             = apperrors.New(apperrors.WebPushDisabled, "las notificaciones web push no están configuradas")
+	ErrWebPushSubscriptionInvalid  = apperrors.New(apperrors.WebPushSubscriptionInvalid, "suscripción web push inválida: se requieren endpoint https, keys.p256dh y keys.auth")
+	ErrWebPushSubscriptionNotFound = apperrors.New(apperrors.WebPushSubscriptionNotFound, "suscripción web push no encontrada")
+)
+
+// IPushDeviceService define la interfaz del servicio de dispositivos y preferencias de
+// notificaciones push.
+type IPushDeviceService interface {
+	ListDevices(userID int64) ([]models.PushDevice, error)
+	RegisterDevice(userID int64, req models.RegisterPushDeviceRequest) (*models.PushDevice, error)
+	DeleteDevice(userID, deviceID int64) error
+	GetPreferences(userID int64) (models.NotificationPreferences, error)
+	UpdatePreferences(userID int64, req models.UpdateNotificationPreferencesRequest) (models.NotificationPreferences, error)
+	GetWebPushConfig() models.WebPushConfigResponse
+	ListWebPushSubscriptions(userID int64) ([]models.WebPushSubscription, error)
+	RegisterWebPushSubscription(userID int64, req models.RegisterWebPushSubscriptionRequest, userAgent string) (*models.WebPushSubscription, error)
+	DeleteWebPushSubscription(userID, subscriptionID int64) error
+}
+
+// PushDeviceService gestiona los dispositivos que reciben notificaciones push y las
+// preferencias de notificaciones. El envío se hace desde el servidor WebSocket.
+type PushDeviceService struct {
+	db *sql.DB
+	// vapidPublicKey es la clave pública VAPID que usan los navegadores para suscribirse.
+	// Vacía si Web Push no está configurado.
+	vapidPublicKey string
+}
+
+// NewPushDeviceService crea una nueva instancia de PushDeviceService.
+func NewPushDeviceService(db *sql.DB, cfg *config.Config) IPushDeviceService {
+	service := &PushDeviceService{db: db}
+	if cfg.PushVAPIDPrivateKey != "" {
+		sender, err := push.NewWebPushSender(cfg.PushVAPIDPrivateKey, cfg.PushVAPIDSubject, cfg.PushTimeout)
+		if err != nil {
+			logger.Errorf(pushDeviceServiceComponent, "Web Push deshabilitado: %v", err)
+		} else {
+			service.vapidPublicKey = sender.PublicKey()
+		}
+	}
+	return service
+}
+
+// ListDevices devuelve los dispositivos registrados por el usuario.
+func (s *PushDeviceService) ListDevices(userID int64) ([]models.PushDevice, error) {
+	return queries.GetPushDevices(userID)
+}
+
+// RegisterDevice registra o actualiza el token de un dispositivo del usuario.
+func (s *PushDeviceService) RegisterDevice(userID int64, req models.RegisterPushDeviceRequest) (*models.PushDevice, error) {
+	req.Provider = strings.ToUpper(strings.TrimSpace(req.Provider))
+	req.Token = strings.TrimSpace(req.Token)
+	req.DeviceName = strings.TrimSpace(req.DeviceName)
+
+	if req.Provider != push.ProviderFCM && req.Provider != push.ProviderAPNs {
+		return nil, ErrPushProviderInvalid
+	}
+	if req.Token == "" || len(req.Token) > 255 {
+		return nil, ErrPushTokenInvalid
+	}
+	if utf8.RuneCountInString(req.DeviceName) > 100 {
+		req.DeviceName = string([]rune(req.DeviceName)[:100])
+	}
+
+	deviceID, err := queries.UpsertPushDevice(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof(pushDeviceServiceComponent, "Dispositivo %d (%s) registrado por el usuario %d", deviceID, req.Provider, userID)
+	return queries.GetPushDevice(userID, deviceID)
+}
+
+// DeleteDevice da de baja un dispositivo del usuario. El cliente debe llamarlo al cerrar
+// sesión para dejar de recibir notificaciones en ese dispositivo.
+func (s *PushDeviceService) DeleteDevice(userID, deviceID int64) error {
+	err := queries.DeletePushDevice(userID, deviceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPushDeviceNotFound
+	}
+	return err
+}
+
+// GetPreferences devuelve las preferencias de notificaciones del usuario.
+func (s *PushDeviceService) GetPreferences(userID int64) (models.NotificationPreferences, error) {
+	return queries.GetNotificationPreferences(userID)
+}
+
+// UpdatePreferences aplica los campos indicados sobre las preferencias actuales del usuario.
+func (s *PushDeviceService) UpdatePreferences(userID int64, req models.UpdateNotificationPreferencesRequest) (models.NotificationPreferences, error) {
+	prefs, err := queries.GetNotificationPreferences(userID)
+	if err != nil {
+		return prefs, err
+	}
+	fields := []struct {
+		value  *bool
+		target *bool
+	}{
+		{req.PushEnabled, &prefs.PushEnabled},
+		{req.ChatMessages, &prefs.ChatMessages},
+		{req.ChatPreviews, &prefs.ChatPreviews},
+		{req.ContactRequests, &prefs.ContactRequests},
+		{req.Community, &prefs.Community},
+		{req.Challenges, &prefs.Challenges},
+		{req.JobApplications, &prefs.JobApplications},
+		{req.SavedSearches, &prefs.SavedSearches},
+		{req.EmailDigest, &prefs.EmailDigest},
+	}
+	for _, field := range fields {
+		if field.value != nil {
+			*field.target = *field.value
+		}
+	}
+	if req.DndEnabled != nil {
+		prefs.DndEnabled = *req.DndEnabled
+	}
+	dndFields := []struct {
+		value  *string
+		target *string
+	}{
+		{req.DndStart, &prefs.DndStart},
+		{req.DndEnd, &prefs.DndEnd},
+		{req.DndTimezone, &prefs.DndTimezone},
+	}
+	for _, field := range dndFields {
+		if field.value != nil {
+			*field.target = strings.TrimSpace(*field.value)
+		}
+	}
+	if err := prefs.ValidateDnd(); err != nil {
+		return prefs, apperrors.New(apperrors.DndScheduleInvalid, err.Error())
+	}
+	if err := queries.SaveNotificationPreferences(userID, prefs); err != nil {
+		return prefs, err
+	}
+	logger.Infof(pushDeviceServiceComponent, "Usuario %d actualizó sus preferencias de notificaciones", userID)
+	return queries.GetNotificationPreferences(userID)
+}
+
+// GetWebPushConfig devuelve la clave pública VAPID que el navegador necesita para suscribirse.
+func (s *PushDeviceService) GetWebPushConfig() models.WebPushConfigResponse {
+	return models.WebPushConfigResponse{Enabled: s.vapidPublicKey != "", PublicKey: s.vapidPublicKey}
+}
+
+// ListWebPushSubscriptions devuelve las suscripciones Web Push del usuario.
+func (s *PushDeviceService) ListWebPushSubscriptions(userID int64) ([]models.WebPushSubscription, error) {
+	return queries.GetWebPushSubscriptions(userID)
+}
+
+// RegisterWebPushSubscription registra o actualiza la suscripción Web Push de un navegador del
+// usuario. userAgent identifica el navegador en el listado de suscripciones.
+func (s *PushDeviceService) RegisterWebPushSubscription(userID int64, req models.RegisterWebPushSubscriptionRequest, userAgent string) (*models.WebPushSubscription, error) {
+	if s.vapidPublicKey == "" {
+		return nil, ErrWebPushDisabled
+	}
+	subscription := models.WebPushSubscription{
+		Endpoint:  strings.TrimSpace(req.Endpoint),
+		P256dh:    strings.TrimSpace(req.Keys.P256dh),
+		Auth:      strings.TrimSpace(req.Keys.Auth),
+		UserAgent: userAgent,
+	}
+	endpoint, err := url.Parse(subscription.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" || len(subscription.Endpoint) > 2048 {
+		return nil, ErrWebPushSubscriptionInvalid
+	}
+	if subscription.P256dh == "" || len(subscription.P256dh) > 128 || subscription.Auth == "" || len(subscription.Auth) > 64 {
+		return nil, ErrWebPushSubscriptionInvalid
+	}
+	if utf8.RuneCountInString(subscription.UserAgent) > 255 {
+		subscription.UserAgent = string([]rune(subscription.UserAgent)[:255])
+	}
+
+	subscriptionID, err := queries.UpsertWebPushSubscription(userID, subscription)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof(pushDeviceServiceComponent, "Suscripción web push %d (%s) registrada por el usuario %d", subscriptionID, endpoint.Host, userID)
+	return queries.GetWebPushSubscription(userID, subscriptionID)
+}
+
+// DeleteWebPushSubscription da de baja una suscripción Web Push del usuario.
+func (s *PushDeviceService) DeleteWebPushSubscription(userID, subscriptionID int64) error {
+	err := queries.DeleteWebPushSubscription(userID, subscriptionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrWebPushSubscriptionNotFound
+	}
+	return err
+}
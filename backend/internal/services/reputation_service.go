@@ -2,38 +2,113 @@ package services
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/go-sql-driver/mysql"
 )
 
 const reputationServiceComponent = "REPUTATION_SERVICE"
 
+const (
+	// leaderboardRefreshInterval define cada cuánto se recalcula el ranking en memoria.
+	leaderboardRefreshInterval = 5 * time.Minute
+	// leaderboardMaxEntries limita el número de posiciones que se mantienen en caché por grupo.
+	leaderboardMaxEntries = 1000
+)
+
+// Errores de negocio del servicio de reputación. Su código del catálogo determina la
+// respuesta HTTP y WebSocket.
+var (
+	ErrUserNotFound            = apperrors.New(apperrors.ReputationUserMissing, "usuario no encontrado")
+	ErrInvalidLeaderboardGroup = apperrors.New(apperrors.InvalidParam, "tipo de ranking no válido, use 'students' o 'companies'")
+	ErrMissingCommunityEvent   = apperrors.New(apperrors.ReviewInvalid, "el campo 'communityEventId' es obligatorio")
+	ErrMissingReviewee         = apperrors.New(apperrors.ReviewInvalid, "el campo 'revieweeId' es obligatorio")
+	ErrSelfReview              = apperrors.New(apperrors.ReviewSelf, "un usuario no puede calificarse a sí mismo")
+	ErrInvalidRating           = apperrors.New(apperrors.ReviewInvalid, "la calificación debe estar entre 0 y 5")
+	ErrInvalidInteractionType  = apperrors.New(apperrors.ReviewInvalid, "tipo de interacción no válido")
+	ErrNoEventInteraction      = apperrors.New(apperrors.ReviewNoInteraction, "no existe una interacción entre ambos usuarios en el evento indicado")
+	ErrReviewAlreadyExists     = apperrors.New(apperrors.ReviewAlreadyExists, "ya has calificado a este usuario para este evento")
+)
+
+// validInteractionTypes refleja los valores del ENUM ReputationReview.InteractionType.
+var validInteractionTypes = map[string]struct{}{
+	"ENTREVISTA":            {},
+	"MENTORIA":              {},
+	"PROYECTO_COLABORATIVO": {},
+	"EVENTO":                {},
+	"POSTULACION_EMPLEO":    {},
+	"DESAFIO_COMPLETADO":    {},
+}
+
 // IReputationService define la interfaz para el servicio de reputación.
 type IReputationService interface {
-	CreateReview(reviewerID int64, req models.CreateReviewRequest) error
+	CreateReview(reviewerID int64, req models.CreateReviewRequest) (*models.Event, error)
+	GetReputationSummary(userID int64) (*models.ReputationSummary, error)
+	GetLeaderboard(group string, page, pageSize int) (*models.PaginatedLeaderboardResponse, error)
+}
+
+// leaderboardSnapshot es una copia en memoria del ranking de un grupo.
+type leaderboardSnapshot struct {
+	entries     []models.LeaderboardEntry
+	refreshedAt time.Time
 }
 
 // ReputationService implementa la lógica de negocio para el sistema de reputación.
 type ReputationService struct {
 	db *sql.DB
+
+	leaderboardMu sync.RWMutex
+	leaderboards  map[string]leaderboardSnapshot
 }
 
 // NewReputationService crea una nueva instancia de ReputationService.
+// Inicia una goroutine que refresca periódicamente el ranking en caché.
 func NewReputationService(db *sql.DB) IReputationService {
-	return &ReputationService{db: db}
+	s := &ReputationService{
+		db:           db,
+		leaderboards: make(map[string]leaderboardSnapshot),
+	}
+	go s.leaderboardRefreshLoop()
+	return s
 }
 
-// CreateReview gestiona la creación de una nueva reseña, calculando los RP
-// y guardando el registro en la base de datos.
-func (s *ReputationService) CreateReview(reviewerID int64, req models.CreateReviewRequest) error {
-	if req.Rating < 0 || req.Rating > 5 {
-		return errors.New("la calificación debe estar entre 0 y 5")
+// CreateReview valida y registra una nueva reseña, calculando los RP y notificando al revisado.
+// La reseña solo se acepta si ambos usuarios interactuaron a través del evento comunitario
+// referenciado. Devuelve la notificación creada para el revisado (nil si no pudo crearse).
+func (s *ReputationService) CreateReview(reviewerID int64, req models.CreateReviewRequest) (*models.Event, error) {
+	if err := validateReviewRequest(reviewerID, req); err != nil {
+		return nil, err
+	}
+
+	reviewer, err := queries.GetUserByID(s.db, reviewerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("error al obtener el revisor %d: %w", reviewerID, err)
+	}
+
+	interacted, err := queries.HasEventInteraction(req.CommunityEventId, reviewerID, req.RevieweeID)
+	if err != nil {
+		logger.Errorf(reputationServiceComponent, "Error al validar la participación en el evento %d: %v", req.CommunityEventId, err)
+		return nil, fmt.Errorf("error interno al validar la reseña: %w", err)
+	}
+	if !interacted {
+		logger.Warnf(reputationServiceComponent, "Reseña rechazada: %d y %d no interactuaron en el evento %d", reviewerID, req.RevieweeID, req.CommunityEventId)
+		return nil, ErrNoEventInteraction
 	}
 
-	pointsRP := s.convertStarsToRP(req.Rating)
+	pointsRP := starsToRP(req.Rating)
 
 	// Aplicar el bono si la condición se cumple.
 	// El bono de "3 estrellas extra" equivale a 25 RP.
@@ -41,16 +116,13 @@ func (s *ReputationService) CreateReview(reviewerID int64, req models.CreateRevi
 		pointsRP += 25
 	}
 
-	// TODO: Idealmente, esto usaría sqlc para una mayor seguridad de tipos.
-	// Por ahora, se usa una consulta directa.
-	query := `
-        INSERT INTO ReputationReview (ReviewerId, RevieweeId, CommunityEventId, PointsRP, Rating, Comment, InteractionType)
-        VALUES (?, ?, ?, ?, ?, ?, ?)`
-
-	_, err := s.db.Exec(query, reviewerID, req.RevieweeID, req.CommunityEventId, pointsRP, req.Rating, req.Comment, req.InteractionType)
-	if err != nil {
+	if _, err := queries.CreateReputationReview(reviewerID, req, pointsRP); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return nil, ErrReviewAlreadyExists
+		}
 		logger.Errorf(reputationServiceComponent, "Error al insertar la reseña en la base de datos: %v", err)
-		return fmt.Errorf("error interno al guardar la reseña: %w", err)
+		return nil, fmt.Errorf("error interno al guardar la reseña: %w", err)
 	}
 
 	logger.Infof(reputationServiceComponent, "Reseña creada exitosamente por %d para %d con %d RP", reviewerID, req.RevieweeID, pointsRP)
@@ -58,11 +130,79 @@ func (s *ReputationService) CreateReview(reviewerID int64, req models.CreateRevi
 	// TODO: Aquí se podría disparar un evento para recalcular el Nivel de Reputación
 	// del usuario 'RevieweeId' de forma asíncrona.
 
+	return s.notifyReviewee(reviewer, req), nil
+}
+
+// validateReviewRequest aplica las validaciones de formato de una reseña.
+func validateReviewRequest(reviewerID int64, req models.CreateReviewRequest) error {
+	if req.CommunityEventId == 0 {
+		return ErrMissingCommunityEvent
+	}
+	if req.RevieweeID == 0 {
+		return ErrMissingReviewee
+	}
+	if reviewerID == req.RevieweeID {
+		return ErrSelfReview
+	}
+	if req.Rating < 0 || req.Rating > 5 {
+		return ErrInvalidRating
+	}
+	if req.InteractionType != "" {
+		if _, ok := validInteractionTypes[req.InteractionType]; !ok {
+			return ErrInvalidInteractionType
+		}
+	}
 	return nil
 }
 
-// convertStarsToRP convierte una calificación de 0-5 estrellas a Puntos de Reputación (RP).
-func (s *ReputationService) convertStarsToRP(rating float64) int {
+// notifyReviewee crea la notificación para el usuario calificado. Si la reseña la emite una
+// empresa, se invita al estudiante a calificarla de vuelta; si la emite un estudiante, se
+// informa a la empresa. Un fallo al notificar no invalida la reseña ya guardada.
+func (s *ReputationService) notifyReviewee(reviewer models.User, req models.CreateReviewRequest) *models.Event {
+	metadataJSON, err := json.Marshal(models.EventMetadata{
+		CommunityEventId: req.CommunityEventId,
+		ReviewerId:       reviewer.Id,
+		RevieweeId:       req.RevieweeID,
+	})
+	if err != nil {
+		logger.Errorf(reputationServiceComponent, "Error al serializar los metadatos de la notificación: %v", err)
+	}
+
+	notification := models.Event{
+		UserId:      req.RevieweeID,
+		OtherUserId: sql.NullInt64{Int64: reviewer.Id, Valid: true},
+		Metadata:    metadataJSON,
+	}
+
+	if reviewer.RoleId == int(models.RoleBusiness) {
+		companyName, err := queries.GetCompanyNameByID(reviewer.Id)
+		if err != nil {
+			logger.Warnf(reputationServiceComponent, "No se pudo obtener el nombre de la empresa para el revisor %d: %v", reviewer.Id, err)
+			companyName = "Una empresa"
+		}
+		notification.EventType = "COMPANY_REVIEW_PENDING"
+		notification.EventTitle = fmt.Sprintf("Valora tu experiencia con %s", companyName)
+		notification.Description = "Ahora puedes calificar a la empresa que te ha evaluado. Tu opinión es importante."
+		notification.ActionRequired = true
+	} else {
+		reviewerName := strings.TrimSpace(reviewer.FirstName.String + " " + reviewer.LastName.String)
+		if reviewerName == "" {
+			reviewerName = "Un estudiante"
+		}
+		notification.EventType = "REVIEW_CREATED_BY_STUDENT"
+		notification.EventTitle = fmt.Sprintf("%s ha valorado tu empresa.", reviewerName)
+		notification.Description = fmt.Sprintf("Has recibido una nueva calificación de %.1f estrellas.", req.Rating)
+	}
+
+	if err := queries.CreateEvent(&notification); err != nil {
+		logger.Errorf(reputationServiceComponent, "No se pudo crear la notificación de reseña para el usuario %d: %v", req.RevieweeID, err)
+		return nil
+	}
+	return &notification
+}
+
+// starsToRP convierte una calificación de 0-5 estrellas a Puntos de Reputación (RP).
+func starsToRP(rating float64) int {
 	switch {
 	case rating >= 5:
 		return 100
@@ -78,3 +218,109 @@ func (s *ReputationService) convertStarsToRP(rating float64) int {
 		return 0
 	}
 }
+
+// GetReputationSummary agrega la reputación de un usuario: RP total, calificación promedio
+// general y por tipo de interacción, y su percentil dentro de su grupo (estudiantes o empresas).
+func (s *ReputationService) GetReputationSummary(userID int64) (*models.ReputationSummary, error) {
+	user, err := queries.GetUserByID(s.db, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("error al obtener el usuario %d: %w", userID, err)
+	}
+
+	summary, err := queries.GetReputationSummaryByUserID(userID)
+	if err != nil {
+		logger.Errorf(reputationServiceComponent, "Error al obtener el resumen de reputación del usuario %d: %v", userID, err)
+		return nil, err
+	}
+
+	byInteraction, err := queries.GetAverageRatingByInteractionType(userID)
+	if err != nil {
+		logger.Errorf(reputationServiceComponent, "Error al obtener calificaciones por interacción del usuario %d: %v", userID, err)
+		return nil, err
+	}
+	summary.ByInteraction = byInteraction
+
+	if summary.ReviewCount > 0 {
+		group := queries.LeaderboardGroupForRole(user.RoleId)
+		percentile, err := queries.GetReputationPercentile(group, summary.TotalPointsRP)
+		if err != nil {
+			logger.Errorf(reputationServiceComponent, "Error al calcular el percentil del usuario %d: %v", userID, err)
+			return nil, err
+		}
+		summary.PercentileRank = percentile
+	}
+
+	return summary, nil
+}
+
+// GetLeaderboard devuelve una página del ranking de reputación de un grupo.
+// El ranking se sirve desde la caché en memoria; si aún no existe, se calcula en el momento.
+func (s *ReputationService) GetLeaderboard(group string, page, pageSize int) (*models.PaginatedLeaderboardResponse, error) {
+	if !queries.IsValidLeaderboardGroup(group) {
+		return nil, ErrInvalidLeaderboardGroup
+	}
+
+	s.leaderboardMu.RLock()
+	snapshot, ok := s.leaderboards[group]
+	s.leaderboardMu.RUnlock()
+
+	if !ok {
+		var err error
+		snapshot, err = s.refreshLeaderboard(group)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	total := len(snapshot.entries)
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+
+	return &models.PaginatedLeaderboardResponse{
+		Type:         group,
+		CurrentPage:  page,
+		PageSize:     pageSize,
+		TotalPages:   int(math.Ceil(float64(total) / float64(pageSize))),
+		TotalRecords: total,
+		RefreshedAt:  snapshot.refreshedAt,
+		Entries:      snapshot.entries[start:end],
+	}, nil
+}
+
+// refreshLeaderboard recalcula el ranking de un grupo y lo guarda en la caché.
+func (s *ReputationService) refreshLeaderboard(group string) (leaderboardSnapshot, error) {
+	entries, err := queries.GetReputationLeaderboard(group, leaderboardMaxEntries)
+	if err != nil {
+		logger.Errorf(reputationServiceComponent, "Error al recalcular el ranking '%s': %v", group, err)
+		return leaderboardSnapshot{}, err
+	}
+
+	snapshot := leaderboardSnapshot{entries: entries, refreshedAt: time.Now().UTC()}
+	s.leaderboardMu.Lock()
+	s.leaderboards[group] = snapshot
+	s.leaderboardMu.Unlock()
+	return snapshot, nil
+}
+
+// leaderboardRefreshLoop recalcula todos los rankings cada leaderboardRefreshInterval.
+func (s *ReputationService) leaderboardRefreshLoop() {
+	ticker := time.NewTicker(leaderboardRefreshInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, group := range []string{queries.LeaderboardStudents, queries.LeaderboardCompanies} {
+			if _, err := s.refreshLeaderboard(group); err == nil {
+				logger.Debugf(reputationServiceComponent, "Ranking '%s' recalculado", group)
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/taxonomy"
+)
+
+const savedSearchServiceComponent = "SAVED_SEARCH_SERVICE"
+
+// Límites de las búsquedas guardadas.
+const (
+	savedSearchMaxPerUser     = 20
+	savedSearchNameMaxLength  = 100
+	savedSearchQueryMaxLength = 255
+	savedSearchMaxKeywords    = 10
+	savedSearchFieldMaxLength = 100 // Ubicación, carrera, universidad y cada habilidad
+	savedSearchMaxSkills      = 10
+)
+
+// savedSearchPostTypes son los tipos de publicación admitidos en el filtro postType.
+var savedSearchPostTypes = map[string]bool{
+	"EVENTO": true, "NOTICIA": true, "ARTICULO": true, "ANUNCIO": true,
+	"MULTIMEDIA": true, "DESAFIO": true, "DISCUSION": true,
+}
+
+// Errores de negocio de las búsquedas guardadas.
+var (
+	ErrSavedSearchNotFound = apperrors.New(apperrors.SavedSearchNotFound, "la búsqueda guardada no existe")
+	ErrSavedSearchLimit    = apperrors.New(apperrors.SavedSearchLimit, fmt.Sprintf("como máximo %d búsquedas guardadas", savedSearchMaxPerUser))
+	ErrSavedSearchName     = apperrors.New(apperrors.SavedSearchInvalid, fmt.Sprintf("name es obligatorio y tiene como máximo %d caracteres", savedSearchNameMaxLength))
+	ErrSavedSearchType     = apperrors.New(apperrors.SavedSearchInvalid, "searchType debe ser JOBS o PEOPLE")
+	ErrSavedSearchQuery    = apperrors.New(apperrors.SavedSearchInvalid, fmt.Sprintf("query tiene como máximo %d caracteres y %d palabras", savedSearchQueryMaxLength, savedSearchMaxKeywords))
+	ErrSavedSearchEmpty    = apperrors.New(apperrors.SavedSearchInvalid, "la búsqueda necesita al menos una palabra clave o un filtro")
+	ErrSavedSearchFilters  = apperrors.New(apperrors.SavedSearchInvalid, fmt.Sprintf("filtros inválidos: cada texto tiene como máximo %d caracteres y hay como máximo %d habilidades", savedSearchFieldMaxLength, savedSearchMaxSkills))
+	ErrSavedSearchPostType = apperrors.New(apperrors.SavedSearchInvalid, "postType no es un tipo de publicación válido")
+	ErrSavedSearchRole     = apperrors.New(apperrors.SavedSearchInvalid, "roleId debe ser 1 (estudiante), 2 (egresado) o 3 (empresa)")
+)
+
+// ISavedSearchService define la interfaz del servicio de búsquedas guardadas.
+type ISavedSearchService interface {
+	List(userID int64) ([]models.SavedSearch, error)
+	Create(userID int64, req models.SavedSearchRequest) (*models.SavedSearch, error)
+	Update(id, userID int64, req models.SavedSearchRequest) (*models.SavedSearch, error)
+	Delete(id, userID int64) error
+}
+
+// SavedSearchService gestiona las búsquedas guardadas de los usuarios. Las alertas las genera
+// el job del servidor WebSocket a partir de la marca LastSeenItemId: al crear o editar una
+// búsqueda la marca se lleva al último elemento existente, así que solo se avisa de lo nuevo.
+type SavedSearchService struct {
+	db     *sql.DB
+	skills ISkillService
+}
+
+// NewSavedSearchService crea una nueva instancia de SavedSearchService.
+func NewSavedSearchService(db *sql.DB, skills ISkillService) ISavedSearchService {
+	return &SavedSearchService{db: db, skills: skills}
+}
+
+// List devuelve las búsquedas guardadas del usuario.
+func (s *SavedSearchService) List(userID int64) ([]models.SavedSearch, error) {
+	return queries.ListSavedSearches(userID)
+}
+
+// Create guarda una búsqueda nueva.
+func (s *SavedSearchService) Create(userID int64, req models.SavedSearchRequest) (*models.SavedSearch, error) {
+	search, err := s.validate(req)
+	if err != nil {
+		return nil, err
+	}
+	count, err := queries.CountSavedSearches(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= savedSearchMaxPerUser {
+		return nil, ErrSavedSearchLimit
+	}
+
+	search.UserId = userID
+	if search.LastSeenItemId, err = queries.GetSavedSearchMaxItemID(search.SearchType, time.Now()); err != nil {
+		return nil, err
+	}
+	id, err := queries.InsertSavedSearch(search)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof(savedSearchServiceComponent, "Búsqueda %d (%s) guardada por el usuario %d", id, search.SearchType, userID)
+	return queries.GetSavedSearch(id, userID)
+}
+
+// Update reemplaza los criterios de una búsqueda guardada del usuario.
+func (s *SavedSearchService) Update(id, userID int64, req models.SavedSearchRequest) (*models.SavedSearch, error) {
+	search, err := s.validate(req)
+	if err != nil {
+		return nil, err
+	}
+	search.Id = id
+	search.UserId = userID
+	if search.LastSeenItemId, err = queries.GetSavedSearchMaxItemID(search.SearchType, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := queries.UpdateSavedSearch(search); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSavedSearchNotFound
+		}
+		return nil, err
+	}
+	return queries.GetSavedSearch(id, userID)
+}
+
+// Delete elimina una búsqueda guardada del usuario.
+func (s *SavedSearchService) Delete(id, userID int64) error {
+	deleted, err := queries.DeleteSavedSearch(id, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+// validate comprueba los límites de la búsqueda y normaliza sus textos. Las habilidades se
+// guardan con el nombre del catálogo y sin repetir; los filtros de otro tipo de búsqueda se
+// descartan.
+func (s *SavedSearchService) validate(req models.SavedSearchRequest) (models.SavedSearch, error) {
+	search := models.SavedSearch{
+		Name:          strings.TrimSpace(req.Name),
+		SearchType:    strings.ToUpper(strings.TrimSpace(req.SearchType)),
+		Query:         strings.Join(strings.Fields(req.Query), " "),
+		AlertsEnabled: req.AlertsEnabled == nil || *req.AlertsEnabled,
+	}
+	if search.Name == "" || utf8.RuneCountInString(search.Name) > savedSearchNameMaxLength {
+		return search, ErrSavedSearchName
+	}
+	if search.SearchType != models.SavedSearchTypeJobs && search.SearchType != models.SavedSearchTypePeople {
+		return search, ErrSavedSearchType
+	}
+	if utf8.RuneCountInString(search.Query) > savedSearchQueryMaxLength || len(strings.Fields(search.Query)) > savedSearchMaxKeywords {
+		return search, ErrSavedSearchQuery
+	}
+
+	filters := models.SavedSearchFilters{Location: strings.TrimSpace(req.Filters.Location)}
+	texts := []string{filters.Location}
+	if search.SearchType == models.SavedSearchTypeJobs {
+		filters.PostType = strings.ToUpper(strings.TrimSpace(req.Filters.PostType))
+		if filters.PostType != "" && !savedSearchPostTypes[filters.PostType] {
+			return search, ErrSavedSearchPostType
+		}
+	} else {
+		filters.RoleId = req.Filters.RoleId
+		if filters.RoleId != 0 && filters.RoleId != int(models.RoleStudent) && filters.RoleId != int(models.RoleEgresado) && filters.RoleId != int(models.RoleBusiness) {
+			return search, ErrSavedSearchRole
+		}
+		filters.Degree = strings.TrimSpace(req.Filters.Degree)
+		filters.University = strings.TrimSpace(req.Filters.University)
+		texts = append(texts, filters.Degree, filters.University)
+	}
+	for _, text := range texts {
+		if utf8.RuneCountInString(text) > savedSearchFieldMaxLength {
+			return search, ErrSavedSearchFilters
+		}
+	}
+
+	if len(req.Filters.Skills) > savedSearchMaxSkills {
+		return search, ErrSavedSearchFilters
+	}
+	seen := make(map[string]bool, len(req.Filters.Skills))
+	for _, skill := range req.Filters.Skills {
+		skill = strings.TrimSpace(skill)
+		if skill == "" || utf8.RuneCountInString(skill) > savedSearchFieldMaxLength {
+			return search, ErrSavedSearchFilters
+		}
+		name, _ := s.skills.CanonicalizeSkill(skill)
+		if key := taxonomy.Normalize(name); !seen[key] {
+			seen[key] = true
+			filters.Skills = append(filters.Skills, name)
+		}
+	}
+	search.Filters = filters
+
+	// Sin criterios la búsqueda coincidiría con todo y avisaría de cada elemento nuevo.
+	if search.Query == "" && filters.PostType == "" && filters.Location == "" && filters.RoleId == 0 &&
+		filters.Degree == "" && filters.University == "" && len(filters.Skills) == 0 {
+		return search, ErrSavedSearchEmpty
+	}
+	return search, nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/fieldcrypt"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/phonetic"
 )
@@ -50,9 +51,10 @@ func (s *SearchService) UniversalSearch(ctx context.Context, params models.Unive
 			eventConditions = append(eventConditions, "(ce.dmeta_title_primary LIKE ? OR ce.dmeta_title_secondary LIKE ?)")
 			eventArgs = append(eventArgs, primaryKey+"%", secondaryKey+"%")
 		}
-		// Búsqueda LIKE tradicional
-		userTextSearchConditions = append(userTextSearchConditions, "(u.FirstName LIKE ? OR u.LastName LIKE ? OR u.UserName LIKE ? OR u.RIF LIKE ? OR u.CompanyName LIKE ?)")
-		userArgs = append(userArgs, likeQuery, likeQuery, likeQuery, likeQuery, likeQuery)
+		// Búsqueda LIKE tradicional. El RIF está cifrado: se busca exacto por su índice ciego y
+		// con LIKE solo en las filas que aún están en texto plano.
+		userTextSearchConditions = append(userTextSearchConditions, "(u.FirstName LIKE ? OR u.LastName LIKE ? OR u.UserName LIKE ? OR u.RIFHash = ? OR (u.RIFHash IS NULL AND u.RIF LIKE ?) OR u.CompanyName LIKE ?)")
+		userArgs = append(userArgs, likeQuery, likeQuery, likeQuery, fieldcrypt.Index(queries.FieldRIF, params.Query), likeQuery, likeQuery)
 
 		// Búsqueda en educación (solo para talentos)
 		userTextSearchConditions = append(userTextSearchConditions, "EXISTS (SELECT 1 FROM Education e WHERE e.PersonId = u.Id AND e.Degree LIKE ?)")
@@ -71,16 +73,17 @@ func (s *SearchService) UniversalSearch(ctx context.Context, params models.Unive
 		len(params.Skills) > 0 ||
 		len(params.Languages) > 0 ||
 		params.YearsOfExperienceMin > 0 ||
-		params.YearsOfExperienceMax > 0
+		params.YearsOfExperienceMax > 0 ||
+		params.VerifiedOnly
 
 	// === CONSTRUIR FILTROS DE USUARIO ===
 	if params.Role != "" {
 		var roleId int
 		switch params.Role {
 		case "student":
-			roleId = 1
+			roleId = int(models.RoleStudent)
 		case "graduate":
-			roleId = 2
+			roleId = int(models.RoleEgresado)
 		}
 
 		if roleId > 0 {
@@ -101,6 +104,9 @@ func (s *SearchService) UniversalSearch(ctx context.Context, params models.Unive
 		userConditions = append(userConditions, "u.Location LIKE ?")
 		userArgs = append(userArgs, "%"+params.Location+"%")
 	}
+	if params.VerifiedOnly {
+		userConditions = append(userConditions, queries.VerifiedStudentCondition("u.Id"))
+	}
 	// ... (Aquí se pueden añadir más filtros de usuario como skills, graduation_year, etc.)
 
 	// === CONSTRUIR FILTROS DE EVENTOS (solo si no es una búsqueda de talento) ===
@@ -114,6 +120,24 @@ func (s *SearchService) UniversalSearch(ctx context.Context, params models.Unive
 		eventArgs = []interface{}{}
 	}
 
+	// Los usuarios con un bloqueo con quien busca, y sus publicaciones, no aparecen.
+	if params.ViewerID > 0 {
+		userConditions = append(userConditions, queries.NotBlockedCondition("u.Id"))
+		userArgs = append(userArgs, params.ViewerID, params.ViewerID)
+		if !isTalentOnlySearch {
+			eventConditions = append(eventConditions, queries.NotBlockedCondition("ce.CreatedByUserId"))
+			eventArgs = append(eventArgs, params.ViewerID, params.ViewerID)
+		}
+	}
+
+	// Con una institución solo aparecen sus usuarios y publicaciones y los que no son de ninguna.
+	if params.TenantID > 0 {
+		userConditions = append(userConditions, queries.TenantCondition("u.TenantId", params.TenantID))
+		if !isTalentOnlySearch {
+			eventConditions = append(eventConditions, queries.TenantCondition("ce.TenantId", params.TenantID))
+		}
+	}
+
 	// === CONSTRUIR CONSULTAS FINALES ===
 	userQuery := "SELECT 'user' as type, u.Id, u.CreatedAt, u.RoleId FROM User u"
 	if len(userConditions) > 0 {
@@ -124,7 +148,9 @@ func (s *SearchService) UniversalSearch(ctx context.Context, params models.Unive
 	if !isTalentOnlySearch {
 		eventQuery = "SELECT 'event' as type, ce.Id, ce.CreatedAt, NULL as RoleId FROM CommunityEvent ce"
 		if len(eventConditions) > 0 {
-			eventQuery += " WHERE " + strings.Join(eventConditions, " AND ")
+			// Las publicaciones ocultas por moderación no aparecen en la búsqueda.
+			eventQuery += " WHERE " + strings.Join(eventConditions, " AND ") +
+				" AND NOT EXISTS (SELECT 1 FROM HiddenContent h WHERE h.TargetType = 'POST' AND h.TargetId = CAST(ce.Id AS CHAR))"
 		}
 	}
 
@@ -258,7 +284,7 @@ func (s *SearchService) fetchPaginatedResults(ctx context.Context, userQuery, ev
 				logger.Warnf("SEARCH_SERVICE", "Could not fetch full profile for user ID %d: %v", id, err)
 				continue
 			}
-			if roleId.Valid && roleId.Int64 == 3 {
+			if roleId.Valid && roleId.Int64 == int64(models.RoleBusiness) {
 				companies = append(companies, *profile)
 			} else {
 				users = append(users, *profile)
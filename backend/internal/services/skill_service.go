@@ -0,0 +1,316 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/taxonomy"
+)
+
+const skillServiceComponent = "SKILL_SERVICE"
+
+const (
+	// skillNameMaxLength es la longitud máxima del nombre, la categoría y cada alias de una
+	// habilidad canónica (las columnas son VARCHAR(100)).
+	skillNameMaxLength = 100
+	// skillMaxAliases es el número máximo de alias por habilidad.
+	skillMaxAliases = 20
+	// skillMappingBatchSize es el número de filas que la migración asocia por transacción.
+	skillMappingBatchSize = 500
+	// skillIndexTTL es cuánto se reutiliza el índice de búsqueda antes de reconstruirlo con el
+	// catálogo (cacheado) actual. Las escrituras de esta instancia lo descartan de inmediato.
+	skillIndexTTL = time.Minute
+)
+
+// Errores de negocio del catálogo de habilidades.
+var (
+	ErrSkillDuplicate    = apperrors.New(apperrors.CatalogDuplicate, "ya existe una habilidad con ese nombre o alias")
+	ErrSkillTooManyAlias = apperrors.New(apperrors.CatalogInvalid, fmt.Sprintf("una habilidad no puede tener más de %d alias", skillMaxAliases))
+)
+
+// ISkillService define la interfaz del servicio del catálogo de habilidades.
+type ISkillService interface {
+	GetCatalog() ([]models.SkillCatalogItem, error)
+	Suggest(query string, limit int) ([]models.SkillCatalogItem, error)
+	Normalize(input string) (models.SkillMatch, bool, error)
+	CanonicalizeSkill(name string) (string, *int64)
+	CreateSkill(item models.SkillCatalogItem) (models.SkillCatalogItem, error)
+	UpdateSkill(id int64, item models.SkillCatalogItem) (models.SkillCatalogItem, error)
+	DeleteSkill(id int64) error
+	GetUnmapped(limit int) ([]models.UnmappedSkill, error)
+	MapExistingSkills(dryRun bool) (models.SkillMappingReport, error)
+}
+
+// SkillService normaliza las habilidades que escriben los usuarios contra el catálogo de
+// habilidades canónicas, sugiere habilidades para autocompletar y gestiona el catálogo desde el
+// panel de administración. La búsqueda (exacta, por alias, fonética y aproximada) la hace
+// pkg/taxonomy.
+type SkillService struct {
+	db *sql.DB
+}
+
+// NewSkillService crea una nueva instancia de SkillService.
+func NewSkillService(db *sql.DB) ISkillService {
+	return &SkillService{db: db}
+}
+
+// skillIndexCache guarda el índice de búsqueda compartido por todas las instancias del
+// servicio: los handlers del WebSocket crean un servicio por mensaje.
+var skillIndexCache struct {
+	sync.Mutex
+	index     *taxonomy.Index
+	items     map[int64]models.SkillCatalogItem
+	expiresAt time.Time
+}
+
+// skillIndex devuelve el índice de búsqueda y las habilidades por ID.
+func skillIndex() (*taxonomy.Index, map[int64]models.SkillCatalogItem, error) {
+	skillIndexCache.Lock()
+	defer skillIndexCache.Unlock()
+	if skillIndexCache.index != nil && time.Now().Before(skillIndexCache.expiresAt) {
+		return skillIndexCache.index, skillIndexCache.items, nil
+	}
+
+	catalog, err := queries.GetSkillCatalog()
+	if err != nil {
+		return nil, nil, err
+	}
+	entries := make([]taxonomy.Entry, len(catalog))
+	items := make(map[int64]models.SkillCatalogItem, len(catalog))
+	for i, item := range catalog {
+		entries[i] = taxonomy.Entry{ID: item.Id, Name: item.Name, Aliases: item.Aliases, Popularity: item.UsageCount}
+		items[item.Id] = item
+	}
+	skillIndexCache.index = taxonomy.NewIndex(entries)
+	skillIndexCache.items = items
+	skillIndexCache.expiresAt = time.Now().Add(skillIndexTTL)
+	return skillIndexCache.index, items, nil
+}
+
+// invalidateSkillIndex descarta el índice tras una escritura del catálogo.
+func invalidateSkillIndex() {
+	skillIndexCache.Lock()
+	skillIndexCache.index = nil
+	skillIndexCache.Unlock()
+}
+
+// GetCatalog devuelve todas las habilidades canónicas.
+func (s *SkillService) GetCatalog() ([]models.SkillCatalogItem, error) {
+	return queries.GetSkillCatalog()
+}
+
+// Suggest devuelve hasta limit habilidades para autocompletar query. Con query vacío devuelve
+// las más usadas.
+func (s *SkillService) Suggest(query string, limit int) ([]models.SkillCatalogItem, error) {
+	index, items, err := skillIndex()
+	if err != nil {
+		return nil, err
+	}
+	entries := index.Suggest(query, limit)
+	suggestions := make([]models.SkillCatalogItem, len(entries))
+	for i, entry := range entries {
+		suggestions[i] = items[entry.ID]
+	}
+	return suggestions, nil
+}
+
+// Normalize busca la habilidad canónica que corresponde a input. ok es false si ninguna se
+// parece lo suficiente.
+func (s *SkillService) Normalize(input string) (models.SkillMatch, bool, error) {
+	index, _, err := skillIndex()
+	if err != nil {
+		return models.SkillMatch{}, false, err
+	}
+	match, ok := index.Match(input)
+	if !ok {
+		return models.SkillMatch{}, false, nil
+	}
+	return models.SkillMatch{SkillCatalogId: match.Entry.ID, Name: match.Entry.Name, Kind: match.Kind}, true, nil
+}
+
+// CanonicalizeSkill devuelve el nombre canónico y el ID de la habilidad que corresponde a
+// name. Si ninguna corresponde, o el catálogo no se puede consultar, devuelve name sin cambios
+// y un ID nil: la habilidad se guarda igualmente como texto libre.
+func (s *SkillService) CanonicalizeSkill(name string) (string, *int64) {
+	name = strings.TrimSpace(name)
+	match, ok, err := s.Normalize(name)
+	if err != nil {
+		logger.Warnf(skillServiceComponent, "No se pudo normalizar la habilidad %q: %v", name, err)
+		return name, nil
+	}
+	if !ok {
+		return name, nil
+	}
+	id := match.SkillCatalogId
+	return match.Name, &id
+}
+
+// CreateSkill crea una habilidad canónica con sus alias.
+func (s *SkillService) CreateSkill(item models.SkillCatalogItem) (models.SkillCatalogItem, error) {
+	item, key, err := validateSkillCatalogItem(0, item)
+	if err != nil {
+		return item, err
+	}
+	id, err := queries.InsertSkillCatalogItem(item, key)
+	if err != nil {
+		return item, catalogWriteError(err, ErrSkillDuplicate)
+	}
+	invalidateSkillIndex()
+	item.Id = id
+	logger.Infof(skillServiceComponent, "Habilidad %d creada: %s (%d alias)", item.Id, item.Name, len(item.Aliases))
+	return item, nil
+}
+
+// UpdateSkill reemplaza el nombre, la categoría y los alias de la habilidad. Las habilidades
+// de usuarios asociadas pasan a mostrar el nombre nuevo.
+func (s *SkillService) UpdateSkill(id int64, item models.SkillCatalogItem) (models.SkillCatalogItem, error) {
+	item, key, err := validateSkillCatalogItem(id, item)
+	if err != nil {
+		return item, err
+	}
+	item.Id = id
+	if err := queries.UpdateSkillCatalogItem(item, key); err != nil {
+		return item, catalogWriteError(err, ErrSkillDuplicate)
+	}
+	invalidateSkillIndex()
+	return item, nil
+}
+
+// DeleteSkill elimina la habilidad. Las habilidades de usuarios asociadas conservan su texto.
+func (s *SkillService) DeleteSkill(id int64) error {
+	if err := catalogWriteError(queries.DeleteSkillCatalogItem(id), nil); err != nil {
+		return err
+	}
+	invalidateSkillIndex()
+	return nil
+}
+
+// GetUnmapped devuelve los textos de habilidad más usados que el catálogo no reconoce.
+func (s *SkillService) GetUnmapped(limit int) ([]models.UnmappedSkill, error) {
+	return queries.GetUnmappedSkills(limit)
+}
+
+// MapExistingSkills asocia con el catálogo las habilidades de usuarios que aún no tienen
+// SkillCatalogId, por lotes, y después elimina las que quedan repetidas para un mismo usuario.
+// Es idempotente: las filas que no se reconocen siguen sin asociar y una ejecución posterior,
+// con más habilidades o alias en el catálogo, vuelve a intentarlo. Con dryRun solo calcula el
+// informe (Merged queda en 0).
+func (s *SkillService) MapExistingSkills(dryRun bool) (models.SkillMappingReport, error) {
+	report := models.SkillMappingReport{ByKind: map[string]int{}}
+	index, _, err := skillIndex()
+	if err != nil {
+		return report, err
+	}
+
+	var afterID int64
+	for {
+		rows, err := queries.GetUnmappedSkillRows(afterID, skillMappingBatchSize)
+		if err != nil {
+			return report, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		afterID = rows[len(rows)-1].Id
+
+		var mapped []models.Skills
+		for _, row := range rows {
+			report.Scanned++
+			match, ok := index.Match(row.Skill)
+			if !ok {
+				report.Unmapped++
+				continue
+			}
+			id := match.Entry.ID
+			row.SkillCatalogId, row.Skill = &id, match.Entry.Name
+			mapped = append(mapped, row)
+			report.ByKind[match.Kind]++
+		}
+		report.Mapped += len(mapped)
+		if dryRun || len(mapped) == 0 {
+			continue
+		}
+		if err := queries.AssignSkillCatalog(mapped); err != nil {
+			return report, err
+		}
+	}
+
+	if !dryRun {
+		merged, err := queries.MergeDuplicateSkills()
+		if err != nil {
+			return report, err
+		}
+		report.Merged = int(merged)
+		invalidateSkillIndex()
+	}
+	logger.Infof(skillServiceComponent, "Migración de habilidades (dryRun=%t): %d revisadas, %d asociadas, %d fusionadas, %d sin asociar",
+		dryRun, report.Scanned, report.Mapped, report.Merged, report.Unmapped)
+	return report, nil
+}
+
+// validateSkillCatalogItem limpia la habilidad, normaliza sus alias (sin repetidos ni iguales
+// al nombre) y comprueba que ni el nombre ni los alias los use otra habilidad distinta de id.
+// Devuelve también el nombre normalizado.
+func validateSkillCatalogItem(id int64, item models.SkillCatalogItem) (models.SkillCatalogItem, string, error) {
+	item.Name = strings.TrimSpace(item.Name)
+	item.Category = strings.TrimSpace(item.Category)
+	item.UsageCount = 0
+	if err := skillText("name", item.Name, true); err != nil {
+		return item, "", err
+	}
+	if err := skillText("category", item.Category, false); err != nil {
+		return item, "", err
+	}
+	key := taxonomy.Normalize(item.Name)
+	if key == "" {
+		return item, "", apperrors.New(apperrors.CatalogInvalid, "name debe contener letras o números")
+	}
+
+	aliases := []string{}
+	seen := map[string]bool{key: true}
+	for _, alias := range item.Aliases {
+		if err := skillText("aliases", alias, false); err != nil {
+			return item, "", err
+		}
+		normalized := taxonomy.Normalize(alias)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		aliases = append(aliases, normalized)
+	}
+	if len(aliases) > skillMaxAliases {
+		return item, "", ErrSkillTooManyAlias
+	}
+	item.Aliases = aliases
+
+	for _, k := range append([]string{key}, aliases...) {
+		taken, err := queries.SkillCatalogNameTaken(k, id)
+		if err != nil {
+			return item, "", err
+		}
+		if taken {
+			return item, "", ErrSkillDuplicate
+		}
+	}
+	return item, key, nil
+}
+
+// skillText valida la longitud de un campo de texto del catálogo de habilidades.
+func skillText(field, value string, required bool) error {
+	if required && value == "" {
+		return apperrors.New(apperrors.CatalogInvalid, field+" es obligatorio")
+	}
+	if utf8.RuneCountInString(value) > skillNameMaxLength {
+		return apperrors.New(apperrors.CatalogInvalid, fmt.Sprintf("%s no puede superar los %d caracteres", field, skillNameMaxLength))
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const storageQuotaServiceComponent = "STORAGE_QUOTA_SERVICE"
+
+var (
+	ErrStorageQuotaExceeded     = apperrors.New(apperrors.StorageQuotaExceeded, "el archivo supera tu cuota de almacenamiento")
+	ErrStorageQuotaInvalid      = apperrors.New(apperrors.StorageQuotaInvalid, "quotaBytes no puede ser negativo")
+	ErrStorageQuotaUserNotFound = apperrors.New(apperrors.NotFound, "usuario no encontrado")
+)
+
+// IStorageQuotaService define las cuotas de almacenamiento de los usuarios.
+type IStorageQuotaService interface {
+	Usage(userID int64) (*models.StorageUsage, error)
+	Reserve(userID, size int64) error
+	Charge(userID, size int64)
+	Release(userID, size int64)
+	SetQuota(userID int64, quota *int64) (*models.StorageUsage, error)
+}
+
+// StorageQuotaService lleva la cuenta del espacio que ocupan los archivos subidos por cada
+// usuario y rechaza las subidas que superan su cuota. La cuota depende del rol
+// (STORAGE_QUOTA_STUDENT, STORAGE_QUOTA_COMPANY) salvo que un administrador fije otra.
+//
+// Se contabiliza el tamaño del archivo recibido, no el de las variantes que se generan a
+// partir de él (miniaturas, calidades de video).
+type StorageQuotaService struct {
+	studentQuota int64
+	companyQuota int64
+}
+
+// NewStorageQuotaService crea una nueva instancia de StorageQuotaService.
+func NewStorageQuotaService(cfg *config.Config) IStorageQuotaService {
+	return &StorageQuotaService{studentQuota: cfg.StorageQuotaStudent, companyQuota: cfg.StorageQuotaCompany}
+}
+
+// roleQuota devuelve la cuota del rol del usuario (0 = sin límite).
+func (s *StorageQuotaService) roleQuota(userID int64) (int64, error) {
+	state, err := queries.GetAccountState(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrStorageQuotaUserNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	switch models.UserRole(state.RoleId) {
+	case models.RoleAdmin:
+		return 0, nil
+	case models.RoleBusiness:
+		return s.companyQuota, nil
+	default:
+		return s.studentQuota, nil
+	}
+}
+
+// Usage devuelve el uso y la cuota vigente del usuario.
+func (s *StorageQuotaService) Usage(userID int64) (*models.StorageUsage, error) {
+	roleQuota, err := s.roleQuota(userID)
+	if err != nil {
+		return nil, err
+	}
+	used, override, err := queries.GetUserStorage(userID)
+	if err != nil {
+		return nil, err
+	}
+	usage := &models.StorageUsage{UserId: userID, UsedBytes: used, QuotaBytes: roleQuota, Override: override.Valid}
+	if override.Valid {
+		usage.QuotaBytes = override.Int64
+	}
+	if usage.QuotaBytes > 0 {
+		remaining := max(usage.QuotaBytes-used, 0)
+		usage.RemainingBytes = &remaining
+	}
+	return usage, nil
+}
+
+// Reserve suma size bytes al uso del usuario, o devuelve ErrStorageQuotaExceeded si no caben.
+// Con size 0 solo comprueba que la cuota no esté ya agotada. Si la subida falla, el llamador
+// devuelve la reserva con Release.
+func (s *StorageQuotaService) Reserve(userID, size int64) error {
+	if size <= 0 {
+		usage, err := s.Usage(userID)
+		if err != nil {
+			return err
+		}
+		if usage.RemainingBytes != nil && *usage.RemainingBytes == 0 {
+			return ErrStorageQuotaExceeded
+		}
+		return nil
+	}
+
+	roleQuota, err := s.roleQuota(userID)
+	if err != nil {
+		return err
+	}
+	reserved, err := queries.ReserveUserStorage(userID, size, roleQuota)
+	if err != nil {
+		return err
+	}
+	if !reserved {
+		logger.Infof(storageQuotaServiceComponent, "Subida de %d bytes del usuario %d rechazada por la cuota", size, userID)
+		return ErrStorageQuotaExceeded
+	}
+	return nil
+}
+
+// Charge suma size bytes al uso del usuario sin comprobar la cuota, para subidas cuyo tamaño
+// solo se conoce al terminar.
+func (s *StorageQuotaService) Charge(userID, size int64) {
+	if size <= 0 {
+		return
+	}
+	if err := queries.AdjustUserStorage(userID, size); err != nil {
+		logger.Errorf(storageQuotaServiceComponent, "%v", err)
+	}
+}
+
+// Release devuelve size bytes reservados de una subida que no se completó.
+func (s *StorageQuotaService) Release(userID, size int64) {
+	if size <= 0 {
+		return
+	}
+	if err := queries.AdjustUserStorage(userID, -size); err != nil {
+		logger.Errorf(storageQuotaServiceComponent, "%v", err)
+	}
+}
+
+// SetQuota fija la cuota del usuario en bytes (0 = sin límite); nil vuelve a la de su rol.
+func (s *StorageQuotaService) SetQuota(userID int64, quota *int64) (*models.StorageUsage, error) {
+	if quota != nil && *quota < 0 {
+		return nil, ErrStorageQuotaInvalid
+	}
+	if _, err := s.roleQuota(userID); err != nil {
+		return nil, err
+	}
+	if err := queries.SetUserStorageQuota(userID, quota); err != nil {
+		return nil, err
+	}
+	if quota != nil {
+		logger.Infof(storageQuotaServiceComponent, "Cuota de almacenamiento del usuario %d fijada en %d bytes", userID, *quota)
+	} else {
+		logger.Infof(storageQuotaServiceComponent, "Cuota de almacenamiento del usuario %d restablecida a la de su rol", userID)
+	}
+	return s.Usage(userID)
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const studentAnalyticsServiceComponent = "STUDENT_ANALYTICS_SERVICE"
+
+const (
+	// studentAnalyticsMaxDays es el periodo máximo que se puede consultar.
+	studentAnalyticsMaxDays = 365
+	// studentRecentViewersLimit es el número de visitantes recientes devueltos.
+	studentRecentViewersLimit = 10
+)
+
+// ErrInvalidAnalyticsRange se devuelve si el periodo solicitado está fuera de rango.
+var ErrInvalidAnalyticsRange = apperrors.New(apperrors.InvalidParam, fmt.Sprintf("el parámetro 'days' debe estar entre 1 y %d", studentAnalyticsMaxDays))
+
+// IStudentAnalyticsService define la interfaz de las estadísticas de estudiantes y egresados.
+type IStudentAnalyticsService interface {
+	GetAnalytics(userID int64, days int) (*models.StudentAnalytics, error)
+}
+
+// StudentAnalyticsService calcula las estadísticas del perfil de un estudiante: visitas
+// recibidas, estado de sus postulaciones e impresiones en el feed.
+type StudentAnalyticsService struct {
+	db *sql.DB
+}
+
+// NewStudentAnalyticsService crea una nueva instancia de StudentAnalyticsService.
+func NewStudentAnalyticsService(db *sql.DB) IStudentAnalyticsService {
+	return &StudentAnalyticsService{db: db}
+}
+
+// GetAnalytics devuelve las estadísticas de los últimos days días del usuario.
+func (s *StudentAnalyticsService) GetAnalytics(userID int64, days int) (*models.StudentAnalytics, error) {
+	if days < 1 || days > studentAnalyticsMaxDays {
+		return nil, ErrInvalidAnalyticsRange
+	}
+
+	now := time.Now()
+	from := now.AddDate(0, 0, -days).Truncate(24 * time.Hour)
+	analytics := &models.StudentAnalytics{
+		UserId:      userID,
+		Days:        days,
+		From:        from,
+		GeneratedAt: now.UTC(),
+	}
+
+	if err := s.fillProfileViews(&analytics.ProfileViews, userID, from); err != nil {
+		return nil, err
+	}
+	if err := s.fillApplications(&analytics.Applications, userID, from); err != nil {
+		return nil, err
+	}
+	if err := s.fillFeedImpressions(&analytics.FeedImpressions, userID, from); err != nil {
+		return nil, err
+	}
+	return analytics, nil
+}
+
+func (s *StudentAnalyticsService) fillProfileViews(stats *models.ProfileViewStats, userID int64, from time.Time) error {
+	rows, err := s.db.Query(queries.StudentProfileViewsByDay, models.RoleBusiness, userID, from)
+	if err != nil {
+		logger.Errorf(studentAnalyticsServiceComponent, "Error al consultar las visitas al perfil %d: %v", userID, err)
+		return fmt.Errorf("error al consultar las visitas al perfil: %w", err)
+	}
+	defer rows.Close()
+
+	stats.Series = []models.DailyCount{}
+	for rows.Next() {
+		var day time.Time
+		var total, fromCompanies, anonymous int64
+		if err := rows.Scan(&day, &total, &fromCompanies, &anonymous); err != nil {
+			return fmt.Errorf("error al leer las visitas al perfil: %w", err)
+		}
+		stats.Total += total
+		stats.FromCompanies += fromCompanies
+		stats.Anonymous += anonymous
+		stats.Series = append(stats.Series, models.DailyCount{Date: day.Format(time.DateOnly), Count: total})
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error al leer las visitas al perfil: %w", err)
+	}
+
+	viewerRows, err := s.db.Query(queries.StudentRecentProfileViewers, userID, from, studentRecentViewersLimit)
+	if err != nil {
+		logger.Errorf(studentAnalyticsServiceComponent, "Error al consultar los visitantes del perfil %d: %v", userID, err)
+		return fmt.Errorf("error al consultar los visitantes del perfil: %w", err)
+	}
+	defer viewerRows.Close()
+
+	stats.RecentViewers = []models.ProfileViewer{}
+	for viewerRows.Next() {
+		var viewer models.ProfileViewer
+		var companyName, firstName, lastName string
+		if err := viewerRows.Scan(&viewer.UserId, &viewer.RoleId, &companyName, &firstName, &lastName, &viewer.Picture, &viewer.ViewedAt); err != nil {
+			return fmt.Errorf("error al leer los visitantes del perfil: %w", err)
+		}
+		if viewer.RoleId == int(models.RoleBusiness) && companyName != "" {
+			viewer.Name = companyName
+		} else {
+			viewer.Name = strings.TrimSpace(firstName + " " + lastName)
+		}
+		stats.RecentViewers = append(stats.RecentViewers, viewer)
+	}
+	return viewerRows.Err()
+}
+
+func (s *StudentAnalyticsService) fillApplications(stats *models.ApplicationStats, userID int64, from time.Time) error {
+	rows, err := s.db.Query(queries.StudentApplicationStatusCounts, userID, from)
+	if err != nil {
+		logger.Errorf(studentAnalyticsServiceComponent, "Error al consultar las postulaciones del usuario %d: %v", userID, err)
+		return fmt.Errorf("error al consultar las postulaciones: %w", err)
+	}
+	defer rows.Close()
+
+	stats.ByStatus = make(map[string]int64)
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return fmt.Errorf("error al leer las postulaciones: %w", err)
+		}
+		stats.ByStatus[status] = count
+		stats.Total += count
+	}
+	return rows.Err()
+}
+
+func (s *StudentAnalyticsService) fillFeedImpressions(stats *models.FeedImpressionStats, userID int64, from time.Time) error {
+	rows, err := s.db.Query(queries.StudentFeedImpressionsByDay, userID, from)
+	if err != nil {
+		logger.Errorf(studentAnalyticsServiceComponent, "Error al consultar las impresiones en el feed del usuario %d: %v", userID, err)
+		return fmt.Errorf("error al consultar las impresiones en el feed: %w", err)
+	}
+	defer rows.Close()
+
+	stats.Series = []models.DailyCount{}
+	for rows.Next() {
+		var day time.Time
+		var count int64
+		if err := rows.Scan(&day, &count); err != nil {
+			return fmt.Errorf("error al leer las impresiones en el feed: %w", err)
+		}
+		stats.Total += count
+		stats.Series = append(stats.Series, models.DailyCount{Date: day.Format(time.DateOnly), Count: count})
+	}
+	return rows.Err()
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/mailer"
+	"github.com/go-sql-driver/mysql"
+)
+
+const studentVerificationServiceComponent = "STUDENT_VERIFICATION_SERVICE"
+
+// studentVerificationCodeDigits es la longitud del código que se envía por correo.
+const studentVerificationCodeDigits = 6
+
+var (
+	ErrStudentVerificationForbidden   = apperrors.New(apperrors.StudentVerificationForbidden, "solo los estudiantes y egresados pueden verificar su correo institucional")
+	ErrStudentEmailInvalid            = apperrors.New(apperrors.StudentEmailInvalid, "el correo no es válido")
+	ErrStudentEmailUnknownDomain      = apperrors.New(apperrors.StudentEmailInvalid, "el dominio del correo no pertenece a ninguna universidad registrada")
+	ErrStudentEmailTaken              = apperrors.New(apperrors.StudentEmailTaken, "ese correo ya verificó otra cuenta")
+	ErrStudentCodeInvalid             = apperrors.New(apperrors.StudentCodeInvalid, "el código no es correcto o ha caducado")
+	ErrStudentCodeAttempts            = apperrors.New(apperrors.StudentCodeLimit, "demasiados intentos fallidos; solicita un código nuevo")
+	ErrStudentCodeResend              = apperrors.New(apperrors.StudentCodeLimit, "espera un momento antes de solicitar otro código")
+	ErrStudentVerificationUnavailable = apperrors.New(apperrors.StudentVerificationUnavailable, "la verificación por correo no está disponible")
+)
+
+// IStudentVerificationService define la verificación de estudiantes y egresados con el correo
+// institucional.
+type IStudentVerificationService interface {
+	GetStatus(userID int64, role models.UserRole) (models.StudentVerification, error)
+	RequestCode(userID int64, role models.UserRole, email string) (models.StudentVerification, error)
+	Confirm(userID int64, role models.UserRole, code string) (models.StudentVerification, error)
+}
+
+// StudentVerificationService envía un código al correo institucional del usuario y, cuando lo
+// confirma, le concede la insignia de estudiante o egresado verificado. La universidad se
+// deduce del dominio del correo (University.EmailDomains).
+type StudentVerificationService struct {
+	db           *sql.DB
+	mailer       *mailer.Mailer
+	codeTTL      time.Duration
+	maxAttempts  int
+	resendPeriod time.Duration
+}
+
+// NewStudentVerificationService crea una nueva instancia de StudentVerificationService.
+func NewStudentVerificationService(db *sql.DB, cfg *config.Config) IStudentVerificationService {
+	s := &StudentVerificationService{
+		db:           db,
+		mailer:       mailer.New(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom),
+		codeTTL:      cfg.StudentVerificationCodeTTL,
+		maxAttempts:  max(cfg.StudentVerificationMaxAttempts, 1),
+		resendPeriod: cfg.StudentVerificationResendPeriod,
+	}
+	if !s.mailer.Enabled() {
+		logger.Warn(studentVerificationServiceComponent, "SMTP no configurado: la verificación de estudiantes por correo no está disponible")
+	}
+	return s
+}
+
+// GetStatus devuelve el estado de la verificación del usuario.
+func (s *StudentVerificationService) GetStatus(userID int64, role models.UserRole) (models.StudentVerification, error) {
+	rec, err := queries.GetStudentVerification(userID)
+	if err != nil {
+		return models.StudentVerification{Status: models.StudentVerificationNone}, err
+	}
+	return s.status(rec, role, time.Now()), nil
+}
+
+// RequestCode envía un código de verificación a email. El dominio debe pertenecer a una
+// universidad del catálogo y el correo no puede haber verificado otra cuenta.
+func (s *StudentVerificationService) RequestCode(userID int64, role models.UserRole, email string) (models.StudentVerification, error) {
+	none := models.StudentVerification{Status: models.StudentVerificationNone}
+	if models.StudentBadgeKind(int(role)) == "" {
+		return none, ErrStudentVerificationForbidden
+	}
+	if !s.mailer.Enabled() {
+		return none, ErrStudentVerificationUnavailable
+	}
+	email, domain, ok := normalizeStudentEmail(email)
+	if !ok {
+		return none, ErrStudentEmailInvalid
+	}
+	uni, ok, err := queries.FindUniversityByEmailDomain(domain)
+	if err != nil {
+		return none, err
+	}
+	if !ok {
+		return none, ErrStudentEmailUnknownDomain
+	}
+	taken, err := queries.StudentEmailVerifiedByOther(email, userID)
+	if err != nil {
+		return none, err
+	}
+	if taken {
+		return none, ErrStudentEmailTaken
+	}
+
+	now := time.Now()
+	rec, err := queries.GetStudentVerification(userID)
+	if err != nil {
+		return none, err
+	}
+	if rec != nil && rec.CodeSentAt.Valid && now.Sub(rec.CodeSentAt.Time) < s.resendPeriod {
+		return s.status(rec, role, now), ErrStudentCodeResend
+	}
+
+	code, err := generateStudentVerificationCode()
+	if err != nil {
+		return none, fmt.Errorf("error generando el código de verificación: %w", err)
+	}
+	expiresAt := now.Add(s.codeTTL)
+	if err := queries.SaveStudentVerificationCode(userID, email, uni.Id, hashStudentVerificationCode(code), now, expiresAt); err != nil {
+		return none, err
+	}
+	if err := s.mailer.Send(email, "Verifica tu correo institucional - Alumni USM", fmt.Sprintf(
+		`<p>Tu código para verificar tu correo de %s es:</p>
+		<p style="font-size: 24px; font-weight: bold;">%s</p>
+		<p>Caduca en %d minutos. Si no lo solicitaste, ignora este correo.</p>`,
+		uni.Name, code, int(s.codeTTL.Minutes()))); err != nil {
+		return none, fmt.Errorf("error enviando el código de verificación al usuario %d: %w", userID, err)
+	}
+	logger.Infof(studentVerificationServiceComponent, "Código de verificación enviado al usuario %d (universidad %d)", userID, uni.Id)
+
+	rec, err = queries.GetStudentVerification(userID)
+	if err != nil {
+		return none, err
+	}
+	return s.status(rec, role, now), nil
+}
+
+// Confirm comprueba el código y, si es correcto, marca el correo como verificado. Cada código
+// admite STUDENT_VERIFICATION_MAX_ATTEMPTS intentos fallidos.
+func (s *StudentVerificationService) Confirm(userID int64, role models.UserRole, code string) (models.StudentVerification, error) {
+	none := models.StudentVerification{Status: models.StudentVerificationNone}
+	if models.StudentBadgeKind(int(role)) == "" {
+		return none, ErrStudentVerificationForbidden
+	}
+	rec, err := queries.GetStudentVerification(userID)
+	if err != nil {
+		return none, err
+	}
+	now := time.Now()
+	if rec == nil || !rec.CodeHash.Valid || !rec.CodeExpiresAt.Valid || now.After(rec.CodeExpiresAt.Time) {
+		return s.status(rec, role, now), ErrStudentCodeInvalid
+	}
+	if rec.Attempts >= s.maxAttempts {
+		return s.status(rec, role, now), ErrStudentCodeAttempts
+	}
+	hash := hashStudentVerificationCode(strings.TrimSpace(code))
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(rec.CodeHash.String)) != 1 {
+		if err := queries.AddStudentVerificationAttempt(userID); err != nil {
+			return none, err
+		}
+		return s.status(rec, role, now), ErrStudentCodeInvalid
+	}
+
+	if err := queries.ConfirmStudentVerification(userID, now); err != nil {
+		var mysqlErr *mysql.MySQLError
+		switch {
+		case errors.As(err, &mysqlErr) && mysqlErr.Number == 1062:
+			return s.status(rec, role, now), ErrStudentEmailTaken
+		case errors.Is(err, sql.ErrNoRows):
+			return s.status(rec, role, now), ErrStudentCodeInvalid
+		}
+		return none, fmt.Errorf("error confirmando la verificación del usuario %d: %w", userID, err)
+	}
+	logger.Successf(studentVerificationServiceComponent, "Usuario %d verificado como estudiante de la universidad %d", userID, rec.PendingUniversityId.Int64)
+	// La universidad verificada es la institución de la cuenta si aún no tiene una (ver
+	// docs/multi_institucion.md). Un fallo no revierte la verificación.
+	if err := queries.AssignTenantIfUnset(userID, rec.PendingUniversityId.Int64); err != nil {
+		logger.Errorf(studentVerificationServiceComponent, "%v", err)
+	}
+
+	RecordAudit(nil, models.AuditLog{
+		ActorId:    &userID,
+		Action:     models.AuditActionStudentVerified,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(userID, 10),
+	}, map[string]interface{}{"universityId": rec.PendingUniversityId.Int64})
+
+	rec, err = queries.GetStudentVerification(userID)
+	if err != nil {
+		return none, err
+	}
+	return s.status(rec, role, now), nil
+}
+
+// status construye el estado para el cliente. Un código caducado o agotado deja de contar
+// como pendiente.
+func (s *StudentVerificationService) status(rec *queries.StudentVerificationRecord, role models.UserRole, now time.Time) models.StudentVerification {
+	result := models.StudentVerification{Status: models.StudentVerificationNone}
+	if rec == nil {
+		return result
+	}
+	if rec.VerifiedAt.Valid {
+		result.Status = models.StudentVerificationVerified
+		result.Email = rec.Email.String
+		result.UniversityId = rec.UniversityId.Int64
+		result.UniversityName = rec.UniversityName.String
+		if kind := models.StudentBadgeKind(int(role)); kind != "" {
+			result.Badge = &models.StudentBadge{
+				Kind:           kind,
+				UniversityId:   rec.UniversityId.Int64,
+				UniversityName: rec.UniversityName.String,
+				VerifiedAt:     rec.VerifiedAt.Time,
+			}
+		}
+	}
+	if rec.CodeHash.Valid && rec.CodeExpiresAt.Valid && now.Before(rec.CodeExpiresAt.Time) && rec.Attempts < s.maxAttempts {
+		expiresAt := rec.CodeExpiresAt.Time
+		result.Status = models.StudentVerificationPending
+		result.Email = rec.PendingEmail.String
+		result.UniversityId = rec.PendingUniversityId.Int64
+		result.UniversityName = rec.PendingUniversity.String
+		result.CodeExpiresAt = &expiresAt
+	}
+	return result
+}
+
+// normalizeStudentEmail valida el correo y lo devuelve en minúsculas junto con su dominio.
+func normalizeStudentEmail(email string) (string, string, bool) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || len(email) > 255 {
+		return "", "", false
+	}
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 {
+		return "", "", false
+	}
+	return email, email[at+1:], true
+}
+
+// generateStudentVerificationCode genera un código numérico aleatorio de
+// studentVerificationCodeDigits dígitos.
+func generateStudentVerificationCode() (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < studentVerificationCodeDigits; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", studentVerificationCodeDigits, n.Int64()), nil
+}
+
+// hashStudentVerificationCode devuelve el SHA-256 hexadecimal del código; el código en claro
+// solo viaja en el correo.
+func hashStudentVerificationCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
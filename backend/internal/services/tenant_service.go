@@ -0,0 +1,92 @@
+package services
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const tenantServiceComponent = "TENANT_SERVICE"
+
+// ErrTenantMismatch indica que la cuenta pertenece a otra institución que la del subdominio.
+var ErrTenantMismatch = apperrors.New(apperrors.TenantMismatch, "tu cuenta pertenece a otra institución")
+
+// TenantResolver resuelve la institución (tenant) de una petición, la que limita el feed, la
+// búsqueda y los eventos (ver docs/multi_institucion.md). Con el soporte desactivado todas las
+// peticiones resuelven 0, sin institución, y nada se filtra.
+type TenantResolver struct {
+	enabled    bool
+	baseDomain string
+}
+
+// NewTenantResolver crea el resolvedor con TENANCY_ENABLED y TENANCY_BASE_DOMAIN.
+func NewTenantResolver(cfg *config.Config) *TenantResolver {
+	return &TenantResolver{
+		enabled:    cfg.TenancyEnabled,
+		baseDomain: strings.ToLower(strings.Trim(cfg.TenancyBaseDomain, ".")),
+	}
+}
+
+// Enabled indica si el soporte multi-institución está activo.
+func (t *TenantResolver) Enabled() bool {
+	return t != nil && t.enabled
+}
+
+// FromRequest devuelve la institución del subdominio de la petición (ucv.example.com), o 0 si
+// no es el de ninguna. Se mira primero el Host y después el Origin: el frontend de cada
+// institución puede llamar a una API con dominio compartido (api.example.com).
+func (t *TenantResolver) FromRequest(r *http.Request) int64 {
+	if !t.Enabled() || t.baseDomain == "" {
+		return 0
+	}
+	if tenantID := t.fromHost(r.Host); tenantID != 0 {
+		return tenantID
+	}
+	if origin, err := url.Parse(r.Header.Get("Origin")); err == nil {
+		return t.fromHost(origin.Host)
+	}
+	return 0
+}
+
+// fromHost devuelve la institución cuyo slug es el subdominio de host. Solo se acepta un nivel
+// por debajo de baseDomain.
+func (t *TenantResolver) fromHost(host string) int64 {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	slug, ok := strings.CutSuffix(strings.ToLower(host), "."+t.baseDomain)
+	if !ok || slug == "" || strings.Contains(slug, ".") {
+		return 0
+	}
+	uni, found, err := queries.FindUniversityBySlug(slug)
+	if err != nil {
+		logger.Errorf(tenantServiceComponent, "Error al resolver el subdominio %s: %v", slug, err)
+		return 0
+	}
+	if !found {
+		return 0
+	}
+	return uni.Id
+}
+
+// Resolve combina la institución de la cuenta con la del subdominio. Una cuenta con institución
+// siempre usa la suya y no puede entrar por el subdominio de otra (ErrTenantMismatch); una sin
+// institución (empresas, administradores globales) usa la del subdominio, si la hay.
+func (t *TenantResolver) Resolve(accountTenant, hostTenant int64) (int64, error) {
+	if !t.Enabled() {
+		return 0, nil
+	}
+	if accountTenant == 0 {
+		return hostTenant, nil
+	}
+	if hostTenant != 0 && hostTenant != accountTenant {
+		return 0, ErrTenantMismatch
+	}
+	return accountTenant, nil
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/scan"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/storage"
+	"github.com/google/uuid"
+)
+
+const uploadScanServiceComponent = "UPLOAD_SCAN_SERVICE"
+
+// ProcessingStatusQuarantined marca en Multimedia los archivos que el análisis de malware
+// detectó como infectados.
+const ProcessingStatusQuarantined = "quarantined"
+
+// quarantinePrefix es la carpeta privada del almacenamiento con los archivos en cuarentena.
+const quarantinePrefix = "quarantine/"
+
+var (
+	ErrUploadQuarantined     = apperrors.New(apperrors.UploadQuarantined, "el archivo contiene malware y se puso en cuarentena")
+	ErrUploadScanUnavailable = apperrors.New(apperrors.UploadScanUnavailable, "no se pudo analizar el archivo; inténtalo más tarde")
+	ErrQuarantineNotFound    = apperrors.New(apperrors.NotFound, "archivo en cuarentena no encontrado")
+)
+
+// IUploadScanService define el análisis de malware de las subidas y la gestión de la
+// cuarentena.
+type IUploadScanService interface {
+	Check(ctx context.Context, userID int64, mediaType, originalName string, data []byte) error
+	ListQuarantined(page, pageSize int) (*models.PaginatedQuarantineResponse, error)
+	DeleteQuarantined(ctx context.Context, multimediaID string) error
+}
+
+// UploadScanService analiza los archivos subidos antes de guardarlos. Un archivo infectado
+// no se publica: se guarda como objeto privado en quarantine/, se registra en Multimedia con
+// ProcessingStatus quarantined y se notifica al autor y a los administradores.
+//
+// Sin escáner configurado (UPLOAD_SCANNER vacío) Check no hace nada.
+type UploadScanService struct {
+	scanner  scan.Scanner
+	store    storage.Storage
+	failOpen bool
+}
+
+// NewUploadScanService crea una nueva instancia de UploadScanService. scanner puede ser nil.
+func NewUploadScanService(cfg *config.Config, scanner scan.Scanner, store storage.Storage) IUploadScanService {
+	return &UploadScanService{scanner: scanner, store: store, failOpen: cfg.UploadScanFailOpen}
+}
+
+// Check analiza data antes de guardarlo. Devuelve ErrUploadQuarantined si está infectado y
+// ErrUploadScanUnavailable si no se pudo analizar (salvo con UPLOAD_SCAN_FAIL_OPEN, que lo
+// acepta sin analizar).
+func (s *UploadScanService) Check(ctx context.Context, userID int64, mediaType, originalName string, data []byte) error {
+	if s.scanner == nil {
+		return nil
+	}
+	result, err := s.scanner.Scan(ctx, bytes.NewReader(data))
+	if err != nil {
+		if s.failOpen {
+			logger.Warnf(uploadScanServiceComponent, "Archivo %s del usuario %d aceptado sin analizar: %v", mediaType, userID, err)
+			return nil
+		}
+		logger.Errorf(uploadScanServiceComponent, "No se pudo analizar el archivo %s del usuario %d: %v", mediaType, userID, err)
+		return ErrUploadScanUnavailable
+	}
+	if !result.Infected {
+		return nil
+	}
+
+	signature := result.Signature
+	if signature == "" {
+		signature = "desconocida"
+	}
+	logger.Warnf(uploadScanServiceComponent, "Archivo %s del usuario %d infectado (%s): cuarentena", mediaType, userID, signature)
+	s.quarantine(userID, mediaType, originalName, data, signature)
+	return ErrUploadQuarantined
+}
+
+// quarantine guarda el archivo infectado como objeto privado, lo registra y avisa al autor y
+// a los administradores. Los fallos solo se registran: la subida se rechaza igualmente.
+func (s *UploadScanService) quarantine(userID int64, mediaType, originalName string, data []byte, signature string) {
+	originalName = strings.TrimSpace(filepath.Base(originalName))
+	if originalName == "" || originalName == "." {
+		originalName = mediaType
+	}
+	if len(originalName) > 255 {
+		originalName = originalName[:255]
+	}
+	objectPath := quarantinePrefix + uuid.New().String()
+
+	if err := s.store.Put(context.Background(), objectPath, bytes.NewReader(data), "application/octet-stream", false); err != nil {
+		logger.Errorf(uploadScanServiceComponent, "No se pudo guardar en cuarentena el archivo del usuario %d: %v", userID, err)
+	}
+	media := &models.Multimedia{
+		Id:               uuid.New().String(),
+		Type:             mediaType,
+		UserId:           userID,
+		FileName:         objectPath,
+		CreateAt:         time.Now(),
+		ContentId:        uuid.New().String(),
+		Size:             sql.NullInt64{Int64: int64(len(data)), Valid: true},
+		ProcessingStatus: sql.NullString{String: ProcessingStatusQuarantined, Valid: true},
+	}
+	if err := queries.InsertQuarantinedFile(media, originalName, signature); err != nil {
+		logger.Errorf(uploadScanServiceComponent, "%v", err)
+	}
+
+	metadata, _ := json.Marshal(map[string]string{"multimediaId": media.Id, "fileName": originalName, "signature": signature})
+	s.notify(models.Event{
+		EventType:   models.EventTypeUploadQuarantined,
+		EventTitle:  "Archivo bloqueado",
+		Description: fmt.Sprintf("El archivo %q que subiste contiene malware (%s) y no se publicó.", originalName, signature),
+		UserId:      userID,
+		Metadata:    metadata,
+	})
+	admins, err := queries.GetActiveAdminIDs()
+	if err != nil {
+		logger.Errorf(uploadScanServiceComponent, "%v", err)
+	}
+	for _, adminID := range admins {
+		s.notify(models.Event{
+			EventType:   models.EventTypeUploadQuarantined,
+			EventTitle:  "Archivo en cuarentena",
+			Description: fmt.Sprintf("Un usuario subió un archivo (%s, %q) con malware: %s.", mediaType, originalName, signature),
+			UserId:      adminID,
+			OtherUserId: sql.NullInt64{Int64: userID, Valid: true},
+			Metadata:    metadata,
+		})
+	}
+}
+
+func (s *UploadScanService) notify(event models.Event) {
+	if err := queries.CreateEventWithOutbox(&event); err != nil {
+		logger.Errorf(uploadScanServiceComponent, "No se pudo crear la notificación de cuarentena para el usuario %d: %v", event.UserId, err)
+	}
+}
+
+// ListQuarantined devuelve una página de los archivos en cuarentena.
+func (s *UploadScanService) ListQuarantined(page, pageSize int) (*models.PaginatedQuarantineResponse, error) {
+	files, total, err := queries.ListQuarantinedFiles(page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PaginatedQuarantineResponse{
+		CurrentPage:  page,
+		PageSize:     pageSize,
+		TotalPages:   int(math.Ceil(float64(total) / float64(pageSize))),
+		TotalRecords: total,
+		Files:        files,
+	}, nil
+}
+
+// DeleteQuarantined borra definitivamente un archivo en cuarentena y su registro.
+func (s *UploadScanService) DeleteQuarantined(ctx context.Context, multimediaID string) error {
+	file, err := queries.GetQuarantinedFile(multimediaID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrQuarantineNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.store.Delete(ctx, file.ObjectPath); err != nil {
+		return fmt.Errorf("error borrando el archivo en cuarentena %s: %w", file.ObjectPath, err)
+	}
+	return queries.DeleteQuarantinedFile(multimediaID)
+}
@@ -0,0 +1,360 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const uploadSessionServiceComponent = "UPLOAD_SESSION_SERVICE"
+
+const (
+	// MaxUploadChunkSize es el tamaño máximo de cada parte de una subida reanudable.
+	MaxUploadChunkSize = 16 << 20
+	// maxActiveUploadSessions limita las subidas en curso por usuario, y con ellas el disco que
+	// puede ocupar.
+	maxActiveUploadSessions = 3
+	// uploadSessionMaintenanceInterval es la frecuencia con la que se borran las subidas
+	// caducadas y los archivos temporales huérfanos.
+	uploadSessionMaintenanceInterval = time.Hour
+	// uploadSessionProcessTimeout limita el procesamiento del archivo completo. Es independiente
+	// de la petición para que un corte del cliente no deje el video a medio subir a GCS.
+	uploadSessionProcessTimeout = 30 * time.Minute
+	// uploadPartSuffix es la extensión de los archivos temporales de las subidas.
+	uploadPartSuffix = ".part"
+)
+
+var (
+	ErrUploadSessionInvalid  = apperrors.New(apperrors.UploadSessionInvalid, "se requieren fileName y un size entre 1 byte y el máximo de video")
+	ErrUploadChunkTooLarge   = apperrors.New(apperrors.UploadSessionInvalid, "la parte excede el tamaño declarado de la subida")
+	ErrUploadSessionNotFound = apperrors.New(apperrors.UploadSessionNotFound, "subida no encontrada")
+	ErrUploadOffsetMismatch  = apperrors.New(apperrors.UploadOffsetMismatch, "Upload-Offset no coincide con los bytes recibidos")
+	ErrUploadIncomplete      = apperrors.New(apperrors.UploadIncomplete, "la subida aún no tiene todos los bytes")
+	ErrUploadSessionClosed   = apperrors.New(apperrors.UploadSessionClosed, "la subida ya terminó, se canceló o caducó")
+	ErrUploadSessionBusy     = apperrors.New(apperrors.UploadSessionClosed, "la subida se está procesando")
+	ErrUploadSessionLimit    = apperrors.New(apperrors.UploadSessionLimit, "tienes demasiadas subidas en curso")
+)
+
+// IUploadSessionService define las subidas reanudables de video.
+type IUploadSessionService interface {
+	Create(userID int64, req models.CreateUploadSessionRequest) (*models.UploadSession, error)
+	Get(userID int64, id string) (*models.UploadSession, error)
+	WriteChunk(userID int64, id string, offset int64, body io.Reader) (int64, error)
+	Complete(userID int64, id string) (*UploadVideoDetails, error)
+	Cancel(userID int64, id string) error
+}
+
+// UploadSessionService permite subir un video por partes y reanudar la subida tras un corte.
+// Las partes se escriben en un archivo temporal en dir; cuando están todas, el archivo se
+// procesa con VideoUploadService como una subida normal.
+//
+// El tamaño declarado se descuenta de la cuota de almacenamiento al crear la subida y se
+// devuelve si se cancela o caduca.
+//
+// El archivo temporal vive en el disco de la instancia que recibió la subida, así que con
+// varias instancias las peticiones de una misma subida deben llegar a la misma (afinidad en el
+// balanceador) o dir debe ser un volumen compartido.
+type UploadSessionService struct {
+	videoService *VideoUploadService
+	quota        IStorageQuotaService
+	dir          string
+	ttl          time.Duration
+	locks        sync.Map // id -> *sync.Mutex; serializa las escrituras de una subida
+}
+
+// NewUploadSessionService crea el servicio y lanza la limpieza periódica de subidas caducadas.
+func NewUploadSessionService(cfg *config.Config, videoService *VideoUploadService, quota IStorageQuotaService) IUploadSessionService {
+	s := &UploadSessionService{
+		videoService: videoService,
+		quota:        quota,
+		dir:          cfg.UploadSessionDir,
+		ttl:          cfg.UploadSessionTTL,
+	}
+	go s.maintenanceLoop()
+	return s
+}
+
+func (s *UploadSessionService) partPath(id string) string {
+	return filepath.Join(s.dir, id+uploadPartSuffix)
+}
+
+func (s *UploadSessionService) lock(id string) *sync.Mutex {
+	mu, _ := s.locks.LoadOrStore(id, &sync.Mutex{})
+	return mu.(*sync.Mutex)
+}
+
+// removePart borra el archivo temporal de una subida terminada.
+func (s *UploadSessionService) removePart(id string) {
+	if err := os.Remove(s.partPath(id)); err != nil && !os.IsNotExist(err) {
+		logger.Warnf(uploadSessionServiceComponent, "No se pudo borrar la parte de la subida %s: %v", id, err)
+	}
+	s.locks.Delete(id)
+}
+
+// Create registra una subida de size bytes.
+func (s *UploadSessionService) Create(userID int64, req models.CreateUploadSessionRequest) (*models.UploadSession, error) {
+	req.FileName = strings.TrimSpace(filepath.Base(req.FileName))
+	if req.FileName == "" || req.FileName == "." || len(req.FileName) > 255 || req.Size <= 0 || req.Size > MaxVideoSize {
+		return nil, ErrUploadSessionInvalid
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("error creando el directorio de subidas: %w", err)
+	}
+
+	if err := s.quota.Reserve(userID, req.Size); err != nil {
+		return nil, err
+	}
+	id := uuid.NewString()
+	created, err := queries.CreateUploadSession(id, userID, req.FileName, req.Size, time.Now().Add(s.ttl), maxActiveUploadSessions)
+	if err != nil || !created {
+		s.quota.Release(userID, req.Size)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrUploadSessionLimit
+	}
+	logger.Infof(uploadSessionServiceComponent, "Subida %s creada por el usuario %d: %s (%d bytes)", id, userID, req.FileName, req.Size)
+	return s.Get(userID, id)
+}
+
+// Get devuelve una subida del usuario.
+func (s *UploadSessionService) Get(userID int64, id string) (*models.UploadSession, error) {
+	session, err := queries.GetUploadSession(id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && session.UserId != userID) {
+		return nil, ErrUploadSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	session.ChunkSize = MaxUploadChunkSize
+	return session, nil
+}
+
+// openSession devuelve una subida del usuario que admite más bytes.
+func (s *UploadSessionService) openSession(userID int64, id string) (*models.UploadSession, error) {
+	session, err := s.Get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case session.Status == models.UploadSessionProcessing:
+		return nil, ErrUploadSessionBusy
+	case session.Status != models.UploadSessionUploading:
+		return nil, ErrUploadSessionClosed
+	case time.Now().After(session.ExpiresAt):
+		return nil, ErrUploadSessionClosed
+	}
+	return session, nil
+}
+
+// WriteChunk escribe body a partir de offset, que debe coincidir con los bytes ya recibidos,
+// y devuelve el nuevo offset. Si la conexión se corta a mitad de la parte se conservan los
+// bytes recibidos: el cliente consulta el offset y continúa desde ahí. Con
+// ErrUploadOffsetMismatch el offset devuelto es el esperado.
+func (s *UploadSessionService) WriteChunk(userID int64, id string, offset int64, body io.Reader) (int64, error) {
+	mu := s.lock(id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	session, err := s.openSession(userID, id)
+	if err != nil {
+		return 0, err
+	}
+	if offset != session.Offset {
+		return session.Offset, ErrUploadOffsetMismatch
+	}
+
+	file, err := os.OpenFile(s.partPath(id), os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return offset, fmt.Errorf("error abriendo la parte de la subida %s: %w", id, err)
+	}
+	// Si el cuerpo trae más bytes de los que faltan se rechaza la parte entera y se descarta
+	// lo escrito a partir de offset.
+	remaining := session.Size - offset
+	written, copyErr := io.Copy(io.NewOffsetWriter(file, offset), io.LimitReader(body, remaining+1))
+	if written > remaining {
+		if err := file.Truncate(offset); err != nil {
+			logger.Warnf(uploadSessionServiceComponent, "No se pudo recortar la parte de la subida %s: %v", id, err)
+		}
+		file.Close()
+		return offset, ErrUploadChunkTooLarge
+	}
+	closeErr := file.Close()
+	if closeErr != nil && copyErr == nil {
+		copyErr = closeErr
+		written = 0
+	}
+
+	newOffset := offset + written
+	if written > 0 {
+		advanced, err := queries.AdvanceUploadSession(id, offset, newOffset, time.Now().Add(s.ttl))
+		if err != nil {
+			return offset, err
+		}
+		if !advanced {
+			// Se canceló o caducó mientras llegaba la parte.
+			return offset, ErrUploadSessionClosed
+		}
+	}
+	if copyErr != nil {
+		return newOffset, fmt.Errorf("parte de la subida %s interrumpida en %d bytes: %w", id, newOffset, copyErr)
+	}
+	return newOffset, nil
+}
+
+// Complete procesa el archivo de una subida con todos sus bytes como una subida de video
+// normal. Si el procesamiento falla la subida vuelve a estar en curso con el motivo en Error,
+// para que el cliente pueda reintentar sin enviar el archivo otra vez.
+func (s *UploadSessionService) Complete(userID int64, id string) (*UploadVideoDetails, error) {
+	mu := s.lock(id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	session, err := s.openSession(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.Offset != session.Size {
+		return nil, ErrUploadIncomplete
+	}
+	claimed, err := queries.ClaimUploadSession(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrUploadSessionClosed
+	}
+
+	details, err := s.process(session)
+	if err != nil {
+		if releaseErr := queries.ReleaseUploadSession(id, err.Error()); releaseErr != nil {
+			logger.Errorf(uploadSessionServiceComponent, "%v", releaseErr)
+		}
+		// Un archivo en cuarentena no se puede reintentar: se cancela la subida.
+		if errors.Is(err, ErrUploadQuarantined) {
+			if _, closeErr := queries.CloseUploadSession(id, models.UploadSessionCancelled); closeErr != nil {
+				logger.Errorf(uploadSessionServiceComponent, "%v", closeErr)
+			}
+			s.quota.Release(userID, session.Size)
+			s.removePart(id)
+		}
+		return nil, err
+	}
+	if err := queries.CompleteUploadSession(id, details.ID); err != nil {
+		logger.Errorf(uploadSessionServiceComponent, "%v", err)
+	}
+	s.removePart(id)
+	logger.Infof(uploadSessionServiceComponent, "Subida %s del usuario %d completada como video %s", id, userID, details.ID)
+	return details, nil
+}
+
+func (s *UploadSessionService) process(session *models.UploadSession) (*UploadVideoDetails, error) {
+	file, err := os.Open(s.partPath(session.Id))
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo el archivo de la subida %s: %w", session.Id, err)
+	}
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), uploadSessionProcessTimeout)
+	defer cancel()
+	return s.videoService.ProcessAndUploadVideo(ctx, session.UserId, file,
+		&multipart.FileHeader{Filename: session.FileName, Size: session.Size})
+}
+
+// Cancel cancela una subida en curso y borra lo recibido.
+func (s *UploadSessionService) Cancel(userID int64, id string) error {
+	mu := s.lock(id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	session, err := s.Get(userID, id)
+	if err != nil {
+		return err
+	}
+	if session.Status == models.UploadSessionProcessing {
+		return ErrUploadSessionBusy
+	}
+	closed, err := queries.CloseUploadSession(id, models.UploadSessionCancelled)
+	if err != nil {
+		return err
+	}
+	if !closed {
+		return ErrUploadSessionClosed
+	}
+	s.quota.Release(userID, session.Size)
+	s.removePart(id)
+	return nil
+}
+
+// maintenanceLoop marca como caducadas las subidas sin actividad, retoma las que quedaron a
+// medio procesar y borra los archivos temporales sin subida en curso (p.ej. de cuentas
+// eliminadas).
+func (s *UploadSessionService) maintenanceLoop() {
+	s.maintain()
+
+	ticker := time.NewTicker(uploadSessionMaintenanceInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.maintain()
+	}
+}
+
+func (s *UploadSessionService) maintain() {
+	if err := queries.ReleaseStaleUploadSessions(time.Now().Add(-2 * uploadSessionProcessTimeout)); err != nil {
+		logger.Errorf(uploadSessionServiceComponent, "%v", err)
+	}
+
+	expired, err := queries.GetExpiredUploadSessionIDs(time.Now())
+	if err != nil {
+		logger.Errorf(uploadSessionServiceComponent, "No se pudieron obtener las subidas caducadas: %v", err)
+	}
+	for _, id := range expired {
+		if closed, err := queries.CloseUploadSession(id, models.UploadSessionExpired); err != nil {
+			logger.Errorf(uploadSessionServiceComponent, "%v", err)
+		} else if closed {
+			if session, err := queries.GetUploadSession(id); err == nil {
+				s.quota.Release(session.UserId, session.Size)
+			}
+			s.removePart(id)
+		}
+	}
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf(uploadSessionServiceComponent, "No se pudo leer el directorio de subidas: %v", err)
+		}
+		return
+	}
+	var ids []string
+	for _, entry := range entries {
+		if id, ok := strings.CutSuffix(entry.Name(), uploadPartSuffix); ok && !entry.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	open, err := queries.GetOpenUploadSessionIDs(ids)
+	if err != nil {
+		logger.Errorf(uploadSessionServiceComponent, "%v", err)
+		return
+	}
+	for _, id := range ids {
+		if !open[id] {
+			s.removePart(id)
+		}
+	}
+}
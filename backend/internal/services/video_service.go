@@ -1,6 +1,7 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"fmt"
@@ -14,8 +15,8 @@ import (
 	"github.com/davidM20/micro-service-backend-go.git/internal/config"
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
-	"github.com/davidM20/micro-service-backend-go.git/pkg/cloudclient"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/storage"
 	"github.com/google/uuid"
 	"github.com/h2non/filetype"
 	"github.com/h2non/filetype/types"
@@ -23,13 +24,15 @@ import (
 
 // VideoUploadService encapsula la lógica para subir y procesar archivos de video.
 type VideoUploadService struct {
-	db  *sql.DB
-	cfg *config.Config
+	db      *sql.DB
+	cfg     *config.Config
+	store   storage.Storage
+	scanner IUploadScanService
 }
 
 // NewVideoUploadService crea una nueva instancia de VideoUploadService.
-func NewVideoUploadService(db *sql.DB, cfg *config.Config) *VideoUploadService {
-	return &VideoUploadService{db: db, cfg: cfg}
+func NewVideoUploadService(db *sql.DB, cfg *config.Config, store storage.Storage, scanner IUploadScanService) *VideoUploadService {
+	return &VideoUploadService{db: db, cfg: cfg, store: store, scanner: scanner}
 }
 
 // UploadVideoDetails contiene la información del video subido para la respuesta inicial.
@@ -89,6 +92,10 @@ func (s *VideoUploadService) ProcessAndUploadVideo(ctx context.Context, userID i
 		return nil, fmt.Errorf("el tipo de archivo de video no está permitido: %s", kind.MIME.Value)
 	}
 
+	if err := s.scanner.Check(ctx, userID, "video", fileHeader.Filename, fileBytes); err != nil {
+		return nil, err
+	}
+
 	contentID := uuid.New().String()
 	baseFileName := uuid.New().String()
 	fileExtension := strings.ToLower(kind.Extension)
@@ -100,15 +107,13 @@ func (s *VideoUploadService) ProcessAndUploadVideo(ctx context.Context, userID i
 	}
 	gcsOriginalFileName := baseFileName + "." + fileExtension
 
-	mpFile := NewInMemoryMultipartFile(fileBytes, gcsOriginalFileName)
-
-	err = cloudclient.UploadFile(ctx, mpFile, gcsOriginalFileName, kind.MIME.Value)
+	err = s.store.Put(ctx, gcsOriginalFileName, bytes.NewReader(fileBytes), kind.MIME.Value, true)
 	if err != nil {
-		logger.Errorf("ProcessAndUploadVideo", "Error subiendo video original a GCS: %v", err)
-		return nil, fmt.Errorf("error subiendo video original a GCS: %w", err)
+		logger.Errorf("ProcessAndUploadVideo", "Error subiendo video original al almacenamiento: %v", err)
+		return nil, fmt.Errorf("error subiendo video original al almacenamiento: %w", err)
 	}
 
-	gcsOriginalURL := fmt.Sprintf("https://storage.googleapis.com/%s/%s", s.cfg.GCSBucketName, gcsOriginalFileName)
+	gcsOriginalURL := s.store.URL(gcsOriginalFileName)
 
 	multimediaRecord := &models.Multimedia{
 		Id:               uuid.New().String(),
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/audiometa"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/storage"
+	"github.com/google/uuid"
+)
+
+// Errores de las notas de voz.
+var (
+	ErrVoiceNoteInvalid = apperrors.New(apperrors.VoiceNoteInvalid, "La nota de voz debe ser un archivo WebM o M4A válido")
+	ErrMediaNotFound    = apperrors.New(apperrors.MediaNotFound, "Archivo no encontrado")
+	ErrMediaUnavailable = apperrors.New(apperrors.MediaUnavailable, "El almacenamiento de archivos no está disponible")
+)
+
+// voiceNotePrefix es la carpeta de las notas de voz en el almacenamiento. Son objetos privados: solo se
+// reproducen a través de la API, que comprueba el acceso con GetVoiceNote.
+const voiceNotePrefix = "voice-notes/"
+
+// VoiceNoteObjectPath devuelve la ruta en el almacenamiento de la nota de voz fileName.
+func VoiceNoteObjectPath(fileName string) string {
+	return voiceNotePrefix + fileName
+}
+
+// ProcessAndUploadVoiceNote valida una nota de voz (WebM o M4A), extrae su duración, la sube
+// como objeto privado y la registra en Multimedia con Type voice_note. El FileName
+// devuelto es el mediaId que se envía en chat/send_message.
+func (s *AudioUploadService) ProcessAndUploadVoiceNote(ctx context.Context, userID int64, file io.Reader) (*UploadAudioDetails, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer la nota de voz: %w", err)
+	}
+
+	info, err := audiometa.Probe(data)
+	if err != nil {
+		logger.Warnf("ProcessAndUploadVoiceNote", "Nota de voz rechazada del usuario %d (%s): %v", userID, info.Format, err)
+		return nil, ErrVoiceNoteInvalid
+	}
+	if maxDuration := s.cfg.VoiceNoteMaxDuration; maxDuration > 0 && info.Duration > maxDuration {
+		return nil, apperrors.New(apperrors.VoiceNoteTooLong,
+			fmt.Sprintf("La nota de voz dura %s; el máximo es %s", info.Duration.Round(time.Second), maxDuration))
+	}
+	if err := s.scanner.Check(ctx, userID, models.MultimediaTypeVoiceNote, "nota-de-voz."+info.Format, data); err != nil {
+		return nil, err
+	}
+
+	fileName := uuid.New().String() + "." + info.Format
+	if err := s.store.Put(ctx, VoiceNoteObjectPath(fileName), bytes.NewReader(data), info.ContentType, false); err != nil {
+		if errors.Is(err, storage.ErrUnavailable) {
+			return nil, ErrMediaUnavailable
+		}
+		return nil, fmt.Errorf("error subiendo la nota de voz al almacenamiento: %w", err)
+	}
+
+	contentID := uuid.New().String()
+	seconds := info.Duration.Seconds()
+	_, err = queries.InsertMultimedia(s.db, &models.Multimedia{
+		Id:        uuid.New().String(),
+		Type:      models.MultimediaTypeVoiceNote,
+		UserId:    userID,
+		FileName:  fileName,
+		CreateAt:  time.Now(),
+		ContentId: contentID,
+		Size:      sql.NullInt64{Int64: int64(len(data)), Valid: true},
+		Duration:  sql.NullFloat64{Float64: seconds, Valid: true},
+	})
+	if err != nil {
+		if delErr := s.store.Delete(context.Background(), VoiceNoteObjectPath(fileName)); delErr != nil {
+			logger.Warnf("ProcessAndUploadVoiceNote", "No se pudo eliminar la nota de voz huérfana %s: %v", fileName, delErr)
+		}
+		return nil, fmt.Errorf("error guardando registro de la nota de voz en BD: %w", err)
+	}
+
+	logger.Infof("ProcessAndUploadVoiceNote", "Nota de voz subida: UserID %d, FileName %s, %.1fs", userID, fileName, seconds)
+	return &UploadAudioDetails{
+		ID:        contentID,
+		FileName:  fileName,
+		Extension: info.Format,
+		Duration:  seconds,
+	}, nil
+}
+
+// GetVoiceNote devuelve la nota de voz fileName si userID puede reproducirla: la subió o
+// participa en un chat donde se envió. Devuelve ErrMediaNotFound en caso contrario.
+func (s *AudioUploadService) GetVoiceNote(userID int64, fileName string) (*models.Multimedia, error) {
+	media, err := queries.GetAccessibleMultimedia(userID, fileName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMediaNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if media.Type != models.MultimediaTypeVoiceNote {
+		return nil, ErrMediaNotFound
+	}
+	return media, nil
+}
@@ -0,0 +1,90 @@
+package testharness
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// IDs de los registros de los fixtures, para usarlos en las pruebas.
+const (
+	FixtureStudentID  int64 = 1001 // usuarios: estudiante
+	FixtureGraduateID int64 = 1002 // usuarios: egresado
+	FixtureCompanyID  int64 = 1003 // usuarios: empresa
+	FixtureAdminID    int64 = 1004 // usuarios: administrador
+
+	FixtureChatID = "chat-fixture-ana-luis" // contactos: chat aceptado entre estudiante y egresado
+
+	FixtureEventID     int64 = 3001 // eventos: evento de la empresa
+	FixtureChallengeID int64 = 3002 // eventos: desafío abierto
+)
+
+//go:embed fixtures/*.sql
+var fixtureFiles embed.FS
+
+// LoadFixtures ejecuta los fixtures de internal/testharness/fixtures por nombre (sin .sql), en
+// el orden indicado. Los fixtures dependen unos de otros (contactos requiere usuarios).
+func (h *Harness) LoadFixtures(t testing.TB, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		script, err := fixtureFiles.ReadFile("fixtures/" + name + ".sql")
+		if err != nil {
+			t.Fatalf("testharness: fixture %q no existe", name)
+		}
+		if err := h.execScript(context.Background(), string(script)); err != nil {
+			t.Fatalf("testharness: fixture %q: %v", name, err)
+		}
+	}
+}
+
+// LoadFixtureFile ejecuta un archivo SQL propio de la prueba (p. ej. testdata/caso.sql).
+func (h *Harness) LoadFixtureFile(t testing.TB, path string) {
+	t.Helper()
+	script, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("testharness: %v", err)
+	}
+	if err := h.execScript(context.Background(), string(script)); err != nil {
+		t.Fatalf("testharness: %s: %v", path, err)
+	}
+}
+
+// execScript ejecuta las sentencias separadas por ';' de script, como createTables. Los
+// fixtures no pueden tener ';' dentro de los textos.
+func (h *Harness) execScript(ctx context.Context, script string) error {
+	for _, stmt := range strings.Split(script, ";") {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" || isComment(stmt) {
+			continue
+		}
+		if _, err := h.DB.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("error ejecutando %q: %w", firstLine(stmt), err)
+		}
+	}
+	return nil
+}
+
+// isComment indica si la sentencia solo tiene comentarios de línea.
+func isComment(stmt string) bool {
+	for _, line := range strings.Split(stmt, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
+
+// firstLine devuelve la primera línea que no es comentario, para los mensajes de error.
+func firstLine(stmt string) string {
+	for _, line := range strings.Split(stmt, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return line
+		}
+	}
+	return stmt
+}
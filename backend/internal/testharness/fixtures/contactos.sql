@@ -0,0 +1,9 @@
+-- Requiere usuarios. Ana y Luis son contactos con un chat de dos mensajes; Ana tiene una
+-- solicitud pendiente de la empresa.
+INSERT INTO Contact (ContactId, User1Id, User2Id, Status, ChatId) VALUES
+    (2001, 1001, 1002, 'accepted', 'chat-fixture-ana-luis'),
+    (2002, 1003, 1001, 'pending', 'chat-fixture-tepuy-ana');
+
+INSERT INTO Message (Id, ChatId, SenderId, TypeMessageId, Content, SentAt, Status) VALUES
+    ('msg-fixture-1', 'chat-fixture-ana-luis', 1001, 1, 'Hola Luis', '2024-02-01 10:00:00', 'read'),
+    ('msg-fixture-2', 'chat-fixture-ana-luis', 1002, 1, 'Hola Ana', '2024-02-01 10:05:00', 'delivered');
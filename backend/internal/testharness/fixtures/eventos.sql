@@ -0,0 +1,6 @@
+-- Requiere usuarios. Un evento publicado por la empresa y un desafío abierto.
+INSERT INTO CommunityEvent (Id, PostType, Title, Description, EventDate, Location, OrganizerCompanyName, OrganizerUserId, CreatedByUserId, CreatedAt) VALUES
+    (3001, 'EVENTO', 'Charla de Go', 'Introducción a Go para estudiantes', '2024-03-15 18:00:00', 'Mérida', 'Tepuy Tech', 1003, 1003, '2024-02-20 12:00:00');
+
+INSERT INTO CommunityEvent (Id, PostType, Title, Description, ChallengeStartDate, ChallengeEndDate, ChallengeDifficulty, ChallengeStatus, CreatedByUserId, CreatedAt) VALUES
+    (3002, 'DESAFIO', 'Desafío de APIs', 'Construye una API REST', '2024-03-01 00:00:00', '2024-03-31 23:59:59', 'INTERMEDIO', 'ABIERTO', 1003, '2024-02-25 12:00:00');
@@ -0,0 +1,9 @@
+-- Usuarios base: un estudiante, un egresado, una empresa y un administrador, todos activos.
+-- Los IDs coinciden con las constantes Fixture*ID de fixtures.go.
+INSERT INTO User (Id, FirstName, LastName, UserName, Email, RoleId, StatusAuthorizedId, CreatedAt) VALUES
+    (1001, 'Ana', 'Pérez', 'ana.perez', 'ana.perez@fixture.local', 1, 1, '2024-01-10 09:00:00'),
+    (1002, 'Luis', 'Rojas', 'luis.rojas', 'luis.rojas@fixture.local', 2, 1, '2024-01-11 09:00:00'),
+    (1003, NULL, NULL, 'tepuy.tech', 'rrhh@tepuy.fixture.local', 3, 1, '2024-01-12 09:00:00'),
+    (1004, 'Admin', 'Fixture', 'admin.fixture', 'admin@fixture.local', 8, 1, '2024-01-13 09:00:00');
+
+UPDATE User SET CompanyName = 'Tepuy Tech', Sector = 'Tecnología', Location = 'Mérida' WHERE Id = 1003;
@@ -0,0 +1,43 @@
+package testharness
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// updateGolden reescribe los archivos golden: go test ./internal/... -update-golden
+var updateGolden = flag.Bool("update-golden", false, "Reescribe los archivos golden con el resultado actual")
+
+// Golden compara got, serializado como JSON indentado, con testdata/golden/<name>.json del
+// paquete de la prueba. Con -update-golden escribe el archivo en vez de comparar; los cambios
+// se revisan en el diff antes de confirmarlos.
+func Golden(t testing.TB, name string, got interface{}) {
+	t.Helper()
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatalf("testharness: no se pudo serializar el resultado de %s: %v", name, err)
+	}
+	data = append(data, '\n')
+
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("testharness: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("testharness: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("testharness: no se pudo leer %s (genéralo con -update-golden): %v", path, err)
+	}
+	if string(want) != string(data) {
+		t.Errorf("testharness: %s no coincide con %s\n--- esperado\n%s\n--- obtenido\n%s", name, path, want, data)
+	}
+}
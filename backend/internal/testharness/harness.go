@@ -0,0 +1,252 @@
+// Package testharness levanta una base de datos MySQL real para las pruebas de integración de
+// queries y services. Crea el esquema con db.InitializeDatabase (el mismo que al arrancar los
+// servidores), carga fixtures SQL y compara resultados con archivos golden.
+//
+// Uso típico en un paquete de pruebas:
+//
+//	func TestGetEventCreatorID(t *testing.T) {
+//		h := testharness.Shared(t)
+//		h.LoadFixtures(t, "usuarios", "eventos")
+//		creatorID, err := queries.GetEventCreatorID(testharness.FixtureEventID)
+//		...
+//	}
+//
+// Ver docs/pruebas_integracion.md.
+package testharness
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/go-sql-driver/mysql"
+	tcmysql "github.com/testcontainers/testcontainers-go/modules/mysql"
+)
+
+const (
+	// DSNEnv permite usar un MySQL ya levantado en vez de un contenedor, p. ej.
+	// TEST_MYSQL_DSN="root:password@tcp(127.0.0.1:3306)/backend_test". La base de datos se
+	// borra y se vuelve a crear, así que su nombre debe terminar en _test.
+	DSNEnv = "TEST_MYSQL_DSN"
+
+	mysqlImage    = "mysql:8.0"
+	testDatabase  = "backend_test"
+	startupLimit  = 3 * time.Minute
+	containerUser = "test"
+	containerPass = "test"
+)
+
+// Harness es una base de datos de pruebas con el esquema de la aplicación.
+type Harness struct {
+	DB  *sql.DB
+	DSN string
+
+	container *tcmysql.MySQLContainer
+	// tables son las tablas que Reset vacía: las que estaban vacías tras crear el esquema. Las
+	// de catálogo (roles, estados, nacionalidades...) se conservan.
+	tables []string
+}
+
+// Start levanta MySQL (o usa el de TEST_MYSQL_DSN), crea el esquema y apunta queries.DB y
+// db.GetDB a la base de datos de pruebas.
+func Start(ctx context.Context) (*Harness, error) {
+	h := &Harness{DSN: os.Getenv(DSNEnv)}
+	if h.DSN == "" {
+		container, err := runContainer(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo iniciar el contenedor de MySQL (¿Docker está disponible? también puedes usar %s): %w", DSNEnv, err)
+		}
+		h.container = container
+		if h.DSN, err = container.ConnectionString(ctx); err != nil {
+			h.Close(ctx)
+			return nil, fmt.Errorf("no se pudo obtener el DSN del contenedor: %w", err)
+		}
+	} else if err := recreateDatabase(h.DSN); err != nil {
+		return nil, err
+	}
+
+	dsn, err := withParseTime(h.DSN)
+	if err != nil {
+		h.Close(ctx)
+		return nil, err
+	}
+	h.DSN = dsn
+
+	h.DB, err = db.Open(h.DSN, db.PoolConfig{MaxOpenConns: 10, MaxIdleConns: 10})
+	if err != nil {
+		h.Close(ctx)
+		return nil, fmt.Errorf("no se pudo conectar a la base de datos de pruebas: %w", err)
+	}
+	if err := db.InitializeDatabase(h.DB); err != nil {
+		h.Close(ctx)
+		return nil, fmt.Errorf("no se pudo crear el esquema: %w", err)
+	}
+	if err := h.scanTables(ctx); err != nil {
+		h.Close(ctx)
+		return nil, err
+	}
+	h.Use()
+	return h, nil
+}
+
+// runContainer inicia el contenedor de MySQL. testcontainers entra en pánico si no encuentra
+// Docker; se convierte en un error para que Shared pueda omitir la prueba.
+func runContainer(ctx context.Context) (container *tcmysql.MySQLContainer, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%v", rec)
+		}
+	}()
+	startCtx, cancel := context.WithTimeout(ctx, startupLimit)
+	defer cancel()
+	return tcmysql.Run(startCtx, mysqlImage,
+		tcmysql.WithDatabase(testDatabase),
+		tcmysql.WithUsername(containerUser),
+		tcmysql.WithPassword(containerPass),
+	)
+}
+
+// Use apunta queries.DB y db.GetDB a la base de datos de pruebas. Start ya lo hace; solo hace
+// falta si una prueba cambió la conexión global.
+func (h *Harness) Use() {
+	queries.InitDB(h.DB)
+	db.SetDB(h.DB)
+}
+
+// Close cierra la conexión y detiene el contenedor, si lo hay.
+func (h *Harness) Close(ctx context.Context) error {
+	if h.DB != nil {
+		h.DB.Close()
+	}
+	if h.container != nil {
+		return h.container.Terminate(ctx)
+	}
+	return nil
+}
+
+// Reset vacía todas las tablas salvo las de catálogo, para que cada prueba empiece con la base
+// de datos recién creada.
+func (h *Harness) Reset(ctx context.Context) error {
+	// FOREIGN_KEY_CHECKS es de la sesión: todas las sentencias van por la misma conexión
+	conn, err := h.DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("no se pudo obtener una conexión: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		return fmt.Errorf("no se pudieron desactivar las claves foráneas: %w", err)
+	}
+	defer conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1")
+	for _, table := range h.tables {
+		if _, err := conn.ExecContext(ctx, "TRUNCATE TABLE `"+table+"`"); err != nil {
+			return fmt.Errorf("no se pudo vaciar la tabla %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// scanTables separa las tablas que Reset vacía de las de catálogo.
+func (h *Harness) scanTables(ctx context.Context) error {
+	rows, err := h.DB.QueryContext(ctx,
+		"SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME")
+	if err != nil {
+		return fmt.Errorf("no se pudieron listar las tablas: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("no se pudieron listar las tablas: %w", err)
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("no se pudieron listar las tablas: %w", err)
+	}
+
+	h.tables = nil
+	for _, name := range names {
+		var hasRows bool
+		if err := h.DB.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM `"+name+"`)").Scan(&hasRows); err != nil {
+			return fmt.Errorf("no se pudo revisar la tabla %s: %w", name, err)
+		}
+		if !hasRows {
+			h.tables = append(h.tables, name)
+		}
+	}
+	return nil
+}
+
+// withParseTime activa parseTime en el DSN: los modelos escanean DATETIME en time.Time.
+func withParseTime(dsn string) (string, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", fmt.Errorf("DSN de pruebas inválido: %w", err)
+	}
+	cfg.ParseTime = true
+	return cfg.FormatDSN(), nil
+}
+
+// recreateDatabase borra la base de datos de TEST_MYSQL_DSN para que las pruebas empiecen con
+// el esquema recién creado. db.Open la vuelve a crear.
+func recreateDatabase(dsn string) error {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return fmt.Errorf("%s inválido: %w", DSNEnv, err)
+	}
+	if !strings.HasSuffix(cfg.DBName, "_test") {
+		return fmt.Errorf("%s debe apuntar a una base de datos terminada en _test (es %q): se borra al empezar", DSNEnv, cfg.DBName)
+	}
+	dbName := cfg.DBName
+	cfg.DBName = ""
+	server, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		return fmt.Errorf("no se pudo conectar a %s: %w", DSNEnv, err)
+	}
+	defer server.Close()
+	if _, err := server.Exec("DROP DATABASE IF EXISTS `" + dbName + "`"); err != nil {
+		return fmt.Errorf("no se pudo borrar la base de datos %s: %w", dbName, err)
+	}
+	return nil
+}
+
+var shared struct {
+	once    sync.Once
+	harness *Harness
+	err     error
+}
+
+// Shared devuelve la base de datos de pruebas del proceso, que se crea en la primera llamada y
+// se reutiliza en las siguientes, vaciada con Reset. Con -short, o si no se puede levantar
+// MySQL, la prueba se omite en vez de fallar.
+//
+// El contenedor lo elimina testcontainers al terminar el proceso de pruebas.
+func Shared(t testing.TB) *Harness {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("prueba de integración omitida con -short")
+	}
+	shared.once.Do(func() {
+		shared.harness, shared.err = Start(context.Background())
+	})
+	if shared.err != nil {
+		t.Skipf("base de datos de pruebas no disponible: %v", shared.err)
+	}
+
+	h := shared.harness
+	h.Use()
+	if err := h.Reset(context.Background()); err != nil {
+		t.Fatalf("testharness: %v", err)
+	}
+	return h
+}
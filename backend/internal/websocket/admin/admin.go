@@ -1,18 +1,29 @@
 package admin
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"runtime"
+	"sort"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/davidM20/micro-service-backend-go.git/internal/db"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	apiservices "github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/services"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/maintenance"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/scheduler"
 )
 
 // AdminAuth estructura para autenticación admin
@@ -39,20 +50,40 @@ type MetricsCollector struct {
 	UserSessions         map[int64]time.Time
 	DatabaseQueryTimes   []time.Duration
 	LastNDatabaseQueries int // mantener últimas N consultas para promedio
+	QueriesByCaller      map[string]*QueryStats
 
 	// Referencias
 	manager *customws.ConnectionManager[wsmodels.WsUserData]
 	db      *sql.DB
+	live    *liveConsole // Consola en vivo (/admin/ws/live)
 
 	// Timers para cálculos periódicos
 	lastSecondTime time.Time
 	lastMinuteTime time.Time
 }
 
+// QueryStats acumula las consultas lanzadas desde una misma función.
+type QueryStats struct {
+	Caller  string  `json:"caller"`
+	Count   int64   `json:"count"`
+	Slow    int64   `json:"slow"`
+	TotalMs float64 `json:"totalMs"`
+	MaxMs   float64 `json:"maxMs"`
+	AvgMs   float64 `json:"avgMs"`
+}
+
+// maxQueryStatsShown es el número de funciones devueltas en /admin/api/database.
+const maxQueryStatsShown = 20
+
 // AdminHandler maneja todas las rutas administrativas
 type AdminHandler struct {
-	auth      AdminAuth
-	collector *MetricsCollector
+	auth          AdminAuth
+	collector     *MetricsCollector
+	scheduler     *scheduler.Scheduler
+	dbPool        *db.PoolMonitor
+	announcements *services.AnnouncementService
+	maintenance   *maintenance.Mode
+	drainer       *services.ConnectionDrainer
 }
 
 var (
@@ -61,8 +92,18 @@ var (
 	once            sync.Once
 )
 
-// InitializeAdmin inicializa el sistema de administración
-func InitializeAdmin(manager *customws.ConnectionManager[wsmodels.WsUserData], db *sql.DB, adminUser, adminPass string) *AdminHandler {
+// metricsJobName es el job del scheduler que recalcula las métricas por segundo/minuto.
+const metricsJobName = "admin-metrics"
+
+// metricsPushJobName es el job que envía las métricas a los suscriptores de admin:metrics.
+const (
+	metricsPushJobName  = "admin-metrics-push"
+	metricsPushInterval = 5 * time.Second
+)
+
+// InitializeAdmin inicializa el sistema de administración y registra el cálculo
+// periódico de métricas en el scheduler (que debe iniciarse después).
+func InitializeAdmin(manager *customws.ConnectionManager[wsmodels.WsUserData], dbConn *sql.DB, dbPool *db.PoolMonitor, jobs *scheduler.Scheduler, announcements *services.AnnouncementService, maintenanceMode *maintenance.Mode, drainer *services.ConnectionDrainer, adminUser, adminPass string) *AdminHandler {
 	once.Do(func() {
 		globalCollector = &MetricsCollector{
 			ErrorsByType:         make(map[string]int64),
@@ -70,14 +111,21 @@ func InitializeAdmin(manager *customws.ConnectionManager[wsmodels.WsUserData], d
 			UserSessions:         make(map[int64]time.Time),
 			DatabaseQueryTimes:   make([]time.Duration, 0, 100), // Buffer para 100 consultas
 			LastNDatabaseQueries: 100,
+			QueriesByCaller:      make(map[string]*QueryStats),
 			manager:              manager,
-			db:                   db,
+			db:                   dbConn,
+			live:                 newLiveConsole(),
 			lastSecondTime:       time.Now(),
 			lastMinuteTime:       time.Now(),
 		}
 
-		// Iniciar goroutine para calcular métricas periódicas
-		go globalCollector.startMetricsCalculation()
+		// Registrar el cálculo periódico de métricas
+		if err := jobs.Register(metricsJobName, "@every 1s", globalCollector.calculateMetrics, scheduler.WithQuiet()); err != nil {
+			logger.Errorf("ADMIN", "No se pudo registrar el cálculo de métricas: %v", err)
+		}
+		if err := jobs.Register(metricsPushJobName, "@every "+metricsPushInterval.String(), globalCollector.publishMetrics, scheduler.WithQuiet()); err != nil {
+			logger.Errorf("ADMIN", "No se pudo registrar el envío de métricas por WebSocket: %v", err)
+		}
 
 		logger.Info("ADMIN", "Sistema de administración inicializado")
 	})
@@ -87,7 +135,12 @@ func InitializeAdmin(manager *customws.ConnectionManager[wsmodels.WsUserData], d
 			Username: adminUser,
 			Password: adminPass,
 		},
-		collector: globalCollector,
+		collector:     globalCollector,
+		scheduler:     jobs,
+		dbPool:        dbPool,
+		announcements: announcements,
+		maintenance:   maintenanceMode,
+		drainer:       drainer,
 	}
 }
 
@@ -111,46 +164,78 @@ func (ah *AdminHandler) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 
 // RegisterAdminRoutes registra todas las rutas administrativas
 func (ah *AdminHandler) RegisterAdminRoutes(mux *http.ServeMux) {
-	// Dashboard principal
+	// Dashboard principal y sus archivos estáticos (embebidos, ver assets.go)
 	mux.HandleFunc("/admin", ah.RequireAuth(ah.HandleDashboard))
+	mux.HandleFunc("/admin/static/", ah.RequireAuth(ah.HandleStatic))
 
 	// API endpoints
+	mux.HandleFunc("/admin/api/config", ah.RequireAuth(ah.HandleConfigAPI))
 	mux.HandleFunc("/admin/api/metrics", ah.RequireAuth(ah.HandleMetricsAPI))
 	mux.HandleFunc("/admin/api/connections", ah.RequireAuth(ah.HandleConnectionsAPI))
+	mux.HandleFunc("/admin/api/connections/disconnect", ah.RequireAuth(ah.HandleForceDisconnectAPI))
 	mux.HandleFunc("/admin/api/users", ah.RequireAuth(ah.HandleUsersAPI))
 	mux.HandleFunc("/admin/api/errors", ah.RequireAuth(ah.HandleErrorsAPI))
 	mux.HandleFunc("/admin/api/system", ah.RequireAuth(ah.HandleSystemAPI))
+	mux.HandleFunc("/admin/api/jobs", ah.RequireAuth(ah.HandleJobsAPI))
+	mux.HandleFunc("/admin/api/database", ah.RequireAuth(ah.HandleDatabaseAPI))
+	mux.HandleFunc("/admin/api/announcements", ah.RequireAuth(ah.HandleAnnouncementsAPI))
+	mux.HandleFunc("/admin/api/announcements/cancel", ah.RequireAuth(ah.HandleCancelAnnouncementAPI))
+	mux.HandleFunc("/admin/api/maintenance", ah.RequireAuth(ah.HandleMaintenanceAPI))
+	mux.HandleFunc("/admin/api/drain", ah.RequireAuth(ah.HandleDrainAPI))
+	mux.HandleFunc("/admin/ws/live", ah.RequireAuth(ah.HandleLiveConsole))
 
 	logger.Info("ADMIN", "Rutas administrativas registradas")
 }
 
-// HandleDashboard sirve el dashboard HTML principal
-func (ah *AdminHandler) HandleDashboard(w http.ResponseWriter, r *http.Request) {
-	html := ah.generateDashboardHTML()
-	w.Header().Set("Content-Type", "text/html")
-	w.Write([]byte(html))
-}
-
 // HandleMetricsAPI devuelve métricas generales
 func (ah *AdminHandler) HandleMetricsAPI(w http.ResponseWriter, r *http.Request) {
-	ah.collector.mutex.RLock()
-	defer ah.collector.mutex.RUnlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ah.collector.metricsSnapshot())
+}
+
+// metricsSnapshot devuelve las métricas generales, con copias de los mapas para poder
+// serializarlas fuera del mutex.
+func (mc *MetricsCollector) metricsSnapshot() map[string]interface{} {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	errorsByType := make(map[string]int64, len(mc.ErrorsByType))
+	for k, v := range mc.ErrorsByType {
+		errorsByType[k] = v
+	}
+	messagesByType := make(map[string]int64, len(mc.MessagesByType))
+	for k, v := range mc.MessagesByType {
+		messagesByType[k] = v
+	}
 
-	metrics := map[string]interface{}{
-		"activeConnections":    ah.getActiveConnectionsCount(),
-		"totalConnections":     atomic.LoadInt64(&ah.collector.TotalConnections),
-		"totalMessages":        atomic.LoadInt64(&ah.collector.TotalMessages),
-		"totalErrors":          atomic.LoadInt64(&ah.collector.TotalErrors),
-		"messagesPerSecond":    atomic.LoadInt64(&ah.collector.MessagesPerSecond),
-		"connectionsPerMinute": atomic.LoadInt64(&ah.collector.ConnectionsPerMinute),
-		"errorsByType":         ah.collector.ErrorsByType,
-		"messagesByType":       ah.collector.MessagesByType,
-		"averageQueryTime":     ah.collector.getAverageQueryTime(),
+	return map[string]interface{}{
+		"activeConnections":    int64(len(mc.UserSessions)),
+		"totalConnections":     atomic.LoadInt64(&mc.TotalConnections),
+		"totalMessages":        atomic.LoadInt64(&mc.TotalMessages),
+		"totalErrors":          atomic.LoadInt64(&mc.TotalErrors),
+		"messagesPerSecond":    atomic.LoadInt64(&mc.MessagesPerSecond),
+		"connectionsPerMinute": atomic.LoadInt64(&mc.ConnectionsPerMinute),
+		"errorsByType":         errorsByType,
+		"messagesByType":       messagesByType,
+		"averageQueryTime":     mc.getAverageQueryTime(),
+		"subscriptions":        mc.manager.Subscriptions().SubscriberCounts(),
+		"idleEvictions":        mc.manager.IdleEvictions(),
 		"timestamp":            time.Now().Unix(),
 	}
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(metrics)
+// publishMetrics envía las métricas a los administradores suscritos al tópico
+// admin:metrics. Si no hay suscriptores no hace nada.
+func (mc *MetricsCollector) publishMetrics(ctx context.Context) error {
+	subscriptions := mc.manager.Subscriptions()
+	if !subscriptions.HasSubscribers(wsmodels.TopicAdminMetrics) {
+		return nil
+	}
+	subscriptions.Publish(wsmodels.TopicAdminMetrics, types.ServerToClientMessage{
+		Type:    types.MessageTypeAdminMetrics,
+		Payload: mc.metricsSnapshot(),
+	})
+	return nil
 }
 
 // HandleConnectionsAPI devuelve información de conexiones activas
@@ -166,6 +251,50 @@ func (ah *AdminHandler) HandleConnectionsAPI(w http.ResponseWriter, r *http.Requ
 	}
 	ah.collector.mutex.RUnlock()
 
+	// Dispositivo y ubicación de cada conexión del usuario (puede tener varias abiertas)
+	for _, session := range sessions {
+		entry := session.(map[string]interface{})
+		conns, _ := ah.collector.manager.GetConnections(entry["userId"].(int64))
+		devices := make([]map[string]interface{}, 0, len(conns))
+		for _, conn := range conns {
+			device := conn.UserData.Device
+			info := map[string]interface{}{
+				"ip":         conn.UserData.IP,
+				"deviceType": device.DeviceType,
+				"browser":    device.Browser,
+				"os":         device.OS,
+				"userAgent":  device.UserAgent,
+				"country":    device.Country,
+				"city":       device.City,
+			}
+			// Tráfico de la conexión: mensajes, bytes, cola de envío, acks sin respuesta y latencia
+			stats := conn.Stats()
+			info["stats"] = map[string]interface{}{
+				"connectedAt":      stats.ConnectedAt.Unix(),
+				"lastActivity":     stats.LastActivity.Unix(),
+				"idleSeconds":      time.Since(stats.LastActivity).Seconds(),
+				"messagesReceived": stats.MessagesReceived,
+				"bytesReceived":    stats.BytesReceived,
+				"messagesSent":     stats.MessagesSent,
+				"bytesSent":        stats.BytesSent,
+				"sendQueueDepth":   stats.SendQueueDepth,
+				"sendQueueSize":    stats.SendQueueSize,
+				"ackTimeouts":      stats.AckTimeouts,
+				"ackRetries":       stats.AckRetries,
+				"deadLetters":      stats.DeadLetters,
+				"rttMs":            stats.RTTMillis,
+				"ackReplays":       stats.AckReplays,
+				"pidRejections":    stats.PIDRejections,
+			}
+			// Las conexiones abiertas por el soporte con un token de suplantación se marcan
+			if conn.UserData.IsImpersonated() {
+				info["impersonatorId"] = conn.UserData.ImpersonatorID
+			}
+			devices = append(devices, info)
+		}
+		entry["devices"] = devices
+	}
+
 	response := map[string]interface{}{
 		"activeConnections": ah.getActiveConnectionsCount(),
 		"sessions":          sessions,
@@ -176,6 +305,46 @@ func (ah *AdminHandler) HandleConnectionsAPI(w http.ResponseWriter, r *http.Requ
 	json.NewEncoder(w).Encode(response)
 }
 
+// HandleForceDisconnectAPI cierra todas las conexiones de un usuario (POST ?userId=N)
+// y registra la acción en el AuditLog
+func (ah *AdminHandler) HandleForceDisconnectAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := strconv.ParseInt(r.URL.Query().Get("userId"), 10, 64)
+	if err != nil {
+		http.Error(w, "userId inválido", http.StatusBadRequest)
+		return
+	}
+
+	conns, found := ah.collector.manager.GetConnections(userID)
+	if !found {
+		http.Error(w, "El usuario no tiene conexiones activas", http.StatusNotFound)
+		return
+	}
+	for _, conn := range conns {
+		conn.CloseWithReason(customws.CloseKicked, types.CloseReason{Reason: "kicked"})
+	}
+
+	adminName, _, _ := r.BasicAuth()
+	apiservices.RecordAudit(r, models.AuditLog{
+		ActorName:  adminName,
+		Action:     models.AuditActionForceDisconnect,
+		TargetType: models.AuditTargetUser,
+		TargetId:   strconv.FormatInt(userID, 10),
+	}, map[string]interface{}{"connections": len(conns)})
+	logger.Warnf("ADMIN", "Admin %s desconectó a la fuerza al usuario %d (%d conexiones)", adminName, userID, len(conns))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"userId":       userID,
+		"disconnected": len(conns),
+		"timestamp":    time.Now().Unix(),
+	})
+}
+
 // HandleUsersAPI devuelve estadísticas de usuarios
 func (ah *AdminHandler) HandleUsersAPI(w http.ResponseWriter, r *http.Request) {
 	// Consultar estadísticas de usuarios desde la BD
@@ -209,6 +378,34 @@ func (ah *AdminHandler) HandleErrorsAPI(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(response)
 }
 
+// HandleJobsAPI devuelve las métricas de los jobs del scheduler
+func (ah *AdminHandler) HandleJobsAPI(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"jobs":      ah.scheduler.Stats(),
+		"timestamp": time.Now().Unix(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleDatabaseAPI devuelve el estado del pool de conexiones a la BD, el del modo solo
+// lectura, las funciones con más tiempo acumulado en consultas y las métricas de la caché de
+// consultas. alert indica que en el último intervalo de muestreo hubo más esperas por conexión
+// que el umbral configurado.
+func (ah *AdminHandler) HandleDatabaseAPI(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"pool":      ah.dbPool.Snapshot(),
+		"readOnly":  db.CurrentReadOnlyState(),
+		"queries":   ah.collector.topQueries(maxQueryStatsShown),
+		"cache":     queries.CacheStats(),
+		"timestamp": time.Now().Unix(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 // HandleSystemAPI devuelve métricas del sistema
 func (ah *AdminHandler) HandleSystemAPI(w http.ResponseWriter, r *http.Request) {
 	var m runtime.MemStats
@@ -233,22 +430,30 @@ func (ah *AdminHandler) HandleSystemAPI(w http.ResponseWriter, r *http.Request)
 // Métodos del MetricsCollector
 
 // RecordMessage registra un mensaje procesado
-func (mc *MetricsCollector) RecordMessage(messageType string) {
+func (mc *MetricsCollector) RecordMessage(userID int64, messageType string) {
 	atomic.AddInt64(&mc.TotalMessages, 1)
 	atomic.AddInt64(&mc.LastSecondMessages, 1)
 
 	mc.mutex.Lock()
 	mc.MessagesByType[messageType]++
 	mc.mutex.Unlock()
+
+	mc.live.countMessage(userID, messageType)
 }
 
-// RecordError registra un error
-func (mc *MetricsCollector) RecordError(errorType string) {
+// RecordError registra un error al procesar un mensaje de userID
+func (mc *MetricsCollector) RecordError(userID int64, errorType string, err error) {
 	atomic.AddInt64(&mc.TotalErrors, 1)
 
 	mc.mutex.Lock()
 	mc.ErrorsByType[errorType]++
 	mc.mutex.Unlock()
+
+	detail := errorType
+	if err != nil {
+		detail += ": " + err.Error()
+	}
+	mc.live.publish(LiveEvent{Type: LiveEventError, UserID: userID, Detail: detail})
 }
 
 // RecordConnection registra una nueva conexión
@@ -259,13 +464,24 @@ func (mc *MetricsCollector) RecordConnection(userID int64) {
 	mc.mutex.Lock()
 	mc.UserSessions[userID] = time.Now()
 	mc.mutex.Unlock()
+
+	mc.live.publish(LiveEvent{Type: LiveEventConnect, UserID: userID})
 }
 
-// RecordDisconnection registra una desconexión
-func (mc *MetricsCollector) RecordDisconnection(userID int64) {
-	mc.mutex.Lock()
-	delete(mc.UserSessions, userID)
-	mc.mutex.Unlock()
+// RecordDisconnection registra la desconexión de un dispositivo. La sesión del usuario
+// termina cuando se desconecta el último (lastDevice).
+func (mc *MetricsCollector) RecordDisconnection(userID int64, lastDevice bool, err error) {
+	if lastDevice {
+		mc.mutex.Lock()
+		delete(mc.UserSessions, userID)
+		mc.mutex.Unlock()
+	}
+
+	event := LiveEvent{Type: LiveEventDisconnect, UserID: userID}
+	if err != nil {
+		event.Detail = err.Error()
+	}
+	mc.live.publish(event)
 }
 
 // RecordDatabaseQuery registra el tiempo de una consulta a BD
@@ -280,26 +496,65 @@ func (mc *MetricsCollector) RecordDatabaseQuery(duration time.Duration) {
 	mc.DatabaseQueryTimes = append(mc.DatabaseQueryTimes, duration)
 }
 
-// startMetricsCalculation inicia el cálculo periódico de métricas
-func (mc *MetricsCollector) startMetricsCalculation() {
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
+// RecordQuery registra una consulta medida por el driver instrumentado (db.QueryRecorder).
+func (mc *MetricsCollector) RecordQuery(caller string, duration time.Duration, slow bool) {
+	mc.RecordDatabaseQuery(duration)
 
-	for range ticker.C {
-		now := time.Now()
+	ms := float64(duration.Microseconds()) / 1000
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
 
-		// Calcular mensajes por segundo
-		if now.Sub(mc.lastSecondTime) >= time.Second {
-			atomic.StoreInt64(&mc.MessagesPerSecond, atomic.SwapInt64(&mc.LastSecondMessages, 0))
-			mc.lastSecondTime = now
-		}
+	stats, exists := mc.QueriesByCaller[caller]
+	if !exists {
+		stats = &QueryStats{Caller: caller}
+		mc.QueriesByCaller[caller] = stats
+	}
+	stats.Count++
+	stats.TotalMs += ms
+	if ms > stats.MaxMs {
+		stats.MaxMs = ms
+	}
+	if slow {
+		stats.Slow++
+	}
+}
 
-		// Calcular conexiones por minuto
-		if now.Sub(mc.lastMinuteTime) >= time.Minute {
-			atomic.StoreInt64(&mc.ConnectionsPerMinute, atomic.SwapInt64(&mc.LastMinuteConnections, 0))
-			mc.lastMinuteTime = now
-		}
+// topQueries devuelve las funciones con más tiempo acumulado en consultas.
+func (mc *MetricsCollector) topQueries(limit int) []QueryStats {
+	mc.mutex.RLock()
+	result := make([]QueryStats, 0, len(mc.QueriesByCaller))
+	for _, stats := range mc.QueriesByCaller {
+		s := *stats
+		s.AvgMs = s.TotalMs / float64(s.Count)
+		result = append(result, s)
+	}
+	mc.mutex.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].TotalMs > result[j].TotalMs })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// calculateMetrics recalcula las métricas periódicas; el scheduler la ejecuta cada segundo
+func (mc *MetricsCollector) calculateMetrics(ctx context.Context) error {
+	now := time.Now()
+
+	// Calcular mensajes por segundo
+	if now.Sub(mc.lastSecondTime) >= time.Second {
+		atomic.StoreInt64(&mc.MessagesPerSecond, atomic.SwapInt64(&mc.LastSecondMessages, 0))
+		mc.lastSecondTime = now
 	}
+
+	// Calcular conexiones por minuto
+	if now.Sub(mc.lastMinuteTime) >= time.Minute {
+		atomic.StoreInt64(&mc.ConnectionsPerMinute, atomic.SwapInt64(&mc.LastMinuteConnections, 0))
+		mc.lastMinuteTime = now
+	}
+
+	mc.live.flushMessageCounts()
+	return nil
 }
 
 // getAverageQueryTime calcula el tiempo promedio de consultas a BD
@@ -353,449 +608,6 @@ func bToMb(b uint64) uint64 {
 	return b / 1024 / 1024
 }
 
-// generateDashboardHTML genera el HTML del dashboard administrativo
-func (ah *AdminHandler) generateDashboardHTML() string {
-	return `<!DOCTYPE html>
-<html lang="es">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>WebSocket Server - Panel de Administración</title>
-    <style>
-        * {
-            margin: 0;
-            padding: 0;
-            box-sizing: border-box;
-        }
-        
-        body {
-            font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif;
-            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
-            min-height: 100vh;
-            color: #333;
-        }
-        
-        .container {
-            max-width: 1400px;
-            margin: 0 auto;
-            padding: 20px;
-        }
-        
-        .header {
-            background: rgba(255, 255, 255, 0.95);
-            padding: 20px;
-            border-radius: 10px;
-            margin-bottom: 30px;
-            box-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);
-        }
-        
-        .header h1 {
-            color: #2c3e50;
-            margin-bottom: 10px;
-        }
-        
-        .header p {
-            color: #7f8c8d;
-            font-size: 14px;
-        }
-        
-        .metrics-grid {
-            display: grid;
-            grid-template-columns: repeat(auto-fit, minmax(300px, 1fr));
-            gap: 20px;
-            margin-bottom: 30px;
-        }
-        
-        .metric-card {
-            background: rgba(255, 255, 255, 0.95);
-            border-radius: 10px;
-            padding: 20px;
-            box-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);
-            transition: transform 0.2s;
-        }
-        
-        .metric-card:hover {
-            transform: translateY(-2px);
-        }
-        
-        .metric-card h3 {
-            color: #2c3e50;
-            margin-bottom: 15px;
-            font-size: 18px;
-            border-bottom: 2px solid #3498db;
-            padding-bottom: 5px;
-        }
-        
-        .metric-value {
-            font-size: 32px;
-            font-weight: bold;
-            color: #3498db;
-            margin: 10px 0;
-        }
-        
-        .metric-label {
-            color: #7f8c8d;
-            font-size: 14px;
-        }
-        
-        .metric-list {
-            list-style: none;
-        }
-        
-        .metric-list li {
-            padding: 5px 0;
-            border-bottom: 1px solid #ecf0f1;
-            display: flex;
-            justify-content: space-between;
-        }
-        
-        .metric-list li:last-child {
-            border-bottom: none;
-        }
-        
-        .status-indicator {
-            display: inline-block;
-            width: 10px;
-            height: 10px;
-            border-radius: 50%;
-            margin-right: 10px;
-        }
-        
-        .status-online {
-            background: #27ae60;
-        }
-        
-        .status-warning {
-            background: #f39c12;
-        }
-        
-        .status-error {
-            background: #e74c3c;
-        }
-        
-        .refresh-btn {
-            background: #3498db;
-            color: white;
-            border: none;
-            padding: 10px 20px;
-            border-radius: 5px;
-            cursor: pointer;
-            font-size: 14px;
-            margin: 10px 5px;
-            transition: background 0.2s;
-        }
-        
-        .refresh-btn:hover {
-            background: #2980b9;
-        }
-        
-        .auto-refresh {
-            color: #27ae60;
-            font-size: 12px;
-            margin-left: 10px;
-        }
-        
-        .chart-container {
-            background: rgba(255, 255, 255, 0.95);
-            border-radius: 10px;
-            padding: 20px;
-            margin-bottom: 20px;
-            box-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);
-        }
-        
-        .sessions-table {
-            width: 100%;
-            border-collapse: collapse;
-            margin-top: 15px;
-        }
-        
-        .sessions-table th,
-        .sessions-table td {
-            padding: 10px;
-            text-align: left;
-            border-bottom: 1px solid #ecf0f1;
-        }
-        
-        .sessions-table th {
-            background: #f8f9fa;
-            font-weight: 600;
-            color: #2c3e50;
-        }
-        
-        .sessions-table tr:hover {
-            background: #f8f9fa;
-        }
-        
-        .error-badge {
-            background: #e74c3c;
-            color: white;
-            padding: 2px 8px;
-            border-radius: 12px;
-            font-size: 12px;
-        }
-        
-        .success-badge {
-            background: #27ae60;
-            color: white;
-            padding: 2px 8px;
-            border-radius: 12px;
-            font-size: 12px;
-        }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>🔧 Panel de Administración - Servidor WebSocket</h1>
-            <p>Monitoreo en tiempo real del servidor de chat</p>
-            <button class="refresh-btn" onclick="refreshAll()">🔄 Actualizar Todo</button>
-            <button class="refresh-btn" onclick="toggleAutoRefresh()" id="autoRefreshBtn">⏱️ Auto-actualizar: OFF</button>
-            <span class="auto-refresh" id="autoRefreshStatus"></span>
-        </div>
-
-        <div class="metrics-grid">
-            <!-- Métricas Generales -->
-            <div class="metric-card">
-                <h3>📊 Métricas Generales</h3>
-                <div class="metric-value" id="activeConnections">-</div>
-                <div class="metric-label">Conexiones Activas</div>
-                <div style="margin-top: 15px;">
-                    <div><strong>Total Conexiones:</strong> <span id="totalConnections">-</span></div>
-                    <div><strong>Total Mensajes:</strong> <span id="totalMessages">-</span></div>
-                    <div><strong>Mensajes/seg:</strong> <span id="messagesPerSecond">-</span></div>
-                </div>
-            </div>
-
-            <!-- Estado del Sistema -->
-            <div class="metric-card">
-                <h3>💻 Estado del Sistema</h3>
-                <div class="metric-value" id="goroutines">-</div>
-                <div class="metric-label">Goroutines Activas</div>
-                <div style="margin-top: 15px;">
-                    <div><strong>Memoria (MB):</strong> <span id="memoryAlloc">-</span></div>
-                    <div><strong>GC Runs:</strong> <span id="numGC">-</span></div>
-                    <div><strong>Query Avg (ms):</strong> <span id="avgQueryTime">-</span></div>
-                </div>
-            </div>
-
-            <!-- Usuarios -->
-            <div class="metric-card">
-                <h3>👥 Estadísticas de Usuarios</h3>
-                <div class="metric-value" id="onlineUsers">-</div>
-                <div class="metric-label">Usuarios Online</div>
-                <div style="margin-top: 15px;">
-                    <div><strong>Total Usuarios:</strong> <span id="totalUsers">-</span></div>
-                    <div><strong>Activos 24h:</strong> <span id="recentUsers">-</span></div>
-                </div>
-            </div>
-
-            <!-- Errores -->
-            <div class="metric-card">
-                <h3>🚨 Errores</h3>
-                <div class="metric-value" id="totalErrors">-</div>
-                <div class="metric-label">Total de Errores</div>
-                <ul class="metric-list" id="errorsByType">
-                    <li>Cargando...</li>
-                </ul>
-            </div>
-        </div>
-
-        <!-- Tipos de Mensajes -->
-        <div class="chart-container">
-            <h3>📨 Tipos de Mensajes</h3>
-            <ul class="metric-list" id="messagesByType">
-                <li>Cargando...</li>
-            </ul>
-        </div>
-
-        <!-- Sesiones Activas -->
-        <div class="chart-container">
-            <h3>🔗 Sesiones Activas</h3>
-            <table class="sessions-table">
-                <thead>
-                    <tr>
-                        <th>Usuario ID</th>
-                        <th>Tiempo Conectado</th>
-                        <th>Conectado Desde</th>
-                        <th>Estado</th>
-                    </tr>
-                </thead>
-                <tbody id="sessionsTable">
-                    <tr><td colspan="4">Cargando...</td></tr>
-                </tbody>
-            </table>
-        </div>
-    </div>
-
-    <script>
-        let autoRefresh = false;
-        let autoRefreshInterval;
-
-        function formatBytes(bytes) {
-            return bytes + ' MB';
-        }
-
-        function formatDuration(seconds) {
-            const hours = Math.floor(seconds / 3600);
-            const minutes = Math.floor((seconds % 3600) / 60);
-            const secs = Math.floor(seconds % 60);
-            
-            if (hours > 0) {
-                return hours + 'h ' + minutes + 'm';
-            } else if (minutes > 0) {
-                return minutes + 'm ' + secs + 's';
-            } else {
-                return secs + 's';
-            }
-        }
-
-        function formatTimestamp(timestamp) {
-            return new Date(timestamp * 1000).toLocaleString();
-        }
-
-        async function fetchMetrics() {
-            try {
-                const response = await fetch('/admin/api/metrics');
-                const data = await response.json();
-                
-                document.getElementById('activeConnections').textContent = data.activeConnections;
-                document.getElementById('totalConnections').textContent = data.totalConnections;
-                document.getElementById('totalMessages').textContent = data.totalMessages;
-                document.getElementById('totalErrors').textContent = data.totalErrors;
-                document.getElementById('messagesPerSecond').textContent = data.messagesPerSecond;
-
-                // Mensajes por tipo
-                const messagesList = document.getElementById('messagesByType');
-                messagesList.innerHTML = '';
-                for (const [type, count] of Object.entries(data.messagesByType || {})) {
-                    const li = document.createElement('li');
-                    li.innerHTML = '<span>' + type + '</span><span>' + count + '</span>';
-                    messagesList.appendChild(li);
-                }
-                
-                if (Object.keys(data.messagesByType || {}).length === 0) {
-                    messagesList.innerHTML = '<li>No hay datos disponibles</li>';
-                }
-
-            } catch (error) {
-                console.error('Error fetching metrics:', error);
-            }
-        }
-
-        async function fetchSystemInfo() {
-            try {
-                const response = await fetch('/admin/api/system');
-                const data = await response.json();
-                
-                document.getElementById('goroutines').textContent = data.goroutines;
-                document.getElementById('memoryAlloc').textContent = formatBytes(data.memory.allocMB);
-                document.getElementById('numGC').textContent = data.memory.numGC;
-                document.getElementById('avgQueryTime').textContent = data.averageQueryMs + ' ms';
-
-            } catch (error) {
-                console.error('Error fetching system info:', error);
-            }
-        }
-
-        async function fetchUsers() {
-            try {
-                const response = await fetch('/admin/api/users');
-                const data = await response.json();
-                
-                document.getElementById('onlineUsers').textContent = data.onlineUsers;
-                document.getElementById('totalUsers').textContent = data.totalUsers;
-                document.getElementById('recentUsers').textContent = data.recentUsers24h;
-
-            } catch (error) {
-                console.error('Error fetching users:', error);
-            }
-        }
-
-        async function fetchErrors() {
-            try {
-                const response = await fetch('/admin/api/errors');
-                const data = await response.json();
-                
-                document.getElementById('totalErrors').textContent = data.totalErrors;
-
-                // Errores por tipo
-                const errorsList = document.getElementById('errorsByType');
-                errorsList.innerHTML = '';
-                for (const [type, count] of Object.entries(data.errorsByType || {})) {
-                    const li = document.createElement('li');
-                    li.innerHTML = '<span>' + type + '</span><span class="error-badge">' + count + '</span>';
-                    errorsList.appendChild(li);
-                }
-                
-                if (Object.keys(data.errorsByType || {}).length === 0) {
-                    errorsList.innerHTML = '<li>No hay errores registrados</li>';
-                }
-
-            } catch (error) {
-                console.error('Error fetching errors:', error);
-            }
-        }
-
-        async function fetchConnections() {
-            try {
-                const response = await fetch('/admin/api/connections');
-                const data = await response.json();
-                
-                const table = document.getElementById('sessionsTable');
-                table.innerHTML = '';
-                
-                for (const [userId, session] of Object.entries(data.sessions || {})) {
-                    const row = table.insertRow();
-                    row.innerHTML = 
-                        '<td>' + session.userId + '</td>' +
-                        '<td>' + formatDuration(session.duration) + '</td>' +
-                        '<td>' + formatTimestamp(session.connectedAt) + '</td>' +
-                        '<td><span class="status-indicator status-online"></span>Online</td>';
-                }
-                
-                if (Object.keys(data.sessions || {}).length === 0) {
-                    const row = table.insertRow();
-                    row.innerHTML = '<td colspan="4">No hay sesiones activas</td>';
-                }
-
-            } catch (error) {
-                console.error('Error fetching connections:', error);
-            }
-        }
-
-        function refreshAll() {
-            fetchMetrics();
-            fetchSystemInfo();
-            fetchUsers();
-            fetchErrors();
-            fetchConnections();
-        }
-
-        function toggleAutoRefresh() {
-            autoRefresh = !autoRefresh;
-            const btn = document.getElementById('autoRefreshBtn');
-            const status = document.getElementById('autoRefreshStatus');
-            
-            if (autoRefresh) {
-                btn.textContent = '⏱️ Auto-actualizar: ON';
-                btn.style.background = '#27ae60';
-                status.textContent = '(actualiza cada 5 segundos)';
-                autoRefreshInterval = setInterval(refreshAll, 5000);
-            } else {
-                btn.textContent = '⏱️ Auto-actualizar: OFF';
-                btn.style.background = '#3498db';
-                status.textContent = '';
-                clearInterval(autoRefreshInterval);
-            }
-        }
-
-        // Cargar datos iniciales
-        refreshAll();
-    </script>
-</body>
-</html>`
-}
-
 // getActiveConnectionsCount cuenta manualmente las conexiones activas
 func (ah *AdminHandler) getActiveConnectionsCount() int64 {
 	ah.collector.mutex.RLock()
@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	apiservices "github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// announcementsListLimit es el número de anuncios devueltos por GET /admin/api/announcements.
+const announcementsListLimit = 50
+
+// HandleAnnouncementsAPI lista los últimos anuncios del sistema (GET) o crea uno (POST con
+// models.CreateAnnouncementRequest). Sin scheduledAt el anuncio se envía en el momento.
+func (ah *AdminHandler) HandleAnnouncementsAPI(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		announcements, err := ah.announcements.List(announcementsListLimit)
+		if err != nil {
+			logger.Errorf("ADMIN", "Error listando anuncios: %v", err)
+			http.Error(w, "Error obteniendo los anuncios", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"announcements": announcements})
+
+	case http.MethodPost:
+		var req models.CreateAnnouncementRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Cuerpo inválido", http.StatusBadRequest)
+			return
+		}
+		adminName, _, _ := r.BasicAuth()
+		announcement, err := ah.announcements.Create(req, adminName)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidAnnouncement) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			logger.Errorf("ADMIN", "Error creando anuncio: %v", err)
+			http.Error(w, "Error creando el anuncio", http.StatusInternalServerError)
+			return
+		}
+
+		apiservices.RecordAudit(r, models.AuditLog{
+			ActorName:  adminName,
+			Action:     models.AuditActionAnnouncementCreated,
+			TargetType: models.AuditTargetAnnouncement,
+			TargetId:   strconv.FormatInt(announcement.Id, 10),
+		}, map[string]interface{}{"title": announcement.Title, "targetRoleId": announcement.TargetRoleId, "status": announcement.Status})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(announcement)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleCancelAnnouncementAPI cancela un anuncio programado que aún no se ha enviado (POST ?id=N).
+func (ah *AdminHandler) HandleCancelAnnouncementAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "id inválido", http.StatusBadRequest)
+		return
+	}
+
+	if err := ah.announcements.Cancel(id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			http.Error(w, "El anuncio no existe", http.StatusNotFound)
+		case errors.Is(err, queries.ErrAnnouncementNotScheduled):
+			http.Error(w, "El anuncio ya se envió, se canceló o expiró", http.StatusConflict)
+		default:
+			logger.Errorf("ADMIN", "Error cancelando el anuncio %d: %v", id, err)
+			http.Error(w, "Error cancelando el anuncio", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	adminName, _, _ := r.BasicAuth()
+	apiservices.RecordAudit(r, models.AuditLog{
+		ActorName:  adminName,
+		Action:     models.AuditActionAnnouncementCancelled,
+		TargetType: models.AuditTargetAnnouncement,
+		TargetId:   strconv.FormatInt(id, 10),
+	}, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"id": id, "status": models.AnnouncementStatusCancelled})
+}
@@ -0,0 +1,123 @@
+package admin
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/etag"
+)
+
+// staticFiles contiene el dashboard: index.html, dashboard.css y dashboard.js.
+//
+//go:embed static
+var staticFiles embed.FS
+
+// staticCacheControl obliga al navegador a revalidar los archivos con su ETag: una versión
+// nueva del servidor se ve al recargar y, si no cambió, la respuesta es un 304 sin cuerpo.
+const staticCacheControl = "private, no-cache"
+
+// staticAsset es un archivo del dashboard con su ETag precalculado.
+type staticAsset struct {
+	data []byte
+	etag string
+}
+
+// staticAssets indexa los archivos embebidos por nombre (p. ej. "dashboard.js").
+var staticAssets = loadStaticAssets()
+
+func loadStaticAssets() map[string]staticAsset {
+	assets := make(map[string]staticAsset)
+	err := fs.WalkDir(staticFiles, "static", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		data, err := staticFiles.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		assets[strings.TrimPrefix(name, "static/")] = staticAsset{data: data, etag: etag.FromBytes(data)}
+		return nil
+	})
+	if err != nil {
+		panic("admin: no se pudieron cargar los archivos del dashboard: " + err.Error())
+	}
+	return assets
+}
+
+// serveAsset envía un archivo del dashboard. http.ServeContent responde 304 si el
+// If-None-Match coincide con el ETag y deduce el Content-Type de la extensión.
+func serveAsset(w http.ResponseWriter, r *http.Request, name string) {
+	asset, ok := staticAssets[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("ETag", asset.etag)
+	w.Header().Set("Cache-Control", staticCacheControl)
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(asset.data))
+}
+
+// HandleDashboard sirve el dashboard HTML principal
+func (ah *AdminHandler) HandleDashboard(w http.ResponseWriter, r *http.Request) {
+	serveAsset(w, r, "index.html")
+}
+
+// HandleStatic sirve los estilos y scripts del dashboard (/admin/static/...).
+func (ah *AdminHandler) HandleStatic(w http.ResponseWriter, r *http.Request) {
+	name := path.Clean(strings.TrimPrefix(r.URL.Path, "/admin/static/"))
+	if name == "index.html" {
+		http.Redirect(w, r, "/admin", http.StatusMovedPermanently)
+		return
+	}
+	serveAsset(w, r, name)
+}
+
+// dashboardConfig es la configuración que el dashboard carga al arrancar.
+type dashboardConfig struct {
+	AutoRefreshIntervalMs int64             `json:"autoRefreshIntervalMs"`
+	MetricsPushIntervalMs int64             `json:"metricsPushIntervalMs"` // Intervalo del tópico admin:metrics
+	LiveMaxLines          int               `json:"liveMaxLines"`
+	LiveTailSize          int               `json:"liveTailSize"`
+	LiveEventTypes        []string          `json:"liveEventTypes"`
+	Endpoints             map[string]string `json:"endpoints"`
+	ServerTime            int64             `json:"serverTime"`
+}
+
+// dashboardAutoRefreshInterval y dashboardLiveMaxLines configuran la interfaz del dashboard.
+const (
+	dashboardAutoRefreshInterval = 5 * time.Second
+	dashboardLiveMaxLines        = 500
+)
+
+// HandleConfigAPI devuelve la configuración del dashboard: intervalos, rutas de la API y
+// tipos de evento de la consola en vivo.
+func (ah *AdminHandler) HandleConfigAPI(w http.ResponseWriter, r *http.Request) {
+	config := dashboardConfig{
+		AutoRefreshIntervalMs: dashboardAutoRefreshInterval.Milliseconds(),
+		MetricsPushIntervalMs: metricsPushInterval.Milliseconds(),
+		LiveMaxLines:          dashboardLiveMaxLines,
+		LiveTailSize:          liveTailSize,
+		LiveEventTypes:        []string{LiveEventConnect, LiveEventDisconnect, LiveEventError, LiveEventMessageCounts},
+		Endpoints: map[string]string{
+			"metrics":     "/admin/api/metrics",
+			"system":      "/admin/api/system",
+			"database":    "/admin/api/database",
+			"users":       "/admin/api/users",
+			"errors":      "/admin/api/errors",
+			"connections": "/admin/api/connections",
+			"jobs":        "/admin/api/jobs",
+			"disconnect":  "/admin/api/connections/disconnect",
+			"live":        "/admin/ws/live",
+		},
+		ServerTime: time.Now().Unix(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(config)
+}
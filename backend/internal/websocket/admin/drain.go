@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	apiservices "github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+)
+
+// DrainRequest es el cuerpo de POST /admin/api/drain. Sin spreadSeconds ni retryAfterSeconds
+// se usan WS_DRAIN_SPREAD y WS_DRAIN_RETRY_AFTER.
+type DrainRequest struct {
+	Enabled           bool `json:"enabled"`
+	SpreadSeconds     *int `json:"spreadSeconds"`
+	RetryAfterSeconds *int `json:"retryAfterSeconds"`
+}
+
+// HandleDrainAPI devuelve (GET) o cambia (POST con DrainRequest) el drenaje de conexiones de
+// esta instancia (ver docs/drenaje_conexiones.md). El proxy lo llama desde /proxy/drain.
+func (ah *AdminHandler) HandleDrainAPI(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req DrainRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apperrors.Write(w, apperrors.InvalidBody, "Cuerpo inválido")
+			return
+		}
+		wasActive := ah.drainer.Active()
+		if req.Enabled {
+			ah.drainer.Start(secondsPtr(req.SpreadSeconds), secondsPtr(req.RetryAfterSeconds))
+		} else {
+			ah.drainer.Stop()
+		}
+		if req.Enabled || wasActive {
+			state := ah.drainer.State()
+			adminName, _, _ := r.BasicAuth()
+			apiservices.RecordAudit(r, models.AuditLog{
+				ActorName:  adminName,
+				Action:     models.AuditActionDrainChanged,
+				TargetType: models.AuditTargetService,
+				TargetId:   state.Instance,
+			}, map[string]interface{}{"enabled": state.Active, "endsAt": state.EndsAt, "retryAfterSeconds": state.RetryAfterSeconds, "connections": state.Connections})
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ah.drainer.State())
+}
+
+// secondsPtr convierte un número de segundos opcional en duración.
+func secondsPtr(seconds *int) *time.Duration {
+	if seconds == nil {
+		return nil
+	}
+	d := time.Duration(*seconds) * time.Second
+	return &d
+}
@@ -0,0 +1,296 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/gorilla/websocket"
+)
+
+// Tipos de evento de la consola en vivo (/admin/ws/live).
+const (
+	LiveEventConnect       = "connect"        // Un dispositivo se conectó
+	LiveEventDisconnect    = "disconnect"     // Un dispositivo se desconectó (Detail: causa, si hubo error)
+	LiveEventError         = "error"          // Error procesando un mensaje (Detail: tipo y error)
+	LiveEventMessageCounts = "message_counts" // Mensajes recibidos por tipo en el último segundo
+)
+
+const (
+	liveTailSize          = 200              // Eventos recientes enviados al abrir la consola
+	liveSubscriberBuffer  = 256              // Eventos pendientes por consola antes de descartar
+	liveMaxSubscribers    = 20               // Consolas abiertas a la vez
+	liveWriteTimeout      = 5 * time.Second  // Plazo para escribir un evento
+	livePingInterval      = 30 * time.Second // Ping para mantener viva la conexión
+	liveMaxFilterMessages = 1024             // Tamaño máximo de un mensaje de filtros
+)
+
+// LiveEvent es un evento del servidor enviado a la consola en vivo.
+type LiveEvent struct {
+	Time   int64            `json:"time"` // Unix en milisegundos
+	Type   string           `json:"type"`
+	UserID int64            `json:"userId,omitempty"`
+	Detail string           `json:"detail,omitempty"`
+	Counts map[string]int64 `json:"counts,omitempty"` // Solo en message_counts
+
+	// Dropped es el número de eventos descartados justo antes de este porque la consola no
+	// los leía a tiempo.
+	Dropped int64 `json:"dropped,omitempty"`
+}
+
+// liveFilter selecciona los eventos que recibe una consola. Los campos vacíos no filtran.
+type liveFilter struct {
+	UserID int64    `json:"userId"`
+	Types  []string `json:"types"`
+}
+
+func (f liveFilter) matches(event LiveEvent) bool {
+	if f.UserID != 0 && event.UserID != f.UserID {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// liveSubscriber es una consola conectada.
+type liveSubscriber struct {
+	events  chan LiveEvent
+	mu      sync.Mutex
+	filter  liveFilter
+	dropped int64 // Eventos descartados porque la consola no los leía a tiempo
+}
+
+func (s *liveSubscriber) currentFilter() liveFilter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.filter
+}
+
+// liveConsole reparte los eventos del servidor a las consolas abiertas y guarda los últimos
+// para enviarlos al abrir una nueva. Los mensajes no se envían uno a uno: se agregan por
+// segundo en eventos message_counts.
+type liveConsole struct {
+	mu          sync.Mutex
+	subscribers map[*liveSubscriber]struct{}
+	tail        []LiveEvent
+	active      atomic.Bool // Hay consolas abiertas; sin ellas no se cuentan mensajes
+
+	countsMu sync.Mutex
+	counts   map[int64]map[string]int64 // Mensajes del segundo en curso por usuario y tipo
+}
+
+func newLiveConsole() *liveConsole {
+	return &liveConsole{
+		subscribers: make(map[*liveSubscriber]struct{}),
+		tail:        make([]LiveEvent, 0, liveTailSize),
+		counts:      make(map[int64]map[string]int64),
+	}
+}
+
+// publish envía el evento a las consolas cuyo filtro lo acepta y lo guarda en el historial
+// reciente. Si una consola va retrasada, el evento se descarta para ella.
+func (lc *liveConsole) publish(event LiveEvent) {
+	if event.Time == 0 {
+		event.Time = time.Now().UnixMilli()
+	}
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if event.Type != LiveEventMessageCounts {
+		if len(lc.tail) == liveTailSize {
+			copy(lc.tail, lc.tail[1:])
+			lc.tail = lc.tail[:liveTailSize-1]
+		}
+		lc.tail = append(lc.tail, event)
+	}
+	for sub := range lc.subscribers {
+		if sub.currentFilter().matches(event) {
+			lc.deliver(sub, event)
+		}
+	}
+}
+
+func (lc *liveConsole) deliver(sub *liveSubscriber, event LiveEvent) {
+	select {
+	case sub.events <- event:
+	default:
+		atomic.AddInt64(&sub.dropped, 1)
+	}
+}
+
+// countMessage suma un mensaje al contador del segundo en curso.
+func (lc *liveConsole) countMessage(userID int64, messageType string) {
+	if !lc.active.Load() {
+		return
+	}
+	lc.countsMu.Lock()
+	byType, ok := lc.counts[userID]
+	if !ok {
+		byType = make(map[string]int64)
+		lc.counts[userID] = byType
+	}
+	byType[messageType]++
+	lc.countsMu.Unlock()
+}
+
+// flushMessageCounts envía los mensajes contados desde la última llamada: el total a las
+// consolas sin filtro de usuario y los del usuario a las que lo filtran.
+func (lc *liveConsole) flushMessageCounts() {
+	lc.countsMu.Lock()
+	counts := lc.counts
+	lc.counts = make(map[int64]map[string]int64)
+	lc.countsMu.Unlock()
+	if len(counts) == 0 {
+		return
+	}
+
+	total := make(map[string]int64)
+	for _, byType := range counts {
+		for messageType, n := range byType {
+			total[messageType] += n
+		}
+	}
+	now := time.Now().UnixMilli()
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	for sub := range lc.subscribers {
+		filter := sub.currentFilter()
+		event := LiveEvent{Time: now, Type: LiveEventMessageCounts, Counts: total}
+		if filter.UserID != 0 {
+			byType, ok := counts[filter.UserID]
+			if !ok {
+				continue
+			}
+			event.UserID, event.Counts = filter.UserID, byType
+		}
+		if filter.matches(event) {
+			lc.deliver(sub, event)
+		}
+	}
+}
+
+// subscribe registra una consola y le envía el historial reciente que acepta su filtro.
+func (lc *liveConsole) subscribe(filter liveFilter) (*liveSubscriber, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if len(lc.subscribers) >= liveMaxSubscribers {
+		return nil, false
+	}
+	sub := &liveSubscriber{events: make(chan LiveEvent, liveSubscriberBuffer), filter: filter}
+	for _, event := range lc.tail {
+		if filter.matches(event) {
+			lc.deliver(sub, event)
+		}
+	}
+	lc.subscribers[sub] = struct{}{}
+	lc.active.Store(true)
+	return sub, true
+}
+
+func (lc *liveConsole) unsubscribe(sub *liveSubscriber) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	delete(lc.subscribers, sub)
+	lc.active.Store(len(lc.subscribers) > 0)
+}
+
+// parseLiveFilter lee los filtros iniciales de la URL: ?userId=42&types=connect,error.
+func parseLiveFilter(r *http.Request) (liveFilter, error) {
+	var filter liveFilter
+	if raw := r.URL.Query().Get("userId"); raw != "" {
+		userID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return filter, err
+		}
+		filter.UserID = userID
+	}
+	if raw := r.URL.Query().Get("types"); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				filter.Types = append(filter.Types, t)
+			}
+		}
+	}
+	return filter, nil
+}
+
+// liveUpgrader acepta solo conexiones del mismo origen (el propio dashboard), que es la
+// comprobación por defecto de gorilla/websocket.
+var liveUpgrader = websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 4096}
+
+// HandleLiveConsole abre la consola en vivo: un WebSocket que recibe los eventos del
+// servidor (conexiones, desconexiones, errores y mensajes por tipo) a medida que ocurren.
+// Los filtros iniciales van en la URL (?userId=&types=) y se pueden cambiar enviando
+// {"userId": 42, "types": ["error"]} por el mismo socket.
+func (ah *AdminHandler) HandleLiveConsole(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseLiveFilter(r)
+	if err != nil {
+		http.Error(w, "userId inválido", http.StatusBadRequest)
+		return
+	}
+	sub, ok := ah.collector.live.subscribe(filter)
+	if !ok {
+		http.Error(w, "Demasiadas consolas abiertas", http.StatusServiceUnavailable)
+		return
+	}
+	defer ah.collector.live.unsubscribe(sub)
+
+	conn, err := liveUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Warnf("ADMIN", "No se pudo abrir la consola en vivo: %v", err)
+		return
+	}
+	defer conn.Close()
+	logger.Infof("ADMIN", "Consola en vivo abierta desde %s (userId=%d, types=%v)", r.RemoteAddr, filter.UserID, filter.Types)
+
+	// Lectura: cambios de filtro y detección del cierre. Un filtro mal formado se ignora.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(liveMaxFilterMessages)
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var next liveFilter
+			if err := json.Unmarshal(data, &next); err != nil {
+				continue
+			}
+			sub.mu.Lock()
+			sub.filter = next
+			sub.mu.Unlock()
+		}
+	}()
+
+	ping := time.NewTicker(livePingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case event := <-sub.events:
+			event.Dropped = atomic.SwapInt64(&sub.dropped, 0)
+			conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(liveWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
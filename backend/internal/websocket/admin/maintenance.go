@@ -0,0 +1,27 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	apiservices "github.com/davidM20/micro-service-backend-go.git/internal/services"
+)
+
+// HandleMaintenanceAPI devuelve (GET) o cambia (POST con maintenance.SetRequest) el modo
+// mantenimiento del servidor WebSocket. El proxy lo llama al cambiar su propio modo.
+func (ah *AdminHandler) HandleMaintenanceAPI(w http.ResponseWriter, r *http.Request) {
+	before := ah.maintenance.State()
+	ah.maintenance.Handler()(w, r)
+	after := ah.maintenance.State()
+	if after.Enabled == before.Enabled && after.Since.Equal(before.Since) {
+		return
+	}
+
+	adminName, _, _ := r.BasicAuth()
+	apiservices.RecordAudit(r, models.AuditLog{
+		ActorName:  adminName,
+		Action:     models.AuditActionMaintenanceChanged,
+		TargetType: models.AuditTargetService,
+		TargetId:   "websocket",
+	}, map[string]interface{}{"enabled": after.Enabled, "message": after.Message, "drainAt": after.DrainAt})
+}
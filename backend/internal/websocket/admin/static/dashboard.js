@@ -0,0 +1,357 @@
+let autoRefresh = false;
+let autoRefreshInterval;
+
+// Configuración del panel (/admin/api/config). Estos valores solo se usan si no se puede cargar.
+let config = {
+    autoRefreshIntervalMs: 5000,
+    liveMaxLines: 500,
+    endpoints: {
+        metrics: '/admin/api/metrics',
+        system: '/admin/api/system',
+        database: '/admin/api/database',
+        users: '/admin/api/users',
+        errors: '/admin/api/errors',
+        connections: '/admin/api/connections',
+        live: '/admin/ws/live'
+    }
+};
+
+async function loadConfig() {
+    try {
+        const response = await fetch('/admin/api/config');
+        config = await response.json();
+    } catch (error) {
+        console.error('Error fetching config:', error);
+    }
+}
+
+function formatBytes(bytes) {
+    return bytes + ' MB';
+}
+
+function formatDuration(seconds) {
+    const hours = Math.floor(seconds / 3600);
+    const minutes = Math.floor((seconds % 3600) / 60);
+    const secs = Math.floor(seconds % 60);
+
+    if (hours > 0) {
+        return hours + 'h ' + minutes + 'm';
+    } else if (minutes > 0) {
+        return minutes + 'm ' + secs + 's';
+    } else {
+        return secs + 's';
+    }
+}
+
+function escapeHtml(value) {
+    return String(value).replace(/&/g, '&amp;').replace(/</g, '&lt;')
+        .replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+}
+
+function formatDevice(device) {
+    const name = [device.browser, device.os].filter(Boolean).join(' en ') || 'Desconocido';
+    return '<span title="' + escapeHtml(device.userAgent || '') + '">' +
+        escapeHtml(name) + ' (' + escapeHtml(device.deviceType || 'unknown') + ')</span>';
+}
+
+function formatLocation(device) {
+    return escapeHtml([device.city, device.country].filter(Boolean).join(', ') || '-');
+}
+
+function formatTraffic(device) {
+    const stats = device.stats;
+    if (!stats) {
+        return '-';
+    }
+    let text = '↓' + stats.messagesReceived + ' ↑' + stats.messagesSent +
+        ' · cola ' + stats.sendQueueDepth + '/' + stats.sendQueueSize;
+    if (stats.rttMs > 0) {
+        text += ' · ' + stats.rttMs + ' ms';
+    }
+    if (stats.ackTimeouts > 0) {
+        text += ' · <span class="error-badge">' + stats.ackTimeouts + ' acks</span>';
+    }
+    if (stats.deadLetters > 0) {
+        text += ' · <span class="error-badge">' + stats.deadLetters + ' sin entregar</span>';
+    }
+    if (stats.pidRejections > 0) {
+        text += ' · <span class="error-badge">' + stats.pidRejections + ' PIDs rechazados</span>';
+    }
+    return '<span title="Inactivo ' + formatDuration(stats.idleSeconds) + '">' + text + '</span>';
+}
+
+function formatTimestamp(timestamp) {
+    return new Date(timestamp * 1000).toLocaleString();
+}
+
+async function fetchMetrics() {
+    try {
+        const response = await fetch(config.endpoints.metrics);
+        const data = await response.json();
+
+        document.getElementById('activeConnections').textContent = data.activeConnections;
+        document.getElementById('totalConnections').textContent = data.totalConnections;
+        document.getElementById('totalMessages').textContent = data.totalMessages;
+        document.getElementById('totalErrors').textContent = data.totalErrors;
+        document.getElementById('messagesPerSecond').textContent = data.messagesPerSecond;
+
+        // Mensajes por tipo
+        const messagesList = document.getElementById('messagesByType');
+        messagesList.innerHTML = '';
+        for (const [type, count] of Object.entries(data.messagesByType || {})) {
+            const li = document.createElement('li');
+            li.innerHTML = '<span>' + type + '</span><span>' + count + '</span>';
+            messagesList.appendChild(li);
+        }
+
+        if (Object.keys(data.messagesByType || {}).length === 0) {
+            messagesList.innerHTML = '<li>No hay datos disponibles</li>';
+        }
+
+    } catch (error) {
+        console.error('Error fetching metrics:', error);
+    }
+}
+
+async function fetchSystemInfo() {
+    try {
+        const response = await fetch(config.endpoints.system);
+        const data = await response.json();
+
+        document.getElementById('goroutines').textContent = data.goroutines;
+        document.getElementById('memoryAlloc').textContent = formatBytes(data.memory.allocMB);
+        document.getElementById('numGC').textContent = data.memory.numGC;
+        document.getElementById('avgQueryTime').textContent = data.averageQueryMs + ' ms';
+
+    } catch (error) {
+        console.error('Error fetching system info:', error);
+    }
+}
+
+async function fetchDatabase() {
+    try {
+        const response = await fetch(config.endpoints.database);
+        const data = await response.json();
+        const pool = data.pool;
+
+        document.getElementById('dbInUse').textContent = pool.inUse;
+        document.getElementById('dbOpen').textContent = pool.openConnections + ' / ' + pool.maxOpenConnections;
+        document.getElementById('dbIdle').textContent = pool.idle;
+        document.getElementById('dbWaits').textContent = pool.waitsLastInterval + ' (total ' + pool.waitCount + ')';
+        document.getElementById('dbWaitDuration').textContent = pool.waitDurationMs + ' ms';
+
+        const status = document.getElementById('dbStatus');
+        if (pool.alert) {
+            status.innerHTML = '<span class="status-indicator status-error"></span>Pool saturado: ' +
+                pool.waitsLastInterval + ' esperas (umbral ' + pool.waitAlertThreshold + ')';
+        } else if (pool.waitsLastInterval > 0) {
+            status.innerHTML = '<span class="status-indicator status-warning"></span>Hay esperas por conexión';
+        } else {
+            status.innerHTML = '<span class="status-indicator status-online"></span>Sin esperas';
+        }
+
+        const readOnly = data.readOnly;
+        const writes = document.getElementById('dbWrites');
+        if (readOnly.readOnly) {
+            writes.innerHTML = '<span class="status-indicator status-error"></span>Modo solo lectura (' +
+                readOnly.reason + ') desde ' + new Date(readOnly.since).toLocaleTimeString() +
+                ' · ' + readOnly.consecutiveRecoveries + ' comprobaciones correctas';
+        } else if (!readOnly.enabled) {
+            writes.innerHTML = '<span class="status-indicator status-warning"></span>Modo solo lectura desactivado';
+        } else if (readOnly.consecutiveFailures > 0) {
+            writes.innerHTML = '<span class="status-indicator status-warning"></span>' +
+                readOnly.consecutiveFailures + ' fallos de conexión seguidos';
+        } else {
+            writes.innerHTML = '<span class="status-indicator status-online"></span>Escrituras habilitadas' +
+                (readOnly.replicationLagSeconds !== undefined ? ' · réplica ' + readOnly.replicationLagSeconds + 's' : '');
+        }
+
+        const queriesTable = document.getElementById('queriesTable');
+        queriesTable.innerHTML = '';
+        for (const q of data.queries || []) {
+            const row = queriesTable.insertRow();
+            row.innerHTML =
+                '<td>' + q.caller + '</td>' +
+                '<td>' + q.count + '</td>' +
+                '<td>' + q.avgMs.toFixed(1) + ' ms</td>' +
+                '<td>' + q.maxMs.toFixed(1) + ' ms</td>' +
+                '<td>' + (q.slow > 0 ? '<span class="error-badge">' + q.slow + '</span>' : '0') + '</td>';
+        }
+        if ((data.queries || []).length === 0) {
+            queriesTable.insertRow().innerHTML = '<td colspan="5">No hay consultas registradas</td>';
+        }
+
+    } catch (error) {
+        console.error('Error fetching database pool:', error);
+    }
+}
+
+async function fetchUsers() {
+    try {
+        const response = await fetch(config.endpoints.users);
+        const data = await response.json();
+
+        document.getElementById('onlineUsers').textContent = data.onlineUsers;
+        document.getElementById('totalUsers').textContent = data.totalUsers;
+        document.getElementById('recentUsers').textContent = data.recentUsers24h;
+
+    } catch (error) {
+        console.error('Error fetching users:', error);
+    }
+}
+
+async function fetchErrors() {
+    try {
+        const response = await fetch(config.endpoints.errors);
+        const data = await response.json();
+
+        document.getElementById('totalErrors').textContent = data.totalErrors;
+
+        // Errores por tipo
+        const errorsList = document.getElementById('errorsByType');
+        errorsList.innerHTML = '';
+        for (const [type, count] of Object.entries(data.errorsByType || {})) {
+            const li = document.createElement('li');
+            li.innerHTML = '<span>' + type + '</span><span class="error-badge">' + count + '</span>';
+            errorsList.appendChild(li);
+        }
+
+        if (Object.keys(data.errorsByType || {}).length === 0) {
+            errorsList.innerHTML = '<li>No hay errores registrados</li>';
+        }
+
+    } catch (error) {
+        console.error('Error fetching errors:', error);
+    }
+}
+
+async function fetchConnections() {
+    try {
+        const response = await fetch(config.endpoints.connections);
+        const data = await response.json();
+
+        const table = document.getElementById('sessionsTable');
+        table.innerHTML = '';
+
+        for (const [userId, session] of Object.entries(data.sessions || {})) {
+            const devices = session.devices || [];
+            const row = table.insertRow();
+            row.innerHTML =
+                '<td>' + session.userId + '</td>' +
+                '<td>' + formatDuration(session.duration) + '</td>' +
+                '<td>' + formatTimestamp(session.connectedAt) + '</td>' +
+                '<td>' + devices.map(formatDevice).join('<br>') + '</td>' +
+                '<td>' + devices.map(d => escapeHtml(d.ip || '-')).join('<br>') + '</td>' +
+                '<td>' + devices.map(formatLocation).join('<br>') + '</td>' +
+                '<td>' + devices.map(formatTraffic).join('<br>') + '</td>' +
+                '<td><span class="status-indicator status-online"></span>Online</td>';
+        }
+
+        if (Object.keys(data.sessions || {}).length === 0) {
+            const row = table.insertRow();
+            row.innerHTML = '<td colspan="8">No hay sesiones activas</td>';
+        }
+
+    } catch (error) {
+        console.error('Error fetching connections:', error);
+    }
+}
+
+let liveSocket = null;
+
+function liveFilter() {
+    const userId = parseInt(document.getElementById('liveUserId').value, 10) || 0;
+    const types = Array.from(document.querySelectorAll('.live-type:checked')).map(c => c.value);
+    return { userId: userId, types: types };
+}
+
+function formatLiveEvent(event) {
+    let text = new Date(event.time).toLocaleTimeString() + ' [' + event.type + ']';
+    if (event.userId) {
+        text += ' usuario ' + event.userId;
+    }
+    if (event.counts) {
+        text += ' ' + Object.entries(event.counts).map(([type, n]) => type + '=' + n).join(' ');
+    }
+    if (event.detail) {
+        text += ' ' + event.detail;
+    }
+    if (event.dropped) {
+        text += ' (' + event.dropped + ' eventos descartados antes)';
+    }
+    return text;
+}
+
+function appendLiveEvent(event) {
+    const output = document.getElementById('liveConsole');
+    const atBottom = output.scrollTop + output.clientHeight >= output.scrollHeight - 5;
+    const line = document.createElement('div');
+    line.className = 'live-' + event.type;
+    line.textContent = formatLiveEvent(event);
+    output.appendChild(line);
+    while (output.childElementCount > config.liveMaxLines) {
+        output.removeChild(output.firstChild);
+    }
+    if (atBottom) {
+        output.scrollTop = output.scrollHeight;
+    }
+}
+
+function toggleLiveConsole() {
+    if (liveSocket) {
+        liveSocket.close();
+        return;
+    }
+    const filter = liveFilter();
+    const protocol = location.protocol === 'https:' ? 'wss://' : 'ws://';
+    const url = protocol + location.host + config.endpoints.live + '?userId=' + filter.userId +
+        '&types=' + encodeURIComponent(filter.types.join(','));
+    liveSocket = new WebSocket(url);
+    document.getElementById('liveStatus').textContent = 'Conectando...';
+    liveSocket.onopen = () => {
+        document.getElementById('liveStatus').textContent = 'Conectada';
+        document.getElementById('liveBtn').textContent = '⏹️ Desconectar';
+    };
+    liveSocket.onmessage = (message) => appendLiveEvent(JSON.parse(message.data));
+    liveSocket.onclose = () => {
+        liveSocket = null;
+        document.getElementById('liveStatus').textContent = 'Desconectada';
+        document.getElementById('liveBtn').textContent = '▶️ Conectar';
+    };
+}
+
+function applyLiveFilter() {
+    if (liveSocket && liveSocket.readyState === WebSocket.OPEN) {
+        liveSocket.send(JSON.stringify(liveFilter()));
+    }
+}
+
+function refreshAll() {
+    fetchMetrics();
+    fetchSystemInfo();
+    fetchDatabase();
+    fetchUsers();
+    fetchErrors();
+    fetchConnections();
+}
+
+function toggleAutoRefresh() {
+    autoRefresh = !autoRefresh;
+    const btn = document.getElementById('autoRefreshBtn');
+    const status = document.getElementById('autoRefreshStatus');
+
+    if (autoRefresh) {
+        btn.textContent = '⏱️ Auto-actualizar: ON';
+        btn.style.background = '#27ae60';
+        status.textContent = '(actualiza cada ' + config.autoRefreshIntervalMs / 1000 + ' segundos)';
+        autoRefreshInterval = setInterval(refreshAll, config.autoRefreshIntervalMs);
+    } else {
+        btn.textContent = '⏱️ Auto-actualizar: OFF';
+        btn.style.background = '#3498db';
+        status.textContent = '';
+        clearInterval(autoRefreshInterval);
+    }
+}
+
+// Cargar la configuración y los datos iniciales
+loadConfig().then(refreshAll);
@@ -2,15 +2,21 @@ package auth
 
 import (
 	"database/sql"
-	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/auth"
 	"github.com/davidM20/micro-service-backend-go.git/internal/config"
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/services"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/useragent"
 )
 
 // Este archivo contendrá la lógica de autenticación para las conexiones WebSocket.
@@ -30,6 +36,8 @@ func NewAuthenticator(db *sql.DB, cfg *config.Config) *Authenticator {
 // AuthenticateAndGetUserData es el callback para customws.
 // Valida la petición (ej. token JWT, cookies) y retorna el ID del usuario (int64) y los datos WsUserData.
 // Si la autenticación falla, debe retornar un error y ServeHTTP responderá con HTTP Unauthorized.
+// Sin token devuelve customws.ErrNoCredentials: la conexión se acepta y se autentica con el
+// primer mensaje (AuthenticateMessage), si WS_AUTH_TIMEOUT > 0.
 func (a *Authenticator) AuthenticateAndGetUserData(r *http.Request) (userID int64, userData wsmodels.WsUserData, err error) {
 	var token string
 
@@ -46,41 +54,118 @@ func (a *Authenticator) AuthenticateAndGetUserData(r *http.Request) (userID int6
 	}
 
 	// 2. Si no hay token en header, intentar parámetro de URL (para WebSocket desde navegador/React Native)
-	if token == "" {
+	if token == "" && a.cfg.WsAuthQueryToken {
 		token = r.URL.Query().Get("token")
 	}
 
-	// 3. Si aún no hay token, fallar
+	// 3. Si aún no hay token, el cliente debe autenticarse con el primer mensaje
 	if token == "" {
-		logger.Warn("AUTH", "Intento de conexión WS sin token de autorización (header Authorization o parámetro ?token)")
-		return 0, wsmodels.WsUserData{}, errors.New("token de autorización requerido")
+		return 0, wsmodels.WsUserData{}, fmt.Errorf("token de autorización requerido: %w", customws.ErrNoCredentials)
+	}
+	return a.authenticateToken(r, token)
+}
+
+// AuthenticateMessage es el callback de customws para las conexiones abiertas sin token: el
+// token llega en el primer mensaje (tipo auth) en lugar de en la URL.
+func (a *Authenticator) AuthenticateMessage(r *http.Request, payload types.AuthPayload) (userID int64, userData wsmodels.WsUserData, err error) {
+	if payload.Token == "" {
+		logger.Warn("AUTH", "Mensaje auth de WS sin token")
+		return 0, wsmodels.WsUserData{}, apperrors.New(apperrors.MissingToken, "token de autorización requerido")
 	}
+	return a.authenticateToken(r, payload.Token)
+}
 
-	// 2. Validar el token JWT
+// authenticateToken valida el token JWT y obtiene los datos del usuario.
+func (a *Authenticator) authenticateToken(r *http.Request, token string) (userID int64, userData wsmodels.WsUserData, err error) {
+	// 1. Validar el token JWT
 	claims, err := auth.ValidateJWT(token, []byte(a.cfg.JwtSecret))
 	if err != nil {
 		logger.Warnf("AUTH", "Token JWT inválido para WS: %v", err)
-		return 0, wsmodels.WsUserData{}, errors.New("token inválido o expirado")
+		return 0, wsmodels.WsUserData{}, apperrors.New(apperrors.InvalidToken, "token inválido o expirado")
+	}
+
+	// Los tokens de suplantación solo valen mientras la suplantación siga activa
+	if claims.ImpersonatorID != 0 {
+		impersonationID, err := claims.ImpersonationID()
+		if err != nil {
+			logger.Warnf("AUTH", "Token de suplantación sin ID válido para WS: UserID %d", claims.UserID)
+			return 0, wsmodels.WsUserData{}, apperrors.New(apperrors.InvalidToken, "token inválido o expirado")
+		}
+		active, err := queries.IsImpersonationActive(impersonationID, claims.ImpersonatorID, claims.UserID)
+		if err != nil {
+			logger.Errorf("AUTH", "Error al comprobar la suplantación para WS: %v", err)
+			return 0, wsmodels.WsUserData{}, apperrors.New(apperrors.Internal, "error interno al verificar usuario")
+		}
+		if !active {
+			logger.Warnf("AUTH", "Suplantación %d terminada o expirada: UserID %d", impersonationID, claims.UserID)
+			return 0, wsmodels.WsUserData{}, apperrors.New(apperrors.SessionExpired, "suplantación terminada")
+		}
 	}
 
-	// 3. Si el token es válido, obtener datos adicionales del usuario desde la BD
+	// 2. Si el token es válido, obtener datos adicionales del usuario desde la BD
 	user, err := queries.GetUserByID(a.db, claims.UserID) // Necesitarás crear esta función
 	if err != nil {
 		if err == sql.ErrNoRows {
 			logger.Warnf("AUTH", "Usuario del token JWT no encontrado en BD: UserID %d", claims.UserID)
-			return 0, wsmodels.WsUserData{}, errors.New("usuario no encontrado")
+			return 0, wsmodels.WsUserData{}, apperrors.New(apperrors.InvalidToken, "usuario no encontrado")
 		}
 		logger.Errorf("AUTH", "Error al obtener datos del usuario desde BD para WS: %v", err)
-		return 0, wsmodels.WsUserData{}, errors.New("error interno al verificar usuario")
+		return 0, wsmodels.WsUserData{}, apperrors.New(apperrors.Internal, "error interno al verificar usuario")
 	}
 
-	// 4. Construir y devolver WsUserData
+	// Las cuentas suspendidas por moderación no pueden conectarse aunque su token siga vigente
+	if user.StatusAuthorizedId == int(models.UserStatusSuspended) {
+		logger.Warnf("AUTH", "Intento de conexión WS de una cuenta suspendida: UserID %d", user.Id)
+		return 0, wsmodels.WsUserData{}, apperrors.New(apperrors.AccountSuspended, "cuenta suspendida")
+	}
+	if user.StatusAuthorizedId == int(models.UserStatusDeactivated) {
+		logger.Warnf("AUTH", "Intento de conexión WS de una cuenta desactivada: UserID %d", user.Id)
+		return 0, wsmodels.WsUserData{}, apperrors.New(apperrors.AccountDeactivated, "cuenta desactivada")
+	}
+
+	// La institución de la cuenta manda sobre la del subdominio, como en AuthMiddleware
+	tenants := services.NewTenantResolver(a.cfg)
+	tenantID, err := tenants.Resolve(user.TenantId.Int64, tenants.FromRequest(r))
+	if err != nil {
+		logger.Warnf("AUTH", "Intento de conexión WS desde el subdominio de otra institución: UserID %d", user.Id)
+		return 0, wsmodels.WsUserData{}, err
+	}
+
+	// 3. Construir y devolver WsUserData
 	logger.Infof("AUTH", "Usuario autenticado exitosamente para WS: ID %d, Username %s",
 		user.Id, user.UserName)
 
-	return user.Id, wsmodels.WsUserData{
+	userData = wsmodels.WsUserData{
 		UserID:   user.Id,
 		Username: user.UserName,
 		RoleId:   user.RoleId,
-	}, nil
+		TenantID: tenantID,
+	}
+	userData.IP, userData.Device = a.connectionDevice(r, user.Id, token)
+	if claims.ImpersonatorID != 0 {
+		userData.ImpersonatorID = claims.ImpersonatorID
+		userData.ImpersonationID, _ = claims.ImpersonationID()
+		userData.ImpersonationExpiresAt = claims.ExpiresAt.Time
+	}
+	return user.Id, userData, nil
+}
+
+// connectionDevice devuelve la IP y el dispositivo de la conexión. Se usan los de la sesión
+// del login, que incluyen la ubicación; si la sesión no los tiene (sesiones antiguas) se
+// obtienen de la petición de conexión.
+func (a *Authenticator) connectionDevice(r *http.Request, userID int64, token string) (string, models.SessionDevice) {
+	ip, device, err := queries.GetSessionDevice(a.db, userID, token)
+	if err != nil && err != sql.ErrNoRows {
+		logger.Warnf("AUTH", "No se pudo obtener el dispositivo de la sesión de UserID %d: %v", userID, err)
+	}
+	// Las sesiones antiguas guardaban 127.0.0.1 en lugar de la IP real
+	if ip == "" || ip == "127.0.0.1" {
+		ip = services.ClientIP(r)
+	}
+	if device.DeviceType == "" {
+		info := useragent.Parse(r.UserAgent())
+		device.UserAgent = r.UserAgent()
+		device.DeviceType, device.Browser, device.OS = info.DeviceType, info.Browser, info.OS
+	}
+	return ip, device
 }
@@ -1,12 +1,18 @@
 package websocket
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"time"
 
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/admin"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/services"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 )
 
@@ -23,8 +29,22 @@ func OnConnect(conn *customws.Connection[wsmodels.WsUserData]) error {
 		collector.RecordConnection(conn.ID)
 	}
 
+	if conn.UserData.IsImpersonated() {
+		logger.Infof("CONNECTION", "Conexión del usuario %d abierta por el administrador %d (suplantación %d)",
+			conn.ID, conn.UserData.ImpersonatorID, conn.UserData.ImpersonationID)
+		services.WatchImpersonation(conn)
+	}
+
 	// Procesar lógica de conexión
-	return services.HandleUserConnect(conn.ID, conn.UserData.Username, conn.Manager())
+	if err := services.HandleUserConnect(conn.ID, conn.UserData.Username, conn.Manager()); err != nil {
+		return err
+	}
+
+	// Mensajes críticos que no confirmó. En una suplantación los confirmaría el soporte.
+	if !conn.UserData.IsImpersonated() {
+		services.DeliverDeadLetters(conn)
+	}
+	return nil
 }
 
 // OnDisconnect se ejecuta cuando un usuario se desconecta del WebSocket
@@ -35,17 +55,48 @@ func OnDisconnect(conn *customws.Connection[wsmodels.WsUserData], err error) {
 		logger.Warnf("CONNECTION", "Desconexión con error para UserID %d: %v", conn.ID, err)
 	}
 
-	// Registrar desconexión en métricas
+	// Registrar desconexión en métricas (la sesión termina con el último dispositivo)
 	collector := admin.GetCollector()
 	if collector != nil {
-		collector.RecordDisconnection(conn.ID)
+		collector.RecordDisconnection(conn.ID, !conn.Manager().IsUserOnline(conn.ID), err)
 	}
 
 	// Procesar lógica de desconexión
 	services.HandleUserDisconnect(conn.ID, conn.UserData.Username, conn.Manager(), err)
 }
 
-// GeneratePID genera un ID único para cada mensaje
+// OnPanic registra en las métricas un pánico recuperado al procesar un mensaje del usuario.
+// customws ya dejó la traza en el log y respondió al cliente.
+func OnPanic(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, recovered interface{}) {
+	if collector := admin.GetCollector(); collector != nil {
+		collector.RecordError(conn.ID, string(msg.Type)+"_panic", fmt.Errorf("pánico: %v", recovered))
+	}
+}
+
+// OnDeadLetter guarda el mensaje crítico que el usuario no confirmó para enviárselo cuando
+// vuelva a conectarse (ver docs/mensajes_criticos.md).
+func OnDeadLetter(conn *customws.Connection[wsmodels.WsUserData], msg types.ServerToClientMessage, attempts int, err error) {
+	services.StoreDeadLetter(conn.ID, msg, attempts, err)
+}
+
+// AuthorizeSubscription decide si la conexión puede suscribirse a un tópico: los tópicos
+// "admin:" requieren rol de administrador y los "user:{id}:..." ser ese usuario.
+func AuthorizeSubscription(conn *customws.Connection[wsmodels.WsUserData], topic string) error {
+	if wsmodels.IsAdminTopic(topic) && models.UserRole(conn.UserData.RoleId) != models.RoleAdmin {
+		return apperrors.New(apperrors.AdminRequired, "el tópico requiere rol de administrador")
+	}
+	if ownerID, isUserTopic := wsmodels.TopicOwner(topic); isUserTopic && ownerID != conn.ID {
+		return apperrors.New(apperrors.Forbidden, "no puedes suscribirte a tópicos de otro usuario")
+	}
+	return nil
+}
+
+// GeneratePID genera un ID único para cada mensaje. El sufijo aleatorio evita colisiones entre
+// mensajes del mismo microsegundo y que un cliente adivine los PIDs de otras conexiones.
 func GeneratePID() string {
-	return "server-msg-" + time.Now().Format("20060102150405.000000")
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		logger.Errorf("CALLBACKS", "No se pudo generar el sufijo aleatorio del PID: %v", err)
+	}
+	return "server-msg-" + time.Now().Format("20060102150405.000000") + "-" + hex.EncodeToString(suffix)
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/handlers"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
@@ -31,34 +32,44 @@ REGLAS Y GUÍA PARA MODIFICAR EL ROUTER DE MENSAJES WEBSOCKET
    - Crear un handler específico si es necesario
    - Mantener la consistencia en el manejo de errores
 
-4. MANEJO DE ERRORES:
+4. VALIDACIÓN DEL PAYLOAD:
+   - Registrar el struct del campo "data" en payloadSchemas (payload_schemas.go)
+   - Las reglas se declaran con la etiqueta `validate` del struct
+   - Un payload inválido no llega al handler: el cliente recibe un error 400 con los campos inválidos
+   - Si la acción es solo para ciertos roles, registrarlos en messagePermissions (permissions.go);
+     sin regla, cualquier usuario autenticado puede usarla
+
+5. MANEJO DE ERRORES:
    - Usar los tipos de error definidos
    - Incluir mensajes descriptivos
    - Registrar errores usando el logger
    - Notificar al cliente cuando sea necesario
 
-5. CONVENCIÓN DE NOMBRES:
+6. CONVENCIÓN DE NOMBRES:
    - Handlers: handle[Resource][Action]
    - Funciones de utilidad: verbos descriptivos
    - Variables: camelCase
    - Constantes: UPPER_CASE
 
-6. DOCUMENTACIÓN:
+7. DOCUMENTACIÓN:
    - Documentar cada nuevo recurso y sus acciones
    - Explicar el propósito de cada handler
    - Mantener actualizada esta guía
 
-7. RECURSOS DISPONIBLES:
+8. RECURSOS DISPONIBLES:
    - chat:
      * get_list: Lista de chats
      * get_history: Historial de chat
      * send_message: Envío de mensajes
+     * forward_message: Reenvío de un mensaje existente a otro chat o contacto
    - notification:
      * get_list: Lista de notificaciones
      * get_pending: Notificaciones pendientes
      * mark_read: Marcar notificaciones como leídas
+     * mark_read_bulk / mark_unread_bulk: Marcar varias notificaciones como leídas o no leídas
+     * delete_bulk: Borrar varias notificaciones
    - dashboard:
-     * get_info: Información del panel de control
+     * get_info: Información del panel de control (solo administradores)
    - friend:
      * accept_request: Aceptar solicitud de amistad
      * reject_request: Rechazar solicitud de amistad
@@ -68,43 +79,70 @@ REGLAS Y GUÍA PARA MODIFICAR EL ROUTER DE MENSAJES WEBSOCKET
      * users: Buscar usuarios
      * companies: Buscar empresas
      * all: Buscar usuarios y empresas
-     * graduates: Buscar egresados
+     * graduates: Buscar egresados (empresas y administradores)
    - profile:
      * get: Obtener el perfil del propio usuario.
      * update: Actualizar datos del perfil del propio usuario.
      * view: Ver el perfil público de otro usuario.
+   - reputation:
+     * create_review: Crear una reseña sobre un participante de un evento comunitario.
 
-8. ESTRUCTURA DE PAYLOAD:
+9. ESTRUCTURA DE PAYLOAD:
    - Para chat/get_history:
      {
        "chatID": string,
        "limit": number,
-       "beforeMessageId": string (opcional)
+       "cursor": string (opcional, "" = primera página; ver docs/paginacion.md),
+       "beforeMessageId": string (opcional, obsoleto: usar cursor)
      }
    - Para chat/send_message:
      {
        "text": string,
        "chatID": string,
-       "timestamp": string
+       "timestamp": string,
+       "clientMessageId": string (opcional, se repite al reintentar para no duplicar el mensaje)
+     }
+   - Para chat/forward_message:
+     {
+       "messageId": string,
+       "chatId": string (opcional si se envía toUserId),
+       "toUserId": number (opcional si se envía chatId)
      }
    - Para notification/mark_read:
      {
        "notificationId": string,
        "timestamp": string
      }
+   - Para notification/mark_read_bulk, notification/mark_unread_bulk y notification/delete_bulk:
+     {
+       "notificationIds": [string] (de 1 a 100)
+     }
    - Para friend/accept_request y friend/reject_request:
      {
        "notificationId": string,
        "timestamp": string
      }
    - Para feed/get_list:
-     No se requiere payload en "data". El servidor devolverá la lista de items del feed.
+     {
+       "limit": number (opcional),
+       "cursor": string (opcional, "" = primera página; ver docs/paginacion.md),
+       "page": number (opcional, obsoleto: usar cursor),
+       "ifNoneMatch": string (opcional)
+     }
    - Para search/users, search/companies, search/all y search/graduates:
      {
        "query": string,
        "limit": number (opcional),
        "offset": number (opcional)
      }
+   - Para reputation/create_review:
+     {
+       "revieweeId": number,
+       "communityEventId": number,
+       "rating": number (0-5),
+       "comment": string (opcional),
+       "interactionType": string (opcional)
+     }
 */
 
 // DataRequestPayload define la estructura esperada para los mensajes de data_request
@@ -121,8 +159,12 @@ type ResourceHandler func(conn *customws.Connection[wsmodels.WsUserData], msg ty
 var actionHandlers = map[string]map[string]ResourceHandler{
 	// Chat: Manejo de mensajes y listas de chat
 	"chat": {
-		"get_list": func(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, _ DataRequestPayload) error {
-			return handlers.HandleGetChatList(conn, msg)
+		"get_list": func(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, requestData DataRequestPayload) error {
+			var payload interface{}
+			if requestData.Data != nil {
+				payload = requestData.Data
+			}
+			return handlers.HandleGetChatList(conn, types.ClientToServerMessage{PID: msg.PID, Type: msg.Type, Payload: payload})
 		},
 		"get_history": func(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, requestData DataRequestPayload) error {
 			subHandlerMessage := types.ClientToServerMessage{
@@ -133,6 +175,10 @@ var actionHandlers = map[string]map[string]ResourceHandler{
 			return handlers.HandleGetChatHistory(conn, subHandlerMessage)
 		},
 		"send_message": handleSendChatMessage,
+		"forward_message": func(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, requestData DataRequestPayload) error {
+			sub := types.ClientToServerMessage{PID: msg.PID, Type: msg.Type, Payload: requestData.Data}
+			return handlers.HandleForwardChatMessage(conn, sub)
+		},
 		"mark_read": func(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, requestData DataRequestPayload) error {
 			sub := types.ClientToServerMessage{PID: msg.PID, Type: msg.Type, Payload: requestData.Data}
 			return handlers.HandleMarkMessageRead(conn, sub)
@@ -140,13 +186,26 @@ var actionHandlers = map[string]map[string]ResourceHandler{
 	},
 	// Notification: Manejo de notificaciones
 	"notification": {
-		"get_list": func(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, _ DataRequestPayload) error {
-			return handlers.HandleGetNotifications(conn, msg)
+		"get_list": func(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, requestData DataRequestPayload) error {
+			sub := types.ClientToServerMessage{PID: msg.PID, Type: msg.Type, Payload: requestData.Data}
+			return handlers.HandleGetNotifications(conn, sub)
 		},
 		"get_pending": handlePendingNotifications,
 		"mark_read": func(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, _ DataRequestPayload) error {
 			return handlers.HandleMarkNotificationRead(conn, msg)
 		},
+		"mark_read_bulk": func(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, requestData DataRequestPayload) error {
+			sub := types.ClientToServerMessage{PID: msg.PID, Type: msg.Type, Payload: requestData.Data}
+			return handlers.HandleMarkNotificationsRead(conn, sub)
+		},
+		"mark_unread_bulk": func(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, requestData DataRequestPayload) error {
+			sub := types.ClientToServerMessage{PID: msg.PID, Type: msg.Type, Payload: requestData.Data}
+			return handlers.HandleMarkNotificationsUnread(conn, sub)
+		},
+		"delete_bulk": func(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, requestData DataRequestPayload) error {
+			sub := types.ClientToServerMessage{PID: msg.PID, Type: msg.Type, Payload: requestData.Data}
+			return handlers.HandleDeleteNotifications(conn, sub)
+		},
 	},
 	// Dashboard: Información del panel de control
 	"dashboard": {
@@ -243,6 +302,17 @@ var actionHandlers = map[string]map[string]ResourceHandler{
 			return handlers.HandleViewProfile(conn, subHandlerMessage)
 		},
 	},
+	// Reputation: Reseñas entre participantes de eventos comunitarios
+	"reputation": {
+		"create_review": func(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, requestData DataRequestPayload) error {
+			subHandlerMessage := types.ClientToServerMessage{
+				PID:     msg.PID,
+				Type:    msg.Type,
+				Payload: requestData.Data,
+			}
+			return handlers.HandleCreateReview(conn, subHandlerMessage)
+		},
+	},
 }
 
 // HandleDataRequest es el punto de entrada principal para procesar mensajes de data_request.
@@ -275,6 +345,17 @@ func HandleDataRequest(conn *customws.Connection[wsmodels.WsUserData], msg types
 		return handleUnsupportedResource(conn, msg.PID, requestData.Resource, requestData.Action)
 	}
 
+	key := requestData.Resource + "/" + requestData.Action
+	if err := authorizeMessage(conn, msg.PID, key); err != nil {
+		return err
+	}
+	if err := rejectReadOnlyWrite(conn, msg.PID, key); err != nil {
+		return err
+	}
+	if err := validateIncomingPayload(conn, msg.PID, key, requestData.Data); err != nil {
+		return err
+	}
+
 	return handler(conn, msg, requestData)
 }
 
@@ -428,6 +509,6 @@ func getHandler(resource, action string) (ResourceHandler, bool) {
 func handleMissingResource(conn *customws.Connection[wsmodels.WsUserData], pid string, action string) error {
 	errMsg := fmt.Sprintf("Recurso no especificado para la acción '%s'", action)
 	logger.Warnf("HANDLER_DATA", "Missing resource for action '%s'. UserID: %d, PID: %s", action, conn.ID, pid)
-	conn.SendErrorNotification(pid, 400, errMsg)
+	conn.SendAppError(pid, apperrors.MissingResource, errMsg)
 	return errors.New(errMsg)
 }
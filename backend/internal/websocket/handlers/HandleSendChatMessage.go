@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/services"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
@@ -17,12 +19,15 @@ const handlerSendChatMessageLogComponent = "HANDLER_SEND_CHAT_MESSAGE"
 // SendChatMessagePayload define la estructura esperada en msg.Payload para un mensaje de chat.
 // Ajusta según lo que realmente envía el cliente.
 type SendChatMessagePayload struct {
-	ChatId string `json:"chatId"`
-	Text   string `json:"text"`
+	ChatId string `json:"chatId" validate:"required,max=64"`
+	Text   string `json:"text" validate:"max=5000"`
 	// Timestamp     int64  `json:"timestamp"` // El timestamp se genera en el backend al guardar
 	MediaId       string `json:"mediaId,omitempty"`
 	ResponseTo    string `json:"responseTo,omitempty"`    // Para responder a un mensaje específico
 	TypeMessageId int64  `json:"typeMessageId,omitempty"` // El backend puede determinar esto o el cliente puede enviarlo
+	// ClientMessageId lo genera el cliente (p. ej. un UUID) y lo repite si reintenta el envío:
+	// el mensaje se guarda una sola vez y el reintento recibe el original.
+	ClientMessageId string `json:"clientMessageId,omitempty" validate:"omitempty,max=64"`
 	// TargetUserId int64  `json:"targetUserId"` // No es necesario desde el payload, se infiere en el servicio
 }
 
@@ -82,23 +87,74 @@ func HandleSendChatMessage(conn *customws.Connection[wsmodels.WsUserData], msg t
 		servicePayload["mediaId"] = payload.MediaId
 	}
 
+	if payload.ClientMessageId != "" {
+		servicePayload["clientMessageId"] = payload.ClientMessageId
+	}
+
 	// typeMessageId podría pasarse o dejarse que el servicio lo determine
 	if payload.TypeMessageId != 0 {
 		servicePayload["typeMessageId"] = payload.TypeMessageId
 	}
 
 	// El servicio ahora debería devolver el mensaje guardado
-	savedMessage, err := services.ProcessAndSaveChatMessage(conn.ID, servicePayload, messageServerID, conn.Manager())
+	savedMessage, err := deps.Chat.ProcessAndSaveChatMessage(conn.ID, servicePayload, messageServerID, conn.Manager())
+	if errors.Is(err, services.ErrDuplicateMessage) {
+		// El cliente no recibió la confirmación del original: se le repite solo a él, porque el
+		// destinatario y sus otros dispositivos ya recibieron el mensaje.
+		confirmSentChatMessageToSender(conn, msg.PID, savedMessage)
+		return nil
+	}
+	if errors.Is(err, services.ErrMessageBlocked) {
+		logger.Warnf(handlerSendChatMessageLogComponent, "Mensaje de UserID %d bloqueado por el filtro de contenido, PID %s", conn.ID, msg.PID)
+		appErr := apperrors.From(err, "")
+		conn.SendAppError(msg.PID, appErr.Code, appErr.Message)
+		return nil
+	}
+	if errors.Is(err, services.ErrChatFrozen) {
+		logger.Warnf(handlerSendChatMessageLogComponent, "Mensaje de UserID %d rechazado: chat congelado por un bloqueo, PID %s", conn.ID, msg.PID)
+		appErr := apperrors.From(err, "")
+		conn.SendAppError(msg.PID, appErr.Code, appErr.Message)
+		return nil
+	}
 	if err != nil {
 		logger.Errorf(handlerSendChatMessageLogComponent, "Error en ProcessAndSaveChatMessage para UserID %d, PID %s: %v", conn.ID, msg.PID, err)
 		conn.SendServerAck(msg.PID, "error", err) // Enviar el error del servicio al cliente
 		return fmt.Errorf("error procesando mensaje en servicio: %w", err)
 	}
 
+	confirmSentChatMessage(conn, msg.PID, savedMessage)
+
+	logger.Successf(handlerSendChatMessageLogComponent, "Mensaje de UserID %d (ChatID: %s, PID: %s) procesado. Notificación de estado 'sent' enviada.", conn.ID, payload.ChatId, msg.PID)
+
+	// El ACK genérico ya no es necesario si enviamos una actualización de estado.
+	// conn.SendServerAck(msg.PID, "processed", nil)
+
+	return nil
+}
+
+// confirmSentChatMessage envía al remitente el estado 'sent' del mensaje guardado y lo
+// sincroniza con sus demás dispositivos.
+func confirmSentChatMessage(conn *customws.Connection[wsmodels.WsUserData], originalPID string, savedMessage *wsmodels.MessageDB) {
+	confirmSentChatMessageToSender(conn, originalPID, savedMessage)
+
+	// Los demás dispositivos del remitente también deben mostrar el mensaje enviado.
+	ownDevicesMsg := types.ServerToClientMessage{
+		PID:        conn.Manager().Callbacks().GeneratePID(),
+		Type:       types.MessageTypeNewChatMessage,
+		FromUserID: conn.ID,
+		Payload:    savedMessage,
+	}
+	if err := conn.Manager().SendMessageToOtherDevices(conn, ownDevicesMsg); err != nil {
+		logger.Warnf(handlerSendChatMessageLogComponent, "Error sincronizando el mensaje %s con otros dispositivos de UserID %d: %v", savedMessage.Id, conn.ID, err)
+	}
+}
+
+// confirmSentChatMessageToSender envía el estado 'sent' del mensaje a la conexión que lo envió.
+func confirmSentChatMessageToSender(conn *customws.Connection[wsmodels.WsUserData], originalPID string, savedMessage *wsmodels.MessageDB) {
 	// Enviar una confirmación de estado 'sent' al remitente original.
 	// Esto reemplaza el simple "processed" ACK con una notificación de estado más informativa.
 	statusUpdatePayload := map[string]interface{}{
-		"originalPID": msg.PID, // El PID original que el cliente envió
+		"originalPID": originalPID, // El PID original que el cliente envió
 		"message":     savedMessage,
 	}
 
@@ -110,14 +166,7 @@ func HandleSendChatMessage(conn *customws.Connection[wsmodels.WsUserData], msg t
 	}
 
 	if err := conn.SendMessage(statusUpdateMsg); err != nil {
-		logger.Errorf(handlerSendChatMessageLogComponent, "Error enviando message_status_update a UserID %d para PID %s: %v", conn.ID, msg.PID, err)
+		logger.Errorf(handlerSendChatMessageLogComponent, "Error enviando message_status_update a UserID %d para PID %s: %v", conn.ID, originalPID, err)
 		// No devolvemos error aquí para no cerrar la conexión, pero sí lo registramos.
 	}
-
-	logger.Successf(handlerSendChatMessageLogComponent, "Mensaje de UserID %d (ChatID: %s, PID: %s) procesado. Notificación de estado 'sent' enviada.", conn.ID, payload.ChatId, msg.PID)
-
-	// El ACK genérico ya no es necesario si enviamos una actualización de estado.
-	// conn.SendServerAck(msg.PID, "processed", nil)
-
-	return nil
 }
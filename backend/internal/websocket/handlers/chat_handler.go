@@ -5,18 +5,40 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/services"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
 )
 
+// GetChatListPayload es el payload opcional de chat/get_list. Con SyncToken ("" para la
+// primera sincronización) la respuesta es chat_list_delta; sin él, la lista completa en chat_list.
+type GetChatListPayload struct {
+	SyncToken *string `json:"syncToken,omitempty" validate:"omitempty,max=256"`
+}
+
 // HandleGetChatList maneja la solicitud del cliente para obtener su lista de chats.
 func HandleGetChatList(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
 	logger.Infof("HANDLER_CHAT", "User %d solicitó lista de chats. PID: %s", conn.ID, msg.PID)
 
-	chatList, err := services.GetChatListForUser(conn.ID, conn.Manager())
+	if msg.Payload != nil {
+		var listPayload GetChatListPayload
+		payloadBytes, err := json.Marshal(msg.Payload)
+		if err == nil {
+			err = json.Unmarshal(payloadBytes, &listPayload)
+		}
+		if err != nil {
+			conn.SendAppError(msg.PID, apperrors.InvalidPayload, "Error decodificando payload de get_list: "+err.Error())
+			return fmt.Errorf("error decodificando el payload de get_list: %w", err)
+		}
+		if listPayload.SyncToken != nil {
+			return sendChatListDelta(conn, msg, *listPayload.SyncToken)
+		}
+	}
+
+	chatList, err := deps.Chat.GetChatListForUser(conn.ID, conn.Manager())
 	if err != nil {
 		logger.Errorf("HANDLER_CHAT", "Error obteniendo chat list para user %d: %v", conn.ID, err)
 		errMsg := types.ServerToClientMessage{
@@ -66,30 +88,71 @@ func HandleGetChatList(conn *customws.Connection[wsmodels.WsUserData], msg types
 	return nil
 }
 
+// sendChatListDelta responde a HandleGetChatList en modo sincronización con los chats que
+// cambiaron desde syncToken (chat_list_delta).
+func sendChatListDelta(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, syncToken string) error {
+	delta, err := deps.Chat.GetChatListDelta(conn.ID, syncToken, conn.Manager())
+	if err != nil {
+		appErr := apperrors.From(err, "Error al sincronizar la lista de chats.")
+		if appErr.Code == apperrors.Internal {
+			logger.Errorf("HANDLER_CHAT", "Error sincronizando la lista de chats de user %d: %v", conn.ID, err)
+		}
+		conn.SendAppError(msg.PID, appErr.Code, appErr.Message)
+		return err
+	}
+
+	responseMsg := types.ServerToClientMessage{
+		PID:        conn.Manager().Callbacks().GeneratePID(),
+		Type:       types.MessageTypeChatListDelta,
+		FromUserID: conn.ID,
+		Payload:    delta,
+	}
+	if err := conn.SendMessage(responseMsg); err != nil {
+		logger.Errorf("HANDLER_CHAT", "Error enviando la sincronización de chats a user %d: %v", conn.ID, err)
+		return err
+	}
+
+	if msg.PID != "" {
+		ackMsg := types.ServerToClientMessage{
+			PID:        conn.Manager().Callbacks().GeneratePID(),
+			Type:       types.MessageTypeServerAck,
+			FromUserID: conn.ID,
+			Payload:    types.AckPayload{AcknowledgedPID: msg.PID, Status: "chat_list_delta_sent"},
+		}
+		if err := conn.SendMessage(ackMsg); err != nil {
+			logger.Warnf("HANDLER_CHAT", "Error enviando ServerAck para GetChatList a UserID %d para PID %s: %v", conn.ID, msg.PID, err)
+		}
+	}
+	return nil
+}
+
+// GetChatHistoryPayload es el payload de chat/get_history. Con Cursor ("" para la primera
+// página) la respuesta es chat_history_page; sin él se usa BeforeMessageID, que queda obsoleto.
+type GetChatHistoryPayload struct {
+	ChatID          string  `json:"chatId" validate:"required,max=64"`
+	Limit           int     `json:"limit,omitempty" validate:"min=0,max=100"`
+	BeforeMessageID string  `json:"beforeMessageId,omitempty" validate:"max=64"`
+	Cursor          *string `json:"cursor,omitempty"`
+}
+
 // HandleGetChatHistory maneja la solicitud del cliente para obtener el historial de mensajes de un chat.
 func HandleGetChatHistory(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
 	logger.Infof("HANDLER_CHAT", "User %d solicitó historial de chat. PID: %s", conn.ID, msg.PID)
 
-	type GetChatHistoryPayload struct {
-		ChatID          string `json:"chatId"`
-		Limit           int    `json:"limit,omitempty"`
-		BeforeMessageID string `json:"beforeMessageId,omitempty"`
-	}
-
 	var historyPayload GetChatHistoryPayload
 	// msg.Payload should now directly contain the data for GetChatHistoryPayload
 	payloadBytes, err := json.Marshal(msg.Payload)
 	if err != nil {
-		conn.SendErrorNotification(msg.PID, 400, "Error procesando payload de get_history (marshal): "+err.Error())
+		conn.SendAppError(msg.PID, apperrors.InvalidPayload, "Error procesando payload de get_history (marshal): "+err.Error())
 		return fmt.Errorf("error marshalling get_history payload: %w", err)
 	}
 	if err := json.Unmarshal(payloadBytes, &historyPayload); err != nil {
-		conn.SendErrorNotification(msg.PID, 400, "Error decodificando payload de get_history (unmarshal): "+err.Error())
+		conn.SendAppError(msg.PID, apperrors.InvalidPayload, "Error decodificando payload de get_history (unmarshal): "+err.Error())
 		return fmt.Errorf("error unmarshalling get_history payload: %w", err)
 	}
 
 	if historyPayload.ChatID == "" {
-		conn.SendErrorNotification(msg.PID, 400, "ChatID es requerido para obtener el historial.")
+		conn.SendAppError(msg.PID, apperrors.ChatIdRequired, "ChatID es requerido para obtener el historial.")
 		return errors.New("chatID no especificado en get_history")
 	}
 
@@ -97,10 +160,14 @@ func HandleGetChatHistory(conn *customws.Connection[wsmodels.WsUserData], msg ty
 		historyPayload.Limit = 50 // Default limit
 	}
 
-	messages, err := services.GetChatHistory(historyPayload.ChatID, conn.ID, historyPayload.Limit, historyPayload.BeforeMessageID, conn.Manager())
+	if historyPayload.Cursor != nil {
+		return sendChatHistoryPage(conn, msg, historyPayload)
+	}
+
+	messages, err := deps.Chat.GetChatHistory(historyPayload.ChatID, conn.ID, historyPayload.Limit, historyPayload.BeforeMessageID, conn.Manager())
 	if err != nil {
 		logger.Errorf("HANDLER_CHAT", "Error obteniendo historial para chat %s, user %d: %v", historyPayload.ChatID, conn.ID, err)
-		conn.SendErrorNotification(msg.PID, 500, "Error al obtener el historial del chat: "+err.Error())
+		conn.SendAppError(msg.PID, apperrors.ChatHistoryError, "Error al obtener el historial del chat.")
 		return err
 	}
 
@@ -135,3 +202,44 @@ func HandleGetChatHistory(conn *customws.Connection[wsmodels.WsUserData], msg ty
 	logger.Successf("HANDLER_CHAT", "Historial de chat %s enviado a user %d. PID respuesta: %s", historyPayload.ChatID, conn.ID, responseMsg.PID)
 	return nil
 }
+
+// sendChatHistoryPage responde a HandleGetChatHistory en modo cursor con una página del
+// historial (chat_history_page).
+func sendChatHistoryPage(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, historyPayload GetChatHistoryPayload) error {
+	params, err := pagination.New(*historyPayload.Cursor, historyPayload.Limit)
+	if err != nil {
+		conn.SendAppError(msg.PID, apperrors.InvalidPayload, err.Error())
+		return err
+	}
+
+	page, err := deps.Chat.GetChatHistoryPage(historyPayload.ChatID, conn.ID, params)
+	if err != nil {
+		logger.Errorf("HANDLER_CHAT", "Error obteniendo página del historial para chat %s, user %d: %v", historyPayload.ChatID, conn.ID, err)
+		conn.SendAppError(msg.PID, apperrors.ChatHistoryError, "Error al obtener el historial del chat.")
+		return err
+	}
+
+	responseMsg := types.ServerToClientMessage{
+		PID:        conn.Manager().Callbacks().GeneratePID(),
+		Type:       types.MessageTypeChatHistoryPage,
+		FromUserID: conn.ID,
+		Payload:    page,
+	}
+	if err := conn.SendMessage(responseMsg); err != nil {
+		logger.Errorf("HANDLER_CHAT", "Error enviando página del historial de chat %s a user %d: %v", historyPayload.ChatID, conn.ID, err)
+		return err
+	}
+
+	if msg.PID != "" {
+		ackMsg := types.ServerToClientMessage{
+			PID:        conn.Manager().Callbacks().GeneratePID(),
+			Type:       types.MessageTypeServerAck,
+			FromUserID: conn.ID,
+			Payload:    types.AckPayload{AcknowledgedPID: msg.PID, Status: "chat_history_page_sent"},
+		}
+		if err := conn.SendMessage(ackMsg); err != nil {
+			logger.Warnf("HANDLER_CHAT", "Error enviando ServerAck para GetChatHistory a UserID %d para PID %s: %v", conn.ID, msg.PID, err)
+		}
+	}
+	return nil
+}
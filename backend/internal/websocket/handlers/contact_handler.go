@@ -15,16 +15,23 @@ import (
 	"github.com/google/uuid"
 )
 
+// FriendRequestPayload es el payload de friend/accept_request y friend/reject_request.
+type FriendRequestPayload struct {
+	NotificationId string `json:"notificationId" validate:"required"`
+	Timestamp      string `json:"timestamp"`
+}
+
+// ContactRequestPayload es el payload de friend/contact.
+type ContactRequestPayload struct {
+	ToUserID       int64  `json:"toUserId" validate:"required,min=1"`
+	RequestMessage string `json:"message" validate:"max=500"`
+}
+
 // HandleAcceptFriendRequest maneja la solicitud del cliente para aceptar una solicitud de amistad.
 func HandleAcceptFriendRequest(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
 	logger.Infof("HANDLER_CONTACT", "User %d aceptando solicitud de amistad. PID: %s", conn.ID, msg.PID)
 
-	type AcceptFriendRequestPayload struct {
-		NotificationId string `json:"notificationId"`
-		Timestamp      string `json:"timestamp"`
-	}
-
-	var payload AcceptFriendRequestPayload
+	var payload FriendRequestPayload
 	payloadBytes, err := json.Marshal(msg.Payload)
 	if err != nil {
 		conn.SendErrorNotification(msg.PID, 400, "Error procesando payload de accept_request: "+err.Error())
@@ -41,6 +48,10 @@ func HandleAcceptFriendRequest(conn *customws.Connection[wsmodels.WsUserData], m
 	}
 
 	err = services.AcceptFriendRequest(conn.ID, payload.NotificationId, payload.Timestamp, conn.Manager())
+	if errors.Is(err, services.ErrContactBlocked) {
+		conn.SendErrorNotification(msg.PID, 403, "No puedes aceptar la solicitud de este usuario.")
+		return nil
+	}
 	if err != nil {
 		logger.Errorf("HANDLER_CONTACT", "Error aceptando solicitud de amistad para user %d: %v", conn.ID, err)
 		conn.SendErrorNotification(msg.PID, 500, "Error al aceptar la solicitud de amistad: "+err.Error())
@@ -69,12 +80,7 @@ func HandleAcceptFriendRequest(conn *customws.Connection[wsmodels.WsUserData], m
 func HandleRejectFriendRequest(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
 	logger.Infof("HANDLER_CONTACT", "User %d rechazando solicitud de amistad. PID: %s", conn.ID, msg.PID)
 
-	type RejectFriendRequestPayload struct {
-		NotificationId string `json:"notificationId"`
-		Timestamp      string `json:"timestamp"`
-	}
-
-	var payload RejectFriendRequestPayload
+	var payload FriendRequestPayload
 	payloadBytes, err := json.Marshal(msg.Payload)
 	if err != nil {
 		conn.SendErrorNotification(msg.PID, 400, "Error procesando payload de reject_request: "+err.Error())
@@ -117,10 +123,7 @@ func HandleRejectFriendRequest(conn *customws.Connection[wsmodels.WsUserData], m
 
 // HandleContactRequest maneja una nueva solicitud de contacto de un usuario a otro.
 func HandleContactRequest(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
-	var payload struct {
-		ToUserID       int64  `json:"toUserId"`
-		RequestMessage string `json:"message"`
-	}
+	var payload ContactRequestPayload
 
 	payloadBytes, err := json.Marshal(msg.Payload)
 	if err != nil {
@@ -148,6 +151,19 @@ func HandleContactRequest(conn *customws.Connection[wsmodels.WsUserData], msg ty
 		return nil // Se notificó adecuadamente, no propagar error
 	}
 
+	// Un bloqueo en cualquier sentido impide la solicitud. No se indica quién bloqueó a quién.
+	blocked, err := queries.IsBlockedEither(fromUserID, payload.ToUserID)
+	if err != nil {
+		logger.Errorf("HANDLER_CONTACT", "Error al verificar bloqueos entre %d y %d: %v", fromUserID, payload.ToUserID, err)
+		conn.SendErrorNotification(msg.PID, 500, "Error interno al procesar la solicitud.")
+		return err
+	}
+	if blocked {
+		logger.Warnf("HANDLER_CONTACT", "Solicitud de contacto rechazada por bloqueo entre %d y %d", fromUserID, payload.ToUserID)
+		conn.SendErrorNotification(msg.PID, 403, "No puedes enviar una solicitud de contacto a este usuario.")
+		return nil
+	}
+
 	// --- NUEVA VALIDACIÓN: Verificar si ya existe una solicitud de contacto entre los usuarios ---
 	exists, err := queries.CheckContactExists(fromUserID, payload.ToUserID)
 	if err != nil {
@@ -20,15 +20,13 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
-	"time"
 
-	"github.com/davidM20/micro-service-backend-go.git/internal/db"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
-	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/services"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/timefmt"
 )
 
 type RequestData[T any] struct {
@@ -38,7 +36,7 @@ type RequestData[T any] struct {
 // Payloads para la deserialización de datos del cliente
 type EducationPayload struct {
 	Id                  int64  `json:"id"`
-	Institution         string `json:"institution"`
+	Institution         string `json:"institution" validate:"required,max=255"`
 	Degree              string `json:"degree"`
 	Campus              string `json:"campus"`
 	GraduationDate      string `json:"graduationDate,omitempty"`
@@ -47,8 +45,8 @@ type EducationPayload struct {
 
 type WorkExperiencePayload struct {
 	Id           int64  `json:"id"`
-	Company      string `json:"company"`
-	Position     string `json:"position"`
+	Company      string `json:"company" validate:"required,max=255"`
+	Position     string `json:"position" validate:"required,max=255"`
 	StartDate    string `json:"startDate,omitempty"`
 	EndDate      string `json:"endDate,omitempty"`
 	Description  string `json:"description"`
@@ -57,8 +55,8 @@ type WorkExperiencePayload struct {
 
 type ProjectPayload struct {
 	Id              int64  `json:"id"`
-	Title           string `json:"title"`
-	Role            string `json:"role"`
+	Title           string `json:"title" validate:"required,max=255"`
+	Role            string `json:"role" validate:"required,max=255"`
 	Description     string `json:"description"`
 	Company         string `json:"company"`
 	Document        string `json:"document"`
@@ -70,20 +68,20 @@ type ProjectPayload struct {
 
 type SkillPayload struct {
 	Id    int64  `json:"id"`
-	Skill string `json:"skill"`
-	Level string `json:"level"`
+	Skill string `json:"skill" validate:"required,max=255"`
+	Level string `json:"level" validate:"required,max=255"`
 }
 
 type LanguagePayload struct {
 	Id       int64  `json:"id"`
-	Language string `json:"language"`
-	Level    string `json:"level"`
+	Language string `json:"language" validate:"required,max=255"`
+	Level    string `json:"level" validate:"required,max=255"`
 }
 
 type CertificationPayload struct {
 	Id            int64  `json:"id"`
-	Certification string `json:"certification"`
-	Institution   string `json:"institution"`
+	Certification string `json:"certification" validate:"required,max=255"`
+	Institution   string `json:"institution" validate:"required,max=255"`
 	DateObtained  string `json:"dateObtained,omitempty"`
 }
 
@@ -115,10 +113,7 @@ func HandleSetSkill(conn *customws.Connection[wsmodels.WsUserData], msg types.Cl
 		Level:    skillPayload.Level,
 	}
 
-	dbConn := db.GetDB()
-	cvService := services.NewCVService(dbConn)
-
-	if err := cvService.SetSkill(&skillModel); err != nil {
+	if err := deps.CV.SetSkill(&skillModel); err != nil {
 		logger.Errorf("CV_HANDLER", "Error al establecer habilidad: %v", err)
 		conn.SendErrorNotification(msg.PID, 500, "Error interno al establecer habilidad.")
 		return nil
@@ -128,7 +123,8 @@ func HandleSetSkill(conn *customws.Connection[wsmodels.WsUserData], msg types.Cl
 		PID:        conn.Manager().Callbacks().GeneratePID(),
 		Type:       "set_skill_success",
 		FromUserID: 0,
-		Payload:    map[string]interface{}{"status": "success"},
+		// Skill es el nombre guardado: el canónico si la habilidad se reconoció en el catálogo.
+		Payload: map[string]interface{}{"status": "success", "skill": skillModel.Skill, "skillCatalogId": skillModel.SkillCatalogId},
 	}
 
 	if err := conn.SendMessage(responseMsg); err != nil {
@@ -164,10 +160,7 @@ func HandleSetLanguage(conn *customws.Connection[wsmodels.WsUserData], msg types
 		Level:    languagePayload.Level,
 	}
 
-	dbConn := db.GetDB()
-	cvService := services.NewCVService(dbConn)
-
-	if err := cvService.SetLanguage(&languageModel); err != nil {
+	if err := deps.CV.SetLanguage(&languageModel); err != nil {
 		logger.Errorf("CV_HANDLER", "Error al establecer idioma: %v", err)
 		conn.SendErrorNotification(msg.PID, 500, "Error interno al establecer idioma.")
 		return nil
@@ -207,8 +200,8 @@ func HandleSetWorkExperience(conn *customws.Connection[wsmodels.WsUserData], msg
 	}
 
 	// Convertir payload a modelo de BD
-	startDate, _ := time.Parse(time.RFC3339, experiencePayload.StartDate)
-	endDate, _ := time.Parse(time.RFC3339, experiencePayload.EndDate)
+	startDate, _ := timefmt.Parse(experiencePayload.StartDate)
+	endDate, _ := timefmt.Parse(experiencePayload.EndDate)
 
 	experienceModel := models.WorkExperience{
 		Id:           experiencePayload.Id,
@@ -221,11 +214,7 @@ func HandleSetWorkExperience(conn *customws.Connection[wsmodels.WsUserData], msg
 		IsCurrentJob: sql.NullBool{Bool: experiencePayload.IsCurrentJob, Valid: true},
 	}
 
-	// Usar el servicio de CV
-	dbConn := db.GetDB()
-	cvService := services.NewCVService(dbConn)
-
-	if err := cvService.SetWorkExperience(&experienceModel); err != nil {
+	if err := deps.CV.SetWorkExperience(&experienceModel); err != nil {
 		logger.Errorf("CV_HANDLER", "Error al establecer experiencia laboral: %v", err)
 		conn.SendErrorNotification(msg.PID, 500, "Error interno al establecer experiencia laboral.")
 		return nil
@@ -265,7 +254,7 @@ func HandleSetCertification(conn *customws.Connection[wsmodels.WsUserData], msg
 		return nil
 	}
 
-	dateObtained, _ := time.Parse(time.RFC3339, certPayload.DateObtained)
+	dateObtained, _ := timefmt.Parse(certPayload.DateObtained)
 
 	certificationModel := models.Certifications{
 		Id:            certPayload.Id,
@@ -275,10 +264,7 @@ func HandleSetCertification(conn *customws.Connection[wsmodels.WsUserData], msg
 		DateObtained:  sql.NullTime{Time: dateObtained, Valid: !dateObtained.IsZero()},
 	}
 
-	dbConn := db.GetDB()
-	cvService := services.NewCVService(dbConn)
-
-	if err := cvService.SetCertification(&certificationModel); err != nil {
+	if err := deps.CV.SetCertification(&certificationModel); err != nil {
 		logger.Errorf("CV_HANDLER", "Error al establecer certificación: %v", err)
 		conn.SendErrorNotification(msg.PID, 500, "Error interno al establecer certificación.")
 		return nil
@@ -318,8 +304,8 @@ func HandleSetProject(conn *customws.Connection[wsmodels.WsUserData], msg types.
 	}
 
 	// Convertir payload a modelo de BD
-	startDate, _ := time.Parse(time.RFC3339, projectPayload.StartDate)
-	endDate, _ := time.Parse(time.RFC3339, projectPayload.ExpectedEndDate)
+	startDate, _ := timefmt.Parse(projectPayload.StartDate)
+	endDate, _ := timefmt.Parse(projectPayload.ExpectedEndDate)
 
 	projectModel := models.Project{
 		Id:              projectPayload.Id,
@@ -335,11 +321,7 @@ func HandleSetProject(conn *customws.Connection[wsmodels.WsUserData], msg types.
 		IsOngoing:       sql.NullBool{Bool: projectPayload.IsOngoing, Valid: true},
 	}
 
-	// Usar el servicio de CV
-	dbConn := db.GetDB()
-	cvService := services.NewCVService(dbConn)
-
-	if err := cvService.SetProject(&projectModel); err != nil {
+	if err := deps.CV.SetProject(&projectModel); err != nil {
 		logger.Errorf("CV_HANDLER", "Error al establecer proyecto: %v", err)
 		conn.SendErrorNotification(msg.PID, 500, "Error interno al establecer proyecto.")
 		return nil
@@ -380,7 +362,7 @@ func HandleSetEducation(conn *customws.Connection[wsmodels.WsUserData], msg type
 	}
 
 	// Convertir payload a modelo de BD
-	gradDate, _ := time.Parse(time.RFC3339, educationPayload.GraduationDate)
+	gradDate, _ := timefmt.Parse(educationPayload.GraduationDate)
 
 	educationModel := models.Education{
 		Id:                  educationPayload.Id,
@@ -392,11 +374,7 @@ func HandleSetEducation(conn *customws.Connection[wsmodels.WsUserData], msg type
 		IsCurrentlyStudying: sql.NullBool{Bool: educationPayload.IsCurrentlyStudying, Valid: true},
 	}
 
-	// Usar el servicio de CV
-	dbConn := db.GetDB()
-	cvService := services.NewCVService(dbConn)
-
-	if err := cvService.SetEducation(&educationModel); err != nil {
+	if err := deps.CV.SetEducation(&educationModel); err != nil {
 		logger.Errorf("CV_HANDLER", "Error al establecer educación: %v", err)
 		conn.SendErrorNotification(msg.PID, 500, "Error interno al establecer educación.")
 		return nil
@@ -421,11 +399,7 @@ func HandleSetEducation(conn *customws.Connection[wsmodels.WsUserData], msg type
 func HandleGetCV(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
 	logger.Infof("CV_HANDLER", "Obteniendo CV para UserID %d. PID: %s", conn.ID, msg.PID)
 
-	// Usar el servicio de CV
-	dbConn := db.GetDB()
-	cvService := services.NewCVService(dbConn)
-
-	cv, err := cvService.GetCV(conn.ID)
+	cv, err := deps.CV.GetCV(conn.ID)
 	if err != nil {
 		logger.Errorf("CV_HANDLER", "Error al obtener CV: %v", err)
 		conn.SendErrorNotification(msg.PID, 500, "Error interno al obtener CV.")
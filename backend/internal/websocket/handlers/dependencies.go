@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"database/sql"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/services"
+)
+
+// Dependencies agrupa los servicios que usan los handlers WebSocket. main registra las
+// implementaciones reales con SetDependencies(NewDependencies(db)); las pruebas pueden
+// registrar mocks de las interfaces en su lugar.
+type Dependencies struct {
+	Chat         services.IChatService
+	CV           services.ICVService
+	Feed         services.IFeedService
+	Notification services.INotificationService
+}
+
+// deps son las dependencias registradas. Se asignan al arrancar, antes de aceptar conexiones,
+// y después solo se leen.
+var deps Dependencies
+
+// NewDependencies crea los servicios reales sobre la conexión a la base de datos. Los
+// servicios de chat y notificaciones usan además la conexión de InitializeChatService e
+// InitializeNotificationService.
+func NewDependencies(db *sql.DB) Dependencies {
+	return Dependencies{
+		Chat:         services.NewChatService(),
+		CV:           services.NewCVService(db),
+		Feed:         services.NewFeedService(db),
+		Notification: services.NewNotificationService(),
+	}
+}
+
+// SetDependencies registra los servicios que usarán los handlers.
+func SetDependencies(d Dependencies) {
+	deps = d
+}
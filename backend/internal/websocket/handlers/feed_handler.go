@@ -8,7 +8,9 @@ import (
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/etag"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
 )
 
 /*
@@ -25,7 +27,7 @@ import (
  * FUNCIONAMIENTO:
  * ---------------
  * 1. Recibe la solicitud del cliente a través del router de mensajes WebSocket.
- * 2. Utiliza FeedService para obtener los datos del feed.
+ * 2. Utiliza el servicio del feed para obtener los datos del feed.
  * 3. Construye un mensaje de respuesta con los items del feed.
  * 4. Envía la respuesta al cliente a través de la conexión WebSocket.
  * 5. Maneja errores y envía notificaciones de error si es necesario.
@@ -37,50 +39,39 @@ import (
  *
  * INYECCIÓN DE DEPENDENCIAS:
  * -------------------------
- * Se inicializa con una instancia de FeedService para desacoplar la lógica de negocio.
+ * Usa el IFeedService registrado con SetDependencies (ver dependencies.go) para
+ * desacoplar la lógica de negocio y poder probarlo con un mock.
  */
 
-// FeedHandler maneja las solicitudes WebSocket para el recurso feed.
-type FeedHandler struct {
-	feedService *services.FeedService
-}
-
-// feedHandlerGlobal es una instancia global del FeedHandler.
-// Se inicializa en main o en un paquete de inicialización.
-// TODO: Asegurar que esta instancia se inicialice correctamente con sus dependencias.
-var feedHandlerGlobal *FeedHandler
-
-// InitializeFeedHandler establece la instancia global del FeedHandler.
-// Esta función debe ser llamada durante la inicialización de la aplicación,
-// después de crear las instancias de los servicios necesarios.
-func InitializeFeedHandler(fs *services.FeedService) {
-	feedHandlerGlobal = NewFeedHandler(fs)
-	logger.Info("FEED_HANDLER", "FeedHandler inicializado correctamente.")
-}
-
-// NewFeedHandler crea una nueva instancia de FeedHandler.
-func NewFeedHandler(feedService *services.FeedService) *FeedHandler {
-	return &FeedHandler{feedService: feedService}
+// GetFeedListPayload es el payload de feed/get_list. Con Cursor ("" para la primera página)
+// se pagina por cursor y Page se ignora; la paginación por Page está obsoleta.
+type GetFeedListPayload struct {
+	Page        int     `json:"page,omitempty" validate:"min=0"`
+	Limit       int     `json:"limit,omitempty" validate:"min=0"`
+	Cursor      *string `json:"cursor,omitempty"`
+	IfNoneMatch string  `json:"ifNoneMatch,omitempty"`
 }
 
 // HandleGetFeedList procesa la solicitud para obtener la lista de items del feed.
-// Esta función puede ser llamada directamente desde el router si se opta por no usar la instancia global.
 func HandleGetFeedList(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
-	if feedHandlerGlobal == nil || feedHandlerGlobal.feedService == nil {
-		logger.Error("FEED_HANDLER", "HandleGetFeedList llamado pero FeedHandler no está inicializado.")
-		conn.SendErrorNotification(msg.PID, 500, "Error interno del servidor: FeedHandler no inicializado.")
-		return errors.New("FeedHandler no inicializado")
+	if deps.Feed == nil {
+		logger.Error("FEED_HANDLER", "HandleGetFeedList llamado pero el servicio del feed no está inicializado.")
+		conn.SendErrorNotification(msg.PID, 500, "Error interno del servidor: servicio del feed no inicializado.")
+		return errors.New("servicio del feed no inicializado")
 	}
-	return feedHandlerGlobal.getFeedList(conn, msg)
+	return getFeedList(deps.Feed, conn, msg)
 }
 
 // getFeedList es el método interno que realmente maneja la lógica.
-func (h *FeedHandler) getFeedList(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
+func getFeedList(feedService services.IFeedService, conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
 	userID := conn.ID
 	logger.Infof("FEED_HANDLER", "Procesando get_list para el feed, UserID: %d, PID: %s", userID, msg.PID)
 
-	// Extraer parámetros de paginación del payload
+	// Extraer parámetros de paginación del payload. Con "cursor" (vacío para la primera
+	// página) se pagina por cursor; "page" queda para los clientes anteriores.
 	var page, limit int
+	var ifNoneMatch string
+	var cursor *string
 	if data, ok := msg.Payload.(map[string]interface{}); ok {
 		if p, ok := data["page"].(float64); ok {
 			page = int(p)
@@ -88,6 +79,19 @@ func (h *FeedHandler) getFeedList(conn *customws.Connection[wsmodels.WsUserData]
 		if l, ok := data["limit"].(float64); ok {
 			limit = int(l)
 		}
+		ifNoneMatch, _ = data["ifNoneMatch"].(string)
+		if c, ok := data["cursor"].(string); ok {
+			cursor = &c
+		}
+	}
+
+	var params pagination.Params
+	if cursor != nil {
+		var err error
+		if params, err = pagination.New(*cursor, limit); err != nil {
+			conn.SendErrorNotification(msg.PID, 400, err.Error())
+			return err
+		}
 	}
 
 	// Establecer valores por defecto si no se proporcionaron
@@ -116,7 +120,13 @@ func (h *FeedHandler) getFeedList(conn *customws.Connection[wsmodels.WsUserData]
 	}
 
 	// El servicio ahora devuelve la estructura de payload completa, lista para ser enviada.
-	payload, err := h.feedService.GetFeedItems(userID, page, limit)
+	var payload *wsmodels.FeedListResponsePayload
+	var err error
+	if cursor != nil {
+		payload, err = feedService.GetFeedPage(userID, conn.UserData.TenantID, params)
+	} else {
+		payload, err = feedService.GetFeedItems(userID, conn.UserData.TenantID, page, limit)
+	}
 	if err != nil {
 		// El servicio ya registra el error, así que aquí solo notificamos al cliente.
 		errorMsg := fmt.Sprintf("no se pudo obtener el feed para el usuario %d", userID)
@@ -124,6 +134,16 @@ func (h *FeedHandler) getFeedList(conn *customws.Connection[wsmodels.WsUserData]
 		return fmt.Errorf("error desde feedService.GetFeedItems: %w", err)
 	}
 
+	// ETag de la página: si el cliente ya la tiene, se envía solo la confirmación
+	itemCount := len(payload.Items)
+	if tag, err := etag.FromJSON(payload); err != nil {
+		logger.Warnf("FEED_HANDLER", "No se pudo calcular el ETag del feed de UserID %d: %v", userID, err)
+	} else if etag.Matches(ifNoneMatch, tag) {
+		payload = &wsmodels.FeedListResponsePayload{ETag: tag, NotModified: true}
+	} else {
+		payload.ETag = tag
+	}
+
 	// Creamos el mensaje de respuesta con el payload que ya tiene el formato correcto.
 	responseMessage := types.ServerToClientMessage{
 		PID:        conn.Manager().Callbacks().GeneratePID(),
@@ -137,6 +157,10 @@ func (h *FeedHandler) getFeedList(conn *customws.Connection[wsmodels.WsUserData]
 		return err
 	}
 
-	logger.Successf("FEED_HANDLER", "Lista del feed (data_event) enviada exitosamente a UserID %d. Items: %d", userID, len(payload.Items))
+	if payload.NotModified {
+		logger.Successf("FEED_HANDLER", "Lista del feed sin cambios para UserID %d (not modified). Items: %d", userID, itemCount)
+		return nil
+	}
+	logger.Successf("FEED_HANDLER", "Lista del feed (data_event) enviada exitosamente a UserID %d. Items: %d", userID, itemCount)
 	return nil
 }
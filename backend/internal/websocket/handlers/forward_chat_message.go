@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/services"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ForwardChatMessagePayload es el payload de chat/forward_message. El destino es chatId (chat
+// privado o de grupo) o, si se omite, el chat con el contacto toUserId.
+type ForwardChatMessagePayload struct {
+	MessageId string `json:"messageId" validate:"required,max=64"`
+	ChatId    string `json:"chatId,omitempty" validate:"max=64"`
+	ToUserId  int64  `json:"toUserId,omitempty" validate:"min=0"`
+}
+
+// HandleForwardChatMessage reenvía un mensaje existente a otro chat o contacto. El remitente
+// recibe la copia con message_status_update, igual que al enviar un mensaje.
+func HandleForwardChatMessage(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
+	const logComponent = "HANDLER_FORWARD_CHAT_MESSAGE"
+
+	var payload ForwardChatMessagePayload
+	raw, err := json.Marshal(msg.Payload)
+	if err == nil {
+		err = json.Unmarshal(raw, &payload)
+	}
+	if err != nil {
+		logger.Warnf(logComponent, "Payload inválido de UserID %d, PID %s: %v", conn.ID, msg.PID, err)
+		conn.SendAppError(msg.PID, apperrors.InvalidPayload, "payload inválido")
+		return fmt.Errorf("payload inválido: %w", err)
+	}
+	if payload.MessageId == "" || (payload.ChatId == "" && payload.ToUserId == 0) {
+		conn.SendAppError(msg.PID, apperrors.MissingFields, "se requieren messageId y chatId o toUserId")
+		return fmt.Errorf("messageId y destino requeridos")
+	}
+
+	savedMessage, err := services.ForwardChatMessage(conn.ID, payload.MessageId, payload.ChatId, payload.ToUserId, uuid.NewString(), conn.Manager())
+	if err != nil {
+		appErr := apperrors.From(err, "Error interno al reenviar el mensaje")
+		if appErr.Code == apperrors.Internal {
+			logger.Errorf(logComponent, "Error reenviando el mensaje %s de UserID %d: %v", payload.MessageId, conn.ID, err)
+		} else {
+			logger.Warnf(logComponent, "Reenvío del mensaje %s de UserID %d rechazado: %v", payload.MessageId, conn.ID, err)
+		}
+		conn.SendAppError(msg.PID, appErr.Code, appErr.Message)
+		return nil
+	}
+
+	confirmSentChatMessage(conn, msg.PID, savedMessage)
+	logger.Successf(logComponent, "Mensaje %s reenviado por UserID %d como %s", payload.MessageId, conn.ID, savedMessage.Id)
+	return nil
+}
@@ -4,43 +4,46 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/services"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 )
 
+// MarkMessageReadPayload es el payload de chat/mark_read.
+type MarkMessageReadPayload struct {
+	MessageId string `json:"messageId" validate:"required,max=64"`
+}
+
 // HandleMarkMessageRead procesa la petición del cliente para marcar un mensaje como leído.
 // Se espera un payload: { "messageId": string }
 func HandleMarkMessageRead(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
 	const logComponent = "HANDLER_MARK_MESSAGE_READ"
 
-	var payload struct {
-		MessageId string `json:"messageId"`
-	}
+	var payload MarkMessageReadPayload
 
 	raw, err := json.Marshal(msg.Payload)
 	if err != nil {
 		logger.Warnf(logComponent, "Error marshalling payload: %v", err)
-		conn.SendErrorNotification(msg.PID, 400, "payload inválido")
+		conn.SendAppError(msg.PID, apperrors.InvalidPayload, "payload inválido")
 		return fmt.Errorf("payload inválido: %w", err)
 	}
 	if err := json.Unmarshal(raw, &payload); err != nil {
 		logger.Warnf(logComponent, "Error unmarshalling payload: %v", err)
-		conn.SendErrorNotification(msg.PID, 400, "payload incorrecto")
+		conn.SendAppError(msg.PID, apperrors.InvalidPayload, "payload incorrecto")
 		return fmt.Errorf("payload incorrecto: %w", err)
 	}
 
 	if payload.MessageId == "" {
-		conn.SendErrorNotification(msg.PID, 400, "messageId requerido")
+		conn.SendAppError(msg.PID, apperrors.MissingFields, "messageId requerido")
 		return fmt.Errorf("messageId requerido")
 	}
 
-	senderID, err := services.MarkMessageAsRead(conn.ID, payload.MessageId, conn.Manager())
+	senderID, err := deps.Chat.MarkMessageAsRead(conn.ID, payload.MessageId, conn.Manager())
 	if err != nil {
 		logger.Errorf(logComponent, "Error marcando mensaje %s como leído: %v", payload.MessageId, err)
-		conn.SendErrorNotification(msg.PID, 500, "Error interno al marcar como leído")
+		conn.SendAppError(msg.PID, apperrors.Internal, "Error interno al marcar como leído")
 		return err
 	}
 
@@ -64,6 +67,20 @@ func HandleMarkMessageRead(conn *customws.Connection[wsmodels.WsUserData], msg t
 		}
 	}
 
+	// Sincronizar la lectura con los demás dispositivos del lector.
+	readSyncMsg := types.ServerToClientMessage{
+		PID:        conn.Manager().Callbacks().GeneratePID(),
+		Type:       types.MessageTypeReadSync,
+		FromUserID: conn.ID,
+		Payload: map[string]interface{}{
+			"resource":  "chat",
+			"messageId": payload.MessageId,
+		},
+	}
+	if err := conn.Manager().SendMessageToOtherDevices(conn, readSyncMsg); err != nil {
+		logger.Warnf(logComponent, "No se pudo sincronizar la lectura del mensaje %s con otros dispositivos de UserID %d: %v", payload.MessageId, conn.ID, err)
+	}
+
 	// Enviar ACK al cliente que ejecutó la acción de marcar como leído.
 	conn.SendServerAck(msg.PID, "marked_read", nil)
 	logger.Infof(logComponent, "Mensaje %s marcado como leído por UserID %d", payload.MessageId, conn.ID)
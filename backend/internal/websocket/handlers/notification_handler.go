@@ -6,9 +6,12 @@
 //
 // 1. HandleGetNotifications:
 //    - Obtiene la lista de notificaciones para un usuario específico
-//    - Soporta paginación mediante limit y offset
+//    - Soporta paginación mediante limit y cursor (ver pkg/pagination). Si el payload
+//      incluye "cursor" (vacío para la primera página) responde con notification_page.
+//      Sin cursor se mantiene la paginación por offset (obsoleta) y notification_list
 //    - Permite filtrar por notificaciones no leídas (onlyUnread)
-//    - Si se solicitan notificaciones no leídas y hay menos de 15, complementa con leídas
+//    - En modo offset, si se solicitan notificaciones no leídas y hay menos de 15,
+//      complementa con leídas
 //    - Caso de uso ideal: Cuando el cliente necesita mostrar el feed de notificaciones
 //      o actualizar la bandeja de entrada de notificaciones
 //
@@ -24,6 +27,12 @@
 //    - Caso de uso ideal: Cuando el usuario quiere marcar todas sus notificaciones
 //      como leídas de una vez, por ejemplo, al hacer clic en "Marcar todas como leídas"
 //
+// 4. HandleMarkNotificationsRead, HandleMarkNotificationsUnread y HandleDeleteNotifications:
+//    - Marcan como leídas, como no leídas o borran las notificaciones indicadas (hasta 100)
+//    - Responden con el número de notificaciones afectadas y avisan a los demás
+//      dispositivos del usuario con read_sync
+//    - Caso de uso ideal: selección múltiple en la bandeja de notificaciones
+//
 // Notas importantes:
 // - Todas las funciones manejan errores y envían respuestas apropiadas al cliente
 // - Se mantiene un registro detallado de las operaciones mediante logs
@@ -38,24 +47,43 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 
-	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/services"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
 )
 
+// GetNotificationsPayload es el payload de notification/get_list y notification/get_pending.
+// Cursor activa la paginación por cursor: "" pide la primera página y el nextCursor de una
+// respuesta, la siguiente. Offset solo se usa sin cursor y está obsoleto.
+type GetNotificationsPayload struct {
+	OnlyUnread bool    `json:"onlyUnread,omitempty"`
+	Limit      int     `json:"limit,omitempty" validate:"min=0,max=100"`
+	Offset     int     `json:"offset,omitempty" validate:"min=0"`
+	Cursor     *string `json:"cursor,omitempty"`
+}
+
+// MarkReadPayload es el payload de notification/mark_read.
+type MarkReadPayload struct {
+	NotificationID string `json:"notificationId" validate:"required"`
+}
+
+// NotificationIDsPayload es el payload de notification/mark_read_bulk, mark_unread_bulk y
+// delete_bulk.
+type NotificationIDsPayload struct {
+	NotificationIDs []string `json:"notificationIds" validate:"required,min=1,max=100"`
+}
+
 // HandleGetNotifications maneja la solicitud para obtener la lista de notificaciones.
 func HandleGetNotifications(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
 	logger.Infof("HANDLER_NOTIFICATION", "Usuario %d solicitó lista de notificaciones. PID: %s, Payload: %+v", conn.ID, msg.PID, msg.Payload)
 
 	// Decodificar payload si es necesario para parámetros (onlyUnread, limit, offset)
-	type GetNotificationsPayload struct {
-		OnlyUnread bool `json:"onlyUnread,omitempty"`
-		Limit      int  `json:"limit,omitempty"`
-		Offset     int  `json:"offset,omitempty"`
-	}
 	var payload GetNotificationsPayload
 	if msg.Payload != nil {
 		payloadBytes, err := json.Marshal(msg.Payload)
@@ -69,6 +97,10 @@ func HandleGetNotifications(conn *customws.Connection[wsmodels.WsUserData], msg
 		}
 	}
 
+	if payload.Cursor != nil {
+		return sendNotificationPage(conn, msg, payload)
+	}
+
 	// Establecer valores por defecto si no se proporcionan o son inválidos
 	// payload.Limit es el que se usará para las llamadas a servicios.
 	if payload.Limit <= 0 {
@@ -78,7 +110,7 @@ func HandleGetNotifications(conn *customws.Connection[wsmodels.WsUserData], msg
 		payload.Offset = 0 // Default offset
 	}
 
-	notifications, err := services.GetNotifications(conn.ID, payload.OnlyUnread, payload.Limit, payload.Offset)
+	notifications, err := deps.Notification.GetNotifications(conn.ID, payload.OnlyUnread, payload.Limit, payload.Offset)
 	if err != nil {
 		logger.Errorf("HANDLER_NOTIFICATION", "Error obteniendo notificaciones para user %d: %v", conn.ID, err)
 		conn.SendErrorNotification(msg.PID, 500, "Error al obtener tus notificaciones: "+err.Error())
@@ -92,7 +124,7 @@ func HandleGetNotifications(conn *customws.Connection[wsmodels.WsUserData], msg
 	if payload.OnlyUnread && len(notifications) < minResultsDesired && len(notifications) < currentLimitFromPayload {
 		logger.Infof("HANDLER_NOTIFICATION", "Usuario %d: (onlyUnread=true) Obtuvo %d notificaciones (de %d solicitadas). Menos de %d. Intentando complementar.", conn.ID, len(notifications), currentLimitFromPayload, minResultsDesired)
 
-		allPotentiallyRelevantNotifications, errAll := services.GetNotifications(conn.ID, false, currentLimitFromPayload, payload.Offset)
+		allPotentiallyRelevantNotifications, errAll := deps.Notification.GetNotifications(conn.ID, false, currentLimitFromPayload, payload.Offset)
 		if errAll != nil {
 			logger.Warnf("HANDLER_NOTIFICATION", "Usuario %d: Error obteniendo notificaciones (incluyendo leídas) para complementar: %v. Se continuará con las %d no leídas obtenidas.", conn.ID, errAll, len(notifications))
 		} else {
@@ -175,13 +207,52 @@ func HandleGetNotifications(conn *customws.Connection[wsmodels.WsUserData], msg
 	return nil
 }
 
+// sendNotificationPage responde a HandleGetNotifications en modo cursor con una página de
+// notificaciones (notification_page). No complementa las no leídas con leídas: mezclar dos
+// listados rompería la continuidad del cursor.
+func sendNotificationPage(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, payload GetNotificationsPayload) error {
+	params, err := pagination.New(*payload.Cursor, payload.Limit)
+	if err != nil {
+		conn.SendErrorNotification(msg.PID, 400, err.Error())
+		return err
+	}
+
+	page, err := deps.Notification.GetNotificationsPage(conn.ID, payload.OnlyUnread, params)
+	if err != nil {
+		logger.Errorf("HANDLER_NOTIFICATION", "Error obteniendo página de notificaciones para user %d: %v", conn.ID, err)
+		conn.SendErrorNotification(msg.PID, 500, "Error al obtener tus notificaciones: "+err.Error())
+		return err
+	}
+
+	responseMsg := types.ServerToClientMessage{
+		PID:     conn.Manager().Callbacks().GeneratePID(),
+		Type:    types.MessageTypeNotificationPage,
+		Payload: page,
+	}
+	if err := conn.SendMessage(responseMsg); err != nil {
+		logger.Errorf("HANDLER_NOTIFICATION", "Error enviando página de notificaciones a user %d: %v", conn.ID, err)
+		return err
+	}
+
+	if msg.PID != "" {
+		ackMsg := types.ServerToClientMessage{
+			PID:     conn.Manager().Callbacks().GeneratePID(),
+			Type:    types.MessageTypeServerAck,
+			Payload: types.AckPayload{AcknowledgedPID: msg.PID, Status: "notification_page_sent"},
+		}
+		if err := conn.SendMessage(ackMsg); err != nil {
+			logger.Warnf("HANDLER_NOTIFICATION", "Error enviando ServerAck para GetNotifications a UserID %d para PID %s: %v", conn.ID, msg.PID, err)
+		}
+	}
+
+	logger.Successf("HANDLER_NOTIFICATION", "Página de notificaciones enviada a user %d. Total: %d, hasMore: %t", conn.ID, len(page.Items), page.HasMore)
+	return nil
+}
+
 // HandleMarkNotificationRead maneja la solicitud para marcar una notificación como leída.
 func HandleMarkNotificationRead(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
 	logger.Infof("HANDLER_NOTIFICATION", "Usuario %d solicitó marcar notificación como leída. PID: %s", conn.ID, msg.PID)
 
-	type MarkReadPayload struct {
-		NotificationID string `json:"notificationId"`
-	}
 	var payload MarkReadPayload
 	if msg.Payload == nil {
 		conn.SendErrorNotification(msg.PID, 400, "Payload es requerido para marcar notificación como leída.")
@@ -203,7 +274,7 @@ func HandleMarkNotificationRead(conn *customws.Connection[wsmodels.WsUserData],
 		return errors.New("notificationId vacío en MarkNotificationRead")
 	}
 
-	if err := services.MarkRead(conn.ID, payload.NotificationID); err != nil {
+	if err := deps.Notification.MarkRead(conn.ID, payload.NotificationID); err != nil {
 		logger.Errorf("HANDLER_NOTIFICATION", "Error marcando notificación %s como leída para user %d: %v", payload.NotificationID, conn.ID, err)
 		conn.SendErrorNotification(msg.PID, 500, "Error al marcar notificación como leída: "+err.Error())
 		return err
@@ -226,6 +297,11 @@ func HandleMarkNotificationRead(conn *customws.Connection[wsmodels.WsUserData],
 		}
 	}
 
+	syncReadToOtherDevices(conn, map[string]interface{}{
+		"resource":       "notification",
+		"notificationId": payload.NotificationID,
+	})
+
 	logger.Successf("HANDLER_NOTIFICATION", "Notificación %s marcada como leída para user %d. PID original: %s", payload.NotificationID, conn.ID, msg.PID)
 	return nil
 }
@@ -234,7 +310,7 @@ func HandleMarkNotificationRead(conn *customws.Connection[wsmodels.WsUserData],
 func HandleMarkAllNotificationsRead(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
 	logger.Infof("HANDLER_NOTIFICATION", "Usuario %d solicitó marcar todas las notificaciones como leídas. PID: %s", conn.ID, msg.PID)
 
-	rowsAffected, err := services.MarkAllRead(conn.ID)
+	rowsAffected, err := deps.Notification.MarkAllRead(conn.ID)
 	if err != nil {
 		logger.Errorf("HANDLER_NOTIFICATION", "Error marcando todas las notificaciones como leídas para user %d: %v", conn.ID, err)
 		conn.SendErrorNotification(msg.PID, 500, "Error al marcar todas las notificaciones como leídas: "+err.Error())
@@ -271,6 +347,96 @@ func HandleMarkAllNotificationsRead(conn *customws.Connection[wsmodels.WsUserDat
 		logger.Warnf("HANDLER_NOTIFICATION", "Error enviando número de notificaciones marcadas a UserID %d: %v", conn.ID, err)
 	}
 
+	syncReadToOtherDevices(conn, map[string]interface{}{
+		"resource": "notification",
+		"all":      true,
+	})
+
 	logger.Successf("HANDLER_NOTIFICATION", "%d notificaciones marcadas como leídas para user %d. PID original: %s", rowsAffected, conn.ID, msg.PID)
 	return nil
 }
+
+// HandleMarkNotificationsRead marca como leídas las notificaciones indicadas.
+func HandleMarkNotificationsRead(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
+	return handleNotificationsBulk(conn, msg, "notifications_marked_as_read", false, true)
+}
+
+// HandleMarkNotificationsUnread marca como no leídas las notificaciones indicadas.
+func HandleMarkNotificationsUnread(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
+	return handleNotificationsBulk(conn, msg, "notifications_marked_as_unread", false, false)
+}
+
+// HandleDeleteNotifications borra las notificaciones indicadas.
+func HandleDeleteNotifications(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
+	return handleNotificationsBulk(conn, msg, "notifications_deleted", true, false)
+}
+
+// handleNotificationsBulk aplica una operación masiva: el borrado si deleted, o el cambio del
+// estado de lectura a read. Confirma con un ack y un data_event con el número de notificaciones
+// afectadas, y avisa a los demás dispositivos del usuario.
+func handleNotificationsBulk(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage, ackStatus string, deleted, read bool) error {
+	var payload NotificationIDsPayload
+	raw, err := json.Marshal(msg.Payload)
+	if err == nil {
+		err = json.Unmarshal(raw, &payload)
+	}
+	if err != nil {
+		logger.Warnf("HANDLER_NOTIFICATION", "Payload inválido de UserID %d, PID %s: %v", conn.ID, msg.PID, err)
+		conn.SendAppError(msg.PID, apperrors.InvalidPayload, "payload inválido")
+		return fmt.Errorf("payload inválido: %w", err)
+	}
+	if len(payload.NotificationIDs) == 0 {
+		conn.SendAppError(msg.PID, apperrors.MissingFields, "notificationIds es requerido")
+		return errors.New("notificationIds vacío")
+	}
+
+	var affected int64
+	if deleted {
+		affected, err = deps.Notification.Delete(conn.ID, payload.NotificationIDs)
+	} else {
+		affected, err = deps.Notification.SetRead(conn.ID, payload.NotificationIDs, read)
+	}
+	if err != nil {
+		appErr := apperrors.From(err, "Error al actualizar las notificaciones")
+		conn.SendAppError(msg.PID, appErr.Code, appErr.Message)
+		return err
+	}
+
+	if msg.PID != "" {
+		conn.SendServerAck(msg.PID, ackStatus, nil)
+	}
+	responseMsg := types.ServerToClientMessage{
+		PID:     conn.Manager().Callbacks().GeneratePID(),
+		Type:    types.MessageTypeDataEvent,
+		Payload: models.NotificationBulkResponse{Affected: affected},
+	}
+	if err := conn.SendMessage(responseMsg); err != nil {
+		logger.Warnf("HANDLER_NOTIFICATION", "Error enviando el resultado de %s a UserID %d: %v", ackStatus, conn.ID, err)
+	}
+
+	if affected > 0 {
+		ids := make([]int64, 0, len(payload.NotificationIDs))
+		for _, raw := range payload.NotificationIDs {
+			if id, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				ids = append(ids, id)
+			}
+		}
+		syncReadToOtherDevices(conn, models.NewNotificationSync(ids, read, deleted))
+	}
+
+	logger.Successf("HANDLER_NOTIFICATION", "%s: %d notificaciones de user %d. PID original: %s", ackStatus, affected, conn.ID, msg.PID)
+	return nil
+}
+
+// syncReadToOtherDevices avisa a los demás dispositivos del usuario de que se marcaron notificaciones como leídas.
+func syncReadToOtherDevices(conn *customws.Connection[wsmodels.WsUserData], payload interface{}) {
+	readSyncMsg := types.ServerToClientMessage{
+		PID:        conn.Manager().Callbacks().GeneratePID(),
+		Type:       types.MessageTypeReadSync,
+		FromUserID: conn.ID,
+		Payload:    payload,
+	}
+	if err := conn.Manager().SendMessageToOtherDevices(conn, readSyncMsg); err != nil {
+		logger.Warnf("HANDLER_NOTIFICATION", "No se pudo sincronizar la lectura con otros dispositivos de UserID %d: %v", conn.ID, err)
+	}
+}
@@ -117,15 +117,23 @@ func HandleUpdateProfile(conn *customws.Connection[wsmodels.WsUserData], msg typ
 	return nil
 }
 
+// ViewProfilePayload es el payload de profile/view. Se identifica el perfil por
+// userId, rif o companyName.
+type ViewProfilePayload struct {
+	UserID      *int64  `json:"userId,omitempty" validate:"min=1"`
+	RIF         *string `json:"rif,omitempty" validate:"max=20"`
+	CompanyName *string `json:"companyName,omitempty" validate:"max=255"`
+}
+
+// GetUserProfilePayload es el payload de get_user_profile.
+type GetUserProfilePayload struct {
+	UserID int64 `json:"userId" validate:"required,min=1"`
+}
+
 // HandleViewProfile maneja la solicitud para ver el perfil de otro usuario.
 func HandleViewProfile(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
 	logger.Infof("PROFILE_HANDLER", "Usuario %d solicitó ver un perfil. PID: %s", conn.ID, msg.PID)
 
-	type ViewProfilePayload struct {
-		UserID      *int64  `json:"userId,omitempty"`
-		RIF         *string `json:"rif,omitempty"`
-		CompanyName *string `json:"companyName,omitempty"`
-	}
 	var payload ViewProfilePayload
 
 	if msg.Payload == nil {
@@ -182,7 +190,7 @@ func HandleViewProfile(conn *customws.Connection[wsmodels.WsUserData], msg types
 
 	var responsePayload interface{}
 
-	if targetRoleID == 3 {
+	if targetRoleID == int(models.RoleBusiness) {
 		// Lógica para perfil de empresa
 		profile, err := services.GetCompleteCompanyProfile(targetUserID)
 		if err != nil {
@@ -214,6 +222,7 @@ func HandleViewProfile(conn *customws.Connection[wsmodels.WsUserData], msg types
 	}
 
 	logger.Successf("PROFILE_HANDLER", "Datos de perfil de %d enviados a user %d. PID respuesta: %s", targetUserID, conn.ID, msg.PID)
+	services.RecordProfileView(conn.ID, conn.UserData.RoleId, targetUserID, conn.Manager())
 	return nil
 }
 
@@ -221,9 +230,6 @@ func HandleViewProfile(conn *customws.Connection[wsmodels.WsUserData], msg types
 func HandleGetUserProfile(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
 	logger.Infof("HANDLER_PROFILE", "Usuario %d solicitó perfil de otro usuario. PID: %s", conn.ID, msg.PID)
 
-	type GetUserProfilePayload struct {
-		UserID int64 `json:"userId"`
-	}
 	var payload GetUserProfilePayload
 
 	if msg.Payload == nil {
@@ -268,6 +274,7 @@ func HandleGetUserProfile(conn *customws.Connection[wsmodels.WsUserData], msg ty
 	}
 
 	logger.Successf("HANDLER_PROFILE", "Datos de perfil de %d enviados a user %d. PID respuesta: %s", payload.UserID, conn.ID, responseMsg.PID)
+	services.RecordProfileView(conn.ID, conn.UserData.RoleId, payload.UserID, conn.Manager())
 	return nil
 }
 
@@ -275,7 +282,7 @@ func HandleMyProfileView(conn *customws.Connection[wsmodels.WsUserData], msg typ
 	logger.Infof("PROFILE_HANDLER", "Usuario %d solicitó ver su propio perfil. PID: %s", conn.ID, msg.PID)
 
 	// Verificar el rol del usuario para determinar qué perfil cargar
-	if conn.UserData.RoleId == 3 {
+	if conn.UserData.RoleId == int(models.RoleBusiness) {
 		// Lógica para perfil de empresa
 		profile, err := services.GetCompleteCompanyProfile(conn.ID)
 		if err != nil {
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	apiservices "github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/services"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+/*
+ * ===================================================
+ * MANEJADOR DE SOLICITUDES WEBSOCKET PARA REPUTACIÓN
+ * ===================================================
+ *
+ * Procesa la acción "create_review" del recurso "reputation". Reutiliza el
+ * ReputationService de la API REST, de modo que la validación de participación
+ * en el evento comunitario es la misma en ambos canales. Si la reseña se crea,
+ * la notificación resultante se envía en tiempo real al usuario calificado.
+ */
+
+const reputationWsComponent = "REPUTATION_HANDLER"
+
+var reputationService apiservices.IReputationService
+
+// InitializeReputationHandler establece el servicio de reputación usado por los handlers WS.
+func InitializeReputationHandler(svc apiservices.IReputationService) {
+	reputationService = svc
+	logger.Info(reputationWsComponent, "ReputationHandler inicializado correctamente.")
+}
+
+// HandleCreateReview procesa la creación de una reseña enviada por WebSocket.
+func HandleCreateReview(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
+	if reputationService == nil {
+		logger.Error(reputationWsComponent, "HandleCreateReview llamado pero ReputationHandler no está inicializado.")
+		conn.SendAppError(msg.PID, apperrors.HandlerUnavailable, "Error interno del servidor: ReputationHandler no inicializado.")
+		return errors.New("ReputationHandler no inicializado")
+	}
+
+	var req models.CreateReviewRequest
+	payloadBytes, err := json.Marshal(msg.Payload)
+	if err == nil {
+		err = json.Unmarshal(payloadBytes, &req)
+	}
+	if err != nil {
+		conn.SendAppError(msg.PID, apperrors.InvalidPayload, "Payload inválido para reputation/create_review.")
+		return fmt.Errorf("payload inválido para create_review: %w", err)
+	}
+
+	event, err := reputationService.CreateReview(conn.ID, req)
+	if err != nil {
+		appErr := apperrors.From(err, "Error al procesar la reseña.")
+		conn.SendAppError(msg.PID, appErr.Code, appErr.Message)
+		return fmt.Errorf("error creando reseña de UserID %d: %w", conn.ID, err)
+	}
+
+	if event != nil {
+		services.SendStoredNotification(*event, conn.Manager())
+	}
+
+	responseMessage := types.ServerToClientMessage{
+		PID:        conn.Manager().Callbacks().GeneratePID(),
+		Type:       types.MessageTypeDataEvent,
+		FromUserID: 0,
+		Payload: map[string]interface{}{
+			"resource":         "reputation",
+			"action":           "create_review",
+			"communityEventId": req.CommunityEventId,
+			"revieweeId":       req.RevieweeID,
+		},
+	}
+	if err := conn.SendMessage(responseMessage); err != nil {
+		logger.Errorf(reputationWsComponent, "Error enviando confirmación de reseña a UserID %d: %v", conn.ID, err)
+		return err
+	}
+
+	logger.Successf(reputationWsComponent, "Reseña creada por UserID %d para UserID %d", conn.ID, req.RevieweeID)
+	return nil
+}
@@ -13,9 +13,9 @@ import (
 
 // SearchRequestPayload define la estructura para el payload de una solicitud de búsqueda.
 type SearchRequestPayload struct {
-	Query  string `json:"query"`
-	Limit  int    `json:"limit"`
-	Offset int    `json:"offset"`
+	Query  string `json:"query" validate:"max=100"`
+	Limit  int    `json:"limit" validate:"min=0,max=100"`
+	Offset int    `json:"offset" validate:"min=0"`
 }
 
 // HandleSearchUsers maneja la búsqueda de usuarios.
@@ -90,7 +90,7 @@ func HandleSearchAll(conn *customws.Connection[wsmodels.WsUserData], msg types.C
 		payload.Offset = 0
 	}
 
-	results, err := searchService.SearchAll(conn.ID, payload.Query, payload.Limit, payload.Offset)
+	results, err := searchService.SearchAll(conn.ID, conn.UserData.TenantID, payload.Query, payload.Limit, payload.Offset)
 	if err != nil {
 		logger.Errorf("SEARCH_HANDLER", "Error en el servicio de búsqueda 'all': %v", err)
 		conn.SendErrorNotification(msg.PID, 500, "Error interno al realizar la búsqueda.")
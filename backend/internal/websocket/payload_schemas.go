@@ -0,0 +1,90 @@
+package websocket
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/protocol"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/validation"
+)
+
+/*
+VALIDACIÓN DE PAYLOADS ENTRANTES
+
+Antes de despachar un mensaje, el router decodifica su payload en el struct registrado
+para ese mensaje y aplica las reglas de la etiqueta `validate` (ver pkg/validation).
+Si falla, el handler no se ejecuta y el cliente recibe un error_notification con
+código 400 y la lista de campos inválidos en error.fields:
+
+	{
+	  "type": "error_notification",
+	  "error": {
+	    "originalPid": "...",
+	    "code": 400,
+	    "message": "Payload inválido para chat/send_message",
+	    "fields": [{"field": "chatId", "rule": "required", "message": "es requerido"}]
+	  }
+	}
+
+La clave del registro es "recurso/acción" para los data_request (se valida el campo
+"data") y el tipo de mensaje para el resto. Los mensajes sin schema registrado se
+despachan sin validar.
+
+Para un nuevo mensaje: definir el struct del payload junto a su handler, con etiquetas
+`json` y `validate`, y añadirlo al registro de internal/websocket/protocol, que también
+genera el cliente TypeScript (ver docs/protocolo_ws.md). RegisterPayloadSchema sirve para
+registrar un schema en tiempo de ejecución.
+*/
+
+const validationComponent = "WS_VALIDATION"
+
+var (
+	payloadSchemasMu sync.RWMutex
+	// payloadSchemas asocia cada mensaje con un constructor de su struct de payload. Se
+	// inicializa con el registro de internal/websocket/protocol.
+	payloadSchemas = protocol.PayloadSchemas()
+)
+
+// RegisterPayloadSchema registra (o reemplaza) el struct de payload de un mensaje.
+// key es "recurso/acción" para data_request o el tipo de mensaje en otro caso.
+func RegisterPayloadSchema(key string, newPayload func() interface{}) {
+	payloadSchemasMu.Lock()
+	defer payloadSchemasMu.Unlock()
+	payloadSchemas[key] = newPayload
+}
+
+// validateIncomingPayload valida el payload contra el schema registrado para key.
+// Si no es válido envía el error al cliente y lo devuelve; el mensaje no debe despacharse.
+func validateIncomingPayload(conn *customws.Connection[wsmodels.WsUserData], pid, key string, payload interface{}) error {
+	payloadSchemasMu.RLock()
+	newPayload, exists := payloadSchemas[key]
+	payloadSchemasMu.RUnlock()
+	if !exists {
+		return nil
+	}
+
+	err := validation.Decode(payload, newPayload())
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs validation.Errors
+	if !errors.As(err, &fieldErrs) {
+		logger.Errorf(validationComponent, "Error validando payload de %s (UserID %d, PID %s): %v", key, conn.ID, pid, err)
+		conn.SendAppError(pid, apperrors.Internal, "Error interno validando el payload.")
+		return err
+	}
+
+	fields := make([]types.FieldError, len(fieldErrs))
+	for i, fe := range fieldErrs {
+		fields[i] = types.FieldError{Field: fe.Field, Rule: fe.Rule, Message: fe.Message}
+	}
+	logger.Warnf(validationComponent, "Payload inválido para %s de UserID %d, PID %s: %v", key, conn.ID, pid, err)
+	conn.SendValidationError(pid, "Payload inválido para "+key, fields)
+	return err
+}
@@ -0,0 +1,156 @@
+package websocket
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/admin"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+/*
+PERMISOS POR ROL DE LOS MENSAJES ENTRANTES
+
+Antes de validar y despachar un mensaje, el router comprueba que el rol de la conexión
+(UserData.RoleId) pueda usarlo. Si no puede, el handler no se ejecuta y el cliente
+recibe un error_notification con código 403:
+
+	{
+	  "type": "error_notification",
+	  "error": {
+	    "originalPid": "...",
+	    "code": 403,
+	    "message": "La acción dashboard/get_info requiere rol de administrador",
+	    "errorCode": "AUTH_006"
+	  }
+	}
+
+errorCode es AUTH_006 cuando solo los administradores pueden usar el mensaje y AUTH_005
+en el resto de casos.
+
+La clave de la matriz es la misma que la de payloadSchemas: "recurso/acción" para los
+data_request y el tipo de mensaje para el resto. "recurso/*" se aplica a todas las
+acciones del recurso que no tengan una regla propia. Los mensajes sin regla los puede
+usar cualquier usuario autenticado.
+
+Para un mensaje restringido a ciertos roles: añadirlo a messagePermissions (o llamar a
+RegisterMessagePermission) junto al registro de su handler. ValidateMessagePermissions,
+que el servidor llama al arrancar, falla si una clave no corresponde a un handler de
+actionHandlers o messageHandlers: una regla con una clave mal escrita no restringiría nada.
+*/
+
+const permissionsComponent = "WS_PERMISSIONS"
+
+var (
+	messagePermissionsMu sync.RWMutex
+	// messagePermissions asocia cada mensaje restringido con los roles que pueden usarlo.
+	messagePermissions = map[string][]models.UserRole{
+		// Panel de administración
+		"dashboard/*": {models.RoleAdmin},
+
+		// Búsqueda de candidatos (empresas). La moderación y las ofertas de empleo solo se
+		// gestionan por la API REST, que aplica sus propios roles.
+		"search/graduates": {models.RoleBusiness, models.RoleAdmin},
+
+		// El CV es de estudiantes y egresados
+		"cv/*": {models.RoleStudent, models.RoleEgresado},
+	}
+)
+
+// RegisterMessagePermission registra (o reemplaza) los roles que pueden usar un mensaje.
+// key es "recurso/acción" o "recurso/*" para data_request y el tipo de mensaje en otro caso.
+// Sin roles, el mensaje queda disponible para cualquier usuario autenticado.
+func RegisterMessagePermission(key string, roles ...models.UserRole) {
+	messagePermissionsMu.Lock()
+	defer messagePermissionsMu.Unlock()
+	if len(roles) == 0 {
+		delete(messagePermissions, key)
+		return
+	}
+	messagePermissions[key] = roles
+}
+
+// ValidateMessagePermissions comprueba que cada clave de messagePermissions corresponda a
+// un mensaje con handler: "recurso/acción" o "recurso/*" de actionHandlers, o un tipo de
+// messageHandlers. Devuelve un error con las claves que no lo hacen.
+func ValidateMessagePermissions() error {
+	messagePermissionsMu.RLock()
+	defer messagePermissionsMu.RUnlock()
+
+	var unknown []string
+	for key := range messagePermissions {
+		if !hasMessageHandler(key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("permisos WebSocket sin handler registrado: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// hasMessageHandler indica si la clave de permisos key corresponde a un handler.
+func hasMessageHandler(key string) bool {
+	resource, action, isAction := strings.Cut(key, "/")
+	if !isAction {
+		_, exists := messageHandlers[types.MessageType(key)]
+		return exists
+	}
+	actions, exists := actionHandlers[resource]
+	if !exists {
+		return false
+	}
+	if action == "*" {
+		return true
+	}
+	_, exists = actions[action]
+	return exists
+}
+
+// allowedRoles devuelve los roles que pueden usar el mensaje key y si hay una regla para él.
+func allowedRoles(key string) ([]models.UserRole, bool) {
+	messagePermissionsMu.RLock()
+	defer messagePermissionsMu.RUnlock()
+	if roles, exists := messagePermissions[key]; exists {
+		return roles, true
+	}
+	if resource, _, isAction := strings.Cut(key, "/"); isAction {
+		roles, exists := messagePermissions[resource+"/*"]
+		return roles, exists
+	}
+	return nil, false
+}
+
+// authorizeMessage comprueba que el rol de la conexión pueda usar el mensaje key.
+// Si no puede envía el error al cliente y lo devuelve; el mensaje no debe despacharse.
+func authorizeMessage(conn *customws.Connection[wsmodels.WsUserData], pid, key string) error {
+	roles, restricted := allowedRoles(key)
+	if !restricted {
+		return nil
+	}
+	role := models.UserRole(conn.UserData.RoleId)
+	for _, allowed := range roles {
+		if role == allowed {
+			return nil
+		}
+	}
+
+	appErr := apperrors.New(apperrors.Forbidden, fmt.Sprintf("Tu rol no tiene permiso para usar %s", key))
+	if len(roles) == 1 && roles[0] == models.RoleAdmin {
+		appErr = apperrors.New(apperrors.AdminRequired, fmt.Sprintf("La acción %s requiere rol de administrador", key))
+	}
+	logger.Warnf(permissionsComponent, "UserID %d (rol %d) sin permiso para %s, PID %s", conn.ID, role, key, pid)
+	if collector := admin.GetCollector(); collector != nil {
+		collector.RecordError(conn.ID, key+"_forbidden", appErr)
+	}
+	conn.SendAppError(pid, appErr.Code, appErr.Message)
+	return appErr
+}
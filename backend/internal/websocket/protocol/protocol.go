@@ -0,0 +1,185 @@
+// Package protocol es la definición del contrato de mensajes del servidor WebSocket: qué
+// mensajes envía el cliente, qué data_request admite y qué mensajes recibe, cada uno con el
+// struct de su payload.
+//
+// Es la única fuente de verdad del protocolo. De ella salen:
+//   - los schemas con los que el router valida los payloads entrantes (PayloadSchemas);
+//   - el JSON Schema del protocolo y el cliente TypeScript del frontend, generados con
+//     `go run ./cmd/devtools ws-protocol` (ver docs/protocolo_ws.md).
+//
+// Al añadir o cambiar un mensaje hay que actualizar este registro y volver a generar los
+// ficheros; `ws-protocol -check` falla si están desactualizados.
+package protocol
+
+import (
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/handlers"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
+)
+
+// Message describe un tipo de mensaje y su payload.
+type Message struct {
+	Type        types.MessageType
+	Description string
+	// Payload devuelve un puntero al struct del payload. nil = el mensaje no lleva payload.
+	Payload func() interface{}
+	// Write indica que el mensaje modifica datos: con la BD en modo solo lectura se rechaza.
+	Write bool
+}
+
+// DataRequest describe una acción de data_request (mensaje de tipo data_request con
+// payload {"resource", "action", "data"}).
+type DataRequest struct {
+	Resource    string
+	Action      string
+	Description string
+	// Data devuelve un puntero al struct del campo data. nil = data no tiene forma fija y no
+	// se valida.
+	Data func() interface{}
+	// Write indica que la acción modifica datos: con la BD en modo solo lectura se rechaza.
+	Write bool
+}
+
+// Key es la clave "recurso/acción" con la que se registra la acción.
+func (d DataRequest) Key() string {
+	return d.Resource + "/" + d.Action
+}
+
+// untyped es el payload de los mensajes que se construyen como mapas en los handlers.
+func untyped() interface{} { return &map[string]interface{}{} }
+
+// ClientMessages son los mensajes que el servidor acepta del cliente, incluidos los que
+// gestiona pkg/customws (auth, subscribe, unsubscribe, chunk, client_ack y heartbeat).
+var ClientMessages = []Message{
+	{Type: types.MessageTypeAuth, Description: "Credenciales de una conexión abierta sin token; debe ser el primer mensaje", Payload: func() interface{} { return &types.AuthPayload{} }},
+	{Type: types.MessageTypeSubscribe, Description: "Suscribe la conexión a uno o varios tópicos", Payload: func() interface{} { return &types.SubscriptionPayload{} }},
+	{Type: types.MessageTypeUnsubscribe, Description: "Cancela la suscripción a uno o varios tópicos", Payload: func() interface{} { return &types.SubscriptionPayload{} }},
+	{Type: types.MessageTypeChunk, Description: "Fragmento de un mensaje mayor que maxMessageSize", Payload: func() interface{} { return &types.ChunkPayload{} }},
+	{Type: types.MessageTypeClientAck, Description: "Confirma la recepción de un mensaje del servidor", Payload: func() interface{} { return &types.AckPayload{} }},
+	{Type: types.MessageTypeHeartbeat, Description: "Hora del cliente; el servidor responde con un heartbeat con el mismo PID", Payload: func() interface{} { return &types.HeartbeatRequestPayload{} }},
+	{Type: types.MessageTypeDataRequest, Description: "Solicitud de un recurso/acción (ver DataRequests)"},
+	{Type: types.MessageTypeGetChatList, Description: "Pide la lista de chats del usuario, o sus cambios con syncToken", Payload: func() interface{} { return &handlers.GetChatListPayload{} }},
+	{Type: types.MessageTypeChatHistory, Description: "Pide el historial de un chat", Payload: func() interface{} { return &handlers.GetChatHistoryPayload{} }},
+	{Type: types.MessageTypeSendChatMessage, Description: "Envía un mensaje de chat", Payload: func() interface{} { return &handlers.SendChatMessagePayload{} }, Write: true},
+	{Type: types.MessageTypeGetNotifications, Description: "Pide las notificaciones del usuario", Payload: func() interface{} { return &handlers.GetNotificationsPayload{} }},
+	{Type: types.MessageTypeMarkNotificationRead, Description: "Marca una notificación como leída", Payload: func() interface{} { return &handlers.MarkReadPayload{} }, Write: true},
+	{Type: types.MessageTypeAcceptFriendRequest, Description: "Acepta una solicitud de contacto", Payload: func() interface{} { return &handlers.FriendRequestPayload{} }, Write: true},
+	{Type: types.MessageTypeRejectFriendRequest, Description: "Rechaza una solicitud de contacto", Payload: func() interface{} { return &handlers.FriendRequestPayload{} }, Write: true},
+	{Type: types.MessageTypeGetMyProfile, Description: "Pide el perfil del usuario conectado"},
+	{Type: types.MessageTypeGetUserProfile, Description: "Pide el perfil de otro usuario", Payload: func() interface{} { return &handlers.GetUserProfilePayload{} }},
+}
+
+// DataRequests son las acciones de data_request que despacha el router genérico.
+var DataRequests = []DataRequest{
+	{Resource: "chat", Action: "get_list", Description: "Lista de chats del usuario, o sus cambios con syncToken", Data: func() interface{} { return &handlers.GetChatListPayload{} }},
+	{Resource: "chat", Action: "get_history", Description: "Historial de un chat", Data: func() interface{} { return &handlers.GetChatHistoryPayload{} }},
+	{Resource: "chat", Action: "send_message", Description: "Envía un mensaje de chat", Data: func() interface{} { return &handlers.SendChatMessagePayload{} }, Write: true},
+	{Resource: "chat", Action: "forward_message", Description: "Reenvía un mensaje a otros chats", Data: func() interface{} { return &handlers.ForwardChatMessagePayload{} }, Write: true},
+	{Resource: "chat", Action: "mark_read", Description: "Marca mensajes de un chat como leídos", Data: func() interface{} { return &handlers.MarkMessageReadPayload{} }, Write: true},
+	{Resource: "notification", Action: "get_list", Description: "Lista de notificaciones", Data: func() interface{} { return &handlers.GetNotificationsPayload{} }},
+	{Resource: "notification", Action: "get_pending", Description: "Notificaciones pendientes de leer", Data: func() interface{} { return &handlers.GetNotificationsPayload{} }},
+	{Resource: "notification", Action: "mark_read", Description: "Marca una notificación como leída", Data: func() interface{} { return &handlers.MarkReadPayload{} }, Write: true},
+	{Resource: "notification", Action: "mark_read_bulk", Description: "Marca varias notificaciones como leídas", Data: func() interface{} { return &handlers.NotificationIDsPayload{} }, Write: true},
+	{Resource: "notification", Action: "mark_unread_bulk", Description: "Marca varias notificaciones como no leídas", Data: func() interface{} { return &handlers.NotificationIDsPayload{} }, Write: true},
+	{Resource: "notification", Action: "delete_bulk", Description: "Borra varias notificaciones", Data: func() interface{} { return &handlers.NotificationIDsPayload{} }, Write: true},
+	{Resource: "dashboard", Action: "get_info", Description: "Datos del panel del usuario"},
+	{Resource: "friend", Action: "accept_request", Description: "Acepta una solicitud de contacto", Data: func() interface{} { return &handlers.FriendRequestPayload{} }, Write: true},
+	{Resource: "friend", Action: "reject_request", Description: "Rechaza una solicitud de contacto", Data: func() interface{} { return &handlers.FriendRequestPayload{} }, Write: true},
+	{Resource: "friend", Action: "contact", Description: "Envía una solicitud de contacto", Data: func() interface{} { return &handlers.ContactRequestPayload{} }, Write: true},
+	{Resource: "feed", Action: "get_list", Description: "Página del feed", Data: func() interface{} { return &handlers.GetFeedListPayload{} }},
+	{Resource: "search", Action: "users", Description: "Busca usuarios", Data: func() interface{} { return &handlers.SearchRequestPayload{} }},
+	{Resource: "search", Action: "companies", Description: "Busca empresas", Data: func() interface{} { return &handlers.SearchRequestPayload{} }},
+	{Resource: "search", Action: "all", Description: "Busca usuarios y empresas", Data: func() interface{} { return &handlers.SearchRequestPayload{} }},
+	{Resource: "search", Action: "graduates", Description: "Busca egresados", Data: func() interface{} { return &handlers.SearchRequestPayload{} }},
+	{Resource: "cv", Action: "get", Description: "CV del usuario"},
+	{Resource: "cv", Action: "set_skill", Description: "Crea o actualiza una habilidad", Data: func() interface{} { return &handlers.SkillPayload{} }, Write: true},
+	{Resource: "cv", Action: "set_language", Description: "Crea o actualiza un idioma", Data: func() interface{} { return &handlers.LanguagePayload{} }, Write: true},
+	{Resource: "cv", Action: "set_work_experience", Description: "Crea o actualiza una experiencia laboral", Data: func() interface{} { return &handlers.WorkExperiencePayload{} }, Write: true},
+	{Resource: "cv", Action: "set_certification", Description: "Crea o actualiza una certificación", Data: func() interface{} { return &handlers.CertificationPayload{} }, Write: true},
+	{Resource: "cv", Action: "set_project", Description: "Crea o actualiza un proyecto", Data: func() interface{} { return &handlers.ProjectPayload{} }, Write: true},
+	{Resource: "cv", Action: "set_education", Description: "Crea o actualiza una formación", Data: func() interface{} { return &handlers.EducationPayload{} }, Write: true},
+	{Resource: "profile", Action: "get", Description: "Perfil del usuario conectado"},
+	{Resource: "profile", Action: "update", Description: "Actualiza el perfil del usuario", Data: func() interface{} { return &models.UpdateProfilePayload{} }, Write: true},
+	{Resource: "profile", Action: "view", Description: "Registra la visita a un perfil", Data: func() interface{} { return &handlers.ViewProfilePayload{} }, Write: true},
+	{Resource: "reputation", Action: "create_review", Description: "Crea una reseña", Data: func() interface{} { return &models.CreateReviewRequest{} }, Write: true},
+}
+
+// ServerMessages son los mensajes que el servidor envía al cliente. Los que se construyen
+// como mapas en los handlers se declaran con payload sin forma fija.
+var ServerMessages = []Message{
+	{Type: types.MessageTypeSessionInfo, Description: "Token de reanudación y resultado de la reanudación, al conectar", Payload: func() interface{} { return &types.SessionInfoPayload{} }},
+	{Type: types.MessageTypeMessageLimits, Description: "Tamaños máximos de los mensajes del cliente, al conectar", Payload: func() interface{} { return &types.MessageLimitsPayload{} }},
+	{Type: types.MessageTypeSubscriptions, Description: "Tópicos vigentes tras subscribe/unsubscribe", Payload: func() interface{} { return &types.SubscriptionsPayload{} }},
+	{Type: types.MessageTypeServerAck, Description: "Confirma la recepción o el procesamiento de un mensaje del cliente", Payload: func() interface{} { return &types.AckPayload{} }},
+	{Type: types.MessageTypeErrorNotification, Description: "Error al procesar un mensaje; el detalle va en el campo error"},
+	{Type: types.MessageTypeIdleWarning, Description: "La conexión se cerrará por inactividad", Payload: func() interface{} { return &types.IdleWarningPayload{} }},
+	{Type: types.MessageTypeHeartbeat, Description: "Hora del servidor y estado de la conexión, periódico o en respuesta a un heartbeat", Payload: func() interface{} { return &types.HeartbeatPayload{} }},
+	{Type: types.MessageTypeMaintenance, Description: "El servidor entra o sale de mantenimiento", Payload: func() interface{} { return &wsmodels.MaintenancePayload{} }},
+	{Type: types.MessageTypeSystemAnnouncement, Description: "Anuncio del panel de administración", Payload: func() interface{} { return &wsmodels.SystemAnnouncementPayload{} }},
+	{Type: types.MessageTypeImpersonation, Description: "La conexión usa un token de suplantación del soporte", Payload: func() interface{} { return &wsmodels.ImpersonationPayload{} }},
+	{Type: types.MessageTypeChatList, Description: "Lista de chats", Payload: func() interface{} { return &[]wsmodels.ChatInfo{} }},
+	{Type: types.MessageTypeChatListDelta, Description: "Chats cambiados y eliminados desde el syncToken de chat/get_list", Payload: func() interface{} { return &wsmodels.ChatListDelta{} }},
+	{Type: types.MessageTypeChatHistory, Description: "Historial de un chat", Payload: func() interface{} { return &[]wsmodels.MessageDB{} }},
+	{Type: types.MessageTypeChatHistoryPage, Description: "Página del historial de un chat pedida con cursor", Payload: func() interface{} { return &pagination.Page[wsmodels.MessageDB]{} }},
+	{Type: types.MessageTypeNewChatMessage, Description: "Mensaje de chat nuevo", Payload: func() interface{} { return &wsmodels.MessageDB{} }},
+	{Type: "message_status_update", Description: "Resultado del envío de un mensaje de chat (originalPID y message)", Payload: untyped},
+	{Type: types.MessageTypeReadSync, Description: "Otro dispositivo del usuario marcó mensajes o notificaciones como leídos, o cambió o borró notificaciones", Payload: untyped},
+	{Type: types.MessageTypePresenceEvent, Description: "Cambio de presencia de un contacto", Payload: untyped},
+	{Type: types.MessageTypeNotificationList, Description: "Lista de notificaciones", Payload: func() interface{} { return &[]wsmodels.NotificationInfo{} }},
+	{Type: types.MessageTypeNotificationPage, Description: "Página de notificaciones pedida con cursor", Payload: func() interface{} { return &pagination.Page[wsmodels.NotificationInfo]{} }},
+	{Type: types.MessageTypeNewNotification, Description: "Notificación nueva", Payload: func() interface{} { return &wsmodels.NotificationInfo{} }},
+	{Type: types.MessageTypeDndSummary, Description: "Resumen de lo recibido durante el horario de No molestar", Payload: func() interface{} { return &models.DndSummary{} }},
+	{Type: types.MessageTypeMyProfileData, Description: "Perfil del usuario conectado", Payload: func() interface{} { return &wsmodels.ProfileData{} }},
+	{Type: types.MessageTypeUserProfileData, Description: "Perfil de otro usuario", Payload: func() interface{} { return &wsmodels.ProfileData{} }},
+	{Type: types.MessageTypeProfileViewed, Description: "Una empresa visitó el perfil del usuario", Payload: func() interface{} { return &models.ProfileViewedEvent{} }},
+	{Type: types.MessageTypeNewComment, Description: "Comentario nuevo en el tópico event:{id}:comments", Payload: func() interface{} { return &models.Comment{} }},
+	{Type: types.MessageTypeDataEvent, Description: "Respuesta de un data_request (feed, dashboard, CV, búsquedas...)", Payload: untyped},
+	{Type: types.MessageTypeAdminMetrics, Description: "Métricas del servidor en el tópico admin:metrics", Payload: untyped},
+}
+
+// PayloadSchemas devuelve los constructores de payload con los que se validan los mensajes
+// entrantes, por clave: "recurso/acción" para los data_request y el tipo de mensaje para el
+// resto. Los mensajes sin payload y los que gestiona pkg/customws no se incluyen.
+func PayloadSchemas() map[string]func() interface{} {
+	schemas := make(map[string]func() interface{})
+	for _, dr := range DataRequests {
+		if dr.Data != nil {
+			schemas[dr.Key()] = dr.Data
+		}
+	}
+	for _, msg := range ClientMessages {
+		if msg.Payload != nil && !handledByCustomWS[msg.Type] {
+			schemas[string(msg.Type)] = msg.Payload
+		}
+	}
+	return schemas
+}
+
+// WriteKeys devuelve las claves ("recurso/acción" o tipo de mensaje, como en PayloadSchemas)
+// de los mensajes que modifican datos.
+func WriteKeys() map[string]bool {
+	keys := make(map[string]bool)
+	for _, dr := range DataRequests {
+		if dr.Write {
+			keys[dr.Key()] = true
+		}
+	}
+	for _, msg := range ClientMessages {
+		if msg.Write {
+			keys[string(msg.Type)] = true
+		}
+	}
+	return keys
+}
+
+// handledByCustomWS son los mensajes del cliente que pkg/customws procesa antes del router.
+var handledByCustomWS = map[types.MessageType]bool{
+	types.MessageTypeAuth:        true,
+	types.MessageTypeSubscribe:   true,
+	types.MessageTypeUnsubscribe: true,
+	types.MessageTypeChunk:       true,
+	types.MessageTypeClientAck:   true,
+	types.MessageTypeHeartbeat:   true,
+}
@@ -0,0 +1,29 @@
+package websocket
+
+import (
+	"github.com/davidM20/micro-service-backend-go.git/internal/db"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/admin"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/protocol"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// writeKeys son los mensajes que modifican datos, marcados con Write en el registro de
+// internal/websocket/protocol.
+var writeKeys = protocol.WriteKeys()
+
+// rejectReadOnlyWrite rechaza con GEN_011 los mensajes que modifican datos mientras la BD está
+// en modo solo lectura (ver docs/modo_solo_lectura.md). Si lo rechaza envía el error al
+// cliente y lo devuelve; el mensaje no debe despacharse. Las lecturas siguen atendiéndose.
+func rejectReadOnlyWrite(conn *customws.Connection[wsmodels.WsUserData], pid, key string) error {
+	if !writeKeys[key] || !db.ReadOnly() {
+		return nil
+	}
+	logger.Warnf("ROUTER", "%s de UserID %d rechazado por el modo solo lectura, PID %s", key, conn.ID, pid)
+	if collector := admin.GetCollector(); collector != nil {
+		collector.RecordError(conn.ID, key+"_read_only", db.ErrReadOnly)
+	}
+	conn.SendAppError(pid, db.ErrReadOnly.Code, db.ErrReadOnly.Message)
+	return db.ErrReadOnly
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/admin"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/handlers"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
@@ -16,10 +17,30 @@ import (
 func handleUnsupportedResource(conn *customws.Connection[wsmodels.WsUserData], pid, action, resource string) error {
 	errMsg := fmt.Sprintf("Recurso '%s' no soportado para la acción '%s'", resource, action)
 	logger.Warn("ROUTER_DATA", errMsg)
-	conn.SendErrorNotification(pid, 400, errMsg)
+	conn.SendAppError(pid, apperrors.UnsupportedResource, errMsg)
 	return errors.New(errMsg)
 }
 
+// messageHandlers mapea los tipos de mensaje distintos de data_request a sus handlers.
+var messageHandlers = map[types.MessageType]func(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error{
+	// --- Chat ---
+	types.MessageTypeGetChatList:     handlers.HandleGetChatList,
+	types.MessageTypeChatHistory:     handlers.HandleGetChatHistory,
+	types.MessageTypeSendChatMessage: handlers.HandleSendChatMessage,
+
+	// --- Notificaciones ---
+	types.MessageTypeGetNotifications:     handlers.HandleGetNotifications,
+	types.MessageTypeMarkNotificationRead: handlers.HandleMarkNotificationRead,
+
+	// --- Contactos ---
+	types.MessageTypeAcceptFriendRequest: handlers.HandleAcceptFriendRequest,
+	types.MessageTypeRejectFriendRequest: handlers.HandleRejectFriendRequest,
+
+	// --- Perfil ---
+	types.MessageTypeGetMyProfile:   handlers.HandleGetProfile,
+	types.MessageTypeGetUserProfile: handlers.HandleGetUserProfile,
+}
+
 // ProcessClientMessage enruta los mensajes del cliente a los handlers apropiados
 func ProcessClientMessage(conn *customws.Connection[wsmodels.WsUserData], msg types.ClientToServerMessage) error {
 	logger.Debugf("ROUTER", "Mensaje recibido de UserID %d: Tipo '%s', PID '%s'",
@@ -28,51 +49,41 @@ func ProcessClientMessage(conn *customws.Connection[wsmodels.WsUserData], msg ty
 	// Registrar métricas
 	collector := admin.GetCollector()
 	if collector != nil {
-		collector.RecordMessage(string(msg.Type))
+		collector.RecordMessage(conn.ID, string(msg.Type))
 	}
 
 	var err error
 
-	switch msg.Type {
-	// --- Solicitud de datos genérica ---
-	case types.MessageTypeDataRequest:
-		err = HandleDataRequest(conn, msg)
-
-	// --- Chat ---
-	case types.MessageTypeGetChatList:
-		err = handlers.HandleGetChatList(conn, msg)
-	case types.MessageTypeChatHistory:
-		err = handlers.HandleGetChatHistory(conn, msg)
-	case types.MessageTypeSendChatMessage:
-		err = handlers.HandleSendChatMessage(conn, msg)
-
-	// --- Notificaciones ---
-	case types.MessageTypeGetNotifications:
-		err = handlers.HandleGetNotifications(conn, msg)
-	case types.MessageTypeMarkNotificationRead:
-		err = handlers.HandleMarkNotificationRead(conn, msg)
-
-	// --- Contactos ---
-	case types.MessageTypeAcceptFriendRequest:
-		err = handlers.HandleAcceptFriendRequest(conn, msg)
-	case types.MessageTypeRejectFriendRequest:
-		err = handlers.HandleRejectFriendRequest(conn, msg)
-
-	// --- Perfil ---
-	case types.MessageTypeGetMyProfile:
-		err = handlers.HandleGetProfile(conn, msg)
-	case types.MessageTypeGetUserProfile:
-		err = handlers.HandleGetUserProfile(conn, msg)
+	// data_request comprueba permisos y valida su payload en HandleDataRequest, por recurso y acción.
+	if msg.Type != types.MessageTypeDataRequest {
+		if err = authorizeMessage(conn, msg.PID, string(msg.Type)); err != nil {
+			return err
+		}
+		if err = rejectReadOnlyWrite(conn, msg.PID, string(msg.Type)); err != nil {
+			return err
+		}
+		if err = validateIncomingPayload(conn, msg.PID, string(msg.Type), msg.Payload); err != nil {
+			if collector != nil {
+				collector.RecordError(conn.ID, string(msg.Type)+"_invalid_payload", err)
+			}
+			return err
+		}
+	}
 
-	default:
+	if msg.Type == types.MessageTypeDataRequest {
+		err = HandleDataRequest(conn, msg)
+	} else if handler, exists := messageHandlers[msg.Type]; exists {
+		err = handler(conn, msg)
+	} else {
 		warnMsg := fmt.Sprintf("Tipo de mensaje no soportado: '%s'", msg.Type)
 		logger.Warn("ROUTER", warnMsg)
+		conn.SendAppError(msg.PID, apperrors.UnsupportedMessage, warnMsg)
 		err = errors.New(warnMsg)
 	}
 
 	// Registrar error si ocurrió
 	if err != nil && collector != nil {
-		collector.RecordError(string(msg.Type) + "_error")
+		collector.RecordError(conn.ID, string(msg.Type)+"_error", err)
 	}
 
 	return err
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const announcementServiceComponent = "SERVICE_ANNOUNCEMENT"
+
+// maxAnnouncementTitleLength es la longitud máxima del título (SystemAnnouncement.Title).
+const maxAnnouncementTitleLength = 255
+
+// ErrInvalidAnnouncement indica que los datos del anuncio no son válidos.
+var ErrInvalidAnnouncement = errors.New("anuncio inválido")
+
+// AnnouncementService envía los anuncios del sistema. Al llegar la hora de un anuncio lo
+// difunde a los usuarios conectados de su segmento (todos o un rol) y guarda un Event
+// SYSTEM_ANNOUNCEMENT para el resto, que lo verán en su lista de notificaciones hasta que
+// expire. Run debe registrarse como job periódico para los anuncios programados y las
+// expiraciones; los anuncios sin hora de envío salen al crearse.
+type AnnouncementService struct {
+	manager *customws.ConnectionManager[wsmodels.WsUserData]
+}
+
+// NewAnnouncementService crea el servicio sobre el ConnectionManager del servidor.
+func NewAnnouncementService(manager *customws.ConnectionManager[wsmodels.WsUserData]) *AnnouncementService {
+	return &AnnouncementService{manager: manager}
+}
+
+// Create valida y guarda un anuncio. Si no tiene ScheduledAt o ya pasó, lo envía en el momento.
+func (s *AnnouncementService) Create(req models.CreateAnnouncementRequest, createdBy string) (models.SystemAnnouncement, error) {
+	now := time.Now()
+	a := models.SystemAnnouncement{
+		Title:        strings.TrimSpace(req.Title),
+		Message:      strings.TrimSpace(req.Message),
+		Level:        strings.ToUpper(strings.TrimSpace(req.Level)),
+		TargetRoleId: req.TargetRoleId,
+		ScheduledAt:  now,
+		ExpiresAt:    req.ExpiresAt,
+		CreatedBy:    createdBy,
+	}
+	if req.ScheduledAt != nil {
+		a.ScheduledAt = *req.ScheduledAt
+	}
+	if a.Level == "" {
+		a.Level = models.AnnouncementLevelInfo
+	}
+	if err := validateAnnouncement(a, now); err != nil {
+		return a, err
+	}
+
+	if err := queries.CreateAnnouncement(&a); err != nil {
+		return a, err
+	}
+	logger.Infof(announcementServiceComponent, "Anuncio %d creado por %s para %s (envío %s)",
+		a.Id, createdBy, announcementAudience(a), a.ScheduledAt.Format(time.RFC3339))
+
+	if !a.ScheduledAt.After(now) {
+		if err := s.dispatch(a); err != nil {
+			return a, err
+		}
+		return queries.GetAnnouncement(a.Id)
+	}
+	return a, nil
+}
+
+// Cancel cancela un anuncio que aún no se ha enviado.
+func (s *AnnouncementService) Cancel(id int64) error {
+	if err := queries.CancelAnnouncement(id); err != nil {
+		return err
+	}
+	logger.Infof(announcementServiceComponent, "Anuncio %d cancelado", id)
+	return nil
+}
+
+// List devuelve los últimos anuncios.
+func (s *AnnouncementService) List(limit int) ([]models.SystemAnnouncement, error) {
+	return queries.ListAnnouncements(limit)
+}
+
+// Run expira los anuncios vencidos y envía los programados cuya hora ya llegó.
+func (s *AnnouncementService) Run(ctx context.Context) error {
+	now := time.Now()
+	expired, err := queries.ExpireAnnouncements(now)
+	if err != nil {
+		return err
+	}
+	if len(expired) > 0 {
+		logger.Infof(announcementServiceComponent, "Anuncios expirados: %v", expired)
+	}
+
+	due, err := queries.GetDueAnnouncements(now)
+	if err != nil {
+		return err
+	}
+	for _, a := range due {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.dispatch(a); err != nil {
+			logger.Errorf(announcementServiceComponent, "Error enviando el anuncio %d: %v", a.Id, err)
+		}
+	}
+	return nil
+}
+
+// dispatch envía el anuncio a los conectados de su segmento y guarda las notificaciones de los
+// demás. Si otra ejecución ya lo tomó o se canceló entretanto, no hace nada.
+func (s *AnnouncementService) dispatch(a models.SystemAnnouncement) error {
+	sentAt := time.Now()
+	claimed, err := queries.ClaimAnnouncement(a.Id, sentAt)
+	if err != nil || !claimed {
+		return err
+	}
+
+	msg := types.ServerToClientMessage{
+		PID:  s.manager.Callbacks().GeneratePID(),
+		Type: types.MessageTypeSystemAnnouncement,
+		Payload: wsmodels.SystemAnnouncementPayload{
+			ID:        a.Id,
+			Title:     a.Title,
+			Message:   a.Message,
+			Level:     a.Level,
+			SentAt:    sentAt,
+			ExpiresAt: a.ExpiresAt,
+		},
+	}
+
+	var recipients []int64
+	var sendErrors map[int64]error
+	if a.TargetRoleId == nil {
+		recipients = s.manager.OnlineUserIDs()
+		sendErrors = s.manager.BroadcastToAll(msg)
+	} else {
+		recipients = s.onlineUsersWithRole(*a.TargetRoleId)
+		sendErrors = s.manager.BroadcastToUsers(recipients, msg)
+	}
+	delivered := make([]int64, 0, len(recipients))
+	for _, userID := range recipients {
+		if _, failed := sendErrors[userID]; !failed {
+			delivered = append(delivered, userID)
+		}
+	}
+
+	metadata, err := json.Marshal(models.EventMetadata{AnnouncementId: a.Id, AnnouncementLevel: a.Level})
+	if err != nil {
+		return fmt.Errorf("error serializando los metadatos del anuncio %d: %w", a.Id, err)
+	}
+	stored, err := queries.StoreAnnouncementEvents(a, metadata, delivered)
+	if err != nil {
+		return err
+	}
+	logger.Infof(announcementServiceComponent, "Anuncio %d enviado a %s: %d usuarios conectados, %d notificaciones guardadas",
+		a.Id, announcementAudience(a), len(delivered), stored)
+	return nil
+}
+
+// onlineUsersWithRole devuelve los usuarios conectados a esta instancia con el rol indicado.
+func (s *AnnouncementService) onlineUsersWithRole(roleID int) []int64 {
+	var userIDs []int64
+	for _, userID := range s.manager.OnlineUserIDs() {
+		conns, found := s.manager.GetConnections(userID)
+		if found && len(conns) > 0 && conns[0].UserData.RoleId == roleID {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	return userIDs
+}
+
+func validateAnnouncement(a models.SystemAnnouncement, now time.Time) error {
+	switch {
+	case a.Title == "" || a.Message == "":
+		return fmt.Errorf("%w: title y message son obligatorios", ErrInvalidAnnouncement)
+	case len(a.Title) > maxAnnouncementTitleLength:
+		return fmt.Errorf("%w: title no puede superar %d caracteres", ErrInvalidAnnouncement, maxAnnouncementTitleLength)
+	case a.ExpiresAt != nil && !a.ExpiresAt.After(a.ScheduledAt):
+		return fmt.Errorf("%w: expiresAt debe ser posterior a scheduledAt", ErrInvalidAnnouncement)
+	case a.ExpiresAt != nil && !a.ExpiresAt.After(now):
+		return fmt.Errorf("%w: expiresAt ya pasó", ErrInvalidAnnouncement)
+	}
+	switch a.Level {
+	case models.AnnouncementLevelInfo, models.AnnouncementLevelWarning, models.AnnouncementLevelCritical:
+	default:
+		return fmt.Errorf("%w: level debe ser %s, %s o %s", ErrInvalidAnnouncement,
+			models.AnnouncementLevelInfo, models.AnnouncementLevelWarning, models.AnnouncementLevelCritical)
+	}
+	if a.TargetRoleId != nil {
+		switch models.UserRole(*a.TargetRoleId) {
+		case models.RoleStudent, models.RoleEgresado, models.RoleBusiness, models.RoleGuest, models.RoleAdmin:
+		default:
+			return fmt.Errorf("%w: targetRoleId %d no es un rol válido", ErrInvalidAnnouncement, *a.TargetRoleId)
+		}
+	}
+	return nil
+}
+
+func announcementAudience(a models.SystemAnnouncement) string {
+	if a.TargetRoleId == nil {
+		return "todos los usuarios"
+	}
+	return fmt.Sprintf("el rol %d", *a.TargetRoleId)
+}
@@ -8,18 +8,77 @@ import (
 	"time"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries" // Alias para el paquete que contiene ChatInfo
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/contentfilter"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
 	customwsTypes "github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
+	"github.com/go-sql-driver/mysql"
 )
 
 var chatDB *sql.DB // Renombrado para evitar colisión si otros servicios usan 'db'
 
-// InitializeChatService permite inyectar la dependencia de la base de datos.
+// ErrChatFrozen se devuelve al escribir en un chat privado entre dos usuarios con un bloqueo.
+var ErrChatFrozen = apperrors.New(apperrors.ChatFrozen, "el chat está congelado porque hay un bloqueo entre los participantes")
+
+// ErrDuplicateMessage acompaña al mensaje que devuelve ProcessAndSaveChatMessage cuando el
+// remitente ya envió otro con el mismo clientMessageId: es el original, que no se vuelve a
+// guardar ni a entregar.
+var ErrDuplicateMessage = errors.New("mensaje ya guardado con el mismo clientMessageId")
+
+// IChatService define las operaciones de chat que usan los handlers WebSocket, para poder
+// sustituirlas por un mock en las pruebas.
+type IChatService interface {
+	GetChatListForUser(userID int64, manager *customws.ConnectionManager[wsmodels.WsUserData]) ([]wsmodels.ChatInfo, error)
+	GetChatListDelta(userID int64, syncToken string, manager *customws.ConnectionManager[wsmodels.WsUserData]) (wsmodels.ChatListDelta, error)
+	ProcessAndSaveChatMessage(userID int64, payload map[string]interface{}, messageID string, manager *customws.ConnectionManager[wsmodels.WsUserData]) (*wsmodels.MessageDB, error)
+	GetChatHistory(chatID string, userID int64, limit int, beforeMessageID string, manager *customws.ConnectionManager[wsmodels.WsUserData]) ([]wsmodels.MessageDB, error)
+	GetChatHistoryPage(chatID string, userID int64, page pagination.Params) (pagination.Page[wsmodels.MessageDB], error)
+	MarkMessageAsRead(userID int64, messageID string, manager *customws.ConnectionManager[wsmodels.WsUserData]) (int64, error)
+}
+
+// ChatService implementa IChatService con las funciones del paquete, que usan la conexión de
+// InitializeChatService.
+type ChatService struct{}
+
+// NewChatService crea una nueva instancia de ChatService
+func NewChatService() *ChatService {
+	return &ChatService{}
+}
+
+func (ChatService) GetChatListForUser(userID int64, manager *customws.ConnectionManager[wsmodels.WsUserData]) ([]wsmodels.ChatInfo, error) {
+	return GetChatListForUser(userID, manager)
+}
+
+func (ChatService) GetChatListDelta(userID int64, syncToken string, manager *customws.ConnectionManager[wsmodels.WsUserData]) (wsmodels.ChatListDelta, error) {
+	return GetChatListDelta(userID, syncToken, manager)
+}
+
+func (ChatService) ProcessAndSaveChatMessage(userID int64, payload map[string]interface{}, messageID string, manager *customws.ConnectionManager[wsmodels.WsUserData]) (*wsmodels.MessageDB, error) {
+	return ProcessAndSaveChatMessage(userID, payload, messageID, manager)
+}
+
+func (ChatService) GetChatHistory(chatID string, userID int64, limit int, beforeMessageID string, manager *customws.ConnectionManager[wsmodels.WsUserData]) ([]wsmodels.MessageDB, error) {
+	return GetChatHistory(chatID, userID, limit, beforeMessageID, manager)
+}
+
+func (ChatService) GetChatHistoryPage(chatID string, userID int64, page pagination.Params) (pagination.Page[wsmodels.MessageDB], error) {
+	return GetChatHistoryPage(chatID, userID, page)
+}
+
+func (ChatService) MarkMessageAsRead(userID int64, messageID string, manager *customws.ConnectionManager[wsmodels.WsUserData]) (int64, error) {
+	return MarkMessageAsRead(userID, messageID, manager)
+}
+
+// InitializeChatService permite inyectar la dependencia de la base de datos y la retención de
+// los cambios de la lista de chats (CHAT_SYNC_RETENTION).
 // Esta función debería ser llamada desde main.go después de conectar a la BD.
-func InitializeChatService(database *sql.DB) {
+func InitializeChatService(database *sql.DB, syncRetention time.Duration) {
 	chatDB = database
+	chatSyncRetention = syncRetention
 	logger.Info("SERVICE_CHAT", "ChatService inicializado con conexión a BD.")
 }
 
@@ -39,14 +98,36 @@ func GetChatListForUser(userID int64, manager *customws.ConnectionManager[wsmode
 		return nil, fmt.Errorf("error obteniendo lista de chats: %w", err)
 	}
 
+	// Los chats con usuarios bloqueados (en cualquier sentido) se muestran congelados y sin
+	// estado de conexión.
+	blocked, err := queries.GetBlockRelations(userID)
+	if err != nil {
+		logger.Errorf("SERVICE_CHAT", "Error obteniendo los bloqueos de UserID %d: %v", userID, err)
+		return nil, fmt.Errorf("error obteniendo lista de chats: %w", err)
+	}
+
+	// Última conexión de los contactos desconectados. Todos son contactos aceptados, así que se
+	// muestra salvo que la oculten a todos.
+	var offlineIDs []int64
+	for _, r := range results {
+		if !blocked[r.OtherUserID] && !manager.IsUserOnline(r.OtherUserID) {
+			offlineIDs = append(offlineIDs, r.OtherUserID)
+		}
+	}
+	lastSeen, err := queries.GetLastSeenByIDs(offlineIDs)
+	if err != nil {
+		logger.Warnf("SERVICE_CHAT", "Error obteniendo la última conexión de los contactos de UserID %d: %v", userID, err)
+	}
+
 	var chatList []wsmodels.ChatInfo
 	for _, r := range results {
-		isOnline := manager.IsUserOnline(r.OtherUserID)
+		isBlocked := blocked[r.OtherUserID]
+		isOnline := !isBlocked && manager.IsUserOnline(r.OtherUserID)
 
 		chatType := ""
-		if r.OtherUserRoleID == 3 {
+		if r.OtherUserRoleID == int(models.RoleBusiness) {
 			chatType = "company"
-		} else if r.OtherUserRoleID == 2 {
+		} else if r.OtherUserRoleID == int(models.RoleEgresado) {
 			chatType = "graduate" // egresado
 		} else {
 			chatType = "student"
@@ -58,9 +139,13 @@ func GetChatListForUser(userID int64, manager *customws.ConnectionManager[wsmode
 			IsOtherOnline: isOnline,
 			UnreadCount:   r.UnreadCount,
 			Type:          chatType,
+			IsBlocked:     isBlocked,
+		}
+		if seen, ok := lastSeen[r.OtherUserID]; ok && !isBlocked && !isOnline && seen.Visibility.VisibleTo(true) {
+			chatInfo.LastSeen = seen.LastSeenAt.UnixMilli()
 		}
 
-		if r.OtherUserRoleID == 3 {
+		if r.OtherUserRoleID == int(models.RoleBusiness) {
 			// Para empresas, usar CompanyName. Si está vacío, usar UserName como fallback.
 			displayName := r.OtherCompanyName.String
 			if displayName == "" {
@@ -109,13 +194,29 @@ func ProcessAndSaveChatMessage(userID int64, payload map[string]interface{}, mes
 	content, _ := payload["content"].(string)
 	mediaId, _ := payload["mediaId"].(string) // Este es el FileName
 	replyToMessageId, _ := payload["replyToMessageId"].(string)
+	// Marca de reenvío; solo la añade ForwardChatMessage.
+	forwardedFromMessageId, _ := payload["forwardedFromMessageId"].(string)
+	forwardedFromSenderId, _ := payload["forwardedFromSenderId"].(int64)
+	clientMessageId, _ := payload["clientMessageId"].(string)
+
+	// Un reintento del cliente tras perder la confirmación devuelve el mensaje ya guardado.
+	if clientMessageId != "" {
+		original, err := getClientMessage(userID, clientMessageId)
+		if err != nil {
+			return nil, err
+		}
+		if original != nil {
+			logger.Infof("SERVICE_CHAT", "Reintento del mensaje %s (clientMessageId %s) de UserID %d: no se guarda de nuevo", original.Id, clientMessageId, userID)
+			return original, ErrDuplicateMessage
+		}
+	}
 
-	var realMediaId string
+	var realMediaId, mediaType string
 	var err error
 	if mediaId != "" {
 		// Buscar el ID real del multimedia a partir del FileName
-		query := "SELECT Id FROM Multimedia WHERE FileName = ?"
-		err = chatDB.QueryRow(query, mediaId).Scan(&realMediaId)
+		query := "SELECT Id, COALESCE(Type, '') FROM Multimedia WHERE FileName = ?"
+		err = chatDB.QueryRow(query, mediaId).Scan(&realMediaId, &mediaType)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				logger.Warnf("SERVICE_CHAT", "Multimedia con FileName %s no encontrado para UserID %d", mediaId, userID)
@@ -131,9 +232,40 @@ func ProcessAndSaveChatMessage(userID int64, payload map[string]interface{}, mes
 		return nil, errors.New("el mensaje no puede estar vacío, debe contener contenido o media")
 	}
 
+	// Un bloqueo entre los participantes congela el chat privado: se conserva el historial, pero
+	// ninguno de los dos puede escribir. recipientID es el otro participante.
+	var recipientID int64
+	if chatId != "" {
+		user1, user2, err := GetChatParticipants(chatId)
+		if err != nil {
+			return nil, err
+		}
+		recipientID = user1
+		if user1 == userID {
+			recipientID = user2
+		}
+		blocked, err := queries.IsBlockedEither(user1, user2)
+		if err != nil {
+			return nil, fmt.Errorf("error comprobando bloqueos del chat %s: %w", chatId, err)
+		}
+		if blocked {
+			return nil, ErrChatFrozen
+		}
+	}
+
+	// Filtro de contenido: los mensajes bloqueados no se guardan; los marcados y los
+	// ocultados se guardan y quedan en el registro del filtro.
+	verdict := filterChatMessage(userID, content)
+	if verdict.Action == contentfilter.ActionBlock {
+		recordFilterHit(userID, "", chatId, chatIdGroup, content, verdict)
+		return nil, ErrMessageBlocked
+	}
+
 	// Determinar TypeMessageId basado en si hay MediaId o no.
 	var typeMessageID int64 = 1 // Por defecto, texto
-	if realMediaId != "" {
+	if mediaType == models.MultimediaTypeVoiceNote {
+		typeMessageID = models.TypeMessageVoiceNote
+	} else if realMediaId != "" {
 		typeMessageID = 2 // Asumimos 2 para mensajes con media.
 	}
 
@@ -147,10 +279,30 @@ func ProcessAndSaveChatMessage(userID int64, payload map[string]interface{}, mes
 	dbContent := sql.NullString{String: content, Valid: content != ""}
 	dbMediaId := sql.NullString{String: realMediaId, Valid: realMediaId != ""}
 	dbReplyToId := sql.NullString{String: replyToMessageId, Valid: replyToMessageId != ""}
+	dbForwardedFrom := sql.NullString{String: forwardedFromMessageId, Valid: forwardedFromMessageId != ""}
+	dbForwardedSender := sql.NullInt64{Int64: forwardedFromSenderId, Valid: forwardedFromMessageId != ""}
+	dbClientMessageId := sql.NullString{String: clientMessageId, Valid: clientMessageId != ""}
 
-	query := `INSERT INTO Message (Id, ChatId, ChatIdGroup, SenderId, Content, Status, TypeMessageId, MediaId, ReplyToMessageId, SentAt) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO Message (Id, ChatId, ChatIdGroup, SenderId, Content, Status, TypeMessageId, MediaId, ReplyToMessageId,
+		ForwardedFromMessageId, ForwardedFromSenderId, SentAt, ClientMessageId) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	_, err = chatDB.Exec(query, messageID, dbChatId, dbChatIdGroup, userID, dbContent, status, typeMessageID, dbMediaId, dbReplyToId, sentAt)
+	// Los mensajes ocultados por el filtro de contenido no cuentan como no leídos para el destinatario.
+	unreadFor := recipientID
+	if verdict.Action == contentfilter.ActionShadowDelete {
+		unreadFor = 0
+	}
+	err = insertChatMessage(query, realMediaId, chatId, unreadFor, messageID, dbChatId, dbChatIdGroup, userID, dbContent, status, typeMessageID,
+		dbMediaId, dbReplyToId, dbForwardedFrom, dbForwardedSender, sentAt, dbClientMessageId)
+	// Dos reintentos simultáneos pasan los dos la comprobación anterior: el índice único
+	// uq_message_client rechaza el segundo, que devuelve el mensaje del primero.
+	var mysqlErr *mysql.MySQLError
+	if clientMessageId != "" && errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+		original, getErr := getClientMessage(userID, clientMessageId)
+		if getErr == nil && original != nil {
+			logger.Infof("SERVICE_CHAT", "Reintento simultáneo del mensaje %s (clientMessageId %s) de UserID %d: no se guarda de nuevo", original.Id, clientMessageId, userID)
+			return original, ErrDuplicateMessage
+		}
+	}
 	if err != nil {
 		logContext := fmt.Sprintf("UserID %d", userID)
 		if chatId != "" {
@@ -164,6 +316,11 @@ func ProcessAndSaveChatMessage(userID int64, payload map[string]interface{}, mes
 	}
 
 	logger.Infof("SERVICE_CHAT", "Mensaje guardado (ID: %s) de UserID %d", messageID, userID)
+	if verdict.Action != contentfilter.ActionNone {
+		recordFilterHit(userID, messageID, chatId, chatIdGroup, content, verdict)
+	}
+	// El último mensaje y los no leídos de la lista de chats cambian para ambos participantes
+	queries.InvalidateChatCache(chatId)
 
 	// --- Construir el objeto de mensaje para la transmisión y retorno ---
 	var contentPtr, mediaIdPtr, replyToPtr *string
@@ -191,12 +348,25 @@ func ProcessAndSaveChatMessage(userID int64, payload map[string]interface{}, mes
 		ChatIdGroup:      chatIdGroupPtr,
 		SenderId:         userID,
 		Content:          contentPtr,
-		SentAt:           sentAt.Format(time.RFC3339Nano),
+		SentAt:           sentAt.UTC().Format(time.RFC3339Nano),
 		Status:           status,
 		TypeMessageId:    typeMessageID,
 		MediaId:          mediaIdPtr,
 		ReplyToMessageId: replyToPtr,
 	}
+	if dbClientMessageId.Valid {
+		messageToSend.ClientMessageId = &dbClientMessageId.String
+	}
+	if dbForwardedFrom.Valid {
+		messageToSend.ForwardedFromMessageId = &dbForwardedFrom.String
+		messageToSend.ForwardedFromSenderId = &dbForwardedSender.Int64
+	}
+
+	// Borrado silencioso: el remitente recibe la confirmación como si el mensaje se hubiera
+	// enviado, pero no se entrega al resto ni aparece en su historial.
+	if verdict.Action == contentfilter.ActionShadowDelete {
+		return messageToSend, nil
+	}
 
 	// --- Lógica para encontrar destinatario(s) y enviar si están en línea ---
 	if chatId != "" {
@@ -214,7 +384,7 @@ func ProcessAndSaveChatMessage(userID int64, payload map[string]interface{}, mes
 		case contact.User2Id:
 			recipientUserID = contact.User1Id
 		default:
-			logger.Errorf("SERVICE_CHAT", "El remitente del mensaje (UserID %d) no coincide con los participantes del ContactID %s (User1: %d, User2: %d)", userID, contact.ContactId, contact.User1Id, contact.User2Id)
+			logger.Errorf("SERVICE_CHAT", "El remitente del mensaje (UserID %d) no coincide con los participantes del ContactID %d (User1: %d, User2: %d)", userID, contact.ContactId, contact.User1Id, contact.User2Id)
 			return messageToSend, fmt.Errorf("mensaje guardado pero remitente no coincide con participantes del chat")
 		}
 
@@ -233,6 +403,7 @@ func ProcessAndSaveChatMessage(userID int64, payload map[string]interface{}, mes
 			}
 		} else {
 			logger.Infof("SERVICE_CHAT", "Destinatario UserID %d no está en línea, mensaje (ID: %s) guardado pero no enviado inmediatamente.", recipientUserID, messageToSend.Id)
+			go pushChatMessage(recipientUserID, messageToSend)
 		}
 
 	} else if chatIdGroup != "" {
@@ -263,6 +434,8 @@ func ProcessAndSaveChatMessage(userID int64, payload map[string]interface{}, mes
 				} else {
 					logger.Successf("SERVICE_CHAT", "Mensaje de grupo (ID: %s) enviado exitosamente a miembro %d", messageToSend.Id, member.UserID)
 				}
+			} else {
+				go pushChatMessage(member.UserID, messageToSend)
 			}
 		}
 	}
@@ -277,14 +450,7 @@ func GetChatHistory(chatID string, userID int64, limit int, beforeMessageID stri
 
 	logger.Infof("SERVICE_CHAT", "Recuperando historial para ChatID: %s, UserID: %d, Limit: %d, BeforeMessageID: %s", chatID, userID, limit, beforeMessageID)
 
-	// Consulta base
-	query := `
-        SELECT Id, SenderId, Content, SentAt, Status, TypeMessageId, MediaId, ReplyToMessageId, EditedAt, ChatIdGroup
-        FROM Message
-        WHERE ChatId = ?
-    `
-	args := []interface{}{chatID}
-
+	page := pagination.Params{Limit: limit}
 	// Si se requiere paginación con beforeMessageID, se obtiene la fecha e ID del mensaje ancla.
 	if beforeMessageID != "" {
 		var anchorDate time.Time
@@ -301,10 +467,49 @@ func GetChatHistory(chatID string, userID int64, limit int, beforeMessageID stri
 			logger.Errorf("SERVICE_CHAT", "Error obteniendo mensaje ancla %s: %v", beforeMessageID, err)
 			return nil, fmt.Errorf("error con paginación: %w", err)
 		}
+		page.After = &pagination.Cursor{CreatedAt: anchorDate, ID: anchorID}
+	}
+
+	return queryChatHistory(chatID, userID, page.After, limit)
+}
+
+// GetChatHistoryPage recupera una página del historial de un chat por cursor, del mensaje más
+// reciente al más antiguo. El cursor se calcula a partir de SentAt e Id del último mensaje.
+func GetChatHistoryPage(chatID string, userID int64, page pagination.Params) (pagination.Page[wsmodels.MessageDB], error) {
+	if chatDB == nil {
+		return pagination.Page[wsmodels.MessageDB]{}, errors.New("GetChatHistoryPage: chat service no inicializado con conexión a BD")
+	}
+
+	messages, err := queryChatHistory(chatID, userID, page.After, page.FetchLimit())
+	if err != nil {
+		return pagination.Page[wsmodels.MessageDB]{}, err
+	}
+	return pagination.NewPage(messages, page.Limit, func(m wsmodels.MessageDB) pagination.Cursor {
+		sentAt, _ := time.Parse(time.RFC3339Nano, m.SentAt)
+		return pagination.Cursor{CreatedAt: sentAt, ID: m.Id}
+	}), nil
+}
 
-		// Se agrega la condición de paginación.
-		query += " AND (SentAt < ? OR (SentAt = ? AND Id < ?))"
-		args = append(args, anchorDate, anchorDate, anchorID)
+// queryChatHistory lee hasta limit mensajes del chat anteriores a after (todos si es nil),
+// ordenados por SentAt DESC, Id DESC. Los mensajes que el filtro de contenido borró en
+// silencio solo los ve su remitente.
+func queryChatHistory(chatID string, userID int64, after *pagination.Cursor, limit int) ([]wsmodels.MessageDB, error) {
+	// Consulta base
+	query := `
+        SELECT Id, SenderId, Content, SentAt, Status, TypeMessageId, MediaId, ReplyToMessageId, EditedAt, ChatIdGroup,
+               ForwardedFromMessageId, ForwardedFromSenderId,
+               EXISTS (SELECT 1 FROM HiddenContent h WHERE h.TargetType = 'MESSAGE' AND h.TargetId = Message.Id) AS IsHidden
+        FROM Message
+        WHERE ChatId = ?
+          AND (SenderId = ? OR NOT EXISTS (
+              SELECT 1 FROM ContentFilterHit f WHERE f.MessageId = Message.Id AND f.Action = 'BORRADO_SILENCIOSO'))
+    `
+	args := []interface{}{chatID, userID}
+
+	// Condición de paginación: mensajes anteriores al cursor.
+	if cond, condArgs := (pagination.Params{After: after}).Where("SentAt", "Id"); cond != "" {
+		query += " AND " + cond
+		args = append(args, condArgs...)
 	}
 
 	query += " ORDER BY SentAt DESC, Id DESC LIMIT ?"
@@ -320,7 +525,8 @@ func GetChatHistory(chatID string, userID int64, limit int, beforeMessageID stri
 	var messages []wsmodels.MessageDB
 	for rows.Next() {
 		var m wsmodels.MessageDB
-		var content, mediaId, replyToMessageId, chatIdGroup sql.NullString
+		var content, mediaId, replyToMessageId, chatIdGroup, forwardedFrom sql.NullString
+		var forwardedSender sql.NullInt64
 		var editedAt sql.NullTime
 		var sentAt time.Time
 
@@ -335,6 +541,9 @@ func GetChatHistory(chatID string, userID int64, limit int, beforeMessageID stri
 			&replyToMessageId,
 			&editedAt,
 			&chatIdGroup,
+			&forwardedFrom,
+			&forwardedSender,
+			&m.IsHidden,
 		)
 		if err != nil {
 			logger.Errorf("SERVICE_CHAT", "Error escaneando mensaje: %v", err)
@@ -345,10 +554,11 @@ func GetChatHistory(chatID string, userID int64, limit int, beforeMessageID stri
 		m.ChatId = new(string)
 		*m.ChatId = chatID
 
-		if content.Valid {
+		// El contenido de los mensajes ocultos por moderación no se envía.
+		if content.Valid && !m.IsHidden {
 			m.Content = &content.String
 		}
-		if mediaId.Valid {
+		if mediaId.Valid && !m.IsHidden {
 			m.MediaId = &mediaId.String
 		}
 		if replyToMessageId.Valid {
@@ -357,6 +567,10 @@ func GetChatHistory(chatID string, userID int64, limit int, beforeMessageID stri
 		if chatIdGroup.Valid {
 			m.ChatIdGroup = &chatIdGroup.String
 		}
+		if forwardedFrom.Valid {
+			m.ForwardedFromMessageId = &forwardedFrom.String
+			m.ForwardedFromSenderId = &forwardedSender.Int64
+		}
 
 		// Formateo de los timestamps a ISO8601.
 		m.SentAt = sentAt.UTC().Format(time.RFC3339Nano)
@@ -377,6 +591,83 @@ func GetChatHistory(chatID string, userID int64, limit int, beforeMessageID stri
 	return messages, nil
 }
 
+// getClientMessage devuelve el mensaje que senderID envió con clientMessageId, o nil si no hay
+// ninguno.
+func getClientMessage(senderID int64, clientMessageId string) (*wsmodels.MessageDB, error) {
+	var m wsmodels.MessageDB
+	var chatId, chatIdGroup, content, mediaId, replyToMessageId, forwardedFrom sql.NullString
+	var forwardedSender sql.NullInt64
+	var editedAt sql.NullTime
+	var sentAt time.Time
+	err := chatDB.QueryRow(`
+		SELECT Id, ChatId, ChatIdGroup, SenderId, Content, SentAt, Status, TypeMessageId, MediaId, ReplyToMessageId,
+		       EditedAt, ForwardedFromMessageId, ForwardedFromSenderId
+		FROM Message
+		WHERE SenderId = ? AND ClientMessageId = ?`, senderID, clientMessageId,
+	).Scan(&m.Id, &chatId, &chatIdGroup, &m.SenderId, &content, &sentAt, &m.Status, &m.TypeMessageId, &mediaId,
+		&replyToMessageId, &editedAt, &forwardedFrom, &forwardedSender)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Errorf("SERVICE_CHAT", "Error buscando el mensaje con clientMessageId %s de UserID %d: %v", clientMessageId, senderID, err)
+		return nil, fmt.Errorf("error buscando el mensaje con clientMessageId %s: %w", clientMessageId, err)
+	}
+
+	m.ClientMessageId = &clientMessageId
+	m.SentAt = sentAt.UTC().Format(time.RFC3339Nano)
+	if chatId.Valid {
+		m.ChatId = &chatId.String
+	}
+	if chatIdGroup.Valid {
+		m.ChatIdGroup = &chatIdGroup.String
+	}
+	if content.Valid {
+		m.Content = &content.String
+	}
+	if mediaId.Valid {
+		m.MediaId = &mediaId.String
+	}
+	if replyToMessageId.Valid {
+		m.ReplyToMessageId = &replyToMessageId.String
+	}
+	if editedAt.Valid {
+		editedAtStr := editedAt.Time.UTC().Format(time.RFC3339Nano)
+		m.EditedAt = &editedAtStr
+	}
+	if forwardedFrom.Valid {
+		m.ForwardedFromMessageId = &forwardedFrom.String
+		m.ForwardedFromSenderId = &forwardedSender.Int64
+	}
+	return &m, nil
+}
+
+// insertChatMessage guarda un mensaje y, en la misma transacción, suma la referencia en
+// Multimedia.RefCount si adjunta un archivo y el mensaje al contador de no leídos de
+// unreadFor en el chat privado chatID (0 = no cuenta).
+func insertChatMessage(query, mediaID, chatID string, unreadFor int64, args ...interface{}) error {
+	tx, err := chatDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return err
+	}
+	if mediaID != "" {
+		if _, err := tx.Exec(`UPDATE Multimedia SET RefCount = RefCount + 1 WHERE Id = ?`, mediaID); err != nil {
+			return fmt.Errorf("error actualizando las referencias del multimedia %s: %w", mediaID, err)
+		}
+	}
+	if chatID != "" && unreadFor != 0 {
+		if err := queries.IncrementChatUnread(tx, unreadFor, chatID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 // GetChatParticipants recupera los IDs de los dos participantes de un chat.
 // Retorna user1ID, user2ID, error.
 func GetChatParticipants(chatID string) (int64, int64, error) {
@@ -404,8 +695,9 @@ func MarkMessageAsRead(userID int64, messageID string, manager *customws.Connect
 	// 1. Obtener el SenderId del mensaje para saber a quién notificar.
 	var senderID int64
 	var currentStatus string
-	queryGet := `SELECT SenderId, Status FROM Message WHERE Id = ?`
-	err := chatDB.QueryRow(queryGet, messageID).Scan(&senderID, &currentStatus)
+	var chatID sql.NullString
+	queryGet := `SELECT SenderId, Status, ChatId FROM Message WHERE Id = ?`
+	err := chatDB.QueryRow(queryGet, messageID).Scan(&senderID, &currentStatus, &chatID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, fmt.Errorf("mensaje con ID %s no encontrado", messageID)
@@ -419,16 +711,37 @@ func MarkMessageAsRead(userID int64, messageID string, manager *customws.Connect
 		return senderID, nil // El mensaje ya está leído, no hacer nada más.
 	}
 
-	queryUpdate := `UPDATE Message SET Status = 'read' WHERE Id = ?`
-	result, err := chatDB.Exec(queryUpdate, messageID)
+	// El contador de no leídos se descuenta en la misma transacción y solo si esta petición
+	// cambió el estado: si otro dispositivo marcó el mensaje a la vez, solo uno descuenta.
+	tx, err := chatDB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error iniciando la lectura del mensaje %s: %w", messageID, err)
+	}
+	defer tx.Rollback()
+
+	queryUpdate := `UPDATE Message SET Status = 'read' WHERE Id = ? AND Status <> 'read'`
+	result, err := tx.Exec(queryUpdate, messageID)
 	if err != nil {
 		return 0, fmt.Errorf("error actualizando el estado del mensaje %s a 'read': %w", messageID, err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		// Esto podría ocurrir si el mensaje fue eliminado justo después de leerlo.
-		return 0, fmt.Errorf("no se actualizó ninguna fila para el mensaje ID %s (puede que no exista)", messageID)
+		// Otra petición lo marcó como leído después de la consulta anterior, o el mensaje se
+		// eliminó justo después de leerlo.
+		return senderID, nil
+	}
+	if chatID.Valid {
+		if err := queries.DecrementChatUnread(tx, messageID, chatID.String, senderID); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error confirmando la lectura del mensaje %s: %w", messageID, err)
+	}
+	queries.InvalidateChatCache(chatID.String)
+	if err := queries.TouchChatSync(chatID.String); err != nil {
+		logger.Warnf("SERVICE_CHAT", "No se pudo registrar la lectura del chat %s: %v", chatID.String, err)
 	}
 
 	// 3. Devolver el ID del remitente para que el handler pueda notificarle.
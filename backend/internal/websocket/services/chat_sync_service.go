@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// chatSyncOverlap es el margen con el que se repasan los cambios anteriores al syncToken. Cubre
+// los mensajes y cambios que se confirman en la base de datos después de anotar su fecha y la
+// diferencia de reloj entre servidores; el cliente recibe de nuevo algunos chats sin cambios.
+const chatSyncOverlap = 10 * time.Second
+
+// chatSyncRetention es CHAT_SYNC_RETENTION: un syncToken más antiguo recibe la lista completa.
+var chatSyncRetention time.Duration
+
+// ErrChatSyncToken se devuelve cuando el syncToken no lo generó el servidor.
+var ErrChatSyncToken = apperrors.New(apperrors.InvalidPayload, "syncToken inválido")
+
+// chatSyncToken es el contenido del syncToken: el momento de la sincronización. El cliente lo
+// recibe en base64 y lo trata como opaco.
+type chatSyncToken struct {
+	At time.Time `json:"t"`
+}
+
+func encodeChatSyncToken(at time.Time) string {
+	raw, _ := json.Marshal(chatSyncToken{At: at.UTC()})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeChatSyncToken(value string) (time.Time, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return time.Time{}, ErrChatSyncToken
+	}
+	var token chatSyncToken
+	if err := json.Unmarshal(raw, &token); err != nil || token.At.IsZero() {
+		return time.Time{}, ErrChatSyncToken
+	}
+	return token.At, nil
+}
+
+// GetChatListDelta devuelve los chats de userID que cambiaron desde syncToken (último mensaje,
+// mensajes no leídos, bloqueo) y los que salieron de su lista. Con syncToken vacío o más antiguo
+// que CHAT_SYNC_RETENTION devuelve la lista completa con Full.
+func GetChatListDelta(userID int64, syncToken string, manager *customws.ConnectionManager[wsmodels.WsUserData]) (wsmodels.ChatListDelta, error) {
+	var since time.Time
+	if syncToken != "" {
+		var err error
+		if since, err = decodeChatSyncToken(syncToken); err != nil {
+			return wsmodels.ChatListDelta{}, err
+		}
+	}
+
+	// El token nuevo se toma antes de leer la lista: lo que cambie mientras tanto entra en la
+	// siguiente sincronización.
+	now := time.Now()
+	delta := wsmodels.ChatListDelta{
+		Chats:     []wsmodels.ChatInfo{},
+		Removed:   []wsmodels.ChatRemoved{},
+		SyncToken: encodeChatSyncToken(now),
+	}
+
+	chats, err := GetChatListForUser(userID, manager)
+	if err != nil {
+		return wsmodels.ChatListDelta{}, err
+	}
+
+	if syncToken == "" || (chatSyncRetention > 0 && now.Sub(since) > chatSyncRetention) {
+		delta.Full = true
+		delta.Chats = append(delta.Chats, chats...)
+		return delta, nil
+	}
+
+	from := since.Add(-chatSyncOverlap)
+	changes, err := queries.GetChatSyncChanges(userID, from)
+	if err != nil {
+		return wsmodels.ChatListDelta{}, err
+	}
+	changed := make(map[int64]bool, len(changes))
+	for _, change := range changes {
+		if !change.Removed {
+			changed[change.OtherUserID] = true
+		}
+	}
+
+	inList := make(map[int64]bool, len(chats))
+	for _, chat := range chats {
+		inList[chat.OtherUserID] = true
+		if changed[chat.OtherUserID] || chat.LastMessageTs > from.UnixMilli() {
+			delta.Chats = append(delta.Chats, chat)
+		}
+	}
+	for _, change := range changes {
+		if change.Removed && !inList[change.OtherUserID] {
+			delta.Removed = append(delta.Removed, wsmodels.ChatRemoved{ChatID: change.ChatID, OtherUserID: change.OtherUserID})
+		}
+	}
+
+	logger.Infof("SERVICE_CHAT", "Sincronización de chats para UserID %d: %d cambiados y %d eliminados de %d", userID, len(delta.Chats), len(delta.Removed), len(chats))
+	return delta, nil
+}
+
+// CleanupChatSync devuelve la tarea que borra los cambios de la lista de chats más antiguos que
+// retention.
+func CleanupChatSync(retention time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		deleted, err := queries.DeleteOldChatSyncChanges(retention)
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			logger.Infof("SERVICE_CHAT", "Sincronización de chats: %d cambios antiguos borrados", deleted)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// chatUnreadReconcileBatch es el número de contactos que la conciliación recalcula por consulta.
+const chatUnreadReconcileBatch = 500
+
+// ReconcileChatUnread recalcula desde Message los contadores de mensajes no leídos de todos los
+// chats privados, por lotes de contactos. Corrige las diferencias que dejen los fallos entre el
+// cambio de un mensaje y su contador, o las escrituras simultáneas a un recálculo.
+func ReconcileChatUnread(ctx context.Context) error {
+	var after, fixed int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		last, changed, err := queries.ReconcileChatUnread(after, chatUnreadReconcileBatch)
+		if err != nil {
+			return err
+		}
+		if last == 0 {
+			break
+		}
+		fixed += changed
+		after = last
+	}
+	if fixed > 0 {
+		logger.Infof("SERVICE_CHAT", "Conciliación de no leídos: %d filas de contadores actualizadas", fixed)
+		queries.InvalidateAllChatListsCache()
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const commentServiceComponent = "SERVICE_COMMENT"
+
+// CommentPublisher publica los comentarios nuevos de las publicaciones de la comunidad en
+// los tópicos "event:{id}:comments". Los comentarios se crean en la API REST, que no tiene
+// acceso a las conexiones, así que el publisher consulta periódicamente la tabla Comment
+// desde el último ID publicado. Solo se leen los comentarios de publicaciones con
+// suscriptores; los demás se saltan.
+type CommentPublisher struct {
+	manager *customws.ConnectionManager[wsmodels.WsUserData]
+
+	mu          sync.Mutex
+	lastID      int64
+	initialized bool
+}
+
+// NewCommentPublisher crea el publisher. Publish debe registrarse como job periódico.
+func NewCommentPublisher(manager *customws.ConnectionManager[wsmodels.WsUserData]) *CommentPublisher {
+	return &CommentPublisher{manager: manager}
+}
+
+// Publish envía a los suscriptores los comentarios creados desde la última ejecución. La
+// primera ejecución solo toma el último ID como punto de partida.
+func (p *CommentPublisher) Publish(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	maxID, err := queries.GetMaxCommentID()
+	if err != nil {
+		return err
+	}
+	if !p.initialized {
+		p.lastID, p.initialized = maxID, true
+		return nil
+	}
+	if maxID <= p.lastID {
+		return nil
+	}
+
+	var eventIDs []int64
+	for topic := range p.manager.Subscriptions().SubscriberCounts() {
+		if eventID, ok := wsmodels.ParseEventCommentsTopic(topic); ok {
+			eventIDs = append(eventIDs, eventID)
+		}
+	}
+
+	comments, err := queries.GetNewCommentsForEvents(eventIDs, p.lastID, maxID)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		msg := types.ServerToClientMessage{
+			Type:       types.MessageTypeNewComment,
+			FromUserID: comment.Author.Id,
+			Payload:    comment,
+		}
+		delivered := p.manager.Subscriptions().Publish(wsmodels.EventCommentsTopic(comment.CommunityEventId), msg)
+		logger.Debugf(commentServiceComponent, "Comentario %d publicado a %d conexiones", comment.Id, delivered)
+	}
+	p.lastID = maxID
+	return nil
+}
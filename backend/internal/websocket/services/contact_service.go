@@ -9,12 +9,17 @@ import (
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 	"github.com/google/uuid"
 )
 
+// ErrContactBlocked se devuelve al solicitar o aceptar un contacto con un usuario con el que hay
+// un bloqueo.
+var ErrContactBlocked = apperrors.New(apperrors.BlockedInteraction, "no puedes interactuar con este usuario")
+
 // AcceptFriendRequest procesa la aceptación de una solicitud de amistad.
 // Actualiza el estado del contacto a 'accepted' y crea un chat entre los usuarios.
 func AcceptFriendRequest(userID int64, notificationId string, timestamp string, manager *customws.ConnectionManager[wsmodels.WsUserData]) error {
@@ -45,6 +50,14 @@ func AcceptFriendRequest(userID int64, notificationId string, timestamp string,
 	}
 	otherUserId := event.OtherUserId.Int64
 
+	blocked, err := queries.IsBlockedEither(userID, otherUserId)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrContactBlocked
+	}
+
 	// Actualizar el estado del contacto
 	err = queries.UpdateContactStatus(userID, otherUserId, "accepted", timestamp)
 	if err != nil {
@@ -72,8 +85,11 @@ func AcceptFriendRequest(userID int64, notificationId string, timestamp string,
 		},
 	}
 
-	if err := manager.SendMessageToUser(otherUserId, notificationMsg); err != nil {
-		logger.Warnf("SERVICE_CONTACT", "Error enviando notificación de aceptación a user %d: %v", otherUserId, err)
+	if notificationSuppressedByDnd(otherUserId, models.EventTypeRequestResponse) {
+		logger.Infof("SERVICE_CONTACT", "User %d en No molestar: no se envía la notificación de aceptación", otherUserId)
+	} else {
+		// Mensaje crítico: se reintenta hasta recibir el ack y, si no llega, se entrega al reconectar
+		SendCriticalMessage(manager, otherUserId, notificationMsg)
 	}
 
 	logger.Successf("SERVICE_CONTACT", "Solicitud de amistad aceptada exitosamente para user %d", userID)
@@ -128,7 +144,9 @@ func RejectFriendRequest(userID int64, notificationId string, timestamp string,
 		},
 	}
 
-	if err := manager.SendMessageToUser(otherUserId, notificationMsg); err != nil {
+	if notificationSuppressedByDnd(otherUserId, models.EventTypeRequestResponse) {
+		logger.Infof("SERVICE_CONTACT", "User %d en No molestar: no se envía la notificación de rechazo", otherUserId)
+	} else if err := manager.SendMessageToUser(otherUserId, notificationMsg); err != nil {
 		logger.Warnf("SERVICE_CONTACT", "Error enviando notificación de rechazo a user %d: %v", otherUserId, err)
 	}
 
@@ -141,11 +159,19 @@ func RejectFriendRequest(userID int64, notificationId string, timestamp string,
 func CreateContactRequest(senderID, recipientID int64, manager *customws.ConnectionManager[wsmodels.WsUserData]) error {
 	logger.Infof("SERVICE_CONTACT", "User %d iniciando contacto con user %d", senderID, recipientID)
 
+	blocked, err := queries.IsBlockedEither(senderID, recipientID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrContactBlocked
+	}
+
 	// Crear chatID con UUID
 	chatID := uuid.NewString()
 
 	// Crear el contacto con estado 'pending'
-	err := queries.CreateContact(senderID, recipientID, chatID, "pending")
+	err = queries.CreateContact(senderID, recipientID, chatID, "pending")
 	if err != nil {
 		return fmt.Errorf("error creando contacto: %w", err)
 	}
@@ -182,7 +208,9 @@ func CreateContactRequest(senderID, recipientID int64, manager *customws.Connect
 		},
 	}
 
-	if err := manager.SendMessageToUser(recipientID, notificationMsg); err != nil {
+	if notificationSuppressedByDnd(recipientID, models.EventTypeFriendRequest) {
+		logger.Infof("SERVICE_CONTACT", "User %d en No molestar: la solicitud queda en sus notificaciones", recipientID)
+	} else if err := manager.SendMessageToUser(recipientID, notificationMsg); err != nil {
 		logger.Warnf("SERVICE_CONTACT", "Error enviando notificación de solicitud de amistad a user %d: %v", recipientID, err)
 	}
 
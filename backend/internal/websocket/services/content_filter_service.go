@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/contentfilter"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const contentFilterComponent = "SERVICE_CONTENT_FILTER"
+
+// ErrMessageBlocked se devuelve cuando el filtro de contenido bloquea un mensaje.
+var ErrMessageBlocked = apperrors.New(apperrors.MessageBlocked, "el mensaje no se envió porque infringe las normas de la comunidad")
+
+// contentFilter evalúa los mensajes de chat. Es nil hasta InitializeContentFilter, y en ese
+// caso los mensajes no se filtran.
+var contentFilter *contentfilter.Filter
+
+// InitializeContentFilter crea el filtro de contenido con los idiomas y el servicio externo
+// de la configuración y carga las reglas de la BD. Debe llamarse después de queries.InitDB.
+func InitializeContentFilter(cfg *config.Config) {
+	languages := contentfilter.ParseLanguages(cfg.ContentFilterLanguages)
+
+	var checker contentfilter.Checker
+	if cfg.ContentFilterAPIURL != "" {
+		checker = contentfilter.NewHTTPChecker(cfg.ContentFilterAPIURL, cfg.ContentFilterAPIKey, cfg.ContentFilterAPITimeout)
+	}
+
+	contentFilter = contentfilter.New(languages, checker)
+	if err := ReloadContentFilter(context.Background()); err != nil {
+		logger.Errorf(contentFilterComponent, "Error cargando las reglas del filtro de contenido: %v", err)
+	}
+	logger.Infof(contentFilterComponent, "Filtro de contenido inicializado. Idiomas: %v, servicio externo: %t", languages, checker != nil)
+}
+
+// ReloadContentFilter vuelve a leer las reglas y la política de la BD, para aplicar los
+// cambios hechos desde la API REST. Se registra como job periódico.
+func ReloadContentFilter(ctx context.Context) error {
+	if contentFilter == nil {
+		return nil
+	}
+	return queries.LoadContentFilter(contentFilter)
+}
+
+// filterChatMessage evalúa el texto de un mensaje. Los mensajes sin texto o sin filtro
+// inicializado se consideran limpios.
+func filterChatMessage(userID int64, content string) contentfilter.Verdict {
+	if contentFilter == nil || content == "" {
+		return contentfilter.Verdict{}
+	}
+	return contentFilter.Check(context.Background(), userID, content)
+}
+
+// recordFilterHit guarda en el registro del filtro un mensaje marcado, ocultado o bloqueado.
+// messageID vacío indica que el mensaje no se guardó. Un fallo solo se registra en el log.
+func recordFilterHit(userID int64, messageID, chatId, chatIdGroup, content string, verdict contentfilter.Verdict) {
+	matches, err := json.Marshal(verdict.Hits)
+	if err != nil {
+		logger.Errorf(contentFilterComponent, "Error serializando las coincidencias del mensaje de UserID %d: %v", userID, err)
+		return
+	}
+	hit := models.ContentFilterHit{
+		SenderId: userID,
+		Content:  content,
+		Action:   verdict.Action,
+		Severity: verdict.Severity,
+		Hits:     matches,
+	}
+	if messageID != "" {
+		hit.MessageId = &messageID
+	}
+	if chatId != "" {
+		hit.ChatId = &chatId
+	}
+	if chatIdGroup != "" {
+		hit.ChatIdGroup = &chatIdGroup
+	}
+	if err := queries.InsertContentFilterHit(hit); err != nil {
+		logger.Errorf(contentFilterComponent, "%v", err)
+		return
+	}
+	logger.Warnf(contentFilterComponent, "Mensaje de UserID %d filtrado: acción %s, severidad %s", userID, verdict.Action, verdict.Severity)
+}
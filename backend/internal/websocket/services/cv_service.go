@@ -5,21 +5,39 @@ import (
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	apiservices "github.com/davidM20/micro-service-backend-go.git/internal/services"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
 )
 
+// ICVService define las operaciones del CV que usan los handlers WebSocket, para poder
+// sustituirlas por un mock en las pruebas.
+type ICVService interface {
+	SetSkill(skill *models.Skills) error
+	SetLanguage(language *models.Languages) error
+	SetWorkExperience(experience *models.WorkExperience) error
+	SetCertification(certification *models.Certifications) error
+	SetProject(project *models.Project) error
+	SetEducation(education *models.Education) error
+	GetCV(personID int64) (*wsmodels.CurriculumVitae, error)
+}
+
+var _ ICVService = (*CVService)(nil)
+
 // CVService maneja la lógica de negocio relacionada con el CV
 type CVService struct {
-	db *sql.DB
+	db     *sql.DB
+	skills apiservices.ISkillService
 }
 
 // NewCVService crea una nueva instancia de CVService
 func NewCVService(db *sql.DB) *CVService {
-	return &CVService{db: db}
+	return &CVService{db: db, skills: apiservices.NewSkillService(db)}
 }
 
-// SetSkill establece una habilidad en el CV
+// SetSkill establece una habilidad en el CV. El texto se normaliza contra el catálogo de
+// habilidades: si corresponde a una habilidad canónica se guarda con su nombre y su ID.
 func (s *CVService) SetSkill(skill *models.Skills) error {
+	skill.Skill, skill.SkillCatalogId = s.skills.CanonicalizeSkill(skill.Skill)
 	return queries.SetSkill(s.db, skill)
 }
 
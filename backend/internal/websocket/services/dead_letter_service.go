@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const deadLetterServiceComponent = "SERVICE_DEAD_LETTER"
+
+// deadLetterBatchSize es cuántos mensajes no entregados se envían a un usuario al conectarse.
+// Los demás esperan a la siguiente conexión.
+const deadLetterBatchSize = 50
+
+// SendCriticalMessage envía a un usuario un mensaje que no debe perderse, como la aceptación de
+// un contacto. Todas sus conexiones lo reciben con el mismo PID y se acepta el client_ack de
+// cualquiera de ellas (SendForUserClientAck); los reintentos van a la conexión con actividad
+// más reciente. Si el usuario no está conectado o no lo confirma, el mensaje queda en
+// DeadLetter y se le envía al conectarse.
+func SendCriticalMessage(manager *customws.ConnectionManager[wsmodels.WsUserData], userID int64, msg types.ServerToClientMessage) {
+	if msg.PID == "" {
+		msg.PID = manager.Callbacks().GeneratePID()
+	}
+
+	conns, online := manager.GetConnections(userID)
+	if !online {
+		StoreDeadLetter(userID, msg, 0, nil)
+		return
+	}
+
+	// Los reintentos van a la conexión con actividad más reciente; las demás lo reciben una vez
+	primary := conns[0]
+	for _, conn := range conns[1:] {
+		if conn.LastActivity().After(primary.LastActivity()) {
+			primary = conn
+		}
+	}
+	for _, conn := range conns {
+		if conn == primary {
+			continue
+		}
+		if err := conn.SendMessage(msg); err != nil {
+			logger.Warnf(deadLetterServiceComponent, "Error enviando el mensaje %s a otra conexión del usuario %d: %v", msg.PID, userID, err)
+		}
+	}
+
+	go func() {
+		if _, err := manager.SendForUserClientAck(primary, msg); err != nil {
+			logger.Warnf(deadLetterServiceComponent, "Mensaje %s sin confirmar por el usuario %d, queda pendiente: %v", msg.PID, userID, err)
+		}
+	}()
+}
+
+// StoreDeadLetter guarda un mensaje que el usuario no confirmó para enviárselo al conectarse.
+// attempts son los envíos hechos (0 si no estaba conectado) y cause el último error.
+func StoreDeadLetter(userID int64, msg types.ServerToClientMessage, attempts int, cause error) {
+	payload, err := json.Marshal(msg.Payload)
+	if err != nil {
+		logger.Errorf(deadLetterServiceComponent, "No se pudo serializar el mensaje %s del usuario %d: %v", msg.PID, userID, err)
+		return
+	}
+	letter := models.DeadLetter{
+		UserId:     userID,
+		Pid:        msg.PID,
+		Type:       string(msg.Type),
+		FromUserId: msg.FromUserID,
+		Payload:    payload,
+		Attempts:   attempts,
+	}
+	if cause != nil {
+		letter.LastError = cause.Error()
+	}
+	if err := queries.SaveDeadLetter(letter); err != nil {
+		logger.Errorf(deadLetterServiceComponent, "%v", err)
+		return
+	}
+	logger.Infof(deadLetterServiceComponent, "Mensaje %s (%s) guardado para el usuario %d tras %d intentos", msg.PID, msg.Type, userID, attempts)
+}
+
+// DeliverDeadLetters envía en segundo plano a una conexión recién abierta los mensajes que el
+// usuario no confirmó, del más antiguo al más reciente y con su PID original. Cada uno se
+// borra al recibir su ack. Si uno vuelve a quedar sin confirmar, OnDeadLetter suma sus intentos
+// y el resto espera a la siguiente conexión.
+func DeliverDeadLetters(conn *customws.Connection[wsmodels.WsUserData]) {
+	go func() {
+		letters, err := queries.GetDeadLetters(conn.ID, deadLetterBatchSize)
+		if err != nil {
+			logger.Errorf(deadLetterServiceComponent, "%v", err)
+			return
+		}
+		for _, letter := range letters {
+			msg := types.ServerToClientMessage{
+				PID:        letter.Pid,
+				Type:       types.MessageType(letter.Type),
+				FromUserID: letter.FromUserId,
+				Payload:    letter.Payload,
+			}
+			if _, err := conn.Manager().SendForClientAck(conn, msg); err != nil {
+				return
+			}
+			if err := queries.DeleteDeadLetter(letter.Id); err != nil {
+				logger.Errorf(deadLetterServiceComponent, "%v", err)
+			}
+		}
+		if len(letters) > 0 {
+			logger.Infof(deadLetterServiceComponent, "%d mensajes pendientes entregados al usuario %d", len(letters), conn.ID)
+		}
+	}()
+}
+
+// CleanupDeadLetters borra los mensajes no entregados con más de retention.
+func CleanupDeadLetters(retention time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		deleted, err := queries.DeleteOldDeadLetters(retention)
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			logger.Infof(deadLetterServiceComponent, "DeadLetter: %d mensajes antiguos borrados", deleted)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/push"
+)
+
+const dndServiceComponent = "SERVICE_DND"
+
+// dndSummaryBatchSize es el máximo de resúmenes que se envían en cada ejecución del job.
+const dndSummaryBatchSize = 200
+
+// suppressedByDnd indica si el aviso de la categoría debe silenciarse porque el usuario está
+// en su horario de No molestar. Si se silencia, se registra para enviarle el resumen cuando
+// termine. Los avisos de la cuenta (seguridad y moderación) no se silencian.
+func suppressedByDnd(userID int64, prefs models.NotificationPreferences, category string) bool {
+	now := time.Now()
+	if category == models.PushCategoryAccount || !prefs.InDoNotDisturb(now) {
+		return false
+	}
+	if err := queries.MarkDndSuppressed(userID, now); err != nil {
+		logger.Errorf(dndServiceComponent, "%v", err)
+	}
+	return true
+}
+
+// notificationSuppressedByDnd es suppressedByDnd para una notificación en tiempo real: carga
+// las preferencias y usa la categoría push del tipo de notificación. Si no se pueden cargar
+// las preferencias la notificación se envía.
+func notificationSuppressedByDnd(userID int64, eventType string) bool {
+	prefs, err := queries.GetNotificationPreferences(userID)
+	if err != nil {
+		logger.Errorf(dndServiceComponent, "%v", err)
+		return false
+	}
+	return suppressedByDnd(userID, prefs, pushEventCategories[eventType])
+}
+
+// DndSummaryJob envía a cada usuario con avisos silenciados un resumen de lo recibido cuando
+// termina su horario de No molestar: por WebSocket si está conectado y si no por push. Con
+// varias instancias del servidor, la primera que reclama el resumen es la que lo envía.
+type DndSummaryJob struct {
+	manager *customws.ConnectionManager[wsmodels.WsUserData]
+}
+
+// NewDndSummaryJob crea el job. Devuelve nil si los resúmenes están deshabilitados.
+func NewDndSummaryJob(cfg *config.Config, manager *customws.ConnectionManager[wsmodels.WsUserData]) *DndSummaryJob {
+	if cfg.DndSummaryInterval <= 0 {
+		return nil
+	}
+	return &DndSummaryJob{manager: manager}
+}
+
+// Run envía los resúmenes de los horarios de No molestar que ya terminaron. Se registra como
+// job periódico.
+func (j *DndSummaryJob) Run(ctx context.Context) error {
+	pending, err := queries.GetDndPendingSummaries(dndSummaryBatchSize)
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	for _, p := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		prefs, err := queries.GetNotificationPreferences(p.UserId)
+		if err != nil {
+			logger.Errorf(dndServiceComponent, "%v", err)
+			continue
+		}
+		// Sigue en No molestar: el resumen se envía cuando termine
+		if prefs.InDoNotDisturb(time.Now()) {
+			continue
+		}
+		claimed, err := queries.ClaimDndSummary(p.UserId, p.SuppressedAt)
+		if err != nil {
+			logger.Errorf(dndServiceComponent, "%v", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		summary, err := queries.GetDndSummary(p.UserId, p.SuppressedAt)
+		if err != nil {
+			logger.Errorf(dndServiceComponent, "%v", err)
+			continue
+		}
+		// Lo silenciado ya se leyó desde otro dispositivo
+		if summary.Notifications == 0 && summary.Messages == 0 {
+			continue
+		}
+		if j.deliver(p.UserId, prefs, summary) {
+			sent++
+		}
+	}
+	if sent > 0 {
+		logger.Infof(dndServiceComponent, "Resúmenes de No molestar enviados: %d", sent)
+	}
+	return nil
+}
+
+// deliver envía el resumen por WebSocket si el usuario está conectado y si no por push.
+func (j *DndSummaryJob) deliver(userID int64, prefs models.NotificationPreferences, summary models.DndSummary) bool {
+	if j.manager.IsUserOnline(userID) {
+		err := j.manager.SendMessageToUser(userID, types.ServerToClientMessage{
+			PID:     j.manager.Callbacks().GeneratePID(),
+			Type:    types.MessageTypeDndSummary,
+			Payload: summary,
+		})
+		if err != nil {
+			logger.Warnf(dndServiceComponent, "Error enviando el resumen de No molestar a UserID %d: %v", userID, err)
+			return false
+		}
+		return true
+	}
+	if pushDisabled() || !prefs.PushEnabled {
+		return false
+	}
+	sendToDevices(userID, push.Notification{
+		Title: "Mientras tenías No molestar activado",
+		Body:  dndSummaryText(summary),
+		Data:  map[string]string{"type": string(types.MessageTypeDndSummary)},
+	})
+	return true
+}
+
+// dndSummaryText describe el resumen: "Tienes 3 notificaciones y 1 mensaje sin leer".
+func dndSummaryText(summary models.DndSummary) string {
+	var parts []string
+	switch {
+	case summary.Notifications == 1:
+		parts = append(parts, "1 notificación")
+	case summary.Notifications > 1:
+		parts = append(parts, fmt.Sprintf("%d notificaciones", summary.Notifications))
+	}
+	switch {
+	case summary.Messages == 1:
+		parts = append(parts, "1 mensaje")
+	case summary.Messages > 1:
+		parts = append(parts, fmt.Sprintf("%d mensajes", summary.Messages))
+	}
+	return "Tienes " + strings.Join(parts, " y ") + " sin leer"
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const drainServiceComponent = "SERVICE_DRAIN"
+
+// DrainCloseCode es el código de cierre de las conexiones drenadas. El motivo es
+// {"reason":"draining","reconnect":true,"retryAfter":N}: el cliente debe reconectar pasados N
+// segundos, y el balanceador lo lleva a otra instancia.
+const DrainCloseCode = customws.CloseServerShutdown
+
+// errDraining es el error de la comprobación de readiness durante el drenaje.
+var errDraining = errors.New("la instancia está drenando sus conexiones")
+
+// DrainState es el estado del drenaje de la instancia.
+type DrainState struct {
+	Active   bool   `json:"active"`
+	Instance string `json:"instance"` // Identifica la instancia que responde
+	// Since es cuándo empezó el drenaje y EndsAt cuándo se cerrará la última conexión.
+	Since             time.Time `json:"since,omitempty"`
+	EndsAt            time.Time `json:"endsAt,omitempty"`
+	RetryAfterSeconds int       `json:"retryAfterSeconds,omitempty"`
+	Total             int       `json:"total"`       // Conexiones abiertas al empezar
+	Closed            int       `json:"closed"`      // Conexiones cerradas por el drenaje
+	Connections       int       `json:"connections"` // Conexiones abiertas ahora
+}
+
+// ConnectionDrainer saca de servicio una instancia sin reconexiones en masa: rechaza las
+// conexiones nuevas con 503 (GEN_012, con Retry-After), falla la comprobación de readiness y
+// cierra las conexiones abiertas de una en una, repartidas a lo largo del plazo indicado, con
+// DrainCloseCode. Cada cliente recibe un retry-after distinto, entre el configurado y el doble,
+// para que tampoco reconecten todos a la vez. Hasta que se detiene, la instancia no acepta
+// conexiones aunque haya terminado de cerrar las suyas.
+type ConnectionDrainer struct {
+	manager           *customws.ConnectionManager[wsmodels.WsUserData]
+	instance          string
+	defaultSpread     time.Duration
+	defaultRetryAfter time.Duration
+
+	mu     sync.Mutex
+	state  DrainState
+	cancel chan struct{} // Cierra el drenaje en curso
+}
+
+// NewConnectionDrainer crea el drenador. instance identifica la instancia en las respuestas;
+// spread y retryAfter son los valores por defecto de Start.
+func NewConnectionDrainer(manager *customws.ConnectionManager[wsmodels.WsUserData], instance string, spread, retryAfter time.Duration) *ConnectionDrainer {
+	return &ConnectionDrainer{
+		manager:           manager,
+		instance:          instance,
+		defaultSpread:     spread,
+		defaultRetryAfter: retryAfter,
+		state:             DrainState{Instance: instance},
+	}
+}
+
+// State devuelve el estado del drenaje.
+func (d *ConnectionDrainer) State() DrainState {
+	d.mu.Lock()
+	state := d.state
+	d.mu.Unlock()
+	state.Connections = len(d.connections())
+	return state
+}
+
+// Active indica si la instancia está drenando.
+func (d *ConnectionDrainer) Active() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.state.Active
+}
+
+// Start empieza a drenar la instancia. spread y retryAfter nil usan los valores por defecto.
+// Con un drenaje en curso lo reinicia con las conexiones que quedan.
+func (d *ConnectionDrainer) Start(spread, retryAfter *time.Duration) DrainState {
+	if spread == nil || *spread < 0 {
+		spread = &d.defaultSpread
+	}
+	if retryAfter == nil || *retryAfter < 0 {
+		retryAfter = &d.defaultRetryAfter
+	}
+
+	d.mu.Lock()
+	if d.cancel != nil {
+		close(d.cancel)
+	}
+	cancel := make(chan struct{})
+	d.cancel = cancel
+	now := time.Now()
+	d.state = DrainState{
+		Active:            true,
+		Instance:          d.instance,
+		Since:             now,
+		EndsAt:            now.Add(*spread),
+		RetryAfterSeconds: int(retryAfter.Seconds()),
+	}
+	state := d.state
+	d.mu.Unlock()
+
+	logger.Infof(drainServiceComponent, "Drenando la instancia %s: cierre de las conexiones hasta las %s",
+		d.instance, state.EndsAt.Format(time.RFC3339))
+	go d.run(*spread, cancel)
+	return d.State()
+}
+
+// Stop detiene el drenaje: la instancia vuelve a aceptar conexiones. Las ya cerradas no se
+// recuperan.
+func (d *ConnectionDrainer) Stop() DrainState {
+	d.mu.Lock()
+	if d.cancel != nil {
+		close(d.cancel)
+		d.cancel = nil
+	}
+	wasActive := d.state.Active
+	d.state = DrainState{Instance: d.instance}
+	d.mu.Unlock()
+
+	if wasActive {
+		logger.Infof(drainServiceComponent, "Drenaje de la instancia %s detenido", d.instance)
+	}
+	return d.State()
+}
+
+// run cierra las conexiones abiertas al empezar repartidas a lo largo de spread, salvo que
+// cancel se cierre antes.
+func (d *ConnectionDrainer) run(spread time.Duration, cancel <-chan struct{}) {
+	conns := d.connections()
+	rand.Shuffle(len(conns), func(i, j int) { conns[i], conns[j] = conns[j], conns[i] })
+
+	d.mu.Lock()
+	d.state.Total = len(conns)
+	retryAfter := d.state.RetryAfterSeconds
+	d.mu.Unlock()
+	if len(conns) == 0 {
+		return
+	}
+
+	interval := spread / time.Duration(len(conns))
+	for i, conn := range conns {
+		if i > 0 && interval > 0 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-cancel:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		select {
+		case <-cancel:
+			return
+		default:
+		}
+
+		conn.CloseWithReason(DrainCloseCode, types.CloseReason{
+			Reason:     "draining",
+			Reconnect:  true,
+			RetryAfter: customws.RetryAfterSeconds(time.Duration(retryAfter) * time.Second),
+		})
+		d.mu.Lock()
+		d.state.Closed++
+		d.mu.Unlock()
+	}
+	logger.Infof(drainServiceComponent, "Drenaje de la instancia %s: %d conexiones cerradas", d.instance, len(conns))
+}
+
+// connections devuelve las conexiones abiertas en la instancia.
+func (d *ConnectionDrainer) connections() []*customws.Connection[wsmodels.WsUserData] {
+	var conns []*customws.Connection[wsmodels.WsUserData]
+	for _, userID := range d.manager.OnlineUserIDs() {
+		if userConns, found := d.manager.GetConnections(userID); found {
+			conns = append(conns, userConns...)
+		}
+	}
+	return conns
+}
+
+// Middleware rechaza con 503 (GEN_012) las conexiones nuevas mientras la instancia drena.
+func (d *ConnectionDrainer) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if d.Active() {
+			if retryAfter := d.State().RetryAfterSeconds; retryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			}
+			apperrors.Write(w, apperrors.Draining, "La instancia no acepta conexiones nuevas, conéctate a otra")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// Ready es la comprobación de readiness: falla mientras la instancia drena, para que el
+// orquestador deje de enviarle conexiones.
+func (d *ConnectionDrainer) Ready(ctx context.Context) error {
+	if d.Active() {
+		return errDraining
+	}
+	return nil
+}
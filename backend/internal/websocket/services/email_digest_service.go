@@ -0,0 +1,201 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"strconv"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/mailer"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/timefmt"
+)
+
+const emailDigestComponent = "SERVICE_EMAIL_DIGEST"
+
+// emailDigestBatchSize es el máximo de correos que se envían en cada ejecución del job. El
+// resto de destinatarios se atiende en las siguientes.
+const emailDigestBatchSize = 100
+
+//go:embed templates/email_digest.html
+var emailDigestHTML string
+
+var emailDigestTemplate = template.Must(template.New("email_digest").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return timefmt.Display(t).Format("02/01/2006 15:04") },
+}).Parse(emailDigestHTML))
+
+// emailDigestView son los datos de la plantilla del resumen.
+type emailDigestView struct {
+	Name   string
+	Chats  []emailDigestChat
+	Events []models.DigestEvent
+	AppURL string
+}
+
+// emailDigestChat agrupa los mensajes sin leer de un mismo remitente.
+type emailDigestChat struct {
+	SenderName string
+	Count      int
+	Preview    string // Último mensaje. Vacío si el usuario desactivó las vistas previas
+	LastAt     time.Time
+}
+
+// EmailDigestJob envía por correo un resumen de las notificaciones y mensajes que el usuario
+// lleva sin leer más de la ventana de inactividad configurada. Cada elemento se resume una
+// sola vez (tabla EmailDigestItem) y cada usuario recibe como máximo un resumen por ventana.
+type EmailDigestJob struct {
+	manager    *customws.ConnectionManager[wsmodels.WsUserData]
+	mailer     *mailer.Mailer
+	inactivity time.Duration
+	maxItems   int
+	appURL     string
+}
+
+// NewEmailDigestJob crea el job. Devuelve nil si el resumen está deshabilitado o no hay SMTP
+// configurado.
+func NewEmailDigestJob(cfg *config.Config, manager *customws.ConnectionManager[wsmodels.WsUserData]) *EmailDigestJob {
+	if cfg.EmailDigestInterval <= 0 {
+		return nil
+	}
+	m := mailer.New(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+	if !m.Enabled() {
+		logger.Warn(emailDigestComponent, "SMTP no configurado: el resumen por correo queda deshabilitado")
+		return nil
+	}
+	job := &EmailDigestJob{
+		manager:    manager,
+		mailer:     m,
+		inactivity: cfg.EmailDigestInactivity,
+		maxItems:   cfg.EmailDigestMaxItems,
+		appURL:     cfg.FrontendURL,
+	}
+	if job.inactivity <= 0 {
+		job.inactivity = 24 * time.Hour
+	}
+	if job.maxItems <= 0 {
+		job.maxItems = 20
+	}
+	return job
+}
+
+// Run envía los resúmenes pendientes. Se registra como job periódico.
+func (j *EmailDigestJob) Run(ctx context.Context) error {
+	unreadBefore := time.Now().Add(-j.inactivity)
+	userIDs, err := queries.GetEmailDigestRecipients(unreadBefore, emailDigestBatchSize)
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		// La tabla Online puede quedar desactualizada tras un reinicio; la conexión manda.
+		if j.manager.IsUserOnline(userID) {
+			continue
+		}
+		ok, err := j.sendDigest(userID, unreadBefore)
+		if err != nil {
+			logger.Warnf(emailDigestComponent, "No se pudo enviar el resumen a UserID %d: %v", userID, err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	if sent > 0 {
+		logger.Infof(emailDigestComponent, "Resúmenes por correo enviados: %d", sent)
+	}
+	return nil
+}
+
+// sendDigest arma y envía el resumen de un usuario. Devuelve false si no había nada que
+// enviar o si otra instancia ya lo envió.
+func (j *EmailDigestJob) sendDigest(userID int64, unreadBefore time.Time) (bool, error) {
+	email, name, err := queries.GetDigestRecipient(userID)
+	if err != nil {
+		return false, err
+	}
+	prefs, err := queries.GetNotificationPreferences(userID)
+	if err != nil {
+		return false, err
+	}
+	events, err := queries.GetDigestEvents(userID, unreadBefore, j.maxItems)
+	if err != nil {
+		return false, err
+	}
+	messages, err := queries.GetDigestMessages(userID, unreadBefore, j.maxItems)
+	if err != nil {
+		return false, err
+	}
+
+	items := make([]models.DigestItemKey, 0, len(events)+len(messages))
+	for _, event := range events {
+		items = append(items, models.DigestItemKey{Type: models.DigestItemEvent, Id: strconv.FormatInt(event.Id, 10)})
+	}
+	for _, message := range messages {
+		items = append(items, models.DigestItemKey{Type: models.DigestItemMessage, Id: message.Id})
+	}
+	if len(items) == 0 {
+		return false, nil
+	}
+
+	view := emailDigestView{
+		Name:   name,
+		Chats:  groupDigestMessages(messages, prefs.ChatPreviews),
+		Events: events,
+		AppURL: j.appURL,
+	}
+	var body bytes.Buffer
+	if err := emailDigestTemplate.Execute(&body, view); err != nil {
+		return false, fmt.Errorf("error renderizando el resumen: %w", err)
+	}
+
+	claimed, err := queries.ClaimDigestItems(userID, items)
+	if err != nil || !claimed {
+		return false, err
+	}
+	subject := fmt.Sprintf("Tienes %d novedades sin leer - Alumni USM", len(items))
+	if len(items) == 1 {
+		subject = "Tienes 1 novedad sin leer - Alumni USM"
+	}
+	if err := j.mailer.Send(email, subject, body.String()); err != nil {
+		if releaseErr := queries.ReleaseDigestItems(userID, items); releaseErr != nil {
+			logger.Errorf(emailDigestComponent, "%v", releaseErr)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// groupDigestMessages agrupa los mensajes por remitente, en el orden del más reciente. Los
+// mensajes llegan ordenados del más reciente al más antiguo.
+func groupDigestMessages(messages []models.DigestMessage, withPreview bool) []emailDigestChat {
+	var chats []emailDigestChat
+	index := make(map[int64]int)
+	for _, message := range messages {
+		if i, ok := index[message.SenderId]; ok {
+			chats[i].Count++
+			continue
+		}
+		chat := emailDigestChat{SenderName: message.SenderName, Count: 1, LastAt: message.SentAt}
+		switch {
+		case withPreview && message.Content != "":
+			chat.Preview = truncatePreview(message.Content)
+		case message.HasMedia:
+			chat.Preview = "Te envió un archivo adjunto"
+		}
+		index[message.SenderId] = len(chats)
+		chats = append(chats, chat)
+	}
+	return chats
+}
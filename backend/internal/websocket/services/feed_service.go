@@ -2,10 +2,13 @@ package services
 
 import (
 	"database/sql"
+	"strings"
+	"time"
 
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
 )
 
 /*
@@ -26,7 +29,7 @@ import (
  *
  * USO:
  * ----
- * Es utilizado por FeedHandler para obtener los datos que se enviarán al cliente
+ * Es utilizado por el handler del feed (a través de IFeedService) para obtener los datos que se enviarán al cliente
  * a través de WebSocket.
  *
  * INYECCIÓN DE DEPENDENCIAS:
@@ -34,6 +37,15 @@ import (
  * El servicio se inicializa con una conexión a la base de datos (*sql.DB).
  */
 
+// IFeedService define las operaciones del feed que usan los handlers WebSocket, para poder
+// sustituirlas por un mock en las pruebas.
+type IFeedService interface {
+	GetFeedItems(userID, tenantID int64, page, limit int) (*wsmodels.FeedListResponsePayload, error)
+	GetFeedPage(userID, tenantID int64, page pagination.Params) (*wsmodels.FeedListResponsePayload, error)
+}
+
+var _ IFeedService = (*FeedService)(nil)
+
 // FeedService maneja la lógica de negocio para el feed.
 type FeedService struct {
 	DB *sql.DB
@@ -45,8 +57,9 @@ func NewFeedService(db *sql.DB) *FeedService {
 }
 
 // GetFeedItems obtiene una lista paginada de items para el feed de un usuario.
-// Ahora devuelve un payload completo que incluye la información de paginación.
-func (s *FeedService) GetFeedItems(userID int64, page, limit int) (*wsmodels.FeedListResponsePayload, error) {
+// Ahora devuelve un payload completo que incluye la información de paginación. Con tenantID
+// distinto de 0 solo incluye los items de esa institución y los que no son de ninguna.
+func (s *FeedService) GetFeedItems(userID, tenantID int64, page, limit int) (*wsmodels.FeedListResponsePayload, error) {
 	logger.Infof("FEED_SERVICE", "Usuario %d solicitó items del feed. Página: %d, Límite: %d", userID, page, limit)
 
 	if page < 1 {
@@ -59,26 +72,81 @@ func (s *FeedService) GetFeedItems(userID int64, page, limit int) (*wsmodels.Fee
 
 	// La nueva función GetUnifiedFeed ya combina y ordena los items en la BD
 	// y además devuelve el conteo total de items.
-	feedItems, totalItems, err := queries.GetUnifiedFeed(s.DB, userID, limit, offset)
+	feedItems, totalItems, err := queries.GetUnifiedFeed(s.DB, userID, tenantID, limit, offset)
 	if err != nil {
 		logger.Errorf("FEED_SERVICE", "Error obteniendo el feed unificado para el UserID %d: %v", userID, err)
 		return nil, err
 	}
 
+	s.fillOnlineStatus(feedItems)
+
 	// Calculamos si hay más páginas de forma fiable.
 	hasMore := (offset + len(feedItems)) < totalItems
 
-	pagination := &wsmodels.PaginationInfo{
-		TotalItems:  totalItems,
-		CurrentPage: page,
-		HasMore:     hasMore,
-	}
-
 	response := &wsmodels.FeedListResponsePayload{
-		Items:      feedItems,
-		Pagination: pagination,
+		Page: pagination.Page[wsmodels.FeedItem]{Items: feedItems, HasMore: hasMore},
+		Pagination: &wsmodels.PaginationInfo{
+			TotalItems:  totalItems,
+			CurrentPage: page,
+			HasMore:     hasMore,
+		},
 	}
 
 	logger.Successf("FEED_SERVICE", "Devueltos %d de %d items del feed para el usuario %d. Hay más: %t", len(feedItems), totalItems, userID, hasMore)
 	return response, nil
 }
+
+// GetFeedPage obtiene una página del feed por cursor, ordenada por fecha (ver
+// queries.GetUnifiedFeedPage). No calcula el total de items.
+func (s *FeedService) GetFeedPage(userID, tenantID int64, page pagination.Params) (*wsmodels.FeedListResponsePayload, error) {
+	feedItems, err := queries.GetUnifiedFeedPage(s.DB, userID, tenantID, page)
+	if err != nil {
+		logger.Errorf("FEED_SERVICE", "Error obteniendo la página del feed para el UserID %d: %v", userID, err)
+		return nil, err
+	}
+
+	result := pagination.NewPage(feedItems, page.Limit, feedItemCursor)
+	s.fillOnlineStatus(result.Items)
+	return &wsmodels.FeedListResponsePayload{Page: result}, nil
+}
+
+// feedItemCursor calcula el cursor de un item a partir de su tipo, su Id ("event-12",
+// "user-5") y su fecha, las columnas por las que ordena queries.GetUnifiedFeedPage.
+func feedItemCursor(item wsmodels.FeedItem) pagination.Cursor {
+	createdAt, _ := time.Parse(time.RFC3339, item.Timestamp)
+	_, id, _ := strings.Cut(item.ID, "-")
+	return pagination.Cursor{CreatedAt: createdAt, ID: id, Kind: item.Type}
+}
+
+// fillOnlineStatus marca qué perfiles de la página están conectados, con una sola consulta para
+// todos los items en lugar de una por perfil. Si la consulta falla, se dejan como offline.
+func (s *FeedService) fillOnlineStatus(items []wsmodels.FeedItem) {
+	userIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		switch data := item.Data.(type) {
+		case wsmodels.StudentFeedData:
+			userIDs = append(userIDs, data.UserID)
+		case wsmodels.CompanyFeedData:
+			userIDs = append(userIDs, data.UserID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return
+	}
+
+	online, err := queries.GetOnlineStatusByIDs(userIDs)
+	if err != nil {
+		logger.Warnf("FEED_SERVICE", "Error obteniendo el estado online de %d perfiles del feed: %v", len(userIDs), err)
+		return
+	}
+	for i, item := range items {
+		switch data := item.Data.(type) {
+		case wsmodels.StudentFeedData:
+			data.IsOnline = online[data.UserID]
+			items[i].Data = data
+		case wsmodels.CompanyFeedData:
+			data.IsOnline = online[data.UserID]
+			items[i].Data = data
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// Errores del reenvío de mensajes.
+var (
+	ErrForwardNotFound       = apperrors.New(apperrors.ForwardNotFound, "el mensaje a reenviar no existe o no tienes acceso a él")
+	ErrForwardTargetNotFound = apperrors.New(apperrors.ChatNotFound, "chat de destino no encontrado")
+)
+
+// ForwardChatMessage reenvía el mensaje sourceMessageID (texto y/o adjunto) al chat
+// targetChatID, privado o de grupo, o al chat con el contacto targetUserID si targetChatID
+// está vacío. El usuario debe participar en ambos chats. La copia lleva la marca del mensaje y
+// el autor originales; reenviar un reenvío conserva el original. El adjunto no se duplica:
+// la copia lo referencia y suma una referencia en Multimedia.RefCount.
+func ForwardChatMessage(userID int64, sourceMessageID, targetChatID string, targetUserID int64, messageID string, manager *customws.ConnectionManager[wsmodels.WsUserData]) (*wsmodels.MessageDB, error) {
+	source, err := queries.GetForwardSource(userID, sourceMessageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrForwardNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// El contenido oculto por moderación no puede salir del chat reenviándolo.
+	if source.IsHidden {
+		return nil, ErrForwardNotFound
+	}
+
+	if targetChatID == "" {
+		targetChatID, err = queries.GetAcceptedContactChatID(userID, targetUserID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrForwardTargetNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	isGroup, err := queries.GetUserChatKind(userID, targetChatID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrForwardTargetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{
+		"content":                source.Content,
+		"forwardedFromMessageId": source.OriginMessageId(),
+		"forwardedFromSenderId":  source.OriginSenderId(),
+	}
+	if isGroup {
+		payload["chatIdGroup"] = targetChatID
+	} else {
+		payload["chatId"] = targetChatID
+	}
+	if source.MediaFileName != "" {
+		payload["mediaId"] = source.MediaFileName
+	}
+
+	message, err := ProcessAndSaveChatMessage(userID, payload, messageID, manager)
+	if err != nil {
+		return message, fmt.Errorf("error reenviando el mensaje %s: %w", sourceMessageID, err)
+	}
+	logger.Infof("SERVICE_CHAT", "UserID %d reenvió el mensaje %s al chat %s como %s", userID, sourceMessageID, targetChatID, messageID)
+	return message, nil
+}
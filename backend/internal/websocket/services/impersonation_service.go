@@ -0,0 +1,64 @@
+package services
+
+import (
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const impersonationServiceComponent = "SERVICE_IMPERSONATION"
+
+// impersonationCheckInterval es cada cuánto se comprueba si la suplantación de una conexión
+// se terminó desde el panel de administración.
+const impersonationCheckInterval = time.Minute
+
+// WatchImpersonation envía a una conexión abierta con un token de suplantación el aviso para
+// que el cliente lo muestre, y la cierra cuando la suplantación expira o se termina. Solo se
+// cierra esa conexión: las del propio usuario siguen abiertas.
+func WatchImpersonation(conn *customws.Connection[wsmodels.WsUserData]) {
+	data := conn.UserData
+	msg := types.ServerToClientMessage{
+		Type: types.MessageTypeImpersonation,
+		Payload: wsmodels.ImpersonationPayload{
+			ImpersonationID: data.ImpersonationID,
+			ImpersonatorID:  data.ImpersonatorID,
+			ExpiresAt:       data.ImpersonationExpiresAt,
+		},
+	}
+	if err := conn.SendMessage(msg); err != nil {
+		logger.Warnf(impersonationServiceComponent, "No se pudo enviar el aviso de suplantación %d: %v", data.ImpersonationID, err)
+	}
+
+	go func() {
+		expiry := time.NewTimer(time.Until(data.ImpersonationExpiresAt))
+		defer expiry.Stop()
+		ticker := time.NewTicker(impersonationCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-conn.Context().Done():
+				return
+			case <-expiry.C:
+				logger.Infof(impersonationServiceComponent, "Suplantación %d expirada: cerrando la conexión del usuario %d", data.ImpersonationID, data.UserID)
+				conn.CloseWithReason(customws.CloseAuthExpired, types.CloseReason{Reason: "impersonation_expired"})
+				return
+			case <-ticker.C:
+				active, err := queries.IsImpersonationActive(data.ImpersonationID, data.ImpersonatorID, data.UserID)
+				if err != nil {
+					logger.Warnf(impersonationServiceComponent, "%v", err)
+					continue
+				}
+				if !active {
+					logger.Infof(impersonationServiceComponent, "Suplantación %d terminada: cerrando la conexión del usuario %d", data.ImpersonationID, data.UserID)
+					conn.CloseWithReason(customws.CloseKicked, types.CloseReason{Reason: "impersonation_ended"})
+					return
+				}
+			}
+		}
+	}()
+}
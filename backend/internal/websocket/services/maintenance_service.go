@@ -0,0 +1,120 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/maintenance"
+)
+
+const maintenanceServiceComponent = "SERVICE_MAINTENANCE"
+
+// maintenanceNoticeInterval es cada cuánto se repite el aviso de mantenimiento durante la
+// cuenta atrás.
+const maintenanceNoticeInterval = 15 * time.Second
+
+// MaintenanceCloseCode es el código de cierre de las conexiones al entrar en mantenimiento
+// (1013, Try Again Later, RFC 6455): el cliente debe reconectar pasado el retryAfter del motivo.
+const MaintenanceCloseCode = 1013
+
+// MaintenanceDrainer lleva el modo mantenimiento a los clientes conectados: al activarse les
+// envía un mensaje maintenance con la cuenta atrás, lo repite hasta State.DrainAt y entonces
+// cierra todas las conexiones. Las conexiones nuevas las rechaza maintenance.Mode.Middleware.
+// Si el modo se desactiva antes, cancela la cuenta atrás y avisa con active false.
+type MaintenanceDrainer struct {
+	manager *customws.ConnectionManager[wsmodels.WsUserData]
+
+	mu     sync.Mutex
+	cancel chan struct{} // Cierra la cuenta atrás en curso
+}
+
+// NewMaintenanceDrainer crea el drenador sobre el ConnectionManager del servidor.
+func NewMaintenanceDrainer(manager *customws.ConnectionManager[wsmodels.WsUserData]) *MaintenanceDrainer {
+	return &MaintenanceDrainer{manager: manager}
+}
+
+// Handle aplica un cambio de estado. Se registra con maintenance.Mode.OnChange y no bloquea.
+func (d *MaintenanceDrainer) Handle(state maintenance.State) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cancel != nil {
+		close(d.cancel)
+		d.cancel = nil
+	}
+	if !state.Enabled {
+		logger.Info(maintenanceServiceComponent, "Modo mantenimiento desactivado")
+		go d.notify(wsmodels.MaintenancePayload{Active: false})
+		return
+	}
+
+	logger.Infof(maintenanceServiceComponent, "Modo mantenimiento activado: se cerrarán las conexiones a las %s",
+		state.DrainAt.Format(time.RFC3339))
+	cancel := make(chan struct{})
+	d.cancel = cancel
+	go d.countdown(state, cancel)
+}
+
+// countdown avisa a los clientes cada maintenanceNoticeInterval hasta DrainAt y entonces cierra
+// las conexiones, salvo que cancel se cierre antes.
+func (d *MaintenanceDrainer) countdown(state maintenance.State, cancel <-chan struct{}) {
+	for {
+		remaining := time.Until(state.DrainAt)
+		if remaining <= 0 {
+			d.drain(state)
+			return
+		}
+		d.notify(wsmodels.MaintenancePayload{
+			Active:            true,
+			Message:           state.Message,
+			ClosesInSeconds:   int(remaining.Round(time.Second).Seconds()),
+			DrainAt:           state.DrainAt,
+			RetryAfterSeconds: state.RetryAfterSeconds,
+		})
+
+		wait := maintenanceNoticeInterval
+		if remaining < wait {
+			wait = remaining
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-cancel:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (d *MaintenanceDrainer) notify(payload wsmodels.MaintenancePayload) {
+	d.manager.BroadcastToAll(types.ServerToClientMessage{
+		PID:     d.manager.Callbacks().GeneratePID(),
+		Type:    types.MessageTypeMaintenance,
+		Payload: payload,
+	})
+}
+
+// drain cierra todas las conexiones con MaintenanceCloseCode.
+func (d *MaintenanceDrainer) drain(state maintenance.State) {
+	closed := 0
+	for _, userID := range d.manager.OnlineUserIDs() {
+		conns, found := d.manager.GetConnections(userID)
+		if !found {
+			continue
+		}
+		for _, conn := range conns {
+			conn.CloseWithReason(MaintenanceCloseCode, types.CloseReason{
+				Reason:     "maintenance",
+				Reconnect:  true,
+				RetryAfter: state.RetryAfterSeconds,
+			})
+			closed++
+		}
+	}
+	logger.Infof(maintenanceServiceComponent, "Mantenimiento: %d conexiones cerradas (retry after %ds)",
+		closed, state.RetryAfterSeconds)
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const moderationServiceComponent = "SERVICE_MODERATION"
+
+// suspendedDisconnectDelay es la espera antes de cerrar las conexiones de una cuenta
+// suspendida, desactivada o con un rol nuevo, para que le llegue la notificación.
+const suspendedDisconnectDelay = 2 * time.Second
+
+// ModerationPublisher envía en tiempo real las notificaciones de moderación (reporte
+// resuelto, advertencia y suspensión) y de gestión de cuentas (desactivación y cambio de
+// rol). Las decisiones se toman en la API REST, que no tiene acceso a las conexiones, así que
+// el publisher consulta periódicamente la tabla Event desde el último ID enviado. Tras avisar
+// de una suspensión, desactivación o cambio de rol cierra las conexiones del usuario.
+type ModerationPublisher struct {
+	manager *customws.ConnectionManager[wsmodels.WsUserData]
+
+	mu          sync.Mutex
+	lastID      int64
+	initialized bool
+}
+
+// NewModerationPublisher crea el publisher. Publish debe registrarse como job periódico.
+func NewModerationPublisher(manager *customws.ConnectionManager[wsmodels.WsUserData]) *ModerationPublisher {
+	return &ModerationPublisher{manager: manager}
+}
+
+// Publish envía las notificaciones de moderación creadas desde la última ejecución. La
+// primera ejecución solo toma el último ID como punto de partida; las anteriores se ven en
+// la lista de notificaciones.
+func (p *ModerationPublisher) Publish(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	maxID, err := queries.GetMaxEventID()
+	if err != nil {
+		return err
+	}
+	if !p.initialized {
+		p.lastID, p.initialized = maxID, true
+		return nil
+	}
+	if maxID <= p.lastID {
+		return nil
+	}
+
+	events, err := queries.GetModerationEvents(p.lastID, maxID)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		SendStoredNotification(event, p.manager)
+		switch event.EventType {
+		case models.EventTypeModerationSuspended:
+			p.disconnect(event.UserId, customws.CloseKicked, types.CloseReason{Reason: "account_suspended"})
+		case models.EventTypeAccountDeactivated:
+			p.disconnect(event.UserId, customws.CloseKicked, types.CloseReason{Reason: "account_deactivated"})
+		case models.EventTypeRoleChanged:
+			// La reconexión toma el rol nuevo de la base de datos
+			p.disconnect(event.UserId, customws.CloseAuthExpired, types.CloseReason{Reason: "role_changed", Reconnect: true})
+		}
+	}
+	p.lastID = maxID
+	return nil
+}
+
+// disconnect cierra las conexiones del usuario con code y reason. La autenticación del
+// WebSocket rechaza las reconexiones de cuentas suspendidas o desactivadas y toma el rol
+// actual de la base de datos.
+func (p *ModerationPublisher) disconnect(userID int64, code int, reason types.CloseReason) {
+	if !p.manager.IsUserOnline(userID) {
+		return
+	}
+	time.AfterFunc(suspendedDisconnectDelay, func() {
+		conns, found := p.manager.GetConnections(userID)
+		if !found {
+			return
+		}
+		for _, conn := range conns {
+			conn.CloseWithReason(code, reason)
+		}
+		logger.Infof(moderationServiceComponent, "Cerradas %d conexiones del usuario %d", len(conns), userID)
+	})
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const notificationExpiryComponent = "SERVICE_NOTIFICATION_EXPIRY"
+
+const (
+	// notificationExpiryBatchSize es el número de notificaciones borradas por transacción.
+	notificationExpiryBatchSize = 500
+	// notificationExpiryBatchPause evita acaparar la BD entre lotes consecutivos.
+	notificationExpiryBatchPause = 100 * time.Millisecond
+)
+
+// NotificationExpiryJob borra las notificaciones leídas más antiguas que su retención. Cada
+// tipo puede tener su propio plazo (NOTIFICATION_RETENTION_BY_TYPE); el resto usa
+// NOTIFICATION_RETENTION_DAYS. Las notificaciones sin leer y las que esperan una acción del
+// usuario no expiran.
+type NotificationExpiryJob struct {
+	defaultDays int
+	// byType son los días de retención por tipo de notificación; 0 = ese tipo no expira.
+	byType map[string]int
+}
+
+// NewNotificationExpiryJob crea el job. Devuelve nil si la expiración está deshabilitada y un
+// error si NOTIFICATION_RETENTION_BY_TYPE no es válido.
+func NewNotificationExpiryJob(cfg *config.Config) (*NotificationExpiryJob, error) {
+	if cfg.NotificationRetentionInterval <= 0 {
+		return nil, nil
+	}
+	byType, err := ParseNotificationRetentionByType(cfg.NotificationRetentionByType)
+	if err != nil {
+		return nil, err
+	}
+	return &NotificationExpiryJob{defaultDays: max(cfg.NotificationRetentionDays, 0), byType: byType}, nil
+}
+
+// ParseNotificationRetentionByType interpreta una lista "TIPO=días,TIPO=días".
+func ParseNotificationRetentionByType(spec string) (map[string]int, error) {
+	byType := make(map[string]int)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		eventType, days, ok := strings.Cut(entry, "=")
+		eventType = strings.TrimSpace(eventType)
+		if !ok || eventType == "" {
+			return nil, fmt.Errorf("retención %q inválida: se esperaba TIPO=días", entry)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(days))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("retención %q inválida: los días deben ser un entero mayor o igual que 0", entry)
+		}
+		byType[eventType] = n
+	}
+	return byType, nil
+}
+
+// Run borra las notificaciones expiradas de cada tipo con retención propia y después las del
+// resto. Se registra como job periódico.
+func (j *NotificationExpiryJob) Run(ctx context.Context) error {
+	types := make([]string, 0, len(j.byType))
+	for eventType := range j.byType {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+
+	var total int64
+	for _, eventType := range types {
+		deleted, err := j.expire(ctx, eventType, nil, j.byType[eventType])
+		total += deleted
+		if err != nil {
+			return err
+		}
+	}
+	deleted, err := j.expire(ctx, "", types, j.defaultDays)
+	total += deleted
+	if total > 0 {
+		logger.Infof(notificationExpiryComponent, "Notificaciones leídas expiradas borradas: %d", total)
+	}
+	return err
+}
+
+// expire borra por lotes las notificaciones leídas de eventType (o de cualquier tipo salvo
+// excludeTypes, si eventType está vacío) con más de days días. days 0 no borra nada.
+func (j *NotificationExpiryJob) expire(ctx context.Context, eventType string, excludeTypes []string, days int) (int64, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	var total int64
+	for ctx.Err() == nil {
+		deleted, err := queries.DeleteExpiredReadNotificationsBatch(eventType, excludeTypes, cutoff, notificationExpiryBatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+		if deleted < notificationExpiryBatchSize {
+			break
+		}
+		time.Sleep(notificationExpiryBatchPause)
+	}
+	return total, nil
+}
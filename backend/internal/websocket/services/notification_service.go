@@ -10,9 +10,11 @@ import (
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
 	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
 )
 
 /*
@@ -50,11 +52,59 @@ El NotificationService es responsable de manejar todas las notificaciones del si
 
 var (
 	notificationDB *sql.DB
+	// notificationGroupWindow es NOTIFICATION_GROUP_WINDOW: al listar, las notificaciones sin
+	// leer del mismo grupo separadas por menos de este plazo se muestran como una sola.
+	notificationGroupWindow time.Duration
 )
 
+// INotificationService define las operaciones de notificaciones que usan los handlers
+// WebSocket, para poder sustituirlas por un mock en las pruebas.
+type INotificationService interface {
+	GetNotifications(userID int64, onlyUnread bool, limit int, offset int) ([]wsmodels.NotificationInfo, error)
+	GetNotificationsPage(userID int64, onlyUnread bool, page pagination.Params) (pagination.Page[wsmodels.NotificationInfo], error)
+	MarkRead(userID int64, notificationIDStr string) error
+	MarkAllRead(userID int64) (int64, error)
+	SetRead(userID int64, notificationIDs []string, read bool) (int64, error)
+	Delete(userID int64, notificationIDs []string) (int64, error)
+}
+
+// NotificationService implementa INotificationService con las funciones del paquete, que usan
+// la conexión de InitializeNotificationService.
+type NotificationService struct{}
+
+// NewNotificationService crea una nueva instancia de NotificationService
+func NewNotificationService() *NotificationService {
+	return &NotificationService{}
+}
+
+func (NotificationService) GetNotifications(userID int64, onlyUnread bool, limit int, offset int) ([]wsmodels.NotificationInfo, error) {
+	return GetNotifications(userID, onlyUnread, limit, offset)
+}
+
+func (NotificationService) GetNotificationsPage(userID int64, onlyUnread bool, page pagination.Params) (pagination.Page[wsmodels.NotificationInfo], error) {
+	return GetNotificationsPage(userID, onlyUnread, page)
+}
+
+func (NotificationService) MarkRead(userID int64, notificationIDStr string) error {
+	return MarkRead(userID, notificationIDStr)
+}
+
+func (NotificationService) MarkAllRead(userID int64) (int64, error) {
+	return MarkAllRead(userID)
+}
+
+func (NotificationService) SetRead(userID int64, notificationIDs []string, read bool) (int64, error) {
+	return SetRead(userID, notificationIDs, read)
+}
+
+func (NotificationService) Delete(userID int64, notificationIDs []string) (int64, error) {
+	return Delete(userID, notificationIDs)
+}
+
 // InitializeNotificationService inyecta las dependencias necesarias
-func InitializeNotificationService(db *sql.DB) {
+func InitializeNotificationService(db *sql.DB, groupWindow time.Duration) {
 	notificationDB = db
+	notificationGroupWindow = groupWindow
 	logger.Info("SERVICE_NOTIFICATION", "NotificationService inicializado con conexión a BD.")
 }
 
@@ -174,8 +224,32 @@ func createAndSendNotification(event models.Event) error {
 	return nil
 }
 
-// mapEventToNotificationInfo convierte un models.Event a wsmodels.NotificationInfo
-func mapEventToNotificationInfo(event models.Event) (wsmodels.NotificationInfo, error) {
+// loadNotificationProfiles obtiene en una sola consulta la información base de los usuarios
+// relacionados (OtherUserId y los de los grupos) con los eventos, en lugar de una consulta
+// por notificación. Devuelve nil si no se pudo obtener; las notificaciones se envían entonces
+// sin perfil.
+func loadNotificationProfiles(events []models.Event) map[int64]*models.UserBaseInfo {
+	userIDs := make([]int64, 0, len(events))
+	for _, event := range events {
+		if event.OtherUserId.Valid {
+			userIDs = append(userIDs, event.OtherUserId.Int64)
+		}
+		userIDs = append(userIDs, event.GroupActors...)
+	}
+	if len(userIDs) == 0 {
+		return map[int64]*models.UserBaseInfo{}
+	}
+	profiles, err := queries.GetUserBaseInfoByIDs(userIDs)
+	if err != nil {
+		logger.Warnf("SERVICE_NOTIFICATION", "Error obteniendo UserBaseInfo de %d usuarios para notificaciones: %v", len(userIDs), err)
+		return nil
+	}
+	return profiles
+}
+
+// mapEventToNotificationInfo convierte un models.Event a wsmodels.NotificationInfo. profiles
+// es la información base de los OtherUserId, obtenida con loadNotificationProfiles.
+func mapEventToNotificationInfo(event models.Event, profiles map[int64]*models.UserBaseInfo) (wsmodels.NotificationInfo, error) {
 	wsPayload := make(map[string]interface{})
 	if event.OtherUserId.Valid {
 		wsPayload["otherUserId"] = event.OtherUserId.Int64
@@ -218,24 +292,100 @@ func mapEventToNotificationInfo(event models.Event) (wsmodels.NotificationInfo,
 		notificationInfo.ActionTakenAt = &event.ActionTakenAt.Time
 	}
 
-	// Obtener información del perfil si hay OtherUserId
+	// Completar el perfil del OtherUserId con la información precargada
 	if event.OtherUserId.Valid {
-		otherUserInfo, err := queries.GetUserBaseInfo(event.OtherUserId.Int64)
-		if err == nil && otherUserInfo != nil {
-			notificationInfo.Profile = wsmodels.ProfileData{
-				ID:        otherUserInfo.ID,
-				FirstName: otherUserInfo.FirstName,
-				LastName:  otherUserInfo.LastName,
-				UserName:  otherUserInfo.UserName,
-				Picture:   otherUserInfo.Picture,
+		if otherUserInfo, ok := profiles[event.OtherUserId.Int64]; ok {
+			notificationInfo.Profile = notificationProfile(otherUserInfo)
+		} else if profiles != nil {
+			logger.Warnf("SERVICE_NOTIFICATION", "UserBaseInfo no encontrado para OtherUserId %d (Evento ID %d)", event.OtherUserId.Int64, event.Id)
+		}
+	}
+
+	if event.GroupCount > 1 {
+		group := &wsmodels.NotificationGroupInfo{Count: event.GroupCount}
+		for _, actorID := range event.GroupActors {
+			if actor, ok := profiles[actorID]; ok {
+				group.Actors = append(group.Actors, notificationProfile(actor))
 			}
-		} else if err != nil {
-			logger.Warnf("SERVICE_NOTIFICATION", "Error obteniendo UserBaseInfo para OtherUserId %d (Evento ID %d): %v", event.OtherUserId.Int64, event.Id, err)
 		}
+		notificationInfo.Group = group
 	}
 	return notificationInfo, nil
 }
 
+// notificationProfile convierte la información base de un usuario en el perfil que acompaña
+// a una notificación.
+func notificationProfile(info *models.UserBaseInfo) wsmodels.ProfileData {
+	return wsmodels.ProfileData{
+		ID:        info.ID,
+		FirstName: info.FirstName,
+		LastName:  info.LastName,
+		UserName:  info.UserName,
+		Picture:   info.Picture,
+	}
+}
+
+// collapsedEvent es un evento de la lista ya agrupado con los demás de su grupo.
+type collapsedEvent struct {
+	event     models.Event
+	memberIDs []int64   // Ids de todos los eventos del grupo, empezando por el propio
+	oldestAt  time.Time // CreateAt del evento más antiguo del grupo
+}
+
+// collapseEventGroups junta los eventos sin leer con el mismo GroupKey que estén separados
+// por menos de notificationGroupWindow, ordenados del más reciente al más antiguo. Cubre los
+// que no se agruparon al crearse, como los que se crearon a la vez. El evento más reciente
+// representa al grupo con la suma de los contadores y los últimos usuarios. Solo agrupa
+// dentro de la lista recibida.
+func collapseEventGroups(events []models.Event) []collapsedEvent {
+	result := make([]collapsedEvent, 0, len(events))
+	open := make(map[string]int) // GroupKey -> posición en result de su grupo
+	for _, event := range events {
+		event.GroupCount = max(event.GroupCount, 1)
+		if event.GroupKey.Valid && !event.IsRead && notificationGroupWindow > 0 {
+			if i, ok := open[event.GroupKey.String]; ok && result[i].oldestAt.Sub(event.CreateAt) < notificationGroupWindow {
+				group := &result[i]
+				group.event.GroupCount += event.GroupCount
+				group.event.GroupActors = models.MergeEventGroupActors(group.event.GroupActors, event.GroupActors)
+				group.memberIDs = append(group.memberIDs, event.Id)
+				group.oldestAt = event.CreateAt
+				continue
+			}
+			open[event.GroupKey.String] = len(result)
+		}
+		result = append(result, collapsedEvent{event: event, memberIDs: []int64{event.Id}, oldestAt: event.CreateAt})
+	}
+	return result
+}
+
+// mapCollapsedEvents convierte los eventos agrupados en notificaciones. Las que agrupan varias
+// filas llevan sus Ids en Group.NotificationIds para que el cliente pueda marcarlas todas.
+func mapCollapsedEvents(userID int64, events []models.Event) []wsmodels.NotificationInfo {
+	collapsed := collapseEventGroups(events)
+	grouped := make([]models.Event, len(collapsed))
+	for i, c := range collapsed {
+		grouped[i] = c.event
+	}
+	profiles := loadNotificationProfiles(grouped)
+
+	notificationsInfo := make([]wsmodels.NotificationInfo, 0, len(collapsed))
+	for _, c := range collapsed {
+		notificationForClient, errMap := mapEventToNotificationInfo(c.event, profiles)
+		if errMap != nil {
+			// Loguear el error pero continuar, para no fallar toda la lista por una notificación
+			logger.Warnf("SERVICE_NOTIFICATION", "Error mapeando evento ID %d para UserID %d: %v", c.event.Id, userID, errMap)
+			continue
+		}
+		if len(c.memberIDs) > 1 {
+			for _, id := range c.memberIDs {
+				notificationForClient.Group.NotificationIds = append(notificationForClient.Group.NotificationIds, strconv.FormatInt(id, 10))
+			}
+		}
+		notificationsInfo = append(notificationsInfo, notificationForClient)
+	}
+	return notificationsInfo
+}
+
 // ProcessAndSendNotification crea un evento, lo guarda en la BD y lo envía al usuario si está conectado.
 func ProcessAndSendNotification(userIDToNotify int64, eventType string, title string, message string, relatedData map[string]interface{}, manager *customws.ConnectionManager[wsmodels.WsUserData]) error {
 	if notificationDB == nil {
@@ -316,7 +466,9 @@ func ProcessAndSendNotification(userIDToNotify int64, eventType string, title st
 	logger.Debugf("SERVICE_NOTIFICATION", "Nueva notificación para UserID %d (antes de enviar): ID=%s, Type=%s, Title=%s, ProfileID=%d, ProfileName=%s, ProfilePic=%s, Payload=%+v",
 		userIDToNotify, notificationForClient.ID, notificationForClient.Type, notificationForClient.Title, notificationForClient.Profile.ID, notificationForClient.Profile.FirstName+" "+notificationForClient.Profile.LastName, notificationForClient.Profile.Picture, notificationForClient.Payload)
 
-	if manager.IsUserOnline(userIDToNotify) {
+	if notificationSuppressedByDnd(userIDToNotify, eventType) {
+		logger.Infof("SERVICE_NOTIFICATION", "Usuario %d en No molestar. Notificación (ID: %d) guardada para el resumen.", userIDToNotify, event.Id)
+	} else if manager.IsUserOnline(userIDToNotify) {
 		serverMessage := types.ServerToClientMessage{
 			PID:     manager.Callbacks().GeneratePID(),
 			Type:    types.MessageTypeNewNotification,
@@ -350,21 +502,37 @@ func GetNotifications(userID int64, onlyUnread bool, limit int, offset int) ([]w
 		return nil, fmt.Errorf("error obteniendo eventos: %w", err)
 	}
 
-	notificationsInfo := make([]wsmodels.NotificationInfo, 0, len(events))
-	for _, event := range events {
-		notificationForClient, errMap := mapEventToNotificationInfo(event)
-		if errMap != nil {
-			// Loguear el error pero continuar, para no fallar toda la lista por una notificación
-			logger.Warnf("SERVICE_NOTIFICATION", "Error mapeando evento ID %d para UserID %d: %v", event.Id, userID, errMap)
-			continue
-		}
-		notificationsInfo = append(notificationsInfo, notificationForClient)
-	}
+	notificationsInfo := mapCollapsedEvents(userID, events)
 
 	logger.Successf("SERVICE_NOTIFICATION", "%d notificaciones recuperadas para UserID %d", len(notificationsInfo), userID)
 	return notificationsInfo, nil
 }
 
+// GetNotificationsPage recupera una página de notificaciones por cursor. A diferencia de
+// GetNotifications, el orden (CreateAt DESC, Id DESC) es estable aunque lleguen notificaciones
+// nuevas mientras el cliente pagina. El cursor se calcula antes de agrupar, por lo que una
+// página puede traer menos notificaciones que page.Limit.
+func GetNotificationsPage(userID int64, onlyUnread bool, page pagination.Params) (pagination.Page[wsmodels.NotificationInfo], error) {
+	if notificationDB == nil {
+		return pagination.Page[wsmodels.NotificationInfo]{}, fmt.Errorf("NotificationService no inicializado")
+	}
+
+	events, err := queries.GetEventsPageByUserID(userID, onlyUnread, page)
+	if err != nil {
+		logger.Errorf("SERVICE_NOTIFICATION", "Error obteniendo página de eventos para UserID %d desde la BD: %v", userID, err)
+		return pagination.Page[wsmodels.NotificationInfo]{}, fmt.Errorf("error obteniendo eventos: %w", err)
+	}
+	eventPage := pagination.NewPage(events, page.Limit, func(e models.Event) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: e.CreateAt, ID: strconv.FormatInt(e.Id, 10)}
+	})
+
+	return pagination.Page[wsmodels.NotificationInfo]{
+		Items:      mapCollapsedEvents(userID, eventPage.Items),
+		NextCursor: eventPage.NextCursor,
+		HasMore:    eventPage.HasMore,
+	}, nil
+}
+
 // MarkRead marca una notificación específica como leída.
 func MarkRead(userID int64, notificationIDStr string) error {
 	if notificationDB == nil {
@@ -407,3 +575,79 @@ func MarkAllRead(userID int64) (int64, error) {
 	logger.Successf("SERVICE_NOTIFICATION", "%d notificaciones marcadas como leídas para UserID %d", rowsAffected, userID)
 	return rowsAffected, nil
 }
+
+// SetRead marca como leídas (read=true) o no leídas las notificaciones indicadas del usuario.
+// Las de otros usuarios se ignoran. Devuelve cuántas cambiaron de estado.
+func SetRead(userID int64, notificationIDs []string, read bool) (int64, error) {
+	ids, err := parseNotificationIDs(notificationIDs)
+	if err != nil {
+		return 0, err
+	}
+	changed, err := queries.SetNotificationsRead(userID, ids, read)
+	if err != nil {
+		logger.Errorf("SERVICE_NOTIFICATION", "%v", err)
+		return 0, fmt.Errorf("error actualizando las notificaciones: %w", err)
+	}
+	logger.Infof("SERVICE_NOTIFICATION", "%d notificaciones marcadas (leída=%t) para UserID %d", changed, read, userID)
+	return changed, nil
+}
+
+// Delete borra las notificaciones indicadas del usuario. Las de otros usuarios se ignoran.
+// Devuelve cuántas se borraron.
+func Delete(userID int64, notificationIDs []string) (int64, error) {
+	ids, err := parseNotificationIDs(notificationIDs)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := queries.DeleteNotifications(userID, ids)
+	if err != nil {
+		logger.Errorf("SERVICE_NOTIFICATION", "%v", err)
+		return 0, fmt.Errorf("error borrando las notificaciones: %w", err)
+	}
+	logger.Infof("SERVICE_NOTIFICATION", "%d notificaciones borradas para UserID %d", deleted, userID)
+	return deleted, nil
+}
+
+// parseNotificationIDs convierte los IDs de notificación que envía el cliente, que son texto.
+func parseNotificationIDs(notificationIDs []string) ([]int64, error) {
+	ids := make([]int64, len(notificationIDs))
+	for i, raw := range notificationIDs {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, apperrors.New(apperrors.InvalidPayload, fmt.Sprintf("notificationId %q inválido", raw))
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// SendStoredNotification envía en tiempo real una notificación que ya fue persistida
+// (por ejemplo, desde un servicio compartido con la API REST) si el destinatario está conectado
+// y no está en su horario de No molestar. Devuelve true si se le envió.
+func SendStoredNotification(event models.Event, manager *customws.ConnectionManager[wsmodels.WsUserData]) bool {
+	if manager == nil || !manager.IsUserOnline(event.UserId) {
+		return false
+	}
+	// En No molestar la notificación queda guardada y entra en el resumen
+	if notificationSuppressedByDnd(event.UserId, event.EventType) {
+		return false
+	}
+
+	notificationForClient, err := mapEventToNotificationInfo(event, loadNotificationProfiles([]models.Event{event}))
+	if err != nil {
+		logger.Warnf("SERVICE_NOTIFICATION", "Error mapeando evento ID %d a NotificationInfo: %v", event.Id, err)
+		return false
+	}
+
+	serverMessage := types.ServerToClientMessage{
+		PID:     manager.Callbacks().GeneratePID(),
+		Type:    types.MessageTypeNewNotification,
+		Payload: notificationForClient,
+	}
+	if err := manager.SendMessageToUser(event.UserId, serverMessage); err != nil {
+		logger.Warnf("SERVICE_NOTIFICATION", "Error enviando notificación (ID: %d) a UserID %d online: %v", event.Id, event.UserId, err)
+		return false
+	}
+	logger.Infof("SERVICE_NOTIFICATION", "Notificación (ID: %d) enviada a UserID %d online.", event.Id, event.UserId)
+	return true
+}
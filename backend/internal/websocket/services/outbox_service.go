@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const outboxServiceComponent = "SERVICE_OUTBOX"
+
+// outboxReplayWindow es cuánto hacia atrás busca un servidor que arranca los mensajes que nadie
+// entregó, por ejemplo los creados mientras se reiniciaba.
+const outboxReplayWindow = 2 * time.Minute
+
+// outboxGapWait es cuánto se espera a un ID que falta entre los leídos antes de darlo por
+// perdido. Un hueco es una transacción que aún no se confirmó o que se revirtió.
+const outboxGapWait = 10 * time.Second
+
+// OutboxDispatcher envía a los usuarios conectados los mensajes que la API deja en la tabla
+// Outbox y anota su entrega. Cada servidor WebSocket lee todos los mensajes con su propio cursor,
+// porque solo conoce sus conexiones. Los mensajes de usuarios sin conexión no se reintentan: las
+// notificaciones ya están en su lista y les llega el push. Dispatch debe registrarse como job
+// periódico.
+type OutboxDispatcher struct {
+	manager   *customws.ConnectionManager[wsmodels.WsUserData]
+	batchSize int
+
+	mu          sync.Mutex
+	initialized bool
+	cursor      int64              // Último ID procesado sin huecos por debajo
+	replayUpTo  int64              // Último ID al arrancar: los anteriores ya entregados no se repiten
+	processed   map[int64]struct{} // IDs mayores que cursor ya procesados
+	gapSince    time.Time
+}
+
+// NewOutboxDispatcher crea el dispatcher, que lee como mucho batchSize mensajes por ejecución.
+func NewOutboxDispatcher(manager *customws.ConnectionManager[wsmodels.WsUserData], batchSize int) *OutboxDispatcher {
+	return &OutboxDispatcher{manager: manager, batchSize: batchSize, processed: make(map[int64]struct{})}
+}
+
+// Dispatch envía los mensajes nuevos del outbox. La primera ejecución empieza por los que
+// quedaron sin entregar en los últimos outboxReplayWindow.
+func (d *OutboxDispatcher) Dispatch(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.initialized {
+		startID, lastID, err := queries.GetOutboxStart(outboxReplayWindow)
+		if err != nil {
+			return err
+		}
+		d.cursor, d.replayUpTo, d.initialized = startID, lastID, true
+		if startID < lastID {
+			logger.Infof(outboxServiceComponent, "Reenviando los mensajes sin entregar del outbox desde el ID %d", startID+1)
+		}
+	}
+
+	messages, err := queries.GetOutboxMessages(d.cursor, d.batchSize)
+	if err != nil {
+		return err
+	}
+	var delivered []int64
+	for _, msg := range messages {
+		if ctx.Err() != nil {
+			break
+		}
+		if _, done := d.processed[msg.Id]; done {
+			continue
+		}
+		d.processed[msg.Id] = struct{}{}
+		if msg.Id <= d.replayUpTo && msg.DeliveredAt.Valid {
+			continue
+		}
+		if d.deliver(msg) {
+			delivered = append(delivered, msg.Id)
+		}
+	}
+	d.advance()
+
+	if len(delivered) > 0 {
+		return queries.MarkOutboxDelivered(delivered)
+	}
+	return nil
+}
+
+// deliver envía el mensaje a las conexiones del usuario en este servidor. Devuelve true si se
+// envió.
+func (d *OutboxDispatcher) deliver(msg models.OutboxMessage) bool {
+	if !d.manager.IsUserOnline(msg.UserId) {
+		return false
+	}
+	if msg.EventId.Valid {
+		// La notificación se borró antes de enviarse
+		if msg.Event == nil {
+			return false
+		}
+		return SendStoredNotification(*msg.Event, d.manager)
+	}
+
+	err := d.manager.SendMessageToUser(msg.UserId, types.ServerToClientMessage{
+		PID:     d.manager.Callbacks().GeneratePID(),
+		Type:    types.MessageType(msg.Topic),
+		Payload: msg.Payload,
+	})
+	if err != nil {
+		logger.Warnf(outboxServiceComponent, "Error enviando el mensaje %d (%s) a UserID %d: %v", msg.Id, msg.Topic, msg.UserId, err)
+		return false
+	}
+	return true
+}
+
+// advance mueve el cursor sobre los IDs procesados consecutivos. Si queda un hueco más de
+// outboxGapWait, lo salta.
+func (d *OutboxDispatcher) advance() {
+	for {
+		for {
+			if _, ok := d.processed[d.cursor+1]; !ok {
+				break
+			}
+			delete(d.processed, d.cursor+1)
+			d.cursor++
+		}
+		if len(d.processed) == 0 {
+			d.gapSince = time.Time{}
+			return
+		}
+		if d.gapSince.IsZero() {
+			d.gapSince = time.Now()
+			return
+		}
+		if time.Since(d.gapSince) < outboxGapWait {
+			return
+		}
+
+		next := int64(-1)
+		for id := range d.processed {
+			if next < 0 || id < next {
+				next = id
+			}
+		}
+		logger.Warnf(outboxServiceComponent, "Los mensajes %d a %d del outbox no aparecieron en %s, se omiten",
+			d.cursor+1, next-1, outboxGapWait)
+		d.cursor = next - 1
+		d.gapSince = time.Time{}
+	}
+}
+
+// CleanupOutbox borra los mensajes del outbox con más de retention.
+func CleanupOutbox(retention time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		deleted, err := queries.DeleteOldOutbox(retention)
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			logger.Infof(outboxServiceComponent, "Outbox: %d mensajes antiguos borrados", deleted)
+		}
+		return nil
+	}
+}
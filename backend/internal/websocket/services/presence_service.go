@@ -28,11 +28,14 @@ El PresenceService es responsable de gestionar el estado de presencia de los usu
 package services
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
 
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
 	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
 	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
@@ -42,6 +45,9 @@ import (
 var (
 	presenceDB      *sql.DB
 	presenceManager *customws.ConnectionManager[wsmodels.WsUserData]
+
+	// presenceTTL es el tiempo sin heartbeat tras el cual el reaper marca offline a un usuario.
+	presenceTTL = 90 * time.Second
 )
 
 // InitializePresenceService permite inyectar la dependencia de la base de datos.
@@ -52,10 +58,24 @@ var (
 // O mejor aún, pasamos el db a las funciones Handle directamente si no hay estado en el servicio.
 // Por consistencia con InitializeChatService, haré una función de inicialización.
 
-func InitializePresenceService(database *sql.DB, manager *customws.ConnectionManager[wsmodels.WsUserData]) {
+// InitializePresenceService inyecta la BD y el ConnectionManager. Al iniciar reconcilia la
+// tabla Online: las presencias que quedaron en online sin heartbeat reciente (por una caída
+// del proceso) pasan a offline.
+func InitializePresenceService(database *sql.DB, manager *customws.ConnectionManager[wsmodels.WsUserData], cfg *config.Config) {
 	presenceDB = database
 	presenceManager = manager
+	if cfg.PresenceTTL > 0 {
+		presenceTTL = cfg.PresenceTTL
+	}
+	if interval := cfg.PresenceHeartbeatInterval; interval > 0 && presenceTTL < 2*interval {
+		logger.Warnf("SERVICE_PRESENCE", "PRESENCE_TTL (%s) es menor que dos heartbeats; se usa %s", presenceTTL, 3*interval)
+		presenceTTL = 3 * interval
+	}
 	logger.Info("SERVICE_PRESENCE", "PresenceService inicializado con conexión a BD.")
+
+	if err := ReapStalePresence(context.Background()); err != nil {
+		logger.Errorf("SERVICE_PRESENCE", "Error reconciliando la presencia al iniciar: %v", err)
+	}
 }
 
 // HandleUserConnect se llama cuando un usuario se conecta.
@@ -68,6 +88,12 @@ func HandleUserConnect(userID int64, username string, manager *customws.Connecti
 	}
 	logger.Infof("SERVICE_PRESENCE", "User connected: ID %d, Username: %s. Processing presence update.", userID, username)
 
+	// Con varios dispositivos el usuario ya estaba online: no se repite la notificación a contactos.
+	if devices := manager.ConnectionCount(userID); devices > 1 {
+		logger.Infof("SERVICE_PRESENCE", "UserID %d ya estaba online, nuevo dispositivo conectado (%d en total)", userID, devices)
+		return nil
+	}
+
 	// Actualizar estado a online
 	err := queries.SetUserOnlineStatus(userID, true)
 	if err != nil {
@@ -122,6 +148,12 @@ func HandleUserDisconnect(userID int64, username string, manager *customws.Conne
 	}
 	logger.Infof("SERVICE_PRESENCE", "User disconnected: ID %d, Username: %s. Error (if any): %v. Processing presence update.", userID, username, discErr)
 
+	// El usuario sigue online mientras le quede algún dispositivo conectado.
+	if manager.IsUserOnline(userID) {
+		logger.Infof("SERVICE_PRESENCE", "UserID %d sigue online en %d dispositivo(s)", userID, manager.ConnectionCount(userID))
+		return
+	}
+
 	// Actualizar estado a offline
 	err := queries.SetUserOnlineStatus(userID, false)
 	if err != nil {
@@ -144,16 +176,19 @@ func HandleUserDisconnect(userID int64, username string, manager *customws.Conne
 		}
 
 		if len(onlineContactIDs) > 0 {
+			payload := map[string]interface{}{
+				"eventType": "user_offline",
+				"userId":    userID,
+				"username":  username,
+			}
+			if lastSeenVisibleToContacts(userID) {
+				payload["lastSeen"] = lastSeenTimestamp
+			}
 			presenceMsg := types.ServerToClientMessage{
 				PID:        manager.Callbacks().GeneratePID(),
 				Type:       types.MessageTypePresenceEvent,
 				FromUserID: userID,
-				Payload: map[string]interface{}{
-					"eventType": "user_offline",
-					"userId":    userID,
-					"username":  username,
-					"lastSeen":  lastSeenTimestamp,
-				},
+				Payload:    payload,
 			}
 			errsMap := manager.BroadcastToUsers(onlineContactIDs, presenceMsg)
 			if len(errsMap) > 0 {
@@ -188,3 +223,126 @@ func GetConnection(userID int64) (*customws.Connection[wsmodels.WsUserData], boo
 	// Obtener la conexión del usuario desde el manager
 	return presenceManager.GetConnection(userID)
 }
+
+// PresenceHeartbeat renueva LastSeenAt de los usuarios conectados a esta instancia. Se
+// registra como job periódico cada PRESENCE_HEARTBEAT_INTERVAL.
+func PresenceHeartbeat(ctx context.Context) error {
+	if presenceManager == nil {
+		return nil
+	}
+	return queries.TouchOnlineUsers(presenceManager.OnlineUserIDs(), time.Now())
+}
+
+// ReapStalePresence marca offline a los usuarios sin heartbeat en el último PRESENCE_TTL y
+// avisa a sus contactos conectados a esta instancia, con el último heartbeat como última
+// conexión. Los usuarios que siguen conectados aquí se vuelven a marcar online.
+func ReapStalePresence(ctx context.Context) error {
+	if presenceManager == nil {
+		return nil
+	}
+	reaped, err := queries.MarkStaleUsersOffline(time.Now().Add(-presenceTTL))
+	if err != nil || len(reaped) == 0 {
+		return err
+	}
+
+	var stillConnected, offline []int64
+	for _, userID := range reaped {
+		if presenceManager.IsUserOnline(userID) {
+			stillConnected = append(stillConnected, userID)
+			continue
+		}
+		offline = append(offline, userID)
+	}
+	if len(offline) > 0 {
+		lastSeen, err := queries.GetLastSeenByIDs(offline)
+		if err != nil {
+			// Se avisa igualmente a los contactos, sin la última conexión.
+			logger.Errorf("SERVICE_PRESENCE", "Error obteniendo la última conexión de %d usuario(s) caducados: %v", len(offline), err)
+		}
+		for _, userID := range offline {
+			notifyContactsOffline(userID, lastSeen[userID])
+		}
+	}
+	if len(stillConnected) > 0 {
+		if err := queries.TouchOnlineUsers(stillConnected, time.Now()); err != nil {
+			return err
+		}
+	}
+	logger.Infof("SERVICE_PRESENCE", "Reaper de presencia: %d usuario(s) sin heartbeat marcados offline", len(reaped)-len(stillConnected))
+	return nil
+}
+
+// notifyContactsOffline envía user_offline a los contactos conectados de un usuario cuya
+// presencia caducó. lastSeen se incluye si existe y el usuario la muestra a sus contactos.
+func notifyContactsOffline(userID int64, lastSeen queries.LastSeenRecord) {
+	contactUserIDs, err := queries.GetUserContactIDs(userID)
+	if err != nil {
+		logger.Errorf("SERVICE_PRESENCE", "Error obteniendo IDs de contacto para UserID %d: %v", userID, err)
+		return
+	}
+	var onlineContactIDs []int64
+	for _, contactID := range contactUserIDs {
+		if presenceManager.IsUserOnline(contactID) {
+			onlineContactIDs = append(onlineContactIDs, contactID)
+		}
+	}
+	if len(onlineContactIDs) == 0 {
+		return
+	}
+	payload := map[string]interface{}{
+		"eventType": "user_offline",
+		"userId":    userID,
+	}
+	if !lastSeen.LastSeenAt.IsZero() && lastSeen.Visibility.VisibleTo(true) {
+		payload["lastSeen"] = lastSeen.LastSeenAt.UnixMilli()
+	}
+	presenceMsg := types.ServerToClientMessage{
+		PID:        presenceManager.Callbacks().GeneratePID(),
+		Type:       types.MessageTypePresenceEvent,
+		FromUserID: userID,
+		Payload:    payload,
+	}
+	if errsMap := presenceManager.BroadcastToUsers(onlineContactIDs, presenceMsg); len(errsMap) > 0 {
+		logger.Warnf("SERVICE_PRESENCE", "Errores difundiendo estado offline caducado de UserID %d: %v", userID, errsMap)
+	}
+}
+
+// lastSeenVisibleToContacts indica si el usuario muestra su última conexión a sus contactos.
+// Si no se puede leer su preferencia, se oculta.
+func lastSeenVisibleToContacts(userID int64) bool {
+	settings, err := queries.GetPrivacySettings(userID)
+	if err != nil {
+		logger.Errorf("SERVICE_PRESENCE", "Error obteniendo la privacidad de UserID %d: %v", userID, err)
+		return false
+	}
+	return settings.LastSeenVisibility.VisibleTo(true)
+}
+
+// LastSeenVisibleTo devuelve la última conexión de userID (Unix en milisegundos) si viewerID
+// puede verla según la preferencia de userID, o 0 si no puede, si userID está conectado o si
+// nunca se conectó.
+func LastSeenVisibleTo(userID, viewerID int64) int64 {
+	if userID == viewerID || (presenceManager != nil && presenceManager.IsUserOnline(userID)) {
+		return 0
+	}
+	records, err := queries.GetLastSeenByIDs([]int64{userID})
+	if err != nil {
+		logger.Errorf("SERVICE_PRESENCE", "Error obteniendo la última conexión de UserID %d: %v", userID, err)
+		return 0
+	}
+	record, ok := records[userID]
+	if !ok || record.Visibility == models.LastSeenNobody {
+		return 0
+	}
+	isContact := false
+	if record.Visibility == models.LastSeenContacts {
+		if isContact, err = queries.AreAcceptedContacts(userID, viewerID); err != nil {
+			logger.Errorf("SERVICE_PRESENCE", "Error comprobando el contacto entre %d y %d: %v", userID, viewerID, err)
+			return 0
+		}
+	}
+	if !record.Visibility.VisibleTo(isContact) {
+		return 0
+	}
+	return record.LastSeenAt.UnixMilli()
+}
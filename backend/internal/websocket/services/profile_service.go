@@ -52,9 +52,9 @@ func GetUserProfileData(userID int64, currentUserID int64, manager *customws.Con
 		profileData.LastName = safeNullString(userData.LastName)
 		profileData.UserName = userData.UserName
 		profileData.Email = userData.Email
-		profileData.Phone = safeNullString(userData.Phone)
+		profileData.Phone = safeNullString(userData.Phone.NullString)
 		profileData.Sex = safeNullString(userData.Sex)
-		profileData.DocId = safeNullString(userData.DocId)
+		profileData.DocId = safeNullString(userData.DocId.NullString)
 		if userData.NationalityId.Valid {
 			profileData.NationalityId = int(userData.NationalityId.Int32)
 		}
@@ -145,10 +145,13 @@ func GetUserProfileData(userID int64, currentUserID int64, manager *customws.Con
 		return nil
 	})
 
-	// 3. Obtener estado de conexión
+	// 3. Obtener estado de conexión y, si está desconectado, la última conexión que comparte
 	if manager != nil {
 		profileData.IsOnline = manager.IsUserOnline(userID)
 	}
+	if !profileData.IsOnline {
+		profileData.LastSeen = LastSeenVisibleTo(userID, currentUserID)
+	}
 
 	// 4. Obtener estadísticas de reputación
 	g.Go(func() error {
@@ -161,10 +164,21 @@ func GetUserProfileData(userID int64, currentUserID int64, manager *customws.Con
 		return nil
 	})
 
-	// 5. Obtener lista de reseñas
+	// 5. Insignia de estudiante o egresado verificado
+	g.Go(func() error {
+		badge, err := queries.GetStudentBadge(userID)
+		if err != nil {
+			logger.Warnf("SERVICE_PROFILE", "Error obteniendo la insignia de estudiante para UserID %d: %v", userID, err)
+			return nil // No es un error fatal
+		}
+		profileData.StudentBadge = badge
+		return nil
+	})
+
+	// 6. Obtener lista de reseñas
 	g.Go(func() error {
 		// Lógica condicional basada en el rol del perfil solicitado
-		if targetRoleID == 3 { // Es una empresa, obtener reseñas de usuarios
+		if targetRoleID == int(models.RoleBusiness) { // Es una empresa, obtener reseñas de usuarios
 			reviewsDB, err := queries.GetReputationReviewsForCompanyByUserID(userID)
 			if err != nil {
 				logger.Warnf("SERVICE_PROFILE", "Error obteniendo reseñas para CompanyID %d: %v", userID, err)
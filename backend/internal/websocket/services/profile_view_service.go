@@ -0,0 +1,81 @@
+package services
+
+import (
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// profileViewDedupWindow es el tiempo durante el cual las visitas repetidas de un mismo
+// usuario a un perfil cuentan como una sola.
+const profileViewDedupWindow = time.Hour
+
+// RecordProfileView registra la visita de viewer al perfil profileUserID y, si el visitante
+// es una empresa, avisa al estudiante en tiempo real. Solo se registran visitas a perfiles
+// de estudiantes y egresados, y se respetan las preferencias de privacidad de ambos: el
+// visitado puede desactivar el registro y el visitante puede ocultar su identidad.
+// Los errores solo se registran en el log: no deben afectar a la respuesta del perfil.
+func RecordProfileView(viewerID int64, viewerRoleID int, profileUserID int64, manager *customws.ConnectionManager[wsmodels.WsUserData]) {
+	if viewerID == profileUserID {
+		return
+	}
+	profileRoleID, err := queries.GetUserRoleByID(profileUserID)
+	if err != nil {
+		logger.Warnf("SERVICE_PROFILE_VIEW", "No se pudo obtener el rol del usuario %d: %v", profileUserID, err)
+		return
+	}
+	if role := models.UserRole(profileRoleID); role != models.RoleStudent && role != models.RoleEgresado {
+		return
+	}
+
+	profileSettings, err := queries.GetPrivacySettings(profileUserID)
+	if err != nil {
+		logger.Errorf("SERVICE_PROFILE_VIEW", "%v", err)
+		return
+	}
+	if !profileSettings.TrackProfileViews {
+		return
+	}
+
+	viewerSettings, err := queries.GetPrivacySettings(viewerID)
+	if err != nil {
+		logger.Errorf("SERVICE_PROFILE_VIEW", "%v", err)
+		return
+	}
+	anonymous := !viewerSettings.ShareProfileViews
+
+	now := time.Now()
+	recorded, err := queries.InsertProfileView(profileUserID, viewerID, viewerRoleID, anonymous, now.Add(-profileViewDedupWindow))
+	if err != nil {
+		logger.Errorf("SERVICE_PROFILE_VIEW", "%v", err)
+		return
+	}
+	if !recorded || models.UserRole(viewerRoleID) != models.RoleBusiness {
+		return
+	}
+
+	event := models.ProfileViewedEvent{Anonymous: anonymous, ViewedAt: now.UTC()}
+	if !anonymous {
+		event.CompanyId = viewerID
+		if name, err := queries.GetCompanyNameByID(viewerID); err == nil {
+			event.CompanyName = name
+		}
+	}
+
+	msg := types.ServerToClientMessage{
+		Type:    types.MessageTypeProfileViewed,
+		Payload: event,
+	}
+	if !anonymous {
+		msg.FromUserID = viewerID
+	}
+	if err := manager.SendMessageToUser(profileUserID, msg); err != nil {
+		// El estudiante no está conectado: verá la visita en sus estadísticas.
+		logger.Debugf("SERVICE_PROFILE_VIEW", "No se pudo avisar al usuario %d de la visita de %d: %v", profileUserID, viewerID, err)
+	}
+}
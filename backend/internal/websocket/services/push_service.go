@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	apiservices "github.com/davidM20/micro-service-backend-go.git/internal/services"
+	"github.com/davidM20/micro-service-backend-go.git/internal/websocket/wsmodels"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/push"
+)
+
+const pushServiceComponent = "SERVICE_PUSH"
+
+// pushPreviewMaxLength es la longitud máxima, en caracteres, del texto de un mensaje de chat
+// en la notificación push.
+const pushPreviewMaxLength = 100
+
+// pushEventCategories asocia cada tipo de notificación que se envía por push con su
+// categoría de preferencias. Los tipos que no aparecen solo se ven en la aplicación.
+var pushEventCategories = map[string]string{
+	models.EventTypeFriendRequest:            models.PushCategoryContactRequests,
+	models.EventTypeRequestResponse:          models.PushCategoryContactRequests,
+	apiservices.EventTypeCommentCreated:      models.PushCategoryCommunity,
+	apiservices.EventTypeCommentReply:        models.PushCategoryCommunity,
+	apiservices.EventTypePostLiked:           models.PushCategoryCommunity,
+	"COMPANY_REVIEW_PENDING":                 models.PushCategoryCommunity,
+	"REVIEW_CREATED_BY_STUDENT":              models.PushCategoryCommunity,
+	apiservices.EventTypeChallengeSubmission: models.PushCategoryChallenges,
+	apiservices.EventTypeChallengeReviewed:   models.PushCategoryChallenges,
+	apiservices.EventTypeChallengeCompleted:  models.PushCategoryChallenges,
+	apiservices.EventTypeChallengeClosed:     models.PushCategoryChallenges,
+	apiservices.EventTypeChallengeCancelled:  models.PushCategoryChallenges,
+	"NEW_JOB_APPLICATION":                    models.PushCategoryJobApplications,
+	models.EventTypeSavedSearchMatch:         models.PushCategorySavedSearches,
+	models.EventTypeReportResolved:           models.PushCategoryAccount,
+	models.EventTypeModerationWarning:        models.PushCategoryAccount,
+	models.EventTypeModerationSuspended:      models.PushCategoryAccount,
+	models.EventTypeImageRemoved:             models.PushCategoryAccount,
+	models.EventTypeAccountDeactivated:       models.PushCategoryAccount,
+	models.EventTypeRoleChanged:              models.PushCategoryAccount,
+	models.EventTypeSecurityNewDevice:        models.PushCategoryAccount,
+	models.EventTypeSecurityNewCountry:       models.PushCategoryAccount,
+}
+
+var (
+	// pushSenders contiene un Sender por cada proveedor nativo configurado.
+	pushSenders = map[string]push.Sender{}
+	// webPushSender envía a navegadores. nil = Web Push deshabilitado.
+	webPushSender *push.WebPushSender
+	pushTimeout   = 5 * time.Second
+)
+
+// InitializePushService crea los senders de FCM, APNs y Web Push con las credenciales de la
+// configuración. Un proveedor sin credenciales o con credenciales inválidas queda
+// deshabilitado y sus dispositivos no reciben notificaciones.
+func InitializePushService(cfg *config.Config) {
+	if cfg.PushTimeout > 0 {
+		pushTimeout = cfg.PushTimeout
+	}
+	if cfg.PushFCMCredentialsFile != "" {
+		sender, err := push.NewFCMSender(cfg.PushFCMCredentialsFile, pushTimeout)
+		if err != nil {
+			logger.Errorf(pushServiceComponent, "FCM deshabilitado: %v", err)
+		} else {
+			pushSenders[push.ProviderFCM] = sender
+		}
+	}
+	if cfg.PushAPNsKeyFile != "" {
+		sender, err := push.NewAPNsSender(cfg.PushAPNsKeyFile, cfg.PushAPNsKeyID, cfg.PushAPNsTeamID, cfg.PushAPNsTopic, cfg.PushAPNsSandbox, pushTimeout)
+		if err != nil {
+			logger.Errorf(pushServiceComponent, "APNs deshabilitado: %v", err)
+		} else {
+			pushSenders[push.ProviderAPNs] = sender
+		}
+	}
+	if cfg.PushVAPIDPrivateKey != "" {
+		sender, err := push.NewWebPushSender(cfg.PushVAPIDPrivateKey, cfg.PushVAPIDSubject, pushTimeout)
+		if err != nil {
+			logger.Errorf(pushServiceComponent, "Web Push deshabilitado: %v", err)
+		} else {
+			webPushSender = sender
+		}
+	}
+	logger.Infof(pushServiceComponent, "Servicio de push inicializado. Proveedores nativos activos: %d, Web Push: %t", len(pushSenders), webPushSender != nil)
+}
+
+// pushDisabled indica que no hay ningún proveedor configurado.
+func pushDisabled() bool {
+	return len(pushSenders) == 0 && webPushSender == nil
+}
+
+// sendPush envía la notificación a los dispositivos del usuario si sus preferencias permiten
+// la categoría y no está en su horario de No molestar.
+func sendPush(userID int64, category string, notification push.Notification) {
+	if pushDisabled() {
+		return
+	}
+	prefs, err := queries.GetNotificationPreferences(userID)
+	if err != nil {
+		logger.Errorf(pushServiceComponent, "%v", err)
+		return
+	}
+	if prefs.Allows(category) && !suppressedByDnd(userID, prefs, category) {
+		sendToDevices(userID, notification)
+	}
+}
+
+// sendToDevices envía la notificación a todos los dispositivos móviles del usuario. Si el
+// usuario no tiene ninguno con un proveedor activo (solo usa la aplicación desde el
+// navegador) se envía por Web Push. Los tokens y suscripciones rechazados se dan de baja.
+func sendToDevices(userID int64, notification push.Notification) {
+	devices, err := queries.GetPushDevices(userID)
+	if err != nil {
+		logger.Errorf(pushServiceComponent, "%v", err)
+		return
+	}
+
+	nativeDevices := 0
+	for _, device := range devices {
+		sender, ok := pushSenders[device.Provider]
+		if !ok {
+			continue
+		}
+		nativeDevices++
+		ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+		err := sender.Send(ctx, device.Token, notification)
+		cancel()
+		switch {
+		case errors.Is(err, push.ErrInvalidToken):
+			logger.Infof(pushServiceComponent, "Token %s del dispositivo %d (UserID %d) rechazado; se da de baja", device.Provider, device.Id, userID)
+			if err := queries.DeletePushDeviceByToken(device.Token); err != nil {
+				logger.Errorf(pushServiceComponent, "%v", err)
+			}
+		case err != nil:
+			logger.Warnf(pushServiceComponent, "Error enviando push al dispositivo %d de UserID %d: %v", device.Id, userID, err)
+		}
+	}
+	if nativeDevices == 0 {
+		sendToBrowsers(userID, notification)
+	}
+}
+
+// sendToBrowsers envía la notificación a las suscripciones Web Push del usuario.
+func sendToBrowsers(userID int64, notification push.Notification) {
+	if webPushSender == nil {
+		return
+	}
+	subscriptions, err := queries.GetWebPushSubscriptions(userID)
+	if err != nil {
+		logger.Errorf(pushServiceComponent, "%v", err)
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+		err := webPushSender.Send(ctx, push.WebPushSubscription{
+			Endpoint: subscription.Endpoint,
+			P256dh:   subscription.P256dh,
+			Auth:     subscription.Auth,
+		}, notification)
+		cancel()
+		switch {
+		case errors.Is(err, push.ErrInvalidToken):
+			logger.Infof(pushServiceComponent, "Suscripción web push %d (UserID %d) caducada; se da de baja", subscription.Id, userID)
+			if err := queries.DeleteWebPushSubscriptionByEndpoint(subscription.Endpoint); err != nil {
+				logger.Errorf(pushServiceComponent, "%v", err)
+			}
+		case err != nil:
+			logger.Warnf(pushServiceComponent, "Error enviando web push a la suscripción %d de UserID %d: %v", subscription.Id, userID, err)
+		}
+	}
+}
+
+// pushChatMessage avisa por push de un mensaje a un destinatario sin conexión. Si el
+// destinatario desactivó las vistas previas no se incluye el texto. Durante su horario de No
+// molestar no se envía.
+func pushChatMessage(recipientID int64, message *wsmodels.MessageDB) {
+	if pushDisabled() {
+		return
+	}
+	prefs, err := queries.GetNotificationPreferences(recipientID)
+	if err != nil {
+		logger.Errorf(pushServiceComponent, "%v", err)
+		return
+	}
+	if !prefs.Allows(models.PushCategoryChat) || suppressedByDnd(recipientID, prefs, models.PushCategoryChat) {
+		return
+	}
+
+	body := "Te envió un mensaje"
+	switch {
+	case prefs.ChatPreviews && message.Content != nil:
+		body = truncatePreview(*message.Content)
+	case message.MediaId != nil:
+		body = "Te envió un archivo adjunto"
+	}
+
+	chatKey, data := "", map[string]string{"type": "chat_message", "messageId": message.Id}
+	if message.ChatId != nil {
+		chatKey, data["chatId"] = *message.ChatId, *message.ChatId
+	}
+	if message.ChatIdGroup != nil {
+		chatKey, data["chatIdGroup"] = *message.ChatIdGroup, *message.ChatIdGroup
+	}
+	senderName, err := queries.GetUserDisplayName(message.SenderId)
+	if err != nil || senderName == "" {
+		senderName = "Nuevo mensaje"
+	}
+	notification := push.Notification{
+		Title:    senderName,
+		Body:     body,
+		Data:     data,
+		ThreadID: chatKey,
+	}
+	// La insignia del icono es el total de mensajes sin leer de sus chats privados.
+	if unread, err := queries.GetUnreadMessagesTotal(recipientID); err != nil {
+		logger.Warnf(pushServiceComponent, "%v", err)
+	} else {
+		notification.Badge = &unread
+	}
+	sendToDevices(recipientID, notification)
+}
+
+func truncatePreview(text string) string {
+	if utf8.RuneCountInString(text) <= pushPreviewMaxLength {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:pushPreviewMaxLength-1]) + "…"
+}
+
+// PushPublisher envía por push las notificaciones (tabla Event) de los usuarios sin
+// conexión. Las notificaciones se crean tanto en la API REST como en este servidor, así que
+// el publisher consulta periódicamente la tabla Event desde el último ID procesado.
+type PushPublisher struct {
+	manager *customws.ConnectionManager[wsmodels.WsUserData]
+
+	mu          sync.Mutex
+	lastID      int64
+	initialized bool
+}
+
+// NewPushPublisher crea el publisher. Publish debe registrarse como job periódico.
+func NewPushPublisher(manager *customws.ConnectionManager[wsmodels.WsUserData]) *PushPublisher {
+	return &PushPublisher{manager: manager}
+}
+
+// Publish envía las notificaciones creadas desde la última ejecución a los usuarios sin
+// conexión. La primera ejecución solo toma el último ID como punto de partida.
+func (p *PushPublisher) Publish(ctx context.Context) error {
+	if pushDisabled() {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	maxID, err := queries.GetMaxEventID()
+	if err != nil {
+		return err
+	}
+	if !p.initialized {
+		p.lastID, p.initialized = maxID, true
+		return nil
+	}
+	if maxID <= p.lastID {
+		return nil
+	}
+
+	eventTypes := make([]string, 0, len(pushEventCategories))
+	for eventType := range pushEventCategories {
+		eventTypes = append(eventTypes, eventType)
+	}
+	events, err := queries.GetEventsByType(p.lastID, maxID, eventTypes)
+	if err != nil {
+		return err
+	}
+	p.lastID = maxID
+
+	for _, event := range events {
+		if p.manager.IsUserOnline(event.UserId) {
+			continue
+		}
+		data := map[string]string{
+			"type":      "notification",
+			"eventId":   strconv.FormatInt(event.Id, 10),
+			"eventType": event.EventType,
+		}
+		go sendPush(event.UserId, pushEventCategories[event.EventType], push.Notification{
+			Title: event.EventTitle,
+			Body:  event.Description,
+			Data:  data,
+		})
+	}
+	return nil
+}
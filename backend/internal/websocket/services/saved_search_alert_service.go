@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/internal/config"
+	"github.com/davidM20/micro-service-backend-go.git/internal/db/queries"
+	"github.com/davidM20/micro-service-backend-go.git/internal/models"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const savedSearchAlertComponent = "SERVICE_SAVED_SEARCH_ALERT"
+
+const (
+	// savedSearchAlertBatchSize es cuántas búsquedas se cargan por consulta.
+	savedSearchAlertBatchSize = 100
+	// savedSearchAlertMaxItems es el máximo de elementos que se listan en una alerta.
+	savedSearchAlertMaxItems = 20
+)
+
+// SavedSearchAlertJob avisa a los usuarios de las publicaciones y personas nuevas que
+// coinciden con sus búsquedas guardadas. Es incremental: cada búsqueda guarda el mayor ID ya
+// evaluado y en cada ejecución solo se comparan los elementos posteriores. El aviso es una
+// notificación SAVED_SEARCH_MATCH, que llega por push y en el resumen por correo.
+type SavedSearchAlertJob struct {
+	// peopleDelay es cuánto se espera a que un usuario nuevo complete su perfil (formación,
+	// habilidades) antes de compararlo con las búsquedas.
+	peopleDelay time.Duration
+}
+
+// NewSavedSearchAlertJob crea el job. Devuelve nil si las alertas están deshabilitadas.
+func NewSavedSearchAlertJob(cfg *config.Config) *SavedSearchAlertJob {
+	if cfg.SavedSearchAlertInterval <= 0 {
+		return nil
+	}
+	return &SavedSearchAlertJob{peopleDelay: max(cfg.SavedSearchPeopleDelay, 0)}
+}
+
+// Run evalúa las búsquedas con elementos nuevos. Se registra como job periódico.
+func (j *SavedSearchAlertJob) Run(ctx context.Context) error {
+	alerts := 0
+	for _, searchType := range []string{models.SavedSearchTypeJobs, models.SavedSearchTypePeople} {
+		sent, err := j.runType(ctx, searchType)
+		alerts += sent
+		if err != nil {
+			return err
+		}
+	}
+	if alerts > 0 {
+		logger.Infof(savedSearchAlertComponent, "Alertas de búsquedas guardadas creadas: %d", alerts)
+	}
+	return nil
+}
+
+// runType evalúa las búsquedas de un tipo hasta el último elemento existente. Devuelve cuántas
+// alertas creó.
+func (j *SavedSearchAlertJob) runType(ctx context.Context, searchType string) (int, error) {
+	maxItemID, err := queries.GetSavedSearchMaxItemID(searchType, time.Now().Add(-j.peopleDelay))
+	if err != nil {
+		return 0, err
+	}
+
+	alerts := 0
+	var afterID int64
+	for {
+		searches, err := queries.GetSavedSearchesToEvaluate(searchType, maxItemID, afterID, savedSearchAlertBatchSize)
+		if err != nil {
+			return alerts, err
+		}
+		for _, search := range searches {
+			if ctx.Err() != nil {
+				return alerts, nil
+			}
+			afterID = search.Id
+			alerted, err := j.evaluate(search, maxItemID)
+			if err != nil {
+				logger.Warnf(savedSearchAlertComponent, "No se pudo evaluar la búsqueda %d: %v", search.Id, err)
+				continue
+			}
+			if alerted {
+				alerts++
+			}
+		}
+		if len(searches) < savedSearchAlertBatchSize {
+			return alerts, nil
+		}
+	}
+}
+
+// evaluate busca las coincidencias de una búsqueda hasta maxItemID, avanza su marca y, si hubo
+// coincidencias, crea la notificación. Devuelve true si avisó al usuario.
+func (j *SavedSearchAlertJob) evaluate(search models.SavedSearch, maxItemID int64) (bool, error) {
+	// Se pide uno más para saber si hay más coincidencias de las que se listan.
+	ids, err := queries.FindSavedSearchMatches(search, maxItemID, savedSearchAlertMaxItems+1)
+	if err != nil {
+		return false, err
+	}
+	claimed, err := queries.AdvanceSavedSearch(search.Id, search.LastSeenItemId, maxItemID, len(ids) > 0)
+	if err != nil || !claimed || len(ids) == 0 {
+		return false, err
+	}
+
+	hasMore := len(ids) > savedSearchAlertMaxItems
+	if hasMore {
+		ids = ids[:savedSearchAlertMaxItems]
+	}
+	metadata, err := json.Marshal(map[string]interface{}{
+		"savedSearchId": search.Id,
+		"searchType":    search.SearchType,
+		"itemIds":       ids,
+		"hasMore":       hasMore,
+	})
+	if err != nil {
+		return false, fmt.Errorf("error al serializar la alerta: %w", err)
+	}
+	notification := models.Event{
+		EventType:   models.EventTypeSavedSearchMatch,
+		EventTitle:  fmt.Sprintf("Novedades en \"%s\"", search.Name),
+		Description: savedSearchAlertDescription(search.SearchType, len(ids), hasMore),
+		UserId:      search.UserId,
+		Metadata:    metadata,
+	}
+	if err := queries.CreateEvent(&notification); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// savedSearchAlertDescription describe cuántos elementos nuevos coinciden con la búsqueda.
+func savedSearchAlertDescription(searchType string, count int, hasMore bool) string {
+	singular, plural := "publicación nueva coincide", "publicaciones nuevas coinciden"
+	if searchType == models.SavedSearchTypePeople {
+		singular, plural = "persona nueva coincide", "personas nuevas coinciden"
+	}
+	switch {
+	case hasMore:
+		return fmt.Sprintf("Más de %d %s con tu búsqueda", count, plural)
+	case count == 1:
+		return fmt.Sprintf("1 %s con tu búsqueda", singular)
+	default:
+		return fmt.Sprintf("%d %s con tu búsqueda", count, plural)
+	}
+}
@@ -12,7 +12,7 @@ import (
 )
 
 type SearchService interface {
-	SearchAll(currentUserID int64, searchTerm string, limit, offset int) ([]wsmodels.SearchResultItem, error)
+	SearchAll(currentUserID, tenantID int64, searchTerm string, limit, offset int) ([]wsmodels.SearchResultItem, error)
 }
 
 type searchService struct {
@@ -23,9 +23,9 @@ func NewSearchService(db *sql.DB) SearchService {
 	return &searchService{db: db}
 }
 
-func (s *searchService) SearchAll(currentUserID int64, searchTerm string, limit, offset int) ([]wsmodels.SearchResultItem, error) {
+func (s *searchService) SearchAll(currentUserID, tenantID int64, searchTerm string, limit, offset int) ([]wsmodels.SearchResultItem, error) {
 	// 1. Llamar a la consulta de la base de datos
-	users, err := queries.SearchAll(currentUserID, searchTerm, limit, offset)
+	users, err := queries.SearchAll(currentUserID, tenantID, searchTerm, limit, offset)
 	if err != nil {
 		logger.Errorf("SEARCH_SERVICE", "Error al buscar 'all': %v", err)
 		return nil, fmt.Errorf("error al realizar la búsqueda: %w", err)
@@ -46,7 +46,7 @@ func (s *searchService) mapUserToSearchResult(user models.User) wsmodels.SearchR
 		ID: strconv.FormatInt(user.Id, 10),
 	}
 
-	if user.RoleId == 3 { // Empresa
+	if user.RoleId == int(models.RoleBusiness) { // Empresa
 		item.Type = "company"
 		item.Data = wsmodels.CompanySearchResultData{
 			Name:     user.CompanyName.String,
@@ -59,7 +59,7 @@ func (s *searchService) mapUserToSearchResult(user models.User) wsmodels.SearchR
 		}
 	} else { // Estudiante o Egresado
 		item.Type = "student"
-		if user.RoleId == 2 {
+		if user.RoleId == int(models.RoleEgresado) {
 			item.Type = "graduate"
 		}
 		item.Data = wsmodels.UserSearchResultData{
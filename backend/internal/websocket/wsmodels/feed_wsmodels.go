@@ -1,5 +1,7 @@
 package wsmodels
 
+import "github.com/davidM20/micro-service-backend-go.git/pkg/pagination"
+
 /*
  * ===================================================
  * MODELOS DE DATOS PARA EL FEED WEBSOCKET
@@ -21,7 +23,7 @@ package wsmodels
  * USO:
  * ----
  * Estos modelos son utilizados por FeedService para construir la respuesta
- * y por FeedHandler para enviarla al cliente.
+ * y por el handler del feed para enviarla al cliente.
  */
 
 // FeedItem representa un elemento genérico en el feed.
@@ -44,6 +46,7 @@ type StudentFeedData struct {
 	UserName      string   `json:"userName"`
 	ContactStatus string   `json:"contactStatus"` // Puede ser 'pending', 'accepted', 'rejected', o "" (vacío)
 	HasContact    bool     `json:"hasContact"`
+	IsOnline      bool     `json:"isOnline"`
 }
 
 // CompanyFeedData contiene los datos específicos para un item del feed de tipo "company".
@@ -56,6 +59,7 @@ type CompanyFeedData struct {
 	UserID      int64  `json:"userId"`
 	UserName    string `json:"userName"`
 	HasContact  bool   `json:"hasContact"`
+	IsOnline    bool   `json:"isOnline"`
 }
 
 // EventFeedData contiene los datos específicos para un item del feed de tipo "event".
@@ -70,6 +74,11 @@ type EventFeedData struct {
 	PostType    string `json:"postType"` // Diferenciar entre 'EVENTO', 'DESAFIO', 'ARTICULO', etc.
 	EventID     int64  `json:"eventId"`
 	UserID      int64  `json:"userId"`
+
+	LikeCount      int64 `json:"likeCount"`
+	CommentCount   int64 `json:"commentCount"`
+	LikedByMe      bool  `json:"likedByMe"`      // El usuario que pide el feed le dio "me gusta"
+	BookmarkedByMe bool  `json:"bookmarkedByMe"` // El usuario que pide el feed la guardó
 }
 
 // PaginationInfo contiene detalles sobre la paginación de una lista.
@@ -80,9 +89,16 @@ type PaginationInfo struct {
 }
 
 // FeedListResponsePayload es el payload para la respuesta de la lista de feed.
+// Si el cliente envía en ifNoneMatch el ETag de la página que ya tiene y no cambió, la
+// respuesta lleva notModified y el ETag, sin items.
+//
+// Lleva el sobre de pagination.Page (items, nextCursor, hasMore). Pagination solo se incluye
+// en la paginación por número de página, que está obsoleta.
 type FeedListResponsePayload struct {
-	Items      []FeedItem      `json:"items"`
-	Pagination *PaginationInfo `json:"pagination"`
+	pagination.Page[FeedItem]
+	Pagination  *PaginationInfo `json:"pagination,omitempty"`
+	ETag        string          `json:"etag,omitempty"`
+	NotModified bool            `json:"notModified,omitempty"`
 }
 
 // FeedItemViewRef es una referencia a un item del feed que ha sido visto.
@@ -0,0 +1,58 @@
+package wsmodels
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Tópicos a los que pueden suscribirse los clientes con los mensajes subscribe/unsubscribe
+// (ver customws.SubscriptionManager). Los tópicos con prefijo "admin:" solo están
+// disponibles para administradores y los "user:{id}:..." solo para ese usuario.
+const (
+	// TopicAdminMetrics recibe periódicamente las métricas del servidor (admin_metrics).
+	TopicAdminMetrics = "admin:metrics"
+
+	topicAdminPrefix = "admin:"
+	topicUserPrefix  = "user:"
+	topicEventPrefix = "event:"
+
+	topicCommentsSuffix = ":comments"
+)
+
+// EventCommentsTopic devuelve el tópico por el que se publican los comentarios nuevos de
+// una publicación de la comunidad (new_comment).
+func EventCommentsTopic(eventID int64) string {
+	return topicEventPrefix + strconv.FormatInt(eventID, 10) + topicCommentsSuffix
+}
+
+// ParseEventCommentsTopic devuelve la publicación de un tópico "event:{id}:comments".
+func ParseEventCommentsTopic(topic string) (eventID int64, ok bool) {
+	if !strings.HasPrefix(topic, topicEventPrefix) || !strings.HasSuffix(topic, topicCommentsSuffix) {
+		return 0, false
+	}
+	segment := strings.TrimSuffix(strings.TrimPrefix(topic, topicEventPrefix), topicCommentsSuffix)
+	eventID, err := strconv.ParseInt(segment, 10, 64)
+	if err != nil || eventID <= 0 {
+		return 0, false
+	}
+	return eventID, true
+}
+
+// IsAdminTopic indica si el tópico está reservado a administradores.
+func IsAdminTopic(topic string) bool {
+	return strings.HasPrefix(topic, topicAdminPrefix)
+}
+
+// TopicOwner devuelve el usuario dueño de un tópico "user:{id}:...". isUserTopic es false
+// si el tópico no es de usuario; si lo es pero el ID no es válido se devuelve 0.
+func TopicOwner(topic string) (userID int64, isUserTopic bool) {
+	if !strings.HasPrefix(topic, topicUserPrefix) {
+		return 0, false
+	}
+	segment, _, _ := strings.Cut(strings.TrimPrefix(topic, topicUserPrefix), ":")
+	userID, err := strconv.ParseInt(segment, 10, 64)
+	if err != nil {
+		return 0, true
+	}
+	return userID, true
+}
@@ -12,6 +12,18 @@ type WsUserData struct {
 	UserID   int64
 	Username string
 	RoleId   int
+	// IP y dispositivo de la conexión, para la vista de conexiones del panel de administración.
+	// Se toman de la sesión del login y, si faltan, de la petición de conexión WS.
+	IP     string
+	Device models.SessionDevice
+	// Suplantación: si la conexión usa un token de suplantación del soporte, ImpersonatorID
+	// es el administrador y el cliente muestra un aviso hasta ImpersonationExpiresAt.
+	ImpersonatorID         int64
+	ImpersonationID        int64
+	ImpersonationExpiresAt time.Time
+	// TenantID es la institución de la conexión (ver docs/multi_institucion.md); 0 si no tiene o el
+	// soporte multi-institución está desactivado.
+	TenantID int64
 	// Podríamos añadir más datos aquí si son frecuentemente necesarios
 	// y queremos evitar consultas repetidas a la BD en cada mensaje.
 	// Por ejemplo: Roles, Email.
@@ -32,6 +44,24 @@ type ChatInfo struct {
 	UnreadCount           int    `json:"unreadCount,omitempty"`           // Número de mensajes no leídos por el usuario actual en este chat
 	IsOtherOnline         bool   `json:"isOnline"`                        // Estado de conexión del otro usuario
 	Type                  string `json:"type,omitempty"`                  // Tipo de chat (contact, company, group)
+	IsBlocked             bool   `json:"isBlocked,omitempty"`             // Hay un bloqueo entre los participantes: el chat está congelado
+	LastSeen              int64  `json:"lastSeen,omitempty"`              // Última conexión (Unix en milisegundos) del otro participante desconectado, si la comparte
+}
+
+// ChatListDelta es la respuesta de chat/get_list con syncToken (chat_list_delta): los chats
+// que cambiaron desde el token y los que salieron de la lista. Con Full, Chats es la lista
+// completa y el cliente descarta la que tenía.
+type ChatListDelta struct {
+	Full      bool          `json:"full"`
+	Chats     []ChatInfo    `json:"chats"`
+	Removed   []ChatRemoved `json:"removed"`
+	SyncToken string        `json:"syncToken"` // Token para la siguiente sincronización
+}
+
+// ChatRemoved identifica un chat que salió de la lista del usuario.
+type ChatRemoved struct {
+	ChatID      string `json:"chatId"`
+	OtherUserID int64  `json:"otherUserId"`
 }
 
 // NotificationInfo representa una notificación para el usuario.
@@ -51,6 +81,39 @@ type NotificationInfo struct {
 	OtherUserId    int64       `json:"otherUserId,omitempty"`    // OtherUserId de la tabla Event (directamente)
 	ProyectId      int64       `json:"proyectId,omitempty"`      // ProyectId de la tabla Event (directamente)
 	GroupId        int64       `json:"groupId,omitempty"`        // GroupId de la tabla Event (directamente)
+	// Group está presente si la notificación agrupa varios eventos similares (varios "me
+	// gusta" de la misma publicación, por ejemplo).
+	Group *NotificationGroupInfo `json:"group,omitempty"`
+}
+
+// NotificationGroupInfo resume los eventos de una notificación agrupada.
+type NotificationGroupInfo struct {
+	Count           int           `json:"count"`                     // Total de eventos agrupados
+	Actors          []ProfileData `json:"actors,omitempty"`          // Últimos usuarios que los originaron, del más reciente al más antiguo
+	NotificationIds []string      `json:"notificationIds,omitempty"` // Todas las notificaciones del grupo, para marcarlas con notification/mark_read_bulk
+}
+
+// SystemAnnouncementPayload es el payload de system_announcement, el anuncio que un
+// administrador envía a todos los usuarios conectados o a los de un rol.
+type SystemAnnouncementPayload struct {
+	ID        int64      `json:"id"`
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	Level     string     `json:"level"`               // INFO, WARNING o CRITICAL
+	SentAt    time.Time  `json:"sentAt"`              // Momento del envío
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"` // El cliente deja de mostrarlo a partir de aquí
+}
+
+// MaintenancePayload es el payload de maintenance. Con Active el servidor cerrará las
+// conexiones (código 1013) en ClosesInSeconds y rechazará las nuevas con 503 hasta que termine
+// el mantenimiento; el cliente puede reintentar pasados RetryAfterSeconds. Active false indica
+// que el mantenimiento se canceló o terminó.
+type MaintenancePayload struct {
+	Active            bool      `json:"active"`
+	Message           string    `json:"message,omitempty"`
+	ClosesInSeconds   int       `json:"closesInSeconds,omitempty"`
+	DrainAt           time.Time `json:"drainAt,omitempty"`
+	RetryAfterSeconds int       `json:"retryAfterSeconds,omitempty"`
 }
 
 // ProfileData representa la información completa del perfil de un usuario.
@@ -81,8 +144,11 @@ type ProfileData struct {
 	UpdatedAt          time.Time               `json:"updatedAt"`
 	Curriculum         CurriculumVitae         `json:"curriculum"`
 	IsOnline           bool                    `json:"isOnline,omitempty"`
+	LastSeen           int64                   `json:"lastSeen,omitempty"` // Última conexión (Unix en milisegundos) si está desconectado y quien consulta puede verla
 	Reputation         *models.ReputationStats `json:"reputation,omitempty"`
 	Reviews            []ReputationReviewItem  `json:"reviews,omitempty"`
+	// StudentBadge es la insignia de estudiante o egresado verificado con el correo institucional.
+	StudentBadge *models.StudentBadge `json:"studentBadge,omitempty"`
 }
 
 // CurriculumVitae agrupa las secciones del currículum de un usuario.
@@ -208,6 +274,11 @@ type MessageDB struct {
 	SentAt           string  `json:"sentAt"`                     // Timestamp ISO8601 UTC del envío.
 	EditedAt         *string `json:"editedAt,omitempty"`         // Timestamp ISO8601 UTC de la última edición.
 	Status           string  `json:"status"`                     // Estado: 'sending', 'sent', 'delivered', 'read', 'failed'.
+	IsHidden         bool    `json:"isHidden,omitempty"`         // Oculto por moderación; Content y MediaId quedan nulos.
+	ClientMessageId  *string `json:"clientMessageId,omitempty"`  // Id generado por el cliente al enviarlo, si lo indicó.
+
+	ForwardedFromMessageId *string `json:"forwardedFromMessageId,omitempty"` // Mensaje original si es un reenvío.
+	ForwardedFromSenderId  *int64  `json:"forwardedFromSenderId,omitempty"`  // Autor del mensaje original si es un reenvío.
 }
 
 // WsMessage es una estructura genérica para los mensajes WebSocket salientes.
@@ -218,6 +289,19 @@ type WsMessage struct {
 	Payload interface{} `json:"payload"`
 }
 
+// IsImpersonated indica si la conexión usa un token de suplantación.
+func (d WsUserData) IsImpersonated() bool {
+	return d.ImpersonatorID != 0
+}
+
+// ImpersonationPayload es el payload de MessageTypeImpersonation, enviado al conectar con un
+// token de suplantación para que el cliente muestre el aviso.
+type ImpersonationPayload struct {
+	ImpersonationID int64     `json:"impersonationId"`
+	ImpersonatorID  int64     `json:"impersonatorId"`
+	ExpiresAt       time.Time `json:"expiresAt"`
+}
+
 // DashboardDataPayload representa los datos para el dashboard de administración.
 type DashboardDataPayload struct {
 	ActiveUsers          int64           `json:"activeUsers"`
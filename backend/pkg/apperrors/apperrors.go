@@ -0,0 +1,482 @@
+// Package apperrors define el catálogo de códigos de error compartido por la API REST
+// y el servidor WebSocket.
+//
+// Cada código (ej. "AUTH_004") tiene asociado un status HTTP. En REST el error se
+// devuelve como JSON:
+//
+//	{"error": "Invalid credentials", "errorCode": "AUTH_004"}
+//
+// y en WebSocket como ErrorPayload, con el status en "code" y el código del catálogo
+// en "errorCode". Los clientes deben decidir por errorCode, nunca por el texto.
+//
+// Los errores de dominio se declaran como sentinels con New, de modo que errors.Is
+// sigue funcionando y los handlers pueden responder con WriteError / From sin
+// mantener su propio mapeo a status HTTP.
+package apperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/validation"
+)
+
+// Code identifica un tipo de error del catálogo.
+type Code string
+
+// Genéricos
+const (
+	InvalidBody   Code = "GEN_001" // El cuerpo de la petición no es JSON válido
+	MissingFields Code = "GEN_002" // Faltan campos obligatorios
+	InvalidParam  Code = "GEN_003" // Parámetro de ruta o query con formato inválido
+	NotFound      Code = "GEN_004" // Recurso no encontrado
+	Internal      Code = "GEN_005" // Error interno del servidor
+	Conflict      Code = "GEN_006" // Conflicto con el estado actual del recurso
+	BodyTooLarge  Code = "GEN_007" // El cuerpo de la petición supera el tamaño máximo de la ruta
+	Timeout       Code = "GEN_008" // El cliente no terminó de enviar la petición a tiempo
+	Maintenance   Code = "GEN_009" // El servicio está en modo mantenimiento
+	InvalidFields Code = "GEN_010" // Uno o más campos del cuerpo no cumplen la especificación
+	ReadOnly      Code = "GEN_011" // La base de datos está en modo solo lectura
+	Draining      Code = "GEN_012" // La instancia WebSocket está drenando sus conexiones
+)
+
+// Autenticación y autorización
+const (
+	Unauthenticated    Code = "AUTH_001" // No hay usuario autenticado en la petición
+	MissingToken       Code = "AUTH_002" // No se envió el token
+	InvalidToken       Code = "AUTH_003" // Token inválido o expirado
+	InvalidCredentials Code = "AUTH_004" // Email o contraseña incorrectos
+	Forbidden          Code = "AUTH_005" // Sin permisos para la operación
+	AdminRequired      Code = "AUTH_006" // La operación requiere rol de administrador
+	SessionExpired     Code = "AUTH_007" // La sesión no existe o expiró
+	AccountExists      Code = "AUTH_008" // Email, usuario o RIF ya registrados
+	DocumentIdTaken    Code = "AUTH_009" // Documento de identidad ya registrado
+	WeakPassword       Code = "AUTH_010" // La contraseña no cumple la política
+	InvalidResetCode   Code = "AUTH_011" // Código de restablecimiento inválido o expirado
+	AccountSuspended   Code = "AUTH_012" // La cuenta fue suspendida por moderación
+	AccountDeactivated Code = "AUTH_013" // La cuenta fue desactivada por un administrador
+)
+
+// WebSocket
+const (
+	InvalidPayload      Code = "WS_001" // El payload no supera la validación
+	UnsupportedMessage  Code = "WS_002" // Tipo de mensaje desconocido
+	UnsupportedResource Code = "WS_003" // Recurso o acción no soportados
+	MissingResource     Code = "WS_004" // data_request sin recurso
+	HandlerUnavailable  Code = "WS_005" // El handler no está inicializado
+	InvalidTopic        Code = "WS_006" // Nombre de tópico inválido
+	SubscriptionLimit   Code = "WS_007" // La conexión alcanzó el máximo de suscripciones
+	MessageTooLarge     Code = "WS_008" // El mensaje supera el tamaño máximo de su tipo
+	InvalidChunk        Code = "WS_009" // Fragmento (chunk) inválido o fuera de orden
+	ChunkTimeout        Code = "WS_010" // No llegaron todos los fragmentos a tiempo
+	InvalidPID          Code = "WS_011" // PID demasiado largo o con caracteres no permitidos
+)
+
+// Chat
+const (
+	ChatIdRequired   Code = "CHAT_001" // Falta el chatId
+	EmptyMessage     Code = "CHAT_002" // Mensaje sin texto ni adjunto
+	ChatNotFound     Code = "CHAT_003" // El chat no existe
+	MessageBlocked   Code = "CHAT_004" // El filtro de contenido bloqueó el mensaje
+	ChatFrozen       Code = "CHAT_005" // Uno de los participantes bloqueó al otro
+	ForwardNotFound  Code = "CHAT_006" // El mensaje a reenviar no existe o no es accesible
+	ChatHistoryError Code = "CHAT_010" // Error al obtener el historial
+	ChatSendError    Code = "CHAT_011" // Error al guardar o enviar el mensaje
+)
+
+// Retención de mensajes
+const (
+	RetentionDisabled      Code = "RET_001" // La retención está deshabilitada
+	RetentionRunActive     Code = "RET_002" // Ya hay una ejecución en curso
+	RetentionOptOutMissing Code = "RET_003" // El chat no tiene opt-out
+)
+
+// Reputación
+const (
+	ReviewInvalid         Code = "REP_001" // Datos de la reseña inválidos
+	ReviewSelf            Code = "REP_002" // Un usuario no puede calificarse a sí mismo
+	ReviewNoInteraction   Code = "REP_003" // No hubo interacción en el evento indicado
+	ReviewAlreadyExists   Code = "REP_004" // Ya existe una reseña para ese usuario y evento
+	ReputationUserMissing Code = "REP_005" // Usuario no encontrado
+)
+
+// Privacidad (exportación y borrado de datos personales)
+const (
+	PrivacyRequestActive   Code = "PRIV_001" // Ya hay una solicitud en curso
+	PrivacyRequestNotFound Code = "PRIV_002" // La solicitud no existe
+	PrivacyInvalidPassword Code = "PRIV_003" // Contraseña de confirmación incorrecta
+	PrivacyInvalidMode     Code = "PRIV_004" // Modo de borrado no válido
+	DataExportNotReady     Code = "PRIV_005" // La exportación aún no está lista
+	DataExportExpired      Code = "PRIV_006" // La exportación expiró
+)
+
+// Exportación de conversaciones
+const (
+	ChatExportInvalid     Code = "CEXP_001" // Falta el chatId o el formato no es válido
+	ChatExportActive      Code = "CEXP_002" // Ya hay una exportación en curso de ese chat
+	ChatExportNotFound    Code = "CEXP_003" // La exportación no existe
+	ChatExportUnavailable Code = "CEXP_004" // El almacenamiento de exportaciones no está configurado
+)
+
+// Multimedia y notas de voz
+const (
+	VoiceNoteInvalid  Code = "MEDIA_001" // El archivo no es una nota de voz WebM o M4A legible
+	VoiceNoteTooLong  Code = "MEDIA_002" // La nota de voz supera la duración máxima
+	MediaNotFound     Code = "MEDIA_003" // El archivo no existe o no tienes acceso a él
+	MediaRangeInvalid Code = "MEDIA_004" // El rango pedido no es satisfacible
+	MediaUnavailable  Code = "MEDIA_005" // El almacenamiento de archivos no está disponible
+)
+
+// Subidas reanudables
+const (
+	UploadSessionInvalid  Code = "UPL_001" // Nombre o tamaño inválidos, o la parte excede el tamaño declarado
+	UploadSessionNotFound Code = "UPL_002" // La subida no existe
+	UploadOffsetMismatch  Code = "UPL_003" // Upload-Offset no coincide con los bytes recibidos
+	UploadIncomplete      Code = "UPL_004" // Se pidió finalizar antes de recibir todos los bytes
+	UploadSessionClosed   Code = "UPL_005" // La subida ya terminó, se canceló o caducó
+	UploadSessionLimit    Code = "UPL_006" // Demasiadas subidas en curso
+)
+
+// Cuotas de almacenamiento
+const (
+	StorageQuotaExceeded Code = "QUOTA_001" // La subida supera la cuota de almacenamiento del usuario
+	StorageQuotaInvalid  Code = "QUOTA_002" // Cuota negativa
+)
+
+// Análisis de malware de las subidas
+const (
+	UploadQuarantined     Code = "SCAN_001" // El archivo contiene malware y se puso en cuarentena
+	UploadScanUnavailable Code = "SCAN_002" // El motor de análisis no está disponible
+)
+
+// Comentarios de publicaciones
+const (
+	CommentInvalid  Code = "COM_001" // Contenido del comentario vacío o demasiado largo
+	CommentNotFound Code = "COM_002" // El comentario no existe o fue eliminado
+)
+
+// Entregas de desafíos
+const (
+	ChallengeInvalid           Code = "CHL_001" // La publicación no es un desafío o la entrega es inválida
+	ChallengeClosed            Code = "CHL_002" // El desafío no admite entregas ni revisiones
+	SubmissionNotFound         Code = "CHL_003" // La entrega no existe
+	ChallengeInvalidTransition Code = "CHL_004" // Transición de estado del desafío no permitida
+	SubmissionLocked           Code = "CHL_005" // La entrega ya fue revisada y no se puede reemplazar
+)
+
+// Moderación
+const (
+	ReportInvalid           Code = "MOD_001" // Tipo, motivo o contenido del reporte inválidos
+	ReportNotFound          Code = "MOD_002" // El reporte no existe
+	ReportDuplicate         Code = "MOD_003" // El usuario ya reportó ese contenido
+	ReportAlreadyClosed     Code = "MOD_004" // El reporte o la revisión de la imagen ya se cerró
+	ModerationActionInvalid Code = "MOD_005" // Acción de moderación no aplicable al contenido
+	ReportContextInvalid    Code = "MOD_006" // El reporte no es de un mensaje o el mensaje ya no existe
+	ReportContextExists     Code = "MOD_007" // El contexto del reporte ya fue capturado
+	ImageRejected           Code = "MOD_008" // La moderación automática rechazó la imagen
+	ImageModerationDown     Code = "MOD_009" // El clasificador de imágenes no está disponible
+)
+
+// Filtro de contenido del chat
+const (
+	FilterRuleInvalid   Code = "FLT_001" // Idioma, tipo, patrón, categoría o severidad inválidos
+	FilterRuleNotFound  Code = "FLT_002" // La regla no existe
+	FilterRuleDuplicate Code = "FLT_003" // Ya existe una regla con ese patrón para el idioma
+	FilterPolicyInvalid Code = "FLT_004" // Acción o umbral de la política inválidos
+)
+
+// Notificaciones push
+const (
+	PushDeviceInvalid           Code = "PUSH_001" // Proveedor o token del dispositivo inválidos
+	PushDeviceNotFound          Code = "PUSH_002" // El dispositivo no existe
+	WebPushDisabled             Code = "PUSH_003" // Web Push no está configurado en el servidor
+	WebPushSubscriptionInvalid  Code = "PUSH_004" // Suscripción Web Push incompleta o inválida
+	WebPushSubscriptionNotFound Code = "PUSH_005" // La suscripción Web Push no existe
+	DndScheduleInvalid          Code = "PUSH_006" // Horario de No molestar inválido (hora o zona horaria)
+)
+
+// Gestión de usuarios (administración)
+const (
+	UserRoleInvalid         Code = "USR_001" // El rol no existe
+	UserStatusInvalid       Code = "USR_002" // El estado no existe o tiene su propia operación
+	UserSelfManagement      Code = "USR_003" // Un administrador no puede cambiar su propio rol o estado
+	UserAlreadyDeactivated  Code = "USR_004" // La cuenta ya está desactivada
+	UserNotDeactivated      Code = "USR_005" // La cuenta no está desactivada
+	ImpersonationForbidden  Code = "USR_006" // La cuenta no se puede suplantar
+	ImpersonationEnded      Code = "USR_007" // La suplantación ya terminó o expiró
+	ImpersonationNotAllowed Code = "USR_008" // La operación no se puede hacer con un token de suplantación
+)
+
+// Catálogos (nacionalidades, universidades y carreras)
+const (
+	CatalogInvalid   Code = "CAT_001" // Nombre, código ISO, formato de documento o universidad inválidos
+	CatalogNotFound  Code = "CAT_002" // El elemento del catálogo no existe
+	CatalogDuplicate Code = "CAT_003" // Ya existe un elemento con ese nombre o código
+	CatalogInUse     Code = "CAT_004" // El elemento está asignado a usuarios o tiene carreras
+)
+
+// Recomendaciones (requisitos de ofertas y afinidad de candidatos)
+const (
+	JobRequirementsInvalid  Code = "MATCH_001" // Habilidades, carreras, idiomas o experiencia inválidos
+	JobRequirementsNotFound Code = "MATCH_002" // La publicación no tiene requisitos
+)
+
+// Búsquedas guardadas
+const (
+	SavedSearchInvalid  Code = "SRCH_001" // Nombre, tipo, palabras clave o filtros inválidos
+	SavedSearchNotFound Code = "SRCH_002" // La búsqueda guardada no existe o es de otro usuario
+	SavedSearchLimit    Code = "SRCH_003" // El usuario alcanzó el máximo de búsquedas guardadas
+)
+
+// Bloqueos entre usuarios
+const (
+	BlockInvalid       Code = "BLK_001" // No se puede bloquear a uno mismo
+	BlockedInteraction Code = "BLK_002" // Hay un bloqueo entre los dos usuarios
+)
+
+// Verificación de estudiantes con el correo institucional
+const (
+	StudentVerificationForbidden   Code = "STV_001" // Solo estudiantes y egresados pueden verificarse
+	StudentEmailInvalid            Code = "STV_002" // Correo inválido o de un dominio sin universidad
+	StudentEmailTaken              Code = "STV_003" // El correo ya verificó otra cuenta
+	StudentCodeInvalid             Code = "STV_004" // Código incorrecto, caducado o sin solicitar
+	StudentCodeLimit               Code = "STV_005" // Demasiados intentos o códigos solicitados seguidos
+	StudentVerificationUnavailable Code = "STV_006" // El envío de correos no está configurado
+)
+
+// Multi-institución
+const (
+	TenantMismatch      Code = "TEN_001" // La cuenta pertenece a otra institución que la del subdominio
+	TenantAdminRequired Code = "TEN_002" // La operación requiere un administrador global
+	TenantInvalid       Code = "TEN_003" // La institución no existe
+)
+
+// statusByCode asocia cada código con su status HTTP.
+var statusByCode = map[Code]int{
+	InvalidBody:   http.StatusBadRequest,
+	MissingFields: http.StatusBadRequest,
+	InvalidParam:  http.StatusBadRequest,
+	NotFound:      http.StatusNotFound,
+	Internal:      http.StatusInternalServerError,
+	Conflict:      http.StatusConflict,
+	BodyTooLarge:  http.StatusRequestEntityTooLarge,
+	Timeout:       http.StatusRequestTimeout,
+	Maintenance:   http.StatusServiceUnavailable,
+	InvalidFields: http.StatusBadRequest,
+	ReadOnly:      http.StatusServiceUnavailable,
+	Draining:      http.StatusServiceUnavailable,
+
+	Unauthenticated:    http.StatusUnauthorized,
+	MissingToken:       http.StatusUnauthorized,
+	InvalidToken:       http.StatusUnauthorized,
+	InvalidCredentials: http.StatusUnauthorized,
+	Forbidden:          http.StatusForbidden,
+	AdminRequired:      http.StatusForbidden,
+	SessionExpired:     http.StatusUnauthorized,
+	AccountExists:      http.StatusConflict,
+	DocumentIdTaken:    http.StatusConflict,
+	WeakPassword:       http.StatusBadRequest,
+	InvalidResetCode:   http.StatusBadRequest,
+	AccountSuspended:   http.StatusForbidden,
+	AccountDeactivated: http.StatusForbidden,
+
+	InvalidPayload:      http.StatusBadRequest,
+	UnsupportedMessage:  http.StatusBadRequest,
+	UnsupportedResource: http.StatusBadRequest,
+	MissingResource:     http.StatusBadRequest,
+	HandlerUnavailable:  http.StatusInternalServerError,
+	InvalidTopic:        http.StatusBadRequest,
+	SubscriptionLimit:   http.StatusBadRequest,
+	MessageTooLarge:     http.StatusRequestEntityTooLarge,
+	InvalidChunk:        http.StatusBadRequest,
+	ChunkTimeout:        http.StatusRequestTimeout,
+	InvalidPID:          http.StatusBadRequest,
+
+	ChatIdRequired:   http.StatusBadRequest,
+	EmptyMessage:     http.StatusBadRequest,
+	ChatNotFound:     http.StatusNotFound,
+	MessageBlocked:   http.StatusUnprocessableEntity,
+	ChatFrozen:       http.StatusForbidden,
+	ForwardNotFound:  http.StatusNotFound,
+	ChatHistoryError: http.StatusInternalServerError,
+	ChatSendError:    http.StatusInternalServerError,
+
+	RetentionDisabled:      http.StatusBadRequest,
+	RetentionRunActive:     http.StatusConflict,
+	RetentionOptOutMissing: http.StatusNotFound,
+
+	ReviewInvalid:         http.StatusBadRequest,
+	ReviewSelf:            http.StatusBadRequest,
+	ReviewNoInteraction:   http.StatusForbidden,
+	ReviewAlreadyExists:   http.StatusConflict,
+	ReputationUserMissing: http.StatusNotFound,
+
+	PrivacyRequestActive:   http.StatusConflict,
+	PrivacyRequestNotFound: http.StatusNotFound,
+	PrivacyInvalidPassword: http.StatusForbidden,
+	PrivacyInvalidMode:     http.StatusBadRequest,
+	DataExportNotReady:     http.StatusConflict,
+	DataExportExpired:      http.StatusGone,
+
+	ChatExportInvalid:     http.StatusBadRequest,
+	ChatExportActive:      http.StatusConflict,
+	ChatExportNotFound:    http.StatusNotFound,
+	ChatExportUnavailable: http.StatusServiceUnavailable,
+
+	VoiceNoteInvalid:  http.StatusBadRequest,
+	VoiceNoteTooLong:  http.StatusBadRequest,
+	MediaNotFound:     http.StatusNotFound,
+	MediaRangeInvalid: http.StatusRequestedRangeNotSatisfiable,
+	MediaUnavailable:  http.StatusServiceUnavailable,
+
+	UploadSessionInvalid:  http.StatusBadRequest,
+	UploadSessionNotFound: http.StatusNotFound,
+	UploadOffsetMismatch:  http.StatusConflict,
+	UploadIncomplete:      http.StatusConflict,
+	UploadSessionClosed:   http.StatusGone,
+	UploadSessionLimit:    http.StatusTooManyRequests,
+
+	StorageQuotaExceeded: http.StatusRequestEntityTooLarge,
+	StorageQuotaInvalid:  http.StatusBadRequest,
+
+	UploadQuarantined:     http.StatusUnprocessableEntity,
+	UploadScanUnavailable: http.StatusServiceUnavailable,
+
+	CommentInvalid:  http.StatusBadRequest,
+	CommentNotFound: http.StatusNotFound,
+
+	ChallengeInvalid:           http.StatusBadRequest,
+	ChallengeClosed:            http.StatusConflict,
+	SubmissionNotFound:         http.StatusNotFound,
+	ChallengeInvalidTransition: http.StatusConflict,
+	SubmissionLocked:           http.StatusConflict,
+
+	ReportInvalid:           http.StatusBadRequest,
+	ReportNotFound:          http.StatusNotFound,
+	ReportDuplicate:         http.StatusConflict,
+	ReportAlreadyClosed:     http.StatusConflict,
+	ModerationActionInvalid: http.StatusBadRequest,
+	ReportContextInvalid:    http.StatusBadRequest,
+	ReportContextExists:     http.StatusConflict,
+	ImageRejected:           http.StatusUnprocessableEntity,
+	ImageModerationDown:     http.StatusServiceUnavailable,
+
+	FilterRuleInvalid:   http.StatusBadRequest,
+	FilterRuleNotFound:  http.StatusNotFound,
+	FilterRuleDuplicate: http.StatusConflict,
+	FilterPolicyInvalid: http.StatusBadRequest,
+
+	PushDeviceInvalid:           http.StatusBadRequest,
+	PushDeviceNotFound:          http.StatusNotFound,
+	WebPushDisabled:             http.StatusServiceUnavailable,
+	WebPushSubscriptionInvalid:  http.StatusBadRequest,
+	WebPushSubscriptionNotFound: http.StatusNotFound,
+	DndScheduleInvalid:          http.StatusBadRequest,
+
+	UserRoleInvalid:         http.StatusBadRequest,
+	UserStatusInvalid:       http.StatusBadRequest,
+	UserSelfManagement:      http.StatusForbidden,
+	UserAlreadyDeactivated:  http.StatusConflict,
+	UserNotDeactivated:      http.StatusConflict,
+	ImpersonationForbidden:  http.StatusForbidden,
+	ImpersonationEnded:      http.StatusConflict,
+	ImpersonationNotAllowed: http.StatusForbidden,
+
+	CatalogInvalid:   http.StatusBadRequest,
+	CatalogNotFound:  http.StatusNotFound,
+	CatalogDuplicate: http.StatusConflict,
+	CatalogInUse:     http.StatusConflict,
+
+	JobRequirementsInvalid:  http.StatusBadRequest,
+	JobRequirementsNotFound: http.StatusNotFound,
+
+	SavedSearchInvalid:  http.StatusBadRequest,
+	SavedSearchNotFound: http.StatusNotFound,
+	SavedSearchLimit:    http.StatusConflict,
+
+	BlockInvalid:       http.StatusBadRequest,
+	BlockedInteraction: http.StatusForbidden,
+
+	StudentVerificationForbidden:   http.StatusForbidden,
+	StudentEmailInvalid:            http.StatusBadRequest,
+	StudentEmailTaken:              http.StatusConflict,
+	StudentCodeInvalid:             http.StatusBadRequest,
+	StudentCodeLimit:               http.StatusTooManyRequests,
+	StudentVerificationUnavailable: http.StatusServiceUnavailable,
+
+	TenantMismatch:      http.StatusForbidden,
+	TenantAdminRequired: http.StatusForbidden,
+	TenantInvalid:       http.StatusBadRequest,
+}
+
+// Status devuelve el status HTTP de un código. Los códigos desconocidos son 500.
+func Status(code Code) int {
+	if status, ok := statusByCode[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Error es un error del catálogo con un mensaje apto para el cliente.
+type Error struct {
+	Code    Code
+	Message string
+}
+
+// New crea un error del catálogo. Pensado para declarar sentinels de dominio.
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Status devuelve el status HTTP del error.
+func (e *Error) Status() int {
+	return Status(e.Code)
+}
+
+// From extrae el *Error del catálogo contenido en err. Si err no contiene ninguno,
+// devuelve un error Internal con fallbackMessage, para no exponer detalles internos.
+func From(err error, fallbackMessage string) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return New(Internal, fallbackMessage)
+}
+
+// Response es el cuerpo JSON de una respuesta de error REST. Fields solo se incluye en los
+// errores de validación e indica qué campos fallaron y por qué.
+type Response struct {
+	Error     string                  `json:"error"`
+	ErrorCode Code                    `json:"errorCode"`
+	Fields    []validation.FieldError `json:"fields,omitempty"`
+}
+
+// Write responde la petición con el código y mensaje indicados.
+func Write(w http.ResponseWriter, code Code, message string) {
+	writeResponse(w, Response{Error: message, ErrorCode: code})
+}
+
+// WriteFields responde con el código y mensaje indicados y el detalle de los campos inválidos.
+func WriteFields(w http.ResponseWriter, code Code, message string, fields []validation.FieldError) {
+	writeResponse(w, Response{Error: message, ErrorCode: code, Fields: fields})
+}
+
+func writeResponse(w http.ResponseWriter, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(Status(resp.ErrorCode))
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// WriteError responde con el error del catálogo contenido en err, o con un error
+// Internal y fallbackMessage si no lo hay. El llamador es responsable de registrar
+// en el log los errores internos.
+func WriteError(w http.ResponseWriter, err error, fallbackMessage string) {
+	appErr := From(err, fallbackMessage)
+	Write(w, appErr.Code, appErr.Message)
+}
@@ -0,0 +1,244 @@
+// Package audiometa identifica el contenedor de una nota de voz (WebM o M4A) y extrae su
+// duración sin depender de ffprobe.
+//
+//	WebM  la duración se lee de Segment/Info/Duration. MediaRecorder la omite al grabar en
+//	      vivo; en ese caso se calcula con la marca de tiempo del último bloque.
+//	M4A   la duración se lee de moov/mvhd (duration / timescale).
+//
+// Probe solo recorre las cabeceras de los elementos, así que el coste es lineal en el
+// tamaño del archivo y no decodifica audio.
+package audiometa
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+)
+
+// Contenedores soportados.
+const (
+	FormatWebM = "webm"
+	FormatM4A  = "m4a"
+)
+
+var (
+	// ErrUnsupported indica que el archivo no es WebM ni M4A.
+	ErrUnsupported = errors.New("audiometa: contenedor no soportado")
+	// ErrNoDuration indica que el contenedor es válido pero no se pudo determinar la duración.
+	ErrNoDuration = errors.New("audiometa: duración no disponible")
+)
+
+// Info describe una nota de voz.
+type Info struct {
+	Format      string
+	ContentType string
+	Duration    time.Duration
+}
+
+// Probe identifica el contenedor de data y extrae su duración.
+func Probe(data []byte) (Info, error) {
+	switch {
+	case len(data) >= 4 && binary.BigEndian.Uint32(data) == ebmlHeaderID:
+		d, err := webmDuration(data)
+		return Info{Format: FormatWebM, ContentType: "audio/webm", Duration: d}, err
+	case len(data) >= 8 && string(data[4:8]) == "ftyp":
+		d, err := mp4Duration(data)
+		return Info{Format: FormatM4A, ContentType: "audio/mp4", Duration: d}, err
+	}
+	return Info{}, ErrUnsupported
+}
+
+// ---------------------------------------------------------------------------------
+// WebM (EBML)
+// ---------------------------------------------------------------------------------
+
+const (
+	ebmlHeaderID    = 0x1A45DFA3
+	segmentID       = 0x18538067
+	infoID          = 0x1549A966
+	timecodeScaleID = 0x2AD7B1
+	durationID      = 0x4489
+	clusterID       = 0x1F43B675
+	clusterTimeID   = 0xE7
+	blockGroupID    = 0xA0
+	blockID         = 0xA1
+	simpleBlockID   = 0xA3
+
+	defaultTimecodeScale = 1000000 // ns por unidad de marca de tiempo
+)
+
+// webmDuration recorre el archivo entrando "en el sitio" en los elementos contenedores que
+// interesan (Segment, Info, Cluster, BlockGroup) y saltando el resto. Así funciona también
+// con tamaños desconocidos, habituales en Segment y Cluster de grabaciones en vivo.
+func webmDuration(data []byte) (time.Duration, error) {
+	scale := uint64(defaultTimecodeScale)
+	var declared float64
+	var clusterTime, lastBlock int64
+	seenBlock := false
+
+	for pos := 0; pos < len(data); {
+		id, n := readVint(data[pos:], true)
+		if n == 0 {
+			break
+		}
+		pos += n
+		size, m := readVint(data[pos:], false)
+		if m == 0 {
+			break
+		}
+		pos += m
+		unknown := size == vintUnknown(m)
+
+		switch id {
+		case segmentID, infoID, clusterID, blockGroupID:
+			continue
+		}
+		if unknown || size > uint64(len(data)-pos) {
+			// Solo los contenedores pueden tener tamaño desconocido; un elemento truncado
+			// termina el recorrido con lo leído hasta aquí.
+			break
+		}
+		body := data[pos : pos+int(size)]
+		pos += int(size)
+
+		switch id {
+		case timecodeScaleID:
+			if v := readUint(body); v > 0 {
+				scale = v
+			}
+		case durationID:
+			declared = readFloat(body)
+		case clusterTimeID:
+			clusterTime = int64(readUint(body))
+		case simpleBlockID, blockID:
+			if _, t := readVint(body, false); t > 0 && len(body) >= t+2 {
+				ts := clusterTime + int64(int16(binary.BigEndian.Uint16(body[t:])))
+				if !seenBlock || ts > lastBlock {
+					lastBlock, seenBlock = ts, true
+				}
+			}
+		}
+	}
+
+	if declared > 0 && !math.IsInf(declared, 0) && !math.IsNaN(declared) {
+		return time.Duration(declared * float64(scale)), nil
+	}
+	if seenBlock && lastBlock > 0 {
+		return time.Duration(lastBlock) * time.Duration(scale), nil
+	}
+	return 0, ErrNoDuration
+}
+
+// readVint lee un entero de longitud variable de EBML y devuelve su valor y los bytes que
+// ocupa (0 si data no contiene uno válido). Los IDs conservan el bit marcador.
+func readVint(data []byte, keepMarker bool) (uint64, int) {
+	if len(data) == 0 || data[0] == 0 {
+		return 0, 0
+	}
+	length := 1
+	for mask := byte(0x80); data[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 8 || len(data) < length {
+		return 0, 0
+	}
+	value := uint64(data[0])
+	if !keepMarker {
+		value &= uint64(0xFF >> length)
+	}
+	for i := 1; i < length; i++ {
+		value = value<<8 | uint64(data[i])
+	}
+	return value, length
+}
+
+// vintUnknown es el valor reservado para "tamaño desconocido" en un vint de length bytes.
+func vintUnknown(length int) uint64 {
+	return 1<<(7*uint(length)) - 1
+}
+
+func readUint(data []byte) uint64 {
+	var v uint64
+	for _, b := range data {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
+
+func readFloat(data []byte) float64 {
+	switch len(data) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(data))
+	}
+	return 0
+}
+
+// ---------------------------------------------------------------------------------
+// M4A (ISO BMFF)
+// ---------------------------------------------------------------------------------
+
+func mp4Duration(data []byte) (time.Duration, error) {
+	moov, ok := findBox(data, "moov")
+	if !ok {
+		return 0, ErrNoDuration
+	}
+	mvhd, ok := findBox(moov, "mvhd")
+	if !ok || len(mvhd) < 4 {
+		return 0, ErrNoDuration
+	}
+
+	var timescale uint32
+	var duration uint64
+	switch mvhd[0] { // versión
+	case 0:
+		if len(mvhd) < 20 {
+			return 0, ErrNoDuration
+		}
+		timescale = binary.BigEndian.Uint32(mvhd[12:])
+		if d := binary.BigEndian.Uint32(mvhd[16:]); d != math.MaxUint32 {
+			duration = uint64(d)
+		}
+	case 1:
+		if len(mvhd) < 32 {
+			return 0, ErrNoDuration
+		}
+		timescale = binary.BigEndian.Uint32(mvhd[20:])
+		if d := binary.BigEndian.Uint64(mvhd[24:]); d != math.MaxUint64 {
+			duration = d
+		}
+	}
+	// Los MP4 fragmentados dejan la duración a 0 o a todo unos en mvhd.
+	if timescale == 0 || duration == 0 {
+		return 0, ErrNoDuration
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second)), nil
+}
+
+// findBox busca la caja boxType entre las cajas de primer nivel de data y devuelve su
+// contenido sin la cabecera.
+func findBox(data []byte, boxType string) ([]byte, bool) {
+	for pos := 0; pos+8 <= len(data); {
+		size := uint64(binary.BigEndian.Uint32(data[pos:]))
+		header := uint64(8)
+		switch size {
+		case 0: // hasta el final del archivo
+			size = uint64(len(data) - pos)
+		case 1: // tamaño de 64 bits
+			if pos+16 > len(data) {
+				return nil, false
+			}
+			size, header = binary.BigEndian.Uint64(data[pos+8:]), 16
+		}
+		if size < header || size > uint64(len(data)-pos) {
+			return nil, false
+		}
+		if string(data[pos+4:pos+8]) == boxType {
+			return data[pos+int(header) : pos+int(size)], true
+		}
+		pos += int(size)
+	}
+	return nil, false
+}
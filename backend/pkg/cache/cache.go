@@ -0,0 +1,284 @@
+// Package cache guarda resultados de consultas frecuentes en dos niveles: una LRU en memoria
+// en cada proceso y, opcionalmente, Redis compartido entre instancias.
+//
+// Las claves tienen la forma grupo:resto (p. ej. user_base:42); el grupo agrupa las métricas
+// de aciertos y fallos. Los valores se guardan en JSON, así que quien lee recibe siempre una
+// copia que puede modificar sin afectar a la caché.
+//
+// Con Redis, las entradas de la LRU viven como mucho LocalTTL: las invalidaciones de otra
+// instancia borran Redis pero no la memoria de las demás, y ese es el retraso máximo con que
+// lo notan. Si Redis falla, la caché sigue funcionando solo en memoria.
+//
+// Con ServeStale, Load entrega el último valor que quedó en memoria aunque haya caducado
+// cuando la consulta falla; así se siguen sirviendo datos con la BD en modo solo lectura.
+package cache
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// Options configura la caché.
+type Options struct {
+	MaxEntries    int           // Entradas de la LRU en memoria
+	LocalTTL      time.Duration // Vida máxima en memoria cuando hay Redis
+	RedisAddr     string        // host:puerto; vacío = solo memoria
+	RedisPassword string
+	RedisDB       int
+	RedisTimeout  time.Duration
+	// ServeStale indica si Load puede servir un valor caducado cuando load falla; nil = nunca.
+	ServeStale func() bool
+}
+
+// Cache es la caché de dos niveles. Un *Cache nil es válido y no guarda nada, para que el
+// código que la usa no tenga que comprobar si está activada.
+type Cache struct {
+	local      *LRU
+	remote     *Redis
+	localTTL   time.Duration
+	serveStale func() bool
+
+	mu            sync.Mutex
+	groups        map[string]*groupStats
+	remoteRetryAt time.Time // Tras un fallo de Redis no se vuelve a usar hasta este momento
+}
+
+// remoteRetryDelay es el tiempo que se deja de usar Redis tras un fallo, para que una caída no
+// sume el timeout de conexión a cada consulta.
+const remoteRetryDelay = 10 * time.Second
+
+type groupStats struct {
+	localHits     int64
+	remoteHits    int64
+	staleHits     int64
+	misses        int64
+	invalidations int64
+	errors        int64
+}
+
+// New crea la caché.
+func New(opts Options) *Cache {
+	c := &Cache{local: NewLRU(opts.MaxEntries), localTTL: opts.LocalTTL, serveStale: opts.ServeStale, groups: make(map[string]*groupStats)}
+	if opts.RedisAddr != "" {
+		c.remote = NewRedis(opts.RedisAddr, opts.RedisPassword, opts.RedisDB, opts.RedisTimeout)
+	}
+	return c
+}
+
+// Get devuelve el valor guardado en key, buscando primero en memoria y después en Redis.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	if value, ok := c.local.Get(key); ok {
+		c.record(key, func(s *groupStats) { s.localHits++ })
+		return value, true
+	}
+	if c.useRemote() {
+		value, found, err := c.remote.Get(key)
+		if err != nil {
+			c.remoteError("GET", key, err)
+		} else if found {
+			if c.localTTL > 0 {
+				c.local.Set(key, value, c.localTTL)
+			}
+			c.record(key, func(s *groupStats) { s.remoteHits++ })
+			return value, true
+		}
+	}
+	c.record(key, func(s *groupStats) { s.misses++ })
+	return nil, false
+}
+
+// Set guarda value en key durante ttl.
+func (c *Cache) Set(key string, value []byte, ttl time.Duration) {
+	if c == nil || ttl <= 0 {
+		return
+	}
+	localTTL := ttl
+	if c.remote != nil {
+		if c.useRemote() {
+			if err := c.remote.Set(key, value, ttl); err != nil {
+				c.remoteError("SET", key, err)
+			}
+		}
+		if c.localTTL > 0 && c.localTTL < localTTL {
+			localTTL = c.localTTL
+		}
+	}
+	c.local.Set(key, value, localTTL)
+}
+
+// Delete invalida las claves indicadas.
+func (c *Cache) Delete(keys ...string) {
+	if c == nil || len(keys) == 0 {
+		return
+	}
+	c.local.Delete(keys...)
+	if c.useRemote() {
+		if err := c.remote.Delete(keys...); err != nil {
+			c.remoteError("DEL", keys[0], err)
+		}
+	}
+	for _, key := range keys {
+		c.record(key, func(s *groupStats) { s.invalidations++ })
+	}
+}
+
+// DeletePrefix invalida todas las claves que empiezan por prefix (p. ej. un grupo entero).
+func (c *Cache) DeletePrefix(prefix string) {
+	if c == nil {
+		return
+	}
+	c.local.DeletePrefix(prefix)
+	if c.useRemote() {
+		if err := c.remote.DeletePrefix(prefix); err != nil {
+			c.remoteError("SCAN", prefix, err)
+		}
+	}
+	c.record(prefix, func(s *groupStats) { s.invalidations++ })
+}
+
+// Load devuelve el valor de key o, si no está, lo obtiene con load y lo guarda durante ttl.
+// Los errores de load no se guardan: si ServeStale lo permite se devuelve el valor caducado
+// que quede en memoria y, si no, el error.
+func Load[T any](c *Cache, key string, ttl time.Duration, load func() (T, error)) (T, error) {
+	if value, ok := GetJSON[T](c, key); ok {
+		return value, nil
+	}
+	value, err := load()
+	if err != nil {
+		if stale, ok := getStaleJSON[T](c, key); ok {
+			logger.Warnf("CACHE", "Se sirve %s caducado porque la consulta falló: %v", key, err)
+			return stale, nil
+		}
+		return value, err
+	}
+	SetJSON(c, key, value, ttl)
+	return value, nil
+}
+
+// getStaleJSON devuelve el valor de key que quede en memoria aunque haya caducado, si
+// ServeStale lo permite en este momento.
+func getStaleJSON[T any](c *Cache, key string) (T, bool) {
+	var value T
+	if c == nil || c.serveStale == nil || !c.serveStale() {
+		return value, false
+	}
+	data, ok := c.local.GetStale(key)
+	if !ok || json.Unmarshal(data, &value) != nil {
+		var zero T
+		return zero, false
+	}
+	c.record(key, func(s *groupStats) { s.staleHits++ })
+	return value, true
+}
+
+// GetJSON devuelve el valor de key decodificado. Una entrada que no se puede decodificar se
+// descarta y cuenta como ausente.
+func GetJSON[T any](c *Cache, key string) (T, bool) {
+	var value T
+	data, ok := c.Get(key)
+	if !ok {
+		return value, false
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		c.Delete(key)
+		var zero T
+		return zero, false
+	}
+	return value, true
+}
+
+// SetJSON guarda value codificado en JSON durante ttl.
+func SetJSON(c *Cache, key string, value any, ttl time.Duration) {
+	if c == nil {
+		return
+	}
+	if data, err := json.Marshal(value); err == nil {
+		c.Set(key, data, ttl)
+	}
+}
+
+// GroupStats son las métricas de un grupo de claves.
+type GroupStats struct {
+	Group         string  `json:"group"`
+	LocalHits     int64   `json:"localHits"`
+	RemoteHits    int64   `json:"remoteHits"`
+	StaleHits     int64   `json:"staleHits"` // Valores caducados servidos porque la consulta falló
+	Misses        int64   `json:"misses"`
+	HitRatio      float64 `json:"hitRatio"` // Aciertos (memoria + Redis) sobre el total de lecturas
+	Invalidations int64   `json:"invalidations"`
+	Errors        int64   `json:"errors"` // Fallos de Redis
+}
+
+// Stats es el estado de la caché.
+type Stats struct {
+	Enabled      bool         `json:"enabled"`
+	Redis        bool         `json:"redis"`
+	LocalEntries int          `json:"localEntries"`
+	Groups       []GroupStats `json:"groups"`
+}
+
+// Stats devuelve las métricas por grupo, ordenadas por nombre.
+func (c *Cache) Stats() Stats {
+	if c == nil {
+		return Stats{Groups: []GroupStats{}}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := Stats{Enabled: true, Redis: c.remote != nil, LocalEntries: c.local.Len(), Groups: make([]GroupStats, 0, len(c.groups))}
+	for name, g := range c.groups {
+		group := GroupStats{
+			Group:         name,
+			LocalHits:     g.localHits,
+			RemoteHits:    g.remoteHits,
+			StaleHits:     g.staleHits,
+			Misses:        g.misses,
+			Invalidations: g.invalidations,
+			Errors:        g.errors,
+		}
+		if reads := g.localHits + g.remoteHits + g.misses; reads > 0 {
+			group.HitRatio = float64(g.localHits+g.remoteHits) / float64(reads)
+		}
+		stats.Groups = append(stats.Groups, group)
+	}
+	sort.Slice(stats.Groups, func(i, j int) bool { return stats.Groups[i].Group < stats.Groups[j].Group })
+	return stats
+}
+
+// record actualiza las métricas del grupo de key.
+func (c *Cache) record(key string, update func(*groupStats)) {
+	group, _, _ := strings.Cut(key, ":")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats, ok := c.groups[group]
+	if !ok {
+		stats = &groupStats{}
+		c.groups[group] = stats
+	}
+	update(stats)
+}
+
+// useRemote indica si se consulta Redis: está configurado y no falló hace poco.
+func (c *Cache) useRemote() bool {
+	if c.remote == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Now().After(c.remoteRetryAt)
+}
+
+func (c *Cache) remoteError(command, key string, err error) {
+	c.record(key, func(s *groupStats) { s.errors++ })
+	c.mu.Lock()
+	c.remoteRetryAt = time.Now().Add(remoteRetryDelay)
+	c.mu.Unlock()
+	logger.Warnf("CACHE", "Redis %s %s falló, se usa solo la memoria durante %s: %v", command, key, remoteRetryDelay, err)
+}
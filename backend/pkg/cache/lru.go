@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LRU es una caché en memoria con un máximo de entradas: al llenarse descarta la usada hace
+// más tiempo. Cada entrada caduca según su TTL; las caducadas se conservan hasta que las
+// desplaza la capacidad, para poder servirlas con GetStale. Es segura para uso concurrente.
+type LRU struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // Frente = usada más recientemente
+	items      map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewLRU crea una LRU con capacidad para maxEntries entradas (mínimo 1).
+func NewLRU(maxEntries int) *LRU {
+	if maxEntries < 1 {
+		maxEntries = 1
+	}
+	return &LRU{maxEntries: maxEntries, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+// Get devuelve el valor de key si existe y no ha caducado.
+func (c *LRU) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// GetStale devuelve el valor de key aunque haya caducado.
+func (c *LRU) GetStale(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	return element.Value.(*lruEntry).value, true
+}
+
+// Set guarda value en key durante ttl.
+func (c *LRU) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expiresAt := time.Now().Add(ttl)
+	if element, ok := c.items[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value, entry.expiresAt = value, expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+// Delete elimina las claves indicadas.
+func (c *LRU) Delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if element, ok := c.items[key]; ok {
+			c.remove(element)
+		}
+	}
+}
+
+// DeletePrefix elimina todas las claves que empiezan por prefix.
+func (c *LRU) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, element := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(element)
+		}
+	}
+}
+
+// Len devuelve el número de entradas guardadas, incluidas las caducadas aún no descartadas.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*lruEntry).key)
+}
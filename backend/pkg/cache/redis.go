@@ -0,0 +1,233 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// redisPoolSize es el número máximo de conexiones inactivas que se reutilizan.
+const redisPoolSize = 8
+
+// Redis es un cliente mínimo de Redis (protocolo RESP) con los comandos que usa la caché:
+// GET, SET con PX, DEL y SCAN. Las conexiones se reutilizan con un pool sencillo.
+type Redis struct {
+	addr     string
+	password string
+	db       int
+	timeout  time.Duration
+	idle     chan *redisConn
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedis crea el cliente. No abre conexiones hasta el primer comando.
+func NewRedis(addr, password string, db int, timeout time.Duration) *Redis {
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+	return &Redis{addr: addr, password: password, db: db, timeout: timeout, idle: make(chan *redisConn, redisPoolSize)}
+}
+
+// errRedisNil es la respuesta nula de Redis (clave inexistente).
+var errRedisNil = errors.New("redis: nil")
+
+// Get devuelve el valor de key. found es false si la clave no existe.
+func (r *Redis) Get(key string) (value []byte, found bool, err error) {
+	reply, err := r.do("GET", key)
+	if errors.Is(err, errRedisNil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: respuesta inesperada a GET: %T", reply)
+	}
+	return value, true, nil
+}
+
+// Set guarda value en key durante ttl.
+func (r *Redis) Set(key string, value []byte, ttl time.Duration) error {
+	ms := ttl.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	_, err := r.do("SET", key, string(value), "PX", strconv.FormatInt(ms, 10))
+	return err
+}
+
+// Delete elimina las claves indicadas.
+func (r *Redis) Delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := r.do("DEL", keys...)
+	return err
+}
+
+// DeletePrefix elimina las claves que empiezan por prefix recorriéndolas con SCAN, que no
+// bloquea al servidor como KEYS.
+func (r *Redis) DeletePrefix(prefix string) error {
+	cursor := "0"
+	for {
+		reply, err := r.do("SCAN", cursor, "MATCH", prefix+"*", "COUNT", "500")
+		if err != nil {
+			return err
+		}
+		parts, ok := reply.([]any)
+		if !ok || len(parts) != 2 {
+			return fmt.Errorf("redis: respuesta inesperada a SCAN")
+		}
+		next, _ := parts[0].([]byte)
+		found, _ := parts[1].([]any)
+		keys := make([]string, 0, len(found))
+		for _, key := range found {
+			if k, ok := key.([]byte); ok {
+				keys = append(keys, string(k))
+			}
+		}
+		if err := r.Delete(keys...); err != nil {
+			return err
+		}
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return nil
+		}
+	}
+}
+
+// do envía un comando y lee su respuesta. La conexión se descarta si falla la red.
+func (r *Redis) do(command string, args ...string) (any, error) {
+	conn, err := r.acquire()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.command(r.timeout, command, args...)
+	var replyErr redisError
+	if err != nil && !errors.Is(err, errRedisNil) && !errors.As(err, &replyErr) {
+		conn.conn.Close()
+		return nil, err
+	}
+	r.release(conn)
+	return reply, err
+}
+
+func (r *Redis) acquire() (*redisConn, error) {
+	select {
+	case conn := <-r.idle:
+		return conn, nil
+	default:
+	}
+	netConn, err := net.DialTimeout("tcp", r.addr, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn := &redisConn{conn: netConn, reader: bufio.NewReader(netConn)}
+	if r.password != "" {
+		if _, err := conn.command(r.timeout, "AUTH", r.password); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	if r.db != 0 {
+		if _, err := conn.command(r.timeout, "SELECT", strconv.Itoa(r.db)); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (r *Redis) release(conn *redisConn) {
+	select {
+	case r.idle <- conn:
+	default:
+		conn.conn.Close()
+	}
+}
+
+// redisError es un error devuelto por el servidor (respuesta -ERR); la conexión sigue sirviendo.
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+func (c *redisConn) command(timeout time.Duration, command string, args ...string) (any, error) {
+	if err := c.conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)+1), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range append([]string{command}, args...) {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+	if _, err := c.conn.Write(buf); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+// readReply lee una respuesta RESP2: +simple, -error, :entero, $bulk o *array.
+func (c *redisConn) readReply() (any, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 {
+		return nil, fmt.Errorf("redis: respuesta mal formada %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, errRedisNil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, errRedisNil
+		}
+		items := make([]any, count)
+		for i := range items {
+			item, err := c.readReply()
+			if err != nil && !errors.Is(err, errRedisNil) {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: tipo de respuesta desconocido %q", kind)
+}
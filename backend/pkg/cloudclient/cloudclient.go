@@ -6,6 +6,8 @@ import (
 	"io"
 	"log" // Usar log estándar en lugar de tools
 	"mime/multipart"
+	"net/url"
+	"time"
 
 	"cloud.google.com/go/storage"
 	"google.golang.org/api/option"
@@ -50,6 +52,114 @@ func UploadFile(ctx context.Context, file multipart.File, remotePath string, con
 	return nil
 }
 
+// UploadPrivate sube a GCS el contenido de reader sin hacerlo público. Solo se puede
+// descargar con un enlace firmado (SignedURL).
+func UploadPrivate(ctx context.Context, reader io.Reader, remotePath string, contentType string) error {
+	return upload(ctx, reader, remotePath, contentType, false)
+}
+
+// UploadPublic sube a GCS el contenido de reader con lectura pública, como UploadFile pero sin
+// exigir un multipart.File.
+func UploadPublic(ctx context.Context, reader io.Reader, remotePath string, contentType string) error {
+	return upload(ctx, reader, remotePath, contentType, true)
+}
+
+func upload(ctx context.Context, reader io.Reader, remotePath string, contentType string, public bool) error {
+	if bucket == nil {
+		return fmt.Errorf("GCS bucket handle not initialized")
+	}
+	wc := bucket.Object(remotePath).NewWriter(ctx)
+	wc.ContentType = contentType
+	if public {
+		wc.ACL = []storage.ACLRule{{Entity: storage.AllUsers, Role: storage.RoleReader}}
+	}
+	if _, err := io.Copy(wc, reader); err != nil {
+		wc.Close()
+		return fmt.Errorf("error subiendo %s a GCS: %w", remotePath, err)
+	}
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("error cerrando la subida de %s a GCS: %w", remotePath, err)
+	}
+	return nil
+}
+
+// SignedURL genera un enlace de descarga firmado (V4) para un objeto privado, válido durante
+// ttl. Si downloadName no está vacío el navegador lo descarga con ese nombre.
+func SignedURL(remotePath string, ttl time.Duration, downloadName string) (string, error) {
+	if bucket == nil {
+		return "", fmt.Errorf("GCS bucket handle not initialized")
+	}
+	opts := &storage.SignedURLOptions{
+		Scheme:  storage.SigningSchemeV4,
+		Method:  "GET",
+		Expires: time.Now().Add(ttl),
+	}
+	if downloadName != "" {
+		opts.QueryParameters = url.Values{
+			"response-content-disposition": {fmt.Sprintf("attachment; filename=%q", downloadName)},
+		}
+	}
+	signed, err := bucket.SignedURL(remotePath, opts)
+	if err != nil {
+		return "", fmt.Errorf("error firmando el enlace de %s: %w", remotePath, err)
+	}
+	return signed, nil
+}
+
+// DeleteFile elimina un objeto de GCS. Un objeto que ya no existe no es un error.
+func DeleteFile(ctx context.Context, remotePath string) error {
+	if bucket == nil {
+		return fmt.Errorf("GCS bucket handle not initialized")
+	}
+	if err := bucket.Object(remotePath).Delete(ctx); err != nil && err != storage.ErrObjectNotExist {
+		return fmt.Errorf("error eliminando %s de GCS: %w", remotePath, err)
+	}
+	return nil
+}
+
+// ErrObjectNotExist indica que el objeto pedido no existe en el bucket.
+var ErrObjectNotExist = storage.ErrObjectNotExist
+
+// ObjectInfo describe un objeto de GCS.
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+	ETag        string
+	Updated     time.Time
+}
+
+// Stat devuelve el tamaño, el tipo, el ETag y la fecha de modificación de un objeto. Devuelve
+// ErrObjectNotExist si no existe.
+func Stat(ctx context.Context, remotePath string) (*ObjectInfo, error) {
+	if bucket == nil {
+		return nil, fmt.Errorf("GCS bucket handle not initialized")
+	}
+	attrs, err := bucket.Object(remotePath).Attrs(ctx)
+	if err != nil {
+		if err == storage.ErrObjectNotExist {
+			return nil, ErrObjectNotExist
+		}
+		return nil, fmt.Errorf("error obteniendo los atributos de %s: %w", remotePath, err)
+	}
+	return &ObjectInfo{Size: attrs.Size, ContentType: attrs.ContentType, ETag: attrs.Etag, Updated: attrs.Updated}, nil
+}
+
+// OpenRange abre un lector de length bytes del objeto a partir de offset (length -1 lee hasta
+// el final). El llamador debe cerrar el lector.
+func OpenRange(ctx context.Context, remotePath string, offset, length int64) (io.ReadCloser, error) {
+	if bucket == nil {
+		return nil, fmt.Errorf("GCS bucket handle not initialized")
+	}
+	rc, err := bucket.Object(remotePath).NewRangeReader(ctx, offset, length)
+	if err != nil {
+		if err == storage.ErrObjectNotExist {
+			return nil, ErrObjectNotExist
+		}
+		return nil, fmt.Errorf("error abriendo %s en GCS: %w", remotePath, err)
+	}
+	return rc, nil
+}
+
 // Open inicializa la conexión con el bucket de GCS y asigna la variable global bucket.
 // TODO: Considerar devolver el handle en lugar de usar variable global.
 func Open(bucketNameInput string, credentialsFile string) error {
@@ -101,3 +211,15 @@ func DownloadFile(ctx context.Context, remotePath string) ([]byte, error) {
 
 	return data, nil
 }
+
+// Ping verifica que el cliente esté inicializado y que el bucket sea accesible.
+// Se usa en las comprobaciones de readiness.
+func Ping(ctx context.Context) error {
+	if bucket == nil {
+		return fmt.Errorf("GCS bucket handle not initialized")
+	}
+	if _, err := bucket.Attrs(ctx); err != nil {
+		return fmt.Errorf("GCS bucket %s no accesible: %w", gcsBucketName, err)
+	}
+	return nil
+}
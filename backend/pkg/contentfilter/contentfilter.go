@@ -0,0 +1,437 @@
+// Package contentfilter evalúa textos de chat contra listas de palabras, heurísticas de spam
+// y, opcionalmente, un servicio externo de moderación.
+//
+// Cada coincidencia tiene una severidad (BAJA, MEDIA o ALTA) y la política asocia cada
+// severidad con una acción:
+//
+//	MARCAR              el mensaje se entrega y queda registrado para revisión
+//	BORRADO_SILENCIOSO  el mensaje se guarda y el remitente lo ve, pero no se entrega al resto
+//	BLOQUEAR            el mensaje se rechaza
+//
+// Si un texto tiene varias coincidencias se aplica la acción más fuerte. El Filter es seguro
+// para uso concurrente y sus reglas se pueden recargar en caliente con Load.
+package contentfilter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Severidades de una coincidencia.
+const (
+	SeverityLow    = "BAJA"
+	SeverityMedium = "MEDIA"
+	SeverityHigh   = "ALTA"
+)
+
+// Acciones que se aplican a un mensaje. ActionNone indica que el texto está limpio.
+const (
+	ActionNone         = ""
+	ActionFlag         = "MARCAR"
+	ActionShadowDelete = "BORRADO_SILENCIOSO"
+	ActionBlock        = "BLOQUEAR"
+)
+
+// Tipos de regla.
+const (
+	MatchWord   = "PALABRA" // Palabra completa, tolerante a acentos, mayúsculas, letras repetidas y leetspeak
+	MatchPhrase = "FRASE"   // Secuencia de palabras completas, con la misma normalización
+	MatchRegex  = "REGEX"   // Expresión regular sobre el texto en minúsculas
+)
+
+// Origen de una coincidencia.
+const (
+	SourceWordlist  = "LISTA"
+	SourceHeuristic = "HEURISTICA"
+	SourceExternal  = "EXTERNO"
+)
+
+// AllLanguages es el idioma de las reglas que se aplican siempre.
+const AllLanguages = "*"
+
+var severityRank = map[string]int{SeverityLow: 1, SeverityMedium: 2, SeverityHigh: 3}
+
+var actionRank = map[string]int{ActionNone: 0, ActionFlag: 1, ActionShadowDelete: 2, ActionBlock: 3}
+
+// Rule es una regla de la lista de palabras.
+type Rule struct {
+	Id        int64
+	Language  string
+	MatchType string
+	Pattern   string
+	Severity  string
+}
+
+// Policy son los parámetros ajustables del filtro. Un umbral en cero desactiva su heurística.
+type Policy struct {
+	Enabled            bool
+	Actions            map[string]string // Severidad -> acción
+	MaxLinks           int               // Enlaces permitidos por mensaje
+	MaxRepeatedChars   int               // Repeticiones seguidas permitidas de un mismo carácter
+	MaxUppercaseRatio  float64           // Proporción de mayúsculas permitida (mensajes con 20 letras o más)
+	DuplicateThreshold int               // Veces que se puede enviar el mismo texto dentro de DuplicateWindow
+	DuplicateWindow    time.Duration
+	SpamSeverity       string // Severidad de las coincidencias heurísticas
+}
+
+// Hit es una coincidencia encontrada en el texto.
+type Hit struct {
+	Source   string `json:"source"`
+	RuleId   int64  `json:"ruleId,omitempty"`
+	Detail   string `json:"detail"`
+	Severity string `json:"severity"`
+}
+
+// Verdict es el resultado de evaluar un texto.
+type Verdict struct {
+	Action   string `json:"action"`
+	Severity string `json:"severity,omitempty"`
+	Hits     []Hit  `json:"hits,omitempty"`
+}
+
+// Checker consulta un servicio externo de moderación. Devuelve la severidad (vacía si el
+// texto está limpio) y las etiquetas con las que el servicio lo clasificó.
+type Checker interface {
+	Check(ctx context.Context, text string, languages []string) (severity string, labels []string, err error)
+}
+
+// uppercaseMinLetters es el mínimo de letras para aplicar la heurística de mayúsculas.
+const uppercaseMinLetters = 20
+
+// duplicateSweepSize es el número de remitentes a partir del cual se purgan los historiales
+// vencidos de la heurística de mensajes repetidos.
+const duplicateSweepSize = 10000
+
+type compiledRegex struct {
+	rule Rule
+	re   *regexp.Regexp
+}
+
+type compiledPhrase struct {
+	rule   Rule
+	phrase string
+}
+
+// Filter evalúa textos con las reglas y la política cargadas.
+type Filter struct {
+	languages []string
+	checker   Checker
+
+	mu      sync.RWMutex
+	policy  Policy
+	words   map[string]Rule
+	phrases []compiledPhrase
+	regexes []compiledRegex
+
+	recentMu sync.Mutex
+	recent   map[int64][]recentMessage
+}
+
+type recentMessage struct {
+	text string
+	at   time.Time
+}
+
+// New crea un filtro deshabilitado hasta la primera llamada a Load. languages son los idiomas
+// cuyas listas se aplican (además de las de AllLanguages); checker puede ser nil.
+func New(languages []string, checker Checker) *Filter {
+	return &Filter{
+		languages: languages,
+		checker:   checker,
+		words:     make(map[string]Rule),
+		recent:    make(map[int64][]recentMessage),
+	}
+}
+
+// ParseLanguages convierte una lista de idiomas separados por comas ("es, en") en la lista
+// que recibe New.
+func ParseLanguages(list string) []string {
+	var languages []string
+	for _, lang := range strings.Split(list, ",") {
+		if lang = strings.ToLower(strings.TrimSpace(lang)); lang != "" {
+			languages = append(languages, lang)
+		}
+	}
+	return languages
+}
+
+// ValidateRule comprueba que la regla se pueda cargar.
+func ValidateRule(rule Rule) error {
+	if _, ok := severityRank[rule.Severity]; !ok {
+		return fmt.Errorf("severidad no válida: %q", rule.Severity)
+	}
+	if strings.TrimSpace(rule.Pattern) == "" {
+		return errors.New("el patrón está vacío")
+	}
+	switch rule.MatchType {
+	case MatchWord:
+		if words := tokens(rule.Pattern); len(words) != 1 {
+			return errors.New("una regla PALABRA debe contener exactamente una palabra")
+		}
+	case MatchPhrase:
+		if len(tokens(rule.Pattern)) == 0 {
+			return errors.New("la frase no contiene palabras")
+		}
+	case MatchRegex:
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("expresión regular inválida: %w", err)
+		}
+	default:
+		return fmt.Errorf("tipo de regla no válido: %q", rule.MatchType)
+	}
+	return nil
+}
+
+// ValidatePolicy comprueba los valores de la política.
+func ValidatePolicy(policy Policy) error {
+	for severity := range severityRank {
+		action, ok := policy.Actions[severity]
+		if _, valid := actionRank[action]; !ok || !valid || action == ActionNone {
+			return fmt.Errorf("acción no válida para la severidad %s", severity)
+		}
+	}
+	if _, ok := severityRank[policy.SpamSeverity]; !ok {
+		return fmt.Errorf("severidad de spam no válida: %q", policy.SpamSeverity)
+	}
+	if policy.MaxLinks < 0 || policy.MaxRepeatedChars < 0 || policy.DuplicateThreshold < 0 || policy.DuplicateWindow < 0 {
+		return errors.New("los umbrales no pueden ser negativos")
+	}
+	if policy.MaxUppercaseRatio < 0 || policy.MaxUppercaseRatio > 1 {
+		return errors.New("la proporción de mayúsculas debe estar entre 0 y 1")
+	}
+	return nil
+}
+
+// Load reemplaza las reglas y la política. Las reglas de otros idiomas se ignoran y las
+// inválidas se omiten; el error las enumera pero el resto queda cargado.
+func (f *Filter) Load(rules []Rule, policy Policy) error {
+	languages := map[string]bool{AllLanguages: true}
+	for _, lang := range f.languages {
+		languages[lang] = true
+	}
+
+	words := make(map[string]Rule)
+	var phrases []compiledPhrase
+	var regexes []compiledRegex
+	var errs []error
+	for _, rule := range rules {
+		if !languages[rule.Language] {
+			continue
+		}
+		if err := ValidateRule(rule); err != nil {
+			errs = append(errs, fmt.Errorf("regla %d: %w", rule.Id, err))
+			continue
+		}
+		switch rule.MatchType {
+		case MatchWord:
+			word := tokens(rule.Pattern)[0]
+			// Ante dos reglas para la misma palabra gana la más severa.
+			if existing, ok := words[word]; !ok || severityRank[rule.Severity] > severityRank[existing.Severity] {
+				words[word] = rule
+			}
+		case MatchPhrase:
+			phrases = append(phrases, compiledPhrase{rule: rule, phrase: " " + strings.Join(tokens(rule.Pattern), " ") + " "})
+		case MatchRegex:
+			regexes = append(regexes, compiledRegex{rule: rule, re: regexp.MustCompile(rule.Pattern)})
+		}
+	}
+
+	f.mu.Lock()
+	f.policy, f.words, f.phrases, f.regexes = policy, words, phrases, regexes
+	f.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// Check evalúa el texto enviado por userID. La heurística de mensajes repetidos recuerda los
+// textos de cada remitente, así que Check solo debe llamarse una vez por mensaje; Test
+// evalúa sin registrar el texto.
+func (f *Filter) Check(ctx context.Context, userID int64, text string) Verdict {
+	return f.check(ctx, userID, text, true)
+}
+
+// Test evalúa el texto sin afectar a la heurística de mensajes repetidos. Pensado para
+// probar las reglas desde la administración.
+func (f *Filter) Test(ctx context.Context, text string) Verdict {
+	return f.check(ctx, 0, text, false)
+}
+
+func (f *Filter) check(ctx context.Context, userID int64, text string, remember bool) Verdict {
+	f.mu.RLock()
+	policy, words, phrases, regexes := f.policy, f.words, f.phrases, f.regexes
+	f.mu.RUnlock()
+
+	if !policy.Enabled || strings.TrimSpace(text) == "" {
+		return Verdict{Action: ActionNone}
+	}
+
+	var hits []Hit
+	normalized := tokens(text)
+	seen := make(map[int64]bool)
+	for _, word := range normalized {
+		if rule, ok := words[word]; ok && !seen[rule.Id] {
+			seen[rule.Id] = true
+			hits = append(hits, Hit{Source: SourceWordlist, RuleId: rule.Id, Detail: rule.Pattern, Severity: rule.Severity})
+		}
+	}
+	joined := " " + strings.Join(normalized, " ") + " "
+	for _, p := range phrases {
+		if strings.Contains(joined, p.phrase) {
+			hits = append(hits, Hit{Source: SourceWordlist, RuleId: p.rule.Id, Detail: p.rule.Pattern, Severity: p.rule.Severity})
+		}
+	}
+	lower := strings.ToLower(text)
+	for _, r := range regexes {
+		if r.re.MatchString(lower) {
+			hits = append(hits, Hit{Source: SourceWordlist, RuleId: r.rule.Id, Detail: r.rule.Pattern, Severity: r.rule.Severity})
+		}
+	}
+
+	for _, detail := range heuristics(text, policy) {
+		hits = append(hits, Hit{Source: SourceHeuristic, Detail: detail, Severity: policy.SpamSeverity})
+	}
+	if remember && f.isDuplicate(userID, joined, policy) {
+		hits = append(hits, Hit{Source: SourceHeuristic, Detail: "mensaje_repetido", Severity: policy.SpamSeverity})
+	}
+
+	if f.checker != nil {
+		severity, labels, err := f.checker.Check(ctx, text, f.languages)
+		// Si el servicio externo falla el mensaje se evalúa solo con las reglas locales.
+		if err == nil && severityRank[severity] > 0 {
+			hits = append(hits, Hit{Source: SourceExternal, Detail: strings.Join(labels, ","), Severity: severity})
+		}
+	}
+
+	verdict := Verdict{Action: ActionNone, Hits: hits}
+	for _, hit := range hits {
+		if severityRank[hit.Severity] > severityRank[verdict.Severity] {
+			verdict.Severity = hit.Severity
+		}
+		if action := policy.Actions[hit.Severity]; actionRank[action] > actionRank[verdict.Action] {
+			verdict.Action = action
+		}
+	}
+	return verdict
+}
+
+// heuristics devuelve las heurísticas de spam que el texto supera.
+func heuristics(text string, policy Policy) []string {
+	var details []string
+
+	if policy.MaxLinks > 0 {
+		lower := strings.ToLower(text)
+		links := strings.Count(lower, "http://") + strings.Count(lower, "https://") + strings.Count(lower, "www.")
+		if links > policy.MaxLinks {
+			details = append(details, "enlaces")
+		}
+	}
+
+	if policy.MaxRepeatedChars > 0 {
+		var prev rune
+		run := 0
+		for _, r := range text {
+			if r == prev && !unicode.IsSpace(r) {
+				run++
+			} else {
+				prev, run = r, 1
+			}
+			if run > policy.MaxRepeatedChars {
+				details = append(details, "caracteres_repetidos")
+				break
+			}
+		}
+	}
+
+	if policy.MaxUppercaseRatio > 0 {
+		letters, upper := 0, 0
+		for _, r := range text {
+			if unicode.IsLetter(r) {
+				letters++
+				if unicode.IsUpper(r) {
+					upper++
+				}
+			}
+		}
+		if letters >= uppercaseMinLetters && float64(upper)/float64(letters) > policy.MaxUppercaseRatio {
+			details = append(details, "mayusculas")
+		}
+	}
+
+	return details
+}
+
+// isDuplicate registra el texto normalizado del remitente e indica si ya lo envió
+// DuplicateThreshold veces dentro de la ventana.
+func (f *Filter) isDuplicate(userID int64, text string, policy Policy) bool {
+	if policy.DuplicateThreshold <= 0 || policy.DuplicateWindow <= 0 || userID == 0 {
+		return false
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-policy.DuplicateWindow)
+
+	f.recentMu.Lock()
+	defer f.recentMu.Unlock()
+
+	if len(f.recent) > duplicateSweepSize {
+		for id, messages := range f.recent {
+			if len(messages) == 0 || messages[len(messages)-1].at.Before(cutoff) {
+				delete(f.recent, id)
+			}
+		}
+	}
+
+	kept := f.recent[userID][:0]
+	repeats := 0
+	for _, m := range f.recent[userID] {
+		if m.at.After(cutoff) {
+			kept = append(kept, m)
+			if m.text == text {
+				repeats++
+			}
+		}
+	}
+	f.recent[userID] = append(kept, recentMessage{text: text, at: now})
+	return repeats >= policy.DuplicateThreshold
+}
+
+// leetReplacer deshace las sustituciones habituales de letras por números y símbolos.
+var leetReplacer = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s",
+)
+
+// tokens normaliza el texto y lo divide en palabras: minúsculas, sin acentos, sin leetspeak
+// y con las letras repetidas reducidas a una ("hoooola" -> "hola").
+func tokens(text string) []string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	normalized, _, err := transform.String(t, strings.ToLower(text))
+	if err != nil {
+		normalized = strings.ToLower(text)
+	}
+
+	var words []string
+	for _, field := range strings.FieldsFunc(normalized, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !strings.ContainsRune("@$", r)
+	}) {
+		field = leetReplacer.Replace(field)
+		var b strings.Builder
+		var prev rune
+		for _, r := range field {
+			if r != prev {
+				b.WriteRune(r)
+			}
+			prev = r
+		}
+		words = append(words, b.String())
+	}
+	return words
+}
@@ -0,0 +1,74 @@
+package contentfilter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HTTPChecker consulta un servicio externo de moderación por HTTP.
+//
+// Petición:  POST {url}  {"text": "...", "languages": ["es", "en"]}
+// Respuesta: 200         {"severity": "BAJA|MEDIA|ALTA" o "", "labels": ["..."]}
+//
+// Si apiKey no está vacía se envía como "Authorization: Bearer {apiKey}".
+type HTTPChecker struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// NewHTTPChecker crea un Checker para url. timeout limita cada consulta; al vencer el
+// mensaje se evalúa solo con las reglas locales.
+func NewHTTPChecker(url, apiKey string, timeout time.Duration) *HTTPChecker {
+	return &HTTPChecker{url: url, apiKey: apiKey, client: &http.Client{Timeout: timeout}}
+}
+
+type checkRequest struct {
+	Text      string   `json:"text"`
+	Languages []string `json:"languages"`
+}
+
+type checkResponse struct {
+	Severity string   `json:"severity"`
+	Labels   []string `json:"labels"`
+}
+
+// Check implementa Checker.
+func (c *HTTPChecker) Check(ctx context.Context, text string, languages []string) (string, []string, error) {
+	body, err := json.Marshal(checkRequest{Text: text, Languages: languages})
+	if err != nil {
+		return "", nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("error al consultar el servicio de moderación: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("el servicio de moderación respondió %d", resp.StatusCode)
+	}
+
+	var result checkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", nil, fmt.Errorf("respuesta inválida del servicio de moderación: %w", err)
+	}
+	if result.Severity != "" {
+		if _, ok := severityRank[result.Severity]; !ok {
+			return "", nil, fmt.Errorf("severidad desconocida del servicio de moderación: %q", result.Severity)
+		}
+	}
+	return result.Severity, result.Labels, nil
+}
@@ -0,0 +1,154 @@
+package customws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/gorilla/websocket"
+)
+
+// ErrNoCredentials es el error que AuthenticateAndGetUserData devuelve (o envuelve) cuando la
+// petición de upgrade no trae credenciales. Si Callbacks.AuthenticateMessage está definido y
+// Config.AuthTimeout > 0, ServeHTTP acepta la conexión y espera un mensaje auth; si no,
+// responde 401 como con cualquier otro error.
+var ErrNoCredentials = errors.New("la petición no incluye credenciales")
+
+// maxAuthMessageSize es el tamaño máximo del mensaje auth.
+const maxAuthMessageSize = 8 << 10
+
+// authFailure es un fallo de la autenticación por primer mensaje, con el código de cierre.
+type authFailure struct {
+	pid       string
+	closeCode int
+	err       *apperrors.Error
+}
+
+// firstMessageAuth indica si se aceptan conexiones sin credenciales que se autentican con
+// el primer mensaje.
+func (cm *ConnectionManager[TUserData]) firstMessageAuth() bool {
+	return cm.callbacks.AuthenticateMessage != nil && cm.config.AuthTimeout > 0
+}
+
+// serveFirstMessageAuth actualiza la conexión sin autenticar y espera el mensaje auth durante
+// Config.AuthTimeout. Mientras tanto la conexión no está registrada ni recibe mensajes. Si la
+// autenticación falla se envía el error y se cierra con CloseAuthFailed o CloseAuthTimeout.
+func (cm *ConnectionManager[TUserData]) serveFirstMessageAuth(w http.ResponseWriter, r *http.Request) {
+	wsConn, err := cm.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Errorf(componentLog, "Error al actualizar a WebSocket sin credenciales: %v", err)
+		return
+	}
+
+	userID, userData, auth, failure := cm.readAuthMessage(wsConn, r)
+	if failure != nil {
+		cm.rejectAuth(wsConn, failure)
+		return
+	}
+
+	connection := cm.startConnection(wsConn, userID, userData, auth.ResumeToken, auth.LastPID)
+	if connection != nil && auth.pid != "" {
+		connection.SendServerAck(auth.pid, "authenticated", nil)
+	}
+}
+
+// authMessage es el mensaje auth ya decodificado.
+type authMessage struct {
+	types.AuthPayload
+	pid string
+}
+
+// readAuthMessage lee el primer mensaje de la conexión y autentica sus credenciales con
+// Callbacks.AuthenticateMessage.
+func (cm *ConnectionManager[TUserData]) readAuthMessage(wsConn *websocket.Conn, r *http.Request) (int64, TUserData, authMessage, *authFailure) {
+	var zero TUserData
+	wsConn.SetReadLimit(maxAuthMessageSize)
+	wsConn.SetReadDeadline(time.Now().Add(cm.config.AuthTimeout))
+
+	_, data, err := wsConn.ReadMessage()
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			logger.Warnf(componentLog, "Autenticación por mensaje: %s no envió el mensaje auth en %s", r.RemoteAddr, cm.config.AuthTimeout)
+			return 0, zero, authMessage{}, &authFailure{
+				closeCode: CloseAuthTimeout,
+				err:       apperrors.New(apperrors.MissingToken, fmt.Sprintf("no se recibió el mensaje auth en %s", cm.config.AuthTimeout)),
+			}
+		}
+		logger.Infof(componentLog, "Autenticación por mensaje: conexión de %s cerrada antes de autenticarse: %v", r.RemoteAddr, err)
+		return 0, zero, authMessage{}, &authFailure{closeCode: websocket.CloseNormalClosure}
+	}
+
+	var msg types.ClientToServerMessage
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Type != types.MessageTypeAuth {
+		return 0, zero, authMessage{}, &authFailure{
+			pid:       msg.PID,
+			closeCode: CloseAuthFailed,
+			err:       apperrors.New(apperrors.Unauthenticated, "el primer mensaje debe ser de tipo auth"),
+		}
+	}
+	auth := authMessage{pid: msg.PID}
+	raw, err := json.Marshal(msg.Payload)
+	if err == nil {
+		err = json.Unmarshal(raw, &auth.AuthPayload)
+	}
+	if err != nil {
+		return 0, zero, authMessage{}, &authFailure{
+			pid:       msg.PID,
+			closeCode: CloseAuthFailed,
+			err:       apperrors.New(apperrors.InvalidPayload, fmt.Sprintf("payload de auth inválido: %v", err)),
+		}
+	}
+
+	userID, userData, err := cm.callbacks.AuthenticateMessage(r, auth.AuthPayload)
+	if err != nil {
+		logger.Warnf(componentLog, "Autenticación por mensaje rechazada para %s: %v", r.RemoteAddr, err)
+		appErr := apperrors.New(apperrors.InvalidToken, err.Error())
+		errors.As(err, &appErr)
+		return 0, zero, authMessage{}, &authFailure{pid: msg.PID, closeCode: CloseAuthFailed, err: appErr}
+	}
+
+	// readPump fija sus propios límites y plazos
+	wsConn.SetReadDeadline(time.Time{})
+	return userID, userData, auth, nil
+}
+
+// closeReasonFor devuelve el motivo de los cierres de la autenticación por primer mensaje.
+func closeReasonFor(code int) string {
+	if code == CloseAuthTimeout {
+		return "auth_timeout"
+	}
+	return "auth_failed"
+}
+
+// rejectAuth envía el error de autenticación (si lo hay) y cierra la conexión. No hay pumps
+// todavía, así que se escribe directamente en el socket.
+func (cm *ConnectionManager[TUserData]) rejectAuth(wsConn *websocket.Conn, failure *authFailure) {
+	defer wsConn.Close()
+	deadline := time.Now().Add(cm.config.WriteWait)
+	reason := ""
+	if failure.err != nil {
+		reason = encodeCloseReason(types.CloseReason{
+			Reason:    closeReasonFor(failure.closeCode),
+			ErrorCode: string(failure.err.Code),
+		})
+		wsConn.SetWriteDeadline(deadline)
+		wsConn.WriteJSON(types.ServerToClientMessage{
+			PID:  cm.callbacks.GeneratePID(),
+			Type: types.MessageTypeErrorNotification,
+			Error: &types.ErrorPayload{
+				OriginalPID: failure.pid,
+				Code:        failure.err.Status(),
+				Message:     failure.err.Message,
+				ErrorCode:   string(failure.err.Code),
+			},
+		})
+	}
+	wsConn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(failure.closeCode, reason), deadline)
+}
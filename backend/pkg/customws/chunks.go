@@ -0,0 +1,252 @@
+package customws
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+/*
+MENSAJES GRANDES DEL CLIENTE
+
+Cada tipo de mensaje tiene un tamaño máximo: el de Config.MessageSizeLimits o, si no está,
+Config.MaxMessageSize. Al conectar, el servidor los envía al cliente en un message_limits.
+
+Un mensaje que supera MaxMessageSize puede enviarse en un solo frame si su tipo tiene un límite
+mayor, o fragmentado: el cliente serializa el mensaje completo, corta el JSON en trozos y envía
+cada uno en un mensaje chunk (cada chunk no puede superar MaxMessageSize):
+
+	{"pid": "c-7", "type": "chunk", "payload": {"chunkId": "cv-1", "seq": 0, "total": 3, "data": "{\"pid\":\"c-8\",\"type\":\"data_request\",..."}}
+
+Al llegar el último fragmento el mensaje se reensambla y se procesa como si hubiera llegado
+entero, con el límite de su tipo. Los fragmentos pueden llegar en cualquier orden; si no llegan
+todos en Config.ChunkTimeout desde el primero se descartan y el cliente recibe WS_010.
+*/
+
+const (
+	// maxChunkIDLength es la longitud máxima del chunkId elegido por el cliente.
+	maxChunkIDLength = 64
+	// maxChunksPerMessage es el máximo de fragmentos de un mensaje.
+	maxChunksPerMessage = 1024
+)
+
+// partialMessage es un mensaje fragmentado del que faltan fragmentos.
+type partialMessage struct {
+	firstPID string // PID del primer fragmento recibido, para los errores del mensaje
+	parts    []string
+	received []bool
+	count    int
+	size     int64
+	timer    *time.Timer
+}
+
+// chunkAssembler guarda los mensajes fragmentados en curso de una conexión. Lo usan readPump y
+// los timers de ChunkTimeout.
+type chunkAssembler struct {
+	mu      sync.Mutex
+	pending map[string]*partialMessage
+	closed  bool
+}
+
+// discard descarta los mensajes en curso al cerrarse la conexión. Se llama antes de cerrar
+// SendChan: un timer que esté enviando su error termina antes.
+func (a *chunkAssembler) discard() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, partial := range a.pending {
+		partial.timer.Stop()
+	}
+	a.pending = nil
+	a.closed = true
+}
+
+// messageLimit devuelve el tamaño máximo en bytes de un mensaje de tipo msgType. Cada chunk
+// está limitado por MaxMessageSize.
+func (cm *ConnectionManager[TUserData]) messageLimit(msgType types.MessageType) int64 {
+	if msgType != types.MessageTypeChunk {
+		if limit, ok := cm.config.MessageSizeLimits[msgType]; ok && limit > 0 {
+			return limit
+		}
+	}
+	return cm.config.MaxMessageSize
+}
+
+// maxMessageLimit devuelve el mayor de los límites de tamaño. Es el límite de lectura de la
+// conexión y el tamaño máximo de un mensaje en reensamblado, antes de conocer su tipo.
+func (cm *ConnectionManager[TUserData]) maxMessageLimit() int64 {
+	maxLimit := cm.config.MaxMessageSize
+	for _, limit := range cm.config.MessageSizeLimits {
+		if limit > maxLimit {
+			maxLimit = limit
+		}
+	}
+	return maxLimit
+}
+
+// ParseMessageSizeLimits convierte una lista "tipo=bytes" separada por comas
+// (ej. "data_request=65536,send_chat_message=16384") en Config.MessageSizeLimits.
+func ParseMessageSizeLimits(spec string) (map[types.MessageType]int64, error) {
+	limits := make(map[types.MessageType]int64)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		msgType, size, ok := strings.Cut(entry, "=")
+		msgType = strings.TrimSpace(msgType)
+		if !ok || msgType == "" {
+			return nil, fmt.Errorf("límite de tamaño %q inválido: se esperaba tipo=bytes", entry)
+		}
+		limit, err := strconv.ParseInt(strings.TrimSpace(size), 10, 64)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("límite de tamaño %q inválido: los bytes deben ser un entero positivo", entry)
+		}
+		limits[types.MessageType(msgType)] = limit
+	}
+	return limits, nil
+}
+
+// sendMessageLimits informa al cliente de los tamaños máximos de sus mensajes.
+func (c *Connection[TUserData]) sendMessageLimits() {
+	cfg := c.manager.config
+	limits := make(map[types.MessageType]int64, len(cfg.MessageSizeLimits))
+	for msgType, limit := range cfg.MessageSizeLimits {
+		if limit > 0 {
+			limits[msgType] = limit
+		}
+	}
+	msg := types.ServerToClientMessage{
+		PID:  c.manager.callbacks.GeneratePID(),
+		Type: types.MessageTypeMessageLimits,
+		Payload: types.MessageLimitsPayload{
+			MaxMessageSize:      cfg.MaxMessageSize,
+			Limits:              limits,
+			ChunkTimeoutSeconds: int(cfg.ChunkTimeout.Seconds()),
+			MaxPendingChunks:    cfg.MaxPendingChunks,
+		},
+	}
+	if err := c.SendMessage(msg); err != nil {
+		logger.Errorf(componentLog, "sendMessageLimits: No se pudo enviar message_limits a UserID %d: %v", c.ID, err)
+	}
+}
+
+// handleChunk añade un fragmento a su mensaje y, si era el último, procesa el mensaje
+// reensamblado. Un fragmento inválido descarta el mensaje entero.
+func (c *Connection[TUserData]) handleChunk(msg types.ClientToServerMessage) {
+	cfg := c.manager.config
+	if cfg.ChunkTimeout <= 0 {
+		c.SendAppError(msg.PID, apperrors.UnsupportedMessage, "la fragmentación de mensajes está deshabilitada")
+		return
+	}
+
+	var chunk types.ChunkPayload
+	raw, err := json.Marshal(msg.Payload)
+	if err == nil {
+		err = json.Unmarshal(raw, &chunk)
+	}
+	if err != nil {
+		c.SendAppError(msg.PID, apperrors.InvalidChunk, fmt.Sprintf("payload de chunk inválido: %v", err))
+		return
+	}
+	if chunk.ChunkID == "" || len(chunk.ChunkID) > maxChunkIDLength {
+		c.SendAppError(msg.PID, apperrors.InvalidChunk, fmt.Sprintf("chunkId debe tener entre 1 y %d caracteres", maxChunkIDLength))
+		return
+	}
+	if chunk.Total < 1 || chunk.Total > maxChunksPerMessage || chunk.Seq < 0 || chunk.Seq >= chunk.Total {
+		c.SendAppError(msg.PID, apperrors.InvalidChunk,
+			fmt.Sprintf("%s: seq debe estar entre 0 y total-1, y total entre 1 y %d", chunk.ChunkID, maxChunksPerMessage))
+		return
+	}
+
+	assembled, appErr := c.addChunk(msg.PID, chunk)
+	if appErr != nil {
+		logger.Warnf(componentLog, "handleChunk: Mensaje fragmentado %s de UserID %d descartado: %s", chunk.ChunkID, c.ID, appErr.Message)
+		c.SendAppError(msg.PID, appErr.Code, fmt.Sprintf("%s: %s", chunk.ChunkID, appErr.Message))
+		return
+	}
+	if assembled != nil {
+		logger.Infof(componentLog, "handleChunk: Mensaje fragmentado %s de UserID %d reensamblado (%d fragmentos, %d bytes)",
+			chunk.ChunkID, c.ID, chunk.Total, len(assembled))
+		c.handleIncoming(assembled, true)
+	}
+}
+
+// addChunk guarda el fragmento y devuelve el mensaje completo si ya llegaron todos.
+func (c *Connection[TUserData]) addChunk(pid string, chunk types.ChunkPayload) ([]byte, *apperrors.Error) {
+	a := &c.chunks
+	cfg := c.manager.config
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return nil, nil
+	}
+
+	partial, exists := a.pending[chunk.ChunkID]
+	if !exists {
+		if cfg.MaxPendingChunks > 0 && len(a.pending) >= cfg.MaxPendingChunks {
+			return nil, apperrors.New(apperrors.InvalidChunk,
+				fmt.Sprintf("hay demasiados mensajes fragmentados incompletos (máximo %d)", cfg.MaxPendingChunks))
+		}
+		if a.pending == nil {
+			a.pending = make(map[string]*partialMessage)
+		}
+		partial = &partialMessage{
+			firstPID: pid,
+			parts:    make([]string, chunk.Total),
+			received: make([]bool, chunk.Total),
+		}
+		chunkID := chunk.ChunkID
+		partial.timer = time.AfterFunc(cfg.ChunkTimeout, func() { c.expireChunks(chunkID, partial) })
+		a.pending[chunk.ChunkID] = partial
+	}
+
+	drop := func(appErr *apperrors.Error) ([]byte, *apperrors.Error) {
+		partial.timer.Stop()
+		delete(a.pending, chunk.ChunkID)
+		return nil, appErr
+	}
+	if len(partial.parts) != chunk.Total {
+		return drop(apperrors.New(apperrors.InvalidChunk, "total no coincide con el de los fragmentos anteriores"))
+	}
+	if partial.received[chunk.Seq] {
+		return drop(apperrors.New(apperrors.InvalidChunk, fmt.Sprintf("fragmento %d repetido", chunk.Seq)))
+	}
+	partial.size += int64(len(chunk.Data))
+	if limit := c.manager.maxMessageLimit(); partial.size > limit {
+		return drop(apperrors.New(apperrors.MessageTooLarge, fmt.Sprintf("el mensaje supera el tamaño máximo de %d bytes", limit)))
+	}
+	partial.parts[chunk.Seq] = chunk.Data
+	partial.received[chunk.Seq] = true
+	partial.count++
+	if partial.count < chunk.Total {
+		return nil, nil
+	}
+
+	partial.timer.Stop()
+	delete(a.pending, chunk.ChunkID)
+	return []byte(strings.Join(partial.parts, "")), nil
+}
+
+// expireChunks descarta el mensaje fragmentado si sigue incompleto al vencer ChunkTimeout.
+func (c *Connection[TUserData]) expireChunks(chunkID string, partial *partialMessage) {
+	a := &c.chunks
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed || a.pending[chunkID] != partial {
+		return
+	}
+	delete(a.pending, chunkID)
+
+	logger.Warnf(componentLog, "expireChunks: Mensaje fragmentado %s de UserID %d incompleto tras %s (%d de %d fragmentos)",
+		chunkID, c.ID, c.manager.config.ChunkTimeout, partial.count, len(partial.parts))
+	c.SendAppError(partial.firstPID, apperrors.ChunkTimeout,
+		fmt.Sprintf("%s: llegaron %d de %d fragmentos en %s", chunkID, partial.count, len(partial.parts), c.manager.config.ChunkTimeout))
+}
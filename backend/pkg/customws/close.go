@@ -0,0 +1,78 @@
+package customws
+
+import (
+	"encoding/json"
+	"math/rand"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/gorilla/websocket"
+)
+
+// Códigos de cierre de la aplicación. El rango 4000-4999 es de uso privado (RFC 6455); las
+// tres últimas cifras siguen el código HTTP equivalente. El motivo del frame es un
+// types.CloseReason en JSON (ver Connection.CloseWithReason).
+const (
+	CloseProtocolViolation = 4400 // El cliente no respeta el protocolo
+	CloseAuthFailed        = 4401 // El mensaje auth no es válido o las credenciales fueron rechazadas
+	CloseKicked            = 4403 // Un administrador o la moderación cerró la conexión
+	CloseAuthTimeout       = 4408 // No llegó el mensaje auth en Config.AuthTimeout
+	CloseIdle              = 4410 // Sin mensajes del cliente durante Config.IdleTimeout
+	CloseAuthExpired       = 4419 // Las credenciales de la conexión caducaron o se revocaron
+	CloseRateLimited       = 4429 // El cliente superó el límite de mensajes
+	CloseServerShutdown    = 4503 // El servidor se apaga o drena sus conexiones
+)
+
+// CloseCode describe un código de cierre para la documentación y el SDK generado.
+type CloseCode struct {
+	Code        int
+	Name        string
+	Description string
+}
+
+// CloseCodes son los códigos de cierre que puede usar el servidor, incluidos los de RFC 6455
+// con significado propio en este protocolo.
+var CloseCodes = []CloseCode{
+	{websocket.CloseServiceRestart, "serviceRestart", "El proceso se sustituye por otro; reconectar enseguida"},
+	{websocket.CloseTryAgainLater, "tryAgainLater", "Mantenimiento; reconectar pasado retryAfter"},
+	{CloseProtocolViolation, "protocolViolation", "El cliente no respeta el protocolo; no reconectar sin corregirlo"},
+	{CloseAuthFailed, "authFailed", "Credenciales rechazadas; no reconectar con las mismas"},
+	{CloseKicked, "kicked", "Conexión cerrada por un administrador o la moderación"},
+	{CloseAuthTimeout, "authTimeout", "No llegó el mensaje auth a tiempo"},
+	{CloseIdle, "idle", "Conexión inactiva; reconectar cuando el usuario vuelva"},
+	{CloseAuthExpired, "authExpired", "Credenciales caducadas; renovarlas antes de reconectar"},
+	{CloseRateLimited, "rateLimited", "Demasiados mensajes; reconectar pasado retryAfter"},
+	{CloseServerShutdown, "serverShutdown", "El servidor se apaga o drena; reconectar pasado retryAfter"},
+}
+
+// maxCloseReasonSize es el tamaño máximo del motivo de un frame de cierre: 125 bytes de
+// payload de control menos los 2 del código.
+const maxCloseReasonSize = 123
+
+// encodeCloseReason serializa reason para el frame de cierre. Si no cabe, se envía solo
+// reason.Reason.
+func encodeCloseReason(reason types.CloseReason) string {
+	data, err := json.Marshal(reason)
+	if err != nil || len(data) > maxCloseReasonSize {
+		logger.Warnf(componentLog, "Motivo de cierre demasiado largo, se envía solo %q", reason.Reason)
+		return reason.Reason
+	}
+	return string(data)
+}
+
+// CloseWithReason cierra la conexión con code y reason en JSON como motivo, para que el
+// cliente decida si reconecta y cuándo.
+func (c *Connection[TUserData]) CloseWithReason(code int, reason types.CloseReason) {
+	c.CloseWithCode(code, encodeCloseReason(reason))
+}
+
+// RetryAfterSeconds convierte d a segundos y le suma un retardo aleatorio de hasta otro
+// tanto, para que los clientes cerrados a la vez no reconecten todos en el mismo instante.
+func RetryAfterSeconds(d time.Duration) int {
+	seconds := int(d.Round(time.Second).Seconds())
+	if seconds <= 0 {
+		return 0
+	}
+	return seconds + rand.Intn(seconds+1)
+}
@@ -6,9 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
 	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
 	"github.com/google/uuid"
@@ -27,13 +30,32 @@ const (
 // Connection representa una conexión WebSocket activa para un usuario.
 // TUserData es el tipo de datos específicos del usuario que se asociarán con esta conexión.
 type Connection[TUserData any] struct {
-	ID       int64 // ID del usuario (asumiendo que es int64, como en tu ejemplo original)
+	ID       int64  // ID del usuario (asumiendo que es int64, como en tu ejemplo original)
+	serial   uint64 // Identifica la conexión en el manager (ver pids.go)
 	conn     *websocket.Conn
 	manager  *ConnectionManager[TUserData]
 	SendChan chan types.ServerToClientMessage // Canal para enviar mensajes al cliente.
 	UserData TUserData                        // Datos personalizados del usuario.
 	ctx      context.Context
 	cancel   context.CancelFunc
+	session  *resumableSession // nil si la reanudación de sesiones está deshabilitada
+	chunks   chunkAssembler    // Mensajes fragmentados en curso (ver chunks.go)
+
+	// Heartbeats (ver heartbeat.go): respuestas pendientes de escribir y ida y vuelta del
+	// último ping en nanosegundos.
+	heartbeats chan heartbeatRequest
+	rtt        atomic.Int64
+
+	ackedPIDs recentPIDs // Últimos PIDs confirmados por el cliente, para descartar acks repetidos
+
+	// Inactividad (ver idle.go): último mensaje del cliente en UnixNano, si ya se envió
+	// idle_warning y si la conexión se cerró por inactividad.
+	lastActivity atomic.Int64
+	idleWarned   atomic.Bool
+	evicted      atomic.Bool
+
+	connectedAt time.Time
+	counters    connectionCounters // Tráfico de la conexión (ver stats.go)
 }
 
 // Manager devuelve el ConnectionManager asociado con esta conexión.
@@ -41,6 +63,12 @@ func (c *Connection[TUserData]) Manager() *ConnectionManager[TUserData] {
 	return c.manager
 }
 
+// Context devuelve el contexto de la conexión, que se cancela al cerrarse. Sirve para
+// terminar las goroutines asociadas a la conexión.
+func (c *Connection[TUserData]) Context() context.Context {
+	return c.ctx
+}
+
 // Callbacks define las funciones que el usuario de la biblioteca debe implementar
 // para manejar eventos y mensajes específicos de la aplicación.
 type Callbacks[TUserData any] struct {
@@ -64,9 +92,31 @@ type Callbacks[TUserData any] struct {
 	// Si la autenticación falla, debe retornar un error y ServeHTTP responderá con HTTP Unauthorized.
 	AuthenticateAndGetUserData func(r *http.Request) (userID int64, userData TUserData, err error)
 
+	// AuthenticateMessage (opcional) autentica la conexión con el primer mensaje del cliente
+	// (tipo auth), para los clientes que no pueden enviar credenciales en el upgrade (los
+	// navegadores no permiten el header Authorization). Se usa cuando AuthenticateAndGetUserData
+	// devuelve ErrNoCredentials y Config.AuthTimeout > 0 (ver auth.go).
+	AuthenticateMessage func(r *http.Request, auth types.AuthPayload) (userID int64, userData TUserData, err error)
+
 	// GeneratePID (opcional): Si se proporciona, se usará para generar PIDs para mensajes salientes.
 	// Si es nil, se usará uuid.NewString().
 	GeneratePID func() string
+
+	// AuthorizeSubscription (opcional) decide si la conexión puede suscribirse al tópico.
+	// Un error lo rechaza: si es un *apperrors.Error se envía su código, si no AUTH_005.
+	// Si es nil se permiten todos los tópicos con nombre válido.
+	AuthorizeSubscription func(conn *Connection[TUserData], topic string) error
+
+	// OnPanic (opcional) se llama cuando el procesamiento de un mensaje entra en pánico, tras
+	// registrar la traza y responder al cliente con GEN_005. La conexión sigue abierta.
+	// Sirve para contar los pánicos en las métricas de la aplicación.
+	OnPanic func(conn *Connection[TUserData], msg types.ClientToServerMessage, recovered interface{})
+
+	// OnDeadLetter (opcional) se llama cuando SendForClientAck agota Config.AckMaxAttempts sin
+	// recibir el ack o la conexión se cierra sin poder reanudarse. Sirve para que la aplicación
+	// guarde los mensajes críticos y los entregue más tarde. attempts es el número de envíos
+	// hechos y err el último error.
+	OnDeadLetter func(conn *Connection[TUserData], msg types.ServerToClientMessage, attempts int, err error)
 }
 
 // ConnectionManager gestiona todas las conexiones WebSocket activas.
@@ -76,11 +126,15 @@ type ConnectionManager[TUserData any] struct {
 	upgrader  websocket.Upgrader
 
 	// pendingClientAcks almacena PIDs de mensajes enviados por el servidor que esperan un ClientAck.
+	// Solo se acepta el ack de la conexión a la que se envió el mensaje o de la que reanuda su
+	// sesión (PendingClientAck.ConnectionID), o de cualquier conexión del usuario si el mensaje
+	// se envió a todas (PendingClientAck.AnyConnection).
 	// map[pid string]*types.PendingClientAck
 	pendingClientAcks sync.Map
 
-	// pendingServerResponses almacena PIDs de ClientToServerMessage de tipo GenericRequest
-	// que esperan una respuesta ServerToClientMessage con el mismo PID.
+	// pendingServerResponses almacena PIDs de solicitudes del servidor que esperan una
+	// respuesta del cliente con el mismo PID. Solo la conexión a la que se envió la solicitud
+	// puede responderla (PendingServerResponse.ConnectionID).
 	// map[pid string]*types.PendingServerResponse
 	pendingServerResponses sync.Map
 
@@ -92,6 +146,19 @@ type ConnectionManager[TUserData any] struct {
 
 	// userConnections es un mapa para almacenar conexiones activas por UserID
 	userConnections map[int64][]*Connection[TUserData]
+
+	// sessions almacena las sesiones reanudables por token (ver resume.go).
+	sessionsMu sync.Mutex
+	sessions   map[string]*resumableSession
+
+	// subscriptions gestiona las suscripciones de las conexiones a tópicos (ver subscriptions.go).
+	subscriptions *SubscriptionManager[TUserData]
+
+	// idleEvictions cuenta las conexiones cerradas por inactividad (ver idle.go).
+	idleEvictions atomic.Int64
+
+	// connSerial numera las conexiones para asociar a cada una sus solicitudes pendientes.
+	connSerial atomic.Uint64
 }
 
 // Callbacks devuelve la configuración de callbacks del ConnectionManager.
@@ -152,6 +219,7 @@ func NewConnectionManager[TUserData any](cfg types.Config, cbs Callbacks[TUserDa
 		ctx:    rootCtx,
 		cancel: rootCancel,
 	}
+	manager.subscriptions = newSubscriptionManager(manager)
 
 	go manager.cleanupRoutine()
 
@@ -162,6 +230,10 @@ func NewConnectionManager[TUserData any](cfg types.Config, cbs Callbacks[TUserDa
 // ServeHTTP maneja las solicitudes HTTP entrantes y las actualiza a conexiones WebSocket.
 func (cm *ConnectionManager[TUserData]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	userID, userData, err := cm.callbacks.AuthenticateAndGetUserData(r)
+	if errors.Is(err, ErrNoCredentials) && cm.firstMessageAuth() {
+		cm.serveFirstMessageAuth(w, r)
+		return
+	}
 	if err != nil {
 		logger.Errorf(componentLog, "Error de autenticación en ServeHTTP: %v", err)
 		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
@@ -174,19 +246,34 @@ func (cm *ConnectionManager[TUserData]) ServeHTTP(w http.ResponseWriter, r *http
 		return
 	}
 
+	query := r.URL.Query()
+	cm.startConnection(wsConn, userID, userData, query.Get(ResumeTokenQueryParam), query.Get(LastPIDQueryParam))
+}
+
+// startConnection registra una conexión ya autenticada, inicia sus pumps y le envía los
+// límites de mensajes y la información de la sesión. Devuelve nil si OnConnect la rechazó.
+func (cm *ConnectionManager[TUserData]) startConnection(wsConn *websocket.Conn, userID int64, userData TUserData, resumeToken, lastPID string) *Connection[TUserData] {
 	logger.Infof(componentLog, "Conexión WebSocket establecida para UserID %d", userID)
 
+	session, resumed := cm.startSession(userID, resumeToken)
+
 	connCtx, connCancel := context.WithCancel(cm.ctx)
 
 	connection := &Connection[TUserData]{
 		ID:       userID,
+		serial:   cm.connSerial.Add(1),
 		conn:     wsConn,
 		manager:  cm,
 		SendChan: make(chan types.ServerToClientMessage, cm.config.SendChannelBuffer),
 		UserData: userData,
 		ctx:      connCtx,
 		cancel:   connCancel,
+		session:  session,
+
+		heartbeats:  make(chan heartbeatRequest, 1),
+		connectedAt: time.Now(),
 	}
+	connection.touch()
 
 	cm.registerConnection(connection)
 
@@ -194,14 +281,28 @@ func (cm *ConnectionManager[TUserData]) ServeHTTP(w http.ResponseWriter, r *http
 		if err := cm.callbacks.OnConnect(connection); err != nil {
 			logger.Errorf(componentLog, "Error en callback OnConnect para UserID %d: %v. Cerrando conexión.", userID, err)
 			connection.Close()
-			return
+			return nil
+		}
+	}
+
+	var restoredTopics []string
+	if session != nil {
+		previous := session.attach(connection.serial)
+		if resumed {
+			// Los mensajes en vuelo se reenvían por esta conexión y sus acks llegarán por ella.
+			cm.transferClientAcks(previous, connection.serial)
+			restoredTopics = cm.subscriptions.restore(connection, session.takeTopics())
 		}
 	}
 
 	go connection.readPump()
 	go connection.writePump()
 
+	connection.sendMessageLimits()
+	connection.sendSessionInfo(resumed, lastPID, restoredTopics)
+
 	logger.Infof(componentLog, "Pumps de lectura/escritura iniciadas para UserID %d", userID)
+	return connection
 }
 
 // Close cierra la conexión WebSocket y cancela su contexto.
@@ -211,20 +312,37 @@ func (c *Connection[TUserData]) Close() {
 	logger.Infof(componentLog, "Conexión cerrada explícitamente para UserID %d", c.ID)
 }
 
+// CloseWithCode envía al cliente un frame de cierre con el código (RFC 6455 o 4000-4999) y el
+// motivo indicados y cierra la conexión, para que el cliente sepa por qué se cerró.
+func (c *Connection[TUserData]) CloseWithCode(code int, reason string) {
+	deadline := time.Now().Add(c.manager.config.WriteWait)
+	if err := c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), deadline); err != nil {
+		logger.Debugf(componentLog, "No se pudo enviar el frame de cierre %d a UserID %d: %v", code, c.ID, err)
+	}
+	c.Close()
+}
+
 func (c *Connection[TUserData]) readPump() {
 	defer func() {
 		logger.Infof(componentLog, "readPump: Finalizando para UserID %d", c.ID)
-		c.manager.unregisterConnection(c, errors.New("readPump finalizado"))
+		disconnectErr := errors.New("readPump finalizado")
+		if c.evicted.Load() {
+			disconnectErr = ErrIdleEviction
+		}
+		c.manager.unregisterConnection(c, disconnectErr)
 		c.cancel()
 		c.conn.Close()
 	}()
 
-	c.conn.SetReadLimit(c.manager.config.MaxMessageSize)
+	// Los tipos con límite propio mayor pueden llegar en un solo frame: el límite de cada tipo se
+	// comprueba en handleIncoming.
+	c.conn.SetReadLimit(c.manager.maxMessageLimit())
 	if err := c.conn.SetReadDeadline(time.Now().Add(c.manager.config.PongWait)); err != nil {
 		logger.Errorf(componentLog, "readPump: Error al establecer ReadDeadline inicial para UserID %d: %v", c.ID, err)
 		return
 	}
-	c.conn.SetPongHandler(func(string) error {
+	c.conn.SetPongHandler(func(appData string) error {
+		c.handlePong(appData)
 		if err := c.conn.SetReadDeadline(time.Now().Add(c.manager.config.PongWait)); err != nil {
 			logger.Errorf(componentLog, "readPump: Error al establecer ReadDeadline en PongHandler para UserID %d: %v", c.ID, err)
 			return err
@@ -250,47 +368,115 @@ func (c *Connection[TUserData]) readPump() {
 				return
 			}
 
-			var clientMsg types.ClientToServerMessage
-			if err := json.Unmarshal(messageBytes, &clientMsg); err != nil {
-				logger.Errorf(componentLog, "readPump: Error al deserializar mensaje de UserID %d: %v. Mensaje: %s", c.ID, err, string(messageBytes))
-				c.SendErrorNotification(clientMsg.PID, 0, fmt.Sprintf("Error deserializando tu mensaje: %v", err))
-				continue
-			}
+			c.counters.messagesReceived.Add(1)
+			c.counters.bytesReceived.Add(int64(len(messageBytes)))
+			c.handleIncoming(messageBytes, false)
+		}
+	}
+}
 
-			logger.Infof(componentLog, "readPump: Mensaje recibido de UserID %d, Tipo: %s, PID: %s", c.ID, clientMsg.Type, clientMsg.PID)
+// handleIncoming procesa un mensaje del cliente, leído de la conexión o reensamblado a partir
+// de sus fragmentos (reassembled).
+func (c *Connection[TUserData]) handleIncoming(messageBytes []byte, reassembled bool) {
+	var clientMsg types.ClientToServerMessage
+	err := json.Unmarshal(messageBytes, &clientMsg)
+	// Los heartbeats no cuentan como actividad: un cliente que solo los envía sigue inactivo
+	if err != nil || clientMsg.Type != types.MessageTypeHeartbeat {
+		c.touch()
+	}
+	if err != nil {
+		logger.Errorf(componentLog, "handleIncoming: Error al deserializar mensaje de UserID %d: %v. Mensaje: %s", c.ID, err, string(messageBytes))
+		c.SendErrorNotification(clientMsg.PID, 0, fmt.Sprintf("Error deserializando tu mensaje: %v", err))
+		return
+	}
 
-			if clientMsg.Type == types.MessageTypeClientAck {
-				c.manager.handleClientAck(clientMsg)
-				continue
-			}
+	if !validPID(clientMsg.PID) {
+		logger.Warnf(componentLog, "handleIncoming: PID inválido (%d bytes) en mensaje %s de UserID %d", len(clientMsg.PID), clientMsg.Type, c.ID)
+		c.counters.pidRejections.Add(1)
+		c.SendAppError("", apperrors.InvalidPID,
+			fmt.Sprintf("el PID admite hasta %d letras, dígitos, '-', '_', '.' o ':'", MaxPIDLength))
+		return
+	}
 
-			// Si el mensaje del cliente tiene un PID y este PID está en nuestro mapa de respuestas pendientes,
-			// entonces este mensaje es una respuesta a una solicitud que el servidor hizo previamente.
-			if clientMsg.PID != "" {
-				if pending, loaded := c.manager.pendingServerResponses.Load(clientMsg.PID); loaded {
-					if pResp, castOk := pending.(*types.PendingServerResponse); castOk {
-						select {
-						case pResp.ResponseChan <- clientMsg: // Enviar la respuesta completa del cliente
-							logger.Infof(componentLog, "readPump: Respuesta del cliente para PID %s reenviada al solicitante interno.", clientMsg.PID)
-							// El solicitante (SendRequestAndWaitClientResponse) es responsable de eliminar de pendingServerResponses.
-						default:
-							logger.Warnf(componentLog, "readPump: Canal de ResponseChan para PID %s bloqueado o cerrado.", clientMsg.PID)
-						}
-						continue // Mensaje manejado como respuesta, no pasar a ProcessClientMessage general
-					} else {
-						logger.Errorf(componentLog, "readPump: Error al castear PendingServerResponse para PID %s.", clientMsg.PID)
-						// No continuar, podría ser un mensaje normal que coincida con un PID antiguo.
-					}
-				}
-			}
+	logger.Infof(componentLog, "handleIncoming: Mensaje recibido de UserID %d, Tipo: %s, PID: %s", c.ID, clientMsg.Type, clientMsg.PID)
+	defer c.recoverMessagePanic(clientMsg)
+
+	if limit := c.manager.messageLimit(clientMsg.Type); int64(len(messageBytes)) > limit {
+		logger.Warnf(componentLog, "handleIncoming: Mensaje %s de UserID %d demasiado grande (%d de %d bytes)", clientMsg.Type, c.ID, len(messageBytes), limit)
+		c.SendAppError(clientMsg.PID, apperrors.MessageTooLarge,
+			fmt.Sprintf("el mensaje %s ocupa %d bytes y el máximo es %d", clientMsg.Type, len(messageBytes), limit))
+		return
+	}
+
+	if clientMsg.Type == types.MessageTypeChunk {
+		if reassembled {
+			c.SendAppError(clientMsg.PID, apperrors.InvalidChunk, "un mensaje fragmentado no puede contener otro chunk")
+			return
+		}
+		c.handleChunk(clientMsg)
+		return
+	}
+
+	if clientMsg.Type == types.MessageTypeClientAck {
+		c.manager.handleClientAck(c, clientMsg)
+		return
+	}
+
+	if clientMsg.Type == types.MessageTypeHeartbeat {
+		c.handleHeartbeat(clientMsg)
+		return
+	}
+
+	if clientMsg.Type == types.MessageTypeSubscribe || clientMsg.Type == types.MessageTypeUnsubscribe {
+		c.handleSubscriptionMessage(clientMsg)
+		return
+	}
 
-			// Procesar otros tipos de mensajes a través del callback (si no fue una respuesta manejada arriba)
-			if err := c.manager.callbacks.ProcessClientMessage(c, clientMsg); err != nil {
-				logger.Errorf(componentLog, "readPump: Error en callback ProcessClientMessage para UserID %d, PID %s: %v", c.ID, clientMsg.PID, err)
-				c.SendErrorNotification(clientMsg.PID, 0, fmt.Sprintf("Error procesando tu mensaje: %v", err))
+	// Si el mensaje del cliente tiene un PID y este PID está en nuestro mapa de respuestas pendientes,
+	// entonces este mensaje es una respuesta a una solicitud que el servidor hizo previamente.
+	// Si la solicitud se envió a otra conexión, el mensaje se procesa como uno normal.
+	if clientMsg.PID != "" {
+		if pending, loaded := c.manager.pendingServerResponses.Load(clientMsg.PID); loaded {
+			if pResp, castOk := pending.(*types.PendingServerResponse); castOk && pResp.ConnectionID != c.serial {
+				logger.Warnf(componentLog, "handleIncoming: UserID %d respondió con el PID %s de una solicitud enviada a otra conexión; se ignora como respuesta.", c.ID, clientMsg.PID)
+				c.counters.pidRejections.Add(1)
+			} else if castOk {
+				select {
+				case pResp.ResponseChan <- clientMsg: // Enviar la respuesta completa del cliente
+					logger.Infof(componentLog, "handleIncoming: Respuesta del cliente para PID %s reenviada al solicitante interno.", clientMsg.PID)
+					// El solicitante (SendRequestAndWaitClientResponse) es responsable de eliminar de pendingServerResponses.
+				default:
+					logger.Warnf(componentLog, "handleIncoming: Canal de ResponseChan para PID %s bloqueado o cerrado.", clientMsg.PID)
+				}
+				return // Mensaje manejado como respuesta, no pasar a ProcessClientMessage general
+			} else {
+				logger.Errorf(componentLog, "handleIncoming: Error al castear PendingServerResponse para PID %s.", clientMsg.PID)
+				// No continuar, podría ser un mensaje normal que coincida con un PID antiguo.
 			}
 		}
 	}
+
+	// Procesar otros tipos de mensajes a través del callback (si no fue una respuesta manejada arriba)
+	if err := c.manager.callbacks.ProcessClientMessage(c, clientMsg); err != nil {
+		logger.Errorf(componentLog, "handleIncoming: Error en callback ProcessClientMessage para UserID %d, PID %s: %v", c.ID, clientMsg.PID, err)
+		c.SendErrorNotification(clientMsg.PID, 0, fmt.Sprintf("Error procesando tu mensaje: %v", err))
+	}
+}
+
+// recoverMessagePanic evita que un pánico al procesar msg termine readPump y cierre la
+// conexión sin diagnóstico: registra la traza, responde con un error interno y avisa a
+// Callbacks.OnPanic.
+func (c *Connection[TUserData]) recoverMessagePanic(msg types.ClientToServerMessage) {
+	rec := recover()
+	if rec == nil {
+		return
+	}
+	logger.Errorf(componentLog, "Pánico recuperado procesando mensaje de UserID %d, Tipo: %s, PID: %s: %v\n%s",
+		c.ID, msg.Type, msg.PID, rec, debug.Stack())
+	c.SendAppError(msg.PID, apperrors.Internal, "Error interno procesando tu mensaje")
+	if onPanic := c.manager.callbacks.OnPanic; onPanic != nil {
+		onPanic(c, msg, rec)
+	}
 }
 
 func (c *Connection[TUserData]) writePump() {
@@ -301,6 +487,18 @@ func (c *Connection[TUserData]) writePump() {
 		c.conn.Close()
 	}()
 
+	var heartbeatC <-chan time.Time
+	if interval := c.manager.config.HeartbeatInterval; interval > 0 {
+		heartbeatTicker := time.NewTicker(interval)
+		defer heartbeatTicker.Stop()
+		heartbeatC = heartbeatTicker.C
+		// El primero va al conectar, para que el cliente conozca el intervalo y ajuste su reloj
+		if err := c.writeHeartbeat("", types.HeartbeatPayload{}); err != nil {
+			logger.Errorf(componentLog, "writePump: Error al enviar heartbeat a UserID %d: %v", c.ID, err)
+			return
+		}
+	}
+
 	for {
 		select {
 		case <-c.ctx.Done():
@@ -328,16 +526,33 @@ func (c *Connection[TUserData]) writePump() {
 
 			if err := c.conn.WriteMessage(websocket.TextMessage, messageBytes); err != nil {
 				logger.Errorf(componentLog, "writePump: Error de escritura para UserID %d, PID %s: %v", c.ID, message.PID, err)
+				c.bufferForResume(message)
 				return
 			}
+			c.counters.messagesSent.Add(1)
+			c.counters.bytesSent.Add(int64(len(messageBytes)))
+			// Se retiene aunque se haya escrito: el cliente puede perderlo si la red cae antes de procesarlo.
+			c.bufferForResume(message)
 			logger.Infof(componentLog, "writePump: Mensaje enviado a UserID %d, Tipo: %s, PID: %s", c.ID, message.Type, message.PID)
 
-		case <-pingTicker.C:
-			if err := c.conn.SetWriteDeadline(time.Now().Add(c.manager.config.WriteWait)); err != nil {
-				logger.Errorf(componentLog, "writePump: Error al establecer WriteDeadline para Ping (UserID %d): %v", c.ID, err)
-				continue
+		case req := <-c.heartbeats:
+			err := c.writeHeartbeat(req.pid, types.HeartbeatPayload{
+				ClientTime: req.clientTime,
+				ReceivedAt: req.receivedAt.UnixMilli(),
+			})
+			if err != nil {
+				logger.Errorf(componentLog, "writePump: Error al responder el heartbeat %s de UserID %d: %v", req.pid, c.ID, err)
+				return
+			}
+
+		case <-heartbeatC:
+			if err := c.writeHeartbeat("", types.HeartbeatPayload{}); err != nil {
+				logger.Errorf(componentLog, "writePump: Error al enviar heartbeat a UserID %d: %v", c.ID, err)
+				return
 			}
-			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+
+		case <-pingTicker.C:
+			if err := c.writePing(); err != nil {
 				logger.Errorf(componentLog, "writePump: Error al enviar Ping a UserID %d: %v", c.ID, err)
 				return
 			}
@@ -347,8 +562,17 @@ func (c *Connection[TUserData]) writePump() {
 
 // unregisterConnection es llamado para limpiar una conexión del manager.
 func (cm *ConnectionManager[TUserData]) unregisterConnection(conn *Connection[TUserData], disconnectErr error) {
+	// Se quitan las suscripciones antes de cerrar SendChan para que Publish no la elija.
+	topics := cm.subscriptions.removeConnection(conn)
+	conn.chunks.discard()
 	close(conn.SendChan)
 
+	// Los mensajes que quedaron en cola sin escribirse se conservan para una posible reanudación.
+	for msg := range conn.SendChan {
+		conn.bufferForResume(msg)
+	}
+	cm.detachSession(conn.session, topics)
+
 	// Usar el mutex para modificar userConnections
 	cm.mu.Lock()
 	if conns, exists := cm.userConnections[conn.ID]; exists {
@@ -415,6 +639,43 @@ func (c *Connection[TUserData]) SendErrorNotification(originalPID string, code i
 	}
 }
 
+// SendAppError envía una notificación de error del catálogo apperrors: el status HTTP
+// equivalente va en Code y el código del catálogo en ErrorCode.
+func (c *Connection[TUserData]) SendAppError(originalPID string, code apperrors.Code, message string) {
+	errMsg := types.ServerToClientMessage{
+		PID:  c.manager.callbacks.GeneratePID(),
+		Type: types.MessageTypeErrorNotification,
+		Error: &types.ErrorPayload{
+			OriginalPID: originalPID,
+			Code:        apperrors.Status(code),
+			Message:     message,
+			ErrorCode:   string(code),
+		},
+	}
+	if err := c.SendMessage(errMsg); err != nil {
+		logger.Errorf(componentLog, "SendAppError: No se pudo enviar notificación de error a UserID %d para PID original %s: %v", c.ID, originalPID, err)
+	}
+}
+
+// SendValidationError envía una notificación de error 400 con la lista de campos inválidos
+// del payload del mensaje originalPID.
+func (c *Connection[TUserData]) SendValidationError(originalPID string, message string, fields []types.FieldError) {
+	errMsg := types.ServerToClientMessage{
+		PID:  c.manager.callbacks.GeneratePID(),
+		Type: types.MessageTypeErrorNotification,
+		Error: &types.ErrorPayload{
+			OriginalPID: originalPID,
+			Code:        apperrors.Status(apperrors.InvalidPayload),
+			Message:     message,
+			ErrorCode:   string(apperrors.InvalidPayload),
+			Fields:      fields,
+		},
+	}
+	if err := c.SendMessage(errMsg); err != nil {
+		logger.Errorf(componentLog, "SendValidationError: No se pudo enviar error de validación a UserID %d para PID original %s: %v", c.ID, originalPID, err)
+	}
+}
+
 // SendServerAck envía un ack al cliente confirmando la recepción/procesamiento de un mensaje del cliente.
 func (c *Connection[TUserData]) SendServerAck(acknowledgedPID string, status string, ackErr error) {
 	payload := types.AckPayload{
@@ -434,8 +695,9 @@ func (c *Connection[TUserData]) SendServerAck(acknowledgedPID string, status str
 	}
 }
 
-// handleClientAck procesa un ClientAck recibido.
-func (cm *ConnectionManager[TUserData]) handleClientAck(ackMsg types.ClientToServerMessage) {
+// handleClientAck procesa un ClientAck recibido por conn. Descarta los acks de PIDs que conn
+// ya confirmó y los de mensajes enviados a otro usuario.
+func (cm *ConnectionManager[TUserData]) handleClientAck(conn *Connection[TUserData], ackMsg types.ClientToServerMessage) {
 	// Necesitamos decodificar el payload correctamente ya que json.Unmarshal a interface{} crea un map[string]interface{}
 	var ackPayload types.AckPayload
 	payloadBytes, err := json.Marshal(ackMsg.Payload)
@@ -448,10 +710,22 @@ func (cm *ConnectionManager[TUserData]) handleClientAck(ackMsg types.ClientToSer
 		return
 	}
 
+	if conn.ackedPIDs.contains(ackPayload.AcknowledgedPID) {
+		logger.Warnf(componentLog, "handleClientAck: UserID %d repitió el ack del PID %s; se descarta.", conn.ID, ackPayload.AcknowledgedPID)
+		conn.counters.ackReplays.Add(1)
+		return
+	}
+
 	if pending, loaded := cm.pendingClientAcks.Load(ackPayload.AcknowledgedPID); loaded {
 		if pAck, castOk := pending.(*types.PendingClientAck); castOk {
+			if pAck.UserID != conn.ID || (!pAck.AnyConnection && pAck.ConnectionID.Load() != conn.serial) {
+				logger.Warnf(componentLog, "handleClientAck: UserID %d confirmó el PID %s, enviado a otra conexión (UserID %d); se descarta.", conn.ID, ackPayload.AcknowledgedPID, pAck.UserID)
+				conn.counters.pidRejections.Add(1)
+				return
+			}
 			select {
 			case pAck.AckChan <- ackMsg:
+				conn.ackedPIDs.add(ackPayload.AcknowledgedPID)
 				logger.Infof(componentLog, "handleClientAck: ClientAck para PID %s (mensaje original %s) reenviado al solicitante.", ackMsg.PID, ackPayload.AcknowledgedPID)
 			default:
 				logger.Warnf(componentLog, "handleClientAck: Canal de AckChan para PID original %s bloqueado o cerrado.", ackPayload.AcknowledgedPID)
@@ -477,6 +751,8 @@ func (cm *ConnectionManager[TUserData]) cleanupRoutine() {
 			return
 		case <-ticker.C:
 			now := time.Now()
+			cm.expireSessions(now)
+			cm.evictIdle(now)
 			cm.pendingClientAcks.Range(func(key, value interface{}) bool {
 				pid := key.(string)
 				pAck, ok := value.(*types.PendingClientAck)
@@ -485,10 +761,11 @@ func (cm *ConnectionManager[TUserData]) cleanupRoutine() {
 					cm.pendingClientAcks.Delete(pid) // Eliminar el elemento corrupto
 					return true
 				}
-				if now.Sub(pAck.Timestamp) > cm.config.AckTimeout {
+				// CompareAndDelete: un reintento de SendForClientAck puede haber guardado ya otra
+				// espera con el mismo PID.
+				if now.Sub(pAck.Timestamp) > cm.config.AckTimeout && cm.pendingClientAcks.CompareAndDelete(pid, pAck) {
 					logger.Warnf(componentLog, "cleanupRoutine: Timeout para ClientAck PID %s. Eliminando y cerrando canal.", pid)
 					close(pAck.AckChan)
-					cm.pendingClientAcks.Delete(pid)
 				}
 				return true
 			})
@@ -539,6 +816,8 @@ func (cm *ConnectionManager[TUserData]) GetConnections(userID int64) ([]*Connect
 
 // SendMessageToUser envía un mensaje a un usuario específico si está conectado.
 func (cm *ConnectionManager[TUserData]) SendMessageToUser(userID int64, msg types.ServerToClientMessage) error {
+	cm.bufferForDetachedSessions(userID, msg)
+
 	conns, found := cm.GetConnections(userID)
 	if !found {
 		return fmt.Errorf("usuario %d no conectado o no encontrado", userID)
@@ -646,58 +925,155 @@ func (cm *ConnectionManager[TUserData]) BroadcastToUsers(userIDs []int64, msg ty
 	return errorsMap
 }
 
+// Errores de SendForClientAck. El error devuelto los envuelve con el PID del mensaje.
+var (
+	// ErrAckTimeout indica que el cliente no confirmó el mensaje en Config.AckTimeout.
+	ErrAckTimeout = errors.New("timeout esperando ClientAck")
+	// ErrAckConnectionClosed indica que la conexión se cerró sin poder reanudarse mientras se
+	// esperaba el ack. No se reintenta: los mensajes para un usuario desconectado los guarda
+	// OnDeadLetter.
+	ErrAckConnectionClosed = errors.New("conexión cerrada esperando ClientAck")
+)
+
 // SendForClientAck envía un mensaje a la conexión especificada y espera un ClientAck.
 // El PID en msgToSend se usará para la correlación. Si está vacío, se generará uno.
-// Devuelve el ClientToServerMessage de ack o un error si hay timeout o fallo.
+// Si el ack no llega en AckTimeout reenvía el mensaje con el mismo PID, hasta AckMaxAttempts
+// envíos, esperando entre ellos AckBackoff, que se duplica en cada reintento hasta
+// AckMaxBackoff. El cliente debe confirmar también los PIDs repetidos aunque ya los haya
+// procesado. Si ningún envío se confirma se llama a Callbacks.OnDeadLetter.
+// Devuelve el ClientToServerMessage de ack o el error del último intento.
 func (cm *ConnectionManager[TUserData]) SendForClientAck(conn *Connection[TUserData], msgToSend types.ServerToClientMessage) (types.ClientToServerMessage, error) {
+	return cm.sendForClientAck(conn, msgToSend, false)
+}
+
+// SendForUserClientAck es SendForClientAck para un mensaje que el llamador envió también, con
+// el mismo PID, a las demás conexiones del usuario: se acepta el ack de cualquiera de ellas.
+// Los reintentos se envían solo a conn.
+func (cm *ConnectionManager[TUserData]) SendForUserClientAck(conn *Connection[TUserData], msgToSend types.ServerToClientMessage) (types.ClientToServerMessage, error) {
+	return cm.sendForClientAck(conn, msgToSend, true)
+}
+
+func (cm *ConnectionManager[TUserData]) sendForClientAck(conn *Connection[TUserData], msgToSend types.ServerToClientMessage, anyConnection bool) (types.ClientToServerMessage, error) {
 	if conn == nil {
 		return types.ClientToServerMessage{}, errors.New("conexión es nil")
 	}
 
-	pidToAck := msgToSend.PID
-	if pidToAck == "" {
-		pidToAck = cm.callbacks.GeneratePID()
-		msgToSend.PID = pidToAck // Asegurar que el mensaje saliente tenga el PID
+	if msgToSend.PID == "" {
+		msgToSend.PID = cm.callbacks.GeneratePID() // Asegurar que el mensaje saliente tenga el PID
+	}
+
+	maxAttempts := cm.config.AckMaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
 	}
+	backoff := cm.config.AckBackoff
 
+	var lastErr error
+	attempts := 0
+	for attempts < maxAttempts {
+		if attempts > 0 {
+			logger.Infof(componentLog, "SendForClientAck: Reintentando PID %s a UserID %d en %v (intento %d de %d).", msgToSend.PID, conn.ID, backoff, attempts+1, maxAttempts)
+			if !cm.waitAckBackoff(conn, backoff) {
+				lastErr = fmt.Errorf("%w (PID: %s)", ErrAckConnectionClosed, msgToSend.PID)
+				break
+			}
+			backoff *= 2
+			if cm.config.AckMaxBackoff > 0 && backoff > cm.config.AckMaxBackoff {
+				backoff = cm.config.AckMaxBackoff
+			}
+			conn.counters.ackRetries.Add(1)
+		}
+
+		attempts++
+		ack, err := cm.sendOnceForClientAck(conn, msgToSend, anyConnection)
+		if err == nil {
+			return ack, nil
+		}
+		lastErr = err
+		if errors.Is(err, ErrAckConnectionClosed) {
+			break
+		}
+	}
+
+	logger.Warnf(componentLog, "SendForClientAck: Mensaje PID %s a UserID %d sin ack tras %d intentos: %v", msgToSend.PID, conn.ID, attempts, lastErr)
+	conn.counters.deadLetters.Add(1)
+	if cm.callbacks.OnDeadLetter != nil {
+		cm.callbacks.OnDeadLetter(conn, msgToSend, attempts, lastErr)
+	}
+	return types.ClientToServerMessage{}, lastErr
+}
+
+// waitAckBackoff espera d entre dos intentos de SendForClientAck. Devuelve false si antes se
+// cierra el manager o la conexión sin sesión reanudable.
+func (cm *ConnectionManager[TUserData]) waitAckBackoff(conn *Connection[TUserData], d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-cm.ctx.Done():
+		return false
+	case <-cm.ackConnDone(conn):
+		return false
+	}
+}
+
+// ackConnDone devuelve el canal que indica que ya no se puede recibir el ack por la conexión.
+// Con sesión reanudable el mensaje se reenvía al reconectar, por lo que el ack puede llegar
+// por la nueva conexión: se devuelve nil y se sigue esperando.
+func (cm *ConnectionManager[TUserData]) ackConnDone(conn *Connection[TUserData]) <-chan struct{} {
+	if conn.session != nil {
+		return nil
+	}
+	return conn.ctx.Done()
+}
+
+// sendOnceForClientAck hace un intento de SendForClientAck: envía el mensaje y espera el ack
+// durante AckTimeout. Con anyConnection vale el ack de cualquier conexión del usuario.
+func (cm *ConnectionManager[TUserData]) sendOnceForClientAck(conn *Connection[TUserData], msgToSend types.ServerToClientMessage, anyConnection bool) (types.ClientToServerMessage, error) {
+	pidToAck := msgToSend.PID
 	ackChannel := make(chan types.ClientToServerMessage, 1) // Buffer de 1 para evitar bloqueo si el ack llega antes de que empecemos a escuchar
 	pendingAck := &types.PendingClientAck{
-		AckChan:   ackChannel,
-		Timestamp: time.Now(),
-		MessageID: pidToAck,
+		AckChan:       ackChannel,
+		Timestamp:     time.Now(),
+		MessageID:     pidToAck,
+		UserID:        conn.ID,
+		AnyConnection: anyConnection,
 	}
+	pendingAck.ConnectionID.Store(conn.serial)
 
+	// Cada intento guarda su propia espera. Al salir solo se borra la suya: con el mismo PID
+	// puede haber ya la del intento siguiente.
 	cm.pendingClientAcks.Store(pidToAck, pendingAck)
-	defer cm.pendingClientAcks.Delete(pidToAck) // Asegurar limpieza al salir
+	defer cm.pendingClientAcks.CompareAndDelete(pidToAck, pendingAck)
 
 	logger.Infof(componentLog, "SendForClientAck: Enviando mensaje (PID: %s) a UserID %d, esperando ClientAck.", pidToAck, conn.ID)
-	err := conn.SendMessage(msgToSend)
-	if err != nil {
-		close(ackChannel) // Cerrar para que el select de abajo no se bloquee si SendMessage falla
-		return types.ClientToServerMessage{}, fmt.Errorf("error al enviar mensaje inicial (PID: %s) a UserID %d: %w", pidToAck, conn.ID, err)
+	if err := conn.SendMessage(msgToSend); err != nil {
+		return types.ClientToServerMessage{}, fmt.Errorf("error al enviar mensaje (PID: %s) a UserID %d: %w", pidToAck, conn.ID, err)
 	}
 
+	timer := time.NewTimer(cm.config.AckTimeout)
+	defer timer.Stop()
+
 	select {
 	case ack, ok := <-ackChannel:
 		if !ok {
 			// Canal cerrado por cleanupRoutine debido a timeout
 			logger.Warnf(componentLog, "SendForClientAck: Canal de Ack cerrado (probablemente timeout) para PID %s, UserID %d.", pidToAck, conn.ID)
-			return types.ClientToServerMessage{}, fmt.Errorf("timeout o error esperando ClientAck para PID %s", pidToAck)
+			conn.counters.ackTimeouts.Add(1)
+			return types.ClientToServerMessage{}, fmt.Errorf("%w (PID: %s)", ErrAckTimeout, pidToAck)
 		}
 		logger.Infof(componentLog, "SendForClientAck: ClientAck recibido para PID %s de UserID %d.", pidToAck, conn.ID)
 		return ack, nil
 
-	case <-time.After(cm.config.AckTimeout):
+	case <-timer.C:
 		logger.Warnf(componentLog, "SendForClientAck: Timeout esperando ClientAck para PID %s de UserID %d.", pidToAck, conn.ID)
-		// No necesitamos cerrar ackChannel aquí porque el defer de pendingClientAcks.Delete lo hará la cleanupRoutine eventualmente,
-		// o si el ack llega tarde y la cleanupRoutine ya lo eliminó, el select en handleClientAck no encontrará el canal.
-		// Sin embargo, es bueno tener un cleanup explícito si la función retorna por timeout.
-		// La cleanupRoutine cerrará el canal si detecta el timeout.
-		return types.ClientToServerMessage{}, fmt.Errorf("timeout esperando ClientAck para PID %s", pidToAck)
+		conn.counters.ackTimeouts.Add(1)
+		return types.ClientToServerMessage{}, fmt.Errorf("%w (PID: %s)", ErrAckTimeout, pidToAck)
 
-	case <-conn.ctx.Done(): // Si la conexión se cierra mientras esperamos el ack
+	case <-cm.ackConnDone(conn): // Si la conexión se cierra mientras esperamos el ack (y no puede reanudarse)
 		logger.Warnf(componentLog, "SendForClientAck: Contexto de conexión para UserID %d cerrado mientras se esperaba Ack para PID %s.", conn.ID, pidToAck)
-		return types.ClientToServerMessage{}, fmt.Errorf("conexión cerrada esperando ClientAck para PID %s", pidToAck)
+		return types.ClientToServerMessage{}, fmt.Errorf("%w (PID: %s)", ErrAckConnectionClosed, pidToAck)
 	}
 }
 
@@ -720,6 +1096,7 @@ func (cm *ConnectionManager[TUserData]) SendRequestAndWaitClientResponse(conn *C
 	pendingReq := &types.PendingServerResponse{ // Usamos PendingServerResponse, pero es para una *respuesta del cliente*
 		ResponseChan: responseChannel,
 		Timestamp:    time.Now(),
+		ConnectionID: conn.serial,
 	}
 
 	cm.pendingServerResponses.Store(requestPID, pendingReq)
@@ -751,6 +1128,36 @@ func (cm *ConnectionManager[TUserData]) SendRequestAndWaitClientResponse(conn *C
 	}
 }
 
+// Drain cierra todas las conexiones con el código 1012 (Service Restart) repartiendo los
+// cierres a lo largo de spread, para que los clientes no reconecten todos a la vez al proceso
+// que sustituye a este. Si ctx termina antes, cierra de golpe las que quedan. Devuelve el
+// número de conexiones cerradas.
+func (cm *ConnectionManager[TUserData]) Drain(ctx context.Context, spread time.Duration) int {
+	cm.mu.RLock()
+	allConns := make([]*Connection[TUserData], 0)
+	for _, userConns := range cm.userConnections {
+		allConns = append(allConns, userConns...)
+	}
+	cm.mu.RUnlock()
+	if len(allConns) == 0 {
+		return 0
+	}
+
+	interval := spread / time.Duration(len(allConns))
+	logger.Infof(componentLog, "Drain: cerrando %d conexiones en %s", len(allConns), spread)
+	for i, conn := range allConns {
+		if i > 0 && interval > 0 {
+			select {
+			case <-ctx.Done():
+				interval = 0
+			case <-time.After(interval):
+			}
+		}
+		conn.CloseWithReason(websocket.CloseServiceRestart, types.CloseReason{Reason: "service_restart", Reconnect: true})
+	}
+	return len(allConns)
+}
+
 // Shutdown cierra ordenadamente el ConnectionManager y todas las conexiones activas.
 func (cm *ConnectionManager[TUserData]) Shutdown(ctx context.Context) error {
 	logger.Infof(componentLog, "Iniciando shutdown del ConnectionManager...")
@@ -774,7 +1181,12 @@ func (cm *ConnectionManager[TUserData]) Shutdown(ctx context.Context) error {
 		go func(c *Connection[TUserData]) {
 			defer wg.Done()
 			logger.Infof(componentLog, "Shutdown: Cerrando conexión para UserID %d...", c.ID)
-			c.Close() // Esto llama a c.cancel() y c.conn.Close()
+			// Esto llama a c.cancel() y c.conn.Close()
+			c.CloseWithReason(CloseServerShutdown, types.CloseReason{
+				Reason:     "server_shutdown",
+				Reconnect:  true,
+				RetryAfter: RetryAfterSeconds(cm.config.ShutdownRetryAfter),
+			})
 			// La unregisterConnection se llamará desde el defer de readPump.
 		}(conn)
 	}
@@ -824,6 +1236,39 @@ func (cm *ConnectionManager[TUserData]) IsUserOnline(userID int64) bool {
 	return exists && len(conns) > 0
 }
 
+// ConnectionCount devuelve el número de conexiones activas (dispositivos) de un usuario.
+func (cm *ConnectionManager[TUserData]) ConnectionCount(userID int64) int {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return len(cm.userConnections[userID])
+}
+
+// SendMessageToOtherDevices envía un mensaje a las demás conexiones del mismo usuario,
+// excluyendo la conexión de origen. Se usa para sincronizar acciones entre dispositivos
+// (ej. un mensaje enviado o leído desde el móvil debe reflejarse en el navegador).
+// No devuelve error si el usuario no tiene otros dispositivos conectados.
+func (cm *ConnectionManager[TUserData]) SendMessageToOtherDevices(from *Connection[TUserData], msg types.ServerToClientMessage) error {
+	if from == nil {
+		return errors.New("conexión de origen es nil")
+	}
+	conns, found := cm.GetConnections(from.ID)
+	if !found {
+		return nil
+	}
+
+	var lastErr error
+	for _, c := range conns {
+		if c == from {
+			continue
+		}
+		if err := c.SendMessage(msg); err != nil {
+			lastErr = err
+			logger.Errorf(componentLog, "SendMessageToOtherDevices: Error enviando a otro dispositivo de UserID %d: %v", c.ID, err)
+		}
+	}
+	return lastErr
+}
+
 // GetUserCount devuelve el número de usuarios únicos con al menos una conexión activa.
 func (cm *ConnectionManager[TUserData]) GetUserCount() int {
 	cm.mu.RLock()
@@ -831,6 +1276,19 @@ func (cm *ConnectionManager[TUserData]) GetUserCount() int {
 	return len(cm.userConnections)
 }
 
+// OnlineUserIDs devuelve los UserID con al menos una conexión activa en esta instancia.
+func (cm *ConnectionManager[TUserData]) OnlineUserIDs() []int64 {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	userIDs := make([]int64, 0, len(cm.userConnections))
+	for userID, conns := range cm.userConnections {
+		if len(conns) > 0 {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	return userIDs
+}
+
 // HandlePeerToPeerMessage maneja el envío de mensajes directos entre usuarios.
 // Verifica si el destinatario está en línea y envía el mensaje si es posible.
 func (cm *ConnectionManager[TUserData]) HandlePeerToPeerMessage(fromConn *Connection[TUserData], toUserID int64, msg types.ServerToClientMessage) error {
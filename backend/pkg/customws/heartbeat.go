@@ -0,0 +1,101 @@
+package customws
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+	"github.com/gorilla/websocket"
+)
+
+// heartbeatRequest es un heartbeat del cliente pendiente de respuesta.
+type heartbeatRequest struct {
+	pid        string
+	clientTime int64
+	receivedAt time.Time
+}
+
+// handleHeartbeat encola la respuesta a un heartbeat del cliente. La escribe writePump para
+// que ServerTime sea la hora de envío y para que no se retenga para la reanudación. Si ya hay
+// una respuesta pendiente, el heartbeat se descarta.
+func (c *Connection[TUserData]) handleHeartbeat(msg types.ClientToServerMessage) {
+	req := heartbeatRequest{pid: msg.PID, receivedAt: time.Now()}
+	var payload types.HeartbeatRequestPayload
+	if msg.Payload != nil {
+		raw, err := json.Marshal(msg.Payload)
+		if err == nil {
+			err = json.Unmarshal(raw, &payload)
+		}
+		if err != nil {
+			c.SendAppError(msg.PID, apperrors.InvalidPayload, fmt.Sprintf("payload de heartbeat inválido: %v", err))
+			return
+		}
+	}
+	req.clientTime = payload.ClientTime
+
+	select {
+	case c.heartbeats <- req:
+	default:
+		logger.Debugf(componentLog, "handleHeartbeat: Respuesta pendiente para UserID %d, se descarta el heartbeat %s", c.ID, msg.PID)
+	}
+}
+
+// writeHeartbeat escribe un heartbeat con la hora del servidor y las estadísticas de la
+// conexión. Solo lo llama writePump. pid vacío genera uno nuevo.
+func (c *Connection[TUserData]) writeHeartbeat(pid string, payload types.HeartbeatPayload) error {
+	if pid == "" {
+		pid = c.manager.callbacks.GeneratePID()
+	}
+	payload.IntervalSeconds = int(c.manager.config.HeartbeatInterval.Seconds())
+	payload.Stats = types.HeartbeatStats{
+		RTTMillis:        c.RTT().Milliseconds(),
+		MessagesReceived: c.counters.messagesReceived.Load(),
+		MessagesSent:     c.counters.messagesSent.Load(),
+		SendQueueDepth:   len(c.SendChan),
+	}
+	payload.ServerTime = time.Now().UnixMilli()
+
+	messageBytes, err := json.Marshal(types.ServerToClientMessage{PID: pid, Type: types.MessageTypeHeartbeat, Payload: payload})
+	if err != nil {
+		return err
+	}
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.manager.config.WriteWait)); err != nil {
+		return err
+	}
+	if err := c.conn.WriteMessage(websocket.TextMessage, messageBytes); err != nil {
+		return err
+	}
+	c.counters.messagesSent.Add(1)
+	c.counters.bytesSent.Add(int64(len(messageBytes)))
+	return nil
+}
+
+// writePing envía un ping con la hora de envío como datos, para medir la ida y vuelta al
+// recibir el pong (ver handlePong).
+func (c *Connection[TUserData]) writePing() error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.manager.config.WriteWait)); err != nil {
+		return err
+	}
+	return c.conn.WriteMessage(websocket.PingMessage, []byte(strconv.FormatInt(time.Now().UnixNano(), 10)))
+}
+
+// handlePong registra la ida y vuelta del ping al que responde appData. Los pongs sin la hora
+// (no solicitados) no la cambian.
+func (c *Connection[TUserData]) handlePong(appData string) {
+	sentAt, err := strconv.ParseInt(appData, 10, 64)
+	if err != nil {
+		return
+	}
+	if rtt := time.Now().UnixNano() - sentAt; rtt >= 0 {
+		c.rtt.Store(rtt)
+	}
+}
+
+// RTT devuelve la ida y vuelta del último ping del servidor, o 0 si aún no llegó ningún pong.
+func (c *Connection[TUserData]) RTT() time.Duration {
+	return time.Duration(c.rtt.Load())
+}
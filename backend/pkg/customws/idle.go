@@ -0,0 +1,80 @@
+package customws
+
+import (
+	"errors"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// ErrIdleEviction es el error con el que se llama a OnDisconnect cuando la conexión se cerró por
+// inactividad.
+var ErrIdleEviction = errors.New("conexión cerrada por inactividad")
+
+// touch registra actividad del cliente. Los pongs no cuentan: un navegador responde a los
+// pings aunque la pestaña esté abandonada.
+func (c *Connection[TUserData]) touch() {
+	c.lastActivity.Store(time.Now().UnixNano())
+	c.idleWarned.Store(false)
+}
+
+// LastActivity devuelve la hora del último mensaje recibido del cliente (o de la conexión, si
+// aún no envió ninguno).
+func (c *Connection[TUserData]) LastActivity() time.Time {
+	return time.Unix(0, c.lastActivity.Load())
+}
+
+// IdleEvictions devuelve el número de conexiones cerradas por inactividad desde el arranque.
+func (cm *ConnectionManager[TUserData]) IdleEvictions() int64 {
+	return cm.idleEvictions.Load()
+}
+
+// evictIdle avisa a las conexiones que se acercan a Config.IdleTimeout sin mensajes del cliente
+// y cierra las que lo superan. Lo llama cleanupRoutine.
+func (cm *ConnectionManager[TUserData]) evictIdle(now time.Time) {
+	timeout := cm.config.IdleTimeout
+	if timeout <= 0 {
+		return
+	}
+	warnAfter := timeout - cm.config.IdleWarning
+	if cm.config.IdleWarning <= 0 || warnAfter <= 0 {
+		warnAfter = timeout
+	}
+
+	cm.mu.RLock()
+	conns := make([]*Connection[TUserData], 0, len(cm.userConnections))
+	for _, userConns := range cm.userConnections {
+		conns = append(conns, userConns...)
+	}
+	cm.mu.RUnlock()
+
+	for _, conn := range conns {
+		idle := now.Sub(conn.LastActivity())
+		switch {
+		case idle >= timeout:
+			if conn.evicted.Swap(true) {
+				continue
+			}
+			cm.idleEvictions.Add(1)
+			logger.Infof(componentLog, "evictIdle: Cerrando conexión de UserID %d tras %s sin mensajes", conn.ID, idle.Truncate(time.Second))
+			conn.CloseWithReason(CloseIdle, types.CloseReason{Reason: "idle"})
+		case idle >= warnAfter && !conn.idleWarned.Swap(true):
+			conn.sendIdleWarning(timeout - idle)
+		}
+	}
+}
+
+// sendIdleWarning avisa al cliente de que la conexión se cerrará si no envía ningún mensaje.
+func (c *Connection[TUserData]) sendIdleWarning(remaining time.Duration) {
+	msg := types.ServerToClientMessage{
+		PID:  c.manager.callbacks.GeneratePID(),
+		Type: types.MessageTypeIdleWarning,
+		Payload: types.IdleWarningPayload{
+			ClosesInSeconds: int(remaining.Round(time.Second).Seconds()),
+		},
+	}
+	if err := c.SendMessage(msg); err != nil {
+		logger.Warnf(componentLog, "sendIdleWarning: No se pudo avisar de la inactividad a UserID %d: %v", c.ID, err)
+	}
+}
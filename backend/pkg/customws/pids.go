@@ -0,0 +1,66 @@
+package customws
+
+import (
+	"sync"
+)
+
+// MaxPIDLength es la longitud máxima del PID de un mensaje del cliente.
+const MaxPIDLength = 128
+
+// recentAckCapacity es el número de PIDs confirmados que recuerda cada conexión para detectar
+// acks repetidos.
+const recentAckCapacity = 256
+
+// validPID indica si pid puede usarse como PID de un mensaje del cliente: vacío, o hasta
+// MaxPIDLength letras ASCII, dígitos y los caracteres "-", "_", ".", ":".
+func validPID(pid string) bool {
+	if len(pid) > MaxPIDLength {
+		return false
+	}
+	for i := 0; i < len(pid); i++ {
+		switch ch := pid[i]; {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		case ch == '-', ch == '_', ch == '.', ch == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// recentPIDs es un conjunto acotado de los últimos PIDs añadidos. Al llenarse olvida los más
+// antiguos.
+type recentPIDs struct {
+	mu    sync.Mutex
+	seen  map[string]struct{}
+	order []string // Anillo con los PIDs en orden de llegada
+	next  int
+}
+
+// add añade pid y devuelve false si ya estaba.
+func (r *recentPIDs) add(pid string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seen == nil {
+		r.seen = make(map[string]struct{}, recentAckCapacity)
+		r.order = make([]string, recentAckCapacity)
+	}
+	if _, dup := r.seen[pid]; dup {
+		return false
+	}
+	if old := r.order[r.next]; old != "" {
+		delete(r.seen, old)
+	}
+	r.order[r.next] = pid
+	r.next = (r.next + 1) % len(r.order)
+	r.seen[pid] = struct{}{}
+	return true
+}
+
+// contains indica si pid está en el conjunto.
+func (r *recentPIDs) contains(pid string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.seen[pid]
+	return ok
+}
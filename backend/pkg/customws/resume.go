@@ -0,0 +1,277 @@
+package customws
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// Parámetros de query con los que el cliente reanuda una sesión al reconectar.
+const (
+	ResumeTokenQueryParam = "resume_token"
+	LastPIDQueryParam     = "last_pid"
+)
+
+// bufferedMessage es un mensaje saliente retenido para poder reenviarlo al reanudar.
+type bufferedMessage struct {
+	msg types.ServerToClientMessage
+	at  time.Time
+}
+
+// resumableSession conserva los mensajes enviados a una conexión durante ResumeWindow,
+// de forma que un cliente que reconecta tras un corte breve no pierda los mensajes en vuelo.
+type resumableSession struct {
+	token  string
+	userID int64
+
+	mu         sync.Mutex
+	buffer     []bufferedMessage
+	pids       map[string]bool // PIDs presentes en buffer, para no duplicar mensajes
+	detachedAt time.Time       // cero mientras haya una conexión activa usando la sesión
+	topics     []string        // suscripciones de la conexión desconectada, para restaurarlas
+	connSerial uint64          // última conexión que usó la sesión
+}
+
+func newResumeToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// add guarda un mensaje en el buffer descartando duplicados por PID, los mensajes
+// más antiguos que la ventana y el exceso sobre el tamaño máximo.
+func (s *resumableSession) add(msg types.ServerToClientMessage, window time.Duration, maxSize int) {
+	// session_info lleva el token vigente: reenviarlo entregaría un token ya rotado.
+	if msg.PID == "" || msg.Type == types.MessageTypeSessionInfo {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pids[msg.PID] {
+		return
+	}
+	now := time.Now()
+	s.buffer = append(s.buffer, bufferedMessage{msg: msg, at: now})
+	s.pids[msg.PID] = true
+
+	drop := 0
+	for drop < len(s.buffer) && (now.Sub(s.buffer[drop].at) > window || len(s.buffer)-drop > maxSize) {
+		delete(s.pids, s.buffer[drop].msg.PID)
+		drop++
+	}
+	s.buffer = s.buffer[drop:]
+}
+
+// pendingAfter devuelve los mensajes del buffer posteriores a lastPID, dentro de la ventana.
+// Si lastPID está vacío o ya no está en el buffer se devuelven todos.
+func (s *resumableSession) pendingAfter(lastPID string, window time.Duration) []types.ServerToClientMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := 0
+	if lastPID != "" {
+		for i, b := range s.buffer {
+			if b.msg.PID == lastPID {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	now := time.Now()
+	pending := make([]types.ServerToClientMessage, 0, len(s.buffer)-start)
+	for _, b := range s.buffer[start:] {
+		if now.Sub(b.at) <= window {
+			pending = append(pending, b.msg)
+		}
+	}
+	return pending
+}
+
+// attach registra la conexión que pasa a usar la sesión y devuelve la que la usaba antes.
+func (s *resumableSession) attach(serial uint64) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.connSerial
+	s.connSerial = serial
+	return previous
+}
+
+// transferClientAcks pasa a la conexión serial los acks pendientes de los mensajes enviados
+// a la conexión previous, cuya sesión acaba de reanudar.
+func (cm *ConnectionManager[TUserData]) transferClientAcks(previous, serial uint64) {
+	cm.pendingClientAcks.Range(func(_, value interface{}) bool {
+		if pAck, ok := value.(*types.PendingClientAck); ok {
+			pAck.ConnectionID.CompareAndSwap(previous, serial)
+		}
+		return true
+	})
+}
+
+// bufferForResume retiene un mensaje de la conexión en su sesión reanudable.
+func (c *Connection[TUserData]) bufferForResume(msg types.ServerToClientMessage) {
+	if c.session == nil {
+		return
+	}
+	c.session.add(msg, c.manager.config.ResumeWindow, c.manager.config.ResumeBufferSize)
+}
+
+// resumeEnabled indica si la configuración permite reanudar sesiones.
+func (cm *ConnectionManager[TUserData]) resumeEnabled() bool {
+	return cm.config.ResumeWindow > 0
+}
+
+// startSession crea una sesión nueva o reanuda la indicada por el token si sigue vigente
+// y pertenece al mismo usuario. Devuelve la sesión y si fue reanudada.
+func (cm *ConnectionManager[TUserData]) startSession(userID int64, resumeToken string) (*resumableSession, bool) {
+	if !cm.resumeEnabled() {
+		return nil, false
+	}
+
+	cm.sessionsMu.Lock()
+	defer cm.sessionsMu.Unlock()
+
+	if resumeToken != "" {
+		if session, ok := cm.sessions[resumeToken]; ok && session.userID == userID {
+			session.mu.Lock()
+			detached := !session.detachedAt.IsZero()
+			resumable := detached && time.Since(session.detachedAt) <= cm.config.ResumeWindow
+			if resumable {
+				session.detachedAt = time.Time{}
+				// Se rota el token para que uno filtrado no pueda reutilizarse.
+				if token, err := newResumeToken(); err == nil {
+					delete(cm.sessions, session.token)
+					session.token = token
+					cm.sessions[token] = session
+				}
+			}
+			session.mu.Unlock()
+
+			if resumable {
+				return session, true
+			}
+		}
+		logger.Warnf(componentLog, "startSession: Token de reanudación inválido o expirado para UserID %d. Se crea una sesión nueva.", userID)
+	}
+
+	token, err := newResumeToken()
+	if err != nil {
+		logger.Errorf(componentLog, "startSession: No se pudo generar el token de reanudación para UserID %d: %v", userID, err)
+		return nil, false
+	}
+	session := &resumableSession{token: token, userID: userID, pids: make(map[string]bool)}
+	if cm.sessions == nil {
+		cm.sessions = make(map[string]*resumableSession)
+	}
+	cm.sessions[token] = session
+	return session, false
+}
+
+// detachSession marca la sesión como desconectada y guarda las suscripciones de la
+// conexión; se conserva durante ResumeWindow.
+func (cm *ConnectionManager[TUserData]) detachSession(session *resumableSession, topics []string) {
+	if session == nil {
+		return
+	}
+	session.mu.Lock()
+	session.detachedAt = time.Now()
+	session.topics = topics
+	session.mu.Unlock()
+}
+
+// takeTopics devuelve las suscripciones guardadas al desconectar y las borra de la sesión.
+func (s *resumableSession) takeTopics() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	topics := s.topics
+	s.topics = nil
+	return topics
+}
+
+// bufferForDetachedSessions guarda un mensaje dirigido a un usuario en sus sesiones
+// desconectadas, para entregarlo si reconecta dentro de la ventana.
+func (cm *ConnectionManager[TUserData]) bufferForDetachedSessions(userID int64, msg types.ServerToClientMessage) {
+	if !cm.resumeEnabled() {
+		return
+	}
+	cm.sessionsMu.Lock()
+	defer cm.sessionsMu.Unlock()
+
+	for _, session := range cm.sessions {
+		if session.userID != userID {
+			continue
+		}
+		session.mu.Lock()
+		detached := !session.detachedAt.IsZero()
+		session.mu.Unlock()
+		if detached {
+			session.add(msg, cm.config.ResumeWindow, cm.config.ResumeBufferSize)
+		}
+	}
+}
+
+// expireSessions elimina las sesiones desconectadas cuya ventana de reanudación ya pasó.
+func (cm *ConnectionManager[TUserData]) expireSessions(now time.Time) {
+	cm.sessionsMu.Lock()
+	defer cm.sessionsMu.Unlock()
+
+	for token, session := range cm.sessions {
+		session.mu.Lock()
+		expired := !session.detachedAt.IsZero() && now.Sub(session.detachedAt) > cm.config.ResumeWindow
+		session.mu.Unlock()
+		if expired {
+			delete(cm.sessions, token)
+		}
+	}
+}
+
+// sendSessionInfo informa al cliente del token de reanudación y, si la sesión fue
+// reanudada, de las suscripciones restauradas, y reenvía los mensajes pendientes.
+func (c *Connection[TUserData]) sendSessionInfo(resumed bool, lastPID string, topics []string) {
+	if c.session == nil {
+		return
+	}
+	window := c.manager.config.ResumeWindow
+
+	var pending []types.ServerToClientMessage
+	if resumed {
+		pending = c.session.pendingAfter(lastPID, window)
+	}
+
+	c.session.mu.Lock()
+	token := c.session.token
+	c.session.mu.Unlock()
+
+	info := types.ServerToClientMessage{
+		PID:  c.manager.callbacks.GeneratePID(),
+		Type: types.MessageTypeSessionInfo,
+		Payload: types.SessionInfoPayload{
+			ResumeToken:         token,
+			ResumeWindowSeconds: int(window.Seconds()),
+			Resumed:             resumed,
+			Replayed:            len(pending),
+			Topics:              topics,
+		},
+	}
+	if err := c.SendMessage(info); err != nil {
+		logger.Errorf(componentLog, "sendSessionInfo: No se pudo enviar session_info a UserID %d: %v", c.ID, err)
+		return
+	}
+
+	for _, msg := range pending {
+		if err := c.SendMessage(msg); err != nil {
+			logger.Errorf(componentLog, "sendSessionInfo: Error reenviando PID %s a UserID %d: %v", msg.PID, c.ID, err)
+			return
+		}
+	}
+	if resumed {
+		logger.Infof(componentLog, "Sesión reanudada para UserID %d: %d mensajes reenviados.", c.ID, len(pending))
+	}
+}
@@ -0,0 +1,58 @@
+package customws
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// connectionCounters acumula el tráfico de una conexión. Lo actualizan readPump, writePump,
+// SendForClientAck y handleClientAck sin bloqueo.
+type connectionCounters struct {
+	messagesReceived atomic.Int64
+	bytesReceived    atomic.Int64
+	messagesSent     atomic.Int64
+	bytesSent        atomic.Int64
+	ackTimeouts      atomic.Int64
+	ackRetries       atomic.Int64
+	deadLetters      atomic.Int64
+	ackReplays       atomic.Int64
+	pidRejections    atomic.Int64
+}
+
+// ConnectionStats es una foto de las estadísticas de una conexión.
+type ConnectionStats struct {
+	ConnectedAt      time.Time `json:"connectedAt"`
+	LastActivity     time.Time `json:"lastActivity"`     // Último mensaje recibido del cliente
+	MessagesReceived int64     `json:"messagesReceived"` // Frames recibidos (cada chunk cuenta)
+	BytesReceived    int64     `json:"bytesReceived"`
+	MessagesSent     int64     `json:"messagesSent"`
+	BytesSent        int64     `json:"bytesSent"`
+	SendQueueDepth   int       `json:"sendQueueDepth"` // Mensajes en SendChan pendientes de escribir
+	SendQueueSize    int       `json:"sendQueueSize"`  // Capacidad de SendChan
+	AckTimeouts      int64     `json:"ackTimeouts"`    // SendForClientAck sin ack a tiempo
+	AckRetries       int64     `json:"ackRetries"`     // Reenvíos de SendForClientAck tras un timeout
+	DeadLetters      int64     `json:"deadLetters"`    // Mensajes de SendForClientAck sin ack tras todos los intentos
+	RTTMillis        int64     `json:"rttMs"`          // Ida y vuelta del último ping. 0 = aún no hay pong
+	AckReplays       int64     `json:"ackReplays"`     // Acks descartados de PIDs que la conexión ya había confirmado
+	PIDRejections    int64     `json:"pidRejections"`  // PIDs inválidos y respuestas o acks a mensajes de otra conexión o usuario
+}
+
+// Stats devuelve las estadísticas de la conexión.
+func (c *Connection[TUserData]) Stats() ConnectionStats {
+	return ConnectionStats{
+		ConnectedAt:      c.connectedAt,
+		LastActivity:     c.LastActivity(),
+		MessagesReceived: c.counters.messagesReceived.Load(),
+		BytesReceived:    c.counters.bytesReceived.Load(),
+		MessagesSent:     c.counters.messagesSent.Load(),
+		BytesSent:        c.counters.bytesSent.Load(),
+		SendQueueDepth:   len(c.SendChan),
+		SendQueueSize:    cap(c.SendChan),
+		AckTimeouts:      c.counters.ackTimeouts.Load(),
+		AckRetries:       c.counters.ackRetries.Load(),
+		DeadLetters:      c.counters.deadLetters.Load(),
+		RTTMillis:        c.RTT().Milliseconds(),
+		AckReplays:       c.counters.ackReplays.Load(),
+		PIDRejections:    c.counters.pidRejections.Load(),
+	}
+}
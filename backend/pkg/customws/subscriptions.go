@@ -0,0 +1,274 @@
+package customws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/customws/types"
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+// topicPattern valida los nombres de tópico: segmentos en minúsculas, dígitos, '_' o '-'
+// separados por ':' (ej. "feed:updates", "event:1234:comments", "admin:metrics").
+var topicPattern = regexp.MustCompile(`^[a-z0-9_-]+(:[a-z0-9_-]+)*$`)
+
+// maxTopicLength es la longitud máxima de un nombre de tópico.
+const maxTopicLength = 128
+
+// ErrInvalidTopic se devuelve al suscribirse a un tópico con un nombre inválido.
+var ErrInvalidTopic = apperrors.New(apperrors.InvalidTopic, "nombre de tópico inválido")
+
+// ErrSubscriptionLimit se devuelve si la conexión ya tiene el máximo de suscripciones.
+var ErrSubscriptionLimit = apperrors.New(apperrors.SubscriptionLimit, "se alcanzó el máximo de suscripciones de la conexión")
+
+// SubscriptionManager mantiene, por tópico, las conexiones suscritas. Las suscripciones
+// son de cada conexión (no del usuario): cada dispositivo elige qué quiere recibir. Al
+// desconectarse se eliminan y, si la sesión se reanuda, se restauran.
+type SubscriptionManager[TUserData any] struct {
+	manager *ConnectionManager[TUserData]
+
+	mu     sync.RWMutex
+	topics map[string]map[*Connection[TUserData]]struct{}
+	byConn map[*Connection[TUserData]]map[string]struct{}
+}
+
+func newSubscriptionManager[TUserData any](manager *ConnectionManager[TUserData]) *SubscriptionManager[TUserData] {
+	return &SubscriptionManager[TUserData]{
+		manager: manager,
+		topics:  make(map[string]map[*Connection[TUserData]]struct{}),
+		byConn:  make(map[*Connection[TUserData]]map[string]struct{}),
+	}
+}
+
+// Subscriptions devuelve el gestor de suscripciones a tópicos.
+func (cm *ConnectionManager[TUserData]) Subscriptions() *SubscriptionManager[TUserData] {
+	return cm.subscriptions
+}
+
+// ValidTopic indica si topic es un nombre de tópico válido.
+func ValidTopic(topic string) bool {
+	return len(topic) <= maxTopicLength && topicPattern.MatchString(topic)
+}
+
+// Subscribe suscribe la conexión al tópico tras validarlo y consultar
+// Callbacks.AuthorizeSubscription. Suscribirse dos veces al mismo tópico no es un error.
+func (sm *SubscriptionManager[TUserData]) Subscribe(conn *Connection[TUserData], topic string) error {
+	if !ValidTopic(topic) {
+		return ErrInvalidTopic
+	}
+	if authorize := sm.manager.callbacks.AuthorizeSubscription; authorize != nil {
+		if err := authorize(conn, topic); err != nil {
+			return err
+		}
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	connTopics := sm.byConn[conn]
+	if _, ok := connTopics[topic]; ok {
+		return nil
+	}
+	if limit := sm.manager.config.MaxSubscriptions; limit > 0 && len(connTopics) >= limit {
+		return ErrSubscriptionLimit
+	}
+	if connTopics == nil {
+		connTopics = make(map[string]struct{})
+		sm.byConn[conn] = connTopics
+	}
+	connTopics[topic] = struct{}{}
+
+	members := sm.topics[topic]
+	if members == nil {
+		members = make(map[*Connection[TUserData]]struct{})
+		sm.topics[topic] = members
+	}
+	members[conn] = struct{}{}
+	return nil
+}
+
+// Unsubscribe cancela la suscripción de la conexión al tópico. Devuelve false si no
+// estaba suscrita.
+func (sm *SubscriptionManager[TUserData]) Unsubscribe(conn *Connection[TUserData], topic string) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	connTopics := sm.byConn[conn]
+	if _, ok := connTopics[topic]; !ok {
+		return false
+	}
+	delete(connTopics, topic)
+	if len(connTopics) == 0 {
+		delete(sm.byConn, conn)
+	}
+	sm.removeMemberLocked(topic, conn)
+	return true
+}
+
+// removeConnection elimina todas las suscripciones de la conexión y devuelve sus tópicos.
+func (sm *SubscriptionManager[TUserData]) removeConnection(conn *Connection[TUserData]) []string {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	connTopics := sm.byConn[conn]
+	delete(sm.byConn, conn)
+	topics := make([]string, 0, len(connTopics))
+	for topic := range connTopics {
+		sm.removeMemberLocked(topic, conn)
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+func (sm *SubscriptionManager[TUserData]) removeMemberLocked(topic string, conn *Connection[TUserData]) {
+	members := sm.topics[topic]
+	delete(members, conn)
+	if len(members) == 0 {
+		delete(sm.topics, topic)
+	}
+}
+
+// Topics devuelve, ordenados, los tópicos a los que está suscrita la conexión.
+func (sm *SubscriptionManager[TUserData]) Topics(conn *Connection[TUserData]) []string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	topics := make([]string, 0, len(sm.byConn[conn]))
+	for topic := range sm.byConn[conn] {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// HasSubscribers indica si alguna conexión está suscrita al tópico. Permite a los
+// productores evitar el trabajo de construir un mensaje que nadie va a recibir.
+func (sm *SubscriptionManager[TUserData]) HasSubscribers(topic string) bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return len(sm.topics[topic]) > 0
+}
+
+// SubscriberCounts devuelve el número de conexiones suscritas a cada tópico activo.
+func (sm *SubscriptionManager[TUserData]) SubscriberCounts() map[string]int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	counts := make(map[string]int, len(sm.topics))
+	for topic, members := range sm.topics {
+		counts[topic] = len(members)
+	}
+	return counts
+}
+
+// Publish envía msg a todas las conexiones suscritas al tópico, indicando el tópico en
+// msg.Topic. Si el mensaje no tiene PID se le asigna uno. Devuelve el número de
+// conexiones a las que se entregó.
+func (sm *SubscriptionManager[TUserData]) Publish(topic string, msg types.ServerToClientMessage) int {
+	sm.mu.RLock()
+	members := make([]*Connection[TUserData], 0, len(sm.topics[topic]))
+	for conn := range sm.topics[topic] {
+		members = append(members, conn)
+	}
+	sm.mu.RUnlock()
+
+	if len(members) == 0 {
+		return 0
+	}
+	msg.Topic = topic
+	if msg.PID == "" {
+		msg.PID = sm.manager.callbacks.GeneratePID()
+	}
+
+	delivered := 0
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, conn := range members {
+		wg.Add(1)
+		go func(c *Connection[TUserData]) {
+			defer wg.Done()
+			if err := c.SendMessage(msg); err != nil {
+				logger.Warnf(componentLog, "Publish: Error enviando el tópico %s a UserID %d: %v", topic, c.ID, err)
+				return
+			}
+			mu.Lock()
+			delivered++
+			mu.Unlock()
+		}(conn)
+	}
+	wg.Wait()
+	return delivered
+}
+
+// restore vuelve a suscribir la conexión a los tópicos de una sesión reanudada. Los que
+// ya no estén autorizados se descartan. Devuelve los tópicos restaurados.
+func (sm *SubscriptionManager[TUserData]) restore(conn *Connection[TUserData], topics []string) []string {
+	restored := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		if err := sm.Subscribe(conn, topic); err != nil {
+			logger.Warnf(componentLog, "No se restauró la suscripción de UserID %d a %s: %v", conn.ID, topic, err)
+			continue
+		}
+		restored = append(restored, topic)
+	}
+	return restored
+}
+
+// handleSubscriptionMessage procesa los mensajes subscribe/unsubscribe del cliente y
+// responde con un mensaje subscriptions con los tópicos vigentes de la conexión. Si un
+// tópico falla se responde con el error y los anteriores de la lista quedan suscritos.
+func (c *Connection[TUserData]) handleSubscriptionMessage(msg types.ClientToServerMessage) {
+	var payload types.SubscriptionPayload
+	raw, err := json.Marshal(msg.Payload)
+	if err == nil {
+		err = json.Unmarshal(raw, &payload)
+	}
+	if err != nil {
+		c.SendAppError(msg.PID, apperrors.InvalidPayload, fmt.Sprintf("payload de %s inválido: %v", msg.Type, err))
+		return
+	}
+	if len(payload.Topics) == 0 {
+		c.SendValidationError(msg.PID, "se requiere al menos un tópico", []types.FieldError{
+			{Field: "topics", Rule: "required", Message: "lista de tópicos vacía"},
+		})
+		return
+	}
+
+	subscriptions := c.manager.subscriptions
+	for _, topic := range payload.Topics {
+		if msg.Type == types.MessageTypeUnsubscribe {
+			subscriptions.Unsubscribe(c, topic)
+			continue
+		}
+		if err := subscriptions.Subscribe(c, topic); err != nil {
+			var appErr *apperrors.Error
+			if !errors.As(err, &appErr) {
+				appErr = apperrors.New(apperrors.Forbidden, err.Error())
+			}
+			logger.Warnf(componentLog, "UserID %d no pudo suscribirse a %s: %v", c.ID, topic, err)
+			c.SendAppError(msg.PID, appErr.Code, fmt.Sprintf("%s: %s", topic, appErr.Message))
+			return
+		}
+	}
+
+	response := types.ServerToClientMessage{
+		PID:  msg.PID,
+		Type: types.MessageTypeSubscriptions,
+		Payload: types.SubscriptionsPayload{
+			Action: string(msg.Type),
+			Topics: subscriptions.Topics(c),
+		},
+	}
+	if response.PID == "" {
+		response.PID = c.manager.callbacks.GeneratePID()
+	}
+	if err := c.SendMessage(response); err != nil {
+		logger.Errorf(componentLog, "No se pudo confirmar %s a UserID %d: %v", msg.Type, c.ID, err)
+	}
+}
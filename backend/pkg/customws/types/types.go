@@ -1,6 +1,9 @@
 package types
 
-import "time"
+import (
+	"sync/atomic"
+	"time"
+)
 
 // Apiresponse es una estructura de respuesta genérica.
 type Apiresponse struct {
@@ -18,6 +21,11 @@ const (
 	MessageTypePresenceUpdate MessageType = "presence_update" // Ej: typing, focus
 	MessageTypeClientAck      MessageType = "client_ack"      // Cliente confirma recepción/procesamiento de un mensaje del servidor
 	MessageTypeGenericRequest MessageType = "generic_request" // Solicitud genérica del cliente que espera una respuesta con el mismo PID
+	MessageTypeSubscribe      MessageType = "subscribe"       // El cliente se suscribe a uno o varios tópicos
+	MessageTypeUnsubscribe    MessageType = "unsubscribe"     // El cliente cancela la suscripción a uno o varios tópicos
+	MessageTypeChunk          MessageType = "chunk"           // Fragmento de un mensaje mayor que MaxMessageSize (ver ChunkPayload)
+	MessageTypeAuth           MessageType = "auth"            // Credenciales de la conexión, como primer mensaje (ver AuthPayload)
+	MessageTypeHeartbeat      MessageType = "heartbeat"       // Heartbeat con la hora del cliente; el servidor responde con otro heartbeat
 
 	// --- Chat --- Client -> Server
 	MessageTypeGetChatList        MessageType = "get_chat_list"
@@ -50,24 +58,38 @@ const (
 	MessageTypeServerAck         MessageType = "server_ack"         // Servidor confirma recepción/procesamiento de un mensaje del cliente
 	MessageTypeGenericResponse   MessageType = "generic_response"   // Respuesta del servidor a una GenericRequest
 	MessageTypeErrorNotification MessageType = "error_notification" // Notificación de error (ej. fallo al procesar un mensaje previo)
+	MessageTypeSessionInfo       MessageType = "session_info"       // Token de reanudación de la sesión y resultado de la reanudación
+	MessageTypeSubscriptions     MessageType = "subscriptions"      // Resultado de subscribe/unsubscribe con los tópicos vigentes
+	MessageTypeAdminMetrics      MessageType = "admin_metrics"      // Métricas del servidor publicadas en el tópico admin:metrics
+	MessageTypeImpersonation     MessageType = "impersonation"      // La conexión usa un token de suplantación del soporte
+	MessageTypeMessageLimits     MessageType = "message_limits"     // Tamaños máximos de los mensajes del cliente, enviado al conectar
+	MessageTypeIdleWarning       MessageType = "idle_warning"       // La conexión se cerrará por inactividad si el cliente no envía nada
+	MessageTypeMaintenance       MessageType = "maintenance"        // El servidor entra (o sale) de mantenimiento y cerrará las conexiones
 
 	// --- Chat --- Server -> Client
 	MessageTypeChatList             MessageType = "chat_list"
+	MessageTypeChatListDelta        MessageType = "chat_list_delta" // Cambios de la lista de chats desde un syncToken
 	MessageTypeNewChatMessage       MessageType = "new_chat_message"
 	MessageTypeChatHistory          MessageType = "get_history"            // Nuevo: Para enviar el historial de mensajes de un chat
+	MessageTypeChatHistoryPage      MessageType = "chat_history_page"      // Página del historial pedida con cursor
 	MessageTypeMessageStatusUpdated MessageType = "message_status_updated" // Ej: delivered_to_recipient, read_by_recipient
 	MessageTypeTypingEvent          MessageType = "typing_event"           // Evento de "está escribiendo"
+	MessageTypeReadSync             MessageType = "read_sync"              // Otro dispositivo del mismo usuario marcó mensajes/notificaciones como leídos
 
 	// --- Perfil --- Server -> Client
 	MessageTypeMyProfileData         MessageType = "my_profile_data"
 	MessageTypeUserProfileData       MessageType = "user_profile_data"
 	MessageTypeProfileUpdateResult   MessageType = "profile_update_result"
 	MessageTypeProfileSectionUpdated MessageType = "profile_section_updated"
+	MessageTypeProfileViewed         MessageType = "profile_viewed" // Una empresa visitó el perfil del usuario
 
 	// --- Notificaciones --- Server -> Client
-	MessageTypeNotificationList MessageType = "notification_list"
-	MessageTypeNewNotification  MessageType = "new_notification"
-	MessageTypeNotificationRead MessageType = "notification_read"
+	MessageTypeNotificationList   MessageType = "notification_list"
+	MessageTypeNotificationPage   MessageType = "notification_page" // Página de notificaciones pedida con cursor
+	MessageTypeNewNotification    MessageType = "new_notification"
+	MessageTypeNotificationRead   MessageType = "notification_read"
+	MessageTypeSystemAnnouncement MessageType = "system_announcement" // Anuncio del sistema enviado desde el panel de administración
+	MessageTypeDndSummary         MessageType = "dnd_summary"         // Resumen de lo recibido durante el horario de No molestar
 
 	// --- Contactos y Búsqueda --- Server -> Client
 	MessageTypeSearchResultsUsers       MessageType = "search_results_users"
@@ -76,6 +98,9 @@ const (
 	MessageTypeContactRequestResponded  MessageType = "contact_request_responded"
 	MessageTypeContactStatusChanged     MessageType = "contact_status_changed" // Ej: amigo añadido, eliminado
 
+	// --- Comunidad --- Server -> Client
+	MessageTypeNewComment MessageType = "new_comment" // Comentario nuevo publicado en el tópico event:{id}:comments
+
 	// --- Mensajes del Cliente al Servidor ---
 	MessageTypeAcceptFriendRequest MessageType = "accept_request"
 	MessageTypeRejectFriendRequest MessageType = "reject_request"
@@ -106,15 +131,25 @@ type ServerToClientMessage struct {
 	PID        string        `json:"pid,omitempty"` // ID de Proceso/Petición, para que el cliente pueda correlacionar respuestas o confirmar con un ClientAck.
 	Type       MessageType   `json:"type"`
 	FromUserID int64         `json:"fromUserId,omitempty"` // Quién originó el mensaje (ej. en comunicación peer-to-peer).
+	Topic      string        `json:"topic,omitempty"`      // Tópico por el que se entrega el mensaje, si se publicó con Publish.
 	Payload    interface{}   `json:"payload,omitempty"`
 	Error      *ErrorPayload `json:"error,omitempty"` // Para reportar errores específicos de la operación.
 }
 
 // ErrorPayload define la estructura para errores.
 type ErrorPayload struct {
-	OriginalPID string `json:"originalPid,omitempty"` // PID del mensaje que causó el error, si aplica.
-	Code        int    `json:"code"`                  // Código de error interno o HTTP status-like.
-	Message     string `json:"message"`               // Mensaje de error legible.
+	OriginalPID string       `json:"originalPid,omitempty"` // PID del mensaje que causó el error, si aplica.
+	Code        int          `json:"code"`                  // Código de error interno o HTTP status-like.
+	Message     string       `json:"message"`               // Mensaje de error legible.
+	ErrorCode   string       `json:"errorCode,omitempty"`   // Código del catálogo pkg/apperrors (ej: "CHAT_001").
+	Fields      []FieldError `json:"fields,omitempty"`      // Campos inválidos del payload, si el error es de validación.
+}
+
+// FieldError describe un campo del payload que no superó la validación.
+type FieldError struct {
+	Field   string `json:"field"`   // Nombre JSON del campo (ej: "chatId", "items[0].name").
+	Rule    string `json:"rule"`    // Regla incumplida (ej: "required", "max", "type").
+	Message string `json:"message"` // Descripción legible del problema.
 }
 
 // AckPayload es un payload común para mensajes de tipo ack (tanto ClientAck como ServerAck).
@@ -136,29 +171,150 @@ type DataRequestPayload struct {
 	// Cualquier otro metadato del mensaje, como ID de mensaje temporal del cliente.
 }
 
+// SessionInfoPayload es el payload de MessageTypeSessionInfo, enviado al conectar.
+// El cliente reconecta con ?resume_token=<ResumeToken>&last_pid=<último PID procesado>
+// para recibir de nuevo los mensajes que no llegó a procesar.
+type SessionInfoPayload struct {
+	ResumeToken         string `json:"resumeToken"`
+	ResumeWindowSeconds int    `json:"resumeWindowSeconds"`
+	Resumed             bool   `json:"resumed"`  // true si la conexión reanudó una sesión anterior
+	Replayed            int    `json:"replayed"` // Mensajes reenviados tras este session_info
+	// Topics son las suscripciones restauradas de la sesión reanudada.
+	Topics []string `json:"topics,omitempty"`
+}
+
+// SubscriptionPayload es el payload de MessageTypeSubscribe y MessageTypeUnsubscribe.
+// Los tópicos son nombres separados por ':' (ej. "feed:updates", "event:1234:comments").
+type SubscriptionPayload struct {
+	Topics []string `json:"topics"`
+}
+
+// SubscriptionsPayload es el payload de MessageTypeSubscriptions.
+type SubscriptionsPayload struct {
+	Action string   `json:"action"` // "subscribe" o "unsubscribe"
+	Topics []string `json:"topics"` // Tópicos vigentes de la conexión tras la operación
+}
+
+// MessageLimitsPayload es el payload de MessageTypeMessageLimits, enviado al conectar. Un
+// mensaje que no cabe en MaxMessageSize se puede enviar en un solo frame si su tipo tiene un
+// límite mayor en Limits, o fragmentado en mensajes chunk.
+type MessageLimitsPayload struct {
+	MaxMessageSize int64                 `json:"maxMessageSize"`   // Límite de los tipos sin límite propio y de cada chunk
+	Limits         map[MessageType]int64 `json:"limits,omitempty"` // Límites propios por tipo de mensaje
+	// ChunkTimeoutSeconds es el tiempo para enviar todos los fragmentos de un mensaje desde el
+	// primero. 0 = la fragmentación está deshabilitada.
+	ChunkTimeoutSeconds int `json:"chunkTimeoutSeconds"`
+	MaxPendingChunks    int `json:"maxPendingChunks"` // Mensajes fragmentados incompletos a la vez
+}
+
+// IdleWarningPayload es el payload de MessageTypeIdleWarning. Cualquier mensaje del cliente
+// (p. ej. un data_request con action "ping") mantiene la conexión abierta.
+type IdleWarningPayload struct {
+	ClosesInSeconds int `json:"closesInSeconds"`
+}
+
+// AuthPayload es el payload de MessageTypeAuth, el primer mensaje de una conexión abierta sin
+// credenciales. ResumeToken y LastPID sustituyen a los parámetros de query resume_token y
+// last_pid, para que ningún secreto viaje en la URL.
+type AuthPayload struct {
+	Token       string `json:"token"`
+	ResumeToken string `json:"resumeToken,omitempty"`
+	LastPID     string `json:"lastPid,omitempty"`
+}
+
+// ChunkPayload es el payload de MessageTypeChunk. La concatenación de Data de los fragmentos,
+// en orden de Seq, es el ClientToServerMessage completo serializado en JSON.
+type ChunkPayload struct {
+	ChunkID string `json:"chunkId"` // Identificador del mensaje fragmentado, elegido por el cliente
+	Seq     int    `json:"seq"`     // Posición del fragmento, de 0 a Total-1
+	Total   int    `json:"total"`   // Número de fragmentos del mensaje
+	Data    string `json:"data"`
+}
+
+// HeartbeatRequestPayload es el payload de MessageTypeHeartbeat enviado por el cliente.
+type HeartbeatRequestPayload struct {
+	ClientTime int64 `json:"clientTime"` // Hora del cliente al enviarlo, en milisegundos Unix
+}
+
+// HeartbeatPayload es el payload de MessageTypeHeartbeat enviado por el servidor, cada
+// Config.HeartbeatInterval o en respuesta a un heartbeat del cliente (con su mismo PID). Las
+// horas son milisegundos Unix. Con la respuesta y su hora de llegada el cliente estima el
+// desfase de su reloj: ((ReceivedAt - ClientTime) + (ServerTime - llegada)) / 2.
+type HeartbeatPayload struct {
+	ServerTime      int64          `json:"serverTime"`           // Hora del servidor al escribir el mensaje
+	ClientTime      int64          `json:"clientTime,omitempty"` // ClientTime del heartbeat al que responde
+	ReceivedAt      int64          `json:"receivedAt,omitempty"` // Hora del servidor al recibir ese heartbeat
+	IntervalSeconds int            `json:"intervalSeconds"`      // Cada cuánto envía heartbeats el servidor. 0 = solo responde
+	Stats           HeartbeatStats `json:"stats"`
+}
+
+// HeartbeatStats es el estado de la conexión visto desde el servidor.
+type HeartbeatStats struct {
+	RTTMillis        int64 `json:"rttMs"` // Ida y vuelta del último ping del servidor. 0 = aún no hay pong
+	MessagesReceived int64 `json:"messagesReceived"`
+	MessagesSent     int64 `json:"messagesSent"`
+	SendQueueDepth   int   `json:"sendQueueDepth"` // Mensajes en cola pendientes de escribir
+}
+
+// CloseReason es el motivo, en JSON, de los frames de cierre del servidor. Reason identifica la
+// causa ("server_shutdown", "idle", "kicked"...). Si Reconnect es true el cliente puede
+// reconectar con las mismas credenciales pasados RetryAfter segundos (0 = enseguida).
+type CloseReason struct {
+	Reason     string `json:"reason"`
+	Reconnect  bool   `json:"reconnect"`
+	RetryAfter int    `json:"retryAfter,omitempty"`
+	ErrorCode  string `json:"errorCode,omitempty"` // Código de pkg/apperrors, si lo hay
+}
+
 // Configuration para el ConnectionManager.
 type Config struct {
-	WriteWait         time.Duration // Tiempo máximo para una escritura al peer.
-	PongWait          time.Duration // Tiempo máximo para leer el siguiente pong del peer.
-	PingPeriod        time.Duration // Frecuencia de envío de pings al peer. (Debe ser menor que PongWait)
-	MaxMessageSize    int64         // Tamaño máximo permitido para un mensaje leído del peer.
-	SendChannelBuffer int           // Tamaño del buffer para el canal de envío de cada conexión.
-	AckTimeout        time.Duration // Timeout para esperar una confirmación (ack) de un mensaje enviado con SendWithAck.
-	RequestTimeout    time.Duration // Timeout genérico para solicitudes que esperan una respuesta.
-	AllowedOrigins    []string      // Lista de orígenes permitidos. Si es nil o vacía, se denegarán todos los orígenes no locales por defecto.
+	WriteWait          time.Duration // Tiempo máximo para una escritura al peer.
+	PongWait           time.Duration // Tiempo máximo para leer el siguiente pong del peer.
+	PingPeriod         time.Duration // Frecuencia de envío de pings al peer. (Debe ser menor que PongWait)
+	MaxMessageSize     int64         // Tamaño máximo de un mensaje del peer cuyo tipo no está en MessageSizeLimits, y de cada chunk.
+	SendChannelBuffer  int           // Tamaño del buffer para el canal de envío de cada conexión.
+	AckTimeout         time.Duration // Timeout para esperar una confirmación (ack) de un mensaje enviado con SendWithAck.
+	AckMaxAttempts     int           // Envíos de un mensaje de SendForClientAck antes de darlo por no entregado. Menos de 1 equivale a 1.
+	AckBackoff         time.Duration // Espera tras el primer envío sin ack. Se duplica en cada reintento.
+	AckMaxBackoff      time.Duration // Espera máxima entre reintentos. 0 = sin límite.
+	RequestTimeout     time.Duration // Timeout genérico para solicitudes que esperan una respuesta.
+	AllowedOrigins     []string      // Lista de orígenes permitidos. Si es nil o vacía, se denegarán todos los orígenes no locales por defecto.
+	ResumeWindow       time.Duration // Tiempo durante el que una sesión desconectada puede reanudarse. 0 deshabilita la reanudación.
+	ResumeBufferSize   int           // Máximo de mensajes retenidos por sesión para reenviar al reanudar.
+	MaxSubscriptions   int           // Máximo de tópicos a los que puede suscribirse una conexión. 0 = sin límite.
+	ChunkTimeout       time.Duration // Tiempo para recibir todos los fragmentos de un mensaje. 0 deshabilita la fragmentación.
+	MaxPendingChunks   int           // Máximo de mensajes fragmentados incompletos por conexión.
+	IdleTimeout        time.Duration // Tiempo sin mensajes del cliente (los pongs no cuentan) tras el que se cierra la conexión. 0 = nunca.
+	IdleWarning        time.Duration // Antelación con la que se envía idle_warning antes del cierre por inactividad.
+	AuthTimeout        time.Duration // Tiempo para enviar el mensaje auth en una conexión abierta sin credenciales. 0 deshabilita ese modo.
+	ShutdownRetryAfter time.Duration // Espera mínima que Shutdown indica a los clientes antes de reconectar (se añade un retardo aleatorio).
+	HeartbeatInterval  time.Duration // Frecuencia de los heartbeats del servidor con su hora y las estadísticas. 0 = solo responde a los del cliente.
+	// MessageSizeLimits asigna a algunos tipos de mensaje un tamaño máximo propio (mayor o menor
+	// que MaxMessageSize), tanto en un solo frame como fragmentado.
+	MessageSizeLimits map[MessageType]int64
 }
 
 // DefaultConfig retorna una configuración por defecto.
 func DefaultConfig() Config {
 	return Config{
-		WriteWait:         10 * time.Second,
-		PongWait:          60 * time.Second,
-		PingPeriod:        (60 * time.Second * 9) / 10, // Debe ser menor que PongWait
-		MaxMessageSize:    2048,                        // Aumentado a 2KB, ajustar según necesidad
-		SendChannelBuffer: 512,                         // Buffer más grande para el canal de envío
-		AckTimeout:        5 * time.Second,
-		RequestTimeout:    10 * time.Second,
-		AllowedOrigins:    nil, // Por defecto, nil. El CheckOrigin lo interpretará.
+		WriteWait:          10 * time.Second,
+		PongWait:           60 * time.Second,
+		PingPeriod:         (60 * time.Second * 9) / 10, // Debe ser menor que PongWait
+		MaxMessageSize:     2048,                        // Aumentado a 2KB, ajustar según necesidad
+		SendChannelBuffer:  512,                         // Buffer más grande para el canal de envío
+		AckTimeout:         5 * time.Second,
+		AckMaxAttempts:     3,
+		AckBackoff:         1 * time.Second,
+		AckMaxBackoff:      15 * time.Second,
+		RequestTimeout:     10 * time.Second,
+		AllowedOrigins:     nil, // Por defecto, nil. El CheckOrigin lo interpretará.
+		ResumeWindow:       30 * time.Second,
+		ResumeBufferSize:   256,
+		MaxSubscriptions:   50,
+		ChunkTimeout:       30 * time.Second,
+		MaxPendingChunks:   4,
+		AuthTimeout:        10 * time.Second,
+		ShutdownRetryAfter: 5 * time.Second,
 	}
 }
 
@@ -173,6 +329,13 @@ type PendingClientAck struct {
 	AckChan   chan ClientToServerMessage // Canal para recibir el ClientAck.
 	Timestamp time.Time                  // Para gestionar timeouts.
 	MessageID string                     // PID del mensaje original enviado por el servidor.
+	UserID    int64                      // Usuario al que se envió.
+	// ConnectionID es la conexión a la que se envió; los acks de otras conexiones se
+	// descartan. Cambia a la conexión nueva cuando esta reanuda la sesión.
+	ConnectionID atomic.Uint64
+	// AnyConnection acepta el ack de cualquier conexión de UserID: el mensaje se envió con
+	// el mismo PID a todas ellas (SendForUserClientAck).
+	AnyConnection bool
 }
 
 // Estructura para solicitudes genéricas del cliente que esperan una respuesta del servidor,
@@ -180,4 +343,5 @@ type PendingClientAck struct {
 type PendingServerResponse struct {
 	ResponseChan chan ClientToServerMessage // Corregido: Debe ser ClientToServerMessage si esperamos respuesta del cliente.
 	Timestamp    time.Time                  // Para gestionar timeouts.
+	ConnectionID uint64                     // Conexión a la que se envió la solicitud; solo ella puede responderla.
 }
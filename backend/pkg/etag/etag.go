@@ -0,0 +1,42 @@
+// Package etag genera ETags a partir del contenido de una respuesta y evalúa la cabecera
+// If-None-Match de las peticiones condicionales.
+package etag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// FromBytes devuelve un ETag fuerte (entre comillas) con el hash del contenido.
+func FromBytes(content []byte) string {
+	sum := sha256.Sum256(content)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// FromJSON devuelve el ETag de la representación JSON de v.
+func FromJSON(v any) (string, error) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return FromBytes(content), nil
+}
+
+// Matches indica si algún ETag de If-None-Match coincide con etag. La comparación es débil
+// (RFC 9110): se ignora el prefijo W/, así que el ETag que la compresión vuelve débil sigue
+// coincidiendo. "*" coincide con cualquier representación.
+func Matches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,286 @@
+// Package fieldcrypt cifra columnas sensibles (documento de identidad, teléfono, RIF) en la
+// aplicación antes de guardarlas en la base de datos, con AES-256-GCM.
+//
+// Un valor cifrado tiene la forma
+//
+//	enc:v1:<id de la clave>:<base64(nonce || texto cifrado)>
+//
+// donde el id son los primeros 8 caracteres hexadecimales del SHA-256 de la clave. Así se
+// puede rotar la clave: los valores cifrados con una clave anterior se siguen leyendo mientras
+// esa clave esté en la lista de anteriores, y la herramienta de migración los vuelve a cifrar
+// con la actual. Los valores sin el prefijo se consideran texto plano heredado y se devuelven
+// tal cual, de modo que la base de datos puede migrarse mientras los servidores funcionan.
+//
+// Como el cifrado es aleatorio, las columnas cifradas no se pueden buscar por igualdad. Para
+// las que se buscan o son únicas se guarda además un índice ciego (BlindIndex): un HMAC-SHA256
+// del valor normalizado con una clave propia, que no cambia al rotar la de cifrado.
+//
+// Las consultas usan el cifrado por defecto (SetDefault) a través de Value, NullString e Index;
+// sin cifrado por defecto los valores se guardan y leen en texto plano.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	// Prefix marca los valores cifrados con la versión 1 del formato.
+	Prefix = "enc:v1:"
+	// KeySize es el tamaño en bytes de las claves (AES-256 y HMAC-SHA256).
+	KeySize = 32
+	// IndexLength es la longitud en caracteres de un índice ciego (HMAC-SHA256 en hexadecimal).
+	IndexLength = 2 * sha256.Size
+	// keyIDLength es la longitud en caracteres del id de la clave dentro del valor cifrado.
+	keyIDLength = 8
+)
+
+var (
+	// ErrUnknownKey indica que el valor se cifró con una clave que no está configurada.
+	ErrUnknownKey = errors.New("fieldcrypt: el valor está cifrado con una clave desconocida")
+	// ErrMalformed indica que el valor tiene el prefijo de cifrado pero no el formato esperado.
+	ErrMalformed = errors.New("fieldcrypt: valor cifrado mal formado")
+)
+
+// Cipher cifra y descifra valores con la clave actual y descifra con las anteriores.
+type Cipher struct {
+	current  *aesKey
+	keys     map[string]*aesKey // Por id, incluida la actual
+	indexKey []byte
+}
+
+type aesKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// ParseKey decodifica una clave en base64 (estándar o URL, con o sin relleno) y comprueba que
+// tenga KeySize bytes.
+func ParseKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		key, err := enc.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("fieldcrypt: la clave debe tener %d bytes (tiene %d)", KeySize, len(key))
+		}
+		return key, nil
+	}
+	return nil, errors.New("fieldcrypt: la clave no está en base64")
+}
+
+// New crea un Cipher con la clave actual, las anteriores (solo para descifrar) y la clave de
+// los índices ciegos, todas en base64. La clave de índices debe ser distinta de las de cifrado.
+func New(currentKey string, previousKeys []string, indexKey string) (*Cipher, error) {
+	current, err := newAESKey(currentKey)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cipher{current: current, keys: map[string]*aesKey{current.id: current}}
+	for _, encoded := range previousKeys {
+		if strings.TrimSpace(encoded) == "" {
+			continue
+		}
+		previous, err := newAESKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("clave anterior: %w", err)
+		}
+		c.keys[previous.id] = previous
+	}
+
+	c.indexKey, err = ParseKey(indexKey)
+	if err != nil {
+		return nil, fmt.Errorf("clave de índices: %w", err)
+	}
+	if _, reused := c.keys[keyID(c.indexKey)]; reused {
+		return nil, errors.New("fieldcrypt: la clave de índices no puede ser una clave de cifrado")
+	}
+	return c, nil
+}
+
+func newAESKey(encoded string) (*aesKey, error) {
+	raw, err := ParseKey(encoded)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, fmt.Errorf("fieldcrypt: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("fieldcrypt: %w", err)
+	}
+	return &aesKey{id: keyID(raw), aead: aead}, nil
+}
+
+// keyID identifica una clave sin revelarla.
+func keyID(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])[:keyIDLength]
+}
+
+// KeyID devuelve el id de la clave actual, el que llevan los valores que cifra.
+func (c *Cipher) KeyID() string {
+	return c.current.id
+}
+
+// Encrypt cifra value con la clave actual. La cadena vacía no se cifra.
+func (c *Cipher) Encrypt(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	nonce := make([]byte, c.current.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("fieldcrypt: generando nonce: %w", err)
+	}
+	sealed := c.current.aead.Seal(nonce, nonce, []byte(value), nil)
+	return Prefix + c.current.id + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt descifra value. Los valores sin el prefijo de cifrado se devuelven sin cambios.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	id, payload, ok := strings.Cut(value[len(Prefix):], ":")
+	if !ok {
+		return "", ErrMalformed
+	}
+	key, ok := c.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w (%s)", ErrUnknownKey, id)
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < key.aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	nonceSize := key.aead.NonceSize()
+	plain, err := key.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt: no se pudo descifrar con la clave %s: %w", id, err)
+	}
+	return string(plain), nil
+}
+
+// NeedsRewrite indica si value debe volver a guardarse: está en texto plano o cifrado con una
+// clave que no es la actual.
+func (c *Cipher) NeedsRewrite(value string) bool {
+	if value == "" {
+		return false
+	}
+	if !IsEncrypted(value) {
+		return true
+	}
+	return !strings.HasPrefix(value[len(Prefix):], c.current.id+":")
+}
+
+// BlindIndex devuelve el índice ciego de value para la columna field. El valor se normaliza
+// (sin espacios alrededor y en mayúsculas) para que las búsquedas no distingan mayúsculas, como
+// la intercalación de MySQL con el texto plano. Incluir field evita que el mismo valor tenga el
+// mismo índice en columnas distintas. La cadena vacía no tiene índice ("").
+func (c *Cipher) BlindIndex(field, value string) string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsEncrypted indica si value tiene el prefijo de un valor cifrado.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// defaultCipher es el cifrado que usan Value, NullString e Index. nil = texto plano.
+var defaultCipher atomic.Pointer[Cipher]
+
+// SetDefault establece el cifrado que usan las consultas. nil lo desactiva.
+func SetDefault(c *Cipher) {
+	defaultCipher.Store(c)
+}
+
+// Default devuelve el cifrado que usan las consultas, o nil si está desactivado.
+func Default() *Cipher {
+	return defaultCipher.Load()
+}
+
+// Enabled indica si hay un cifrado por defecto.
+func Enabled() bool {
+	return Default() != nil
+}
+
+// Value devuelve un argumento de consulta que guarda value cifrado con el cifrado por defecto,
+// o en texto plano si no lo hay. La cadena vacía se guarda tal cual.
+func Value(value string) driver.Valuer {
+	return NullString{sql.NullString{String: value, Valid: true}}
+}
+
+// Index devuelve el argumento de consulta con el índice ciego de value para la columna field:
+// NULL si no hay cifrado por defecto o value está vacío.
+func Index(field, value string) interface{} {
+	c := Default()
+	if c == nil {
+		return nil
+	}
+	if index := c.BlindIndex(field, value); index != "" {
+		return index
+	}
+	return nil
+}
+
+// NullString es un sql.NullString que se guarda cifrado y se descifra al leerlo. Se usa como
+// destino de Scan en lugar de sql.NullString para las columnas cifradas; los valores en texto
+// plano heredados se leen sin cambios.
+type NullString struct {
+	sql.NullString
+}
+
+// Scan implementa sql.Scanner descifrando el valor con el cifrado por defecto.
+func (n *NullString) Scan(src interface{}) error {
+	if err := n.NullString.Scan(src); err != nil {
+		return err
+	}
+	if !n.Valid || !IsEncrypted(n.String) {
+		return nil
+	}
+	c := Default()
+	if c == nil {
+		return errors.New("fieldcrypt: valor cifrado sin FIELD_ENCRYPTION_KEY configurada")
+	}
+	plain, err := c.Decrypt(n.String)
+	if err != nil {
+		return err
+	}
+	n.String = plain
+	return nil
+}
+
+// Value implementa driver.Valuer cifrando el valor con el cifrado por defecto.
+func (n NullString) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	c := Default()
+	if c == nil {
+		return n.String, nil
+	}
+	return c.Encrypt(n.String)
+}
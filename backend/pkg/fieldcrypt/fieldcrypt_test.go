@@ -0,0 +1,249 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testKey devuelve una clave en base64 con todos sus bytes iguales a b.
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, KeySize))
+}
+
+func newTestCipher(t *testing.T, current string, previous []string, index string) *Cipher {
+	t.Helper()
+	c, err := New(current, previous, index)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+// useDefault instala c como cifrado por defecto durante la prueba.
+func useDefault(t *testing.T, c *Cipher) {
+	t.Helper()
+	previous := Default()
+	SetDefault(c)
+	t.Cleanup(func() { SetDefault(previous) })
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	c := newTestCipher(t, testKey(1), nil, testKey(9))
+
+	for _, value := range []string{"V-12345678", "+58 412 555 0101", "J-40123456-7", "ñandú ✓"} {
+		encrypted, err := c.Encrypt(value)
+		if err != nil {
+			t.Fatalf("Encrypt(%q): %v", value, err)
+		}
+		if !strings.HasPrefix(encrypted, Prefix+c.KeyID()+":") {
+			t.Errorf("Encrypt(%q) = %q, sin el prefijo de la clave actual", value, encrypted)
+		}
+		if strings.Contains(encrypted, value) {
+			t.Errorf("Encrypt(%q) = %q contiene el texto plano", value, encrypted)
+		}
+		if c.NeedsRewrite(encrypted) {
+			t.Errorf("NeedsRewrite(%q) = true con la clave actual", encrypted)
+		}
+
+		decrypted, err := c.Decrypt(encrypted)
+		if err != nil {
+			t.Fatalf("Decrypt(%q): %v", encrypted, err)
+		}
+		if decrypted != value {
+			t.Errorf("Decrypt(Encrypt(%q)) = %q", value, decrypted)
+		}
+
+		again, _ := c.Encrypt(value)
+		if again == encrypted {
+			t.Errorf("Encrypt(%q) devolvió dos veces el mismo valor; el nonce debe ser aleatorio", value)
+		}
+	}
+
+	if encrypted, err := c.Encrypt(""); err != nil || encrypted != "" {
+		t.Errorf(`Encrypt("") = %q, %v; se esperaba "" sin cifrar`, encrypted, err)
+	}
+}
+
+func TestDecryptAfterKeyRotation(t *testing.T) {
+	oldKey, newKey, indexKey := testKey(1), testKey(2), testKey(9)
+	before := newTestCipher(t, oldKey, nil, indexKey)
+	encrypted, err := before.Encrypt("V-12345678")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	rotated := newTestCipher(t, newKey, []string{oldKey}, indexKey)
+	if rotated.KeyID() == before.KeyID() {
+		t.Fatal("la clave nueva tiene el mismo id que la anterior")
+	}
+	decrypted, err := rotated.Decrypt(encrypted)
+	if err != nil {
+		t.Fatalf("Decrypt con la clave anterior en la lista: %v", err)
+	}
+	if decrypted != "V-12345678" {
+		t.Errorf("Decrypt = %q, se esperaba V-12345678", decrypted)
+	}
+	if !rotated.NeedsRewrite(encrypted) {
+		t.Error("NeedsRewrite = false para un valor cifrado con la clave anterior")
+	}
+
+	withoutOld := newTestCipher(t, newKey, nil, indexKey)
+	if _, err := withoutOld.Decrypt(encrypted); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Decrypt sin la clave anterior: error = %v, se esperaba ErrUnknownKey", err)
+	}
+}
+
+func TestDecryptRejectsMalformedAndTampered(t *testing.T) {
+	c := newTestCipher(t, testKey(1), nil, testKey(9))
+	encrypted, err := c.Encrypt("J-40123456-7")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(encrypted[len(Prefix)+keyIDLength+1:])
+	if err != nil {
+		t.Fatalf("el valor cifrado no está en base64url: %v", err)
+	}
+	sealed[len(sealed)-1] ^= 0x01
+	tampered := Prefix + c.KeyID() + ":" + base64.RawURLEncoding.EncodeToString(sealed)
+
+	tests := []struct {
+		name      string
+		value     string
+		malformed bool // se espera ErrMalformed; si no, cualquier error
+	}{
+		{"sin id de clave", Prefix + "sinseparador", true},
+		{"payload que no es base64", Prefix + c.KeyID() + ":***", true},
+		{"payload más corto que el nonce", Prefix + c.KeyID() + ":" + base64.RawURLEncoding.EncodeToString([]byte("corto")), true},
+		{"texto cifrado alterado", tampered, false},
+		{"clave cambiada en el id", Prefix + "00000000" + encrypted[len(Prefix)+keyIDLength:], false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Decrypt(tt.value)
+			if err == nil {
+				t.Fatalf("Decrypt(%q) = %q, se esperaba un error", tt.value, got)
+			}
+			if tt.malformed && !errors.Is(err, ErrMalformed) {
+				t.Errorf("Decrypt(%q): error = %v, se esperaba ErrMalformed", tt.value, err)
+			}
+		})
+	}
+
+	// Sin el prefijo es texto plano heredado y se devuelve tal cual.
+	if got, err := c.Decrypt("enc:v2:otro-formato"); err != nil || got != "enc:v2:otro-formato" {
+		t.Errorf("Decrypt de texto plano = %q, %v", got, err)
+	}
+}
+
+func TestNullString(t *testing.T) {
+	c := newTestCipher(t, testKey(1), nil, testKey(9))
+
+	t.Run("sin cifrado por defecto", func(t *testing.T) {
+		useDefault(t, nil)
+
+		value, err := NullString{}.Value()
+		if err != nil || value != nil {
+			t.Errorf("Value de NULL = %v, %v; se esperaba nil", value, err)
+		}
+		value, err = Value("04125550101").Value()
+		if err != nil || value != "04125550101" {
+			t.Errorf("Value = %v, %v; se esperaba el texto plano", value, err)
+		}
+
+		var n NullString
+		if err := n.Scan("V-12345678"); err != nil || !n.Valid || n.String != "V-12345678" {
+			t.Errorf("Scan de texto plano = %+v, %v", n, err)
+		}
+		encrypted, _ := c.Encrypt("V-12345678")
+		if err := n.Scan(encrypted); err == nil {
+			t.Error("Scan de un valor cifrado sin clave configurada no devolvió error")
+		}
+	})
+
+	t.Run("con cifrado por defecto", func(t *testing.T) {
+		useDefault(t, c)
+
+		value, err := NullString{}.Value()
+		if err != nil || value != nil {
+			t.Errorf("Value de NULL = %v, %v; se esperaba nil", value, err)
+		}
+		value, err = Value("04125550101").Value()
+		if err != nil {
+			t.Fatalf("Value: %v", err)
+		}
+		stored, ok := value.(string)
+		if !ok || !IsEncrypted(stored) {
+			t.Fatalf("Value = %v, se esperaba un valor cifrado", value)
+		}
+
+		var n NullString
+		if err := n.Scan([]byte(stored)); err != nil || !n.Valid || n.String != "04125550101" {
+			t.Errorf("Scan del valor cifrado = %+v, %v", n, err)
+		}
+		if err := n.Scan(nil); err != nil || n.Valid {
+			t.Errorf("Scan(nil) = %+v, %v; se esperaba NULL", n, err)
+		}
+		if err := n.Scan([]byte("04125550101")); err != nil || !n.Valid || n.String != "04125550101" {
+			t.Errorf("Scan de texto plano heredado = %+v, %v", n, err)
+		}
+		if err := n.Scan(Prefix + "roto"); !errors.Is(err, ErrMalformed) {
+			t.Errorf("Scan de un valor mal formado: error = %v, se esperaba ErrMalformed", err)
+		}
+	})
+}
+
+func TestBlindIndexStability(t *testing.T) {
+	indexKey := testKey(9)
+	c := newTestCipher(t, testKey(1), nil, indexKey)
+
+	index := c.BlindIndex("RIF", "J-40123456-7")
+	if len(index) != IndexLength {
+		t.Fatalf("len(BlindIndex) = %d, se esperaba %d", len(index), IndexLength)
+	}
+	if again := c.BlindIndex("RIF", "J-40123456-7"); again != index {
+		t.Errorf("BlindIndex no es determinista: %q y %q", index, again)
+	}
+	if normalized := c.BlindIndex("RIF", "  j-40123456-7 "); normalized != index {
+		t.Error("BlindIndex distingue mayúsculas o espacios alrededor")
+	}
+	if other := c.BlindIndex("DocId", "J-40123456-7"); other == index {
+		t.Error("BlindIndex da el mismo índice en columnas distintas")
+	}
+	if empty := c.BlindIndex("RIF", "   "); empty != "" {
+		t.Errorf(`BlindIndex de un valor vacío = %q, se esperaba ""`, empty)
+	}
+
+	// Rotar la clave de cifrado no cambia los índices; cambiar la de índices sí.
+	rotated := newTestCipher(t, testKey(2), []string{testKey(1)}, indexKey)
+	if got := rotated.BlindIndex("RIF", "J-40123456-7"); got != index {
+		t.Error("BlindIndex cambió al rotar la clave de cifrado")
+	}
+	otherIndexKey := newTestCipher(t, testKey(1), nil, testKey(8))
+	if got := otherIndexKey.BlindIndex("RIF", "J-40123456-7"); got == index {
+		t.Error("BlindIndex no depende de la clave de índices")
+	}
+
+	useDefault(t, nil)
+	if got := Index("RIF", "J-40123456-7"); got != nil {
+		t.Errorf("Index sin cifrado por defecto = %v, se esperaba nil", got)
+	}
+	SetDefault(c)
+	if got := Index("RIF", "J-40123456-7"); got != index {
+		t.Errorf("Index = %v, se esperaba %q", got, index)
+	}
+}
+
+func TestNewRejectsIndexKeyReuse(t *testing.T) {
+	if _, err := New(testKey(1), nil, testKey(1)); err == nil {
+		t.Error("New aceptó la clave de cifrado como clave de índices")
+	}
+	if _, err := New(testKey(1), []string{testKey(2)}, testKey(2)); err == nil {
+		t.Error("New aceptó una clave anterior como clave de índices")
+	}
+	if _, err := New("corta", nil, testKey(9)); err == nil {
+		t.Error("New aceptó una clave que no tiene 32 bytes")
+	}
+}
@@ -0,0 +1,161 @@
+// Package geoip resuelve el país y la ciudad aproximados de una IP.
+//
+// Se usan, por este orden, las cabeceras que añade el CDN o el proxy (por ejemplo
+// CF-IPCountry de Cloudflare) y, si están configurados, un servicio HTTP de geolocalización
+// que responde JSON. Las IP privadas o de loopback no se resuelven.
+package geoip
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheTTL es el tiempo que se reutiliza la ubicación de una IP sin volver a consultarla.
+const cacheTTL = 6 * time.Hour
+
+// cacheMaxEntries limita la memoria de la caché; al llenarse se vacía por completo.
+const cacheMaxEntries = 10000
+
+// Location es la ubicación aproximada de una IP. Los campos vacíos son desconocidos.
+type Location struct {
+	Country string // Código ISO 3166-1 alfa-2 en mayúsculas
+	City    string
+}
+
+// Resolver resuelve ubicaciones a partir de la petición HTTP del cliente.
+type Resolver struct {
+	countryHeader string
+	cityHeader    string
+	apiURL        string // Con el marcador {ip}
+	client        *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedLocation
+}
+
+type cachedLocation struct {
+	location  Location
+	expiresAt time.Time
+}
+
+// NewResolver crea un Resolver. countryHeader y cityHeader son las cabeceras del proxy con el
+// país y la ciudad (vacías = no se usan). apiURL es la URL del servicio de geolocalización con
+// el marcador {ip}, por ejemplo https://ipapi.co/{ip}/json/ (vacía = no se consulta).
+func NewResolver(countryHeader, cityHeader, apiURL string, timeout time.Duration) *Resolver {
+	return &Resolver{
+		countryHeader: countryHeader,
+		cityHeader:    cityHeader,
+		apiURL:        apiURL,
+		client:        &http.Client{Timeout: timeout},
+		cache:         make(map[string]cachedLocation),
+	}
+}
+
+// Resolve devuelve la ubicación de la IP del cliente. Los errores del servicio externo no se
+// propagan: la ubicación queda vacía.
+func (r *Resolver) Resolve(ctx context.Context, req *http.Request, ip string) Location {
+	var location Location
+	if r.countryHeader != "" {
+		location.Country = normalizeCountry(req.Header.Get(r.countryHeader))
+	}
+	if r.cityHeader != "" {
+		location.City = strings.TrimSpace(req.Header.Get(r.cityHeader))
+	}
+	if location.Country != "" || r.apiURL == "" || !isPublicIP(ip) {
+		return location
+	}
+
+	if cached, ok := r.cached(ip); ok {
+		return cached
+	}
+	resolved, err := r.lookup(ctx, ip)
+	if err != nil {
+		return location
+	}
+	r.store(ip, resolved)
+	return resolved
+}
+
+func (r *Resolver) cached(ip string) (Location, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.cache[ip]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return Location{}, false
+	}
+	return entry.location, true
+}
+
+func (r *Resolver) store(ip string, location Location) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.cache) >= cacheMaxEntries {
+		r.cache = make(map[string]cachedLocation)
+	}
+	r.cache[ip] = cachedLocation{location: location, expiresAt: time.Now().Add(cacheTTL)}
+}
+
+// lookup consulta el servicio HTTP. Acepta los nombres de campo de los servicios más comunes
+// (ipapi.co, ip-api.com, ipinfo.io).
+func (r *Resolver) lookup(ctx context.Context, ip string) (Location, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.ReplaceAll(r.apiURL, "{ip}", url.PathEscape(ip)), nil)
+	if err != nil {
+		return Location{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return Location{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Location{}, fmt.Errorf("geoip: el servicio respondió %d", resp.StatusCode)
+	}
+
+	var body struct {
+		CountryCode  string `json:"country_code"`
+		CountryCode2 string `json:"countryCode"`
+		Country      string `json:"country"`
+		City         string `json:"city"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body); err != nil {
+		return Location{}, err
+	}
+	location := Location{City: strings.TrimSpace(body.City)}
+	for _, candidate := range []string{body.CountryCode, body.CountryCode2, body.Country} {
+		if country := normalizeCountry(candidate); country != "" {
+			location.Country = country
+			break
+		}
+	}
+	return location, nil
+}
+
+// normalizeCountry devuelve el código de país en mayúsculas, o vacío si no es un código de
+// dos letras (Cloudflare usa XX para desconocido y T1 para Tor).
+func normalizeCountry(value string) string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) != 2 || value == "XX" || value == "T1" {
+		return ""
+	}
+	for _, c := range value {
+		if c < 'A' || c > 'Z' {
+			return ""
+		}
+	}
+	return value
+}
+
+func isPublicIP(value string) bool {
+	ip := net.ParseIP(value)
+	return ip != nil && !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsMulticast()
+}
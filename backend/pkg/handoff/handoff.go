@@ -0,0 +1,70 @@
+// Package handoff permite reiniciar un servidor sin cortar el servicio: el proceso en marcha
+// arranca el binario nuevo pasándole su socket de escucha, espera a que el nuevo avise de que
+// ya atiende peticiones y, a partir de ahí, deja de aceptar conexiones y drena las suyas.
+//
+// El flujo es:
+//
+//  1. El proceso actual recibe la señal de Notify (SIGUSR2) y llama a Upgrade.
+//  2. El proceso nuevo abre el servidor con Listen, que reutiliza el socket heredado, y llama
+//     a Ready cuando ya puede atender peticiones.
+//  3. Upgrade vuelve sin error y el proceso anterior cierra su servidor y drena sus conexiones.
+//
+// Si el proceso nuevo falla o no avisa a tiempo, Upgrade lo termina y el anterior sigue
+// sirviendo como si nada. Solo está disponible en sistemas Unix.
+package handoff
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Variables de entorno con las que el proceso anterior pasa el socket al nuevo.
+const (
+	EnvListenerFD = "HANDOFF_LISTENER_FD" // Descriptor del socket heredado
+	EnvParentPID  = "HANDOFF_PARENT_PID"  // Proceso al que Ready avisa
+)
+
+// ErrUnsupported indica que el sistema no admite el traspaso del socket.
+var ErrUnsupported = errors.New("handoff: no soportado en este sistema")
+
+// Listen devuelve el socket heredado del proceso anterior o, si no lo hay, abre uno nuevo en
+// addr. inherited indica si se heredó.
+func Listen(addr string) (ln net.Listener, inherited bool, err error) {
+	fdValue := os.Getenv(EnvListenerFD)
+	if fdValue == "" {
+		ln, err = net.Listen("tcp", addr)
+		return ln, false, err
+	}
+
+	fd, err := strconv.Atoi(fdValue)
+	if err != nil {
+		return nil, false, fmt.Errorf("handoff: %s inválido (%q): %w", EnvListenerFD, fdValue, err)
+	}
+	file := os.NewFile(uintptr(fd), "listener")
+	if file == nil {
+		return nil, false, fmt.Errorf("handoff: el descriptor %d no es válido", fd)
+	}
+	defer file.Close() // FileListener duplica el descriptor
+	ln, err = net.FileListener(file)
+	if err != nil {
+		return nil, false, fmt.Errorf("handoff: no se pudo usar el socket heredado: %w", err)
+	}
+	os.Unsetenv(EnvListenerFD)
+	return ln, true, nil
+}
+
+// childEnv devuelve el entorno del proceso actual sin las variables de un traspaso anterior.
+func childEnv() []string {
+	env := make([]string, 0, len(os.Environ())+2)
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, EnvListenerFD+"=") || strings.HasPrefix(kv, EnvParentPID+"=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
@@ -0,0 +1,94 @@
+//go:build !windows
+
+package handoff
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// upgradeSignal pide al proceso en marcha que arranque su reemplazo y readySignal es el aviso
+// del proceso nuevo de que ya atiende peticiones.
+const (
+	upgradeSignal = syscall.SIGUSR2
+	readySignal   = syscall.SIGUSR1
+)
+
+// Notify envía a ch la señal que pide el traspaso (SIGUSR2).
+func Notify(ch chan<- os.Signal) {
+	signal.Notify(ch, upgradeSignal)
+}
+
+// Upgrade arranca una nueva instancia del binario actual, con los mismos argumentos y entorno,
+// y le pasa ln. Espera a que llame a Ready durante como mucho timeout; si no lo hace o termina
+// antes, la mata y devuelve el error, y el proceso actual debe seguir sirviendo.
+func Upgrade(ln net.Listener, timeout time.Duration) (*os.Process, error) {
+	filer, ok := ln.(interface{ File() (*os.File, error) })
+	if !ok {
+		return nil, fmt.Errorf("handoff: el listener %T no expone su descriptor", ln)
+	}
+	file, err := filer.File()
+	if err != nil {
+		return nil, fmt.Errorf("handoff: no se pudo obtener el descriptor del socket: %w", err)
+	}
+	defer file.Close()
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("handoff: no se pudo localizar el binario: %w", err)
+	}
+
+	ready := make(chan os.Signal, 1)
+	signal.Notify(ready, readySignal)
+	defer signal.Stop(ready)
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = []*os.File{file} // Descriptor 3 en el proceso nuevo
+	cmd.Env = append(childEnv(),
+		EnvListenerFD+"=3",
+		EnvParentPID+"="+strconv.Itoa(os.Getpid()))
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("handoff: no se pudo arrancar %s: %w", executable, err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-ready:
+		return cmd.Process, nil
+	case err := <-exited:
+		if err == nil {
+			err = errors.New("terminó sin avisar")
+		}
+		return nil, fmt.Errorf("handoff: el proceso nuevo (PID %d) falló: %w", cmd.Process.Pid, err)
+	case <-timer.C:
+		cmd.Process.Kill()
+		return nil, fmt.Errorf("handoff: el proceso nuevo (PID %d) no avisó en %s", cmd.Process.Pid, timeout)
+	}
+}
+
+// Ready avisa al proceso anterior de que este ya atiende peticiones. No hace nada si el
+// proceso no se arrancó con Upgrade.
+func Ready() error {
+	pidValue := os.Getenv(EnvParentPID)
+	if pidValue == "" {
+		return nil
+	}
+	os.Unsetenv(EnvParentPID)
+	pid, err := strconv.Atoi(pidValue)
+	if err != nil {
+		return fmt.Errorf("handoff: %s inválido (%q): %w", EnvParentPID, pidValue, err)
+	}
+	return syscall.Kill(pid, readySignal)
+}
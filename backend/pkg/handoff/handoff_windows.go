@@ -0,0 +1,22 @@
+//go:build windows
+
+package handoff
+
+import (
+	"net"
+	"os"
+	"time"
+)
+
+// Notify no hace nada: en Windows no hay señal para pedir el traspaso.
+func Notify(ch chan<- os.Signal) {}
+
+// Upgrade no está soportado en Windows.
+func Upgrade(ln net.Listener, timeout time.Duration) (*os.Process, error) {
+	return nil, ErrUnsupported
+}
+
+// Ready no hace nada en Windows.
+func Ready() error {
+	return nil
+}
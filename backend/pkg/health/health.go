@@ -0,0 +1,199 @@
+// Package health expone los endpoints de liveness (/healthz) y readiness (/readyz)
+// comunes a todos los servicios.
+//
+// Liveness solo indica que el proceso responde. Readiness ejecuta en paralelo las
+// comprobaciones de dependencias registradas (BD, GCS, servicios upstream...) y
+// responde 503 si alguna falla, con el estado y la latencia de cada una. Una comprobación
+// que devuelve un error envuelto con Degraded marca el servicio como degradado: sigue
+// atendiendo (200) pero con funciones limitadas, como el modo solo lectura de la BD.
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Estados posibles de una dependencia o del servicio.
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFail     = "fail"
+)
+
+// DefaultTimeout es el tiempo máximo de cada comprobación de dependencia.
+const DefaultTimeout = 3 * time.Second
+
+// Check comprueba una dependencia. Debe respetar la cancelación del contexto.
+type Check func(ctx context.Context) error
+
+// degradedError marca el error de una comprobación como degradación y no como fallo.
+type degradedError struct{ err error }
+
+func (e degradedError) Error() string { return e.err.Error() }
+func (e degradedError) Unwrap() error { return e.err }
+
+// Degraded envuelve el error de una comprobación cuya dependencia funciona a medias: el
+// servicio se reporta como degradado pero sigue listo para recibir tráfico.
+func Degraded(err error) error {
+	return degradedError{err: err}
+}
+
+// DependencyStatus es el resultado de una comprobación individual.
+type DependencyStatus struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report es el cuerpo JSON devuelto por /healthz y /readyz.
+type Report struct {
+	Service      string                      `json:"service"`
+	Status       string                      `json:"status"`
+	Timestamp    time.Time                   `json:"timestamp"`
+	Dependencies map[string]DependencyStatus `json:"dependencies,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker agrupa las comprobaciones de readiness de un servicio.
+type Checker struct {
+	service string
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks []namedCheck
+}
+
+// NewChecker crea un Checker para el servicio indicado.
+func NewChecker(service string) *Checker {
+	return &Checker{service: service, timeout: DefaultTimeout}
+}
+
+// Register añade una comprobación de dependencia a la readiness.
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Run ejecuta todas las comprobaciones en paralelo y construye el reporte.
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := make([]namedCheck, len(c.checks))
+	copy(checks, c.checks)
+	c.mu.RUnlock()
+
+	report := Report{
+		Service:      c.service,
+		Status:       StatusOK,
+		Timestamp:    time.Now().UTC(),
+		Dependencies: make(map[string]DependencyStatus, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, nc := range checks {
+		wg.Add(1)
+		go func(nc namedCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := nc.check(checkCtx)
+			status := DependencyStatus{
+				Status:    StatusOK,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			var degraded degradedError
+			switch {
+			case errors.As(err, &degraded):
+				status.Status = StatusDegraded
+				status.Error = err.Error()
+			case err != nil:
+				status.Status = StatusFail
+				status.Error = err.Error()
+			}
+
+			mu.Lock()
+			report.Dependencies[nc.name] = status
+			if status.Status == StatusFail || (status.Status == StatusDegraded && report.Status == StatusOK) {
+				report.Status = status.Status
+			}
+			mu.Unlock()
+		}(nc)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// LivenessHandler responde 200 mientras el proceso pueda atender peticiones.
+func (c *Checker) LivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, http.StatusOK, Report{
+			Service:   c.service,
+			Status:    StatusOK,
+			Timestamp: time.Now().UTC(),
+		})
+	}
+}
+
+// ReadinessHandler responde 200 si todas las dependencias están disponibles o degradadas y 503
+// si alguna falla.
+func (c *Checker) ReadinessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context())
+		code := http.StatusOK
+		if report.Status == StatusFail {
+			code = http.StatusServiceUnavailable
+		}
+		writeReport(w, code, report)
+	}
+}
+
+func writeReport(w http.ResponseWriter, code int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(report)
+}
+
+// DBCheck comprueba la conexión a la base de datos con un ping.
+func DBCheck(db *sql.DB) Check {
+	return func(ctx context.Context) error {
+		if db == nil {
+			return fmt.Errorf("conexión a la base de datos no inicializada")
+		}
+		return db.PingContext(ctx)
+	}
+}
+
+// HTTPCheck comprueba que un servicio upstream responda a un GET sin error 5xx.
+func HTTPCheck(url string) Check {
+	client := &http.Client{}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%s respondió %d", url, resp.StatusCode)
+		}
+		return nil
+	}
+}
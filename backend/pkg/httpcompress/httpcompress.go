@@ -0,0 +1,379 @@
+// Package httpcompress comprime las respuestas HTTP con gzip o deflate según la cabecera
+// Accept-Encoding del cliente.
+//
+// Solo se comprimen las respuestas cuyo Content-Type está en la lista permitida y que alcanzan
+// un tamaño mínimo; el resto se envía tal cual. Las respuestas que ya traen Content-Encoding
+// (p. ej. las de la API reenviadas por el proxy) no se vuelven a comprimir.
+package httpcompress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+// DefaultMinSize es el tamaño mínimo por defecto: por debajo la cabecera gzip y el coste de
+// CPU no compensan.
+const DefaultMinSize = 1024
+
+// DefaultTypes son los tipos comprimibles por defecto. Un elemento terminado en /* admite
+// todos los subtipos.
+var DefaultTypes = []string{
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"image/svg+xml",
+	"text/*",
+}
+
+// Options configura el middleware.
+type Options struct {
+	MinSize int      // Bytes; 0 = DefaultMinSize
+	Types   []string // Tipos MIME permitidos; vacío = DefaultTypes
+	Level   int      // Nivel de compresión de 1 a 9; 0 = el nivel por defecto de gzip
+}
+
+// ParseTypes separa una lista de tipos MIME separados por comas.
+func ParseTypes(value string) []string {
+	var types []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			types = append(types, item)
+		}
+	}
+	return types
+}
+
+// Middleware devuelve un middleware que comprime las respuestas de next. Las peticiones de
+// upgrade (WebSocket) pasan sin envolver para que el handler pueda secuestrar la conexión.
+func Middleware(opts Options) func(http.Handler) http.Handler {
+	c := newCompressor(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			writer := &responseWriter{
+				ResponseWriter: w,
+				compressor:     c,
+				encoding:       negotiate(r.Header.Get("Accept-Encoding")),
+				head:           r.Method == http.MethodHead,
+			}
+			defer writer.close()
+			next.ServeHTTP(writer, r)
+		})
+	}
+}
+
+type compressor struct {
+	minSize  int
+	exact    map[string]bool
+	prefixes []string
+	gzip     sync.Pool
+	deflate  sync.Pool
+}
+
+func newCompressor(opts Options) *compressor {
+	c := &compressor{minSize: opts.MinSize, exact: make(map[string]bool)}
+	if c.minSize <= 0 {
+		c.minSize = DefaultMinSize
+	}
+	types := opts.Types
+	if len(types) == 0 {
+		types = DefaultTypes
+	}
+	for _, t := range types {
+		t = strings.ToLower(t)
+		if strings.HasSuffix(t, "/*") {
+			c.prefixes = append(c.prefixes, strings.TrimSuffix(t, "*"))
+		} else {
+			c.exact[t] = true
+		}
+	}
+
+	level := opts.Level
+	if level < gzip.BestSpeed || level > gzip.BestCompression {
+		level = gzip.DefaultCompression
+	}
+	c.gzip.New = func() any {
+		gw, _ := gzip.NewWriterLevel(io.Discard, level)
+		return gw
+	}
+	c.deflate.New = func() any {
+		zw, _ := zlib.NewWriterLevel(io.Discard, level)
+		return zw
+	}
+	return c
+}
+
+// allowed indica si el Content-Type está en la lista permitida.
+func (c *compressor) allowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if c.exact[mediaType] {
+		return true
+	}
+	for _, prefix := range c.prefixes {
+		if strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// encoder es la parte común de gzip.Writer y zlib.Writer.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+func (c *compressor) acquire(encoding string, w io.Writer) encoder {
+	pool := &c.gzip
+	if encoding == encodingDeflate {
+		pool = &c.deflate
+	}
+	enc := pool.Get().(encoder)
+	enc.Reset(w)
+	return enc
+}
+
+func (c *compressor) release(encoding string, enc encoder) {
+	enc.Reset(io.Discard)
+	if encoding == encodingDeflate {
+		c.deflate.Put(enc)
+	} else {
+		c.gzip.Put(enc)
+	}
+}
+
+// negotiate elige la codificación según Accept-Encoding: la de mayor calidad entre gzip y
+// deflate, con preferencia por gzip en caso de empate. Devuelve "" si el cliente no acepta
+// ninguna. "deflate" es el formato zlib (RFC 1950), como indica HTTP.
+func negotiate(header string) string {
+	if header == "" {
+		return ""
+	}
+	qualities := map[string]float64{}
+	wildcard := -1.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(strings.TrimSpace(key), "q") {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if name == "*" {
+			wildcard = q
+		} else {
+			qualities[name] = q
+		}
+	}
+
+	quality := func(encoding string) float64 {
+		if q, ok := qualities[encoding]; ok {
+			return q
+		}
+		if wildcard >= 0 {
+			return wildcard
+		}
+		return 0
+	}
+	gzipQ, deflateQ := quality(encodingGzip), quality(encodingDeflate)
+	switch {
+	case gzipQ > 0 && gzipQ >= deflateQ:
+		return encodingGzip
+	case deflateQ > 0:
+		return encodingDeflate
+	}
+	return ""
+}
+
+// responseWriter retiene los primeros bytes de la respuesta hasta saber si se comprime: el
+// tamaño mínimo se decide con Content-Length o, si no lo hay, con lo que el handler escribe.
+type responseWriter struct {
+	http.ResponseWriter
+	compressor *compressor
+	encoding   string // Codificación aceptada por el cliente ("" = ninguna)
+	head       bool
+
+	status      int
+	wroteHeader bool // WriteHeader llamado por el handler
+	decided     bool // Cabeceras enviadas al cliente
+	buffer      bytes.Buffer
+	enc         encoder
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.wroteHeader || w.decided {
+		return
+	}
+	// Las respuestas informativas (103 Early Hints) no cuentan como la respuesta final
+	if status >= 100 && status < 200 {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+	// Sin Content-Type el tipo se detecta con los primeros bytes del cuerpo
+	if w.Header().Get("Content-Type") == "" {
+		return
+	}
+	if !w.compressible() {
+		w.decide(false)
+		return
+	}
+	if length := w.Header().Get("Content-Length"); length != "" {
+		size, err := strconv.Atoi(length)
+		w.decide(err == nil && size >= w.compressor.minSize)
+	}
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.decided {
+		if w.enc != nil {
+			return w.enc.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buffer.Write(p)
+	if w.buffer.Len() >= w.compressor.minSize {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush envía lo acumulado. Si aún no se había decidido, se comprime siempre que el tipo lo
+// permita: una respuesta que se envía por partes no tiene tamaño conocido.
+func (w *responseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		if err := w.start(w.buffer.Len() > 0 || w.Header().Get("Content-Type") != ""); err != nil {
+			return
+		}
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap expone el ResponseWriter original a http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close termina la respuesta: envía lo retenido (sin comprimir si no llegó al mínimo) y
+// cierra el compresor.
+func (w *responseWriter) close() {
+	if !w.wroteHeader {
+		// El handler no escribió nada: el servidor responderá 200 vacío por su cuenta
+		return
+	}
+	if !w.decided {
+		_ = w.start(false)
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+		w.compressor.release(w.encoding, w.enc)
+		w.enc = nil
+	}
+}
+
+// start decide si se comprime (compress y que la respuesta lo admita) y envía lo retenido.
+func (w *responseWriter) start(compress bool) error {
+	if compress && !w.compressible() {
+		compress = false
+	}
+	w.decide(compress)
+	if w.buffer.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(w.buffer.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buffer.Bytes())
+	}
+	w.buffer.Reset()
+	return err
+}
+
+// compressible indica si la respuesta puede comprimirse, sin tener en cuenta el tamaño. Se
+// evalúa con las cabeceras que el handler haya fijado hasta el momento.
+func (w *responseWriter) compressible() bool {
+	header := w.Header()
+	switch {
+	case w.status < http.StatusOK,
+		w.status == http.StatusNoContent,
+		w.status == http.StatusNotModified,
+		w.status == http.StatusPartialContent:
+		return false
+	case header.Get("Content-Encoding") != "",
+		header.Get("Content-Range") != "",
+		strings.Contains(strings.ToLower(header.Get("Cache-Control")), "no-transform"):
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" && w.buffer.Len() > 0 {
+		contentType = http.DetectContentType(w.buffer.Bytes())
+		header.Set("Content-Type", contentType)
+	}
+	return contentType != "" && w.compressor.allowed(contentType)
+}
+
+// decide envía las cabeceras. Las respuestas que se comprimirían llevan Vary: Accept-Encoding
+// aunque el cliente no acepte compresión, para que las cachés no mezclen las dos versiones.
+func (w *responseWriter) decide(compress bool) {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	header := w.Header()
+	if compress {
+		header.Add("Vary", "Accept-Encoding")
+	}
+	if compress && w.encoding != "" {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		header.Del("Accept-Ranges")
+		// El ETag identifica la representación sin comprimir
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+		if !w.head {
+			w.enc = w.compressor.acquire(w.encoding, w.ResponseWriter)
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
@@ -0,0 +1,197 @@
+// Package httpmetrics acumula métricas de las peticiones HTTP por ruta: número de peticiones,
+// códigos de estado, bytes enviados e histograma de latencias con buckets fijos.
+//
+// Las rutas se identifican por su plantilla (p. ej. /api/v1/users/{id}) y no por la URL,
+// para que el número de series no crezca con los IDs.
+package httpmetrics
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets son los límites superiores (inclusivos) de los buckets del histograma, en
+// milisegundos. Las peticiones más lentas que el último caen en el bucket +Inf.
+var LatencyBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// Registry guarda las métricas de cada combinación de método y ruta. Es seguro para uso
+// concurrente.
+type Registry struct {
+	mu      sync.Mutex
+	routes  map[routeKey]*routeStats
+	started time.Time
+}
+
+type routeKey struct {
+	method string
+	route  string
+}
+
+type routeStats struct {
+	count      int64
+	panics     int64
+	statuses   map[int]int64
+	bytes      int64
+	sumLatency time.Duration
+	maxLatency time.Duration
+	buckets    []int64 // len(LatencyBuckets)+1, el último es +Inf
+}
+
+// NewRegistry crea un Registry vacío.
+func NewRegistry() *Registry {
+	return &Registry{routes: make(map[routeKey]*routeStats), started: time.Now()}
+}
+
+// Observe registra una petición terminada.
+func (r *Registry) Observe(method, route string, status int, bytes int64, latency time.Duration) {
+	ms := float64(latency) / float64(time.Millisecond)
+	bucket := sort.SearchFloat64s(LatencyBuckets, ms)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := routeKey{method: method, route: route}
+	stats, ok := r.routes[key]
+	if !ok {
+		stats = &routeStats{statuses: make(map[int]int64), buckets: make([]int64, len(LatencyBuckets)+1)}
+		r.routes[key] = stats
+	}
+	stats.count++
+	stats.statuses[status]++
+	stats.bytes += bytes
+	stats.sumLatency += latency
+	if latency > stats.maxLatency {
+		stats.maxLatency = latency
+	}
+	stats.buckets[bucket]++
+}
+
+// ObservePanic registra que la petición terminó con un pánico recuperado. Se llama además de
+// Observe.
+func (r *Registry) ObservePanic(method, route string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stats, ok := r.routes[routeKey{method: method, route: route}]; ok {
+		stats.panics++
+	}
+}
+
+// Bucket es un bucket acumulado del histograma: peticiones con latencia <= LeMs.
+type Bucket struct {
+	LeMs  string `json:"le"` // Límite en milisegundos o "+Inf"
+	Count int64  `json:"count"`
+}
+
+// RouteSnapshot son las métricas de una ruta en un momento dado.
+type RouteSnapshot struct {
+	Method         string           `json:"method"`
+	Route          string           `json:"route"`
+	Requests       int64            `json:"requests"`
+	Statuses       map[string]int64 `json:"statuses"` // Por código de estado
+	Errors         int64            `json:"errors"`   // Respuestas 5xx
+	Panics         int64            `json:"panics"`   // Pánicos recuperados en el handler
+	BytesSent      int64            `json:"bytesSent"`
+	AvgLatencyMs   float64          `json:"avgLatencyMs"`
+	MaxLatencyMs   float64          `json:"maxLatencyMs"`
+	P50LatencyMs   float64          `json:"p50LatencyMs"`
+	P95LatencyMs   float64          `json:"p95LatencyMs"`
+	P99LatencyMs   float64          `json:"p99LatencyMs"`
+	LatencyBuckets []Bucket         `json:"latencyBuckets"`
+}
+
+// Snapshot es el estado completo del Registry.
+type Snapshot struct {
+	Since  time.Time       `json:"since"`
+	Routes []RouteSnapshot `json:"routes"`
+}
+
+// Snapshot devuelve una copia de las métricas, ordenada por número de peticiones.
+func (r *Registry) Snapshot() Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := Snapshot{Since: r.started, Routes: make([]RouteSnapshot, 0, len(r.routes))}
+	for key, stats := range r.routes {
+		route := RouteSnapshot{
+			Method:         key.method,
+			Route:          key.route,
+			Requests:       stats.count,
+			Panics:         stats.panics,
+			Statuses:       make(map[string]int64, len(stats.statuses)),
+			BytesSent:      stats.bytes,
+			MaxLatencyMs:   toMs(stats.maxLatency),
+			LatencyBuckets: make([]Bucket, len(stats.buckets)),
+		}
+		for status, count := range stats.statuses {
+			route.Statuses[strconv.Itoa(status)] = count
+			if status >= 500 {
+				route.Errors += count
+			}
+		}
+		if stats.count > 0 {
+			route.AvgLatencyMs = toMs(stats.sumLatency) / float64(stats.count)
+		}
+		var cumulative int64
+		for i, count := range stats.buckets {
+			cumulative += count
+			le := "+Inf"
+			if i < len(LatencyBuckets) {
+				le = strconv.FormatFloat(LatencyBuckets[i], 'f', -1, 64)
+			}
+			route.LatencyBuckets[i] = Bucket{LeMs: le, Count: cumulative}
+		}
+		route.P50LatencyMs = stats.quantile(0.50)
+		route.P95LatencyMs = stats.quantile(0.95)
+		route.P99LatencyMs = stats.quantile(0.99)
+		snapshot.Routes = append(snapshot.Routes, route)
+	}
+	sort.Slice(snapshot.Routes, func(i, j int) bool {
+		if snapshot.Routes[i].Requests != snapshot.Routes[j].Requests {
+			return snapshot.Routes[i].Requests > snapshot.Routes[j].Requests
+		}
+		return snapshot.Routes[i].Route < snapshot.Routes[j].Route
+	})
+	return snapshot
+}
+
+// Reset descarta las métricas acumuladas.
+func (r *Registry) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = make(map[routeKey]*routeStats)
+	r.started = time.Now()
+}
+
+// quantile estima el percentil interpolando linealmente dentro del bucket que lo contiene.
+// En el bucket +Inf se usa la latencia máxima observada como límite superior.
+func (s *routeStats) quantile(q float64) float64 {
+	if s.count == 0 {
+		return 0
+	}
+	rank := q * float64(s.count)
+	var cumulative int64
+	for i, count := range s.buckets {
+		if count == 0 || float64(cumulative+count) < rank {
+			cumulative += count
+			continue
+		}
+		lower := 0.0
+		if i > 0 {
+			lower = LatencyBuckets[i-1]
+		}
+		upper := toMs(s.maxLatency)
+		if i < len(LatencyBuckets) && LatencyBuckets[i] < upper {
+			upper = LatencyBuckets[i]
+		}
+		if upper < lower {
+			upper = lower
+		}
+		return lower + (upper-lower)*(rank-float64(cumulative))/float64(count)
+	}
+	return toMs(s.maxLatency)
+}
+
+func toMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,90 @@
+package imagemoderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTP clasifica imágenes con un servicio externo. La imagen se envía en el cuerpo de un POST
+// (con su Content-Type y "Authorization: Bearer <apiKey>" si hay clave) y el servicio
+// responde 200 con:
+//
+//	{"score": 0.93, "labels": ["nudity"]}
+//
+// Las APIs de proveedores con otro formato se integran con un adaptador que exponga este
+// contrato.
+type HTTP struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// httpClassifyResponse es el cuerpo de la respuesta del servicio.
+type httpClassifyResponse struct {
+	Score  *float64 `json:"score"`
+	Labels []string `json:"labels"`
+}
+
+// NewHTTP crea un clasificador que envía las imágenes a url.
+func NewHTTP(url, apiKey string, timeout time.Duration) *HTTP {
+	return &HTTP{url: url, apiKey: apiKey, client: &http.Client{Timeout: timeout}}
+}
+
+func (h *HTTP) request(ctx context.Context, method, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, h.url, body)
+	if err != nil {
+		return nil, fmt.Errorf("imagemoderation: petición inválida: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if h.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.apiKey)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("imagemoderation: error llamando al servicio de clasificación: %w", err)
+	}
+	return resp, nil
+}
+
+func (h *HTTP) Classify(ctx context.Context, image []byte, contentType string) (Result, error) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	resp, err := h.request(ctx, http.MethodPost, contentType, bytes.NewReader(image))
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("imagemoderation: el servicio de clasificación respondió %d", resp.StatusCode)
+	}
+	var verdict httpClassifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return Result{}, fmt.Errorf("imagemoderation: respuesta inválida del servicio de clasificación: %w", err)
+	}
+	if verdict.Score == nil || *verdict.Score < 0 || *verdict.Score > 1 {
+		return Result{}, fmt.Errorf("imagemoderation: el servicio de clasificación no devolvió un score entre 0 y 1")
+	}
+	return Result{Score: *verdict.Score, Labels: verdict.Labels}, nil
+}
+
+// Ping hace un HEAD a la URL del servicio; cualquier respuesta por debajo de 500 cuenta como
+// disponible.
+func (h *HTTP) Ping(ctx context.Context) error {
+	resp, err := h.request(ctx, http.MethodHead, "", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("imagemoderation: el servicio de clasificación respondió %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Package imagemoderation clasifica imágenes con un modelo de detección de contenido
+// explícito (NSFW) antes de publicarlas.
+//
+// Drivers:
+//   - http: modelo o API de clasificación detrás de un endpoint HTTP (ver NewHTTP).
+//
+// Sin driver configurado New devuelve nil y las imágenes no se clasifican.
+package imagemoderation
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DriverHTTP es el único driver de Options.Driver por ahora.
+const DriverHTTP = "http"
+
+// Result es la clasificación de una imagen.
+type Result struct {
+	Score  float64  // Probabilidad de contenido no permitido, entre 0 y 1
+	Labels []string // Categorías detectadas (p.ej. "nudity", "gore"), si el modelo las informa
+}
+
+// Classifier clasifica imágenes.
+type Classifier interface {
+	// Classify devuelve la clasificación de la imagen. Un error significa que no se pudo
+	// clasificar, no que la imagen no esté permitida.
+	Classify(ctx context.Context, image []byte, contentType string) (Result, error)
+	// Ping comprueba que el servicio de clasificación responde.
+	Ping(ctx context.Context) error
+}
+
+// Options es la configuración del clasificador.
+type Options struct {
+	Driver  string
+	URL     string
+	APIKey  string
+	Timeout time.Duration // Límite de cada clasificación
+}
+
+// New crea el clasificador del driver configurado, o nil si Driver está vacío.
+func New(opts Options) (Classifier, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	switch opts.Driver {
+	case "":
+		return nil, nil
+	case DriverHTTP:
+		if opts.URL == "" {
+			return nil, fmt.Errorf("imagemoderation: el driver http requiere la URL del servicio")
+		}
+		return NewHTTP(opts.URL, opts.APIKey, opts.Timeout), nil
+	default:
+		return nil, fmt.Errorf("imagemoderation: driver desconocido %q", opts.Driver)
+	}
+}
@@ -181,7 +181,7 @@ func ProxyLog(method, path, target, status string, duration time.Duration) {
 		methodColor = ColorWhite
 	}
 
-	message := fmt.Sprintf("%s%s%s %s → %s%s %s%s%s %s[%v]%s",
+	message := fmt.Sprintf("%s%s%s %s → %s%s%s %s%s%s %s[%v]%s",
 		methodColor, method, ColorReset,
 		path,
 		ColorCyan, target, ColorReset,
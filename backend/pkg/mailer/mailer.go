@@ -0,0 +1,58 @@
+// Package mailer envía correos HTML por SMTP con la configuración SMTP_* del servicio.
+//
+// Si SMTP_HOST no está configurado el Mailer queda deshabilitado: Send no envía nada
+// y devuelve ErrDisabled, de modo que en desarrollo los flujos que notifican por correo
+// siguen funcionando.
+package mailer
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/mail.v2"
+)
+
+// ErrDisabled indica que no hay servidor SMTP configurado.
+var ErrDisabled = errors.New("mailer: SMTP no configurado")
+
+// Mailer envía correos a través de un servidor SMTP.
+type Mailer struct {
+	dialer *mail.Dialer
+	from   string
+}
+
+// New crea un Mailer. Con host vacío el Mailer queda deshabilitado.
+func New(host string, port int, username, password, from string) *Mailer {
+	if host == "" {
+		return &Mailer{}
+	}
+	if from == "" {
+		from = username
+	}
+	return &Mailer{
+		dialer: mail.NewDialer(host, port, username, password),
+		from:   from,
+	}
+}
+
+// Enabled indica si hay un servidor SMTP configurado.
+func (m *Mailer) Enabled() bool {
+	return m != nil && m.dialer != nil
+}
+
+// Send envía un correo HTML a un único destinatario.
+func (m *Mailer) Send(to, subject, htmlBody string) error {
+	if !m.Enabled() {
+		return ErrDisabled
+	}
+	msg := mail.NewMessage()
+	msg.SetHeader("From", m.from)
+	msg.SetHeader("To", to)
+	msg.SetHeader("Subject", subject)
+	msg.SetBody("text/html", htmlBody)
+
+	if err := m.dialer.DialAndSend(msg); err != nil {
+		return fmt.Errorf("mailer: error enviando correo a %s: %w", to, err)
+	}
+	return nil
+}
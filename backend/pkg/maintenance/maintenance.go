@@ -0,0 +1,194 @@
+// Package maintenance implementa el modo mantenimiento común al proxy y al servidor
+// WebSocket.
+//
+// Con el modo activo, Middleware responde 503 (código GEN_009, con Retry-After) a las
+// peticiones nuevas salvo las de los prefijos exentos, como las rutas de administración. El
+// estado se cambia desde la configuración al arrancar o con Handler (GET devuelve el estado,
+// POST lo cambia); cada cambio se notifica a los observadores registrados con OnChange.
+package maintenance
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/apperrors"
+)
+
+// DefaultMessage es el mensaje que reciben los clientes si no se indica otro.
+const DefaultMessage = "El servicio está en mantenimiento. Vuelve a intentarlo en unos minutos."
+
+// State es el estado del modo mantenimiento.
+type State struct {
+	Enabled bool      `json:"enabled"`
+	Message string    `json:"message,omitempty"`
+	Since   time.Time `json:"since,omitempty"` // Momento en que se activó
+	// DrainAt es cuándo el servidor WebSocket cierra las conexiones; hasta entonces avisa a
+	// los clientes con una cuenta atrás.
+	DrainAt           time.Time `json:"drainAt,omitempty"`
+	RetryAfterSeconds int       `json:"retryAfterSeconds,omitempty"` // Cabecera Retry-After de las respuestas 503
+}
+
+// Options es la configuración inicial del modo mantenimiento.
+type Options struct {
+	Enabled     bool
+	Message     string
+	RetryAfter  time.Duration
+	Countdown   time.Duration
+	ExemptPaths []string // Prefijos de path que se atienden aunque el modo esté activo
+}
+
+// Mode guarda el estado del modo mantenimiento de un proceso.
+type Mode struct {
+	defaults Options
+	exempt   []string
+
+	mu        sync.RWMutex
+	state     State
+	listeners []func(State)
+}
+
+// New crea el modo mantenimiento, activo desde el arranque si opts.Enabled.
+func New(opts Options) *Mode {
+	if opts.Message == "" {
+		opts.Message = DefaultMessage
+	}
+	m := &Mode{defaults: opts}
+	for _, prefix := range opts.ExemptPaths {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			m.exempt = append(m.exempt, prefix)
+		}
+	}
+	m.state = m.newState(opts.Enabled, "", nil)
+	return m
+}
+
+// State devuelve el estado actual.
+func (m *Mode) State() State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// Enabled indica si el modo mantenimiento está activo.
+func (m *Mode) Enabled() bool {
+	return m.State().Enabled
+}
+
+// OnChange registra una función que se llama tras cada cambio de estado, en orden. fn no debe
+// bloquear ni llamar a los métodos de Mode. Si el modo ya está activo al registrarla,
+// se llama con el estado actual.
+func (m *Mode) OnChange(fn func(State)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, fn)
+	if m.state.Enabled {
+		fn(m.state)
+	}
+}
+
+// Set activa o desactiva el modo. message vacío y countdown nil usan los valores de Options.
+// Activarlo de nuevo con el modo activo actualiza el mensaje y reinicia la cuenta atrás.
+func (m *Mode) Set(enabled bool, message string, countdown *time.Duration) State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = m.newState(enabled, message, countdown)
+	// Se notifica con el lock tomado para que los observadores vean los cambios en orden.
+	for _, fn := range m.listeners {
+		fn(m.state)
+	}
+	return m.state
+}
+
+func (m *Mode) newState(enabled bool, message string, countdown *time.Duration) State {
+	if !enabled {
+		return State{}
+	}
+	state := State{
+		Enabled:           true,
+		Message:           m.defaults.Message,
+		Since:             time.Now(),
+		RetryAfterSeconds: int(m.defaults.RetryAfter.Seconds()),
+	}
+	if message != "" {
+		state.Message = message
+	}
+	wait := m.defaults.Countdown
+	if countdown != nil && *countdown >= 0 {
+		wait = *countdown
+	}
+	state.DrainAt = state.Since.Add(wait)
+	return state
+}
+
+// Exempt indica si el path se atiende aunque el modo esté activo.
+func (m *Mode) Exempt(path string) bool {
+	for _, prefix := range m.exempt {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reject responde 503 si el modo está activo y el path no está exento. Devuelve true si
+// respondió; en ese caso la petición no debe atenderse.
+func (m *Mode) Reject(w http.ResponseWriter, r *http.Request) bool {
+	state := m.State()
+	if !state.Enabled || m.Exempt(r.URL.Path) {
+		return false
+	}
+	if state.RetryAfterSeconds > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(state.RetryAfterSeconds))
+	}
+	apperrors.Write(w, apperrors.Maintenance, state.Message)
+	return true
+}
+
+// Middleware rechaza con Reject las peticiones que llegan con el modo activo.
+func (m *Mode) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if m.Reject(w, r) {
+			return
+		}
+		next(w, r)
+	}
+}
+
+// SetRequest es el cuerpo de POST en Handler. Sin message ni countdownSeconds se usan los
+// valores de la configuración.
+type SetRequest struct {
+	Enabled          bool   `json:"enabled"`
+	Message          string `json:"message"`
+	CountdownSeconds *int   `json:"countdownSeconds"`
+}
+
+// Handler devuelve el estado (GET) o lo cambia (POST con SetRequest). No autentica: quien lo
+// registra debe protegerlo.
+func (m *Mode) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			var req SetRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				apperrors.Write(w, apperrors.InvalidBody, "Cuerpo inválido")
+				return
+			}
+			var countdown *time.Duration
+			if req.CountdownSeconds != nil {
+				d := time.Duration(*req.CountdownSeconds) * time.Second
+				countdown = &d
+			}
+			m.Set(req.Enabled, strings.TrimSpace(req.Message), countdown)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m.State())
+	}
+}
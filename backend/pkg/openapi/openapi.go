@@ -0,0 +1,270 @@
+// Package openapi genera una especificación OpenAPI 3.0 a partir de una lista de rutas y de
+// los structs de sus cuerpos, y la sirve como JSON y con Swagger UI.
+//
+// Los esquemas se obtienen por reflexión de los tipos Go, con los mismos nombres de campo que
+// usa encoding/json. Las reglas de la etiqueta `validate` (ver pkg/validation) se traducen a
+// sus equivalentes de JSON Schema: required, minLength/maxLength, minimum/maximum,
+// minItems/maxItems y enum. Así la documentación y la validación de las peticiones salen de
+// la misma definición.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Version es la versión de OpenAPI de los documentos generados.
+const Version = "3.0.3"
+
+// Document es la raíz de una especificación OpenAPI.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+	Tags       []Tag                `json:"tags,omitempty"`
+}
+
+// Info describe la API.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server es una URL base de la API.
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag agrupa operaciones en Swagger UI.
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// PathItem reúne las operaciones de una ruta, por método en minúsculas ("get", "post"...).
+type PathItem map[string]*Operation
+
+// Operation describe un método de una ruta.
+type Operation struct {
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []SecurityRequirement `json:"security"`
+}
+
+// Parameter es un parámetro de ruta o de query.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody es el cuerpo de una operación.
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response es una respuesta de una operación.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType asocia un tipo de contenido con su esquema.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components guarda los esquemas con nombre y los esquemas de seguridad.
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describe una forma de autenticarse.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// SecurityRequirement indica un esquema de seguridad que acepta la operación.
+type SecurityRequirement map[string][]string
+
+// Route es una ruta a documentar.
+type Route struct {
+	Method   string
+	Path     string // Plantilla de gorilla/mux, ej. /users/{id:[0-9]+}
+	Summary  string
+	Tags     []string    // Vacío = el primer segmento de la ruta
+	Security []string    // Esquemas de Spec.SecuritySchemes que acepta; vacío = pública
+	Request  interface{} // Valor (o puntero) del tipo del cuerpo JSON; nil = sin cuerpo
+	Response interface{} // Valor del tipo de la respuesta correcta; nil = sin esquema
+}
+
+// Spec son los datos comunes del documento.
+type Spec struct {
+	Info            Info
+	Servers         []Server
+	SecuritySchemes map[string]*SecurityScheme
+	Error           interface{} // Cuerpo de las respuestas de error; nil = sin esquema
+}
+
+// Build genera el documento de las rutas. Las rutas repetidas (mismo método y plantilla) se
+// documentan una vez.
+func Build(spec Spec, routes []Route) *Document {
+	gen := newGenerator("#/components/schemas/")
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    spec.Info,
+		Servers: spec.Servers,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas:         gen.components,
+			SecuritySchemes: spec.SecuritySchemes,
+		},
+	}
+
+	var errSchema *Schema
+	if spec.Error != nil {
+		errSchema = gen.schemaOf(spec.Error)
+	}
+
+	tags := make(map[string]struct{})
+	for _, route := range routes {
+		path, params := convertPath(route.Path)
+		item := doc.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		method := strings.ToLower(route.Method)
+		if _, exists := (*item)[method]; exists {
+			continue
+		}
+
+		op := &Operation{
+			Summary:     route.Summary,
+			OperationID: operationID(route.Method, path),
+			Tags:        route.Tags,
+			Parameters:  params,
+			Responses:   make(map[string]*Response),
+			Security:    []SecurityRequirement{},
+		}
+		if len(op.Tags) == 0 {
+			op.Tags = []string{defaultTag(path)}
+		}
+		for _, tag := range op.Tags {
+			tags[tag] = struct{}{}
+		}
+		for _, name := range route.Security {
+			op.Security = append(op.Security, SecurityRequirement{name: {}})
+		}
+		if route.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]*MediaType{"application/json": {Schema: gen.schemaOf(route.Request)}},
+			}
+		}
+
+		ok := &Response{Description: "Operación correcta"}
+		if route.Response != nil {
+			ok.Content = map[string]*MediaType{"application/json": {Schema: gen.schemaOf(route.Response)}}
+		}
+		op.Responses["2XX"] = ok
+		failed := &Response{Description: "Error (ver errorCode en docs/codigos_de_error.md)"}
+		if errSchema != nil {
+			failed.Content = map[string]*MediaType{"application/json": {Schema: errSchema}}
+		}
+		op.Responses["default"] = failed
+
+		(*item)[method] = op
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	return doc
+}
+
+// pathParam captura los parámetros de una plantilla de gorilla/mux: {name} o {name:regexp}.
+var pathParam = regexp.MustCompile(`\{([^{}:]+)(?::((?:[^{}]|\{[^{}]*\})*))?\}`)
+
+// integerPatterns son las expresiones de los parámetros que se documentan como enteros.
+var integerPatterns = map[string]bool{`[0-9]+`: true, `\d+`: true}
+
+// convertPath pasa una plantilla de gorilla/mux a la sintaxis de OpenAPI y devuelve sus
+// parámetros.
+func convertPath(template string) (string, []Parameter) {
+	var params []Parameter
+	path := pathParam.ReplaceAllStringFunc(template, func(m string) string {
+		sub := pathParam.FindStringSubmatch(m)
+		schema := &Schema{Type: "string"}
+		if integerPatterns[sub[2]] {
+			schema = &Schema{Type: "integer", Format: "int64"}
+		} else if sub[2] != "" && sub[2] != ".+" {
+			schema.Pattern = "^" + sub[2] + "$"
+		}
+		params = append(params, Parameter{Name: sub[1], In: "path", Required: true, Schema: schema})
+		return "{" + sub[1] + "}"
+	})
+	if path == "" {
+		path = "/"
+	}
+	return path, params
+}
+
+// defaultTag es el primer segmento estático de la ruta.
+func defaultTag(path string) string {
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" && !strings.HasPrefix(segment, "{") {
+			return segment
+		}
+	}
+	return "default"
+}
+
+// operationID genera un identificador estable a partir del método y la ruta, ej.
+// "post_users_me_devices".
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.Trim(segment, "{}")
+		if segment == "" {
+			continue
+		}
+		b.WriteByte('_')
+		b.WriteString(strings.ReplaceAll(segment, "-", "_"))
+	}
+	return b.String()
+}
+
+// Handler sirve el documento como JSON. Se serializa una sola vez.
+func Handler(doc *Document) http.HandlerFunc {
+	body, err := json.Marshal(doc)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			http.Error(w, "no se pudo generar la especificación OpenAPI", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(body)
+	}
+}
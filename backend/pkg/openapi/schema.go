@@ -0,0 +1,291 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema es un esquema de OpenAPI 3.0 (subconjunto de JSON Schema).
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// generator convierte tipos Go en esquemas. Los structs con nombre se guardan en components y
+// se referencian con $ref, lo que además resuelve los tipos recursivos.
+type generator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+	refPrefix  string // Ruta de components en el documento, ej. "#/components/schemas/"
+}
+
+func newGenerator(refPrefix string) *generator {
+	return &generator{components: make(map[string]*Schema), names: make(map[reflect.Type]string), refPrefix: refPrefix}
+}
+
+// schemaOf devuelve el esquema del tipo de v.
+func (g *generator) schemaOf(v interface{}) *Schema {
+	return g.schema(reflect.TypeOf(v))
+}
+
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		s := g.schema(t.Elem())
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// Serialización propia: no se puede deducir su forma
+		return &Schema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.ref(t)
+	default:
+		// interface{} y tipos sin representación JSON
+		return &Schema{}
+	}
+}
+
+// ref registra el struct en components y devuelve su referencia.
+func (g *generator) ref(t reflect.Type) *Schema {
+	name, ok := g.names[t]
+	if !ok {
+		name = g.componentName(t)
+		g.names[t] = name
+		g.components[name] = &Schema{} // Reservado antes de recorrer los campos (tipos recursivos)
+		*g.components[name] = *g.structSchema(t)
+	}
+	return &Schema{Ref: g.refPrefix + name}
+}
+
+// typeArgPath es la ruta de paquete de un argumento de tipo genérico, sin el último elemento.
+var typeArgPath = regexp.MustCompile(`[\w.\-]+/`)
+
+// componentName es "paquete.Tipo", con sufijo numérico si dos paquetes comparten nombre.
+func (g *generator) componentName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	base := pkg
+	if base != "" {
+		base += "."
+	}
+	// Los tipos genéricos incluyen la ruta de sus argumentos: "Page[github.com/.../models.X]"
+	// queda "Page_models.X".
+	base += strings.NewReplacer("[", "_", "]", "", ",", "_").Replace(typeArgPath.ReplaceAllString(t.Name(), ""))
+	base = strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, base)
+
+	name := base
+	for i := 2; ; i++ {
+		if _, taken := g.components[name]; !taken {
+			return name
+		}
+		name = base + strconv.Itoa(i)
+	}
+}
+
+// structSchema recorre los campos exportados igual que encoding/json: respeta la etiqueta
+// `json` e integra los campos de los structs embebidos sin nombre JSON.
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(s, t)
+	return s
+}
+
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		var fs *Schema
+		if hasOption(opts, "string") {
+			fs = &Schema{Type: "string"}
+		} else {
+			fs = g.schema(sf.Type)
+		}
+		if applyRules(fs, sf.Tag.Get("validate")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = fs
+	}
+}
+
+func hasOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRules traduce las reglas de la etiqueta `validate` al esquema del campo. Devuelve true
+// si el campo es obligatorio. Las reglas no se aplican sobre una referencia ($ref no admite
+// palabras clave hermanas en OpenAPI 3.0).
+func applyRules(s *Schema, tag string) (required bool) {
+	if tag == "" || tag == "-" {
+		return false
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		key, arg, _ := strings.Cut(rule, "=")
+		if key == "required" {
+			required = true
+			continue
+		}
+		if s.Ref != "" {
+			continue
+		}
+		switch key {
+		case "min", "max":
+			limit, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			setBound(s, key, limit)
+		case "oneof":
+			for _, value := range strings.Fields(arg) {
+				if s.Type == "integer" {
+					if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+						s.Enum = append(s.Enum, n)
+					}
+					continue
+				}
+				s.Enum = append(s.Enum, value)
+			}
+		}
+	}
+	return required
+}
+
+func setBound(s *Schema, rule string, limit float64) {
+	n := int(limit)
+	switch s.Type {
+	case "string":
+		if rule == "min" {
+			s.MinLength = &n
+		} else {
+			s.MaxLength = &n
+		}
+	case "array":
+		if rule == "min" {
+			s.MinItems = &n
+		} else {
+			s.MaxItems = &n
+		}
+	case "integer", "number":
+		if rule == "min" {
+			s.Minimum = &limit
+		} else {
+			s.Maximum = &limit
+		}
+	}
+}
+
+// SchemaSet genera esquemas de varios tipos que comparten las definiciones de sus structs con
+// nombre, para documentos que no son OpenAPI (ej. un JSON Schema con "definitions").
+type SchemaSet struct {
+	gen *generator
+}
+
+// NewSchemaSet crea un conjunto cuyas referencias apuntan a refPrefix + nombre, ej.
+// "#/definitions/".
+func NewSchemaSet(refPrefix string) *SchemaSet {
+	return &SchemaSet{gen: newGenerator(refPrefix)}
+}
+
+// Schema devuelve el esquema del tipo de v y registra sus structs en Definitions.
+func (s *SchemaSet) Schema(v interface{}) *Schema {
+	return s.gen.schemaOf(v)
+}
+
+// Definitions devuelve los esquemas de los structs con nombre registrados, por nombre.
+func (s *SchemaSet) Definitions() map[string]*Schema {
+	return s.gen.components
+}
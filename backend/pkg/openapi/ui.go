@@ -0,0 +1,39 @@
+package openapi
+
+import (
+	"html/template"
+	"net/http"
+)
+
+// SwaggerUIVersion es la versión de swagger-ui-dist que carga la página de documentación.
+const SwaggerUIVersion = "5.17.14"
+
+var uiTemplate = template.Must(template.New("swagger-ui").Parse(`<!DOCTYPE html>
+<html lang="es">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: {{.SpecURL}}, dom_id: "#swagger-ui", deepLinking: true });
+  </script>
+</body>
+</html>
+`))
+
+// UIHandler sirve Swagger UI para la especificación publicada en specURL. Los recursos de
+// Swagger UI se cargan desde unpkg, así que el navegador necesita acceso a internet.
+func UIHandler(title, specURL string) http.HandlerFunc {
+	data := struct{ Title, Version, SpecURL string }{title, SwaggerUIVersion, specURL}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := uiTemplate.Execute(w, data); err != nil {
+			http.Error(w, "no se pudo generar la página de documentación", http.StatusInternalServerError)
+		}
+	}
+}
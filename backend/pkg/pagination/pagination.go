@@ -0,0 +1,172 @@
+// Package pagination implementa la paginación por cursor (keyset) de los listados.
+//
+// Con LIMIT/OFFSET la base de datos recorre y descarta todas las filas anteriores a la página,
+// así que las páginas profundas son cada vez más lentas, y si entra una fila nueva mientras el
+// cliente pagina, la siguiente página repite un elemento. Con cursor, cada página continúa
+// después del último elemento de la anterior: la consulta filtra con
+//
+//	WHERE (CreatedAt < ? OR (CreatedAt = ? AND Id < ?))
+//	ORDER BY CreatedAt DESC, Id DESC
+//	LIMIT limit+1
+//
+// y usa el índice sobre (CreatedAt, Id) sin importar la profundidad. La fila de más indica si
+// hay otra página.
+//
+// El cursor es opaco para el cliente: base64 de un JSON con la fecha y el Id del último
+// elemento. El cliente lo devuelve tal cual en "cursor" para pedir la página siguiente; un
+// cursor vacío pide la primera.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultLimit es el tamaño de página cuando el cliente no indica limit.
+	DefaultLimit = 20
+	// MaxLimit es el tamaño de página máximo; un limit mayor se recorta.
+	MaxLimit = 100
+)
+
+// ErrInvalidCursor indica que el cursor no lo generó este paquete o está corrupto.
+var ErrInvalidCursor = errors.New("cursor inválido")
+
+// Cursor es la posición del último elemento de una página.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"i"`
+	// Kind desempata entre fuentes distintas de un mismo listado (ej. el tipo de item del
+	// feed), cuyos Id pueden coincidir. Vacío en los listados de una sola tabla.
+	Kind string `json:"k,omitempty"`
+}
+
+// Encode devuelve el cursor en el formato opaco que recibe el cliente.
+func (c Cursor) Encode() string {
+	c.CreatedAt = c.CreatedAt.UTC()
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// Decode interpreta un cursor de Encode. Un cursor vacío devuelve nil: la primera página.
+func Decode(value string) (*Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" || c.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Params son los parámetros de una petición paginada por cursor.
+type Params struct {
+	After *Cursor // nil para la primera página
+	Limit int
+}
+
+// New valida el cursor y normaliza limit: DefaultLimit si no es positivo, como mucho MaxLimit.
+func New(cursor string, limit int) (Params, error) {
+	after, err := Decode(cursor)
+	if err != nil {
+		return Params{}, err
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	return Params{After: after, Limit: limit}, nil
+}
+
+// FromQuery lee los parámetros "cursor" y "limit" de la query string. ok es false si la
+// petición no incluye "cursor": el cliente usa todavía la paginación por página u offset y el
+// handler debe responder como antes. "cursor=" (vacío) pide la primera página.
+func FromQuery(query url.Values) (params Params, ok bool, err error) {
+	if !query.Has("cursor") {
+		return Params{}, false, nil
+	}
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil {
+			return Params{}, true, errors.New("limit debe ser un número entero")
+		}
+	}
+	params, err = New(query.Get("cursor"), limit)
+	return params, true, err
+}
+
+// FetchLimit es el LIMIT de la consulta: una fila más que la página, para saber si hay otra.
+func (p Params) FetchLimit() int {
+	return p.Limit + 1
+}
+
+// Where devuelve la condición keyset para continuar después del cursor en un listado ordenado
+// por createdAtCol DESC, idCol DESC, y sus argumentos. Sin cursor devuelve "". El Id se pasa
+// como texto: en las columnas VARCHAR el orden es el del texto y en las BIGINT MySQL lo
+// convierte a número.
+func (p Params) Where(createdAtCol, idCol string) (string, []interface{}) {
+	if p.After == nil {
+		return "", nil
+	}
+	return Before([]string{createdAtCol, idCol}, []interface{}{p.After.CreatedAt, p.After.ID})
+}
+
+// Before construye la condición "la tupla columns va después de values" en un orden
+// descendente por todas las columnas, ej. para (a, b, c):
+//
+//	(a < ? OR (a = ? AND (b < ? OR (b = ? AND c < ?))))
+//
+// MySQL no usa el índice con la comparación de tuplas (a, b) < (?, ?), por eso se expande.
+func Before(columns []string, values []interface{}) (string, []interface{}) {
+	last := len(columns) - 1
+	var cond strings.Builder
+	var args []interface{}
+	for i, col := range columns {
+		if i == last {
+			cond.WriteString(col + " < ?")
+			args = append(args, values[i])
+			break
+		}
+		cond.WriteString("(" + col + " < ? OR (" + col + " = ? AND ")
+		args = append(args, values[i], values[i])
+	}
+	cond.WriteString(strings.Repeat("))", last))
+	return cond.String(), args
+}
+
+// Page es el sobre común de las respuestas paginadas por cursor.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// NextCursor es el cursor para pedir la página siguiente; vacío si no hay más.
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+// NewPage construye la página a partir de las filas de una consulta con LIMIT FetchLimit():
+// descarta la fila de más y calcula el cursor del último elemento con cursorOf.
+func NewPage[T any](rows []T, limit int, cursorOf func(T) Cursor) Page[T] {
+	page := Page[T]{Items: rows}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(rows) > limit {
+		page.Items = rows[:limit]
+		page.HasMore = true
+		if limit > 0 {
+			page.NextCursor = cursorOf(page.Items[limit-1]).Encode()
+		}
+	}
+	return page
+}
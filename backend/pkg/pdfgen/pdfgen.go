@@ -0,0 +1,167 @@
+// Package pdfgen genera documentos PDF de texto simples sin dependencias externas.
+//
+// El contenido se describe con un marcado mínimo, una instrucción por línea:
+//
+//	# Título          -> encabezado principal (Helvetica-Bold 18pt)
+//	## Sección        -> encabezado de sección (Helvetica-Bold 13pt)
+//	### Subtítulo     -> texto destacado (Helvetica-Bold 10pt)
+//	texto             -> párrafo (Helvetica 10pt), con ajuste de línea automático
+//	(línea vacía)     -> espacio vertical
+//
+// Está pensado para documentos generados por el servidor (p. ej. exportación de CV)
+// a partir de una plantilla text/template.
+package pdfgen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Dimensiones de página A4 en puntos y márgenes.
+const (
+	pageWidth    = 595.28
+	pageHeight   = 841.89
+	marginX      = 50.0
+	marginTop    = 60.0
+	marginBottom = 60.0
+)
+
+type lineStyle struct {
+	font     string // Nombre del recurso de fuente (/F1 regular, /F2 negrita)
+	size     float64
+	leading  float64
+	spaceTop float64
+}
+
+var (
+	styleTitle   = lineStyle{font: "F2", size: 18, leading: 22, spaceTop: 0}
+	styleSection = lineStyle{font: "F2", size: 13, leading: 17, spaceTop: 8}
+	styleStrong  = lineStyle{font: "F2", size: 10, leading: 13, spaceTop: 4}
+	styleBody    = lineStyle{font: "F1", size: 10, leading: 13, spaceTop: 0}
+)
+
+// RenderMarkup convierte el marcado descrito en la documentación del paquete en un PDF.
+func RenderMarkup(markup string) ([]byte, error) {
+	encoder := encoding.ReplaceUnsupported(charmap.Windows1252.NewEncoder())
+
+	var pages []string
+	var page strings.Builder
+	y := pageHeight - marginTop
+
+	newPage := func() {
+		pages = append(pages, page.String())
+		page.Reset()
+		y = pageHeight - marginTop
+	}
+
+	for _, raw := range strings.Split(strings.ReplaceAll(markup, "\r\n", "\n"), "\n") {
+		line := strings.TrimRight(raw, " \t")
+		style := styleBody
+		switch {
+		case strings.HasPrefix(line, "### "):
+			style, line = styleStrong, strings.TrimSpace(line[4:])
+		case strings.HasPrefix(line, "## "):
+			style, line = styleSection, strings.TrimSpace(line[3:])
+		case strings.HasPrefix(line, "# "):
+			style, line = styleTitle, strings.TrimSpace(line[2:])
+		}
+
+		if line == "" {
+			y -= styleBody.leading / 2
+			continue
+		}
+
+		for _, wrapped := range wrapText(line, style.size) {
+			if y-style.spaceTop-style.leading < marginBottom {
+				newPage()
+			}
+			y -= style.spaceTop + style.leading
+			style.spaceTop = 0
+
+			encoded, err := encoder.String(wrapped)
+			if err != nil {
+				return nil, fmt.Errorf("pdfgen: error codificando texto: %w", err)
+			}
+			fmt.Fprintf(&page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", style.font, style.size, marginX, y, escapeString(encoded))
+		}
+	}
+	if page.Len() > 0 || len(pages) == 0 {
+		newPage()
+	}
+
+	return assemble(pages), nil
+}
+
+// wrapText divide una línea en fragmentos que caben en el ancho útil de la página.
+// Usa un ancho medio de carácter aproximado para Helvetica (0.5em).
+func wrapText(text string, fontSize float64) []string {
+	maxChars := int((pageWidth - 2*marginX) / (fontSize * 0.5))
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil
+	}
+
+	var lines []string
+	current := words[0]
+	for _, word := range words[1:] {
+		if len([]rune(current))+1+len([]rune(word)) > maxChars {
+			lines = append(lines, current)
+			current = word
+			continue
+		}
+		current += " " + word
+	}
+	return append(lines, current)
+}
+
+// escapeString escapa los caracteres con significado especial en cadenas literales PDF.
+func escapeString(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+	return replacer.Replace(s)
+}
+
+// assemble construye el archivo PDF final (objetos, tabla xref y trailer).
+func assemble(pageContents []string) []byte {
+	var buf bytes.Buffer
+	var offsets []int
+
+	writeObject := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objetos fijos: 1 catálogo, 2 árbol de páginas, 3 y 4 fuentes.
+	// Cada página ocupa dos objetos: la página y su flujo de contenido.
+	kids := make([]string, len(pageContents))
+	for i := range pageContents {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pageContents)))
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, content := range pageContents {
+		writeObject(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+i*2,
+		))
+		writeObject(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+
+	return buf.Bytes()
+}
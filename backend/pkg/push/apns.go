@@ -0,0 +1,156 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	apnsProductionHost = "https://api.push.apple.com"
+	apnsSandboxHost    = "https://api.sandbox.push.apple.com"
+
+	// apnsTokenLifetime es cada cuánto se firma un nuevo JWT de proveedor. Apple rechaza los
+	// tokens con más de una hora y también los que se renuevan más de una vez cada 20 minutos.
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+// APNsSender envía notificaciones a dispositivos iOS por HTTP/2 con autenticación por token
+// (clave .p8 de la cuenta de desarrollador de Apple).
+type APNsSender struct {
+	host   string
+	topic  string
+	keyID  string
+	teamID string
+	key    *ecdsa.PrivateKey
+	client *http.Client
+
+	mu       sync.Mutex
+	jwt      string
+	issuedAt time.Time
+}
+
+// NewAPNsSender crea un Sender con la clave .p8 en keyFile. topic es el bundle ID de la
+// aplicación y sandbox selecciona el entorno de desarrollo de APNs. timeout limita cada envío.
+func NewAPNsSender(keyFile, keyID, teamID, topic string, sandbox bool, timeout time.Duration) (*APNsSender, error) {
+	pem, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("push: error leyendo la clave de APNs: %w", err)
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(pem)
+	if err != nil {
+		return nil, fmt.Errorf("push: clave de APNs inválida: %w", err)
+	}
+	if keyID == "" || teamID == "" || topic == "" {
+		return nil, fmt.Errorf("push: APNs requiere key ID, team ID y topic")
+	}
+
+	host := apnsProductionHost
+	if sandbox {
+		host = apnsSandboxHost
+	}
+	// El transporte por defecto negocia HTTP/2, que APNs exige.
+	return &APNsSender{
+		host:   host,
+		topic:  topic,
+		keyID:  keyID,
+		teamID: teamID,
+		key:    key,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// providerToken devuelve el JWT vigente, firmando uno nuevo si caducó.
+func (s *APNsSender) providerToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.jwt != "" && time.Since(s.issuedAt) < apnsTokenLifetime {
+		return s.jwt, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"iss": s.teamID, "iat": now.Unix()})
+	token.Header["kid"] = s.keyID
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("push: error firmando el token de APNs: %w", err)
+	}
+	s.jwt, s.issuedAt = signed, now
+	return signed, nil
+}
+
+// apnsPayload construye el cuerpo de la notificación: el diccionario aps y los datos de la
+// aplicación como claves de primer nivel.
+func apnsPayload(notification Notification) ([]byte, error) {
+	aps := map[string]interface{}{
+		"alert": map[string]string{"title": notification.Title, "body": notification.Body},
+		"sound": "default",
+	}
+	if notification.ThreadID != "" {
+		aps["thread-id"] = notification.ThreadID
+	}
+	if notification.Badge != nil {
+		aps["badge"] = *notification.Badge
+	}
+	payload := map[string]interface{}{"aps": aps}
+	for key, value := range notification.Data {
+		if key != "aps" {
+			payload[key] = value
+		}
+	}
+	return json.Marshal(payload)
+}
+
+// Send implementa Sender.
+func (s *APNsSender) Send(ctx context.Context, token string, notification Notification) error {
+	body, err := apnsPayload(notification)
+	if err != nil {
+		return err
+	}
+	bearer, err := s.providerToken()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.host+"/3/device/"+token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+bearer)
+	req.Header.Set("apns-topic", s.topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	if notification.CollapseKey != "" && len(notification.CollapseKey) <= 64 {
+		req.Header.Set("apns-collapse-id", notification.CollapseKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("push: error enviando a APNs: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var apnsErr struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&apnsErr)
+	switch {
+	case resp.StatusCode == http.StatusGone,
+		apnsErr.Reason == "BadDeviceToken",
+		apnsErr.Reason == "DeviceTokenNotForTopic",
+		apnsErr.Reason == "Unregistered":
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("push: APNs respondió %d: %s", resp.StatusCode, apnsErr.Reason)
+}
@@ -0,0 +1,131 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	fcmScope    = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmEndpoint = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+)
+
+// FCMSender envía notificaciones con la API HTTP v1 de Firebase Cloud Messaging,
+// autenticándose con una cuenta de servicio del proyecto de Firebase.
+type FCMSender struct {
+	url    string
+	client *http.Client
+}
+
+// NewFCMSender crea un Sender a partir del JSON de la cuenta de servicio. El proyecto se toma
+// del campo project_id del archivo. timeout limita cada envío.
+func NewFCMSender(credentialsFile string, timeout time.Duration) (*FCMSender, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("push: error leyendo las credenciales de FCM: %w", err)
+	}
+	ctx := context.Background()
+	creds, err := google.CredentialsFromJSON(ctx, data, fcmScope)
+	if err != nil {
+		return nil, fmt.Errorf("push: credenciales de FCM inválidas: %w", err)
+	}
+	if creds.ProjectID == "" {
+		return nil, errors.New("push: las credenciales de FCM no incluyen project_id")
+	}
+
+	client := oauth2.NewClient(ctx, creds.TokenSource)
+	client.Timeout = timeout
+	return &FCMSender{url: fmt.Sprintf(fcmEndpoint, creds.ProjectID), client: client}, nil
+}
+
+type fcmRequest struct {
+	Message fcmMessage `json:"message"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+	Android      *fcmAndroid       `json:"android,omitempty"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+type fcmAndroid struct {
+	CollapseKey  string                  `json:"collapse_key,omitempty"`
+	Priority     string                  `json:"priority"`
+	Notification *fcmAndroidNotification `json:"notification,omitempty"`
+}
+
+type fcmAndroidNotification struct {
+	Tag               string `json:"tag,omitempty"`
+	NotificationCount *int   `json:"notification_count,omitempty"`
+}
+
+type fcmErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// Send implementa Sender.
+func (s *FCMSender) Send(ctx context.Context, token string, notification Notification) error {
+	message := fcmMessage{
+		Token:        token,
+		Notification: fcmNotification{Title: notification.Title, Body: notification.Body},
+		Data:         notification.Data,
+		Android:      &fcmAndroid{CollapseKey: notification.CollapseKey, Priority: "HIGH"},
+	}
+	if notification.ThreadID != "" || notification.Badge != nil {
+		message.Android.Notification = &fcmAndroidNotification{Tag: notification.ThreadID, NotificationCount: notification.Badge}
+	}
+	body, err := json.Marshal(fcmRequest{Message: message})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("push: error enviando a FCM: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var fcmErr fcmErrorResponse
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	_ = json.Unmarshal(raw, &fcmErr)
+	for _, detail := range fcmErr.Error.Details {
+		// UNREGISTERED: la aplicación se desinstaló o el token caducó.
+		// SENDER_ID_MISMATCH: el token pertenece a otro proyecto de Firebase.
+		if detail.ErrorCode == "UNREGISTERED" || detail.ErrorCode == "SENDER_ID_MISMATCH" {
+			return ErrInvalidToken
+		}
+	}
+	return fmt.Errorf("push: FCM respondió %d (%s): %s", resp.StatusCode, fcmErr.Error.Status, fcmErr.Error.Message)
+}
@@ -0,0 +1,44 @@
+// Package push envía notificaciones push a dispositivos móviles a través de Firebase Cloud
+// Messaging (FCM, Android) y Apple Push Notification service (APNs, iOS), y a navegadores con
+// el protocolo Web Push (VAPID).
+//
+// Cada proveedor nativo implementa Sender; WebPushSender recibe la suscripción completa del
+// navegador en lugar de un token. Cuando el proveedor indica que el token ya no es válido
+// (aplicación desinstalada, token rotado o de otro proyecto) Send devuelve ErrInvalidToken y
+// el llamador debe dar de baja el dispositivo.
+package push
+
+import (
+	"context"
+	"errors"
+)
+
+// Proveedores de push (ENUM PushDevice.Provider).
+const (
+	ProviderFCM  = "FCM"
+	ProviderAPNs = "APNS"
+)
+
+// ErrInvalidToken indica que el proveedor rechazó el token del dispositivo de forma definitiva.
+var ErrInvalidToken = errors.New("push: token de dispositivo inválido o dado de baja")
+
+// Notification es el contenido de una notificación push.
+type Notification struct {
+	Title string
+	Body  string
+	// Data son pares clave-valor que la aplicación recibe junto a la notificación, por ejemplo
+	// el tipo y el ID del chat o evento que se debe abrir.
+	Data map[string]string
+	// ThreadID agrupa las notificaciones en el dispositivo (thread-id en APNs y tag en FCM).
+	ThreadID string
+	// CollapseKey reemplaza en el dispositivo una notificación pendiente con la misma clave.
+	CollapseKey string
+	// Badge es el número que muestra el icono de la aplicación (badge en APNs,
+	// notification_count en FCM y badgeCount en Web Push). nil no lo cambia.
+	Badge *int
+}
+
+// Sender envía una notificación a un dispositivo.
+type Sender interface {
+	Send(ctx context.Context, token string, notification Notification) error
+}
@@ -0,0 +1,291 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// webPushRecordSize es el tamaño de registro declarado en la cabecera aes128gcm. El
+	// contenido cifrado debe caber en un único registro.
+	webPushRecordSize = 4096
+	// webPushMaxPayload es el máximo de texto plano que cabe en un registro: se restan la
+	// etiqueta de AES-GCM (16 bytes) y el delimitador de relleno (1 byte).
+	webPushMaxPayload = webPushRecordSize - 17
+
+	webPushTTL = 24 * time.Hour
+
+	// vapidTokenLifetime es la validez del JWT de VAPID. El estándar permite hasta 24 horas.
+	vapidTokenLifetime = 12 * time.Hour
+)
+
+// ErrPayloadTooLarge indica que la notificación cifrada no cabe en un mensaje de Web Push.
+var ErrPayloadTooLarge = errors.New("push: la notificación supera el tamaño máximo de Web Push")
+
+// WebPushSubscription es la suscripción que el navegador entrega con PushManager.subscribe().
+type WebPushSubscription struct {
+	Endpoint string
+	P256dh   string // Clave pública del navegador (P-256, base64url)
+	Auth     string // Secreto de autenticación (16 bytes, base64url)
+}
+
+// WebPushSender envía notificaciones a navegadores con el protocolo Web Push, identificándose
+// ante el servicio de push del navegador con las claves VAPID (RFC 8292) y cifrando el
+// contenido según RFC 8291.
+type WebPushSender struct {
+	subject   string
+	publicKey string // Clave pública VAPID sin relleno, tal como la necesita el cliente
+	key       *ecdsa.PrivateKey
+	client    *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]vapidToken // Por origen del servicio de push
+}
+
+type vapidToken struct {
+	jwt      string
+	issuedAt time.Time
+}
+
+// NewWebPushSender crea un Sender con la clave privada VAPID (32 bytes en base64url, el
+// formato de la mayoría de generadores de claves VAPID). subject es un mailto: o una URL de
+// contacto que el servicio de push puede usar ante problemas. timeout limita cada envío.
+func NewWebPushSender(privateKey, subject string, timeout time.Duration) (*WebPushSender, error) {
+	raw, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("push: clave privada VAPID inválida: %w", err)
+	}
+	ecdhKey, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("push: clave privada VAPID inválida: %w", err)
+	}
+	if !strings.HasPrefix(subject, "mailto:") && !strings.HasPrefix(subject, "https://") {
+		return nil, errors.New("push: el subject de VAPID debe ser un mailto: o una URL https://")
+	}
+
+	public := ecdhKey.PublicKey().Bytes() // Formato sin comprimir: 0x04 || X || Y
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(public[1:33]),
+			Y:     new(big.Int).SetBytes(public[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}
+	return &WebPushSender{
+		subject:   subject,
+		publicKey: base64.RawURLEncoding.EncodeToString(public),
+		key:       key,
+		client:    &http.Client{Timeout: timeout},
+		tokens:    make(map[string]vapidToken),
+	}, nil
+}
+
+// PublicKey devuelve la clave pública VAPID en base64url. El cliente la pasa como
+// applicationServerKey al suscribirse.
+func (s *WebPushSender) PublicKey() string {
+	return s.publicKey
+}
+
+// vapidAuthorization devuelve la cabecera Authorization para el origen del endpoint.
+func (s *WebPushSender) vapidAuthorization(endpoint *url.URL) (string, error) {
+	audience := endpoint.Scheme + "://" + endpoint.Host
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cached, ok := s.tokens[audience]
+	if !ok || time.Since(cached.issuedAt) > vapidTokenLifetime/2 {
+		now := time.Now()
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+			"aud": audience,
+			"exp": now.Add(vapidTokenLifetime).Unix(),
+			"sub": s.subject,
+		})
+		signed, err := token.SignedString(s.key)
+		if err != nil {
+			return "", fmt.Errorf("push: error firmando el token de VAPID: %w", err)
+		}
+		cached = vapidToken{jwt: signed, issuedAt: now}
+		s.tokens[audience] = cached
+	}
+	return "vapid t=" + cached.jwt + ", k=" + s.publicKey, nil
+}
+
+// webPushPayload es el JSON que recibe el service worker en el evento push.
+type webPushPayload struct {
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Tag   string            `json:"tag,omitempty"`
+	Data  map[string]string `json:"data,omitempty"`
+	// BadgeCount es el número para navigator.setAppBadge en el service worker.
+	BadgeCount *int `json:"badgeCount,omitempty"`
+}
+
+// Send envía la notificación a la suscripción. Si el servicio de push responde que la
+// suscripción caducó o no existe devuelve ErrInvalidToken.
+func (s *WebPushSender) Send(ctx context.Context, subscription WebPushSubscription, notification Notification) error {
+	endpoint, err := url.Parse(subscription.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return ErrInvalidToken
+	}
+
+	payload, err := json.Marshal(webPushPayload{
+		Title:      notification.Title,
+		Body:       notification.Body,
+		Tag:        notification.ThreadID,
+		Data:       notification.Data,
+		BadgeCount: notification.Badge,
+	})
+	if err != nil {
+		return err
+	}
+	body, err := encryptWebPush(subscription, payload)
+	if err != nil {
+		return err
+	}
+	authorization, err := s.vapidAuthorization(endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", fmt.Sprint(int(webPushTTL.Seconds())))
+	req.Header.Set("Urgency", "high")
+	if topic := webPushTopic(notification.CollapseKey); topic != "" {
+		req.Header.Set("Topic", topic)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("push: error enviando a %s: %w", endpoint.Host, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
+		return nil
+	case http.StatusNotFound, http.StatusGone:
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("push: %s respondió %d", endpoint.Host, resp.StatusCode)
+}
+
+// webPushTopic adapta la clave de colapso a la cabecera Topic, que admite como máximo 32
+// caracteres del alfabeto base64url. Las claves que no cumplen se resumen con SHA-256.
+func webPushTopic(collapseKey string) string {
+	if collapseKey == "" {
+		return ""
+	}
+	valid := len(collapseKey) <= 32
+	for _, c := range collapseKey {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			valid = false
+			break
+		}
+	}
+	if valid {
+		return collapseKey
+	}
+	sum := sha256.Sum256([]byte(collapseKey))
+	return base64.RawURLEncoding.EncodeToString(sum[:24])
+}
+
+// encryptWebPush cifra el contenido para la suscripción con el esquema aes128gcm de
+// RFC 8291: una clave efímera ECDH por mensaje, combinada con el secreto de autenticación
+// del navegador.
+func encryptWebPush(subscription WebPushSubscription, plaintext []byte) ([]byte, error) {
+	if len(plaintext) > webPushMaxPayload {
+		return nil, ErrPayloadTooLarge
+	}
+	uaPublicRaw, err := decodeBase64URL(subscription.P256dh)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	authSecret, err := decodeBase64URL(subscription.Auth)
+	if err != nil || len(authSecret) != 16 {
+		return nil, ErrInvalidToken
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicRaw)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	keyInfo := "WebPush: info\x00" + string(uaPublicRaw) + string(asPublic)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cabecera: salt (16) || tamaño de registro (4) || longitud del key id (1) || key id.
+	header := make([]byte, 0, 21+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	// 0x02 marca el último (y único) registro.
+	record := append(append([]byte{}, plaintext...), 0x02)
+	return gcm.Seal(header, nonce, record, nil), nil
+}
+
+// decodeBase64URL acepta base64url con o sin relleno, que es como lo entregan los navegadores.
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(value), "="))
+}
@@ -0,0 +1,113 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamavChunkSize es el tamaño de cada bloque que se envía a clamd. clamd rechaza el archivo
+// entero si supera su StreamMaxLength.
+const clamavChunkSize = 64 << 10
+
+// ClamAV analiza archivos con el demonio clamd mediante el protocolo INSTREAM.
+type ClamAV struct {
+	address string
+	timeout time.Duration
+}
+
+// NewClamAV crea un escáner para el clamd de address (host:puerto).
+func NewClamAV(address string, timeout time.Duration) *ClamAV {
+	return &ClamAV{address: address, timeout: timeout}
+}
+
+// command abre una conexión, ejecuta fn con ella y devuelve la respuesta de clamd, que
+// termina en un byte nulo.
+func (c *ClamAV) command(ctx context.Context, fn func(conn net.Conn) error) (string, error) {
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return "", fmt.Errorf("scan: no se pudo conectar con clamd en %s: %w", c.address, err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	if err := fn(conn); err != nil {
+		return "", fmt.Errorf("scan: error enviando a clamd: %w", err)
+	}
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !(err == io.EOF && reply != "") {
+		return "", fmt.Errorf("scan: error leyendo la respuesta de clamd: %w", err)
+	}
+	return strings.TrimRight(reply, "\x00\n"), nil
+}
+
+// Scan envía reader a clamd en bloques de clamavChunkSize bytes, cada uno precedido de su
+// longitud, y un bloque de longitud 0 para terminar.
+func (c *ClamAV) Scan(ctx context.Context, reader io.Reader) (Result, error) {
+	reply, err := c.command(ctx, func(conn net.Conn) error {
+		if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+			return err
+		}
+		buf := make([]byte, clamavChunkSize)
+		var size [4]byte
+		for {
+			n, readErr := io.ReadFull(reader, buf)
+			if n > 0 {
+				binary.BigEndian.PutUint32(size[:], uint32(n))
+				if _, err := conn.Write(append(size[:], buf[:n]...)); err != nil {
+					return err
+				}
+			}
+			if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+				break
+			}
+			if readErr != nil {
+				return readErr
+			}
+		}
+		_, err := conn.Write([]byte{0, 0, 0, 0})
+		return err
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return parseClamAVReply(reply)
+}
+
+// parseClamAVReply interpreta "stream: OK", "stream: <firma> FOUND" o "... ERROR".
+func parseClamAVReply(reply string) (Result, error) {
+	reply = strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case reply == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return Result{Infected: true, Signature: strings.TrimSuffix(reply, " FOUND")}, nil
+	default:
+		return Result{}, fmt.Errorf("scan: clamd respondió %q", reply)
+	}
+}
+
+func (c *ClamAV) Ping(ctx context.Context) error {
+	reply, err := c.command(ctx, func(conn net.Conn) error {
+		_, err := conn.Write([]byte("zPING\x00"))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("scan: clamd respondió %q a PING", reply)
+	}
+	return nil
+}
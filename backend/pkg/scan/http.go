@@ -0,0 +1,81 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTP analiza archivos con un servicio externo. El archivo se envía en el cuerpo de un POST
+// (application/octet-stream, con "Authorization: Bearer <apiKey>" si hay clave) y el servicio
+// responde 200 con:
+//
+//	{"infected": true, "signature": "Win.Test.EICAR_HDB-1"}
+//
+// Los análisis de proveedores en la nube con otro formato se integran con un adaptador que
+// exponga este contrato.
+type HTTP struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// httpScanResponse es el cuerpo de la respuesta del servicio.
+type httpScanResponse struct {
+	Infected  bool   `json:"infected"`
+	Signature string `json:"signature"`
+}
+
+// NewHTTP crea un escáner que envía los archivos a url.
+func NewHTTP(url, apiKey string, timeout time.Duration) *HTTP {
+	return &HTTP{url: url, apiKey: apiKey, client: &http.Client{Timeout: timeout}}
+}
+
+func (h *HTTP) request(ctx context.Context, method string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, h.url, body)
+	if err != nil {
+		return nil, fmt.Errorf("scan: petición inválida: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if h.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.apiKey)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("scan: error llamando al servicio de análisis: %w", err)
+	}
+	return resp, nil
+}
+
+func (h *HTTP) Scan(ctx context.Context, reader io.Reader) (Result, error) {
+	resp, err := h.request(ctx, http.MethodPost, reader)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("scan: el servicio de análisis respondió %d", resp.StatusCode)
+	}
+	var verdict httpScanResponse
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return Result{}, fmt.Errorf("scan: respuesta inválida del servicio de análisis: %w", err)
+	}
+	return Result{Infected: verdict.Infected, Signature: verdict.Signature}, nil
+}
+
+// Ping hace un HEAD a la URL del servicio; cualquier respuesta por debajo de 500 cuenta como
+// disponible.
+func (h *HTTP) Ping(ctx context.Context) error {
+	resp, err := h.request(ctx, http.MethodHead, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("scan: el servicio de análisis respondió %d", resp.StatusCode)
+	}
+	return nil
+}
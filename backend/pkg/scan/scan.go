@@ -0,0 +1,68 @@
+// Package scan analiza archivos en busca de virus y malware antes de guardarlos.
+//
+// Drivers:
+//   - clamav: demonio clamd por TCP (comando INSTREAM), sin dependencias externas.
+//   - http: servicio de análisis en la nube detrás de un endpoint HTTP (ver NewHTTP).
+//
+// Sin driver configurado New devuelve nil y las subidas no se analizan.
+package scan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Drivers de Options.Driver.
+const (
+	DriverClamAV = "clamav"
+	DriverHTTP   = "http"
+)
+
+// Result es el veredicto del análisis de un archivo.
+type Result struct {
+	Infected  bool
+	Signature string // Nombre de la amenaza detectada, si el motor lo informa
+}
+
+// Scanner analiza el contenido de un archivo.
+type Scanner interface {
+	// Scan lee reader hasta el final y devuelve el veredicto. Un error significa que el
+	// archivo no se pudo analizar, no que esté infectado.
+	Scan(ctx context.Context, reader io.Reader) (Result, error)
+	// Ping comprueba que el motor de análisis responde.
+	Ping(ctx context.Context) error
+}
+
+// Options es la configuración del escáner.
+type Options struct {
+	Driver        string
+	ClamAVAddress string // host:puerto de clamd
+	HTTPURL       string
+	HTTPAPIKey    string
+	Timeout       time.Duration // Límite de cada análisis
+}
+
+// New crea el escáner del driver configurado, o nil si Driver está vacío.
+func New(opts Options) (Scanner, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 2 * time.Minute
+	}
+	switch opts.Driver {
+	case "":
+		return nil, nil
+	case DriverClamAV:
+		if opts.ClamAVAddress == "" {
+			return nil, fmt.Errorf("scan: el driver clamav requiere la dirección de clamd")
+		}
+		return NewClamAV(opts.ClamAVAddress, opts.Timeout), nil
+	case DriverHTTP:
+		if opts.HTTPURL == "" {
+			return nil, fmt.Errorf("scan: el driver http requiere la URL del servicio")
+		}
+		return NewHTTP(opts.HTTPURL, opts.HTTPAPIKey, opts.Timeout), nil
+	default:
+		return nil, fmt.Errorf("scan: driver desconocido %q", opts.Driver)
+	}
+}
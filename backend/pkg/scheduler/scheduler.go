@@ -0,0 +1,269 @@
+// Package scheduler ejecuta trabajos periódicos en segundo plano (snapshots de métricas,
+// retención, limpiezas...) con una programación tipo cron.
+//
+// Cada job corre en su propia goroutine y nunca se solapa consigo mismo: si una ejecución
+// dura más que el intervalo, la siguiente se programa al terminar. Los pánicos se recuperan
+// y se cuentan como fallos, y Shutdown espera a que terminen las ejecuciones en curso.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/logger"
+)
+
+const componentLog = "SCHEDULER"
+
+var (
+	ErrDuplicateJob = errors.New("scheduler: ya existe un job con ese nombre")
+	ErrStarted      = errors.New("scheduler: no se pueden registrar jobs después de Start")
+)
+
+// Job es el trabajo periódico. Debe respetar la cancelación del contexto, que se
+// cancela al llamar a Shutdown (o al vencer el Timeout del job).
+type Job func(ctx context.Context) error
+
+// Option ajusta el comportamiento de un job al registrarlo.
+type Option func(*job)
+
+// WithJitter retrasa cada ejecución un tiempo aleatorio en [0, jitter), para que varias
+// instancias del servicio no lancen el mismo job a la vez.
+func WithJitter(jitter time.Duration) Option {
+	return func(j *job) { j.jitter = jitter }
+}
+
+// WithTimeout limita la duración de cada ejecución.
+func WithTimeout(timeout time.Duration) Option {
+	return func(j *job) { j.timeout = timeout }
+}
+
+// WithQuiet evita registrar en el log cada ejecución correcta (útil para jobs de alta frecuencia).
+func WithQuiet() Option {
+	return func(j *job) { j.quiet = true }
+}
+
+// JobStats son las métricas de un job registrado.
+type JobStats struct {
+	Name           string    `json:"name"`
+	Spec           string    `json:"spec"`
+	Running        bool      `json:"running"`
+	Runs           int64     `json:"runs"`
+	Failures       int64     `json:"failures"`
+	Panics         int64     `json:"panics"`
+	LastRun        time.Time `json:"lastRun,omitempty"`
+	LastDurationMs float64   `json:"lastDurationMs"`
+	AvgDurationMs  float64   `json:"avgDurationMs"`
+	LastError      string    `json:"lastError,omitempty"`
+	NextRun        time.Time `json:"nextRun,omitempty"`
+}
+
+type job struct {
+	name     string
+	spec     string
+	schedule Schedule
+	fn       Job
+	jitter   time.Duration
+	timeout  time.Duration
+	quiet    bool
+
+	mu            sync.Mutex
+	stats         JobStats
+	totalDuration time.Duration
+}
+
+// Scheduler agrupa los jobs periódicos de un servicio.
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    map[string]*job
+	started bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New crea un Scheduler vacío.
+func New() *Scheduler {
+	return &Scheduler{jobs: make(map[string]*job)}
+}
+
+// Register añade un job con la programación indicada (ver ParseSpec).
+// Debe llamarse antes de Start.
+func (s *Scheduler) Register(name, spec string, fn Job, opts ...Option) error {
+	schedule, err := ParseSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return ErrStarted
+	}
+	if _, exists := s.jobs[name]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, name)
+	}
+
+	j := &job{name: name, spec: spec, schedule: schedule, fn: fn}
+	for _, opt := range opts {
+		opt(j)
+	}
+	j.stats = JobStats{Name: name, Spec: spec}
+	s.jobs[name] = j
+	return nil
+}
+
+// Start lanza todos los jobs registrados. Se detienen al cancelar ctx o con Shutdown.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+	logger.Infof(componentLog, "Scheduler iniciado con %d jobs", len(s.jobs))
+}
+
+// Shutdown detiene la programación y espera a que terminen las ejecuciones en curso
+// o a que venza ctx.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Info(componentLog, "Scheduler detenido")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("scheduler: jobs todavía en ejecución al cerrar: %w", ctx.Err())
+	}
+}
+
+// Stats devuelve las métricas de todos los jobs, ordenadas por nombre.
+func (s *Scheduler) Stats() []JobStats {
+	s.mu.Lock()
+	jobs := make([]*job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+
+	stats := make([]JobStats, 0, len(jobs))
+	for _, j := range jobs {
+		j.mu.Lock()
+		stats = append(stats, j.stats)
+		j.mu.Unlock()
+	}
+	sort.Slice(stats, func(a, b int) bool { return stats[a].Name < stats[b].Name })
+	return stats
+}
+
+// loop espera a la siguiente ejecución programada y ejecuta el job hasta que se cancele ctx.
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	for {
+		now := time.Now()
+		next := j.schedule.Next(now)
+		if next.IsZero() {
+			logger.Warnf(componentLog, "Job %s: la expresión %q no tiene más ejecuciones", j.name, j.spec)
+			return
+		}
+		if j.jitter > 0 {
+			next = next.Add(time.Duration(rand.Int63n(int64(j.jitter))))
+		}
+
+		j.mu.Lock()
+		j.stats.NextRun = next
+		j.mu.Unlock()
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.run(ctx, j)
+	}
+}
+
+// run ejecuta una vez el job, recupera pánicos y actualiza sus métricas.
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	runCtx := ctx
+	if j.timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, j.timeout)
+		defer cancel()
+	}
+
+	j.mu.Lock()
+	j.stats.Running = true
+	j.mu.Unlock()
+
+	start := time.Now()
+	panicked, err := safeRun(runCtx, j.fn)
+	duration := time.Since(start)
+
+	j.mu.Lock()
+	j.stats.Running = false
+	j.stats.Runs++
+	j.stats.LastRun = start
+	j.stats.LastDurationMs = float64(duration.Microseconds()) / 1000
+	j.totalDuration += duration
+	j.stats.AvgDurationMs = float64((j.totalDuration / time.Duration(j.stats.Runs)).Microseconds()) / 1000
+	if panicked {
+		j.stats.Panics++
+	}
+	if err != nil {
+		j.stats.Failures++
+		j.stats.LastError = err.Error()
+	} else {
+		j.stats.LastError = ""
+	}
+	j.mu.Unlock()
+
+	switch {
+	case panicked:
+		logger.Errorf(componentLog, "Job %s abortado por %v", j.name, err)
+	case err != nil:
+		logger.Errorf(componentLog, "Job %s falló tras %v: %v", j.name, duration, err)
+	case !j.quiet:
+		logger.Infof(componentLog, "Job %s completado en %v", j.name, duration)
+	}
+}
+
+// safeRun ejecuta fn convirtiendo un pánico en error.
+func safeRun(ctx context.Context, fn Job) (panicked bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf(componentLog, "Pánico recuperado en job: %v\n%s", r, debug.Stack())
+			panicked = true
+			err = fmt.Errorf("pánico: %v", r)
+		}
+	}()
+	return false, fn(ctx)
+}
@@ -0,0 +1,171 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule calcula la siguiente ejecución de un job a partir de un instante dado.
+type Schedule interface {
+	Next(from time.Time) time.Time
+}
+
+// everySchedule ejecuta el job a intervalos fijos.
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (e everySchedule) Next(from time.Time) time.Time {
+	return from.Add(e.interval)
+}
+
+// cronSchedule es una expresión cron de 5 campos (minuto hora día-mes mes día-semana)
+// evaluada en la zona horaria local. Cada campo es un bitmask de los valores permitidos.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar/dowStar siguen la semántica clásica de cron: si ambos campos están
+	// restringidos basta con que coincida uno de los dos.
+	domStar, dowStar bool
+}
+
+// maxCronSearch limita la búsqueda de la siguiente coincidencia (p.ej. "0 0 30 2 *" nunca ocurre).
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+func (c cronSchedule) Next(from time.Time) time.Time {
+	t := from.Truncate(time.Minute).Add(time.Minute)
+	limit := from.Add(maxCronSearch)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// descriptors son los atajos aceptados además de "@every <duración>".
+var descriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// ParseSpec interpreta una especificación de programación:
+//
+//	@every 1s | @every 5m      intervalo fijo (duración de Go)
+//	@hourly, @daily, @weekly   atajos de cron
+//	"30 3 * * *"               cron de 5 campos: *, números, listas (1,5), rangos (1-5) y pasos (*/15)
+func ParseSpec(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("scheduler: intervalo inválido en %q: %w", spec, err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("scheduler: el intervalo de %q debe ser positivo", spec)
+		}
+		return everySchedule{interval: interval}, nil
+	}
+	if expr, ok := descriptors[spec]; ok {
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("scheduler: %q no es una expresión válida (se esperaban 5 campos)", spec)
+	}
+
+	var c cronSchedule
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	// 7 es un alias de domingo.
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domStar = fields[2] == "*"
+	c.dowStar = fields[4] == "*"
+	return c, nil
+}
+
+// parseCronField convierte un campo cron en un bitmask de los valores permitidos.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			s, err := strconv.Atoi(stepPart)
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("scheduler: paso inválido en %q", field)
+			}
+			step = s
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("scheduler: valor inválido en %q", field)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("scheduler: valor inválido en %q", field)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("scheduler: %q fuera de rango [%d-%d]", field, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
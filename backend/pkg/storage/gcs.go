@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/cloudclient"
+)
+
+// GCS guarda los objetos en un bucket de Google Cloud Storage. Usa el cliente global de
+// cloudclient, que comparte con los servicios que aún lo llaman directamente.
+type GCS struct {
+	bucket string
+}
+
+// NewGCS abre el cliente de GCS para bucket con el archivo de credenciales indicado.
+func NewGCS(bucket, credentialsFile string) (*GCS, error) {
+	if err := cloudclient.Open(bucket, credentialsFile); err != nil {
+		return nil, err
+	}
+	return &GCS{bucket: bucket}, nil
+}
+
+func (g *GCS) Put(ctx context.Context, path string, reader io.Reader, contentType string, public bool) error {
+	if public {
+		return cloudclient.UploadPublic(ctx, reader, path, contentType)
+	}
+	return cloudclient.UploadPrivate(ctx, reader, path, contentType)
+}
+
+func (g *GCS) Get(ctx context.Context, path string) ([]byte, error) {
+	data, err := cloudclient.DownloadFile(ctx, path)
+	return data, gcsError(err)
+}
+
+func (g *GCS) OpenRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	rc, err := cloudclient.OpenRange(ctx, path, offset, length)
+	return rc, gcsError(err)
+}
+
+func (g *GCS) Stat(ctx context.Context, path string) (*ObjectInfo, error) {
+	info, err := cloudclient.Stat(ctx, path)
+	if err != nil {
+		return nil, gcsError(err)
+	}
+	return &ObjectInfo{Size: info.Size, ContentType: info.ContentType, ETag: info.ETag, Updated: info.Updated}, nil
+}
+
+func (g *GCS) Delete(ctx context.Context, path string) error {
+	return cloudclient.DeleteFile(ctx, path)
+}
+
+func (g *GCS) URL(path string) string {
+	return fmt.Sprintf("https://storage.googleapis.com/%s/%s", g.bucket, escapePath(path))
+}
+
+func (g *GCS) SignedURL(path string, ttl time.Duration, downloadName string) (string, error) {
+	return cloudclient.SignedURL(path, ttl, downloadName)
+}
+
+func (g *GCS) Ping(ctx context.Context) error {
+	return cloudclient.Ping(ctx)
+}
+
+// gcsError traduce el error de objeto inexistente de GCS a ErrNotExist.
+func gcsError(err error) error {
+	if errors.Is(err, cloudclient.ErrObjectNotExist) {
+		return ErrNotExist
+	}
+	return err
+}
+
+// escapePath codifica cada segmento de la ruta de un objeto para usarla en una URL.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
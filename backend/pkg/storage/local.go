@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// localMetaDir es el subdirectorio con los metadatos (tipo y visibilidad) de cada objeto.
+const localMetaDir = ".meta"
+
+// Local guarda los objetos en un directorio del disco. Pensado para desarrollo: los archivos
+// públicos y los enlaces firmados se sirven con Handler, montado en baseURL.
+type Local struct {
+	dir        string
+	baseURL    string
+	signingKey []byte
+}
+
+// localMeta son los metadatos que los otros drivers guardan en el propio objeto.
+type localMeta struct {
+	ContentType string `json:"contentType"`
+	Public      bool   `json:"public"`
+}
+
+// NewLocal crea el almacenamiento en dir. signingKey firma los enlaces de SignedURL.
+func NewLocal(dir, baseURL, signingKey string) (*Local, error) {
+	if dir == "" || signingKey == "" {
+		return nil, fmt.Errorf("storage: el driver local requiere un directorio y una clave de firma")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("storage: no se pudo crear %s: %w", dir, err)
+	}
+	return &Local{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/"), signingKey: []byte(signingKey)}, nil
+}
+
+// file devuelve la ruta en disco de un objeto. Rechaza rutas que salgan del directorio o que
+// apunten a los metadatos.
+func (l *Local) file(objectPath string) (string, error) {
+	clean := path.Clean("/" + objectPath)[1:]
+	if clean == "" || clean != objectPath || strings.HasPrefix(clean, localMetaDir+"/") || clean == localMetaDir {
+		return "", ErrNotExist
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(clean)), nil
+}
+
+func (l *Local) metaFile(objectPath string) string {
+	return filepath.Join(l.dir, localMetaDir, filepath.FromSlash(objectPath)+".json")
+}
+
+func (l *Local) readMeta(objectPath string) localMeta {
+	meta := localMeta{ContentType: mime.TypeByExtension(path.Ext(objectPath))}
+	if data, err := os.ReadFile(l.metaFile(objectPath)); err == nil {
+		json.Unmarshal(data, &meta)
+	}
+	if meta.ContentType == "" {
+		meta.ContentType = "application/octet-stream"
+	}
+	return meta
+}
+
+// Put escribe el objeto en un archivo temporal y lo renombra, para que nadie lea un objeto a
+// medio escribir.
+func (l *Local) Put(ctx context.Context, objectPath string, reader io.Reader, contentType string, public bool) error {
+	target, err := l.file(objectPath)
+	if err != nil {
+		return fmt.Errorf("storage: ruta inválida %q", objectPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, reader); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error guardando %s: %w", objectPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	meta, _ := json.Marshal(localMeta{ContentType: contentType, Public: public})
+	if err := os.MkdirAll(filepath.Dir(l.metaFile(objectPath)), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(l.metaFile(objectPath), meta, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (l *Local) Get(ctx context.Context, objectPath string) ([]byte, error) {
+	target, err := l.file(objectPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(target)
+	return data, localError(err)
+}
+
+func (l *Local) OpenRange(ctx context.Context, objectPath string, offset, length int64) (io.ReadCloser, error) {
+	target, err := l.file(objectPath)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(target)
+	if err != nil {
+		return nil, localError(err)
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if length < 0 {
+		return f, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(f, length), f}, nil
+}
+
+func (l *Local) Stat(ctx context.Context, objectPath string) (*ObjectInfo, error) {
+	target, err := l.file(objectPath)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(target)
+	if err != nil {
+		return nil, localError(err)
+	}
+	return &ObjectInfo{
+		Size:        fi.Size(),
+		ContentType: l.readMeta(objectPath).ContentType,
+		ETag:        strconv.FormatInt(fi.ModTime().UnixNano(), 36) + "-" + strconv.FormatInt(fi.Size(), 36),
+		Updated:     fi.ModTime(),
+	}, nil
+}
+
+func (l *Local) Delete(ctx context.Context, objectPath string) error {
+	target, err := l.file(objectPath)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error eliminando %s: %w", objectPath, err)
+	}
+	os.Remove(l.metaFile(objectPath))
+	return nil
+}
+
+func (l *Local) URL(objectPath string) string {
+	return l.baseURL + "/" + escapePath(objectPath)
+}
+
+// SignedURL genera un enlace a Handler con la caducidad y una firma HMAC en la query.
+func (l *Local) SignedURL(objectPath string, ttl time.Duration, downloadName string) (string, error) {
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {l.sign(objectPath, expires, downloadName)}}
+	if downloadName != "" {
+		query.Set("download", downloadName)
+	}
+	return l.URL(objectPath) + "?" + query.Encode(), nil
+}
+
+func (l *Local) sign(objectPath, expires, downloadName string) string {
+	mac := hmac.New(sha256.New, l.signingKey)
+	mac.Write([]byte(objectPath + "\n" + expires + "\n" + downloadName))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (l *Local) Ping(ctx context.Context) error {
+	fi, err := os.Stat(l.dir)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s no es un directorio", l.dir)
+	}
+	return nil
+}
+
+// Handler sirve los objetos del driver local bajo su baseURL (hay que montarlo con
+// http.StripPrefix). Los objetos públicos se sirven a cualquiera; los privados solo con un
+// enlace de SignedURL vigente. Soporta Range.
+func (l *Local) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "método no permitido", http.StatusMethodNotAllowed)
+			return
+		}
+		objectPath := strings.TrimPrefix(r.URL.Path, "/")
+		target, err := l.file(objectPath)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		meta := l.readMeta(objectPath)
+		query := r.URL.Query()
+		downloadName := query.Get("download")
+		if !meta.Public {
+			expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+			valid := err == nil && time.Now().Unix() <= expires &&
+				hmac.Equal([]byte(query.Get("signature")), []byte(l.sign(objectPath, query.Get("expires"), downloadName)))
+			if !valid {
+				// No se distingue un objeto privado de uno inexistente.
+				http.NotFound(w, r)
+				return
+			}
+		}
+
+		f, err := os.Open(target)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil || fi.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", meta.ContentType)
+		if downloadName != "" {
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", downloadName))
+		}
+		http.ServeContent(w, r, "", fi.ModTime(), f)
+	})
+}
+
+// localError traduce el error de archivo inexistente a ErrNotExist.
+func localError(err error) error {
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotExist
+	}
+	return err
+}
@@ -0,0 +1,346 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3TimeFormat      = "20060102T150405Z"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// s3MaxPresignTTL es la validez máxima de un enlace firmado con V4.
+	s3MaxPresignTTL = 7 * 24 * time.Hour
+)
+
+// S3 guarda los objetos en un bucket compatible con S3. Firma las peticiones con AWS Signature
+// V4 directamente sobre net/http, sin el SDK de AWS.
+type S3 struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+	publicURL string
+	client    *http.Client
+}
+
+// NewS3 crea el almacenamiento S3 con las opciones S3* de opts.
+func NewS3(opts Options) (*S3, error) {
+	if opts.S3Endpoint == "" || opts.S3Bucket == "" || opts.S3AccessKey == "" || opts.S3SecretKey == "" {
+		return nil, fmt.Errorf("storage: S3 requiere endpoint, bucket y credenciales")
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(opts.S3Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("storage: endpoint de S3 inválido %q", opts.S3Endpoint)
+	}
+	region := opts.S3Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3{
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    opts.S3Bucket,
+		accessKey: opts.S3AccessKey,
+		secretKey: opts.S3SecretKey,
+		pathStyle: opts.S3PathStyle,
+		publicURL: strings.TrimSuffix(opts.S3PublicURL, "/"),
+		// Sin timeout global: las subidas de video pueden tardar minutos. El plazo lo pone el
+		// contexto de cada petición.
+		client: &http.Client{},
+	}, nil
+}
+
+// objectURL devuelve la URL de un objeto (o del bucket si path está vacío).
+func (s *S3) objectURL(path string) *url.URL {
+	u := *s.endpoint
+	if s.pathStyle {
+		u.Path = "/" + s.bucket + "/" + path
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = "/" + path
+	}
+	// La ruta se envía codificada igual que en la firma.
+	u.RawPath = awsEscape(u.Path, false)
+	return &u
+}
+
+// do firma y envía una petición. body puede ser nil.
+func (s *S3) do(ctx context.Context, method, path string, body io.Reader, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(path).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil && req.ContentLength == 0 && req.Body != http.NoBody {
+		// S3 no acepta subidas sin Content-Length: si el cuerpo no es un archivo se lee entero
+		// para conocer su tamaño.
+		if seeker, ok := body.(io.Seeker); ok {
+			current, _ := seeker.Seek(0, io.SeekCurrent)
+			end, err := seeker.Seek(0, io.SeekEnd)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := seeker.Seek(current, io.SeekStart); err != nil {
+				return nil, err
+			}
+			req.ContentLength = end - current
+			if req.ContentLength == 0 {
+				req.Body = http.NoBody
+			}
+		} else {
+			data, err := io.ReadAll(body)
+			if err != nil {
+				return nil, err
+			}
+			req.Body = io.NopCloser(bytes.NewReader(data))
+			req.ContentLength = int64(len(data))
+		}
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	s.sign(req, time.Now().UTC())
+	return s.client.Do(req)
+}
+
+func (s *S3) Put(ctx context.Context, path string, reader io.Reader, contentType string, public bool) error {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	if public {
+		header.Set("X-Amz-Acl", "public-read")
+	}
+	resp, err := s.do(ctx, http.MethodPut, path, reader, header)
+	if err != nil {
+		return fmt.Errorf("error subiendo %s a S3: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error("subiendo", path, resp)
+	}
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, path string) ([]byte, error) {
+	rc, err := s.OpenRange(ctx, path, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func (s *S3) OpenRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	header := http.Header{}
+	switch {
+	case length > 0:
+		header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	case offset > 0:
+		header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := s.do(ctx, http.MethodGet, path, nil, header)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo %s en S3: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		defer resp.Body.Close()
+		return nil, s3Error("abriendo", path, resp)
+	}
+	return resp.Body, nil
+}
+
+func (s *S3) Stat(ctx context.Context, path string) (*ObjectInfo, error) {
+	resp, err := s.do(ctx, http.MethodHead, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo los atributos de %s en S3: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error("consultando", path, resp)
+	}
+	info := &ObjectInfo{
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        strings.Trim(resp.Header.Get("ETag"), `"`),
+	}
+	if updated, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.Updated = updated
+	}
+	return info, nil
+}
+
+func (s *S3) Delete(ctx context.Context, path string) error {
+	resp, err := s.do(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil {
+		return fmt.Errorf("error eliminando %s de S3: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3Error("eliminando", path, resp)
+	}
+	return nil
+}
+
+func (s *S3) URL(path string) string {
+	if s.publicURL != "" {
+		return s.publicURL + "/" + escapePath(path)
+	}
+	return s.objectURL(path).String()
+}
+
+// SignedURL genera un enlace prefirmado (firma V4 en la query). S3 limita su validez a 7 días.
+func (s *S3) SignedURL(path string, ttl time.Duration, downloadName string) (string, error) {
+	if ttl > s3MaxPresignTTL {
+		ttl = s3MaxPresignTTL
+	}
+	now := time.Now().UTC()
+	u := s.objectURL(path)
+	query := url.Values{
+		"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+		"X-Amz-Credential":    {s.accessKey + "/" + s.scope(now)},
+		"X-Amz-Date":          {now.Format(s3TimeFormat)},
+		"X-Amz-Expires":       {strconv.Itoa(int(ttl.Seconds()))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	if downloadName != "" {
+		query.Set("response-content-disposition", fmt.Sprintf("attachment; filename=%q", downloadName))
+	}
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		awsEscape(u.Path, false),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(now, canonical))
+	u.RawQuery = canonicalQuery(query)
+	return u.String(), nil
+}
+
+func (s *S3) Ping(ctx context.Context) error {
+	resp, err := s.do(ctx, http.MethodHead, "", nil, nil)
+	if err != nil {
+		return fmt.Errorf("bucket S3 %s no accesible: %w", s.bucket, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bucket S3 %s no accesible: HTTP %d", s.bucket, resp.StatusCode)
+	}
+	return nil
+}
+
+// sign añade a req la cabecera Authorization de AWS Signature V4. Se firman host y las
+// cabeceras x-amz-*; el cuerpo no se firma (UNSIGNED-PAYLOAD) para no tener que leerlo dos
+// veces.
+func (s *S3) sign(req *http.Request, now time.Time) {
+	req.Header.Set("X-Amz-Date", now.Format(s3TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		if lower := strings.ToLower(key); strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		awsEscape(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		s3UnsignedPayload,
+	}, "\n")
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, s.scope(now), signedHeaders, s.signature(now, canonical)))
+}
+
+// scope es el ámbito de la credencial: fecha/región/s3/aws4_request.
+func (s *S3) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+}
+
+// signature firma la petición canónica con la clave derivada del día.
+func (s *S3) signature(now time.Time, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + now.Format(s3TimeFormat) + "\n" + s.scope(now) + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery ordena y codifica la query como exige la firma V4.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, awsEscape(key, true)+"="+awsEscape(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape codifica s según la firma V4: solo se conservan letras, dígitos y "-_.~" (y "/"
+// si encodeSlash es false).
+func awsEscape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3Error construye el error de una respuesta inesperada. 404 se traduce a ErrNotExist.
+func s3Error(action, path string, resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotExist
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("error %s %s en S3: HTTP %d: %s", action, path, resp.StatusCode, strings.TrimSpace(string(body)))
+}
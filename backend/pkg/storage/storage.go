@@ -0,0 +1,132 @@
+// Package storage abstrae el almacenamiento de archivos (imágenes, videos, notas de voz) detrás
+// de la interfaz Storage, con tres implementaciones que se eligen por configuración:
+//
+//   - gcs: Google Cloud Storage, a través de pkg/cloudclient.
+//   - s3: cualquier servicio compatible con S3 (AWS, MinIO, Cloudflare R2...), con firma V4.
+//   - local: un directorio del disco, para desarrollo sin credenciales de la nube. Los archivos
+//     se sirven con (*Local).Handler.
+//
+// Las rutas de los objetos usan siempre "/" como separador y no empiezan por "/".
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Drivers de almacenamiento (STORAGE_DRIVER).
+const (
+	DriverGCS   = "gcs"
+	DriverS3    = "s3"
+	DriverLocal = "local"
+)
+
+var (
+	// ErrNotExist indica que el objeto pedido no existe.
+	ErrNotExist = errors.New("storage: el objeto no existe")
+	// ErrUnavailable indica que el almacenamiento no está configurado.
+	ErrUnavailable = errors.New("storage: almacenamiento no configurado")
+)
+
+// ObjectInfo describe un objeto almacenado.
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+	ETag        string
+	Updated     time.Time
+}
+
+// Storage es un almacén de objetos.
+type Storage interface {
+	// Put guarda el contenido de reader en path. Si public es true el objeto se puede descargar
+	// sin autenticación en URL(path); si no, solo con un enlace de SignedURL.
+	Put(ctx context.Context, path string, reader io.Reader, contentType string, public bool) error
+	// Get devuelve el contenido completo de un objeto.
+	Get(ctx context.Context, path string) ([]byte, error)
+	// OpenRange abre un lector de length bytes del objeto a partir de offset (length -1 lee
+	// hasta el final). El llamador debe cerrar el lector.
+	OpenRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error)
+	// Stat devuelve el tamaño, el tipo, el ETag y la fecha de modificación de un objeto.
+	Stat(ctx context.Context, path string) (*ObjectInfo, error)
+	// Delete elimina un objeto. Un objeto que ya no existe no es un error.
+	Delete(ctx context.Context, path string) error
+	// URL devuelve la URL pública de un objeto guardado con public.
+	URL(path string) string
+	// SignedURL genera un enlace de descarga temporal, válido durante ttl. Si downloadName no
+	// está vacío el navegador lo descarga con ese nombre.
+	SignedURL(path string, ttl time.Duration, downloadName string) (string, error)
+	// Ping comprueba que el almacenamiento es accesible. Se usa en /readyz.
+	Ping(ctx context.Context) error
+}
+
+// Options configura el almacenamiento.
+type Options struct {
+	Driver string
+
+	// gcs
+	GCSBucket          string
+	GCSCredentialsFile string
+
+	// s3
+	S3Endpoint  string // p. ej. https://s3.us-east-1.amazonaws.com o http://localhost:9000
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PathStyle bool   // endpoint/bucket/objeto en lugar de bucket.endpoint/objeto (MinIO)
+	S3PublicURL string // Base de las URL públicas (CDN); vacío = la del endpoint
+
+	// local
+	LocalDir        string
+	LocalBaseURL    string // Prefijo con el que se sirven los archivos, p. ej. /files
+	LocalSigningKey string // Clave de los enlaces firmados
+}
+
+// New crea el almacenamiento indicado en opts.Driver. Si el driver es gcs y falta el bucket o
+// las credenciales, devuelve un almacenamiento no disponible (todas las operaciones fallan con
+// ErrUnavailable), como antes de existir este paquete.
+func New(opts Options) (Storage, error) {
+	var (
+		store Storage
+		err   error
+	)
+	switch opts.Driver {
+	case DriverGCS, "":
+		if opts.GCSBucket == "" || opts.GCSCredentialsFile == "" {
+			return Unavailable(), nil
+		}
+		store, err = NewGCS(opts.GCSBucket, opts.GCSCredentialsFile)
+	case DriverS3:
+		store, err = NewS3(opts)
+	case DriverLocal:
+		store, err = NewLocal(opts.LocalDir, opts.LocalBaseURL, opts.LocalSigningKey)
+	default:
+		err = fmt.Errorf("storage: driver desconocido %q (gcs, s3 o local)", opts.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// unavailable es el almacenamiento cuando no hay ninguno configurado.
+type unavailable struct{}
+
+// Unavailable devuelve un almacenamiento cuyas operaciones fallan con ErrUnavailable.
+func Unavailable() Storage { return unavailable{} }
+
+func (unavailable) Put(context.Context, string, io.Reader, string, bool) error { return ErrUnavailable }
+func (unavailable) Get(context.Context, string) ([]byte, error)                { return nil, ErrUnavailable }
+func (unavailable) OpenRange(context.Context, string, int64, int64) (io.ReadCloser, error) {
+	return nil, ErrUnavailable
+}
+func (unavailable) Stat(context.Context, string) (*ObjectInfo, error) { return nil, ErrUnavailable }
+func (unavailable) Delete(context.Context, string) error              { return ErrUnavailable }
+func (unavailable) URL(string) string                                 { return "" }
+func (unavailable) SignedURL(string, time.Duration, string) (string, error) {
+	return "", ErrUnavailable
+}
+func (unavailable) Ping(context.Context) error { return ErrUnavailable }
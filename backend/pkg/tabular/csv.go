@@ -0,0 +1,56 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// csvFlushRows es cada cuántas filas se vacía el búfer del CSV hacia el destino.
+const csvFlushRows = 500
+
+type csvWriter struct {
+	w    *csv.Writer
+	rows int
+}
+
+// newCSVWriter crea un Writer CSV. Empieza con el BOM de UTF-8 para que Excel reconozca la
+// codificación al abrir el archivo.
+func newCSVWriter(w io.Writer) *csvWriter {
+	io.WriteString(w, "\ufeff")
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) WriteRow(values []any) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		text := cellText(value)
+		if _, ok := value.(string); ok {
+			text = escapeFormula(text)
+		}
+		record[i] = text
+	}
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+	c.rows++
+	if c.rows%csvFlushRows == 0 {
+		c.w.Flush()
+		return c.w.Error()
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula antepone un apóstrofo a los textos que una hoja de cálculo interpretaría como
+// fórmula, para que un dato introducido por un usuario no se ejecute al abrir el CSV.
+func escapeFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
@@ -0,0 +1,82 @@
+// Package tabular escribe tablas en CSV o XLSX fila a fila, sin retener las filas en memoria,
+// para exportaciones que se envían al cliente mientras se leen de la base de datos.
+//
+// El XLSX se genera sin dependencias externas: un libro con una sola hoja cuyas celdas de
+// texto van en línea (inlineStr), así que no hace falta la tabla de cadenas compartidas.
+package tabular
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Formatos admitidos.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Writer escribe una tabla. La cabecera va en la primera llamada a WriteRow y Close termina el
+// documento; sin Close el XLSX queda incompleto.
+type Writer interface {
+	// WriteRow escribe una fila. Los valores pueden ser string, enteros, float64, bool,
+	// time.Time o nil (celda vacía); el resto se escribe con fmt.Sprint.
+	WriteRow(values []any) error
+	Close() error
+}
+
+// New crea un Writer del formato indicado sobre w.
+func New(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	}
+	return nil, fmt.Errorf("formato de exportación no admitido: %q", format)
+}
+
+// ContentType devuelve el tipo MIME del formato.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// IsValidFormat indica si el formato está admitido.
+func IsValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatXLSX
+}
+
+// cellText devuelve la representación textual de un valor. Las fechas se escriben en UTC con
+// formato ISO 8601.
+func cellText(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// isNumber indica si el valor se escribe como número en el XLSX.
+func isNumber(value any) bool {
+	switch value.(type) {
+	case int, int32, int64, float64:
+		return true
+	}
+	return false
+}
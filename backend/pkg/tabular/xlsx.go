@@ -0,0 +1,119 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// xlsxMaxRows es el límite de filas de una hoja de Excel.
+const xlsxMaxRows = 1048576
+
+// xlsxFlushRows es cada cuántas filas se envía al destino lo comprimido hasta el momento.
+const xlsxFlushRows = 500
+
+// ErrTooManyRows se devuelve al superar el límite de filas de una hoja XLSX.
+var ErrTooManyRows = errors.New("la exportación supera el límite de filas de una hoja XLSX")
+
+// Partes fijas del libro: una sola hoja llamada "Datos".
+var xlsxStaticParts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Datos" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`},
+}
+
+type xlsxWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+// newXLSXWriter escribe las partes fijas del libro y abre la hoja, que se completa fila a fila.
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(f)
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return &xlsxWriter{zip: zw, sheet: sheet}, nil
+}
+
+func (x *xlsxWriter) WriteRow(values []any) error {
+	if x.rows >= xlsxMaxRows {
+		return ErrTooManyRows
+	}
+	x.rows++
+	row := strconv.Itoa(x.rows)
+	x.sheet.WriteString(`<row r="` + row + `">`)
+	for i, value := range values {
+		ref := columnName(i) + row
+		switch {
+		case value == nil:
+			continue
+		case isNumber(value):
+			x.sheet.WriteString(`<c r="` + ref + `"><v>` + cellText(value) + `</v></c>`)
+		default:
+			x.sheet.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(x.sheet, []byte(cellText(value)))
+			x.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	if _, err := x.sheet.WriteString(`</row>`); err != nil {
+		return err
+	}
+	if x.rows%xlsxFlushRows == 0 {
+		if err := x.sheet.Flush(); err != nil {
+			return err
+		}
+		return x.zip.Flush()
+	}
+	return nil
+}
+
+func (x *xlsxWriter) Close() error {
+	x.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// columnName devuelve la letra de la columna i (0 = A, 26 = AA).
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
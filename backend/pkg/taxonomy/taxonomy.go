@@ -0,0 +1,284 @@
+// Package taxonomy asocia textos libres (por ejemplo las habilidades que escriben los
+// usuarios) con las entradas de un catálogo canónico y sugiere entradas mientras se escribe.
+//
+// Un texto se asocia de una de estas formas, de más a menos fiable:
+//
+//	exact     el texto normalizado coincide con el nombre normalizado de una entrada
+//	alias     el texto normalizado coincide con un alias ("js" → JavaScript)
+//	phonetic  las claves Double Metaphone coinciden y la distancia de edición es pequeña
+//	fuzzy     la distancia de edición cabe en el margen de erratas según la longitud
+//
+// Las coincidencias exactas y por alias se resuelven primero; si no hay, gana la candidata
+// fonética o aproximada con menor distancia de edición.
+//
+// Los textos muy cortos ("C", "Go", "R") solo se asocian por coincidencia exacta o alias: con
+// dos o tres letras cualquier errata convierte una habilidad en otra distinta. Un Index es
+// inmutable y seguro para uso concurrente.
+package taxonomy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/davidM20/micro-service-backend-go.git/pkg/phonetic"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Tipos de coincidencia, de más a menos fiable.
+const (
+	MatchExact    = "exact"
+	MatchAlias    = "alias"
+	MatchPhonetic = "phonetic"
+	MatchFuzzy    = "fuzzy"
+)
+
+// minApproximateLength es la longitud mínima, en caracteres normalizados, para intentar una
+// coincidencia fonética o aproximada.
+const minApproximateLength = 4
+
+// Entry es una entrada del catálogo canónico.
+type Entry struct {
+	ID         int64
+	Name       string
+	Aliases    []string
+	Popularity int // Número de usos; desempata las sugerencias y las coincidencias aproximadas
+}
+
+// Match es el resultado de asociar un texto con el catálogo.
+type Match struct {
+	Entry    Entry
+	Kind     string
+	Distance int // Distancia de edición con el nombre o alias más parecido (0 en exact y alias)
+}
+
+// term es un nombre o alias normalizado de una entrada.
+type term struct {
+	key                string
+	primary, secondary string
+	entry              int
+	alias              bool
+}
+
+// Index permite buscar textos en el catálogo.
+type Index struct {
+	entries []Entry
+	terms   []term
+	byKey   map[string]int // clave normalizada → posición en terms
+}
+
+// NewIndex construye el índice. Si dos entradas comparten nombre o alias normalizado se queda
+// con la primera: el catálogo impide duplicados, así que solo ocurre con datos inconsistentes.
+func NewIndex(entries []Entry) *Index {
+	ix := &Index{entries: entries, byKey: make(map[string]int)}
+	for i, e := range entries {
+		ix.addTerm(e.Name, i, false)
+		for _, alias := range e.Aliases {
+			ix.addTerm(alias, i, true)
+		}
+	}
+	return ix
+}
+
+func (ix *Index) addTerm(text string, entry int, alias bool) {
+	key := Normalize(text)
+	if key == "" {
+		return
+	}
+	if _, exists := ix.byKey[key]; exists {
+		return
+	}
+	t := term{key: key, entry: entry, alias: alias}
+	if len([]rune(key)) >= minApproximateLength {
+		t.primary, t.secondary, _ = phonetic.GenerateKeys(key)
+	}
+	ix.byKey[key] = len(ix.terms)
+	ix.terms = append(ix.terms, t)
+}
+
+// Match busca la entrada que corresponde al texto. ok es false si ninguna se parece lo
+// suficiente.
+func (ix *Index) Match(input string) (Match, bool) {
+	key := Normalize(input)
+	if key == "" {
+		return Match{}, false
+	}
+	if pos, ok := ix.byKey[key]; ok {
+		t := ix.terms[pos]
+		kind := MatchExact
+		if t.alias {
+			kind = MatchAlias
+		}
+		return Match{Entry: ix.entries[t.entry], Kind: kind}, true
+	}
+
+	length := len([]rune(key))
+	if length < minApproximateLength {
+		return Match{}, false
+	}
+	primary, secondary, _ := phonetic.GenerateKeys(key)
+
+	var best Match
+	found := false
+	for _, t := range ix.terms {
+		if len([]rune(t.key)) < minApproximateLength {
+			continue
+		}
+		distance := levenshtein(key, t.key)
+		kind := ""
+		switch {
+		case distance <= fuzzyTolerance(length):
+			kind = MatchFuzzy
+		case phoneticEqual(primary, secondary, t) && distance <= length/3:
+			kind = MatchPhonetic
+		default:
+			continue
+		}
+		// A igual distancia gana la coincidencia fonética y, después, la entrada más usada.
+		candidate := Match{Entry: ix.entries[t.entry], Kind: kind, Distance: distance}
+		if !found || better(candidate, best) {
+			best, found = candidate, true
+		}
+	}
+	return best, found
+}
+
+// better indica si a es mejor candidata que b.
+func better(a, b Match) bool {
+	if a.Distance != b.Distance {
+		return a.Distance < b.Distance
+	}
+	if a.Kind != b.Kind {
+		return a.Kind == MatchPhonetic
+	}
+	return a.Entry.Popularity > b.Entry.Popularity
+}
+
+// Suggest devuelve hasta limit entradas para autocompletar query: primero las que empiezan
+// por el texto (por nombre y luego por alias), después las que lo contienen y por último la
+// coincidencia aproximada, si la hay. Con una sola letra solo se buscan prefijos. Dentro de
+// cada grupo se ordenan por popularidad. Con query vacío devuelve las más usadas.
+func (ix *Index) Suggest(query string, limit int) []Entry {
+	if limit <= 0 {
+		return []Entry{}
+	}
+	key := Normalize(query)
+
+	const (
+		rankNamePrefix = iota
+		rankAliasPrefix
+		rankContains
+		rankApproximate
+	)
+	ranks := make(map[int]int) // posición de la entrada → mejor rango
+	rank := func(entry, r int) {
+		if current, ok := ranks[entry]; !ok || r < current {
+			ranks[entry] = r
+		}
+	}
+	for _, t := range ix.terms {
+		switch {
+		case key == "" || strings.HasPrefix(t.key, key):
+			if t.alias {
+				rank(t.entry, rankAliasPrefix)
+			} else {
+				rank(t.entry, rankNamePrefix)
+			}
+		case len(key) > 1 && strings.Contains(t.key, key):
+			rank(t.entry, rankContains)
+		}
+	}
+	if match, ok := ix.Match(query); ok {
+		for i := range ix.entries {
+			if ix.entries[i].ID == match.Entry.ID {
+				rank(i, rankApproximate)
+			}
+		}
+	}
+
+	positions := make([]int, 0, len(ranks))
+	for pos := range ranks {
+		positions = append(positions, pos)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		a, b := ix.entries[positions[i]], ix.entries[positions[j]]
+		if ranks[positions[i]] != ranks[positions[j]] {
+			return ranks[positions[i]] < ranks[positions[j]]
+		}
+		if a.Popularity != b.Popularity {
+			return a.Popularity > b.Popularity
+		}
+		return a.Name < b.Name
+	})
+	if len(positions) > limit {
+		positions = positions[:limit]
+	}
+	suggestions := make([]Entry, len(positions))
+	for i, pos := range positions {
+		suggestions[i] = ix.entries[pos]
+	}
+	return suggestions
+}
+
+// Normalize devuelve la clave con la que se comparan los textos: minúsculas, sin acentos y
+// sin espacios ni signos de puntuación, salvo '+' y '#' para distinguir C, C++ y C#.
+// "Node.js", "node js" y "NodeJS" dan la misma clave.
+func Normalize(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	s, _, err := transform.String(t, strings.ToLower(s))
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// fuzzyTolerance es la distancia de edición máxima aceptada como errata para un texto de
+// length caracteres.
+func fuzzyTolerance(length int) int {
+	switch {
+	case length < minApproximateLength+1:
+		return 0
+	case length < 9:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func phoneticEqual(primary, secondary string, t term) bool {
+	if primary == "" || t.primary == "" {
+		return false
+	}
+	return primary == t.primary || (secondary != "" && secondary == t.secondary) ||
+		primary == t.secondary || secondary == t.primary
+}
+
+// levenshtein calcula la distancia de edición entre a y b, por caracteres.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
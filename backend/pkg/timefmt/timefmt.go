@@ -0,0 +1,56 @@
+// Package timefmt unifica el tratamiento de las fechas: el servidor trabaja en UTC y todas las
+// fechas del JSON (API y WebSocket) se escriben en RFC 3339 con zona "Z".
+//
+// Para no romper a los clientes existentes, Parse acepta además los formatos que se usaban
+// antes en las peticiones ("2006-01-02 15:04:05", sin zona, y solo fecha). Las fechas sin zona
+// se interpretan en UTC.
+package timefmt
+
+import (
+	"fmt"
+	"time"
+)
+
+// legacyLayouts son los formatos sin zona que se aceptan por compatibilidad, en orden.
+var legacyLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	time.DateOnly,
+}
+
+// display es la zona horaria del proceso antes de UseUTC, para los textos que lee una persona.
+var display = time.Local
+
+// UseUTC hace que time.Now y las conversiones a hora local usen UTC en todo el proceso, de
+// modo que las fechas que se serializan salen siempre con zona "Z". La zona original (variable
+// TZ) sigue disponible en Display. Se llama al arrancar, antes de crear ningún valor de tiempo.
+func UseUTC() {
+	time.Local = time.UTC
+}
+
+// Display convierte t a la zona horaria original del servidor, para textos dirigidos a
+// personas (correos, descripciones de notificaciones). No se usa en el JSON.
+func Display(t time.Time) time.Time {
+	return t.In(display)
+}
+
+// Format devuelve t en UTC con formato RFC 3339.
+func Format(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Parse interpreta una fecha en RFC 3339 (con o sin fracción de segundo) o en uno de los
+// formatos anteriores sin zona, que se toman como UTC. Devuelve la fecha en UTC.
+func Parse(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t.UTC(), nil
+	}
+	for _, layout := range legacyLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("fecha %q inválida: se espera RFC 3339 (ej. 2006-01-02T15:04:05Z)", value)
+}
@@ -0,0 +1,336 @@
+// Package tsgen genera declaraciones TypeScript a partir de tipos Go, con los mismos nombres
+// de campo que usa encoding/json.
+//
+// Cada struct con nombre se declara una vez como interface y se referencia por su nombre. La
+// opcionalidad de los campos depende de quién produce el valor:
+//   - en los tipos que envía el cliente (input) un campo es opcional salvo que tenga la regla
+//     `validate:"required"`;
+//   - en los tipos que envía el servidor un campo es opcional si tiene omitempty, y los
+//     punteros, slices y mapas sin omitempty pueden llegar como null.
+//
+// Un struct usado en los dos sentidos se declara con las reglas del servidor.
+package tsgen
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generator acumula las interfaces de los structs alcanzables desde los tipos pedidos.
+type Generator struct {
+	decls map[reflect.Type]*decl
+	order []*decl
+	names map[string]bool
+}
+
+type decl struct {
+	t      reflect.Type
+	name   string
+	input  bool // Alcanzable desde un tipo que envía el cliente
+	output bool // Alcanzable desde un tipo que envía el servidor
+}
+
+// New crea un generador vacío.
+func New() *Generator {
+	return &Generator{decls: make(map[reflect.Type]*decl), names: make(map[string]bool)}
+}
+
+// Type devuelve la expresión TypeScript del tipo de v y registra los structs que alcanza.
+// input indica si los valores del tipo los envía el cliente.
+func (g *Generator) Type(v interface{}, input bool) string {
+	t := reflect.TypeOf(v)
+	g.mark(t, input)
+	return g.expr(t)
+}
+
+// Declarations devuelve las interfaces registradas, en el orden en que se encontraron.
+func (g *Generator) Declarations() string {
+	var b strings.Builder
+	for i, d := range g.order {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "export interface %s {\n", d.name)
+		for _, f := range g.fields(d.t, d.input && !d.output) {
+			fmt.Fprintf(&b, "  %s;\n", f)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// mark recorre el tipo y anota en qué sentido se usa cada struct con nombre.
+func (g *Generator) mark(t reflect.Type, input bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if opaque(t) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		g.mark(t.Elem(), input)
+	case reflect.Struct:
+		if t.Name() != "" {
+			d, ok := g.decls[t]
+			if !ok {
+				d = &decl{t: t, name: g.declName(t)}
+				g.decls[t] = d
+				g.order = append(g.order, d)
+			}
+			if (input && d.input) || (!input && d.output) {
+				return
+			}
+			if input {
+				d.input = true
+			} else {
+				d.output = true
+			}
+		}
+		eachField(t, func(sf reflect.StructField, _ string, _ string) {
+			g.mark(sf.Type, input)
+		})
+	}
+}
+
+// opaque indica si el tipo tiene una serialización que no se deduce de sus campos.
+func opaque(t reflect.Type) bool {
+	return t == timeType || t == rawMessageType ||
+		t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+func (g *Generator) expr(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return "string"
+	case t == rawMessageType:
+		return "unknown"
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return "unknown"
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return "string"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string" // base64
+		}
+		elem := g.expr(t.Elem())
+		if strings.ContainsAny(elem, " |") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case reflect.Map:
+		return "Record<string, " + g.expr(t.Elem()) + ">"
+	case reflect.Struct:
+		if d, ok := g.decls[t]; ok {
+			return d.name
+		}
+		// Struct anónimo: se declara en línea con las reglas del servidor
+		return "{ " + strings.Join(g.fields(t, false), "; ") + " }"
+	default:
+		// interface{} y tipos sin representación JSON
+		return "unknown"
+	}
+}
+
+// fields devuelve los campos del struct como "nombre: tipo".
+func (g *Generator) fields(t reflect.Type, inputOnly bool) []string {
+	var out []string
+	eachField(t, func(sf reflect.StructField, name, opts string) {
+		omitempty := hasOption(opts, "omitempty")
+		rules := sf.Tag.Get("validate")
+
+		typ := g.expr(sf.Type)
+		if hasOption(opts, "string") {
+			typ = "string"
+		} else if values := oneOf(rules); len(values) > 0 {
+			typ = literalUnion(sf.Type, values, typ)
+		}
+
+		optional := omitempty
+		if inputOnly {
+			optional = !hasRule(rules, "required")
+		} else if !omitempty && nullable(sf.Type) {
+			typ += " | null"
+		}
+
+		if optional {
+			out = append(out, propertyName(name)+"?: "+typ)
+		} else {
+			out = append(out, propertyName(name)+": "+typ)
+		}
+	})
+	return out
+}
+
+// eachField recorre los campos que serializa encoding/json, integrando los structs embebidos
+// sin nombre JSON.
+func eachField(t reflect.Type, fn func(sf reflect.StructField, name, opts string)) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				eachField(ft, fn)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fn(sf, name, opts)
+	}
+}
+
+func nullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+func hasOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRule(rules, rule string) bool {
+	for _, r := range strings.Split(rules, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// oneOf devuelve los valores de la regla oneof, si la hay.
+func oneOf(rules string) []string {
+	for _, r := range strings.Split(rules, ",") {
+		if arg, ok := strings.CutPrefix(r, "oneof="); ok {
+			return strings.Fields(arg)
+		}
+	}
+	return nil
+}
+
+// literalUnion convierte los valores de oneof en una unión de literales del tipo del campo.
+func literalUnion(t reflect.Type, values []string, fallback string) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	literals := make([]string, 0, len(values))
+	for _, v := range values {
+		switch t.Kind() {
+		case reflect.String:
+			literals = append(literals, strconv.Quote(v))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+				return fallback
+			}
+			literals = append(literals, v)
+		default:
+			return fallback
+		}
+	}
+	return strings.Join(literals, " | ")
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func propertyName(name string) string {
+	if identifier.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+// declName es el nombre del tipo Go; si otro paquete ya lo usa se antepone el paquete, ej.
+// "ModelsComment".
+func (g *Generator) declName(t reflect.Type) string {
+	base := sanitize(genericName(t.Name()))
+	name := base
+	if g.names[name] {
+		pkg := t.PkgPath()
+		if i := strings.LastIndex(pkg, "/"); i >= 0 {
+			pkg = pkg[i+1:]
+		}
+		prefix := sanitize(strings.ToUpper(pkg[:1]) + pkg[1:])
+		name = prefix + base
+		for i := 2; g.names[name]; i++ {
+			name = prefix + base + strconv.Itoa(i)
+		}
+	}
+	g.names[name] = true
+	return name
+}
+
+// qualifiedType es un nombre de tipo con su paquete, ej. "github.com/x/models.Comment".
+var qualifiedType = regexp.MustCompile(`[\w.\-/]+\.`)
+
+// genericName quita los paquetes de los argumentos de un tipo genérico y los concatena:
+// "Page[github.com/x/models.Comment]" queda "PageComment".
+func genericName(name string) string {
+	if !strings.Contains(name, "[") {
+		return name
+	}
+	name = qualifiedType.ReplaceAllString(name, "")
+	return strings.NewReplacer("[", "", "]", "", ",", "").Replace(name)
+}
+
+// sanitize deja solo caracteres válidos en un identificador (los tipos genéricos incluyen
+// corchetes y rutas de paquete en su nombre).
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
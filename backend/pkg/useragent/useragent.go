@@ -0,0 +1,124 @@
+// Package useragent clasifica la cabecera User-Agent en tipo de dispositivo, navegador y
+// sistema operativo. No pretende identificar versiones exactas: solo lo necesario para
+// mostrar las sesiones al usuario y detectar inicios de sesión desde dispositivos nuevos.
+package useragent
+
+import "strings"
+
+// Tipos de dispositivo.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+	DeviceUnknown = "unknown"
+)
+
+// Info es el resultado de clasificar un User-Agent.
+type Info struct {
+	DeviceType string
+	Browser    string
+	OS         string
+}
+
+// Fingerprint identifica el tipo de dispositivo para comparar sesiones: dispositivo,
+// navegador y sistema operativo, sin versiones.
+func (i Info) Fingerprint() string {
+	return i.DeviceType + "|" + i.Browser + "|" + i.OS
+}
+
+// String devuelve una descripción legible, por ejemplo "Chrome en Windows".
+func (i Info) String() string {
+	switch {
+	case i.Browser != "" && i.OS != "":
+		return i.Browser + " en " + i.OS
+	case i.Browser != "":
+		return i.Browser
+	case i.OS != "":
+		return i.OS
+	}
+	return "Dispositivo desconocido"
+}
+
+// rule asocia un fragmento del User-Agent (en minúsculas) con un nombre. Las reglas se
+// evalúan en orden, por eso las más específicas van primero (Edge y Opera incluyen "chrome",
+// y Chrome incluye "safari").
+type rule struct {
+	token string
+	name  string
+}
+
+var browserRules = []rule{
+	{"okhttp", "App Android"},
+	{"cfnetwork", "App iOS"},
+	{"dart:io", "App"},
+	{"edg", "Edge"},
+	{"opr/", "Opera"},
+	{"opera", "Opera"},
+	{"samsungbrowser", "Samsung Internet"},
+	{"firefox", "Firefox"},
+	{"fxios", "Firefox"},
+	{"crios", "Chrome"},
+	{"chrome", "Chrome"},
+	{"safari", "Safari"},
+	{"postman", "Postman"},
+	{"curl", "curl"},
+}
+
+var osRules = []rule{
+	{"windows", "Windows"},
+	{"iphone", "iOS"},
+	{"ipad", "iPadOS"},
+	{"cfnetwork", "iOS"},
+	{"android", "Android"},
+	{"okhttp", "Android"},
+	{"cros", "ChromeOS"},
+	{"mac os", "macOS"},
+	{"macintosh", "macOS"},
+	{"linux", "Linux"},
+}
+
+var botTokens = []string{"bot", "crawler", "spider", "curl", "wget", "python-requests", "go-http-client", "postman"}
+
+// Parse clasifica un User-Agent. Con una cadena vacía devuelve DeviceUnknown.
+func Parse(userAgent string) Info {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return Info{DeviceType: DeviceUnknown}
+	}
+
+	info := Info{Browser: match(ua, browserRules), OS: match(ua, osRules)}
+	switch {
+	case containsAny(ua, botTokens):
+		info.DeviceType = DeviceBot
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet") ||
+		(strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		info.DeviceType = DeviceTablet
+	case strings.Contains(ua, "mobi") || strings.Contains(ua, "iphone") ||
+		strings.Contains(ua, "okhttp") || strings.Contains(ua, "cfnetwork") || strings.Contains(ua, "dart:io"):
+		info.DeviceType = DeviceMobile
+	case info.OS == "Windows" || info.OS == "macOS" || info.OS == "Linux" || info.OS == "ChromeOS":
+		info.DeviceType = DeviceDesktop
+	default:
+		info.DeviceType = DeviceUnknown
+	}
+	return info
+}
+
+func match(ua string, rules []rule) string {
+	for _, r := range rules {
+		if strings.Contains(ua, r.token) {
+			return r.name
+		}
+	}
+	return ""
+}
+
+func containsAny(ua string, tokens []string) bool {
+	for _, token := range tokens {
+		if strings.Contains(ua, token) {
+			return true
+		}
+	}
+	return false
+}
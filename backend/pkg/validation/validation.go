@@ -0,0 +1,269 @@
+// Package validation valida structs a partir de la etiqueta `validate` de sus campos.
+//
+// Reglas soportadas (separadas por comas):
+//
+//	required     el campo no puede tener su valor cero (ni ser nil)
+//	min=N        strings: longitud mínima en caracteres; números: valor mínimo; slices/maps: elementos mínimos
+//	max=N        igual que min, como máximo
+//	oneof=a b c  el valor debe ser uno de los indicados (strings y enteros)
+//	dive         aplica la validación a cada elemento de un slice de structs
+//
+// Los campos se identifican por su nombre JSON, de modo que los errores se pueden
+// devolver tal cual al cliente.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError describe un campo que no cumple una regla.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Errors agrupa los errores de validación de un payload.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return "payload inválido: " + strings.Join(parts, "; ")
+}
+
+// Struct valida v (un struct o un puntero a struct) y devuelve Errors si algún campo
+// incumple sus reglas, o nil.
+func Struct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return Errors{{Field: "payload", Rule: "required", Message: "es requerido"}}
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("validation: se esperaba un struct, se recibió %s", rv.Kind())
+	}
+
+	var errs Errors
+	validateStruct(rv, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Decode convierte un payload genérico (normalmente un map[string]interface{} de un
+// mensaje JSON) en target y lo valida. Los errores de tipo del decodificador también se
+// devuelven como Errors, indicando el campo afectado.
+func Decode(payload interface{}, target interface{}) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("validation: error serializando el payload: %w", err)
+	}
+	if string(raw) == "null" {
+		raw = []byte("{}")
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			field := typeErr.Field
+			if field == "" {
+				field = "payload"
+			}
+			return Errors{{
+				Field:   field,
+				Rule:    "type",
+				Message: fmt.Sprintf("debe ser de tipo %s, se recibió %s", jsonTypeName(typeErr.Type), typeErr.Value),
+			}}
+		}
+		return Errors{{Field: "payload", Rule: "json", Message: err.Error()}}
+	}
+	return Struct(target)
+}
+
+func validateStruct(rv reflect.Value, prefix string, errs *Errors) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := fieldName(sf)
+		if name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		fv := rv.Field(i)
+		tag := sf.Tag.Get("validate")
+		if tag == "" || tag == "-" {
+			// Sin reglas propias, pero un struct anidado puede tenerlas.
+			if inner, ok := structValue(fv); ok {
+				validateStruct(inner, name, errs)
+			}
+			continue
+		}
+		validateField(fv, name, tag, errs)
+	}
+}
+
+func validateField(fv reflect.Value, name, tag string, errs *Errors) {
+	rules := strings.Split(tag, ",")
+
+	isNil := (fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface) && fv.IsNil()
+	for _, rule := range rules {
+		if rule == "required" && (isNil || fv.IsZero()) {
+			*errs = append(*errs, FieldError{Field: name, Rule: "required", Message: "es requerido"})
+			return
+		}
+	}
+	// Un campo opcional ausente no se valida más.
+	if isNil {
+		return
+	}
+	for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+		fv = fv.Elem()
+	}
+
+	for _, rule := range rules {
+		key, arg, _ := strings.Cut(rule, "=")
+		var fe *FieldError
+		switch key {
+		case "required", "":
+		case "min", "max":
+			fe = checkBound(fv, key, arg)
+		case "oneof":
+			fe = checkOneOf(fv, arg)
+		case "dive":
+			if fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array {
+				for j := 0; j < fv.Len(); j++ {
+					if inner, ok := structValue(fv.Index(j)); ok {
+						validateStruct(inner, fmt.Sprintf("%s[%d]", name, j), errs)
+					}
+				}
+			}
+		default:
+			fe = &FieldError{Rule: key, Message: "regla de validación desconocida"}
+		}
+		if fe != nil {
+			fe.Field = name
+			*errs = append(*errs, *fe)
+		}
+	}
+
+	if inner, ok := structValue(fv); ok {
+		validateStruct(inner, name, errs)
+	}
+}
+
+func checkBound(fv reflect.Value, rule, arg string) *FieldError {
+	limit, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return &FieldError{Rule: rule, Message: "límite de validación inválido: " + arg}
+	}
+
+	var value float64
+	var unit string
+	switch fv.Kind() {
+	case reflect.String:
+		value = float64(utf8.RuneCountInString(fv.String()))
+		unit = " caracteres"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		value = float64(fv.Len())
+		unit = " elementos"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(fv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(fv.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = fv.Float()
+	default:
+		return nil
+	}
+
+	if rule == "min" && value < limit {
+		if unit != "" {
+			return &FieldError{Rule: rule, Message: fmt.Sprintf("debe tener al menos %s%s", arg, unit)}
+		}
+		return &FieldError{Rule: rule, Message: "debe ser mayor o igual a " + arg}
+	}
+	if rule == "max" && value > limit {
+		if unit != "" {
+			return &FieldError{Rule: rule, Message: fmt.Sprintf("debe tener como máximo %s%s", arg, unit)}
+		}
+		return &FieldError{Rule: rule, Message: "debe ser menor o igual a " + arg}
+	}
+	return nil
+}
+
+func checkOneOf(fv reflect.Value, arg string) *FieldError {
+	options := strings.Fields(arg)
+	var value string
+	switch fv.Kind() {
+	case reflect.String:
+		value = fv.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = strconv.FormatInt(fv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = strconv.FormatUint(fv.Uint(), 10)
+	default:
+		return nil
+	}
+	for _, option := range options {
+		if value == option {
+			return nil
+		}
+	}
+	return &FieldError{Rule: "oneof", Message: "debe ser uno de: " + strings.Join(options, ", ")}
+}
+
+// structValue devuelve el struct contenido en v (directamente o tras un puntero no nil).
+func structValue(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	// time.Time y similares no tienen campos exportados que validar.
+	return v, v.Kind() == reflect.Struct && v.NumField() > 0 && v.Type().PkgPath() != "time"
+}
+
+// fieldName devuelve el nombre JSON del campo, o el nombre Go si no tiene etiqueta json.
+func fieldName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
+
+// jsonTypeName traduce un tipo Go al nombre del tipo JSON equivalente.
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
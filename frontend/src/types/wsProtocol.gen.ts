@@ -0,0 +1,845 @@
+// Código generado por "go run ./cmd/devtools ws-protocol" (backend) a partir de
+// internal/websocket/protocol. NO EDITAR A MANO.
+
+export interface AuthPayload {
+  token?: string;
+  resumeToken?: string;
+  lastPid?: string;
+}
+
+export interface SubscriptionPayload {
+  topics?: string[];
+}
+
+export interface ChunkPayload {
+  chunkId?: string;
+  seq?: number;
+  total?: number;
+  data?: string;
+}
+
+export interface AckPayload {
+  acknowledgedPid: string;
+  status: string;
+  error: string;
+}
+
+export interface HeartbeatRequestPayload {
+  clientTime?: number;
+}
+
+export interface GetChatListPayload {
+  syncToken?: string;
+}
+
+export interface GetChatHistoryPayload {
+  chatId: string;
+  limit?: number;
+  beforeMessageId?: string;
+  cursor?: string;
+}
+
+export interface SendChatMessagePayload {
+  chatId: string;
+  text?: string;
+  mediaId?: string;
+  responseTo?: string;
+  typeMessageId?: number;
+  clientMessageId?: string;
+}
+
+export interface GetNotificationsPayload {
+  onlyUnread?: boolean;
+  limit?: number;
+  offset?: number;
+  cursor?: string;
+}
+
+export interface MarkReadPayload {
+  notificationId: string;
+}
+
+export interface FriendRequestPayload {
+  notificationId: string;
+  timestamp?: string;
+}
+
+export interface GetUserProfilePayload {
+  userId: number;
+}
+
+export interface ForwardChatMessagePayload {
+  messageId: string;
+  chatId?: string;
+  toUserId?: number;
+}
+
+export interface MarkMessageReadPayload {
+  messageId: string;
+}
+
+export interface NotificationIDsPayload {
+  notificationIds: string[];
+}
+
+export interface ContactRequestPayload {
+  toUserId: number;
+  message?: string;
+}
+
+export interface GetFeedListPayload {
+  page?: number;
+  limit?: number;
+  cursor?: string;
+  ifNoneMatch?: string;
+}
+
+export interface SearchRequestPayload {
+  query?: string;
+  limit?: number;
+  offset?: number;
+}
+
+export interface SkillPayload {
+  id?: number;
+  skill: string;
+  level: string;
+}
+
+export interface LanguagePayload {
+  id?: number;
+  language: string;
+  level: string;
+}
+
+export interface WorkExperiencePayload {
+  id?: number;
+  company: string;
+  position: string;
+  startDate?: string;
+  endDate?: string;
+  description?: string;
+  isCurrentJob?: boolean;
+}
+
+export interface CertificationPayload {
+  id?: number;
+  certification: string;
+  institution: string;
+  dateObtained?: string;
+}
+
+export interface ProjectPayload {
+  id?: number;
+  title: string;
+  role: string;
+  description?: string;
+  company?: string;
+  document?: string;
+  projectStatus?: string;
+  startDate?: string;
+  expectedEndDate?: string;
+  isOngoing?: boolean;
+}
+
+export interface EducationPayload {
+  id?: number;
+  institution: string;
+  degree?: string;
+  campus?: string;
+  graduationDate?: string;
+  isCurrentlyStudying?: boolean;
+}
+
+export interface UpdateProfilePayload {
+  firstName?: string;
+  lastName?: string;
+  userName?: string;
+  phone?: string;
+  sex?: string;
+  birthdate?: string;
+  nationalityId?: number;
+  summary?: string;
+  address?: string;
+  github?: string;
+  linkedin?: string;
+  companyName?: string;
+  picture?: string;
+  email?: string;
+  contactEmail?: string;
+  twitter?: string;
+  facebook?: string;
+  docId?: string;
+  degreeId?: number;
+  universityId?: number;
+  sector?: string;
+  location?: string;
+  foundationYear?: number;
+  employeeCount?: number;
+}
+
+export interface ViewProfilePayload {
+  userId?: number;
+  rif?: string;
+  companyName?: string;
+}
+
+export interface CreateReviewRequest {
+  revieweeId: number;
+  communityEventId: number;
+  rating?: number;
+  comment?: string;
+  interactionType?: string;
+  applyBonus?: boolean;
+}
+
+export interface SessionInfoPayload {
+  resumeToken: string;
+  resumeWindowSeconds: number;
+  resumed: boolean;
+  replayed: number;
+  topics?: string[];
+}
+
+export interface MessageLimitsPayload {
+  maxMessageSize: number;
+  limits?: Record<string, number>;
+  chunkTimeoutSeconds: number;
+  maxPendingChunks: number;
+}
+
+export interface SubscriptionsPayload {
+  action: string;
+  topics: string[] | null;
+}
+
+export interface IdleWarningPayload {
+  closesInSeconds: number;
+}
+
+export interface HeartbeatPayload {
+  serverTime: number;
+  clientTime?: number;
+  receivedAt?: number;
+  intervalSeconds: number;
+  stats: HeartbeatStats;
+}
+
+export interface HeartbeatStats {
+  rttMs: number;
+  messagesReceived: number;
+  messagesSent: number;
+  sendQueueDepth: number;
+}
+
+export interface MaintenancePayload {
+  active: boolean;
+  message?: string;
+  closesInSeconds?: number;
+  drainAt?: string;
+  retryAfterSeconds?: number;
+}
+
+export interface SystemAnnouncementPayload {
+  id: number;
+  title: string;
+  message: string;
+  level: string;
+  sentAt: string;
+  expiresAt?: string;
+}
+
+export interface ImpersonationPayload {
+  impersonationId: number;
+  impersonatorId: number;
+  expiresAt: string;
+}
+
+export interface ChatInfo {
+  chatId: string;
+  otherUserId: number;
+  otherUserName: string;
+  otherFirstName?: string;
+  otherLastName?: string;
+  otherPicture?: string;
+  lastMessage?: string;
+  lastMessageTs?: number;
+  lastMessageFromUserId?: number;
+  unreadCount?: number;
+  isOnline: boolean;
+  type?: string;
+  isBlocked?: boolean;
+  lastSeen?: number;
+}
+
+export interface ChatListDelta {
+  full: boolean;
+  chats: ChatInfo[] | null;
+  removed: ChatRemoved[] | null;
+  syncToken: string;
+}
+
+export interface ChatRemoved {
+  chatId: string;
+  otherUserId: number;
+}
+
+export interface MessageDB {
+  id: string;
+  chatId?: string;
+  chatIdGroup?: string;
+  senderId: number;
+  typeMessageId: number;
+  content?: string;
+  mediaId?: string;
+  replyToMessageId?: string;
+  sentAt: string;
+  editedAt?: string;
+  status: string;
+  isHidden?: boolean;
+  clientMessageId?: string;
+  forwardedFromMessageId?: string;
+  forwardedFromSenderId?: number;
+}
+
+export interface PageMessageDB {
+  items: MessageDB[] | null;
+  nextCursor?: string;
+  hasMore: boolean;
+}
+
+export interface NotificationInfo {
+  id: string;
+  type: string;
+  title: string;
+  message: string;
+  timestamp: string;
+  isRead: boolean;
+  payload?: unknown;
+  profile?: ProfileData;
+  status?: string;
+  actionRequired?: boolean;
+  actionTakenAt?: string;
+  otherUserId?: number;
+  proyectId?: number;
+  groupId?: number;
+  group?: NotificationGroupInfo;
+}
+
+export interface ProfileData {
+  id: number;
+  firstName: string;
+  lastName: string;
+  userName: string;
+  email: string;
+  phone?: string;
+  sex?: string;
+  docId?: string;
+  nationalityId?: number;
+  nationalityName?: string;
+  birthdate?: string;
+  picture?: string;
+  degreeName?: string;
+  universityName?: string;
+  roleId: number;
+  roleName: string;
+  statusAuthorizedId: number;
+  summary?: string;
+  address?: string;
+  github?: string;
+  linkedin?: string;
+  createdAt: string;
+  updatedAt: string;
+  curriculum: CurriculumVitae;
+  isOnline?: boolean;
+  lastSeen?: number;
+  reputation?: ReputationStats;
+  reviews?: ReputationReviewItem[];
+  studentBadge?: StudentBadge;
+}
+
+export interface CurriculumVitae {
+  education: EducationItem[] | null;
+  experience: WorkExperienceItem[] | null;
+  certifications: CertificationItem[] | null;
+  skills: SkillItem[] | null;
+  languages: LanguageItem[] | null;
+  projects: ProjectItem[] | null;
+}
+
+export interface EducationItem {
+  id: number;
+  institution: string;
+  degree: string;
+  campus?: string;
+  graduationDate?: string;
+  countryId?: number;
+  countryName?: string;
+  isCurrentlyStudying?: boolean;
+}
+
+export interface WorkExperienceItem {
+  id: number;
+  personId?: number;
+  company: string;
+  position: string;
+  startDate?: string;
+  endDate?: string;
+  description?: string;
+  countryId?: number;
+  countryName?: string;
+  isCurrentJob?: boolean;
+}
+
+export interface CertificationItem {
+  id: number;
+  certification: string;
+  institution: string;
+  dateObtained?: string;
+  projectStatus?: string;
+  startDate?: string;
+  expectedEndDate?: string;
+  isOngoing?: boolean;
+}
+
+export interface SkillItem {
+  id: number;
+  skill: string;
+  level: string;
+}
+
+export interface LanguageItem {
+  id: number;
+  language: string;
+  level: string;
+}
+
+export interface ProjectItem {
+  id: number;
+  title: string;
+  role: string;
+  description?: string;
+  company?: string;
+  document?: string;
+  projectStatus?: string;
+  startDate?: string;
+  expectedEndDate?: string;
+  isOngoing?: boolean;
+}
+
+export interface ReputationStats {
+  reviewCount: number;
+  totalPointsRp: number;
+}
+
+export interface ReputationReviewItem {
+  id: number;
+  rating?: number;
+  comment?: string;
+  reviewerCompanyName?: string;
+  reviewerPicture?: string;
+}
+
+export interface StudentBadge {
+  kind: string;
+  universityId: number;
+  universityName: string;
+  verifiedAt: string;
+}
+
+export interface NotificationGroupInfo {
+  count: number;
+  actors?: ProfileData[];
+  notificationIds?: string[];
+}
+
+export interface PageNotificationInfo {
+  items: NotificationInfo[] | null;
+  nextCursor?: string;
+  hasMore: boolean;
+}
+
+export interface DndSummary {
+  since: string;
+  until: string;
+  notifications: number;
+  messages: number;
+}
+
+export interface ProfileViewedEvent {
+  anonymous: boolean;
+  companyId?: number;
+  companyName?: string;
+  viewedAt: string;
+}
+
+export interface Comment {
+  id: number;
+  communityEventId: number;
+  parentId?: number;
+  author: CommentAuthor;
+  content: string;
+  createdAt: string;
+  editedAt?: string;
+  isDeleted: boolean;
+  isHidden?: boolean;
+  replyCount: number;
+  replies?: Comment[];
+}
+
+export interface CommentAuthor {
+  id: number;
+  roleId: number;
+  firstName?: string;
+  lastName?: string;
+  companyName?: string;
+  picture?: string;
+}
+
+export interface ErrorPayload {
+  originalPid?: string;
+  code: number;
+  message: string;
+  errorCode?: string;
+  fields?: FieldError[];
+}
+
+export interface FieldError {
+  field: string;
+  rule: string;
+  message: string;
+}
+
+export interface CloseReason {
+  reason: string;
+  reconnect: boolean;
+  retryAfter?: number;
+  errorCode?: string;
+}
+
+/** Payload de cada mensaje que envía el cliente. `undefined` = sin payload. */
+export interface ClientPayloads {
+  /** Credenciales de una conexión abierta sin token; debe ser el primer mensaje */
+  "auth": AuthPayload;
+  /** Suscribe la conexión a uno o varios tópicos */
+  "subscribe": SubscriptionPayload;
+  /** Cancela la suscripción a uno o varios tópicos */
+  "unsubscribe": SubscriptionPayload;
+  /** Fragmento de un mensaje mayor que maxMessageSize */
+  "chunk": ChunkPayload;
+  /** Confirma la recepción de un mensaje del servidor */
+  "client_ack": AckPayload;
+  /** Hora del cliente; el servidor responde con un heartbeat con el mismo PID */
+  "heartbeat": HeartbeatRequestPayload;
+  /** Solicitud de un recurso/acción (ver DataRequests) */
+  "data_request": DataRequestPayload;
+  /** Pide la lista de chats del usuario, o sus cambios con syncToken */
+  "get_chat_list": GetChatListPayload;
+  /** Pide el historial de un chat */
+  "get_history": GetChatHistoryPayload;
+  /** Envía un mensaje de chat */
+  "send_chat_message": SendChatMessagePayload;
+  /** Pide las notificaciones del usuario */
+  "get_notifications": GetNotificationsPayload;
+  /** Marca una notificación como leída */
+  "mark_notification_read": MarkReadPayload;
+  /** Acepta una solicitud de contacto */
+  "accept_request": FriendRequestPayload;
+  /** Rechaza una solicitud de contacto */
+  "reject_request": FriendRequestPayload;
+  /** Pide el perfil del usuario conectado */
+  "get_my_profile": undefined;
+  /** Pide el perfil de otro usuario */
+  "get_user_profile": GetUserProfilePayload;
+}
+
+/** Campo data de cada acción de data_request, por "recurso/acción". */
+export interface DataRequests {
+  /** Lista de chats del usuario, o sus cambios con syncToken */
+  "chat/get_list": GetChatListPayload;
+  /** Historial de un chat */
+  "chat/get_history": GetChatHistoryPayload;
+  /** Envía un mensaje de chat */
+  "chat/send_message": SendChatMessagePayload;
+  /** Reenvía un mensaje a otros chats */
+  "chat/forward_message": ForwardChatMessagePayload;
+  /** Marca mensajes de un chat como leídos */
+  "chat/mark_read": MarkMessageReadPayload;
+  /** Lista de notificaciones */
+  "notification/get_list": GetNotificationsPayload;
+  /** Notificaciones pendientes de leer */
+  "notification/get_pending": GetNotificationsPayload;
+  /** Marca una notificación como leída */
+  "notification/mark_read": MarkReadPayload;
+  /** Marca varias notificaciones como leídas */
+  "notification/mark_read_bulk": NotificationIDsPayload;
+  /** Marca varias notificaciones como no leídas */
+  "notification/mark_unread_bulk": NotificationIDsPayload;
+  /** Borra varias notificaciones */
+  "notification/delete_bulk": NotificationIDsPayload;
+  /** Datos del panel del usuario */
+  "dashboard/get_info": Record<string, unknown> | undefined;
+  /** Acepta una solicitud de contacto */
+  "friend/accept_request": FriendRequestPayload;
+  /** Rechaza una solicitud de contacto */
+  "friend/reject_request": FriendRequestPayload;
+  /** Envía una solicitud de contacto */
+  "friend/contact": ContactRequestPayload;
+  /** Página del feed */
+  "feed/get_list": GetFeedListPayload;
+  /** Busca usuarios */
+  "search/users": SearchRequestPayload;
+  /** Busca empresas */
+  "search/companies": SearchRequestPayload;
+  /** Busca usuarios y empresas */
+  "search/all": SearchRequestPayload;
+  /** Busca egresados */
+  "search/graduates": SearchRequestPayload;
+  /** CV del usuario */
+  "cv/get": Record<string, unknown> | undefined;
+  /** Crea o actualiza una habilidad */
+  "cv/set_skill": SkillPayload;
+  /** Crea o actualiza un idioma */
+  "cv/set_language": LanguagePayload;
+  /** Crea o actualiza una experiencia laboral */
+  "cv/set_work_experience": WorkExperiencePayload;
+  /** Crea o actualiza una certificación */
+  "cv/set_certification": CertificationPayload;
+  /** Crea o actualiza un proyecto */
+  "cv/set_project": ProjectPayload;
+  /** Crea o actualiza una formación */
+  "cv/set_education": EducationPayload;
+  /** Perfil del usuario conectado */
+  "profile/get": Record<string, unknown> | undefined;
+  /** Actualiza el perfil del usuario */
+  "profile/update": UpdateProfilePayload;
+  /** Registra la visita a un perfil */
+  "profile/view": ViewProfilePayload;
+  /** Crea una reseña */
+  "reputation/create_review": CreateReviewRequest;
+}
+
+/** Payload de cada mensaje que envía el servidor. `undefined` = sin payload. */
+export interface ServerPayloads {
+  /** Token de reanudación y resultado de la reanudación, al conectar */
+  "session_info": SessionInfoPayload;
+  /** Tamaños máximos de los mensajes del cliente, al conectar */
+  "message_limits": MessageLimitsPayload;
+  /** Tópicos vigentes tras subscribe/unsubscribe */
+  "subscriptions": SubscriptionsPayload;
+  /** Confirma la recepción o el procesamiento de un mensaje del cliente */
+  "server_ack": AckPayload;
+  /** Error al procesar un mensaje; el detalle va en el campo error */
+  "error_notification": undefined;
+  /** La conexión se cerrará por inactividad */
+  "idle_warning": IdleWarningPayload;
+  /** Hora del servidor y estado de la conexión, periódico o en respuesta a un heartbeat */
+  "heartbeat": HeartbeatPayload;
+  /** El servidor entra o sale de mantenimiento */
+  "maintenance": MaintenancePayload;
+  /** Anuncio del panel de administración */
+  "system_announcement": SystemAnnouncementPayload;
+  /** La conexión usa un token de suplantación del soporte */
+  "impersonation": ImpersonationPayload;
+  /** Lista de chats */
+  "chat_list": ChatInfo[];
+  /** Chats cambiados y eliminados desde el syncToken de chat/get_list */
+  "chat_list_delta": ChatListDelta;
+  /** Historial de un chat */
+  "get_history": MessageDB[];
+  /** Página del historial de un chat pedida con cursor */
+  "chat_history_page": PageMessageDB;
+  /** Mensaje de chat nuevo */
+  "new_chat_message": MessageDB;
+  /** Resultado del envío de un mensaje de chat (originalPID y message) */
+  "message_status_update": Record<string, unknown>;
+  /** Otro dispositivo del usuario marcó mensajes o notificaciones como leídos, o cambió o borró notificaciones */
+  "read_sync": Record<string, unknown>;
+  /** Cambio de presencia de un contacto */
+  "presence_event": Record<string, unknown>;
+  /** Lista de notificaciones */
+  "notification_list": NotificationInfo[];
+  /** Página de notificaciones pedida con cursor */
+  "notification_page": PageNotificationInfo;
+  /** Notificación nueva */
+  "new_notification": NotificationInfo;
+  /** Resumen de lo recibido durante el horario de No molestar */
+  "dnd_summary": DndSummary;
+  /** Perfil del usuario conectado */
+  "my_profile_data": ProfileData;
+  /** Perfil de otro usuario */
+  "user_profile_data": ProfileData;
+  /** Una empresa visitó el perfil del usuario */
+  "profile_viewed": ProfileViewedEvent;
+  /** Comentario nuevo en el tópico event:{id}:comments */
+  "new_comment": Comment;
+  /** Respuesta de un data_request (feed, dashboard, CV, búsquedas...) */
+  "data_event": Record<string, unknown>;
+  /** Métricas del servidor en el tópico admin:metrics */
+  "admin_metrics": Record<string, unknown>;
+}
+
+/** Códigos de cierre del servidor (CloseEvent.code); el motivo se lee con parseCloseReason. */
+export const WsCloseCodes = {
+  /** El proceso se sustituye por otro; reconectar enseguida */
+  serviceRestart: 1012,
+  /** Mantenimiento; reconectar pasado retryAfter */
+  tryAgainLater: 1013,
+  /** El cliente no respeta el protocolo; no reconectar sin corregirlo */
+  protocolViolation: 4400,
+  /** Credenciales rechazadas; no reconectar con las mismas */
+  authFailed: 4401,
+  /** Conexión cerrada por un administrador o la moderación */
+  kicked: 4403,
+  /** No llegó el mensaje auth a tiempo */
+  authTimeout: 4408,
+  /** Conexión inactiva; reconectar cuando el usuario vuelva */
+  idle: 4410,
+  /** Credenciales caducadas; renovarlas antes de reconectar */
+  authExpired: 4419,
+  /** Demasiados mensajes; reconectar pasado retryAfter */
+  rateLimited: 4429,
+  /** El servidor se apaga o drena; reconectar pasado retryAfter */
+  serverShutdown: 4503,
+} as const;
+
+export type ClientMessageType = keyof ClientPayloads;
+export type DataRequestKey = keyof DataRequests;
+export type ServerMessageType = keyof ServerPayloads;
+
+/** Payload de data_request. */
+export interface DataRequestPayload {
+  resource: string;
+  action: string;
+  data?: unknown;
+}
+
+/** Mensaje del cliente al servidor. */
+export interface ClientMessage<T extends ClientMessageType = ClientMessageType> {
+  pid?: string;
+  type: T;
+  targetUserId?: number;
+  payload?: ClientPayloads[T];
+}
+
+/** Mensaje del servidor al cliente. */
+export interface ServerMessage<T extends ServerMessageType = ServerMessageType> {
+  pid?: string;
+  type: T;
+  fromUserId?: number;
+  topic?: string;
+  payload: ServerPayloads[T];
+  error?: ErrorPayload;
+}
+
+export interface SendOptions {
+  /** PID del mensaje; por defecto se genera uno. */
+  pid?: string;
+  targetUserId?: number;
+}
+
+/** Argumentos de send/request: el payload es opcional solo si admite undefined. */
+type PayloadArgs<P> = undefined extends P
+  ? [payload?: P, options?: SendOptions]
+  : [payload: P, options?: SendOptions];
+
+type ServerHandler<T extends ServerMessageType> = (payload: ServerPayloads[T], message: ServerMessage<T>) => void;
+
+/**
+ * Decodifica el motivo JSON de un cierre del servidor. Devuelve null si el motivo no lo es
+ * (p. ej. un cierre del navegador o de un proxy). Si reconnect es true se puede reconectar
+ * con las mismas credenciales pasados retryAfter segundos.
+ */
+export function parseCloseReason(event: CloseEvent): CloseReason | null {
+  try {
+    const reason = JSON.parse(event.reason) as CloseReason;
+    return typeof reason?.reason === 'string' ? reason : null;
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Estima el desfase del reloj local con el del servidor a partir de la respuesta a un
+ * heartbeat del cliente: hora del servidor ≈ Date.now() + desfase. Devuelve null si el
+ * heartbeat no responde a uno del cliente.
+ */
+export function clockOffset(heartbeat: HeartbeatPayload, receivedAt = Date.now()): number | null {
+  if (!heartbeat.clientTime || !heartbeat.receivedAt) {
+    return null;
+  }
+  return Math.round((heartbeat.receivedAt - heartbeat.clientTime + (heartbeat.serverTime - receivedAt)) / 2);
+}
+
+/**
+ * Cliente tipado sobre un WebSocket ya abierto. No gestiona la conexión ni la reconexión:
+ * al reconectar hay que crear otro cliente con el nuevo socket.
+ */
+export class WsProtocolClient {
+  private readonly socket: WebSocket;
+  private readonly handlers = new Map<string, Set<(message: ServerMessage) => void>>();
+  private seq = 0;
+
+  constructor(socket: WebSocket) {
+    this.socket = socket;
+    socket.addEventListener('message', (event: MessageEvent) => this.dispatch(event.data));
+  }
+
+  /** Envía un mensaje y devuelve su PID. */
+  send<T extends ClientMessageType>(type: T, ...args: PayloadArgs<ClientPayloads[T]>): string {
+    const [payload, options = {}] = args;
+    const pid = options.pid ?? this.nextPid();
+    const message: ClientMessage<T> = { pid, type, payload };
+    if (options.targetUserId !== undefined) {
+      message.targetUserId = options.targetUserId;
+    }
+    this.socket.send(JSON.stringify(message));
+    return pid;
+  }
+
+  /** Envía un data_request para la clave "recurso/acción" y devuelve su PID. */
+  request<K extends DataRequestKey>(key: K, ...args: PayloadArgs<DataRequests[K]>): string {
+    const [data, options] = args;
+    const [resource, action] = key.split('/');
+    return this.send('data_request', { resource, action, data }, options);
+  }
+
+  /** Confirma la recepción de un mensaje del servidor. */
+  ack(pid: string, status = 'processed'): string {
+    return this.send('client_ack', { acknowledgedPid: pid, status, error: '' });
+  }
+
+  /** Envía un heartbeat con la hora local. El servidor responde con un heartbeat con el mismo PID. */
+  heartbeat(): string {
+    return this.send('heartbeat', { clientTime: Date.now() });
+  }
+
+  /** Registra un handler para un tipo de mensaje del servidor. Devuelve la función para quitarlo. */
+  on<T extends ServerMessageType>(type: T, handler: ServerHandler<T>): () => void {
+    const wrapped = (message: ServerMessage) => handler(message.payload as ServerPayloads[T], message as ServerMessage<T>);
+    const set = this.handlers.get(type) ?? new Set<(message: ServerMessage) => void>();
+    this.handlers.set(type, set);
+    set.add(wrapped);
+    return () => {
+      set.delete(wrapped);
+    };
+  }
+
+  private dispatch(data: unknown): void {
+    if (typeof data !== 'string') {
+      return;
+    }
+    let message: ServerMessage;
+    try {
+      message = JSON.parse(data) as ServerMessage;
+    } catch {
+      return;
+    }
+    this.handlers.get(message.type)?.forEach((handler) => handler(message));
+  }
+
+  private nextPid(): string {
+    this.seq += 1;
+    return `${Date.now().toString(36)}-${this.seq}`;
+  }
+}